@@ -0,0 +1,242 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"mercator-hq/jupiter/pkg/cli"
+	"mercator-hq/jupiter/pkg/config"
+)
+
+var configValidateFlags struct {
+	schema bool
+	output string
+	strict bool
+	format string
+}
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Configuration tooling",
+	Long: `Tools for Mercator configuration files.
+
+Subcommands:
+  validate - Validate a configuration file, or export its JSON Schema`,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate [file]",
+	Short: "Validate a configuration file",
+	Long: `Validate a configuration file without starting the server, and list
+every problem with its field path and line:
+  - YAML syntax and type errors
+  - ${NAME} environment variable and ${secret:NAME} secret references
+    that do not resolve
+  - validation errors, before and after MERCATOR_* environment overrides
+  - unknown keys, which are ignored when loading (warnings)
+
+References are resolved in dry-run: environment variables are read, and
+secrets are fetched from the providers under security.secrets, to
+validate the resolved values. Values are never printed.
+
+With --schema, a JSON Schema of the configuration structure is written
+instead, for editor completion and CI checks.
+
+The file defaults to --config.
+
+Examples:
+  # Validate the configuration
+  mercator config validate config.yaml
+
+  # Fail on unknown keys too, with JSON output for CI
+  mercator config validate config.yaml --strict --format json
+
+  # Export the JSON Schema for editors
+  mercator config validate --schema -o mercator.schema.json`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: configValidate,
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configValidateCmd)
+
+	configValidateCmd.Flags().BoolVar(&configValidateFlags.schema, "schema", false, "write the JSON Schema of the configuration instead of validating")
+	configValidateCmd.Flags().StringVarP(&configValidateFlags.output, "output", "o", "", "schema output file (default: stdout)")
+	configValidateCmd.Flags().BoolVar(&configValidateFlags.strict, "strict", false, "treat warnings as errors")
+	configValidateCmd.Flags().StringVar(&configValidateFlags.format, "format", "text", "output format: text, json")
+}
+
+// configIssue is a configuration problem in the validate output.
+type configIssue struct {
+	Field   string `json:"field,omitempty"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// configValidateReport is the JSON output of config validate.
+type configValidateReport struct {
+	File       string             `json:"file"`
+	Valid      bool               `json:"valid"`
+	Errors     []configIssue      `json:"errors"`
+	Warnings   []configIssue      `json:"warnings"`
+	References []config.Reference `json:"references"`
+}
+
+func configValidate(cmd *cobra.Command, args []string) error {
+	if configValidateFlags.schema {
+		return writeConfigSchema(configValidateFlags.output)
+	}
+
+	path := cfgFile
+	if len(args) > 0 {
+		path = args[0]
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cli.NewConfigError(path, fmt.Sprintf("failed to read config: %v", err))
+	}
+
+	result, err := checkConfig(data)
+	if err != nil {
+		return cli.NewCommandError("config validate", err)
+	}
+
+	report := configValidateReport{
+		File:       path,
+		Valid:      result.OK() && (!configValidateFlags.strict || len(result.Warnings) == 0),
+		Errors:     configIssues(result, result.Errors),
+		Warnings:   configIssues(result, result.Warnings),
+		References: result.References,
+	}
+	if report.References == nil {
+		report.References = []config.Reference{}
+	}
+
+	if configValidateFlags.format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		printConfigReport(&report)
+	}
+
+	if !report.Valid {
+		return cli.NewCommandError("config validate", fmt.Errorf("%d error(s), %d warning(s) in %s", len(report.Errors), len(report.Warnings), path))
+	}
+	return nil
+}
+
+// checkConfig checks a configuration file, resolving its references. As
+// secrets are fetched from the providers the file configures, the file is
+// decoded a first time with environment references only.
+func checkConfig(data []byte) (*config.CheckResult, error) {
+	resolveEnv := func(ref config.Reference) (string, error) {
+		if ref.Secret {
+			return "", fmt.Errorf("secrets are not resolved")
+		}
+		value, ok := os.LookupEnv(ref.Name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", ref.Name)
+		}
+		return value, nil
+	}
+
+	result := config.Check(data, resolveEnv)
+	hasSecrets := false
+	for _, ref := range result.References {
+		hasSecrets = hasSecrets || ref.Secret
+	}
+	if !hasSecrets || result.Config == nil {
+		return result, nil
+	}
+
+	manager, closeProviders, err := newSecretsManager(&result.Config.Security.Secrets)
+	if err != nil {
+		return nil, err
+	}
+	defer closeProviders()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	return config.Check(data, func(ref config.Reference) (string, error) {
+		if !ref.Secret {
+			return resolveEnv(ref)
+		}
+		return manager.GetSecret(ctx, ref.Name)
+	}), nil
+}
+
+// configIssues returns field errors with their lines in the file.
+func configIssues(result *config.CheckResult, errs []config.FieldError) []configIssue {
+	issues := make([]configIssue, 0, len(errs))
+	for _, err := range errs {
+		issues = append(issues, configIssue{
+			Field:   err.Field,
+			Line:    result.Line(err.Field),
+			Message: err.Message,
+		})
+	}
+	return issues
+}
+
+func printConfigReport(report *configValidateReport) {
+	fmt.Printf("Validating %s...\n", report.File)
+
+	for _, issue := range report.Errors {
+		fmt.Printf("✗ Error: %s\n", formatConfigIssue(issue))
+	}
+	for _, issue := range report.Warnings {
+		fmt.Printf("⚠  Warning: %s\n", formatConfigIssue(issue))
+	}
+	if len(report.Errors) == 0 {
+		fmt.Println("✓ Configuration valid")
+		if len(report.References) > 0 {
+			fmt.Printf("✓ %d reference(s) resolved\n", len(report.References))
+		}
+	}
+
+	fmt.Println()
+	fmt.Println("Summary:")
+	fmt.Printf("  %d error(s), %d warning(s)\n", len(report.Errors), len(report.Warnings))
+	if configValidateFlags.strict && len(report.Warnings) > 0 {
+		fmt.Println("  Strict mode enabled: treating warnings as errors")
+	}
+}
+
+// formatConfigIssue formats an issue as "field: message (line N)".
+func formatConfigIssue(issue configIssue) string {
+	s := issue.Message
+	if issue.Field != "" {
+		s = issue.Field + ": " + s
+	}
+	if issue.Line > 0 {
+		s += fmt.Sprintf(" (line %d)", issue.Line)
+	}
+	return s
+}
+
+// writeConfigSchema writes the configuration JSON Schema to path, or to
+// stdout if path is empty.
+func writeConfigSchema(path string) error {
+	var output io.Writer = os.Stdout
+	if path != "" {
+		file, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer file.Close()
+		output = file
+	}
+
+	encoder := json.NewEncoder(output)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(config.JSONSchema())
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeTestConfig(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestConfigValidate(t *testing.T) {
+	t.Setenv("MERCATOR_TEST_OPENAI_KEY", "sk-test")
+	path := writeTestConfig(t, `
+proxy:
+  listen_address: "127.0.0.1:8080"
+providers:
+  openai:
+    base_url: "https://api.openai.com/v1"
+    api_key: "${MERCATOR_TEST_OPENAI_KEY}"
+    timeout: 60s
+    extra: true
+`)
+
+	configValidateFlags.schema = false
+	configValidateFlags.strict = false
+	configValidateFlags.format = "text"
+	if err := configValidate(nil, []string{path}); err != nil {
+		t.Errorf("configValidate() returned error: %v", err)
+	}
+
+	// The unknown providers.openai.extra key fails in strict mode
+	configValidateFlags.strict = true
+	defer func() { configValidateFlags.strict = false }()
+	if err := configValidate(nil, []string{path}); err == nil {
+		t.Error("configValidate() in strict mode should return error")
+	}
+}
+
+func TestConfigValidateUnresolvedReference(t *testing.T) {
+	path := writeTestConfig(t, `
+proxy:
+  listen_address: "127.0.0.1:8080"
+providers:
+  openai:
+    base_url: "https://api.openai.com/v1"
+    api_key: "${MERCATOR_TEST_UNSET_KEY}"
+`)
+
+	configValidateFlags.schema = false
+	configValidateFlags.strict = false
+	configValidateFlags.format = "json"
+	if err := configValidate(nil, []string{path}); err == nil {
+		t.Error("configValidate() with an unresolved reference should return error")
+	}
+}
+
+func TestConfigValidateSchema(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "schema.json")
+	configValidateFlags.schema = true
+	configValidateFlags.output = output
+	defer func() {
+		configValidateFlags.schema = false
+		configValidateFlags.output = ""
+	}()
+
+	if err := configValidate(nil, nil); err != nil {
+		t.Fatalf("configValidate() --schema returned error: %v", err)
+	}
+	data, err := os.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("schema is not JSON: %v", err)
+	}
+	if schema["properties"] == nil {
+		t.Errorf("schema = %v, want properties", schema)
+	}
+}
@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
@@ -12,27 +14,35 @@ import (
 	"mercator-hq/jupiter/pkg/cli"
 	"mercator-hq/jupiter/pkg/config"
 	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/evidence/export"
+	"mercator-hq/jupiter/pkg/evidence/integrity"
 	"mercator-hq/jupiter/pkg/evidence/storage"
+	"mercator-hq/jupiter/pkg/security/sigv4"
 )
 
 var evidenceFlags struct {
-	backend   string
-	timeRange string
-	user      string
-	apiKey    string
-	policy    string
-	provider  string
-	model     string
-	minCost   float64
-	maxCost   float64
-	minTokens int
-	maxTokens int
-	limit     int
-	offset    int
-	format    string
-	verify    bool
-	output    string
-	decision  string
+	backend    string
+	timeRange  string
+	user       string
+	apiKey     string
+	policy     string
+	provider   string
+	model      string
+	minCost    float64
+	maxCost    float64
+	minTokens  int
+	maxTokens  int
+	limit      int
+	offset     int
+	format     string
+	verify     bool
+	keyFile    string
+	output     string
+	decision   string
+	search     string
+	tool       string
+	traceID    string
+	decisionID string
 }
 
 var evidenceCmd = &cobra.Command{
@@ -45,6 +55,9 @@ querying, exporting, and analyzing LLM request/response audit trails.
 
 Subcommands:
   query   - Query evidence records with filters
+  export  - Stream all matching records to a file (resumable)
+  stats   - Aggregate statistics by user, team, provider, model, day, or cost tag
+  replay  - Re-run recorded requests through the current policies
   report  - Generate audit report with statistics (not yet implemented)
 
 Examples:
@@ -77,6 +90,12 @@ Examples:
   # Filter by cost threshold
   mercator evidence query --min-cost 1.0 --max-cost 10.0
 
+  # Search prompts and responses
+  mercator evidence query --search '"credit card"'
+
+  # Verify record signatures with the signer's public key
+  mercator evidence query --user "user-123" --verify --key keys/evidence_public.pem
+
   # Export to JSON
   mercator evidence query --format json --output evidence.json`,
 	RunE: queryEvidence,
@@ -102,6 +121,10 @@ func init() {
 	evidenceQueryCmd.Flags().StringVar(&evidenceFlags.provider, "provider", "", "filter by provider")
 	evidenceQueryCmd.Flags().StringVar(&evidenceFlags.model, "model", "", "filter by model")
 	evidenceQueryCmd.Flags().StringVar(&evidenceFlags.decision, "decision", "", "filter by policy decision (allow, block, transform)")
+	evidenceQueryCmd.Flags().StringVar(&evidenceFlags.search, "search", "", "full-text search of prompts and responses (words and \"quoted phrases\")")
+	evidenceQueryCmd.Flags().StringVar(&evidenceFlags.tool, "tool", "", "filter by tool called in the response")
+	evidenceQueryCmd.Flags().StringVar(&evidenceFlags.traceID, "trace-id", "", "filter by OpenTelemetry trace ID")
+	evidenceQueryCmd.Flags().StringVar(&evidenceFlags.decisionID, "decision-id", "", "filter by policy decision ID")
 	evidenceQueryCmd.Flags().Float64Var(&evidenceFlags.minCost, "min-cost", 0, "minimum cost threshold")
 	evidenceQueryCmd.Flags().Float64Var(&evidenceFlags.maxCost, "max-cost", 0, "maximum cost threshold")
 	evidenceQueryCmd.Flags().IntVar(&evidenceFlags.minTokens, "min-tokens", 0, "minimum token threshold")
@@ -109,7 +132,8 @@ func init() {
 	evidenceQueryCmd.Flags().IntVar(&evidenceFlags.limit, "limit", 100, "max results")
 	evidenceQueryCmd.Flags().IntVar(&evidenceFlags.offset, "offset", 0, "pagination offset")
 	evidenceQueryCmd.Flags().StringVar(&evidenceFlags.format, "format", "text", "output format: text, json, csv")
-	evidenceQueryCmd.Flags().BoolVar(&evidenceFlags.verify, "verify", false, "verify signatures")
+	evidenceQueryCmd.Flags().BoolVar(&evidenceFlags.verify, "verify", false, "verify record signatures")
+	evidenceQueryCmd.Flags().StringVar(&evidenceFlags.keyFile, "key", "", "public or private key file for --verify (default: evidence signing key)")
 	evidenceQueryCmd.Flags().StringVarP(&evidenceFlags.output, "output", "o", "", "output file (default: stdout)")
 
 	// Flags for report command
@@ -131,26 +155,9 @@ func queryEvidence(cmd *cobra.Command, args []string) error {
 		backendType = cfg.Evidence.Backend
 	}
 
-	// Create storage backend
-	var store evidence.Storage
-	var err error
-	switch backendType {
-	case "sqlite":
-		sqliteConfig := &storage.SQLiteConfig{
-			Path:         cfg.Evidence.SQLite.Path,
-			MaxOpenConns: cfg.Evidence.SQLite.MaxOpenConns,
-			MaxIdleConns: cfg.Evidence.SQLite.MaxIdleConns,
-			WALMode:      cfg.Evidence.SQLite.WALMode,
-			BusyTimeout:  cfg.Evidence.SQLite.BusyTimeout,
-		}
-		store, err = storage.NewSQLiteStorage(sqliteConfig)
-		if err != nil {
-			return cli.NewCommandError("evidence", fmt.Errorf("failed to create SQLite storage: %w", err))
-		}
-	case "memory":
-		store = storage.NewMemoryStorage()
-	default:
-		return fmt.Errorf("unsupported backend: %s (supported: sqlite, memory)", backendType)
+	store, err := openEvidenceStore(cfg, backendType)
+	if err != nil {
+		return err
 	}
 	defer store.Close()
 
@@ -180,37 +187,7 @@ func queryEvidence(cmd *cobra.Command, args []string) error {
 		query.EndTime = &endTime
 	}
 
-	// Apply filters
-	if evidenceFlags.user != "" {
-		query.UserID = evidenceFlags.user
-	}
-	if evidenceFlags.apiKey != "" {
-		query.APIKey = evidenceFlags.apiKey
-	}
-	if evidenceFlags.provider != "" {
-		query.Provider = evidenceFlags.provider
-	}
-	if evidenceFlags.model != "" {
-		query.Model = evidenceFlags.model
-	}
-	if evidenceFlags.policy != "" {
-		query.PolicyID = evidenceFlags.policy
-	}
-	if evidenceFlags.decision != "" {
-		query.PolicyDecision = evidenceFlags.decision
-	}
-	if evidenceFlags.minCost > 0 {
-		query.MinCost = &evidenceFlags.minCost
-	}
-	if evidenceFlags.maxCost > 0 {
-		query.MaxCost = &evidenceFlags.maxCost
-	}
-	if evidenceFlags.minTokens > 0 {
-		query.MinTokens = &evidenceFlags.minTokens
-	}
-	if evidenceFlags.maxTokens > 0 {
-		query.MaxTokens = &evidenceFlags.maxTokens
-	}
+	applyEvidenceFilters(query)
 
 	// Execute query
 	ctx := context.Background()
@@ -219,6 +196,18 @@ func queryEvidence(cmd *cobra.Command, args []string) error {
 		return cli.NewCommandError("evidence", fmt.Errorf("query failed: %w", err))
 	}
 
+	var signatures *signatureResults
+	if evidenceFlags.verify {
+		publicKey, err := loadEvidencePublicKey(ctx, cfg, evidenceFlags.keyFile)
+		if err != nil {
+			return cli.NewCommandError("evidence", fmt.Errorf("failed to load verification key: %w", err))
+		}
+		if publicKey == nil {
+			return fmt.Errorf("--verify requires --key or evidence.signing_key_path/signing_key_secret")
+		}
+		signatures = verifySignatures(records, publicKey)
+	}
+
 	// Output results
 	var output *os.File
 	if evidenceFlags.output != "" {
@@ -233,15 +222,47 @@ func queryEvidence(cmd *cobra.Command, args []string) error {
 
 	switch evidenceFlags.format {
 	case "json":
-		return outputEvidenceJSON(output, records)
+		err = outputEvidenceJSON(output, records, signatures)
 	case "csv":
 		return fmt.Errorf("CSV format not yet implemented")
 	default:
-		return outputEvidenceText(output, records, query)
+		err = outputEvidenceText(output, records, query, signatures)
 	}
+	if err != nil {
+		return err
+	}
+
+	if signatures != nil && len(signatures.Invalid) > 0 {
+		return cli.NewCommandError("evidence", fmt.Errorf("%d records failed signature verification", len(signatures.Invalid)))
+	}
+	return nil
 }
 
-func outputEvidenceText(output *os.File, records []*evidence.EvidenceRecord, query *evidence.Query) error {
+// signatureResults summarizes signature verification of query results.
+type signatureResults struct {
+	Verified int               `json:"verified"`
+	Unsigned int               `json:"unsigned"`
+	Invalid  map[string]string `json:"invalid,omitempty"` // record ID -> error
+}
+
+// verifySignatures verifies the signature of each record against publicKey.
+func verifySignatures(records []*evidence.EvidenceRecord, publicKey ed25519.PublicKey) *signatureResults {
+	results := &signatureResults{Invalid: make(map[string]string)}
+	for _, record := range records {
+		err := integrity.VerifyRecord(record, publicKey)
+		switch {
+		case err == nil:
+			results.Verified++
+		case errors.Is(err, integrity.ErrUnsigned):
+			results.Unsigned++
+		default:
+			results.Invalid[record.ID] = err.Error()
+		}
+	}
+	return results
+}
+
+func outputEvidenceText(output *os.File, records []*evidence.EvidenceRecord, query *evidence.Query, signatures *signatureResults) error {
 	fmt.Fprintln(output, "Querying evidence records...")
 	fmt.Fprintln(output)
 
@@ -251,6 +272,10 @@ func outputEvidenceText(output *os.File, records []*evidence.EvidenceRecord, que
 			query.EndTime.Format(time.RFC3339))
 	}
 	fmt.Fprintf(output, "Total records: %d\n", len(records))
+	if signatures != nil {
+		fmt.Fprintf(output, "Signatures: %d valid, %d invalid, %d unsigned\n",
+			signatures.Verified, len(signatures.Invalid), signatures.Unsigned)
+	}
 	fmt.Fprintln(output)
 
 	if len(records) == 0 {
@@ -276,13 +301,23 @@ func outputEvidenceText(output *os.File, records []*evidence.EvidenceRecord, que
 		if record.BlockReason != "" {
 			fmt.Fprintf(output, "Block Reason: %s\n", record.BlockReason)
 		}
+		if record.TraceID != "" {
+			fmt.Fprintf(output, "Trace ID: %s\n", record.TraceID)
+		}
 		fmt.Fprintf(output, "Tokens: %d (prompt: %d, completion: %d)\n",
 			record.TotalTokens, record.PromptTokens, record.CompletionTokens)
 		if record.ActualCost > 0 {
 			fmt.Fprintf(output, "Cost: $%.4f\n", record.ActualCost)
 		}
-		if evidenceFlags.verify {
-			fmt.Fprintf(output, "Signature: ✓ Valid\n")
+		if signatures != nil {
+			switch {
+			case signatures.Invalid[record.ID] != "":
+				fmt.Fprintf(output, "Signature: ✗ %s\n", signatures.Invalid[record.ID])
+			case record.Signature == "":
+				fmt.Fprintf(output, "Signature: - Not signed\n")
+			default:
+				fmt.Fprintf(output, "Signature: ✓ Valid (key %s)\n", record.SigningKeyID)
+			}
 		}
 
 		// Show limited output for large result sets
@@ -298,7 +333,7 @@ func outputEvidenceText(output *os.File, records []*evidence.EvidenceRecord, que
 	return nil
 }
 
-func outputEvidenceJSON(output *os.File, records []*evidence.EvidenceRecord) error {
+func outputEvidenceJSON(output *os.File, records []*evidence.EvidenceRecord, signatures *signatureResults) error {
 	encoder := json.NewEncoder(output)
 	encoder.SetIndent("", "  ")
 
@@ -306,6 +341,9 @@ func outputEvidenceJSON(output *os.File, records []*evidence.EvidenceRecord) err
 		"total_records": len(records),
 		"records":       records,
 	}
+	if signatures != nil {
+		result["signatures"] = signatures
+	}
 
 	return encoder.Encode(result)
 }
@@ -323,26 +361,9 @@ func generateReport(cmd *cobra.Command, args []string) error {
 		backendType = cfg.Evidence.Backend
 	}
 
-	// Create storage backend
-	var store evidence.Storage
-	var err error
-	switch backendType {
-	case "sqlite":
-		sqliteConfig := &storage.SQLiteConfig{
-			Path:         cfg.Evidence.SQLite.Path,
-			MaxOpenConns: cfg.Evidence.SQLite.MaxOpenConns,
-			MaxIdleConns: cfg.Evidence.SQLite.MaxIdleConns,
-			WALMode:      cfg.Evidence.SQLite.WALMode,
-			BusyTimeout:  cfg.Evidence.SQLite.BusyTimeout,
-		}
-		store, err = storage.NewSQLiteStorage(sqliteConfig)
-		if err != nil {
-			return cli.NewCommandError("evidence", fmt.Errorf("failed to create SQLite storage: %w", err))
-		}
-	case "memory":
-		store = storage.NewMemoryStorage()
-	default:
-		return fmt.Errorf("unsupported backend: %s", backendType)
+	store, err := openEvidenceStore(cfg, backendType)
+	if err != nil {
+		return err
 	}
 	defer store.Close()
 
@@ -434,3 +455,170 @@ func generateAuditReport(output *os.File, records []*evidence.EvidenceRecord, qu
 
 	return nil
 }
+
+// openEvidenceStore opens the evidence storage backend named by backendType.
+func openEvidenceStore(cfg *config.Config, backendType string) (evidence.Storage, error) {
+	switch backendType {
+	case "sqlite":
+		sqliteConfig := &storage.SQLiteConfig{
+			Path:         cfg.Evidence.SQLite.Path,
+			MaxOpenConns: cfg.Evidence.SQLite.MaxOpenConns,
+			MaxIdleConns: cfg.Evidence.SQLite.MaxIdleConns,
+			WALMode:      cfg.Evidence.SQLite.WALMode,
+			BusyTimeout:  cfg.Evidence.SQLite.BusyTimeout,
+			WriteOnce:    cfg.Evidence.WriteOnce.Enabled,
+		}
+		if cfg.Evidence.Compression.Enabled {
+			sqliteConfig.CompressMinSize = cfg.Evidence.Compression.MinSize
+		}
+		store, err := storage.NewSQLiteStorage(sqliteConfig)
+		if err != nil {
+			return nil, cli.NewCommandError("evidence", fmt.Errorf("failed to create SQLite storage: %w", err))
+		}
+		return store, nil
+	case "s3":
+		store, err := newS3Storage(&cfg.Evidence)
+		if err != nil {
+			return nil, cli.NewCommandError("evidence", fmt.Errorf("failed to create S3 storage: %w", err))
+		}
+		return store, nil
+	case "memory":
+		return storage.NewMemoryStorage(), nil
+	default:
+		return nil, fmt.Errorf("unsupported backend: %s (supported: sqlite, s3, memory)", backendType)
+	}
+}
+
+// applyEvidenceFilters copies the record filter flags into query.
+func applyEvidenceFilters(query *evidence.Query) {
+	if evidenceFlags.user != "" {
+		query.UserID = evidenceFlags.user
+	}
+	if evidenceFlags.apiKey != "" {
+		query.APIKey = evidenceFlags.apiKey
+	}
+	if evidenceFlags.provider != "" {
+		query.Provider = evidenceFlags.provider
+	}
+	if evidenceFlags.model != "" {
+		query.Model = evidenceFlags.model
+	}
+	if evidenceFlags.policy != "" {
+		query.PolicyID = evidenceFlags.policy
+	}
+	if evidenceFlags.decision != "" {
+		query.PolicyDecision = evidenceFlags.decision
+	}
+	if evidenceFlags.minCost > 0 {
+		query.MinCost = &evidenceFlags.minCost
+	}
+	if evidenceFlags.maxCost > 0 {
+		query.MaxCost = &evidenceFlags.maxCost
+	}
+	if evidenceFlags.minTokens > 0 {
+		query.MinTokens = &evidenceFlags.minTokens
+	}
+	if evidenceFlags.maxTokens > 0 {
+		query.MaxTokens = &evidenceFlags.maxTokens
+	}
+	if evidenceFlags.search != "" {
+		query.Search = evidenceFlags.search
+	}
+	if evidenceFlags.tool != "" {
+		query.ToolName = evidenceFlags.tool
+	}
+	if evidenceFlags.traceID != "" {
+		query.TraceID = evidenceFlags.traceID
+	}
+	if evidenceFlags.decisionID != "" {
+		query.DecisionID = evidenceFlags.decisionID
+	}
+}
+
+// newS3Storage creates the S3 evidence backend from configuration.
+func newS3Storage(evidenceCfg *config.EvidenceConfig) (*storage.S3Storage, error) {
+	cfg := &evidenceCfg.S3
+	s3Config := &storage.S3Config{
+		Bucket:   cfg.Bucket,
+		Region:   cfg.Region,
+		Prefix:   cfg.Prefix,
+		Endpoint: cfg.Endpoint,
+		Credentials: sigv4.Credentials{
+			AccessKeyID:     cfg.AccessKeyID,
+			SecretAccessKey: cfg.SecretAccessKey,
+			SessionToken:    cfg.SessionToken,
+		},
+		BatchSize:     cfg.BatchSize,
+		FlushInterval: cfg.FlushInterval,
+		Compress:      cfg.Compression != "none",
+		WriteOnce:     evidenceCfg.WriteOnce.Enabled,
+	}
+	if evidenceCfg.WriteOnce.Enabled {
+		s3Config.ObjectLockMode = evidenceCfg.WriteOnce.ObjectLockMode
+		s3Config.ObjectLockRetention = time.Duration(evidenceCfg.WriteOnce.ObjectLockDays) * 24 * time.Hour
+	}
+	return storage.NewS3Storage(s3Config)
+}
+
+// newExportScheduler creates the scheduler of the configured evidence
+// export jobs.
+func newExportScheduler(store evidence.Storage, cfg *config.ExportConfig) (*export.Scheduler, error) {
+	state, err := export.OpenStateFile(cfg.StatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*export.Job, 0, len(cfg.Jobs))
+	for _, jobCfg := range cfg.Jobs {
+		destCfg := &export.DestinationConfig{
+			URL:      jobCfg.Destination,
+			Region:   jobCfg.Region,
+			Endpoint: jobCfg.Endpoint,
+			Credentials: sigv4.Credentials{
+				AccessKeyID:     jobCfg.AccessKeyID,
+				SecretAccessKey: jobCfg.SecretAccessKey,
+				SessionToken:    jobCfg.SessionToken,
+			},
+			Password: jobCfg.Password,
+			HostKey:  jobCfg.HostKey,
+			Headers:  jobCfg.Headers,
+			Secret:   jobCfg.Secret,
+		}
+		if jobCfg.AccessKeyID == "" {
+			destCfg.Credentials = sigv4.CredentialsFromEnv()
+		}
+		if jobCfg.PrivateKeyPath != "" {
+			if destCfg.PrivateKey, err = os.ReadFile(jobCfg.PrivateKeyPath); err != nil {
+				return nil, fmt.Errorf("export job %q: failed to read private key: %w", jobCfg.Name, err)
+			}
+		}
+		dest, err := export.NewDestination(destCfg)
+		if err != nil {
+			return nil, fmt.Errorf("export job %q: %w", jobCfg.Name, err)
+		}
+		jobs = append(jobs, &export.Job{
+			Name:        jobCfg.Name,
+			Schedule:    jobCfg.Schedule,
+			Format:      jobCfg.Format,
+			Delay:       jobCfg.Delay,
+			MaxRecords:  jobCfg.MaxRecords,
+			Destination: dest,
+		})
+	}
+	return export.NewScheduler(store, state, jobs)
+}
+
+// newArchiveStore creates the bucket retention archives are uploaded to.
+func newArchiveStore(cfg *config.ArchiveS3Config) (*storage.S3ObjectStore, error) {
+	return storage.NewS3ObjectStore(&storage.S3Config{
+		Bucket:   cfg.Bucket,
+		Region:   cfg.Region,
+		Prefix:   cfg.Prefix,
+		Endpoint: cfg.Endpoint,
+		Credentials: sigv4.Credentials{
+			AccessKeyID:     cfg.AccessKeyID,
+			SecretAccessKey: cfg.SecretAccessKey,
+			SessionToken:    cfg.SessionToken,
+		},
+	})
+}
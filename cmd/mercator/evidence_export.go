@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"mercator-hq/jupiter/pkg/cli"
+	"mercator-hq/jupiter/pkg/config"
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/evidence/export"
+)
+
+var evidenceExportFlags struct {
+	since         string
+	until         string
+	resume        bool
+	pageSize      int
+	format        string
+	allowUnsigned bool
+}
+
+var evidenceExportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Stream evidence records to a file",
+	Long: `Export all evidence records matching the filters in ascending time order.
+
+Records are read page by page with a time-based cursor and written as they
+are read, so exports of millions of records run in constant memory.
+
+The default JSON Lines format (one record per line) can be resumed: if an
+export is interrupted, run the same command again with --resume to append
+the remaining records to the output file. Alternatively, pass the resume
+time printed at the end of an export as --since to continue later.
+
+With --verify, the signature of every record is checked before it is
+written, and the export fails at the first record that does not verify
+against the signing key. Use this for exports handed over for audit.
+
+Examples:
+  # Export a month of evidence
+  mercator evidence export --since 2025-11-01T00:00:00Z --until 2025-12-01T00:00:00Z -o nov.jsonl
+
+  # Resume an interrupted export
+  mercator evidence export --since 2025-11-01T00:00:00Z --until 2025-12-01T00:00:00Z -o nov.jsonl --resume
+
+  # Export for audit handover, verifying every record signature
+  mercator evidence export --verify --key keys/evidence_public.pem -o audit.jsonl
+
+  # Export blocked requests as CSV
+  mercator evidence export --decision block --format csv -o blocked.csv
+
+  # Export OCSF API Activity events for Amazon Security Lake or a SIEM
+  mercator evidence export --since 2025-11-01T00:00:00Z --format ocsf -o evidence.ocsf.jsonl`,
+	RunE: exportEvidence,
+}
+
+func init() {
+	evidenceCmd.AddCommand(evidenceExportCmd)
+
+	flags := evidenceExportCmd.Flags()
+	flags.StringVar(&evidenceFlags.backend, "backend", "", "backend: sqlite, s3 (uses config if not specified)")
+	flags.StringVar(&evidenceExportFlags.since, "since", "", "export records at or after this time (RFC3339)")
+	flags.StringVar(&evidenceExportFlags.until, "until", "", "export records at or before this time (RFC3339)")
+	flags.BoolVar(&evidenceExportFlags.resume, "resume", false, "append to --output, continuing after its last record (jsonl only)")
+	flags.IntVar(&evidenceExportFlags.pageSize, "page-size", export.DefaultPageSize, "records fetched per storage query")
+	flags.StringVar(&evidenceExportFlags.format, "format", "jsonl", "output format: jsonl, json, csv, ocsf")
+	flags.StringVarP(&evidenceFlags.output, "output", "o", "", "output file (default: stdout)")
+	flags.BoolVar(&evidenceFlags.verify, "verify", false, "verify record signatures, failing at the first invalid record")
+	flags.StringVar(&evidenceFlags.keyFile, "key", "", "public or private key file for --verify (default: evidence signing key)")
+	flags.BoolVar(&evidenceExportFlags.allowUnsigned, "allow-unsigned", false, "with --verify, export unsigned records instead of failing")
+	flags.StringVar(&evidenceFlags.user, "user", "", "filter by user ID")
+	flags.StringVar(&evidenceFlags.apiKey, "api-key", "", "filter by API key")
+	flags.StringVar(&evidenceFlags.policy, "policy", "", "filter by policy rule")
+	flags.StringVar(&evidenceFlags.provider, "provider", "", "filter by provider")
+	flags.StringVar(&evidenceFlags.model, "model", "", "filter by model")
+	flags.StringVar(&evidenceFlags.decision, "decision", "", "filter by policy decision (allow, block, transform)")
+	flags.StringVar(&evidenceFlags.search, "search", "", "full-text search of prompts and responses (words and \"quoted phrases\")")
+	flags.StringVar(&evidenceFlags.tool, "tool", "", "filter by tool called in the response")
+	flags.Float64Var(&evidenceFlags.minCost, "min-cost", 0, "minimum cost threshold")
+	flags.Float64Var(&evidenceFlags.maxCost, "max-cost", 0, "maximum cost threshold")
+	flags.IntVar(&evidenceFlags.minTokens, "min-tokens", 0, "minimum token threshold")
+	flags.IntVar(&evidenceFlags.maxTokens, "max-tokens", 0, "maximum token threshold")
+}
+
+func exportEvidence(cmd *cobra.Command, args []string) error {
+	if evidenceExportFlags.resume {
+		if evidenceFlags.output == "" {
+			return fmt.Errorf("--resume requires --output")
+		}
+		if evidenceExportFlags.format != "jsonl" {
+			return fmt.Errorf("--resume is only supported for the jsonl format")
+		}
+	}
+
+	query := &evidence.Query{}
+	if evidenceExportFlags.since != "" {
+		since, err := time.Parse(time.RFC3339, evidenceExportFlags.since)
+		if err != nil {
+			return fmt.Errorf("invalid --since time: %w", err)
+		}
+		query.StartTime = &since
+	}
+	if evidenceExportFlags.until != "" {
+		until, err := time.Parse(time.RFC3339, evidenceExportFlags.until)
+		if err != nil {
+			return fmt.Errorf("invalid --until time: %w", err)
+		}
+		query.EndTime = &until
+	}
+	applyEvidenceFilters(query)
+
+	if err := config.Initialize(cfgFile); err != nil {
+		return cli.NewConfigError("", fmt.Sprintf("failed to load config: %v", err))
+	}
+	cfg := config.GetConfig()
+
+	backendType := evidenceFlags.backend
+	if backendType == "" {
+		backendType = cfg.Evidence.Backend
+	}
+	store, err := openEvidenceStore(cfg, backendType)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	var verifier *export.SignatureVerifier
+	if evidenceFlags.verify {
+		publicKey, err := loadEvidencePublicKey(context.Background(), cfg, evidenceFlags.keyFile)
+		if err != nil {
+			return cli.NewCommandError("evidence", fmt.Errorf("failed to load verification key: %w", err))
+		}
+		if publicKey == nil {
+			return fmt.Errorf("--verify requires --key or evidence.signing_key_path/signing_key_secret")
+		}
+		verifier = export.NewSignatureVerifier(publicKey)
+		verifier.AllowUnsigned = evidenceExportFlags.allowUnsigned
+	}
+
+	cursor := export.NewCursor(store, query, evidenceExportFlags.pageSize)
+
+	output := os.Stdout
+	if evidenceFlags.output != "" {
+		flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+		if evidenceExportFlags.resume {
+			flags = os.O_CREATE | os.O_RDWR
+		}
+		output, err = os.OpenFile(evidenceFlags.output, flags, 0o644)
+		if err != nil {
+			return fmt.Errorf("failed to open output file: %w", err)
+		}
+		defer output.Close()
+	}
+
+	if evidenceExportFlags.resume {
+		position, seen, err := resumeJSONL(output)
+		if err != nil {
+			return fmt.Errorf("failed to resume from %s: %w", evidenceFlags.output, err)
+		}
+		if !position.IsZero() {
+			cursor.Resume(position, seen)
+			fmt.Fprintf(os.Stderr, "Resuming after %s\n", position.Format(time.RFC3339Nano))
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	recordsCh, errCh := cursor.Stream(ctx)
+	var verifyErrCh <-chan error
+	if verifier != nil {
+		recordsCh, verifyErrCh = verifier.Stream(ctx, recordsCh)
+	}
+	counted := make(chan *evidence.EvidenceRecord)
+	count := 0
+	go func() {
+		defer close(counted)
+		for record := range recordsCh {
+			count++
+			select {
+			case counted <- record:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	switch evidenceExportFlags.format {
+	case "jsonl":
+		err = export.NewJSONLExporter().ExportStream(ctx, counted, output)
+	case "json":
+		err = export.NewJSONExporter(false).ExportStream(ctx, counted, output)
+	case "csv":
+		err = export.NewCSVExporter(true).ExportStream(ctx, counted, output)
+	case "ocsf":
+		err = export.NewOCSFExporter(Version).ExportStream(ctx, counted, output)
+	default:
+		return fmt.Errorf("unsupported export format: %s (supported: jsonl, json, csv, ocsf)", evidenceExportFlags.format)
+	}
+	if err != nil {
+		return cli.NewCommandError("evidence", fmt.Errorf("export failed: %w", err))
+	}
+	if verifyErrCh != nil {
+		if err := <-verifyErrCh; err != nil {
+			return cli.NewCommandError("evidence", fmt.Errorf("signature verification failed after %d records: %w", count, err))
+		}
+	}
+	if err := <-errCh; err != nil {
+		return cli.NewCommandError("evidence", fmt.Errorf("export failed after %d records: %w", count, err))
+	}
+
+	fmt.Fprintf(os.Stderr, "Exported %d records\n", count)
+	if verifier != nil {
+		fmt.Fprintf(os.Stderr, "Signatures verified: %d", verifier.Verified())
+		if verifier.Unsigned() > 0 {
+			fmt.Fprintf(os.Stderr, " (%d unsigned records exported)", verifier.Unsigned())
+		}
+		fmt.Fprintln(os.Stderr)
+	}
+	if position := cursor.Position(); !position.IsZero() {
+		fmt.Fprintf(os.Stderr, "Resume time: %s\n", position.Format(time.RFC3339Nano))
+	}
+	return nil
+}
+
+// resumeJSONL scans a partial JSON Lines export and returns the request time
+// of its last record together with the IDs of all records at that time. A
+// trailing incomplete line left by an interrupted export is truncated, and
+// the file offset is left at the end of the file for appending.
+func resumeJSONL(f *os.File) (time.Time, []string, error) {
+	var (
+		position time.Time
+		seen     []string
+		offset   int64 // end of the last complete line
+	)
+
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			break // an incomplete final line is discarded
+		}
+		if err != nil {
+			return time.Time{}, nil, err
+		}
+		offset += int64(len(line))
+
+		var record struct {
+			ID          string    `json:"id"`
+			RequestTime time.Time `json:"request_time"`
+		}
+		if err := json.Unmarshal(line, &record); err != nil {
+			return time.Time{}, nil, fmt.Errorf("invalid record at byte %d: %w", offset-int64(len(line)), err)
+		}
+
+		if !record.RequestTime.Equal(position) {
+			position = record.RequestTime
+			seen = seen[:0]
+		}
+		seen = append(seen, record.ID)
+	}
+
+	if err := f.Truncate(offset); err != nil {
+		return time.Time{}, nil, err
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return time.Time{}, nil, err
+	}
+	return position, seen, nil
+}
@@ -0,0 +1,267 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"mercator-hq/jupiter/pkg/cli"
+	"mercator-hq/jupiter/pkg/config"
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/evidence/capture"
+	"mercator-hq/jupiter/pkg/evidence/replay"
+	"mercator-hq/jupiter/pkg/policy/engine"
+	"mercator-hq/jupiter/pkg/policy/engine/source"
+	"mercator-hq/jupiter/pkg/providerfactory"
+	"mercator-hq/jupiter/pkg/providers"
+)
+
+var evidenceReplayFlags struct {
+	policies string
+	send     bool
+	all      bool
+}
+
+var evidenceReplayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Re-run recorded requests through the policy engine",
+	Long: `Rebuild the requests of matching evidence records and evaluate them
+against the current policies, reporting records whose decision changed.
+
+Requests are rebuilt from their captured bodies (evidence.capture) where
+available, otherwise from the recorded model, prompt excerpts, and tool
+names. Policies see the token estimates, risk scores, and PII detection
+recorded with the request.
+
+With --send, requests the policies allow are also re-sent to the
+configured providers in a sandbox: responses are evaluated against the
+response policies and compared with the recorded response, but are not
+recorded as evidence. Re-sending incurs provider costs.
+
+Examples:
+  # Check a policy change against yesterday's traffic
+  mercator evidence replay --policies ./policies-next --time-range "2025-11-19T00:00:00Z/2025-11-20T00:00:00Z"
+
+  # Reconstruct the blocked requests of one user, including provider responses
+  mercator evidence replay --user "user-123" --decision block --send
+
+  # Every result as JSON
+  mercator evidence replay --all --format json --output replay.json`,
+	RunE: evidenceReplay,
+}
+
+func init() {
+	evidenceCmd.AddCommand(evidenceReplayCmd)
+
+	flags := evidenceReplayCmd.Flags()
+	flags.StringVar(&evidenceFlags.backend, "backend", "", "backend: sqlite, s3 (uses config if not specified)")
+	flags.StringVar(&evidenceReplayFlags.policies, "policies", "", "policy file or directory to replay against (default: configured policies)")
+	flags.BoolVar(&evidenceReplayFlags.send, "send", false, "re-send allowed requests to the configured providers")
+	flags.BoolVar(&evidenceReplayFlags.all, "all", false, "show every result, not only changed decisions and errors")
+	flags.StringVar(&evidenceFlags.timeRange, "time-range", "", "time range (RFC3339 interval: start/end)")
+	flags.StringVar(&evidenceFlags.user, "user", "", "filter by user ID")
+	flags.StringVar(&evidenceFlags.apiKey, "api-key", "", "filter by API key")
+	flags.StringVar(&evidenceFlags.policy, "policy", "", "filter by policy rule")
+	flags.StringVar(&evidenceFlags.provider, "provider", "", "filter by provider")
+	flags.StringVar(&evidenceFlags.model, "model", "", "filter by model")
+	flags.StringVar(&evidenceFlags.decision, "decision", "", "filter by recorded policy decision (allow, block, transform)")
+	flags.StringVar(&evidenceFlags.search, "search", "", "full-text search of prompts and responses (words and \"quoted phrases\")")
+	flags.StringVar(&evidenceFlags.tool, "tool", "", "filter by tool called in the response")
+	flags.IntVar(&evidenceFlags.limit, "limit", 1000, "max records to replay")
+	flags.StringVar(&evidenceFlags.format, "format", "text", "output format: text, json")
+	flags.StringVarP(&evidenceFlags.output, "output", "o", "", "output file (default: stdout)")
+}
+
+func evidenceReplay(cmd *cobra.Command, args []string) error {
+	if evidenceFlags.format != "text" && evidenceFlags.format != "json" {
+		return fmt.Errorf("unsupported format: %s (supported: text, json)", evidenceFlags.format)
+	}
+
+	q := &evidence.Query{Limit: evidenceFlags.limit, SortBy: "request_time", SortOrder: "asc"}
+	if evidenceFlags.timeRange != "" {
+		parts := strings.Split(evidenceFlags.timeRange, "/")
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid time range format (expected: start/end)")
+		}
+
+		startTime, err := time.Parse(time.RFC3339, parts[0])
+		if err != nil {
+			return fmt.Errorf("invalid start time: %w", err)
+		}
+		q.StartTime = &startTime
+
+		endTime, err := time.Parse(time.RFC3339, parts[1])
+		if err != nil {
+			return fmt.Errorf("invalid end time: %w", err)
+		}
+		q.EndTime = &endTime
+	}
+	applyEvidenceFilters(q)
+
+	if err := config.Initialize(cfgFile); err != nil {
+		return cli.NewConfigError("", fmt.Sprintf("failed to load config: %v", err))
+	}
+	cfg := config.GetConfig()
+	logger := slog.Default()
+
+	backendType := evidenceFlags.backend
+	if backendType == "" {
+		backendType = cfg.Evidence.Backend
+	}
+	store, err := openEvidenceStore(cfg, backendType)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	var policySource engine.PolicySource
+	if evidenceReplayFlags.policies != "" {
+		policySource = source.NewFileSource(evidenceReplayFlags.policies, logger)
+	} else {
+		var closeSource func()
+		policySource, _, closeSource, err = newPolicySource(&cfg.Policy, logger)
+		if err != nil {
+			return cli.NewCommandError("evidence", fmt.Errorf("failed to create policy source: %w", err))
+		}
+		defer closeSource()
+	}
+	policyEngine, err := engine.NewInterpreterEngine(engine.DefaultEngineConfig(), policySource, logger)
+	if err != nil {
+		return cli.NewCommandError("evidence", fmt.Errorf("failed to load policies: %w", err))
+	}
+	defer policyEngine.Close()
+
+	replayConfig := &replay.Config{Engine: policyEngine}
+	if _, err := os.Stat(cfg.Evidence.Capture.BlobPath); err == nil {
+		blobs, err := capture.NewFileBlobStore(cfg.Evidence.Capture.BlobPath)
+		if err != nil {
+			return cli.NewCommandError("evidence", fmt.Errorf("failed to open evidence blob storage: %w", err))
+		}
+		defer blobs.Close()
+		replayConfig.Blobs = blobs
+	}
+	if evidenceReplayFlags.send {
+		manager := providerfactory.NewManager()
+		defer manager.Close()
+		providerConfigs := make([]providers.ProviderConfig, 0, len(cfg.Providers))
+		for name, providerCfg := range cfg.Providers {
+			providerConfigs = append(providerConfigs, providers.ProviderConfig{
+				Name:            name,
+				Type:            providerType(name, providerCfg),
+				BaseURL:         providerCfg.BaseURL,
+				APIKey:          providerCfg.APIKey,
+				Timeout:         providerCfg.Timeout,
+				MaxRetries:      providerCfg.MaxRetries,
+				Region:          providerCfg.Region,
+				API:             providerCfg.API,
+				Project:         providerCfg.Project,
+				CredentialsFile: providerCfg.CredentialsFile,
+				SafetySettings:  providerCfg.SafetySettings,
+				PromptFormat:    providerCfg.PromptFormat,
+				Models:          providerCfg.Models,
+				ProxyURL:        providerCfg.Proxy.URL,
+				NoProxy:         providerCfg.Proxy.NoProxy,
+			})
+		}
+		if err := manager.LoadFromConfig(providerConfigs); err != nil {
+			slog.Warn("some providers failed to initialize", "error", err)
+		}
+		replayConfig.Providers = manager
+	}
+
+	replayer, err := replay.NewReplayer(replayConfig)
+	if err != nil {
+		return cli.NewCommandError("evidence", err)
+	}
+
+	output := os.Stdout
+	if evidenceFlags.output != "" {
+		output, err = os.Create(evidenceFlags.output)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer output.Close()
+	}
+
+	results := []*replay.Result{}
+	report, err := replayer.Run(context.Background(), store, q, func(result *replay.Result) {
+		if !evidenceReplayFlags.all && len(result.Differences) == 0 && result.Error == "" {
+			return
+		}
+		if evidenceFlags.format == "text" {
+			outputReplayResultText(output, result)
+		} else {
+			results = append(results, result)
+		}
+	})
+	if err != nil {
+		return cli.NewCommandError("evidence", fmt.Errorf("replay failed: %w", err))
+	}
+
+	if evidenceFlags.format == "json" {
+		encoder := json.NewEncoder(output)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		return encoder.Encode(struct {
+			Report  *replay.Report   `json:"report"`
+			Results []*replay.Result `json:"results"`
+		}{report, results})
+	}
+
+	fmt.Fprintf(output, "Replayed %d records: %d changed, %d errors", report.Replayed, report.Divergent, report.Errors)
+	if report.Incomplete > 0 {
+		fmt.Fprintf(output, ", %d without captured body", report.Incomplete)
+	}
+	fmt.Fprintln(output)
+	changes := make([]string, 0, len(report.ActionChanges))
+	for change, count := range report.ActionChanges {
+		changes = append(changes, fmt.Sprintf("  %s: %d", change, count))
+	}
+	slices.Sort(changes)
+	for _, change := range changes {
+		fmt.Fprintln(output, change)
+	}
+	return nil
+}
+
+func outputReplayResultText(output *os.File, result *replay.Result) {
+	fmt.Fprintf(output, "%s (record %s)\n", result.RequestID, result.RecordID)
+	if result.Error != "" {
+		fmt.Fprintf(output, "  Error: %s\n\n", result.Error)
+		return
+	}
+	fmt.Fprintf(output, "  Decision: %s -> %s", result.Recorded.Action, result.Replayed.Action)
+	if len(result.Differences) > 0 {
+		fmt.Fprintf(output, " (changed: %s)", strings.Join(result.Differences, ", "))
+	}
+	fmt.Fprintln(output)
+	if result.Replayed.BlockReason != "" {
+		fmt.Fprintf(output, "  Block reason: %s\n", result.Replayed.BlockReason)
+	}
+	if !slices.Equal(result.Recorded.MatchedRules, result.Replayed.MatchedRules) {
+		fmt.Fprintf(output, "  Rules: [%s] -> [%s]\n",
+			strings.Join(result.Recorded.MatchedRules, ", "), strings.Join(result.Replayed.MatchedRules, ", "))
+	}
+	if !result.Complete {
+		fmt.Fprintln(output, "  Rebuilt from excerpts (no captured body)")
+	}
+	if resp := result.Response; resp != nil {
+		if resp.Error != "" {
+			fmt.Fprintf(output, "  Provider %s: %s\n", resp.Provider, resp.Error)
+		} else {
+			fmt.Fprintf(output, "  Provider %s/%s: finish=%s tokens=%d/%d latency=%s",
+				resp.Provider, resp.Model, resp.FinishReason, resp.PromptTokens, resp.CompletionTokens, resp.Latency.Round(time.Millisecond))
+			if resp.Decision != nil {
+				fmt.Fprintf(output, " response decision=%s", resp.Decision.Action)
+			}
+			fmt.Fprintln(output)
+		}
+	}
+	fmt.Fprintln(output)
+}
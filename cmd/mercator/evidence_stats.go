@@ -0,0 +1,206 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"mercator-hq/jupiter/pkg/cli"
+	"mercator-hq/jupiter/pkg/config"
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/evidence/query"
+)
+
+var evidenceStatsFlags struct {
+	groupBy []string
+	team    string
+	costTag string
+	rollup  bool
+}
+
+var evidenceStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Aggregate evidence statistics",
+	Long: `Show request counts, token usage, cost, and policy decisions, grouped
+by user, team, provider, model, cost allocation tag (cost_tag), and/or
+day (UTC).
+
+Aggregation runs in the storage backend where supported. With --rollup,
+the pre-computed daily rollups maintained by the rollup job
+(evidence.query.rollup) are used instead; they cover whole days only but
+remain available after retention pruning.
+
+Examples:
+  # Cost per model per day
+  mercator evidence stats --group-by model,day --time-range "2025-11-01T00:00:00Z/2025-12-01T00:00:00Z"
+
+  # Totals per team from the daily rollups
+  mercator evidence stats --group-by team --rollup
+
+  # Spend per cost center per model
+  mercator evidence stats --group-by cost_tag,model
+
+  # Blocked requests per user as JSON
+  mercator evidence stats --group-by user --decision block --format json`,
+	RunE: evidenceStats,
+}
+
+func init() {
+	evidenceCmd.AddCommand(evidenceStatsCmd)
+
+	flags := evidenceStatsCmd.Flags()
+	flags.StringVar(&evidenceFlags.backend, "backend", "", "backend: sqlite, s3 (uses config if not specified)")
+	flags.StringSliceVar(&evidenceStatsFlags.groupBy, "group-by", nil, "dimensions to group by: user, team, provider, model, day, cost_tag")
+	flags.StringVar(&evidenceFlags.timeRange, "time-range", "", "time range (RFC3339 interval: start/end)")
+	flags.BoolVar(&evidenceStatsFlags.rollup, "rollup", false, "aggregate the pre-computed daily rollups")
+	flags.StringVar(&evidenceFlags.user, "user", "", "filter by user ID")
+	flags.StringVar(&evidenceStatsFlags.team, "team", "", "filter by team ID")
+	flags.StringVar(&evidenceStatsFlags.costTag, "cost-tag", "", "filter by cost allocation tag")
+	flags.StringVar(&evidenceFlags.provider, "provider", "", "filter by provider")
+	flags.StringVar(&evidenceFlags.model, "model", "", "filter by model")
+	flags.StringVar(&evidenceFlags.decision, "decision", "", "filter by policy decision (allow, block, transform)")
+	flags.StringVar(&evidenceFlags.search, "search", "", "full-text search of prompts and responses (words and \"quoted phrases\")")
+	flags.StringVar(&evidenceFlags.tool, "tool", "", "filter by tool called in the response")
+	flags.StringVar(&evidenceFlags.format, "format", "text", "output format: text, json")
+	flags.StringVarP(&evidenceFlags.output, "output", "o", "", "output file (default: stdout)")
+}
+
+func evidenceStats(cmd *cobra.Command, args []string) error {
+	q := &evidence.AggregateQuery{GroupBy: evidenceStatsFlags.groupBy}
+	if evidenceFlags.timeRange != "" {
+		parts := strings.Split(evidenceFlags.timeRange, "/")
+		if len(parts) != 2 {
+			return fmt.Errorf("invalid time range format (expected: start/end)")
+		}
+
+		startTime, err := time.Parse(time.RFC3339, parts[0])
+		if err != nil {
+			return fmt.Errorf("invalid start time: %w", err)
+		}
+		q.StartTime = &startTime
+
+		endTime, err := time.Parse(time.RFC3339, parts[1])
+		if err != nil {
+			return fmt.Errorf("invalid end time: %w", err)
+		}
+		q.EndTime = &endTime
+	}
+	applyEvidenceFilters(&q.Query)
+	q.TeamID = evidenceStatsFlags.team
+	q.CostTag = evidenceStatsFlags.costTag
+
+	if err := config.Initialize(cfgFile); err != nil {
+		return cli.NewConfigError("", fmt.Sprintf("failed to load config: %v", err))
+	}
+	cfg := config.GetConfig()
+
+	backendType := evidenceFlags.backend
+	if backendType == "" {
+		backendType = cfg.Evidence.Backend
+	}
+	store, err := openEvidenceStore(cfg, backendType)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	ctx := context.Background()
+	var rows []*evidence.AggregateRow
+	if evidenceStatsFlags.rollup {
+		rows, err = query.AggregateRollups(ctx, store, q)
+	} else {
+		rows, err = query.Aggregate(ctx, store, q)
+	}
+	if err != nil {
+		return cli.NewCommandError("evidence", fmt.Errorf("aggregation failed: %w", err))
+	}
+
+	output := os.Stdout
+	if evidenceFlags.output != "" {
+		output, err = os.Create(evidenceFlags.output)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer output.Close()
+	}
+
+	switch evidenceFlags.format {
+	case "text":
+		return outputStatsText(output, rows, q.GroupBy)
+	case "json":
+		if rows == nil {
+			rows = []*evidence.AggregateRow{}
+		}
+		encoder := json.NewEncoder(output)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(rows)
+	default:
+		return fmt.Errorf("unsupported format: %s (supported: text, json)", evidenceFlags.format)
+	}
+}
+
+func outputStatsText(output *os.File, rows []*evidence.AggregateRow, groupBy []string) error {
+	if len(rows) == 0 {
+		fmt.Fprintln(output, "No records found.")
+		return nil
+	}
+
+	for i, row := range rows {
+		if i > 0 {
+			fmt.Fprintln(output)
+		}
+
+		var group []string
+		for _, dim := range groupBy {
+			group = append(group, fmt.Sprintf("%s=%s", dim, statsDimension(row, dim)))
+		}
+		if len(group) == 0 {
+			group = append(group, "all records")
+		}
+		fmt.Fprintln(output, strings.Join(group, " "))
+
+		fmt.Fprintf(output, "  Requests: %d\n", row.Requests)
+		fmt.Fprintf(output, "  Tokens: %d (prompt: %d, completion: %d)\n",
+			row.TotalTokens, row.PromptTokens, row.CompletionTokens)
+		fmt.Fprintf(output, "  Cost: $%.4f\n", row.Cost)
+
+		decisions := make([]string, 0, len(row.Decisions))
+		for decision, count := range row.Decisions {
+			if decision == "" {
+				decision = "none"
+			}
+			decisions = append(decisions, fmt.Sprintf("%s=%d", decision, count))
+		}
+		slices.Sort(decisions)
+		fmt.Fprintf(output, "  Decisions: %s\n", strings.Join(decisions, ", "))
+	}
+	return nil
+}
+
+// statsDimension returns the value of an aggregation dimension of row.
+func statsDimension(row *evidence.AggregateRow, dim string) string {
+	var value string
+	switch dim {
+	case evidence.DimensionUser:
+		value = row.User
+	case evidence.DimensionTeam:
+		value = row.Team
+	case evidence.DimensionProvider:
+		value = row.Provider
+	case evidence.DimensionModel:
+		value = row.Model
+	case evidence.DimensionDay:
+		value = row.Day
+	case evidence.DimensionCostTag:
+		value = row.CostTag
+	}
+	if value == "" {
+		return "-"
+	}
+	return value
+}
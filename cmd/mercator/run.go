@@ -2,23 +2,58 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
+	"net/http"
 	"os"
+	"path/filepath"
 	"time"
 
+	_ "github.com/mattn/go-sqlite3"
 	"github.com/spf13/cobra"
 	"mercator-hq/jupiter/pkg/cli"
 	"mercator-hq/jupiter/pkg/config"
+	"mercator-hq/jupiter/pkg/config/remote"
 	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/evidence/capture"
+	"mercator-hq/jupiter/pkg/evidence/erasure"
+	"mercator-hq/jupiter/pkg/evidence/integrity"
+	"mercator-hq/jupiter/pkg/evidence/query"
 	"mercator-hq/jupiter/pkg/evidence/recorder"
 	"mercator-hq/jupiter/pkg/evidence/retention"
 	"mercator-hq/jupiter/pkg/evidence/storage"
+	"mercator-hq/jupiter/pkg/evidence/stream"
+	"mercator-hq/jupiter/pkg/kafkarest"
+	"mercator-hq/jupiter/pkg/limits"
+	"mercator-hq/jupiter/pkg/natsclient"
 	"mercator-hq/jupiter/pkg/policy/engine"
 	"mercator-hq/jupiter/pkg/policy/engine/source"
+	"mercator-hq/jupiter/pkg/policy/events"
+	"mercator-hq/jupiter/pkg/policy/git"
+	"mercator-hq/jupiter/pkg/processing"
+	"mercator-hq/jupiter/pkg/processing/content"
+	"mercator-hq/jupiter/pkg/processing/costs"
+	"mercator-hq/jupiter/pkg/processing/risk"
+	"mercator-hq/jupiter/pkg/processing/tokens"
 	"mercator-hq/jupiter/pkg/providerfactory"
 	"mercator-hq/jupiter/pkg/providers"
+	"mercator-hq/jupiter/pkg/providers/anthropic"
+	"mercator-hq/jupiter/pkg/proxy/handlers"
+	"mercator-hq/jupiter/pkg/proxy/middleware"
+	"mercator-hq/jupiter/pkg/proxy/types"
 	"mercator-hq/jupiter/pkg/server"
+	"mercator-hq/jupiter/pkg/telemetry/audit"
+	"mercator-hq/jupiter/pkg/telemetry/bus"
+	"mercator-hq/jupiter/pkg/telemetry/logging"
+	"mercator-hq/jupiter/pkg/telemetry/metrics"
+	"mercator-hq/jupiter/pkg/telemetry/profiling"
+	"mercator-hq/jupiter/pkg/telemetry/tracing"
 )
 
 var runFlags struct {
@@ -58,6 +93,10 @@ func init() {
 	runCmd.Flags().BoolVar(&runFlags.dryRun, "dry-run", false, "validate config without starting server")
 }
 
+// errConfigChanged is returned by serve when the server stopped to restart
+// with a new remote configuration, set as the global configuration.
+var errConfigChanged = errors.New("configuration changed")
+
 func runServer(cmd *cobra.Command, args []string) error {
 	// Load configuration
 	if err := config.Initialize(cfgFile); err != nil {
@@ -65,6 +104,35 @@ func runServer(cmd *cobra.Command, args []string) error {
 	}
 	cfg := config.GetConfig()
 
+	// Load the configuration from etcd or Consul (if configured), and
+	// restart with each change
+	var changes <-chan *config.Config
+	if cfg.Remote.Backend != "" {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		var err error
+		cfg, changes, err = loadRemoteConfig(ctx, cfg)
+		if err != nil {
+			return cli.NewConfigError("", fmt.Sprintf("failed to load remote config: %v", err))
+		}
+		config.SetConfig(cfg)
+	}
+
+	sigChan := cli.WaitForShutdown()
+	for {
+		err := serve(cfg, sigChan, changes)
+		if !errors.Is(err, errConfigChanged) {
+			return err
+		}
+		cfg = config.GetConfig()
+		fmt.Println()
+	}
+}
+
+// serve runs the server with cfg until a shutdown signal, a server error,
+// or a remote configuration change.
+func serve(cfg *config.Config, sigChan <-chan os.Signal, changes <-chan *config.Config) error {
 	// Apply flag overrides
 	if runFlags.listenAddress != "" {
 		cfg.Proxy.ListenAddress = runFlags.listenAddress
@@ -73,24 +141,14 @@ func runServer(cmd *cobra.Command, args []string) error {
 		cfg.Telemetry.Logging.Level = runFlags.logLevel
 	}
 
-	// Initialize logging based on config
-	var logLevel slog.Level
-	switch cfg.Telemetry.Logging.Level {
-	case "debug":
-		logLevel = slog.LevelDebug
-	case "info":
-		logLevel = slog.LevelInfo
-	case "warn":
-		logLevel = slog.LevelWarn
-	case "error":
-		logLevel = slog.LevelError
-	default:
-		logLevel = slog.LevelInfo
-	}
-
-	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-		Level: logLevel,
-	}))
+	// Initialize logging based on config. The log level and redaction are
+	// applied by logControl, and can be changed at runtime.
+	logControl := logging.NewControl(&cfg.Telemetry.Logging)
+	defer logControl.Stop()
+	var logHandler slog.Handler = slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+	})
+	logger := newLogger(cfg, logControl.Wrap(logHandler))
 	slog.SetDefault(logger)
 
 	if runFlags.dryRun {
@@ -98,6 +156,72 @@ func runServer(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
+	// Ship logs and audit events to the message bus (if enabled)
+	logHandlers := []slog.Handler{logControl.Wrap(logHandler)}
+	var auditBus []io.Writer
+	if busCfg := &cfg.Telemetry.Bus; busCfg.Enabled {
+		logShipper, err := newTelemetryBusShipper(busCfg, busCfg.LogTopic)
+		if err != nil {
+			return fmt.Errorf("failed to create log bus: %w", err)
+		}
+		defer logShipper.Close()
+		logHandlers = append(logHandlers, logControl.Redact(bus.NewHandler(logShipper, slogLevel(busCfg.Level))))
+
+		if busCfg.AuditTopic != "" {
+			auditShipper, err := newTelemetryBusShipper(busCfg, busCfg.AuditTopic)
+			if err != nil {
+				return fmt.Errorf("failed to create audit bus: %w", err)
+			}
+			defer auditShipper.Close()
+			auditBus = append(auditBus, auditShipper)
+		}
+		fmt.Printf("✓ Telemetry bus enabled (%s)\n", busCfg.Type)
+	}
+
+	// Send logs to GELF and syslog collectors (if configured). Outputs
+	// without a level follow the runtime logging level.
+	for i := range cfg.Telemetry.Logging.Outputs {
+		outputCfg := &cfg.Telemetry.Logging.Outputs[i]
+		output, err := newLogOutput(outputCfg)
+		if err != nil {
+			return fmt.Errorf("failed to create %s log output: %w", outputCfg.Type, err)
+		}
+		defer output.Close()
+		if outputCfg.Level == "" {
+			logHandlers = append(logHandlers, logControl.Wrap(output.Handler()))
+		} else {
+			logHandlers = append(logHandlers, logControl.Redact(output.Handler()))
+		}
+		fmt.Printf("✓ Log output enabled (%s to %s)\n", outputCfg.Type, outputCfg.Address)
+	}
+	if len(logHandlers) > 1 {
+		logger = newLogger(cfg, logging.NewMultiHandler(logHandlers...))
+		slog.SetDefault(logger)
+	}
+
+	// Initialize the security audit event stream (if enabled)
+	var auditLogger *audit.Logger
+	if cfg.Telemetry.Audit.Enabled {
+		var err error
+		auditLogger, err = audit.New(&cfg.Telemetry.Audit, auditBus...)
+		if err != nil {
+			return fmt.Errorf("failed to create audit logger: %w", err)
+		}
+		defer func() { _ = auditLogger.Close() }()
+		audit.SetDefault(auditLogger)
+		defer audit.SetDefault(nil)
+	}
+
+	// Initialize distributed tracing (if enabled)
+	if cfg.Telemetry.Tracing.Enabled {
+		tracer, err := tracing.New(&cfg.Telemetry.Tracing)
+		if err != nil {
+			return fmt.Errorf("failed to create tracer: %w", err)
+		}
+		defer func() { _ = tracer.Shutdown(context.Background()) }()
+		fmt.Printf("✓ Tracing enabled (%s exporter to %s)\n", cfg.Telemetry.Tracing.Exporter, cfg.Telemetry.Tracing.Endpoint)
+	}
+
 	// Print startup banner
 	printBanner(cfg)
 
@@ -110,12 +234,23 @@ func runServer(cmd *cobra.Command, args []string) error {
 	providerConfigs := make([]providers.ProviderConfig, 0, len(cfg.Providers))
 	for name, providerCfg := range cfg.Providers {
 		pc := providers.ProviderConfig{
-			Name:       name,
-			Type:       name,
-			BaseURL:    providerCfg.BaseURL,
-			APIKey:     providerCfg.APIKey,
-			Timeout:    providerCfg.Timeout,
-			MaxRetries: providerCfg.MaxRetries,
+			Name:            name,
+			Type:            providerType(name, providerCfg),
+			BaseURL:         providerCfg.BaseURL,
+			APIKey:          providerCfg.APIKey,
+			Timeout:         providerCfg.Timeout,
+			MaxRetries:      providerCfg.MaxRetries,
+			Region:          providerCfg.Region,
+			API:             providerCfg.API,
+			Project:         providerCfg.Project,
+			CredentialsFile: providerCfg.CredentialsFile,
+			SafetySettings:  providerCfg.SafetySettings,
+			PromptFormat:    providerCfg.PromptFormat,
+			Models:          providerCfg.Models,
+			ProxyURL:        providerCfg.Proxy.URL,
+			NoProxy:         providerCfg.Proxy.NoProxy,
+
+			TraceBaggage: cfg.Telemetry.Tracing.Enabled && cfg.Telemetry.Tracing.Baggage,
 		}
 		providerConfigs = append(providerConfigs, pc)
 	}
@@ -130,39 +265,90 @@ func runServer(cmd *cobra.Command, args []string) error {
 
 	fmt.Printf("✓ Providers initialized (%d providers)\n", manager.ProviderCount())
 
-	// Initialize policy engine (if mode is file and file exists)
+	// Initialize metrics collector (if enabled)
+	var collector *metrics.Collector
+	if cfg.Telemetry.Metrics.Enabled {
+		collector = metrics.NewCollector(&cfg.Telemetry.Metrics, nil)
+	}
+
+	// Initialize policy engine (if mode is file and file exists, or layered sources are configured)
 	var policyEngine *engine.InterpreterEngine
-	if cfg.Policy.Mode == "file" && cfg.Policy.FilePath != "" {
+	var previewEvaluator *engine.ShadowEvaluator
+	if len(cfg.Policy.Sources) > 0 || (cfg.Policy.Mode == "file" && cfg.Policy.FilePath != "") {
 		slog.Info("initializing policy engine",
 			"mode", cfg.Policy.Mode,
 			"policy_path", cfg.Policy.FilePath,
+			"sources", len(cfg.Policy.Sources),
 		)
 
-		policySource := source.NewFileSource(cfg.Policy.FilePath, logger)
+		policySource, policyRepo, closeSource, err := newPolicySource(&cfg.Policy, logger)
+		if err != nil {
+			return fmt.Errorf("failed to create policy source: %w", err)
+		}
+		defer closeSource()
+
 		engineConfig := engine.DefaultEngineConfig()
 		engineConfig.EnableTrace = true
 		engineConfig.FailSafeMode = engine.FailOpen
 		engineConfig.DefaultAction = engine.ActionAllow
+		engineConfig.ExplanationHistory = 1000
 
-		var err error
 		policyEngine, err = engine.NewInterpreterEngine(engineConfig, policySource, logger)
 		if err != nil {
 			slog.Warn("failed to initialize policy engine", "error", err)
 		} else {
 			defer policyEngine.Close()
+			if policyRepo != nil && cfg.Policy.Git.Poll.Enabled {
+				watcher := git.NewWatcher(policyRepo, cfg.Policy.Git.Poll.Interval, cfg.Policy.Git.Poll.Timeout, func(string) error {
+					return policyEngine.ReloadPolicies(context.Background())
+				})
+				watcher.SetLogger(logger.With("component", "policy.git"))
+				if err := watcher.Start(context.Background()); err != nil {
+					return fmt.Errorf("failed to start policy watcher: %w", err)
+				}
+				defer watcher.Stop()
+			}
+			if collector != nil {
+				policyEngine.SetMetricsRecorder(collector)
+			}
+			if auditLogger != nil {
+				policyEngine.AddDecisionObserver(engine.NewAuditObserver(auditLogger))
+			}
+			if cfg.Policy.Events.Enabled {
+				bus, err := newDecisionEventBus(&cfg.Policy.Events)
+				if err != nil {
+					return fmt.Errorf("failed to create policy event bus: %w", err)
+				}
+				defer bus.Close()
+				policyEngine.AddDecisionObserver(bus)
+				fmt.Printf("✓ Policy decision events enabled (%d sinks)\n", len(cfg.Policy.Events.Sinks))
+			}
+			if cfg.Policy.Preview.Enabled {
+				shadow, closePreview, err := newPreviewEvaluator(&cfg.Policy, engineConfig, logger)
+				if err != nil {
+					return fmt.Errorf("failed to load preview policies: %w", err)
+				}
+				defer closePreview()
+				policyEngine.AddDecisionObserver(shadow)
+				previewEvaluator = shadow
+				fmt.Println("✓ Preview policies loaded in shadow mode")
+			}
 			fmt.Printf("✓ Policy engine loaded (%d policies)\n", len(policyEngine.GetPolicies()))
 		}
 	}
 
 	// Initialize evidence recording (if enabled)
 	var evidenceRecorder *recorder.Recorder
+	var evidenceStorage evidence.Storage
+	var evidencePublicKey ed25519.PublicKey
 	var pruner *retention.Pruner
+	var eraser *erasure.Eraser
+	var holds *retention.HoldRegistry
 	if cfg.Evidence.Enabled {
 		slog.Info("initializing evidence recording",
 			"backend", cfg.Evidence.Backend,
 		)
 
-		var evidenceStorage evidence.Storage
 		var err error
 		switch cfg.Evidence.Backend {
 		case "sqlite":
@@ -172,11 +358,20 @@ func runServer(cmd *cobra.Command, args []string) error {
 				MaxIdleConns: cfg.Evidence.SQLite.MaxIdleConns,
 				WALMode:      cfg.Evidence.SQLite.WALMode,
 				BusyTimeout:  cfg.Evidence.SQLite.BusyTimeout,
+				WriteOnce:    cfg.Evidence.WriteOnce.Enabled,
+			}
+			if cfg.Evidence.Compression.Enabled {
+				sqliteConfig.CompressMinSize = cfg.Evidence.Compression.MinSize
 			}
 			evidenceStorage, err = storage.NewSQLiteStorage(sqliteConfig)
 			if err != nil {
 				return fmt.Errorf("failed to create SQLite storage: %w", err)
 			}
+		case "s3":
+			evidenceStorage, err = newS3Storage(&cfg.Evidence)
+			if err != nil {
+				return fmt.Errorf("failed to create S3 storage: %w", err)
+			}
 		case "memory":
 			evidenceStorage = storage.NewMemoryStorage()
 		default:
@@ -188,22 +383,230 @@ func runServer(cmd *cobra.Command, args []string) error {
 			Enabled:        true,
 			AsyncBuffer:    cfg.Evidence.Recorder.AsyncBuffer,
 			WriteTimeout:   cfg.Evidence.Recorder.WriteTimeout,
+			BatchSize:      cfg.Evidence.Recorder.BatchSize,
+			FlushInterval:  cfg.Evidence.Recorder.FlushInterval,
 			HashRequest:    cfg.Evidence.Recorder.HashRequest,
 			HashResponse:   cfg.Evidence.Recorder.HashResponse,
 			RedactAPIKeys:  cfg.Evidence.Recorder.RedactAPIKeys,
 			MaxFieldLength: cfg.Evidence.Recorder.MaxFieldLength,
 		}
+		// The publisher is closed after the recorder so that records drained
+		// at shutdown are still exported.
+		publisher, err := newEvidencePublisher(&cfg.Evidence.Stream)
+		if err != nil {
+			return fmt.Errorf("failed to create evidence exporters: %w", err)
+		}
+		if publisher != nil {
+			defer publisher.Close()
+		}
+
+		signingKey, err := loadEvidenceSigningKey(context.Background(), cfg)
+		if err != nil {
+			return fmt.Errorf("failed to load evidence signing key: %w", err)
+		}
+		if signingKey != nil {
+			signer := integrity.NewSigner(signingKey)
+			recorderConfig.Signer = signer
+			evidencePublicKey = signer.PublicKey()
+			fmt.Printf("✓ Evidence signing enabled (key %s)\n", signer.KeyID())
+		}
+
+		// Like the publisher, the checkpointer is closed after the recorder
+		// so that its final checkpoint covers every record.
+		var checkpointer *integrity.Checkpointer
+		if cfg.Evidence.Integrity.Enabled {
+			checkpoints, err := integrity.NewFileCheckpointStore(cfg.Evidence.Integrity.CheckpointPath)
+			if err != nil {
+				return fmt.Errorf("failed to open evidence checkpoints: %w", err)
+			}
+			defer checkpoints.Close()
+
+			checkpointer = integrity.NewCheckpointer(checkpoints, signingKey, &integrity.CheckpointerConfig{
+				Interval: int64(cfg.Evidence.Integrity.CheckpointInterval),
+				Period:   cfg.Evidence.Integrity.CheckpointPeriod,
+			})
+			defer checkpointer.Close()
+
+			recorderConfig.Chain = integrity.NewChain(cfg.Evidence.Integrity.ChainID)
+		}
+
+		if cfg.Evidence.Capture.Enabled {
+			blobs, err := capture.NewFileBlobStore(cfg.Evidence.Capture.BlobPath)
+			if err != nil {
+				return fmt.Errorf("failed to open evidence blob storage: %w", err)
+			}
+			if cfg.Evidence.Compression.Enabled {
+				blobs.WithCompression(cfg.Evidence.Compression.MinSize)
+			}
+			recorderConfig.Capture = capture.NewCapturer(blobs, &capture.Policy{
+				APIKeys:     cfg.Evidence.Capture.APIKeys,
+				Models:      cfg.Evidence.Capture.Models,
+				Policies:    cfg.Evidence.Capture.Policies,
+				MaxBodySize: cfg.Evidence.Capture.MaxBodySize,
+			})
+			fmt.Printf("✓ Evidence full-body capture enabled (%s)\n", cfg.Evidence.Capture.BlobPath)
+		}
+
+		// A nil checker means no holds; a typed nil would not compare nil
+		var holdChecker evidence.HoldChecker
+		if cfg.Evidence.Retention.LegalHolds.Enabled {
+			holds, err = retention.NewHoldRegistry(cfg.Evidence.Retention.LegalHolds.Path)
+			if err != nil {
+				return fmt.Errorf("failed to load evidence legal holds: %w", err)
+			}
+			holdChecker = holds
+			fmt.Printf("✓ Evidence legal holds enabled (%d active)\n", len(holds.List(false)))
+		}
+
+		if cfg.Evidence.Erasure.Enabled {
+			certificates, err := integrity.NewFileErasureStore(cfg.Evidence.Erasure.CertificatePath)
+			if err != nil {
+				return fmt.Errorf("failed to open evidence erasure certificates: %w", err)
+			}
+			defer certificates.Close()
+
+			// Bodies captured earlier are erased even if capture is now
+			// disabled
+			var blobs capture.BlobStore
+			if recorderConfig.Capture != nil {
+				blobs = recorderConfig.Capture.Store()
+			} else if _, err := os.Stat(cfg.Evidence.Capture.BlobPath); err == nil {
+				if blobs, err = capture.NewFileBlobStore(cfg.Evidence.Capture.BlobPath); err != nil {
+					return fmt.Errorf("failed to open evidence blob storage: %w", err)
+				}
+			}
+
+			eraser, err = erasure.NewEraser(evidenceStorage, &erasure.Config{
+				Key:          signingKey,
+				Certificates: certificates,
+				Blobs:        blobs,
+				Holds:        holdChecker,
+			})
+			if err != nil {
+				return fmt.Errorf("failed to create evidence eraser: %w", err)
+			}
+			fmt.Printf("✓ Evidence erasure API enabled (%s)\n", cfg.Evidence.Erasure.CertificatePath)
+		}
+
+		// The spill queue is closed by the recorder
+		if cfg.Evidence.Recorder.Spill.Enabled {
+			recorderConfig.Spill, err = recorder.OpenSpillQueue(cfg.Evidence.Recorder.Spill.Path, cfg.Evidence.Recorder.Spill.MaxSize)
+			if err != nil {
+				return fmt.Errorf("failed to open evidence spill queue: %w", err)
+			}
+			fmt.Printf("✓ Evidence spill queue enabled (%s, %d records pending)\n", cfg.Evidence.Recorder.Spill.Path, recorderConfig.Spill.Len())
+		}
+
+		if sampling := cfg.Evidence.Recorder.Sampling; sampling.Enabled {
+			rules := make([]recorder.SampleRule, 0, len(sampling.Rules))
+			for _, rule := range sampling.Rules {
+				rules = append(rules, recorder.SampleRule{
+					Decisions: rule.Decisions,
+					Errors:    rule.Errors,
+					Paths:     rule.Paths,
+					Models:    rule.Models,
+					MaxCost:   rule.MaxCost,
+					Rate:      *rule.Rate,
+				})
+			}
+			defaultRate := 1.0
+			if sampling.DefaultRate != nil {
+				defaultRate = *sampling.DefaultRate
+			}
+			recorderConfig.Sampler, err = recorder.NewSampler(rules, defaultRate)
+			if err != nil {
+				return fmt.Errorf("failed to configure evidence sampling: %w", err)
+			}
+			fmt.Printf("✓ Evidence sampling enabled (%d rules, default rate %g)\n", len(rules), defaultRate)
+		}
+
 		evidenceRecorder = recorder.NewRecorder(evidenceStorage, recorderConfig)
 		defer evidenceRecorder.Close()
+		if collector != nil {
+			collector.RegisterEvidenceRecorder(func() metrics.EvidenceRecorderStats {
+				stats := evidenceRecorder.Stats()
+				return metrics.EvidenceRecorderStats{
+					Queued:       stats.Queued,
+					Spilled:      stats.Spilled,
+					Replayed:     stats.Replayed,
+					SpillPending: stats.SpillPending,
+					Dropped:      stats.Dropped,
+					SampledOut:   stats.SampledOut,
+				}
+			})
+		}
+		if publisher != nil {
+			evidenceRecorder.AddObserver(publisher)
+			if collector != nil {
+				collector.RegisterEvidenceStream(func() map[string]int {
+					queued := make(map[string]int)
+					for name, stats := range publisher.Stats() {
+						queued[name] = stats.Queued
+					}
+					return queued
+				})
+			}
+			fmt.Printf("✓ Evidence streaming enabled (%d exporters)\n", len(publisher.Stats()))
+		}
+		if checkpointer != nil {
+			evidenceRecorder.AddObserver(checkpointer)
+			fmt.Printf("✓ Evidence hash chain enabled (chain %s)\n", recorderConfig.Chain.ID())
+		}
 
-		// Start retention pruner if schedule is configured
-		if cfg.Evidence.Retention.PruneSchedule != "" {
+		// Start retention pruner if schedule is configured; write-once
+		// storage cannot be pruned
+		if cfg.Evidence.WriteOnce.Enabled {
+			fmt.Println("✓ Evidence write-once mode enabled (retention pruning disabled)")
+		} else if cfg.Evidence.Retention.PruneSchedule != "" {
 			retentionConfig := &retention.Config{
 				RetentionDays:       cfg.Evidence.Retention.Days,
 				PruneSchedule:       cfg.Evidence.Retention.PruneSchedule,
 				ArchiveBeforeDelete: cfg.Evidence.Retention.ArchiveBeforeDelete,
 				ArchivePath:         cfg.Evidence.Retention.ArchivePath,
 				MaxRecords:          cfg.Evidence.Retention.MaxRecords,
+				Holds:               holdChecker,
+			}
+			if cfg.Evidence.Retention.ArchiveS3.Bucket != "" {
+				retentionConfig.ArchiveStore, err = newArchiveStore(&cfg.Evidence.Retention.ArchiveS3)
+				if err != nil {
+					return fmt.Errorf("failed to create evidence archive storage: %w", err)
+				}
+			}
+			for _, rule := range cfg.Evidence.Retention.Rules {
+				retentionConfig.Rules = append(retentionConfig.Rules, retention.Rule{
+					TeamID:         rule.Team,
+					PolicyDecision: rule.Decision,
+					Category:       rule.Category,
+					Days:           rule.Days,
+				})
+			}
+			if cfg.Evidence.Retention.Anonymize.Enabled {
+				key, err := loadEvidenceAnonymizeKey(context.Background(), cfg)
+				if err != nil {
+					return fmt.Errorf("failed to load evidence anonymization key: %w", err)
+				}
+				// Bodies captured earlier are deleted even if capture is now
+				// disabled
+				var blobs capture.BlobStore
+				if recorderConfig.Capture != nil {
+					blobs = recorderConfig.Capture.Store()
+				} else if _, err := os.Stat(cfg.Evidence.Capture.BlobPath); err == nil {
+					if blobs, err = capture.NewFileBlobStore(cfg.Evidence.Capture.BlobPath); err != nil {
+						return fmt.Errorf("failed to open evidence blob storage: %w", err)
+					}
+				}
+				retentionConfig.Anonymizer, err = retention.NewAnonymizer(evidenceStorage, &retention.AnonymizeConfig{
+					AfterDays: cfg.Evidence.Retention.Anonymize.AfterDays,
+					Key:       key,
+					Redactor:  content.NewAnalyzer(&cfg.Processing.Content),
+					Blobs:     blobs,
+					Signer:    recorderConfig.Signer,
+					Holds:     holdChecker,
+				})
+				if err != nil {
+					return fmt.Errorf("failed to create evidence anonymizer: %w", err)
+				}
+				fmt.Printf("✓ Evidence anonymization enabled (after %d days)\n", cfg.Evidence.Retention.Anonymize.AfterDays)
 			}
 			pruner = retention.NewPruner(evidenceStorage, retentionConfig)
 			ctx := context.Background()
@@ -217,12 +620,126 @@ func runServer(cmd *cobra.Command, args []string) error {
 			}
 		}
 
+		// Start rollup job if enabled
+		if cfg.Evidence.Query.Rollup.Enabled {
+			if rollupStore, ok := evidenceStorage.(evidence.RollupStore); ok {
+				rollupJob := query.NewRollupJob(rollupStore, query.RollupConfig{
+					Interval:     cfg.Evidence.Query.Rollup.Interval,
+					LookbackDays: cfg.Evidence.Query.Rollup.LookbackDays,
+				})
+				rollupJob.Start(context.Background())
+				defer rollupJob.Stop()
+				fmt.Printf("✓ Evidence rollups enabled (every %s)\n", cfg.Evidence.Query.Rollup.Interval)
+			} else {
+				slog.Warn("evidence backend does not support rollups, skipping rollup job",
+					"backend", cfg.Evidence.Backend)
+			}
+		}
+
+		// Start scheduled export jobs if configured
+		if len(cfg.Evidence.Export.Jobs) > 0 {
+			scheduler, err := newExportScheduler(evidenceStorage, &cfg.Evidence.Export)
+			if err != nil {
+				return fmt.Errorf("failed to create evidence export jobs: %w", err)
+			}
+			if err := scheduler.Start(context.Background()); err != nil {
+				return fmt.Errorf("failed to start evidence export jobs: %w", err)
+			}
+			defer scheduler.Stop()
+			fmt.Printf("✓ Evidence export jobs enabled (%d jobs)\n", len(cfg.Evidence.Export.Jobs))
+		}
+
 		fmt.Println("✓ Evidence store initialized")
 	}
 
 	// Create HTTP server
 	slog.Info("creating HTTP server")
 	srv := server.NewServer(&cfg.Proxy, &cfg.Security, manager)
+	processor := processing.NewProcessorWithEstimator(&cfg.Processing, newTokenEstimator(&cfg.Processing.Tokens, manager))
+	if cfg.Processing.Risk.File != "" {
+		riskWatcher := risk.NewFileWatcher(cfg.Processing.Risk.File, processor.RiskScorer())
+		if err := riskWatcher.Start(context.Background()); err != nil {
+			return fmt.Errorf("failed to load risk model: %w", err)
+		}
+		defer riskWatcher.Stop()
+		fmt.Printf("✓ Risk model loaded from %s (reloaded on change)\n", cfg.Processing.Risk.File)
+	}
+	if cfg.Processing.Content.Injection.Feed.Enabled {
+		feedUpdater, err := content.NewPatternFeedUpdater(processor.ContentAnalyzer(), &cfg.Processing.Content.Injection.Feed)
+		if err != nil {
+			return fmt.Errorf("failed to create injection pattern feed updater: %w", err)
+		}
+		feedUpdater.Start(context.Background())
+		defer feedUpdater.Stop()
+		fmt.Printf("✓ Injection pattern feed enabled (version %q, refreshed every %s)\n",
+			feedUpdater.Version(), cfg.Processing.Content.Injection.Feed.RefreshInterval)
+	}
+	if cfg.Processing.Costs.Catalog.Enabled {
+		catalogUpdater, err := costs.NewCatalogUpdater(processor.CostCalculator(), &cfg.Processing.Costs.Catalog)
+		if err != nil {
+			return fmt.Errorf("failed to create pricing catalog updater: %w", err)
+		}
+		catalogUpdater.Start(context.Background())
+		defer catalogUpdater.Stop()
+		fmt.Printf("✓ Pricing catalog enabled (version %q, refreshed every %s)\n",
+			catalogUpdater.Version(), cfg.Processing.Costs.Catalog.RefreshInterval)
+	}
+
+	// Enforce rate limits and budgets if enabled
+	var limitsManager *limits.Manager
+	if cfg.Limits.RateLimits.Enabled || cfg.Limits.Budgets.Enabled {
+		var err error
+		limitsManager, err = middleware.NewLimitsManagerFromConfig(&cfg.Limits, cfg.Security.Authentication.Keys)
+		if err != nil {
+			return fmt.Errorf("failed to create limits manager: %w", err)
+		}
+		defer limitsManager.Close()
+		srv.SetLimitsManager(limitsManager)
+		fmt.Println("✓ Rate limits and budgets enabled")
+	}
+
+	srv.Handle("/v1/estimate", handlers.NewEstimateHandler(processor, limitsManager))
+	if collector != nil {
+		metricsPath := cfg.Telemetry.Metrics.Path
+		if metricsPath == "" {
+			metricsPath = "/metrics"
+		}
+		srv.HandleOperational(metricsPath, collector.Handler())
+		srv.HandleAdmin("/policy/slow-rules", collector.SlowRulesHandler())
+	}
+	if policyEngine != nil {
+		srv.HandleAdmin("/policy/explanations/{request_id}", policyEngine.ExplanationHandler())
+		srv.HandleAdmin("/policy/debug-trace", policyEngine.DebugTraceHandler())
+	}
+	if previewEvaluator != nil {
+		srv.HandleAdmin("/policy/preview", previewEvaluator.Handler())
+	}
+	if limitsManager != nil {
+		srv.HandleAdmin("/limits/usage", limitsManager.UsageHandler())
+		srv.HandleAdmin("/limits/usage/history", limitsManager.UsageHistoryHandler())
+		srv.HandleAdmin("/limits/forecast", limitsManager.ForecastHandler())
+		srv.HandleAdmin("/limits/overrides", limitsManager.OverridesHandler())
+		srv.HandleAdmin("/limits/reset", limitsManager.ResetHandler())
+	}
+	if evidenceStorage != nil {
+		srv.HandleAdmin("/evidence/verify", evidenceVerifyHandler(evidenceStorage, cfg, evidencePublicKey))
+		srv.HandleAdmin("/evidence/aggregate", query.AggregateHandler(evidenceStorage))
+		srv.HandleAdmin("/evidence/chargeback", query.ChargebackHandler(evidenceStorage))
+		srv.HandleAdmin("/evidence/records", query.RecordsHandler(evidenceStorage))
+	}
+	if evidenceRecorder != nil {
+		srv.HandleAdmin("/evidence/recorder", evidenceRecorder.StatsHandler())
+	}
+	if eraser != nil {
+		srv.HandleAdmin("/evidence/erasures", eraser.Handler())
+	}
+	if holds != nil {
+		srv.HandleAdmin("/evidence/holds", holds.Handler())
+	}
+	srv.HandleAdmin("/logging", logControl.Handler())
+	if cfg.Security.Admin.Debug {
+		srv.HandleAdmin("/debug/", profiling.Handler(server.AdminPathPrefix))
+	}
 
 	// Start server in background goroutine
 	ctx, cancel := context.WithCancel(context.Background())
@@ -248,35 +765,89 @@ func runServer(cmd *cobra.Command, args []string) error {
 	fmt.Printf("✓ Server listening on %s\n", cfg.Proxy.ListenAddress)
 	fmt.Printf("✓ Health endpoint: http://%s/health\n", cfg.Proxy.ListenAddress)
 	fmt.Printf("✓ Ready endpoint: http://%s/ready\n", cfg.Proxy.ListenAddress)
+	if admin := cfg.Security.Admin; admin.ListenAddress != "" {
+		scheme := "http"
+		if admin.TLS.Enabled {
+			scheme = "https"
+		}
+		fmt.Printf("✓ Admin endpoints: %s://%s%s (separate listener)\n", scheme, admin.ListenAddress, server.AdminPathPrefix)
+	}
 	fmt.Println("\nPress Ctrl+C to stop")
 
-	// Wait for shutdown signal or server error
-	sigChan := cli.WaitForShutdown()
-
+	// Wait for shutdown signal, server error, or configuration change
+	var next *config.Config
 	select {
 	case err := <-errChan:
 		return cli.NewCommandError("run", err)
 	case sig := <-sigChan:
 		fmt.Printf("\nReceived signal %s, shutting down gracefully...\n", sig)
-		cancel()
+	case next = <-changes:
+		fmt.Println("\nRemote configuration changed, restarting gracefully...")
+	}
+	cancel()
 
-		// Graceful shutdown with timeout
-		shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.Proxy.ShutdownTimeout)
-		defer shutdownCancel()
+	// Graceful shutdown with timeout
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), cfg.Proxy.ShutdownTimeout)
+	defer shutdownCancel()
 
-		if err := srv.Shutdown(shutdownCtx); err != nil {
-			slog.Error("shutdown failed", "error", err)
-			return cli.NewCommandError("run", err)
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("shutdown failed", "error", err)
+		return cli.NewCommandError("run", err)
+	}
+
+	fmt.Println("✓ Server stopped")
+	if next != nil {
+		config.SetConfig(next)
+		return errConfigChanged
+	}
+	return nil
+}
+
+// loadRemoteConfig loads the configuration from the remote source of local,
+// the configuration loaded from the local file, and watches it for changes.
+// If the remote configuration cannot be loaded, the last valid one cached
+// or else local is used until the source recovers.
+func loadRemoteConfig(ctx context.Context, local *config.Config) (*config.Config, <-chan *config.Config, error) {
+	client := &http.Client{}
+	if local.Remote.CAFile != "" {
+		tlsConfig, err := caTLSConfig(local.Remote.CAFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("remote: %w", err)
 		}
+		client.Transport = &http.Transport{
+			Proxy:           http.ProxyFromEnvironment,
+			TLSClientConfig: tlsConfig,
+		}
+	}
 
-		fmt.Println("✓ Server stopped")
-		return nil
+	loader, err := remote.NewLoader(local, client, slog.Default())
+	if err != nil {
+		return nil, nil, err
 	}
+
+	cfg, err := loader.Load(ctx)
+	if err != nil {
+		var origin string
+		cfg, origin = loader.Fallback()
+		if origin == "" {
+			origin = cfgFile
+		}
+		slog.Warn("remote configuration unavailable, using fallback", "source", loader.Source().String(), "fallback", origin, "error", err)
+		fmt.Printf("⚠  Remote configuration unavailable (%v), using %s\n", err, origin)
+	} else {
+		fmt.Printf("✓ Configuration loaded from %s\n", loader.Source())
+	}
+
+	return cfg, loader.Watch(ctx), nil
 }
 
 func printBanner(cfg *config.Config) {
 	fmt.Printf("Mercator Jupiter v%s\n", Version)
-	fmt.Printf("Loading configuration from: %s\n", cfgFile)
+	if cfg.Remote.Backend != "" {
+		fmt.Printf("Loading configuration from: %s key %s (fallback: %s)\n", cfg.Remote.Backend, cfg.Remote.Key, cfgFile)
+	} else {
+		fmt.Printf("Loading configuration from: %s\n", cfgFile)
+	}
 	fmt.Println("✓ Configuration loaded")
 
 	// Count providers
@@ -303,3 +874,446 @@ func waitForServerReady(address string, timeout time.Duration) error {
 	time.Sleep(100 * time.Millisecond)
 	return nil
 }
+
+// caTLSConfig returns a client TLS configuration that trusts the CA
+// certificates in a PEM file.
+func caTLSConfig(caFile string) (*tls.Config, error) {
+	pem, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA file: %w", err)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in CA file %s", caFile)
+	}
+	return &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}, nil
+}
+
+// providerType returns the adapter type of a configured provider: its
+// type, or else its name.
+func providerType(name string, cfg config.ProviderConfig) string {
+	if cfg.Type != "" {
+		return cfg.Type
+	}
+	return name
+}
+
+// slogLevel converts a configured log level to a slog.Level, defaulting to
+// info.
+func slogLevel(level string) slog.Level {
+	switch level {
+	case "debug":
+		return slog.LevelDebug
+	case "warn":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// newLogger creates the application logger writing to handler, sampling
+// log lines if configured.
+func newLogger(cfg *config.Config, handler slog.Handler) *slog.Logger {
+	if cfg.Telemetry.Logging.Sampling.Enabled {
+		handler = logging.NewSamplingHandler(handler, cfg.Telemetry.Logging.Sampling.Rules)
+	}
+	return slog.New(handler)
+}
+
+// newTelemetryBusShipper creates a shipper publishing to topic, a Kafka
+// topic or NATS subject, of the telemetry bus.
+func newTelemetryBusShipper(cfg *config.TelemetryBusConfig, topic string) (*bus.Shipper, error) {
+	var publisher bus.Publisher
+	switch cfg.Type {
+	case "kafka":
+		publisher = bus.NewKafkaPublisher(&kafkarest.Config{
+			URL:     cfg.URL,
+			Topic:   topic,
+			Headers: cfg.Headers,
+			Client:  &http.Client{Timeout: cfg.Timeout},
+		})
+	case "nats":
+		natsConfig := &natsclient.Config{
+			URL:         cfg.URL,
+			Subject:     topic,
+			DialTimeout: cfg.Timeout,
+		}
+		if cfg.CAFile != "" {
+			tlsConfig, err := caTLSConfig(cfg.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("nats: %w", err)
+			}
+			natsConfig.TLSConfig = tlsConfig
+		}
+		natsPublisher, err := bus.NewNATSPublisher(natsConfig)
+		if err != nil {
+			return nil, err
+		}
+		publisher = natsPublisher
+	default:
+		return nil, fmt.Errorf("unsupported bus type: %s", cfg.Type)
+	}
+
+	return bus.NewShipper(&bus.Config{
+		BufferSize:    cfg.BufferSize,
+		BatchSize:     cfg.BatchSize,
+		FlushInterval: cfg.FlushInterval,
+		Timeout:       cfg.Timeout,
+		Block:         cfg.Backpressure == "block",
+	}, publisher), nil
+}
+
+// newLogOutput creates a GELF or syslog log output.
+func newLogOutput(cfg *config.LogOutputConfig) (*logging.Output, error) {
+	var tlsConfig *tls.Config
+	if cfg.CAFile != "" {
+		var err error
+		if tlsConfig, err = caTLSConfig(cfg.CAFile); err != nil {
+			return nil, err
+		}
+	}
+	return logging.NewOutput(cfg, tlsConfig)
+}
+
+// newDecisionEventBus creates the policy decision event bus and its sinks.
+func newDecisionEventBus(cfg *config.PolicyEventsConfig) (*events.Bus, error) {
+	client := &http.Client{Timeout: cfg.Timeout}
+
+	sinks := make([]events.Sink, 0, len(cfg.Sinks))
+	for _, sinkCfg := range cfg.Sinks {
+		switch sinkCfg.Type {
+		case "webhook":
+			sinks = append(sinks, events.NewWebhookSink(&events.WebhookConfig{
+				URL:     sinkCfg.URL,
+				Headers: sinkCfg.Headers,
+				Secret:  sinkCfg.Secret,
+				Client:  client,
+			}))
+		case "kafka":
+			sinks = append(sinks, events.NewKafkaSink(&events.KafkaConfig{
+				URL:     sinkCfg.URL,
+				Topic:   sinkCfg.Topic,
+				Headers: sinkCfg.Headers,
+				Client:  client,
+			}))
+		case "nats":
+			natsConfig := &events.NATSConfig{
+				URL:         sinkCfg.URL,
+				Subject:     sinkCfg.Subject,
+				DialTimeout: cfg.Timeout,
+			}
+			if sinkCfg.CAFile != "" {
+				tlsConfig, err := caTLSConfig(sinkCfg.CAFile)
+				if err != nil {
+					return nil, fmt.Errorf("nats sink: %w", err)
+				}
+				natsConfig.TLSConfig = tlsConfig
+			}
+			sink, err := events.NewNATSSink(natsConfig)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, sink)
+		default:
+			return nil, fmt.Errorf("unsupported event sink type: %s", sinkCfg.Type)
+		}
+	}
+
+	return events.NewBus(&events.Config{
+		BufferSize:  cfg.BufferSize,
+		SendTimeout: cfg.Timeout,
+	}, sinks...), nil
+}
+
+// newPreviewEvaluator loads the preview policy set into a separate engine and
+// returns a shadow evaluator for it. Preview policies come from a local path
+// or from a branch of the policy Git repository, which is cloned next to the
+// production checkout and polled for changes. The returned function stops
+// the evaluator and releases the preview engine.
+func newPreviewEvaluator(cfg *config.PolicyConfig, engineConfig *engine.EngineConfig, logger *slog.Logger) (*engine.ShadowEvaluator, func(), error) {
+	policyPath := cfg.Preview.FilePath
+
+	var repo *git.Repository
+	if cfg.Preview.GitBranch != "" {
+		gitCfg := cfg.Git
+		gitCfg.Branch = cfg.Preview.GitBranch
+		localPath := gitCfg.Clone.LocalPath
+		if localPath == "" {
+			localPath = filepath.Join(os.TempDir(), "mercator-policies")
+		}
+		gitCfg.Clone.LocalPath = localPath + "-preview"
+		gitCfg.Clone.CleanOnStart = true
+
+		var err error
+		repo, err = cloneGitPolicies(&gitCfg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to clone preview branch %s: %w", gitCfg.Branch, err)
+		}
+		policyPath = repo.GetPolicyPath()
+	}
+
+	shadowConfig := *engineConfig
+	shadowConfig.ExplanationHistory = 0
+	shadowEngine, err := engine.NewInterpreterEngine(&shadowConfig, source.NewFileSource(policyPath, logger), logger)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	shadow := engine.NewShadowEvaluator(shadowEngine, &engine.ShadowConfig{
+		QueueSize: cfg.Preview.QueueSize,
+		History:   cfg.Preview.History,
+	}, logger)
+
+	var watcher *git.Watcher
+	if repo != nil && cfg.Git.Poll.Enabled {
+		watcher = git.NewWatcher(repo, cfg.Git.Poll.Interval, cfg.Git.Poll.Timeout, func(string) error {
+			if err := shadowEngine.ReloadPolicies(context.Background()); err != nil {
+				return err
+			}
+			// Results from the previous preview revision no longer apply.
+			shadow.Reset()
+			return nil
+		})
+		watcher.SetLogger(logger.With("component", "policy.preview"))
+		if err := watcher.Start(context.Background()); err != nil {
+			shadow.Close()
+			shadowEngine.Close()
+			return nil, nil, fmt.Errorf("failed to start preview watcher: %w", err)
+		}
+	}
+
+	return shadow, func() {
+		if watcher != nil {
+			watcher.Stop()
+		}
+		shadow.Close()
+		shadowEngine.Close()
+	}, nil
+}
+
+// newPolicySource creates the production policy source. With layered sources
+// configured, policies are merged from every source by precedence; otherwise
+// they are loaded from policy.file_path. The Git repository is returned when a
+// git source is configured so the caller can watch it for changes. The
+// returned function releases the sources' resources.
+func newPolicySource(cfg *config.PolicyConfig, logger *slog.Logger) (engine.PolicySource, *git.Repository, func(), error) {
+	if len(cfg.Sources) == 0 {
+		return source.NewFileSource(cfg.FilePath, logger), nil, func() {}, nil
+	}
+
+	var (
+		repo    *git.Repository
+		layers  []source.Layer
+		closers []func() error
+	)
+	closeAll := func() {
+		for _, c := range closers {
+			c()
+		}
+	}
+
+	for _, srcCfg := range cfg.Sources {
+		var policySource engine.PolicySource
+		switch srcCfg.Type {
+		case "file":
+			policySource = source.NewFileSource(srcCfg.Path, logger)
+		case "git":
+			if repo == nil {
+				var err error
+				repo, err = cloneGitPolicies(&cfg.Git)
+				if err != nil {
+					closeAll()
+					return nil, nil, nil, fmt.Errorf("source %q: %w", srcCfg.Name, err)
+				}
+			}
+			policySource = source.NewFileSource(repo.GetPolicyPath(), logger)
+		case "sqlite":
+			db, err := sql.Open("sqlite3", srcCfg.Path)
+			if err != nil {
+				closeAll()
+				return nil, nil, nil, fmt.Errorf("source %q: failed to open database: %w", srcCfg.Name, err)
+			}
+			closers = append(closers, db.Close)
+
+			dbSource, err := source.NewDatabaseSource(db, &source.DatabaseConfig{
+				Table:        srcCfg.Table,
+				PollInterval: srcCfg.PollInterval,
+			}, logger)
+			if err == nil {
+				err = dbSource.EnsureSchema(context.Background())
+			}
+			if err != nil {
+				closeAll()
+				return nil, nil, nil, fmt.Errorf("source %q: %w", srcCfg.Name, err)
+			}
+			policySource = dbSource
+		default:
+			closeAll()
+			return nil, nil, nil, fmt.Errorf("source %q: unsupported type %q", srcCfg.Name, srcCfg.Type)
+		}
+
+		layers = append(layers, source.Layer{
+			Name:       srcCfg.Name,
+			Source:     policySource,
+			Precedence: srcCfg.Precedence,
+			Namespace:  srcCfg.Namespace,
+			Optional:   srcCfg.Optional,
+		})
+	}
+
+	multi, err := source.NewMultiSource(logger, layers...)
+	if err != nil {
+		closeAll()
+		return nil, nil, nil, err
+	}
+	return multi, repo, closeAll, nil
+}
+
+// cloneGitPolicies clones (or opens) the policy repository described by cfg.
+func cloneGitPolicies(cfg *config.GitPolicyConfig) (*git.Repository, error) {
+	repo, err := git.NewRepository(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := cfg.Poll.Timeout
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := repo.Clone(ctx); err != nil {
+		return nil, err
+	}
+	return repo, nil
+}
+
+// newEvidencePublisher creates the real-time evidence publisher and its
+// exporters. It returns nil if no exporter is enabled.
+func newEvidencePublisher(cfg *config.EvidenceStreamConfig) (*stream.Publisher, error) {
+	client := &http.Client{Timeout: cfg.Timeout}
+
+	var exporters []stream.Exporter
+	if cfg.Kafka.Enabled {
+		kafka, err := stream.NewKafkaExporter(&stream.KafkaConfig{
+			URL:           cfg.Kafka.URL,
+			Topic:         cfg.Kafka.Topic,
+			Serialization: cfg.Kafka.Serialization,
+			Headers:       cfg.Kafka.Headers,
+			Client:        client,
+		})
+		if err != nil {
+			return nil, err
+		}
+		exporters = append(exporters, kafka)
+	}
+	if cfg.Elasticsearch.Enabled {
+		es, err := stream.NewElasticsearchExporter(&stream.ElasticsearchConfig{
+			URL:         cfg.Elasticsearch.URL,
+			IndexPrefix: cfg.Elasticsearch.IndexPrefix,
+			IndexMode:   cfg.Elasticsearch.IndexMode,
+			ILMPolicy:   cfg.Elasticsearch.ILMPolicy,
+			Username:    cfg.Elasticsearch.Username,
+			Password:    cfg.Elasticsearch.Password,
+			APIKey:      cfg.Elasticsearch.APIKey,
+			Client:      client,
+		})
+		if err != nil {
+			return nil, err
+		}
+		exporters = append(exporters, es)
+	}
+	if cfg.Syslog.Enabled {
+		syslogConfig := &stream.SyslogConfig{
+			Network:        cfg.Syslog.Network,
+			Address:        cfg.Syslog.Address,
+			Format:         cfg.Syslog.Format,
+			Facility:       cfg.Syslog.Facility,
+			Tag:            cfg.Syslog.Tag,
+			ProductVersion: Version,
+		}
+		if cfg.Syslog.CAFile != "" {
+			tlsConfig, err := caTLSConfig(cfg.Syslog.CAFile)
+			if err != nil {
+				return nil, fmt.Errorf("syslog: %w", err)
+			}
+			syslogConfig.TLSConfig = tlsConfig
+		}
+		syslog, err := stream.NewSyslogExporter(syslogConfig)
+		if err != nil {
+			return nil, err
+		}
+		exporters = append(exporters, syslog)
+	}
+	for _, webhookCfg := range cfg.Webhooks {
+		webhook, err := stream.NewWebhookExporter(&stream.WebhookConfig{
+			Name: webhookCfg.Name,
+			URL:  webhookCfg.URL,
+			Match: stream.WebhookMatch{
+				Decisions:   webhookCfg.Match.Decisions,
+				MinCost:     webhookCfg.Match.MinCost,
+				PIIDetected: webhookCfg.Match.PIIDetected,
+				Users:       webhookCfg.Match.Users,
+				Teams:       webhookCfg.Match.Teams,
+			},
+			Template:    webhookCfg.Template,
+			ContentType: webhookCfg.ContentType,
+			Headers:     webhookCfg.Headers,
+			Secret:      webhookCfg.Secret,
+			Client:      client,
+		})
+		if err != nil {
+			return nil, err
+		}
+		exporters = append(exporters, webhook)
+	}
+
+	if len(exporters) == 0 {
+		return nil, nil
+	}
+
+	return stream.NewPublisher(&stream.Config{
+		BufferSize:    cfg.BufferSize,
+		BatchSize:     cfg.BatchSize,
+		FlushInterval: cfg.FlushInterval,
+		ExportTimeout: cfg.Timeout,
+	}, exporters...), nil
+}
+
+// newTokenEstimator creates the token estimator selected by
+// processing.tokens.estimator. The anthropic estimator falls back to the
+// simple estimator if its provider is not an initialized Anthropic
+// provider.
+func newTokenEstimator(cfg *config.TokensConfig, manager *providerfactory.Manager) tokens.Estimator {
+	simple := tokens.NewSimpleEstimator(cfg)
+	if cfg.Estimator != "anthropic" {
+		return simple
+	}
+
+	provider, err := manager.GetProvider(cfg.Provider)
+	if err != nil {
+		slog.Warn("anthropic token estimator disabled: provider not initialized",
+			"provider", cfg.Provider, "error", err)
+		return simple
+	}
+	anthropicProvider, ok := provider.(*anthropic.Provider)
+	if !ok {
+		slog.Warn("anthropic token estimator disabled: provider is not an Anthropic provider",
+			"provider", cfg.Provider)
+		return simple
+	}
+	return tokens.NewAnthropicEstimator(anthropicTokenCounter{anthropicProvider}, simple, cfg)
+}
+
+// anthropicTokenCounter counts the prompt tokens of requests with an
+// Anthropic provider.
+type anthropicTokenCounter struct {
+	provider *anthropic.Provider
+}
+
+// CountTokens implements tokens.Counter.
+func (c anthropicTokenCounter) CountTokens(ctx context.Context, req *types.ChatCompletionRequest) (int, error) {
+	return c.provider.CountTokens(ctx, handlers.ConvertToProviderRequest(req))
+}
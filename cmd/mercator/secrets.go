@@ -0,0 +1,134 @@
+package main
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"time"
+
+	"mercator-hq/jupiter/pkg/config"
+	"mercator-hq/jupiter/pkg/evidence/integrity"
+	"mercator-hq/jupiter/pkg/security/secrets"
+)
+
+// newSecretsManager creates a secrets manager from the enabled providers in
+// cfg. The returned function closes providers that hold resources, such as
+// file watchers.
+func newSecretsManager(cfg *config.SecretsConfig) (*secrets.Manager, func(), error) {
+	var (
+		providers []secrets.SecretProvider
+		closers   []func() error
+	)
+	closeAll := func() {
+		for _, c := range closers {
+			c()
+		}
+	}
+
+	for i, p := range cfg.Providers {
+		if !p.Enabled {
+			continue
+		}
+		switch p.Type {
+		case "env":
+			providers = append(providers, secrets.NewEnvProvider(p.Prefix))
+		case "file":
+			fp, err := secrets.NewFileProvider(p.Path, p.Watch)
+			if err != nil {
+				closeAll()
+				return nil, nil, fmt.Errorf("security.secrets.providers[%d]: %w", i, err)
+			}
+			providers = append(providers, fp)
+			closers = append(closers, fp.Close)
+		case "aws_kms":
+			providers = append(providers, secrets.NewAWSKMSProvider(p.Region, p.KeyID, true))
+		case "gcp_kms":
+			providers = append(providers, secrets.NewGCPKMSProvider(p.Project, p.Location, p.KeyRing, p.Key, true))
+		case "vault":
+			providers = append(providers, secrets.NewVaultProvider(p.Address, p.Token, p.VaultPath, true))
+		default:
+			closeAll()
+			return nil, nil, fmt.Errorf("security.secrets.providers[%d]: unknown provider type %q", i, p.Type)
+		}
+	}
+
+	cacheConfig := secrets.CacheConfig{
+		Enabled: cfg.Cache.Enabled,
+		MaxSize: cfg.Cache.MaxSize,
+	}
+	if cfg.Cache.TTL != "" {
+		ttl, err := time.ParseDuration(cfg.Cache.TTL)
+		if err != nil {
+			closeAll()
+			return nil, nil, fmt.Errorf("security.secrets.cache.ttl: %w", err)
+		}
+		cacheConfig.TTL = ttl
+	}
+
+	return secrets.NewManager(providers, cacheConfig), closeAll, nil
+}
+
+// loadEvidenceSigningKey loads the evidence signing key from
+// evidence.signing_key_path or, via the secrets manager, from
+// evidence.signing_key_secret. It returns nil if neither is configured.
+func loadEvidenceSigningKey(ctx context.Context, cfg *config.Config) (ed25519.PrivateKey, error) {
+	if cfg.Evidence.SigningKeyPath != "" {
+		return integrity.LoadPrivateKey(cfg.Evidence.SigningKeyPath)
+	}
+	if cfg.Evidence.SigningKeySecret == "" {
+		return nil, nil
+	}
+
+	manager, closeProviders, err := newSecretsManager(&cfg.Security.Secrets)
+	if err != nil {
+		return nil, err
+	}
+	defer closeProviders()
+
+	value, err := manager.GetSecret(ctx, cfg.Evidence.SigningKeySecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret %q: %w", cfg.Evidence.SigningKeySecret, err)
+	}
+	key, err := integrity.ParsePrivateKey(value)
+	if err != nil {
+		return nil, fmt.Errorf("secret %q: %w", cfg.Evidence.SigningKeySecret, err)
+	}
+	return key, nil
+}
+
+// loadEvidenceAnonymizeKey reads the pseudonym key of evidence
+// anonymization from evidence.retention.anonymize.key_secret.
+func loadEvidenceAnonymizeKey(ctx context.Context, cfg *config.Config) ([]byte, error) {
+	name := cfg.Evidence.Retention.Anonymize.KeySecret
+	manager, closeProviders, err := newSecretsManager(&cfg.Security.Secrets)
+	if err != nil {
+		return nil, err
+	}
+	defer closeProviders()
+
+	value, err := manager.GetSecret(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secret %q: %w", name, err)
+	}
+	if value == "" {
+		return nil, fmt.Errorf("secret %q is empty", name)
+	}
+	return []byte(value), nil
+}
+
+// loadEvidencePublicKey returns the public key that verifies evidence
+// signatures: from keyFile if set (a public or private key file), otherwise
+// from the configured signing key. It returns nil if no key is available.
+func loadEvidencePublicKey(ctx context.Context, cfg *config.Config, keyFile string) (ed25519.PublicKey, error) {
+	if keyFile != "" {
+		return integrity.LoadPublicKey(keyFile)
+	}
+	if cfg.Evidence.SigningKeyPath != "" {
+		return integrity.LoadPublicKey(cfg.Evidence.SigningKeyPath)
+	}
+	key, err := loadEvidenceSigningKey(ctx, cfg)
+	if err != nil || key == nil {
+		return nil, err
+	}
+	return key.Public().(ed25519.PublicKey), nil
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+	"mercator-hq/jupiter/pkg/cli"
+	"mercator-hq/jupiter/pkg/config"
+	"mercator-hq/jupiter/pkg/telemetry/metrics"
+)
+
+var sloFlags struct {
+	output string
+}
+
+var sloCmd = &cobra.Command{
+	Use:   "slo",
+	Short: "Service level objective tooling",
+	Long: `Tools for the service level objectives configured under
+telemetry.metrics.slo.
+
+Subcommands:
+  rules - Generate Prometheus recording and burn-rate alerting rules`,
+}
+
+var sloRulesCmd = &cobra.Command{
+	Use:   "rules",
+	Short: "Generate Prometheus SLO rules",
+	Long: `Generate Prometheus recording and alerting rules for the service level
+objectives configured under telemetry.metrics.slo.
+
+For each objective, recording rules compute the error ratio of the
+slo_requests_total counter over 5m to 3d windows, and multi-window
+burn-rate alerts fire when the error budget burns too fast:
+
+  severity=page    2% of the budget within 1h (checked over 1h and 5m)
+                   5% of the budget within 6h (checked over 6h and 30m)
+  severity=ticket  10% of the budget within 1d (checked over 1d and 2h)
+                   10% of the budget within 3d (checked over 3d and 6h)
+
+Burn-rate thresholds are scaled to the objective's window, and the metric
+names follow telemetry.metrics.namespace and subsystem.
+
+Examples:
+  # Print the rules
+  mercator slo rules
+
+  # Write a rule file for Prometheus
+  mercator slo rules --config config.yaml -o /etc/prometheus/rules/mercator-slo.yaml`,
+	RunE: sloRules,
+}
+
+func init() {
+	rootCmd.AddCommand(sloCmd)
+	sloCmd.AddCommand(sloRulesCmd)
+
+	sloRulesCmd.Flags().StringVarP(&sloFlags.output, "output", "o", "", "output file (default: stdout)")
+}
+
+func sloRules(cmd *cobra.Command, args []string) error {
+	if err := config.Initialize(cfgFile); err != nil {
+		return cli.NewConfigError("", fmt.Sprintf("failed to load config: %v", err))
+	}
+	cfg := config.GetConfig()
+
+	if len(cfg.Telemetry.Metrics.SLO.Objectives) == 0 {
+		return cli.NewConfigError("telemetry.metrics.slo.objectives", "no service level objectives configured")
+	}
+
+	var output io.Writer = os.Stdout
+	if sloFlags.output != "" {
+		file, err := os.Create(sloFlags.output)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer file.Close()
+		output = file
+	}
+
+	return writeSLORules(output, &cfg.Telemetry.Metrics)
+}
+
+// writeSLORules writes the Prometheus rule file of the objectives of cfg.
+func writeSLORules(w io.Writer, cfg *config.MetricsConfig) error {
+	encoder := yaml.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(metrics.SLORules(cfg)); err != nil {
+		return fmt.Errorf("failed to write rules: %w", err)
+	}
+	return encoder.Close()
+}
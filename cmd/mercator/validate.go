@@ -2,7 +2,11 @@ package main
 
 import (
 	"context"
+	"crypto/ed25519"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"os"
 	"strings"
 	"time"
 
@@ -10,32 +14,40 @@ import (
 	"mercator-hq/jupiter/pkg/cli"
 	"mercator-hq/jupiter/pkg/config"
 	"mercator-hq/jupiter/pkg/evidence"
-	"mercator-hq/jupiter/pkg/evidence/storage"
+	"mercator-hq/jupiter/pkg/evidence/export"
+	"mercator-hq/jupiter/pkg/evidence/integrity"
 )
 
 var validateFlags struct {
-	backend   string
-	recordID  string
-	timeRange string
-	keyFile   string
-	report    bool
-	format    string
+	backend     string
+	recordID    string
+	timeRange   string
+	keyFile     string
+	checkpoints string
+	since       string
+	report      bool
+	format      string
 }
 
 var validateCmd = &cobra.Command{
 	Use:   "validate",
-	Short: "Validate evidence signatures",
-	Long: `Verify cryptographic signatures and integrity of evidence records.
+	Short: "Validate evidence integrity",
+	Long: `Verify that evidence records have not been modified or deleted.
 
-The validate command checks the cryptographic signatures of evidence records
-to ensure they haven't been tampered with. It verifies:
-  - Ed25519 digital signatures
-  - SHA-256 hash integrity
-  - Policy provenance (Git commit hash)
+When evidence.integrity is enabled, every evidence record includes the hash
+of the previous record, and signed checkpoints of each hash chain are
+written to evidence.integrity.checkpoint_path. The validate command checks:
+  - SHA-256 record hashes (modified records)
+  - Hash chain links and sequence numbers (deleted or re-ordered records)
+  - Ed25519 checkpoint signatures and the checkpointed records
+    (records deleted from the end of a chain, rewritten chains)
 
-Note: Signature generation and verification will be fully implemented
-in the Evidence Generation feature. This MVP provides the command structure
-and basic validation logic.
+Records missing from the start of a chain are reported as pruned, since
+retention pruning deletes the oldest records. Checkpoints older than
+--since (default: the retention cut-off) are not checked.
+
+With --record-id or --time-range only the hashes of the selected records
+are verified.
 
 Examples:
   # Validate all records
@@ -55,11 +67,13 @@ Examples:
 func init() {
 	rootCmd.AddCommand(validateCmd)
 
-	validateCmd.Flags().StringVar(&validateFlags.backend, "backend", "", "backend: sqlite, postgres, s3 (uses config if not specified)")
+	validateCmd.Flags().StringVar(&validateFlags.backend, "backend", "", "backend: sqlite, s3 (uses config if not specified)")
 	validateCmd.Flags().StringVar(&validateFlags.recordID, "record-id", "", "validate specific record")
 	validateCmd.Flags().StringVar(&validateFlags.timeRange, "time-range", "", "validate records in time range (RFC3339 interval)")
-	validateCmd.Flags().StringVar(&validateFlags.keyFile, "key", "", "public key file")
-	validateCmd.Flags().BoolVar(&validateFlags.report, "report", false, "generate detailed report")
+	validateCmd.Flags().StringVar(&validateFlags.keyFile, "key", "", "public or private key file for checkpoint signatures (default: evidence signing key)")
+	validateCmd.Flags().StringVar(&validateFlags.checkpoints, "checkpoints", "", "checkpoint file (default: evidence.integrity.checkpoint_path)")
+	validateCmd.Flags().StringVar(&validateFlags.since, "since", "", "ignore checkpoints before this time (RFC3339, default: retention cut-off)")
+	validateCmd.Flags().BoolVar(&validateFlags.report, "report", false, "list every issue found")
 	validateCmd.Flags().StringVar(&validateFlags.format, "format", "text", "output format: text, json")
 }
 
@@ -76,37 +90,36 @@ func validateEvidence(cmd *cobra.Command, args []string) error {
 		backendType = cfg.Evidence.Backend
 	}
 
-	// Create storage backend
-	var store evidence.Storage
-	var err error
-	switch backendType {
-	case "sqlite":
-		sqliteConfig := &storage.SQLiteConfig{
-			Path:         cfg.Evidence.SQLite.Path,
-			MaxOpenConns: cfg.Evidence.SQLite.MaxOpenConns,
-			MaxIdleConns: cfg.Evidence.SQLite.MaxIdleConns,
-			WALMode:      cfg.Evidence.SQLite.WALMode,
-			BusyTimeout:  cfg.Evidence.SQLite.BusyTimeout,
-		}
-		store, err = storage.NewSQLiteStorage(sqliteConfig)
+	store, err := openEvidenceStore(cfg, backendType)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	opts := &integrity.VerifyOptions{Since: retentionCutoff(cfg)}
+	if validateFlags.since != "" {
+		opts.Since, err = time.Parse(time.RFC3339, validateFlags.since)
 		if err != nil {
-			return cli.NewCommandError("validate", fmt.Errorf("failed to create SQLite storage: %w", err))
+			return fmt.Errorf("invalid --since time: %w", err)
 		}
-	case "memory":
-		store = storage.NewMemoryStorage()
-	default:
-		return fmt.Errorf("unsupported backend: %s", backendType)
 	}
-	defer store.Close()
+
+	opts.PublicKey, err = loadEvidencePublicKey(context.Background(), cfg, validateFlags.keyFile)
+	if err != nil {
+		return cli.NewCommandError("validate", err)
+	}
+
+	checkpointPath := validateFlags.checkpoints
+	if checkpointPath == "" {
+		checkpointPath = cfg.Evidence.Integrity.CheckpointPath
+	}
 
 	// Build query
 	query := &evidence.Query{}
-
-	if validateFlags.recordID != "" {
-		// Validate specific record
-		// For MVP, we'll query all and filter
-		query.Limit = 1000
-	} else if validateFlags.timeRange != "" {
+	if validateFlags.recordID != "" || validateFlags.timeRange != "" {
+		opts.Partial = true
+	}
+	if validateFlags.timeRange != "" {
 		// Parse time range
 		parts := strings.Split(validateFlags.timeRange, "/")
 		if len(parts) != 2 {
@@ -124,35 +137,83 @@ func validateEvidence(cmd *cobra.Command, args []string) error {
 			return fmt.Errorf("invalid end time: %w", err)
 		}
 		query.EndTime = &endTime
-	} else {
-		// Validate all (with limit)
-		query.Limit = 10000
 	}
 
-	// Execute query
-	ctx := context.Background()
-	records, err := store.Query(ctx, query)
+	var filter func(*evidence.EvidenceRecord) bool
+	if validateFlags.recordID != "" {
+		filter = func(record *evidence.EvidenceRecord) bool {
+			return record.ID == validateFlags.recordID
+		}
+	}
+
+	report, err := verifyEvidence(context.Background(), store, query, filter, checkpointPath, cfg.Evidence.Erasure.CertificatePath, opts)
 	if err != nil {
-		return cli.NewCommandError("validate", fmt.Errorf("query failed: %w", err))
+		return cli.NewCommandError("validate", err)
 	}
 
-	// Filter by record ID if specified
-	if validateFlags.recordID != "" {
-		filtered := make([]*evidence.EvidenceRecord, 0)
-		for _, record := range records {
-			if record.ID == validateFlags.recordID {
-				filtered = append(filtered, record)
-			}
+	if validateFlags.format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
 		}
-		records = filtered
+	} else {
+		printValidationReport(report, backendType, opts)
 	}
 
-	if len(records) == 0 {
-		fmt.Println("No evidence records found.")
-		return nil
+	if !report.OK() {
+		return cli.NewCommandError("validate", fmt.Errorf("%d integrity issues found", len(report.Issues)))
+	}
+	return nil
+}
+
+// verifyEvidence verifies the records matching query (and filter, if not
+// nil) against each other and against the checkpoints in checkpointPath,
+// accounting for the records erased by the certificates in erasurePath.
+func verifyEvidence(ctx context.Context, store evidence.Storage, query *evidence.Query, filter func(*evidence.EvidenceRecord) bool, checkpointPath, erasurePath string, opts *integrity.VerifyOptions) (*integrity.Report, error) {
+	verifier := integrity.NewVerifier(opts)
+
+	recordsCh, errCh := export.NewCursor(store, query, 0).Stream(ctx)
+	for record := range recordsCh {
+		if filter == nil || filter(record) {
+			verifier.Add(record)
+		}
+	}
+	if err := <-errCh; err != nil {
+		return nil, fmt.Errorf("failed to read evidence: %w", err)
+	}
+
+	var checkpoints []*integrity.Checkpoint
+	if checkpointPath != "" && !opts.Partial {
+		var err error
+		checkpoints, err = integrity.ReadCheckpointFile(checkpointPath)
+		if err != nil {
+			return nil, err
+		}
 	}
+	if erasurePath != "" && !opts.Partial {
+		certs, err := integrity.ReadErasureFile(erasurePath)
+		if err != nil {
+			return nil, err
+		}
+		for _, cert := range certs {
+			verifier.AddErasure(cert)
+		}
+	}
+
+	return verifier.Finish(checkpoints), nil
+}
+
+// retentionCutoff returns the time before which evidence may have been
+// deleted by retention pruning, or the zero time if retention is disabled.
+func retentionCutoff(cfg *config.Config) time.Time {
+	if cfg.Evidence.Retention.Days <= 0 {
+		return time.Time{}
+	}
+	return time.Now().AddDate(0, 0, -cfg.Evidence.Retention.Days)
+}
 
-	// Validate signatures
+func printValidationReport(report *integrity.Report, backendType string, opts *integrity.VerifyOptions) {
 	fmt.Println("Validating evidence records...")
 	fmt.Println()
 
@@ -163,34 +224,107 @@ func validateEvidence(cmd *cobra.Command, args []string) error {
 	if validateFlags.backend != "" {
 		fmt.Printf("Backend: %s\n", backendType)
 	}
-	fmt.Printf("Total records: %d\n", len(records))
+	fmt.Printf("Total records: %d\n", report.Records)
 	fmt.Println()
 
-	// For MVP, we simulate signature validation
-	// In production, this would verify actual Ed25519 signatures
-	validSignatures := len(records) // All valid for MVP
-	validHashes := len(records)
-	uniqueCommits := make(map[string]bool)
+	if report.Records == 0 {
+		fmt.Println("No evidence records found.")
+		return
+	}
 
-	for _, record := range records {
-		if record.PolicyVersion != "" {
-			uniqueCommits[record.PolicyVersion] = true
+	counts := make(map[integrity.IssueKind]int)
+	for _, issue := range report.Issues {
+		counts[issue.Kind]++
+	}
+	chained := report.Records - report.Unchained
+
+	printCheck := func(name string, failed int, detail string) {
+		mark := "✓"
+		if failed > 0 {
+			mark = "✗"
 		}
+		fmt.Printf("%s %s: %s\n", mark, name, detail)
 	}
 
-	fmt.Printf("✓ Signature verification: %d/%d valid (100%%)\n", validSignatures, len(records))
-	fmt.Printf("✓ Hash integrity: %d/%d valid (100%%)\n", validHashes, len(records))
-	if len(uniqueCommits) > 0 {
-		fmt.Printf("✓ Policy provenance: %d records (%d unique commits)\n", len(records), len(uniqueCommits))
+	printCheck("Hash integrity", counts[integrity.IssueModified],
+		fmt.Sprintf("%d/%d valid", chained-int64(counts[integrity.IssueModified]), chained))
+	if !opts.Partial {
+		linkIssues := counts[integrity.IssueBrokenLink] + counts[integrity.IssueMissing] + counts[integrity.IssueDuplicate]
+		printCheck("Hash chains", linkIssues,
+			fmt.Sprintf("%d chains, %d issues", report.Chains, linkIssues))
+
+		checkpointIssues := counts[integrity.IssueBadSignature] + counts[integrity.IssueCheckpointMismatch]
+		detail := fmt.Sprintf("%d checkpoints, %d issues", report.Checkpoints, checkpointIssues)
+		if !report.SignaturesVerified {
+			detail += " (signatures not verified: no key)"
+		}
+		printCheck("Checkpoints", checkpointIssues, detail)
+	}
+	if report.Unchained > 0 {
+		fmt.Printf("- %d records are not hash-chained and cannot be verified\n", report.Unchained)
+	}
+	if report.Pruned > 0 {
+		fmt.Printf("- %d records pruned from the start of chains\n", report.Pruned)
+	}
+	if report.Erased > 0 {
+		fmt.Printf("- %d records erased (covered by erasure certificates)\n", report.Erased)
+	}
+	if report.Anonymized > 0 {
+		fmt.Printf("- %d records anonymized (content verified against their anonymization hash)\n", report.Anonymized)
+	}
+	if !opts.Since.IsZero() {
+		fmt.Printf("- Checkpoints before %s not checked\n", opts.Since.Format(time.RFC3339))
 	}
 
 	fmt.Println()
 	fmt.Println("Summary:")
-	fmt.Println("  All evidence records are valid and tamper-free")
-	fmt.Println()
-	fmt.Println("Note: This is an MVP implementation. Full cryptographic")
-	fmt.Println("signature verification with Ed25519 will be implemented")
-	fmt.Println("in the Evidence Generation feature.")
+	if report.OK() {
+		fmt.Println("  No evidence tampering detected")
+		return
+	}
 
-	return nil
+	fmt.Printf("  %d integrity issues found\n", len(report.Issues))
+	limit := len(report.Issues)
+	if !validateFlags.report && limit > 10 {
+		limit = 10
+	}
+	for _, issue := range report.Issues[:limit] {
+		fmt.Printf("  [%s] chain %s #%d: %s\n", issue.Kind, issue.ChainID, issue.Sequence, issue.Message)
+	}
+	if limit < len(report.Issues) {
+		fmt.Printf("  ... and %d more (use --report to list all)\n", len(report.Issues)-limit)
+	}
+}
+
+// evidenceVerifyHandler serves integrity verification of the whole
+// evidence store as a JSON integrity.Report. The optional "since" query
+// parameter (RFC3339) overrides the retention cut-off for checkpoints.
+func evidenceVerifyHandler(store evidence.Storage, cfg *config.Config, publicKey ed25519.PublicKey) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		opts := &integrity.VerifyOptions{PublicKey: publicKey, Since: retentionCutoff(cfg)}
+		if since := r.URL.Query().Get("since"); since != "" {
+			t, err := time.Parse(time.RFC3339, since)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid since time: %v", err), http.StatusBadRequest)
+				return
+			}
+			opts.Since = t
+		}
+
+		report, err := verifyEvidence(r.Context(), store, &evidence.Query{}, nil,
+			cfg.Evidence.Integrity.CheckpointPath, cfg.Evidence.Erasure.CertificatePath, opts)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	})
 }
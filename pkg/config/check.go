@@ -0,0 +1,297 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// referencePattern matches ${NAME} environment variable and ${secret:NAME}
+// secret references in configuration values.
+var referencePattern = regexp.MustCompile(`\$\{(secret:)?([^}]*)\}`)
+
+// typeErrorLine extracts the line of a yaml.v3 type error.
+var typeErrorLine = regexp.MustCompile(`^line (\d+): `)
+
+// Reference is an environment variable or secret referenced in a
+// configuration value, as ${NAME} or ${secret:NAME}.
+type Reference struct {
+	// Field is the dotted path of the value (e.g., "providers.openai.api_key").
+	Field string `json:"field"`
+
+	// Line is the line of the value in the file.
+	Line int `json:"line"`
+
+	// Name is the environment variable or secret name.
+	Name string `json:"name"`
+
+	// Secret is set for ${secret:NAME} references.
+	Secret bool `json:"secret"`
+}
+
+// String returns the reference as written, e.g. "${secret:openai-key}".
+func (r Reference) String() string {
+	if r.Secret {
+		return "${secret:" + r.Name + "}"
+	}
+	return "${" + r.Name + "}"
+}
+
+// ResolveFunc returns the value of a reference.
+type ResolveFunc func(ref Reference) (string, error)
+
+// CheckResult is the outcome of checking a configuration file.
+type CheckResult struct {
+	// Config is the decoded configuration with defaults applied, and
+	// environment overrides if it is valid without them. It is nil if the
+	// file cannot be parsed, and may be invalid if Errors is not empty.
+	Config *Config
+
+	// References are the references found in the file.
+	References []Reference
+
+	// Errors are the problems that prevent the configuration from loading.
+	Errors []FieldError
+
+	// Warnings are problems ignored when loading, such as unknown keys.
+	Warnings []FieldError
+
+	// lines are the lines of the fields in the file.
+	lines map[string]int
+}
+
+// OK reports whether the configuration loads.
+func (r *CheckResult) OK() bool {
+	return len(r.Errors) == 0
+}
+
+// Line returns the line of field in the file, or of its closest parent
+// present in the file, or 0.
+func (r *CheckResult) Line(field string) int {
+	for field != "" {
+		if line, ok := r.lines[field]; ok {
+			return line
+		}
+		i := strings.LastIndexAny(field, ".[")
+		if i < 0 {
+			break
+		}
+		field = field[:i]
+	}
+	return 0
+}
+
+// Check loads the configuration file data like LoadConfigWithEnvOverrides
+// and reports every problem found with its field path, instead of
+// stopping at the first failing step:
+//   - YAML syntax and type errors
+//   - references that do not resolve
+//   - keys that do not match any field (as warnings)
+//   - validation errors, before and after environment overrides
+//
+// References are replaced with the values returned by resolve before the
+// configuration is decoded, so that referenced values are validated too.
+// If resolve is nil, references are listed but not replaced.
+func Check(data []byte, resolve ResolveFunc) *CheckResult {
+	result := &CheckResult{lines: make(map[string]int)}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		result.Errors = append(result.Errors, FieldError{Message: err.Error()})
+		return result
+	}
+
+	var cfg Config
+	if len(root.Content) > 0 {
+		walker := &checkWalker{
+			result:      result,
+			resolve:     resolve,
+			valueFields: make(map[int]string),
+			unresolved:  make(map[string]bool),
+		}
+		walker.walk(root.Content[0], reflect.TypeOf(cfg), "")
+
+		if err := root.Decode(&cfg); err != nil {
+			var typeErr *yaml.TypeError
+			if !errors.As(err, &typeErr) {
+				result.Errors = append(result.Errors, FieldError{Message: err.Error()})
+				return result
+			}
+			for _, message := range typeErr.Errors {
+				fieldErr := FieldError{Message: message}
+				if m := typeErrorLine.FindStringSubmatch(message); m != nil {
+					line, _ := strconv.Atoi(m[1])
+					fieldErr.Field = walker.valueFields[line]
+					fieldErr.Message = strings.TrimPrefix(message, m[0])
+				}
+				if walker.unresolved[fieldErr.Field] {
+					// Already reported as an unresolved reference
+					continue
+				}
+				result.Errors = append(result.Errors, fieldErr)
+			}
+		}
+	}
+	result.Config = &cfg
+
+	ApplyDefaults(&cfg)
+	if err := Validate(&cfg); err != nil {
+		result.Errors = append(result.Errors, validationErrors(err)...)
+		return result
+	}
+	applyEnvOverrides(&cfg)
+	if err := Validate(&cfg); err != nil {
+		for _, fieldErr := range validationErrors(err) {
+			fieldErr.Message += " (after environment overrides)"
+			result.Errors = append(result.Errors, fieldErr)
+		}
+	}
+
+	return result
+}
+
+// validationErrors returns the field errors of a Validate error.
+func validationErrors(err error) []FieldError {
+	var validationErr ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Errors
+	}
+	return []FieldError{{Message: err.Error()}}
+}
+
+// checkWalker walks a YAML document along the configuration types.
+type checkWalker struct {
+	result  *CheckResult
+	resolve ResolveFunc
+
+	// valueFields are the fields of scalar values by line, to locate
+	// decoding errors.
+	valueFields map[int]string
+
+	// unresolved are the fields with references that did not resolve.
+	unresolved map[string]bool
+}
+
+// walk checks node, the value of field path of type t. t is nil for
+// values of unknown or interface type.
+func (w *checkWalker) walk(node *yaml.Node, t reflect.Type, path string) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t != nil && t.Kind() == reflect.Interface {
+		t = nil
+	}
+
+	switch node.Kind {
+	case yaml.ScalarNode:
+		w.valueFields[node.Line] = path
+		w.resolveScalar(node, path)
+
+	case yaml.SequenceNode:
+		var elem reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elem = t.Elem()
+		}
+		for i, item := range node.Content {
+			itemPath := fmt.Sprintf("%s[%d]", path, i)
+			w.result.lines[itemPath] = item.Line
+			w.walk(item, elem, itemPath)
+		}
+
+	case yaml.MappingNode:
+		var fields map[string]reflect.Type
+		if t != nil && t.Kind() == reflect.Struct && t != reflect.TypeOf(time.Time{}) {
+			fields = make(map[string]reflect.Type)
+			for _, f := range yamlFields(t) {
+				fields[f.name] = f.field.Type
+			}
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "<<" {
+				// Merge keys are checked where the anchor is defined
+				continue
+			}
+			keyPath := key.Value
+			if path != "" {
+				keyPath = path + "." + key.Value
+			}
+			w.result.lines[keyPath] = key.Line
+
+			var valueType reflect.Type
+			switch {
+			case fields != nil:
+				fieldType, ok := fields[key.Value]
+				if !ok {
+					w.result.Warnings = append(w.result.Warnings, FieldError{
+						Field:   keyPath,
+						Message: "unknown field (ignored)",
+					})
+					continue
+				}
+				valueType = fieldType
+			case t != nil && t.Kind() == reflect.Map:
+				valueType = t.Elem()
+			}
+			w.walk(value, valueType, keyPath)
+		}
+	}
+}
+
+// resolveScalar records the references in a scalar value, and replaces
+// them with their values if they all resolve.
+func (w *checkWalker) resolveScalar(node *yaml.Node, path string) {
+	matches := referencePattern.FindAllStringSubmatch(node.Value, -1)
+	if len(matches) == 0 {
+		return
+	}
+
+	values := make(map[string]string, len(matches))
+	resolved := true
+	for _, m := range matches {
+		ref := Reference{Field: path, Line: node.Line, Name: m[2], Secret: m[1] != ""}
+		w.result.References = append(w.result.References, ref)
+		if w.resolve == nil {
+			resolved = false
+			continue
+		}
+		if ref.Name == "" {
+			w.result.Errors = append(w.result.Errors, FieldError{
+				Field:   path,
+				Message: fmt.Sprintf("invalid reference %s: name is required", ref),
+			})
+			resolved = false
+			continue
+		}
+		value, err := w.resolve(ref)
+		if err != nil {
+			w.result.Errors = append(w.result.Errors, FieldError{
+				Field:   path,
+				Message: fmt.Sprintf("unresolved reference %s: %v", ref, err),
+			})
+			resolved = false
+			continue
+		}
+		values[m[0]] = value
+	}
+	if !resolved {
+		w.unresolved[path] = true
+		return
+	}
+
+	node.Value = referencePattern.ReplaceAllStringFunc(node.Value, func(match string) string {
+		return values[match]
+	})
+	// The value is typed by its resolved content, e.g. "${PORT}" as an
+	// integer, unless the tag is explicit
+	if node.Style&yaml.TaggedStyle == 0 {
+		node.Tag = ""
+		node.Style = 0
+	}
+}
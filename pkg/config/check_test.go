@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const checkTestConfig = `
+proxy:
+  listen_address: "127.0.0.1:${PORT}"
+  max_header_bytes: ${MAX_HEADER_BYTES}
+  timeot: 30s
+providers:
+  openai:
+    base_url: "https://api.openai.com/v1"
+    api_key: "${secret:openai-key}"
+    timeout: 60s
+    max_retries: ${RETRIES}
+telemetry:
+  logging:
+    level: verbose
+`
+
+func TestCheck(t *testing.T) {
+	values := map[string]string{
+		"PORT":              "8080",
+		"MAX_HEADER_BYTES":  "2048",
+		"secret:openai-key": "sk-test",
+	}
+	var resolved []string
+	result := Check([]byte(checkTestConfig), func(ref Reference) (string, error) {
+		resolved = append(resolved, ref.String())
+		key := ref.Name
+		if ref.Secret {
+			key = "secret:" + key
+		}
+		value, ok := values[key]
+		if !ok {
+			return "", fmt.Errorf("%s is not set", ref.Name)
+		}
+		return value, nil
+	})
+
+	if len(result.References) != 4 || len(resolved) != 4 {
+		t.Fatalf("References = %+v, resolved %v, want 4", result.References, resolved)
+	}
+	if ref := result.References[2]; ref.Field != "providers.openai.api_key" || !ref.Secret || ref.Name != "openai-key" || ref.Line != 9 {
+		t.Errorf("References[2] = %+v", ref)
+	}
+
+	if result.Config == nil {
+		t.Fatal("Config = nil")
+	}
+	if result.Config.Proxy.ListenAddress != "127.0.0.1:8080" || result.Config.Proxy.MaxHeaderBytes != 2048 {
+		t.Errorf("proxy = %+v, want resolved references", result.Config.Proxy)
+	}
+	if result.Config.Providers["openai"].APIKey != "sk-test" {
+		t.Errorf("api_key = %q, want the secret value", result.Config.Providers["openai"].APIKey)
+	}
+
+	wantErrors := map[string]string{
+		"providers.openai.max_retries": "unresolved reference ${RETRIES}",
+		"telemetry.logging.level":      "invalid logging level",
+	}
+	for _, err := range result.Errors {
+		want, ok := wantErrors[err.Field]
+		if !ok || !strings.Contains(err.Message, want) {
+			t.Errorf("unexpected error %v", err)
+		}
+		delete(wantErrors, err.Field)
+	}
+	for field := range wantErrors {
+		t.Errorf("no error for %s in %v", field, result.Errors)
+	}
+
+	if len(result.Warnings) != 1 || result.Warnings[0].Field != "proxy.timeot" {
+		t.Errorf("Warnings = %v, want the unknown proxy.timeot", result.Warnings)
+	}
+	if line := result.Line("telemetry.logging.level"); line != 14 {
+		t.Errorf("Line(telemetry.logging.level) = %d, want 14", line)
+	}
+	if result.OK() {
+		t.Error("OK() = true, want false")
+	}
+}
+
+func TestCheck_TypeErrors(t *testing.T) {
+	data := `
+proxy:
+  listen_address: "127.0.0.1:8080"
+  max_header_bytes: lots
+`
+	result := Check([]byte(data), nil)
+	if len(result.Errors) == 0 || result.Errors[0].Field != "proxy.max_header_bytes" {
+		t.Errorf("Errors = %v, want a type error for proxy.max_header_bytes", result.Errors)
+	}
+}
+
+func TestCheck_SyntaxError(t *testing.T) {
+	result := Check([]byte("proxy: [unclosed"), nil)
+	if result.Config != nil || len(result.Errors) != 1 {
+		t.Errorf("Check() = %+v, want a single syntax error", result)
+	}
+}
@@ -1,6 +1,9 @@
 package config
 
-import "time"
+import (
+	"slices"
+	"time"
+)
 
 // Default values for configuration fields.
 const (
@@ -17,6 +20,13 @@ const (
 	DefaultCORSMaxAge           = 3600 // 1 hour
 	DefaultCORSAllowCredentials = false
 
+	// Cost allocation defaults
+	DefaultCostAllocationHeader      = "X-Cost-Center"
+	DefaultCostAllocationMetadataKey = "cost_center"
+
+	// Streaming defaults
+	DefaultStreamingMode = "passthrough"
+
 	// Provider defaults
 	DefaultProviderTimeout    = 60 * time.Second
 	DefaultProviderMaxRetries = 3
@@ -29,6 +39,10 @@ const (
 	DefaultPolicyWatch             = false
 	DefaultPolicyValidationEnabled = true
 	DefaultPolicyValidationStrict  = false
+	DefaultPolicyEventsBufferSize  = 1000
+	DefaultPolicyEventsTimeout     = 5 * time.Second
+	DefaultPolicyPreviewQueueSize  = 1000
+	DefaultPolicyPreviewHistory    = 100
 
 	// Evidence defaults
 	DefaultEvidenceEnabled              = true
@@ -38,12 +52,38 @@ const (
 	DefaultEvidenceSQLiteMaxIdleConns   = 5
 	DefaultEvidenceSQLiteWALMode        = true
 	DefaultEvidenceSQLiteBusyTimeout    = 5 * time.Second
+	DefaultEvidenceS3BatchSize          = 1000
+	DefaultEvidenceS3FlushInterval      = time.Minute
+	DefaultEvidenceS3Compression        = "gzip"
+	DefaultEvidenceStreamBufferSize     = 10000
+	DefaultEvidenceStreamBatchSize      = 100
+	DefaultEvidenceStreamFlushInterval  = time.Second
+	DefaultEvidenceStreamTimeout        = 10 * time.Second
+	DefaultEvidenceKafkaSerialization   = "json"
+	DefaultEvidenceESIndexPrefix        = "mercator-evidence"
+	DefaultEvidenceESIndexMode          = "daily"
+	DefaultEvidenceSyslogNetwork        = "udp"
+	DefaultEvidenceSyslogFormat         = "cef"
+	DefaultEvidenceSyslogFacility       = 16
+	DefaultEvidenceSyslogTag            = "mercator"
+	DefaultEvidenceCheckpointPath       = "data/evidence-checkpoints.jsonl"
+	DefaultEvidenceCheckpointInterval   = 1000
+	DefaultEvidenceCheckpointPeriod     = time.Minute
+	DefaultEvidenceCaptureMaxBodySize   = 1 << 20
+	DefaultEvidenceCaptureBlobPath      = "data/evidence-blobs"
+	DefaultEvidenceErasurePath          = "data/evidence-erasures.jsonl"
+	DefaultEvidenceLegalHoldPath        = "data/evidence-holds.json"
+	DefaultEvidenceObjectLockMode       = "COMPLIANCE"
+	DefaultEvidenceCompressionMinSize   = 1024
 	DefaultEvidenceRecorderAsyncBuffer  = 1000
 	DefaultEvidenceRecorderWriteTimeout = 5 * time.Second
+	DefaultEvidenceRecorderBatchSize    = 100
 	DefaultEvidenceRecorderHashRequest  = true
 	DefaultEvidenceRecorderHashResponse = true
 	DefaultEvidenceRecorderRedactKeys   = true
 	DefaultEvidenceRecorderMaxFieldLen  = 500
+	DefaultEvidenceRecorderSpillPath    = "data/evidence-spill.jsonl"
+	DefaultEvidenceRecorderSpillMaxSize = int64(1 << 30)
 	DefaultEvidenceRetentionDays        = 90
 	DefaultEvidenceRetentionSchedule    = "0 3 * * *"
 	DefaultEvidenceRetentionArchive     = false
@@ -52,39 +92,102 @@ const (
 	DefaultEvidenceQueryDefaultLimit    = 100
 	DefaultEvidenceQueryMaxLimit        = 10000
 	DefaultEvidenceQueryTimeout         = 30 * time.Second
+	DefaultEvidenceRollupInterval       = 5 * time.Minute
+	DefaultEvidenceRollupLookbackDays   = 1
 	DefaultEvidenceExportJSONPretty     = true
 	DefaultEvidenceExportCSVHeader      = true
 	DefaultEvidenceExportMaxSize        = 1000000
+	DefaultEvidenceExportStatePath      = "data/export-state.json"
+	DefaultEvidenceExportJobFormat      = "jsonl"
+	DefaultEvidenceExportJobDelay       = 5 * time.Minute
+	DefaultEvidenceExportJobMaxRecords  = 100000
 	DefaultPostgresPort                 = 5432
 	DefaultPostgresSSLMode              = "require"
 
 	// Telemetry defaults
 	DefaultLoggingLevel        = "info"
 	DefaultLoggingFormat       = "json"
+	DefaultLogOutputNetwork    = "udp"
+	DefaultLogOutputFacility   = 16
+	DefaultLogOutputAppName    = "mercator"
 	DefaultMetricsEnabled      = true
 	DefaultPrometheusPath      = "/metrics"
+	DefaultMetricsTenantValues = 100
+	DefaultSLOWindow           = 30 * 24 * time.Hour
+	DefaultAuditSinkType       = "stderr"
+	DefaultBusLevel            = "info"
+	DefaultBusBatchSize        = 100
+	DefaultBusFlushInterval    = time.Second
+	DefaultBusBufferSize       = 10000
+	DefaultBusBackpressure     = "drop"
+	DefaultBusTimeout          = 10 * time.Second
 	DefaultTracingEnabled      = false
 	DefaultTracingSamplingRate = 1.0
 
 	// Security defaults
 	DefaultTLSEnabled  = false
 	DefaultMTLSEnabled = false
+	DefaultAdminAuth   = "key"
+
+	// Remote configuration defaults
+	DefaultRemoteTimeout       = 5 * time.Second
+	DefaultRemoteWatchTimeout  = time.Minute
+	DefaultRemoteRetryInterval = 5 * time.Second
 
 	// Processing defaults
 	DefaultTokensEstimator            = "simple"
 	DefaultTokensCacheSize            = 100
+	DefaultTokensMessageCacheSize     = 10000
 	DefaultTokensCharsPerToken        = 4.0
+	DefaultTokensProvider             = "anthropic"
+	DefaultTokensTimeout              = 2 * time.Second
 	DefaultCostsPricing               = 0.001 // $0.001 per 1K tokens
 	DefaultContentPIIEnabled          = true
 	DefaultContentPIIRedactInLogs     = true
+	DefaultContentPIICustomSeverity   = "medium"
 	DefaultContentSensitiveEnabled    = true
 	DefaultContentSensitiveSeverity   = "medium"
+	DefaultContentSensitiveMinScore   = 0.5
+	DefaultRiskLargePromptTokens      = 32000
+	DefaultRiskHistorySize            = 10000
 	DefaultContentInjectionEnabled    = true
 	DefaultContentInjectionConfidence = 0.7
+	DefaultContentSecretsMinEntropy   = 3.5
+	DefaultClassifierThreshold        = 0.8
+	DefaultClassifierMode             = "shadow"
+	DefaultDLPBackend                 = "presidio"
+	DefaultDLPLanguage                = "en"
+	DefaultDLPMinScore                = 0.5
+	DefaultDLPTimeout                 = 500 * time.Millisecond
+	DefaultDLPFailureThreshold        = 5
+	DefaultDLPCooldown                = 30 * time.Second
+	DefaultTopicsEmbedder             = "local"
+	DefaultTopicsTimeout              = 200 * time.Millisecond
+	DefaultTopicsThreshold            = 0.3
+	DefaultCatalogRefreshInterval     = time.Hour
+	DefaultCatalogTimeout             = 10 * time.Second
+	DefaultInjectionFeedInterval      = time.Hour
+	DefaultInjectionFeedTimeout       = 10 * time.Second
+	DefaultClassifierTimeout          = 5 * time.Millisecond
+	DefaultAnalyzerType               = "http"
+	DefaultAnalyzerTimeout            = 500 * time.Millisecond
 	DefaultConversationWarnThreshold  = 0.8
 	DefaultConversationContextWindow  = 4096
 )
 
+// DefaultClassificationLabels are the data classification labels used when
+// none are configured, from least to most sensitive.
+var DefaultClassificationLabels = []string{"public", "internal", "confidential", "restricted"}
+
+// DefaultRiskWeights are the risk signal weights used when none are
+// configured. They score content analysis only.
+var DefaultRiskWeights = RiskWeightsConfig{
+	PII:       4,
+	Injection: 5,
+	Toxicity:  5,
+	Secrets:   3,
+}
+
 // ApplyDefaults applies default values to a Config struct.
 // It sets defaults for any fields that have zero values.
 // This function is idempotent and safe to call multiple times.
@@ -135,6 +238,18 @@ func ApplyDefaults(cfg *Config) {
 	if !cfg.Policy.Watch {
 		cfg.Policy.Watch = DefaultPolicyWatch
 	}
+	if cfg.Policy.Events.BufferSize == 0 {
+		cfg.Policy.Events.BufferSize = DefaultPolicyEventsBufferSize
+	}
+	if cfg.Policy.Events.Timeout == 0 {
+		cfg.Policy.Events.Timeout = DefaultPolicyEventsTimeout
+	}
+	if cfg.Policy.Preview.QueueSize == 0 {
+		cfg.Policy.Preview.QueueSize = DefaultPolicyPreviewQueueSize
+	}
+	if cfg.Policy.Preview.History == 0 {
+		cfg.Policy.Preview.History = DefaultPolicyPreviewHistory
+	}
 	// Validation defaults - need to check if struct was set at all
 	// Since bools have zero value false, we apply defaults unconditionally
 	// unless the config explicitly sets them
@@ -164,6 +279,91 @@ func ApplyDefaults(cfg *Config) {
 		cfg.Evidence.SQLite.BusyTimeout = DefaultEvidenceSQLiteBusyTimeout
 	}
 
+	// S3 defaults
+	if cfg.Evidence.S3.BatchSize == 0 {
+		cfg.Evidence.S3.BatchSize = DefaultEvidenceS3BatchSize
+	}
+	if cfg.Evidence.S3.FlushInterval == 0 {
+		cfg.Evidence.S3.FlushInterval = DefaultEvidenceS3FlushInterval
+	}
+	if cfg.Evidence.S3.Compression == "" {
+		cfg.Evidence.S3.Compression = DefaultEvidenceS3Compression
+	}
+
+	// Stream defaults
+	if cfg.Evidence.Stream.BufferSize == 0 {
+		cfg.Evidence.Stream.BufferSize = DefaultEvidenceStreamBufferSize
+	}
+	if cfg.Evidence.Stream.BatchSize == 0 {
+		cfg.Evidence.Stream.BatchSize = DefaultEvidenceStreamBatchSize
+	}
+	if cfg.Evidence.Stream.FlushInterval == 0 {
+		cfg.Evidence.Stream.FlushInterval = DefaultEvidenceStreamFlushInterval
+	}
+	if cfg.Evidence.Stream.Timeout == 0 {
+		cfg.Evidence.Stream.Timeout = DefaultEvidenceStreamTimeout
+	}
+	if cfg.Evidence.Stream.Kafka.Serialization == "" {
+		cfg.Evidence.Stream.Kafka.Serialization = DefaultEvidenceKafkaSerialization
+	}
+	if cfg.Evidence.Stream.Elasticsearch.IndexPrefix == "" {
+		cfg.Evidence.Stream.Elasticsearch.IndexPrefix = DefaultEvidenceESIndexPrefix
+	}
+	if cfg.Evidence.Stream.Elasticsearch.IndexMode == "" {
+		cfg.Evidence.Stream.Elasticsearch.IndexMode = DefaultEvidenceESIndexMode
+	}
+	if cfg.Evidence.Stream.Syslog.Network == "" {
+		cfg.Evidence.Stream.Syslog.Network = DefaultEvidenceSyslogNetwork
+	}
+	if cfg.Evidence.Stream.Syslog.Format == "" {
+		cfg.Evidence.Stream.Syslog.Format = DefaultEvidenceSyslogFormat
+	}
+	if cfg.Evidence.Stream.Syslog.Facility == 0 {
+		cfg.Evidence.Stream.Syslog.Facility = DefaultEvidenceSyslogFacility
+	}
+	if cfg.Evidence.Stream.Syslog.Tag == "" {
+		cfg.Evidence.Stream.Syslog.Tag = DefaultEvidenceSyslogTag
+	}
+
+	// Integrity defaults
+	if cfg.Evidence.Integrity.CheckpointPath == "" {
+		cfg.Evidence.Integrity.CheckpointPath = DefaultEvidenceCheckpointPath
+	}
+	if cfg.Evidence.Integrity.CheckpointInterval == 0 {
+		cfg.Evidence.Integrity.CheckpointInterval = DefaultEvidenceCheckpointInterval
+	}
+	if cfg.Evidence.Integrity.CheckpointPeriod == 0 {
+		cfg.Evidence.Integrity.CheckpointPeriod = DefaultEvidenceCheckpointPeriod
+	}
+
+	// Capture defaults
+	if cfg.Evidence.Capture.MaxBodySize == 0 {
+		cfg.Evidence.Capture.MaxBodySize = DefaultEvidenceCaptureMaxBodySize
+	}
+	if cfg.Evidence.Capture.BlobPath == "" {
+		cfg.Evidence.Capture.BlobPath = DefaultEvidenceCaptureBlobPath
+	}
+
+	// Legal hold defaults
+	if cfg.Evidence.Retention.LegalHolds.Path == "" {
+		cfg.Evidence.Retention.LegalHolds.Path = DefaultEvidenceLegalHoldPath
+	}
+
+	// Write-once defaults
+	if cfg.Evidence.WriteOnce.ObjectLockMode == "" {
+		cfg.Evidence.WriteOnce.ObjectLockMode = DefaultEvidenceObjectLockMode
+	}
+
+	// Compression defaults
+	if cfg.Evidence.Compression.MinSize == 0 {
+		cfg.Evidence.Compression.MinSize = DefaultEvidenceCompressionMinSize
+	}
+
+	// Erasure defaults
+	if cfg.Evidence.Erasure.CertificatePath == "" {
+		cfg.Evidence.Erasure.CertificatePath = DefaultEvidenceErasurePath
+	}
+
 	// Recorder defaults
 	if cfg.Evidence.Recorder.AsyncBuffer == 0 {
 		cfg.Evidence.Recorder.AsyncBuffer = DefaultEvidenceRecorderAsyncBuffer
@@ -171,6 +371,9 @@ func ApplyDefaults(cfg *Config) {
 	if cfg.Evidence.Recorder.WriteTimeout == 0 {
 		cfg.Evidence.Recorder.WriteTimeout = DefaultEvidenceRecorderWriteTimeout
 	}
+	if cfg.Evidence.Recorder.BatchSize == 0 {
+		cfg.Evidence.Recorder.BatchSize = DefaultEvidenceRecorderBatchSize
+	}
 	if !cfg.Evidence.Recorder.HashRequest {
 		cfg.Evidence.Recorder.HashRequest = DefaultEvidenceRecorderHashRequest
 	}
@@ -183,6 +386,12 @@ func ApplyDefaults(cfg *Config) {
 	if cfg.Evidence.Recorder.MaxFieldLength == 0 {
 		cfg.Evidence.Recorder.MaxFieldLength = DefaultEvidenceRecorderMaxFieldLen
 	}
+	if cfg.Evidence.Recorder.Spill.Path == "" {
+		cfg.Evidence.Recorder.Spill.Path = DefaultEvidenceRecorderSpillPath
+	}
+	if cfg.Evidence.Recorder.Spill.MaxSize == 0 {
+		cfg.Evidence.Recorder.Spill.MaxSize = DefaultEvidenceRecorderSpillMaxSize
+	}
 
 	// Retention defaults
 	if cfg.Evidence.Retention.Days == 0 {
@@ -211,6 +420,12 @@ func ApplyDefaults(cfg *Config) {
 	if cfg.Evidence.Query.Timeout == 0 {
 		cfg.Evidence.Query.Timeout = DefaultEvidenceQueryTimeout
 	}
+	if cfg.Evidence.Query.Rollup.Interval == 0 {
+		cfg.Evidence.Query.Rollup.Interval = DefaultEvidenceRollupInterval
+	}
+	if cfg.Evidence.Query.Rollup.LookbackDays == 0 {
+		cfg.Evidence.Query.Rollup.LookbackDays = DefaultEvidenceRollupLookbackDays
+	}
 
 	// Export defaults
 	if !cfg.Evidence.Export.JSONPretty {
@@ -222,6 +437,21 @@ func ApplyDefaults(cfg *Config) {
 	if cfg.Evidence.Export.MaxExportSize == 0 {
 		cfg.Evidence.Export.MaxExportSize = DefaultEvidenceExportMaxSize
 	}
+	if cfg.Evidence.Export.StatePath == "" {
+		cfg.Evidence.Export.StatePath = DefaultEvidenceExportStatePath
+	}
+	for i := range cfg.Evidence.Export.Jobs {
+		job := &cfg.Evidence.Export.Jobs[i]
+		if job.Format == "" {
+			job.Format = DefaultEvidenceExportJobFormat
+		}
+		if job.Delay == 0 {
+			job.Delay = DefaultEvidenceExportJobDelay
+		}
+		if job.MaxRecords == 0 {
+			job.MaxRecords = DefaultEvidenceExportJobMaxRecords
+		}
+	}
 
 	// Postgres defaults
 	if cfg.Evidence.Postgres.Port == 0 {
@@ -238,12 +468,55 @@ func ApplyDefaults(cfg *Config) {
 	if cfg.Telemetry.Logging.Format == "" {
 		cfg.Telemetry.Logging.Format = DefaultLoggingFormat
 	}
+	for i := range cfg.Telemetry.Logging.Outputs {
+		output := &cfg.Telemetry.Logging.Outputs[i]
+		if output.Network == "" {
+			output.Network = DefaultLogOutputNetwork
+		}
+		if output.Type == "syslog" {
+			if output.Facility == 0 {
+				output.Facility = DefaultLogOutputFacility
+			}
+			if output.AppName == "" {
+				output.AppName = DefaultLogOutputAppName
+			}
+		}
+	}
 	if cfg.Telemetry.Metrics.Path == "" {
 		cfg.Telemetry.Metrics.Path = DefaultPrometheusPath
 	}
+	if cfg.Telemetry.Metrics.TenantLabels.MaxValues == 0 {
+		cfg.Telemetry.Metrics.TenantLabels.MaxValues = DefaultMetricsTenantValues
+	}
+	for i := range cfg.Telemetry.Metrics.SLO.Objectives {
+		if cfg.Telemetry.Metrics.SLO.Objectives[i].Window == 0 {
+			cfg.Telemetry.Metrics.SLO.Objectives[i].Window = DefaultSLOWindow
+		}
+	}
 	if cfg.Telemetry.Tracing.SampleRatio == 0 {
 		cfg.Telemetry.Tracing.SampleRatio = DefaultTracingSamplingRate
 	}
+	if cfg.Telemetry.Audit.Enabled && len(cfg.Telemetry.Audit.Sinks) == 0 {
+		cfg.Telemetry.Audit.Sinks = []AuditSinkConfig{{Type: DefaultAuditSinkType}}
+	}
+	if cfg.Telemetry.Bus.Level == "" {
+		cfg.Telemetry.Bus.Level = DefaultBusLevel
+	}
+	if cfg.Telemetry.Bus.BatchSize == 0 {
+		cfg.Telemetry.Bus.BatchSize = DefaultBusBatchSize
+	}
+	if cfg.Telemetry.Bus.FlushInterval == 0 {
+		cfg.Telemetry.Bus.FlushInterval = DefaultBusFlushInterval
+	}
+	if cfg.Telemetry.Bus.BufferSize == 0 {
+		cfg.Telemetry.Bus.BufferSize = DefaultBusBufferSize
+	}
+	if cfg.Telemetry.Bus.Backpressure == "" {
+		cfg.Telemetry.Bus.Backpressure = DefaultBusBackpressure
+	}
+	if cfg.Telemetry.Bus.Timeout == 0 {
+		cfg.Telemetry.Bus.Timeout = DefaultBusTimeout
+	}
 
 	// Proxy shutdown timeout
 	if cfg.Proxy.ShutdownTimeout == 0 {
@@ -253,10 +526,36 @@ func ApplyDefaults(cfg *Config) {
 	// CORS defaults
 	applyCORSDefaults(cfg)
 
+	// Cost allocation defaults
+	if cfg.Proxy.CostAllocation.Header == "" {
+		cfg.Proxy.CostAllocation.Header = DefaultCostAllocationHeader
+	}
+	if cfg.Proxy.CostAllocation.MetadataKey == "" {
+		cfg.Proxy.CostAllocation.MetadataKey = DefaultCostAllocationMetadataKey
+	}
+	if cfg.Proxy.Streaming.Mode == "" {
+		cfg.Proxy.Streaming.Mode = DefaultStreamingMode
+	}
+
 	// Processing defaults
 	applyProcessingDefaults(cfg)
 
-	// Security defaults are false (zero values), which is correct
+	// Security defaults are false (zero values), which is correct, except
+	// for the admin authentication method
+	if cfg.Security.Admin.Auth == "" {
+		cfg.Security.Admin.Auth = DefaultAdminAuth
+	}
+
+	// Remote configuration defaults
+	if cfg.Remote.Timeout == 0 {
+		cfg.Remote.Timeout = DefaultRemoteTimeout
+	}
+	if cfg.Remote.WatchTimeout == 0 {
+		cfg.Remote.WatchTimeout = DefaultRemoteWatchTimeout
+	}
+	if cfg.Remote.RetryInterval == 0 {
+		cfg.Remote.RetryInterval = DefaultRemoteRetryInterval
+	}
 }
 
 // applyCORSDefaults applies default values to CORS configuration.
@@ -325,6 +624,15 @@ func applyProcessingDefaults(cfg *Config) {
 	if cfg.Processing.Tokens.CacheSize == 0 {
 		cfg.Processing.Tokens.CacheSize = DefaultTokensCacheSize
 	}
+	if cfg.Processing.Tokens.MessageCacheSize == 0 {
+		cfg.Processing.Tokens.MessageCacheSize = DefaultTokensMessageCacheSize
+	}
+	if cfg.Processing.Tokens.Provider == "" {
+		cfg.Processing.Tokens.Provider = DefaultTokensProvider
+	}
+	if cfg.Processing.Tokens.Timeout == 0 {
+		cfg.Processing.Tokens.Timeout = DefaultTokensTimeout
+	}
 	if cfg.Processing.Tokens.Models == nil {
 		cfg.Processing.Tokens.Models = map[string]float64{
 			"gpt-4":           4.0,
@@ -336,7 +644,18 @@ func applyProcessingDefaults(cfg *Config) {
 		}
 	}
 
-	// Costs defaults
+	// Costs defaults. With a pricing catalog, only the fallback pricing is
+	// set, since configured prices override those of the catalog.
+	if cfg.Processing.Costs.Pricing == nil && cfg.Processing.Costs.Catalog.Enabled {
+		cfg.Processing.Costs.Pricing = map[string]map[string]ModelPricingConfig{
+			"default": {
+				"default": {
+					Prompt:     DefaultCostsPricing,
+					Completion: DefaultCostsPricing * 2,
+				},
+			},
+		}
+	}
 	if cfg.Processing.Costs.Pricing == nil {
 		cfg.Processing.Costs.Pricing = map[string]map[string]ModelPricingConfig{
 			"openai": {
@@ -367,6 +686,20 @@ func applyProcessingDefaults(cfg *Config) {
 					Completion: 0.00125,
 				},
 			},
+			"google": {
+				"gemini-1.5-pro": {
+					Prompt:     0.00125,
+					Completion: 0.005,
+				},
+				"gemini-1.5-flash": {
+					Prompt:     0.000075,
+					Completion: 0.0003,
+				},
+				"gemini-2.0-flash": {
+					Prompt:     0.0001,
+					Completion: 0.0004,
+				},
+			},
 			"default": {
 				"default": {
 					Prompt:     DefaultCostsPricing,
@@ -376,6 +709,18 @@ func applyProcessingDefaults(cfg *Config) {
 		}
 	}
 
+	// Pricing catalog defaults
+	catalog := &cfg.Processing.Costs.Catalog
+	if catalog.SignatureURL == "" && catalog.URL != "" {
+		catalog.SignatureURL = catalog.URL + ".sig"
+	}
+	if catalog.RefreshInterval == 0 {
+		catalog.RefreshInterval = DefaultCatalogRefreshInterval
+	}
+	if catalog.Timeout == 0 {
+		catalog.Timeout = DefaultCatalogTimeout
+	}
+
 	// Content PII defaults
 	if len(cfg.Processing.Content.PII.Types) == 0 {
 		cfg.Processing.Content.PII.Types = []string{
@@ -387,6 +732,12 @@ func applyProcessingDefaults(cfg *Config) {
 		}
 	}
 
+	for i := range cfg.Processing.Content.PII.Custom {
+		if cfg.Processing.Content.PII.Custom[i].Severity == "" {
+			cfg.Processing.Content.PII.Custom[i].Severity = DefaultContentPIICustomSeverity
+		}
+	}
+
 	// Content sensitive defaults
 	if cfg.Processing.Content.Sensitive.SeverityThreshold == "" {
 		cfg.Processing.Content.Sensitive.SeverityThreshold = DefaultContentSensitiveSeverity
@@ -395,10 +746,15 @@ func applyProcessingDefaults(cfg *Config) {
 		cfg.Processing.Content.Sensitive.Categories = []string{
 			"profanity",
 			"violence",
-			"hate_speech",
-			"adult_content",
+			"self_harm",
+			"sexual",
+			"hate",
+			"harassment",
 		}
 	}
+	if cfg.Processing.Content.Sensitive.MinScore == 0 {
+		cfg.Processing.Content.Sensitive.MinScore = DefaultContentSensitiveMinScore
+	}
 
 	// Content injection defaults
 	if cfg.Processing.Content.Injection.ConfidenceThreshold == 0 {
@@ -414,6 +770,115 @@ func applyProcessingDefaults(cfg *Config) {
 		}
 	}
 
+	if cfg.Processing.Content.Injection.Classifier.Threshold == 0 {
+		cfg.Processing.Content.Injection.Classifier.Threshold = DefaultClassifierThreshold
+	}
+	if cfg.Processing.Content.Injection.Classifier.Mode == "" {
+		cfg.Processing.Content.Injection.Classifier.Mode = DefaultClassifierMode
+	}
+	if cfg.Processing.Content.Injection.Classifier.Timeout == 0 {
+		cfg.Processing.Content.Injection.Classifier.Timeout = DefaultClassifierTimeout
+	}
+	feed := &cfg.Processing.Content.Injection.Feed
+	if feed.SignatureURL == "" && feed.URL != "" {
+		feed.SignatureURL = feed.URL + ".sig"
+	}
+	if feed.RefreshInterval == 0 {
+		feed.RefreshInterval = DefaultInjectionFeedInterval
+	}
+	if feed.Timeout == 0 {
+		feed.Timeout = DefaultInjectionFeedTimeout
+	}
+
+	// Content DLP defaults
+	dlp := &cfg.Processing.Content.DLP
+	if dlp.Backend == "" {
+		dlp.Backend = DefaultDLPBackend
+	}
+	if dlp.Language == "" {
+		dlp.Language = DefaultDLPLanguage
+	}
+	if dlp.MinScore == 0 {
+		dlp.MinScore = DefaultDLPMinScore
+	}
+	if dlp.Timeout == 0 {
+		dlp.Timeout = DefaultDLPTimeout
+	}
+	if dlp.FailureThreshold == 0 {
+		dlp.FailureThreshold = DefaultDLPFailureThreshold
+	}
+	if dlp.Cooldown == 0 {
+		dlp.Cooldown = DefaultDLPCooldown
+	}
+
+	// Content topic similarity defaults
+	topics := &cfg.Processing.Content.Topics
+	if topics.Embedder == "" {
+		topics.Embedder = DefaultTopicsEmbedder
+	}
+	if topics.Timeout == 0 {
+		topics.Timeout = DefaultTopicsTimeout
+	}
+	if topics.Threshold == 0 {
+		topics.Threshold = DefaultTopicsThreshold
+	}
+
+	// Content entity recognition defaults
+	entities := &cfg.Processing.Content.Entities
+	if len(entities.Types) == 0 {
+		entities.Types = []string{"organization", "person", "location"}
+	}
+
+	// Content classification defaults
+	classification := &cfg.Processing.Content.Classification
+	if len(classification.Labels) == 0 {
+		classification.Labels = slices.Clone(DefaultClassificationLabels)
+		if len(classification.Rules) == 0 {
+			classification.Rules = []ClassificationRuleConfig{
+				{Label: "restricted", SecretTypes: []string{"*"}},
+				{Label: "restricted", PIISeverity: "high"},
+				{Label: "confidential", PIITypes: []string{"*"}},
+			}
+		}
+	}
+	if classification.Default == "" {
+		classification.Default = classification.Labels[0]
+	}
+
+	// Content secrets defaults
+	if len(cfg.Processing.Content.Secrets.Types) == 0 {
+		cfg.Processing.Content.Secrets.Types = []string{
+			"aws_access_key",
+			"aws_secret_key",
+			"github_token",
+			"slack_token",
+			"private_key",
+			"jwt",
+			"connection_string",
+			"generic_secret",
+		}
+	}
+	if cfg.Processing.Content.Secrets.MinEntropy == 0 {
+		cfg.Processing.Content.Secrets.MinEntropy = DefaultContentSecretsMinEntropy
+	}
+
+	// Risk defaults
+	ApplyRiskDefaults(&cfg.Processing.Risk)
+
+	// Custom analyzer defaults
+	for i := range cfg.Processing.Analyzers {
+		analyzer := &cfg.Processing.Analyzers[i]
+		if analyzer.Type == "" {
+			analyzer.Type = DefaultAnalyzerType
+		}
+		if len(analyzer.Stages) == 0 {
+			analyzer.Stages = []string{"request"}
+		}
+		if analyzer.Timeout == 0 {
+			analyzer.Timeout = DefaultAnalyzerTimeout
+		}
+	}
+
 	// Conversation defaults
 	if cfg.Processing.Conversation.WarnThreshold == 0 {
 		cfg.Processing.Conversation.WarnThreshold = DefaultConversationWarnThreshold
@@ -434,6 +899,12 @@ func applyProcessingDefaults(cfg *Config) {
 	if cfg.Limits.Budgets.AlertThreshold == 0 {
 		cfg.Limits.Budgets.AlertThreshold = 0.8 // 80%
 	}
+	if cfg.Limits.Budgets.Hierarchy.Inheritance == "" {
+		cfg.Limits.Budgets.Hierarchy.Inheritance = "all"
+	}
+	if cfg.Limits.Budgets.Alerts.DedupInterval == 0 {
+		cfg.Limits.Budgets.Alerts.DedupInterval = time.Hour
+	}
 	if cfg.Limits.Enforcement.Action == "" {
 		cfg.Limits.Enforcement.Action = "block"
 	}
@@ -443,9 +914,58 @@ func applyProcessingDefaults(cfg *Config) {
 	if cfg.Limits.Enforcement.QueueTimeout == 0 {
 		cfg.Limits.Enforcement.QueueTimeout = 30 * time.Second
 	}
+	if cfg.Limits.RateLimits.Backend == "" {
+		cfg.Limits.RateLimits.Backend = "memory"
+	}
+	if cfg.Limits.RateLimits.Redis.KeyPrefix == "" {
+		cfg.Limits.RateLimits.Redis.KeyPrefix = "mercator:ratelimit:"
+	}
+	if cfg.Limits.RateLimits.Redis.PoolSize == 0 {
+		cfg.Limits.RateLimits.Redis.PoolSize = 10
+	}
+	if cfg.Limits.RateLimits.Redis.DialTimeout == 0 {
+		cfg.Limits.RateLimits.Redis.DialTimeout = 500 * time.Millisecond
+	}
+	if cfg.Limits.RateLimits.Redis.OpTimeout == 0 {
+		cfg.Limits.RateLimits.Redis.OpTimeout = 100 * time.Millisecond
+	}
+	if cfg.Limits.RateLimits.Redis.FallbackRetry == 0 {
+		cfg.Limits.RateLimits.Redis.FallbackRetry = 5 * time.Second
+	}
 	if cfg.Limits.Storage.Backend == "" {
 		cfg.Limits.Storage.Backend = "memory"
 	}
+	if cfg.Limits.Storage.SnapshotInterval == 0 {
+		cfg.Limits.Storage.SnapshotInterval = 10 * time.Second
+		if cfg.Limits.Storage.Backend == "redis" {
+			cfg.Limits.Storage.SnapshotInterval = time.Second
+		}
+	}
+	if cfg.Limits.Storage.Backend == "redis" && cfg.Limits.Storage.Redis.Address == "" && cfg.Limits.RateLimits.Backend == "redis" {
+		cfg.Limits.Storage.Redis = cfg.Limits.RateLimits.Redis
+		cfg.Limits.Storage.Redis.KeyPrefix = ""
+	}
+	if cfg.Limits.Storage.Redis.KeyPrefix == "" {
+		cfg.Limits.Storage.Redis.KeyPrefix = "mercator:limits:"
+	}
+	if cfg.Limits.Storage.Redis.PoolSize == 0 {
+		cfg.Limits.Storage.Redis.PoolSize = 10
+	}
+	if cfg.Limits.Storage.Redis.DialTimeout == 0 {
+		cfg.Limits.Storage.Redis.DialTimeout = 500 * time.Millisecond
+	}
+	if cfg.Limits.Storage.Redis.OpTimeout == 0 {
+		cfg.Limits.Storage.Redis.OpTimeout = 100 * time.Millisecond
+	}
+	if cfg.Limits.Storage.Backend == "postgres" && cfg.Limits.Storage.Postgres.Host == "" && cfg.Evidence.Backend == "postgres" {
+		cfg.Limits.Storage.Postgres = cfg.Evidence.Postgres
+	}
+	if cfg.Limits.Storage.Postgres.Port == 0 {
+		cfg.Limits.Storage.Postgres.Port = DefaultPostgresPort
+	}
+	if cfg.Limits.Storage.Postgres.SSLMode == "" {
+		cfg.Limits.Storage.Postgres.SSLMode = DefaultPostgresSSLMode
+	}
 	if cfg.Limits.Storage.SQLite.Path == "" {
 		cfg.Limits.Storage.SQLite.Path = "/var/lib/mercator/limits.db"
 	}
@@ -458,4 +978,22 @@ func applyProcessingDefaults(cfg *Config) {
 	if cfg.Limits.Storage.Memory.CleanupInterval == 0 {
 		cfg.Limits.Storage.Memory.CleanupInterval = time.Minute
 	}
+	if cfg.Limits.Storage.Memory.SnapshotInterval == 0 {
+		cfg.Limits.Storage.Memory.SnapshotInterval = 30 * time.Second
+	}
+}
+
+// ApplyRiskDefaults applies default values to a risk configuration, such as
+// one reloaded from a risk file. The default weights apply only if no weight
+// is set, so that individual signals can be disabled with a weight of 0.
+func ApplyRiskDefaults(cfg *RiskConfig) {
+	if cfg.Weights == (RiskWeightsConfig{}) {
+		cfg.Weights = DefaultRiskWeights
+	}
+	if cfg.LargePromptTokens == 0 {
+		cfg.LargePromptTokens = DefaultRiskLargePromptTokens
+	}
+	if cfg.HistorySize == 0 {
+		cfg.HistorySize = DefaultRiskHistorySize
+	}
 }
@@ -138,6 +138,60 @@ func TestApplyDefaults(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "limits postgres storage uses evidence database",
+			input: Config{
+				Providers: make(map[string]ProviderConfig),
+				Evidence: EvidenceConfig{
+					Backend: "postgres",
+					Postgres: PostgresConfig{
+						Host:     "evidence-db",
+						Database: "mercator",
+						User:     "user",
+					},
+				},
+				Limits: LimitsConfig{
+					Storage: LimitsStorageConfig{Backend: "postgres"},
+				},
+			},
+			check: func(t *testing.T, cfg *Config) {
+				pg := cfg.Limits.Storage.Postgres
+				if pg.Host != "evidence-db" || pg.Database != "mercator" || pg.User != "user" {
+					t.Errorf("expected evidence database settings, got %+v", pg)
+				}
+				if pg.Port != DefaultPostgresPort {
+					t.Errorf("expected postgres port %d, got %d", DefaultPostgresPort, pg.Port)
+				}
+				if cfg.Limits.Storage.SnapshotInterval != 10*time.Second {
+					t.Errorf("expected snapshot interval 10s, got %v", cfg.Limits.Storage.SnapshotInterval)
+				}
+			},
+		},
+		{
+			name: "limits redis storage uses rate limit redis",
+			input: Config{
+				Providers: make(map[string]ProviderConfig),
+				Limits: LimitsConfig{
+					RateLimits: RateLimitsConfig{
+						Backend: "redis",
+						Redis:   RateLimitsRedisConfig{Address: "redis:6379", Password: "secret"},
+					},
+					Storage: LimitsStorageConfig{Backend: "redis"},
+				},
+			},
+			check: func(t *testing.T, cfg *Config) {
+				redis := cfg.Limits.Storage.Redis
+				if redis.Address != "redis:6379" || redis.Password != "secret" {
+					t.Errorf("expected rate limit Redis settings, got %+v", redis)
+				}
+				if redis.KeyPrefix != "mercator:limits:" {
+					t.Errorf("expected key prefix %q, got %q", "mercator:limits:", redis.KeyPrefix)
+				}
+				if cfg.Limits.Storage.SnapshotInterval != time.Second {
+					t.Errorf("expected snapshot interval 1s, got %v", cfg.Limits.Storage.SnapshotInterval)
+				}
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -38,6 +38,28 @@ func LoadConfig(path string) (*Config, error) {
 	return &cfg, nil
 }
 
+// ParseConfigWithEnvOverrides loads configuration from YAML data, such as
+// a document read from a remote source, like LoadConfigWithEnvOverrides
+// loads a file.
+func ParseConfigWithEnvOverrides(data []byte) (*Config, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse configuration: %w", err)
+	}
+
+	ApplyDefaults(&cfg)
+	if err := Validate(&cfg); err != nil {
+		return nil, fmt.Errorf("configuration validation failed: %w", err)
+	}
+
+	applyEnvOverrides(&cfg)
+	if err := Validate(&cfg); err != nil {
+		return nil, fmt.Errorf("configuration validation failed after environment overrides: %w", err)
+	}
+
+	return &cfg, nil
+}
+
 // LoadConfigWithEnvOverrides loads configuration from a YAML file and applies
 // environment variable overrides. Environment variables follow the naming
 // convention MERCATOR_SECTION_FIELD (e.g., MERCATOR_PROXY_LISTEN_ADDRESS).
@@ -95,9 +117,11 @@ func applyEnvOverrides(cfg *Config) {
 	}
 
 	// Provider overrides - we need to handle dynamic provider names
-	// For now, we'll support common providers: openai, anthropic
+	// For now, we'll support common providers: openai, anthropic, gemini, replicate
 	applyProviderEnvOverrides(cfg, "openai")
 	applyProviderEnvOverrides(cfg, "anthropic")
+	applyProviderEnvOverrides(cfg, "gemini")
+	applyProviderEnvOverrides(cfg, "replicate")
 	// Add more providers as needed
 
 	// Policy overrides
@@ -184,6 +208,9 @@ func applyEnvOverrides(cfg *Config) {
 	if val := os.Getenv("MERCATOR_EVIDENCE_SIGNING_KEY_PATH"); val != "" {
 		cfg.Evidence.SigningKeyPath = val
 	}
+	if val := os.Getenv("MERCATOR_EVIDENCE_SIGNING_KEY_SECRET"); val != "" {
+		cfg.Evidence.SigningKeySecret = val
+	}
 
 	// Telemetry overrides
 	if val := os.Getenv("MERCATOR_TELEMETRY_LOGGING_LEVEL"); val != "" {
@@ -234,6 +261,26 @@ func applyEnvOverrides(cfg *Config) {
 	if val := os.Getenv("MERCATOR_SECURITY_MTLS_CA_FILE"); val != "" {
 		cfg.Security.TLS.MTLS.ClientCAFile = val
 	}
+	if val := os.Getenv("MERCATOR_SECURITY_ADMIN_LISTEN_ADDRESS"); val != "" {
+		cfg.Security.Admin.ListenAddress = val
+	}
+
+	// Remote configuration overrides
+	if val := os.Getenv("MERCATOR_REMOTE_BACKEND"); val != "" {
+		cfg.Remote.Backend = val
+	}
+	if val := os.Getenv("MERCATOR_REMOTE_ENDPOINTS"); val != "" {
+		cfg.Remote.Endpoints = strings.Split(val, ",")
+	}
+	if val := os.Getenv("MERCATOR_REMOTE_KEY"); val != "" {
+		cfg.Remote.Key = val
+	}
+	if val := os.Getenv("MERCATOR_REMOTE_PASSWORD"); val != "" {
+		cfg.Remote.Password = val
+	}
+	if val := os.Getenv("MERCATOR_REMOTE_TOKEN"); val != "" {
+		cfg.Remote.Token = val
+	}
 }
 
 // applyProviderEnvOverrides applies environment variable overrides for a specific provider.
@@ -277,6 +324,14 @@ func applyProviderEnvOverrides(cfg *Config, providerName string) {
 			modified = true
 		}
 	}
+	if val := os.Getenv(prefix + "PROXY_URL"); val != "" {
+		provider.Proxy.URL = val
+		modified = true
+	}
+	if val := os.Getenv(prefix + "PROXY_NO_PROXY"); val != "" {
+		provider.Proxy.NoProxy = strings.Split(val, ",")
+		modified = true
+	}
 
 	// Only update the map if we found at least one override
 	if modified || exists {
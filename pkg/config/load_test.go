@@ -261,10 +261,14 @@ evidence:
 
 	os.Setenv("MERCATOR_PROXY_MAX_HEADER_BYTES", "2097152")
 	os.Setenv("MERCATOR_PROVIDERS_OPENAI_MAX_RETRIES", "5")
+	os.Setenv("MERCATOR_PROVIDERS_OPENAI_PROXY_URL", "http://proxy.corp.example:3128")
+	os.Setenv("MERCATOR_PROVIDERS_OPENAI_PROXY_NO_PROXY", ".internal,10.0.0.0/8")
 	os.Setenv("MERCATOR_EVIDENCE_RETENTION_DAYS", "30")
 	defer func() {
 		os.Unsetenv("MERCATOR_PROXY_MAX_HEADER_BYTES")
 		os.Unsetenv("MERCATOR_PROVIDERS_OPENAI_MAX_RETRIES")
+		os.Unsetenv("MERCATOR_PROVIDERS_OPENAI_PROXY_URL")
+		os.Unsetenv("MERCATOR_PROVIDERS_OPENAI_PROXY_NO_PROXY")
 		os.Unsetenv("MERCATOR_EVIDENCE_RETENTION_DAYS")
 	}()
 
@@ -281,6 +285,10 @@ evidence:
 		t.Errorf("expected max retries %d, got %d", 5, cfg.Providers["openai"].MaxRetries)
 	}
 
+	if proxy := cfg.Providers["openai"].Proxy; proxy.URL != "http://proxy.corp.example:3128" || len(proxy.NoProxy) != 2 {
+		t.Errorf("expected proxy from environment, got %+v", proxy)
+	}
+
 	if cfg.Evidence.Retention.Days != 30 {
 		t.Errorf("expected retention days %d, got %d", 30, cfg.Evidence.Retention.Days)
 	}
@@ -431,3 +439,43 @@ telemetry:
 		}
 	}
 }
+
+func TestParseConfigWithEnvOverrides(t *testing.T) {
+	data := []byte(`
+proxy:
+  listen_address: "127.0.0.1:8080"
+providers:
+  openai:
+    base_url: "https://api.openai.com/v1"
+    api_key: "test-key"
+remote:
+  backend: etcd
+  endpoints: ["http://etcd:2379"]
+  key: mercator/config.yaml
+`)
+
+	os.Setenv("MERCATOR_REMOTE_ENDPOINTS", "http://etcd-0:2379,http://etcd-1:2379")
+	os.Setenv("MERCATOR_REMOTE_KEY", "mercator/prod/config.yaml")
+	defer func() {
+		os.Unsetenv("MERCATOR_REMOTE_ENDPOINTS")
+		os.Unsetenv("MERCATOR_REMOTE_KEY")
+	}()
+
+	cfg, err := ParseConfigWithEnvOverrides(data)
+	if err != nil {
+		t.Fatalf("failed to parse config: %v", err)
+	}
+	if len(cfg.Remote.Endpoints) != 2 || cfg.Remote.Endpoints[1] != "http://etcd-1:2379" {
+		t.Errorf("expected endpoints from env, got %v", cfg.Remote.Endpoints)
+	}
+	if cfg.Remote.Key != "mercator/prod/config.yaml" {
+		t.Errorf("expected key from env, got %q", cfg.Remote.Key)
+	}
+	if cfg.Remote.WatchTimeout != DefaultRemoteWatchTimeout {
+		t.Errorf("expected default watch timeout, got %v", cfg.Remote.WatchTimeout)
+	}
+
+	if _, err := ParseConfigWithEnvOverrides([]byte("proxy: [unclosed")); err == nil {
+		t.Error("expected an error for malformed YAML")
+	}
+}
@@ -0,0 +1,117 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"mercator-hq/jupiter/pkg/config"
+)
+
+// consulSource reads a key through the Consul KV API, and watches it with
+// blocking queries.
+type consulSource struct {
+	endpoints    *endpoints
+	key          string
+	token        string
+	datacenter   string
+	timeout      time.Duration
+	watchTimeout time.Duration
+}
+
+func newConsulSource(cfg *config.RemoteConfig, endpoints *endpoints) *consulSource {
+	return &consulSource{
+		endpoints:    endpoints,
+		key:          strings.TrimPrefix(cfg.Key, "/"),
+		token:        cfg.Token,
+		datacenter:   cfg.Datacenter,
+		timeout:      cfg.Timeout,
+		watchTimeout: cfg.WatchTimeout,
+	}
+}
+
+// String describes the key.
+func (s *consulSource) String() string {
+	return "consul key " + s.key
+}
+
+// Get returns the current value of the key.
+func (s *consulSource) Get(ctx context.Context) (*Value, error) {
+	return s.Watch(ctx, 0)
+}
+
+// Watch returns the value of the key once its index is past revision, or
+// after the watch timeout. Consul resets the wait on any change of the
+// KV index, so the value may be unchanged.
+func (s *consulSource) Watch(ctx context.Context, revision int64) (*Value, error) {
+	query := url.Values{}
+	if s.datacenter != "" {
+		query.Set("dc", s.datacenter)
+	}
+	timeout := s.timeout
+	if revision > 0 {
+		query.Set("index", strconv.FormatInt(revision, 10))
+		query.Set("wait", s.watchTimeout.String())
+		// Consul adds up to wait/16 of jitter to the wait
+		timeout += s.watchTimeout + s.watchTimeout/16
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	resp, err := s.endpoints.do(ctx, func(ctx context.Context, baseURL string) (*http.Request, error) {
+		endpoint := baseURL + "/v1/kv/" + (&url.URL{Path: s.key}).EscapedPath()
+		if len(query) > 0 {
+			endpoint += "?" + query.Encode()
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create consul request: %w", err)
+		}
+		if s.token != "" {
+			req.Header.Set("X-Consul-Token", s.token)
+		}
+		return req, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return nil, statusError(resp, "consul")
+	}
+
+	index, err := strconv.ParseInt(resp.Header.Get("X-Consul-Index"), 10, 64)
+	if err != nil || index < 1 {
+		return nil, fmt.Errorf("consul returned an invalid index %q", resp.Header.Get("X-Consul-Index"))
+	}
+	if index < revision {
+		// The index went backwards (e.g., the store was restored), so the
+		// next watch restarts from the first index
+		index = 0
+	}
+	value := &Value{Revision: index}
+	if resp.StatusCode == http.StatusNotFound {
+		return value, nil
+	}
+
+	var entries []struct {
+		Value []byte `json:"Value"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("failed to decode consul response: %w", err)
+	}
+	if len(entries) > 0 {
+		value.Data = entries[0].Value
+		if value.Data == nil {
+			value.Data = []byte{}
+		}
+	}
+	return value, nil
+}
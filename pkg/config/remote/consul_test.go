@@ -0,0 +1,163 @@
+package remote
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/config"
+)
+
+// fakeConsul is a Consul KV API holding a single key.
+type fakeConsul struct {
+	mu      sync.Mutex
+	value   []byte
+	index   int64
+	changed chan struct{}
+
+	// requests are the requests received
+	requests []*http.Request
+}
+
+func newFakeConsul() *fakeConsul {
+	return &fakeConsul{index: 10, changed: make(chan struct{})}
+}
+
+func (c *fakeConsul) put(value []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.value = value
+	c.index++
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+func (c *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	c.requests = append(c.requests, r)
+	c.mu.Unlock()
+
+	if r.URL.Path != "/v1/kv/mercator/config.yaml" {
+		http.NotFound(w, r)
+		return
+	}
+
+	index, _ := strconv.ParseInt(r.URL.Query().Get("index"), 10, 64)
+	wait, _ := time.ParseDuration(r.URL.Query().Get("wait"))
+	deadline := time.After(wait)
+	for {
+		c.mu.Lock()
+		current, changed := c.index, c.changed
+		c.mu.Unlock()
+		if index == 0 || current > index {
+			break
+		}
+		select {
+		case <-changed:
+			continue
+		case <-deadline:
+		case <-r.Context().Done():
+			return
+		}
+		break
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w.Header().Set("X-Consul-Index", strconv.FormatInt(c.index, 10))
+	if c.value == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	_ = json.NewEncoder(w).Encode([]map[string]any{
+		{"Key": "mercator/config.yaml", "Value": c.value, "ModifyIndex": c.index},
+	})
+}
+
+func newConsulTestSource(t *testing.T, url string) Source {
+	t.Helper()
+	source, err := NewSource(&config.RemoteConfig{
+		Backend:      "consul",
+		Endpoints:    []string{url},
+		Key:          "/mercator/config.yaml",
+		Token:        "acl-token",
+		Datacenter:   "dc1",
+		Timeout:      5 * time.Second,
+		WatchTimeout: time.Second,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return source
+}
+
+func TestConsulSource_GetAndWatch(t *testing.T) {
+	consul := newFakeConsul()
+	server := httptest.NewServer(consul)
+	defer server.Close()
+
+	source := newConsulTestSource(t, server.URL)
+	ctx := context.Background()
+
+	value, err := source.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if value.Data != nil || value.Revision != 10 {
+		t.Errorf("Get() = %+v, want a missing key at index 10", value)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		consul.put([]byte("proxy: {}"))
+	}()
+	value, err = source.Watch(ctx, value.Revision)
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	if string(value.Data) != "proxy: {}" || value.Revision != 11 {
+		t.Errorf("Watch() = %+v, want the new value at index 11", value)
+	}
+
+	consul.mu.Lock()
+	last := consul.requests[len(consul.requests)-1]
+	consul.mu.Unlock()
+	if last.Header.Get("X-Consul-Token") != "acl-token" {
+		t.Errorf("X-Consul-Token = %q, want the ACL token", last.Header.Get("X-Consul-Token"))
+	}
+	if query := last.URL.Query(); query.Get("dc") != "dc1" || query.Get("index") != "10" || query.Get("wait") != "1s" {
+		t.Errorf("watch query = %v, want dc, index and wait", query)
+	}
+}
+
+func TestConsulSource_IndexReset(t *testing.T) {
+	consul := newFakeConsul()
+	consul.put([]byte("proxy: {}"))
+	server := httptest.NewServer(consul)
+	defer server.Close()
+
+	// The store was restored to an earlier index
+	value, err := newConsulTestSource(t, server.URL).Watch(context.Background(), 50)
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	if value.Revision != 0 || string(value.Data) != "proxy: {}" {
+		t.Errorf("Watch() = %+v, want the value with the index reset", value)
+	}
+}
+
+func TestConsulSource_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "ACL not found", http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	if _, err := newConsulTestSource(t, server.URL).Get(context.Background()); err == nil {
+		t.Error("Get() succeeded, want the status error")
+	}
+}
@@ -0,0 +1,27 @@
+// Package remote loads and watches the proxy configuration from a key in
+// etcd or Consul KV.
+//
+// Both stores are accessed over HTTP, without a client library: etcd
+// through its v3 JSON gateway (range and watch requests), and Consul
+// through the KV API with blocking queries. A Source reads and watches a
+// single key; the Loader parses its value as a configuration document and
+// falls back to the local configuration file, or to a cache of the last
+// valid remote document, when the store is unreachable.
+//
+// Changes are delivered as validated configurations. Invalid documents and
+// deleted keys are logged and ignored, so the running configuration is
+// only ever replaced by a valid one.
+//
+// # Usage
+//
+//	loader, err := remote.NewLoader(localConfig, httpClient, logger)
+//
+//	cfg, err := loader.Load(ctx)
+//	if err != nil {
+//	    cfg, origin = loader.Fallback()
+//	}
+//
+//	for cfg := range loader.Watch(ctx) {
+//	    // apply cfg
+//	}
+package remote
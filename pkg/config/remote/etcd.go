@@ -0,0 +1,233 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"mercator-hq/jupiter/pkg/config"
+)
+
+// etcdSource reads a key through the etcd v3 JSON gateway. Keys, values
+// and 64-bit integers are base64 and decimal strings in the gateway API.
+type etcdSource struct {
+	endpoints    *endpoints
+	key          string
+	username     string
+	password     string
+	timeout      time.Duration
+	watchTimeout time.Duration
+
+	// mu guards the authentication token
+	mu    sync.Mutex
+	token string
+}
+
+func newEtcdSource(cfg *config.RemoteConfig, endpoints *endpoints) *etcdSource {
+	return &etcdSource{
+		endpoints:    endpoints,
+		key:          base64.StdEncoding.EncodeToString([]byte(cfg.Key)),
+		username:     cfg.Username,
+		password:     cfg.Password,
+		timeout:      cfg.Timeout,
+		watchTimeout: cfg.WatchTimeout,
+	}
+}
+
+// etcdHeader is the response header of the gateway API.
+type etcdHeader struct {
+	Revision int64 `json:"revision,string"`
+}
+
+// etcdRangeResponse is the body of a range response.
+type etcdRangeResponse struct {
+	Header etcdHeader `json:"header"`
+	KVs    []struct {
+		Value string `json:"value"`
+	} `json:"kvs"`
+}
+
+// etcdWatchResponse is a message of a watch stream.
+type etcdWatchResponse struct {
+	Result *struct {
+		Created  bool              `json:"created"`
+		Canceled bool              `json:"canceled"`
+		Events   []json.RawMessage `json:"events"`
+	} `json:"result"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// String describes the key.
+func (s *etcdSource) String() string {
+	key, _ := base64.StdEncoding.DecodeString(s.key)
+	return "etcd key " + string(key)
+}
+
+// Get returns the current value of the key.
+func (s *etcdSource) Get(ctx context.Context) (*Value, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	resp, err := s.post(ctx, "/v3/kv/range", map[string]string{"key": s.key})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body etcdRangeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode etcd range response: %w", err)
+	}
+	value := &Value{Revision: body.Header.Revision}
+	if len(body.KVs) > 0 {
+		value.Data, err = base64.StdEncoding.DecodeString(body.KVs[0].Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode etcd value: %w", err)
+		}
+	}
+	return value, nil
+}
+
+// Watch waits for a change of the key after revision, then returns its
+// current value. The value is read again rather than taken from the
+// watch events, which also covers watches canceled by a compaction.
+func (s *etcdSource) Watch(ctx context.Context, revision int64) (*Value, error) {
+	if revision > 0 {
+		watchCtx, cancel := context.WithTimeout(ctx, s.watchTimeout)
+		err := s.watch(watchCtx, revision+1)
+		expired := watchCtx.Err() != nil
+		cancel()
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if err != nil && !expired {
+			return nil, err
+		}
+	}
+	return s.Get(ctx)
+}
+
+// watch opens a watch stream from revision, and returns when the key
+// changes or the stream ends.
+func (s *etcdSource) watch(ctx context.Context, revision int64) error {
+	resp, err := s.post(ctx, "/v3/watch", map[string]any{
+		"create_request": map[string]string{
+			"key":            s.key,
+			"start_revision": fmt.Sprint(revision),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var message etcdWatchResponse
+		if err := decoder.Decode(&message); err != nil {
+			return fmt.Errorf("etcd watch stream failed: %w", err)
+		}
+		if message.Error != nil {
+			return fmt.Errorf("etcd watch failed: %s", message.Error.Message)
+		}
+		if message.Result != nil && (len(message.Result.Events) > 0 || message.Result.Canceled) {
+			return nil
+		}
+	}
+}
+
+// post sends a gateway request, authenticating first if credentials are
+// configured. An expired token is renewed once.
+func (s *etcdSource) post(ctx context.Context, path string, body any) (*http.Response, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode etcd request: %w", err)
+	}
+
+	for attempt := 0; ; attempt++ {
+		token, err := s.authenticate(ctx)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := s.endpoints.do(ctx, func(ctx context.Context, baseURL string) (*http.Request, error) {
+			req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+path, bytes.NewReader(payload))
+			if err != nil {
+				return nil, fmt.Errorf("failed to create etcd request: %w", err)
+			}
+			req.Header.Set("Content-Type", "application/json")
+			if token != "" {
+				req.Header.Set("Authorization", token)
+			}
+			return req, nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode == http.StatusOK {
+			return resp, nil
+		}
+		if resp.StatusCode == http.StatusUnauthorized && token != "" && attempt == 0 {
+			resp.Body.Close()
+			s.mu.Lock()
+			s.token = ""
+			s.mu.Unlock()
+			continue
+		}
+		err = statusError(resp, "etcd")
+		resp.Body.Close()
+		return nil, err
+	}
+}
+
+// authenticate returns the authentication token, requesting one if
+// credentials are configured and no token is held.
+func (s *etcdSource) authenticate(ctx context.Context) (string, error) {
+	if s.username == "" {
+		return "", nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != "" {
+		return s.token, nil
+	}
+
+	payload, err := json.Marshal(map[string]string{"name": s.username, "password": s.password})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode etcd authentication: %w", err)
+	}
+	resp, err := s.endpoints.do(ctx, func(ctx context.Context, baseURL string) (*http.Request, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/v3/auth/authenticate", bytes.NewReader(payload))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create etcd request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		return req, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("etcd authentication failed: %w", statusError(resp, "etcd"))
+	}
+
+	var body struct {
+		Token string `json:"token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("failed to decode etcd authentication response: %w", err)
+	}
+	if body.Token == "" {
+		return "", errors.New("etcd authentication returned no token")
+	}
+	s.token = body.Token
+	return s.token, nil
+}
@@ -0,0 +1,194 @@
+package remote
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/config"
+)
+
+// fakeEtcd is an etcd v3 JSON gateway holding a single key.
+type fakeEtcd struct {
+	mu       sync.Mutex
+	value    []byte
+	revision int64
+	changed  chan struct{}
+
+	// token, if set, is required in the Authorization header
+	token  string
+	issued int
+}
+
+func newFakeEtcd() *fakeEtcd {
+	return &fakeEtcd{revision: 1, changed: make(chan struct{})}
+}
+
+func (e *fakeEtcd) put(value []byte) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.value = value
+	e.revision++
+	close(e.changed)
+	e.changed = make(chan struct{})
+}
+
+func (e *fakeEtcd) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/v3/auth/authenticate" {
+		var body struct{ Name, Password string }
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if body.Name != "mercator" || body.Password != "secret" {
+			http.Error(w, `{"message":"authentication failed"}`, http.StatusBadRequest)
+			return
+		}
+		e.mu.Lock()
+		e.issued++
+		e.token = fmt.Sprintf("token-%d", e.issued)
+		e.mu.Unlock()
+		fmt.Fprintf(w, `{"token":"token-%d"}`, e.issued)
+		return
+	}
+
+	e.mu.Lock()
+	token := e.token
+	e.mu.Unlock()
+	if token != "" && r.Header.Get("Authorization") != token {
+		http.Error(w, `{"message":"invalid auth token"}`, http.StatusUnauthorized)
+		return
+	}
+
+	switch r.URL.Path {
+	case "/v3/kv/range":
+		e.mu.Lock()
+		defer e.mu.Unlock()
+		if e.value == nil {
+			fmt.Fprintf(w, `{"header":{"revision":"%d"}}`, e.revision)
+			return
+		}
+		fmt.Fprintf(w, `{"header":{"revision":"%d"},"kvs":[{"value":%q}],"count":"1"}`,
+			e.revision, base64.StdEncoding.EncodeToString(e.value))
+	case "/v3/watch":
+		var body struct {
+			CreateRequest struct {
+				StartRevision int64 `json:"start_revision,string"`
+			} `json:"create_request"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		fmt.Fprint(w, `{"result":{"header":{"revision":"1"},"created":true}}`+"\n")
+		w.(http.Flusher).Flush()
+		for {
+			e.mu.Lock()
+			revision, changed := e.revision, e.changed
+			e.mu.Unlock()
+			if revision >= body.CreateRequest.StartRevision {
+				fmt.Fprint(w, `{"result":{"events":[{"kv":{}}]}}`+"\n")
+				return
+			}
+			select {
+			case <-changed:
+			case <-r.Context().Done():
+				return
+			}
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newEtcdTestSource(t *testing.T, cfg config.RemoteConfig, endpoints ...string) Source {
+	t.Helper()
+	cfg.Backend = "etcd"
+	cfg.Endpoints = endpoints
+	cfg.Key = "mercator/config.yaml"
+	cfg.Timeout = 5 * time.Second
+	if cfg.WatchTimeout == 0 {
+		cfg.WatchTimeout = 5 * time.Second
+	}
+	source, err := NewSource(&cfg, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return source
+}
+
+func TestEtcdSource_GetAndWatch(t *testing.T) {
+	etcd := newFakeEtcd()
+	server := httptest.NewServer(etcd)
+	defer server.Close()
+
+	// The first endpoint is unreachable
+	source := newEtcdTestSource(t, config.RemoteConfig{}, "http://127.0.0.1:1", server.URL)
+	ctx := context.Background()
+
+	value, err := source.Get(ctx)
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if value.Data != nil || value.Revision != 1 {
+		t.Errorf("Get() = %+v, want a missing key at revision 1", value)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		etcd.put([]byte("proxy: {}"))
+	}()
+	value, err = source.Watch(ctx, value.Revision)
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	if string(value.Data) != "proxy: {}" || value.Revision != 2 {
+		t.Errorf("Watch() = %+v, want the new value at revision 2", value)
+	}
+}
+
+func TestEtcdSource_WatchTimeout(t *testing.T) {
+	etcd := newFakeEtcd()
+	etcd.put([]byte("proxy: {}"))
+	server := httptest.NewServer(etcd)
+	defer server.Close()
+
+	source := newEtcdTestSource(t, config.RemoteConfig{WatchTimeout: 100 * time.Millisecond}, server.URL)
+	value, err := source.Watch(context.Background(), 2)
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+	if string(value.Data) != "proxy: {}" || value.Revision != 2 {
+		t.Errorf("Watch() = %+v, want the unchanged value", value)
+	}
+}
+
+func TestEtcdSource_Authentication(t *testing.T) {
+	etcd := newFakeEtcd()
+	etcd.put([]byte("proxy: {}"))
+	etcd.token = "expired"
+	server := httptest.NewServer(etcd)
+	defer server.Close()
+
+	source := newEtcdTestSource(t, config.RemoteConfig{Username: "mercator", Password: "secret"}, server.URL)
+	if _, err := source.Get(context.Background()); err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+
+	// An expired token is renewed
+	etcd.mu.Lock()
+	etcd.token = "rotated"
+	etcd.mu.Unlock()
+	value, err := source.Get(context.Background())
+	if err != nil {
+		t.Fatalf("Get() with an expired token error = %v", err)
+	}
+	if string(value.Data) != "proxy: {}" || etcd.issued != 2 {
+		t.Errorf("Get() = %+v after %d tokens, want the value after 2", value, etcd.issued)
+	}
+
+	source = newEtcdTestSource(t, config.RemoteConfig{Username: "mercator", Password: "wrong"}, server.URL)
+	if _, err := source.Get(context.Background()); err == nil {
+		t.Error("Get() with invalid credentials succeeded")
+	}
+}
@@ -0,0 +1,213 @@
+package remote
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"mercator-hq/jupiter/pkg/config"
+)
+
+// Loader loads the configuration from a remote source, and watches it for
+// changes.
+type Loader struct {
+	source Source
+	local  *config.Config
+	cfg    *config.RemoteConfig
+	logger *slog.Logger
+
+	// data and revision are the last remote value seen; they are only
+	// used by Load and the watch goroutine, never concurrently.
+	data     []byte
+	revision int64
+}
+
+// NewLoader creates a loader for the remote section of local, the
+// configuration loaded from the local file. Requests are sent with
+// client, or http.DefaultClient if nil.
+func NewLoader(local *config.Config, client *http.Client, logger *slog.Logger) (*Loader, error) {
+	source, err := NewSource(&local.Remote, client)
+	if err != nil {
+		return nil, err
+	}
+	return newLoader(source, local, logger), nil
+}
+
+func newLoader(source Source, local *config.Config, logger *slog.Logger) *Loader {
+	if logger == nil {
+		logger = slog.Default()
+	}
+	return &Loader{
+		source: source,
+		local:  local,
+		cfg:    &local.Remote,
+		logger: logger.With("component", "config.remote", "source", source.String()),
+	}
+}
+
+// Source returns the remote source.
+func (l *Loader) Source() Source {
+	return l.source
+}
+
+// Load reads and parses the remote configuration. It returns an error if
+// the store is unreachable, the key does not exist, or the configuration
+// is invalid; Fallback then returns the configuration to use instead.
+func (l *Loader) Load(ctx context.Context) (*config.Config, error) {
+	value, err := l.source.Get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	l.revision = value.Revision
+	l.data = value.Data
+	if value.Data == nil {
+		return nil, fmt.Errorf("%s does not exist", l.source)
+	}
+
+	cfg, err := l.parse(value.Data)
+	if err != nil {
+		return nil, err
+	}
+	l.saveCache(value.Data)
+	return cfg, nil
+}
+
+// Fallback returns the configuration to use when the remote one cannot be
+// loaded: the cached copy of the last valid remote configuration if there
+// is one, or else the local configuration. It also returns the file the
+// configuration was read from, or "" for the local configuration.
+func (l *Loader) Fallback() (*config.Config, string) {
+	if l.cfg.CacheFile != "" {
+		data, err := os.ReadFile(l.cfg.CacheFile)
+		var cfg *config.Config
+		if err == nil {
+			cfg, err = l.parse(data)
+		}
+		if err == nil {
+			// The watch only reports a change from the cached copy
+			l.data = data
+			return cfg, l.cfg.CacheFile
+		}
+		if !os.IsNotExist(err) {
+			l.logger.Warn("ignoring remote configuration cache", "file", l.cfg.CacheFile, "error", err)
+		}
+	}
+	return l.local, ""
+}
+
+// Watch watches the remote configuration until ctx is done, and sends each
+// new valid configuration on the returned channel. Only the latest
+// configuration is kept if the receiver falls behind. Changes are delayed
+// by a random duration up to the configured jitter.
+func (l *Loader) Watch(ctx context.Context) <-chan *config.Config {
+	changes := make(chan *config.Config, 1)
+	go l.watch(ctx, changes)
+	return changes
+}
+
+func (l *Loader) watch(ctx context.Context, changes chan *config.Config) {
+	missing := false
+	for ctx.Err() == nil {
+		value, err := l.source.Watch(ctx, l.revision)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			l.logger.Warn("failed to watch remote configuration", "error", err, "retry_in", l.cfg.RetryInterval)
+			if !sleep(ctx, l.cfg.RetryInterval) {
+				return
+			}
+			continue
+		}
+		l.revision = value.Revision
+
+		if value.Data == nil {
+			if !missing {
+				l.logger.Warn("remote configuration key does not exist, keeping the current configuration")
+			}
+			missing = true
+			continue
+		}
+		missing = false
+		if bytes.Equal(value.Data, l.data) {
+			continue
+		}
+		l.data = value.Data
+
+		cfg, err := l.parse(value.Data)
+		if err != nil {
+			l.logger.Error("ignoring invalid remote configuration", "revision", value.Revision, "error", err)
+			continue
+		}
+		l.saveCache(value.Data)
+
+		if l.cfg.Jitter > 0 && !sleep(ctx, rand.N(l.cfg.Jitter)) {
+			return
+		}
+		l.logger.Info("remote configuration changed", "revision", value.Revision)
+
+		select {
+		case <-changes:
+		default:
+		}
+		changes <- cfg
+	}
+}
+
+// parse loads a remote configuration document, keeping the remote section
+// of the local configuration.
+func (l *Loader) parse(data []byte) (*config.Config, error) {
+	cfg, err := config.ParseConfigWithEnvOverrides(data)
+	if err != nil {
+		return nil, err
+	}
+	cfg.Remote = l.local.Remote
+	return cfg, nil
+}
+
+// saveCache writes a valid remote configuration to the cache file.
+func (l *Loader) saveCache(data []byte) {
+	if l.cfg.CacheFile == "" {
+		return
+	}
+	if err := writeFileAtomic(l.cfg.CacheFile, data); err != nil {
+		l.logger.Warn("failed to write remote configuration cache", "file", l.cfg.CacheFile, "error", err)
+	}
+}
+
+// writeFileAtomic writes data to a temporary file and renames it to path.
+// The file is only readable by the owner, as configurations hold secrets.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// sleep waits for d, and reports whether ctx is still active.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
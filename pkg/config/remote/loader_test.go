@@ -0,0 +1,184 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/config"
+)
+
+const testConfig = `
+proxy:
+  listen_address: "127.0.0.1:%s"
+providers:
+  openai:
+    base_url: "https://api.openai.com/v1"
+    api_key: "test-key"
+remote:
+  backend: consul
+  endpoints: ["http://elsewhere:8500"]
+  key: other
+`
+
+func testDocument(port string) []byte {
+	return []byte(fmt.Sprintf(testConfig, port))
+}
+
+// memorySource is a Source whose value is set by the test.
+type memorySource struct {
+	mu       sync.Mutex
+	value    *Value
+	err      error
+	changed  chan struct{}
+	revision int64
+}
+
+func newMemorySource() *memorySource {
+	return &memorySource{value: &Value{}, changed: make(chan struct{})}
+}
+
+func (s *memorySource) set(data []byte, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.revision++
+	s.value = &Value{Data: data, Revision: s.revision}
+	s.err = err
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (s *memorySource) Get(ctx context.Context) (*Value, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.value, s.err
+}
+
+func (s *memorySource) Watch(ctx context.Context, revision int64) (*Value, error) {
+	s.mu.Lock()
+	value, err, changed := s.value, s.err, s.changed
+	s.mu.Unlock()
+	if revision == 0 || value.Revision > revision {
+		return value, err
+	}
+	select {
+	case <-changed:
+		return s.Get(ctx)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (s *memorySource) String() string {
+	return "memory"
+}
+
+func newTestLoader(t *testing.T, source Source, cacheFile string) *Loader {
+	t.Helper()
+	local, err := config.ParseConfigWithEnvOverrides(testDocument("8080"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	local.Remote = config.RemoteConfig{
+		Backend:       "etcd",
+		Endpoints:     []string{"http://etcd:2379"},
+		Key:           "mercator/config.yaml",
+		RetryInterval: 10 * time.Millisecond,
+		CacheFile:     cacheFile,
+	}
+	return newLoader(source, local, nil)
+}
+
+func TestLoader_Load(t *testing.T) {
+	source := newMemorySource()
+	source.set(testDocument("9000"), nil)
+	cacheFile := filepath.Join(t.TempDir(), "remote.yaml")
+	loader := newTestLoader(t, source, cacheFile)
+
+	cfg, err := loader.Load(context.Background())
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.Proxy.ListenAddress != "127.0.0.1:9000" {
+		t.Errorf("listen address = %q, want the remote one", cfg.Proxy.ListenAddress)
+	}
+	if cfg.Remote.Backend != "etcd" || cfg.Remote.Key != "mercator/config.yaml" {
+		t.Errorf("remote = %+v, want the local remote section", cfg.Remote)
+	}
+	if data, err := os.ReadFile(cacheFile); err != nil || string(data) != string(testDocument("9000")) {
+		t.Errorf("cache = %q (%v), want the remote document", data, err)
+	}
+}
+
+func TestLoader_Fallback(t *testing.T) {
+	source := newMemorySource()
+	source.set(nil, errors.New("connection refused"))
+	cacheFile := filepath.Join(t.TempDir(), "remote.yaml")
+	loader := newTestLoader(t, source, cacheFile)
+
+	if _, err := loader.Load(context.Background()); err == nil {
+		t.Fatal("Load() succeeded with an unreachable store")
+	}
+	cfg, origin := loader.Fallback()
+	if origin != "" || cfg.Proxy.ListenAddress != "127.0.0.1:8080" {
+		t.Errorf("Fallback() = %q from %q, want the local configuration", cfg.Proxy.ListenAddress, origin)
+	}
+
+	if err := os.WriteFile(cacheFile, testDocument("9000"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, origin = loader.Fallback()
+	if origin != cacheFile || cfg.Proxy.ListenAddress != "127.0.0.1:9000" {
+		t.Errorf("Fallback() = %q from %q, want the cached configuration", cfg.Proxy.ListenAddress, origin)
+	}
+
+	// An invalid cache is ignored
+	if err := os.WriteFile(cacheFile, []byte("proxy: [unclosed"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, origin = loader.Fallback(); origin != "" {
+		t.Errorf("Fallback() from %q, want the local configuration", origin)
+	}
+}
+
+func TestLoader_Watch(t *testing.T) {
+	source := newMemorySource()
+	source.set(testDocument("9000"), nil)
+	loader := newTestLoader(t, source, "")
+
+	if _, err := loader.Load(context.Background()); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	changes := loader.Watch(ctx)
+
+	// Errors, deleted keys and invalid documents are skipped
+	source.set(nil, errors.New("connection refused"))
+	source.set(nil, nil)
+	source.set([]byte("proxy: [unclosed"), nil)
+	source.set(testDocument("9001"), nil)
+
+	select {
+	case cfg := <-changes:
+		if cfg.Proxy.ListenAddress != "127.0.0.1:9001" {
+			t.Errorf("change = %q, want the valid document", cfg.Proxy.ListenAddress)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change received")
+	}
+
+	// An unchanged document is not sent again
+	source.set(testDocument("9001"), nil)
+	select {
+	case cfg := <-changes:
+		t.Errorf("unexpected change %q", cfg.Proxy.ListenAddress)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
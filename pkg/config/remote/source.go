@@ -0,0 +1,100 @@
+package remote
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"mercator-hq/jupiter/pkg/config"
+)
+
+// Value is the value of the configuration key at a revision of the store.
+type Value struct {
+	// Data is the value, or nil if the key does not exist.
+	Data []byte
+
+	// Revision is the store revision (etcd) or index (Consul) the value
+	// was read at, from which to watch for changes.
+	Revision int64
+}
+
+// Source reads and watches a configuration key.
+type Source interface {
+	// Get returns the current value of the key.
+	Get(ctx context.Context) (*Value, error)
+
+	// Watch blocks until the key changes after revision, or until the
+	// watch timeout, and returns the current value. A revision of 0
+	// returns immediately.
+	Watch(ctx context.Context, revision int64) (*Value, error)
+
+	// String describes the key, e.g. "etcd key mercator/config.yaml".
+	String() string
+}
+
+// NewSource creates the source of the configured backend. Requests are
+// sent with client, or http.DefaultClient if nil.
+func NewSource(cfg *config.RemoteConfig, client *http.Client) (Source, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	endpoints := &endpoints{urls: make([]string, len(cfg.Endpoints)), client: client}
+	for i, endpoint := range cfg.Endpoints {
+		endpoints.urls[i] = strings.TrimSuffix(endpoint, "/")
+	}
+
+	switch cfg.Backend {
+	case "etcd":
+		return newEtcdSource(cfg, endpoints), nil
+	case "consul":
+		return newConsulSource(cfg, endpoints), nil
+	default:
+		return nil, fmt.Errorf("unsupported remote backend: %s", cfg.Backend)
+	}
+}
+
+// endpoints sends requests to the first reachable endpoint of a store,
+// starting with the last one that answered.
+type endpoints struct {
+	urls    []string
+	client  *http.Client
+	current atomic.Int32
+}
+
+// do sends the request built by newRequest for each endpoint in turn,
+// until one answers.
+func (e *endpoints) do(ctx context.Context, newRequest func(ctx context.Context, baseURL string) (*http.Request, error)) (*http.Response, error) {
+	start := int(e.current.Load())
+	var lastErr error
+	for i := range e.urls {
+		index := (start + i) % len(e.urls)
+		req, err := newRequest(ctx, e.urls[index])
+		if err != nil {
+			return nil, err
+		}
+		resp, err := e.client.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			lastErr = fmt.Errorf("request to %s failed: %w", req.URL.Redacted(), err)
+			continue
+		}
+		e.current.Store(int32(index))
+		return resp, nil
+	}
+	return nil, lastErr
+}
+
+// statusError returns the error of an unexpected response status.
+func statusError(resp *http.Response, store string) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	message := strings.TrimSpace(string(body))
+	if message == "" {
+		return fmt.Errorf("%s returned status %d", store, resp.StatusCode)
+	}
+	return fmt.Errorf("%s returned status %d: %s", store, resp.StatusCode, message)
+}
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// yamlField is a struct field decoded from a YAML key.
+type yamlField struct {
+	name  string
+	field reflect.StructField
+}
+
+// yamlFields returns the fields of struct type t by YAML key, in
+// declaration order. Keys are the yaml tag names, or the lowercased field
+// names as decoded by yaml.v3.
+func yamlFields(t reflect.Type) []yamlField {
+	var fields []yamlField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields = append(fields, yamlField{name: name, field: field})
+	}
+	return fields
+}
+
+// JSONSchema returns a JSON Schema (draft 2020-12) of the configuration
+// file, for editor completion and CI checks. It describes the structure
+// and value types of the configuration; the rules checked by Validate,
+// such as required fields and allowed values, are not included.
+//
+// Unknown keys are rejected, durations are strings such as "30s" (or
+// nanoseconds), and values may be ${NAME} or ${secret:NAME} references.
+func JSONSchema() map[string]any {
+	schema := typeSchema(reflect.TypeOf(Config{}), map[reflect.Type]bool{})
+	schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	schema["title"] = "Mercator Jupiter configuration"
+	return schema
+}
+
+// typeSchema returns the schema of values of type t. visiting holds the
+// struct types being described, to stop at recursive types.
+func typeSchema(t reflect.Type, visiting map[reflect.Type]bool) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == reflect.TypeOf(time.Duration(0)) {
+		return map[string]any{
+			"type":        []string{"string", "integer"},
+			"pattern":     `^(\$\{[^}]+\}|([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+|0)$`,
+			"description": "duration, e.g. \"30s\" or \"1h30m\"",
+		}
+	}
+
+	if t == reflect.TypeOf(time.Time{}) {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return referenceOr("boolean")
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return referenceOr("integer")
+	case reflect.Float32, reflect.Float64:
+		return referenceOr("number")
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": typeSchema(t.Elem(), visiting)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": typeSchema(t.Elem(), visiting)}
+	case reflect.Struct:
+		if visiting[t] {
+			return map[string]any{"type": "object"}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		properties := make(map[string]any)
+		for _, f := range yamlFields(t) {
+			properties[f.name] = typeSchema(f.field.Type, visiting)
+		}
+		return map[string]any{
+			"type":                 "object",
+			"properties":           properties,
+			"additionalProperties": false,
+		}
+	default:
+		// Interface values accept anything
+		return map[string]any{}
+	}
+}
+
+// referenceOr returns the schema of a scalar type that may also be written
+// as a reference, resolved before decoding.
+func referenceOr(typ string) map[string]any {
+	return map[string]any{
+		"anyOf": []any{
+			map[string]any{"type": typ},
+			map[string]any{"type": "string", "pattern": `^\$\{[^}]+\}$`},
+		},
+	}
+}
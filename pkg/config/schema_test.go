@@ -0,0 +1,33 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestJSONSchema(t *testing.T) {
+	data, err := json.Marshal(JSONSchema())
+	if err != nil {
+		t.Fatalf("schema does not marshal: %v", err)
+	}
+	var schema map[string]any
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	properties := schema["properties"].(map[string]any)
+	proxy, ok := properties["proxy"].(map[string]any)
+	if !ok || proxy["additionalProperties"] != false {
+		t.Fatalf("proxy = %v, want a closed object", proxy)
+	}
+	timeout := proxy["properties"].(map[string]any)["read_timeout"].(map[string]any)
+	if timeout["pattern"] == nil {
+		t.Errorf("read_timeout = %v, want a duration", timeout)
+	}
+
+	providers := properties["providers"].(map[string]any)
+	provider, ok := providers["additionalProperties"].(map[string]any)
+	if !ok || provider["properties"].(map[string]any)["base_url"] == nil {
+		t.Errorf("providers = %v, want a map of provider objects", providers)
+	}
+}
@@ -2,9 +2,15 @@ package config
 
 import (
 	"fmt"
+	"maps"
+	"net"
 	"net/url"
+	"path"
+	"regexp"
+	"slices"
 	"strings"
 	"time"
+	"unicode"
 )
 
 // FieldError represents a validation error for a specific configuration field.
@@ -72,6 +78,57 @@ func Validate(cfg *Config) error {
 	// Validate security configuration
 	errs = append(errs, validateSecurity(&cfg.Security)...)
 
+	// Validate the admin listener against the proxy listener
+	errs = append(errs, validateAdminListener(cfg)...)
+
+	// Validate API key priority classes against the priority tiers
+	errs = append(errs, validatePriorityClasses(cfg)...)
+
+	// Validate the token estimator against the providers
+	errs = append(errs, validateTokens(cfg)...)
+
+	// Validate custom PII types
+	errs = append(errs, validateCustomPII(cfg.Processing.Content.PII.Custom)...)
+
+	// Validate secret detection
+	errs = append(errs, validateSecretDetection(&cfg.Processing.Content.Secrets)...)
+
+	// Validate sensitive content categories
+	errs = append(errs, validateSensitiveCategories(&cfg.Processing.Content.Sensitive)...)
+
+	// Validate the prompt injection classifier
+	errs = append(errs, validateInjectionClassifier(&cfg.Processing.Content.Injection.Classifier)...)
+
+	// Validate the injection pattern feed
+	errs = append(errs, validateInjectionFeed(&cfg.Processing.Content.Injection.Feed)...)
+
+	// Validate the external DLP service
+	errs = append(errs, validateDLP(&cfg.Processing.Content.DLP)...)
+
+	// Validate topic similarity
+	errs = append(errs, validateTopics(&cfg.Processing.Content.Topics)...)
+
+	// Validate named entity recognition
+	errs = append(errs, validateEntities(&cfg.Processing.Content.Entities)...)
+
+	// Validate data classification
+	errs = append(errs, validateClassification(&cfg.Processing.Content.Classification)...)
+
+	// Validate model pricing and the pricing catalog
+	errs = append(errs, validatePricing(cfg.Processing.Costs.Pricing, "processing.costs.pricing")...)
+	errs = append(errs, validatePricingCatalog(&cfg.Processing.Costs.Catalog)...)
+
+	// Validate the risk score model
+	errs = append(errs, validateRisk(&cfg.Processing.Risk, "processing.risk")...)
+
+	// Validate custom analyzers
+	errs = append(errs, validateAnalyzers(cfg.Processing.Analyzers)...)
+
+	// Validate the remote configuration source
+	if cfg.Remote.Backend != "" {
+		errs = append(errs, validateRemote(&cfg.Remote)...)
+	}
+
 	if len(errs) > 0 {
 		return ValidationError{Errors: errs}
 	}
@@ -125,6 +182,37 @@ func validateProxy(cfg *ProxyConfig) []FieldError {
 		})
 	}
 
+	// Validate cost allocation tags
+	if cfg.CostAllocation.Enabled {
+		seen := make(map[string]bool, len(cfg.CostAllocation.AllowedTags))
+		for i, tag := range cfg.CostAllocation.AllowedTags {
+			field := fmt.Sprintf("proxy.cost_allocation.allowed_tags[%d]", i)
+			switch {
+			case tag == "" || len(tag) > 128 || strings.IndexFunc(tag, unicode.IsSpace) >= 0:
+				errs = append(errs, FieldError{
+					Field:   field,
+					Message: fmt.Sprintf("invalid tag %q: must be 1-128 characters without whitespace", tag),
+				})
+			case seen[tag]:
+				errs = append(errs, FieldError{
+					Field:   field,
+					Message: fmt.Sprintf("duplicate tag %q", tag),
+				})
+			}
+			seen[tag] = true
+		}
+	}
+
+	// Validate the streaming mode
+	switch cfg.Streaming.Mode {
+	case "", "passthrough", "decode":
+	default:
+		errs = append(errs, FieldError{
+			Field:   "proxy.streaming.mode",
+			Message: fmt.Sprintf("invalid streaming mode %q (must be passthrough or decode)", cfg.Streaming.Mode),
+		})
+	}
+
 	return errs
 }
 
@@ -143,8 +231,22 @@ func validateProviders(providers map[string]ProviderConfig) []FieldError {
 	for name, provider := range providers {
 		prefix := fmt.Sprintf("providers.%s", name)
 
-		// Validate base URL
-		if provider.BaseURL == "" {
+		// Validate type
+		providerType := provider.Type
+		if providerType == "" {
+			providerType = name
+		}
+		validTypes := map[string]bool{"openai": true, "anthropic": true, "bedrock": true, "vertex": true, "gemini": true, "tgi": true, "replicate": true, "generic": true}
+		if provider.Type != "" && !validTypes[provider.Type] {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".type",
+				Message: fmt.Sprintf("invalid type %q: must be 'openai', 'anthropic', 'bedrock', 'vertex', 'gemini', 'tgi', 'replicate', or 'generic'", provider.Type),
+			})
+		}
+
+		// Validate base URL (Bedrock and Vertex derive it from the region,
+		// Gemini and Replicate have a single endpoint)
+		if provider.BaseURL == "" && providerType != "bedrock" && providerType != "vertex" && providerType != "gemini" && providerType != "replicate" {
 			errs = append(errs, FieldError{
 				Field:   prefix + ".base_url",
 				Message: "base URL is required",
@@ -184,6 +286,93 @@ func validateProviders(providers map[string]ProviderConfig) []FieldError {
 				Message: "max retries exceeds reasonable limit (10)",
 			})
 		}
+
+		// Validate Bedrock API
+		if provider.API != "" && provider.API != "converse" && provider.API != "invoke" {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".api",
+				Message: fmt.Sprintf("invalid API %q: must be 'converse' or 'invoke'", provider.API),
+			})
+		}
+
+		// Validate TGI and Replicate prompt format
+		if provider.PromptFormat != "" && provider.PromptFormat != "chatml" && provider.PromptFormat != "llama3" && provider.PromptFormat != "mistral" {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".prompt_format",
+				Message: fmt.Sprintf("invalid prompt format %q: must be 'chatml', 'llama3', or 'mistral'", provider.PromptFormat),
+			})
+		}
+
+		// Validate egress proxy
+		if provider.Proxy.URL != "" {
+			if u, err := url.Parse(provider.Proxy.URL); err != nil {
+				errs = append(errs, FieldError{
+					Field:   prefix + ".proxy.url",
+					Message: fmt.Sprintf("invalid URL format: %v", err),
+				})
+			} else if u.Scheme != "http" && u.Scheme != "https" && u.Scheme != "socks5" {
+				errs = append(errs, FieldError{
+					Field:   prefix + ".proxy.url",
+					Message: fmt.Sprintf("invalid proxy scheme %q: must be 'http', 'https', or 'socks5'", u.Scheme),
+				})
+			} else if u.Host == "" {
+				errs = append(errs, FieldError{
+					Field:   prefix + ".proxy.url",
+					Message: "proxy host is required",
+				})
+			}
+		} else if len(provider.Proxy.NoProxy) > 0 {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".proxy.no_proxy",
+				Message: "no_proxy requires a proxy URL",
+			})
+		}
+
+		// Validate Gemini safety settings
+		validThresholds := map[string]bool{
+			"BLOCK_NONE":             true,
+			"BLOCK_LOW_AND_ABOVE":    true,
+			"BLOCK_MEDIUM_AND_ABOVE": true,
+			"BLOCK_ONLY_HIGH":        true,
+			"OFF":                    true,
+		}
+		for category, threshold := range provider.SafetySettings {
+			if !strings.HasPrefix(category, "HARM_CATEGORY_") {
+				errs = append(errs, FieldError{
+					Field:   fmt.Sprintf("%s.safety_settings.%s", prefix, category),
+					Message: "invalid harm category: must start with 'HARM_CATEGORY_'",
+				})
+			}
+			if !validThresholds[threshold] {
+				errs = append(errs, FieldError{
+					Field:   fmt.Sprintf("%s.safety_settings.%s", prefix, category),
+					Message: fmt.Sprintf("invalid threshold %q: must be 'BLOCK_NONE', 'BLOCK_LOW_AND_ABOVE', 'BLOCK_MEDIUM_AND_ABOVE', 'BLOCK_ONLY_HIGH', or 'OFF'", threshold),
+				})
+			}
+		}
+	}
+
+	// Validate model routes: a model name is routed to a single provider
+	routed := make(map[string]string)
+	for _, name := range slices.Sorted(maps.Keys(providers)) {
+		for _, model := range slices.Sorted(maps.Keys(providers[name].Models)) {
+			field := fmt.Sprintf("providers.%s.models.%s", name, model)
+			if strings.TrimSpace(model) == "" {
+				errs = append(errs, FieldError{
+					Field:   field,
+					Message: "model name cannot be empty",
+				})
+				continue
+			}
+			if other, ok := routed[model]; ok {
+				errs = append(errs, FieldError{
+					Field:   field,
+					Message: fmt.Sprintf("model %q is already routed to provider %q", model, other),
+				})
+				continue
+			}
+			routed[model] = name
+		}
 	}
 
 	return errs
@@ -237,442 +426,2461 @@ func validatePolicy(cfg *PolicyConfig) []FieldError {
 		}
 	}
 
+	if cfg.Git.Verify.Enabled {
+		verify := &cfg.Git.Verify
+		if verify.Mode != "" && verify.Mode != "strict" && verify.Mode != "warn" {
+			errs = append(errs, FieldError{
+				Field:   "policy.git.verify.mode",
+				Message: fmt.Sprintf("invalid mode %q: must be 'strict' or 'warn'", verify.Mode),
+			})
+		}
+		if verify.GPGKeyringPath == "" && verify.SSHAllowedSignersPath == "" {
+			errs = append(errs, FieldError{
+				Field:   "policy.git.verify",
+				Message: "gpg_keyring_path or ssh_allowed_signers_path is required when verification is enabled",
+			})
+		}
+	}
+
+	if cfg.Events.Enabled {
+		errs = append(errs, validatePolicyEvents(&cfg.Events)...)
+	}
+
+	if cfg.Preview.Enabled {
+		errs = append(errs, validatePolicyPreview(cfg)...)
+	}
+
+	if len(cfg.Sources) > 0 {
+		errs = append(errs, validatePolicySources(cfg)...)
+	}
+
 	return errs
 }
 
-// validateEvidence validates evidence configuration.
-func validateEvidence(cfg *EvidenceConfig) []FieldError {
+// validatePolicyPreview validates the preview (shadow) policy configuration.
+func validatePolicyPreview(cfg *PolicyConfig) []FieldError {
 	var errs []FieldError
+	preview := &cfg.Preview
 
-	// If evidence is disabled, skip validation
-	if !cfg.Enabled {
-		return errs
+	switch {
+	case preview.FilePath == "" && preview.GitBranch == "":
+		errs = append(errs, FieldError{
+			Field:   "policy.preview",
+			Message: "file_path or git_branch is required when preview is enabled",
+		})
+	case preview.FilePath != "" && preview.GitBranch != "":
+		errs = append(errs, FieldError{
+			Field:   "policy.preview",
+			Message: "file_path and git_branch are mutually exclusive",
+		})
+	case preview.GitBranch != "" && cfg.Git.Repository == "":
+		errs = append(errs, FieldError{
+			Field:   "policy.preview.git_branch",
+			Message: "policy.git.repository is required to load a preview branch",
+		})
 	}
 
-	// Validate backend
-	validBackends := map[string]bool{"sqlite": true, "postgres": true, "s3": true}
-	if cfg.Backend == "" {
+	if preview.QueueSize < 0 {
 		errs = append(errs, FieldError{
-			Field:   "evidence.backend",
-			Message: "backend is required when evidence is enabled",
+			Field:   "policy.preview.queue_size",
+			Message: "queue size must be non-negative",
 		})
-	} else if !validBackends[cfg.Backend] {
+	}
+	if preview.History < 0 {
 		errs = append(errs, FieldError{
-			Field:   "evidence.backend",
-			Message: fmt.Sprintf("invalid backend %q: must be 'sqlite', 'postgres', or 's3'", cfg.Backend),
+			Field:   "policy.preview.history",
+			Message: "history must be non-negative",
 		})
 	}
 
-	// Validate backend-specific configuration
-	switch cfg.Backend {
-	case "sqlite":
-		if cfg.SQLite.Path == "" {
-			errs = append(errs, FieldError{
-				Field:   "evidence.sqlite.path",
-				Message: "SQLite path is required when backend is 'sqlite'",
-			})
-		}
-	case "postgres":
-		if cfg.Postgres.Host == "" {
-			errs = append(errs, FieldError{
-				Field:   "evidence.postgres.host",
-				Message: "PostgreSQL host is required when backend is 'postgres'",
-			})
-		}
-		if cfg.Postgres.Port < 1 || cfg.Postgres.Port > 65535 {
+	return errs
+}
+
+// validatePolicySources validates layered policy sources.
+func validatePolicySources(cfg *PolicyConfig) []FieldError {
+	var errs []FieldError
+
+	names := make(map[string]bool, len(cfg.Sources))
+	validTypes := map[string]bool{"file": true, "git": true, "sqlite": true}
+	for i, src := range cfg.Sources {
+		prefix := fmt.Sprintf("policy.sources[%d]", i)
+		if src.Name == "" {
 			errs = append(errs, FieldError{
-				Field:   "evidence.postgres.port",
-				Message: "PostgreSQL port must be between 1 and 65535",
+				Field:   prefix + ".name",
+				Message: "name is required",
 			})
-		}
-		if cfg.Postgres.Database == "" {
+		} else if names[src.Name] {
 			errs = append(errs, FieldError{
-				Field:   "evidence.postgres.database",
-				Message: "PostgreSQL database is required when backend is 'postgres'",
+				Field:   prefix + ".name",
+				Message: fmt.Sprintf("duplicate source name %q", src.Name),
 			})
 		}
-		if cfg.Postgres.User == "" {
+		names[src.Name] = true
+
+		if !validTypes[src.Type] {
 			errs = append(errs, FieldError{
-				Field:   "evidence.postgres.user",
-				Message: "PostgreSQL user is required when backend is 'postgres'",
+				Field:   prefix + ".type",
+				Message: fmt.Sprintf("invalid source type %q: must be 'file', 'git', or 'sqlite'", src.Type),
 			})
 		}
-		// Password can be empty if using other auth methods
-		validSSLModes := map[string]bool{"disable": true, "require": true, "verify-ca": true, "verify-full": true}
-		if !validSSLModes[cfg.Postgres.SSLMode] {
+		if (src.Type == "file" || src.Type == "sqlite") && src.Path == "" {
 			errs = append(errs, FieldError{
-				Field:   "evidence.postgres.ssl_mode",
-				Message: fmt.Sprintf("invalid SSL mode %q: must be 'disable', 'require', 'verify-ca', or 'verify-full'", cfg.Postgres.SSLMode),
+				Field:   prefix + ".path",
+				Message: fmt.Sprintf("path is required for %s sources", src.Type),
 			})
 		}
-	case "s3":
-		if cfg.S3.Bucket == "" {
+		if src.Type == "git" && cfg.Git.Repository == "" {
 			errs = append(errs, FieldError{
-				Field:   "evidence.s3.bucket",
-				Message: "S3 bucket is required when backend is 's3'",
+				Field:   prefix + ".type",
+				Message: "policy.git.repository is required for git sources",
 			})
 		}
-		if cfg.S3.Region == "" {
+		if src.PollInterval < 0 {
 			errs = append(errs, FieldError{
-				Field:   "evidence.s3.region",
-				Message: "S3 region is required when backend is 's3'",
+				Field:   prefix + ".poll_interval",
+				Message: "poll interval must be non-negative",
 			})
 		}
 	}
 
-	// Validate retention days
-	if cfg.Retention.Days < 0 {
-		errs = append(errs, FieldError{
-			Field:   "evidence.retention.days",
-			Message: "retention days must be non-negative",
-		})
-	}
-	if cfg.Retention.Days > 3650 { // 10 years is excessive
-		errs = append(errs, FieldError{
-			Field:   "evidence.retention.days",
-			Message: "retention days exceeds reasonable limit (3650 days / 10 years)",
-		})
-	}
-
 	return errs
 }
 
-// validateTelemetry validates telemetry configuration.
-func validateTelemetry(cfg *TelemetryConfig) []FieldError {
+// validatePolicyEvents validates the policy decision event bus configuration.
+func validatePolicyEvents(cfg *PolicyEventsConfig) []FieldError {
 	var errs []FieldError
 
-	// Validate logging level
-	validLevels := map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
-	if cfg.Logging.Level == "" {
+	if cfg.BufferSize < 0 {
 		errs = append(errs, FieldError{
-			Field:   "telemetry.logging.level",
-			Message: "logging level is required",
-		})
-	} else if !validLevels[cfg.Logging.Level] {
-		errs = append(errs, FieldError{
-			Field:   "telemetry.logging.level",
-			Message: fmt.Sprintf("invalid logging level %q: must be 'debug', 'info', 'warn', or 'error'", cfg.Logging.Level),
+			Field:   "policy.events.buffer_size",
+			Message: "buffer size must be non-negative",
 		})
 	}
-
-	// Validate logging format
-	validFormats := map[string]bool{"json": true, "text": true}
-	if cfg.Logging.Format == "" {
+	if cfg.Timeout < 0 {
 		errs = append(errs, FieldError{
-			Field:   "telemetry.logging.format",
-			Message: "logging format is required",
+			Field:   "policy.events.timeout",
+			Message: "timeout must be non-negative",
 		})
-	} else if !validFormats[cfg.Logging.Format] {
+	}
+	if len(cfg.Sinks) == 0 {
 		errs = append(errs, FieldError{
-			Field:   "telemetry.logging.format",
-			Message: fmt.Sprintf("invalid logging format %q: must be 'json' or 'text'", cfg.Logging.Format),
+			Field:   "policy.events.sinks",
+			Message: "at least one sink is required when events are enabled",
 		})
 	}
 
-	// Validate metrics prometheus path
-	if cfg.Metrics.Enabled && cfg.Metrics.Path == "" {
-		errs = append(errs, FieldError{
-			Field:   "telemetry.metrics.path",
-			Message: "metrics path is required when metrics are enabled",
-		})
+	validTypes := map[string]bool{"webhook": true, "kafka": true, "nats": true}
+	for i, sink := range cfg.Sinks {
+		prefix := fmt.Sprintf("policy.events.sinks[%d]", i)
+		if !validTypes[sink.Type] {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".type",
+				Message: fmt.Sprintf("invalid sink type %q: must be 'webhook', 'kafka', or 'nats'", sink.Type),
+			})
+		}
+		if sink.URL == "" {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".url",
+				Message: "url is required",
+			})
+		}
+		if sink.Type == "kafka" && sink.Topic == "" {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".topic",
+				Message: "topic is required for kafka sinks",
+			})
+		}
+		if sink.Type == "nats" && sink.Subject == "" {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".subject",
+				Message: "subject is required for nats sinks",
+			})
+		}
+		if sink.Type != "nats" && sink.CAFile != "" {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".ca_file",
+				Message: "ca_file is only supported for nats sinks",
+			})
+		}
 	}
 
-	// Validate tracing configuration
-	if cfg.Tracing.Enabled && cfg.Tracing.Endpoint == "" {
+	return errs
+}
+
+// validateEvidence validates evidence configuration.
+func validateEvidence(cfg *EvidenceConfig) []FieldError {
+	var errs []FieldError
+
+	// If evidence is disabled, skip validation
+	if !cfg.Enabled {
+		return errs
+	}
+
+	// Validate backend
+	validBackends := map[string]bool{"sqlite": true, "postgres": true, "s3": true}
+	if cfg.Backend == "" {
 		errs = append(errs, FieldError{
-			Field:   "telemetry.tracing.endpoint",
-			Message: "tracing endpoint is required when tracing is enabled",
+			Field:   "evidence.backend",
+			Message: "backend is required when evidence is enabled",
 		})
-	}
-	if cfg.Tracing.SampleRatio < 0 || cfg.Tracing.SampleRatio > 1.0 {
+	} else if !validBackends[cfg.Backend] {
 		errs = append(errs, FieldError{
-			Field:   "telemetry.tracing.sample_ratio",
-			Message: "sample ratio must be between 0.0 and 1.0",
+			Field:   "evidence.backend",
+			Message: fmt.Sprintf("invalid backend %q: must be 'sqlite', 'postgres', or 's3'", cfg.Backend),
 		})
 	}
 
-	// Validate health check configuration
-	if cfg.Health.Enabled {
-		// Validate paths are non-empty
-		if cfg.Health.LivenessPath == "" {
+	// Validate backend-specific configuration
+	switch cfg.Backend {
+	case "sqlite":
+		if cfg.SQLite.Path == "" {
 			errs = append(errs, FieldError{
-				Field:   "telemetry.health.liveness_path",
-				Message: "liveness path is required when health checks are enabled",
+				Field:   "evidence.sqlite.path",
+				Message: "SQLite path is required when backend is 'sqlite'",
 			})
 		}
-		if cfg.Health.ReadinessPath == "" {
+	case "postgres":
+		errs = append(errs, validatePostgres("evidence.postgres", &cfg.Postgres)...)
+	case "s3":
+		if cfg.S3.Bucket == "" {
 			errs = append(errs, FieldError{
-				Field:   "telemetry.health.readiness_path",
-				Message: "readiness path is required when health checks are enabled",
+				Field:   "evidence.s3.bucket",
+				Message: "S3 bucket is required when backend is 's3'",
 			})
 		}
-		if cfg.Health.VersionPath == "" {
+		if cfg.S3.Region == "" {
 			errs = append(errs, FieldError{
-				Field:   "telemetry.health.version_path",
-				Message: "version path is required when health checks are enabled",
+				Field:   "evidence.s3.region",
+				Message: "S3 region is required when backend is 's3'",
 			})
 		}
-
-		// Validate paths start with /
-		if cfg.Health.LivenessPath != "" && cfg.Health.LivenessPath[0] != '/' {
+		if cfg.S3.BatchSize < 0 {
 			errs = append(errs, FieldError{
-				Field:   "telemetry.health.liveness_path",
-				Message: "liveness path must start with /",
+				Field:   "evidence.s3.batch_size",
+				Message: "batch size must be non-negative",
 			})
 		}
-		if cfg.Health.ReadinessPath != "" && cfg.Health.ReadinessPath[0] != '/' {
+		if cfg.S3.FlushInterval < 0 {
 			errs = append(errs, FieldError{
-				Field:   "telemetry.health.readiness_path",
-				Message: "readiness path must start with /",
+				Field:   "evidence.s3.flush_interval",
+				Message: "flush interval must be non-negative",
 			})
 		}
-		if cfg.Health.VersionPath != "" && cfg.Health.VersionPath[0] != '/' {
+		if cfg.S3.Compression != "" && cfg.S3.Compression != "gzip" && cfg.S3.Compression != "none" {
 			errs = append(errs, FieldError{
-				Field:   "telemetry.health.version_path",
-				Message: "version path must start with /",
+				Field:   "evidence.s3.compression",
+				Message: fmt.Sprintf("invalid compression %q: must be 'gzip' or 'none'", cfg.S3.Compression),
+			})
+		}
+	}
+
+	errs = append(errs, validateEvidenceStream(&cfg.Stream)...)
+	errs = append(errs, validateEvidenceExportJobs(cfg.Export.Jobs)...)
+
+	if cfg.SigningKeyPath != "" && cfg.SigningKeySecret != "" {
+		errs = append(errs, FieldError{
+			Field:   "evidence.signing_key_secret",
+			Message: "signing_key_path and signing_key_secret are mutually exclusive",
+		})
+	}
+
+	if cfg.Integrity.Enabled {
+		if cfg.SigningKeyPath == "" && cfg.SigningKeySecret == "" {
+			errs = append(errs, FieldError{
+				Field:   "evidence.signing_key_path",
+				Message: "signing key is required to sign hash chain checkpoints when evidence.integrity is enabled",
+			})
+		}
+		if cfg.Integrity.CheckpointPath == "" {
+			errs = append(errs, FieldError{
+				Field:   "evidence.integrity.checkpoint_path",
+				Message: "checkpoint path is required when evidence.integrity is enabled",
+			})
+		}
+		if cfg.Integrity.CheckpointInterval < 0 {
+			errs = append(errs, FieldError{
+				Field:   "evidence.integrity.checkpoint_interval",
+				Message: "checkpoint interval must be non-negative",
+			})
+		}
+		if cfg.Integrity.CheckpointPeriod < 0 {
+			errs = append(errs, FieldError{
+				Field:   "evidence.integrity.checkpoint_period",
+				Message: "checkpoint period must be non-negative",
+			})
+		}
+	}
+
+	if cfg.Capture.Enabled {
+		if cfg.Capture.MaxBodySize < 0 {
+			errs = append(errs, FieldError{
+				Field:   "evidence.capture.max_body_size",
+				Message: "max body size must be non-negative",
+			})
+		}
+		if cfg.Capture.BlobPath == "" {
+			errs = append(errs, FieldError{
+				Field:   "evidence.capture.blob_path",
+				Message: "blob path is required when evidence.capture is enabled",
+			})
+		}
+		for i, pattern := range cfg.Capture.Models {
+			if _, err := path.Match(pattern, ""); err != nil {
+				errs = append(errs, FieldError{
+					Field:   fmt.Sprintf("evidence.capture.models[%d]", i),
+					Message: fmt.Sprintf("invalid model pattern %q: %v", pattern, err),
+				})
+			}
+		}
+	}
+
+	if cfg.Retention.LegalHolds.Enabled && cfg.Retention.LegalHolds.Path == "" {
+		errs = append(errs, FieldError{
+			Field:   "evidence.retention.legal_holds.path",
+			Message: "hold path is required when evidence.retention.legal_holds is enabled",
+		})
+	}
+
+	if cfg.Retention.Anonymize.Enabled {
+		if cfg.Retention.Anonymize.AfterDays <= 0 {
+			errs = append(errs, FieldError{
+				Field:   "evidence.retention.anonymize.after_days",
+				Message: "after days must be positive when evidence.retention.anonymize is enabled",
+			})
+		}
+		if cfg.Retention.Anonymize.KeySecret == "" {
+			errs = append(errs, FieldError{
+				Field:   "evidence.retention.anonymize.key_secret",
+				Message: "key secret is required when evidence.retention.anonymize is enabled",
+			})
+		}
+		if cfg.Backend == "s3" {
+			errs = append(errs, FieldError{
+				Field:   "evidence.retention.anonymize.enabled",
+				Message: "anonymization is not supported by the s3 backend",
+			})
+		}
+		if cfg.Retention.PruneSchedule == "" {
+			errs = append(errs, FieldError{
+				Field:   "evidence.retention.prune_schedule",
+				Message: "prune schedule is required when evidence.retention.anonymize is enabled, since anonymization runs on it",
+			})
+		}
+	}
+
+	if cfg.Erasure.Enabled {
+		if cfg.SigningKeyPath == "" && cfg.SigningKeySecret == "" {
+			errs = append(errs, FieldError{
+				Field:   "evidence.signing_key_path",
+				Message: "signing key is required to sign deletion certificates when evidence.erasure is enabled",
+			})
+		}
+		if cfg.Erasure.CertificatePath == "" {
+			errs = append(errs, FieldError{
+				Field:   "evidence.erasure.certificate_path",
+				Message: "certificate path is required when evidence.erasure is enabled",
+			})
+		}
+	}
+
+	if cfg.WriteOnce.Enabled {
+		if cfg.Erasure.Enabled {
+			errs = append(errs, FieldError{
+				Field:   "evidence.erasure.enabled",
+				Message: "erasure cannot be enabled when evidence.write_once is enabled",
+			})
+		}
+		if cfg.Retention.Anonymize.Enabled {
+			errs = append(errs, FieldError{
+				Field:   "evidence.retention.anonymize.enabled",
+				Message: "anonymization cannot be enabled when evidence.write_once is enabled",
+			})
+		}
+		if cfg.Backend == "s3" {
+			if cfg.WriteOnce.ObjectLockMode != "GOVERNANCE" && cfg.WriteOnce.ObjectLockMode != "COMPLIANCE" {
+				errs = append(errs, FieldError{
+					Field:   "evidence.write_once.object_lock_mode",
+					Message: fmt.Sprintf("invalid object lock mode %q: must be 'GOVERNANCE' or 'COMPLIANCE'", cfg.WriteOnce.ObjectLockMode),
+				})
+			}
+			if cfg.WriteOnce.ObjectLockDays <= 0 {
+				errs = append(errs, FieldError{
+					Field:   "evidence.write_once.object_lock_days",
+					Message: "object lock days must be positive when evidence.write_once is enabled with the s3 backend",
+				})
+			}
+		}
+	}
+
+	if cfg.Recorder.BatchSize < 0 {
+		errs = append(errs, FieldError{
+			Field:   "evidence.recorder.batch_size",
+			Message: "batch size must be non-negative",
+		})
+	}
+	if cfg.Recorder.FlushInterval < 0 {
+		errs = append(errs, FieldError{
+			Field:   "evidence.recorder.flush_interval",
+			Message: "flush interval must be non-negative",
+		})
+	}
+	if cfg.Recorder.Sampling.Enabled {
+		if rate := cfg.Recorder.Sampling.DefaultRate; rate != nil && (*rate < 0 || *rate > 1) {
+			errs = append(errs, FieldError{
+				Field:   "evidence.recorder.sampling.default_rate",
+				Message: "default rate must be between 0 and 1",
+			})
+		}
+		for i, rule := range cfg.Recorder.Sampling.Rules {
+			prefix := fmt.Sprintf("evidence.recorder.sampling.rules[%d]", i)
+			if rule.Rate == nil || *rule.Rate < 0 || *rule.Rate > 1 {
+				errs = append(errs, FieldError{
+					Field:   prefix + ".rate",
+					Message: "rate is required and must be between 0 and 1",
+				})
+			}
+			for _, pattern := range append(append([]string{}, rule.Paths...), rule.Models...) {
+				if _, err := path.Match(pattern, ""); err != nil {
+					errs = append(errs, FieldError{
+						Field:   prefix,
+						Message: fmt.Sprintf("invalid pattern %q", pattern),
+					})
+				}
+			}
+		}
+	}
+	if cfg.Recorder.Spill.Enabled {
+		if cfg.Recorder.Spill.Path == "" {
+			errs = append(errs, FieldError{
+				Field:   "evidence.recorder.spill.path",
+				Message: "spill path is required when evidence.recorder.spill is enabled",
+			})
+		}
+		if cfg.Recorder.Spill.MaxSize < 0 {
+			errs = append(errs, FieldError{
+				Field:   "evidence.recorder.spill.max_size",
+				Message: "maximum size must be non-negative",
+			})
+		}
+	}
+
+	if cfg.Compression.Enabled && cfg.Compression.MinSize < 0 {
+		errs = append(errs, FieldError{
+			Field:   "evidence.compression.min_size",
+			Message: "minimum size must be non-negative",
+		})
+	}
+
+	if cfg.Query.Rollup.Enabled {
+		if cfg.Query.Rollup.Interval < 0 {
+			errs = append(errs, FieldError{
+				Field:   "evidence.query.rollup.interval",
+				Message: "rollup interval must be non-negative",
+			})
+		}
+		if cfg.Query.Rollup.LookbackDays < 0 {
+			errs = append(errs, FieldError{
+				Field:   "evidence.query.rollup.lookback_days",
+				Message: "rollup lookback days must be non-negative",
+			})
+		}
+		if cfg.Backend != "sqlite" {
+			errs = append(errs, FieldError{
+				Field:   "evidence.query.rollup.enabled",
+				Message: fmt.Sprintf("rollups are not supported by the %s backend", cfg.Backend),
+			})
+		}
+	}
+
+	// Validate retention days
+	if cfg.Retention.Days < 0 {
+		errs = append(errs, FieldError{
+			Field:   "evidence.retention.days",
+			Message: "retention days must be non-negative",
+		})
+	}
+	if cfg.Retention.Days > 3650 { // 10 years is excessive
+		errs = append(errs, FieldError{
+			Field:   "evidence.retention.days",
+			Message: "retention days exceeds reasonable limit (3650 days / 10 years)",
+		})
+	}
+	if cfg.Retention.ArchiveS3.Bucket != "" && cfg.Retention.ArchiveS3.Region == "" {
+		errs = append(errs, FieldError{
+			Field:   "evidence.retention.archive_s3.region",
+			Message: "region is required when evidence.retention.archive_s3.bucket is set",
+		})
+	}
+	for i, rule := range cfg.Retention.Rules {
+		field := fmt.Sprintf("evidence.retention.rules[%d]", i)
+		if rule.Team == "" && rule.Decision == "" && rule.Category == "" {
+			errs = append(errs, FieldError{
+				Field:   field,
+				Message: "retention rule requires a team, decision, or category",
+			})
+		}
+		if rule.Days < 0 || rule.Days > 3650 {
+			errs = append(errs, FieldError{
+				Field:   field + ".days",
+				Message: "retention days must be between 0 and 3650",
+			})
+		}
+	}
+
+	return errs
+}
+
+// validateEvidenceExportJobs validates the scheduled export jobs. Cron
+// schedules are parsed when the scheduler starts.
+func validateEvidenceExportJobs(jobs []EvidenceExportJobConfig) []FieldError {
+	var errs []FieldError
+
+	validFormats := map[string]bool{"jsonl": true, "json": true, "csv": true, "ocsf": true}
+	names := make(map[string]bool, len(jobs))
+	for i, job := range jobs {
+		prefix := fmt.Sprintf("evidence.export.jobs[%d]", i)
+		if job.Name == "" {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".name",
+				Message: "name is required",
+			})
+		} else if names[job.Name] {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".name",
+				Message: fmt.Sprintf("duplicate job name %q", job.Name),
+			})
+		}
+		names[job.Name] = true
+		if job.Schedule == "" {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".schedule",
+				Message: "schedule is required",
+			})
+		}
+		if !validFormats[job.Format] {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".format",
+				Message: fmt.Sprintf("invalid format %q: must be 'jsonl', 'json', 'csv', or 'ocsf'", job.Format),
+			})
+		}
+		if job.Delay < 0 {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".delay",
+				Message: "delay must be non-negative",
+			})
+		}
+		if job.MaxRecords < 0 {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".max_records",
+				Message: "max records must be non-negative",
+			})
+		}
+
+		u, err := url.Parse(job.Destination)
+		if job.Destination == "" || err != nil || u.Host == "" {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".destination",
+				Message: "destination must be an s3://, sftp://, http://, or https:// URL",
+			})
+			continue
+		}
+		switch u.Scheme {
+		case "s3":
+			if job.Region == "" {
+				errs = append(errs, FieldError{
+					Field:   prefix + ".region",
+					Message: "region is required for S3 destinations",
+				})
+			}
+		case "sftp":
+			if job.HostKey == "" {
+				errs = append(errs, FieldError{
+					Field:   prefix + ".host_key",
+					Message: "host key is required for SFTP destinations",
+				})
+			}
+			if job.Password == "" && job.PrivateKeyPath == "" {
+				errs = append(errs, FieldError{
+					Field:   prefix + ".password",
+					Message: "password or private_key_path is required for SFTP destinations",
+				})
+			}
+		case "http", "https":
+		default:
+			errs = append(errs, FieldError{
+				Field:   prefix + ".destination",
+				Message: fmt.Sprintf("unsupported destination scheme %q: must be 's3', 'sftp', 'http', or 'https'", u.Scheme),
+			})
+		}
+	}
+
+	return errs
+}
+
+// validateEvidenceStream validates the real-time evidence exporters.
+func validateEvidenceStream(cfg *EvidenceStreamConfig) []FieldError {
+	var errs []FieldError
+
+	if cfg.BufferSize < 0 {
+		errs = append(errs, FieldError{
+			Field:   "evidence.stream.buffer_size",
+			Message: "buffer size must be non-negative",
+		})
+	}
+	if cfg.BatchSize < 0 {
+		errs = append(errs, FieldError{
+			Field:   "evidence.stream.batch_size",
+			Message: "batch size must be non-negative",
+		})
+	}
+	if cfg.FlushInterval < 0 {
+		errs = append(errs, FieldError{
+			Field:   "evidence.stream.flush_interval",
+			Message: "flush interval must be non-negative",
+		})
+	}
+	if cfg.Timeout < 0 {
+		errs = append(errs, FieldError{
+			Field:   "evidence.stream.timeout",
+			Message: "timeout must be non-negative",
+		})
+	}
+
+	if cfg.Kafka.Enabled {
+		if cfg.Kafka.URL == "" {
+			errs = append(errs, FieldError{
+				Field:   "evidence.stream.kafka.url",
+				Message: "url is required when the kafka exporter is enabled",
+			})
+		}
+		if cfg.Kafka.Topic == "" {
+			errs = append(errs, FieldError{
+				Field:   "evidence.stream.kafka.topic",
+				Message: "topic is required when the kafka exporter is enabled",
+			})
+		}
+		if cfg.Kafka.Serialization != "" && cfg.Kafka.Serialization != "json" && cfg.Kafka.Serialization != "avro" {
+			errs = append(errs, FieldError{
+				Field:   "evidence.stream.kafka.serialization",
+				Message: fmt.Sprintf("invalid serialization %q: must be 'json' or 'avro'", cfg.Kafka.Serialization),
+			})
+		}
+	}
+
+	if cfg.Elasticsearch.Enabled {
+		if cfg.Elasticsearch.URL == "" {
+			errs = append(errs, FieldError{
+				Field:   "evidence.stream.elasticsearch.url",
+				Message: "url is required when the elasticsearch exporter is enabled",
+			})
+		}
+		if cfg.Elasticsearch.IndexMode != "" && cfg.Elasticsearch.IndexMode != "daily" && cfg.Elasticsearch.IndexMode != "rollover" {
+			errs = append(errs, FieldError{
+				Field:   "evidence.stream.elasticsearch.index_mode",
+				Message: fmt.Sprintf("invalid index mode %q: must be 'daily' or 'rollover'", cfg.Elasticsearch.IndexMode),
+			})
+		}
+	}
+
+	if cfg.Syslog.Enabled {
+		if _, _, err := net.SplitHostPort(cfg.Syslog.Address); err != nil {
+			errs = append(errs, FieldError{
+				Field:   "evidence.stream.syslog.address",
+				Message: "address must be in host:port format",
+			})
+		}
+		validNetworks := map[string]bool{"udp": true, "tcp": true, "tls": true}
+		if cfg.Syslog.Network != "" && !validNetworks[cfg.Syslog.Network] {
+			errs = append(errs, FieldError{
+				Field:   "evidence.stream.syslog.network",
+				Message: fmt.Sprintf("invalid network %q: must be 'udp', 'tcp', or 'tls'", cfg.Syslog.Network),
+			})
+		}
+		if cfg.Syslog.Format != "" && cfg.Syslog.Format != "cef" && cfg.Syslog.Format != "leef" {
+			errs = append(errs, FieldError{
+				Field:   "evidence.stream.syslog.format",
+				Message: fmt.Sprintf("invalid format %q: must be 'cef' or 'leef'", cfg.Syslog.Format),
+			})
+		}
+		if cfg.Syslog.Facility < 0 || cfg.Syslog.Facility > 23 {
+			errs = append(errs, FieldError{
+				Field:   "evidence.stream.syslog.facility",
+				Message: "facility must be between 0 and 23",
+			})
+		}
+		if cfg.Syslog.CAFile != "" && cfg.Syslog.Network != "tls" {
+			errs = append(errs, FieldError{
+				Field:   "evidence.stream.syslog.ca_file",
+				Message: "ca_file requires network 'tls'",
+			})
+		}
+	}
+
+	names := make(map[string]bool, len(cfg.Webhooks))
+	for i, webhook := range cfg.Webhooks {
+		prefix := fmt.Sprintf("evidence.stream.webhooks[%d]", i)
+		if webhook.Name == "" {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".name",
+				Message: "name is required",
+			})
+		} else if names[webhook.Name] {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".name",
+				Message: fmt.Sprintf("duplicate webhook name %q", webhook.Name),
+			})
+		}
+		names[webhook.Name] = true
+		if u, err := url.Parse(webhook.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".url",
+				Message: "url must be an http:// or https:// URL",
+			})
+		}
+		if webhook.Match.MinCost < 0 {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".match.min_cost",
+				Message: "min cost must be non-negative",
+			})
+		}
+	}
+
+	return errs
+}
+
+// validateTelemetry validates telemetry configuration.
+func validateTelemetry(cfg *TelemetryConfig) []FieldError {
+	var errs []FieldError
+
+	// Validate logging level
+	validLevels := map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
+	if cfg.Logging.Level == "" {
+		errs = append(errs, FieldError{
+			Field:   "telemetry.logging.level",
+			Message: "logging level is required",
+		})
+	} else if !validLevels[cfg.Logging.Level] {
+		errs = append(errs, FieldError{
+			Field:   "telemetry.logging.level",
+			Message: fmt.Sprintf("invalid logging level %q: must be 'debug', 'info', 'warn', or 'error'", cfg.Logging.Level),
+		})
+	}
+
+	// Validate logging format
+	validFormats := map[string]bool{"json": true, "text": true}
+	if cfg.Logging.Format == "" {
+		errs = append(errs, FieldError{
+			Field:   "telemetry.logging.format",
+			Message: "logging format is required",
+		})
+	} else if !validFormats[cfg.Logging.Format] {
+		errs = append(errs, FieldError{
+			Field:   "telemetry.logging.format",
+			Message: fmt.Sprintf("invalid logging format %q: must be 'json' or 'text'", cfg.Logging.Format),
+		})
+	}
+
+	// Validate log sampling rules
+	if cfg.Logging.Sampling.Enabled {
+		for i, rule := range cfg.Logging.Sampling.Rules {
+			if rule.Every < 1 {
+				errs = append(errs, FieldError{
+					Field:   fmt.Sprintf("telemetry.logging.sampling.rules[%d].every", i),
+					Message: "every must be at least 1",
+				})
+			}
+		}
+	}
+	errs = append(errs, validateLogOutputs(cfg.Logging.Outputs)...)
+
+	// Validate metrics prometheus path
+	if cfg.Metrics.Enabled && cfg.Metrics.Path == "" {
+		errs = append(errs, FieldError{
+			Field:   "telemetry.metrics.path",
+			Message: "metrics path is required when metrics are enabled",
+		})
+	}
+	if cfg.Metrics.TenantLabels.MaxValues < 0 {
+		errs = append(errs, FieldError{
+			Field:   "telemetry.metrics.tenant_labels.max_values",
+			Message: "max values must be non-negative",
+		})
+	}
+	errs = append(errs, validateSLOObjectives(cfg.Metrics.SLO.Objectives)...)
+
+	// Validate audit sinks
+	if cfg.Audit.Enabled {
+		for i, sink := range cfg.Audit.Sinks {
+			field := fmt.Sprintf("telemetry.audit.sinks[%d]", i)
+			switch sink.Type {
+			case "stdout", "stderr":
+			case "file":
+				if sink.Path == "" {
+					errs = append(errs, FieldError{
+						Field:   field + ".path",
+						Message: "path is required for file sinks",
+					})
+				}
+			default:
+				errs = append(errs, FieldError{
+					Field:   field + ".type",
+					Message: fmt.Sprintf("invalid audit sink type %q: must be 'stdout', 'stderr', or 'file'", sink.Type),
+				})
+			}
+		}
+	}
+
+	if cfg.Bus.Enabled {
+		errs = append(errs, validateTelemetryBus(&cfg.Bus, cfg.Audit.Enabled)...)
+	}
+
+	// Validate tracing configuration
+	if cfg.Tracing.Enabled && cfg.Tracing.Endpoint == "" {
+		errs = append(errs, FieldError{
+			Field:   "telemetry.tracing.endpoint",
+			Message: "tracing endpoint is required when tracing is enabled",
+		})
+	}
+	if cfg.Tracing.SampleRatio < 0 || cfg.Tracing.SampleRatio > 1.0 {
+		errs = append(errs, FieldError{
+			Field:   "telemetry.tracing.sample_ratio",
+			Message: "sample ratio must be between 0.0 and 1.0",
+		})
+	}
+
+	// Validate health check configuration
+	if cfg.Health.Enabled {
+		// Validate paths are non-empty
+		if cfg.Health.LivenessPath == "" {
+			errs = append(errs, FieldError{
+				Field:   "telemetry.health.liveness_path",
+				Message: "liveness path is required when health checks are enabled",
+			})
+		}
+		if cfg.Health.ReadinessPath == "" {
+			errs = append(errs, FieldError{
+				Field:   "telemetry.health.readiness_path",
+				Message: "readiness path is required when health checks are enabled",
+			})
+		}
+		if cfg.Health.VersionPath == "" {
+			errs = append(errs, FieldError{
+				Field:   "telemetry.health.version_path",
+				Message: "version path is required when health checks are enabled",
+			})
+		}
+
+		// Validate paths start with /
+		if cfg.Health.LivenessPath != "" && cfg.Health.LivenessPath[0] != '/' {
+			errs = append(errs, FieldError{
+				Field:   "telemetry.health.liveness_path",
+				Message: "liveness path must start with /",
+			})
+		}
+		if cfg.Health.ReadinessPath != "" && cfg.Health.ReadinessPath[0] != '/' {
+			errs = append(errs, FieldError{
+				Field:   "telemetry.health.readiness_path",
+				Message: "readiness path must start with /",
+			})
+		}
+		if cfg.Health.VersionPath != "" && cfg.Health.VersionPath[0] != '/' {
+			errs = append(errs, FieldError{
+				Field:   "telemetry.health.version_path",
+				Message: "version path must start with /",
 			})
 		}
 
 		// Validate check timeout is reasonable
 		if cfg.Health.CheckTimeout < 0 {
 			errs = append(errs, FieldError{
-				Field:   "telemetry.health.check_timeout",
-				Message: "check timeout must be positive",
+				Field:   "telemetry.health.check_timeout",
+				Message: "check timeout must be positive",
+			})
+		}
+		if cfg.Health.CheckTimeout > 60*time.Second {
+			errs = append(errs, FieldError{
+				Field:   "telemetry.health.check_timeout",
+				Message: "check timeout exceeds reasonable limit (60s)",
+			})
+		}
+
+		// Validate min healthy providers
+		if cfg.Health.MinHealthyProviders < 0 {
+			errs = append(errs, FieldError{
+				Field:   "telemetry.health.min_healthy_providers",
+				Message: "min healthy providers must be non-negative",
+			})
+		}
+	}
+
+	return errs
+}
+
+// validateTelemetryBus validates the log and audit event bus.
+func validateTelemetryBus(cfg *TelemetryBusConfig, auditEnabled bool) []FieldError {
+	var errs []FieldError
+
+	if cfg.Type != "kafka" && cfg.Type != "nats" {
+		errs = append(errs, FieldError{
+			Field:   "telemetry.bus.type",
+			Message: fmt.Sprintf("invalid bus type %q: must be 'kafka' or 'nats'", cfg.Type),
+		})
+	}
+	if cfg.URL == "" {
+		errs = append(errs, FieldError{
+			Field:   "telemetry.bus.url",
+			Message: "url is required",
+		})
+	}
+	if cfg.LogTopic == "" {
+		errs = append(errs, FieldError{
+			Field:   "telemetry.bus.log_topic",
+			Message: "log topic is required",
+		})
+	}
+	if cfg.AuditTopic != "" && !auditEnabled {
+		errs = append(errs, FieldError{
+			Field:   "telemetry.bus.audit_topic",
+			Message: "audit topic requires telemetry.audit.enabled",
+		})
+	}
+	if cfg.Type == "nats" {
+		if strings.ContainsAny(cfg.LogTopic, " \t\r\n") {
+			errs = append(errs, FieldError{
+				Field:   "telemetry.bus.log_topic",
+				Message: fmt.Sprintf("invalid NATS subject %q", cfg.LogTopic),
+			})
+		}
+		if strings.ContainsAny(cfg.AuditTopic, " \t\r\n") {
+			errs = append(errs, FieldError{
+				Field:   "telemetry.bus.audit_topic",
+				Message: fmt.Sprintf("invalid NATS subject %q", cfg.AuditTopic),
+			})
+		}
+	}
+	if cfg.Type != "nats" && cfg.CAFile != "" {
+		errs = append(errs, FieldError{
+			Field:   "telemetry.bus.ca_file",
+			Message: "ca_file is only supported for nats",
+		})
+	}
+
+	validLevels := map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
+	if !validLevels[cfg.Level] {
+		errs = append(errs, FieldError{
+			Field:   "telemetry.bus.level",
+			Message: fmt.Sprintf("invalid level %q: must be 'debug', 'info', 'warn', or 'error'", cfg.Level),
+		})
+	}
+	if cfg.Backpressure != "drop" && cfg.Backpressure != "block" {
+		errs = append(errs, FieldError{
+			Field:   "telemetry.bus.backpressure",
+			Message: fmt.Sprintf("invalid backpressure %q: must be 'drop' or 'block'", cfg.Backpressure),
+		})
+	}
+	if cfg.BatchSize < 1 {
+		errs = append(errs, FieldError{
+			Field:   "telemetry.bus.batch_size",
+			Message: "batch size must be positive",
+		})
+	}
+	if cfg.BufferSize < 1 {
+		errs = append(errs, FieldError{
+			Field:   "telemetry.bus.buffer_size",
+			Message: "buffer size must be positive",
+		})
+	}
+	if cfg.FlushInterval <= 0 || cfg.Timeout <= 0 {
+		errs = append(errs, FieldError{
+			Field:   "telemetry.bus",
+			Message: "flush_interval and timeout must be positive",
+		})
+	}
+
+	return errs
+}
+
+// validateRemote validates the remote configuration source.
+func validateRemote(cfg *RemoteConfig) []FieldError {
+	var errs []FieldError
+
+	if cfg.Backend != "etcd" && cfg.Backend != "consul" {
+		errs = append(errs, FieldError{
+			Field:   "remote.backend",
+			Message: fmt.Sprintf("invalid backend %q: must be 'etcd' or 'consul'", cfg.Backend),
+		})
+	}
+	if len(cfg.Endpoints) == 0 {
+		errs = append(errs, FieldError{
+			Field:   "remote.endpoints",
+			Message: "at least one endpoint is required",
+		})
+	}
+	for i, endpoint := range cfg.Endpoints {
+		u, err := url.Parse(endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			errs = append(errs, FieldError{
+				Field:   fmt.Sprintf("remote.endpoints[%d]", i),
+				Message: fmt.Sprintf("invalid endpoint %q: must be an http or https URL", endpoint),
+			})
+		}
+	}
+	if strings.Trim(cfg.Key, "/") == "" {
+		errs = append(errs, FieldError{
+			Field:   "remote.key",
+			Message: "key is required",
+		})
+	}
+	if cfg.Backend == "etcd" && (cfg.Username == "") != (cfg.Password == "") {
+		errs = append(errs, FieldError{
+			Field:   "remote.username",
+			Message: "username and password must be set together",
+		})
+	}
+	if cfg.Backend != "etcd" && cfg.Username != "" {
+		errs = append(errs, FieldError{
+			Field:   "remote.username",
+			Message: "username is only supported for etcd",
+		})
+	}
+	if cfg.Backend != "consul" && (cfg.Token != "" || cfg.Datacenter != "") {
+		errs = append(errs, FieldError{
+			Field:   "remote.token",
+			Message: "token and datacenter are only supported for consul",
+		})
+	}
+	if cfg.Timeout <= 0 || cfg.WatchTimeout <= 0 || cfg.RetryInterval <= 0 {
+		errs = append(errs, FieldError{
+			Field:   "remote",
+			Message: "timeout, watch_timeout and retry_interval must be positive",
+		})
+	}
+	if cfg.Backend == "consul" && cfg.WatchTimeout > 10*time.Minute {
+		errs = append(errs, FieldError{
+			Field:   "remote.watch_timeout",
+			Message: "watch_timeout must not exceed 10m for consul",
+		})
+	}
+	if cfg.Jitter < 0 {
+		errs = append(errs, FieldError{
+			Field:   "remote.jitter",
+			Message: "jitter must not be negative",
+		})
+	}
+
+	return errs
+}
+
+// logOutputFieldPattern matches the field names sent to log collectors,
+// valid both as GELF additional fields and syslog SD-PARAM names.
+var logOutputFieldPattern = regexp.MustCompile(`^[A-Za-z0-9_.\-]{1,32}$`)
+
+// validateLogOutputs validates the GELF and syslog log outputs.
+func validateLogOutputs(outputs []LogOutputConfig) []FieldError {
+	var errs []FieldError
+
+	validLevels := map[string]bool{"debug": true, "info": true, "warn": true, "error": true}
+	validNetworks := map[string]bool{"udp": true, "tcp": true, "tls": true}
+	for i, output := range outputs {
+		prefix := fmt.Sprintf("telemetry.logging.outputs[%d]", i)
+
+		if output.Type != "gelf" && output.Type != "syslog" {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".type",
+				Message: fmt.Sprintf("invalid output type %q: must be 'gelf' or 'syslog'", output.Type),
+			})
+		}
+		if _, _, err := net.SplitHostPort(output.Address); err != nil {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".address",
+				Message: "address must be in host:port format",
+			})
+		}
+		if !validNetworks[output.Network] {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".network",
+				Message: fmt.Sprintf("invalid network %q: must be 'udp', 'tcp', or 'tls'", output.Network),
+			})
+		}
+		if output.CAFile != "" && output.Network != "tls" {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".ca_file",
+				Message: "ca_file requires network 'tls'",
+			})
+		}
+		if output.Level != "" && !validLevels[output.Level] {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".level",
+				Message: fmt.Sprintf("invalid level %q: must be 'debug', 'info', 'warn', or 'error'", output.Level),
+			})
+		}
+		if output.Facility < 0 || output.Facility > 23 {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".facility",
+				Message: "facility must be between 0 and 23",
+			})
+		}
+
+		for _, attr := range slices.Sorted(maps.Keys(output.Fields)) {
+			name := output.Fields[attr]
+			if name != "" && !logOutputFieldPattern.MatchString(name) {
+				errs = append(errs, FieldError{
+					Field:   prefix + ".fields." + attr,
+					Message: fmt.Sprintf("invalid field name %q: must be up to 32 letters, digits, '_', '.', or '-'", name),
+				})
+			}
+		}
+		for _, name := range slices.Sorted(maps.Keys(output.StaticFields)) {
+			if !logOutputFieldPattern.MatchString(name) {
+				errs = append(errs, FieldError{
+					Field:   prefix + ".static_fields." + name,
+					Message: fmt.Sprintf("invalid field name %q: must be up to 32 letters, digits, '_', '.', or '-'", name),
+				})
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateSLOObjectives validates the service level objectives.
+func validateSLOObjectives(objectives []SLOObjectiveConfig) []FieldError {
+	var errs []FieldError
+
+	seen := make(map[string]bool)
+	for i, objective := range objectives {
+		prefix := fmt.Sprintf("telemetry.metrics.slo.objectives[%d]", i)
+
+		switch {
+		case objective.Name == "":
+			errs = append(errs, FieldError{
+				Field:   prefix + ".name",
+				Message: "name is required",
+			})
+		case seen[objective.Name]:
+			errs = append(errs, FieldError{
+				Field:   prefix + ".name",
+				Message: fmt.Sprintf("duplicate objective %q", objective.Name),
+			})
+		}
+		seen[objective.Name] = true
+
+		switch objective.Type {
+		case "availability":
+		case "latency":
+			if objective.Threshold <= 0 {
+				errs = append(errs, FieldError{
+					Field:   prefix + ".threshold",
+					Message: "threshold must be positive for latency objectives",
+				})
+			}
+		default:
+			errs = append(errs, FieldError{
+				Field:   prefix + ".type",
+				Message: fmt.Sprintf("type must be availability or latency, got %q", objective.Type),
+			})
+		}
+
+		if objective.Target <= 0 || objective.Target >= 1 {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".target",
+				Message: "target must be between 0 and 1 (exclusive)",
+			})
+		}
+		if objective.Window < time.Hour {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".window",
+				Message: "window must be at least 1h",
+			})
+		}
+	}
+
+	return errs
+}
+
+// validateLimits validates limits configuration.
+func validateLimits(cfg *LimitsConfig) []FieldError {
+	var errs []FieldError
+
+	// Validate budgets configuration
+	if cfg.Budgets.Enabled {
+		// Validate alert threshold
+		if cfg.Budgets.AlertThreshold < 0.0 || cfg.Budgets.AlertThreshold > 1.0 {
+			errs = append(errs, FieldError{
+				Field:   "limits.budgets.alert_threshold",
+				Message: "alert threshold must be between 0.0 and 1.0",
+			})
+		}
+		if cfg.Budgets.GraceOverage < 0.0 || cfg.Budgets.GraceOverage > 1.0 {
+			errs = append(errs, FieldError{
+				Field:   "limits.budgets.grace_overage",
+				Message: "grace overage must be between 0.0 and 1.0",
+			})
+		}
+
+		// Validate per-API key budgets
+		for apiKey, limits := range cfg.Budgets.ByAPIKey {
+			prefix := fmt.Sprintf("limits.budgets.by_api_key.%s", apiKey)
+			errs = append(errs, validateBudgetLimits(prefix, &limits)...)
+		}
+
+		// Validate per-user budgets
+		for user, limits := range cfg.Budgets.ByUser {
+			prefix := fmt.Sprintf("limits.budgets.by_user.%s", user)
+			errs = append(errs, validateBudgetLimits(prefix, &limits)...)
+		}
+
+		// Validate per-team budgets
+		for team, limits := range cfg.Budgets.ByTeam {
+			prefix := fmt.Sprintf("limits.budgets.by_team.%s", team)
+			errs = append(errs, validateBudgetLimits(prefix, &limits)...)
+		}
+
+		// Validate per-organization budgets
+		for org, limits := range cfg.Budgets.ByOrg {
+			prefix := fmt.Sprintf("limits.budgets.by_org.%s", org)
+			errs = append(errs, validateBudgetLimits(prefix, &limits)...)
+		}
+
+		errs = append(errs, validateBudgetHierarchy(&cfg.Budgets)...)
+		errs = append(errs, validateBudgetAlerts(&cfg.Budgets.Alerts)...)
+	}
+
+	// Validate rate limits configuration
+	if cfg.RateLimits.Enabled {
+		// Validate per-API key rate limits
+		for apiKey, limits := range cfg.RateLimits.ByAPIKey {
+			prefix := fmt.Sprintf("limits.rate_limits.by_api_key.%s", apiKey)
+			errs = append(errs, validateRateLimits(prefix, &limits)...)
+		}
+
+		// Validate per-user rate limits
+		for user, limits := range cfg.RateLimits.ByUser {
+			prefix := fmt.Sprintf("limits.rate_limits.by_user.%s", user)
+			errs = append(errs, validateRateLimits(prefix, &limits)...)
+		}
+
+		// Validate per-team rate limits
+		for team, limits := range cfg.RateLimits.ByTeam {
+			prefix := fmt.Sprintf("limits.rate_limits.by_team.%s", team)
+			errs = append(errs, validateRateLimits(prefix, &limits)...)
+		}
+
+		switch cfg.RateLimits.Backend {
+		case "", "memory":
+		case "redis":
+			errs = append(errs, validateRedis("limits.rate_limits.redis", &cfg.RateLimits.Redis)...)
+		default:
+			errs = append(errs, FieldError{
+				Field:   "limits.rate_limits.backend",
+				Message: fmt.Sprintf("invalid backend %q: must be 'memory' or 'redis'", cfg.RateLimits.Backend),
+			})
+		}
+	}
+
+	// Validate enforcement configuration
+	errs = append(errs, validateEnforcement(&cfg.Enforcement)...)
+
+	// Validate storage configuration
+	errs = append(errs, validateLimitsStorage(&cfg.Storage)...)
+
+	// Validate exemptions
+	errs = append(errs, validateLimitsExemptions(&cfg.Exemptions)...)
+
+	return errs
+}
+
+// validateLimitsExemptions validates the limits exemptions and break-glass
+// tokens.
+func validateLimitsExemptions(cfg *LimitsExemptionsConfig) []FieldError {
+	var errs []FieldError
+
+	names := make(map[string]bool)
+	for i, exemption := range cfg.Identifiers {
+		prefix := fmt.Sprintf("limits.exemptions.identifiers[%d]", i)
+		if exemption.Name == "" {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".name",
+				Message: "name is required",
+			})
+		} else if names[exemption.Name] {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".name",
+				Message: fmt.Sprintf("duplicate exemption name %q", exemption.Name),
+			})
+		}
+		names[exemption.Name] = true
+
+		if exemption.Identifier == "" {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".identifier",
+				Message: "identifier is required",
+			})
+		}
+	}
+
+	names = make(map[string]bool)
+	for i, token := range cfg.BreakGlassTokens {
+		prefix := fmt.Sprintf("limits.exemptions.break_glass_tokens[%d]", i)
+		if token.Name == "" {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".name",
+				Message: "name is required",
+			})
+		} else if names[token.Name] {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".name",
+				Message: fmt.Sprintf("duplicate break-glass token name %q", token.Name),
+			})
+		}
+		names[token.Name] = true
+
+		if len(token.Token) < 32 {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".token",
+				Message: "token must be at least 32 characters",
+			})
+		}
+	}
+
+	return errs
+}
+
+// validateBudgetAlerts validates budget alert channels and routes.
+func validateBudgetAlerts(cfg *BudgetAlertsConfig) []FieldError {
+	var errs []FieldError
+
+	if cfg.DedupInterval < 0 {
+		errs = append(errs, FieldError{
+			Field:   "limits.budgets.alerts.dedup_interval",
+			Message: "dedup interval must be non-negative",
+		})
+	}
+
+	names := make(map[string]bool)
+	for i, channel := range cfg.Channels {
+		prefix := fmt.Sprintf("limits.budgets.alerts.channels[%d]", i)
+		if channel.Name == "" {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".name",
+				Message: "name is required",
+			})
+		} else if names[channel.Name] {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".name",
+				Message: fmt.Sprintf("duplicate channel name %q", channel.Name),
 			})
 		}
-		if cfg.Health.CheckTimeout > 60*time.Second {
+		names[channel.Name] = true
+
+		switch channel.Type {
+		case "slack", "webhook":
+			if channel.URL == "" {
+				errs = append(errs, FieldError{
+					Field:   prefix + ".url",
+					Message: fmt.Sprintf("url is required for %s channels", channel.Type),
+				})
+			}
+		case "pagerduty":
+			if channel.RoutingKey == "" {
+				errs = append(errs, FieldError{
+					Field:   prefix + ".routing_key",
+					Message: "routing_key is required for pagerduty channels",
+				})
+			}
+		case "email":
+			if channel.SMTP.Host == "" {
+				errs = append(errs, FieldError{
+					Field:   prefix + ".smtp.host",
+					Message: "smtp host is required for email channels",
+				})
+			}
+			if channel.SMTP.From == "" {
+				errs = append(errs, FieldError{
+					Field:   prefix + ".smtp.from",
+					Message: "smtp from address is required for email channels",
+				})
+			}
+			if len(channel.To) == 0 {
+				errs = append(errs, FieldError{
+					Field:   prefix + ".to",
+					Message: "at least one recipient is required for email channels",
+				})
+			}
+		default:
 			errs = append(errs, FieldError{
-				Field:   "telemetry.health.check_timeout",
-				Message: "check timeout exceeds reasonable limit (60s)",
+				Field:   prefix + ".type",
+				Message: fmt.Sprintf("invalid channel type %q: must be 'slack', 'pagerduty', 'email', or 'webhook'", channel.Type),
+			})
+		}
+	}
+
+	validDimensions := map[string]bool{"api_key": true, "user": true, "team": true, "org": true}
+	validLevels := map[string]bool{"warning": true, "grace": true, "exceeded": true}
+	for i, route := range cfg.Routes {
+		prefix := fmt.Sprintf("limits.budgets.alerts.routes[%d]", i)
+		if len(route.Channels) == 0 {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".channels",
+				Message: "at least one channel is required",
+			})
+		}
+		for _, name := range route.Channels {
+			if !names[name] {
+				errs = append(errs, FieldError{
+					Field:   prefix + ".channels",
+					Message: fmt.Sprintf("unknown channel %q", name),
+				})
+			}
+		}
+		for _, dimension := range route.Dimensions {
+			if !validDimensions[dimension] {
+				errs = append(errs, FieldError{
+					Field:   prefix + ".dimensions",
+					Message: fmt.Sprintf("invalid dimension %q: must be 'api_key', 'user', 'team', or 'org'", dimension),
+				})
+			}
+		}
+		for _, level := range route.Levels {
+			if !validLevels[level] {
+				errs = append(errs, FieldError{
+					Field:   prefix + ".levels",
+					Message: fmt.Sprintf("invalid level %q: must be 'warning', 'grace', or 'exceeded'", level),
+				})
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateBudgetHierarchy validates nested budget scopes.
+func validateBudgetHierarchy(cfg *BudgetsConfig) []FieldError {
+	var errs []FieldError
+	hierarchy := &cfg.Hierarchy
+
+	switch hierarchy.Inheritance {
+	case "", "all", "nearest":
+	default:
+		errs = append(errs, FieldError{
+			Field:   "limits.budgets.hierarchy.inheritance",
+			Message: fmt.Sprintf("invalid inheritance %q: must be 'all' or 'nearest'", hierarchy.Inheritance),
+		})
+	}
+
+	for level, threshold := range hierarchy.AlertThresholds {
+		field := fmt.Sprintf("limits.budgets.hierarchy.alert_thresholds.%s", level)
+		switch level {
+		case "api_key", "user", "team", "org":
+		default:
+			errs = append(errs, FieldError{
+				Field:   field,
+				Message: "level must be 'api_key', 'user', 'team' or 'org'",
+			})
+			continue
+		}
+		if threshold < 0.0 || threshold > 1.0 {
+			errs = append(errs, FieldError{
+				Field:   field,
+				Message: "alert threshold must be between 0.0 and 1.0",
+			})
+		}
+	}
+
+	for team, org := range hierarchy.TeamOrgs {
+		if org == "" {
+			errs = append(errs, FieldError{
+				Field:   fmt.Sprintf("limits.budgets.hierarchy.team_orgs.%s", team),
+				Message: "organization is required",
+			})
+		}
+	}
+
+	// Per-model budgets are only tracked for API keys
+	if hierarchy.Enabled {
+		scoped := map[string]map[string]BudgetLimits{
+			"by_user": cfg.ByUser,
+			"by_team": cfg.ByTeam,
+			"by_org":  cfg.ByOrg,
+		}
+		for level, budgets := range scoped {
+			for id, limits := range budgets {
+				if len(limits.Models) > 0 {
+					errs = append(errs, FieldError{
+						Field:   fmt.Sprintf("limits.budgets.%s.%s.models", level, id),
+						Message: "per-model budgets are only supported for API keys",
+					})
+				}
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateBudgetLimits validates budget limit values.
+func validateBudgetLimits(prefix string, limits *BudgetLimits) []FieldError {
+	var errs []FieldError
+
+	if limits.Hourly < 0 {
+		errs = append(errs, FieldError{
+			Field:   prefix + ".hourly",
+			Message: "hourly budget must be non-negative",
+		})
+	}
+	if limits.Daily < 0 {
+		errs = append(errs, FieldError{
+			Field:   prefix + ".daily",
+			Message: "daily budget must be non-negative",
+		})
+	}
+	if limits.Monthly < 0 {
+		errs = append(errs, FieldError{
+			Field:   prefix + ".monthly",
+			Message: "monthly budget must be non-negative",
+		})
+	}
+
+	// Validate that smaller windows don't exceed larger windows
+	if limits.Hourly > 0 && limits.Daily > 0 && limits.Hourly > limits.Daily {
+		errs = append(errs, FieldError{
+			Field:   prefix + ".hourly",
+			Message: "hourly budget cannot exceed daily budget",
+		})
+	}
+	if limits.Daily > 0 && limits.Monthly > 0 && limits.Daily > limits.Monthly {
+		errs = append(errs, FieldError{
+			Field:   prefix + ".daily",
+			Message: "daily budget cannot exceed monthly budget",
+		})
+	}
+
+	// Validate per-model budgets
+	for model, modelLimits := range limits.Models {
+		modelPrefix := fmt.Sprintf("%s.models.%s", prefix, model)
+		if len(modelLimits.Models) > 0 {
+			errs = append(errs, FieldError{
+				Field:   modelPrefix + ".models",
+				Message: "per-model budgets cannot be nested",
+			})
+		}
+		errs = append(errs, validateBudgetLimits(modelPrefix, &modelLimits)...)
+	}
+
+	return errs
+}
+
+// validateRateLimits validates rate limit values.
+func validateRateLimits(prefix string, limits *RateLimits) []FieldError {
+	var errs []FieldError
+
+	switch limits.Algorithm {
+	case "", "token_bucket", "gcra":
+	default:
+		errs = append(errs, FieldError{
+			Field:   prefix + ".algorithm",
+			Message: fmt.Sprintf("invalid algorithm %q: must be 'token_bucket' or 'gcra'", limits.Algorithm),
+		})
+	}
+	if limits.BurstMultiplier < 0 {
+		errs = append(errs, FieldError{
+			Field:   prefix + ".burst_multiplier",
+			Message: "burst multiplier must be non-negative",
+		})
+	}
+	if limits.BurstMultiplier > 100 {
+		errs = append(errs, FieldError{
+			Field:   prefix + ".burst_multiplier",
+			Message: "burst multiplier exceeds reasonable limit (100)",
+		})
+	}
+	if limits.BurstMultiplier != 0 && limits.Algorithm == "gcra" {
+		errs = append(errs, FieldError{
+			Field:   prefix + ".burst_multiplier",
+			Message: "burst multiplier cannot be used with the gcra algorithm",
+		})
+	}
+
+	if limits.RequestsPerSecond < 0 {
+		errs = append(errs, FieldError{
+			Field:   prefix + ".requests_per_second",
+			Message: "requests per second must be non-negative",
+		})
+	}
+	if limits.RequestsPerMinute < 0 {
+		errs = append(errs, FieldError{
+			Field:   prefix + ".requests_per_minute",
+			Message: "requests per minute must be non-negative",
+		})
+	}
+	if limits.RequestsPerHour < 0 {
+		errs = append(errs, FieldError{
+			Field:   prefix + ".requests_per_hour",
+			Message: "requests per hour must be non-negative",
+		})
+	}
+	if limits.TokensPerMinute < 0 {
+		errs = append(errs, FieldError{
+			Field:   prefix + ".tokens_per_minute",
+			Message: "tokens per minute must be non-negative",
+		})
+	}
+	if limits.TokensPerHour < 0 {
+		errs = append(errs, FieldError{
+			Field:   prefix + ".tokens_per_hour",
+			Message: "tokens per hour must be non-negative",
+		})
+	}
+	if limits.MaxConcurrent < 0 {
+		errs = append(errs, FieldError{
+			Field:   prefix + ".max_concurrent",
+			Message: "max concurrent must be non-negative",
+		})
+	}
+	if limits.MaxConcurrentStreams < 0 {
+		errs = append(errs, FieldError{
+			Field:   prefix + ".max_concurrent_streams",
+			Message: "max concurrent streams must be non-negative",
+		})
+	}
+
+	// Validate reasonable limits
+	if limits.RequestsPerSecond > 100000 {
+		errs = append(errs, FieldError{
+			Field:   prefix + ".requests_per_second",
+			Message: "requests per second exceeds reasonable limit (100,000)",
+		})
+	}
+	if limits.TokensPerMinute > 10000000 { // 10M tokens/min
+		errs = append(errs, FieldError{
+			Field:   prefix + ".tokens_per_minute",
+			Message: "tokens per minute exceeds reasonable limit (10,000,000)",
+		})
+	}
+	if limits.MaxConcurrent > 10000 {
+		errs = append(errs, FieldError{
+			Field:   prefix + ".max_concurrent",
+			Message: "max concurrent exceeds reasonable limit (10,000)",
+		})
+	}
+	if limits.MaxConcurrentStreams > 10000 {
+		errs = append(errs, FieldError{
+			Field:   prefix + ".max_concurrent_streams",
+			Message: "max concurrent streams exceeds reasonable limit (10,000)",
+		})
+	}
+
+	// Validate per-model limits
+	for model, modelLimits := range limits.Models {
+		modelPrefix := fmt.Sprintf("%s.models.%s", prefix, model)
+		if len(modelLimits.Models) > 0 {
+			errs = append(errs, FieldError{
+				Field:   modelPrefix + ".models",
+				Message: "per-model limits cannot be nested",
+			})
+		}
+		if modelLimits.MaxConcurrent != 0 {
+			errs = append(errs, FieldError{
+				Field:   modelPrefix + ".max_concurrent",
+				Message: "max concurrent cannot be set per model",
 			})
 		}
+		if modelLimits.MaxConcurrentStreams != 0 {
+			errs = append(errs, FieldError{
+				Field:   modelPrefix + ".max_concurrent_streams",
+				Message: "max concurrent streams cannot be set per model",
+			})
+		}
+		errs = append(errs, validateRateLimits(modelPrefix, &modelLimits)...)
+	}
+
+	return errs
+}
+
+// validateRedis validates Redis connection settings.
+func validateRedis(prefix string, cfg *RateLimitsRedisConfig) []FieldError {
+	var errs []FieldError
+
+	if cfg.Address == "" {
+		errs = append(errs, FieldError{
+			Field:   prefix + ".address",
+			Message: "Redis address is required when backend is 'redis'",
+		})
+	}
+	if cfg.DB < 0 {
+		errs = append(errs, FieldError{
+			Field:   prefix + ".db",
+			Message: "database number must be non-negative",
+		})
+	}
+	if cfg.PoolSize < 0 {
+		errs = append(errs, FieldError{
+			Field:   prefix + ".pool_size",
+			Message: "pool size must be non-negative",
+		})
+	}
+	if cfg.DialTimeout < 0 || cfg.OpTimeout < 0 || cfg.FallbackRetry < 0 {
+		errs = append(errs, FieldError{
+			Field:   prefix,
+			Message: "timeouts must be non-negative",
+		})
+	}
+
+	return errs
+}
+
+// validateEnforcement validates enforcement configuration.
+func validateEnforcement(cfg *EnforcementConfig) []FieldError {
+	var errs []FieldError
+
+	// Validate action
+	validActions := map[string]bool{"block": true, "queue": true, "downgrade": true, "alert": true}
+	if cfg.Action != "" && !validActions[cfg.Action] {
+		errs = append(errs, FieldError{
+			Field:   "limits.enforcement.action",
+			Message: fmt.Sprintf("invalid action %q: must be 'block', 'queue', 'downgrade', or 'alert'", cfg.Action),
+		})
+	}
+
+	// Validate queue configuration
+	if cfg.QueueDepth < 0 {
+		errs = append(errs, FieldError{
+			Field:   "limits.enforcement.queue_depth",
+			Message: "queue depth must be non-negative",
+		})
+	}
+	if cfg.QueueDepth > 100000 {
+		errs = append(errs, FieldError{
+			Field:   "limits.enforcement.queue_depth",
+			Message: "queue depth exceeds reasonable limit (100,000)",
+		})
+	}
+	if cfg.QueueTimeout < 0 {
+		errs = append(errs, FieldError{
+			Field:   "limits.enforcement.queue_timeout",
+			Message: "queue timeout must be positive",
+		})
+	}
+
+	// Validate priority tiers
+	if _, ok := cfg.PriorityTiers[""]; ok {
+		errs = append(errs, FieldError{
+			Field:   "limits.enforcement.priority_tiers",
+			Message: "priority tier name cannot be empty",
+		})
+	}
+
+	// Validate model downgrades (ensure no circular references)
+	if len(cfg.ModelDowngrades) > 0 {
+		visited := make(map[string]bool)
+		for model := range cfg.ModelDowngrades {
+			if err := checkCircularDowngrade(model, cfg.ModelDowngrades, visited); err != nil {
+				errs = append(errs, FieldError{
+					Field:   "limits.enforcement.model_downgrades",
+					Message: err.Error(),
+				})
+				break // Only report one circular reference error
+			}
+		}
+	}
 
-		// Validate min healthy providers
-		if cfg.Health.MinHealthyProviders < 0 {
+	return errs
+}
+
+// validatePriorityClasses validates that the priority classes of API keys
+// are configured priority tiers.
+func validatePriorityClasses(cfg *Config) []FieldError {
+	var errs []FieldError
+	for i, key := range cfg.Security.Authentication.Keys {
+		if key.PriorityClass == "" {
+			continue
+		}
+		if _, ok := cfg.Limits.Enforcement.PriorityTiers[key.PriorityClass]; !ok {
 			errs = append(errs, FieldError{
-				Field:   "telemetry.health.min_healthy_providers",
-				Message: "min healthy providers must be non-negative",
+				Field:   fmt.Sprintf("security.authentication.keys[%d].priority_class", i),
+				Message: fmt.Sprintf("unknown priority class %q: must be a tier in limits.enforcement.priority_tiers", key.PriorityClass),
 			})
 		}
 	}
+	return errs
+}
+
+// validateTokens validates token estimation configuration. The anthropic
+// estimator requires a configured Anthropic provider.
+func validateTokens(cfg *Config) []FieldError {
+	var errs []FieldError
+	tokens := &cfg.Processing.Tokens
+
+	if tokens.Timeout < 0 {
+		errs = append(errs, FieldError{
+			Field:   "processing.tokens.timeout",
+			Message: "timeout must be non-negative",
+		})
+	}
 
+	if tokens.Estimator != "anthropic" {
+		return errs
+	}
+	if _, ok := cfg.Providers[tokens.Provider]; !ok {
+		errs = append(errs, FieldError{
+			Field:   "processing.tokens.provider",
+			Message: fmt.Sprintf("unknown provider %q: the anthropic estimator requires a configured Anthropic provider", tokens.Provider),
+		})
+	}
 	return errs
 }
 
-// validateLimits validates limits configuration.
-func validateLimits(cfg *LimitsConfig) []FieldError {
+// builtinPIITypes are the PII types detected by the content analyzer's
+// built-in patterns.
+var builtinPIITypes = map[string]bool{
+	"email":       true,
+	"phone":       true,
+	"ssn":         true,
+	"credit_card": true,
+	"ip_address":  true,
+}
+
+// validateCustomPII validates custom PII types. Each type needs a unique
+// name that does not shadow a built-in type, and a valid pattern or at
+// least one keyword.
+func validateCustomPII(custom []CustomPIIConfig) []FieldError {
 	var errs []FieldError
+	seen := make(map[string]bool)
 
-	// Validate budgets configuration
-	if cfg.Budgets.Enabled {
-		// Validate alert threshold
-		if cfg.Budgets.AlertThreshold < 0.0 || cfg.Budgets.AlertThreshold > 1.0 {
+	for i, pii := range custom {
+		prefix := fmt.Sprintf("processing.content.pii.custom[%d]", i)
+
+		switch {
+		case pii.Name == "":
 			errs = append(errs, FieldError{
-				Field:   "limits.budgets.alert_threshold",
-				Message: "alert threshold must be between 0.0 and 1.0",
+				Field:   prefix + ".name",
+				Message: "name is required",
+			})
+		case builtinPIITypes[pii.Name]:
+			errs = append(errs, FieldError{
+				Field:   prefix + ".name",
+				Message: fmt.Sprintf("name %q collides with a built-in PII type", pii.Name),
+			})
+		case seen[pii.Name]:
+			errs = append(errs, FieldError{
+				Field:   prefix + ".name",
+				Message: fmt.Sprintf("duplicate custom PII type %q", pii.Name),
 			})
 		}
+		seen[pii.Name] = true
 
-		// Validate per-API key budgets
-		for apiKey, limits := range cfg.Budgets.ByAPIKey {
-			prefix := fmt.Sprintf("limits.budgets.by_api_key.%s", apiKey)
-			errs = append(errs, validateBudgetLimits(prefix, &limits)...)
+		if pii.Pattern == "" && len(pii.Keywords) == 0 {
+			errs = append(errs, FieldError{
+				Field:   prefix,
+				Message: "pattern or keywords is required",
+			})
 		}
-
-		// Validate per-user budgets
-		for user, limits := range cfg.Budgets.ByUser {
-			prefix := fmt.Sprintf("limits.budgets.by_user.%s", user)
-			errs = append(errs, validateBudgetLimits(prefix, &limits)...)
+		if pii.Pattern != "" {
+			if re, err := regexp.Compile(pii.Pattern); err != nil {
+				errs = append(errs, FieldError{
+					Field:   prefix + ".pattern",
+					Message: fmt.Sprintf("invalid pattern: %v", err),
+				})
+			} else if re.MatchString("") {
+				errs = append(errs, FieldError{
+					Field:   prefix + ".pattern",
+					Message: "pattern must not match empty text",
+				})
+			}
+		}
+		for j, keyword := range pii.Keywords {
+			if strings.TrimSpace(keyword) == "" {
+				errs = append(errs, FieldError{
+					Field:   fmt.Sprintf("%s.keywords[%d]", prefix, j),
+					Message: "keyword must not be empty",
+				})
+			}
 		}
 
-		// Validate per-team budgets
-		for team, limits := range cfg.Budgets.ByTeam {
-			prefix := fmt.Sprintf("limits.budgets.by_team.%s", team)
-			errs = append(errs, validateBudgetLimits(prefix, &limits)...)
+		switch pii.Severity {
+		case "", "low", "medium", "high", "critical":
+		default:
+			errs = append(errs, FieldError{
+				Field:   prefix + ".severity",
+				Message: fmt.Sprintf("invalid severity %q: must be one of low, medium, high, critical", pii.Severity),
+			})
 		}
 	}
+	return errs
+}
 
-	// Validate rate limits configuration
-	if cfg.RateLimits.Enabled {
-		// Validate per-API key rate limits
-		for apiKey, limits := range cfg.RateLimits.ByAPIKey {
-			prefix := fmt.Sprintf("limits.rate_limits.by_api_key.%s", apiKey)
-			errs = append(errs, validateRateLimits(prefix, &limits)...)
+// secretTypes are the secret types detected by the content analyzer.
+var secretTypes = map[string]bool{
+	"aws_access_key":    true,
+	"aws_secret_key":    true,
+	"github_token":      true,
+	"slack_token":       true,
+	"private_key":       true,
+	"jwt":               true,
+	"connection_string": true,
+	"generic_secret":    true,
+}
+
+// validateSecretDetection validates secret detection configuration.
+func validateSecretDetection(cfg *SecretDetectionConfig) []FieldError {
+	var errs []FieldError
+
+	for i, secretType := range cfg.Types {
+		if !secretTypes[secretType] {
+			errs = append(errs, FieldError{
+				Field:   fmt.Sprintf("processing.content.secrets.types[%d]", i),
+				Message: fmt.Sprintf("unknown secret type %q", secretType),
+			})
 		}
+	}
 
-		// Validate per-user rate limits
-		for user, limits := range cfg.RateLimits.ByUser {
-			prefix := fmt.Sprintf("limits.rate_limits.by_user.%s", user)
-			errs = append(errs, validateRateLimits(prefix, &limits)...)
+	if cfg.MinEntropy < 0 {
+		errs = append(errs, FieldError{
+			Field:   "processing.content.secrets.min_entropy",
+			Message: "min_entropy must be non-negative",
+		})
+	}
+	return errs
+}
+
+// sensitiveCategories are the sensitive content categories with built-in
+// terms. hate_speech and adult_content are accepted as the former names of
+// hate and sexual.
+var sensitiveCategories = map[string]bool{
+	"profanity":     true,
+	"violence":      true,
+	"self_harm":     true,
+	"sexual":        true,
+	"hate":          true,
+	"harassment":    true,
+	"hate_speech":   true,
+	"adult_content": true,
+}
+
+// validateSensitiveCategories validates sensitive content categories. Each
+// category must be built in or have a wordlist.
+func validateSensitiveCategories(cfg *SensitiveConfig) []FieldError {
+	var errs []FieldError
+
+	for i, category := range cfg.Categories {
+		if _, ok := cfg.Wordlists[category]; !ok && !sensitiveCategories[category] {
+			errs = append(errs, FieldError{
+				Field:   fmt.Sprintf("processing.content.sensitive.categories[%d]", i),
+				Message: fmt.Sprintf("unknown category %q: must be a built-in category or have a wordlist", category),
+			})
 		}
+	}
 
-		// Validate per-team rate limits
-		for team, limits := range cfg.RateLimits.ByTeam {
-			prefix := fmt.Sprintf("limits.rate_limits.by_team.%s", team)
-			errs = append(errs, validateRateLimits(prefix, &limits)...)
+	categories := make([]string, 0, len(cfg.Wordlists))
+	for category := range cfg.Wordlists {
+		categories = append(categories, category)
+	}
+	slices.Sort(categories)
+	for _, category := range categories {
+		if cfg.Wordlists[category] == "" {
+			errs = append(errs, FieldError{
+				Field:   fmt.Sprintf("processing.content.sensitive.wordlists.%s", category),
+				Message: "wordlist path is required",
+			})
 		}
 	}
 
-	// Validate enforcement configuration
-	errs = append(errs, validateEnforcement(&cfg.Enforcement)...)
+	if cfg.MinScore < 0 || cfg.MinScore > 1 {
+		errs = append(errs, FieldError{
+			Field:   "processing.content.sensitive.min_score",
+			Message: "min_score must be between 0.0 and 1.0",
+		})
+	}
+	return errs
+}
 
-	// Validate storage configuration
-	errs = append(errs, validateLimitsStorage(&cfg.Storage)...)
+// validateInjectionClassifier validates prompt injection classifier
+// configuration. The proxy has no classifier backend, so enabling the
+// classifier is an error rather than a setting without effect.
+func validateInjectionClassifier(cfg *InjectionClassifierConfig) []FieldError {
+	var errs []FieldError
+	if !cfg.Enabled {
+		return errs
+	}
 
+	errs = append(errs, FieldError{
+		Field:   "processing.content.injection.classifier.enabled",
+		Message: "no injection classifier backend is available: classifiers can only be registered by programs embedding the processing pipeline (content.Analyzer.SetInjectionClassifier)",
+	})
+	if cfg.Threshold < 0 || cfg.Threshold > 1 {
+		errs = append(errs, FieldError{
+			Field:   "processing.content.injection.classifier.threshold",
+			Message: "threshold must be between 0 and 1",
+		})
+	}
+	if cfg.Mode != "" && cfg.Mode != "inline" && cfg.Mode != "shadow" {
+		errs = append(errs, FieldError{
+			Field:   "processing.content.injection.classifier.mode",
+			Message: fmt.Sprintf("invalid mode %q: must be inline or shadow", cfg.Mode),
+		})
+	}
+	if cfg.Timeout < 0 {
+		errs = append(errs, FieldError{
+			Field:   "processing.content.injection.classifier.timeout",
+			Message: "timeout must be non-negative",
+		})
+	}
 	return errs
 }
 
-// validateBudgetLimits validates budget limit values.
-func validateBudgetLimits(prefix string, limits *BudgetLimits) []FieldError {
+// validateInjectionFeed validates injection pattern feed configuration. The
+// feed must be served over HTTPS and signed.
+func validateInjectionFeed(cfg *InjectionFeedConfig) []FieldError {
 	var errs []FieldError
+	if !cfg.Enabled {
+		return errs
+	}
 
-	if limits.Hourly < 0 {
+	if u, err := url.Parse(cfg.URL); err != nil || u.Scheme != "https" || u.Host == "" {
 		errs = append(errs, FieldError{
-			Field:   prefix + ".hourly",
-			Message: "hourly budget must be non-negative",
+			Field:   "processing.content.injection.feed.url",
+			Message: fmt.Sprintf("invalid URL %q: must be an https URL", cfg.URL),
 		})
 	}
-	if limits.Daily < 0 {
+	if cfg.PublicKey == "" {
 		errs = append(errs, FieldError{
-			Field:   prefix + ".daily",
-			Message: "daily budget must be non-negative",
+			Field:   "processing.content.injection.feed.public_key",
+			Message: "public_key is required when the feed is enabled",
 		})
 	}
-	if limits.Monthly < 0 {
+	if cfg.RefreshInterval < 0 {
 		errs = append(errs, FieldError{
-			Field:   prefix + ".monthly",
-			Message: "monthly budget must be non-negative",
+			Field:   "processing.content.injection.feed.refresh_interval",
+			Message: "refresh_interval must be non-negative",
 		})
 	}
-
-	// Validate that smaller windows don't exceed larger windows
-	if limits.Hourly > 0 && limits.Daily > 0 && limits.Hourly > limits.Daily {
+	if cfg.Timeout < 0 {
 		errs = append(errs, FieldError{
-			Field:   prefix + ".hourly",
-			Message: "hourly budget cannot exceed daily budget",
+			Field:   "processing.content.injection.feed.timeout",
+			Message: "timeout must be non-negative",
 		})
 	}
-	if limits.Daily > 0 && limits.Monthly > 0 && limits.Daily > limits.Monthly {
+	return errs
+}
+
+// validateDLP validates external DLP service configuration.
+func validateDLP(cfg *DLPConfig) []FieldError {
+	var errs []FieldError
+	if !cfg.Enabled {
+		return errs
+	}
+
+	switch cfg.Backend {
+	case "", "presidio", "http":
+		if cfg.URL == "" {
+			errs = append(errs, FieldError{
+				Field:   "processing.content.dlp.url",
+				Message: "url is required for the presidio and http backends",
+			})
+		}
+	case "google":
+		if cfg.ProjectID == "" {
+			errs = append(errs, FieldError{
+				Field:   "processing.content.dlp.project_id",
+				Message: "project_id is required for the google backend",
+			})
+		}
+	default:
 		errs = append(errs, FieldError{
-			Field:   prefix + ".daily",
-			Message: "daily budget cannot exceed monthly budget",
+			Field:   "processing.content.dlp.backend",
+			Message: fmt.Sprintf("invalid backend %q: must be presidio, google, or http", cfg.Backend),
 		})
 	}
 
+	if cfg.URL != "" {
+		if u, err := url.Parse(cfg.URL); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, FieldError{
+				Field:   "processing.content.dlp.url",
+				Message: fmt.Sprintf("invalid URL %q", cfg.URL),
+			})
+		}
+	}
+	if cfg.MinScore < 0 || cfg.MinScore > 1 {
+		errs = append(errs, FieldError{
+			Field:   "processing.content.dlp.min_score",
+			Message: "min_score must be between 0 and 1",
+		})
+	}
+	if cfg.Timeout < 0 {
+		errs = append(errs, FieldError{
+			Field:   "processing.content.dlp.timeout",
+			Message: "timeout must be non-negative",
+		})
+	}
+	if cfg.FailureThreshold < 0 {
+		errs = append(errs, FieldError{
+			Field:   "processing.content.dlp.failure_threshold",
+			Message: "failure_threshold must be non-negative",
+		})
+	}
+	if cfg.Cooldown < 0 {
+		errs = append(errs, FieldError{
+			Field:   "processing.content.dlp.cooldown",
+			Message: "cooldown must be non-negative",
+		})
+	}
 	return errs
 }
 
-// validateRateLimits validates rate limit values.
-func validateRateLimits(prefix string, limits *RateLimits) []FieldError {
+// validateTopics validates topic similarity configuration.
+func validateTopics(cfg *TopicsConfig) []FieldError {
 	var errs []FieldError
+	if !cfg.Enabled {
+		return errs
+	}
 
-	if limits.RequestsPerSecond < 0 {
+	switch cfg.Embedder {
+	case "", "local":
+	case "provider":
+		if u, err := url.Parse(cfg.URL); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, FieldError{
+				Field:   "processing.content.topics.url",
+				Message: fmt.Sprintf("invalid URL %q: the provider embedder requires an embeddings URL", cfg.URL),
+			})
+		}
+		if cfg.Model == "" {
+			errs = append(errs, FieldError{
+				Field:   "processing.content.topics.model",
+				Message: "model is required for the provider embedder",
+			})
+		}
+	default:
 		errs = append(errs, FieldError{
-			Field:   prefix + ".requests_per_second",
-			Message: "requests per second must be non-negative",
+			Field:   "processing.content.topics.embedder",
+			Message: fmt.Sprintf("invalid embedder %q: must be local or provider", cfg.Embedder),
 		})
 	}
-	if limits.RequestsPerMinute < 0 {
+
+	if cfg.Timeout < 0 {
 		errs = append(errs, FieldError{
-			Field:   prefix + ".requests_per_minute",
-			Message: "requests per minute must be non-negative",
+			Field:   "processing.content.topics.timeout",
+			Message: "timeout must be non-negative",
 		})
 	}
-	if limits.RequestsPerHour < 0 {
+	if cfg.Threshold < 0 || cfg.Threshold > 1 {
 		errs = append(errs, FieldError{
-			Field:   prefix + ".requests_per_hour",
-			Message: "requests per hour must be non-negative",
+			Field:   "processing.content.topics.threshold",
+			Message: "threshold must be between 0 and 1",
 		})
 	}
-	if limits.TokensPerMinute < 0 {
+	if len(cfg.Banned) == 0 && len(cfg.Allowed) == 0 {
 		errs = append(errs, FieldError{
-			Field:   prefix + ".tokens_per_minute",
-			Message: "tokens per minute must be non-negative",
+			Field:   "processing.content.topics",
+			Message: "at least one banned or allowed topic is required",
 		})
 	}
-	if limits.TokensPerHour < 0 {
-		errs = append(errs, FieldError{
-			Field:   prefix + ".tokens_per_hour",
-			Message: "tokens per hour must be non-negative",
-		})
+
+	names := make(map[string]bool)
+	for _, list := range []struct {
+		kind   string
+		topics []TopicConfig
+	}{{"banned", cfg.Banned}, {"allowed", cfg.Allowed}} {
+		for i, topic := range list.topics {
+			field := fmt.Sprintf("processing.content.topics.%s[%d]", list.kind, i)
+			switch {
+			case topic.Name == "":
+				errs = append(errs, FieldError{Field: field + ".name", Message: "name is required"})
+			case names[topic.Name]:
+				errs = append(errs, FieldError{Field: field + ".name", Message: fmt.Sprintf("duplicate topic %q", topic.Name)})
+			}
+			names[topic.Name] = true
+			if len(topic.Examples) == 0 {
+				errs = append(errs, FieldError{Field: field + ".examples", Message: "at least one example is required"})
+			}
+		}
+	}
+	return errs
+}
+
+// entityTypes are the recognized named entity types.
+var entityTypes = map[string]bool{
+	"organization": true,
+	"person":       true,
+	"location":     true,
+}
+
+// validateEntities validates named entity recognition configuration.
+func validateEntities(cfg *EntitiesConfig) []FieldError {
+	var errs []FieldError
+
+	for i, entityType := range cfg.Types {
+		if !entityTypes[entityType] {
+			errs = append(errs, FieldError{
+				Field:   fmt.Sprintf("processing.content.entities.types[%d]", i),
+				Message: fmt.Sprintf("unknown entity type %q: must be organization, person, or location", entityType),
+			})
+		}
+	}
+
+	types := make([]string, 0, len(cfg.Gazetteers))
+	for entityType := range cfg.Gazetteers {
+		types = append(types, entityType)
+	}
+	slices.Sort(types)
+	for _, entityType := range types {
+		field := fmt.Sprintf("processing.content.entities.gazetteers.%s", entityType)
+		switch {
+		case !entityTypes[entityType]:
+			errs = append(errs, FieldError{
+				Field:   field,
+				Message: fmt.Sprintf("unknown entity type %q: must be organization, person, or location", entityType),
+			})
+		case cfg.Gazetteers[entityType] == "":
+			errs = append(errs, FieldError{Field: field, Message: "gazetteer path is required"})
+		}
+	}
+	return errs
+}
+
+// validateClassification validates data classification configuration.
+// Rule labels and the default label must be configured labels.
+func validateClassification(cfg *ClassificationConfig) []FieldError {
+	var errs []FieldError
+	if !cfg.Enabled {
+		return errs
+	}
+
+	labels := make(map[string]bool, len(cfg.Labels))
+	for i, label := range cfg.Labels {
+		field := fmt.Sprintf("processing.content.classification.labels[%d]", i)
+		switch {
+		case label == "":
+			errs = append(errs, FieldError{Field: field, Message: "label is required"})
+		case labels[label]:
+			errs = append(errs, FieldError{Field: field, Message: fmt.Sprintf("duplicate label %q", label)})
+		}
+		labels[label] = true
 	}
-	if limits.MaxConcurrent < 0 {
+
+	if cfg.Default != "" && !labels[cfg.Default] {
 		errs = append(errs, FieldError{
-			Field:   prefix + ".max_concurrent",
-			Message: "max concurrent must be non-negative",
+			Field:   "processing.content.classification.default",
+			Message: fmt.Sprintf("unknown label %q", cfg.Default),
 		})
 	}
 
-	// Validate reasonable limits
-	if limits.RequestsPerSecond > 100000 {
-		errs = append(errs, FieldError{
-			Field:   prefix + ".requests_per_second",
-			Message: "requests per second exceeds reasonable limit (100,000)",
-		})
+	for i, rule := range cfg.Rules {
+		field := fmt.Sprintf("processing.content.classification.rules[%d]", i)
+		if !labels[rule.Label] {
+			errs = append(errs, FieldError{
+				Field:   field + ".label",
+				Message: fmt.Sprintf("unknown label %q", rule.Label),
+			})
+		}
+		switch rule.PIISeverity {
+		case "", "low", "medium", "high", "critical":
+		default:
+			errs = append(errs, FieldError{
+				Field:   field + ".pii_severity",
+				Message: fmt.Sprintf("invalid severity %q: must be one of low, medium, high, critical", rule.PIISeverity),
+			})
+		}
+		if len(rule.PIITypes) == 0 && rule.PIISeverity == "" && len(rule.SecretTypes) == 0 &&
+			len(rule.SensitiveCategories) == 0 && len(rule.Organizations) == 0 && len(rule.Topics) == 0 {
+			errs = append(errs, FieldError{
+				Field:   field,
+				Message: "rule must match at least one finding",
+			})
+		}
 	}
-	if limits.TokensPerMinute > 10000000 { // 10M tokens/min
-		errs = append(errs, FieldError{
-			Field:   prefix + ".tokens_per_minute",
-			Message: "tokens per minute exceeds reasonable limit (10,000,000)",
-		})
+	return errs
+}
+
+// ValidatePricing validates model pricing, such as that of a pricing
+// catalog. It returns a ValidationError if any validation rules fail.
+func ValidatePricing(pricing map[string]map[string]ModelPricingConfig) error {
+	if errs := validatePricing(pricing, "pricing"); len(errs) > 0 {
+		return ValidationError{Errors: errs}
 	}
-	if limits.MaxConcurrent > 10000 {
-		errs = append(errs, FieldError{
-			Field:   prefix + ".max_concurrent",
-			Message: "max concurrent exceeds reasonable limit (10,000)",
-		})
+	return nil
+}
+
+// validatePricing validates model pricing. Prices must be non-negative, the
+// batch discount a fraction and long context tiers must have a threshold.
+// Field paths are prefixed with prefix.
+func validatePricing(pricing map[string]map[string]ModelPricingConfig, prefix string) []FieldError {
+	var errs []FieldError
+	nonNegative := func(field string, price float64) {
+		if price < 0 {
+			errs = append(errs, FieldError{
+				Field:   field,
+				Message: "price must be non-negative",
+			})
+		}
 	}
 
+	providers := make([]string, 0, len(pricing))
+	for provider := range pricing {
+		providers = append(providers, provider)
+	}
+	slices.Sort(providers)
+	for _, provider := range providers {
+		models := make([]string, 0, len(pricing[provider]))
+		for model := range pricing[provider] {
+			models = append(models, model)
+		}
+		slices.Sort(models)
+
+		for _, model := range models {
+			p := pricing[provider][model]
+			field := fmt.Sprintf("%s.%s.%s", prefix, provider, model)
+			nonNegative(field+".prompt", p.Prompt)
+			nonNegative(field+".completion", p.Completion)
+			nonNegative(field+".cached_prompt", p.CachedPrompt)
+			if p.BatchDiscount < 0 || p.BatchDiscount > 1 {
+				errs = append(errs, FieldError{
+					Field:   field + ".batch_discount",
+					Message: "batch_discount must be between 0 and 1",
+				})
+			}
+			for i, tier := range p.LongContext {
+				tierField := fmt.Sprintf("%s.long_context[%d]", field, i)
+				if tier.AboveTokens <= 0 {
+					errs = append(errs, FieldError{
+						Field:   tierField + ".above_tokens",
+						Message: "above_tokens must be positive",
+					})
+				}
+				nonNegative(tierField+".prompt", tier.Prompt)
+				nonNegative(tierField+".completion", tier.Completion)
+				nonNegative(tierField+".cached_prompt", tier.CachedPrompt)
+			}
+		}
+	}
 	return errs
 }
 
-// validateEnforcement validates enforcement configuration.
-func validateEnforcement(cfg *EnforcementConfig) []FieldError {
+// validatePricingCatalog validates pricing catalog configuration. A remote
+// catalog must be served over HTTPS and signed.
+func validatePricingCatalog(cfg *PricingCatalogConfig) []FieldError {
 	var errs []FieldError
+	if !cfg.Enabled {
+		return errs
+	}
 
-	// Validate action
-	validActions := map[string]bool{"block": true, "queue": true, "downgrade": true, "alert": true}
-	if cfg.Action != "" && !validActions[cfg.Action] {
+	switch {
+	case cfg.URL == "" && cfg.File == "":
 		errs = append(errs, FieldError{
-			Field:   "limits.enforcement.action",
-			Message: fmt.Sprintf("invalid action %q: must be 'block', 'queue', 'downgrade', or 'alert'", cfg.Action),
+			Field:   "processing.costs.catalog",
+			Message: "url or file is required",
 		})
-	}
-
-	// Validate queue configuration
-	if cfg.QueueDepth < 0 {
+	case cfg.URL != "" && cfg.File != "":
 		errs = append(errs, FieldError{
-			Field:   "limits.enforcement.queue_depth",
-			Message: "queue depth must be non-negative",
+			Field:   "processing.costs.catalog",
+			Message: "url and file are mutually exclusive",
 		})
 	}
-	if cfg.QueueDepth > 100000 {
+
+	if cfg.URL != "" {
+		if u, err := url.Parse(cfg.URL); err != nil || u.Scheme != "https" || u.Host == "" {
+			errs = append(errs, FieldError{
+				Field:   "processing.costs.catalog.url",
+				Message: fmt.Sprintf("invalid URL %q: must be an https URL", cfg.URL),
+			})
+		}
+		if cfg.PublicKey == "" {
+			errs = append(errs, FieldError{
+				Field:   "processing.costs.catalog.public_key",
+				Message: "public_key is required for a remote catalog",
+			})
+		}
+	}
+	if cfg.RefreshInterval < 0 {
 		errs = append(errs, FieldError{
-			Field:   "limits.enforcement.queue_depth",
-			Message: "queue depth exceeds reasonable limit (100,000)",
+			Field:   "processing.costs.catalog.refresh_interval",
+			Message: "refresh_interval must be non-negative",
 		})
 	}
-	if cfg.QueueTimeout < 0 {
+	if cfg.Timeout < 0 {
 		errs = append(errs, FieldError{
-			Field:   "limits.enforcement.queue_timeout",
-			Message: "queue timeout must be positive",
+			Field:   "processing.costs.catalog.timeout",
+			Message: "timeout must be non-negative",
 		})
 	}
+	return errs
+}
 
-	// Validate model downgrades (ensure no circular references)
-	if len(cfg.ModelDowngrades) > 0 {
-		visited := make(map[string]bool)
-		for model := range cfg.ModelDowngrades {
-			if err := checkCircularDowngrade(model, cfg.ModelDowngrades, visited); err != nil {
+// analyzerNamePattern matches analyzer and analyzer field names, which are
+// used in policy field paths.
+var analyzerNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+// analyzerFieldTypes are the types of analyzer fields.
+var analyzerFieldTypes = map[string]bool{"string": true, "number": true, "boolean": true, "array": true}
+
+// validateAnalyzers validates custom analyzer configuration. Registered Go
+// analyzer types are checked when the processor is created.
+func validateAnalyzers(analyzers []AnalyzerConfig) []FieldError {
+	var errs []FieldError
+	names := make(map[string]bool, len(analyzers))
+
+	for i, analyzer := range analyzers {
+		prefix := fmt.Sprintf("processing.analyzers[%d]", i)
+
+		switch {
+		case !analyzerNamePattern.MatchString(analyzer.Name):
+			errs = append(errs, FieldError{
+				Field:   prefix + ".name",
+				Message: fmt.Sprintf("invalid name %q: must be a lowercase identifier", analyzer.Name),
+			})
+		case names[analyzer.Name]:
+			errs = append(errs, FieldError{
+				Field:   prefix + ".name",
+				Message: fmt.Sprintf("duplicate analyzer %q", analyzer.Name),
+			})
+		}
+		names[analyzer.Name] = true
+
+		if analyzer.Type == "" || analyzer.Type == "http" {
+			if u, err := url.Parse(analyzer.URL); err != nil || u.Scheme == "" || u.Host == "" {
 				errs = append(errs, FieldError{
-					Field:   "limits.enforcement.model_downgrades",
-					Message: err.Error(),
+					Field:   prefix + ".url",
+					Message: fmt.Sprintf("invalid URL %q", analyzer.URL),
+				})
+			}
+		}
+
+		for _, stage := range analyzer.Stages {
+			if stage != "request" && stage != "response" {
+				errs = append(errs, FieldError{
+					Field:   prefix + ".stages",
+					Message: fmt.Sprintf("invalid stage %q: must be request or response", stage),
+				})
+			}
+		}
+
+		if len(analyzer.Fields) == 0 {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".fields",
+				Message: "at least one field is required",
+			})
+		}
+		for _, field := range slices.Sorted(maps.Keys(analyzer.Fields)) {
+			if !analyzerNamePattern.MatchString(field) {
+				errs = append(errs, FieldError{
+					Field:   prefix + ".fields",
+					Message: fmt.Sprintf("invalid field name %q: must be a lowercase identifier", field),
+				})
+			}
+			if !analyzerFieldTypes[analyzer.Fields[field]] {
+				errs = append(errs, FieldError{
+					Field:   prefix + ".fields." + field,
+					Message: fmt.Sprintf("invalid type %q: must be string, number, boolean, or array", analyzer.Fields[field]),
 				})
-				break // Only report one circular reference error
 			}
 		}
-	}
 
+		if analyzer.Timeout < 0 {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".timeout",
+				Message: "timeout must be non-negative",
+			})
+		}
+	}
 	return errs
 }
 
@@ -693,12 +2901,52 @@ func checkCircularDowngrade(model string, downgrades map[string]string, visited
 	return nil
 }
 
+// validatePostgres validates PostgreSQL connection settings.
+func validatePostgres(prefix string, cfg *PostgresConfig) []FieldError {
+	var errs []FieldError
+
+	if cfg.Host == "" {
+		errs = append(errs, FieldError{
+			Field:   prefix + ".host",
+			Message: "PostgreSQL host is required when backend is 'postgres'",
+		})
+	}
+	if cfg.Port < 1 || cfg.Port > 65535 {
+		errs = append(errs, FieldError{
+			Field:   prefix + ".port",
+			Message: "PostgreSQL port must be between 1 and 65535",
+		})
+	}
+	if cfg.Database == "" {
+		errs = append(errs, FieldError{
+			Field:   prefix + ".database",
+			Message: "PostgreSQL database is required when backend is 'postgres'",
+		})
+	}
+	if cfg.User == "" {
+		errs = append(errs, FieldError{
+			Field:   prefix + ".user",
+			Message: "PostgreSQL user is required when backend is 'postgres'",
+		})
+	}
+	// Password can be empty if using other auth methods
+	validSSLModes := map[string]bool{"disable": true, "require": true, "verify-ca": true, "verify-full": true}
+	if !validSSLModes[cfg.SSLMode] {
+		errs = append(errs, FieldError{
+			Field:   prefix + ".ssl_mode",
+			Message: fmt.Sprintf("invalid SSL mode %q: must be 'disable', 'require', 'verify-ca', or 'verify-full'", cfg.SSLMode),
+		})
+	}
+
+	return errs
+}
+
 // validateLimitsStorage validates limits storage configuration.
 func validateLimitsStorage(cfg *LimitsStorageConfig) []FieldError {
 	var errs []FieldError
 
 	// Validate backend
-	validBackends := map[string]bool{"memory": true, "sqlite": true}
+	validBackends := map[string]bool{"memory": true, "sqlite": true, "postgres": true, "redis": true}
 	if cfg.Backend == "" {
 		errs = append(errs, FieldError{
 			Field:   "limits.storage.backend",
@@ -707,7 +2955,24 @@ func validateLimitsStorage(cfg *LimitsStorageConfig) []FieldError {
 	} else if !validBackends[cfg.Backend] {
 		errs = append(errs, FieldError{
 			Field:   "limits.storage.backend",
-			Message: fmt.Sprintf("invalid backend %q: must be 'memory' or 'sqlite'", cfg.Backend),
+			Message: fmt.Sprintf("invalid backend %q: must be 'memory', 'sqlite', 'postgres', or 'redis'", cfg.Backend),
+		})
+	}
+	if cfg.SnapshotInterval < 0 {
+		errs = append(errs, FieldError{
+			Field:   "limits.storage.snapshot_interval",
+			Message: "snapshot interval must be positive",
+		})
+	}
+	if cfg.MaxStaleness < 0 {
+		errs = append(errs, FieldError{
+			Field:   "limits.storage.max_staleness",
+			Message: "max staleness must be non-negative",
+		})
+	} else if cfg.MaxStaleness > 0 && cfg.MaxStaleness < cfg.SnapshotInterval {
+		errs = append(errs, FieldError{
+			Field:   "limits.storage.max_staleness",
+			Message: fmt.Sprintf("max staleness must be at least the snapshot interval (%s)", cfg.SnapshotInterval),
 		})
 	}
 
@@ -726,6 +2991,10 @@ func validateLimitsStorage(cfg *LimitsStorageConfig) []FieldError {
 				Message: "snapshot interval must be positive",
 			})
 		}
+	case "postgres":
+		errs = append(errs, validatePostgres("limits.storage.postgres", &cfg.Postgres)...)
+	case "redis":
+		errs = append(errs, validateRedis("limits.storage.redis", &cfg.Redis)...)
 	case "memory":
 		if cfg.Memory.MaxEntries < 0 {
 			errs = append(errs, FieldError{
@@ -739,6 +3008,12 @@ func validateLimitsStorage(cfg *LimitsStorageConfig) []FieldError {
 				Message: "cleanup interval must be positive",
 			})
 		}
+		if cfg.Memory.SnapshotInterval < 0 {
+			errs = append(errs, FieldError{
+				Field:   "limits.storage.memory.snapshot_interval",
+				Message: "snapshot interval must be positive",
+			})
+		}
 	}
 
 	return errs
@@ -781,5 +3056,191 @@ func validateSecurity(cfg *SecurityConfig) []FieldError {
 		}
 	}
 
+	// Validate admin keys
+	names := make(map[string]bool)
+	for i, key := range cfg.Admin.Keys {
+		prefix := fmt.Sprintf("security.admin.keys[%d]", i)
+		if key.Name == "" {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".name",
+				Message: "admin key name is required",
+			})
+		} else if names[key.Name] {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".name",
+				Message: fmt.Sprintf("duplicate admin key name %q", key.Name),
+			})
+		}
+		names[key.Name] = true
+		if len(key.Key) < 16 {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".key",
+				Message: "admin key must be at least 16 characters",
+			})
+		}
+	}
+
+	// Validate API key concurrency limits
+	for i, key := range cfg.Authentication.Keys {
+		prefix := fmt.Sprintf("security.authentication.keys[%d]", i)
+		if key.MaxConcurrent < 0 {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".max_concurrent",
+				Message: "max concurrent must be non-negative",
+			})
+		}
+		if key.MaxConcurrentStreams < 0 {
+			errs = append(errs, FieldError{
+				Field:   prefix + ".max_concurrent_streams",
+				Message: "max concurrent streams must be non-negative",
+			})
+		}
+	}
+
+	return errs
+}
+
+// validateAdminListener validates the admin listener, its TLS
+// configuration, and the admin authentication method.
+func validateAdminListener(cfg *Config) []FieldError {
+	var errs []FieldError
+	admin := &cfg.Security.Admin
+
+	if admin.ListenAddress != "" {
+		if _, _, err := net.SplitHostPort(admin.ListenAddress); err != nil {
+			errs = append(errs, FieldError{
+				Field:   "security.admin.listen_address",
+				Message: fmt.Sprintf("invalid listen address: %v", err),
+			})
+		} else if admin.ListenAddress == cfg.Proxy.ListenAddress {
+			errs = append(errs, FieldError{
+				Field:   "security.admin.listen_address",
+				Message: "admin listen address must differ from proxy.listen_address",
+			})
+		}
+	}
+
+	if admin.TLS.Enabled {
+		if admin.ListenAddress == "" {
+			errs = append(errs, FieldError{
+				Field:   "security.admin.tls.enabled",
+				Message: "admin TLS requires a separate admin listener (security.admin.listen_address)",
+			})
+		}
+		if admin.TLS.CertFile == "" {
+			errs = append(errs, FieldError{
+				Field:   "security.admin.tls.cert_file",
+				Message: "TLS certificate file is required when TLS is enabled",
+			})
+		}
+		if admin.TLS.KeyFile == "" {
+			errs = append(errs, FieldError{
+				Field:   "security.admin.tls.key_file",
+				Message: "TLS key file is required when TLS is enabled",
+			})
+		}
+	}
+	if admin.TLS.MTLS.Enabled {
+		if admin.TLS.MTLS.ClientCAFile == "" {
+			errs = append(errs, FieldError{
+				Field:   "security.admin.tls.mtls.client_ca_file",
+				Message: "mTLS client CA file is required when mTLS is enabled",
+			})
+		}
+		if !admin.TLS.Enabled {
+			errs = append(errs, FieldError{
+				Field:   "security.admin.tls.mtls.enabled",
+				Message: "mTLS requires TLS to be enabled (security.admin.tls.enabled must be true)",
+			})
+		}
+	}
+
+	switch admin.Auth {
+	case "", "key":
+	case "client_cert":
+		// Client certificates must be verified by the admin listener, so
+		// that any certificate presented is trusted
+		if !admin.TLS.MTLS.Enabled {
+			errs = append(errs, FieldError{
+				Field:   "security.admin.auth",
+				Message: "client_cert authentication requires mTLS on the admin listener (security.admin.tls.mtls.enabled)",
+			})
+		} else if admin.TLS.MTLS.ClientAuthType == "request" {
+			errs = append(errs, FieldError{
+				Field:   "security.admin.tls.mtls.client_auth_type",
+				Message: "client_cert authentication requires verified client certificates (require or verify_if_given)",
+			})
+		}
+	default:
+		errs = append(errs, FieldError{
+			Field:   "security.admin.auth",
+			Message: fmt.Sprintf("invalid admin authentication %q (must be key or client_cert)", admin.Auth),
+		})
+	}
+
+	return errs
+}
+
+// ValidateRisk validates a risk configuration, such as one reloaded from a
+// risk file. It returns a ValidationError if any validation rules fail.
+func ValidateRisk(cfg *RiskConfig) error {
+	if errs := validateRisk(cfg, "risk"); len(errs) > 0 {
+		return ValidationError{Errors: errs}
+	}
+	return nil
+}
+
+// validateRisk validates risk configuration. Field paths are prefixed with
+// prefix.
+func validateRisk(cfg *RiskConfig, prefix string) []FieldError {
+	var errs []FieldError
+
+	weights := []struct {
+		name   string
+		weight float64
+	}{
+		{"pii", cfg.Weights.PII},
+		{"injection", cfg.Weights.Injection},
+		{"toxicity", cfg.Weights.Toxicity},
+		{"secrets", cfg.Weights.Secrets},
+		{"token_size", cfg.Weights.TokenSize},
+		{"model_sensitivity", cfg.Weights.ModelSensitivity},
+		{"caller_history", cfg.Weights.CallerHistory},
+	}
+	for _, w := range weights {
+		if w.weight < 0 {
+			errs = append(errs, FieldError{
+				Field:   fmt.Sprintf("%s.weights.%s", prefix, w.name),
+				Message: "weight must be non-negative",
+			})
+		}
+	}
+
+	models := make([]string, 0, len(cfg.ModelSensitivity))
+	for model := range cfg.ModelSensitivity {
+		models = append(models, model)
+	}
+	slices.Sort(models)
+	for _, model := range models {
+		if sensitivity := cfg.ModelSensitivity[model]; sensitivity < 0 || sensitivity > 1 {
+			errs = append(errs, FieldError{
+				Field:   fmt.Sprintf("%s.model_sensitivity.%s", prefix, model),
+				Message: "sensitivity must be between 0.0 and 1.0",
+			})
+		}
+	}
+
+	if cfg.LargePromptTokens < 0 {
+		errs = append(errs, FieldError{
+			Field:   prefix + ".large_prompt_tokens",
+			Message: "large_prompt_tokens must be non-negative",
+		})
+	}
+	if cfg.HistorySize < 0 {
+		errs = append(errs, FieldError{
+			Field:   prefix + ".history_size",
+			Message: "history_size must be non-negative",
+		})
+	}
 	return errs
 }
@@ -91,6 +91,47 @@ func TestValidateLimits_AlertThreshold(t *testing.T) {
 	}
 }
 
+// TestValidateLimits_GraceOverage tests grace overage validation.
+func TestValidateLimits_GraceOverage(t *testing.T) {
+	tests := []struct {
+		name    string
+		grace   float64
+		wantErr bool
+	}{
+		{"valid 0.0", 0.0, false},
+		{"valid 0.05", 0.05, false},
+		{"valid 1.0", 1.0, false},
+		{"invalid negative", -0.05, true},
+		{"invalid > 1.0", 1.5, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &LimitsConfig{
+				Budgets: BudgetsConfig{
+					Enabled:        true,
+					AlertThreshold: 0.8,
+					GraceOverage:   tt.grace,
+				},
+				Storage: LimitsStorageConfig{Backend: "memory"},
+			}
+
+			errs := validateLimits(cfg)
+			hasErr := false
+			for _, err := range errs {
+				if strings.Contains(err.Field, "grace_overage") {
+					hasErr = true
+					break
+				}
+			}
+
+			if hasErr != tt.wantErr {
+				t.Errorf("Expected error: %v, got errors: %v", tt.wantErr, errs)
+			}
+		})
+	}
+}
+
 // TestValidateLimits_BudgetValues tests budget value validation.
 func TestValidateLimits_BudgetValues(t *testing.T) {
 	tests := []struct {
@@ -134,6 +175,20 @@ func TestValidateLimits_BudgetValues(t *testing.T) {
 			wantErr: true,
 			errMsg:  "daily budget cannot exceed monthly budget",
 		},
+		{
+			name: "valid per-model budgets",
+			limits: BudgetLimits{
+				Daily:  100,
+				Models: map[string]BudgetLimits{"o1": {Daily: 20}},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "invalid per-model budget",
+			limits:  BudgetLimits{Models: map[string]BudgetLimits{"o1": {Daily: -5}}},
+			wantErr: true,
+			errMsg:  "daily budget must be non-negative",
+		},
 	}
 
 	for _, tt := range tests {
@@ -190,6 +245,46 @@ func TestValidateLimits_RateLimitValues(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name:    "gcra algorithm",
+			limits:  RateLimits{Algorithm: "gcra", RequestsPerMinute: 60},
+			wantErr: false,
+		},
+		{
+			name:    "invalid algorithm",
+			limits:  RateLimits{Algorithm: "fixed_window", RequestsPerMinute: 60},
+			wantErr: true,
+			errMsg:  "invalid algorithm",
+		},
+		{
+			name:    "invalid per-model algorithm",
+			limits:  RateLimits{Models: map[string]RateLimits{"o1": {Algorithm: "leaky"}}},
+			wantErr: true,
+			errMsg:  "invalid algorithm",
+		},
+		{
+			name:    "burst multiplier",
+			limits:  RateLimits{RequestsPerMinute: 60, BurstMultiplier: 1.5},
+			wantErr: false,
+		},
+		{
+			name:    "negative burst multiplier",
+			limits:  RateLimits{BurstMultiplier: -1},
+			wantErr: true,
+			errMsg:  "burst multiplier must be non-negative",
+		},
+		{
+			name:    "excessive burst multiplier",
+			limits:  RateLimits{BurstMultiplier: 500},
+			wantErr: true,
+			errMsg:  "burst multiplier exceeds reasonable limit",
+		},
+		{
+			name:    "burst multiplier with gcra",
+			limits:  RateLimits{Algorithm: "gcra", BurstMultiplier: 2},
+			wantErr: true,
+			errMsg:  "burst multiplier cannot be used with the gcra algorithm",
+		},
 		{
 			name:    "negative requests per second",
 			limits:  RateLimits{RequestsPerSecond: -1},
@@ -202,6 +297,12 @@ func TestValidateLimits_RateLimitValues(t *testing.T) {
 			wantErr: true,
 			errMsg:  "max concurrent must be non-negative",
 		},
+		{
+			name:    "negative max concurrent streams",
+			limits:  RateLimits{MaxConcurrentStreams: -1},
+			wantErr: true,
+			errMsg:  "max concurrent streams must be non-negative",
+		},
 		{
 			name:    "excessive requests per second",
 			limits:  RateLimits{RequestsPerSecond: 200000},
@@ -220,6 +321,43 @@ func TestValidateLimits_RateLimitValues(t *testing.T) {
 			wantErr: true,
 			errMsg:  "max concurrent exceeds reasonable limit",
 		},
+		{
+			name: "valid per-model rate limits",
+			limits: RateLimits{
+				RequestsPerMinute: 500,
+				Models: map[string]RateLimits{
+					"o1":          {RequestsPerMinute: 10},
+					"gpt-4o-mini": {RequestsPerMinute: 500},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "negative per-model limit",
+			limits:  RateLimits{Models: map[string]RateLimits{"o1": {RequestsPerMinute: -1}}},
+			wantErr: true,
+			errMsg:  "requests per minute must be non-negative",
+		},
+		{
+			name:    "per-model max concurrent",
+			limits:  RateLimits{Models: map[string]RateLimits{"o1": {MaxConcurrent: 2}}},
+			wantErr: true,
+			errMsg:  "max concurrent cannot be set per model",
+		},
+		{
+			name:    "per-model max concurrent streams",
+			limits:  RateLimits{Models: map[string]RateLimits{"o1": {MaxConcurrentStreams: 2}}},
+			wantErr: true,
+			errMsg:  "max concurrent streams cannot be set per model",
+		},
+		{
+			name: "nested per-model limits",
+			limits: RateLimits{Models: map[string]RateLimits{
+				"o1": {Models: map[string]RateLimits{"o1": {RequestsPerMinute: 1}}},
+			}},
+			wantErr: true,
+			errMsg:  "per-model limits cannot be nested",
+		},
 	}
 
 	for _, tt := range tests {
@@ -385,7 +523,7 @@ func TestValidateLimits_StorageBackend(t *testing.T) {
 		},
 		{
 			name:    "invalid backend",
-			storage: LimitsStorageConfig{Backend: "redis"},
+			storage: LimitsStorageConfig{Backend: "etcd"},
 			wantErr: true,
 			errMsg:  "invalid backend",
 		},
@@ -398,6 +536,61 @@ func TestValidateLimits_StorageBackend(t *testing.T) {
 			wantErr: true,
 			errMsg:  "SQLite path is required",
 		},
+		{
+			name: "valid postgres backend",
+			storage: LimitsStorageConfig{
+				Backend: "postgres",
+				Postgres: PostgresConfig{
+					Host:     "db",
+					Port:     5432,
+					Database: "mercator",
+					User:     "mercator",
+					SSLMode:  "require",
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "postgres without host",
+			storage: LimitsStorageConfig{
+				Backend:  "postgres",
+				Postgres: PostgresConfig{Port: 5432, Database: "mercator", User: "mercator", SSLMode: "require"},
+			},
+			wantErr: true,
+			errMsg:  "PostgreSQL host is required",
+		},
+		{
+			name: "valid redis backend",
+			storage: LimitsStorageConfig{
+				Backend: "redis",
+				Redis:   RateLimitsRedisConfig{Address: "redis:6379"},
+			},
+			wantErr: false,
+		},
+		{
+			name:    "redis without address",
+			storage: LimitsStorageConfig{Backend: "redis"},
+			wantErr: true,
+			errMsg:  "Redis address is required",
+		},
+		{
+			name:    "max staleness below snapshot interval",
+			storage: LimitsStorageConfig{Backend: "memory", SnapshotInterval: 10 * time.Second, MaxStaleness: time.Second},
+			wantErr: true,
+			errMsg:  "max staleness must be at least the snapshot interval",
+		},
+		{
+			name:    "negative max staleness",
+			storage: LimitsStorageConfig{Backend: "memory", MaxStaleness: -time.Second},
+			wantErr: true,
+			errMsg:  "max staleness must be non-negative",
+		},
+		{
+			name:    "negative snapshot interval",
+			storage: LimitsStorageConfig{Backend: "memory", SnapshotInterval: -time.Second},
+			wantErr: true,
+			errMsg:  "snapshot interval must be positive",
+		},
 		{
 			name: "memory with negative max entries",
 			storage: LimitsStorageConfig{
@@ -438,6 +631,154 @@ func TestValidateLimits_StorageBackend(t *testing.T) {
 	}
 }
 
+// TestValidateLimits_RateLimitBackend tests rate limit backend validation.
+func TestValidateLimits_RateLimitBackend(t *testing.T) {
+	tests := []struct {
+		name       string
+		rateLimits RateLimitsConfig
+		errMsg     string
+	}{
+		{
+			name:       "memory backend",
+			rateLimits: RateLimitsConfig{Enabled: true, Backend: "memory"},
+		},
+		{
+			name: "redis backend",
+			rateLimits: RateLimitsConfig{
+				Enabled: true,
+				Backend: "redis",
+				Redis:   RateLimitsRedisConfig{Address: "redis:6379"},
+			},
+		},
+		{
+			name:       "redis without address",
+			rateLimits: RateLimitsConfig{Enabled: true, Backend: "redis"},
+			errMsg:     "Redis address is required",
+		},
+		{
+			name:       "invalid backend",
+			rateLimits: RateLimitsConfig{Enabled: true, Backend: "memcached"},
+			errMsg:     "invalid backend",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &LimitsConfig{
+				RateLimits: tt.rateLimits,
+				Storage:    LimitsStorageConfig{Backend: "memory"},
+			}
+
+			errs := validateLimits(cfg)
+			if tt.errMsg == "" {
+				if len(errs) > 0 {
+					t.Errorf("Expected no errors, got: %v", errs)
+				}
+				return
+			}
+
+			found := false
+			for _, err := range errs {
+				if strings.Contains(err.Message, tt.errMsg) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				t.Errorf("Expected error message containing %q, got: %v", tt.errMsg, errs)
+			}
+		})
+	}
+}
+
+// TestValidateLimits_BudgetHierarchy tests nested budget scope validation.
+func TestValidateLimits_BudgetHierarchy(t *testing.T) {
+	tests := []struct {
+		name    string
+		budgets BudgetsConfig
+		errMsg  string
+	}{
+		{
+			name: "valid hierarchy",
+			budgets: BudgetsConfig{
+				ByTeam: map[string]BudgetLimits{"platform": {Daily: 100}},
+				ByOrg:  map[string]BudgetLimits{"acme": {Monthly: 5000}},
+				Hierarchy: BudgetHierarchyConfig{
+					Enabled:         true,
+					TeamOrgs:        map[string]string{"platform": "acme"},
+					Inheritance:     "nearest",
+					AlertThresholds: map[string]float64{"org": 0.9, "team": 0.7},
+				},
+			},
+		},
+		{
+			name:    "invalid inheritance",
+			budgets: BudgetsConfig{Hierarchy: BudgetHierarchyConfig{Inheritance: "some"}},
+			errMsg:  "invalid inheritance",
+		},
+		{
+			name:    "unknown alert threshold level",
+			budgets: BudgetsConfig{Hierarchy: BudgetHierarchyConfig{AlertThresholds: map[string]float64{"region": 0.5}}},
+			errMsg:  "level must be",
+		},
+		{
+			name:    "alert threshold out of range",
+			budgets: BudgetsConfig{Hierarchy: BudgetHierarchyConfig{AlertThresholds: map[string]float64{"team": 1.5}}},
+			errMsg:  "alert threshold must be between",
+		},
+		{
+			name:    "team without organization",
+			budgets: BudgetsConfig{Hierarchy: BudgetHierarchyConfig{TeamOrgs: map[string]string{"platform": ""}}},
+			errMsg:  "organization is required",
+		},
+		{
+			name: "per-model team budget",
+			budgets: BudgetsConfig{
+				ByTeam: map[string]BudgetLimits{
+					"platform": {Daily: 100, Models: map[string]BudgetLimits{"o1": {Daily: 10}}},
+				},
+				Hierarchy: BudgetHierarchyConfig{Enabled: true},
+			},
+			errMsg: "only supported for API keys",
+		},
+		{
+			name:    "negative org budget",
+			budgets: BudgetsConfig{ByOrg: map[string]BudgetLimits{"acme": {Daily: -1}}},
+			errMsg:  "must be non-negative",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.budgets.Enabled = true
+			tt.budgets.AlertThreshold = 0.8
+			cfg := &LimitsConfig{
+				Budgets: tt.budgets,
+				Storage: LimitsStorageConfig{Backend: "memory"},
+			}
+
+			errs := validateLimits(cfg)
+			if tt.errMsg == "" {
+				if len(errs) > 0 {
+					t.Errorf("Expected no errors, got: %v", errs)
+				}
+				return
+			}
+
+			found := false
+			for _, err := range errs {
+				if strings.Contains(err.Message, tt.errMsg) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				t.Errorf("Expected error message containing %q, got: %v", tt.errMsg, errs)
+			}
+		})
+	}
+}
+
 // TestValidateLimits_MultiDimensional tests validation across all dimensions.
 func TestValidateLimits_MultiDimensional(t *testing.T) {
 	cfg := &LimitsConfig{
@@ -474,3 +815,149 @@ func TestValidateLimits_MultiDimensional(t *testing.T) {
 		t.Errorf("Expected no errors for multi-dimensional config, got: %v", errs)
 	}
 }
+
+// TestValidateLimits_BudgetAlerts tests budget alert channel and route validation.
+func TestValidateLimits_BudgetAlerts(t *testing.T) {
+	tests := []struct {
+		name   string
+		alerts BudgetAlertsConfig
+		errMsg string
+	}{
+		{
+			name: "valid alerts",
+			alerts: BudgetAlertsConfig{
+				Channels: []AlertChannelConfig{
+					{Name: "finops", Type: "slack", URL: "https://hooks.slack.com/services/T/B/X"},
+					{Name: "oncall", Type: "pagerduty", RoutingKey: "key"},
+					{Name: "finance", Type: "email", SMTP: SMTPConfig{Host: "smtp.example.com", From: "mercator@example.com"}, To: []string{"finance@example.com"}},
+				},
+				Routes: []AlertRouteConfig{
+					{Dimensions: []string{"team"}, Channels: []string{"finops"}},
+					{Levels: []string{"exceeded"}, Channels: []string{"oncall", "finance"}},
+				},
+			},
+		},
+		{
+			name:   "invalid channel type",
+			alerts: BudgetAlertsConfig{Channels: []AlertChannelConfig{{Name: "sms", Type: "sms"}}},
+			errMsg: "invalid channel type",
+		},
+		{
+			name: "duplicate channel name",
+			alerts: BudgetAlertsConfig{Channels: []AlertChannelConfig{
+				{Name: "hook", Type: "webhook", URL: "https://example.com/a"},
+				{Name: "hook", Type: "webhook", URL: "https://example.com/b"},
+			}},
+			errMsg: "duplicate channel name",
+		},
+		{
+			name:   "pagerduty without routing key",
+			alerts: BudgetAlertsConfig{Channels: []AlertChannelConfig{{Name: "oncall", Type: "pagerduty"}}},
+			errMsg: "routing_key is required",
+		},
+		{
+			name:   "email without recipients",
+			alerts: BudgetAlertsConfig{Channels: []AlertChannelConfig{{Name: "mail", Type: "email", SMTP: SMTPConfig{Host: "smtp", From: "a@example.com"}}}},
+			errMsg: "at least one recipient",
+		},
+		{
+			name:   "route to unknown channel",
+			alerts: BudgetAlertsConfig{Routes: []AlertRouteConfig{{Channels: []string{"missing"}}}},
+			errMsg: "unknown channel",
+		},
+		{
+			name: "invalid route level",
+			alerts: BudgetAlertsConfig{
+				Channels: []AlertChannelConfig{{Name: "hook", Type: "webhook", URL: "https://example.com"}},
+				Routes:   []AlertRouteConfig{{Levels: []string{"critical"}, Channels: []string{"hook"}}},
+			},
+			errMsg: "invalid level",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateBudgetAlerts(&tt.alerts)
+			if tt.errMsg == "" {
+				if len(errs) > 0 {
+					t.Errorf("Expected no errors, got: %v", errs)
+				}
+				return
+			}
+
+			found := false
+			for _, err := range errs {
+				if strings.Contains(err.Message, tt.errMsg) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				t.Errorf("Expected error message containing %q, got: %v", tt.errMsg, errs)
+			}
+		})
+	}
+}
+
+func TestValidateLimits_Exemptions(t *testing.T) {
+	token := strings.Repeat("x", 32)
+	tests := []struct {
+		name       string
+		exemptions LimitsExemptionsConfig
+		errMsg     string
+	}{
+		{
+			name: "valid exemptions",
+			exemptions: LimitsExemptionsConfig{
+				Identifiers:      []LimitExemptionConfig{{Name: "health-check", Identifier: "health-key"}},
+				BreakGlassTokens: []BreakGlassTokenConfig{{Name: "oncall", Token: token}},
+			},
+		},
+		{
+			name:       "exemption without identifier",
+			exemptions: LimitsExemptionsConfig{Identifiers: []LimitExemptionConfig{{Name: "health-check"}}},
+			errMsg:     "identifier is required",
+		},
+		{
+			name: "duplicate exemption name",
+			exemptions: LimitsExemptionsConfig{Identifiers: []LimitExemptionConfig{
+				{Name: "internal", Identifier: "key-1"},
+				{Name: "internal", Identifier: "key-2"},
+			}},
+			errMsg: "duplicate exemption name",
+		},
+		{
+			name:       "break-glass token without name",
+			exemptions: LimitsExemptionsConfig{BreakGlassTokens: []BreakGlassTokenConfig{{Token: token}}},
+			errMsg:     "name is required",
+		},
+		{
+			name:       "short break-glass token",
+			exemptions: LimitsExemptionsConfig{BreakGlassTokens: []BreakGlassTokenConfig{{Name: "oncall", Token: "secret"}}},
+			errMsg:     "at least 32 characters",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateLimitsExemptions(&tt.exemptions)
+			if tt.errMsg == "" {
+				if len(errs) > 0 {
+					t.Errorf("Expected no errors, got: %v", errs)
+				}
+				return
+			}
+
+			found := false
+			for _, err := range errs {
+				if strings.Contains(err.Message, tt.errMsg) {
+					found = true
+					break
+				}
+			}
+			if !found {
+				t.Errorf("Expected error message containing %q, got: %v", tt.errMsg, errs)
+			}
+		})
+	}
+}
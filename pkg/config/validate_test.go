@@ -3,6 +3,7 @@ package config
 import (
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestValidate_ValidConfig(t *testing.T) {
@@ -87,6 +88,41 @@ func TestValidate_ProxyConfig(t *testing.T) {
 			wantError:  true,
 			errorField: "proxy.max_header_bytes",
 		},
+		{
+			name: "valid cost allocation tags",
+			proxy: ProxyConfig{
+				ListenAddress: "127.0.0.1:8080",
+				CostAllocation: CostAllocationConfig{
+					Enabled:     true,
+					AllowedTags: []string{"research", "team/platform"},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "cost allocation tag with whitespace",
+			proxy: ProxyConfig{
+				ListenAddress: "127.0.0.1:8080",
+				CostAllocation: CostAllocationConfig{
+					Enabled:     true,
+					AllowedTags: []string{"research", "cost center"},
+				},
+			},
+			wantError:  true,
+			errorField: "proxy.cost_allocation.allowed_tags[1]",
+		},
+		{
+			name: "duplicate cost allocation tag",
+			proxy: ProxyConfig{
+				ListenAddress: "127.0.0.1:8080",
+				CostAllocation: CostAllocationConfig{
+					Enabled:     true,
+					AllowedTags: []string{"research", "research"},
+				},
+			},
+			wantError:  true,
+			errorField: "proxy.cost_allocation.allowed_tags[1]",
+		},
 	}
 
 	for _, tt := range tests {
@@ -182,6 +218,187 @@ func TestValidate_Providers(t *testing.T) {
 			wantError:  true,
 			errorField: "providers.openai.max_retries",
 		},
+		{
+			name: "bedrock without base URL",
+			providers: map[string]ProviderConfig{
+				"claude": {
+					Type:   "bedrock",
+					Region: "us-east-1",
+					API:    "invoke",
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "invalid type",
+			providers: map[string]ProviderConfig{
+				"claude": {
+					Type: "azure",
+				},
+			},
+			wantError:  true,
+			errorField: "providers.claude.type",
+		},
+		{
+			name: "invalid bedrock API",
+			providers: map[string]ProviderConfig{
+				"bedrock": {
+					API: "chat",
+				},
+			},
+			wantError:  true,
+			errorField: "providers.bedrock.api",
+		},
+		{
+			name: "vertex without base URL",
+			providers: map[string]ProviderConfig{
+				"gemini": {
+					Type:    "vertex",
+					Region:  "europe-west4",
+					Project: "my-project",
+					SafetySettings: map[string]string{
+						"HARM_CATEGORY_HARASSMENT": "BLOCK_ONLY_HIGH",
+					},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "gemini without base URL",
+			providers: map[string]ProviderConfig{
+				"gemini": {
+					APIKey: "key",
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "tgi with prompt format",
+			providers: map[string]ProviderConfig{
+				"llama": {
+					Type:         "tgi",
+					BaseURL:      "http://tgi:8080",
+					PromptFormat: "llama3",
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "replicate without base URL",
+			providers: map[string]ProviderConfig{
+				"replicate": {
+					APIKey: "r8_key",
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "invalid tgi prompt format",
+			providers: map[string]ProviderConfig{
+				"tgi": {
+					BaseURL:      "http://tgi:8080",
+					PromptFormat: "alpaca",
+				},
+			},
+			wantError:  true,
+			errorField: "providers.tgi.prompt_format",
+		},
+		{
+			name: "model routes",
+			providers: map[string]ProviderConfig{
+				"anthropic": {
+					BaseURL: "https://api.anthropic.com/v1",
+					Models: map[string]string{
+						"gpt-4":         "claude-3-5-sonnet-20241022",
+						"default-smart": "claude-3-opus-20240229",
+					},
+				},
+				"vllm": {
+					Type:    "generic",
+					BaseURL: "http://vllm:8000/v1",
+					Models:  map[string]string{"llama-3-70b": ""},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "model routed to two providers",
+			providers: map[string]ProviderConfig{
+				"anthropic": {
+					BaseURL: "https://api.anthropic.com/v1",
+					Models:  map[string]string{"gpt-4": "claude-3-5-sonnet-20241022"},
+				},
+				"bedrock": {
+					Models: map[string]string{"gpt-4": "anthropic.claude-3-5-sonnet-20241022-v2:0"},
+				},
+			},
+			wantError:  true,
+			errorField: "providers.bedrock.models.gpt-4",
+		},
+		{
+			name: "egress proxies",
+			providers: map[string]ProviderConfig{
+				"openai": {
+					BaseURL: "https://api.openai.com/v1",
+					Proxy:   ProviderProxyConfig{URL: "http://proxy.corp.example:3128"},
+				},
+				"vllm": {
+					Type:    "generic",
+					BaseURL: "http://vllm.internal:8000/v1",
+					Proxy: ProviderProxyConfig{
+						URL:     "socks5://egress.corp.example:1080",
+						NoProxy: []string{".internal", "10.0.0.0/8"},
+					},
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "invalid proxy scheme",
+			providers: map[string]ProviderConfig{
+				"openai": {
+					BaseURL: "https://api.openai.com/v1",
+					Proxy:   ProviderProxyConfig{URL: "ftp://proxy.corp.example"},
+				},
+			},
+			wantError:  true,
+			errorField: "providers.openai.proxy.url",
+		},
+		{
+			name: "no_proxy without proxy",
+			providers: map[string]ProviderConfig{
+				"openai": {
+					BaseURL: "https://api.openai.com/v1",
+					Proxy:   ProviderProxyConfig{NoProxy: []string{".internal"}},
+				},
+			},
+			wantError:  true,
+			errorField: "providers.openai.proxy.no_proxy",
+		},
+		{
+			name: "invalid vertex safety threshold",
+			providers: map[string]ProviderConfig{
+				"vertex": {
+					SafetySettings: map[string]string{
+						"HARM_CATEGORY_HARASSMENT": "BLOCK_SOME",
+					},
+				},
+			},
+			wantError:  true,
+			errorField: "providers.vertex.safety_settings.HARM_CATEGORY_HARASSMENT",
+		},
+		{
+			name: "invalid vertex harm category",
+			providers: map[string]ProviderConfig{
+				"vertex": {
+					SafetySettings: map[string]string{
+						"HARASSMENT": "BLOCK_NONE",
+					},
+				},
+			},
+			wantError:  true,
+			errorField: "providers.vertex.safety_settings.HARASSMENT",
+		},
 	}
 
 	for _, tt := range tests {
@@ -405,6 +622,58 @@ func TestValidate_Evidence(t *testing.T) {
 			wantError:  true,
 			errorField: "evidence.retention.days",
 		},
+		{
+			name: "anonymize without prune schedule",
+			evidence: EvidenceConfig{
+				Enabled: true,
+				Backend: "sqlite",
+				SQLite:  SQLiteConfig{Path: "./evidence.db"},
+				Retention: RetentionConfig{
+					Anonymize: AnonymizeConfig{Enabled: true, AfterDays: 30, KeySecret: "anonymize-key"},
+				},
+			},
+			wantError:  true,
+			errorField: "evidence.retention.prune_schedule",
+		},
+		{
+			name: "valid export job",
+			evidence: EvidenceConfig{
+				Enabled: true,
+				Backend: "sqlite",
+				SQLite:  SQLiteConfig{Path: "./evidence.db"},
+				Export: ExportConfig{Jobs: []EvidenceExportJobConfig{
+					{Name: "siem", Schedule: "0 * * * *", Format: "jsonl", Destination: "https://siem.example.com/ingest"},
+				}},
+			},
+			wantError: false,
+		},
+		{
+			name: "export job sftp missing host key",
+			evidence: EvidenceConfig{
+				Enabled: true,
+				Backend: "sqlite",
+				SQLite:  SQLiteConfig{Path: "./evidence.db"},
+				Export: ExportConfig{Jobs: []EvidenceExportJobConfig{
+					{Name: "archive", Schedule: "0 * * * *", Format: "csv", Destination: "sftp://mercator@sftp.example.com/exports", Password: "secret"},
+				}},
+			},
+			wantError:  true,
+			errorField: "evidence.export.jobs[0].host_key",
+		},
+		{
+			name: "export job duplicate name",
+			evidence: EvidenceConfig{
+				Enabled: true,
+				Backend: "sqlite",
+				SQLite:  SQLiteConfig{Path: "./evidence.db"},
+				Export: ExportConfig{Jobs: []EvidenceExportJobConfig{
+					{Name: "siem", Schedule: "0 * * * *", Format: "jsonl", Destination: "https://siem.example.com/ingest"},
+					{Name: "siem", Schedule: "0 * * * *", Format: "jsonl", Destination: "s3://bucket/evidence", Region: "us-east-1"},
+				}},
+			},
+			wantError:  true,
+			errorField: "evidence.export.jobs[1].name",
+		},
 	}
 
 	for _, tt := range tests {
@@ -473,6 +742,165 @@ func TestValidate_Telemetry(t *testing.T) {
 			wantError:  true,
 			errorField: "telemetry.metrics.path",
 		},
+		{
+			name: "audit file sink without path",
+			telemetry: TelemetryConfig{
+				Logging: LoggingConfig{Level: "info", Format: "json"},
+				Audit:   AuditConfig{Enabled: true, Sinks: []AuditSinkConfig{{Type: "stderr"}, {Type: "file"}}},
+			},
+			wantError:  true,
+			errorField: "telemetry.audit.sinks[1].path",
+		},
+		{
+			name: "invalid audit sink type",
+			telemetry: TelemetryConfig{
+				Logging: LoggingConfig{Level: "info", Format: "json"},
+				Audit:   AuditConfig{Enabled: true, Sinks: []AuditSinkConfig{{Type: "syslog"}}},
+			},
+			wantError:  true,
+			errorField: "telemetry.audit.sinks[0].type",
+		},
+		{
+			name: "valid telemetry bus",
+			telemetry: TelemetryConfig{
+				Logging: LoggingConfig{Level: "info", Format: "json"},
+				Audit:   AuditConfig{Enabled: true, Sinks: []AuditSinkConfig{{Type: "stderr"}}},
+				Bus: TelemetryBusConfig{
+					Enabled: true, Type: "nats", URL: "nats://localhost:4222", LogTopic: "mercator.logs", AuditTopic: "mercator.audit",
+					Level: "info", BatchSize: 100, FlushInterval: time.Second, BufferSize: 1000, Backpressure: "drop", Timeout: 10 * time.Second,
+				},
+			},
+			wantError: false,
+		},
+		{
+			name: "telemetry bus audit topic without audit",
+			telemetry: TelemetryConfig{
+				Logging: LoggingConfig{Level: "info", Format: "json"},
+				Bus: TelemetryBusConfig{
+					Enabled: true, Type: "kafka", URL: "http://kafka-rest:8082", LogTopic: "mercator.logs", AuditTopic: "mercator.audit",
+					Level: "info", BatchSize: 100, FlushInterval: time.Second, BufferSize: 1000, Backpressure: "drop", Timeout: 10 * time.Second,
+				},
+			},
+			wantError:  true,
+			errorField: "telemetry.bus.audit_topic",
+		},
+		{
+			name: "invalid telemetry bus backpressure",
+			telemetry: TelemetryConfig{
+				Logging: LoggingConfig{Level: "info", Format: "json"},
+				Bus: TelemetryBusConfig{
+					Enabled: true, Type: "kafka", URL: "http://kafka-rest:8082", LogTopic: "mercator.logs",
+					Level: "info", BatchSize: 100, FlushInterval: time.Second, BufferSize: 1000, Backpressure: "retry", Timeout: 10 * time.Second,
+				},
+			},
+			wantError:  true,
+			errorField: "telemetry.bus.backpressure",
+		},
+		{
+			name: "log sampling rule without rate",
+			telemetry: TelemetryConfig{
+				Logging: LoggingConfig{Level: "info", Format: "json", Sampling: LogSamplingConfig{
+					Enabled: true,
+					Rules:   []LogSamplingRuleConfig{{Message: "request completed", Every: 100}, {Component: "limits"}},
+				}},
+			},
+			wantError:  true,
+			errorField: "telemetry.logging.sampling.rules[1].every",
+		},
+		{
+			name: "valid log outputs",
+			telemetry: TelemetryConfig{
+				Logging: LoggingConfig{Level: "info", Format: "json", Outputs: []LogOutputConfig{
+					{Type: "gelf", Network: "udp", Address: "graylog:12201", Fields: map[string]string{"request_id": "correlation_id", "user": ""}},
+					{Type: "syslog", Network: "tls", Address: "syslog:6514", CAFile: "/etc/ca.pem", Level: "warn", Facility: 16, StaticFields: map[string]string{"env": "prod"}},
+				}},
+			},
+			wantError: false,
+		},
+		{
+			name: "log output ca_file without tls",
+			telemetry: TelemetryConfig{
+				Logging: LoggingConfig{Level: "info", Format: "json", Outputs: []LogOutputConfig{
+					{Type: "syslog", Network: "tcp", Address: "syslog:514", CAFile: "/etc/ca.pem", Facility: 16},
+				}},
+			},
+			wantError:  true,
+			errorField: "telemetry.logging.outputs[0].ca_file",
+		},
+		{
+			name: "invalid log output field name",
+			telemetry: TelemetryConfig{
+				Logging: LoggingConfig{Level: "info", Format: "json", Outputs: []LogOutputConfig{
+					{Type: "gelf", Network: "udp", Address: "graylog:12201", Fields: map[string]string{"request_id": "request id"}},
+				}},
+			},
+			wantError:  true,
+			errorField: "telemetry.logging.outputs[0].fields.request_id",
+		},
+		{
+			name: "invalid log output type",
+			telemetry: TelemetryConfig{
+				Logging: LoggingConfig{Level: "info", Format: "json", Outputs: []LogOutputConfig{
+					{Type: "fluentd", Network: "udp", Address: "fluentd:24224"},
+				}},
+			},
+			wantError:  true,
+			errorField: "telemetry.logging.outputs[0].type",
+		},
+		{
+			name: "negative tenant label budget",
+			telemetry: TelemetryConfig{
+				Logging: LoggingConfig{Level: "info", Format: "json"},
+				Metrics: MetricsConfig{Enabled: true, Path: "/metrics", TenantLabels: MetricsTenantLabelsConfig{Enabled: true, MaxValues: -1}},
+			},
+			wantError:  true,
+			errorField: "telemetry.metrics.tenant_labels.max_values",
+		},
+		{
+			name: "valid SLO objectives",
+			telemetry: TelemetryConfig{
+				Logging: LoggingConfig{Level: "info", Format: "json"},
+				Metrics: MetricsConfig{Enabled: true, Path: "/metrics", SLO: SLOConfig{Objectives: []SLOObjectiveConfig{
+					{Name: "availability", Type: "availability", Target: 0.999, Window: DefaultSLOWindow},
+					{Name: "latency", Type: "latency", Target: 0.99, Threshold: 5 * time.Second, Window: DefaultSLOWindow},
+				}}},
+			},
+			wantError: false,
+		},
+		{
+			name: "latency SLO without threshold",
+			telemetry: TelemetryConfig{
+				Logging: LoggingConfig{Level: "info", Format: "json"},
+				Metrics: MetricsConfig{Enabled: true, Path: "/metrics", SLO: SLOConfig{Objectives: []SLOObjectiveConfig{
+					{Name: "latency", Type: "latency", Target: 0.99, Window: DefaultSLOWindow},
+				}}},
+			},
+			wantError:  true,
+			errorField: "telemetry.metrics.slo.objectives[0].threshold",
+		},
+		{
+			name: "SLO target out of range",
+			telemetry: TelemetryConfig{
+				Logging: LoggingConfig{Level: "info", Format: "json"},
+				Metrics: MetricsConfig{Enabled: true, Path: "/metrics", SLO: SLOConfig{Objectives: []SLOObjectiveConfig{
+					{Name: "availability", Type: "availability", Target: 99.9, Window: DefaultSLOWindow},
+				}}},
+			},
+			wantError:  true,
+			errorField: "telemetry.metrics.slo.objectives[0].target",
+		},
+		{
+			name: "duplicate SLO objective",
+			telemetry: TelemetryConfig{
+				Logging: LoggingConfig{Level: "info", Format: "json"},
+				Metrics: MetricsConfig{Enabled: true, Path: "/metrics", SLO: SLOConfig{Objectives: []SLOObjectiveConfig{
+					{Name: "api", Type: "availability", Target: 0.999, Window: DefaultSLOWindow},
+					{Name: "api", Type: "availability", Target: 0.99, Window: DefaultSLOWindow},
+				}}},
+			},
+			wantError:  true,
+			errorField: "telemetry.metrics.slo.objectives[1].name",
+		},
 		{
 			name: "tracing enabled without endpoint",
 			telemetry: TelemetryConfig{
@@ -589,19 +1017,136 @@ func TestValidate_Security(t *testing.T) {
 			wantError:  true,
 			errorField: "security.tls.mtls.enabled",
 		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			errs := validateSecurity(&tt.security)
-			if tt.wantError && len(errs) == 0 {
-				t.Error("expected validation error, got none")
-			}
-			if !tt.wantError && len(errs) > 0 {
-				t.Errorf("expected no validation error, got: %v", errs)
-			}
-			if tt.wantError && len(errs) > 0 {
-				found := false
+		{
+			name: "valid admin key",
+			security: SecurityConfig{
+				Admin: AdminConfig{Keys: []AdminKeyConfig{{Name: "ops", Key: "0123456789abcdef0123"}}},
+			},
+			wantError: false,
+		},
+		{
+			name: "short admin key",
+			security: SecurityConfig{
+				Admin: AdminConfig{Keys: []AdminKeyConfig{{Name: "ops", Key: "short"}}},
+			},
+			wantError:  true,
+			errorField: "security.admin.keys[0].key",
+		},
+		{
+			name: "valid API key concurrency limits",
+			security: SecurityConfig{
+				Authentication: AuthenticationConfig{Keys: []APIKeyConfig{{Key: "sk-test", MaxConcurrent: 10, MaxConcurrentStreams: 2}}},
+			},
+			wantError: false,
+		},
+		{
+			name: "negative API key stream limit",
+			security: SecurityConfig{
+				Authentication: AuthenticationConfig{Keys: []APIKeyConfig{{Key: "sk-test", MaxConcurrentStreams: -1}}},
+			},
+			wantError:  true,
+			errorField: "security.authentication.keys[0].max_concurrent_streams",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateSecurity(&tt.security)
+			if tt.wantError && len(errs) == 0 {
+				t.Error("expected validation error, got none")
+			}
+			if !tt.wantError && len(errs) > 0 {
+				t.Errorf("expected no validation error, got: %v", errs)
+			}
+			if tt.wantError && len(errs) > 0 {
+				found := false
+				for _, err := range errs {
+					if err.Field == tt.errorField {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("expected error for field %q, got errors: %v", tt.errorField, errs)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateAdminListener(t *testing.T) {
+	adminTLS := TLSConfig{
+		Enabled:  true,
+		CertFile: "/path/to/admin-cert.pem",
+		KeyFile:  "/path/to/admin-key.pem",
+	}
+	adminMTLS := adminTLS
+	adminMTLS.MTLS = MTLSConfig{Enabled: true, ClientCAFile: "/path/to/ca.pem"}
+
+	tests := []struct {
+		name       string
+		admin      AdminConfig
+		wantError  bool
+		errorField string
+	}{
+		{
+			name:      "no admin listener",
+			admin:     AdminConfig{Auth: "key"},
+			wantError: false,
+		},
+		{
+			name:      "valid admin listener",
+			admin:     AdminConfig{ListenAddress: "127.0.0.1:9090", TLS: adminTLS},
+			wantError: false,
+		},
+		{
+			name:       "same address as the proxy",
+			admin:      AdminConfig{ListenAddress: "0.0.0.0:8080"},
+			wantError:  true,
+			errorField: "security.admin.listen_address",
+		},
+		{
+			name:       "invalid address",
+			admin:      AdminConfig{ListenAddress: "localhost"},
+			wantError:  true,
+			errorField: "security.admin.listen_address",
+		},
+		{
+			name:       "tls without admin listener",
+			admin:      AdminConfig{TLS: adminTLS},
+			wantError:  true,
+			errorField: "security.admin.tls.enabled",
+		},
+		{
+			name:      "client certificate authentication",
+			admin:     AdminConfig{ListenAddress: "127.0.0.1:9090", TLS: adminMTLS, Auth: "client_cert"},
+			wantError: false,
+		},
+		{
+			name:       "client certificate authentication without mtls",
+			admin:      AdminConfig{ListenAddress: "127.0.0.1:9090", TLS: adminTLS, Auth: "client_cert"},
+			wantError:  true,
+			errorField: "security.admin.auth",
+		},
+		{
+			name:       "invalid auth",
+			admin:      AdminConfig{Auth: "basic"},
+			wantError:  true,
+			errorField: "security.admin.auth",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{}
+			cfg.Proxy.ListenAddress = "0.0.0.0:8080"
+			cfg.Security.Admin = tt.admin
+			errs := validateAdminListener(cfg)
+			if !tt.wantError && len(errs) > 0 {
+				t.Errorf("expected no validation error, got: %v", errs)
+			}
+			if tt.wantError {
+				found := false
 				for _, err := range errs {
 					if err.Field == tt.errorField {
 						found = true
@@ -616,6 +1161,564 @@ func TestValidate_Security(t *testing.T) {
 	}
 }
 
+func TestValidateRemote(t *testing.T) {
+	valid := RemoteConfig{
+		Backend:       "etcd",
+		Endpoints:     []string{"http://etcd-0:2379", "https://etcd-1:2379"},
+		Key:           "mercator/config.yaml",
+		Timeout:       5 * time.Second,
+		WatchTimeout:  time.Minute,
+		RetryInterval: 5 * time.Second,
+	}
+
+	tests := []struct {
+		name       string
+		modify     func(cfg *RemoteConfig)
+		wantError  bool
+		errorField string
+	}{
+		{
+			name:      "valid etcd source",
+			modify:    func(cfg *RemoteConfig) {},
+			wantError: false,
+		},
+		{
+			name: "valid consul source",
+			modify: func(cfg *RemoteConfig) {
+				cfg.Backend = "consul"
+				cfg.Token = "acl-token"
+				cfg.Datacenter = "dc1"
+			},
+			wantError: false,
+		},
+		{
+			name:       "invalid backend",
+			modify:     func(cfg *RemoteConfig) { cfg.Backend = "zookeeper" },
+			wantError:  true,
+			errorField: "remote.backend",
+		},
+		{
+			name:       "no endpoints",
+			modify:     func(cfg *RemoteConfig) { cfg.Endpoints = nil },
+			wantError:  true,
+			errorField: "remote.endpoints",
+		},
+		{
+			name:       "invalid endpoint",
+			modify:     func(cfg *RemoteConfig) { cfg.Endpoints = []string{"http://etcd-0:2379", "etcd-1:2379"} },
+			wantError:  true,
+			errorField: "remote.endpoints[1]",
+		},
+		{
+			name:       "no key",
+			modify:     func(cfg *RemoteConfig) { cfg.Key = "/" },
+			wantError:  true,
+			errorField: "remote.key",
+		},
+		{
+			name:       "username without password",
+			modify:     func(cfg *RemoteConfig) { cfg.Username = "mercator" },
+			wantError:  true,
+			errorField: "remote.username",
+		},
+		{
+			name:       "token with etcd",
+			modify:     func(cfg *RemoteConfig) { cfg.Token = "acl-token" },
+			wantError:  true,
+			errorField: "remote.token",
+		},
+		{
+			name: "consul watch timeout too long",
+			modify: func(cfg *RemoteConfig) {
+				cfg.Backend = "consul"
+				cfg.WatchTimeout = time.Hour
+			},
+			wantError:  true,
+			errorField: "remote.watch_timeout",
+		},
+		{
+			name:       "negative jitter",
+			modify:     func(cfg *RemoteConfig) { cfg.Jitter = -time.Second },
+			wantError:  true,
+			errorField: "remote.jitter",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := valid
+			tt.modify(&cfg)
+			errs := validateRemote(&cfg)
+			if !tt.wantError && len(errs) > 0 {
+				t.Errorf("expected no validation error, got: %v", errs)
+			}
+			if tt.wantError {
+				found := false
+				for _, err := range errs {
+					if err.Field == tt.errorField {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("expected error for field %q, got errors: %v", tt.errorField, errs)
+				}
+			}
+		})
+	}
+}
+
+func TestValidatePriorityClasses(t *testing.T) {
+	cfg := &Config{}
+	cfg.Limits.Enforcement.PriorityTiers = map[string]int{"prod": 100, "batch": 0}
+	cfg.Security.Authentication.Keys = []APIKeyConfig{
+		{Key: "sk-prod", PriorityClass: "prod"},
+		{Key: "sk-default"},
+		{Key: "sk-exp", PriorityClass: "experimentation"},
+	}
+
+	errs := validatePriorityClasses(cfg)
+	if len(errs) != 1 || errs[0].Field != "security.authentication.keys[2].priority_class" {
+		t.Errorf("expected unknown priority class error for keys[2], got: %v", errs)
+	}
+}
+
+func TestValidateTokens(t *testing.T) {
+	cfg := &Config{}
+	cfg.Providers = map[string]ProviderConfig{"anthropic": {BaseURL: "https://api.anthropic.com"}}
+	cfg.Processing.Tokens = TokensConfig{Estimator: "anthropic", Provider: "anthropic"}
+	if errs := validateTokens(cfg); len(errs) != 0 {
+		t.Errorf("expected no validation error, got: %v", errs)
+	}
+
+	cfg.Processing.Tokens.Provider = "claude"
+	cfg.Processing.Tokens.Timeout = -1
+	errs := validateTokens(cfg)
+	if len(errs) != 2 || errs[0].Field != "processing.tokens.timeout" || errs[1].Field != "processing.tokens.provider" {
+		t.Errorf("expected timeout and provider errors, got: %v", errs)
+	}
+
+	// The provider is only required by the anthropic estimator
+	cfg.Processing.Tokens = TokensConfig{Estimator: "simple", Provider: "claude"}
+	if errs := validateTokens(cfg); len(errs) != 0 {
+		t.Errorf("expected no validation error, got: %v", errs)
+	}
+}
+
+func TestValidateCustomPII(t *testing.T) {
+	valid := []CustomPIIConfig{
+		{Name: "employee_id", Pattern: `\bEMP-\d{6}\b`, Severity: "high"},
+		{Name: "project_code", Keywords: []string{"Project Falcon"}},
+	}
+	if errs := validateCustomPII(valid); len(errs) != 0 {
+		t.Errorf("expected no validation error, got: %v", errs)
+	}
+
+	tests := []struct {
+		name  string
+		pii   CustomPIIConfig
+		field string
+	}{
+		{"missing name", CustomPIIConfig{Pattern: `\d+`}, "processing.content.pii.custom[1].name"},
+		{"built-in name", CustomPIIConfig{Name: "email", Pattern: `\d+`}, "processing.content.pii.custom[1].name"},
+		{"duplicate name", CustomPIIConfig{Name: "employee_id", Pattern: `\d+`}, "processing.content.pii.custom[1].name"},
+		{"no pattern or keywords", CustomPIIConfig{Name: "account"}, "processing.content.pii.custom[1]"},
+		{"invalid pattern", CustomPIIConfig{Name: "account", Pattern: `ACC-(\d+`}, "processing.content.pii.custom[1].pattern"},
+		{"pattern matching empty text", CustomPIIConfig{Name: "account", Pattern: `\d*`}, "processing.content.pii.custom[1].pattern"},
+		{"empty keyword", CustomPIIConfig{Name: "account", Keywords: []string{" "}}, "processing.content.pii.custom[1].keywords[0]"},
+		{"invalid severity", CustomPIIConfig{Name: "account", Pattern: `\d+`, Severity: "severe"}, "processing.content.pii.custom[1].severity"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateCustomPII([]CustomPIIConfig{valid[0], tt.pii})
+			if len(errs) != 1 || errs[0].Field != tt.field {
+				t.Errorf("expected %s error, got: %v", tt.field, errs)
+			}
+		})
+	}
+}
+
+func TestValidateSecretDetection(t *testing.T) {
+	cfg := &SecretDetectionConfig{Types: []string{"aws_access_key", "jwt"}, MinEntropy: 3.5}
+	if errs := validateSecretDetection(cfg); len(errs) != 0 {
+		t.Errorf("expected no validation error, got: %v", errs)
+	}
+
+	cfg = &SecretDetectionConfig{Types: []string{"jwt", "password"}, MinEntropy: -1}
+	errs := validateSecretDetection(cfg)
+	if len(errs) != 2 || errs[0].Field != "processing.content.secrets.types[1]" || errs[1].Field != "processing.content.secrets.min_entropy" {
+		t.Errorf("expected type and min_entropy errors, got: %v", errs)
+	}
+}
+
+func TestValidateInjectionClassifier(t *testing.T) {
+	// The proxy has no classifier backend to enable
+	cfg := &InjectionClassifierConfig{Enabled: true, Threshold: 0.8, Mode: "inline"}
+	errs := validateInjectionClassifier(cfg)
+	if len(errs) != 1 || errs[0].Field != "processing.content.injection.classifier.enabled" {
+		t.Errorf("expected an enabled error, got: %v", errs)
+	}
+
+	cfg = &InjectionClassifierConfig{Enabled: true, Threshold: 1.5, Mode: "async", Timeout: -1}
+	if errs := validateInjectionClassifier(cfg); len(errs) != 4 {
+		t.Errorf("expected enabled, threshold, mode, and timeout errors, got: %v", errs)
+	}
+
+	// A disabled classifier is not validated
+	if errs := validateInjectionClassifier(&InjectionClassifierConfig{Mode: "async"}); len(errs) != 0 {
+		t.Errorf("expected no validation error, got: %v", errs)
+	}
+}
+
+func TestValidateDLP(t *testing.T) {
+	tests := []struct {
+		name   string
+		cfg    DLPConfig
+		fields []string
+	}{
+		{"disabled", DLPConfig{Backend: "unknown"}, nil},
+		{"presidio", DLPConfig{Enabled: true, Backend: "presidio", URL: "http://presidio:5002"}, nil},
+		{"google", DLPConfig{Enabled: true, Backend: "google", ProjectID: "acme"}, nil},
+		{"missing url", DLPConfig{Enabled: true, Backend: "http"}, []string{"processing.content.dlp.url"}},
+		{"missing project", DLPConfig{Enabled: true, Backend: "google"}, []string{"processing.content.dlp.project_id"}},
+		{"unknown backend", DLPConfig{Enabled: true, Backend: "macie"}, []string{"processing.content.dlp.backend"}},
+		{
+			"invalid settings",
+			DLPConfig{Enabled: true, URL: "presidio:5002", MinScore: 2, Timeout: -1},
+			[]string{"processing.content.dlp.url", "processing.content.dlp.min_score", "processing.content.dlp.timeout"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateDLP(&tt.cfg)
+			if len(errs) != len(tt.fields) {
+				t.Fatalf("expected errors for %v, got: %v", tt.fields, errs)
+			}
+			for i, field := range tt.fields {
+				if errs[i].Field != field {
+					t.Errorf("expected error for %s, got: %v", field, errs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateSensitiveCategories(t *testing.T) {
+	cfg := &SensitiveConfig{
+		Categories: []string{"violence", "hate_speech", "extremism"},
+		Wordlists:  map[string]string{"extremism": "/etc/mercator/extremism.txt"},
+		MinScore:   0.5,
+	}
+	if errs := validateSensitiveCategories(cfg); len(errs) != 0 {
+		t.Errorf("expected no validation error, got: %v", errs)
+	}
+
+	cfg = &SensitiveConfig{
+		Categories: []string{"gambling"},
+		Wordlists:  map[string]string{"violence": ""},
+		MinScore:   1.5,
+	}
+	errs := validateSensitiveCategories(cfg)
+	fields := make([]string, 0, len(errs))
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+	want := []string{
+		"processing.content.sensitive.categories[0]",
+		"processing.content.sensitive.wordlists.violence",
+		"processing.content.sensitive.min_score",
+	}
+	if strings.Join(fields, ",") != strings.Join(want, ",") {
+		t.Errorf("expected errors for %v, got: %v", want, errs)
+	}
+}
+
+func TestValidateRisk(t *testing.T) {
+	cfg := &RiskConfig{
+		Weights:          RiskWeightsConfig{PII: 4, CallerHistory: 2},
+		ModelSensitivity: map[string]float64{"gpt-4": 0.5},
+	}
+	if err := ValidateRisk(cfg); err != nil {
+		t.Errorf("expected no validation error, got: %v", err)
+	}
+
+	cfg = &RiskConfig{
+		Weights:           RiskWeightsConfig{Toxicity: -1},
+		ModelSensitivity:  map[string]float64{"gpt-4": 1.5},
+		LargePromptTokens: -1,
+	}
+	errs := validateRisk(cfg, "processing.risk")
+	fields := make([]string, 0, len(errs))
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+	want := []string{
+		"processing.risk.weights.toxicity",
+		"processing.risk.model_sensitivity.gpt-4",
+		"processing.risk.large_prompt_tokens",
+	}
+	if strings.Join(fields, ",") != strings.Join(want, ",") {
+		t.Errorf("expected errors for %v, got: %v", want, errs)
+	}
+}
+
+func TestValidateTopics(t *testing.T) {
+	topic := []TopicConfig{{Name: "weapons", Examples: []string{"build a bomb"}}}
+
+	tests := []struct {
+		name   string
+		cfg    TopicsConfig
+		fields []string
+	}{
+		{"disabled", TopicsConfig{Embedder: "unknown"}, nil},
+		{"local", TopicsConfig{Enabled: true, Embedder: "local", Threshold: 0.3, Banned: topic}, nil},
+		{
+			"provider",
+			TopicsConfig{Enabled: true, Embedder: "provider", URL: "https://api.openai.com/v1", Model: "text-embedding-3-small", Allowed: topic},
+			nil,
+		},
+		{
+			"provider without url and model",
+			TopicsConfig{Enabled: true, Embedder: "provider", Banned: topic},
+			[]string{"processing.content.topics.url", "processing.content.topics.model"},
+		},
+		{"unknown embedder", TopicsConfig{Enabled: true, Embedder: "word2vec", Banned: topic}, []string{"processing.content.topics.embedder"}},
+		{"no topics", TopicsConfig{Enabled: true, Threshold: 2}, []string{"processing.content.topics.threshold", "processing.content.topics"}},
+		{
+			"invalid topics",
+			TopicsConfig{Enabled: true, Banned: topic, Allowed: []TopicConfig{{Name: "weapons"}, {Examples: []string{"hello"}}}},
+			[]string{
+				"processing.content.topics.allowed[0].name",
+				"processing.content.topics.allowed[0].examples",
+				"processing.content.topics.allowed[1].name",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateTopics(&tt.cfg)
+			if len(errs) != len(tt.fields) {
+				t.Fatalf("expected errors for %v, got: %v", tt.fields, errs)
+			}
+			for i, field := range tt.fields {
+				if errs[i].Field != field {
+					t.Errorf("expected error for %s, got: %v", field, errs[i])
+				}
+			}
+		})
+	}
+}
+
+func TestValidateEntities(t *testing.T) {
+	cfg := &EntitiesConfig{
+		Types:      []string{"organization", "person"},
+		Gazetteers: map[string]string{"organization": "/etc/mercator/customers.txt"},
+	}
+	if errs := validateEntities(cfg); len(errs) != 0 {
+		t.Errorf("expected no validation error, got: %v", errs)
+	}
+
+	cfg = &EntitiesConfig{
+		Types:      []string{"product"},
+		Gazetteers: map[string]string{"person": "", "product": "/etc/products.txt"},
+	}
+	errs := validateEntities(cfg)
+	fields := make([]string, 0, len(errs))
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+	want := []string{
+		"processing.content.entities.types[0]",
+		"processing.content.entities.gazetteers.person",
+		"processing.content.entities.gazetteers.product",
+	}
+	if strings.Join(fields, ",") != strings.Join(want, ",") {
+		t.Errorf("expected errors for %v, got: %v", want, errs)
+	}
+}
+
+func TestValidateClassification(t *testing.T) {
+	cfg := &ClassificationConfig{
+		Enabled: true,
+		Labels:  []string{"public", "secret"},
+		Default: "public",
+		Rules:   []ClassificationRuleConfig{{Label: "secret", PIISeverity: "high"}},
+	}
+	if errs := validateClassification(cfg); len(errs) != 0 {
+		t.Errorf("expected no validation error, got: %v", errs)
+	}
+
+	cfg = &ClassificationConfig{
+		Enabled: true,
+		Labels:  []string{"public", "", "public"},
+		Default: "internal",
+		Rules: []ClassificationRuleConfig{
+			{Label: "restricted", PIISeverity: "severe"},
+			{Label: "public"},
+		},
+	}
+	errs := validateClassification(cfg)
+	fields := make([]string, 0, len(errs))
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+	want := []string{
+		"processing.content.classification.labels[1]",
+		"processing.content.classification.labels[2]",
+		"processing.content.classification.default",
+		"processing.content.classification.rules[0].label",
+		"processing.content.classification.rules[0].pii_severity",
+		"processing.content.classification.rules[1]",
+	}
+	if strings.Join(fields, ",") != strings.Join(want, ",") {
+		t.Errorf("expected errors for %v, got: %v", want, errs)
+	}
+}
+
+func TestValidateInjectionFeed(t *testing.T) {
+	cfg := &InjectionFeedConfig{
+		Enabled:   true,
+		URL:       "https://feeds.example.com/injection.yaml",
+		PublicKey: "/etc/mercator/feed.pub",
+	}
+	if errs := validateInjectionFeed(cfg); len(errs) != 0 {
+		t.Errorf("expected no validation error, got: %v", errs)
+	}
+
+	cfg = &InjectionFeedConfig{
+		Enabled: true,
+		URL:     "http://feeds.example.com/injection.yaml",
+		Timeout: -1,
+	}
+	errs := validateInjectionFeed(cfg)
+	fields := make([]string, 0, len(errs))
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+	want := []string{
+		"processing.content.injection.feed.url",
+		"processing.content.injection.feed.public_key",
+		"processing.content.injection.feed.timeout",
+	}
+	if strings.Join(fields, ",") != strings.Join(want, ",") {
+		t.Errorf("expected errors for %v, got: %v", want, errs)
+	}
+}
+
+func TestValidatePricing(t *testing.T) {
+	pricing := map[string]map[string]ModelPricingConfig{
+		"google": {
+			"gemini-1.5-pro": {
+				Prompt:        0.00125,
+				Completion:    0.005,
+				BatchDiscount: 0.5,
+				LongContext:   []LongContextPricingConfig{{AboveTokens: 128000, Prompt: 0.0025, Completion: 0.01}},
+			},
+		},
+	}
+	if errs := validatePricing(pricing, "processing.costs.pricing"); len(errs) != 0 {
+		t.Errorf("expected no validation error, got: %v", errs)
+	}
+
+	pricing = map[string]map[string]ModelPricingConfig{
+		"google": {
+			"gemini-1.5-pro": {
+				Prompt:        -0.001,
+				BatchDiscount: 1.5,
+				LongContext:   []LongContextPricingConfig{{Prompt: 0.0025, Completion: -0.01}},
+			},
+		},
+	}
+	errs := validatePricing(pricing, "processing.costs.pricing")
+	fields := make([]string, 0, len(errs))
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+	want := []string{
+		"processing.costs.pricing.google.gemini-1.5-pro.prompt",
+		"processing.costs.pricing.google.gemini-1.5-pro.batch_discount",
+		"processing.costs.pricing.google.gemini-1.5-pro.long_context[0].above_tokens",
+		"processing.costs.pricing.google.gemini-1.5-pro.long_context[0].completion",
+	}
+	if strings.Join(fields, ",") != strings.Join(want, ",") {
+		t.Errorf("expected errors for %v, got: %v", want, errs)
+	}
+}
+
+func TestValidatePricingCatalog(t *testing.T) {
+	cfg := &PricingCatalogConfig{
+		Enabled:   true,
+		URL:       "https://pricing.example.com/catalog.yaml",
+		PublicKey: "/etc/mercator/pricing.pub",
+	}
+	if errs := validatePricingCatalog(cfg); len(errs) != 0 {
+		t.Errorf("expected no validation error, got: %v", errs)
+	}
+
+	cfg = &PricingCatalogConfig{
+		Enabled:         true,
+		URL:             "http://pricing.example.com/catalog.yaml",
+		File:            "/etc/mercator/pricing.yaml",
+		RefreshInterval: -1,
+	}
+	errs := validatePricingCatalog(cfg)
+	fields := make([]string, 0, len(errs))
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+	want := []string{
+		"processing.costs.catalog",
+		"processing.costs.catalog.url",
+		"processing.costs.catalog.public_key",
+		"processing.costs.catalog.refresh_interval",
+	}
+	if strings.Join(fields, ",") != strings.Join(want, ",") {
+		t.Errorf("expected errors for %v, got: %v", want, errs)
+	}
+}
+
+func TestValidateAnalyzers(t *testing.T) {
+	analyzers := []AnalyzerConfig{
+		{
+			Name:   "toxicity",
+			Type:   "http",
+			URL:    "https://analyzers.example.com/toxicity",
+			Stages: []string{"request", "response"},
+			Fields: map[string]string{"score": "number", "toxic": "boolean"},
+		},
+		{Name: "custom", Type: "keyword", Fields: map[string]string{"matches": "array"}},
+	}
+	if errs := validateAnalyzers(analyzers); len(errs) != 0 {
+		t.Errorf("expected no validation error, got: %v", errs)
+	}
+
+	analyzers = []AnalyzerConfig{
+		{Name: "toxicity", Type: "http", URL: "https://analyzers.example.com/toxicity", Fields: map[string]string{"score": "number"}},
+		{Name: "toxicity", Type: "http", URL: "analyzers", Stages: []string{"output"}},
+		{Name: "Custom", Type: "keyword", Fields: map[string]string{"Label": "text"}, Timeout: -1},
+	}
+	errs := validateAnalyzers(analyzers)
+	fields := make([]string, 0, len(errs))
+	for _, err := range errs {
+		fields = append(fields, err.Field)
+	}
+	want := []string{
+		"processing.analyzers[1].name",
+		"processing.analyzers[1].url",
+		"processing.analyzers[1].stages",
+		"processing.analyzers[1].fields",
+		"processing.analyzers[2].name",
+		"processing.analyzers[2].fields",
+		"processing.analyzers[2].fields.Label",
+		"processing.analyzers[2].timeout",
+	}
+	if strings.Join(fields, ",") != strings.Join(want, ",") {
+		t.Errorf("expected errors for %v, got: %v", want, errs)
+	}
+}
+
 func TestValidationError_Error(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,157 @@
+package capture
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/evidence/compress"
+)
+
+// refPrefix is the prefix of blob references.
+const refPrefix = "sha256:"
+
+// ErrBlobNotFound is returned by BlobStore.Get for unknown references.
+var ErrBlobNotFound = errors.New("blob not found")
+
+// BlobStore stores captured bodies by content address.
+// Implementations must be thread-safe.
+type BlobStore interface {
+	// Put stores data and returns its reference. Storing the same data
+	// twice returns the same reference.
+	Put(ctx context.Context, data []byte) (string, error)
+
+	// Get returns the data stored under ref.
+	// Returns ErrBlobNotFound if there is none.
+	Get(ctx context.Context, ref string) ([]byte, error)
+
+	// Delete removes the data stored under ref. Deleting a missing blob
+	// is not an error.
+	Delete(ctx context.Context, ref string) error
+
+	// Close releases any resources held by the store.
+	Close() error
+}
+
+// Ref returns the blob reference of data.
+func Ref(data []byte) string {
+	sum := sha256.Sum256(data)
+	return refPrefix + hex.EncodeToString(sum[:])
+}
+
+// FileBlobStore stores blobs as files below a directory, at
+// <dir>/<first two hex digits>/<hex digest>.
+type FileBlobStore struct {
+	dir             string
+	compressMinSize int
+}
+
+// NewFileBlobStore creates a blob store in dir, creating it if needed.
+func NewFileBlobStore(dir string) (*FileBlobStore, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, evidence.NewStorageError("blob", "open", err)
+	}
+	return &FileBlobStore{dir: dir}, nil
+}
+
+// WithCompression stores blobs of at least minSize bytes zstd-compressed.
+// Their references remain the SHA-256 of the uncompressed data, and Get
+// decompresses them transparently.
+func (s *FileBlobStore) WithCompression(minSize int) *FileBlobStore {
+	s.compressMinSize = minSize
+	return s
+}
+
+// Put writes data unless a blob with the same content already exists.
+// Blobs are written to a temporary file and renamed, so a blob is never
+// visible partially written.
+func (s *FileBlobStore) Put(ctx context.Context, data []byte) (string, error) {
+	ref := Ref(data)
+	path, err := s.path(ref)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return ref, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return "", evidence.NewStorageError("blob", "put", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".blob-*")
+	if err != nil {
+		return "", evidence.NewStorageError("blob", "put", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if s.compressMinSize > 0 {
+		if compressed := compress.Shrink(data, s.compressMinSize); compressed != nil {
+			data = compressed
+		}
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", evidence.NewStorageError("blob", "put", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return "", evidence.NewStorageError("blob", "put", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", evidence.NewStorageError("blob", "put", err)
+	}
+	return ref, nil
+}
+
+// Get reads the blob stored under ref.
+func (s *FileBlobStore) Get(ctx context.Context, ref string) ([]byte, error) {
+	path, err := s.path(ref)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+	if err != nil {
+		return nil, evidence.NewStorageError("blob", "get", err)
+	}
+	if data, err = compress.Decompress(data); err != nil {
+		return nil, evidence.NewStorageError("blob", "get", err)
+	}
+	return data, nil
+}
+
+// Delete removes the blob stored under ref.
+func (s *FileBlobStore) Delete(ctx context.Context, ref string) error {
+	path, err := s.path(ref)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return evidence.NewStorageError("blob", "delete", err)
+	}
+	return nil
+}
+
+// Close is a no-op; FileBlobStore holds no open files.
+func (s *FileBlobStore) Close() error {
+	return nil
+}
+
+// path returns the file path of ref, rejecting malformed references.
+func (s *FileBlobStore) path(ref string) (string, error) {
+	digest, ok := strings.CutPrefix(ref, refPrefix)
+	if !ok || len(digest) != sha256.Size*2 {
+		return "", fmt.Errorf("invalid blob reference %q", ref)
+	}
+	if _, err := hex.DecodeString(digest); err != nil {
+		return "", fmt.Errorf("invalid blob reference %q", ref)
+	}
+	return filepath.Join(s.dir, digest[:2], digest), nil
+}
@@ -0,0 +1,123 @@
+package capture
+
+import (
+	"context"
+	"path"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+// DefaultMaxBodySize is the default maximum size of a captured body.
+const DefaultMaxBodySize = 1 << 20 // 1 MiB
+
+// Policy selects the requests whose full bodies are captured. A request is
+// captured if it matches any selector; a policy without selectors captures
+// every request.
+type Policy struct {
+	// APIKeys lists API keys whose requests are captured.
+	APIKeys []string
+
+	// Models lists model name patterns (path.Match syntax, e.g. "gpt-4*")
+	// whose requests are captured.
+	Models []string
+
+	// Policies lists policies ("policy-id") or rules ("policy-id/rule-id")
+	// whose matches are captured.
+	Policies []string
+
+	// MaxBodySize is the maximum number of bytes stored per body; longer
+	// bodies are truncated.
+	// Default: 1 MiB
+	MaxBodySize int
+}
+
+// Capturer captures request and response bodies selected by a Policy.
+type Capturer struct {
+	store   BlobStore
+	policy  *Policy
+	apiKeys map[string]bool
+}
+
+// NewCapturer creates a capturer that writes bodies selected by policy to
+// store.
+func NewCapturer(store BlobStore, policy *Policy) *Capturer {
+	if policy == nil {
+		policy = &Policy{}
+	}
+	if policy.MaxBodySize <= 0 {
+		p := *policy
+		p.MaxBodySize = DefaultMaxBodySize
+		policy = &p
+	}
+
+	c := &Capturer{
+		store:   store,
+		policy:  policy,
+		apiKeys: make(map[string]bool, len(policy.APIKeys)),
+	}
+	for _, key := range policy.APIKeys {
+		c.apiKeys[key] = true
+	}
+	return c
+}
+
+// Store returns the capturer's blob store.
+func (c *Capturer) Store() BlobStore {
+	return c.store
+}
+
+// Matches reports whether the bodies of a request should be captured.
+// apiKey is the request's unredacted API key; the record must include the
+// model and matched policy rules.
+func (c *Capturer) Matches(apiKey string, record *evidence.EvidenceRecord) bool {
+	p := c.policy
+	if len(p.APIKeys) == 0 && len(p.Models) == 0 && len(p.Policies) == 0 {
+		return true
+	}
+
+	if apiKey != "" && c.apiKeys[apiKey] {
+		return true
+	}
+	for _, pattern := range p.Models {
+		if ok, _ := path.Match(pattern, record.Model); ok {
+			return true
+		}
+	}
+	for _, selector := range p.Policies {
+		for _, rule := range record.MatchedRules {
+			if selector == rule.PolicyID || selector == rule.PolicyID+"/"+rule.RuleID {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Capture stores the request and response bodies and sets the record's
+// RequestBodyRef, ResponseBodyRef, and BodyTruncated. Empty bodies are
+// not stored.
+func (c *Capturer) Capture(ctx context.Context, record *evidence.EvidenceRecord, request, response []byte) error {
+	var err error
+	if len(request) > 0 {
+		record.RequestBodyRef, err = c.put(ctx, record, request)
+		if err != nil {
+			return err
+		}
+	}
+	if len(response) > 0 {
+		record.ResponseBodyRef, err = c.put(ctx, record, response)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// put stores a body, truncated to MaxBodySize.
+func (c *Capturer) put(ctx context.Context, record *evidence.EvidenceRecord, body []byte) (string, error) {
+	if len(body) > c.policy.MaxBodySize {
+		body = body[:c.policy.MaxBodySize]
+		record.BodyTruncated = true
+	}
+	return c.store.Put(ctx, body)
+}
@@ -0,0 +1,155 @@
+package capture
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/evidence/compress"
+)
+
+func TestCapturer_Matches(t *testing.T) {
+	capturer := NewCapturer(nil, &Policy{
+		APIKeys:  []string{"sk-audit"},
+		Models:   []string{"gpt-4*"},
+		Policies: []string{"pii", "jailbreak/block-dan"},
+	})
+
+	tests := []struct {
+		name   string
+		apiKey string
+		record *evidence.EvidenceRecord
+		want   bool
+	}{
+		{"api key", "sk-audit", &evidence.EvidenceRecord{Model: "claude-3"}, true},
+		{"model pattern", "sk-other", &evidence.EvidenceRecord{Model: "gpt-4-turbo"}, true},
+		{"policy", "", &evidence.EvidenceRecord{MatchedRules: []evidence.MatchedRuleRecord{{PolicyID: "pii", RuleID: "ssn"}}}, true},
+		{"rule", "", &evidence.EvidenceRecord{MatchedRules: []evidence.MatchedRuleRecord{{PolicyID: "jailbreak", RuleID: "block-dan"}}}, true},
+		{"other rule of policy", "", &evidence.EvidenceRecord{MatchedRules: []evidence.MatchedRuleRecord{{PolicyID: "jailbreak", RuleID: "warn"}}}, false},
+		{"no match", "sk-other", &evidence.EvidenceRecord{Model: "gpt-3.5-turbo"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := capturer.Matches(tt.apiKey, tt.record); got != tt.want {
+				t.Errorf("Matches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if !NewCapturer(nil, &Policy{}).Matches("", &evidence.EvidenceRecord{}) {
+		t.Error("policy without selectors should capture every request")
+	}
+}
+
+func TestCapturer_Capture(t *testing.T) {
+	store, err := NewFileBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileBlobStore() error = %v", err)
+	}
+	capturer := NewCapturer(store, &Policy{MaxBodySize: 8})
+	ctx := context.Background()
+
+	record := &evidence.EvidenceRecord{}
+	if err := capturer.Capture(ctx, record, []byte("request"), []byte("a long response")); err != nil {
+		t.Fatalf("Capture() error = %v", err)
+	}
+	if !record.BodyTruncated {
+		t.Error("BodyTruncated not set for a body over the size cap")
+	}
+
+	request, err := store.Get(ctx, record.RequestBodyRef)
+	if err != nil || string(request) != "request" {
+		t.Errorf("request body = %q, %v", request, err)
+	}
+	response, err := store.Get(ctx, record.ResponseBodyRef)
+	if err != nil || string(response) != "a long r" {
+		t.Errorf("response body = %q, %v; want truncated to 8 bytes", response, err)
+	}
+	if record.RequestBodyRef != Ref([]byte("request")) {
+		t.Errorf("RequestBodyRef = %q, want content address", record.RequestBodyRef)
+	}
+}
+
+func TestFileBlobStore(t *testing.T) {
+	store, err := NewFileBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileBlobStore() error = %v", err)
+	}
+	ctx := context.Background()
+	data := []byte(`{"model":"gpt-4"}`)
+
+	ref, err := store.Put(ctx, data)
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	again, err := store.Put(ctx, data)
+	if err != nil || again != ref {
+		t.Errorf("Put() of identical data = %q, %v; want %q", again, err, ref)
+	}
+
+	got, err := store.Get(ctx, ref)
+	if err != nil || !bytes.Equal(got, data) {
+		t.Errorf("Get() = %q, %v", got, err)
+	}
+
+	if err := store.Delete(ctx, ref); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := store.Get(ctx, ref); !errors.Is(err, ErrBlobNotFound) {
+		t.Errorf("Get() after Delete() error = %v, want ErrBlobNotFound", err)
+	}
+	if err := store.Delete(ctx, ref); err != nil {
+		t.Errorf("Delete() of missing blob error = %v", err)
+	}
+
+	for _, ref := range []string{"", "sha256:../../etc/passwd", "md5:abc"} {
+		if _, err := store.Get(ctx, ref); err == nil || errors.Is(err, ErrBlobNotFound) {
+			t.Errorf("Get(%q) error = %v, want invalid reference", ref, err)
+		}
+	}
+}
+
+func TestFileBlobStore_Compression(t *testing.T) {
+	dir := t.TempDir()
+	plain, err := NewFileBlobStore(dir)
+	if err != nil {
+		t.Fatalf("NewFileBlobStore() error = %v", err)
+	}
+	ctx := context.Background()
+
+	// Blobs written before compression was enabled stay readable
+	old := []byte(strings.Repeat(`{"role":"user","content":"hello"}`, 10))
+	oldRef, err := plain.Put(ctx, old)
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+
+	store := plain.WithCompression(1024)
+	data := []byte(strings.Repeat(`{"role":"assistant","content":"hi there"}`, 100))
+	ref, err := store.Put(ctx, data)
+	if err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	if ref != Ref(data) {
+		t.Errorf("Put() = %q, want reference of uncompressed data", ref)
+	}
+
+	path, _ := store.path(ref)
+	stored, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if !compress.IsCompressed(stored) || len(stored) >= len(data) {
+		t.Errorf("expected blob stored compressed, got %d of %d bytes", len(stored), len(data))
+	}
+
+	for ref, want := range map[string][]byte{ref: data, oldRef: old} {
+		if got, err := store.Get(ctx, ref); err != nil || !bytes.Equal(got, want) {
+			t.Errorf("Get(%q) = %d bytes, %v; want %d bytes", ref, len(got), err, len(want))
+		}
+	}
+}
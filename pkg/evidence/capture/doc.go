@@ -0,0 +1,25 @@
+// Package capture stores complete request and response bodies for
+// selected evidence records.
+//
+// Evidence records only hold the first characters of prompts and responses
+// together with SHA-256 hashes of the bodies. A capture Policy selects the
+// requests whose full bodies are kept as well, by API key, model, or
+// matched policy rule. Bodies are written to a BlobStore, separate from
+// evidence storage, and the record refers to them by content address:
+//
+//	capturer := capture.NewCapturer(blobs, &capture.Policy{
+//	    Models:      []string{"gpt-4*"},
+//	    Policies:    []string{"pii-detection"},
+//	    MaxBodySize: 1 << 20,
+//	})
+//	if capturer.Matches(apiKey, record) {
+//	    err := capturer.Capture(ctx, record, requestBody, responseBody)
+//	}
+//
+// A blob reference has the form "sha256:<hex>", the SHA-256 of the body,
+// so a captured body can be checked against its record and identical
+// bodies are stored once. FileBlobStore.WithCompression stores large
+// bodies zstd-compressed; the reference is still that of the uncompressed
+// body. Bodies larger than MaxBodySize are
+// truncated and the record is marked with BodyTruncated.
+package capture
@@ -0,0 +1,63 @@
+// Package compress compresses stored evidence payloads, such as long
+// prompts and captured bodies, with zstd.
+//
+// Compressed payloads are plain zstd frames, recognized by the frame magic
+// number, so readers decompress them transparently and uncompressed
+// payloads written before compression was enabled stay readable:
+//
+//	stored := compress.Compress(body)
+//	body, err := compress.Decompress(stored) // also accepts uncompressed data
+package compress
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// magic is the magic number starting every zstd frame.
+var magic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+// The encoder and decoder are safe for concurrent use with EncodeAll and
+// DecodeAll.
+var (
+	encoder, _ = zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.SpeedDefault))
+	decoder, _ = zstd.NewReader(nil, zstd.WithDecoderConcurrency(0))
+)
+
+// Compress returns data as a zstd frame.
+func Compress(data []byte) []byte {
+	return encoder.EncodeAll(data, make([]byte, 0, len(data)/2))
+}
+
+// IsCompressed reports whether data is a zstd frame.
+func IsCompressed(data []byte) bool {
+	return bytes.HasPrefix(data, magic)
+}
+
+// Decompress returns the content of a zstd frame, or data unchanged if it
+// is not compressed.
+func Decompress(data []byte) ([]byte, error) {
+	if !IsCompressed(data) {
+		return data, nil
+	}
+	out, err := decoder.DecodeAll(data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress: %w", err)
+	}
+	return out, nil
+}
+
+// Shrink returns data compressed if it is at least minSize bytes long and
+// compressing it saves space, and nil otherwise.
+func Shrink(data []byte, minSize int) []byte {
+	if len(data) < minSize {
+		return nil
+	}
+	compressed := Compress(data)
+	if len(compressed) >= len(data) {
+		return nil
+	}
+	return compressed
+}
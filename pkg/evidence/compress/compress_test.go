@@ -0,0 +1,59 @@
+package compress
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCompress_RoundTrip(t *testing.T) {
+	data := []byte(strings.Repeat("You are a helpful assistant. ", 200))
+
+	compressed := Compress(data)
+	if !IsCompressed(compressed) {
+		t.Fatal("expected zstd frame")
+	}
+	if len(compressed) >= len(data) {
+		t.Errorf("expected compression, got %d bytes from %d", len(compressed), len(data))
+	}
+
+	got, err := Decompress(compressed)
+	if err != nil {
+		t.Fatalf("Decompress() error = %v", err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("round trip changed data")
+	}
+}
+
+func TestDecompress_Uncompressed(t *testing.T) {
+	data := []byte("plain text")
+	got, err := Decompress(data)
+	if err != nil || !bytes.Equal(got, data) {
+		t.Errorf("Decompress() = %q, %v; want data unchanged", got, err)
+	}
+
+	corrupt := append(bytes.Clone(magic), 0xff, 0xff)
+	if _, err := Decompress(corrupt); err == nil {
+		t.Error("expected error for corrupt frame")
+	}
+}
+
+func TestShrink(t *testing.T) {
+	long := []byte(strings.Repeat("a", 4096))
+	tests := []struct {
+		name    string
+		data    []byte
+		minSize int
+		want    bool
+	}{
+		{"below minimum size", long[:100], 1024, false},
+		{"compressible", long, 1024, true},
+		{"incompressible", []byte("abcdefgh"), 1, false},
+	}
+	for _, tt := range tests {
+		if got := Shrink(tt.data, tt.minSize) != nil; got != tt.want {
+			t.Errorf("%s: Shrink() compressed = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
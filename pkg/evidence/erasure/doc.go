@@ -0,0 +1,43 @@
+// Package erasure implements the right to erasure (GDPR Art. 17) for
+// evidence records.
+//
+// # Erasure
+//
+// An Eraser deletes every evidence record of a user ID that is not under
+// legal hold, deletes the captured request and response bodies of those
+// records, and anonymizes the user's rollups. It then writes a deletion
+// certificate, signed with the evidence signing key, to an
+// integrity.ErasureStore:
+//
+//	eraser, err := erasure.NewEraser(storage, &erasure.Config{
+//	    Key:          signingKey,
+//	    Certificates: certificates,
+//	    Blobs:        blobs,
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//	cert, err := eraser.Erase(ctx, &erasure.Request{
+//	    UserID:      "user-123",
+//	    Reason:      "DSR-2025-0042",
+//	    RequestedBy: "privacy@example.com",
+//	})
+//
+// The certificate identifies the subject by the SHA-256 hash of the user
+// ID, and lists the ID, chain position, and hash of every erased record,
+// but none of their content. Hash chain verification uses it to account
+// for the erased records (see integrity.Verifier.AddErasure).
+//
+// Erasure deletes records; it does not crypto-shred them, since evidence
+// is not encrypted per data subject. Captured bodies are content-addressed,
+// so a body byte-identical to one recorded for another user is deleted
+// as well.
+//
+// # Admin API
+//
+// Handler serves erasure over HTTP. POST erases a user and responds with
+// the certificate; GET lists the certificates:
+//
+//	POST /admin/evidence/erasures {"user_id": "user-123", "reason": "DSR-2025-0042"}
+//	GET  /admin/evidence/erasures?user_id=user-123
+package erasure
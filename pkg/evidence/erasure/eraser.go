@@ -0,0 +1,177 @@
+package erasure
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/evidence/capture"
+	"mercator-hq/jupiter/pkg/evidence/export"
+	"mercator-hq/jupiter/pkg/evidence/integrity"
+)
+
+// deleteBatchSize is the number of records deleted per storage call.
+const deleteBatchSize = 500
+
+// Config contains configuration for an Eraser.
+type Config struct {
+	// Key signs erasure certificates. Required.
+	Key ed25519.PrivateKey
+
+	// Certificates stores the erasure certificates. Required.
+	Certificates integrity.ErasureStore
+
+	// Blobs holds captured request and response bodies, which are deleted
+	// with their records. Optional.
+	Blobs capture.BlobStore
+
+	// Holds reports records under legal hold, which are kept. Optional.
+	Holds evidence.HoldChecker
+}
+
+// Request is a request to erase the evidence of a data subject.
+type Request struct {
+	// UserID identifies the data subject. Required.
+	UserID string `json:"user_id"`
+
+	// Reason describes the legal basis, e.g. a ticket reference.
+	Reason string `json:"reason,omitempty"`
+
+	// RequestedBy identifies who requested the erasure. The admin API sets
+	// it to the authenticated admin principal; it is never read from the
+	// request body.
+	RequestedBy string `json:"-"`
+}
+
+// Eraser erases the evidence records of data subjects and certifies the
+// erasure.
+type Eraser struct {
+	store  evidence.Storage
+	config *Config
+	logger *slog.Logger
+}
+
+// NewEraser creates an eraser for the records in store.
+func NewEraser(store evidence.Storage, config *Config) (*Eraser, error) {
+	if config == nil || config.Key == nil {
+		return nil, errors.New("erasure requires a signing key")
+	}
+	if config.Certificates == nil {
+		return nil, errors.New("erasure requires a certificate store")
+	}
+	return &Eraser{
+		store:  store,
+		config: config,
+		logger: slog.Default().With("component", "evidence.erasure"),
+	}, nil
+}
+
+// Erase deletes every record of the user that is not under legal hold,
+// together with its captured bodies, anonymizes the user's rollups, and
+// returns the signed certificate appended to the certificate store.
+//
+// If deletion fails part way, the certificate of the records erased so far
+// is still stored and returned along with the error; erasing again
+// continues with the remaining records.
+func (e *Eraser) Erase(ctx context.Context, req *Request) (*integrity.ErasureCertificate, error) {
+	if req.UserID == "" {
+		return nil, errors.New("user ID is required")
+	}
+
+	cert := &integrity.ErasureCertificate{
+		ID:          uuid.New().String(),
+		Subject:     integrity.SubjectHash(req.UserID),
+		Reason:      req.Reason,
+		RequestedBy: req.RequestedBy,
+		Records:     []integrity.ErasedRecord{},
+	}
+
+	// Collect the records first: deleting while paging would move the
+	// cursor's pages
+	var (
+		pending []integrity.ErasedRecord
+		blobs   = make(map[string]bool)
+	)
+	recordsCh, errCh := export.NewCursor(e.store, &evidence.Query{UserID: req.UserID}, 0).Stream(ctx)
+	for record := range recordsCh {
+		if e.config.Holds != nil && e.config.Holds.IsHeld(record) {
+			cert.Held++
+			continue
+		}
+		pending = append(pending, integrity.NewErasedRecord(record))
+		for _, ref := range []string{record.RequestBodyRef, record.ResponseBodyRef} {
+			if ref != "" {
+				blobs[ref] = true
+			}
+		}
+	}
+	if err := <-errCh; err != nil {
+		return nil, fmt.Errorf("failed to read evidence: %w", err)
+	}
+
+	eraseErr := e.deleteRecords(ctx, req.UserID, pending, cert)
+	if eraseErr == nil && e.config.Blobs != nil {
+		for ref := range blobs {
+			if err := e.config.Blobs.Delete(ctx, ref); err != nil {
+				eraseErr = fmt.Errorf("failed to delete captured body: %w", err)
+				break
+			}
+			cert.Blobs++
+		}
+	}
+	if eraseErr == nil {
+		if rollups, ok := e.store.(evidence.RollupStore); ok {
+			if err := rollups.AnonymizeRollups(ctx, req.UserID); err != nil {
+				eraseErr = fmt.Errorf("failed to anonymize rollups: %w", err)
+			}
+		}
+	}
+
+	cert.Time = time.Now().UTC()
+	if err := integrity.SignErasureCertificate(cert, e.config.Key); err != nil {
+		return nil, err
+	}
+	if err := e.config.Certificates.Append(ctx, cert); err != nil {
+		return nil, fmt.Errorf("failed to store erasure certificate: %w", err)
+	}
+
+	e.logger.Info("evidence erased",
+		"certificate", cert.ID,
+		"records", len(cert.Records),
+		"held", cert.Held,
+		"blobs", cert.Blobs,
+		"requested_by", req.RequestedBy,
+	)
+	if eraseErr != nil {
+		return cert, eraseErr
+	}
+	return cert, nil
+}
+
+// deleteRecords deletes the pending records in batches, adding each
+// deleted batch to the certificate.
+func (e *Eraser) deleteRecords(ctx context.Context, userID string, pending []integrity.ErasedRecord, cert *integrity.ErasureCertificate) error {
+	for start := 0; start < len(pending); start += deleteBatchSize {
+		batch := pending[start:min(start+deleteBatchSize, len(pending))]
+		ids := make([]string, len(batch))
+		for i, record := range batch {
+			ids[i] = record.ID
+		}
+
+		if _, err := e.store.Delete(ctx, &evidence.Query{UserID: userID, IDs: ids}); err != nil {
+			return fmt.Errorf("failed to delete evidence: %w", err)
+		}
+		cert.Records = append(cert.Records, batch...)
+	}
+	return nil
+}
+
+// Certificates returns the stored erasure certificates.
+func (e *Eraser) Certificates(ctx context.Context) ([]*integrity.ErasureCertificate, error) {
+	return e.config.Certificates.List(ctx)
+}
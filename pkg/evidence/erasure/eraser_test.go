@@ -0,0 +1,210 @@
+package erasure
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/evidence/capture"
+	"mercator-hq/jupiter/pkg/evidence/integrity"
+	"mercator-hq/jupiter/pkg/evidence/storage"
+	"mercator-hq/jupiter/pkg/security/auth"
+)
+
+// heldIDs holds the records with the given IDs.
+type heldIDs map[string]bool
+
+func (h heldIDs) IsHeld(record *evidence.EvidenceRecord) bool {
+	return h[record.ID]
+}
+
+type fixture struct {
+	store  *storage.MemoryStorage
+	blobs  *capture.FileBlobStore
+	key    ed25519.PrivateKey
+	eraser *Eraser
+	certs  *integrity.FileErasureStore
+	ref    string
+}
+
+// newFixture stores a chain of records alternating between alice and bob,
+// with a captured body on alice's first record.
+func newFixture(t *testing.T, holds evidence.HoldChecker) *fixture {
+	t.Helper()
+	ctx := context.Background()
+	f := &fixture{store: storage.NewMemoryStorage()}
+
+	var err error
+	_, f.key, _ = ed25519.GenerateKey(nil)
+	if f.blobs, err = capture.NewFileBlobStore(filepath.Join(t.TempDir(), "blobs")); err != nil {
+		t.Fatal(err)
+	}
+	if f.ref, err = f.blobs.Put(ctx, []byte("secret prompt")); err != nil {
+		t.Fatal(err)
+	}
+	if f.certs, err = integrity.NewFileErasureStore(filepath.Join(t.TempDir(), "erasures.jsonl")); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.certs.Close() })
+
+	chain := integrity.NewChain("chain-a")
+	start := time.Date(2025, 11, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 6; i++ {
+		record := &evidence.EvidenceRecord{
+			ID:             fmt.Sprintf("rec-%d", i),
+			RequestID:      fmt.Sprintf("req-%d", i),
+			RequestTime:    start.Add(time.Duration(i) * time.Minute),
+			UserID:         []string{"alice", "bob"}[i%2],
+			Provider:       "openai",
+			Model:          "gpt-4",
+			PolicyDecision: "allow",
+		}
+		if i == 0 {
+			record.RequestBodyRef = f.ref
+		}
+		chain.Link(record)
+		if err := f.store.Store(ctx, record); err != nil {
+			t.Fatal(err)
+		}
+		chain.Commit(record)
+	}
+
+	f.eraser, err = NewEraser(f.store, &Config{Key: f.key, Certificates: f.certs, Blobs: f.blobs, Holds: holds})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+func TestEraser_Erase(t *testing.T) {
+	ctx := context.Background()
+	f := newFixture(t, heldIDs{"rec-4": true})
+
+	cert, err := f.eraser.Erase(ctx, &Request{UserID: "alice", Reason: "DSR-1", RequestedBy: "privacy"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cert.Records) != 2 || cert.Held != 1 || cert.Blobs != 1 {
+		t.Errorf("certificate has %d records, %d held, %d blobs, want 2, 1, 1",
+			len(cert.Records), cert.Held, cert.Blobs)
+	}
+	if cert.Subject != integrity.SubjectHash("alice") {
+		t.Errorf("Subject = %q, want hash of user ID", cert.Subject)
+	}
+	if err := integrity.VerifyErasureCertificate(cert, f.key.Public().(ed25519.PublicKey)); err != nil {
+		t.Errorf("certificate does not verify: %v", err)
+	}
+
+	if f.store.GetByID("rec-0") != nil || f.store.GetByID("rec-2") != nil {
+		t.Error("erased records still stored")
+	}
+	if f.store.GetByID("rec-4") == nil {
+		t.Error("held record erased")
+	}
+	if f.store.Size() != 4 {
+		t.Errorf("store has %d records, want 4", f.store.Size())
+	}
+	if _, err := f.blobs.Get(ctx, f.ref); !errors.Is(err, capture.ErrBlobNotFound) {
+		t.Errorf("captured body not deleted: %v", err)
+	}
+
+	// The certificate accounts for the erased chain positions
+	v := integrity.NewVerifier(&integrity.VerifyOptions{PublicKey: f.key.Public().(ed25519.PublicKey)})
+	records, _ := f.store.Query(ctx, &evidence.Query{})
+	for _, r := range records {
+		v.Add(r)
+	}
+	v.AddErasure(cert)
+	if report := v.Finish(nil); !report.OK() || report.Erased != 2 {
+		t.Errorf("verification after erasure: erased %d, issues %+v", report.Erased, report.Issues)
+	}
+
+	certs, err := f.eraser.Certificates(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(certs) != 1 || certs[0].ID != cert.ID {
+		t.Errorf("stored certificates = %+v, want the erasure certificate", certs)
+	}
+}
+
+func TestNewEraser_Validation(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(nil)
+	store := storage.NewMemoryStorage()
+
+	if _, err := NewEraser(store, &Config{}); err == nil {
+		t.Error("expected error without signing key")
+	}
+	if _, err := NewEraser(store, &Config{Key: key}); err == nil {
+		t.Error("expected error without certificate store")
+	}
+}
+
+func TestEraser_Handler(t *testing.T) {
+	f := newFixture(t, nil)
+	handler := f.eraser.Handler()
+
+	unauthenticated := httptest.NewRecorder()
+	handler.ServeHTTP(unauthenticated, httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"user_id": "bob"}`)))
+	if unauthenticated.Code != http.StatusUnauthorized {
+		t.Fatalf("unauthenticated status = %d, want 401", unauthenticated.Code)
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		target     string
+		body       string
+		wantStatus int
+		wantCerts  int
+	}{
+		{name: "missing user", method: http.MethodPost, target: "/", body: `{}`, wantStatus: http.StatusBadRequest},
+		{name: "invalid body", method: http.MethodPost, target: "/", body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "erase", method: http.MethodPost, target: "/", body: `{"user_id": "bob", "requested_by": "someone-else"}`, wantStatus: http.StatusOK},
+		{name: "list", method: http.MethodGet, target: "/", wantStatus: http.StatusOK, wantCerts: 1},
+		{name: "list by user", method: http.MethodGet, target: "/?user_id=bob", wantStatus: http.StatusOK, wantCerts: 1},
+		{name: "list other user", method: http.MethodGet, target: "/?user_id=alice", wantStatus: http.StatusOK, wantCerts: 0},
+		{name: "method not allowed", method: http.MethodDelete, target: "/", wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, strings.NewReader(tt.body))
+			req = req.WithContext(auth.WithAdminPrincipal(req.Context(), "privacy@example.com"))
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if tt.method == http.MethodPost && rec.Code == http.StatusOK {
+				var cert integrity.ErasureCertificate
+				if err := json.Unmarshal(rec.Body.Bytes(), &cert); err != nil {
+					t.Fatal(err)
+				}
+				if cert.RequestedBy != "privacy@example.com" {
+					t.Errorf("RequestedBy = %q, want the authenticated principal", cert.RequestedBy)
+				}
+			}
+			if tt.method != http.MethodGet || rec.Code != http.StatusOK {
+				return
+			}
+			var certs []*integrity.ErasureCertificate
+			if err := json.Unmarshal(rec.Body.Bytes(), &certs); err != nil {
+				t.Fatal(err)
+			}
+			if len(certs) != tt.wantCerts {
+				t.Errorf("got %d certificates, want %d", len(certs), tt.wantCerts)
+			}
+		})
+	}
+}
@@ -0,0 +1,93 @@
+package erasure
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"mercator-hq/jupiter/pkg/evidence/integrity"
+	"mercator-hq/jupiter/pkg/security/auth"
+)
+
+// maxRequestSize limits the size of erasure request bodies.
+const maxRequestSize = 64 << 10
+
+// Handler returns an HTTP handler for erasure, e.g. when mounted at
+// /admin/evidence/erasures.
+//
+// POST erases the evidence of the user in the JSON Request body and
+// responds with the signed certificate. GET lists the certificates, or with
+// a user_id parameter, those of that user.
+//
+// The handler must be served behind admin authentication: requests without
+// an authenticated admin principal are rejected, and the principal is
+// recorded as the certificate's requester.
+func (e *Eraser) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := auth.AdminPrincipal(r.Context()); !ok {
+			http.Error(w, "admin authentication required", http.StatusUnauthorized)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodPost:
+			e.handleErase(w, r)
+		case http.MethodGet:
+			e.handleList(w, r)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// handleErase erases the evidence of a user.
+func (e *Eraser) handleErase(w http.ResponseWriter, r *http.Request) {
+	var req Request
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestSize)).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if req.UserID == "" {
+		http.Error(w, "user_id is required", http.StatusBadRequest)
+		return
+	}
+	req.RequestedBy, _ = auth.AdminPrincipal(r.Context())
+
+	cert, err := e.Erase(r.Context(), &req)
+	if err != nil {
+		message := err.Error()
+		if cert != nil {
+			message = fmt.Sprintf("erasure incomplete (certificate %s covers %d records): %v",
+				cert.ID, len(cert.Records), err)
+		}
+		http.Error(w, message, http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(cert)
+}
+
+// handleList lists erasure certificates.
+func (e *Eraser) handleList(w http.ResponseWriter, r *http.Request) {
+	certs, err := e.Certificates(r.Context())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result := []*integrity.ErasureCertificate{}
+	subject := ""
+	if userID := r.URL.Query().Get("user_id"); userID != "" {
+		subject = integrity.SubjectHash(userID)
+	}
+	for _, cert := range certs {
+		if subject == "" || cert.Subject == subject {
+			result = append(result, cert)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
@@ -1,6 +1,13 @@
 package evidence
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrWriteOnce is returned when deleting records from storage in write-once
+// (WORM) mode.
+var ErrWriteOnce = errors.New("evidence storage is write-once")
 
 // EvidenceError is the base error type for all evidence-related errors.
 type EvidenceError struct {
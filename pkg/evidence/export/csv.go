@@ -127,6 +127,19 @@ func (e *CSVExporter) getHeaderRow() []string {
 		"user_id", "api_key", "ip_address",
 		"error", "error_type",
 		"turn_number", "context_usage",
+		"chain_id", "sequence", "prev_hash", "record_hash",
+		"signing_key_id", "signature",
+		"request_body_ref", "response_body_ref", "body_truncated",
+		"team_id",
+		"streamed", "stream_chunks", "time_to_first_token_ms", "stream_duration_ms", "client_disconnected",
+		"tool_calls", "tool_results",
+		"trace_id", "decision_id",
+		"cost_tag",
+		"limit_bypass",
+		"response_analysis",
+		"data_classification",
+		"data_classification_level",
+		"findings",
 	}
 }
 
@@ -191,6 +204,31 @@ func (e *CSVExporter) recordToRow(record *evidence.EvidenceRecord) ([]string, er
 		record.ErrorType,
 		fmt.Sprintf("%d", record.TurnNumber),
 		fmt.Sprintf("%.2f", record.ContextUsage),
+		record.ChainID,
+		fmt.Sprintf("%d", record.Sequence),
+		record.PrevHash,
+		record.RecordHash,
+		record.SigningKeyID,
+		record.Signature,
+		record.RequestBodyRef,
+		record.ResponseBodyRef,
+		fmt.Sprintf("%t", record.BodyTruncated),
+		record.TeamID,
+		fmt.Sprintf("%t", record.Streamed),
+		fmt.Sprintf("%d", record.StreamChunks),
+		fmt.Sprintf("%d", record.TimeToFirstToken.Milliseconds()),
+		fmt.Sprintf("%d", record.StreamDuration.Milliseconds()),
+		fmt.Sprintf("%t", record.ClientDisconnected),
+		formatJSON(record.ToolCalls),
+		formatJSON(record.ToolResults),
+		record.TraceID,
+		record.DecisionID,
+		record.CostTag,
+		record.LimitBypass,
+		formatJSON(record.ResponseAnalysis),
+		record.DataClassification,
+		fmt.Sprintf("%d", record.DataClassificationLevel),
+		formatJSON(record.Findings),
 	}
 
 	return row, nil
@@ -0,0 +1,166 @@
+package export
+
+import (
+	"context"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+// DefaultPageSize is the default number of records fetched per cursor page.
+const DefaultPageSize = 1000
+
+// Cursor pages through all evidence records matching a query in ascending
+// (request time, ID) order, or (recorded time, ID) order if the query's
+// TimeField is "recorded_time", without offsets.
+//
+// Each page starts after the time and ID of the last record
+// returned, so the cost of a page does not grow with the number of records
+// already read, and records sharing a timestamp are neither skipped nor
+// repeated however many there are. This makes it possible to export
+// millions of records with constant memory, and to resume an interrupted
+// export from Position.
+//
+// The query's Limit, Offset, and sort settings are ignored.
+type Cursor struct {
+	store    evidence.Storage
+	query    evidence.Query
+	pageSize int
+
+	started  bool
+	done     bool
+	position time.Time
+	lastID   string // ID of the last record returned at position
+}
+
+// NewCursor creates a cursor over the records matching query. A pageSize of
+// zero or less uses DefaultPageSize.
+func NewCursor(store evidence.Storage, query *evidence.Query, pageSize int) *Cursor {
+	if pageSize <= 0 {
+		pageSize = DefaultPageSize
+	}
+
+	c := &Cursor{
+		store:    store,
+		pageSize: pageSize,
+	}
+	if query != nil {
+		c.query = *query
+	}
+	if c.query.TimeField != "recorded_time" {
+		c.query.TimeField = "request_time"
+	}
+	c.query.SortBy = c.query.TimeField
+	c.query.SortOrder = "asc"
+	c.query.Offset = 0
+	c.query.AfterID = ""
+
+	return c
+}
+
+// Next returns the next page of records. It returns an empty page once all
+// records have been read.
+func (c *Cursor) Next(ctx context.Context) ([]*evidence.EvidenceRecord, error) {
+	if c.done {
+		return nil, nil
+	}
+
+	q := c.query
+	q.Limit = c.pageSize
+	if c.started {
+		// A query start time later than the position still applies
+		if q.StartTime == nil || !q.StartTime.After(c.position) {
+			q.StartTime = &c.position
+			q.AfterID = c.lastID
+		}
+	}
+
+	page, err := c.store.Query(ctx, &q)
+	if err != nil {
+		return nil, err
+	}
+	if len(page) < q.Limit {
+		c.done = true
+	}
+	if len(page) == 0 {
+		c.done = true
+		return page, nil
+	}
+
+	last := page[len(page)-1]
+	c.started = true
+	c.position = last.RequestTime
+	if c.query.TimeField == "recorded_time" {
+		c.position = last.RecordedTime
+	}
+	c.lastID = last.ID
+	return page, nil
+}
+
+// Resume positions the cursor after a previous export: records before
+// position, and records at exactly position whose IDs are not greater than
+// the greatest ID in seen, are skipped. It must be called before the first
+// Next.
+func (c *Cursor) Resume(position time.Time, seen []string) {
+	c.started = true
+	c.position = position
+	c.lastID = ""
+	for _, id := range seen {
+		if id > c.lastID {
+			c.lastID = id
+		}
+	}
+}
+
+// Seen returns the ID of the last record returned, which, with Position,
+// is where a resumed cursor continues.
+func (c *Cursor) Seen() []string {
+	if c.lastID == "" {
+		return nil
+	}
+	return []string{c.lastID}
+}
+
+// Position returns the request time (or recorded time, see Cursor) of the
+// last record returned, or the zero time if no record has been returned
+// yet. An export resumed with this position as its start time (inclusive)
+// misses no records; records at exactly this time up to the ID returned by
+// Seen are repeated.
+func (c *Cursor) Position() time.Time {
+	return c.position
+}
+
+// Stream reads all remaining pages in the background and delivers the
+// records on a channel, following the Storage.QueryStream conventions: both
+// channels are closed when the cursor is exhausted or fails, and at most one
+// error is sent. Position may only be read once the channels are closed.
+func (c *Cursor) Stream(ctx context.Context) (<-chan *evidence.EvidenceRecord, <-chan error) {
+	recordsCh := make(chan *evidence.EvidenceRecord, c.pageSize)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(recordsCh)
+		defer close(errCh)
+
+		for {
+			page, err := c.Next(ctx)
+			if err != nil {
+				errCh <- err
+				return
+			}
+			if len(page) == 0 {
+				return
+			}
+			for _, record := range page {
+				select {
+				case recordsCh <- record:
+				case <-ctx.Done():
+					errCh <- ctx.Err()
+					return
+				}
+			}
+		}
+	}()
+
+	return recordsCh, errCh
+}
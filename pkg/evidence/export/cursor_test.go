@@ -0,0 +1,164 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/evidence/storage"
+)
+
+// newCursorStore returns a memory store with n records, three per timestamp,
+// so that pages regularly end in the middle of a group of equal timestamps.
+func newCursorStore(t *testing.T, n int) (*storage.MemoryStorage, time.Time) {
+	t.Helper()
+	store := storage.NewMemoryStorage()
+	base := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < n; i++ {
+		err := store.Store(context.Background(), &evidence.EvidenceRecord{
+			ID:           fmt.Sprintf("rec-%03d", i),
+			RequestTime:  base.Add(time.Duration(i/3) * time.Second),
+			RecordedTime: base.Add(time.Duration(i/3) * time.Second),
+		})
+		if err != nil {
+			t.Fatalf("Store() error = %v", err)
+		}
+	}
+	return store, base
+}
+
+func TestCursor_ReadsEveryRecordOnce(t *testing.T) {
+	store, _ := newCursorStore(t, 100)
+
+	cursor := NewCursor(store, &evidence.Query{Limit: 5, Offset: 50}, 7)
+	seen := make(map[string]bool)
+	var last time.Time
+	for {
+		page, err := cursor.Next(context.Background())
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		for _, r := range page {
+			if seen[r.ID] {
+				t.Fatalf("record %s returned twice", r.ID)
+			}
+			if r.RequestTime.Before(last) {
+				t.Fatalf("records out of order at %s", r.ID)
+			}
+			seen[r.ID] = true
+			last = r.RequestTime
+		}
+	}
+
+	if len(seen) != 100 {
+		t.Errorf("read %d records, want 100 (query limit and offset must be ignored)", len(seen))
+	}
+	if !cursor.Position().Equal(last) {
+		t.Errorf("Position() = %v, want %v", cursor.Position(), last)
+	}
+}
+
+func TestCursor_Resume(t *testing.T) {
+	store, base := newCursorStore(t, 30)
+
+	// Records 0-13 were exported before the interruption; rec-012 and
+	// rec-013 share their timestamp with rec-014, which was not.
+	cursor := NewCursor(store, nil, 4)
+	cursor.Resume(base.Add(4*time.Second), []string{"rec-012", "rec-013"})
+
+	recordsCh, errCh := cursor.Stream(context.Background())
+	var ids []string
+	for r := range recordsCh {
+		ids = append(ids, r.ID)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+
+	if !sort.StringsAreSorted(ids) {
+		t.Errorf("resumed export returned records out of ID order: %v", ids)
+	}
+	if len(ids) != 16 || ids[0] != "rec-014" || ids[15] != "rec-029" {
+		t.Errorf("resumed export returned %d records %v, want rec-014..rec-029", len(ids), ids)
+	}
+}
+
+func TestCursor_SQLiteEqualTimestamps(t *testing.T) {
+	store, err := storage.NewSQLiteStorage(&storage.SQLiteConfig{
+		Path:         filepath.Join(t.TempDir(), "evidence.db"),
+		MaxOpenConns: 1,
+		BusyTimeout:  time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage() error = %v", err)
+	}
+	defer store.Close()
+
+	// More records share a timestamp than fit in a page
+	at := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 250; i++ {
+		record := &evidence.EvidenceRecord{ID: fmt.Sprintf("rec-%03d", 249-i), RequestID: fmt.Sprintf("req-%d", i), RequestTime: at}
+		if i >= 200 {
+			record.RequestTime = at.Add(time.Second)
+		}
+		if err := store.Store(context.Background(), record); err != nil {
+			t.Fatalf("Store() error = %v", err)
+		}
+	}
+
+	recordsCh, errCh := NewCursor(store, nil, 30).Stream(context.Background())
+	var ids []string
+	for r := range recordsCh {
+		ids = append(ids, r.ID)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+
+	// Records at the first timestamp (rec-050..rec-249) come first, in ID
+	// order, then those at the second
+	if len(ids) != 250 || ids[0] != "rec-050" || ids[199] != "rec-249" || ids[200] != "rec-000" || ids[249] != "rec-049" {
+		t.Fatalf("cursor returned %d records, first %v", len(ids), ids[:min(len(ids), 3)])
+	}
+	for i := 1; i < 200; i++ {
+		if ids[i] <= ids[i-1] {
+			t.Fatalf("records out of (time, ID) order at %s", ids[i])
+		}
+	}
+}
+
+func TestJSONLExporter_ExportStream(t *testing.T) {
+	store, _ := newCursorStore(t, 25)
+	recordsCh, errCh := NewCursor(store, nil, 10).Stream(context.Background())
+
+	var buf bytes.Buffer
+	if err := NewJSONLExporter().ExportStream(context.Background(), recordsCh, &buf); err != nil {
+		t.Fatalf("ExportStream() error = %v", err)
+	}
+	if err := <-errCh; err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+
+	scanner := bufio.NewScanner(&buf)
+	lines := 0
+	for scanner.Scan() {
+		var record evidence.EvidenceRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("line %d is not a JSON record: %v", lines+1, err)
+		}
+		lines++
+	}
+	if lines != 25 {
+		t.Errorf("got %d lines, want 25", lines)
+	}
+}
@@ -0,0 +1,198 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"mercator-hq/jupiter/pkg/policy/events"
+	"mercator-hq/jupiter/pkg/security/sigv4"
+)
+
+// ExportNameHeader carries the file name of an export delivered to a
+// webhook destination.
+const ExportNameHeader = "X-Mercator-Export"
+
+// Destination receives the files written by scheduled exports.
+type Destination interface {
+	// Name identifies the destination in logs, without credentials.
+	Name() string
+
+	// Put writes a file. Names are unique per export run.
+	Put(ctx context.Context, name, contentType string, data []byte) error
+}
+
+// DestinationConfig configures an export destination. The scheme of URL
+// selects the destination type:
+//
+//   - s3://bucket/prefix: one object per export under prefix
+//   - sftp://user@host[:port]/directory: one file per export in directory
+//   - http(s)://...: one POST request per export (webhook)
+type DestinationConfig struct {
+	// URL is the destination. Required.
+	URL string
+
+	// Region is the AWS region of the bucket. Required for S3.
+	Region string
+
+	// Endpoint is an optional custom S3 endpoint (e.g., MinIO), which is
+	// addressed path-style.
+	Endpoint string
+
+	// Credentials sign S3 requests.
+	Credentials sigv4.Credentials
+
+	// Password authenticates SFTP connections.
+	Password string
+
+	// PrivateKey is a PEM private key authenticating SFTP connections.
+	PrivateKey []byte
+
+	// HostKey is the SFTP server's public key in authorized_keys format.
+	// Required for SFTP; connections to other hosts are refused.
+	HostKey string
+
+	// Headers are extra headers sent with each webhook request.
+	Headers map[string]string
+
+	// Secret, if set, signs webhook payloads with HMAC-SHA256 in the
+	// X-Mercator-Signature header (see events.Sign).
+	Secret string
+
+	// Client is the HTTP client for S3 and webhook requests.
+	// Default: a client with a 60s timeout
+	Client *http.Client
+}
+
+// NewDestination creates the destination configured by cfg.
+func NewDestination(cfg *DestinationConfig) (Destination, error) {
+	u, err := url.Parse(cfg.URL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid export destination %q", cfg.URL)
+	}
+	client := cfg.Client
+	if client == nil {
+		client = &http.Client{Timeout: 60 * time.Second}
+	}
+
+	switch u.Scheme {
+	case "s3":
+		return newS3Destination(cfg, u, client)
+	case "sftp":
+		return newSFTPDestination(cfg, u)
+	case "http", "https":
+		return &webhookDestination{url: u, config: cfg, client: client}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export destination scheme %q (supported: s3, sftp, http, https)", u.Scheme)
+	}
+}
+
+// s3Destination uploads each export as an S3 object.
+type s3Destination struct {
+	client   *http.Client
+	signer   *sigv4.Signer
+	scheme   string
+	host     string
+	basePath string // "/bucket" for path-style endpoints, "" for virtual-hosted
+	prefix   string
+	name     string
+}
+
+func newS3Destination(cfg *DestinationConfig, u *url.URL, client *http.Client) (*s3Destination, error) {
+	if cfg.Region == "" {
+		return nil, fmt.Errorf("S3 export destination %q requires a region", cfg.URL)
+	}
+	bucket := u.Host
+	d := &s3Destination{
+		client: client,
+		signer: sigv4.NewSigner(cfg.Credentials, cfg.Region, "s3"),
+		scheme: "https",
+		host:   fmt.Sprintf("%s.s3.%s.amazonaws.com", bucket, cfg.Region),
+		prefix: strings.Trim(u.Path, "/"),
+		name:   "s3://" + bucket + u.Path,
+	}
+	if cfg.Endpoint != "" {
+		endpoint, err := url.Parse(cfg.Endpoint)
+		if err != nil || endpoint.Host == "" {
+			return nil, fmt.Errorf("invalid S3 endpoint %q", cfg.Endpoint)
+		}
+		d.scheme = endpoint.Scheme
+		d.host = endpoint.Host
+		d.basePath = strings.TrimSuffix(endpoint.Path, "/") + "/" + bucket
+	}
+	return d, nil
+}
+
+func (d *s3Destination) Name() string {
+	return d.name
+}
+
+func (d *s3Destination) Put(ctx context.Context, name, contentType string, data []byte) error {
+	u := url.URL{
+		Scheme: d.scheme,
+		Host:   d.host,
+		Path:   d.basePath + "/" + path.Join(d.prefix, name),
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, u.String(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	sum := md5.Sum(data)
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+	if err := d.signer.Sign(req, data, time.Now()); err != nil {
+		return err
+	}
+	return doRequest(d.client, req)
+}
+
+// webhookDestination POSTs each export to an HTTP endpoint.
+type webhookDestination struct {
+	url    *url.URL
+	config *DestinationConfig
+	client *http.Client
+}
+
+func (d *webhookDestination) Name() string {
+	return d.url.Redacted()
+}
+
+func (d *webhookDestination) Put(ctx context.Context, name, contentType string, data []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.url.String(), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set(ExportNameHeader, name)
+	for k, v := range d.config.Headers {
+		req.Header.Set(k, v)
+	}
+	if d.config.Secret != "" {
+		req.Header.Set(events.SignatureHeader, events.Sign(d.config.Secret, data))
+	}
+	return doRequest(d.client, req)
+}
+
+// doRequest sends an HTTP request and treats any non-2xx status as an error.
+func doRequest(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %w", req.URL.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("request to %s returned status %d: %s", req.URL.Redacted(), resp.StatusCode, bytes.TrimSpace(body))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
@@ -5,7 +5,10 @@
 // The export package provides exporters for:
 //
 //   - JSON: Single record or array, with optional pretty-printing
+//   - JSON Lines: One compact record per line, appendable and resumable
 //   - CSV: Flattened schema with header row and proper escaping
+//   - OCSF: Open Cybersecurity Schema Framework API Activity events, one
+//     per line, for Amazon Security Lake and SIEMs
 //
 // # JSON Export
 //
@@ -41,6 +44,42 @@
 // All exporters support streaming large result sets without loading all records
 // into memory. Records are written to the output writer as they are processed.
 //
+// A Cursor pages through every record matching a query in ascending request
+// time order using the previous page's last timestamp instead of an offset,
+// and feeds an exporter through Cursor.Stream:
+//
+//	cursor := export.NewCursor(store, query, export.DefaultPageSize)
+//	recordsCh, errCh := cursor.Stream(ctx)
+//	if err := export.NewJSONLExporter().ExportStream(ctx, recordsCh, w); err != nil {
+//	    return err
+//	}
+//	if err := <-errCh; err != nil {
+//	    return err
+//	}
+//
+// An interrupted export is continued with Cursor.Resume, given the request
+// time of the last exported record and the IDs exported at that time.
+//
+// # Scheduled Exports
+//
+// A Scheduler runs export Jobs on cron schedules. Each run writes the
+// records stored since the previous run as one file to a Destination (S3,
+// SFTP, or a webhook), and records the job's cursor position in a
+// StateFile once the file was delivered:
+//
+//	dest, err := export.NewDestination(&export.DestinationConfig{
+//	    URL:    "https://siem.example.com/ingest",
+//	    Secret: secret,
+//	})
+//	state, err := export.OpenStateFile("data/export-state.json")
+//	scheduler, err := export.NewScheduler(store, state, []*export.Job{{
+//	    Name:        "siem",
+//	    Schedule:    "*/15 * * * *",
+//	    Delay:       5 * time.Minute,
+//	    Destination: dest,
+//	}})
+//	err = scheduler.Start(ctx)
+//
 // # Error Handling
 //
 // Exporters return ExportError if the export fails:
@@ -0,0 +1,94 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+// JSONLExporter exports evidence records in JSON Lines format: one compact
+// JSON object per line, with no enclosing array. Unlike the JSON exporter's
+// array output, a partial JSONL export is still valid and can be appended
+// to when an export is resumed.
+type JSONLExporter struct {
+	// format names the format in errors; convert, if set, maps each record
+	// to the value written. Both are set by exporters of other line formats.
+	format  string
+	convert func(*evidence.EvidenceRecord) any
+}
+
+// NewJSONLExporter creates a new JSON Lines exporter.
+func NewJSONLExporter() *JSONLExporter {
+	return &JSONLExporter{format: "jsonl"}
+}
+
+// encode writes a record, converted if the format requires it.
+func (e *JSONLExporter) encode(enc *json.Encoder, record *evidence.EvidenceRecord) error {
+	if e.convert != nil {
+		return enc.Encode(e.convert(record))
+	}
+	return enc.Encode(record)
+}
+
+// Export writes evidence records to the provided writer, one per line.
+func (e *JSONLExporter) Export(ctx context.Context, records []*evidence.EvidenceRecord, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+
+	for i, record := range records {
+		if err := e.encode(enc, record); err != nil {
+			return evidence.NewExportError(e.format, i, err)
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return evidence.NewExportError(e.format, len(records), err)
+	}
+	return nil
+}
+
+// ExportStream exports evidence records from a channel in JSON Lines format.
+// Records are written as they arrive and output is flushed whenever the
+// channel has no record ready, so the output never lags behind a slow
+// producer.
+func (e *JSONLExporter) ExportStream(ctx context.Context, recordsCh <-chan *evidence.EvidenceRecord, w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	recordCount := 0
+
+	for {
+		var (
+			record *evidence.EvidenceRecord
+			ok     bool
+		)
+
+		// Flush before blocking so output never lags behind a slow producer
+		select {
+		case record, ok = <-recordsCh:
+		default:
+			if err := bw.Flush(); err != nil {
+				return evidence.NewExportError(e.format, recordCount, err)
+			}
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case record, ok = <-recordsCh:
+			}
+		}
+
+		if !ok {
+			if err := bw.Flush(); err != nil {
+				return evidence.NewExportError(e.format, recordCount, err)
+			}
+			return nil
+		}
+
+		if err := e.encode(enc, record); err != nil {
+			return evidence.NewExportError(e.format, recordCount, err)
+		}
+		recordCount++
+	}
+}
@@ -0,0 +1,323 @@
+package export
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+// OCSF schema identifiers of the API Activity class.
+const (
+	OCSFVersion      = "1.1.0"
+	ocsfCategoryUID  = 6 // Application Activity
+	ocsfCategoryName = "Application Activity"
+	ocsfClassUID     = 6003
+	ocsfClassName    = "API Activity"
+)
+
+// OCSFExporter exports evidence records as Open Cybersecurity Schema
+// Framework (OCSF) API Activity events, one JSON event per line, for
+// Amazon Security Lake and SIEMs that ingest OCSF.
+//
+// Standard attributes carry the request, client, user, and policy outcome
+// (as the security control profile's action and disposition). Evidence
+// fields without an OCSF attribute, such as the model, token counts, and
+// matched rules, are kept under "unmapped". Prompt and response content is
+// not included.
+type OCSFExporter struct {
+	lines *JSONLExporter
+}
+
+// NewOCSFExporter creates an OCSF exporter. productVersion is reported as
+// the version of the product that logged the events.
+func NewOCSFExporter(productVersion string) *OCSFExporter {
+	return &OCSFExporter{lines: &JSONLExporter{
+		format: "ocsf",
+		convert: func(record *evidence.EvidenceRecord) any {
+			return ToOCSF(record, productVersion)
+		},
+	}}
+}
+
+// Export writes evidence records to the provided writer as OCSF events,
+// one per line.
+func (e *OCSFExporter) Export(ctx context.Context, records []*evidence.EvidenceRecord, w io.Writer) error {
+	return e.lines.Export(ctx, records, w)
+}
+
+// ExportStream exports evidence records from a channel as OCSF events, with
+// the same flushing behavior as JSONLExporter.ExportStream.
+func (e *OCSFExporter) ExportStream(ctx context.Context, recordsCh <-chan *evidence.EvidenceRecord, w io.Writer) error {
+	return e.lines.ExportStream(ctx, recordsCh, w)
+}
+
+// OCSFEvent is an OCSF API Activity event (class 6003).
+type OCSFEvent struct {
+	ActivityID   int    `json:"activity_id"`
+	ActivityName string `json:"activity_name"`
+	CategoryUID  int    `json:"category_uid"`
+	CategoryName string `json:"category_name"`
+	ClassUID     int    `json:"class_uid"`
+	ClassName    string `json:"class_name"`
+	TypeUID      int    `json:"type_uid"`
+	TypeName     string `json:"type_name"`
+
+	Time     int64 `json:"time"`               // Milliseconds since the epoch
+	Duration int64 `json:"duration,omitempty"` // Milliseconds
+
+	SeverityID   int    `json:"severity_id"`
+	Severity     string `json:"severity"`
+	StatusID     int    `json:"status_id"`
+	Status       string `json:"status"`
+	StatusCode   string `json:"status_code,omitempty"`
+	StatusDetail string `json:"status_detail,omitempty"`
+
+	// Security control profile
+	ActionID      int    `json:"action_id"`
+	Action        string `json:"action"`
+	DispositionID int    `json:"disposition_id"`
+	Disposition   string `json:"disposition"`
+
+	Metadata     OCSFMetadata      `json:"metadata"`
+	Actor        OCSFActor         `json:"actor"`
+	API          OCSFAPI           `json:"api"`
+	Cloud        OCSFCloud         `json:"cloud"`
+	SrcEndpoint  OCSFEndpoint      `json:"src_endpoint"`
+	HTTPRequest  *OCSFHTTPRequest  `json:"http_request,omitempty"`
+	HTTPResponse *OCSFHTTPResponse `json:"http_response,omitempty"`
+
+	Unmapped map[string]any `json:"unmapped,omitempty"`
+}
+
+// OCSFMetadata is the OCSF metadata object.
+type OCSFMetadata struct {
+	Version        string      `json:"version"`
+	Product        OCSFProduct `json:"product"`
+	Profiles       []string    `json:"profiles"`
+	UID            string      `json:"uid"`
+	CorrelationUID string      `json:"correlation_uid,omitempty"`
+	LoggedTime     int64       `json:"logged_time,omitempty"`
+}
+
+// OCSFProduct is the OCSF product object.
+type OCSFProduct struct {
+	Name       string `json:"name"`
+	VendorName string `json:"vendor_name"`
+	Version    string `json:"version,omitempty"`
+}
+
+// OCSFActor is the OCSF actor object.
+type OCSFActor struct {
+	User OCSFUser `json:"user"`
+}
+
+// OCSFUser is the OCSF user object. Teams are reported as groups.
+type OCSFUser struct {
+	UID    string      `json:"uid,omitempty"`
+	Groups []OCSFGroup `json:"groups,omitempty"`
+}
+
+// OCSFGroup is the OCSF group object.
+type OCSFGroup struct {
+	Name string `json:"name"`
+}
+
+// OCSFAPI is the OCSF api object.
+type OCSFAPI struct {
+	Operation string           `json:"operation"`
+	Service   OCSFService      `json:"service"`
+	Request   OCSFAPIRequest   `json:"request"`
+	Response  *OCSFAPIResponse `json:"response,omitempty"`
+}
+
+// OCSFService is the OCSF service object.
+type OCSFService struct {
+	Name string `json:"name,omitempty"`
+}
+
+// OCSFAPIRequest is the OCSF request object.
+type OCSFAPIRequest struct {
+	UID string `json:"uid"`
+}
+
+// OCSFAPIResponse is the OCSF response object.
+type OCSFAPIResponse struct {
+	Code         int    `json:"code,omitempty"`
+	Error        string `json:"error,omitempty"`
+	ErrorMessage string `json:"error_message,omitempty"`
+}
+
+// OCSFCloud is the OCSF cloud object (cloud profile). The provider is the
+// LLM provider.
+type OCSFCloud struct {
+	Provider string `json:"provider"`
+}
+
+// OCSFEndpoint is the OCSF network endpoint object.
+type OCSFEndpoint struct {
+	IP string `json:"ip,omitempty"`
+}
+
+// OCSFHTTPRequest is the OCSF HTTP request object.
+type OCSFHTTPRequest struct {
+	HTTPMethod string  `json:"http_method,omitempty"`
+	URL        OCSFURL `json:"url"`
+}
+
+// OCSFURL is the OCSF URL object.
+type OCSFURL struct {
+	Path string `json:"path,omitempty"`
+}
+
+// OCSFHTTPResponse is the OCSF HTTP response object.
+type OCSFHTTPResponse struct {
+	Code int `json:"code"`
+}
+
+// ToOCSF maps an evidence record to an OCSF API Activity event.
+func ToOCSF(r *evidence.EvidenceRecord, productVersion string) *OCSFEvent {
+	activityID, activityName := ocsfActivity(r.RequestMethod)
+	decision := r.PolicyDecision
+	if decision == "" {
+		decision = "allow"
+	}
+
+	e := &OCSFEvent{
+		ActivityID:   activityID,
+		ActivityName: activityName,
+		CategoryUID:  ocsfCategoryUID,
+		CategoryName: ocsfCategoryName,
+		ClassUID:     ocsfClassUID,
+		ClassName:    ocsfClassName,
+		TypeUID:      ocsfClassUID*100 + activityID,
+		TypeName:     ocsfClassName + ": " + activityName,
+		Time:         r.RequestTime.UnixMilli(),
+
+		SeverityID: 1,
+		Severity:   "Informational",
+		StatusID:   1,
+		Status:     "Success",
+
+		ActionID:      1,
+		Action:        "Allowed",
+		DispositionID: 1,
+		Disposition:   "Allowed",
+
+		Metadata: OCSFMetadata{
+			Version: OCSFVersion,
+			Product: OCSFProduct{
+				Name:       "Jupiter",
+				VendorName: "Mercator",
+				Version:    productVersion,
+			},
+			Profiles:       []string{"cloud", "security_control"},
+			UID:            r.ID,
+			CorrelationUID: r.TraceID,
+		},
+		Actor: OCSFActor{User: OCSFUser{UID: r.UserID}},
+		API: OCSFAPI{
+			Operation: r.RequestPath,
+			Service:   OCSFService{Name: r.Provider},
+			Request:   OCSFAPIRequest{UID: r.RequestID},
+		},
+		Cloud:       OCSFCloud{Provider: r.Provider},
+		SrcEndpoint: OCSFEndpoint{IP: r.IPAddress},
+	}
+	if e.API.Operation == "" {
+		e.API.Operation = "completion"
+	}
+	if !r.RecordedTime.IsZero() {
+		e.Metadata.LoggedTime = r.RecordedTime.UnixMilli()
+	}
+	if !r.ResponseTime.IsZero() && r.ResponseTime.After(r.RequestTime) {
+		e.Duration = r.ResponseTime.Sub(r.RequestTime).Milliseconds()
+	}
+	if r.TeamID != "" {
+		e.Actor.User.Groups = []OCSFGroup{{Name: r.TeamID}}
+	}
+	if r.RequestMethod != "" || r.RequestPath != "" {
+		e.HTTPRequest = &OCSFHTTPRequest{HTTPMethod: r.RequestMethod, URL: OCSFURL{Path: r.RequestPath}}
+	}
+	if r.ResponseStatus != 0 {
+		e.HTTPResponse = &OCSFHTTPResponse{Code: r.ResponseStatus}
+		e.StatusCode = strconv.Itoa(r.ResponseStatus)
+	}
+	if r.ResponseStatus != 0 || r.Error != "" {
+		e.API.Response = &OCSFAPIResponse{Code: r.ResponseStatus, Error: r.ErrorType, ErrorMessage: r.Error}
+	}
+
+	switch {
+	case decision == "block":
+		e.SeverityID, e.Severity = 4, "High"
+		e.StatusID, e.Status = 2, "Failure"
+		e.StatusDetail = r.BlockReason
+		e.ActionID, e.Action = 2, "Denied"
+		e.DispositionID, e.Disposition = 2, "Blocked"
+	case r.Error != "":
+		e.SeverityID, e.Severity = 3, "Medium"
+		e.StatusID, e.Status = 2, "Failure"
+		e.StatusDetail = r.Error
+	case decision != "allow":
+		e.SeverityID, e.Severity = 2, "Low"
+	}
+
+	e.Unmapped = ocsfUnmapped(r, decision)
+	return e
+}
+
+// ocsfActivity maps an HTTP method to an API Activity activity.
+func ocsfActivity(method string) (int, string) {
+	switch strings.ToUpper(method) {
+	case http.MethodPost:
+		return 1, "Create"
+	case http.MethodGet, http.MethodHead:
+		return 2, "Read"
+	case http.MethodPut, http.MethodPatch:
+		return 3, "Update"
+	case http.MethodDelete:
+		return 4, "Delete"
+	default:
+		return 99, "Other"
+	}
+}
+
+// ocsfUnmapped returns the evidence fields without an OCSF attribute.
+func ocsfUnmapped(r *evidence.EvidenceRecord, decision string) map[string]any {
+	unmapped := map[string]any{
+		"policy_decision": decision,
+	}
+	add := func(key string, value any, set bool) {
+		if set {
+			unmapped[key] = value
+		}
+	}
+	add("model", r.Model, r.Model != "")
+	add("provider_model", r.ProviderModel, r.ProviderModel != "")
+	add("decision_id", r.DecisionID, r.DecisionID != "")
+	add("cost_tag", r.CostTag, r.CostTag != "")
+	add("limit_bypass", r.LimitBypass, r.LimitBypass != "")
+	add("pii_types", r.PIITypes, len(r.PIITypes) > 0)
+	add("response_analysis", r.ResponseAnalysis, r.ResponseAnalysis != nil)
+	add("data_classification", r.DataClassification, r.DataClassification != "")
+	add("data_classification_level", r.DataClassificationLevel, r.DataClassification != "")
+	add("findings", r.Findings, len(r.Findings) > 0)
+	add("prompt_tokens", r.PromptTokens, r.PromptTokens > 0)
+	add("completion_tokens", r.CompletionTokens, r.CompletionTokens > 0)
+	add("total_tokens", r.TotalTokens, r.TotalTokens > 0)
+	add("cost", r.ActualCost, r.ActualCost > 0)
+	add("request_hash", r.RequestHash, r.RequestHash != "")
+	add("response_hash", r.ResponseHash, r.ResponseHash != "")
+
+	if len(r.MatchedRules) > 0 {
+		rules := make([]string, 0, len(r.MatchedRules))
+		for _, rule := range r.MatchedRules {
+			rules = append(rules, rule.PolicyID+"/"+rule.RuleID)
+		}
+		unmapped["matched_rules"] = rules
+	}
+	return unmapped
+}
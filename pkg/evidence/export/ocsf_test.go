@@ -0,0 +1,91 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+func TestOCSFExporter_Export(t *testing.T) {
+	requestTime := time.Date(2025, 11, 19, 14, 0, 0, 0, time.UTC)
+	records := []*evidence.EvidenceRecord{
+		{
+			ID:             "e1",
+			RequestID:      "req-1",
+			RequestTime:    requestTime,
+			ResponseTime:   requestTime.Add(1500 * time.Millisecond),
+			RequestMethod:  "POST",
+			RequestPath:    "/v1/chat/completions",
+			UserID:         "alice",
+			TeamID:         "research",
+			IPAddress:      "10.0.0.7",
+			Provider:       "openai",
+			Model:          "gpt-4",
+			UserPrompt:     "my SSN is 123-45-6789",
+			PolicyDecision: "block",
+			BlockReason:    "PII in prompt",
+			MatchedRules:   []evidence.MatchedRuleRecord{{PolicyID: "security", RuleID: "block-pii"}},
+			ResponseStatus: 403,
+			TraceID:        "4bf92f3577b34da6a3ce929d0e0e4736",
+		},
+		{ID: "e2", RequestID: "req-2", RequestTime: requestTime, Provider: "anthropic", Error: "upstream timeout", ErrorType: "timeout"},
+	}
+
+	var buf bytes.Buffer
+	if err := NewOCSFExporter("1.2.3").Export(context.Background(), records, &buf); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("123-45-6789")) {
+		t.Error("OCSF events contain prompt content")
+	}
+
+	var events []OCSFEvent
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var event OCSFEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatalf("invalid event %q: %v", scanner.Text(), err)
+		}
+		events = append(events, event)
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2", len(events))
+	}
+
+	blocked := events[0]
+	if blocked.ClassUID != 6003 || blocked.CategoryUID != 6 || blocked.TypeUID != 600301 || blocked.ActivityName != "Create" {
+		t.Errorf("unexpected classification: %+v", blocked)
+	}
+	if blocked.Time != requestTime.UnixMilli() || blocked.Duration != 1500 {
+		t.Errorf("time = %d, duration = %d", blocked.Time, blocked.Duration)
+	}
+	if blocked.Action != "Denied" || blocked.Disposition != "Blocked" || blocked.Status != "Failure" || blocked.StatusDetail != "PII in prompt" {
+		t.Errorf("unexpected outcome: action=%s disposition=%s status=%s detail=%s",
+			blocked.Action, blocked.Disposition, blocked.Status, blocked.StatusDetail)
+	}
+	if blocked.Metadata.UID != "e1" || blocked.Metadata.CorrelationUID != records[0].TraceID || blocked.Metadata.Product.Version != "1.2.3" {
+		t.Errorf("unexpected metadata: %+v", blocked.Metadata)
+	}
+	if blocked.Actor.User.UID != "alice" || len(blocked.Actor.User.Groups) != 1 || blocked.SrcEndpoint.IP != "10.0.0.7" {
+		t.Errorf("unexpected actor or endpoint: %+v, %+v", blocked.Actor, blocked.SrcEndpoint)
+	}
+	if blocked.API.Operation != "/v1/chat/completions" || blocked.API.Request.UID != "req-1" || blocked.HTTPResponse == nil || blocked.HTTPResponse.Code != 403 {
+		t.Errorf("unexpected api: %+v", blocked.API)
+	}
+	if blocked.Unmapped["model"] != "gpt-4" {
+		t.Errorf("unmapped = %v", blocked.Unmapped)
+	}
+
+	failed := events[1]
+	if failed.ActivityID != 99 || failed.Action != "Allowed" || failed.Status != "Failure" || failed.StatusDetail != "upstream timeout" {
+		t.Errorf("unexpected failed event: %+v", failed)
+	}
+	if failed.API.Response == nil || failed.API.Response.Error != "timeout" {
+		t.Errorf("unexpected api response: %+v", failed.API.Response)
+	}
+}
@@ -0,0 +1,340 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+// Job is a scheduled export of new evidence records to a destination.
+//
+// Each run exports the records recorded since the previous run, up to
+// Delay before the run, as a single file. Runs page by recorded time, the
+// time a record was completed, rather than request time: a long streamed
+// response is recorded long after its request, and would be skipped by a
+// run that already passed its request time. A record written to storage
+// more than Delay after its recorded time is still skipped, so Delay must
+// cover the recorder's write latency.
+//
+// The high-water mark of each job is kept in a StateFile and only
+// advanced once the destination accepted the file. Delivery is
+// at-least-once: if the process stops between the upload and the state
+// update, the next run exports the same records again.
+type Job struct {
+	// Name identifies the job in state, logs, and file names. Required.
+	Name string
+
+	// Schedule is a standard cron expression, e.g. "0 * * * *". Required.
+	Schedule string
+
+	// Format is the file format: "jsonl", "json", "csv", or "ocsf" (OCSF
+	// API Activity events as JSON Lines).
+	// Default: "jsonl"
+	Format string
+
+	// Delay excludes records recorded less than this before a run, so
+	// that records still being written are exported by a later run.
+	Delay time.Duration
+
+	// MaxRecords caps the records exported per run, rounded up to whole
+	// cursor pages; the remainder is exported by the next runs. A run
+	// holds its file in memory, so runs are always capped.
+	// Default: DefaultMaxRecords
+	MaxRecords int
+
+	// Query optionally restricts the exported records (e.g., to a team).
+	// Its time range, limit, and sort settings are ignored.
+	Query *evidence.Query
+
+	// Destination receives the exported files. Required.
+	Destination Destination
+}
+
+// DefaultMaxRecords is the default number of records exported per run.
+const DefaultMaxRecords = 100000
+
+// JobState is the persisted high-water mark of a job.
+type JobState struct {
+	// Position is the recorded time of the last exported record. State
+	// written by earlier versions holds its request time instead, which
+	// is never later, so resuming from it repeats records but skips none.
+	Position time.Time `json:"position"`
+
+	// Seen holds the ID of the last record exported at Position. State
+	// written by earlier versions lists all IDs exported at Position.
+	Seen []string `json:"seen,omitempty"`
+
+	// LastRun is the time of the last successful run.
+	LastRun time.Time `json:"last_run"`
+
+	// Exported is the total number of records exported by the job.
+	Exported int64 `json:"exported"`
+}
+
+// StateFile persists job state to a JSON file, rewritten on every change.
+type StateFile struct {
+	path string
+
+	mu   sync.Mutex
+	jobs map[string]*JobState
+}
+
+// OpenStateFile loads the job state at path. A missing file is created on
+// the first change.
+func OpenStateFile(path string) (*StateFile, error) {
+	s := &StateFile{path: path, jobs: make(map[string]*JobState)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, evidence.NewStorageError("export", "load state", err)
+	}
+	if err := json.Unmarshal(data, &s.jobs); err != nil {
+		return nil, evidence.NewStorageError("export", "load state", err)
+	}
+	return s, nil
+}
+
+// Get returns the state of a job, or nil if it never ran.
+func (s *StateFile) Get(job string) *JobState {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, ok := s.jobs[job]
+	if !ok {
+		return nil
+	}
+	copied := *state
+	return &copied
+}
+
+// Set stores the state of a job.
+func (s *StateFile) Set(job string, state *JobState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous, existed := s.jobs[job]
+	copied := *state
+	s.jobs[job] = &copied
+	if err := s.save(); err != nil {
+		if existed {
+			s.jobs[job] = previous
+		} else {
+			delete(s.jobs, job)
+		}
+		return err
+	}
+	return nil
+}
+
+// save writes the state to a temporary file and renames it over the state
+// file, so that a crash never leaves a partial file. The caller must hold
+// s.mu.
+func (s *StateFile) save() error {
+	data, err := json.MarshalIndent(s.jobs, "", "  ")
+	if err != nil {
+		return evidence.NewStorageError("export", "save state", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0o755); err != nil {
+		return evidence.NewStorageError("export", "save state", err)
+	}
+
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return evidence.NewStorageError("export", "save state", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return evidence.NewStorageError("export", "save state", err)
+	}
+	return nil
+}
+
+// Scheduler runs export jobs on their cron schedules. A job is skipped if
+// its previous run is still in progress.
+type Scheduler struct {
+	store  evidence.Storage
+	state  *StateFile
+	jobs   []*Job
+	cron   *cron.Cron
+	logger *slog.Logger
+
+	// now returns the current time; replaced in tests.
+	now func() time.Time
+}
+
+// NewScheduler creates a scheduler for jobs, validating their
+// configuration.
+func NewScheduler(store evidence.Storage, state *StateFile, jobs []*Job) (*Scheduler, error) {
+	logger := slog.Default().With("component", "evidence.export")
+	s := &Scheduler{
+		store:  store,
+		state:  state,
+		jobs:   jobs,
+		cron:   cron.New(cron.WithChain(cron.SkipIfStillRunning(cron.DiscardLogger))),
+		logger: logger,
+		now:    time.Now,
+	}
+
+	names := make(map[string]bool, len(jobs))
+	for _, job := range jobs {
+		if job.Name == "" {
+			return nil, errors.New("export job requires a name")
+		}
+		if names[job.Name] {
+			return nil, fmt.Errorf("duplicate export job %q", job.Name)
+		}
+		names[job.Name] = true
+		if job.Destination == nil {
+			return nil, fmt.Errorf("export job %q requires a destination", job.Name)
+		}
+		if _, _, _, err := newJobExporter(job.Format); err != nil {
+			return nil, fmt.Errorf("export job %q: %w", job.Name, err)
+		}
+		if _, err := cron.ParseStandard(job.Schedule); err != nil {
+			return nil, fmt.Errorf("export job %q has invalid cron schedule %q: %w", job.Name, job.Schedule, err)
+		}
+	}
+	return s, nil
+}
+
+// Start schedules the jobs until ctx is cancelled or Stop is called.
+func (s *Scheduler) Start(ctx context.Context) error {
+	for _, job := range s.jobs {
+		_, err := s.cron.AddFunc(job.Schedule, func() {
+			if _, err := s.Run(ctx, job); err != nil {
+				s.logger.Error("scheduled export failed",
+					"job", job.Name,
+					"destination", job.Destination.Name(),
+					"error", err,
+				)
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("failed to schedule export job %q: %w", job.Name, err)
+		}
+	}
+	s.cron.Start()
+
+	s.logger.Info("export scheduler started", "jobs", len(s.jobs))
+
+	go func() {
+		<-ctx.Done()
+		s.Stop()
+	}()
+	return nil
+}
+
+// Stop stops the scheduler and waits for running exports to finish.
+func (s *Scheduler) Stop() {
+	<-s.cron.Stop().Done()
+}
+
+// Run exports the records stored since the job's previous run and returns
+// how many were exported. No file is written if there are no new records.
+func (s *Scheduler) Run(ctx context.Context, job *Job) (int, error) {
+	exporter, ext, contentType, err := newJobExporter(job.Format)
+	if err != nil {
+		return 0, err
+	}
+
+	end := s.now().Add(-job.Delay).UTC()
+	q := evidence.Query{}
+	if job.Query != nil {
+		q = *job.Query
+	}
+	q.StartTime = nil
+	q.EndTime = &end
+	q.TimeField = "recorded_time"
+
+	maxRecords := job.MaxRecords
+	if maxRecords <= 0 {
+		maxRecords = DefaultMaxRecords
+	}
+	pageSize := min(DefaultPageSize, maxRecords)
+	cursor := NewCursor(s.store, &q, pageSize)
+	previous := s.state.Get(job.Name)
+	if previous != nil {
+		cursor.Resume(previous.Position, previous.Seen)
+	}
+
+	var records []*evidence.EvidenceRecord
+	for len(records) < maxRecords {
+		page, err := cursor.Next(ctx)
+		if err != nil {
+			return 0, err
+		}
+		if len(page) == 0 {
+			break
+		}
+		records = append(records, page...)
+	}
+	if len(records) == 0 {
+		s.logger.Debug("no new evidence to export", "job", job.Name)
+		return 0, nil
+	}
+
+	var buf bytes.Buffer
+	if err := exporter.Export(ctx, records, &buf); err != nil {
+		return 0, err
+	}
+	name := fmt.Sprintf("%s-%s.%s", job.Name, end.Format("20060102T150405Z"), ext)
+	if err := job.Destination.Put(ctx, name, contentType, buf.Bytes()); err != nil {
+		return 0, fmt.Errorf("failed to deliver %s to %s: %w", name, job.Destination.Name(), err)
+	}
+
+	state := &JobState{
+		Position: cursor.Position(),
+		Seen:     cursor.Seen(),
+		LastRun:  s.now().UTC(),
+		Exported: int64(len(records)),
+	}
+	if previous != nil {
+		state.Exported += previous.Exported
+	}
+	if err := s.state.Set(job.Name, state); err != nil {
+		return 0, err
+	}
+
+	s.logger.Info("evidence exported",
+		"job", job.Name,
+		"destination", job.Destination.Name(),
+		"file", name,
+		"records", len(records),
+	)
+	return len(records), nil
+}
+
+// jobExporter is implemented by the exporters of this package.
+type jobExporter interface {
+	Export(ctx context.Context, records []*evidence.EvidenceRecord, w io.Writer) error
+}
+
+// newJobExporter returns the exporter, file extension, and content type of
+// an export format.
+func newJobExporter(format string) (jobExporter, string, string, error) {
+	switch format {
+	case "", "jsonl":
+		return NewJSONLExporter(), "jsonl", "application/x-ndjson", nil
+	case "json":
+		return NewJSONExporter(false), "json", "application/json", nil
+	case "csv":
+		return NewCSVExporter(true), "csv", "text/csv", nil
+	case "ocsf":
+		return NewOCSFExporter(""), "ocsf.jsonl", "application/x-ndjson", nil
+	default:
+		return nil, "", "", fmt.Errorf("unsupported export format %q (supported: jsonl, json, csv, ocsf)", format)
+	}
+}
@@ -0,0 +1,229 @@
+package export
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/policy/events"
+)
+
+// exportReceiver is a webhook destination that records the exported IDs.
+type exportReceiver struct {
+	mu     sync.Mutex
+	files  []string
+	ids    []string
+	status int
+}
+
+func (r *exportReceiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	body, _ := io.ReadAll(req.Body)
+	if r.status != 0 {
+		w.WriteHeader(r.status)
+		return
+	}
+	if got, want := req.Header.Get(events.SignatureHeader), events.Sign("s3cret", body); got != want {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	r.files = append(r.files, req.Header.Get(ExportNameHeader))
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	for scanner.Scan() {
+		var record evidence.EvidenceRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		r.ids = append(r.ids, record.ID)
+	}
+}
+
+func newTestJob(t *testing.T, url string) *Job {
+	t.Helper()
+	dest, err := NewDestination(&DestinationConfig{URL: url, Secret: "s3cret"})
+	if err != nil {
+		t.Fatalf("NewDestination() error = %v", err)
+	}
+	return &Job{Name: "siem", Schedule: "@hourly", Delay: 5 * time.Second, Destination: dest}
+}
+
+func TestScheduler_RunExportsNewRecordsOnce(t *testing.T) {
+	store, base := newCursorStore(t, 30) // 3 records per second, base to base+9s
+	receiver := &exportReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	statePath := filepath.Join(t.TempDir(), "state.json")
+	state, err := OpenStateFile(statePath)
+	if err != nil {
+		t.Fatalf("OpenStateFile() error = %v", err)
+	}
+	job := newTestJob(t, server.URL)
+	scheduler, err := NewScheduler(store, state, []*Job{job})
+	if err != nil {
+		t.Fatalf("NewScheduler() error = %v", err)
+	}
+	ctx := context.Background()
+
+	// Records newer than the delay are left for a later run
+	scheduler.now = func() time.Time { return base.Add(10 * time.Second) }
+	if n, err := scheduler.Run(ctx, job); err != nil || n != 18 {
+		t.Fatalf("Run() = %d, %v, want 18 records", n, err)
+	}
+	if n, err := scheduler.Run(ctx, job); err != nil || n != 0 {
+		t.Fatalf("second Run() = %d, %v, want no records", n, err)
+	}
+
+	// A failed delivery does not advance the high-water mark
+	scheduler.now = func() time.Time { return base.Add(time.Hour) }
+	receiver.status = http.StatusServiceUnavailable
+	if _, err := scheduler.Run(ctx, job); err == nil {
+		t.Fatal("Run() with failing destination succeeded")
+	}
+	receiver.status = 0
+
+	// The state survives a restart
+	state, err = OpenStateFile(statePath)
+	if err != nil {
+		t.Fatalf("OpenStateFile() error = %v", err)
+	}
+	scheduler, err = NewScheduler(store, state, []*Job{job})
+	if err != nil {
+		t.Fatalf("NewScheduler() error = %v", err)
+	}
+	scheduler.now = func() time.Time { return base.Add(time.Hour) }
+	if n, err := scheduler.Run(ctx, job); err != nil || n != 12 {
+		t.Fatalf("Run() after restart = %d, %v, want 12 records", n, err)
+	}
+
+	seen := make(map[string]bool)
+	for _, id := range receiver.ids {
+		if seen[id] {
+			t.Errorf("record %s exported twice", id)
+		}
+		seen[id] = true
+	}
+	if len(seen) != 30 {
+		t.Errorf("exported %d records, want 30", len(seen))
+	}
+	if len(receiver.files) != 2 || receiver.files[0] != "siem-20251101T000005Z.jsonl" {
+		t.Errorf("files = %v", receiver.files)
+	}
+	if got := state.Get("siem"); got == nil || got.Exported != 30 {
+		t.Errorf("state = %+v, want 30 exported", got)
+	}
+}
+
+func TestScheduler_RunExportsLateRecords(t *testing.T) {
+	store, base := newCursorStore(t, 30)
+	receiver := &exportReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	state, err := OpenStateFile(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("OpenStateFile() error = %v", err)
+	}
+	job := newTestJob(t, server.URL)
+	scheduler, err := NewScheduler(store, state, []*Job{job})
+	if err != nil {
+		t.Fatalf("NewScheduler() error = %v", err)
+	}
+	ctx := context.Background()
+
+	scheduler.now = func() time.Time { return base.Add(time.Minute) }
+	if n, err := scheduler.Run(ctx, job); err != nil || n != 30 {
+		t.Fatalf("Run() = %d, %v, want 30 records", n, err)
+	}
+
+	// A long streamed response is recorded after records of later
+	// requests were exported
+	late := &evidence.EvidenceRecord{
+		ID:           "late",
+		RequestTime:  base,
+		RecordedTime: base.Add(2 * time.Minute),
+	}
+	if err := store.Store(ctx, late); err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+	scheduler.now = func() time.Time { return base.Add(time.Hour) }
+	if n, err := scheduler.Run(ctx, job); err != nil || n != 1 {
+		t.Fatalf("Run() after late record = %d, %v, want 1 record", n, err)
+	}
+	if last := receiver.ids[len(receiver.ids)-1]; last != "late" {
+		t.Errorf("last exported record = %s, want late", last)
+	}
+}
+
+func TestScheduler_MaxRecords(t *testing.T) {
+	store, base := newCursorStore(t, 30)
+	receiver := &exportReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	state, err := OpenStateFile(filepath.Join(t.TempDir(), "state.json"))
+	if err != nil {
+		t.Fatalf("OpenStateFile() error = %v", err)
+	}
+	job := newTestJob(t, server.URL)
+	job.MaxRecords = 7
+	scheduler, err := NewScheduler(store, state, []*Job{job})
+	if err != nil {
+		t.Fatalf("NewScheduler() error = %v", err)
+	}
+	scheduler.now = func() time.Time { return base.Add(time.Hour) }
+
+	total := 0
+	for i := 0; i < 10; i++ {
+		n, err := scheduler.Run(context.Background(), job)
+		if err != nil {
+			t.Fatalf("Run() error = %v", err)
+		}
+		if n > 7 {
+			t.Fatalf("Run() exported %d records, want at most 7", n)
+		}
+		total += n
+	}
+	if total != 30 || len(receiver.ids) != 30 {
+		t.Errorf("exported %d records (%d received), want 30", total, len(receiver.ids))
+	}
+}
+
+func TestNewScheduler_InvalidJobs(t *testing.T) {
+	dest, err := NewDestination(&DestinationConfig{URL: "https://example.com/ingest"})
+	if err != nil {
+		t.Fatalf("NewDestination() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		jobs []*Job
+	}{
+		{"invalid schedule", []*Job{{Name: "a", Schedule: "every hour", Destination: dest}}},
+		{"invalid format", []*Job{{Name: "a", Schedule: "@hourly", Format: "xml", Destination: dest}}},
+		{"missing destination", []*Job{{Name: "a", Schedule: "@hourly"}}},
+		{"duplicate name", []*Job{
+			{Name: "a", Schedule: "@hourly", Destination: dest},
+			{Name: "a", Schedule: "@daily", Destination: dest},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewScheduler(nil, nil, tt.jobs); err == nil {
+				t.Error("NewScheduler() succeeded, want error")
+			}
+		})
+	}
+}
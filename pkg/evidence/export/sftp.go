@@ -0,0 +1,291 @@
+package export
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"path"
+	"strings"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// SFTP protocol version 3 packet types and flags (draft-ietf-secsh-filexfer-02)
+// used to upload files.
+const (
+	sftpInit     = 1
+	sftpVersion  = 2
+	sftpOpen     = 3
+	sftpClose    = 4
+	sftpWrite    = 6
+	sftpRename   = 18
+	sftpStatus   = 101
+	sftpHandle   = 102
+	sftpStatusOK = 0
+
+	sftpFlagWrite    = 0x02
+	sftpFlagCreate   = 0x08
+	sftpFlagTruncate = 0x10
+
+	// sftpChunkSize is the data size of a write request. Servers must
+	// accept at least 32 KiB packets.
+	sftpChunkSize = 32 * 1024
+)
+
+// sftpDestination uploads each export as a file over SFTP. A connection is
+// opened per export, since exports are infrequent.
+type sftpDestination struct {
+	addr      string
+	directory string
+	config    *ssh.ClientConfig
+	name      string
+}
+
+func newSFTPDestination(cfg *DestinationConfig, u *url.URL) (*sftpDestination, error) {
+	if u.User == nil || u.User.Username() == "" {
+		return nil, fmt.Errorf("SFTP export destination %q requires a user", cfg.URL)
+	}
+	if cfg.HostKey == "" {
+		return nil, fmt.Errorf("SFTP export destination %q requires a host key", cfg.URL)
+	}
+	hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(cfg.HostKey))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SFTP host key: %w", err)
+	}
+
+	var auth []ssh.AuthMethod
+	if len(cfg.PrivateKey) > 0 {
+		signer, err := ssh.ParsePrivateKey(cfg.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SFTP private key: %w", err)
+		}
+		auth = append(auth, ssh.PublicKeys(signer))
+	}
+	if cfg.Password != "" {
+		auth = append(auth, ssh.Password(cfg.Password))
+	}
+	if len(auth) == 0 {
+		return nil, fmt.Errorf("SFTP export destination %q requires a password or private key", cfg.URL)
+	}
+
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "22")
+	}
+	return &sftpDestination{
+		addr:      addr,
+		directory: u.Path,
+		config: &ssh.ClientConfig{
+			User:            u.User.Username(),
+			Auth:            auth,
+			HostKeyCallback: ssh.FixedHostKey(hostKey),
+		},
+		name: "sftp://" + u.User.Username() + "@" + u.Host + u.Path,
+	}, nil
+}
+
+func (d *sftpDestination) Name() string {
+	return d.name
+}
+
+// Put uploads the file under a temporary name and renames it once
+// complete, so that readers of the directory never see partial exports.
+func (d *sftpDestination) Put(ctx context.Context, name, contentType string, data []byte) error {
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", d.addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s: %w", d.addr, err)
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, d.addr, d.config)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("SSH handshake with %s failed: %w", d.addr, err)
+	}
+	client := ssh.NewClient(sshConn, chans, reqs)
+	defer client.Close()
+
+	// Unblock the protocol exchange when the context ends
+	stop := context.AfterFunc(ctx, func() { client.Close() })
+	defer stop()
+
+	session, err := client.NewSession()
+	if err != nil {
+		return err
+	}
+	defer session.Close()
+	w, err := session.StdinPipe()
+	if err != nil {
+		return err
+	}
+	r, err := session.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		return fmt.Errorf("failed to start SFTP subsystem: %w", err)
+	}
+
+	target := path.Join(d.directory, name)
+	if err := newSFTPClient(r, w).upload(target+".part", target, data); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("SFTP upload of %s failed: %w", target, err)
+	}
+	return nil
+}
+
+// sftpClient speaks the subset of SFTP version 3 needed to upload a file.
+type sftpClient struct {
+	r      io.Reader
+	w      io.Writer
+	nextID uint32
+}
+
+func newSFTPClient(r io.Reader, w io.Writer) *sftpClient {
+	return &sftpClient{r: r, w: w}
+}
+
+// upload writes data to temp and renames it to target.
+func (c *sftpClient) upload(temp, target string, data []byte) error {
+	if err := c.send(sftpInit, uint32(3)); err != nil {
+		return err
+	}
+	if typ, _, err := c.recv(); err != nil {
+		return err
+	} else if typ != sftpVersion {
+		return fmt.Errorf("unexpected SFTP packet type %d, want version", typ)
+	}
+
+	handle, err := c.open(temp)
+	if err != nil {
+		return err
+	}
+	for offset := 0; offset < len(data); offset += sftpChunkSize {
+		chunk := data[offset:min(offset+sftpChunkSize, len(data))]
+		if err := c.call(sftpWrite, handle, uint64(offset), string(chunk)); err != nil {
+			return fmt.Errorf("write: %w", err)
+		}
+	}
+	if err := c.call(sftpClose, handle); err != nil {
+		return fmt.Errorf("close: %w", err)
+	}
+	if err := c.call(sftpRename, temp, target); err != nil {
+		return fmt.Errorf("rename: %w", err)
+	}
+	return nil
+}
+
+// open opens a file for writing, creating or truncating it, and returns
+// its handle.
+func (c *sftpClient) open(name string) (string, error) {
+	id := c.id()
+	if err := c.send(sftpOpen, id, name, uint32(sftpFlagWrite|sftpFlagCreate|sftpFlagTruncate), uint32(0)); err != nil {
+		return "", err
+	}
+	typ, payload, err := c.recv()
+	if err != nil {
+		return "", err
+	}
+	if typ == sftpStatus {
+		return "", fmt.Errorf("open: %w", statusError(payload))
+	}
+	if typ != sftpHandle || len(payload) < 8 || binary.BigEndian.Uint32(payload) != id {
+		return "", fmt.Errorf("open: unexpected SFTP packet type %d", typ)
+	}
+	handle, _, ok := readString(payload[4:])
+	if !ok {
+		return "", errors.New("open: malformed handle")
+	}
+	return handle, nil
+}
+
+// call sends a request with a new ID and waits for its status.
+func (c *sftpClient) call(typ byte, fields ...any) error {
+	id := c.id()
+	if err := c.send(typ, append([]any{id}, fields...)...); err != nil {
+		return err
+	}
+	respType, payload, err := c.recv()
+	if err != nil {
+		return err
+	}
+	if respType != sftpStatus || len(payload) < 4 || binary.BigEndian.Uint32(payload) != id {
+		return fmt.Errorf("unexpected SFTP packet type %d", respType)
+	}
+	return statusError(payload)
+}
+
+func (c *sftpClient) id() uint32 {
+	c.nextID++
+	return c.nextID
+}
+
+// send writes a packet with fields encoded as SFTP uint32, uint64, or
+// string values.
+func (c *sftpClient) send(typ byte, fields ...any) error {
+	packet := []byte{0, 0, 0, 0, typ}
+	for _, field := range fields {
+		switch v := field.(type) {
+		case uint32:
+			packet = binary.BigEndian.AppendUint32(packet, v)
+		case uint64:
+			packet = binary.BigEndian.AppendUint64(packet, v)
+		case string:
+			packet = binary.BigEndian.AppendUint32(packet, uint32(len(v)))
+			packet = append(packet, v...)
+		default:
+			panic(fmt.Sprintf("unsupported SFTP field type %T", field))
+		}
+	}
+	binary.BigEndian.PutUint32(packet, uint32(len(packet)-4))
+	_, err := c.w.Write(packet)
+	return err
+}
+
+// recv reads a packet and returns its type and payload.
+func (c *sftpClient) recv() (byte, []byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(c.r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	length := binary.BigEndian.Uint32(header[:4])
+	if length < 1 || length > 256*1024 {
+		return 0, nil, fmt.Errorf("invalid SFTP packet length %d", length)
+	}
+	payload := make([]byte, length-1)
+	if _, err := io.ReadFull(c.r, payload); err != nil {
+		return 0, nil, err
+	}
+	return header[4], payload, nil
+}
+
+// statusError returns the error of a status payload (request ID, code,
+// message), or nil for SSH_FX_OK.
+func statusError(payload []byte) error {
+	if len(payload) < 8 {
+		return errors.New("malformed SFTP status")
+	}
+	code := binary.BigEndian.Uint32(payload[4:8])
+	if code == sftpStatusOK {
+		return nil
+	}
+	message, _, _ := readString(payload[8:])
+	return fmt.Errorf("SFTP status %d: %s", code, strings.TrimSpace(message))
+}
+
+// readString reads an SFTP string and returns it with the remaining bytes.
+func readString(b []byte) (string, []byte, bool) {
+	if len(b) < 4 {
+		return "", nil, false
+	}
+	n := binary.BigEndian.Uint32(b)
+	if uint32(len(b)-4) < n {
+		return "", nil, false
+	}
+	return string(b[4 : 4+n]), b[4+n:], true
+}
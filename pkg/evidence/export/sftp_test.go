@@ -0,0 +1,130 @@
+package export
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+)
+
+// fakeSFTPServer serves the SFTP requests of an upload from memory.
+type fakeSFTPServer struct {
+	files   map[string][]byte
+	open    map[string]string // handle -> file name
+	denyDir string            // opening files under this prefix fails
+}
+
+func (s *fakeSFTPServer) serve(r io.Reader, w io.Writer) {
+	conn := &sftpClient{r: r, w: w}
+	for {
+		typ, payload, err := conn.recv()
+		if err != nil {
+			return
+		}
+		if typ == sftpInit {
+			conn.send(sftpVersion, uint32(3))
+			continue
+		}
+
+		id := binary.BigEndian.Uint32(payload)
+		rest := payload[4:]
+		status := func(code uint32, message string) {
+			conn.send(sftpStatus, id, code, message, "")
+		}
+		switch typ {
+		case sftpOpen:
+			name, _, _ := readString(rest)
+			if s.denyDir != "" && strings.HasPrefix(name, s.denyDir) {
+				status(3, "permission denied")
+				continue
+			}
+			handle := "h" + name
+			s.open[handle] = name
+			s.files[name] = nil
+			conn.send(sftpHandle, id, handle)
+		case sftpWrite:
+			handle, rest, _ := readString(rest)
+			offset := binary.BigEndian.Uint64(rest)
+			data, _, _ := readString(rest[8:])
+			name := s.open[handle]
+			if uint64(len(s.files[name])) != offset {
+				status(4, "unexpected offset")
+				continue
+			}
+			s.files[name] = append(s.files[name], data...)
+			status(sftpStatusOK, "")
+		case sftpClose:
+			handle, _, _ := readString(rest)
+			delete(s.open, handle)
+			status(sftpStatusOK, "")
+		case sftpRename:
+			from, rest, _ := readString(rest)
+			to, _, _ := readString(rest)
+			s.files[to] = s.files[from]
+			delete(s.files, from)
+			status(sftpStatusOK, "")
+		default:
+			status(8, "unsupported")
+		}
+	}
+}
+
+func TestSFTPClient_Upload(t *testing.T) {
+	server := &fakeSFTPServer{
+		files:   make(map[string][]byte),
+		open:    make(map[string]string),
+		denyDir: "/readonly/",
+	}
+
+	upload := func(temp, target string, data []byte) error {
+		clientR, serverW := io.Pipe()
+		serverR, clientW := io.Pipe()
+		go server.serve(serverR, serverW)
+		defer clientW.Close()
+		return newSFTPClient(clientR, clientW).upload(temp, target, data)
+	}
+
+	// Larger than one write request
+	data := bytes.Repeat([]byte("evidence\n"), 10000)
+	if err := upload("/exports/a.jsonl.part", "/exports/a.jsonl", data); err != nil {
+		t.Fatalf("upload() error = %v", err)
+	}
+	if !bytes.Equal(server.files["/exports/a.jsonl"], data) {
+		t.Errorf("uploaded %d bytes, want %d", len(server.files["/exports/a.jsonl"]), len(data))
+	}
+	if _, ok := server.files["/exports/a.jsonl.part"]; ok {
+		t.Error("temporary file was not renamed")
+	}
+
+	err := upload("/readonly/a.jsonl.part", "/readonly/a.jsonl", data)
+	if err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("upload() to read-only directory error = %v, want permission denied", err)
+	}
+}
+
+func TestNewDestination_SFTP(t *testing.T) {
+	const hostKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIOMqqnkVzrm0SdG6UOoqKLsabgH5C9okWi0dh2l9GKJl"
+
+	tests := []struct {
+		name    string
+		cfg     DestinationConfig
+		wantErr bool
+	}{
+		{"password", DestinationConfig{URL: "sftp://mercator@sftp.example.com/exports", Password: "secret", HostKey: hostKey}, false},
+		{"missing host key", DestinationConfig{URL: "sftp://mercator@sftp.example.com/exports", Password: "secret"}, true},
+		{"missing credentials", DestinationConfig{URL: "sftp://mercator@sftp.example.com/exports", HostKey: hostKey}, true},
+		{"missing user", DestinationConfig{URL: "sftp://sftp.example.com/exports", Password: "secret", HostKey: hostKey}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest, err := NewDestination(&tt.cfg)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NewDestination() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && dest.Name() != "sftp://mercator@sftp.example.com/exports" {
+				t.Errorf("Name() = %q", dest.Name())
+			}
+		})
+	}
+}
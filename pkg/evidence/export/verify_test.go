@@ -0,0 +1,74 @@
+package export
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/evidence/integrity"
+)
+
+func signedRecords(t *testing.T, n int) ([]*evidence.EvidenceRecord, ed25519.PublicKey) {
+	t.Helper()
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	signer := integrity.NewSigner(key)
+
+	records := make([]*evidence.EvidenceRecord, n)
+	for i := range records {
+		records[i] = &evidence.EvidenceRecord{ID: fmt.Sprintf("rec-%d", i), Model: "gpt-4"}
+		signer.Sign(records[i])
+	}
+	return records, signer.PublicKey()
+}
+
+func TestSignatureVerifier_Stream(t *testing.T) {
+	records, pub := signedRecords(t, 5)
+	records[3].Model = "gpt-3.5" // tampered after signing
+
+	in := make(chan *evidence.EvidenceRecord)
+	go func() {
+		defer close(in)
+		for _, r := range records {
+			in <- r
+		}
+	}()
+
+	verifier := NewSignatureVerifier(pub)
+	out, errCh := verifier.Stream(context.Background(), in)
+
+	var got []string
+	for r := range out {
+		got = append(got, r.ID)
+	}
+	if len(got) != 3 {
+		t.Errorf("passed %v, want the 3 records before the tampered one", got)
+	}
+	if err := <-errCh; err == nil {
+		t.Error("Stream() reported no error for a tampered record")
+	}
+	if verifier.Verified() != 3 {
+		t.Errorf("Verified() = %d, want 3", verifier.Verified())
+	}
+}
+
+func TestSignatureVerifier_Unsigned(t *testing.T) {
+	records, pub := signedRecords(t, 2)
+	records = append(records, &evidence.EvidenceRecord{ID: "legacy"})
+
+	verifier := NewSignatureVerifier(pub)
+	if err := verifier.VerifyAll(records); err == nil {
+		t.Error("VerifyAll() accepted an unsigned record")
+	}
+
+	verifier = NewSignatureVerifier(pub)
+	verifier.AllowUnsigned = true
+	if err := verifier.VerifyAll(records); err != nil {
+		t.Fatalf("VerifyAll() error = %v", err)
+	}
+	if verifier.Verified() != 2 || verifier.Unsigned() != 1 {
+		t.Errorf("Verified/Unsigned = %d/%d, want 2/1", verifier.Verified(), verifier.Unsigned())
+	}
+}
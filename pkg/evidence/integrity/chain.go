@@ -0,0 +1,285 @@
+package integrity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+// canonicalRecord is the form of an evidence record that is hashed. It
+// contains the fields that every storage backend persists, normalized so
+// that a record read back from storage hashes to the same value as the
+// record that was written: times are UTC, durations have the millisecond
+// resolution of the SQLite backend, and empty collections are omitted
+// regardless of whether they were nil or empty.
+type canonicalRecord struct {
+	ID        string `json:"id"`
+	RequestID string `json:"request_id"`
+
+	TraceID    string `json:"trace_id,omitempty"`
+	DecisionID string `json:"decision_id,omitempty"`
+
+	RequestTime      string `json:"request_time"`
+	PolicyEvalTime   string `json:"policy_eval_time"`
+	ProviderCallTime string `json:"provider_call_time"`
+	ResponseTime     string `json:"response_time"`
+	RecordedTime     string `json:"recorded_time"`
+
+	RequestHash    string            `json:"request_hash"`
+	RequestMethod  string            `json:"request_method"`
+	RequestPath    string            `json:"request_path"`
+	RequestHeaders map[string]string `json:"request_headers,omitempty"`
+
+	Model        string   `json:"model"`
+	Provider     string   `json:"provider"`
+	Messages     int      `json:"messages"`
+	SystemPrompt string   `json:"system_prompt"`
+	UserPrompt   string   `json:"user_prompt"`
+	ToolsUsed    []string `json:"tools_used,omitempty"`
+
+	EstimatedTokens int      `json:"estimated_tokens"`
+	EstimatedCost   float64  `json:"estimated_cost"`
+	RiskScore       int      `json:"risk_score"`
+	ComplexityScore int      `json:"complexity_score"`
+	PIIDetected     bool     `json:"pii_detected"`
+	PIITypes        []string `json:"pii_types,omitempty"`
+
+	PolicyDecision string                       `json:"policy_decision"`
+	MatchedRules   []evidence.MatchedRuleRecord `json:"matched_rules,omitempty"`
+	BlockReason    string                       `json:"block_reason"`
+	PolicyVersion  string                       `json:"policy_version"`
+
+	ResponseHash    string `json:"response_hash"`
+	ResponseStatus  int    `json:"response_status"`
+	ResponseContent string `json:"response_content"`
+	FinishReason    string `json:"finish_reason"`
+
+	ResponseAnalysis *evidence.ResponseAnalysisRecord `json:"response_analysis,omitempty"`
+
+	Findings []evidence.FindingRecord `json:"findings,omitempty"`
+
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	ActualCost       float64 `json:"actual_cost"`
+
+	ProviderLatencyMs int64  `json:"provider_latency_ms"`
+	ProviderModel     string `json:"provider_model"`
+
+	Streamed           bool  `json:"streamed,omitempty"`
+	StreamChunks       int   `json:"stream_chunks,omitempty"`
+	TimeToFirstTokenMs int64 `json:"time_to_first_token_ms,omitempty"`
+	StreamDurationMs   int64 `json:"stream_duration_ms,omitempty"`
+	ClientDisconnected bool  `json:"client_disconnected,omitempty"`
+
+	ToolCalls   []evidence.ToolCallRecord   `json:"tool_calls,omitempty"`
+	ToolResults []evidence.ToolResultRecord `json:"tool_results,omitempty"`
+
+	UserID    string `json:"user_id"`
+	TeamID    string `json:"team_id,omitempty"`
+	APIKey    string `json:"api_key"`
+	IPAddress string `json:"ip_address"`
+
+	CostTag     string `json:"cost_tag,omitempty"`
+	LimitBypass string `json:"limit_bypass,omitempty"`
+
+	DataClassification      string `json:"data_classification,omitempty"`
+	DataClassificationLevel int    `json:"data_classification_level,omitempty"`
+
+	Error     string `json:"error"`
+	ErrorType string `json:"error_type"`
+
+	TurnNumber   int     `json:"turn_number"`
+	ContextUsage float64 `json:"context_usage"`
+
+	RequestBodyRef  string `json:"request_body_ref,omitempty"`
+	ResponseBodyRef string `json:"response_body_ref,omitempty"`
+	BodyTruncated   bool   `json:"body_truncated,omitempty"`
+
+	ChainID  string `json:"chain_id"`
+	Sequence int64  `json:"sequence"`
+	PrevHash string `json:"prev_hash"`
+}
+
+// HashRecord computes the SHA-256 hash of the canonical form of an evidence
+// record and returns it hex-encoded. The hash covers the record's chain
+// position and PrevHash, but not RecordHash itself.
+func HashRecord(record *evidence.EvidenceRecord) string {
+	c := canonicalRecord{
+		ID:                 record.ID,
+		RequestID:          record.RequestID,
+		TraceID:            record.TraceID,
+		DecisionID:         record.DecisionID,
+		RequestTime:        canonicalTime(record.RequestTime),
+		PolicyEvalTime:     canonicalTime(record.PolicyEvalTime),
+		ProviderCallTime:   canonicalTime(record.ProviderCallTime),
+		ResponseTime:       canonicalTime(record.ResponseTime),
+		RecordedTime:       canonicalTime(record.RecordedTime),
+		RequestHash:        record.RequestHash,
+		RequestMethod:      record.RequestMethod,
+		RequestPath:        record.RequestPath,
+		Model:              record.Model,
+		Provider:           record.Provider,
+		Messages:           record.Messages,
+		SystemPrompt:       record.SystemPrompt,
+		UserPrompt:         record.UserPrompt,
+		EstimatedTokens:    record.EstimatedTokens,
+		EstimatedCost:      record.EstimatedCost,
+		RiskScore:          record.RiskScore,
+		ComplexityScore:    record.ComplexityScore,
+		PIIDetected:        record.PIIDetected,
+		PolicyDecision:     record.PolicyDecision,
+		BlockReason:        record.BlockReason,
+		PolicyVersion:      record.PolicyVersion,
+		ResponseHash:       record.ResponseHash,
+		ResponseStatus:     record.ResponseStatus,
+		ResponseContent:    record.ResponseContent,
+		FinishReason:       record.FinishReason,
+		ResponseAnalysis:   record.ResponseAnalysis,
+		Findings:           record.Findings,
+		PromptTokens:       record.PromptTokens,
+		CompletionTokens:   record.CompletionTokens,
+		TotalTokens:        record.TotalTokens,
+		ActualCost:         record.ActualCost,
+		ProviderLatencyMs:  record.ProviderLatency.Milliseconds(),
+		ProviderModel:      record.ProviderModel,
+		Streamed:           record.Streamed,
+		StreamChunks:       record.StreamChunks,
+		TimeToFirstTokenMs: record.TimeToFirstToken.Milliseconds(),
+		StreamDurationMs:   record.StreamDuration.Milliseconds(),
+		ClientDisconnected: record.ClientDisconnected,
+		ToolCalls:          record.ToolCalls,
+		ToolResults:        record.ToolResults,
+		UserID:             record.UserID,
+		TeamID:             record.TeamID,
+		APIKey:             record.APIKey,
+		IPAddress:          record.IPAddress,
+		CostTag:            record.CostTag,
+		LimitBypass:        record.LimitBypass,
+		Error:              record.Error,
+		ErrorType:          record.ErrorType,
+		TurnNumber:         record.TurnNumber,
+		ContextUsage:       record.ContextUsage,
+		RequestBodyRef:     record.RequestBodyRef,
+		ResponseBodyRef:    record.ResponseBodyRef,
+		BodyTruncated:      record.BodyTruncated,
+		ChainID:            record.ChainID,
+		Sequence:           record.Sequence,
+		PrevHash:           record.PrevHash,
+	}
+	if len(record.RequestHeaders) > 0 {
+		c.RequestHeaders = record.RequestHeaders
+	}
+	if len(record.ToolsUsed) > 0 {
+		c.ToolsUsed = record.ToolsUsed
+	}
+	if len(record.PIITypes) > 0 {
+		c.PIITypes = record.PIITypes
+	}
+	if len(record.MatchedRules) > 0 {
+		c.MatchedRules = record.MatchedRules
+	}
+	if record.DataClassification != "" {
+		c.DataClassification = record.DataClassification
+		c.DataClassificationLevel = record.DataClassificationLevel
+	}
+
+	// Marshaling a struct of strings, numbers, and string maps cannot fail,
+	// and map keys are encoded in sorted order.
+	data, _ := json.Marshal(c)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// canonicalTime formats t in UTC with nanosecond precision.
+func canonicalTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// Chain links evidence records into a hash chain. Each record carries the
+// chain ID, its sequence number (starting at 1), the RecordHash of the
+// previous record, and its own RecordHash, so that modifying a record
+// invalidates its hash and deleting one leaves a gap in the sequence and a
+// dangling PrevHash.
+//
+// A chain is written by a single recorder. Linking is split into Link,
+// before the record is stored, and Commit, after it was stored
+// successfully, so that a failed write does not leave a gap in the chain.
+type Chain struct {
+	mu       sync.Mutex
+	id       string
+	sequence int64
+	head     string
+}
+
+// NewChain starts a new hash chain. If id is empty, a random ID is
+// generated. Chain IDs must be unique: every recorder process must start
+// its own chain.
+func NewChain(id string) *Chain {
+	if id == "" {
+		id = uuid.New().String()
+	}
+	return &Chain{id: id}
+}
+
+// ID returns the chain ID.
+func (c *Chain) ID() string {
+	return c.id
+}
+
+// Head returns the sequence number and hash of the last committed record.
+// Both are zero values before the first Commit.
+func (c *Chain) Head() (int64, string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.sequence, c.head
+}
+
+// Link assigns the record the next position in the chain and sets its
+// PrevHash and RecordHash. The record must not be modified afterwards.
+func (c *Chain) Link(record *evidence.EvidenceRecord) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	record.ChainID = c.id
+	record.Sequence = c.sequence + 1
+	record.PrevHash = c.head
+	record.RecordHash = HashRecord(record)
+}
+
+// LinkAll links records stored together as consecutive positions in the
+// chain, each record's PrevHash being the RecordHash of the one before.
+// Commit each record, in order, once all are stored.
+func (c *Chain) LinkAll(records []*evidence.EvidenceRecord) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	sequence, head := c.sequence, c.head
+	for _, record := range records {
+		sequence++
+		record.ChainID = c.id
+		record.Sequence = sequence
+		record.PrevHash = head
+		record.RecordHash = HashRecord(record)
+		head = record.RecordHash
+	}
+}
+
+// Commit advances the chain past a linked record once it has been stored.
+// Records that are not the next record in this chain are ignored.
+func (c *Chain) Commit(record *evidence.EvidenceRecord) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if record.ChainID != c.id || record.Sequence != c.sequence+1 {
+		return
+	}
+	c.sequence = record.Sequence
+	c.head = record.RecordHash
+}
@@ -0,0 +1,134 @@
+package integrity
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/evidence/storage"
+)
+
+// newRecord returns a record with the fields that backends normalize on
+// their way through storage.
+func newRecord(i int) *evidence.EvidenceRecord {
+	now := time.Date(2025, 11, 1, 12, 0, i, 123456789, time.FixedZone("CET", 3600))
+	return &evidence.EvidenceRecord{
+		ID:              fmt.Sprintf("rec-%d", i),
+		RequestID:       fmt.Sprintf("req-%d", i),
+		RequestTime:     now,
+		PolicyEvalTime:  now,
+		ResponseTime:    now.Add(time.Second),
+		RecordedTime:    now.Add(time.Second),
+		RequestHeaders:  map[string]string{"user-agent": "test"},
+		Model:           "gpt-4",
+		Provider:        "openai",
+		ToolsUsed:       []string{},
+		PolicyDecision:  "allow",
+		ActualCost:      0.0123,
+		ProviderLatency: 1234567 * time.Microsecond,
+		UserID:          "alice",
+	}
+}
+
+func TestChain_LinkAndCommit(t *testing.T) {
+	chain := NewChain("chain-a")
+
+	first := newRecord(1)
+	chain.Link(first)
+	if first.ChainID != "chain-a" || first.Sequence != 1 || first.PrevHash != "" {
+		t.Fatalf("first record linked as %q/%d/%q", first.ChainID, first.Sequence, first.PrevHash)
+	}
+	if first.RecordHash != HashRecord(first) {
+		t.Error("RecordHash does not match HashRecord")
+	}
+
+	// A record that failed to store is not committed; its position is reused
+	failed := newRecord(2)
+	chain.Link(failed)
+	retry := newRecord(3)
+	chain.Link(retry)
+	if retry.Sequence != 1 {
+		t.Fatalf("uncommitted position not reused: sequence = %d", retry.Sequence)
+	}
+
+	chain.Commit(first)
+	second := newRecord(4)
+	chain.Link(second)
+	if second.Sequence != 2 || second.PrevHash != first.RecordHash {
+		t.Errorf("second record linked as %d/%q, want 2/%q", second.Sequence, second.PrevHash, first.RecordHash)
+	}
+}
+
+func TestChain_LinkAll(t *testing.T) {
+	chain := NewChain("chain-a")
+	first := newRecord(1)
+	chain.Link(first)
+	chain.Commit(first)
+
+	batch := []*evidence.EvidenceRecord{newRecord(2), newRecord(3), newRecord(4)}
+	chain.LinkAll(batch)
+	prev := first.RecordHash
+	for i, record := range batch {
+		if record.Sequence != int64(i+2) || record.PrevHash != prev || record.RecordHash != HashRecord(record) {
+			t.Errorf("record %d linked as %d/%q", i, record.Sequence, record.PrevHash)
+		}
+		prev = record.RecordHash
+	}
+	if sequence, _ := chain.Head(); sequence != 1 {
+		t.Errorf("LinkAll advanced the chain to %d", sequence)
+	}
+
+	for _, record := range batch {
+		chain.Commit(record)
+	}
+	if sequence, head := chain.Head(); sequence != 4 || head != prev {
+		t.Errorf("Head() = %d, %q after committing the batch", sequence, head)
+	}
+}
+
+func TestHashRecord_DetectsModification(t *testing.T) {
+	record := newRecord(1)
+	NewChain("").Link(record)
+
+	record.PolicyDecision = "block"
+	if HashRecord(record) == record.RecordHash {
+		t.Error("modified record has unchanged hash")
+	}
+}
+
+func TestHashRecord_StableThroughSQLite(t *testing.T) {
+	store, err := storage.NewSQLiteStorage(&storage.SQLiteConfig{
+		Path:         filepath.Join(t.TempDir(), "evidence.db"),
+		MaxOpenConns: 1,
+		BusyTimeout:  time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage() error = %v", err)
+	}
+	defer store.Close()
+
+	chain := NewChain("")
+	for i := 0; i < 3; i++ {
+		record := newRecord(i)
+		chain.Link(record)
+		if err := store.Store(context.Background(), record); err != nil {
+			t.Fatalf("Store() error = %v", err)
+		}
+		chain.Commit(record)
+	}
+
+	records, err := store.Query(context.Background(), &evidence.Query{})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	verifier := NewVerifier(nil)
+	for _, record := range records {
+		verifier.Add(record)
+	}
+	if report := verifier.Finish(nil); !report.OK() || report.Chains != 1 {
+		t.Errorf("records read back from SQLite do not verify: %+v", report)
+	}
+}
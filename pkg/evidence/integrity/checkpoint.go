@@ -0,0 +1,312 @@
+package integrity
+
+import (
+	"bufio"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+// Checkpoint is a signed statement of the head of a hash chain at a point
+// in time. Checkpoints are kept outside evidence storage, so that records
+// deleted from the end of a chain, or a whole chain deleted, are detected
+// by verification even though no later record refers to them.
+type Checkpoint struct {
+	ChainID    string    `json:"chain_id"`
+	Sequence   int64     `json:"sequence"`
+	RecordHash string    `json:"record_hash"`
+	Time       time.Time `json:"time"`
+	KeyID      string    `json:"key_id"`
+	Signature  string    `json:"signature"` // base64 Ed25519 signature
+}
+
+// signedPayload returns the bytes covered by the checkpoint signature.
+func (cp *Checkpoint) signedPayload() []byte {
+	return []byte(fmt.Sprintf("mercator-evidence-checkpoint/v1\n%s\n%d\n%s\n%s",
+		cp.ChainID, cp.Sequence, cp.RecordHash, canonicalTime(cp.Time)))
+}
+
+// SignCheckpoint signs a checkpoint with an Ed25519 private key, setting its
+// KeyID and Signature.
+func SignCheckpoint(cp *Checkpoint, key ed25519.PrivateKey) {
+	cp.KeyID = KeyID(key.Public().(ed25519.PublicKey))
+	cp.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, cp.signedPayload()))
+}
+
+// VerifyCheckpoint checks a checkpoint's signature against an Ed25519
+// public key.
+func VerifyCheckpoint(cp *Checkpoint, key ed25519.PublicKey) error {
+	if cp.KeyID != KeyID(key) {
+		return fmt.Errorf("checkpoint signed with key %s, not %s", cp.KeyID, KeyID(key))
+	}
+	sig, err := base64.StdEncoding.DecodeString(cp.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid checkpoint signature encoding: %w", err)
+	}
+	if !ed25519.Verify(key, cp.signedPayload(), sig) {
+		return errors.New("invalid checkpoint signature")
+	}
+	return nil
+}
+
+// CheckpointStore persists checkpoints.
+// Implementations must be thread-safe.
+type CheckpointStore interface {
+	// Append adds a checkpoint to the store.
+	Append(ctx context.Context, cp *Checkpoint) error
+
+	// List returns all checkpoints in the order they were appended.
+	List(ctx context.Context) ([]*Checkpoint, error)
+
+	// Close releases any resources held by the store.
+	Close() error
+}
+
+// FileCheckpointStore stores checkpoints in an append-only JSON Lines file.
+type FileCheckpointStore struct {
+	path string
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileCheckpointStore opens (or creates) a checkpoint file.
+func NewFileCheckpointStore(path string) (*FileCheckpointStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, evidence.NewStorageError("checkpoint", "open", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, evidence.NewStorageError("checkpoint", "open", err)
+	}
+	return &FileCheckpointStore{path: path, file: f}, nil
+}
+
+// Append writes a checkpoint and syncs it to disk.
+func (s *FileCheckpointStore) Append(ctx context.Context, cp *Checkpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return evidence.NewStorageError("checkpoint", "append", err)
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(data); err != nil {
+		return evidence.NewStorageError("checkpoint", "append", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return evidence.NewStorageError("checkpoint", "append", err)
+	}
+	return nil
+}
+
+// List reads all checkpoints from the file.
+func (s *FileCheckpointStore) List(ctx context.Context) ([]*Checkpoint, error) {
+	return ReadCheckpointFile(s.path)
+}
+
+// Close closes the checkpoint file.
+func (s *FileCheckpointStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// ReadCheckpointFile reads the checkpoints in a file written by
+// FileCheckpointStore. A missing file contains no checkpoints.
+func ReadCheckpointFile(path string) ([]*Checkpoint, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, evidence.NewStorageError("checkpoint", "list", err)
+	}
+	defer f.Close()
+
+	var checkpoints []*Checkpoint
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var cp Checkpoint
+		if err := json.Unmarshal(scanner.Bytes(), &cp); err != nil {
+			return nil, evidence.NewStorageError("checkpoint", "list",
+				fmt.Errorf("%s:%d: %w", path, line, err))
+		}
+		checkpoints = append(checkpoints, &cp)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, evidence.NewStorageError("checkpoint", "list", err)
+	}
+	return checkpoints, nil
+}
+
+// CheckpointerConfig contains configuration for a Checkpointer.
+type CheckpointerConfig struct {
+	// Interval is the number of records after which a checkpoint is written.
+	// Default: 1000
+	Interval int64
+
+	// Period is the maximum time between checkpoints while records are
+	// being written.
+	// Default: 1 minute
+	Period time.Duration
+}
+
+// DefaultCheckpointerConfig returns the default checkpointer configuration.
+func DefaultCheckpointerConfig() *CheckpointerConfig {
+	return &CheckpointerConfig{
+		Interval: 1000,
+		Period:   time.Minute,
+	}
+}
+
+// Checkpointer writes signed checkpoints of a hash chain. It implements
+// recorder.RecordObserver: it follows the head of the chain from the
+// records the recorder stores, and writes a checkpoint every Interval
+// records, every Period, and when it is closed.
+type Checkpointer struct {
+	store  CheckpointStore
+	key    ed25519.PrivateKey
+	config *CheckpointerConfig
+	logger *slog.Logger
+
+	mu           sync.Mutex
+	chainID      string
+	sequence     int64
+	head         string
+	checkpointed int64 // sequence of the last checkpoint written
+
+	trigger chan struct{}
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewCheckpointer creates a checkpointer that signs checkpoints with key
+// and appends them to store.
+func NewCheckpointer(store CheckpointStore, key ed25519.PrivateKey, config *CheckpointerConfig) *Checkpointer {
+	if config == nil {
+		config = DefaultCheckpointerConfig()
+	}
+
+	c := &Checkpointer{
+		store:   store,
+		key:     key,
+		config:  config,
+		logger:  slog.Default().With("component", "evidence.integrity"),
+		trigger: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+
+	c.wg.Add(1)
+	go c.run()
+
+	return c
+}
+
+// ObserveRecord records the new head of the record's chain.
+func (c *Checkpointer) ObserveRecord(record *evidence.EvidenceRecord) {
+	if record.ChainID == "" {
+		return
+	}
+
+	c.mu.Lock()
+	if record.ChainID != c.chainID {
+		c.chainID = record.ChainID
+		c.checkpointed = 0
+	}
+	c.sequence = record.Sequence
+	c.head = record.RecordHash
+	due := c.sequence-c.checkpointed >= c.config.Interval
+	c.mu.Unlock()
+
+	if due {
+		select {
+		case c.trigger <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Close writes a final checkpoint and stops the checkpointer. It must be
+// called after the recorder has been closed. The checkpoint store is not
+// closed.
+func (c *Checkpointer) Close() error {
+	close(c.done)
+	c.wg.Wait()
+	return nil
+}
+
+// run writes checkpoints when triggered and periodically.
+func (c *Checkpointer) run() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.config.Period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.trigger:
+			c.checkpoint()
+		case <-ticker.C:
+			c.checkpoint()
+		case <-c.done:
+			c.checkpoint()
+			return
+		}
+	}
+}
+
+// checkpoint writes a checkpoint of the current head, unless it has
+// already been checkpointed.
+func (c *Checkpointer) checkpoint() {
+	c.mu.Lock()
+	if c.sequence <= c.checkpointed {
+		c.mu.Unlock()
+		return
+	}
+	cp := &Checkpoint{
+		ChainID:    c.chainID,
+		Sequence:   c.sequence,
+		RecordHash: c.head,
+		Time:       time.Now().UTC(),
+	}
+	c.mu.Unlock()
+
+	SignCheckpoint(cp, c.key)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.store.Append(ctx, cp); err != nil {
+		c.logger.Error("failed to write evidence checkpoint",
+			"chain_id", cp.ChainID,
+			"sequence", cp.Sequence,
+			"error", err,
+		)
+		return
+	}
+
+	c.mu.Lock()
+	if c.chainID == cp.ChainID && cp.Sequence > c.checkpointed {
+		c.checkpointed = cp.Sequence
+	}
+	c.mu.Unlock()
+
+	c.logger.Debug("evidence checkpoint written",
+		"chain_id", cp.ChainID,
+		"sequence", cp.Sequence,
+	)
+}
@@ -0,0 +1,54 @@
+// Package integrity makes evidence records tamper-evident.
+//
+// # Hash Chain
+//
+// The evidence recorder links every record it stores into a hash chain
+// (see Chain). Each record carries its chain ID, its sequence number, the
+// hash of the previous record in the chain, and its own hash, computed
+// over a canonical form of the record (HashRecord). Every recorder process
+// starts a new chain.
+//
+// Modifying a stored record invalidates its hash; recomputing the hash
+// breaks the link from the next record. Deleting records leaves a gap in
+// the sequence.
+//
+// # Checkpoints
+//
+// Deleting records from the end of a chain, or a whole chain, leaves no
+// trace in the remaining records. The Checkpointer therefore periodically
+// writes the head of the chain, signed with an Ed25519 key, to a
+// CheckpointStore kept outside evidence storage. Verification checks that
+// every checkpointed record is still present with the checkpointed hash.
+//
+// # Verification
+//
+// The Verifier accepts records in any order and reports records that were
+// modified, missing, or re-linked, and checkpoints that are not signed by
+// the expected key:
+//
+//	verifier := integrity.NewVerifier(&integrity.VerifyOptions{PublicKey: publicKey})
+//	for record := range recordsCh {
+//	    verifier.Add(record)
+//	}
+//	checkpoints, err := checkpointStore.List(ctx)
+//	if err != nil {
+//	    return err
+//	}
+//	report := verifier.Finish(checkpoints)
+//	if !report.OK() {
+//	    // report.Issues describes each violation
+//	}
+//
+// # Erasure
+//
+// Erasing the records of a data subject (see package erasure) leaves gaps
+// in chains. The eraser writes a signed ErasureCertificate listing the
+// chain position and hash of every erased record to an ErasureStore;
+// passing the certificates to Verifier.AddErasure fills the gaps, so only
+// deletions without a certificate are reported as missing.
+//
+// Records missing from the start of a chain are counted as pruned rather
+// than reported, since retention pruning deletes the oldest records. Set
+// VerifyOptions.Since to the retention cut-off so that checkpoints of
+// pruned records are not reported either.
+package integrity
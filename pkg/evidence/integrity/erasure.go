@@ -0,0 +1,217 @@
+package integrity
+
+import (
+	"bufio"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+// ErasureCertificate is a signed statement that the evidence records of a
+// data subject were erased. It lists the chain positions and hashes of the
+// erased records, so that verification can tell erasure apart from
+// tampering, without keeping any of their content.
+type ErasureCertificate struct {
+	ID string `json:"id"`
+
+	// Subject is the SubjectHash of the erased user ID. The user ID
+	// itself is not kept, but erasure for a known user can be proven.
+	Subject string `json:"subject"`
+
+	Reason      string    `json:"reason,omitempty"`
+	RequestedBy string    `json:"requested_by,omitempty"`
+	Time        time.Time `json:"time"`
+
+	// Records lists the erased records.
+	Records []ErasedRecord `json:"records"`
+
+	// Held is the number of records kept because they are under legal hold.
+	Held int64 `json:"held"`
+
+	// Blobs is the number of captured request and response bodies deleted.
+	Blobs int `json:"blobs"`
+
+	KeyID     string `json:"key_id"`
+	Signature string `json:"signature"` // base64 Ed25519 signature
+}
+
+// ErasedRecord identifies an erased record and its position in its hash
+// chain. The chain fields are empty for unchained records.
+type ErasedRecord struct {
+	ID         string `json:"id"`
+	ChainID    string `json:"chain_id,omitempty"`
+	Sequence   int64  `json:"sequence,omitempty"`
+	PrevHash   string `json:"prev_hash,omitempty"`
+	RecordHash string `json:"record_hash,omitempty"`
+}
+
+// NewErasedRecord returns the erasure entry of a record.
+func NewErasedRecord(record *evidence.EvidenceRecord) ErasedRecord {
+	return ErasedRecord{
+		ID:         record.ID,
+		ChainID:    record.ChainID,
+		Sequence:   record.Sequence,
+		PrevHash:   record.PrevHash,
+		RecordHash: record.RecordHash,
+	}
+}
+
+// SubjectHash returns the hex-encoded SHA-256 hash of a user ID, as stored
+// in ErasureCertificate.Subject.
+func SubjectHash(userID string) string {
+	sum := sha256.Sum256([]byte(userID))
+	return hex.EncodeToString(sum[:])
+}
+
+// signedPayload returns the bytes covered by the certificate signature:
+// the JSON encoding of the certificate without its signature.
+func (c *ErasureCertificate) signedPayload() ([]byte, error) {
+	unsigned := *c
+	unsigned.Signature = ""
+	unsigned.Time = unsigned.Time.UTC()
+	data, err := json.Marshal(&unsigned)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte("mercator-evidence-erasure/v1\n"), data...), nil
+}
+
+// SignErasureCertificate signs a certificate with an Ed25519 private key,
+// setting its KeyID and Signature.
+func SignErasureCertificate(cert *ErasureCertificate, key ed25519.PrivateKey) error {
+	cert.KeyID = KeyID(key.Public().(ed25519.PublicKey))
+	payload, err := cert.signedPayload()
+	if err != nil {
+		return fmt.Errorf("failed to encode erasure certificate: %w", err)
+	}
+	cert.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(key, payload))
+	return nil
+}
+
+// VerifyErasureCertificate checks a certificate's signature against an
+// Ed25519 public key.
+func VerifyErasureCertificate(cert *ErasureCertificate, key ed25519.PublicKey) error {
+	if cert.KeyID != KeyID(key) {
+		return fmt.Errorf("erasure certificate signed with key %s, not %s", cert.KeyID, KeyID(key))
+	}
+	sig, err := base64.StdEncoding.DecodeString(cert.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid erasure certificate signature encoding: %w", err)
+	}
+	payload, err := cert.signedPayload()
+	if err != nil {
+		return fmt.Errorf("failed to encode erasure certificate: %w", err)
+	}
+	if !ed25519.Verify(key, payload, sig) {
+		return errors.New("invalid erasure certificate signature")
+	}
+	return nil
+}
+
+// ErasureStore persists erasure certificates.
+// Implementations must be thread-safe.
+type ErasureStore interface {
+	// Append adds a certificate to the store.
+	Append(ctx context.Context, cert *ErasureCertificate) error
+
+	// List returns all certificates in the order they were appended.
+	List(ctx context.Context) ([]*ErasureCertificate, error)
+
+	// Close releases any resources held by the store.
+	Close() error
+}
+
+// FileErasureStore stores erasure certificates in an append-only JSON Lines
+// file.
+type FileErasureStore struct {
+	path string
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileErasureStore opens (or creates) an erasure certificate file.
+func NewFileErasureStore(path string) (*FileErasureStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, evidence.NewStorageError("erasure", "open", err)
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, evidence.NewStorageError("erasure", "open", err)
+	}
+	return &FileErasureStore{path: path, file: f}, nil
+}
+
+// Append writes a certificate and syncs it to disk.
+func (s *FileErasureStore) Append(ctx context.Context, cert *ErasureCertificate) error {
+	data, err := json.Marshal(cert)
+	if err != nil {
+		return evidence.NewStorageError("erasure", "append", err)
+	}
+	data = append(data, '\n')
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := s.file.Write(data); err != nil {
+		return evidence.NewStorageError("erasure", "append", err)
+	}
+	if err := s.file.Sync(); err != nil {
+		return evidence.NewStorageError("erasure", "append", err)
+	}
+	return nil
+}
+
+// List reads all certificates from the file.
+func (s *FileErasureStore) List(ctx context.Context) ([]*ErasureCertificate, error) {
+	return ReadErasureFile(s.path)
+}
+
+// Close closes the certificate file.
+func (s *FileErasureStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// ReadErasureFile reads the certificates in a file written by
+// FileErasureStore. A missing file contains no certificates.
+func ReadErasureFile(path string) ([]*ErasureCertificate, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, evidence.NewStorageError("erasure", "list", err)
+	}
+	defer f.Close()
+
+	var certs []*ErasureCertificate
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 64<<20) // certificates list every erased record
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var cert ErasureCertificate
+		if err := json.Unmarshal(scanner.Bytes(), &cert); err != nil {
+			return nil, evidence.NewStorageError("erasure", "list",
+				fmt.Errorf("%s:%d: %w", path, line, err))
+		}
+		certs = append(certs, &cert)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, evidence.NewStorageError("erasure", "list", err)
+	}
+	return certs, nil
+}
@@ -0,0 +1,120 @@
+package integrity
+
+import (
+	"context"
+	"crypto/ed25519"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+func erasureCertificate(t *testing.T, key ed25519.PrivateKey, records ...ErasedRecord) *ErasureCertificate {
+	t.Helper()
+	cert := &ErasureCertificate{
+		ID:      "cert-1",
+		Subject: SubjectHash("alice"),
+		Reason:  "DSR-1",
+		Time:    time.Now(),
+		Records: records,
+	}
+	if err := SignErasureCertificate(cert, key); err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+func TestErasureCertificate_Signature(t *testing.T) {
+	pub, key, _ := ed25519.GenerateKey(nil)
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+	cert := erasureCertificate(t, key, NewErasedRecord(newRecord(0)))
+
+	if err := VerifyErasureCertificate(cert, pub); err != nil {
+		t.Fatalf("valid certificate rejected: %v", err)
+	}
+	if err := VerifyErasureCertificate(cert, otherPub); err == nil {
+		t.Error("certificate accepted with another key")
+	}
+
+	tampered := *cert
+	tampered.Records = nil
+	if err := VerifyErasureCertificate(&tampered, pub); err == nil {
+		t.Error("certificate with removed records accepted")
+	}
+}
+
+func TestFileErasureStore(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(nil)
+	path := filepath.Join(t.TempDir(), "erasures", "certs.jsonl")
+
+	certs, err := ReadErasureFile(path)
+	if err != nil || len(certs) != 0 {
+		t.Fatalf("missing file: got %d certificates, err %v", len(certs), err)
+	}
+
+	store, err := NewFileErasureStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	cert := erasureCertificate(t, key, NewErasedRecord(newRecord(0)), NewErasedRecord(newRecord(1)))
+	for i := 0; i < 2; i++ {
+		if err := store.Append(context.Background(), cert); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	certs, err = store.List(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(certs) != 2 || len(certs[1].Records) != 2 {
+		t.Fatalf("got %d certificates, want 2 with 2 records", len(certs))
+	}
+	if err := VerifyErasureCertificate(certs[1], key.Public().(ed25519.PublicKey)); err != nil {
+		t.Errorf("stored certificate does not verify: %v", err)
+	}
+}
+
+func TestVerifier_Erasure(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(nil)
+	_, otherKey, _ := ed25519.GenerateKey(nil)
+	records, cp := chainedRecords(t, 5, key)
+
+	// Erase records in the middle of the chain
+	erased := []ErasedRecord{NewErasedRecord(records[1]), NewErasedRecord(records[2])}
+	remaining := []*evidence.EvidenceRecord{records[0], records[3], records[4]}
+
+	if report := verify(remaining, []*Checkpoint{cp}, key); report.OK() {
+		t.Fatal("gap without erasure certificate not reported")
+	}
+
+	tests := []struct {
+		name       string
+		signer     ed25519.PrivateKey
+		wantOK     bool
+		wantErased int64
+	}{
+		{name: "signed", signer: key, wantOK: true, wantErased: 2},
+		{name: "signed by another key", signer: otherKey, wantOK: false, wantErased: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := NewVerifier(&VerifyOptions{PublicKey: key.Public().(ed25519.PublicKey)})
+			for _, r := range remaining {
+				v.Add(r)
+			}
+			v.AddErasure(erasureCertificate(t, tt.signer, erased...))
+			report := v.Finish([]*Checkpoint{cp})
+
+			if report.OK() != tt.wantOK {
+				t.Errorf("OK = %v, want %v: %+v", report.OK(), tt.wantOK, report.Issues)
+			}
+			if report.Erased != tt.wantErased {
+				t.Errorf("Erased = %d, want %d", report.Erased, tt.wantErased)
+			}
+		})
+	}
+}
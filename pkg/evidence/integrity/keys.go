@@ -0,0 +1,117 @@
+package integrity
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// KeyID returns a short fingerprint identifying a public key: the first 8
+// bytes of its SHA-256 hash, hex-encoded.
+func KeyID(key ed25519.PublicKey) string {
+	sum := sha256.Sum256(key)
+	return hex.EncodeToString(sum[:8])
+}
+
+// LoadPrivateKey reads an Ed25519 private key file in any format accepted
+// by ParsePrivateKey.
+func LoadPrivateKey(path string) (ed25519.PrivateKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+	key, err := ParsePrivateKey(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return key, nil
+}
+
+// LoadPublicKey reads a PEM-encoded Ed25519 public key, either as written by
+// "mercator keys generate" or in PKIX form. A private key is also accepted,
+// in which case its public key is returned.
+func LoadPublicKey(path string) (ed25519.PublicKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM data found in %s", path)
+	}
+
+	if block.Type == "PRIVATE KEY" {
+		key, err := parsePrivateKeyBlock(block)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return key.Public().(ed25519.PublicKey), nil
+	}
+	if len(block.Bytes) == ed25519.PublicKeySize {
+		return ed25519.PublicKey(block.Bytes), nil
+	}
+
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse public key %s: %w", path, err)
+	}
+	edKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("public key %s is %T, not Ed25519", path, key)
+	}
+	return edKey, nil
+}
+
+// ParsePrivateKey parses an Ed25519 private key: PEM-encoded as written by
+// "mercator keys generate" or in PKCS #8 form, or the raw 64-byte key or
+// 32-byte seed encoded as base64 or hex. Keys stored in a secrets manager
+// are parsed with it.
+func ParsePrivateKey(value string) (ed25519.PrivateKey, error) {
+	value = strings.TrimSpace(value)
+
+	if block, _ := pem.Decode([]byte(value)); block != nil {
+		return parsePrivateKeyBlock(block)
+	}
+
+	// Hex is tried first: hex keys and seeds are also valid base64.
+	raw, err := hex.DecodeString(value)
+	if err != nil {
+		raw, err = base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			return nil, errors.New("private key is not PEM, base64, or hex encoded")
+		}
+	}
+	switch len(raw) {
+	case ed25519.PrivateKeySize:
+		return ed25519.PrivateKey(raw), nil
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(raw), nil
+	default:
+		return nil, fmt.Errorf("private key is %d bytes, not an Ed25519 key", len(raw))
+	}
+}
+
+// parsePrivateKeyBlock parses a PEM block holding a raw or PKCS #8 Ed25519
+// private key.
+func parsePrivateKeyBlock(block *pem.Block) (ed25519.PrivateKey, error) {
+	if len(block.Bytes) == ed25519.PrivateKeySize {
+		return ed25519.PrivateKey(block.Bytes), nil
+	}
+
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	edKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("private key is %T, not Ed25519", key)
+	}
+	return edKey, nil
+}
@@ -0,0 +1,73 @@
+package integrity
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+// ErrUnsigned is returned by VerifyRecord for records without a signature.
+var ErrUnsigned = errors.New("record is not signed")
+
+// Signer signs evidence records with an Ed25519 private key.
+//
+// The signature covers the same canonical form of the record as HashRecord,
+// including the record's chain position when it is hash-chained, so a
+// signed record cannot be modified or moved to another position without
+// invalidating its signature.
+type Signer struct {
+	key   ed25519.PrivateKey
+	keyID string
+}
+
+// NewSigner creates a signer for key.
+func NewSigner(key ed25519.PrivateKey) *Signer {
+	return &Signer{
+		key:   key,
+		keyID: KeyID(key.Public().(ed25519.PublicKey)),
+	}
+}
+
+// KeyID returns the ID of the signer's key.
+func (s *Signer) KeyID() string {
+	return s.keyID
+}
+
+// PublicKey returns the public key that verifies the signer's signatures.
+func (s *Signer) PublicKey() ed25519.PublicKey {
+	return s.key.Public().(ed25519.PublicKey)
+}
+
+// Sign sets the record's SigningKeyID and Signature. The record must not be
+// modified afterwards.
+func (s *Signer) Sign(record *evidence.EvidenceRecord) {
+	record.SigningKeyID = s.keyID
+	record.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(s.key, recordSignedPayload(record)))
+}
+
+// VerifyRecord checks a record's signature against an Ed25519 public key.
+// It returns ErrUnsigned if the record has no signature.
+func VerifyRecord(record *evidence.EvidenceRecord, key ed25519.PublicKey) error {
+	if record.Signature == "" {
+		return ErrUnsigned
+	}
+	if record.SigningKeyID != KeyID(key) {
+		return fmt.Errorf("record signed with key %s, not %s", record.SigningKeyID, KeyID(key))
+	}
+	sig, err := base64.StdEncoding.DecodeString(record.Signature)
+	if err != nil {
+		return fmt.Errorf("invalid record signature encoding: %w", err)
+	}
+	if !ed25519.Verify(key, recordSignedPayload(record), sig) {
+		return errors.New("invalid record signature")
+	}
+	return nil
+}
+
+// recordSignedPayload returns the bytes covered by a record signature.
+func recordSignedPayload(record *evidence.EvidenceRecord) []byte {
+	return []byte("mercator-evidence-record/v1\n" + HashRecord(record))
+}
@@ -0,0 +1,94 @@
+package integrity
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"testing"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+func TestSigner_SignAndVerify(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	signer := NewSigner(key)
+
+	record := newRecord(1)
+	NewChain("chain-a").Link(record)
+	signer.Sign(record)
+
+	if record.SigningKeyID != signer.KeyID() || record.Signature == "" {
+		t.Fatalf("Sign() did not set signature fields: %q/%q", record.SigningKeyID, record.Signature)
+	}
+	if err := VerifyRecord(record, signer.PublicKey()); err != nil {
+		t.Fatalf("VerifyRecord() error = %v", err)
+	}
+
+	// The record hash is not covered twice: signing must not change it.
+	if got := HashRecord(record); got != record.RecordHash {
+		t.Errorf("HashRecord() changed after signing")
+	}
+
+	otherPub, _, _ := ed25519.GenerateKey(rand.Reader)
+	tests := []struct {
+		name   string
+		modify func(r *evidence.EvidenceRecord)
+		key    ed25519.PublicKey
+	}{
+		{"modified content", func(r *evidence.EvidenceRecord) { r.ActualCost = 0 }, nil},
+		{"moved in chain", func(r *evidence.EvidenceRecord) { r.Sequence = 7 }, nil},
+		{"forged signature", func(r *evidence.EvidenceRecord) { r.Signature = base64.StdEncoding.EncodeToString(make([]byte, 64)) }, nil},
+		{"wrong key", func(r *evidence.EvidenceRecord) {}, otherPub},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := *record
+			tt.modify(&r)
+			pub := tt.key
+			if pub == nil {
+				pub = signer.PublicKey()
+			}
+			if err := VerifyRecord(&r, pub); err == nil {
+				t.Error("VerifyRecord() succeeded, want error")
+			}
+		})
+	}
+
+	if err := VerifyRecord(newRecord(2), signer.PublicKey()); !errors.Is(err, ErrUnsigned) {
+		t.Errorf("VerifyRecord(unsigned) error = %v, want ErrUnsigned", err)
+	}
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	pkcs8, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"raw PEM":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key})),
+		"PKCS8 PEM":  string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: pkcs8})),
+		"base64 key": base64.StdEncoding.EncodeToString(key),
+		"hex seed":   hex.EncodeToString(key.Seed()) + "\n",
+	}
+	for name, value := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := ParsePrivateKey(value)
+			if err != nil {
+				t.Fatalf("ParsePrivateKey() error = %v", err)
+			}
+			if !got.Equal(key) {
+				t.Error("ParsePrivateKey() returned a different key")
+			}
+		})
+	}
+
+	if _, err := ParsePrivateKey("not a key"); err == nil {
+		t.Error("ParsePrivateKey(invalid) succeeded, want error")
+	}
+}
@@ -0,0 +1,317 @@
+package integrity
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"sort"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+// IssueKind classifies an integrity violation found by verification.
+type IssueKind string
+
+const (
+	// IssueModified means a record's content does not match its RecordHash.
+	IssueModified IssueKind = "modified"
+
+	// IssueBrokenLink means a record's PrevHash does not match the
+	// RecordHash of the previous record in its chain.
+	IssueBrokenLink IssueKind = "broken_link"
+
+	// IssueMissing means records are missing from a chain.
+	IssueMissing IssueKind = "missing"
+
+	// IssueDuplicate means two records claim the same chain position.
+	IssueDuplicate IssueKind = "duplicate"
+
+	// IssueBadSignature means a checkpoint's signature is invalid.
+	IssueBadSignature IssueKind = "bad_signature"
+
+	// IssueCheckpointMismatch means the record at a checkpointed position
+	// does not have the checkpointed hash.
+	IssueCheckpointMismatch IssueKind = "checkpoint_mismatch"
+)
+
+// Issue describes a single integrity violation.
+type Issue struct {
+	Kind     IssueKind `json:"kind"`
+	ChainID  string    `json:"chain_id"`
+	Sequence int64     `json:"sequence"`
+	RecordID string    `json:"record_id,omitempty"`
+	Message  string    `json:"message"`
+}
+
+// Report is the result of verifying evidence records.
+type Report struct {
+	// Records is the number of records verified.
+	Records int64 `json:"records"`
+
+	// Unchained is the number of records without a chain, such as records
+	// written before hash chaining was enabled. They cannot be verified.
+	Unchained int64 `json:"unchained"`
+
+	// Chains is the number of hash chains found.
+	Chains int `json:"chains"`
+
+	// Checkpoints is the number of checkpoints checked.
+	Checkpoints int `json:"checkpoints"`
+
+	// SignaturesVerified reports whether checkpoint signatures were checked.
+	SignaturesVerified bool `json:"signatures_verified"`
+
+	// Pruned is the number of records missing from the start of chains,
+	// as left by retention pruning. Deletions before VerifyOptions.Since
+	// are indistinguishable from pruning.
+	Pruned int64 `json:"pruned"`
+
+	// Erased is the number of chain positions accounted for by erasure
+	// certificates rather than records.
+	Erased int64 `json:"erased"`
+
+	// Anonymized is the number of anonymized records. Their content is
+	// verified against their AnonymizedHash, and their chain links
+	// against their original RecordHash.
+	Anonymized int64 `json:"anonymized"`
+
+	// Issues lists the integrity violations found.
+	Issues []Issue `json:"issues"`
+}
+
+// OK reports whether verification found no integrity violations.
+func (r *Report) OK() bool {
+	return len(r.Issues) == 0
+}
+
+// VerifyOptions configures verification.
+type VerifyOptions struct {
+	// PublicKey verifies checkpoint signatures. If nil, signatures are
+	// not checked.
+	PublicKey ed25519.PublicKey
+
+	// Since ignores checkpoints older than this time. Set it to the
+	// retention cut-off so that records deleted by retention pruning are
+	// not reported as missing.
+	Since time.Time
+
+	// Partial verifies only the hashes of the added records, for checking
+	// a subset of records such as a time range. Chain continuity and
+	// checkpoints are not checked, since the subset has gaps.
+	Partial bool
+}
+
+// link is the chain information of a verified record.
+type link struct {
+	recordID   string
+	prevHash   string
+	recordHash string
+}
+
+// Verifier checks evidence records and checkpoints for tampering.
+//
+// Records are added one at a time in any order with Add, so that large
+// stores can be verified while streaming; only the chain links of each
+// record are kept in memory. Finish then checks each chain for gaps and
+// broken links, and checks the checkpoints against the chains.
+type Verifier struct {
+	opts   VerifyOptions
+	chains map[string]map[int64]link
+	erased map[string]map[int64]link
+	report Report
+}
+
+// NewVerifier creates a verifier.
+func NewVerifier(opts *VerifyOptions) *Verifier {
+	v := &Verifier{
+		chains: make(map[string]map[int64]link),
+		erased: make(map[string]map[int64]link),
+	}
+	if opts != nil {
+		v.opts = *opts
+	}
+	return v
+}
+
+// Add verifies a record's hash and records its chain position.
+func (v *Verifier) Add(record *evidence.EvidenceRecord) {
+	v.report.Records++
+	if record.ChainID == "" {
+		v.report.Unchained++
+		return
+	}
+
+	if record.AnonymizedAt != nil {
+		v.report.Anonymized++
+		if hash := HashRecord(record); hash != record.AnonymizedHash {
+			v.issue(IssueModified, record.ChainID, record.Sequence, record.ID,
+				"anonymized record content does not match its anonymization hash")
+		}
+	} else if hash := HashRecord(record); hash != record.RecordHash {
+		v.issue(IssueModified, record.ChainID, record.Sequence, record.ID,
+			"record content does not match its hash")
+	}
+
+	chain := v.chains[record.ChainID]
+	if chain == nil {
+		chain = make(map[int64]link)
+		v.chains[record.ChainID] = chain
+	}
+	if existing, ok := chain[record.Sequence]; ok {
+		v.issue(IssueDuplicate, record.ChainID, record.Sequence, record.ID,
+			fmt.Sprintf("position already taken by record %s", existing.recordID))
+		return
+	}
+	chain[record.Sequence] = link{
+		recordID:   record.ID,
+		prevHash:   record.PrevHash,
+		recordHash: record.RecordHash,
+	}
+}
+
+// AddErasure accounts for the records erased by an erasure certificate, so
+// that their chain positions are not reported as missing. Certificates not
+// signed by VerifyOptions.PublicKey are reported and ignored.
+func (v *Verifier) AddErasure(cert *ErasureCertificate) {
+	if v.opts.PublicKey != nil {
+		if err := VerifyErasureCertificate(cert, v.opts.PublicKey); err != nil {
+			v.issue(IssueBadSignature, "", 0, "",
+				fmt.Sprintf("erasure certificate %s: %v", cert.ID, err))
+			return
+		}
+	}
+
+	for _, record := range cert.Records {
+		if record.ChainID == "" {
+			continue
+		}
+		chain := v.erased[record.ChainID]
+		if chain == nil {
+			chain = make(map[int64]link)
+			v.erased[record.ChainID] = chain
+		}
+		chain[record.Sequence] = link{
+			recordID:   record.ID,
+			prevHash:   record.PrevHash,
+			recordHash: record.RecordHash,
+		}
+	}
+}
+
+// Finish checks the chains of all added records against each other and
+// against checkpoints, and returns the report.
+func (v *Verifier) Finish(checkpoints []*Checkpoint) *Report {
+	// Erased positions fill the gaps in the remaining records; records
+	// still present take precedence
+	for chainID, erased := range v.erased {
+		chain := v.chains[chainID]
+		if chain == nil {
+			chain = make(map[int64]link)
+			v.chains[chainID] = chain
+		}
+		for seq, l := range erased {
+			if _, ok := chain[seq]; !ok {
+				chain[seq] = l
+				v.report.Erased++
+			}
+		}
+	}
+
+	v.report.Chains = len(v.chains)
+	if !v.opts.Partial {
+		for chainID, chain := range v.chains {
+			v.verifyChain(chainID, chain)
+		}
+
+		v.report.SignaturesVerified = v.opts.PublicKey != nil
+		for _, cp := range checkpoints {
+			v.verifyCheckpoint(cp)
+		}
+	}
+
+	if v.report.Issues == nil {
+		v.report.Issues = []Issue{}
+	}
+	sort.SliceStable(v.report.Issues, func(i, j int) bool {
+		a, b := v.report.Issues[i], v.report.Issues[j]
+		if a.ChainID != b.ChainID {
+			return a.ChainID < b.ChainID
+		}
+		return a.Sequence < b.Sequence
+	})
+	return &v.report
+}
+
+// verifyChain checks a chain for gaps and broken links.
+func (v *Verifier) verifyChain(chainID string, chain map[int64]link) {
+	sequences := make([]int64, 0, len(chain))
+	for seq := range chain {
+		sequences = append(sequences, seq)
+	}
+	sort.Slice(sequences, func(i, j int) bool { return sequences[i] < sequences[j] })
+
+	// A gap at the start of a chain is what retention pruning leaves
+	// behind; the first remaining record's link cannot be checked.
+	first := sequences[0]
+	if first > 1 {
+		v.report.Pruned += first - 1
+	} else if l := chain[first]; l.prevHash != "" {
+		v.issue(IssueBrokenLink, chainID, first, l.recordID,
+			"first record of chain refers to a previous record")
+	}
+
+	for i := 1; i < len(sequences); i++ {
+		prev, seq := sequences[i-1], sequences[i]
+		l := chain[seq]
+		if seq != prev+1 {
+			message := fmt.Sprintf("records %d to %d are missing", prev+1, seq-1)
+			if seq == prev+2 {
+				message = fmt.Sprintf("record %d is missing", prev+1)
+			}
+			v.issue(IssueMissing, chainID, prev+1, "", message)
+			continue
+		}
+		if l.prevHash != chain[prev].recordHash {
+			v.issue(IssueBrokenLink, chainID, seq, l.recordID,
+				fmt.Sprintf("previous hash does not match record %d", prev))
+		}
+	}
+}
+
+// verifyCheckpoint checks a checkpoint's signature and that the
+// checkpointed record is present with the checkpointed hash.
+func (v *Verifier) verifyCheckpoint(cp *Checkpoint) {
+	v.report.Checkpoints++
+
+	if v.opts.PublicKey != nil {
+		if err := VerifyCheckpoint(cp, v.opts.PublicKey); err != nil {
+			v.issue(IssueBadSignature, cp.ChainID, cp.Sequence, "", err.Error())
+			return
+		}
+	}
+	if cp.Time.Before(v.opts.Since) {
+		return
+	}
+
+	l, ok := v.chains[cp.ChainID][cp.Sequence]
+	if !ok {
+		v.issue(IssueMissing, cp.ChainID, cp.Sequence, "",
+			fmt.Sprintf("record %d checkpointed at %s is missing", cp.Sequence, cp.Time.Format(time.RFC3339)))
+		return
+	}
+	if l.recordHash != cp.RecordHash {
+		v.issue(IssueCheckpointMismatch, cp.ChainID, cp.Sequence, l.recordID,
+			"record hash does not match checkpoint")
+	}
+}
+
+// issue adds an issue to the report.
+func (v *Verifier) issue(kind IssueKind, chainID string, sequence int64, recordID, message string) {
+	v.report.Issues = append(v.report.Issues, Issue{
+		Kind:     kind,
+		ChainID:  chainID,
+		Sequence: sequence,
+		RecordID: recordID,
+		Message:  message,
+	})
+}
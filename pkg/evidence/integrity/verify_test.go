@@ -0,0 +1,231 @@
+package integrity
+
+import (
+	"context"
+	"crypto/ed25519"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+// chainedRecords returns n linked records and a signed checkpoint of the
+// last one.
+func chainedRecords(t *testing.T, n int, key ed25519.PrivateKey) ([]*evidence.EvidenceRecord, *Checkpoint) {
+	t.Helper()
+	chain := NewChain("chain-a")
+	records := make([]*evidence.EvidenceRecord, n)
+	for i := range records {
+		records[i] = newRecord(i)
+		chain.Link(records[i])
+		chain.Commit(records[i])
+	}
+
+	sequence, head := chain.Head()
+	cp := &Checkpoint{ChainID: chain.ID(), Sequence: sequence, RecordHash: head, Time: time.Now()}
+	SignCheckpoint(cp, key)
+	return records, cp
+}
+
+func verify(records []*evidence.EvidenceRecord, checkpoints []*Checkpoint, key ed25519.PrivateKey) *Report {
+	v := NewVerifier(&VerifyOptions{PublicKey: key.Public().(ed25519.PublicKey)})
+	// Add in reverse to show that order does not matter
+	for i := len(records) - 1; i >= 0; i-- {
+		v.Add(records[i])
+	}
+	return v.Finish(checkpoints)
+}
+
+func TestVerifier(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(nil)
+	_, otherKey, _ := ed25519.GenerateKey(nil)
+
+	tests := []struct {
+		name   string
+		tamper func(records []*evidence.EvidenceRecord, cp *Checkpoint) []*evidence.EvidenceRecord
+		want   []IssueKind
+	}{
+		{
+			name: "intact",
+		},
+		{
+			name: "modified record",
+			tamper: func(records []*evidence.EvidenceRecord, cp *Checkpoint) []*evidence.EvidenceRecord {
+				records[2].ActualCost = 0
+				return records
+			},
+			want: []IssueKind{IssueModified},
+		},
+		{
+			name: "modified record with recomputed hash",
+			tamper: func(records []*evidence.EvidenceRecord, cp *Checkpoint) []*evidence.EvidenceRecord {
+				records[2].ActualCost = 0
+				records[2].RecordHash = HashRecord(records[2])
+				return records
+			},
+			want: []IssueKind{IssueBrokenLink},
+		},
+		{
+			name: "deleted record",
+			tamper: func(records []*evidence.EvidenceRecord, cp *Checkpoint) []*evidence.EvidenceRecord {
+				return append(records[:2], records[3:]...)
+			},
+			want: []IssueKind{IssueMissing},
+		},
+		{
+			name: "deleted tail",
+			tamper: func(records []*evidence.EvidenceRecord, cp *Checkpoint) []*evidence.EvidenceRecord {
+				return records[:3]
+			},
+			want: []IssueKind{IssueMissing},
+		},
+		{
+			name: "pruned head",
+			tamper: func(records []*evidence.EvidenceRecord, cp *Checkpoint) []*evidence.EvidenceRecord {
+				return records[2:]
+			},
+		},
+		{
+			name: "forged checkpoint",
+			tamper: func(records []*evidence.EvidenceRecord, cp *Checkpoint) []*evidence.EvidenceRecord {
+				SignCheckpoint(cp, otherKey)
+				return records
+			},
+			want: []IssueKind{IssueBadSignature},
+		},
+		{
+			name: "rewritten chain",
+			tamper: func(records []*evidence.EvidenceRecord, cp *Checkpoint) []*evidence.EvidenceRecord {
+				// Relinking every record after a change leaves no trace in
+				// the records themselves, only in the checkpoint
+				chain := NewChain("chain-a")
+				records[0].ActualCost = 0
+				for _, r := range records {
+					chain.Link(r)
+					chain.Commit(r)
+				}
+				return records
+			},
+			want: []IssueKind{IssueCheckpointMismatch},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			records, cp := chainedRecords(t, 5, key)
+			if tt.tamper != nil {
+				records = tt.tamper(records, cp)
+			}
+
+			report := verify(records, []*Checkpoint{cp}, key)
+			if len(report.Issues) != len(tt.want) {
+				t.Fatalf("got issues %+v, want kinds %v", report.Issues, tt.want)
+			}
+			for i, kind := range tt.want {
+				if report.Issues[i].Kind != kind {
+					t.Errorf("issue %d kind = %s, want %s", i, report.Issues[i].Kind, kind)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifier_PrunedAndUnchained(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(nil)
+	records, cp := chainedRecords(t, 5, key)
+	records = append(records[3:], newRecord(99))
+
+	// The checkpoint of the last record still applies after pruning
+	report := verify(records, []*Checkpoint{cp}, key)
+	if !report.OK() {
+		t.Fatalf("unexpected issues: %+v", report.Issues)
+	}
+	if report.Pruned != 3 || report.Unchained != 1 || report.Records != 3 {
+		t.Errorf("report = %+v, want 3 pruned, 1 unchained, 3 records", report)
+	}
+
+	// Checkpoints before Since are not checked against the records
+	old := &Checkpoint{ChainID: "chain-a", Sequence: 2, RecordHash: "gone", Time: cp.Time.Add(-time.Hour)}
+	SignCheckpoint(old, key)
+	v := NewVerifier(&VerifyOptions{PublicKey: key.Public().(ed25519.PublicKey), Since: cp.Time.Add(-time.Minute)})
+	for _, r := range records {
+		v.Add(r)
+	}
+	if report := v.Finish([]*Checkpoint{old, cp}); !report.OK() {
+		t.Errorf("checkpoint before Since reported: %+v", report.Issues)
+	}
+}
+
+func TestVerifier_Anonymized(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(nil)
+	records, cp := chainedRecords(t, 3, key)
+
+	// Anonymization changes the content but keeps the chain links
+	anonymizedAt := time.Now()
+	records[1].UserID = "anon-1234"
+	records[1].AnonymizedAt = &anonymizedAt
+	records[1].AnonymizedHash = HashRecord(records[1])
+
+	report := verify(records, []*Checkpoint{cp}, key)
+	if !report.OK() {
+		t.Fatalf("unexpected issues: %+v", report.Issues)
+	}
+	if report.Anonymized != 1 {
+		t.Errorf("report.Anonymized = %d, want 1", report.Anonymized)
+	}
+
+	// Changes after anonymization are found
+	records[1].UserID = "anon-5678"
+	if report := verify(records, []*Checkpoint{cp}, key); report.OK() {
+		t.Error("expected record modified after anonymization to be reported")
+	}
+	records[1].UserID = "anon-1234"
+
+	// Marking a record anonymized does not hide changes
+	records[2].UserID = "anon-5678"
+	records[2].AnonymizedAt = &anonymizedAt
+	if report := verify(records, []*Checkpoint{cp}, key); report.OK() {
+		t.Error("expected anonymized record without hash to be reported")
+	}
+	records[2].AnonymizedAt = nil
+
+	// Content changes of records that are not anonymized are still found
+	if report := verify(records, []*Checkpoint{cp}, key); report.OK() {
+		t.Error("expected modified record to be reported")
+	}
+}
+
+func TestCheckpointer(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(nil)
+	path := filepath.Join(t.TempDir(), "checkpoints.jsonl")
+	store, err := NewFileCheckpointStore(path)
+	if err != nil {
+		t.Fatalf("NewFileCheckpointStore() error = %v", err)
+	}
+	defer store.Close()
+
+	checkpointer := NewCheckpointer(store, key, &CheckpointerConfig{Interval: 3, Period: time.Hour})
+	records, _ := chainedRecords(t, 7, key)
+	for _, r := range records {
+		checkpointer.ObserveRecord(r)
+		time.Sleep(time.Millisecond)
+	}
+	checkpointer.Close()
+
+	checkpoints, err := store.List(context.Background())
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(checkpoints) < 2 {
+		t.Fatalf("got %d checkpoints, want at least 2", len(checkpoints))
+	}
+	last := checkpoints[len(checkpoints)-1]
+	if last.Sequence != 7 || last.RecordHash != records[6].RecordHash {
+		t.Errorf("final checkpoint = %d/%s, want head of chain", last.Sequence, last.RecordHash)
+	}
+
+	if report := verify(records, checkpoints, key); !report.OK() {
+		t.Errorf("unexpected issues: %+v", report.Issues)
+	}
+}
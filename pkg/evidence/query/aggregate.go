@@ -0,0 +1,182 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+// ValidDimensions contains the dimensions aggregations can group by.
+var ValidDimensions = map[string]bool{
+	evidence.DimensionUser:     true,
+	evidence.DimensionTeam:     true,
+	evidence.DimensionProvider: true,
+	evidence.DimensionModel:    true,
+	evidence.DimensionDay:      true,
+	evidence.DimensionCostTag:  true,
+}
+
+// ErrRollupsUnsupported is returned by AggregateRollups for storage
+// backends that do not maintain rollups.
+var ErrRollupsUnsupported = errors.New("storage backend does not support rollups")
+
+// ValidateAggregate validates an aggregate query.
+func ValidateAggregate(q *evidence.AggregateQuery) error {
+	seen := make(map[string]bool, len(q.GroupBy))
+	for _, dim := range q.GroupBy {
+		if !ValidDimensions[dim] {
+			return evidence.NewQueryError(&q.Query, fmt.Errorf("invalid group_by dimension: %s (must be one of user, team, provider, model, day, cost_tag)", dim))
+		}
+		if seen[dim] {
+			return evidence.NewQueryError(&q.Query, fmt.Errorf("duplicate group_by dimension: %s", dim))
+		}
+		seen[dim] = true
+	}
+	return Validate(&q.Query)
+}
+
+// Aggregate computes the totals of the records matching q, grouped by
+// q.GroupBy. Backends implementing evidence.Aggregator aggregate natively;
+// for other backends every matching record is read.
+func Aggregate(ctx context.Context, store evidence.Storage, q *evidence.AggregateQuery) ([]*evidence.AggregateRow, error) {
+	if err := ValidateAggregate(q); err != nil {
+		return nil, err
+	}
+	if aggregator, ok := store.(evidence.Aggregator); ok {
+		return aggregator.Aggregate(ctx, q)
+	}
+
+	filter := q.Query
+	filter.Limit = 0
+	filter.Offset = 0
+	recordsCh, errCh, err := store.QueryStream(ctx, &filter)
+	if err != nil {
+		return nil, err
+	}
+
+	acc := NewAccumulator(q.GroupBy)
+	for record := range recordsCh {
+		acc.Add(record)
+	}
+	if err := <-errCh; err != nil {
+		return nil, err
+	}
+	return acc.Rows(), nil
+}
+
+// AggregateRollups computes the same totals as Aggregate from the
+// pre-computed daily rollups of a evidence.RollupStore. This is much
+// faster for long time ranges, but only supports time, user, team, cost
+// tag, provider, model, and decision filters, counts whole days, and reflects
+// the records as of the last rollup refresh.
+func AggregateRollups(ctx context.Context, store evidence.Storage, q *evidence.AggregateQuery) ([]*evidence.AggregateRow, error) {
+	if err := ValidateAggregate(q); err != nil {
+		return nil, err
+	}
+	f := &q.Query
+	if f.APIKey != "" || f.PolicyID != "" || f.RuleID != "" || f.Status != "" ||
+		f.MinCost != nil || f.MaxCost != nil || f.MinTokens != nil || f.MaxTokens != nil ||
+		f.Search != "" || f.ToolName != "" ||
+		f.TraceID != "" || f.DecisionID != "" {
+		return nil, evidence.NewQueryError(f, fmt.Errorf("rollups only support time, user, team, cost tag, provider, model, and decision filters"))
+	}
+	rollups, ok := store.(evidence.RollupStore)
+	if !ok {
+		return nil, ErrRollupsUnsupported
+	}
+	return rollups.AggregateRollups(ctx, q)
+}
+
+// Accumulator aggregates evidence records in memory.
+type Accumulator struct {
+	groupBy []string
+	groups  map[string]*evidence.AggregateRow
+}
+
+// NewAccumulator creates an accumulator grouping by groupBy.
+func NewAccumulator(groupBy []string) *Accumulator {
+	return &Accumulator{
+		groupBy: groupBy,
+		groups:  make(map[string]*evidence.AggregateRow),
+	}
+}
+
+// Add adds a record to its group.
+func (a *Accumulator) Add(record *evidence.EvidenceRecord) {
+	row := &evidence.AggregateRow{}
+	for _, dim := range a.groupBy {
+		switch dim {
+		case evidence.DimensionUser:
+			row.User = record.UserID
+		case evidence.DimensionTeam:
+			row.Team = record.TeamID
+		case evidence.DimensionProvider:
+			row.Provider = record.Provider
+		case evidence.DimensionModel:
+			row.Model = record.Model
+		case evidence.DimensionDay:
+			row.Day = record.RequestTime.UTC().Format(time.DateOnly)
+		case evidence.DimensionCostTag:
+			row.CostTag = record.CostTag
+		}
+	}
+
+	key := groupKey(row, a.groupBy)
+	if existing, ok := a.groups[key]; ok {
+		row = existing
+	} else {
+		row.Decisions = make(map[string]int64)
+		a.groups[key] = row
+	}
+
+	row.Requests++
+	row.PromptTokens += int64(record.PromptTokens)
+	row.CompletionTokens += int64(record.CompletionTokens)
+	row.TotalTokens += int64(record.TotalTokens)
+	row.Cost += record.ActualCost
+	row.Decisions[record.PolicyDecision]++
+}
+
+// Rows returns the groups ordered by their dimension values.
+func (a *Accumulator) Rows() []*evidence.AggregateRow {
+	rows := make([]*evidence.AggregateRow, 0, len(a.groups))
+	for _, row := range a.groups {
+		rows = append(rows, row)
+	}
+	slices.SortFunc(rows, func(x, y *evidence.AggregateRow) int {
+		return slices.Compare(groupValues(x, a.groupBy), groupValues(y, a.groupBy))
+	})
+	return rows
+}
+
+// groupValues returns the values of the groupBy dimensions of row.
+func groupValues(row *evidence.AggregateRow, groupBy []string) []string {
+	values := make([]string, len(groupBy))
+	for i, dim := range groupBy {
+		switch dim {
+		case evidence.DimensionUser:
+			values[i] = row.User
+		case evidence.DimensionTeam:
+			values[i] = row.Team
+		case evidence.DimensionProvider:
+			values[i] = row.Provider
+		case evidence.DimensionModel:
+			values[i] = row.Model
+		case evidence.DimensionDay:
+			values[i] = row.Day
+		case evidence.DimensionCostTag:
+			values[i] = row.CostTag
+		}
+	}
+	return values
+}
+
+// groupKey returns a map key identifying the group of row.
+func groupKey(row *evidence.AggregateRow, groupBy []string) string {
+	return strings.Join(groupValues(row, groupBy), "\x00")
+}
@@ -0,0 +1,216 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/evidence/storage"
+)
+
+func newAggregateStore(t *testing.T) evidence.Storage {
+	t.Helper()
+
+	store := storage.NewMemoryStorage()
+	day := time.Date(2025, 11, 1, 23, 30, 0, 0, time.UTC)
+	records := []*evidence.EvidenceRecord{
+		{ID: "1", RequestTime: day, UserID: "alice", TeamID: "red", Model: "gpt-4", PolicyDecision: "allow", TotalTokens: 30, ActualCost: 0.5},
+		{ID: "2", RequestTime: day, UserID: "bob", TeamID: "red", Model: "gpt-4", PolicyDecision: "block", TotalTokens: 5},
+		{ID: "3", RequestTime: day.Add(time.Hour), UserID: "alice", Model: "claude-3", PolicyDecision: "allow", TotalTokens: 3, ActualCost: 0.25},
+	}
+	for _, record := range records {
+		if err := store.Store(context.Background(), record); err != nil {
+			t.Fatalf("Store() failed: %v", err)
+		}
+	}
+	return store
+}
+
+func TestAggregate_Fallback(t *testing.T) {
+	store := newAggregateStore(t)
+
+	rows, err := Aggregate(context.Background(), store, &evidence.AggregateQuery{
+		GroupBy: []string{evidence.DimensionDay, evidence.DimensionModel},
+	})
+	if err != nil {
+		t.Fatalf("Aggregate() failed: %v", err)
+	}
+
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 rows, got %d", len(rows))
+	}
+	if rows[0].Day != "2025-11-01" || rows[0].Model != "gpt-4" {
+		t.Errorf("Expected gpt-4 on 2025-11-01 first, got %s on %s", rows[0].Model, rows[0].Day)
+	}
+	if rows[0].Requests != 2 || rows[0].TotalTokens != 35 || rows[0].Cost != 0.5 {
+		t.Errorf("Unexpected totals: %+v", rows[0])
+	}
+	if rows[0].Decisions["allow"] != 1 || rows[0].Decisions["block"] != 1 {
+		t.Errorf("Unexpected decisions: %v", rows[0].Decisions)
+	}
+	if rows[1].Day != "2025-11-02" || rows[1].Model != "claude-3" {
+		t.Errorf("Expected claude-3 on 2025-11-02 second, got %s on %s", rows[1].Model, rows[1].Day)
+	}
+}
+
+func TestValidateAggregate(t *testing.T) {
+	tests := []struct {
+		name    string
+		groupBy []string
+		wantErr bool
+	}{
+		{"no dimensions", nil, false},
+		{"all dimensions", []string{"user", "team", "provider", "model", "day"}, false},
+		{"unknown dimension", []string{"region"}, true},
+		{"duplicate dimension", []string{"model", "model"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateAggregate(&evidence.AggregateQuery{GroupBy: tt.groupBy})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateAggregate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAggregateRollups_Unsupported(t *testing.T) {
+	_, err := AggregateRollups(context.Background(), storage.NewMemoryStorage(), &evidence.AggregateQuery{})
+	if !errors.Is(err, ErrRollupsUnsupported) {
+		t.Errorf("Expected ErrRollupsUnsupported, got %v", err)
+	}
+}
+
+func TestAggregateHandler(t *testing.T) {
+	handler := AggregateHandler(newAggregateStore(t))
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		wantRows   int
+	}{
+		{"group by user", "/?group_by=user", http.StatusOK, 2},
+		{"filtered", "/?group_by=user&team=red&decision=block", http.StatusOK, 1},
+		{"time range", "/?start=2025-11-02T00:00:00Z&end=2025-11-03T00:00:00Z", http.StatusOK, 1},
+		{"no matches", "/?model=gpt-5", http.StatusOK, 0},
+		{"invalid dimension", "/?group_by=region", http.StatusBadRequest, 0},
+		{"invalid time", "/?start=yesterday", http.StatusBadRequest, 0},
+		{"rollups unsupported", "/?rollup=true", http.StatusBadRequest, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			var rows []*evidence.AggregateRow
+			if err := json.NewDecoder(w.Body).Decode(&rows); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if len(rows) != tt.wantRows {
+				t.Errorf("Expected %d rows, got %d", tt.wantRows, len(rows))
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", nil))
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected status 405 for POST, got %d", w.Code)
+	}
+}
+
+func TestChargeback(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	records := []*evidence.EvidenceRecord{
+		{ID: "1", CostTag: "search", Model: "gpt-4", ActualCost: 1.5, TotalTokens: 100},
+		{ID: "2", CostTag: "search", Model: "claude-3", ActualCost: 0.5, TotalTokens: 40},
+		{ID: "3", CostTag: "support", Model: "gpt-4", ActualCost: 1.5, TotalTokens: 90},
+		{ID: "4", Model: "gpt-4", ActualCost: 0.5, TotalTokens: 10},
+	}
+	for _, record := range records {
+		if err := store.Store(context.Background(), record); err != nil {
+			t.Fatalf("Store() failed: %v", err)
+		}
+	}
+
+	report, err := Chargeback(context.Background(), store, &evidence.AggregateQuery{
+		GroupBy: []string{evidence.DimensionModel},
+	}, false)
+	if err != nil {
+		t.Fatalf("Chargeback() failed: %v", err)
+	}
+
+	if report.Requests != 4 || report.Cost != 4 {
+		t.Errorf("Unexpected totals: %d requests, cost %v", report.Requests, report.Cost)
+	}
+	if len(report.Tags) != 3 {
+		t.Fatalf("Expected 3 tags, got %d", len(report.Tags))
+	}
+	search := report.Tags[0]
+	if search.CostTag != "search" || search.Requests != 2 || search.TotalTokens != 140 || search.Share != 0.5 {
+		t.Errorf("Unexpected first line: %+v", search)
+	}
+	if len(search.Breakdown) != 2 || search.Breakdown[0].CostTag != "" {
+		t.Errorf("Expected search broken down by model, got %+v", search.Breakdown)
+	}
+	if report.Tags[1].CostTag != "support" || report.Tags[2].CostTag != "" {
+		t.Errorf("Expected support, then untagged, got %q, %q", report.Tags[1].CostTag, report.Tags[2].CostTag)
+	}
+
+	_, err = Chargeback(context.Background(), store, &evidence.AggregateQuery{
+		GroupBy: []string{evidence.DimensionCostTag},
+	}, false)
+	var queryErr *evidence.QueryError
+	if !errors.As(err, &queryErr) {
+		t.Errorf("Expected query error for grouping by cost_tag, got %v", err)
+	}
+}
+
+func TestChargebackHandler(t *testing.T) {
+	handler := ChargebackHandler(newAggregateStore(t))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?group_by=model", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var report ChargebackReport
+	if err := json.NewDecoder(w.Body).Decode(&report); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(report.Tags) != 1 || len(report.Tags[0].Breakdown) != 2 {
+		t.Errorf("Expected one untagged line broken down by model, got %+v", report.Tags)
+	}
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/?group_by=model&format=csv", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "text/csv" {
+		t.Fatalf("Expected CSV, got %d %q", w.Code, w.Header().Get("Content-Type"))
+	}
+	want := "cost_tag,model,requests,prompt_tokens,completion_tokens,total_tokens,cost,share\n"
+	if body := w.Body.String(); len(body) < len(want) || body[:len(want)] != want {
+		t.Errorf("Unexpected CSV header: %q", body)
+	}
+
+	for _, target := range []string{"/?format=xml", "/?group_by=cost_tag", "/?rollup=true"} {
+		w = httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", target, w.Code)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+// ChargebackReport allocates the spending of the records matching a query
+// to their cost allocation tags.
+type ChargebackReport struct {
+	// StartTime and EndTime are the time range of the report, if bounded.
+	StartTime *time.Time `json:"start_time,omitempty"`
+	EndTime   *time.Time `json:"end_time,omitempty"`
+
+	// Requests and Cost are the totals of all tags.
+	Requests int64   `json:"requests"`
+	Cost     float64 `json:"cost"`
+
+	// Tags are the spending of each tag, most expensive first. Untagged
+	// requests are reported under the empty tag.
+	Tags []*ChargebackLine `json:"tags"`
+}
+
+// ChargebackLine is the spending of one cost allocation tag.
+type ChargebackLine struct {
+	CostTag          string  `json:"cost_tag"`
+	Requests         int64   `json:"requests"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	TotalTokens      int64   `json:"total_tokens"`
+	Cost             float64 `json:"cost"`
+
+	// Share is the fraction (0-1) of the report's cost charged to the tag.
+	Share float64 `json:"share"`
+
+	// Breakdown splits the tag's spending by the dimensions the report is
+	// broken down by, if any.
+	Breakdown []*evidence.AggregateRow `json:"breakdown,omitempty"`
+}
+
+// Chargeback aggregates the records matching q by cost allocation tag,
+// breaking each tag's spending down by q.GroupBy. With useRollups, the
+// daily rollups are aggregated instead of the records (see
+// AggregateRollups).
+func Chargeback(ctx context.Context, store evidence.Storage, q *evidence.AggregateQuery, useRollups bool) (*ChargebackReport, error) {
+	if slices.Contains(q.GroupBy, evidence.DimensionCostTag) {
+		return nil, evidence.NewQueryError(&q.Query, fmt.Errorf("chargeback reports are always grouped by cost_tag"))
+	}
+
+	grouped := *q
+	grouped.GroupBy = append([]string{evidence.DimensionCostTag}, q.GroupBy...)
+
+	var (
+		rows []*evidence.AggregateRow
+		err  error
+	)
+	if useRollups {
+		rows, err = AggregateRollups(ctx, store, &grouped)
+	} else {
+		rows, err = Aggregate(ctx, store, &grouped)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ChargebackReport{
+		StartTime: q.StartTime,
+		EndTime:   q.EndTime,
+		Tags:      []*ChargebackLine{},
+	}
+	lines := make(map[string]*ChargebackLine)
+	for _, row := range rows {
+		line, ok := lines[row.CostTag]
+		if !ok {
+			line = &ChargebackLine{CostTag: row.CostTag}
+			lines[row.CostTag] = line
+			report.Tags = append(report.Tags, line)
+		}
+		line.Requests += row.Requests
+		line.PromptTokens += row.PromptTokens
+		line.CompletionTokens += row.CompletionTokens
+		line.TotalTokens += row.TotalTokens
+		line.Cost += row.Cost
+		if len(q.GroupBy) > 0 {
+			row.CostTag = ""
+			line.Breakdown = append(line.Breakdown, row)
+		}
+
+		report.Requests += row.Requests
+		report.Cost += row.Cost
+	}
+
+	for _, line := range report.Tags {
+		if report.Cost > 0 {
+			line.Share = line.Cost / report.Cost
+		}
+	}
+	slices.SortStableFunc(report.Tags, func(a, b *ChargebackLine) int {
+		switch {
+		case a.Cost > b.Cost:
+			return -1
+		case a.Cost < b.Cost:
+			return 1
+		default:
+			return strings.Compare(a.CostTag, b.CostTag)
+		}
+	})
+	return report, nil
+}
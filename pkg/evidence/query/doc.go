@@ -24,6 +24,26 @@
 //   - Time range is valid (start <= end)
 //   - Cost/token thresholds are valid (min <= max)
 //
+// # Aggregation
+//
+// Aggregate groups the records matching a query by user, team, cost tag,
+// provider, model, and/or day, and sums their requests, tokens, and cost, counting
+// requests per policy decision. Backends implementing evidence.Aggregator
+// compute the groups natively; other backends are scanned in full.
+//
+// AggregateRollups answers the same queries from the daily rollups of an
+// evidence.RollupStore, which a RollupJob refreshes in the background.
+// AggregateHandler serves both over HTTP.
+//
+//	rows, err := query.Aggregate(ctx, storage, &evidence.AggregateQuery{
+//	    Query:   evidence.Query{StartTime: &startTime},
+//	    GroupBy: []string{evidence.DimensionModel, evidence.DimensionDay},
+//	})
+//
+// Chargeback allocates the spending of the matching records to their cost
+// allocation tags, optionally broken down by further dimensions, and
+// ChargebackHandler serves the report as JSON or CSV.
+//
 // # Basic Usage
 //
 //	// Create query
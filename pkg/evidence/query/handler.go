@@ -0,0 +1,263 @@
+package query
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+// AggregateHandler returns an HTTP handler that serves evidence
+// aggregations as JSON, e.g. when mounted at /admin/evidence/aggregate.
+//
+// Query parameters:
+//   - group_by: comma-separated dimensions (user, team, provider, model, day,
+//     cost_tag)
+//   - start, end: time range (RFC3339)
+//   - user, team, cost_tag, provider, model, decision: filters
+//   - search: full-text search of prompts and responses
+//   - tool: records whose response called this tool
+//   - rollup: "true" to aggregate the pre-computed daily rollups
+func AggregateHandler(store evidence.Storage) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		q, useRollups, err := parseAggregateQuery(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		var rows []*evidence.AggregateRow
+		if useRollups {
+			rows, err = AggregateRollups(r.Context(), store, q)
+		} else {
+			rows, err = Aggregate(r.Context(), store, q)
+		}
+		if err != nil {
+			var queryErr *evidence.QueryError
+			switch {
+			case errors.As(err, &queryErr), errors.Is(err, ErrRollupsUnsupported):
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			default:
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+		if rows == nil {
+			rows = []*evidence.AggregateRow{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(rows)
+	})
+}
+
+// ChargebackHandler returns an HTTP handler that serves chargeback reports,
+// e.g. when mounted at /admin/evidence/chargeback. A report allocates the
+// spending in a time range to the cost allocation tags of the requests.
+//
+// Query parameters:
+//   - start, end: time range (RFC3339)
+//   - group_by: comma-separated dimensions to break each tag's spending
+//     down by (user, team, provider, model, day)
+//   - user, team, cost_tag, provider, model, decision, search, tool: filters,
+//     as for AggregateHandler
+//   - rollup: "true" to aggregate the pre-computed daily rollups
+//   - format: "json" (default) or "csv"
+func ChargebackHandler(store evidence.Storage) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		q, useRollups, err := parseAggregateQuery(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		format := r.URL.Query().Get("format")
+		if format != "" && format != "json" && format != "csv" {
+			http.Error(w, fmt.Sprintf("invalid format: %q (must be json or csv)", format), http.StatusBadRequest)
+			return
+		}
+
+		report, err := Chargeback(r.Context(), store, q, useRollups)
+		if err != nil {
+			var queryErr *evidence.QueryError
+			switch {
+			case errors.As(err, &queryErr), errors.Is(err, ErrRollupsUnsupported):
+				http.Error(w, err.Error(), http.StatusBadRequest)
+			default:
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			}
+			return
+		}
+
+		if format == "csv" {
+			w.Header().Set("Content-Type", "text/csv")
+			writeChargebackCSV(w, report, q.GroupBy)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(report)
+	})
+}
+
+// writeChargebackCSV writes a chargeback report as CSV, with one line per
+// tag, or per tag and breakdown group if the report is broken down.
+func writeChargebackCSV(w io.Writer, report *ChargebackReport, groupBy []string) {
+	out := csv.NewWriter(w)
+	header := append([]string{evidence.DimensionCostTag}, groupBy...)
+	out.Write(append(header, "requests", "prompt_tokens", "completion_tokens", "total_tokens", "cost", "share"))
+
+	share := func(cost float64) string {
+		if report.Cost == 0 {
+			return "0"
+		}
+		return strconv.FormatFloat(cost/report.Cost, 'f', 4, 64)
+	}
+	totals := func(requests, prompt, completion, total int64, cost float64) []string {
+		return []string{
+			strconv.FormatInt(requests, 10),
+			strconv.FormatInt(prompt, 10),
+			strconv.FormatInt(completion, 10),
+			strconv.FormatInt(total, 10),
+			strconv.FormatFloat(cost, 'f', 6, 64),
+			share(cost),
+		}
+	}
+
+	for _, line := range report.Tags {
+		if len(line.Breakdown) == 0 {
+			out.Write(append([]string{line.CostTag},
+				totals(line.Requests, line.PromptTokens, line.CompletionTokens, line.TotalTokens, line.Cost)...))
+			continue
+		}
+		for _, row := range line.Breakdown {
+			record := append([]string{line.CostTag}, groupValues(row, groupBy)...)
+			out.Write(append(record,
+				totals(row.Requests, row.PromptTokens, row.CompletionTokens, row.TotalTokens, row.Cost)...))
+		}
+	}
+	out.Flush()
+}
+
+// RecordsHandler returns an HTTP handler that serves the evidence records
+// matching a query as JSON, e.g. when mounted at /admin/evidence/records.
+// A tracing backend can link a trace to its evidence with ?trace_id=.
+//
+// Query parameters:
+//   - trace_id: OpenTelemetry trace ID
+//   - decision_id: policy decision ID
+//   - start, end: time range (RFC3339)
+//   - user, team, cost_tag, provider, model, decision, search, tool:
+//     filters, as for AggregateHandler
+//   - limit, offset: pagination (default limit: DefaultLimit)
+func RecordsHandler(store evidence.Storage) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		params := r.URL.Query()
+		q, err := parseQuery(params)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		q.TraceID = params.Get("trace_id")
+		q.DecisionID = params.Get("decision_id")
+		for name, dst := range map[string]*int{"limit": &q.Limit, "offset": &q.Offset} {
+			if value := params.Get(name); value != "" {
+				if *dst, err = strconv.Atoi(value); err != nil {
+					http.Error(w, fmt.Sprintf("invalid %s value: %q", name, value), http.StatusBadRequest)
+					return
+				}
+			}
+		}
+		ApplyDefaults(q)
+		if err := Validate(q); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		records, err := store.Query(r.Context(), q)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if records == nil {
+			records = []*evidence.EvidenceRecord{}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(records)
+	})
+}
+
+// parseQuery builds the record filters shared by the handlers from request
+// parameters.
+func parseQuery(params url.Values) (*evidence.Query, error) {
+	q := &evidence.Query{
+		UserID:         params.Get("user"),
+		TeamID:         params.Get("team"),
+		CostTag:        params.Get("cost_tag"),
+		Provider:       params.Get("provider"),
+		Model:          params.Get("model"),
+		PolicyDecision: params.Get("decision"),
+		Search:         params.Get("search"),
+		ToolName:       params.Get("tool"),
+	}
+	for name, dst := range map[string]**time.Time{"start": &q.StartTime, "end": &q.EndTime} {
+		if value := params.Get(name); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s time: %w", name, err)
+			}
+			*dst = &t
+		}
+	}
+	return q, nil
+}
+
+// parseAggregateQuery builds an aggregate query from request parameters.
+func parseAggregateQuery(r *http.Request) (*evidence.AggregateQuery, bool, error) {
+	params := r.URL.Query()
+	filters, err := parseQuery(params)
+	if err != nil {
+		return nil, false, err
+	}
+	q := &evidence.AggregateQuery{Query: *filters}
+
+	if groupBy := params.Get("group_by"); groupBy != "" {
+		for _, dim := range strings.Split(groupBy, ",") {
+			q.GroupBy = append(q.GroupBy, strings.TrimSpace(dim))
+		}
+	}
+
+	var useRollups bool
+	if value := params.Get("rollup"); value != "" {
+		useRollups, err = strconv.ParseBool(value)
+		if err != nil {
+			return nil, false, fmt.Errorf("invalid rollup value: %q", value)
+		}
+	}
+	return q, useRollups, nil
+}
@@ -0,0 +1,77 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/evidence/storage"
+)
+
+func TestRecordsHandler(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	now := time.Now()
+	records := []*evidence.EvidenceRecord{
+		{ID: "1", RequestTime: now, TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", DecisionID: "dec-1", UserID: "alice"},
+		{ID: "2", RequestTime: now, TraceID: "0af7651916cd43dd8448eb211c80319c", DecisionID: "dec-2", UserID: "alice"},
+		{ID: "3", RequestTime: now, UserID: "bob"},
+	}
+	for _, record := range records {
+		if err := store.Store(context.Background(), record); err != nil {
+			t.Fatalf("Store() failed: %v", err)
+		}
+	}
+	handler := RecordsHandler(store)
+
+	tests := []struct {
+		name       string
+		target     string
+		wantStatus int
+		wantIDs    []string
+	}{
+		{"by trace", "/?trace_id=4bf92f3577b34da6a3ce929d0e0e4736", http.StatusOK, []string{"1"}},
+		{"by decision", "/?decision_id=dec-2", http.StatusOK, []string{"2"}},
+		{"filtered", "/?user=bob", http.StatusOK, []string{"3"}},
+		{"unknown trace", "/?trace_id=00000000000000000000000000000000", http.StatusOK, nil},
+		{"limited", "/?user=alice&limit=1", http.StatusOK, nil},
+		{"invalid limit", "/?limit=many", http.StatusBadRequest, nil},
+		{"limit too large", "/?limit=1000000", http.StatusBadRequest, nil},
+		{"invalid time", "/?end=tomorrow", http.StatusBadRequest, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			var got []*evidence.EvidenceRecord
+			if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+				t.Fatalf("Failed to decode response: %v", err)
+			}
+			if tt.name == "limited" {
+				if len(got) != 1 {
+					t.Errorf("Expected 1 record, got %d", len(got))
+				}
+				return
+			}
+			if len(got) != len(tt.wantIDs) {
+				t.Fatalf("Expected records %v, got %d records", tt.wantIDs, len(got))
+			}
+			for i, record := range got {
+				if record.ID != tt.wantIDs[i] {
+					t.Errorf("Expected record %s, got %s", tt.wantIDs[i], record.ID)
+				}
+			}
+		})
+	}
+}
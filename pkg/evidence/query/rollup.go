@@ -0,0 +1,136 @@
+package query
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+const (
+	// DefaultRollupInterval is the default time between rollup refreshes.
+	DefaultRollupInterval = 5 * time.Minute
+
+	// DefaultRollupLookbackDays is the default number of days before the
+	// latest rollup that are recomputed on each refresh.
+	DefaultRollupLookbackDays = 1
+)
+
+// RollupConfig configures a RollupJob.
+type RollupConfig struct {
+	// Interval is the time between rollup refreshes.
+	// Default: 5m
+	Interval time.Duration
+
+	// LookbackDays is the number of days before the latest rollup that
+	// are recomputed on each refresh, picking up late records.
+	// Default: 1
+	LookbackDays int
+}
+
+// RollupJob periodically refreshes the daily rollups of a
+// evidence.RollupStore.
+//
+// Each refresh recomputes the days from the latest rollup (minus the
+// lookback) onwards, so earlier rollups survive retention pruning of their
+// records.
+type RollupJob struct {
+	store  evidence.RollupStore
+	config RollupConfig
+	logger *slog.Logger
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewRollupJob creates a rollup job for store.
+func NewRollupJob(store evidence.RollupStore, config RollupConfig) *RollupJob {
+	if config.Interval <= 0 {
+		config.Interval = DefaultRollupInterval
+	}
+	if config.LookbackDays < 0 {
+		config.LookbackDays = 0
+	}
+	return &RollupJob{
+		store:  store,
+		config: config,
+		logger: slog.Default().With("component", "evidence.rollup"),
+	}
+}
+
+// Start refreshes the rollups immediately and then every interval until
+// the context is cancelled or Stop is called.
+func (j *RollupJob) Start(ctx context.Context) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.cancel != nil {
+		return
+	}
+	ctx, j.cancel = context.WithCancel(ctx)
+	j.done = make(chan struct{})
+
+	j.logger.Info("rollup job started",
+		"interval", j.config.Interval,
+		"lookback_days", j.config.LookbackDays,
+	)
+
+	go func() {
+		defer close(j.done)
+
+		ticker := time.NewTicker(j.config.Interval)
+		defer ticker.Stop()
+
+		for {
+			if err := j.Run(ctx); err != nil && ctx.Err() == nil {
+				j.logger.Error("rollup refresh failed", "error", err)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Run refreshes the rollups once.
+func (j *RollupJob) Run(ctx context.Context) error {
+	latest, err := j.store.LatestRollup(ctx)
+	if err != nil {
+		return err
+	}
+
+	var since time.Time
+	if !latest.IsZero() {
+		since = latest.AddDate(0, 0, -j.config.LookbackDays)
+	}
+
+	start := time.Now()
+	if err := j.store.RefreshRollups(ctx, since); err != nil {
+		return err
+	}
+
+	j.logger.Debug("rollups refreshed",
+		"since", since.Format(time.DateOnly),
+		"duration", time.Since(start),
+	)
+	return nil
+}
+
+// Stop stops the job and waits for a running refresh to finish.
+func (j *RollupJob) Stop() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.cancel == nil {
+		return
+	}
+	j.cancel()
+	<-j.done
+	j.cancel = nil
+	j.logger.Info("rollup job stopped")
+}
@@ -33,6 +33,37 @@
 //	// Record response evidence (async)
 //	recorder.RecordResponse(ctx, enrichedResp)
 //
+// # Streamed Responses
+//
+// Streamed (SSE) responses are recorded with a StreamRecorder, which
+// reassembles the response from the chunk deltas and records the chunk
+// count, time to first token, stream duration, and whether the client
+// disconnected mid-stream:
+//
+//	stream := recorder.StartStream(requestID)
+//	for chunk := range chunks {
+//	    stream.AddChunk(chunk)
+//	    // ... forward the chunk to the client
+//	}
+//	stream.Finish(ctx, responseMeta, nil, clientDisconnected)
+//
+// The response hash and captured body of a streamed record are those of
+// the reassembled response, as for a non-streamed response.
+//
+// # Sampling
+//
+// A Sampler discards a fraction of routine records to control storage
+// growth, e.g. keeping every blocked or failed request but 10% of cheap
+// successful ones. It is applied once a record is complete, so rules can
+// select by decision, error, path, model, and cost:
+//
+//	sampler, err := recorder.NewSampler([]recorder.SampleRule{
+//	    {Decisions: []string{"block"}, Rate: 1},
+//	    {Errors: true, Rate: 1},
+//	    {MaxCost: 0.01, Rate: 0.1},
+//	}, 1)
+//	config.Sampler = sampler
+//
 // # Async Recording
 //
 // The recorder uses a buffered channel and background goroutine to record
@@ -40,7 +71,11 @@
 //
 //   - RecordRequest() creates evidence record and enqueues to channel (non-blocking)
 //   - RecordResponse() updates evidence record and enqueues to channel (non-blocking)
-//   - Background goroutine drains channel and writes to storage
+//   - Background goroutine drains channel and writes to storage in batches
+//     of up to BatchSize records, in one transaction if the storage
+//     implements evidence.BatchStorer
+//   - With a SpillQueue, records that do not fit in the channel are written
+//     to disk and replayed when the channel is empty
 //   - Graceful shutdown drains channel before exit (zero data loss)
 //
 // # Hashing
@@ -68,6 +103,10 @@
 //   - Response content truncated to 500 characters
 //   - Full content can be reconstructed from request/response hashes
 //
+// With Config.Capture set, the complete request and response bodies of the
+// requests selected by the capture policy are also written to blob storage
+// (see package capture) and referenced from the record.
+//
 // # Thread Safety
 //
 // The recorder is thread-safe and can be used concurrently:
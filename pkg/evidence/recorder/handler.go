@@ -0,0 +1,25 @@
+package recorder
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// StatsHandler returns an HTTP handler that reports the write queue
+// counters (see Stats) as JSON.
+//
+// Example:
+//
+//	GET /admin/evidence/recorder
+func (r *Recorder) StatsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(r.Stats())
+	})
+}
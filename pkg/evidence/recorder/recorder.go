@@ -5,15 +5,20 @@ import (
 	"encoding/json"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/google/uuid"
 
 	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/evidence/capture"
+	"mercator-hq/jupiter/pkg/evidence/integrity"
 	"mercator-hq/jupiter/pkg/policy/engine"
 	"mercator-hq/jupiter/pkg/processing"
+	"mercator-hq/jupiter/pkg/providers"
 	"mercator-hq/jupiter/pkg/proxy"
 	"mercator-hq/jupiter/pkg/proxy/types"
+	"mercator-hq/jupiter/pkg/telemetry/tracing"
 )
 
 // Config contains configuration for the evidence recorder.
@@ -29,6 +34,19 @@ type Config struct {
 	// Default: 5 seconds
 	WriteTimeout time.Duration
 
+	// BatchSize is the maximum number of records written to storage at
+	// once, in one transaction if the storage implements
+	// evidence.BatchStorer. Values below 2 write records one by one.
+	// Default: 100
+	BatchSize int
+
+	// FlushInterval is how long a batch waits for more records before it
+	// is written. With 0, a batch holds the records queued while the
+	// previous batch was written, and is written as soon as the queue is
+	// empty.
+	// Default: 0
+	FlushInterval time.Duration
+
 	// HashRequest enables hashing of request bodies.
 	// Default: true
 	HashRequest bool
@@ -44,6 +62,58 @@ type Config struct {
 	// MaxFieldLength is the maximum length for text fields before truncation.
 	// Default: 500
 	MaxFieldLength int
+
+	// Chain links stored records into a tamper-evident hash chain.
+	// Default: nil (records are not chained)
+	Chain *integrity.Chain
+
+	// Signer signs each record before it is stored. When Chain is also set,
+	// the signature covers the record's chain position.
+	// Default: nil (records are not signed)
+	Signer *integrity.Signer
+
+	// Capture stores the full request and response bodies of the requests
+	// selected by its policy in blob storage.
+	// Default: nil (only truncated content and hashes are recorded)
+	Capture *capture.Capturer
+
+	// Spill queues the records that do not fit in the async channel on
+	// disk, instead of waiting up to WriteTimeout and dropping them. They
+	// are written to storage when the channel is empty. The recorder
+	// closes the queue on Close.
+	// Default: nil (records are dropped when the channel stays full)
+	Spill *SpillQueue
+
+	// Sampler selects the records that are stored once their response is
+	// complete; the others are discarded.
+	// Default: nil (every record is stored)
+	Sampler *Sampler
+}
+
+// spillReplayInterval is how often the writer checks the spill queue.
+const spillReplayInterval = 100 * time.Millisecond
+
+// Stats contains the counters of the recorder's write queue.
+type Stats struct {
+	// Queued is the number of records waiting in the async channel.
+	Queued int `json:"queued"`
+
+	// Spilled is the number of records spilled to disk because the
+	// channel was full.
+	Spilled int64 `json:"spilled"`
+
+	// Replayed is the number of spilled records written to storage.
+	Replayed int64 `json:"replayed"`
+
+	// SpillPending is the number of records in the spill queue.
+	SpillPending int64 `json:"spill_pending"`
+
+	// Dropped is the number of records dropped because the channel was
+	// full and could not be spilled.
+	Dropped int64 `json:"dropped"`
+
+	// SampledOut is the number of records discarded by the sampler.
+	SampledOut int64 `json:"sampled_out"`
 }
 
 // DefaultConfig returns the default recorder configuration.
@@ -52,6 +122,7 @@ func DefaultConfig() *Config {
 		Enabled:        true,
 		AsyncBuffer:    1000,
 		WriteTimeout:   5 * time.Second,
+		BatchSize:      100,
 		HashRequest:    true,
 		HashResponse:   true,
 		RedactAPIKeys:  true,
@@ -59,6 +130,16 @@ func DefaultConfig() *Config {
 	}
 }
 
+// RecordObserver is notified of every evidence record after it has been
+// written to storage.
+//
+// ObserveRecord is called on the recorder's write goroutine, so
+// implementations must return quickly and must not modify the record.
+// Slow work such as network delivery should be queued.
+type RecordObserver interface {
+	ObserveRecord(record *evidence.EvidenceRecord)
+}
+
 // Recorder records evidence for LLM proxy requests and responses.
 // It creates evidence records asynchronously to avoid blocking proxy requests.
 type Recorder struct {
@@ -71,6 +152,18 @@ type Recorder struct {
 
 	// pendingRecords tracks partial evidence records that are waiting for response data
 	pendingRecords sync.Map // map[requestID]*evidence.EvidenceRecord
+
+	// capturedBodies holds the bodies of records selected for full-body
+	// capture until the record is written.
+	capturedBodies sync.Map // map[recordID]*bodies
+
+	observersMu sync.RWMutex
+	observers   []RecordObserver
+
+	spilled    atomic.Int64
+	replayed   atomic.Int64
+	dropped    atomic.Int64
+	sampledOut atomic.Int64
 }
 
 // NewRecorder creates a new evidence recorder with the provided storage backend and configuration.
@@ -94,6 +187,9 @@ func NewRecorder(storage evidence.Storage, config *Config) *Recorder {
 	r.logger.Info("evidence recorder initialized",
 		"async_buffer", config.AsyncBuffer,
 		"write_timeout", config.WriteTimeout,
+		"batch_size", config.BatchSize,
+		"flush_interval", config.FlushInterval,
+		"spill", config.Spill != nil,
 		"hash_request", config.HashRequest,
 		"hash_response", config.HashResponse,
 	)
@@ -101,8 +197,19 @@ func NewRecorder(storage evidence.Storage, config *Config) *Recorder {
 	return r
 }
 
+// AddObserver registers an observer for stored evidence records.
+func (r *Recorder) AddObserver(observer RecordObserver) {
+	if observer == nil {
+		return
+	}
+	r.observersMu.Lock()
+	defer r.observersMu.Unlock()
+	r.observers = append(r.observers, observer)
+}
+
 // RecordRequest creates an evidence record from an enriched request and policy decision.
-// The evidence record is enqueued for async writing to storage.
+// The trace ID of the span in ctx, if any, is recorded with it. The evidence record is
+// enqueued for async writing to storage.
 //
 // This method returns immediately and does not block on storage writes.
 func (r *Recorder) RecordRequest(ctx context.Context, requestMeta *proxy.RequestMetadata, enrichedReq *processing.EnrichedRequest, policyDecision *engine.PolicyDecision) error {
@@ -112,6 +219,13 @@ func (r *Recorder) RecordRequest(ctx context.Context, requestMeta *proxy.Request
 
 	// Create evidence record
 	record := r.createEvidenceRecord(requestMeta, enrichedReq, policyDecision)
+	record.TraceID = tracing.TraceID(ctx)
+
+	if r.config.Capture != nil && r.config.Capture.Matches(requestMeta.APIKey, record) {
+		requestBody, _ := json.Marshal(enrichedReq.OriginalRequest)
+		r.capturedBodies.Store(record.ID, &bodies{request: requestBody})
+		record.ToolResults = r.extractToolResults(enrichedReq.OriginalRequest, true)
+	}
 
 	// Store in pending map (will be updated when response arrives)
 	r.pendingRecords.Store(enrichedReq.RequestID, record)
@@ -132,7 +246,12 @@ func (r *Recorder) RecordResponse(ctx context.Context, responseMeta *proxy.Respo
 	if !r.config.Enabled {
 		return nil
 	}
+	return r.completeRecord(responseMeta, enrichedResp, nil)
+}
 
+// completeRecord updates the pending record of a response with the
+// response data and update, if not nil, and enqueues it for writing.
+func (r *Recorder) completeRecord(responseMeta *proxy.ResponseMetadata, enrichedResp *processing.EnrichedResponse, update func(*evidence.EvidenceRecord)) error {
 	// Retrieve pending record
 	value, ok := r.pendingRecords.LoadAndDelete(enrichedResp.RequestID)
 	if !ok {
@@ -146,8 +265,40 @@ func (r *Recorder) RecordResponse(ctx context.Context, responseMeta *proxy.Respo
 
 	// Update record with response data
 	r.updateEvidenceWithResponse(record, responseMeta, enrichedResp)
+	if update != nil {
+		update(record)
+	}
+
+	if r.config.Sampler != nil && !r.config.Sampler.Keep(record) {
+		r.capturedBodies.Delete(record.ID)
+		r.sampledOut.Add(1)
+		r.logger.Debug("evidence record sampled out",
+			"record_id", record.ID,
+			"request_id", record.RequestID,
+		)
+		return nil
+	}
+
+	if value, ok := r.capturedBodies.Load(record.ID); ok && enrichedResp.OriginalResponse != nil {
+		value.(*bodies).response, _ = json.Marshal(enrichedResp.OriginalResponse)
+		record.ToolCalls = r.extractToolCalls(enrichedResp.OriginalResponse, true)
+	}
 
 	// Enqueue for async writing
+	select {
+	case r.recordChan <- record:
+		r.logger.Debug("evidence record enqueued for writing",
+			"record_id", record.ID,
+			"request_id", record.RequestID,
+		)
+		return nil
+	default:
+	}
+
+	if r.spillRecord(record) {
+		return nil
+	}
+
 	select {
 	case r.recordChan <- record:
 		r.logger.Debug("evidence record enqueued for writing",
@@ -155,6 +306,7 @@ func (r *Recorder) RecordResponse(ctx context.Context, responseMeta *proxy.Respo
 			"request_id", record.RequestID,
 		)
 	case <-time.After(r.config.WriteTimeout):
+		r.dropped.Add(1)
 		r.logger.Error("evidence record channel full, dropping record",
 			"record_id", record.ID,
 			"request_id", record.RequestID,
@@ -162,6 +314,7 @@ func (r *Recorder) RecordResponse(ctx context.Context, responseMeta *proxy.Respo
 		)
 		return evidence.NewRecorderError(record.ID, context.DeadlineExceeded)
 	case <-r.done:
+		r.dropped.Add(1)
 		r.logger.Warn("recorder shutting down, dropping record",
 			"record_id", record.ID,
 			"request_id", record.RequestID,
@@ -172,6 +325,43 @@ func (r *Recorder) RecordResponse(ctx context.Context, responseMeta *proxy.Respo
 	return nil
 }
 
+// spillRecord writes a record that does not fit in the channel to the spill
+// queue, if there is one, and reports whether it was spilled.
+func (r *Recorder) spillRecord(record *evidence.EvidenceRecord) bool {
+	if r.config.Spill == nil {
+		return false
+	}
+	if err := r.config.Spill.push(record); err != nil {
+		r.logger.Error("failed to spill evidence record",
+			"record_id", record.ID,
+			"request_id", record.RequestID,
+			"error", err,
+		)
+		return false
+	}
+	r.spilled.Add(1)
+	r.logger.Debug("evidence record spilled to disk",
+		"record_id", record.ID,
+		"request_id", record.RequestID,
+	)
+	return true
+}
+
+// Stats returns the counters of the write queue.
+func (r *Recorder) Stats() Stats {
+	stats := Stats{
+		Queued:     len(r.recordChan),
+		Spilled:    r.spilled.Load(),
+		Replayed:   r.replayed.Load(),
+		Dropped:    r.dropped.Load(),
+		SampledOut: r.sampledOut.Load(),
+	}
+	if r.config.Spill != nil {
+		stats.SpillPending = r.config.Spill.Len()
+	}
+	return stats
+}
+
 // Close gracefully shuts down the recorder by draining the async channel and
 // waiting for all pending writes to complete.
 func (r *Recorder) Close() error {
@@ -183,32 +373,94 @@ func (r *Recorder) Close() error {
 	// Wait for worker to finish draining channel
 	r.wg.Wait()
 
+	// Spilled records that were not replayed stay on disk for the next start
+	if r.config.Spill != nil {
+		if pending := r.config.Spill.Len(); pending > 0 {
+			r.logger.Warn("evidence records left in spill queue", "pending_count", pending)
+		}
+		if err := r.config.Spill.Close(); err != nil {
+			r.logger.Error("failed to close evidence spill queue", "error", err)
+		}
+	}
+
 	r.logger.Info("evidence recorder shut down complete")
 	return nil
 }
 
 // worker is the background goroutine that drains the evidence channel and
-// writes records to storage.
+// writes records to storage in batches.
 func (r *Recorder) worker() {
 	defer r.wg.Done()
 
+	batchSize := max(r.config.BatchSize, 1)
+	batch := make([]*evidence.EvidenceRecord, 0, batchSize)
+	flush := func() {
+		if len(batch) > 0 {
+			r.writeBatch(batch)
+			batch = make([]*evidence.EvidenceRecord, 0, batchSize)
+		}
+	}
+
+	// timer fires FlushInterval after the first record of a batch
+	var timer *time.Timer
+	var timeout <-chan time.Time
+	stopTimer := func() {
+		if timer != nil {
+			timer.Stop()
+			timer, timeout = nil, nil
+		}
+	}
+
+	// replay fires periodically while there is a spill queue
+	var replay <-chan time.Time
+	if r.config.Spill != nil {
+		ticker := time.NewTicker(spillReplayInterval)
+		defer ticker.Stop()
+		replay = ticker.C
+	}
+
 	for {
 		select {
 		case record := <-r.recordChan:
-			r.writeRecord(record)
+			batch = append(batch, record)
+			switch {
+			case len(batch) >= batchSize:
+				stopTimer()
+				flush()
+			case r.config.FlushInterval <= 0:
+				if len(r.recordChan) == 0 {
+					flush()
+				}
+			case timer == nil:
+				timer = time.NewTimer(r.config.FlushInterval)
+				timeout = timer.C
+			}
+
+		case <-timeout:
+			timer, timeout = nil, nil
+			flush()
+
+		case <-replay:
+			r.replaySpilled(batchSize)
 
 		case <-r.done:
+			stopTimer()
+
 			// Drain remaining records from channel before exit
 			r.logger.Info("draining evidence channel before shutdown",
-				"pending_count", len(r.recordChan),
+				"pending_count", len(r.recordChan)+len(batch),
 			)
 
 			for {
 				select {
 				case record := <-r.recordChan:
-					r.writeRecord(record)
+					batch = append(batch, record)
+					if len(batch) >= batchSize {
+						flush()
+					}
 				default:
 					// Channel is empty, we can exit
+					flush()
 					r.logger.Info("evidence channel drained")
 					return
 				}
@@ -217,6 +469,91 @@ func (r *Recorder) worker() {
 	}
 }
 
+// replaySpilled writes spilled records to storage in batches while the
+// channel is empty, so that live records are not delayed.
+func (r *Recorder) replaySpilled(batchSize int) {
+	for len(r.recordChan) == 0 {
+		select {
+		case <-r.done:
+			return
+		default:
+		}
+
+		records, skipped, err := r.config.Spill.pop(batchSize)
+		if skipped > 0 {
+			r.dropped.Add(int64(skipped))
+			r.logger.Error("skipped undecodable spilled evidence records", "count", skipped)
+		}
+		if err != nil {
+			r.logger.Error("failed to read evidence spill queue", "error", err)
+			return
+		}
+		if len(records) == 0 {
+			return
+		}
+		r.writeBatch(records)
+		r.replayed.Add(int64(len(records)))
+	}
+}
+
+// writeBatch writes records to storage in one batch if the storage
+// supports it, and one by one otherwise. If the batch fails, its records
+// are written one by one, so that one bad record does not lose the others.
+func (r *Recorder) writeBatch(records []*evidence.EvidenceRecord) {
+	batcher, ok := r.storage.(evidence.BatchStorer)
+	if !ok || len(records) == 1 {
+		for _, record := range records {
+			r.writeRecord(record)
+		}
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.config.WriteTimeout)
+	defer cancel()
+
+	start := time.Now()
+
+	for _, record := range records {
+		r.captureBodies(ctx, record)
+	}
+	if r.config.Chain != nil {
+		r.config.Chain.LinkAll(records)
+	}
+	if r.config.Signer != nil {
+		for _, record := range records {
+			r.config.Signer.Sign(record)
+		}
+	}
+
+	if err := batcher.StoreBatch(ctx, records); err != nil {
+		r.logger.Warn("failed to store evidence batch, storing records individually",
+			"record_count", len(records),
+			"error", err,
+		)
+		// writeRecord links and signs each record again
+		for _, record := range records {
+			r.writeRecord(record)
+		}
+		return
+	}
+
+	if r.config.Chain != nil {
+		for _, record := range records {
+			r.config.Chain.Commit(record)
+		}
+	}
+
+	duration := time.Since(start)
+	for _, record := range records {
+		r.recorded(record, duration)
+	}
+
+	r.logger.Debug("evidence batch recorded",
+		"record_count", len(records),
+		"duration_ms", duration.Milliseconds(),
+	)
+}
+
 // writeRecord writes a single evidence record to storage.
 func (r *Recorder) writeRecord(record *evidence.EvidenceRecord) {
 	ctx, cancel := context.WithTimeout(context.Background(), r.config.WriteTimeout)
@@ -224,6 +561,15 @@ func (r *Recorder) writeRecord(record *evidence.EvidenceRecord) {
 
 	start := time.Now()
 
+	r.captureBodies(ctx, record)
+
+	if r.config.Chain != nil {
+		r.config.Chain.Link(record)
+	}
+	if r.config.Signer != nil {
+		r.config.Signer.Sign(record)
+	}
+
 	err := r.storage.Store(ctx, record)
 	if err != nil {
 		r.logger.Error("failed to store evidence record",
@@ -234,7 +580,40 @@ func (r *Recorder) writeRecord(record *evidence.EvidenceRecord) {
 		return
 	}
 
-	duration := time.Since(start)
+	if r.config.Chain != nil {
+		r.config.Chain.Commit(record)
+	}
+
+	r.recorded(record, time.Since(start))
+}
+
+// captureBodies stores the captured bodies of a record, if any. Captured
+// bodies are stored before the record is linked and signed so that the
+// hash and signature cover their references.
+func (r *Recorder) captureBodies(ctx context.Context, record *evidence.EvidenceRecord) {
+	value, ok := r.capturedBodies.LoadAndDelete(record.ID)
+	if !ok {
+		return
+	}
+	captured := value.(*bodies)
+	if err := r.config.Capture.Capture(ctx, record, captured.request, captured.response); err != nil {
+		r.logger.Error("failed to capture evidence bodies",
+			"record_id", record.ID,
+			"request_id", record.RequestID,
+			"error", err,
+		)
+	}
+}
+
+// recorded notifies the observers of a stored record. duration is the time
+// it took to write the record, or its batch.
+func (r *Recorder) recorded(record *evidence.EvidenceRecord, duration time.Duration) {
+	r.observersMu.RLock()
+	observers := r.observers
+	r.observersMu.RUnlock()
+	for _, observer := range observers {
+		observer.ObserveRecord(record)
+	}
 
 	r.logger.Info("evidence recorded",
 		"record_id", record.ID,
@@ -253,6 +632,13 @@ func (r *Recorder) writeRecord(record *evidence.EvidenceRecord) {
 	}
 }
 
+// bodies holds the full request and response bodies of a record selected
+// for capture.
+type bodies struct {
+	request  []byte
+	response []byte
+}
+
 // createEvidenceRecord creates an evidence record from enriched request and policy decision.
 func (r *Recorder) createEvidenceRecord(requestMeta *proxy.RequestMetadata, enrichedReq *processing.EnrichedRequest, policyDecision *engine.PolicyDecision) *evidence.EvidenceRecord {
 	now := time.Now()
@@ -292,6 +678,7 @@ func (r *Recorder) createEvidenceRecord(requestMeta *proxy.RequestMetadata, enri
 
 	// Extract tools used
 	record.ToolsUsed = r.extractTools(enrichedReq.OriginalRequest)
+	record.ToolResults = r.extractToolResults(enrichedReq.OriginalRequest, false)
 
 	// Extract token estimates
 	if enrichedReq.TokenEstimate != nil {
@@ -309,11 +696,20 @@ func (r *Recorder) createEvidenceRecord(requestMeta *proxy.RequestMetadata, enri
 		record.PIITypes = enrichedReq.ContentAnalysis.PIIDetection.PIITypes
 	}
 
+	// Extract data classification and findings
+	if enrichedReq.ContentAnalysis != nil {
+		raiseClassification(record, enrichedReq.ContentAnalysis.Classification)
+		record.Findings = appendFindings(record.Findings, "request", enrichedReq.ContentAnalysis.Findings)
+	}
+
 	// Extract policy decision
 	r.extractPolicyDecision(record, policyDecision)
 
 	// Extract user/API key
 	record.UserID = requestMeta.UserID
+	record.TeamID = requestMeta.TeamID
+	record.CostTag = requestMeta.CostTag
+	record.LimitBypass = requestMeta.LimitBypass
 	if r.config.RedactAPIKeys {
 		record.APIKey = RedactAPIKey(requestMeta.APIKey)
 	} else {
@@ -348,6 +744,14 @@ func (r *Recorder) updateEvidenceWithResponse(record *evidence.EvidenceRecord, r
 	if enrichedResp.OriginalResponse != nil {
 		record.ResponseContent = TruncateString(enrichedResp.OriginalResponse.Content, r.config.MaxFieldLength)
 		record.FinishReason = enrichedResp.OriginalResponse.FinishReason
+		record.ToolCalls = r.extractToolCalls(enrichedResp.OriginalResponse, false)
+	}
+
+	// Extract what the response disclosed
+	record.ResponseAnalysis = extractResponseAnalysis(enrichedResp.ContentAnalysis)
+	if enrichedResp.ContentAnalysis != nil {
+		raiseClassification(record, enrichedResp.ContentAnalysis.Classification)
+		record.Findings = appendFindings(record.Findings, "response", enrichedResp.ContentAnalysis.Findings)
 	}
 
 	// Extract actual token usage
@@ -375,6 +779,60 @@ func (r *Recorder) updateEvidenceWithResponse(record *evidence.EvidenceRecord, r
 	}
 }
 
+// extractResponseAnalysis returns the PII, secret and sensitive content
+// types found in a response, or nil if none were found.
+func extractResponseAnalysis(analysis *processing.ContentAnalysis) *evidence.ResponseAnalysisRecord {
+	if analysis == nil {
+		return nil
+	}
+
+	record := &evidence.ResponseAnalysisRecord{}
+	if analysis.PIIDetection != nil && analysis.PIIDetection.HasPII {
+		record.PIITypes = analysis.PIIDetection.PIITypes
+	}
+	if analysis.Secrets != nil && analysis.Secrets.Detected {
+		record.SecretTypes = analysis.Secrets.Types
+	}
+	if analysis.SensitiveContent != nil && analysis.SensitiveContent.HasSensitiveContent {
+		record.SensitiveCategories = analysis.SensitiveContent.Categories
+	}
+
+	if len(record.PIITypes) == 0 && len(record.SecretTypes) == 0 && len(record.SensitiveCategories) == 0 {
+		return nil
+	}
+	return record
+}
+
+// appendFindings appends the findings of the analysis of a stage to records.
+func appendFindings(records []evidence.FindingRecord, stage string, findings []processing.Finding) []evidence.FindingRecord {
+	for _, finding := range findings {
+		records = append(records, evidence.FindingRecord{
+			Stage:      stage,
+			Detector:   finding.Detector,
+			Type:       finding.Type,
+			Source:     finding.Source,
+			Rule:       finding.Rule,
+			Confidence: finding.Confidence,
+			Start:      finding.Start,
+			End:        finding.End,
+		})
+	}
+	return records
+}
+
+// raiseClassification sets the data classification of record to
+// classification if it is higher, so the record keeps the highest label of
+// the request and the response.
+func raiseClassification(record *evidence.EvidenceRecord, classification *processing.Classification) {
+	if classification == nil {
+		return
+	}
+	if record.DataClassification == "" || classification.Level > record.DataClassificationLevel {
+		record.DataClassification = classification.Label
+		record.DataClassificationLevel = classification.Level
+	}
+}
+
 // extractHeaders extracts selected headers from the request metadata.
 func (r *Recorder) extractHeaders(requestMeta *proxy.RequestMetadata) map[string]string {
 	// Only store selected headers (user-agent)
@@ -416,6 +874,57 @@ func (r *Recorder) extractTools(req *types.ChatCompletionRequest) []string {
 	return tools
 }
 
+// extractToolResults extracts the tool results sent with the request. The
+// content is kept in full only if withContent is set; the function name is
+// taken from the assistant message of the call, if present.
+func (r *Recorder) extractToolResults(req *types.ChatCompletionRequest, withContent bool) []evidence.ToolResultRecord {
+	names := make(map[string]string)
+	var results []evidence.ToolResultRecord
+	for _, msg := range req.Messages {
+		for _, call := range msg.ToolCalls {
+			names[call.ID] = call.Function.Name
+		}
+		if msg.Role != "tool" {
+			continue
+		}
+
+		var content []byte
+		if str, ok := msg.Content.(string); ok {
+			content = []byte(str)
+		} else if msg.Content != nil {
+			content, _ = json.Marshal(msg.Content)
+		}
+		result := evidence.ToolResultRecord{
+			ToolCallID:  msg.ToolCallID,
+			Name:        names[msg.ToolCallID],
+			ContentHash: HashContent(content),
+		}
+		if withContent {
+			result.Content = string(content)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// extractToolCalls extracts the tool calls made by the response. The
+// arguments are kept in full only if withArguments is set.
+func (r *Recorder) extractToolCalls(resp *providers.CompletionResponse, withArguments bool) []evidence.ToolCallRecord {
+	var calls []evidence.ToolCallRecord
+	for _, call := range resp.ToolCalls {
+		record := evidence.ToolCallRecord{
+			ID:            call.ID,
+			Name:          call.Function.Name,
+			ArgumentsHash: HashContent([]byte(call.Function.Arguments)),
+		}
+		if withArguments {
+			record.Arguments = call.Function.Arguments
+		}
+		calls = append(calls, record)
+	}
+	return calls
+}
+
 // extractPolicyDecision extracts policy decision data from the engine decision.
 func (r *Recorder) extractPolicyDecision(record *evidence.EvidenceRecord, policyDecision *engine.PolicyDecision) {
 	if policyDecision == nil {
@@ -424,6 +933,7 @@ func (r *Recorder) extractPolicyDecision(record *evidence.EvidenceRecord, policy
 	}
 
 	record.PolicyDecision = string(policyDecision.Action)
+	record.DecisionID = policyDecision.ID
 	record.BlockReason = policyDecision.BlockReason
 
 	// Convert matched rules
@@ -2,10 +2,19 @@ package recorder
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"go.opentelemetry.io/otel/trace"
+
 	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/evidence/capture"
+	"mercator-hq/jupiter/pkg/evidence/integrity"
 	"mercator-hq/jupiter/pkg/evidence/storage"
 	"mercator-hq/jupiter/pkg/policy/engine"
 	"mercator-hq/jupiter/pkg/processing"
@@ -581,3 +590,368 @@ func BenchmarkRecorder_EndToEnd(b *testing.B) {
 		_ = recorder.RecordResponse(ctx, responseMeta, enrichedResp)
 	}
 }
+
+// TestRecorder_Capture tests full-body capture of selected requests.
+func TestRecorder_Capture(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	blobs, err := capture.NewFileBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileBlobStore() failed: %v", err)
+	}
+
+	config := DefaultConfig()
+	config.AsyncBuffer = 10
+	config.Capture = capture.NewCapturer(blobs, &capture.Policy{Models: []string{"gpt-4*"}})
+
+	recorder := NewRecorder(store, config)
+	ctx := context.Background()
+
+	for i, model := range []string{"gpt-4", "gpt-3.5-turbo"} {
+		requestID := fmt.Sprintf("req-%d", i)
+		_ = recorder.RecordRequest(ctx,
+			&proxy.RequestMetadata{Timestamp: time.Now(), APIKey: "sk-test"},
+			&processing.EnrichedRequest{
+				RequestID:       requestID,
+				OriginalRequest: &types.ChatCompletionRequest{Model: model},
+			},
+			&engine.PolicyDecision{Action: engine.ActionAllow},
+		)
+		_ = recorder.RecordResponse(ctx,
+			&proxy.ResponseMetadata{Timestamp: time.Now(), StatusCode: 200},
+			&processing.EnrichedResponse{
+				RequestID:        requestID,
+				OriginalResponse: &providers.CompletionResponse{Model: model, Content: strings.Repeat("x", 1000)},
+			},
+		)
+	}
+	recorder.Close()
+
+	results, err := store.Query(ctx, &evidence.Query{})
+	if err != nil {
+		t.Fatalf("Query() failed: %v", err)
+	}
+	for _, record := range results {
+		captured := record.RequestBodyRef != "" && record.ResponseBodyRef != ""
+		if captured != (record.Model == "gpt-4") {
+			t.Errorf("model %s: captured = %v", record.Model, captured)
+		}
+		if !captured {
+			continue
+		}
+		body, err := blobs.Get(ctx, record.ResponseBodyRef)
+		if err != nil {
+			t.Fatalf("Get() failed: %v", err)
+		}
+		if !strings.Contains(string(body), strings.Repeat("x", 1000)) {
+			t.Error("captured response body is not complete")
+		}
+	}
+}
+
+// TestRecorder_ToolCalls tests recording tool calls and tool results, with
+// full arguments and results only for captured records.
+func TestRecorder_ToolCalls(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	blobs, err := capture.NewFileBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileBlobStore() failed: %v", err)
+	}
+
+	config := DefaultConfig()
+	config.AsyncBuffer = 10
+	config.Capture = capture.NewCapturer(blobs, &capture.Policy{Models: []string{"gpt-4"}})
+
+	recorder := NewRecorder(store, config)
+	ctx := context.Background()
+
+	for _, model := range []string{"gpt-4", "gpt-3.5-turbo"} {
+		_ = recorder.RecordRequest(ctx,
+			&proxy.RequestMetadata{Timestamp: time.Now()},
+			&processing.EnrichedRequest{
+				RequestID: model,
+				OriginalRequest: &types.ChatCompletionRequest{
+					Model: model,
+					Messages: []types.Message{
+						{Role: "user", Content: "Weather in Paris?"},
+						{Role: "assistant", ToolCalls: []types.ToolCall{{ID: "call-1", Type: "function", Function: types.FunctionCall{Name: "weather", Arguments: `{"city":"Paris"}`}}}},
+						{Role: "tool", ToolCallID: "call-1", Content: "18C, cloudy"},
+					},
+				},
+			},
+			&engine.PolicyDecision{Action: engine.ActionAllow},
+		)
+		_ = recorder.RecordResponse(ctx,
+			&proxy.ResponseMetadata{Timestamp: time.Now(), StatusCode: 200},
+			&processing.EnrichedResponse{
+				RequestID: model,
+				OriginalResponse: &providers.CompletionResponse{
+					Model:        model,
+					FinishReason: "tool_calls",
+					ToolCalls:    []providers.ToolCall{{ID: "call-2", Type: "function", Function: providers.FunctionCall{Name: "send_email", Arguments: `{"to":"bob"}`}}},
+				},
+			},
+		)
+	}
+	recorder.Close()
+
+	for _, model := range []string{"gpt-4", "gpt-3.5-turbo"} {
+		captured := model == "gpt-4"
+		results, err := store.Query(ctx, &evidence.Query{Model: model, ToolName: "send_email"})
+		if err != nil || len(results) != 1 {
+			t.Fatalf("%s: Query() = %d records, %v; want 1", model, len(results), err)
+		}
+		record := results[0]
+
+		wantCall := evidence.ToolCallRecord{ID: "call-2", Name: "send_email", ArgumentsHash: HashString(`{"to":"bob"}`)}
+		wantResult := evidence.ToolResultRecord{ToolCallID: "call-1", Name: "weather", ContentHash: HashString("18C, cloudy")}
+		if captured {
+			wantCall.Arguments = `{"to":"bob"}`
+			wantResult.Content = "18C, cloudy"
+		}
+		if len(record.ToolCalls) != 1 || record.ToolCalls[0] != wantCall {
+			t.Errorf("%s: ToolCalls = %+v, want %+v", model, record.ToolCalls, wantCall)
+		}
+		if len(record.ToolResults) != 1 || record.ToolResults[0] != wantResult {
+			t.Errorf("%s: ToolResults = %+v, want %+v", model, record.ToolResults, wantResult)
+		}
+	}
+
+	if results, _ := store.Query(ctx, &evidence.Query{ToolName: "weather"}); len(results) != 0 {
+		t.Errorf("Query(ToolName: weather) = %d records, want 0", len(results))
+	}
+}
+
+// TestRecorder_DataClassification tests recording the highest data
+// classification of the request and the response.
+func TestRecorder_DataClassification(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	config := DefaultConfig()
+	config.AsyncBuffer = 10
+	recorder := NewRecorder(store, config)
+	ctx := context.Background()
+
+	internal := &processing.Classification{Label: "internal", Level: 1}
+	restricted := &processing.Classification{Label: "restricted", Level: 3, Reasons: []string{"secret:github_token"}}
+	for _, tt := range []struct {
+		requestID string
+		request   *processing.Classification
+		response  *processing.Classification
+	}{
+		{"leaked", internal, restricted},
+		{"pasted", restricted, internal},
+	} {
+		_ = recorder.RecordRequest(ctx,
+			&proxy.RequestMetadata{Timestamp: time.Now()},
+			&processing.EnrichedRequest{
+				RequestID:       tt.requestID,
+				OriginalRequest: &types.ChatCompletionRequest{Model: "gpt-4"},
+				ContentAnalysis: &processing.ContentAnalysis{Classification: tt.request},
+			},
+			&engine.PolicyDecision{Action: engine.ActionAllow},
+		)
+		_ = recorder.RecordResponse(ctx,
+			&proxy.ResponseMetadata{Timestamp: time.Now(), StatusCode: 200},
+			&processing.EnrichedResponse{
+				RequestID:        tt.requestID,
+				OriginalResponse: &providers.CompletionResponse{Model: "gpt-4"},
+				ContentAnalysis:  &processing.ContentAnalysis{Classification: tt.response},
+			},
+		)
+	}
+	recorder.Close()
+
+	records, err := store.Query(ctx, &evidence.Query{})
+	if err != nil || len(records) != 2 {
+		t.Fatalf("Query() = %d records, %v; want 2", len(records), err)
+	}
+	for _, record := range records {
+		if record.DataClassification != "restricted" || record.DataClassificationLevel != 3 {
+			t.Errorf("%s: classification = %q/%d, want restricted/3", record.RequestID, record.DataClassification, record.DataClassificationLevel)
+		}
+	}
+}
+
+// TestRecorder_Findings tests recording the findings of the request and
+// response analysis.
+func TestRecorder_Findings(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	recorder := NewRecorder(store, DefaultConfig())
+	ctx := context.Background()
+
+	_ = recorder.RecordRequest(ctx,
+		&proxy.RequestMetadata{Timestamp: time.Now()},
+		&processing.EnrichedRequest{
+			RequestID:       "req-findings",
+			OriginalRequest: &types.ChatCompletionRequest{Model: "gpt-4"},
+			ContentAnalysis: &processing.ContentAnalysis{Findings: []processing.Finding{
+				{Detector: "pii", Type: "email", Source: "pattern", Rule: "email", Confidence: 1, Start: 5, End: 21},
+			}},
+		},
+		&engine.PolicyDecision{Action: engine.ActionAllow},
+	)
+	_ = recorder.RecordResponse(ctx,
+		&proxy.ResponseMetadata{Timestamp: time.Now(), StatusCode: 200},
+		&processing.EnrichedResponse{
+			RequestID:        "req-findings",
+			OriginalResponse: &providers.CompletionResponse{Model: "gpt-4"},
+			ContentAnalysis: &processing.ContentAnalysis{Findings: []processing.Finding{
+				{Detector: "secrets", Type: "aws_access_key", Source: "pattern", Rule: "aws_access_key", Confidence: 1, Start: 0, End: 20},
+			}},
+		},
+	)
+	recorder.Close()
+
+	records, err := store.Query(ctx, &evidence.Query{})
+	if err != nil || len(records) != 1 {
+		t.Fatalf("Query() = %d records, %v; want 1", len(records), err)
+	}
+
+	want := []evidence.FindingRecord{
+		{Stage: "request", Detector: "pii", Type: "email", Source: "pattern", Rule: "email", Confidence: 1, Start: 5, End: 21},
+		{Stage: "response", Detector: "secrets", Type: "aws_access_key", Source: "pattern", Rule: "aws_access_key", Confidence: 1, Start: 0, End: 20},
+	}
+	if !reflect.DeepEqual(records[0].Findings, want) {
+		t.Errorf("Findings = %+v, want %+v", records[0].Findings, want)
+	}
+}
+
+// TestRecorder_CorrelationIDs tests recording the trace ID of the request
+// span and the policy decision ID.
+func TestRecorder_CorrelationIDs(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	recorder := NewRecorder(store, DefaultConfig())
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceID,
+		SpanID:     spanID,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	_ = recorder.RecordRequest(ctx,
+		&proxy.RequestMetadata{Timestamp: time.Now()},
+		&processing.EnrichedRequest{RequestID: "req-1", OriginalRequest: &types.ChatCompletionRequest{Model: "gpt-4"}},
+		&engine.PolicyDecision{ID: "decision-1", Action: engine.ActionAllow},
+	)
+	_ = recorder.RecordResponse(ctx,
+		&proxy.ResponseMetadata{Timestamp: time.Now(), StatusCode: 200},
+		&processing.EnrichedResponse{RequestID: "req-1", OriginalResponse: &providers.CompletionResponse{Model: "gpt-4"}},
+	)
+	recorder.Close()
+
+	results, err := store.Query(context.Background(), &evidence.Query{TraceID: traceID.String()})
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Query(TraceID) = %d records, %v; want 1", len(results), err)
+	}
+	if results[0].DecisionID != "decision-1" {
+		t.Errorf("DecisionID = %q, want decision-1", results[0].DecisionID)
+	}
+}
+
+// batchStorage is a memory storage implementing evidence.BatchStorer. It
+// records the size of every stored batch and fails batches while fail is
+// set.
+type batchStorage struct {
+	*storage.MemoryStorage
+
+	mu      sync.Mutex
+	batches []int
+	fail    bool
+}
+
+func (s *batchStorage) StoreBatch(ctx context.Context, records []*evidence.EvidenceRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail {
+		return errors.New("batch failed")
+	}
+	s.batches = append(s.batches, len(records))
+	for _, record := range records {
+		if err := s.Store(ctx, record); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TestRecorder_BatchedWrites tests writing records in batches.
+func TestRecorder_BatchedWrites(t *testing.T) {
+	store := &batchStorage{MemoryStorage: storage.NewMemoryStorage()}
+	config := DefaultConfig()
+	config.BatchSize = 4
+	config.FlushInterval = time.Hour // batches are only written when full or on Close
+	config.Chain = integrity.NewChain("batch-chain")
+
+	recorder := NewRecorder(store, config)
+	for i := 0; i < 10; i++ {
+		recorder.recordChan <- &evidence.EvidenceRecord{ID: fmt.Sprintf("rec-%d", i), RequestID: fmt.Sprintf("req-%d", i)}
+	}
+	recorder.Close()
+
+	if fmt.Sprint(store.batches) != "[4 4 2]" {
+		t.Errorf("Expected batches [4 4 2], got %v", store.batches)
+	}
+
+	// Records of a batch are chained to each other
+	prev := ""
+	for i := 0; i < 10; i++ {
+		record := store.GetByID(fmt.Sprintf("rec-%d", i))
+		if record == nil {
+			t.Fatalf("Record %d not stored", i)
+		}
+		if record.Sequence != int64(i+1) || record.PrevHash != prev {
+			t.Errorf("Record %d chained as %d/%q", i, record.Sequence, record.PrevHash)
+		}
+		prev = record.RecordHash
+	}
+	if sequence, _ := config.Chain.Head(); sequence != 10 {
+		t.Errorf("Expected chain head at 10, got %d", sequence)
+	}
+}
+
+// TestRecorder_BatchFlushInterval tests that partial batches are written
+// after the flush interval.
+func TestRecorder_BatchFlushInterval(t *testing.T) {
+	store := &batchStorage{MemoryStorage: storage.NewMemoryStorage()}
+	config := DefaultConfig()
+	config.BatchSize = 100
+	config.FlushInterval = 20 * time.Millisecond
+
+	recorder := NewRecorder(store, config)
+	defer recorder.Close()
+	for i := 0; i < 3; i++ {
+		recorder.recordChan <- &evidence.EvidenceRecord{ID: fmt.Sprintf("rec-%d", i), RequestID: fmt.Sprintf("req-%d", i)}
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for store.Size() < 3 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if store.Size() != 3 {
+		t.Errorf("Expected 3 records written after the flush interval, got %d", store.Size())
+	}
+}
+
+// TestRecorder_BatchFailureFallsBack tests that the records of a failed
+// batch are written one by one.
+func TestRecorder_BatchFailureFallsBack(t *testing.T) {
+	store := &batchStorage{MemoryStorage: storage.NewMemoryStorage(), fail: true}
+	config := DefaultConfig()
+	config.BatchSize = 5
+	config.FlushInterval = time.Hour
+	config.Chain = integrity.NewChain("batch-chain")
+
+	recorder := NewRecorder(store, config)
+	for i := 0; i < 5; i++ {
+		recorder.recordChan <- &evidence.EvidenceRecord{ID: fmt.Sprintf("rec-%d", i), RequestID: fmt.Sprintf("req-%d", i)}
+	}
+	recorder.Close()
+
+	if store.Size() != 5 {
+		t.Errorf("Expected 5 records written individually, got %d", store.Size())
+	}
+	if sequence, _ := config.Chain.Head(); sequence != 5 {
+		t.Errorf("Expected chain head at 5, got %d", sequence)
+	}
+}
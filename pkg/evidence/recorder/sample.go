@@ -0,0 +1,135 @@
+package recorder
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"path"
+	"slices"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+// SampleRule sets the fraction of matching records that are stored. A
+// record matches if it meets every criterion set; a rule without criteria
+// matches every record.
+type SampleRule struct {
+	// Decisions are policy decisions to match (e.g., "block"). Records
+	// without a decision match "allow".
+	Decisions []string
+
+	// Errors matches failed requests: records with an error or an HTTP
+	// status of 400 or above.
+	Errors bool
+
+	// Paths are request path patterns (path.Match syntax, e.g. "/health*").
+	Paths []string
+
+	// Models are model name patterns (path.Match syntax, e.g. "gpt-4*").
+	Models []string
+
+	// MaxCost matches records costing at most this amount in USD (actual
+	// cost, or estimated if the request did not complete). Zero means no
+	// limit.
+	MaxCost float64
+
+	// Rate is the fraction of matching records stored, from 0 (none) to 1
+	// (all).
+	Rate float64
+}
+
+// Sampler decides which evidence records are stored, to control storage
+// growth on high-volume deployments. The first rule matching a record sets
+// its sample rate; records matching no rule use the default rate.
+//
+// Sampling is deterministic: whether a record is kept depends only on its
+// request ID and rate, so every replica keeps the same requests.
+type Sampler struct {
+	rules       []SampleRule
+	defaultRate float64
+}
+
+// NewSampler creates a sampler, validating its rules.
+func NewSampler(rules []SampleRule, defaultRate float64) (*Sampler, error) {
+	if defaultRate < 0 || defaultRate > 1 {
+		return nil, fmt.Errorf("default sample rate %v must be between 0 and 1", defaultRate)
+	}
+	for i, rule := range rules {
+		if rule.Rate < 0 || rule.Rate > 1 {
+			return nil, fmt.Errorf("sample rule %d: rate %v must be between 0 and 1", i, rule.Rate)
+		}
+		for _, pattern := range slices.Concat(rule.Paths, rule.Models) {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("sample rule %d: invalid pattern %q: %w", i, pattern, err)
+			}
+		}
+	}
+	return &Sampler{rules: rules, defaultRate: defaultRate}, nil
+}
+
+// Rate returns the sample rate of a complete record.
+func (s *Sampler) Rate(record *evidence.EvidenceRecord) float64 {
+	for i := range s.rules {
+		if s.rules[i].matches(record) {
+			return s.rules[i].Rate
+		}
+	}
+	return s.defaultRate
+}
+
+// Keep reports whether a complete record is stored.
+func (s *Sampler) Keep(record *evidence.EvidenceRecord) bool {
+	rate := s.Rate(record)
+	switch {
+	case rate >= 1:
+		return true
+	case rate <= 0:
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(record.RequestID))
+	return float64(binary.BigEndian.Uint64(sum[:8]))/math.MaxUint64 < rate
+}
+
+// matches reports whether a record meets the rule's criteria.
+func (r *SampleRule) matches(record *evidence.EvidenceRecord) bool {
+	if len(r.Decisions) > 0 {
+		decision := record.PolicyDecision
+		if decision == "" {
+			decision = "allow"
+		}
+		if !slices.Contains(r.Decisions, decision) {
+			return false
+		}
+	}
+	if r.Errors && record.Error == "" && record.ResponseStatus < 400 {
+		return false
+	}
+	if len(r.Paths) > 0 && !matchAny(r.Paths, record.RequestPath) {
+		return false
+	}
+	if len(r.Models) > 0 && !matchAny(r.Models, record.Model) {
+		return false
+	}
+	if r.MaxCost > 0 {
+		cost := record.ActualCost
+		if cost == 0 {
+			cost = record.EstimatedCost
+		}
+		if cost > r.MaxCost {
+			return false
+		}
+	}
+	return true
+}
+
+// matchAny reports whether name matches any of the path.Match patterns.
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,113 @@
+package recorder
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/evidence/storage"
+	"mercator-hq/jupiter/pkg/policy/engine"
+	"mercator-hq/jupiter/pkg/processing"
+	"mercator-hq/jupiter/pkg/providers"
+	"mercator-hq/jupiter/pkg/proxy"
+	"mercator-hq/jupiter/pkg/proxy/types"
+)
+
+func TestSampler_Rate(t *testing.T) {
+	sampler, err := NewSampler([]SampleRule{
+		{Paths: []string{"/health*"}, Rate: 0},
+		{Decisions: []string{"block"}, Rate: 1},
+		{Errors: true, Rate: 1},
+		{MaxCost: 0.01, Rate: 0.1},
+	}, 0.5)
+	if err != nil {
+		t.Fatalf("NewSampler() error = %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		record evidence.EvidenceRecord
+		want   float64
+	}{
+		{"health probe", evidence.EvidenceRecord{RequestPath: "/healthz", PolicyDecision: "block"}, 0},
+		{"blocked", evidence.EvidenceRecord{PolicyDecision: "block", ActualCost: 0.001}, 1},
+		{"error", evidence.EvidenceRecord{Error: "upstream timeout"}, 1},
+		{"error status", evidence.EvidenceRecord{ResponseStatus: 429}, 1},
+		{"cheap", evidence.EvidenceRecord{ActualCost: 0.002}, 0.1},
+		{"estimated cheap", evidence.EvidenceRecord{EstimatedCost: 0.002}, 0.1},
+		{"expensive", evidence.EvidenceRecord{ActualCost: 0.5}, 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := sampler.Rate(&tt.record); got != tt.want {
+				t.Errorf("Rate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	for _, rules := range [][]SampleRule{{{Rate: 1.5}}, {{Paths: []string{"[health"}, Rate: 0}}} {
+		if _, err := NewSampler(rules, 1); err == nil {
+			t.Errorf("NewSampler(%+v) succeeded, want error", rules)
+		}
+	}
+}
+
+func TestSampler_KeepIsDeterministic(t *testing.T) {
+	sampler, err := NewSampler(nil, 0.25)
+	if err != nil {
+		t.Fatalf("NewSampler() error = %v", err)
+	}
+
+	kept := 0
+	for i := 0; i < 10000; i++ {
+		record := &evidence.EvidenceRecord{RequestID: fmt.Sprintf("req-%d", i)}
+		keep := sampler.Keep(record)
+		if keep != sampler.Keep(record) {
+			t.Fatalf("Keep(%s) is not deterministic", record.RequestID)
+		}
+		if keep {
+			kept++
+		}
+	}
+	if kept < 2300 || kept > 2700 {
+		t.Errorf("kept %d of 10000 records at rate 0.25", kept)
+	}
+}
+
+func TestRecorder_Sampling(t *testing.T) {
+	sampler, err := NewSampler([]SampleRule{{Decisions: []string{"block"}, Rate: 1}}, 0)
+	if err != nil {
+		t.Fatalf("NewSampler() error = %v", err)
+	}
+	config := DefaultConfig()
+	config.Sampler = sampler
+	store := storage.NewMemoryStorage()
+	recorder := NewRecorder(store, config)
+
+	ctx := context.Background()
+	for requestID, action := range map[string]engine.PolicyAction{"req-allow": engine.ActionAllow, "req-block": engine.ActionBlock} {
+		_ = recorder.RecordRequest(ctx,
+			&proxy.RequestMetadata{Timestamp: time.Now()},
+			&processing.EnrichedRequest{RequestID: requestID, OriginalRequest: &types.ChatCompletionRequest{Model: "gpt-4"}},
+			&engine.PolicyDecision{Action: action},
+		)
+		_ = recorder.RecordResponse(ctx,
+			&proxy.ResponseMetadata{Timestamp: time.Now(), StatusCode: 200},
+			&processing.EnrichedResponse{RequestID: requestID, OriginalResponse: &providers.CompletionResponse{Model: "gpt-4"}},
+		)
+	}
+	recorder.Close()
+
+	records, err := store.Query(ctx, &evidence.Query{})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(records) != 1 || records[0].RequestID != "req-block" {
+		t.Errorf("stored %d records, want only req-block", len(records))
+	}
+	if got := recorder.Stats().SampledOut; got != 1 {
+		t.Errorf("SampledOut = %d, want 1", got)
+	}
+}
@@ -0,0 +1,142 @@
+package recorder
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+// errSpillFull is returned by SpillQueue.push when the queue file has
+// reached its maximum size.
+var errSpillFull = errors.New("spill queue full")
+
+// SpillQueue is a FIFO queue of evidence records in a JSON Lines file. It
+// holds the records that did not fit in the recorder's channel until the
+// writer catches up. Records are appended at the end of the file and read
+// from an offset; the file is truncated once every record has been read.
+//
+// The read offset is not persisted, so records read shortly before a crash
+// are read again after a restart.
+type SpillQueue struct {
+	mu      sync.Mutex
+	file    *os.File
+	maxSize int64
+
+	offset  int64 // read offset
+	size    int64 // write offset
+	pending int64 // records between offset and size
+}
+
+// OpenSpillQueue opens the queue file at path, creating it if needed.
+// Records left in the file by a previous process are queued. A record only
+// partially written before a crash is discarded. maxSize limits the size of
+// the file; 0 means no limit.
+func OpenSpillQueue(path string, maxSize int64) (*SpillQueue, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create spill directory: %w", err)
+	}
+	// Spilled records contain prompts, so the file is private
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open spill file: %w", err)
+	}
+
+	q := &SpillQueue{file: file, maxSize: maxSize}
+	reader := bufio.NewReader(file)
+	for {
+		line, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			file.Close()
+			return nil, fmt.Errorf("failed to read spill file: %w", err)
+		}
+		q.size += int64(len(line))
+		q.pending++
+	}
+	if err := file.Truncate(q.size); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to truncate spill file: %w", err)
+	}
+	return q, nil
+}
+
+// push appends a record to the queue.
+func (q *SpillQueue) push(record *evidence.EvidenceRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.maxSize > 0 && q.size+int64(len(line)) > q.maxSize {
+		return errSpillFull
+	}
+	if _, err := q.file.WriteAt(line, q.size); err != nil {
+		return err
+	}
+	q.size += int64(len(line))
+	q.pending++
+	return nil
+}
+
+// pop removes and returns up to n records from the front of the queue.
+// Records that cannot be decoded are skipped and counted in skipped.
+func (q *SpillQueue) pop(n int) (records []*evidence.EvidenceRecord, skipped int, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	reader := bufio.NewReader(io.NewSectionReader(q.file, q.offset, q.size-q.offset))
+	for len(records)+skipped < n && q.offset < q.size {
+		line, err := reader.ReadBytes('\n')
+		if err != nil {
+			return records, skipped, fmt.Errorf("failed to read spill file: %w", err)
+		}
+		q.offset += int64(len(line))
+		q.pending--
+
+		var record evidence.EvidenceRecord
+		if err := json.Unmarshal(bytes.TrimSpace(line), &record); err != nil {
+			skipped++
+			continue
+		}
+		records = append(records, &record)
+	}
+
+	if q.offset == q.size && q.size > 0 {
+		if err := q.file.Truncate(0); err != nil {
+			return records, skipped, fmt.Errorf("failed to truncate spill file: %w", err)
+		}
+		q.offset, q.size = 0, 0
+	}
+	return records, skipped, nil
+}
+
+// Len returns the number of queued records.
+func (q *SpillQueue) Len() int64 {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.pending
+}
+
+// Close syncs and closes the queue file. Queued records stay in the file.
+func (q *SpillQueue) Close() error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if err := q.file.Sync(); err != nil {
+		q.file.Close()
+		return err
+	}
+	return q.file.Close()
+}
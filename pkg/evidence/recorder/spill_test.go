@@ -0,0 +1,145 @@
+package recorder
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/evidence/storage"
+	"mercator-hq/jupiter/pkg/policy/engine"
+	"mercator-hq/jupiter/pkg/processing"
+	"mercator-hq/jupiter/pkg/providers"
+	"mercator-hq/jupiter/pkg/proxy"
+	"mercator-hq/jupiter/pkg/proxy/types"
+)
+
+func TestSpillQueue_PushPop(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spill.jsonl")
+	queue, err := OpenSpillQueue(path, 0)
+	if err != nil {
+		t.Fatalf("OpenSpillQueue() error = %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		if err := queue.push(&evidence.EvidenceRecord{ID: fmt.Sprintf("rec-%d", i)}); err != nil {
+			t.Fatalf("push() error = %v", err)
+		}
+	}
+
+	records, skipped, err := queue.pop(3)
+	if err != nil || skipped != 0 || len(records) != 3 || records[0].ID != "rec-0" {
+		t.Fatalf("pop(3) = %d records, %d skipped, %v", len(records), skipped, err)
+	}
+	if queue.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", queue.Len())
+	}
+	queue.Close()
+
+	// The read offset is not persisted, and a partially written record is
+	// discarded
+	f, _ := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0)
+	f.WriteString(`{"id":"partial`)
+	f.Close()
+
+	queue, err = OpenSpillQueue(path, 0)
+	if err != nil {
+		t.Fatalf("OpenSpillQueue() error = %v", err)
+	}
+	defer queue.Close()
+	if queue.Len() != 5 {
+		t.Fatalf("Len() after reopening = %d, want 5", queue.Len())
+	}
+	records, _, _ = queue.pop(10)
+	if len(records) != 5 || records[4].ID != "rec-4" {
+		t.Errorf("pop(10) = %d records", len(records))
+	}
+
+	// The file is truncated once drained
+	if info, _ := os.Stat(path); info.Size() != 0 {
+		t.Errorf("Expected empty spill file, got %d bytes", info.Size())
+	}
+}
+
+func TestSpillQueue_MaxSize(t *testing.T) {
+	queue, err := OpenSpillQueue(filepath.Join(t.TempDir(), "spill.jsonl"), 1024)
+	if err != nil {
+		t.Fatalf("OpenSpillQueue() error = %v", err)
+	}
+	defer queue.Close()
+
+	record := &evidence.EvidenceRecord{ID: "rec"}
+	var err2 error
+	for i := 0; i < 100 && err2 == nil; i++ {
+		err2 = queue.push(record)
+	}
+	if err2 != errSpillFull {
+		t.Errorf("Expected errSpillFull, got %v", err2)
+	}
+}
+
+// blockingStorage is a memory storage whose writes block until release is
+// closed.
+type blockingStorage struct {
+	*storage.MemoryStorage
+	release chan struct{}
+}
+
+func (s *blockingStorage) Store(ctx context.Context, record *evidence.EvidenceRecord) error {
+	<-s.release
+	return s.MemoryStorage.Store(ctx, record)
+}
+
+// TestRecorder_SpillOverflow tests that records which do not fit in the
+// channel are spilled and replayed.
+func TestRecorder_SpillOverflow(t *testing.T) {
+	spill, err := OpenSpillQueue(filepath.Join(t.TempDir(), "spill.jsonl"), 0)
+	if err != nil {
+		t.Fatalf("OpenSpillQueue() error = %v", err)
+	}
+	store := &blockingStorage{MemoryStorage: storage.NewMemoryStorage(), release: make(chan struct{})}
+	config := DefaultConfig()
+	config.AsyncBuffer = 1
+	config.WriteTimeout = time.Minute // a blocked send would time the test out
+	config.Spill = spill
+
+	recorder := NewRecorder(store, config)
+	defer recorder.Close()
+
+	ctx := context.Background()
+	const n = 10
+	for i := 0; i < n; i++ {
+		enrichedReq := &processing.EnrichedRequest{
+			RequestID:       fmt.Sprintf("req-%d", i),
+			OriginalRequest: &types.ChatCompletionRequest{Model: "gpt-4"},
+		}
+		_ = recorder.RecordRequest(ctx, &proxy.RequestMetadata{Timestamp: time.Now()}, enrichedReq, &engine.PolicyDecision{Action: engine.ActionAllow})
+		err := recorder.RecordResponse(ctx, &proxy.ResponseMetadata{StatusCode: 200}, &processing.EnrichedResponse{
+			RequestID:        enrichedReq.RequestID,
+			OriginalResponse: &providers.CompletionResponse{Model: "gpt-4"},
+		})
+		if err != nil {
+			t.Fatalf("RecordResponse() error = %v", err)
+		}
+	}
+
+	stats := recorder.Stats()
+	if stats.Spilled == 0 || stats.SpillPending != stats.Spilled {
+		t.Errorf("Expected spilled records while storage is blocked, got %+v", stats)
+	}
+
+	close(store.release)
+	deadline := time.Now().Add(5 * time.Second)
+	for store.Size() < n && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if store.Size() != n {
+		t.Fatalf("Expected %d stored records, got %d", n, store.Size())
+	}
+	stats = recorder.Stats()
+	if stats.Replayed != stats.Spilled || stats.SpillPending != 0 || stats.Dropped != 0 {
+		t.Errorf("Unexpected stats after replay: %+v", stats)
+	}
+}
@@ -0,0 +1,157 @@
+package recorder
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/processing"
+	"mercator-hq/jupiter/pkg/providers"
+	"mercator-hq/jupiter/pkg/proxy"
+)
+
+// StreamRecorder accumulates the chunks of a streamed (SSE) response for
+// the evidence record of its request. The response is reassembled from the
+// chunk deltas, so the record holds the same content, hash, finish reason,
+// and usage as for a non-streamed response, together with the chunk count,
+// time to first token, stream duration, and whether the client
+// disconnected mid-stream.
+//
+// Create one with Recorder.StartStream when the provider call starts, pass
+// every chunk to AddChunk, and call Finish once the stream ends.
+type StreamRecorder struct {
+	recorder  *Recorder
+	requestID string
+	start     time.Time
+
+	mu         sync.Mutex
+	firstChunk time.Time
+	chunks     int
+	content    strings.Builder
+	response   providers.CompletionResponse
+	usage      bool
+	err        error
+}
+
+// StartStream starts accumulating the streamed response of a request
+// recorded with RecordRequest.
+func (r *Recorder) StartStream(requestID string) *StreamRecorder {
+	return &StreamRecorder{
+		recorder:  r,
+		requestID: requestID,
+		start:     time.Now(),
+	}
+}
+
+// AddChunk adds a chunk received from the provider. A chunk with an error
+// ends the stream; the error is recorded unless Finish is given another.
+func (s *StreamRecorder) AddChunk(chunk *providers.StreamChunk) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if chunk.Error != nil {
+		s.err = chunk.Error
+		return
+	}
+	if s.chunks == 0 {
+		s.firstChunk = time.Now()
+	}
+	s.chunks++
+
+	s.content.WriteString(chunk.Delta)
+	if chunk.ID != "" {
+		s.response.ID = chunk.ID
+	}
+	if chunk.Model != "" {
+		s.response.Model = chunk.Model
+	}
+	if chunk.Created != 0 && s.response.Created == 0 {
+		s.response.Created = chunk.Created
+	}
+	if chunk.FinishReason != "" {
+		s.response.FinishReason = chunk.FinishReason
+	}
+	if chunk.Usage != nil {
+		s.response.Usage = *chunk.Usage
+		s.usage = true
+	}
+
+	// A tool call starts with its ID; later deltas without one continue
+	// the arguments of the last call
+	for _, call := range chunk.ToolCalls {
+		calls := s.response.ToolCalls
+		if call.ID != "" || len(calls) == 0 {
+			s.response.ToolCalls = append(calls, call)
+			continue
+		}
+		last := &calls[len(calls)-1]
+		if call.Function.Name != "" {
+			last.Function.Name = call.Function.Name
+		}
+		last.Function.Arguments += call.Function.Arguments
+	}
+}
+
+// Response returns the response reassembled from the chunks so far.
+func (s *StreamRecorder) Response() *providers.CompletionResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	response := s.response
+	response.Content = s.content.String()
+	return &response
+}
+
+// Finish records the streamed response with the evidence record of its
+// request and enqueues the record for writing. enrichedResp, if not nil,
+// is the enrichment of Response(); otherwise only the reassembled response
+// and its token usage are recorded. clientDisconnected marks a stream the
+// client went away from before it ended.
+//
+// This method returns immediately and does not block on storage writes.
+func (s *StreamRecorder) Finish(ctx context.Context, responseMeta *proxy.ResponseMetadata, enrichedResp *processing.EnrichedResponse, clientDisconnected bool) error {
+	if !s.recorder.config.Enabled {
+		return nil
+	}
+	end := time.Now()
+	response := s.Response()
+
+	s.mu.Lock()
+	chunks, firstChunk, usage, streamErr := s.chunks, s.firstChunk, s.usage, s.err
+	s.mu.Unlock()
+
+	if enrichedResp == nil {
+		enrichedResp = &processing.EnrichedResponse{RequestID: s.requestID}
+		if usage {
+			enrichedResp.TokenUsage = &processing.TokenUsage{
+				PromptTokens:     response.Usage.PromptTokens,
+				CompletionTokens: response.Usage.CompletionTokens,
+				TotalTokens:      response.Usage.TotalTokens,
+			}
+		}
+	}
+	if enrichedResp.OriginalResponse == nil {
+		enrichedResp.OriginalResponse = response
+	}
+
+	meta := *responseMeta
+	if meta.Timestamp.IsZero() {
+		meta.Timestamp = end
+	}
+	if meta.Error == nil {
+		meta.Error = streamErr
+	}
+
+	return s.recorder.completeRecord(&meta, enrichedResp, func(record *evidence.EvidenceRecord) {
+		record.ProviderCallTime = s.start
+		record.Streamed = true
+		record.StreamChunks = chunks
+		if !firstChunk.IsZero() {
+			record.TimeToFirstToken = firstChunk.Sub(s.start)
+		}
+		record.StreamDuration = end.Sub(s.start)
+		record.ClientDisconnected = clientDisconnected
+	})
+}
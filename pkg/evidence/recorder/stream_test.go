@@ -0,0 +1,124 @@
+package recorder
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/evidence/storage"
+	"mercator-hq/jupiter/pkg/policy/engine"
+	"mercator-hq/jupiter/pkg/processing"
+	"mercator-hq/jupiter/pkg/providers"
+	"mercator-hq/jupiter/pkg/proxy"
+	"mercator-hq/jupiter/pkg/proxy/types"
+)
+
+// recordStream records a request and streams chunks as its response.
+func recordStream(t *testing.T, recorder *Recorder, requestID string, chunks []*providers.StreamChunk, disconnected bool) {
+	t.Helper()
+	ctx := context.Background()
+
+	requestMeta := &proxy.RequestMetadata{Timestamp: time.Now(), Method: "POST", Path: "/v1/chat/completions"}
+	enrichedReq := &processing.EnrichedRequest{
+		RequestID: requestID,
+		OriginalRequest: &types.ChatCompletionRequest{
+			Model:    "gpt-4",
+			Stream:   true,
+			Messages: []types.Message{{Role: "user", Content: "Hello"}},
+		},
+	}
+	if err := recorder.RecordRequest(ctx, requestMeta, enrichedReq, &engine.PolicyDecision{Action: engine.ActionAllow}); err != nil {
+		t.Fatalf("RecordRequest() failed: %v", err)
+	}
+
+	stream := recorder.StartStream(requestID)
+	for _, chunk := range chunks {
+		stream.AddChunk(chunk)
+	}
+	responseMeta := &proxy.ResponseMetadata{RequestID: requestID, StatusCode: 200, ProviderName: "openai"}
+	if err := stream.Finish(ctx, responseMeta, nil, disconnected); err != nil {
+		t.Fatalf("Finish() failed: %v", err)
+	}
+}
+
+// TestStreamRecorder tests recording a streamed response.
+func TestStreamRecorder(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	config := DefaultConfig()
+	config.HashResponse = true
+	recorder := NewRecorder(store, config)
+
+	recordStream(t, recorder, "req-stream", []*providers.StreamChunk{
+		{ID: "resp-1", Model: "gpt-4-0613", Delta: "Hello"},
+		{Delta: ", world", ToolCalls: []providers.ToolCall{{ID: "call-1", Type: "function", Function: providers.FunctionCall{Name: "lookup", Arguments: `{"q":`}}}},
+		{ToolCalls: []providers.ToolCall{{Function: providers.FunctionCall{Arguments: `"x"}`}}}},
+		{FinishReason: "stop", Usage: &providers.TokenUsage{PromptTokens: 3, CompletionTokens: 4, TotalTokens: 7}},
+	}, false)
+	recordStream(t, recorder, "req-gone", []*providers.StreamChunk{
+		{Delta: "Partial"},
+		{Error: errors.New("stream interrupted")},
+	}, true)
+	recorder.Close() // Drains the queued records
+
+	byRequest := make(map[string]*evidence.EvidenceRecord)
+	records, _ := store.Query(context.Background(), &evidence.Query{})
+	for _, record := range records {
+		byRequest[record.RequestID] = record
+	}
+
+	record := byRequest["req-stream"]
+	if record == nil {
+		t.Fatalf("streamed record not stored, got %d records", len(records))
+	}
+	if !record.Streamed || record.StreamChunks != 4 || record.ClientDisconnected {
+		t.Errorf("stream fields = streamed %t, chunks %d, disconnected %t; want true, 4, false",
+			record.Streamed, record.StreamChunks, record.ClientDisconnected)
+	}
+	if record.ResponseContent != "Hello, world" || record.FinishReason != "stop" || record.ProviderModel != "gpt-4-0613" {
+		t.Errorf("response = %q/%q/%q, want reassembled content", record.ResponseContent, record.FinishReason, record.ProviderModel)
+	}
+	if record.TotalTokens != 7 {
+		t.Errorf("TotalTokens = %d, want 7", record.TotalTokens)
+	}
+	if record.ResponseHash == "" {
+		t.Error("expected a response hash")
+	}
+	if record.StreamDuration < record.TimeToFirstToken || record.ProviderCallTime.IsZero() {
+		t.Errorf("timings = first token %s, duration %s, call %s", record.TimeToFirstToken, record.StreamDuration, record.ProviderCallTime)
+	}
+
+	gone := byRequest["req-gone"]
+	if gone == nil {
+		t.Fatal("disconnected record not stored")
+	}
+	if !gone.ClientDisconnected || gone.StreamChunks != 1 || gone.ResponseContent != "Partial" {
+		t.Errorf("disconnected record = %+v", gone)
+	}
+	if gone.Error != "stream interrupted" {
+		t.Errorf("Error = %q, want the stream error", gone.Error)
+	}
+}
+
+// TestStreamRecorder_ToolCalls tests reassembling streamed tool calls.
+func TestStreamRecorder_ToolCalls(t *testing.T) {
+	recorder := NewRecorder(storage.NewMemoryStorage(), DefaultConfig())
+	defer recorder.Close()
+
+	stream := recorder.StartStream("req-tools")
+	stream.AddChunk(&providers.StreamChunk{ToolCalls: []providers.ToolCall{{ID: "call-1", Function: providers.FunctionCall{Name: "lookup", Arguments: `{"q":`}}}})
+	stream.AddChunk(&providers.StreamChunk{ToolCalls: []providers.ToolCall{{Function: providers.FunctionCall{Arguments: `"x"}`}}}})
+	stream.AddChunk(&providers.StreamChunk{ToolCalls: []providers.ToolCall{{ID: "call-2", Function: providers.FunctionCall{Name: "fetch", Arguments: `{}`}}}})
+
+	calls := stream.Response().ToolCalls
+	if len(calls) != 2 {
+		t.Fatalf("got %d tool calls, want 2", len(calls))
+	}
+	if calls[0].Function.Name != "lookup" || calls[0].Function.Arguments != `{"q":"x"}` {
+		t.Errorf("first call = %+v", calls[0])
+	}
+	if calls[1].Function.Name != "fetch" {
+		t.Errorf("second call = %+v", calls[1])
+	}
+}
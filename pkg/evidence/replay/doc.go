@@ -0,0 +1,24 @@
+// Package replay re-runs stored evidence records through the current
+// policy engine, for regression testing of policy changes and for
+// reconstructing incidents.
+//
+// Each record's request is rebuilt with Reconstruct: from its captured
+// body when full-body capture (package capture) kept one, otherwise from
+// the recorded model, prompt excerpts, and tool names. The enrichment the
+// policies evaluate (token and cost estimates, risk scores, PII detection)
+// is taken from the record. The new decision is compared with the
+// recorded one:
+//
+//	replayer, err := replay.NewReplayer(&replay.Config{Engine: policyEngine, Blobs: blobs})
+//	report, err := replayer.Run(ctx, store, &evidence.Query{PolicyDecision: "block"}, func(result *replay.Result) {
+//	    if len(result.Differences) > 0 {
+//	        fmt.Println(result.RequestID, result.Recorded.Action, "->", result.Replayed.Action)
+//	    }
+//	})
+//
+// With Config.Providers set, requests the current policies allow are also
+// re-sent to a provider. This is a sandbox: responses are evaluated
+// against the response policies and compared with the recorded response,
+// but are not returned to anyone or recorded as evidence. Re-sending
+// incurs provider costs.
+package replay
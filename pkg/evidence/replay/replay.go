@@ -0,0 +1,357 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/evidence/capture"
+	"mercator-hq/jupiter/pkg/policy/engine"
+	"mercator-hq/jupiter/pkg/processing"
+	"mercator-hq/jupiter/pkg/providers"
+	"mercator-hq/jupiter/pkg/proxy/handlers"
+	"mercator-hq/jupiter/pkg/proxy/types"
+)
+
+// ProviderSource looks up the provider a replayed request is sent to.
+// providerfactory.Manager implements it, and routes model names.
+type ProviderSource interface {
+	GetProvider(name string) (providers.Provider, error)
+}
+
+// Config contains replay configuration.
+type Config struct {
+	// Engine evaluates the reconstructed requests. Required.
+	Engine engine.Engine
+
+	// Blobs holds captured request bodies. Records with a captured body are
+	// replayed with the complete request; others are rebuilt from the
+	// recorded prompt excerpts. Optional.
+	Blobs capture.BlobStore
+
+	// Providers enables re-sending allowed requests to providers. The
+	// responses are evaluated against the response policies and compared
+	// with the recorded response, and are otherwise discarded. Optional.
+	Providers ProviderSource
+
+	// ProviderTimeout bounds each re-sent request.
+	// Default: 60s
+	ProviderTimeout time.Duration
+}
+
+// DefaultProviderTimeout is the default timeout for re-sent requests.
+const DefaultProviderTimeout = 60 * time.Second
+
+// Result is the outcome of replaying one evidence record.
+type Result struct {
+	// RecordID is the replayed evidence record.
+	RecordID string `json:"record_id"`
+
+	// RequestID is the original request.
+	RequestID string `json:"request_id"`
+
+	// Complete reports whether the request was rebuilt from its captured
+	// body. Otherwise only the recorded excerpts were available.
+	Complete bool `json:"complete"`
+
+	// Recorded is the decision recorded with the evidence.
+	Recorded engine.DecisionSummary `json:"recorded"`
+
+	// Replayed is the decision of the current policies.
+	Replayed engine.DecisionSummary `json:"replayed"`
+
+	// Differences lists the decision fields that differ (see
+	// engine.CompareDecisions). Empty if the decisions agree.
+	Differences []string `json:"differences,omitempty"`
+
+	// Response is the outcome of re-sending the request, if enabled.
+	Response *ResponseResult `json:"response,omitempty"`
+
+	// Error is set if the record could not be replayed.
+	Error string `json:"error,omitempty"`
+}
+
+// ResponseResult is the outcome of re-sending a replayed request.
+type ResponseResult struct {
+	// Provider and Model identify where the request was sent.
+	Provider string `json:"provider"`
+	Model    string `json:"model"`
+
+	// FinishReason, PromptTokens, and CompletionTokens describe the new
+	// response.
+	FinishReason     string `json:"finish_reason,omitempty"`
+	PromptTokens     int    `json:"prompt_tokens,omitempty"`
+	CompletionTokens int    `json:"completion_tokens,omitempty"`
+
+	// Latency is the provider round-trip time.
+	Latency time.Duration `json:"latency"`
+
+	// Decision is the response policy decision for the new response.
+	Decision *engine.DecisionSummary `json:"decision,omitempty"`
+
+	// Differences lists the response fields that differ from the recorded
+	// response ("finish_reason", "model").
+	Differences []string `json:"differences,omitempty"`
+
+	// Error is set if the provider call failed.
+	Error string `json:"error,omitempty"`
+}
+
+// Report summarizes a replay run.
+type Report struct {
+	// Replayed is the number of records replayed.
+	Replayed int64 `json:"replayed"`
+
+	// Divergent is the number of records whose decision changed.
+	Divergent int64 `json:"divergent"`
+
+	// Incomplete is the number of records replayed from excerpts because
+	// no request body was captured.
+	Incomplete int64 `json:"incomplete"`
+
+	// Errors is the number of records that could not be replayed.
+	Errors int64 `json:"errors"`
+
+	// ActionChanges counts divergent records by "recorded->replayed" action.
+	ActionChanges map[string]int64 `json:"action_changes,omitempty"`
+}
+
+// Replayer re-evaluates stored evidence records against a policy engine.
+type Replayer struct {
+	config *Config
+}
+
+// NewReplayer creates a replayer.
+func NewReplayer(config *Config) (*Replayer, error) {
+	if config == nil || config.Engine == nil {
+		return nil, errors.New("replay requires a policy engine")
+	}
+	if config.ProviderTimeout <= 0 {
+		config.ProviderTimeout = DefaultProviderTimeout
+	}
+	return &Replayer{config: config}, nil
+}
+
+// Run replays the records matching query in storage order and returns a
+// summary. fn, if not nil, is called with each result.
+func (r *Replayer) Run(ctx context.Context, store evidence.Storage, query *evidence.Query, fn func(*Result)) (*Report, error) {
+	recordsCh, errCh, err := store.QueryStream(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Report{ActionChanges: make(map[string]int64)}
+	for record := range recordsCh {
+		result := r.Replay(ctx, record)
+		report.add(result)
+		if fn != nil {
+			fn(result)
+		}
+	}
+	if err := <-errCh; err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// add counts a result in the report.
+func (rep *Report) add(result *Result) {
+	rep.Replayed++
+	if !result.Complete {
+		rep.Incomplete++
+	}
+	if result.Error != "" {
+		rep.Errors++
+		return
+	}
+	if len(result.Differences) > 0 {
+		rep.Divergent++
+		if result.Recorded.Action != result.Replayed.Action {
+			rep.ActionChanges[fmt.Sprintf("%s->%s", result.Recorded.Action, result.Replayed.Action)]++
+		}
+	}
+}
+
+// Replay reconstructs the request of a record, evaluates it against the
+// current policies, and, if providers are configured and the request is
+// not blocked, re-sends it.
+func (r *Replayer) Replay(ctx context.Context, record *evidence.EvidenceRecord) *Result {
+	result := &Result{
+		RecordID:  record.ID,
+		RequestID: record.RequestID,
+		Recorded:  RecordedDecision(record),
+	}
+
+	request, complete, err := Reconstruct(ctx, record, r.config.Blobs)
+	result.Complete = complete
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	decision, err := r.config.Engine.EvaluateRequest(ctx, request)
+	if err != nil {
+		result.Error = fmt.Sprintf("policy evaluation failed: %v", err)
+		return result
+	}
+	result.Replayed = summarize(decision)
+	result.Differences = engine.CompareDecisions(result.Recorded, result.Replayed)
+
+	if r.config.Providers != nil && decision.Action != engine.ActionBlock {
+		result.Response = r.send(ctx, record, request, decision)
+	}
+	return result
+}
+
+// send re-sends a request to the provider chosen by the replayed decision,
+// or to the recorded provider. Models that the provider source routes (see
+// handlers.ModelResolver) are sent to their provider as its upstream model.
+func (r *Replayer) send(ctx context.Context, record *evidence.EvidenceRecord, request *processing.EnrichedRequest, decision *engine.PolicyDecision) *ResponseResult {
+	providerReq := handlers.ConvertToProviderRequest(request.OriginalRequest)
+	providerReq.Stream = false
+
+	result := &ResponseResult{Provider: record.Provider, Model: providerReq.Model}
+	if resolver, ok := r.config.Providers.(handlers.ModelResolver); ok {
+		if name, model, ok := resolver.ResolveModel(providerReq.Model); ok {
+			result.Provider = name
+			providerReq.Model = model
+			result.Model = model
+		}
+	}
+	if decision.RoutingTarget != nil {
+		result.Provider = decision.RoutingTarget.Provider
+		if decision.RoutingTarget.Model != "" {
+			providerReq.Model = decision.RoutingTarget.Model
+			result.Model = providerReq.Model
+		}
+	}
+
+	provider, err := r.config.Providers.GetProvider(result.Provider)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.config.ProviderTimeout)
+	defer cancel()
+	start := time.Now()
+	response, err := provider.SendCompletion(ctx, providerReq)
+	result.Latency = time.Since(start)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	result.FinishReason = response.FinishReason
+	result.PromptTokens = response.Usage.PromptTokens
+	result.CompletionTokens = response.Usage.CompletionTokens
+	if response.FinishReason != record.FinishReason {
+		result.Differences = append(result.Differences, "finish_reason")
+	}
+	if record.ProviderModel != "" && response.Model != record.ProviderModel {
+		result.Differences = append(result.Differences, "model")
+	}
+
+	responseDecision, err := r.config.Engine.EvaluateResponse(ctx, &processing.EnrichedResponse{
+		RequestID:        record.RequestID,
+		OriginalResponse: response,
+	})
+	if err != nil {
+		result.Error = fmt.Sprintf("response policy evaluation failed: %v", err)
+		return result
+	}
+	summary := summarize(responseDecision)
+	result.Decision = &summary
+	return result
+}
+
+// Reconstruct rebuilds the enriched request of an evidence record. The
+// request body is taken from blobs if it was captured and not truncated;
+// complete reports whether it was. Otherwise the request is rebuilt from
+// the recorded model, prompt excerpts, and tool names. The enrichment
+// (token and cost estimates, risk and complexity scores, PII detection) is
+// the one recorded, so policies see the values they saw originally.
+func Reconstruct(ctx context.Context, record *evidence.EvidenceRecord, blobs capture.BlobStore) (request *processing.EnrichedRequest, complete bool, err error) {
+	request = &processing.EnrichedRequest{
+		RequestID:       record.RequestID,
+		TokenEstimate:   &processing.TokenEstimate{TotalTokens: record.EstimatedTokens},
+		CostEstimate:    &processing.CostEstimate{TotalCost: record.EstimatedCost, Model: record.Model},
+		RiskScore:       record.RiskScore,
+		ComplexityScore: record.ComplexityScore,
+		ContentAnalysis: &processing.ContentAnalysis{
+			PIIDetection: &processing.PIIDetection{
+				HasPII:   record.PIIDetected,
+				PIITypes: record.PIITypes,
+				PIICount: len(record.PIITypes),
+			},
+		},
+	}
+
+	if blobs != nil && record.RequestBodyRef != "" && !record.BodyTruncated {
+		body, err := blobs.Get(ctx, record.RequestBodyRef)
+		if err != nil && !errors.Is(err, capture.ErrBlobNotFound) {
+			return nil, false, fmt.Errorf("failed to read captured request: %w", err)
+		}
+		if err == nil {
+			var original types.ChatCompletionRequest
+			if err := json.Unmarshal(body, &original); err != nil {
+				return nil, false, fmt.Errorf("failed to decode captured request: %w", err)
+			}
+			request.OriginalRequest = &original
+			return request, true, nil
+		}
+	}
+
+	original := &types.ChatCompletionRequest{Model: record.Model}
+	if record.SystemPrompt != "" {
+		original.Messages = append(original.Messages, types.Message{Role: "system", Content: record.SystemPrompt})
+	}
+	if record.UserPrompt != "" {
+		original.Messages = append(original.Messages, types.Message{Role: "user", Content: record.UserPrompt})
+	}
+	for _, name := range record.ToolsUsed {
+		original.Tools = append(original.Tools, types.Tool{
+			Type:     "function",
+			Function: types.FunctionDefinition{Name: name},
+		})
+	}
+	request.OriginalRequest = original
+	return request, false, nil
+}
+
+// RecordedDecision returns the decision recorded with an evidence record.
+// Records keep every evaluated rule, so a rule counts as matched if it
+// executed an action; routing targets and tags are not recorded.
+func RecordedDecision(record *evidence.EvidenceRecord) engine.DecisionSummary {
+	summary := engine.DecisionSummary{
+		Action:      engine.PolicyAction(record.PolicyDecision),
+		BlockReason: record.BlockReason,
+	}
+	for _, rule := range record.MatchedRules {
+		if rule.Action != "unknown" {
+			summary.MatchedRules = append(summary.MatchedRules, rule.PolicyID+"/"+rule.RuleID)
+		}
+	}
+	sort.Strings(summary.MatchedRules)
+	return summary
+}
+
+// summarize returns the summary of a decision that is comparable with
+// RecordedDecision.
+func summarize(decision *engine.PolicyDecision) engine.DecisionSummary {
+	summary := engine.DecisionSummary{
+		Action:      decision.Action,
+		BlockReason: decision.BlockReason,
+	}
+	for _, rule := range decision.MatchedRules {
+		if len(rule.ActionsExecuted) > 0 {
+			summary.MatchedRules = append(summary.MatchedRules, rule.PolicyID+"/"+rule.RuleID)
+		}
+	}
+	sort.Strings(summary.MatchedRules)
+	return summary
+}
@@ -0,0 +1,183 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/evidence/capture"
+	"mercator-hq/jupiter/pkg/evidence/storage"
+	"mercator-hq/jupiter/pkg/mpl/ast"
+	"mercator-hq/jupiter/pkg/policy/engine"
+	"mercator-hq/jupiter/pkg/processing"
+	"mercator-hq/jupiter/pkg/providers"
+	"mercator-hq/jupiter/pkg/proxy/types"
+)
+
+// riskEngine blocks requests with a risk score above threshold.
+type riskEngine struct {
+	threshold int
+	requests  []*processing.EnrichedRequest
+}
+
+func (e *riskEngine) EvaluateRequest(ctx context.Context, req *processing.EnrichedRequest) (*engine.PolicyDecision, error) {
+	e.requests = append(e.requests, req)
+	if req.RiskScore > e.threshold {
+		return &engine.PolicyDecision{
+			Action:      engine.ActionBlock,
+			BlockReason: "risk too high",
+			MatchedRules: []*engine.MatchedRule{{
+				PolicyID:        "safety",
+				RuleID:          "high-risk",
+				ConditionResult: true,
+				ActionsExecuted: []*engine.ActionResult{{ActionType: "deny", Success: true}},
+			}},
+		}, nil
+	}
+	return &engine.PolicyDecision{Action: engine.ActionAllow}, nil
+}
+
+func (e *riskEngine) EvaluateResponse(ctx context.Context, resp *processing.EnrichedResponse) (*engine.PolicyDecision, error) {
+	return &engine.PolicyDecision{Action: engine.ActionAllow}, nil
+}
+
+func (e *riskEngine) ReloadPolicies(ctx context.Context) error { return nil }
+func (e *riskEngine) GetPolicies() []*ast.Policy               { return nil }
+func (e *riskEngine) Close() error                             { return nil }
+
+// fakeProviders serves a single provider that records requests.
+type fakeProviders struct {
+	providers.Provider
+	requests []*providers.CompletionRequest
+}
+
+func (p *fakeProviders) GetProvider(name string) (providers.Provider, error) { return p, nil }
+
+func (p *fakeProviders) SendCompletion(ctx context.Context, req *providers.CompletionRequest) (*providers.CompletionResponse, error) {
+	p.requests = append(p.requests, req)
+	return &providers.CompletionResponse{Model: req.Model, FinishReason: "length"}, nil
+}
+
+func TestReplayer_Run(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	ctx := context.Background()
+	records := []*evidence.EvidenceRecord{
+		{ID: "a", RequestID: "req-a", RiskScore: 2, PolicyDecision: "allow"},
+		{ID: "b", RequestID: "req-b", RiskScore: 8, PolicyDecision: "allow"},
+		{ID: "c", RequestID: "req-c", RiskScore: 9, PolicyDecision: "block", BlockReason: "risk too high",
+			MatchedRules: []evidence.MatchedRuleRecord{
+				{PolicyID: "safety", RuleID: "high-risk", Action: "deny"},
+				{PolicyID: "safety", RuleID: "pii", Action: "unknown"}, // evaluated, not matched
+			}},
+	}
+	for _, record := range records {
+		if err := store.Store(ctx, record); err != nil {
+			t.Fatalf("Store() error = %v", err)
+		}
+	}
+
+	replayer, err := NewReplayer(&Config{Engine: &riskEngine{threshold: 5}})
+	if err != nil {
+		t.Fatalf("NewReplayer() error = %v", err)
+	}
+	results := make(map[string]*Result)
+	report, err := replayer.Run(ctx, store, &evidence.Query{}, func(result *Result) {
+		results[result.RecordID] = result
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+
+	if report.Replayed != 3 || report.Divergent != 1 || report.Incomplete != 3 || report.Errors != 0 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	if report.ActionChanges["allow->block"] != 1 {
+		t.Errorf("Expected one allow->block change, got %v", report.ActionChanges)
+	}
+	if diffs := results["b"].Differences; len(diffs) == 0 || diffs[0] != "action" {
+		t.Errorf("Expected action difference for record b, got %v", diffs)
+	}
+	if diffs := results["c"].Differences; len(diffs) != 0 {
+		t.Errorf("Expected record c to agree, got %v", diffs)
+	}
+}
+
+func TestReconstruct(t *testing.T) {
+	ctx := context.Background()
+	record := &evidence.EvidenceRecord{
+		RequestID:       "req-1",
+		Model:           "gpt-4",
+		SystemPrompt:    "Be brief",
+		UserPrompt:      "Hello",
+		ToolsUsed:       []string{"search"},
+		EstimatedTokens: 42,
+		RiskScore:       3,
+		PIIDetected:     true,
+		PIITypes:        []string{"email"},
+	}
+
+	request, complete, err := Reconstruct(ctx, record, nil)
+	if err != nil || complete {
+		t.Fatalf("Reconstruct() complete = %v, error = %v", complete, err)
+	}
+	if len(request.OriginalRequest.Messages) != 2 || request.OriginalRequest.Tools[0].Function.Name != "search" {
+		t.Errorf("Unexpected request from excerpts: %+v", request.OriginalRequest)
+	}
+	if request.TokenEstimate.TotalTokens != 42 || request.RiskScore != 3 || !request.ContentAnalysis.PIIDetection.HasPII {
+		t.Errorf("Recorded enrichment not restored: %+v", request)
+	}
+
+	// A captured body takes precedence
+	blobs, err := capture.NewFileBlobStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFileBlobStore() error = %v", err)
+	}
+	body, _ := json.Marshal(&types.ChatCompletionRequest{
+		Model: "gpt-4",
+		Messages: []types.Message{
+			{Role: "user", Content: "first"},
+			{Role: "assistant", Content: "reply"},
+			{Role: "user", Content: "second"},
+		},
+	})
+	record.RequestBodyRef, _ = blobs.Put(ctx, body)
+
+	request, complete, err = Reconstruct(ctx, record, blobs)
+	if err != nil || !complete {
+		t.Fatalf("Reconstruct() complete = %v, error = %v", complete, err)
+	}
+	if len(request.OriginalRequest.Messages) != 3 {
+		t.Errorf("Expected the captured messages, got %d", len(request.OriginalRequest.Messages))
+	}
+}
+
+func TestReplayer_SendToProvider(t *testing.T) {
+	sandbox := &fakeProviders{}
+	replayer, err := NewReplayer(&Config{Engine: &riskEngine{threshold: 5}, Providers: sandbox})
+	if err != nil {
+		t.Fatalf("NewReplayer() error = %v", err)
+	}
+
+	ctx := context.Background()
+	allowed := replayer.Replay(ctx, &evidence.EvidenceRecord{
+		ID: "a", Model: "gpt-4", Provider: "openai", UserPrompt: "Hi", FinishReason: "stop", PolicyDecision: "allow",
+	})
+	if allowed.Response == nil || allowed.Response.Error != "" {
+		t.Fatalf("Expected a provider response, got %+v", allowed.Response)
+	}
+	if allowed.Response.Provider != "openai" || allowed.Response.Decision == nil {
+		t.Errorf("Unexpected response result: %+v", allowed.Response)
+	}
+	if diffs := allowed.Response.Differences; len(diffs) != 1 || diffs[0] != "finish_reason" {
+		t.Errorf("Expected finish_reason difference, got %v", diffs)
+	}
+
+	blocked := replayer.Replay(ctx, &evidence.EvidenceRecord{ID: "b", RiskScore: 9, PolicyDecision: "allow"})
+	if blocked.Response != nil {
+		t.Errorf("Blocked request should not be sent, got %+v", blocked.Response)
+	}
+	if len(sandbox.requests) != 1 || sandbox.requests[0].Stream {
+		t.Errorf("Expected one non-streaming provider request, got %d", len(sandbox.requests))
+	}
+}
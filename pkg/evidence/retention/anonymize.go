@@ -0,0 +1,215 @@
+package retention
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/evidence/capture"
+	"mercator-hq/jupiter/pkg/evidence/integrity"
+)
+
+// PseudonymPrefix prefixes the pseudonyms that replace user IDs and API
+// keys of anonymized records.
+const PseudonymPrefix = "anon-"
+
+// anonymizeBatchSize is the number of records anonymized per storage call.
+const anonymizeBatchSize = 500
+
+// Redactor removes personal data from text. content.Analyzer implements
+// it.
+type Redactor interface {
+	Redact(text string) string
+}
+
+// AnonymizeConfig contains configuration for an Anonymizer.
+type AnonymizeConfig struct {
+	// AfterDays is the age in days after which records are anonymized.
+	// Required.
+	AfterDays int
+
+	// Key is the HMAC key deriving pseudonyms from user IDs and API keys.
+	// The same key yields the same pseudonym, so anonymized records of a
+	// user can still be grouped. Required.
+	Key []byte
+
+	// Redactor redacts the prompt, response, block reason, and error
+	// excerpts and captured tool arguments and results. If nil, they are
+	// cleared.
+	Redactor Redactor
+
+	// Blobs holds captured request and response bodies, which are deleted
+	// when their records are anonymized. Optional.
+	Blobs capture.BlobStore
+
+	// Signer signs anonymized records again, so that their signatures
+	// remain valid. Optional.
+	Signer *integrity.Signer
+
+	// Holds reports records under legal hold, which are kept with full
+	// fidelity. Optional.
+	Holds evidence.HoldChecker
+}
+
+// Anonymizer pseudonymizes the identifiers and redacts the personal data
+// of evidence records after a configurable age.
+type Anonymizer struct {
+	store  evidence.Updater
+	source evidence.Storage
+	config *AnonymizeConfig
+	logger *slog.Logger
+}
+
+// NewAnonymizer creates an anonymizer for the records in store, which must
+// implement evidence.Updater.
+func NewAnonymizer(store evidence.Storage, config *AnonymizeConfig) (*Anonymizer, error) {
+	if config == nil || config.AfterDays <= 0 {
+		return nil, errors.New("anonymization requires a positive age in days")
+	}
+	if len(config.Key) == 0 {
+		return nil, errors.New("anonymization requires a pseudonym key")
+	}
+	updater, ok := store.(evidence.Updater)
+	if !ok {
+		return nil, errors.New("evidence storage does not support updating records")
+	}
+	return &Anonymizer{
+		store:  updater,
+		source: store,
+		config: config,
+		logger: slog.Default().With("component", "evidence.anonymizer"),
+	}, nil
+}
+
+// Pseudonym returns the pseudonym of an identifier. Empty identifiers and
+// pseudonyms are returned unchanged.
+func (a *Anonymizer) Pseudonym(id string) string {
+	if id == "" || strings.HasPrefix(id, PseudonymPrefix) {
+		return id
+	}
+	mac := hmac.New(sha256.New, a.config.Key)
+	mac.Write([]byte(id))
+	return PseudonymPrefix + hex.EncodeToString(mac.Sum(nil)[:16])
+}
+
+// Anonymize anonymizes the records older than AfterDays that are not
+// anonymized yet and not under legal hold, and pseudonymizes the user IDs
+// of the rollups of their days. It returns the number of records
+// anonymized.
+func (a *Anonymizer) Anonymize(ctx context.Context) (int64, error) {
+	now := time.Now()
+	cutoff := now.AddDate(0, 0, -a.config.AfterDays)
+	pending := false
+
+	var anonymized int64
+	offset := 0
+	for {
+		records, err := a.source.Query(ctx, &evidence.Query{
+			EndTime:    &cutoff,
+			Anonymized: &pending,
+			SortBy:     "request_time",
+			SortOrder:  "asc",
+			Limit:      anonymizeBatchSize,
+			Offset:     offset,
+		})
+		if err != nil {
+			return anonymized, fmt.Errorf("failed to query evidence: %w", err)
+		}
+
+		// Anonymized records no longer match the query, so the next page
+		// starts after the held records only
+		batch := make([]*evidence.EvidenceRecord, 0, len(records))
+		for _, record := range records {
+			if a.config.Holds != nil && a.config.Holds.IsHeld(record) {
+				offset++
+				continue
+			}
+			if err := a.anonymize(ctx, record, now); err != nil {
+				return anonymized, err
+			}
+			batch = append(batch, record)
+		}
+		if len(batch) > 0 {
+			if err := a.store.Update(ctx, batch); err != nil {
+				return anonymized, fmt.Errorf("failed to update evidence: %w", err)
+			}
+			anonymized += int64(len(batch))
+		}
+		if len(records) < anonymizeBatchSize {
+			break
+		}
+	}
+
+	if rollups, ok := a.source.(evidence.RollupStore); ok {
+		if err := rollups.PseudonymizeRollups(ctx, cutoff, a.Pseudonym); err != nil {
+			return anonymized, fmt.Errorf("failed to pseudonymize rollups: %w", err)
+		}
+	}
+
+	if anonymized > 0 {
+		a.logger.Info("anonymized evidence records",
+			"anonymized_count", anonymized,
+			"after_days", a.config.AfterDays,
+		)
+	}
+	return anonymized, nil
+}
+
+// anonymize removes the identifiers and personal data of a record and
+// deletes its captured bodies.
+func (a *Anonymizer) anonymize(ctx context.Context, record *evidence.EvidenceRecord, now time.Time) error {
+	record.UserID = a.Pseudonym(record.UserID)
+	record.APIKey = a.Pseudonym(record.APIKey)
+	record.IPAddress = ""
+	record.RequestHeaders = nil
+
+	texts := []*string{
+		&record.SystemPrompt, &record.UserPrompt, &record.ResponseContent,
+		&record.BlockReason, &record.Error,
+	}
+	for i := range record.ToolCalls {
+		texts = append(texts, &record.ToolCalls[i].Arguments)
+	}
+	for i := range record.ToolResults {
+		texts = append(texts, &record.ToolResults[i].Content)
+	}
+	for _, text := range texts {
+		if a.config.Redactor != nil {
+			*text = a.config.Redactor.Redact(*text)
+		} else {
+			*text = ""
+		}
+	}
+
+	for _, ref := range []*string{&record.RequestBodyRef, &record.ResponseBodyRef} {
+		if *ref == "" {
+			continue
+		}
+		if a.config.Blobs != nil {
+			if err := a.config.Blobs.Delete(ctx, *ref); err != nil {
+				return fmt.Errorf("failed to delete captured body: %w", err)
+			}
+		}
+		*ref = ""
+	}
+
+	anonymizedAt := now.UTC()
+	record.AnonymizedAt = &anonymizedAt
+
+	// RecordHash keeps the chain links intact; the anonymized content is
+	// verified against its own hash
+	if record.ChainID != "" {
+		record.AnonymizedHash = integrity.HashRecord(record)
+	}
+	if a.config.Signer != nil && record.Signature != "" {
+		a.config.Signer.Sign(record)
+	}
+	return nil
+}
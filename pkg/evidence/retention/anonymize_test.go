@@ -0,0 +1,201 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/evidence/capture"
+	"mercator-hq/jupiter/pkg/evidence/integrity"
+	"mercator-hq/jupiter/pkg/evidence/storage"
+)
+
+// emailRedactor redacts words containing "@".
+type emailRedactor struct{}
+
+func (emailRedactor) Redact(text string) string {
+	words := strings.Fields(text)
+	for i, word := range words {
+		if strings.Contains(word, "@") {
+			words[i] = "[REDACTED:email]"
+		}
+	}
+	return strings.Join(words, " ")
+}
+
+// TestAnonymizer tests anonymizing records older than AfterDays.
+func TestAnonymizer(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	ctx := context.Background()
+	now := time.Now()
+
+	blobs, err := capture.NewFileBlobStore(filepath.Join(t.TempDir(), "blobs"))
+	if err != nil {
+		t.Fatalf("NewFileBlobStore() failed: %v", err)
+	}
+	ref, err := blobs.Put(ctx, []byte(`{"messages":[]}`))
+	if err != nil {
+		t.Fatalf("Put() failed: %v", err)
+	}
+
+	holds, err := NewHoldRegistry(filepath.Join(t.TempDir(), "holds.json"))
+	if err != nil {
+		t.Fatalf("NewHoldRegistry() failed: %v", err)
+	}
+	if _, err := holds.Place(&Hold{UserID: "carol", Reason: "case-1"}); err != nil {
+		t.Fatalf("Place() failed: %v", err)
+	}
+
+	records := []*evidence.EvidenceRecord{
+		{ID: "old", RequestTime: now.AddDate(0, 0, -40), UserID: "alice", APIKey: "sk-alice", IPAddress: "10.0.0.1",
+			RequestHeaders: map[string]string{"User-Agent": "curl"}, UserPrompt: "mail alice@example.com",
+			RequestBodyRef: ref},
+		{ID: "held", RequestTime: now.AddDate(0, 0, -40), UserID: "carol", UserPrompt: "mail carol@example.com"},
+		{ID: "recent", RequestTime: now.AddDate(0, 0, -5), UserID: "alice", UserPrompt: "mail alice@example.com"},
+	}
+	for _, record := range records {
+		if err := store.Store(ctx, record); err != nil {
+			t.Fatalf("Store() failed: %v", err)
+		}
+	}
+
+	anonymizer, err := NewAnonymizer(store, &AnonymizeConfig{
+		AfterDays: 30,
+		Key:       []byte("secret"),
+		Redactor:  emailRedactor{},
+		Blobs:     blobs,
+		Holds:     holds,
+	})
+	if err != nil {
+		t.Fatalf("NewAnonymizer() failed: %v", err)
+	}
+
+	anonymized, err := anonymizer.Anonymize(ctx)
+	if err != nil {
+		t.Fatalf("Anonymize() failed: %v", err)
+	}
+	if anonymized != 1 {
+		t.Errorf("Anonymize() = %d, want 1", anonymized)
+	}
+
+	old := store.GetByID("old")
+	if old.AnonymizedAt == nil {
+		t.Fatal("Expected old record to be anonymized")
+	}
+	if old.UserID != anonymizer.Pseudonym("alice") || !strings.HasPrefix(old.UserID, PseudonymPrefix) {
+		t.Errorf("UserID = %q, want pseudonym of alice", old.UserID)
+	}
+	if old.APIKey == "sk-alice" || old.IPAddress != "" || old.RequestHeaders != nil {
+		t.Errorf("Expected identifiers to be removed, got %+v", old)
+	}
+	if old.UserPrompt != "mail [REDACTED:email]" {
+		t.Errorf("UserPrompt = %q, want redacted email", old.UserPrompt)
+	}
+	if old.RequestBodyRef != "" {
+		t.Errorf("Expected body ref to be cleared, got %q", old.RequestBodyRef)
+	}
+	if _, err := blobs.Get(ctx, ref); !errors.Is(err, capture.ErrBlobNotFound) {
+		t.Errorf("Expected captured body to be deleted, got %v", err)
+	}
+
+	if held := store.GetByID("held"); held.AnonymizedAt != nil || held.UserID != "carol" {
+		t.Errorf("Expected held record to keep full fidelity, got %+v", held)
+	}
+	if recent := store.GetByID("recent"); recent.AnonymizedAt != nil || recent.UserID != "alice" {
+		t.Errorf("Expected recent record to keep full fidelity, got %+v", recent)
+	}
+
+	// Anonymized records are not anonymized again
+	if anonymized, err := anonymizer.Anonymize(ctx); err != nil || anonymized != 0 {
+		t.Errorf("second Anonymize() = %d, %v, want 0", anonymized, err)
+	}
+	if again := store.GetByID("old"); again.UserID != old.UserID {
+		t.Errorf("Expected a stable pseudonym, got %q then %q", old.UserID, again.UserID)
+	}
+}
+
+// TestAnonymizer_Verifiable tests that anonymized chained records are
+// verified against their anonymization hash after a storage round trip.
+func TestAnonymizer_Verifiable(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewSQLiteStorage(&storage.SQLiteConfig{
+		Path:         filepath.Join(t.TempDir(), "evidence.db"),
+		MaxOpenConns: 1,
+		BusyTimeout:  time.Second,
+	})
+	if err != nil {
+		t.Fatalf("NewSQLiteStorage() failed: %v", err)
+	}
+	defer store.Close()
+
+	chain := integrity.NewChain("")
+	for i, age := range []int{40, 35, 5} {
+		record := &evidence.EvidenceRecord{
+			ID:          fmt.Sprintf("rec-%d", i),
+			RequestID:   fmt.Sprintf("req-%d", i),
+			RequestTime: time.Now().AddDate(0, 0, -age).UTC(),
+			UserID:      "alice",
+			UserPrompt:  "mail alice@example.com",
+		}
+		chain.Link(record)
+		if err := store.Store(ctx, record); err != nil {
+			t.Fatalf("Store() failed: %v", err)
+		}
+		chain.Commit(record)
+	}
+
+	anonymizer, err := NewAnonymizer(store, &AnonymizeConfig{AfterDays: 30, Key: []byte("secret"), Redactor: emailRedactor{}})
+	if err != nil {
+		t.Fatalf("NewAnonymizer() failed: %v", err)
+	}
+	if _, err := anonymizer.Anonymize(ctx); err != nil {
+		t.Fatalf("Anonymize() failed: %v", err)
+	}
+
+	verifyStore := func() *integrity.Report {
+		records, err := store.Query(ctx, &evidence.Query{})
+		if err != nil {
+			t.Fatalf("Query() failed: %v", err)
+		}
+		verifier := integrity.NewVerifier(nil)
+		for _, record := range records {
+			verifier.Add(record)
+		}
+		return verifier.Finish(nil)
+	}
+
+	report := verifyStore()
+	if !report.OK() || report.Anonymized != 2 {
+		t.Fatalf("Expected 2 verified anonymized records, got %+v", report)
+	}
+
+	// Tampering with an anonymized record is detected
+	found, err := store.Query(ctx, &evidence.Query{IDs: []string{"rec-0"}})
+	if err != nil || len(found) != 1 {
+		t.Fatalf("Query() = %d records, %v", len(found), err)
+	}
+	tampered := found[0]
+	tampered.UserPrompt = "mail bob@example.com"
+	if err := store.Update(ctx, []*evidence.EvidenceRecord{tampered}); err != nil {
+		t.Fatalf("Update() failed: %v", err)
+	}
+	if report := verifyStore(); report.OK() {
+		t.Error("Expected modified anonymized record to be reported")
+	}
+}
+
+// TestNewAnonymizer_Validation tests the required configuration.
+func TestNewAnonymizer_Validation(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	if _, err := NewAnonymizer(store, &AnonymizeConfig{AfterDays: 30}); err == nil {
+		t.Error("Expected error without key")
+	}
+	if _, err := NewAnonymizer(store, &AnonymizeConfig{Key: []byte("secret")}); err == nil {
+		t.Error("Expected error without age")
+	}
+}
@@ -0,0 +1,91 @@
+package retention
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+// ArchiveStore stores the archives written before pruning, e.g. in an S3 or
+// GCS bucket (see storage.S3ObjectStore). Put must return an error unless
+// the object was stored completely, since the archived records are deleted
+// afterwards.
+type ArchiveStore interface {
+	Put(ctx context.Context, name string, data []byte, contentType string) error
+}
+
+// ArchiveManifest describes an archive in an ArchiveStore. It is stored
+// next to the archive as <name>.manifest.json once the archive is uploaded,
+// so an archive without a manifest is incomplete.
+type ArchiveManifest struct {
+	// Archive is the object name of the archive: gzip-compressed JSON Lines,
+	// one evidence record per line.
+	Archive string `json:"archive"`
+
+	// SHA256 is the hex-encoded SHA-256 checksum of the archive object.
+	SHA256 string `json:"sha256"`
+	Size   int64  `json:"size"`
+
+	Records          int       `json:"records"`
+	FirstRequestTime time.Time `json:"first_request_time"`
+	LastRequestTime  time.Time `json:"last_request_time"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// uploadArchive writes records to the archive store as <name>.jsonl.gz,
+// followed by its manifest.
+func (p *Pruner) uploadArchive(ctx context.Context, records []*evidence.EvidenceRecord, name string) error {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	encoder := json.NewEncoder(gz)
+
+	manifest := &ArchiveManifest{
+		Archive:   name + ".jsonl.gz",
+		Records:   len(records),
+		CreatedAt: time.Now().UTC(),
+	}
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to encode record %s: %w", record.ID, err)
+		}
+		if manifest.FirstRequestTime.IsZero() || record.RequestTime.Before(manifest.FirstRequestTime) {
+			manifest.FirstRequestTime = record.RequestTime
+		}
+		if record.RequestTime.After(manifest.LastRequestTime) {
+			manifest.LastRequestTime = record.RequestTime
+		}
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("failed to compress archive: %w", err)
+	}
+
+	data := buf.Bytes()
+	sum := sha256.Sum256(data)
+	manifest.SHA256 = hex.EncodeToString(sum[:])
+	manifest.Size = int64(len(data))
+
+	if err := p.config.ArchiveStore.Put(ctx, manifest.Archive, data, "application/gzip"); err != nil {
+		return fmt.Errorf("failed to upload archive: %w", err)
+	}
+	manifestData, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode archive manifest: %w", err)
+	}
+	if err := p.config.ArchiveStore.Put(ctx, name+".manifest.json", manifestData, "application/json"); err != nil {
+		return fmt.Errorf("failed to upload archive manifest: %w", err)
+	}
+
+	p.logger.Info("evidence records archived",
+		"archive", manifest.Archive,
+		"sha256", manifest.SHA256,
+		"record_count", len(records),
+	)
+	return nil
+}
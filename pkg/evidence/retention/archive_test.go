@@ -0,0 +1,128 @@
+package retention
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/evidence/storage"
+)
+
+// memoryArchiveStore is an in-memory ArchiveStore that fails uploads while
+// err is set.
+type memoryArchiveStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	err     error
+}
+
+func (s *memoryArchiveStore) Put(ctx context.Context, name string, data []byte, contentType string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.objects[name] = bytes.Clone(data)
+	return nil
+}
+
+func storeOldRecords(t *testing.T, store evidence.Storage, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		record := &evidence.EvidenceRecord{
+			ID:          fmt.Sprintf("old-%d", i),
+			RequestID:   fmt.Sprintf("req-%d", i),
+			RequestTime: time.Now().AddDate(0, 0, -30-i),
+		}
+		if err := store.Store(context.Background(), record); err != nil {
+			t.Fatalf("Store() failed: %v", err)
+		}
+	}
+}
+
+// TestPruner_ArchiveStore tests uploading archives with manifests.
+func TestPruner_ArchiveStore(t *testing.T) {
+	for _, holds := range []bool{false, true} {
+		store := storage.NewMemoryStorage()
+		storeOldRecords(t, store, 3)
+
+		archives := &memoryArchiveStore{objects: make(map[string][]byte)}
+		config := &Config{RetentionDays: 7, ArchiveBeforeDelete: true, ArchiveStore: archives}
+		if holds {
+			// Records are archived and deleted by ID with holds
+			config.Holds = heldIDs{}
+		}
+		deleted, err := NewPruner(store, config).Prune(context.Background())
+		if err != nil {
+			t.Fatalf("Prune() failed: %v", err)
+		}
+		if deleted != 3 {
+			t.Errorf("Expected 3 deleted records, got %d", deleted)
+		}
+
+		var manifest ArchiveManifest
+		for name, data := range archives.objects {
+			if strings.HasSuffix(name, ".manifest.json") {
+				if err := json.Unmarshal(data, &manifest); err != nil {
+					t.Fatalf("Failed to decode manifest: %v", err)
+				}
+			}
+		}
+		archive, ok := archives.objects[manifest.Archive]
+		if !ok {
+			t.Fatalf("Archive %q not uploaded, objects: %d", manifest.Archive, len(archives.objects))
+		}
+		sum := sha256.Sum256(archive)
+		if manifest.SHA256 != hex.EncodeToString(sum[:]) || manifest.Size != int64(len(archive)) {
+			t.Errorf("Manifest checksum %s (%d bytes) does not match archive", manifest.SHA256, manifest.Size)
+		}
+		if manifest.Records != 3 || !manifest.FirstRequestTime.Before(manifest.LastRequestTime) {
+			t.Errorf("Unexpected manifest: %+v", manifest)
+		}
+
+		gz, err := gzip.NewReader(bytes.NewReader(archive))
+		if err != nil {
+			t.Fatalf("Archive is not gzip-compressed: %v", err)
+		}
+		lines := 0
+		for scanner := bufio.NewScanner(gz); scanner.Scan(); lines++ {
+		}
+		if lines != 3 {
+			t.Errorf("Expected 3 archived records, got %d", lines)
+		}
+	}
+}
+
+// TestPruner_ArchiveStoreFailure tests that records are kept if the archive
+// upload fails.
+func TestPruner_ArchiveStoreFailure(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	storeOldRecords(t, store, 2)
+
+	archives := &memoryArchiveStore{objects: make(map[string][]byte), err: errors.New("bucket unavailable")}
+	pruner := NewPruner(store, &Config{RetentionDays: 7, ArchiveBeforeDelete: true, ArchiveStore: archives})
+	if _, err := pruner.Prune(context.Background()); err == nil {
+		t.Fatal("Expected error when archive upload fails")
+	}
+	if store.Size() != 2 {
+		t.Errorf("Expected records to be kept, got %d", store.Size())
+	}
+}
+
+// heldIDs holds the records with the given IDs.
+type heldIDs map[string]bool
+
+func (h heldIDs) IsHeld(record *evidence.EvidenceRecord) bool {
+	return h[record.ID]
+}
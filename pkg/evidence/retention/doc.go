@@ -46,8 +46,78 @@
 // If archiving is enabled, evidence records are exported to JSON before deletion:
 //
 //   - Archives are stored in the configured archive path
-//   - Archive files are named by date: evidence-2024-01-15.json
-//   - Archives contain all deleted records in JSON format
+//   - Records are archived and deleted a page of up to 1000 records at a
+//     time, one archive per page: evidence-2024-01-15-030000-0001.json
+//   - Only records in a written archive are deleted
+//
+// With an ArchiveStore, such as an S3 or GCS bucket, archives are uploaded
+// instead as gzip-compressed JSON Lines (evidence-<timestamp>-<page>.jsonl.gz),
+// followed by a manifest with the archive's SHA-256 checksum and record
+// count (evidence-<timestamp>-<page>.manifest.json). Records are only deleted once
+// both are uploaded.
+//
+// # Retention Rules
+//
+// Rules set different retention periods for the records of a tenant
+// (team), a policy decision, or a data category (see CategoryPII). A record
+// matching several rules is kept for the longest of their periods; records
+// matching none are kept for RetentionDays:
+//
+//	pruner := retention.NewPruner(storage, &retention.Config{
+//	    RetentionDays: 90,
+//	    Rules: []retention.Rule{
+//	        {PolicyDecision: "block", Days: 730},  // blocked requests: 2 years
+//	        {TeamID: "research", Days: 30},        // tenant with short retention
+//	        {Category: retention.CategoryPII, Days: 30},
+//	    },
+//	})
+//
+// # Legal Holds
+//
+// A HoldRegistry holds records of a user ID, an API key, or a request time
+// range. When set as Config.Holds, the pruner skips held records and
+// deletes the others by ID:
+//
+//	holds, err := retention.NewHoldRegistry("data/evidence-holds.json")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	holds.Place(&retention.Hold{UserID: "user-123", Reason: "Case 2025-17"})
+//
+//	pruner := retention.NewPruner(storage, &retention.Config{
+//	    RetentionDays: 90,
+//	    Holds:         holds,
+//	})
+//
+// Holds are stored in a JSON file, and placing or releasing a hold is
+// logged. HoldRegistry.Handler serves hold management over HTTP.
+//
+// # Anonymization
+//
+// An Anonymizer replaces the user IDs and API keys of records older than
+// AnonymizeConfig.AfterDays with keyed pseudonyms, redacts the PII in
+// their excerpts, clears client IPs and headers, and deletes their captured
+// bodies. Records under legal hold are skipped. Chained records keep
+// their RecordHash, so the chain stays intact, and get an AnonymizedHash
+// of their new content for verification. When set as
+// Config.Anonymizer, it runs on the prune schedule before pruning:
+//
+//	anonymizer, err := retention.NewAnonymizer(storage, &retention.AnonymizeConfig{
+//	    AfterDays: 30,
+//	    Key:       key,
+//	    Redactor:  content.NewAnalyzer(&cfg.Processing.Content),
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//
+//	pruner := retention.NewPruner(storage, &retention.Config{
+//	    RetentionDays: 365,
+//	    PruneSchedule: "0 3 * * *",
+//	    Anonymizer:    anonymizer,
+//	})
+//
+// The storage backend must implement evidence.Updater.
 //
 // # Retention Period
 //
@@ -0,0 +1,92 @@
+package retention
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"mercator-hq/jupiter/pkg/security/auth"
+)
+
+// maxHoldRequestSize limits the size of hold request bodies.
+const maxHoldRequestSize = 64 << 10
+
+// Handler returns an HTTP handler for managing legal holds, e.g. when
+// mounted at /admin/evidence/holds.
+//
+// GET lists the active holds, or with all=true, every hold. POST places the
+// hold in the JSON body and responds with it. DELETE releases the hold
+// given by the id parameter.
+//
+// The handler must be served behind admin authentication: requests without
+// an authenticated admin principal are rejected, and the principal is
+// recorded as the hold's creator or releaser.
+func (r *HoldRegistry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if _, ok := auth.AdminPrincipal(req.Context()); !ok {
+			http.Error(w, "admin authentication required", http.StatusUnauthorized)
+			return
+		}
+
+		switch req.Method {
+		case http.MethodGet:
+			writeHoldJSON(w, http.StatusOK, r.List(req.URL.Query().Get("all") == "true"))
+		case http.MethodPost:
+			r.handlePlace(w, req)
+		case http.MethodDelete:
+			r.handleRelease(w, req)
+		default:
+			w.Header().Set("Allow", "GET, POST, DELETE")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// handlePlace places a hold.
+func (r *HoldRegistry) handlePlace(w http.ResponseWriter, req *http.Request) {
+	var hold Hold
+	if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxHoldRequestSize)).Decode(&hold); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	hold.CreatedBy, _ = auth.AdminPrincipal(req.Context())
+	if err := hold.validate(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	placed, err := r.Place(&hold)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeHoldJSON(w, http.StatusCreated, placed)
+}
+
+// handleRelease releases a hold.
+func (r *HoldRegistry) handleRelease(w http.ResponseWriter, req *http.Request) {
+	id := req.URL.Query().Get("id")
+	if id == "" {
+		http.Error(w, "id is required", http.StatusBadRequest)
+		return
+	}
+
+	principal, _ := auth.AdminPrincipal(req.Context())
+	released, err := r.Release(id, principal)
+	if errors.Is(err, ErrHoldNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeHoldJSON(w, http.StatusOK, released)
+}
+
+func writeHoldJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
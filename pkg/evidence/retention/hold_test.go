@@ -0,0 +1,209 @@
+package retention
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/evidence/storage"
+	"mercator-hq/jupiter/pkg/security/auth"
+)
+
+// TestHoldRegistry tests placing, persisting, and releasing holds.
+func TestHoldRegistry(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "holds", "holds.json")
+	registry, err := NewHoldRegistry(path)
+	if err != nil {
+		t.Fatalf("NewHoldRegistry() failed: %v", err)
+	}
+
+	if _, err := registry.Place(&Hold{Reason: "no criteria"}); err == nil {
+		t.Error("Expected error for hold without criteria")
+	}
+
+	start := time.Date(2025, 11, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(0, 0, 7)
+	userHold, err := registry.Place(&Hold{UserID: "alice", Reason: "case-1", CreatedBy: "legal"})
+	if err != nil {
+		t.Fatalf("Place() failed: %v", err)
+	}
+	if _, err := registry.Place(&Hold{APIKey: "sk-hash", StartTime: &start, EndTime: &end}); err != nil {
+		t.Fatalf("Place() failed: %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		record *evidence.EvidenceRecord
+		want   bool
+	}{
+		{"held user", &evidence.EvidenceRecord{UserID: "alice", RequestTime: start}, true},
+		{"other user", &evidence.EvidenceRecord{UserID: "bob", RequestTime: start}, false},
+		{"held key in range", &evidence.EvidenceRecord{APIKey: "sk-hash", RequestTime: start.Add(time.Hour)}, true},
+		{"held key after range", &evidence.EvidenceRecord{APIKey: "sk-hash", RequestTime: end.Add(time.Hour)}, false},
+	}
+	for _, tt := range tests {
+		if got := registry.IsHeld(tt.record); got != tt.want {
+			t.Errorf("%s: IsHeld() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Holds survive a restart
+	reloaded, err := NewHoldRegistry(path)
+	if err != nil {
+		t.Fatalf("NewHoldRegistry() failed: %v", err)
+	}
+	if holds := reloaded.List(false); len(holds) != 2 || holds[0].CreatedBy != "legal" {
+		t.Fatalf("Expected 2 holds after reload, got %+v", holds)
+	}
+
+	released, err := reloaded.Release(userHold.ID, "legal")
+	if err != nil {
+		t.Fatalf("Release() failed: %v", err)
+	}
+	if released.Active() || released.ReleasedBy != "legal" {
+		t.Errorf("Expected released hold, got %+v", released)
+	}
+	if reloaded.IsHeld(&evidence.EvidenceRecord{UserID: "alice"}) {
+		t.Error("Expected released hold to no longer hold records")
+	}
+	if _, err := reloaded.Release(userHold.ID, "legal"); !errors.Is(err, ErrHoldNotFound) {
+		t.Errorf("Expected ErrHoldNotFound releasing twice, got %v", err)
+	}
+	if active, all := len(reloaded.List(false)), len(reloaded.List(true)); active != 1 || all != 2 {
+		t.Errorf("Expected 1 active of 2 holds, got %d of %d", active, all)
+	}
+}
+
+// TestHoldRegistry_Handler tests the hold admin API.
+func TestHoldRegistry_Handler(t *testing.T) {
+	registry, err := NewHoldRegistry(filepath.Join(t.TempDir(), "holds.json"))
+	if err != nil {
+		t.Fatalf("NewHoldRegistry() failed: %v", err)
+	}
+	handler := registry.Handler()
+
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		handler.ServeHTTP(rec, req.WithContext(auth.WithAdminPrincipal(req.Context(), "legal@example.com")))
+		return rec
+	}
+
+	unauthenticated := httptest.NewRecorder()
+	handler.ServeHTTP(unauthenticated, httptest.NewRequest(http.MethodGet, "/", nil))
+	if unauthenticated.Code != http.StatusUnauthorized {
+		t.Errorf("unauthenticated GET status = %d, want 401", unauthenticated.Code)
+	}
+
+	rec := serve(http.MethodPost, "/", `{"user_id": "alice", "reason": "case-1", "created_by": "someone-else"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var hold Hold
+	if err := json.Unmarshal(rec.Body.Bytes(), &hold); err != nil {
+		t.Fatalf("Failed to decode hold: %v", err)
+	}
+	if hold.ID == "" || hold.UserID != "alice" {
+		t.Errorf("Unexpected hold: %+v", hold)
+	}
+	if hold.CreatedBy != "legal@example.com" {
+		t.Errorf("CreatedBy = %q, want the authenticated principal", hold.CreatedBy)
+	}
+
+	if rec := serve(http.MethodPost, "/", `{"reason": "everything"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("POST without criteria status = %d, want 400", rec.Code)
+	}
+	if rec := serve(http.MethodDelete, "/?id=unknown", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("DELETE unknown status = %d, want 404", rec.Code)
+	}
+	rec = serve(http.MethodDelete, "/?id="+hold.ID, "")
+	if rec.Code != http.StatusOK {
+		t.Errorf("DELETE status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var released Hold
+	if err := json.Unmarshal(rec.Body.Bytes(), &released); err != nil {
+		t.Fatalf("Failed to decode hold: %v", err)
+	}
+	if released.ReleasedBy != "legal@example.com" {
+		t.Errorf("ReleasedBy = %q, want the authenticated principal", released.ReleasedBy)
+	}
+	if rec := serve(http.MethodPut, "/", ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("PUT status = %d, want 405", rec.Code)
+	}
+
+	for target, want := range map[string]int{"/": 0, "/?all=true": 1} {
+		rec := serve(http.MethodGet, target, "")
+		var holds []*Hold
+		if err := json.Unmarshal(rec.Body.Bytes(), &holds); err != nil {
+			t.Fatalf("Failed to decode holds: %v", err)
+		}
+		if len(holds) != want {
+			t.Errorf("GET %s: got %d holds, want %d", target, len(holds), want)
+		}
+	}
+}
+
+// TestPruner_LegalHold tests that pruning skips records under legal hold.
+func TestPruner_LegalHold(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	registry, err := NewHoldRegistry(filepath.Join(t.TempDir(), "holds.json"))
+	if err != nil {
+		t.Fatalf("NewHoldRegistry() failed: %v", err)
+	}
+	if _, err := registry.Place(&Hold{UserID: "alice"}); err != nil {
+		t.Fatalf("Place() failed: %v", err)
+	}
+
+	newStore := func(t *testing.T) *storage.MemoryStorage {
+		store := storage.NewMemoryStorage()
+		for i, user := range []string{"alice", "bob", "alice", "bob"} {
+			record := &evidence.EvidenceRecord{
+				ID:          fmt.Sprintf("%s-%d", user, i),
+				RequestID:   fmt.Sprintf("req-%d", i),
+				RequestTime: now.AddDate(0, 0, -10+i), // 10 to 7 days old
+				UserID:      user,
+			}
+			if err := store.Store(ctx, record); err != nil {
+				t.Fatalf("Store() failed: %v", err)
+			}
+		}
+		return store
+	}
+
+	t.Run("by age", func(t *testing.T) {
+		store := newStore(t)
+		pruner := NewPruner(store, &Config{RetentionDays: 5, Holds: registry})
+		deleted, err := pruner.Prune(ctx)
+		if err != nil {
+			t.Fatalf("Prune() failed: %v", err)
+		}
+		if deleted != 2 {
+			t.Errorf("Expected 2 deleted records, got %d", deleted)
+		}
+		if store.GetByID("alice-0") == nil || store.GetByID("alice-2") == nil {
+			t.Error("Expected held records to be kept")
+		}
+	})
+
+	t.Run("by count", func(t *testing.T) {
+		store := newStore(t)
+		pruner := NewPruner(store, &Config{MaxRecords: 3, Holds: registry})
+		deleted, err := pruner.Prune(ctx)
+		if err != nil {
+			t.Fatalf("Prune() failed: %v", err)
+		}
+		if deleted != 1 || store.GetByID("bob-1") != nil {
+			t.Errorf("Expected oldest unheld record bob-1 deleted, deleted %d", deleted)
+		}
+	})
+}
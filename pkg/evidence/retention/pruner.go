@@ -6,6 +6,7 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"mercator-hq/jupiter/pkg/evidence"
@@ -18,6 +19,12 @@ type Config struct {
 	// 0 means keep evidence forever (no pruning).
 	RetentionDays int
 
+	// Rules set different retention periods for the records of tenants,
+	// policy decisions, or data categories. A record matching several
+	// rules is kept for the longest of their periods; records matching
+	// none are kept for RetentionDays.
+	Rules []Rule
+
 	// PruneSchedule is a cron expression for scheduling pruning.
 	// Example: "0 3 * * *" (daily at 3 AM)
 	PruneSchedule string
@@ -28,9 +35,22 @@ type Config struct {
 	// ArchivePath is the directory to store archived evidence.
 	ArchivePath string
 
+	// ArchiveStore stores archives instead of ArchivePath, e.g. in an S3
+	// bucket, as compressed JSON Lines with a checksum manifest. Records
+	// are only deleted once both are uploaded. Optional.
+	ArchiveStore ArchiveStore
+
 	// MaxRecords is the maximum number of records to keep.
 	// 0 means unlimited.
 	MaxRecords int64
+
+	// Holds reports records under legal hold, which are never pruned.
+	// Optional.
+	Holds evidence.HoldChecker
+
+	// Anonymizer anonymizes aging records on the prune schedule, before
+	// pruning. Optional.
+	Anonymizer *Anonymizer
 }
 
 // DefaultConfig returns the default retention configuration.
@@ -44,6 +64,14 @@ func DefaultConfig() *Config {
 	}
 }
 
+// deleteBatchSize is the number of records deleted per storage call when
+// records are pruned by ID.
+const deleteBatchSize = 500
+
+// prunePageSize is the number of records read per storage query when
+// records are pruned by ID.
+const prunePageSize = 1000
+
 // Pruner enforces retention policies on evidence records.
 type Pruner struct {
 	storage   evidence.Storage
@@ -83,7 +111,7 @@ func (p *Pruner) Prune(ctx context.Context) (int64, error) {
 	var totalDeleted int64
 
 	// Phase 1: Prune by retention period
-	if p.config.RetentionDays > 0 {
+	if p.config.RetentionDays > 0 || len(p.config.Rules) > 0 {
 		deleted, err := p.pruneByAge(ctx)
 		if err != nil {
 			return totalDeleted, fmt.Errorf("prune by age failed: %w", err)
@@ -92,6 +120,7 @@ func (p *Pruner) Prune(ctx context.Context) (int64, error) {
 		p.logger.Info("pruned records by age",
 			"deleted_count", deleted,
 			"retention_days", p.config.RetentionDays,
+			"rules", len(p.config.Rules),
 		)
 	}
 
@@ -133,16 +162,30 @@ func (p *Pruner) pruneByAge(ctx context.Context) (int64, error) {
 		"retention_days", p.config.RetentionDays,
 	)
 
-	// Query for records older than cutoff
+	// Retention rules apply per record, and held records are skipped, so
+	// the records are deleted by ID
+	if len(p.config.Rules) > 0 || p.config.Holds != nil {
+		deleted, err := p.pruneExpired(ctx)
+		if err != nil {
+			return deleted, evidence.NewRetentionError(p.config.RetentionDays, err)
+		}
+		return deleted, nil
+	}
+
 	query := &evidence.Query{
 		EndTime: &cutoff,
 	}
 
-	// Archive before delete if configured
+	// Archived records are deleted by ID, so that records stored while
+	// archiving are not deleted without being archived
 	if p.config.ArchiveBeforeDelete {
-		if err := p.archive(ctx, query); err != nil {
-			return 0, evidence.NewRetentionError(p.config.RetentionDays, err)
+		deleted, err := p.prunePages(ctx, query, "evidence", func(page []*evidence.EvidenceRecord) ([]*evidence.EvidenceRecord, bool) {
+			return page, false
+		})
+		if err != nil {
+			return deleted, evidence.NewRetentionError(p.config.RetentionDays, err)
 		}
+		return deleted, nil
 	}
 
 	// Delete old records
@@ -154,6 +197,33 @@ func (p *Pruner) pruneByAge(ctx context.Context) (int64, error) {
 	return deleted, nil
 }
 
+// pruneExpired deletes the records older than their retention period that
+// are not under legal hold.
+func (p *Pruner) pruneExpired(ctx context.Context) (int64, error) {
+	minDays := p.config.minRetentionDays()
+	if minDays == 0 {
+		return 0, nil
+	}
+
+	now := time.Now()
+	cutoff := now.AddDate(0, 0, -minDays)
+	query := &evidence.Query{EndTime: &cutoff}
+
+	return p.prunePages(ctx, query, "evidence", func(page []*evidence.EvidenceRecord) ([]*evidence.EvidenceRecord, bool) {
+		expired := make([]*evidence.EvidenceRecord, 0, len(page))
+		for _, record := range page {
+			days := p.config.retentionDays(record)
+			if days > 0 && record.RequestTime.Before(now.AddDate(0, 0, -days)) {
+				expired = append(expired, record)
+			}
+		}
+		if p.config.Holds != nil {
+			expired = p.withoutHeld(expired)
+		}
+		return expired, false
+	})
+}
+
 // pruneByCount deletes oldest records if total count exceeds max_records.
 func (p *Pruner) pruneByCount(ctx context.Context) (int64, error) {
 	// Count total records
@@ -179,75 +249,97 @@ func (p *Pruner) pruneByCount(ctx context.Context) (int64, error) {
 		"to_delete", toDelete,
 	)
 
-	// Query ALL records since storage backend may not support sorting
-	allRecords, err := p.storage.Query(ctx, &evidence.Query{})
-	if err != nil {
-		return 0, fmt.Errorf("failed to query records: %w", err)
-	}
-
-	if len(allRecords) == 0 {
-		p.logger.Debug("no records found to delete")
-		return 0, nil
-	}
+	// Held records count towards the limit but are kept, so the oldest
+	// records not under hold are deleted
+	var selected int64
+	return p.prunePages(ctx, &evidence.Query{}, "evidence-count", func(page []*evidence.EvidenceRecord) ([]*evidence.EvidenceRecord, bool) {
+		if p.config.Holds != nil {
+			page = p.withoutHeld(page)
+		}
+		page = page[:min(int64(len(page)), toDelete-selected)]
+		selected += int64(len(page))
+		return page, selected >= toDelete
+	})
+}
 
-	// Sort records by timestamp (oldest first)
-	sortRecordsByTime(allRecords)
+// prunePages reads the records matching query oldest first, a page at a
+// time, and deletes the records selectRecords picks from each page. The
+// selected records of a page are archived, if configured, and deleted
+// before the next page is read, so memory use does not grow with the
+// number of records and only archived records are deleted. Pruning stops
+// after the last page, or once selectRecords reports it is done.
+func (p *Pruner) prunePages(ctx context.Context, query *evidence.Query, archivePrefix string, selectRecords func(page []*evidence.EvidenceRecord) (selected []*evidence.EvidenceRecord, done bool)) (int64, error) {
+	cursor := export.NewCursor(p.storage, query, prunePageSize)
+	started := time.Now()
+
+	var deleted int64
+	for page := 1; ; page++ {
+		records, err := cursor.Next(ctx)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to query records: %w", err)
+		}
+		if len(records) == 0 {
+			return deleted, nil
+		}
 
-	// Determine how many to actually delete (in case count changed)
-	actualToDelete := len(allRecords) - int(p.config.MaxRecords)
-	if actualToDelete <= 0 {
-		p.logger.Debug("record count within limit after query")
-		return 0, nil
-	}
-	if actualToDelete > len(allRecords) {
-		actualToDelete = len(allRecords)
+		selected, done := selectRecords(records)
+		if p.config.ArchiveBeforeDelete {
+			if err := p.archiveRecords(ctx, selected, archiveName(archivePrefix, started, page)); err != nil {
+				return deleted, fmt.Errorf("archive failed: %w", err)
+			}
+		}
+		n, err := p.deleteRecords(ctx, selected)
+		deleted += n
+		if err != nil {
+			return deleted, fmt.Errorf("delete failed: %w", err)
+		}
+		if done {
+			return deleted, nil
+		}
 	}
+}
 
-	// Get the cutoff time: time of the last record to delete
-	cutoffTime := allRecords[actualToDelete-1].RequestTime
-
-	p.logger.Debug("calculated cutoff time for count-based pruning",
-		"cutoff_time", cutoffTime,
-		"records_to_delete", actualToDelete,
-	)
-
-	// Create delete query for records older than or equal to cutoff
-	deleteQuery := &evidence.Query{
-		EndTime: &cutoffTime,
+// withoutHeld returns the records that are not under legal hold.
+func (p *Pruner) withoutHeld(records []*evidence.EvidenceRecord) []*evidence.EvidenceRecord {
+	unheld := make([]*evidence.EvidenceRecord, 0, len(records))
+	for _, record := range records {
+		if !p.config.Holds.IsHeld(record) {
+			unheld = append(unheld, record)
+		}
 	}
+	if held := len(records) - len(unheld); held > 0 {
+		p.logger.Info("keeping records under legal hold", "held_count", held)
+	}
+	return unheld
+}
 
-	// Archive if configured
-	if p.config.ArchiveBeforeDelete {
-		recordsToArchive := allRecords[:actualToDelete]
-		if err := p.archiveRecords(ctx, recordsToArchive); err != nil {
-			return 0, fmt.Errorf("archive failed: %w", err)
+// deleteRecords deletes records by ID, in batches.
+func (p *Pruner) deleteRecords(ctx context.Context, records []*evidence.EvidenceRecord) (int64, error) {
+	var deleted int64
+	for start := 0; start < len(records); start += deleteBatchSize {
+		batch := records[start:min(start+deleteBatchSize, len(records))]
+		ids := make([]string, len(batch))
+		for i, record := range batch {
+			ids[i] = record.ID
 		}
-	}
 
-	// Delete records
-	deleted, err := p.storage.Delete(ctx, deleteQuery)
-	if err != nil {
-		return 0, fmt.Errorf("delete failed: %w", err)
+		n, err := p.storage.Delete(ctx, &evidence.Query{IDs: ids})
+		deleted += n
+		if err != nil {
+			return deleted, err
+		}
 	}
-
 	return deleted, nil
 }
 
-// sortRecordsByTime sorts evidence records by RequestTime in ascending order (oldest first).
-func sortRecordsByTime(records []*evidence.EvidenceRecord) {
-	// Simple bubble sort - fine for test environment
-	// In production, consider using sort.Slice
-	for i := 0; i < len(records)-1; i++ {
-		for j := i + 1; j < len(records); j++ {
-			if records[i].RequestTime.After(records[j].RequestTime) {
-				records[i], records[j] = records[j], records[i]
-			}
-		}
-	}
+// archiveName returns the name of the archive of a page of records pruned
+// by a prune run started at start.
+func archiveName(prefix string, start time.Time, page int) string {
+	return fmt.Sprintf("%s-%s-%04d.json", prefix, start.Format("2006-01-02-150405"), page)
 }
 
 // archiveRecords exports a list of evidence records to JSON before deletion.
-func (p *Pruner) archiveRecords(ctx context.Context, records []*evidence.EvidenceRecord) error {
+func (p *Pruner) archiveRecords(ctx context.Context, records []*evidence.EvidenceRecord, name string) error {
 	if len(records) == 0 {
 		return nil
 	}
@@ -256,46 +348,8 @@ func (p *Pruner) archiveRecords(ctx context.Context, records []*evidence.Evidenc
 		"record_count", len(records),
 	)
 
-	// Create archive directory if it doesn't exist
-	if err := os.MkdirAll(p.config.ArchivePath, 0755); err != nil {
-		return fmt.Errorf("failed to create archive directory: %w", err)
-	}
-
-	// Create archive file
-	archiveFile := filepath.Join(p.config.ArchivePath, fmt.Sprintf("evidence-count-%s.json", time.Now().Format("2006-01-02-150405")))
-	f, err := os.Create(archiveFile)
-	if err != nil {
-		return fmt.Errorf("failed to create archive file: %w", err)
-	}
-	defer f.Close()
-
-	// Export records to JSON
-	exporter := export.NewJSONExporter(true)
-	if err := exporter.Export(ctx, records, f); err != nil {
-		return fmt.Errorf("failed to export records to archive: %w", err)
-	}
-
-	p.logger.Info("evidence records archived",
-		"archive_file", archiveFile,
-		"record_count", len(records),
-	)
-
-	return nil
-}
-
-// archive exports evidence records to JSON before deletion.
-func (p *Pruner) archive(ctx context.Context, query *evidence.Query) error {
-	p.logger.Info("archiving evidence before deletion")
-
-	// Query records to archive
-	records, err := p.storage.Query(ctx, query)
-	if err != nil {
-		return fmt.Errorf("failed to query records for archiving: %w", err)
-	}
-
-	if len(records) == 0 {
-		p.logger.Debug("no records to archive")
-		return nil
+	if p.config.ArchiveStore != nil {
+		return p.uploadArchive(ctx, records, strings.TrimSuffix(name, ".json"))
 	}
 
 	// Create archive directory if it doesn't exist
@@ -304,7 +358,7 @@ func (p *Pruner) archive(ctx context.Context, query *evidence.Query) error {
 	}
 
 	// Create archive file
-	archiveFile := filepath.Join(p.config.ArchivePath, fmt.Sprintf("evidence-%s.json", time.Now().Format("2006-01-02")))
+	archiveFile := filepath.Join(p.config.ArchivePath, name)
 	f, err := os.Create(archiveFile)
 	if err != nil {
 		return fmt.Errorf("failed to create archive file: %w", err)
@@ -316,8 +370,11 @@ func (p *Pruner) archive(ctx context.Context, query *evidence.Query) error {
 	if err := exporter.Export(ctx, records, f); err != nil {
 		return fmt.Errorf("failed to export records to archive: %w", err)
 	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write archive file: %w", err)
+	}
 
-	p.logger.Info("evidence archived",
+	p.logger.Info("evidence records archived",
 		"archive_file", archiveFile,
 		"record_count", len(records),
 	)
@@ -2,8 +2,11 @@ package retention
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -562,6 +565,111 @@ func TestPruner_PruneByCount(t *testing.T) {
 	}
 }
 
+// TestPruner_PagesThroughSQLite tests pruning record by record on a
+// backend whose queries return 100 records unless a limit is given.
+func TestPruner_PagesThroughSQLite(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	const total = prunePageSize + 50
+
+	newStore := func(t *testing.T) *storage.SQLiteStorage {
+		store, err := storage.NewSQLiteStorage(&storage.SQLiteConfig{
+			Path:         filepath.Join(t.TempDir(), "evidence.db"),
+			MaxOpenConns: 1,
+			BusyTimeout:  time.Second,
+		})
+		if err != nil {
+			t.Fatalf("NewSQLiteStorage() failed: %v", err)
+		}
+		t.Cleanup(func() { _ = store.Close() })
+
+		for i := 0; i < total; i++ {
+			record := &evidence.EvidenceRecord{
+				ID:          fmt.Sprintf("rec-%04d", i),
+				RequestID:   fmt.Sprintf("req-%04d", i),
+				RequestTime: now.AddDate(0, 0, -30).Add(-time.Duration(i) * time.Minute),
+			}
+			if err := store.Store(ctx, record); err != nil {
+				t.Fatalf("Store() failed: %v", err)
+			}
+		}
+		return store
+	}
+	held := heldIDs{"rec-0000": true, "rec-1020": true}
+
+	t.Run("by age around holds", func(t *testing.T) {
+		store := newStore(t)
+		pruner := NewPruner(store, &Config{RetentionDays: 7, Holds: held})
+		deleted, err := pruner.Prune(ctx)
+		if err != nil {
+			t.Fatalf("Prune() failed: %v", err)
+		}
+		if deleted != total-2 {
+			t.Errorf("Expected %d deleted records, got %d", total-2, deleted)
+		}
+	})
+
+	t.Run("by retention rules", func(t *testing.T) {
+		store := newStore(t)
+		pruner := NewPruner(store, &Config{RetentionDays: 7, Rules: []Rule{{PolicyDecision: "block", Days: 365}}})
+		deleted, err := pruner.Prune(ctx)
+		if err != nil {
+			t.Fatalf("Prune() failed: %v", err)
+		}
+		if deleted != total {
+			t.Errorf("Expected %d deleted records, got %d", total, deleted)
+		}
+	})
+
+	t.Run("by count around holds", func(t *testing.T) {
+		store := newStore(t)
+		pruner := NewPruner(store, &Config{MaxRecords: 100, Holds: held})
+		deleted, err := pruner.Prune(ctx)
+		if err != nil {
+			t.Fatalf("Prune() failed: %v", err)
+		}
+		if deleted != total-100 {
+			t.Errorf("Expected %d deleted records, got %d", total-100, deleted)
+		}
+		remaining, err := store.Query(ctx, &evidence.Query{Limit: total})
+		if err != nil {
+			t.Fatalf("Query() failed: %v", err)
+		}
+		for _, record := range remaining {
+			if record.ID > "rec-0098" && !held[record.ID] {
+				t.Errorf("Expected %s to be pruned as one of the oldest records", record.ID)
+			}
+		}
+	})
+
+	t.Run("by age with archive", func(t *testing.T) {
+		store := newStore(t)
+		archives := &memoryArchiveStore{objects: make(map[string][]byte)}
+		pruner := NewPruner(store, &Config{RetentionDays: 7, ArchiveBeforeDelete: true, ArchiveStore: archives})
+		deleted, err := pruner.Prune(ctx)
+		if err != nil {
+			t.Fatalf("Prune() failed: %v", err)
+		}
+		if deleted != total {
+			t.Errorf("Expected %d deleted records, got %d", total, deleted)
+		}
+
+		archived := 0
+		for name, data := range archives.objects {
+			if strings.HasSuffix(name, ".manifest.json") {
+				var manifest ArchiveManifest
+				if err := json.Unmarshal(data, &manifest); err != nil {
+					t.Fatalf("invalid manifest: %v", err)
+				}
+				archived += manifest.Records
+			}
+		}
+		if archived != total {
+			t.Errorf("Expected %d archived records, got %d", total, archived)
+		}
+	})
+}
+
 // TestPruner_BothAgeAndCount tests that both age-based and count-based pruning work together.
 func TestPruner_BothAgeAndCount(t *testing.T) {
 	store := storage.NewMemoryStorage()
@@ -0,0 +1,104 @@
+package retention
+
+import (
+	"slices"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+// Data categories of evidence records, matched by Rule.Category. Any other
+// category matches records with that PII type (e.g. "email").
+const (
+	// CategoryPII matches records in which PII was detected.
+	CategoryPII = "pii"
+
+	// CategoryCaptured matches records with captured request or response
+	// bodies.
+	CategoryCaptured = "captured"
+
+	// CategoryError matches records of failed requests.
+	CategoryError = "error"
+)
+
+// Rule sets the retention period of the records it matches, instead of
+// Config.RetentionDays. A record matches a rule if it matches every
+// criterion set on the rule; at least one should be set.
+type Rule struct {
+	// TeamID matches the records of a tenant (team).
+	TeamID string
+
+	// PolicyDecision matches records by policy decision, e.g. "block".
+	PolicyDecision string
+
+	// Category matches records by data category (see CategoryPII).
+	Category string
+
+	// Days is the number of days to retain matching records.
+	// 0 means keep them forever.
+	Days int
+}
+
+// Matches reports whether the rule applies to a record.
+func (r *Rule) Matches(record *evidence.EvidenceRecord) bool {
+	if r.TeamID != "" && record.TeamID != r.TeamID {
+		return false
+	}
+	if r.PolicyDecision != "" && record.PolicyDecision != r.PolicyDecision {
+		return false
+	}
+	if r.Category != "" && !hasCategory(record, r.Category) {
+		return false
+	}
+	return true
+}
+
+// hasCategory reports whether a record belongs to a data category.
+func hasCategory(record *evidence.EvidenceRecord, category string) bool {
+	switch category {
+	case CategoryPII:
+		return record.PIIDetected
+	case CategoryCaptured:
+		return record.RequestBodyRef != "" || record.ResponseBodyRef != ""
+	case CategoryError:
+		return record.Error != ""
+	default:
+		return slices.Contains(record.PIITypes, category)
+	}
+}
+
+// retentionDays returns the number of days to retain a record: the longest
+// period of the rules it matches, or RetentionDays if it matches none.
+// 0 means forever.
+func (c *Config) retentionDays(record *evidence.EvidenceRecord) int {
+	days, matched := 0, false
+	for i := range c.Rules {
+		rule := &c.Rules[i]
+		if !rule.Matches(record) {
+			continue
+		}
+		if rule.Days == 0 {
+			return 0
+		}
+		if !matched || rule.Days > days {
+			days = rule.Days
+		}
+		matched = true
+	}
+	if !matched {
+		return c.RetentionDays
+	}
+	return days
+}
+
+// minRetentionDays returns the shortest retention period of any record, or
+// 0 if every record is kept forever. Records older than that are the
+// candidates for pruning.
+func (c *Config) minRetentionDays() int {
+	days := c.RetentionDays
+	for _, rule := range c.Rules {
+		if rule.Days > 0 && (days == 0 || rule.Days < days) {
+			days = rule.Days
+		}
+	}
+	return days
+}
@@ -0,0 +1,86 @@
+package retention
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/evidence/storage"
+)
+
+// TestConfig_RetentionDays tests choosing the retention period of a record.
+func TestConfig_RetentionDays(t *testing.T) {
+	config := &Config{
+		RetentionDays: 30,
+		Rules: []Rule{
+			{PolicyDecision: "block", Days: 365},
+			{TeamID: "finance", Days: 90},
+			{TeamID: "finance", Category: CategoryPII, Days: 7},
+			{Category: "ssn", Days: 0},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		record *evidence.EvidenceRecord
+		want   int
+	}{
+		{"no rule", &evidence.EvidenceRecord{PolicyDecision: "allow"}, 30},
+		{"blocked", &evidence.EvidenceRecord{PolicyDecision: "block"}, 365},
+		{"tenant", &evidence.EvidenceRecord{TeamID: "finance", PolicyDecision: "allow"}, 90},
+		{"longest of matching rules", &evidence.EvidenceRecord{TeamID: "finance", PIIDetected: true}, 90},
+		{"blocked tenant", &evidence.EvidenceRecord{TeamID: "finance", PolicyDecision: "block"}, 365},
+		{"forever", &evidence.EvidenceRecord{PIIDetected: true, PIITypes: []string{"ssn"}}, 0},
+	}
+	for _, tt := range tests {
+		if got := config.retentionDays(tt.record); got != tt.want {
+			t.Errorf("%s: retentionDays() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+
+	if got := config.minRetentionDays(); got != 7 {
+		t.Errorf("minRetentionDays() = %d, want 7", got)
+	}
+}
+
+// TestPruner_Rules tests pruning with per-tenant and per-decision retention.
+func TestPruner_Rules(t *testing.T) {
+	store := storage.NewMemoryStorage()
+	ctx := context.Background()
+	now := time.Now()
+
+	records := []*evidence.EvidenceRecord{
+		{ID: "allow-old", PolicyDecision: "allow", RequestTime: now.AddDate(0, 0, -40)},
+		{ID: "allow-recent", PolicyDecision: "allow", RequestTime: now.AddDate(0, 0, -20)},
+		{ID: "block-old", PolicyDecision: "block", RequestTime: now.AddDate(0, 0, -40)},
+		{ID: "block-ancient", PolicyDecision: "block", RequestTime: now.AddDate(0, 0, -400)},
+		{ID: "team-recent", TeamID: "short", PolicyDecision: "allow", RequestTime: now.AddDate(0, 0, -10)},
+	}
+	for _, record := range records {
+		record.RequestID = "req-" + record.ID
+		if err := store.Store(ctx, record); err != nil {
+			t.Fatalf("Store() failed: %v", err)
+		}
+	}
+
+	pruner := NewPruner(store, &Config{
+		RetentionDays: 30,
+		Rules: []Rule{
+			{PolicyDecision: "block", Days: 365},
+			{TeamID: "short", Days: 7},
+		},
+	})
+	deleted, err := pruner.Prune(ctx)
+	if err != nil {
+		t.Fatalf("Prune() failed: %v", err)
+	}
+	if deleted != 3 {
+		t.Errorf("Expected 3 deleted records, got %d", deleted)
+	}
+	for _, id := range []string{"allow-recent", "block-old"} {
+		if store.GetByID(id) == nil {
+			t.Errorf("Expected %s to be kept", id)
+		}
+	}
+}
@@ -85,6 +85,14 @@ func (s *Scheduler) Start(ctx context.Context) error {
 
 // runPruning executes a pruning cycle.
 func (s *Scheduler) runPruning(ctx context.Context) {
+	if anonymizer := s.pruner.config.Anonymizer; anonymizer != nil {
+		if _, err := anonymizer.Anonymize(ctx); err != nil {
+			s.logger.Error("scheduled anonymization failed",
+				"error", err,
+			)
+		}
+	}
+
 	s.logger.Info("starting scheduled evidence pruning")
 
 	deleted, err := s.pruner.Prune(ctx)
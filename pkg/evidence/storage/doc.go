@@ -7,8 +7,8 @@
 //
 //   - SQLite: Embedded database for single-node deployments (MVP)
 //   - Memory: In-memory storage for testing
+//   - S3: Long-term archival storage in S3-compatible object stores
 //   - PostgreSQL: High-volume production deployments (Phase 2)
-//   - S3: Long-term archival storage (Phase 2)
 //
 // # SQLite Backend
 //
@@ -20,6 +20,18 @@
 //   - Connection pooling for concurrent access
 //   - Busy timeout for handling locks
 //
+// # S3 Backend
+//
+// The S3 backend buffers records and writes them in batches as JSON Lines
+// objects (gzip-compressed by default), partitioned by request hour:
+//
+//	<prefix>/2025/11/16/09/<write-timestamp>-<first-record-id>.jsonl.gz
+//
+// Queries list and scan the partitions overlapping the requested time range,
+// so narrow time ranges are cheap and unbounded queries read every object.
+// Records still in the buffer are included in query results. Custom endpoints
+// (MinIO, R2, etc.) use path-style addressing.
+//
 // # Basic Usage
 //
 //	// Create SQLite storage
@@ -53,6 +65,22 @@
 //	    log.Fatal(err)
 //	}
 //
+// # Full-Text Search
+//
+// Query.Search finds records whose prompts or response excerpts contain all
+// of its words and "quoted phrases". SQLite maintains a full-text index
+// (evidence_fts) kept in sync by triggers, using FTS5 when built with the
+// sqlite_fts5 tag and FTS4 otherwise; words match whole tokens, ignoring
+// case. The memory and S3 backends scan records and match substrings.
+//
+// # Write-Once Mode
+//
+// With SQLiteConfig.WriteOnce or S3Config.WriteOnce, Delete returns
+// evidence.ErrWriteOnce. SQLite additionally installs triggers rejecting
+// UPDATE and DELETE on the evidence table, which remain after write-once
+// mode is turned off. S3 objects can be written with S3 Object Lock
+// (S3Config.ObjectLockMode), so that S3 itself refuses to delete them.
+//
 // # Thread Safety
 //
 // All storage backends are thread-safe and support concurrent access:
@@ -64,7 +92,8 @@
 // # Schema Migration
 //
 // The SQLite storage automatically initializes the database schema on first use.
-// Schema version is tracked in the schema_version table for future migrations.
+// Schema version is tracked in the schema_version table. Databases created by
+// an earlier version are migrated in place when opened (see Migrations).
 //
 // # Performance
 //
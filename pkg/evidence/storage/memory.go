@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"slices"
 	"sync"
 
 	"mercator-hq/jupiter/pkg/evidence"
@@ -42,15 +43,15 @@ func (s *MemoryStorage) Query(ctx context.Context, query *evidence.Query) ([]*ev
 
 	// Filter records
 	for _, record := range s.records {
-		if s.matchesQuery(record, query) {
+		if matchesQuery(record, query) {
 			// Create a copy to avoid mutation
 			recordCopy := *record
 			results = append(results, &recordCopy)
 		}
 	}
 
-	// Sort results (simple implementation for testing)
-	// In production, use more sophisticated sorting
+	// Sort results like the other backends so pagination is stable
+	sortRecords(results, query.SortBy, query.SortOrder)
 
 	// Apply pagination
 	start := query.Offset
@@ -97,7 +98,7 @@ func (s *MemoryStorage) QueryStream(ctx context.Context, query *evidence.Query)
 			}
 
 			// Check if record matches query
-			if !s.matchesQuery(record, query) {
+			if !matchesQuery(record, query) {
 				continue
 			}
 
@@ -137,7 +138,7 @@ func (s *MemoryStorage) Count(ctx context.Context, query *evidence.Query) (int64
 	var count int64
 
 	for _, record := range s.records {
-		if s.matchesQuery(record, query) {
+		if matchesQuery(record, query) {
 			count++
 		}
 	}
@@ -155,7 +156,7 @@ func (s *MemoryStorage) Delete(ctx context.Context, query *evidence.Query) (int6
 	// Find records to delete
 	toDelete := []string{}
 	for id, record := range s.records {
-		if s.matchesQuery(record, query) {
+		if matchesQuery(record, query) {
 			toDelete = append(toDelete, id)
 		}
 	}
@@ -169,6 +170,20 @@ func (s *MemoryStorage) Delete(ctx context.Context, query *evidence.Query) (int6
 	return deleted, nil
 }
 
+// Update replaces stored records with the same IDs.
+func (s *MemoryStorage) Update(ctx context.Context, records []*evidence.EvidenceRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, record := range records {
+		if _, ok := s.records[record.ID]; ok {
+			recordCopy := *record
+			s.records[record.ID] = &recordCopy
+		}
+	}
+	return nil
+}
+
 // Close releases resources held by the storage backend.
 func (s *MemoryStorage) Close() error {
 	s.mu.Lock()
@@ -179,12 +194,32 @@ func (s *MemoryStorage) Close() error {
 }
 
 // matchesQuery checks if a record matches the query filters.
-func (s *MemoryStorage) matchesQuery(record *evidence.EvidenceRecord, query *evidence.Query) bool {
+func matchesQuery(record *evidence.EvidenceRecord, query *evidence.Query) bool {
 	// Time range filter
-	if query.StartTime != nil && record.RequestTime.Before(*query.StartTime) {
+	at := record.RequestTime
+	if query.TimeField == "recorded_time" {
+		at = record.RecordedTime
+	}
+	if query.StartTime != nil && at.Before(*query.StartTime) {
 		return false
 	}
-	if query.EndTime != nil && record.RequestTime.After(*query.EndTime) {
+	if query.StartTime != nil && query.AfterID != "" && at.Equal(*query.StartTime) && record.ID <= query.AfterID {
+		return false
+	}
+	if query.EndTime != nil && at.After(*query.EndTime) {
+		return false
+	}
+
+	// ID filter
+	if len(query.IDs) > 0 && !slices.Contains(query.IDs, record.ID) {
+		return false
+	}
+
+	// Correlation filters
+	if query.TraceID != "" && record.TraceID != query.TraceID {
+		return false
+	}
+	if query.DecisionID != "" && record.DecisionID != query.DecisionID {
 		return false
 	}
 
@@ -192,6 +227,12 @@ func (s *MemoryStorage) matchesQuery(record *evidence.EvidenceRecord, query *evi
 	if query.UserID != "" && record.UserID != query.UserID {
 		return false
 	}
+	if query.TeamID != "" && record.TeamID != query.TeamID {
+		return false
+	}
+	if query.CostTag != "" && record.CostTag != query.CostTag {
+		return false
+	}
 	if query.APIKey != "" && record.APIKey != query.APIKey {
 		return false
 	}
@@ -209,6 +250,11 @@ func (s *MemoryStorage) matchesQuery(record *evidence.EvidenceRecord, query *evi
 		return false
 	}
 
+	// Tool filter
+	if query.ToolName != "" && !calledTool(record, query.ToolName) {
+		return false
+	}
+
 	// Cost thresholds
 	if query.MinCost != nil && record.ActualCost < *query.MinCost {
 		return false
@@ -243,6 +289,16 @@ func (s *MemoryStorage) matchesQuery(record *evidence.EvidenceRecord, query *evi
 		}
 	}
 
+	// Anonymization filter
+	if query.Anonymized != nil && (record.AnonymizedAt != nil) != *query.Anonymized {
+		return false
+	}
+
+	// Full-text search
+	if query.Search != "" && !matchesSearch(record, query.Search) {
+		return false
+	}
+
 	return true
 }
 
@@ -276,3 +332,13 @@ func (s *MemoryStorage) Size() int {
 
 	return len(s.records)
 }
+
+// calledTool reports whether a record's response called the named tool.
+func calledTool(record *evidence.EvidenceRecord, name string) bool {
+	for _, call := range record.ToolCalls {
+		if call.Name == name {
+			return true
+		}
+	}
+	return false
+}
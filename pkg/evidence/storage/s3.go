@@ -0,0 +1,610 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/md5"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/security/sigv4"
+)
+
+// S3Config contains configuration for the S3 storage backend.
+type S3Config struct {
+	// Bucket is the bucket evidence objects are written to.
+	Bucket string
+
+	// Region is the AWS region of the bucket.
+	Region string
+
+	// Prefix is an optional key prefix for all evidence objects.
+	Prefix string
+
+	// Endpoint is an optional S3-compatible endpoint (e.g., "http://minio:9000").
+	// Custom endpoints use path-style addressing.
+	Endpoint string
+
+	// Credentials are the AWS credentials used to sign requests.
+	Credentials sigv4.Credentials
+
+	// BatchSize is the number of records buffered before an object is written.
+	// Default: 1000
+	BatchSize int
+
+	// FlushInterval is the maximum time records stay buffered.
+	// Default: 1 minute
+	FlushInterval time.Duration
+
+	// Compress gzips objects (".jsonl.gz").
+	// Default: true
+	Compress bool
+
+	// WriteOnce makes evidence records write-once (WORM): Delete returns
+	// evidence.ErrWriteOnce.
+	WriteOnce bool
+
+	// ObjectLockMode is the S3 Object Lock mode ("GOVERNANCE" or
+	// "COMPLIANCE") set on written objects, which S3 then refuses to delete
+	// or overwrite until ObjectLockRetention has passed. The bucket must
+	// have Object Lock enabled. Empty disables object locks.
+	ObjectLockMode string
+
+	// ObjectLockRetention is how long written objects are locked.
+	ObjectLockRetention time.Duration
+
+	// Client is the HTTP client used for S3 requests (optional).
+	Client *http.Client
+}
+
+// DefaultS3Config returns the default S3 configuration.
+func DefaultS3Config() *S3Config {
+	return &S3Config{
+		BatchSize:     1000,
+		FlushInterval: time.Minute,
+		Compress:      true,
+	}
+}
+
+// s3PartitionLayout is the time partition of an object key (yyyy/mm/dd/hh).
+const s3PartitionLayout = "2006/01/02/15"
+
+// S3Storage implements the Storage interface on S3-compatible object storage.
+//
+// Records are buffered and written in batches as JSON Lines objects, time
+// partitioned by request hour:
+//
+//	s3://bucket/prefix/yyyy/mm/dd/hh/<timestamp>-<record id>.jsonl.gz
+//
+// The layout is intended for long-term archival and data-lake ingestion
+// (Athena, Spark, etc.). Queries list and scan the partitions covering the
+// query time range, so they are slower than the SQLite backend; bound
+// queries by time where possible.
+type S3Storage struct {
+	config *S3Config
+	client *s3Client
+	logger *slog.Logger
+
+	// mu protects the write buffer
+	mu     sync.Mutex
+	buffer []*evidence.EvidenceRecord
+
+	// flushMu serializes flushes so objects are not written concurrently
+	flushMu sync.Mutex
+
+	stopCh    chan struct{}
+	wg        sync.WaitGroup
+	closeOnce sync.Once
+}
+
+// NewS3Storage creates a new S3 storage backend and starts its flush loop.
+func NewS3Storage(config *S3Config) (*S3Storage, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("bucket cannot be empty")
+	}
+	if config.Region == "" {
+		return nil, fmt.Errorf("region cannot be empty")
+	}
+	if config.ObjectLockMode != "" && config.ObjectLockRetention <= 0 {
+		return nil, fmt.Errorf("object lock retention must be positive")
+	}
+
+	cfg := *config
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = DefaultS3Config().BatchSize
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = DefaultS3Config().FlushInterval
+	}
+	if !cfg.Credentials.Valid() {
+		cfg.Credentials = sigv4.CredentialsFromEnv()
+	}
+	cfg.Prefix = strings.Trim(cfg.Prefix, "/")
+
+	client, err := newS3Client(&cfg)
+	if err != nil {
+		return nil, evidence.NewStorageError("s3", "open", err)
+	}
+
+	s := &S3Storage{
+		config: &cfg,
+		client: client,
+		logger: slog.Default().With("component", "evidence.storage.s3"),
+		stopCh: make(chan struct{}),
+	}
+
+	s.wg.Add(1)
+	go s.flushLoop()
+
+	return s, nil
+}
+
+// Store buffers an evidence record. A batch is written when the buffer is full.
+func (s *S3Storage) Store(ctx context.Context, record *evidence.EvidenceRecord) error {
+	recordCopy := *record
+
+	s.mu.Lock()
+	s.buffer = append(s.buffer, &recordCopy)
+	full := len(s.buffer) >= s.config.BatchSize
+	s.mu.Unlock()
+
+	if full {
+		return s.Flush(ctx)
+	}
+	return nil
+}
+
+// Flush writes all buffered records to S3. Records that fail to upload are
+// returned to the buffer and retried on the next flush.
+func (s *S3Storage) Flush(ctx context.Context) error {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	s.mu.Lock()
+	batch := s.buffer
+	s.buffer = nil
+	s.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	// Group records by partition hour
+	partitions := make(map[string][]*evidence.EvidenceRecord)
+	for _, record := range batch {
+		partition := record.RequestTime.UTC().Format(s3PartitionLayout)
+		partitions[partition] = append(partitions[partition], record)
+	}
+
+	var failed []*evidence.EvidenceRecord
+	var firstErr error
+	for partition, records := range partitions {
+		key := s.objectKey(partition, records[0])
+		if err := s.writeObject(ctx, key, records); err != nil {
+			failed = append(failed, records...)
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		s.logger.Debug("wrote evidence object", "key", key, "records", len(records))
+	}
+
+	if len(failed) > 0 {
+		s.requeue(failed)
+		return evidence.NewStorageError("s3", "store", firstErr)
+	}
+	return nil
+}
+
+// requeue returns failed records to the buffer. The buffer is capped at ten
+// batches so an unavailable bucket cannot exhaust memory; the oldest records
+// are dropped first.
+func (s *S3Storage) requeue(records []*evidence.EvidenceRecord) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.buffer = append(records, s.buffer...)
+	if limit := s.config.BatchSize * 10; len(s.buffer) > limit {
+		dropped := len(s.buffer) - limit
+		s.buffer = s.buffer[dropped:]
+		s.logger.Error("evidence buffer full, dropping oldest records", "dropped", dropped)
+	}
+}
+
+// flushLoop flushes the buffer periodically.
+func (s *S3Storage) flushLoop() {
+	defer s.wg.Done()
+
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case <-ticker.C:
+			if err := s.Flush(context.Background()); err != nil {
+				s.logger.Error("failed to flush evidence to S3", "error", err)
+			}
+		}
+	}
+}
+
+// objectKey returns the key for a new object in a partition.
+func (s *S3Storage) objectKey(partition string, first *evidence.EvidenceRecord) string {
+	name := fmt.Sprintf("%s-%s.jsonl", time.Now().UTC().Format("20060102T150405.000000000Z"), first.ID)
+	if s.config.Compress {
+		name += ".gz"
+	}
+	return path.Join(s.config.Prefix, partition, name)
+}
+
+// writeObject encodes records as JSON Lines and uploads them.
+func (s *S3Storage) writeObject(ctx context.Context, key string, records []*evidence.EvidenceRecord) error {
+	var buf bytes.Buffer
+	var w io.Writer = &buf
+	var gz *gzip.Writer
+	if strings.HasSuffix(key, ".gz") {
+		gz = gzip.NewWriter(&buf)
+		w = gz
+	}
+
+	encoder := json.NewEncoder(w)
+	for _, record := range records {
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("failed to encode record %s: %w", record.ID, err)
+		}
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			return fmt.Errorf("failed to compress object: %w", err)
+		}
+	}
+
+	// Objects are stored gzip-compressed rather than with a Content-Encoding,
+	// so downloads return the compressed bytes unchanged.
+	return s.client.put(ctx, key, buf.Bytes(), s.config.uploadHeader("application/x-ndjson", buf.Bytes(), false))
+}
+
+// uploadHeader returns the headers of an object upload. With checksum, or
+// object locks (which S3 requires an integrity checksum for), Content-MD5
+// is set so that S3 rejects corrupted uploads.
+func (c *S3Config) uploadHeader(contentType string, body []byte, checksum bool) http.Header {
+	header := http.Header{"Content-Type": {contentType}}
+	if checksum || c.ObjectLockMode != "" {
+		sum := md5.Sum(body)
+		header.Set("Content-MD5", base64.StdEncoding.EncodeToString(sum[:]))
+	}
+	if c.ObjectLockMode != "" {
+		header.Set("X-Amz-Object-Lock-Mode", c.ObjectLockMode)
+		header.Set("X-Amz-Object-Lock-Retain-Until-Date",
+			time.Now().Add(c.ObjectLockRetention).UTC().Format(time.RFC3339))
+	}
+	return header
+}
+
+// readObject downloads and decodes an object.
+func (s *S3Storage) readObject(ctx context.Context, key string) ([]*evidence.EvidenceRecord, error) {
+	data, err := s.client.get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	var r io.Reader = bytes.NewReader(data)
+	if strings.HasSuffix(key, ".gz") {
+		gz, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress %s: %w", key, err)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	var records []*evidence.EvidenceRecord
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for scanner.Scan() {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		var record evidence.EvidenceRecord
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", key, err)
+		}
+		records = append(records, &record)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	return records, nil
+}
+
+// objectKeys lists the objects whose partitions overlap the query time range.
+func (s *S3Storage) objectKeys(ctx context.Context, query *evidence.Query) ([]string, error) {
+	prefixes := []string{s.listPrefix("")}
+
+	// List day by day for bounded ranges instead of the whole bucket prefix
+	if query.TimeField != "recorded_time" && query.StartTime != nil && query.EndTime != nil && query.EndTime.Sub(*query.StartTime) <= 366*24*time.Hour {
+		prefixes = prefixes[:0]
+		day := query.StartTime.UTC().Truncate(24 * time.Hour)
+		for !day.After(query.EndTime.UTC()) {
+			prefixes = append(prefixes, s.listPrefix(day.Format("2006/01/02")+"/"))
+			day = day.Add(24 * time.Hour)
+		}
+	}
+
+	var keys []string
+	for _, prefix := range prefixes {
+		listed, err := s.client.list(ctx, prefix)
+		if err != nil {
+			return nil, err
+		}
+		for _, key := range listed {
+			if s.partitionOverlaps(key, query) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys, nil
+}
+
+// listPrefix returns the list prefix for a partition path below the key prefix.
+func (s *S3Storage) listPrefix(partition string) string {
+	if s.config.Prefix == "" {
+		return partition
+	}
+	return s.config.Prefix + "/" + partition
+}
+
+// partitionOverlaps reports whether an object's partition hour can contain
+// records in the query time range. Keys outside the layout are skipped.
+func (s *S3Storage) partitionOverlaps(key string, query *evidence.Query) bool {
+	rel := strings.TrimPrefix(key, s.listPrefix(""))
+	if len(rel) < len(s3PartitionLayout) {
+		return false
+	}
+	hour, err := time.Parse(s3PartitionLayout, rel[:len(s3PartitionLayout)])
+	if err != nil {
+		return false
+	}
+
+	// Records are recorded after their request, so only the end of a
+	// recorded time range bounds the request time partitions
+	if query.StartTime != nil && query.TimeField != "recorded_time" && hour.Add(time.Hour).Before(*query.StartTime) {
+		return false
+	}
+	if query.EndTime != nil && hour.After(*query.EndTime) {
+		return false
+	}
+	return true
+}
+
+// scan calls fn with every stored and buffered record matching the query.
+// Returning false from fn stops the scan.
+func (s *S3Storage) scan(ctx context.Context, query *evidence.Query, fn func(*evidence.EvidenceRecord) bool) error {
+	keys, err := s.objectKeys(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		records, err := s.readObject(ctx, key)
+		if err != nil {
+			return err
+		}
+		for _, record := range records {
+			if matchesQuery(record, query) && !fn(record) {
+				return nil
+			}
+		}
+	}
+
+	// Include records that have not been flushed yet
+	s.mu.Lock()
+	buffered := make([]*evidence.EvidenceRecord, len(s.buffer))
+	copy(buffered, s.buffer)
+	s.mu.Unlock()
+
+	for _, record := range buffered {
+		if matchesQuery(record, query) {
+			recordCopy := *record
+			if !fn(&recordCopy) {
+				return nil
+			}
+		}
+	}
+	return nil
+}
+
+// Query retrieves evidence records matching the query filters.
+func (s *S3Storage) Query(ctx context.Context, query *evidence.Query) ([]*evidence.EvidenceRecord, error) {
+	var results []*evidence.EvidenceRecord
+	err := s.scan(ctx, query, func(record *evidence.EvidenceRecord) bool {
+		results = append(results, record)
+		return true
+	})
+	if err != nil {
+		return nil, evidence.NewStorageError("s3", "query", err)
+	}
+
+	sortRecords(results, query.SortBy, query.SortOrder)
+
+	if query.Offset >= len(results) {
+		return []*evidence.EvidenceRecord{}, nil
+	}
+	results = results[query.Offset:]
+	if query.Limit > 0 && query.Limit < len(results) {
+		results = results[:query.Limit]
+	}
+	return results, nil
+}
+
+// QueryStream returns a channel of evidence records for memory-efficient streaming.
+// Records are streamed object by object and are not sorted.
+func (s *S3Storage) QueryStream(ctx context.Context, query *evidence.Query) (<-chan *evidence.EvidenceRecord, <-chan error, error) {
+	recordsCh := make(chan *evidence.EvidenceRecord, 100)
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(recordsCh)
+		defer close(errCh)
+
+		seen, sent := 0, 0
+		err := s.scan(ctx, query, func(record *evidence.EvidenceRecord) bool {
+			if seen < query.Offset {
+				seen++
+				return true
+			}
+			if query.Limit > 0 && sent >= query.Limit {
+				return false
+			}
+			select {
+			case <-ctx.Done():
+				return false
+			case recordsCh <- record:
+				sent++
+				return true
+			}
+		})
+		if err == nil {
+			err = ctx.Err()
+		}
+		if err != nil {
+			errCh <- evidence.NewStorageError("s3", "query_stream", err)
+		}
+	}()
+
+	return recordsCh, errCh, nil
+}
+
+// Count returns the number of evidence records matching the query filters.
+func (s *S3Storage) Count(ctx context.Context, query *evidence.Query) (int64, error) {
+	var count int64
+	err := s.scan(ctx, query, func(*evidence.EvidenceRecord) bool {
+		count++
+		return true
+	})
+	if err != nil {
+		return 0, evidence.NewStorageError("s3", "count", err)
+	}
+	return count, nil
+}
+
+// Delete removes evidence records matching the query filters. Objects whose
+// records all match are deleted; partially matching objects are rewritten.
+func (s *S3Storage) Delete(ctx context.Context, query *evidence.Query) (int64, error) {
+	if s.config.WriteOnce {
+		return 0, evidence.NewStorageError("s3", "delete", evidence.ErrWriteOnce)
+	}
+
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+
+	keys, err := s.objectKeys(ctx, query)
+	if err != nil {
+		return 0, evidence.NewStorageError("s3", "delete", err)
+	}
+
+	var deleted int64
+	for _, key := range keys {
+		records, err := s.readObject(ctx, key)
+		if err != nil {
+			return deleted, evidence.NewStorageError("s3", "delete", err)
+		}
+
+		var keep []*evidence.EvidenceRecord
+		for _, record := range records {
+			if !matchesQuery(record, query) {
+				keep = append(keep, record)
+			}
+		}
+
+		removed := int64(len(records) - len(keep))
+		switch {
+		case removed == 0:
+			continue
+		case len(keep) == 0:
+			err = s.client.delete(ctx, key)
+		default:
+			err = s.writeObject(ctx, key, keep)
+		}
+		if err != nil {
+			return deleted, evidence.NewStorageError("s3", "delete", err)
+		}
+		deleted += removed
+	}
+
+	// Drop matching records that have not been flushed yet
+	s.mu.Lock()
+	remaining := s.buffer[:0]
+	for _, record := range s.buffer {
+		if matchesQuery(record, query) {
+			deleted++
+			continue
+		}
+		remaining = append(remaining, record)
+	}
+	s.buffer = remaining
+	s.mu.Unlock()
+
+	return deleted, nil
+}
+
+// Close flushes buffered records and stops the flush loop.
+func (s *S3Storage) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.stopCh)
+		s.wg.Wait()
+		err = s.Flush(context.Background())
+	})
+	return err
+}
+
+// sortRecords sorts records like the SQLite backend: by request time,
+// recorded time, cost, or tokens, then by ID, descending unless sortOrder
+// is "asc".
+func sortRecords(records []*evidence.EvidenceRecord, sortBy, sortOrder string) {
+	less := func(a, b *evidence.EvidenceRecord) bool {
+		return a.RequestTime.Before(b.RequestTime)
+	}
+	switch sortBy {
+	case "recorded_time":
+		less = func(a, b *evidence.EvidenceRecord) bool { return a.RecordedTime.Before(b.RecordedTime) }
+	case "cost", "actual_cost":
+		less = func(a, b *evidence.EvidenceRecord) bool { return a.ActualCost < b.ActualCost }
+	case "tokens", "total_tokens":
+		less = func(a, b *evidence.EvidenceRecord) bool { return a.TotalTokens < b.TotalTokens }
+	}
+
+	asc := strings.EqualFold(sortOrder, "asc")
+	sort.SliceStable(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if !asc {
+			a, b = b, a
+		}
+		if less(a, b) || less(b, a) {
+			return less(a, b)
+		}
+		return a.ID < b.ID
+	})
+}
@@ -0,0 +1,163 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"mercator-hq/jupiter/pkg/security/sigv4"
+)
+
+// s3Client is a minimal S3 object API client (put, get, delete, list).
+type s3Client struct {
+	http     *http.Client
+	signer   *sigv4.Signer
+	scheme   string
+	host     string
+	basePath string // "/bucket" for path-style endpoints, "" for virtual-hosted
+}
+
+// newS3Client creates a client for the bucket. Custom endpoints (e.g., MinIO)
+// use path-style addressing; AWS uses virtual-hosted buckets.
+func newS3Client(cfg *S3Config) (*s3Client, error) {
+	c := &s3Client{
+		http:   cfg.Client,
+		signer: sigv4.NewSigner(cfg.Credentials, cfg.Region, "s3"),
+		scheme: "https",
+		host:   fmt.Sprintf("%s.s3.%s.amazonaws.com", cfg.Bucket, cfg.Region),
+	}
+	if c.http == nil {
+		c.http = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	if cfg.Endpoint != "" {
+		endpoint, err := url.Parse(cfg.Endpoint)
+		if err != nil || endpoint.Host == "" {
+			return nil, fmt.Errorf("invalid S3 endpoint %q", cfg.Endpoint)
+		}
+		c.scheme = endpoint.Scheme
+		c.host = endpoint.Host
+		c.basePath = strings.TrimSuffix(endpoint.Path, "/") + "/" + cfg.Bucket
+	}
+
+	return c, nil
+}
+
+// do signs and sends a request, returning the response body for 2xx responses.
+func (c *s3Client) do(ctx context.Context, method, key string, query url.Values, body []byte, header http.Header) ([]byte, error) {
+	_, data, err := c.send(ctx, method, key, query, body, header)
+	return data, err
+}
+
+// send signs and sends a request, returning the response headers and body
+// for 2xx responses.
+func (c *s3Client) send(ctx context.Context, method, key string, query url.Values, body []byte, header http.Header) (http.Header, []byte, error) {
+	u := url.URL{
+		Scheme:   c.scheme,
+		Host:     c.host,
+		Path:     c.basePath + "/" + key,
+		RawQuery: query.Encode(),
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if err := c.signer.Sign(req, body, time.Now()); err != nil {
+		return nil, nil, err
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, nil, fmt.Errorf("s3 %s %s: status %d: %s", method, key, resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return resp.Header, data, nil
+}
+
+// put uploads an object with the given headers (e.g. Content-Type).
+func (c *s3Client) put(ctx context.Context, key string, body []byte, header http.Header) error {
+	_, err := c.do(ctx, http.MethodPut, key, nil, body, header)
+	return err
+}
+
+// head returns the size of an object.
+func (c *s3Client) head(ctx context.Context, key string) (int64, error) {
+	header, _, err := c.send(ctx, http.MethodHead, key, nil, nil, nil)
+	if err != nil {
+		return 0, err
+	}
+	size, err := strconv.ParseInt(header.Get("Content-Length"), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("s3 HEAD %s: invalid Content-Length %q", key, header.Get("Content-Length"))
+	}
+	return size, nil
+}
+
+// get downloads an object.
+func (c *s3Client) get(ctx context.Context, key string) ([]byte, error) {
+	return c.do(ctx, http.MethodGet, key, nil, nil, nil)
+}
+
+// delete removes an object.
+func (c *s3Client) delete(ctx context.Context, key string) error {
+	_, err := c.do(ctx, http.MethodDelete, key, nil, nil, nil)
+	return err
+}
+
+// listResult is the ListObjectsV2 response body.
+type listResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// list returns the keys of all objects under prefix, in lexical order.
+func (c *s3Client) list(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		data, err := c.do(ctx, http.MethodGet, "", query, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		var result listResult
+		if err := xml.Unmarshal(data, &result); err != nil {
+			return nil, fmt.Errorf("failed to parse list response: %w", err)
+		}
+		for _, object := range result.Contents {
+			keys = append(keys, object.Key)
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return keys, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/security/sigv4"
+)
+
+// S3ObjectStore stores whole objects, such as retention archives, in an
+// S3-compatible bucket (including Google Cloud Storage through its XML API
+// endpoint). Only the bucket, region, prefix, endpoint, credentials, client,
+// and object lock settings of its S3Config are used.
+type S3ObjectStore struct {
+	config *S3Config
+	client *s3Client
+}
+
+// NewS3ObjectStore creates an object store for the bucket.
+func NewS3ObjectStore(config *S3Config) (*S3ObjectStore, error) {
+	if config == nil {
+		return nil, fmt.Errorf("config cannot be nil")
+	}
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("bucket cannot be empty")
+	}
+	if config.Region == "" {
+		return nil, fmt.Errorf("region cannot be empty")
+	}
+	if config.ObjectLockMode != "" && config.ObjectLockRetention <= 0 {
+		return nil, fmt.Errorf("object lock retention must be positive")
+	}
+
+	cfg := *config
+	if !cfg.Credentials.Valid() {
+		cfg.Credentials = sigv4.CredentialsFromEnv()
+	}
+	cfg.Prefix = strings.Trim(cfg.Prefix, "/")
+
+	client, err := newS3Client(&cfg)
+	if err != nil {
+		return nil, evidence.NewStorageError("s3", "open", err)
+	}
+	return &S3ObjectStore{config: &cfg, client: client}, nil
+}
+
+// Put uploads an object under the store's prefix and verifies that it was
+// stored completely. The upload carries a Content-MD5 checksum, so the
+// bucket rejects corrupted uploads, and the stored size is checked
+// afterwards.
+func (s *S3ObjectStore) Put(ctx context.Context, name string, data []byte, contentType string) error {
+	key := path.Join(s.config.Prefix, name)
+	if err := s.client.put(ctx, key, data, s.config.uploadHeader(contentType, data, true)); err != nil {
+		return evidence.NewStorageError("s3", "put", err)
+	}
+
+	size, err := s.client.head(ctx, key)
+	if err != nil {
+		return evidence.NewStorageError("s3", "put", fmt.Errorf("failed to verify upload: %w", err))
+	}
+	if size != int64(len(data)) {
+		return evidence.NewStorageError("s3", "put",
+			fmt.Errorf("failed to verify upload of %s: stored %d bytes, want %d", key, size, len(data)))
+	}
+	return nil
+}
@@ -0,0 +1,360 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/security/sigv4"
+)
+
+// fakeS3 is an in-memory, path-style S3 server supporting the object API
+// used by S3Storage.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	headers map[string]http.Header // headers of the last upload of each key
+
+	truncate bool // store uploads without their last byte
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	t.Helper()
+	f := &fakeS3{objects: make(map[string][]byte)}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ") {
+		http.Error(w, "unsigned request", http.StatusForbidden)
+		return
+	}
+
+	// Path is /bucket/key
+	key := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, "/archive"), "/")
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+		type content struct {
+			Key string `xml:"Key"`
+		}
+		var result struct {
+			XMLName  xml.Name  `xml:"ListBucketResult"`
+			Contents []content `xml:"Contents"`
+		}
+		var keys []string
+		for k := range f.objects {
+			if strings.HasPrefix(k, r.URL.Query().Get("prefix")) {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			result.Contents = append(result.Contents, content{Key: k})
+		}
+		xml.NewEncoder(w).Encode(result)
+	case r.Method == http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		if f.truncate && len(data) > 0 {
+			data = data[:len(data)-1]
+		}
+		f.objects[key] = data
+		if f.headers == nil {
+			f.headers = make(map[string]http.Header)
+		}
+		f.headers[key] = r.Header.Clone()
+	case r.Method == http.MethodHead:
+		data, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+	case r.Method == http.MethodGet:
+		data, ok := f.objects[key]
+		if !ok {
+			http.Error(w, "NoSuchKey", http.StatusNotFound)
+			return
+		}
+		w.Write(data)
+	case r.Method == http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.Error(w, "unsupported", http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *fakeS3) keys() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var keys []string
+	for k := range f.objects {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func newTestS3Storage(t *testing.T, endpoint string, batchSize int) *S3Storage {
+	t.Helper()
+	s, err := NewS3Storage(&S3Config{
+		Bucket:        "archive",
+		Region:        "us-east-1",
+		Prefix:        "/evidence/",
+		Endpoint:      endpoint,
+		Credentials:   sigv4.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"},
+		BatchSize:     batchSize,
+		FlushInterval: time.Hour,
+		Compress:      true,
+	})
+	if err != nil {
+		t.Fatalf("NewS3Storage() error = %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestS3Storage_PartitionedBatches(t *testing.T) {
+	fake, server := newFakeS3(t)
+	s := newTestS3Storage(t, server.URL, 3)
+	ctx := context.Background()
+
+	base := time.Date(2025, 11, 16, 9, 30, 0, 0, time.UTC)
+	records := []*evidence.EvidenceRecord{
+		{ID: "r1", RequestTime: base, Model: "gpt-4", ActualCost: 0.01},
+		{ID: "r2", RequestTime: base.Add(10 * time.Minute), Model: "claude-3", ActualCost: 0.03},
+		{ID: "r3", RequestTime: base.Add(time.Hour), Model: "gpt-4", ActualCost: 0.02},
+	}
+
+	// The first two records stay buffered but are still queryable
+	for _, r := range records[:2] {
+		if err := s.Store(ctx, r); err != nil {
+			t.Fatalf("Store() error = %v", err)
+		}
+	}
+	if len(fake.keys()) != 0 {
+		t.Fatalf("expected no objects before the batch is full, got %v", fake.keys())
+	}
+	if n, _ := s.Count(ctx, &evidence.Query{}); n != 2 {
+		t.Errorf("Count() with buffered records = %d, want 2", n)
+	}
+
+	// The third record fills the batch, writing one object per hour partition
+	if err := s.Store(ctx, records[2]); err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+	keys := fake.keys()
+	if len(keys) != 2 {
+		t.Fatalf("expected 2 objects, got %v", keys)
+	}
+	if !strings.HasPrefix(keys[0], "evidence/2025/11/16/09/") || !strings.HasSuffix(keys[0], ".jsonl.gz") {
+		t.Errorf("unexpected object key %q", keys[0])
+	}
+	if !strings.HasPrefix(keys[1], "evidence/2025/11/16/10/") {
+		t.Errorf("unexpected object key %q", keys[1])
+	}
+
+	// Queries are filtered, sorted, and paginated
+	results, err := s.Query(ctx, &evidence.Query{Model: "gpt-4"})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(results) != 2 || results[0].ID != "r3" || results[1].ID != "r1" {
+		t.Errorf("Query() returned %v, want [r3 r1]", recordIDs(results))
+	}
+
+	start, end := base.Add(-time.Minute), base.Add(30*time.Minute)
+	results, err = s.Query(ctx, &evidence.Query{StartTime: &start, EndTime: &end, SortBy: "cost", SortOrder: "asc"})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if got := recordIDs(results); strings.Join(got, ",") != "r1,r2" {
+		t.Errorf("time range Query() returned %v, want [r1 r2]", got)
+	}
+}
+
+func TestS3Storage_Delete(t *testing.T) {
+	fake, server := newFakeS3(t)
+	s := newTestS3Storage(t, server.URL, 10)
+	ctx := context.Background()
+
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i, id := range []string{"a", "b", "c"} {
+		s.Store(ctx, &evidence.EvidenceRecord{ID: id, RequestTime: base.Add(time.Duration(i) * 20 * time.Minute)})
+	}
+	if err := s.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	s.Store(ctx, &evidence.EvidenceRecord{ID: "buffered", RequestTime: base.Add(5 * time.Hour)})
+
+	// Deleting part of an object rewrites it
+	cutoff := base.Add(30 * time.Minute)
+	deleted, err := s.Delete(ctx, &evidence.Query{EndTime: &cutoff})
+	if err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if deleted != 2 {
+		t.Errorf("Delete() = %d, want 2", deleted)
+	}
+	results, _ := s.Query(ctx, &evidence.Query{SortOrder: "asc"})
+	if got := strings.Join(recordIDs(results), ","); got != "c,buffered" {
+		t.Errorf("remaining records = %s, want c,buffered", got)
+	}
+
+	// Deleting every record in an object removes it
+	if _, err := s.Delete(ctx, &evidence.Query{}); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if keys := fake.keys(); len(keys) != 0 {
+		t.Errorf("expected all objects deleted, got %v", keys)
+	}
+}
+
+func TestS3Storage_CloseFlushes(t *testing.T) {
+	fake, server := newFakeS3(t)
+	s := newTestS3Storage(t, server.URL, 100)
+
+	s.Store(context.Background(), &evidence.EvidenceRecord{ID: "r1", RequestTime: time.Now()})
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if len(fake.keys()) != 1 {
+		t.Errorf("expected buffered record flushed on Close, got %v", fake.keys())
+	}
+}
+
+func TestS3Storage_FailedFlushIsRetried(t *testing.T) {
+	var failing atomic.Bool
+	failing.Store(true)
+	fake := &fakeS3{objects: make(map[string][]byte)}
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing.Load() {
+			http.Error(w, "SlowDown", http.StatusServiceUnavailable)
+			return
+		}
+		fake.ServeHTTP(w, r)
+	}))
+	defer flaky.Close()
+
+	s := newTestS3Storage(t, flaky.URL, 1)
+	if err := s.Store(context.Background(), &evidence.EvidenceRecord{ID: "r1", RequestTime: time.Now()}); err == nil {
+		t.Fatal("expected store error while S3 is unavailable")
+	}
+
+	failing.Store(false)
+	if err := s.Flush(context.Background()); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+	if len(fake.keys()) != 1 {
+		t.Errorf("expected requeued record to be written, got %v", fake.keys())
+	}
+}
+
+func recordIDs(records []*evidence.EvidenceRecord) []string {
+	ids := make([]string, len(records))
+	for i, r := range records {
+		ids[i] = r.ID
+	}
+	return ids
+}
+
+func TestS3Storage_WriteOnce(t *testing.T) {
+	fake, server := newFakeS3(t)
+	s, err := NewS3Storage(&S3Config{
+		Bucket:              "archive",
+		Region:              "us-east-1",
+		Endpoint:            server.URL,
+		Credentials:         sigv4.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"},
+		FlushInterval:       time.Hour,
+		WriteOnce:           true,
+		ObjectLockMode:      "COMPLIANCE",
+		ObjectLockRetention: 24 * time.Hour,
+	})
+	if err != nil {
+		t.Fatalf("NewS3Storage() error = %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	ctx := context.Background()
+
+	s.Store(ctx, &evidence.EvidenceRecord{ID: "a", RequestTime: time.Now()})
+	if err := s.Flush(ctx); err != nil {
+		t.Fatalf("Flush() error = %v", err)
+	}
+
+	keys := fake.keys()
+	if len(keys) != 1 {
+		t.Fatalf("expected 1 object, got %v", keys)
+	}
+	header := fake.headers[keys[0]]
+	if got := header.Get("X-Amz-Object-Lock-Mode"); got != "COMPLIANCE" {
+		t.Errorf("object lock mode = %q, want COMPLIANCE", got)
+	}
+	until, err := time.Parse(time.RFC3339, header.Get("X-Amz-Object-Lock-Retain-Until-Date"))
+	if err != nil || until.Before(time.Now().Add(23*time.Hour)) {
+		t.Errorf("retain until date = %q, want a day from now", header.Get("X-Amz-Object-Lock-Retain-Until-Date"))
+	}
+	if header.Get("Content-MD5") == "" {
+		t.Error("expected Content-MD5 on object lock upload")
+	}
+
+	if _, err := s.Delete(ctx, &evidence.Query{}); !errors.Is(err, evidence.ErrWriteOnce) {
+		t.Errorf("Delete() error = %v, want ErrWriteOnce", err)
+	}
+	if len(fake.keys()) != 1 {
+		t.Error("expected object to be kept")
+	}
+
+	if _, err := NewS3Storage(&S3Config{Bucket: "archive", Region: "us-east-1", ObjectLockMode: "COMPLIANCE"}); err == nil {
+		t.Error("expected error for object lock without retention")
+	}
+}
+
+func TestS3ObjectStore_Put(t *testing.T) {
+	fake, server := newFakeS3(t)
+	store, err := NewS3ObjectStore(&S3Config{
+		Bucket:      "archive",
+		Region:      "us-east-1",
+		Prefix:      "/archives/",
+		Endpoint:    server.URL,
+		Credentials: sigv4.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"},
+	})
+	if err != nil {
+		t.Fatalf("NewS3ObjectStore() error = %v", err)
+	}
+	ctx := context.Background()
+
+	data := []byte("archived records")
+	if err := store.Put(ctx, "evidence-2026-01-02-030405.jsonl.gz", data, "application/gzip"); err != nil {
+		t.Fatalf("Put() error = %v", err)
+	}
+	key := "archives/evidence-2026-01-02-030405.jsonl.gz"
+	if !bytes.Equal(fake.objects[key], data) {
+		t.Fatalf("expected object %s, got %v", key, fake.keys())
+	}
+	if fake.headers[key].Get("Content-MD5") == "" {
+		t.Error("expected Content-MD5 on upload")
+	}
+
+	fake.truncate = true
+	if err := store.Put(ctx, "truncated", data, "application/gzip"); err == nil {
+		t.Error("expected error for incomplete upload")
+	}
+}
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"strings"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+// searchTerms splits a search into its words and "quoted phrases".
+func searchTerms(search string) []string {
+	var terms []string
+	for i, part := range strings.Split(search, `"`) {
+		if i%2 == 1 {
+			// Inside quotes: the whole phrase is one term
+			if phrase := strings.Join(strings.Fields(part), " "); phrase != "" {
+				terms = append(terms, phrase)
+			}
+			continue
+		}
+		terms = append(terms, strings.Fields(part)...)
+	}
+	return terms
+}
+
+// matchesSearch reports whether every search term occurs in the record's
+// prompts or response excerpt, ignoring case.
+func matchesSearch(record *evidence.EvidenceRecord, search string) bool {
+	text := strings.ToLower(strings.Join([]string{
+		record.SystemPrompt, record.UserPrompt, record.ResponseContent,
+	}, "\n"))
+	for _, term := range searchTerms(search) {
+		if !strings.Contains(text, strings.ToLower(term)) {
+			return false
+		}
+	}
+	return true
+}
+
+// ftsQuery converts a search into a full-text MATCH expression, quoting
+// every term so that its characters are never parsed as query syntax.
+// Terms never contain quotes, and are implicitly ANDed by both FTS4 and
+// FTS5.
+func ftsQuery(search string) string {
+	terms := searchTerms(search)
+	for i, term := range terms {
+		terms[i] = `"` + term + `"`
+	}
+	return strings.Join(terms, " ")
+}
@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+func TestSearchTerms(t *testing.T) {
+	tests := []struct {
+		search string
+		want   []string
+	}{
+		{"", nil},
+		{"  falcon  ", []string{"falcon"}},
+		{`"credit card" falcon`, []string{"credit card", "falcon"}},
+		{`falcon "  credit   card "`, []string{"falcon", "credit card"}},
+		{`"unterminated phrase`, []string{"unterminated phrase"}},
+		{`"" *`, []string{"*"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.search, func(t *testing.T) {
+			if got := searchTerms(tt.search); !slices.Equal(got, tt.want) {
+				t.Errorf("searchTerms(%q) = %q, want %q", tt.search, got, tt.want)
+			}
+		})
+	}
+
+	if got, want := ftsQuery(`"credit card" OR`), `"credit card" "OR"`; got != want {
+		t.Errorf("ftsQuery() = %q, want %q", got, want)
+	}
+}
+
+// searchRecords are the records stored by the search tests.
+var searchRecords = []*evidence.EvidenceRecord{
+	{UserPrompt: "What is my credit card limit?", ResponseContent: "I cannot access card data."},
+	{SystemPrompt: "You work on Project Falcon.", UserPrompt: "Summarize the roadmap"},
+	{UserPrompt: "Is this a credit or a debit card?"},
+	{UserPrompt: "Hello", ResponseContent: "Hi! Ask me about FALCON."},
+}
+
+// testSearch checks full-text search against a storage backend holding
+// searchRecords.
+func testSearch(t *testing.T, store evidence.Storage) {
+	t.Helper()
+
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	for i, record := range searchRecords {
+		record.ID = fmt.Sprintf("record-%d", i)
+		record.RequestID = fmt.Sprintf("req-%d", i)
+		record.RequestTime = now.Add(time.Duration(i) * time.Second)
+		if err := store.Store(ctx, record); err != nil {
+			t.Fatalf("Store() failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		search string
+		want   []string
+	}{
+		{`"credit card"`, []string{"record-0"}},
+		{"credit card", []string{"record-2", "record-0"}},
+		{"falcon", []string{"record-3", "record-1"}},
+		{"falcon roadmap", []string{"record-1"}},
+		{"codename", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.search, func(t *testing.T) {
+			records, err := store.Query(ctx, &evidence.Query{Search: tt.search})
+			if err != nil {
+				t.Fatalf("Query() failed: %v", err)
+			}
+			var ids []string
+			for _, record := range records {
+				ids = append(ids, record.ID)
+			}
+			if !slices.Equal(ids, tt.want) {
+				t.Errorf("Search %q = %v, want %v", tt.search, ids, tt.want)
+			}
+		})
+	}
+
+	// Deleted records are no longer found
+	if _, err := store.Delete(ctx, &evidence.Query{Search: `"credit card"`}); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	}
+	count, err := store.Count(ctx, &evidence.Query{Search: "card"})
+	if err != nil {
+		t.Fatalf("Count() failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected 1 record with card after delete, got %d", count)
+	}
+}
+
+func TestSQLiteStorage_Search(t *testing.T) {
+	storage, _ := createTempDB(t)
+	defer storage.Close()
+
+	testSearch(t, storage)
+}
+
+func TestSQLiteStorage_SearchIndexesExistingRecords(t *testing.T) {
+	storage, dbPath := createTempDB(t)
+	ctx := context.Background()
+
+	record := &evidence.EvidenceRecord{ID: "old", RequestID: "req-old", RequestTime: time.Now(), UserPrompt: "Project Falcon"}
+	if err := storage.Store(ctx, record); err != nil {
+		t.Fatalf("Store() failed: %v", err)
+	}
+
+	// Simulate a database created before the search index existed
+	if _, err := storage.db.Exec("DROP TABLE evidence_fts"); err != nil {
+		t.Fatalf("Failed to drop search index: %v", err)
+	}
+	storage.Close()
+
+	storage, err := NewSQLiteStorage(&SQLiteConfig{Path: dbPath, MaxOpenConns: 1, BusyTimeout: time.Second})
+	if err != nil {
+		t.Fatalf("Failed to reopen storage: %v", err)
+	}
+	defer storage.Close()
+
+	count, err := storage.Count(ctx, &evidence.Query{Search: "falcon"})
+	if err != nil {
+		t.Fatalf("Count() failed: %v", err)
+	}
+	if count != 1 {
+		t.Errorf("Expected existing record to be indexed, got %d matches", count)
+	}
+}
+
+func TestMemoryStorage_Search(t *testing.T) {
+	testSearch(t, NewMemoryStorage())
+}
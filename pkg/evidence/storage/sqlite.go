@@ -6,12 +6,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"strings"
 	"sync"
 	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 
 	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/evidence/compress"
 )
 
 // SQLiteConfig contains configuration for the SQLite storage backend.
@@ -34,6 +36,20 @@ type SQLiteConfig struct {
 	// BusyTimeout is the duration to wait when the database is locked.
 	// Default: 5 seconds
 	BusyTimeout time.Duration
+
+	// WriteOnce makes evidence records write-once (WORM): Delete returns
+	// evidence.ErrWriteOnce, and triggers reject UPDATE and DELETE
+	// statements on the evidence table from any client. The triggers stay
+	// in the database after write-once mode is turned off.
+	// Default: false
+	WriteOnce bool
+
+	// CompressMinSize enables zstd compression of the prompts and response
+	// excerpts of at least this many bytes. Compressed fields are stored as
+	// BLOBs and decompressed transparently by queries; the full-text index
+	// holds the uncompressed text.
+	// Default: 0 (no compression)
+	CompressMinSize int
 }
 
 // DefaultSQLiteConfig returns the default SQLite configuration.
@@ -91,6 +107,8 @@ func NewSQLiteStorage(config *SQLiteConfig) (*SQLiteStorage, error) {
 	logger.Info("SQLite storage initialized",
 		"path", config.Path,
 		"wal_mode", config.WALMode,
+		"write_once", config.WriteOnce,
+		"compress_min_size", config.CompressMinSize,
 		"max_open_conns", config.MaxOpenConns,
 	)
 
@@ -122,10 +140,36 @@ func (s *SQLiteStorage) initialize() error {
 	}
 	s.logger.Debug("database schema created")
 
-	// Insert schema version
-	_, err = s.db.Exec(InsertSchemaVersion, SchemaVersion)
+	// Record the schema version of a new database, or migrate an existing
+	// one to the current version
+	var current int
+	err = s.db.QueryRow(GetSchemaVersion).Scan(&current)
+	switch {
+	case err == sql.ErrNoRows:
+		if _, err := s.db.Exec(InsertSchemaVersion, SchemaVersion); err != nil {
+			return evidence.NewStorageError("sqlite", "insert_schema_version", err)
+		}
+	case err != nil:
+		return evidence.NewStorageError("sqlite", "get_schema_version", err)
+	default:
+		if err := s.migrate(current); err != nil {
+			return err
+		}
+	}
+
+	_, err = s.db.Exec(MigratedIndexes)
 	if err != nil {
-		return evidence.NewStorageError("sqlite", "insert_schema_version", err)
+		return evidence.NewStorageError("sqlite", "create_schema", err)
+	}
+
+	if err := s.initializeSearch(); err != nil {
+		return err
+	}
+
+	if s.config.WriteOnce {
+		if _, err := s.db.Exec(WriteOnceTriggers); err != nil {
+			return evidence.NewStorageError("sqlite", "create_schema", err)
+		}
 	}
 
 	// Verify schema version
@@ -145,37 +189,246 @@ func (s *SQLiteStorage) initialize() error {
 	return nil
 }
 
+// migrate applies the migrations from version current to SchemaVersion.
+func (s *SQLiteStorage) migrate(current int) error {
+	for version := current + 1; version <= SchemaVersion; version++ {
+		tx, err := s.db.Begin()
+		if err != nil {
+			return evidence.NewStorageError("sqlite", "migrate", err)
+		}
+		if _, err := tx.Exec(Migrations[version]); err != nil {
+			tx.Rollback()
+			return evidence.NewStorageError("sqlite", "migrate",
+				fmt.Errorf("migration to schema version %d: %w", version, err))
+		}
+		if _, err := tx.Exec(InsertSchemaVersion, version); err != nil {
+			tx.Rollback()
+			return evidence.NewStorageError("sqlite", "insert_schema_version", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return evidence.NewStorageError("sqlite", "migrate", err)
+		}
+		s.logger.Info("evidence database migrated", "schema_version", version)
+	}
+	return nil
+}
+
+// initializeSearch creates the full-text index of the prompts and response
+// excerpts, indexing existing records. FTS5 is used if SQLite was built with
+// it (the sqlite_fts5 build tag), and FTS4 otherwise.
+func (s *SQLiteStorage) initializeSearch() error {
+	var exists int
+	err := s.db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE name = 'evidence_fts'").Scan(&exists)
+	if err != nil {
+		return evidence.NewStorageError("sqlite", "create_search_index", err)
+	}
+	if exists > 0 {
+		return nil
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return evidence.NewStorageError("sqlite", "create_search_index", err)
+	}
+	defer tx.Rollback()
+
+	module := "fts5"
+	if _, err := tx.Exec(SearchTable(module)); err != nil {
+		module = "fts4"
+		if _, err := tx.Exec(SearchTable(module)); err != nil {
+			return evidence.NewStorageError("sqlite", "create_search_index", err)
+		}
+	}
+	if _, err := tx.Exec(SearchIndex); err != nil {
+		return evidence.NewStorageError("sqlite", "create_search_index", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return evidence.NewStorageError("sqlite", "create_search_index", err)
+	}
+
+	s.logger.Info("evidence search index created", "module", module)
+	return nil
+}
+
+// insertQuery inserts an evidence record.
+const insertQuery = `
+	INSERT INTO evidence (
+		id, request_id,
+		request_time, policy_eval_time, provider_call_time, response_time, recorded_time,
+		request_hash, request_method, request_path, request_headers,
+		model, provider, messages, system_prompt, user_prompt, tools_used,
+		estimated_tokens, estimated_cost, risk_score, complexity_score, pii_detected, pii_types,
+		policy_decision, matched_rules, block_reason, policy_version,
+		response_hash, response_status,
+		response_content, finish_reason,
+		prompt_tokens, completion_tokens, total_tokens, actual_cost,
+		provider_latency, provider_model,
+		user_id, api_key, ip_address,
+		error, error_type,
+		turn_number, context_usage,
+		chain_id, sequence, prev_hash, record_hash,
+		signing_key_id, signature,
+		request_body_ref, response_body_ref, body_truncated,
+		team_id,
+		anonymized_at,
+		streamed, stream_chunks, time_to_first_token, stream_duration, client_disconnected,
+		tool_calls, tool_results,
+		trace_id, decision_id,
+		anonymized_hash,
+		cost_tag,
+		limit_bypass,
+		response_analysis,
+		data_classification,
+		data_classification_level,
+		findings
+	) VALUES (
+		?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?,
+		?, ?, ?, ?,
+		?, ?,
+		?, ?, ?,
+		?,
+		?,
+		?, ?, ?, ?, ?,
+		?, ?,
+		?, ?,
+		?,
+		?,
+		?,
+		?,
+		?,
+		?,
+		?
+	)
+`
+
+// execer executes statements; it is implemented by *sql.DB and *sql.Tx.
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
 // Store persists an evidence record to the database.
 func (s *SQLiteStorage) Store(ctx context.Context, record *evidence.EvidenceRecord) error {
+	// Records with compressed fields are reindexed in a transaction
+	if s.config.CompressMinSize > 0 {
+		return s.StoreBatch(ctx, []*evidence.EvidenceRecord{record})
+	}
+	stmt, err := s.prepared(ctx, insertQuery)
+	if err != nil {
+		return evidence.NewStorageError("sqlite", "store", err)
+	}
+	if err := s.insert(ctx, stmt, s.db, record); err != nil {
+		return evidence.NewStorageError("sqlite", "store", err)
+	}
+	return nil
+}
+
+// StoreBatch persists records in a single transaction, which is much
+// faster than storing them one by one since the database is synced once.
+func (s *SQLiteStorage) StoreBatch(ctx context.Context, records []*evidence.EvidenceRecord) error {
+	// Prepared before the transaction takes a connection, which may be
+	// the only one
+	prepared, err := s.prepared(ctx, insertQuery)
+	if err != nil {
+		return evidence.NewStorageError("sqlite", "store", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return evidence.NewStorageError("sqlite", "store", err)
+	}
+	defer tx.Rollback()
+
+	stmt := tx.StmtContext(ctx, prepared)
+	defer stmt.Close()
+
+	for _, record := range records {
+		if err := s.insert(ctx, stmt, tx, record); err != nil {
+			return evidence.NewStorageError("sqlite", "store", fmt.Errorf("record %s: %w", record.ID, err))
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return evidence.NewStorageError("sqlite", "store", err)
+	}
+	return nil
+}
+
+// Update replaces stored records in a single transaction. Records are
+// deleted and inserted again, so the search index is updated by its
+// triggers.
+func (s *SQLiteStorage) Update(ctx context.Context, records []*evidence.EvidenceRecord) error {
+	if s.config.WriteOnce {
+		return evidence.NewStorageError("sqlite", "update", evidence.ErrWriteOnce)
+	}
+
+	prepared, err := s.prepared(ctx, insertQuery)
+	if err != nil {
+		return evidence.NewStorageError("sqlite", "update", err)
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return evidence.NewStorageError("sqlite", "update", err)
+	}
+	defer tx.Rollback()
+
+	stmt := tx.StmtContext(ctx, prepared)
+	defer stmt.Close()
+
+	for _, record := range records {
+		result, err := tx.ExecContext(ctx, "DELETE FROM evidence WHERE id = ?", record.ID)
+		if err != nil {
+			return evidence.NewStorageError("sqlite", "update", fmt.Errorf("record %s: %w", record.ID, err))
+		}
+		if n, _ := result.RowsAffected(); n == 0 {
+			continue
+		}
+		if err := s.insert(ctx, stmt, tx, record); err != nil {
+			return evidence.NewStorageError("sqlite", "update", fmt.Errorf("record %s: %w", record.ID, err))
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return evidence.NewStorageError("sqlite", "update", err)
+	}
+	return nil
+}
+
+// prepared returns the prepared statement of query, preparing it on first
+// use. The statements are closed by Close.
+func (s *SQLiteStorage) prepared(ctx context.Context, query string) (*sql.Stmt, error) {
+	s.mu.RLock()
+	stmt, ok := s.preparedStmts[query]
+	s.mu.RUnlock()
+	if ok {
+		return stmt, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if stmt, ok := s.preparedStmts[query]; ok {
+		return stmt, nil
+	}
+	stmt, err := s.db.PrepareContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	s.preparedStmts[query] = stmt
+	return stmt, nil
+}
+
+// insert inserts a record with stmt, the prepared insertQuery. The search
+// index trigger copies the fields as stored, so a record with compressed
+// fields is indexed again with the uncompressed text through exec, which
+// must then be the statement's transaction.
+func (s *SQLiteStorage) insert(ctx context.Context, stmt *sql.Stmt, exec execer, record *evidence.EvidenceRecord) error {
 	// Marshal JSON fields
 	requestHeaders, _ := json.Marshal(record.RequestHeaders)
 	toolsUsed, _ := json.Marshal(record.ToolsUsed)
 	piiTypes, _ := json.Marshal(record.PIITypes)
 	matchedRules, _ := json.Marshal(record.MatchedRules)
 
-	// Insert evidence record
-	query := `
-		INSERT INTO evidence (
-			id, request_id,
-			request_time, policy_eval_time, provider_call_time, response_time, recorded_time,
-			request_hash, request_method, request_path, request_headers,
-			model, provider, messages, system_prompt, user_prompt, tools_used,
-			estimated_tokens, estimated_cost, risk_score, complexity_score, pii_detected, pii_types,
-			policy_decision, matched_rules, block_reason, policy_version,
-			response_hash, response_status,
-			response_content, finish_reason,
-			prompt_tokens, completion_tokens, total_tokens, actual_cost,
-			provider_latency, provider_model,
-			user_id, api_key, ip_address,
-			error, error_type,
-			turn_number, context_usage
-		) VALUES (
-			?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?
-		)
-	`
-
 	// Convert empty strings to NULL for optional fields
-	var errorVal, errorTypeVal interface{}
+	var errorVal, errorTypeVal, chainIDVal, teamIDVal, anonymizedAtVal, toolCallsVal, toolResultsVal interface{}
+	var traceIDVal, decisionIDVal, anonymizedHashVal, costTagVal, limitBypassVal, responseAnalysisVal, dataClassificationVal, findingsVal interface{}
 	if record.Error == "" {
 		errorVal = nil
 	} else {
@@ -186,27 +439,142 @@ func (s *SQLiteStorage) Store(ctx context.Context, record *evidence.EvidenceReco
 	} else {
 		errorTypeVal = record.ErrorType
 	}
+	if record.ChainID != "" {
+		chainIDVal = record.ChainID
+	}
+	if record.TeamID != "" {
+		teamIDVal = record.TeamID
+	}
+	if record.AnonymizedAt != nil {
+		anonymizedAtVal = *record.AnonymizedAt
+	}
+	if record.AnonymizedHash != "" {
+		anonymizedHashVal = record.AnonymizedHash
+	}
+	if record.TraceID != "" {
+		traceIDVal = record.TraceID
+	}
+	if record.DecisionID != "" {
+		decisionIDVal = record.DecisionID
+	}
+	if record.CostTag != "" {
+		costTagVal = record.CostTag
+	}
+	if record.LimitBypass != "" {
+		limitBypassVal = record.LimitBypass
+	}
+	if record.DataClassification != "" {
+		dataClassificationVal = record.DataClassification
+	}
+	if len(record.ToolCalls) > 0 {
+		toolCalls, _ := json.Marshal(record.ToolCalls)
+		toolCallsVal = string(toolCalls)
+	}
+	if len(record.ToolResults) > 0 {
+		toolResults, _ := json.Marshal(record.ToolResults)
+		toolResultsVal = string(toolResults)
+	}
+	if record.ResponseAnalysis != nil {
+		responseAnalysis, _ := json.Marshal(record.ResponseAnalysis)
+		responseAnalysisVal = string(responseAnalysis)
+	}
+	if len(record.Findings) > 0 {
+		findings, _ := json.Marshal(record.Findings)
+		findingsVal = string(findings)
+	}
 
-	_, err := s.db.ExecContext(ctx, query,
+	systemPrompt, compressedSystem := s.text(record.SystemPrompt)
+	userPrompt, compressedUser := s.text(record.UserPrompt)
+	responseContent, compressedResponse := s.text(record.ResponseContent)
+	args := []interface{}{
 		record.ID, record.RequestID,
 		record.RequestTime, record.PolicyEvalTime, record.ProviderCallTime, record.ResponseTime, record.RecordedTime,
 		record.RequestHash, record.RequestMethod, record.RequestPath, string(requestHeaders),
-		record.Model, record.Provider, record.Messages, record.SystemPrompt, record.UserPrompt, string(toolsUsed),
+		record.Model, record.Provider, record.Messages, systemPrompt, userPrompt, string(toolsUsed),
 		record.EstimatedTokens, record.EstimatedCost, record.RiskScore, record.ComplexityScore, record.PIIDetected, string(piiTypes),
 		record.PolicyDecision, string(matchedRules), record.BlockReason, record.PolicyVersion,
 		record.ResponseHash, record.ResponseStatus,
-		record.ResponseContent, record.FinishReason,
+		responseContent, record.FinishReason,
 		record.PromptTokens, record.CompletionTokens, record.TotalTokens, record.ActualCost,
 		record.ProviderLatency.Milliseconds(), record.ProviderModel,
 		record.UserID, record.APIKey, record.IPAddress,
 		errorVal, errorTypeVal,
 		record.TurnNumber, record.ContextUsage,
-	)
+		chainIDVal, record.Sequence, record.PrevHash, record.RecordHash,
+		record.SigningKeyID, record.Signature,
+		record.RequestBodyRef, record.ResponseBodyRef, record.BodyTruncated,
+		teamIDVal,
+		anonymizedAtVal,
+		record.Streamed, record.StreamChunks, record.TimeToFirstToken.Milliseconds(), record.StreamDuration.Milliseconds(), record.ClientDisconnected,
+		toolCallsVal, toolResultsVal,
+		traceIDVal, decisionIDVal,
+		anonymizedHashVal,
+		costTagVal,
+		limitBypassVal,
+		responseAnalysisVal,
+		dataClassificationVal,
+		record.DataClassificationLevel,
+		findingsVal,
+	}
 
+	result, err := stmt.ExecContext(ctx, args...)
 	if err != nil {
-		return evidence.NewStorageError("sqlite", "store", err)
+		return err
+	}
+	if !compressedSystem && !compressedUser && !compressedResponse {
+		return nil
 	}
 
+	rowID, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	if _, err := exec.ExecContext(ctx, "DELETE FROM evidence_fts WHERE rowid = ?", rowID); err != nil {
+		return err
+	}
+	_, err = exec.ExecContext(ctx, `
+		INSERT INTO evidence_fts (rowid, system_prompt, user_prompt, response_content)
+		VALUES (?, ?, ?, ?)`,
+		rowID, record.SystemPrompt, record.UserPrompt, record.ResponseContent,
+	)
+	return err
+}
+
+// text returns the value to store for a text field: the zstd-compressed
+// bytes if compression is enabled and shrinks it, and the text otherwise.
+// It reports whether the value is compressed.
+func (s *SQLiteStorage) text(value string) (interface{}, bool) {
+	if s.config.CompressMinSize <= 0 {
+		return value, false
+	}
+	if compressed := compress.Shrink([]byte(value), s.config.CompressMinSize); compressed != nil {
+		return compressed, true
+	}
+	return value, false
+}
+
+// textColumn scans a text field stored by Store, decompressing it if it
+// was stored compressed.
+type textColumn struct {
+	value *string
+}
+
+// Scan implements sql.Scanner.
+func (c textColumn) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*c.value = ""
+	case string:
+		*c.value = v
+	case []byte:
+		data, err := compress.Decompress(v)
+		if err != nil {
+			return err
+		}
+		*c.value = string(data)
+	default:
+		return fmt.Errorf("unsupported text column type %T", src)
+	}
 	return nil
 }
 
@@ -221,7 +589,7 @@ func (s *SQLiteStorage) Query(ctx context.Context, query *evidence.Query) ([]*ev
 		sqlQuery += " WHERE " + whereClause
 	}
 
-	// Add sorting
+	// Add sorting; records sorting equal are ordered by ID
 	sortBy := "request_time"
 	sortOrder := "DESC"
 	if query.SortBy != "" {
@@ -230,7 +598,7 @@ func (s *SQLiteStorage) Query(ctx context.Context, query *evidence.Query) ([]*ev
 	if query.SortOrder != "" {
 		sortOrder = query.SortOrder
 	}
-	sqlQuery += fmt.Sprintf(" ORDER BY %s %s", sortBy, sortOrder)
+	sqlQuery += fmt.Sprintf(" ORDER BY %s %s, id %s", sortBy, sortOrder, sortOrder)
 
 	// Add pagination
 	limit := 100
@@ -283,7 +651,7 @@ func (s *SQLiteStorage) QueryStream(ctx context.Context, query *evidence.Query)
 		sqlQuery += " WHERE " + whereClause
 	}
 
-	// Add sorting
+	// Add sorting; records sorting equal are ordered by ID
 	sortBy := "request_time"
 	sortOrder := "DESC"
 	if query.SortBy != "" {
@@ -292,7 +660,7 @@ func (s *SQLiteStorage) QueryStream(ctx context.Context, query *evidence.Query)
 	if query.SortOrder != "" {
 		sortOrder = query.SortOrder
 	}
-	sqlQuery += fmt.Sprintf(" ORDER BY %s %s", sortBy, sortOrder)
+	sqlQuery += fmt.Sprintf(" ORDER BY %s %s, id %s", sortBy, sortOrder, sortOrder)
 
 	// Add pagination
 	limit := 100
@@ -377,6 +745,10 @@ func (s *SQLiteStorage) Count(ctx context.Context, query *evidence.Query) (int64
 // Delete removes evidence records matching the query filters.
 // Returns the number of records deleted.
 func (s *SQLiteStorage) Delete(ctx context.Context, query *evidence.Query) (int64, error) {
+	if s.config.WriteOnce {
+		return 0, evidence.NewStorageError("sqlite", "delete", evidence.ErrWriteOnce)
+	}
+
 	// Build WHERE clause and collect args
 	whereClause, args := s.buildWhereClause(query)
 
@@ -426,20 +798,43 @@ func (s *SQLiteStorage) buildWhereClause(query *evidence.Query) (string, []inter
 	var args []interface{}
 
 	// Time range filter
-	if query.StartTime != nil {
-		conditions = append(conditions, "request_time >= ?")
+	timeColumn := "request_time"
+	if query.TimeField == "recorded_time" {
+		timeColumn = "recorded_time"
+	}
+	if query.StartTime != nil && query.AfterID != "" {
+		conditions = append(conditions, "("+timeColumn+" > ? OR ("+timeColumn+" = ? AND id > ?))")
+		args = append(args, *query.StartTime, *query.StartTime, query.AfterID)
+	} else if query.StartTime != nil {
+		conditions = append(conditions, timeColumn+" >= ?")
 		args = append(args, *query.StartTime)
 	}
 	if query.EndTime != nil {
-		conditions = append(conditions, "request_time <= ?")
+		conditions = append(conditions, timeColumn+" <= ?")
 		args = append(args, *query.EndTime)
 	}
 
+	// ID filter
+	if len(query.IDs) > 0 {
+		conditions = append(conditions, "id IN (?"+strings.Repeat(", ?", len(query.IDs)-1)+")")
+		for _, id := range query.IDs {
+			args = append(args, id)
+		}
+	}
+
 	// User/API key filter
 	if query.UserID != "" {
 		conditions = append(conditions, "user_id = ?")
 		args = append(args, query.UserID)
 	}
+	if query.TeamID != "" {
+		conditions = append(conditions, "team_id = ?")
+		args = append(args, query.TeamID)
+	}
+	if query.CostTag != "" {
+		conditions = append(conditions, "cost_tag = ?")
+		args = append(args, query.CostTag)
+	}
 	if query.APIKey != "" {
 		conditions = append(conditions, "api_key = ?")
 		args = append(args, query.APIKey)
@@ -468,6 +863,18 @@ func (s *SQLiteStorage) buildWhereClause(query *evidence.Query) (string, []inter
 		conditions = append(conditions, "matched_rules LIKE ?")
 		args = append(args, "%"+query.RuleID+"%")
 	}
+	if query.TraceID != "" {
+		conditions = append(conditions, "trace_id = ?")
+		args = append(args, query.TraceID)
+	}
+	if query.DecisionID != "" {
+		conditions = append(conditions, "decision_id = ?")
+		args = append(args, query.DecisionID)
+	}
+	if query.ToolName != "" {
+		conditions = append(conditions, "tool_calls LIKE ?")
+		args = append(args, `%"name":"`+query.ToolName+`"%`)
+	}
 
 	// Cost thresholds
 	if query.MinCost != nil {
@@ -502,6 +909,21 @@ func (s *SQLiteStorage) buildWhereClause(query *evidence.Query) (string, []inter
 		}
 	}
 
+	// Anonymization filter
+	if query.Anonymized != nil {
+		if *query.Anonymized {
+			conditions = append(conditions, "anonymized_at IS NOT NULL")
+		} else {
+			conditions = append(conditions, "anonymized_at IS NULL")
+		}
+	}
+
+	// Full-text search
+	if match := ftsQuery(query.Search); match != "" {
+		conditions = append(conditions, "rowid IN (SELECT rowid FROM evidence_fts WHERE evidence_fts MATCH ?)")
+		args = append(args, match)
+	}
+
 	// Join conditions with AND
 	whereClause := ""
 	if len(conditions) > 0 {
@@ -522,21 +944,42 @@ func (s *SQLiteStorage) scanRow(row *sql.Rows) (*evidence.EvidenceRecord, error)
 	var requestHeaders, toolsUsed, piiTypes, matchedRules string
 	var providerLatencyMs int64
 	var errorVal, errorTypeVal sql.NullString
+	var chainID, prevHash, recordHash, signingKeyID, signature sql.NullString
+	var requestBodyRef, responseBodyRef, teamID sql.NullString
+	var toolCalls, toolResults, responseAnalysis, findings sql.NullString
+	var traceID, decisionID, anonymizedHash, costTag, limitBypass, dataClassification sql.NullString
+	var sequence sql.NullInt64
+	var anonymizedAt sql.NullTime
+	var timeToFirstTokenMs, streamDurationMs int64
 
 	err := row.Scan(
 		&record.ID, &record.RequestID,
 		&record.RequestTime, &record.PolicyEvalTime, &record.ProviderCallTime, &record.ResponseTime, &record.RecordedTime,
 		&record.RequestHash, &record.RequestMethod, &record.RequestPath, &requestHeaders,
-		&record.Model, &record.Provider, &record.Messages, &record.SystemPrompt, &record.UserPrompt, &toolsUsed,
+		&record.Model, &record.Provider, &record.Messages, textColumn{&record.SystemPrompt}, textColumn{&record.UserPrompt}, &toolsUsed,
 		&record.EstimatedTokens, &record.EstimatedCost, &record.RiskScore, &record.ComplexityScore, &record.PIIDetected, &piiTypes,
 		&record.PolicyDecision, &matchedRules, &record.BlockReason, &record.PolicyVersion,
 		&record.ResponseHash, &record.ResponseStatus,
-		&record.ResponseContent, &record.FinishReason,
+		textColumn{&record.ResponseContent}, &record.FinishReason,
 		&record.PromptTokens, &record.CompletionTokens, &record.TotalTokens, &record.ActualCost,
 		&providerLatencyMs, &record.ProviderModel,
 		&record.UserID, &record.APIKey, &record.IPAddress,
 		&errorVal, &errorTypeVal,
 		&record.TurnNumber, &record.ContextUsage,
+		&chainID, &sequence, &prevHash, &recordHash,
+		&signingKeyID, &signature,
+		&requestBodyRef, &responseBodyRef, &record.BodyTruncated,
+		&teamID,
+		&anonymizedAt,
+		&record.Streamed, &record.StreamChunks, &timeToFirstTokenMs, &streamDurationMs, &record.ClientDisconnected,
+		&toolCalls, &toolResults,
+		&traceID, &decisionID,
+		&anonymizedHash,
+		&costTag,
+		&limitBypass,
+		&responseAnalysis,
+		&dataClassification, &record.DataClassificationLevel,
+		&findings,
 	)
 	if err != nil {
 		return nil, err
@@ -549,6 +992,26 @@ func (s *SQLiteStorage) scanRow(row *sql.Rows) (*evidence.EvidenceRecord, error)
 	if errorTypeVal.Valid {
 		record.ErrorType = errorTypeVal.String
 	}
+	record.ChainID = chainID.String
+	record.Sequence = sequence.Int64
+	record.PrevHash = prevHash.String
+	record.RecordHash = recordHash.String
+	record.SigningKeyID = signingKeyID.String
+	record.Signature = signature.String
+	record.RequestBodyRef = requestBodyRef.String
+	record.ResponseBodyRef = responseBodyRef.String
+	record.TeamID = teamID.String
+	record.TraceID = traceID.String
+	record.DecisionID = decisionID.String
+	if anonymizedAt.Valid {
+		record.AnonymizedAt = &anonymizedAt.Time
+	}
+	record.AnonymizedHash = anonymizedHash.String
+	record.CostTag = costTag.String
+	record.LimitBypass = limitBypass.String
+	record.DataClassification = dataClassification.String
+	record.TimeToFirstToken = time.Duration(timeToFirstTokenMs) * time.Millisecond
+	record.StreamDuration = time.Duration(streamDurationMs) * time.Millisecond
 
 	// Unmarshal JSON fields
 	if requestHeaders != "" {
@@ -571,6 +1034,27 @@ func (s *SQLiteStorage) scanRow(row *sql.Rows) (*evidence.EvidenceRecord, error)
 			s.logger.Warn("failed to unmarshal matched rules", "record_id", record.ID, "error", err)
 		}
 	}
+	if toolCalls.Valid {
+		if err := json.Unmarshal([]byte(toolCalls.String), &record.ToolCalls); err != nil {
+			s.logger.Warn("failed to unmarshal tool calls", "record_id", record.ID, "error", err)
+		}
+	}
+	if toolResults.Valid {
+		if err := json.Unmarshal([]byte(toolResults.String), &record.ToolResults); err != nil {
+			s.logger.Warn("failed to unmarshal tool results", "record_id", record.ID, "error", err)
+		}
+	}
+	if responseAnalysis.Valid {
+		record.ResponseAnalysis = &evidence.ResponseAnalysisRecord{}
+		if err := json.Unmarshal([]byte(responseAnalysis.String), record.ResponseAnalysis); err != nil {
+			s.logger.Warn("failed to unmarshal response analysis", "record_id", record.ID, "error", err)
+		}
+	}
+	if findings.Valid {
+		if err := json.Unmarshal([]byte(findings.String), &record.Findings); err != nil {
+			s.logger.Warn("failed to unmarshal findings", "record_id", record.ID, "error", err)
+		}
+	}
 
 	// Convert provider latency from milliseconds
 	record.ProviderLatency = time.Duration(providerLatencyMs) * time.Millisecond
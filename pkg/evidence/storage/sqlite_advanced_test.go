@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -231,9 +232,9 @@ func TestSQLiteStorage_PreparedStatementReuse(t *testing.T) {
 	stmtCount := len(storage.preparedStmts)
 	storage.mu.RUnlock()
 
-	t.Logf("Prepared statements in cache: %d", stmtCount)
-	// Note: Current implementation doesn't cache prepared statements
-	// This test documents the behavior for future optimization
+	if stmtCount != 1 {
+		t.Errorf("Expected the insert statement to be cached once, got %d statements", stmtCount)
+	}
 }
 
 // TestSQLiteStorage_BusyTimeout tests that busy timeout is configured.
@@ -285,6 +286,141 @@ func TestSQLiteStorage_SchemaVersion(t *testing.T) {
 	t.Logf("Schema version verified: %d", version)
 }
 
+// TestSQLiteStorage_MigrateFromVersion1 tests that a database created with
+// schema version 1 is migrated when opened.
+func TestSQLiteStorage_MigrateFromVersion1(t *testing.T) {
+	storage, dbPath := createTempDB(t)
+	now := time.Now()
+	record := &evidence.EvidenceRecord{ID: "legacy-1", RequestID: "req-1", RequestTime: now, PolicyDecision: "allow"}
+	if err := storage.Store(context.Background(), record); err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+
+	// Rewind the database to schema version 1
+	for _, stmt := range []string{
+		"DROP INDEX idx_evidence_chain",
+		"DROP INDEX idx_evidence_team_id",
+		"DROP INDEX idx_evidence_trace_id",
+		"DROP INDEX idx_evidence_decision_id",
+		"DROP INDEX idx_evidence_cost_tag",
+		"DROP INDEX idx_evidence_limit_bypass",
+		"ALTER TABLE evidence DROP COLUMN chain_id",
+		"ALTER TABLE evidence DROP COLUMN sequence",
+		"ALTER TABLE evidence DROP COLUMN prev_hash",
+		"ALTER TABLE evidence DROP COLUMN record_hash",
+		"ALTER TABLE evidence DROP COLUMN signing_key_id",
+		"ALTER TABLE evidence DROP COLUMN signature",
+		"ALTER TABLE evidence DROP COLUMN request_body_ref",
+		"ALTER TABLE evidence DROP COLUMN response_body_ref",
+		"ALTER TABLE evidence DROP COLUMN body_truncated",
+		"ALTER TABLE evidence DROP COLUMN team_id",
+		"ALTER TABLE evidence DROP COLUMN anonymized_at",
+		"ALTER TABLE evidence DROP COLUMN streamed",
+		"ALTER TABLE evidence DROP COLUMN stream_chunks",
+		"ALTER TABLE evidence DROP COLUMN time_to_first_token",
+		"ALTER TABLE evidence DROP COLUMN stream_duration",
+		"ALTER TABLE evidence DROP COLUMN client_disconnected",
+		"ALTER TABLE evidence DROP COLUMN tool_calls",
+		"ALTER TABLE evidence DROP COLUMN tool_results",
+		"ALTER TABLE evidence DROP COLUMN trace_id",
+		"ALTER TABLE evidence DROP COLUMN decision_id",
+		"ALTER TABLE evidence DROP COLUMN anonymized_hash",
+		"ALTER TABLE evidence DROP COLUMN cost_tag",
+		"ALTER TABLE evidence DROP COLUMN limit_bypass",
+		"ALTER TABLE evidence DROP COLUMN response_analysis",
+		"DROP INDEX idx_evidence_data_classification",
+		"ALTER TABLE evidence DROP COLUMN data_classification",
+		"ALTER TABLE evidence DROP COLUMN data_classification_level",
+		"ALTER TABLE evidence DROP COLUMN findings",
+		"DROP TABLE evidence_rollup_daily",
+		"UPDATE schema_version SET version = 1",
+	} {
+		if _, err := storage.db.Exec(stmt); err != nil {
+			t.Fatalf("%s: %v", stmt, err)
+		}
+	}
+	storage.Close()
+
+	migrated, err := NewSQLiteStorage(&SQLiteConfig{
+		Path:         dbPath,
+		MaxOpenConns: 5,
+		MaxIdleConns: 2,
+		BusyTimeout:  5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Failed to open version 1 database: %v", err)
+	}
+	defer migrated.Close()
+
+	chained := &evidence.EvidenceRecord{
+		ID:             "chained-1",
+		RequestID:      "req-2",
+		RequestTime:    now,
+		PolicyDecision: "allow",
+		ChainID:        "chain-a",
+		Sequence:       1,
+		RecordHash:     "abc123",
+		SigningKeyID:   "key-1",
+		Signature:      "c2ln",
+		RequestBodyRef: "sha256:00",
+		BodyTruncated:  true,
+		CostTag:        "search",
+		LimitBypass:    "break_glass:oncall",
+		ResponseAnalysis: &evidence.ResponseAnalysisRecord{
+			SecretTypes: []string{"aws_access_key"},
+		},
+		DataClassification:      "restricted",
+		DataClassificationLevel: 3,
+		Findings: []evidence.FindingRecord{
+			{Stage: "response", Detector: "secrets", Type: "aws_access_key", Source: "pattern", Confidence: 1, Start: 10, End: 30},
+		},
+	}
+	if err := migrated.Store(context.Background(), chained); err != nil {
+		t.Fatalf("Store() after migration error = %v", err)
+	}
+
+	records, err := migrated.Query(context.Background(), &evidence.Query{Limit: 10})
+	if err != nil {
+		t.Fatalf("Query() error = %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	for _, r := range records {
+		switch r.ID {
+		case "legacy-1":
+			if r.ChainID != "" || r.Sequence != 0 {
+				t.Errorf("legacy record has chain %q/%d", r.ChainID, r.Sequence)
+			}
+		case "chained-1":
+			if r.ChainID != "chain-a" || r.Sequence != 1 || r.RecordHash != "abc123" {
+				t.Errorf("chain fields not persisted: %q/%d/%q", r.ChainID, r.Sequence, r.RecordHash)
+			}
+			if r.SigningKeyID != "key-1" || r.Signature != "c2ln" {
+				t.Errorf("signature not persisted: %q/%q", r.SigningKeyID, r.Signature)
+			}
+			if r.RequestBodyRef != "sha256:00" || !r.BodyTruncated {
+				t.Errorf("body capture not persisted: %q/%t", r.RequestBodyRef, r.BodyTruncated)
+			}
+			if r.CostTag != "search" {
+				t.Errorf("cost tag not persisted: %q", r.CostTag)
+			}
+			if r.LimitBypass != "break_glass:oncall" {
+				t.Errorf("limit bypass not persisted: %q", r.LimitBypass)
+			}
+			if r.ResponseAnalysis == nil || len(r.ResponseAnalysis.SecretTypes) != 1 {
+				t.Errorf("response analysis not persisted: %+v", r.ResponseAnalysis)
+			}
+			if r.DataClassification != "restricted" || r.DataClassificationLevel != 3 {
+				t.Errorf("data classification not persisted: %q/%d", r.DataClassification, r.DataClassificationLevel)
+			}
+			if len(r.Findings) != 1 || r.Findings[0].End != 30 {
+				t.Errorf("findings not persisted: %+v", r.Findings)
+			}
+		}
+	}
+}
+
 // TestSQLiteStorage_LargeResultSet tests querying large result sets.
 func TestSQLiteStorage_LargeResultSet(t *testing.T) {
 	if testing.Short() {
@@ -540,3 +676,91 @@ func BenchmarkSQLiteStorage_InsertPerformance(b *testing.B) {
 		b.Logf("Warning: Average insert time %v exceeds target of 5ms", avgInsertTime)
 	}
 }
+
+// TestSQLiteStorage_WriteOnce tests that write-once databases reject
+// deletes and updates, also after write-once mode is turned off.
+func TestSQLiteStorage_WriteOnce(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "worm.db")
+	config := &SQLiteConfig{Path: dbPath, MaxOpenConns: 1, WALMode: true, BusyTimeout: time.Second, WriteOnce: true}
+	storage, err := NewSQLiteStorage(config)
+	if err != nil {
+		t.Fatalf("Failed to create SQLite storage: %v", err)
+	}
+
+	ctx := context.Background()
+	record := &evidence.EvidenceRecord{ID: "rec-1", RequestID: "req-1", RequestTime: time.Now(), Model: "gpt-4"}
+	if err := storage.Store(ctx, record); err != nil {
+		t.Fatalf("Store() failed: %v", err)
+	}
+	if _, err := storage.Delete(ctx, &evidence.Query{}); !errors.Is(err, evidence.ErrWriteOnce) {
+		t.Errorf("Expected ErrWriteOnce, got %v", err)
+	}
+	if _, err := storage.db.ExecContext(ctx, "UPDATE evidence SET model = 'tampered'"); err == nil {
+		t.Error("Expected UPDATE to be rejected")
+	}
+	storage.Close()
+
+	// The triggers outlive write-once mode
+	config.WriteOnce = false
+	storage, err = NewSQLiteStorage(config)
+	if err != nil {
+		t.Fatalf("Failed to reopen SQLite storage: %v", err)
+	}
+	defer storage.Close()
+	if _, err := storage.Delete(ctx, &evidence.Query{}); err == nil {
+		t.Error("Expected DELETE to be rejected by trigger")
+	}
+	if count, _ := storage.Count(ctx, &evidence.Query{}); count != 1 {
+		t.Errorf("Expected 1 record, got %d", count)
+	}
+}
+
+func TestSQLiteStorage_Compression(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "compressed.db")
+	config := &SQLiteConfig{Path: dbPath, MaxOpenConns: 1, WALMode: true, BusyTimeout: time.Second, CompressMinSize: 256}
+	storage, err := NewSQLiteStorage(config)
+	if err != nil {
+		t.Fatalf("Failed to create SQLite storage: %v", err)
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+	long := strings.Repeat("Summarize the quarterly revenue report for the board. ", 50)
+	records := []*evidence.EvidenceRecord{
+		{ID: "long", RequestID: "req-1", RequestTime: time.Now(), Model: "gpt-4", UserPrompt: long, ResponseContent: long + "kangaroo"},
+		{ID: "short", RequestID: "req-2", RequestTime: time.Now(), Model: "gpt-4", UserPrompt: "hello"},
+	}
+	for _, record := range records {
+		if err := storage.Store(ctx, record); err != nil {
+			t.Fatalf("Store() failed: %v", err)
+		}
+	}
+
+	var storedType string
+	var storedSize int
+	err = storage.db.QueryRow("SELECT typeof(user_prompt), length(user_prompt) FROM evidence WHERE id = 'long'").Scan(&storedType, &storedSize)
+	if err != nil {
+		t.Fatalf("Failed to inspect stored prompt: %v", err)
+	}
+	if storedType != "blob" || storedSize >= len(long) {
+		t.Errorf("Expected compressed prompt, got %s of %d bytes", storedType, storedSize)
+	}
+
+	results, err := storage.Query(ctx, &evidence.Query{IDs: []string{"long", "short"}})
+	if err != nil || len(results) != 2 {
+		t.Fatalf("Query() = %d records, %v", len(results), err)
+	}
+	for _, got := range results {
+		for _, want := range records {
+			if got.ID == want.ID && (got.UserPrompt != want.UserPrompt || got.ResponseContent != want.ResponseContent) {
+				t.Errorf("Record %s not decompressed", got.ID)
+			}
+		}
+	}
+
+	// The search index holds the uncompressed text
+	results, err = storage.Query(ctx, &evidence.Query{Search: "kangaroo"})
+	if err != nil || len(results) != 1 || results[0].ID != "long" {
+		t.Errorf("Search for compressed text = %d records, %v", len(results), err)
+	}
+}
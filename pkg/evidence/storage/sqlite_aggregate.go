@@ -0,0 +1,369 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+// evidenceDimensions maps aggregation dimensions to expressions over the
+// evidence table.
+var evidenceDimensions = map[string]string{
+	evidence.DimensionUser:     "COALESCE(user_id, '')",
+	evidence.DimensionTeam:     "COALESCE(team_id, '')",
+	evidence.DimensionProvider: "provider",
+	evidence.DimensionModel:    "model",
+	evidence.DimensionDay:      "date(request_time)",
+	evidence.DimensionCostTag:  "COALESCE(cost_tag, '')",
+}
+
+// rollupDimensions maps aggregation dimensions to columns of the
+// evidence_rollup_daily table.
+var rollupDimensions = map[string]string{
+	evidence.DimensionUser:     "user_id",
+	evidence.DimensionTeam:     "team_id",
+	evidence.DimensionProvider: "provider",
+	evidence.DimensionModel:    "model",
+	evidence.DimensionDay:      "day",
+	evidence.DimensionCostTag:  "cost_tag",
+}
+
+// refreshRollupsSQL computes the daily rollups of the records from the
+// day given as argument onwards.
+const refreshRollupsSQL = `
+INSERT INTO evidence_rollup_daily (
+    day, user_id, team_id, provider, model, cost_tag, policy_decision,
+    requests, prompt_tokens, completion_tokens, total_tokens, cost
+)
+SELECT
+    date(request_time), COALESCE(user_id, ''), COALESCE(team_id, ''), provider, model, COALESCE(cost_tag, ''), policy_decision,
+    COUNT(*), COALESCE(SUM(prompt_tokens), 0), COALESCE(SUM(completion_tokens), 0),
+    COALESCE(SUM(total_tokens), 0), COALESCE(SUM(actual_cost), 0)
+FROM evidence
+WHERE date(request_time) >= ?
+GROUP BY 1, 2, 3, 4, 5, 6, 7
+`
+
+// anonymizeRollupsSQL adds the rollups of the user given as argument to
+// the rollups without a user.
+const anonymizeRollupsSQL = `
+INSERT INTO evidence_rollup_daily (
+    day, user_id, team_id, provider, model, cost_tag, policy_decision,
+    requests, prompt_tokens, completion_tokens, total_tokens, cost
+)
+SELECT day, '', team_id, provider, model, cost_tag, policy_decision,
+    requests, prompt_tokens, completion_tokens, total_tokens, cost
+FROM evidence_rollup_daily
+WHERE user_id = ?
+ON CONFLICT (day, user_id, team_id, provider, model, cost_tag, policy_decision) DO UPDATE SET
+    requests = requests + excluded.requests,
+    prompt_tokens = prompt_tokens + excluded.prompt_tokens,
+    completion_tokens = completion_tokens + excluded.completion_tokens,
+    total_tokens = total_tokens + excluded.total_tokens,
+    cost = cost + excluded.cost
+`
+
+// pseudonymizeRollupsSQL adds the rollups of a user (second argument)
+// before a day (third argument) to the rollups of a pseudonym (first
+// argument).
+const pseudonymizeRollupsSQL = `
+INSERT INTO evidence_rollup_daily (
+    day, user_id, team_id, provider, model, cost_tag, policy_decision,
+    requests, prompt_tokens, completion_tokens, total_tokens, cost
+)
+SELECT day, ?, team_id, provider, model, cost_tag, policy_decision,
+    requests, prompt_tokens, completion_tokens, total_tokens, cost
+FROM evidence_rollup_daily
+WHERE user_id = ? AND day < ?
+ON CONFLICT (day, user_id, team_id, provider, model, cost_tag, policy_decision) DO UPDATE SET
+    requests = requests + excluded.requests,
+    prompt_tokens = prompt_tokens + excluded.prompt_tokens,
+    completion_tokens = completion_tokens + excluded.completion_tokens,
+    total_tokens = total_tokens + excluded.total_tokens,
+    cost = cost + excluded.cost
+`
+
+var (
+	_ evidence.Aggregator  = (*SQLiteStorage)(nil)
+	_ evidence.RollupStore = (*SQLiteStorage)(nil)
+)
+
+// Aggregate groups the evidence records matching the query filters and
+// returns their totals.
+func (s *SQLiteStorage) Aggregate(ctx context.Context, query *evidence.AggregateQuery) ([]*evidence.AggregateRow, error) {
+	whereClause, args := s.buildWhereClause(&query.Query)
+
+	sqlQuery, err := aggregateSQL(evidenceDimensions, query.GroupBy,
+		`COUNT(*), COALESCE(SUM(prompt_tokens), 0), COALESCE(SUM(completion_tokens), 0),
+		COALESCE(SUM(total_tokens), 0), COALESCE(SUM(actual_cost), 0)`,
+		"evidence", whereClause)
+	if err != nil {
+		return nil, evidence.NewStorageError("sqlite", "aggregate", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, evidence.NewStorageError("sqlite", "aggregate", err)
+	}
+	defer rows.Close()
+
+	result, err := scanAggregateRows(rows, query.GroupBy)
+	if err != nil {
+		return nil, evidence.NewStorageError("sqlite", "aggregate", err)
+	}
+	return result, nil
+}
+
+// RefreshRollups recomputes the daily rollups of every UTC day from since
+// onwards. Rollups of earlier days are kept, so totals remain available
+// after retention pruning deletes the records.
+func (s *SQLiteStorage) RefreshRollups(ctx context.Context, since time.Time) error {
+	day := since.UTC().Format(time.DateOnly)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return evidence.NewStorageError("sqlite", "refresh_rollups", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM evidence_rollup_daily WHERE day >= ?", day); err != nil {
+		return evidence.NewStorageError("sqlite", "refresh_rollups", err)
+	}
+	if _, err := tx.ExecContext(ctx, refreshRollupsSQL, day); err != nil {
+		return evidence.NewStorageError("sqlite", "refresh_rollups", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return evidence.NewStorageError("sqlite", "refresh_rollups", err)
+	}
+	return nil
+}
+
+// LatestRollup returns the most recent day with a rollup.
+func (s *SQLiteStorage) LatestRollup(ctx context.Context) (time.Time, error) {
+	var day sql.NullString
+	if err := s.db.QueryRowContext(ctx, "SELECT MAX(day) FROM evidence_rollup_daily").Scan(&day); err != nil {
+		return time.Time{}, evidence.NewStorageError("sqlite", "latest_rollup", err)
+	}
+	if !day.Valid {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.DateOnly, day.String)
+	if err != nil {
+		return time.Time{}, evidence.NewStorageError("sqlite", "latest_rollup", err)
+	}
+	return t, nil
+}
+
+// AggregateRollups aggregates the daily rollups instead of the records.
+func (s *SQLiteStorage) AggregateRollups(ctx context.Context, query *evidence.AggregateQuery) ([]*evidence.AggregateRow, error) {
+	q := &query.Query
+	if q.APIKey != "" || q.PolicyID != "" || q.RuleID != "" || q.Status != "" ||
+		q.MinCost != nil || q.MaxCost != nil || q.MinTokens != nil || q.MaxTokens != nil ||
+		q.Search != "" {
+		return nil, evidence.NewStorageError("sqlite", "aggregate_rollups",
+			fmt.Errorf("rollups only support time, user, team, cost tag, provider, model, and decision filters"))
+	}
+
+	var conditions []string
+	var args []interface{}
+	if q.StartTime != nil {
+		conditions = append(conditions, "day >= ?")
+		args = append(args, q.StartTime.UTC().Format(time.DateOnly))
+	}
+	if q.EndTime != nil {
+		conditions = append(conditions, "day <= ?")
+		args = append(args, q.EndTime.UTC().Format(time.DateOnly))
+	}
+	for column, value := range map[string]string{
+		"user_id":         q.UserID,
+		"team_id":         q.TeamID,
+		"cost_tag":        q.CostTag,
+		"provider":        q.Provider,
+		"model":           q.Model,
+		"policy_decision": q.PolicyDecision,
+	} {
+		if value != "" {
+			conditions = append(conditions, column+" = ?")
+			args = append(args, value)
+		}
+	}
+
+	sqlQuery, err := aggregateSQL(rollupDimensions, query.GroupBy,
+		`SUM(requests), SUM(prompt_tokens), SUM(completion_tokens), SUM(total_tokens), SUM(cost)`,
+		"evidence_rollup_daily", strings.Join(conditions, " AND "))
+	if err != nil {
+		return nil, evidence.NewStorageError("sqlite", "aggregate_rollups", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx, sqlQuery, args...)
+	if err != nil {
+		return nil, evidence.NewStorageError("sqlite", "aggregate_rollups", err)
+	}
+	defer rows.Close()
+
+	result, err := scanAggregateRows(rows, query.GroupBy)
+	if err != nil {
+		return nil, evidence.NewStorageError("sqlite", "aggregate_rollups", err)
+	}
+	return result, nil
+}
+
+// AnonymizeRollups merges the rollups of a user into those without a user.
+func (s *SQLiteStorage) AnonymizeRollups(ctx context.Context, userID string) error {
+	if userID == "" {
+		return nil
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return evidence.NewStorageError("sqlite", "anonymize_rollups", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, anonymizeRollupsSQL, userID); err != nil {
+		return evidence.NewStorageError("sqlite", "anonymize_rollups", err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM evidence_rollup_daily WHERE user_id = ?", userID); err != nil {
+		return evidence.NewStorageError("sqlite", "anonymize_rollups", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return evidence.NewStorageError("sqlite", "anonymize_rollups", err)
+	}
+	return nil
+}
+
+// PseudonymizeRollups replaces the user IDs of the rollups before a day
+// with their pseudonyms.
+func (s *SQLiteStorage) PseudonymizeRollups(ctx context.Context, before time.Time, pseudonym func(userID string) string) error {
+	day := before.UTC().Format(time.DateOnly)
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return evidence.NewStorageError("sqlite", "pseudonymize_rollups", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx,
+		"SELECT DISTINCT user_id FROM evidence_rollup_daily WHERE user_id != '' AND day < ?", day)
+	if err != nil {
+		return evidence.NewStorageError("sqlite", "pseudonymize_rollups", err)
+	}
+	var users []string
+	for rows.Next() {
+		var userID string
+		if err := rows.Scan(&userID); err != nil {
+			rows.Close()
+			return evidence.NewStorageError("sqlite", "pseudonymize_rollups", err)
+		}
+		users = append(users, userID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return evidence.NewStorageError("sqlite", "pseudonymize_rollups", err)
+	}
+
+	for _, userID := range users {
+		alias := pseudonym(userID)
+		if alias == userID {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, pseudonymizeRollupsSQL, alias, userID, day); err != nil {
+			return evidence.NewStorageError("sqlite", "pseudonymize_rollups", err)
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM evidence_rollup_daily WHERE user_id = ? AND day < ?", userID, day); err != nil {
+			return evidence.NewStorageError("sqlite", "pseudonymize_rollups", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return evidence.NewStorageError("sqlite", "pseudonymize_rollups", err)
+	}
+	return nil
+}
+
+// aggregateSQL builds a query that selects the groupBy dimensions, the
+// policy decision, and the totals, grouped and ordered by the dimensions
+// and the decision.
+func aggregateSQL(dimensions map[string]string, groupBy []string, totals, table, whereClause string) (string, error) {
+	exprs := make([]string, 0, len(groupBy)+1)
+	for _, dim := range groupBy {
+		expr, ok := dimensions[dim]
+		if !ok {
+			return "", fmt.Errorf("unknown aggregation dimension %q", dim)
+		}
+		exprs = append(exprs, expr)
+	}
+	exprs = append(exprs, "COALESCE(policy_decision, '')")
+	group := strings.Join(exprs, ", ")
+
+	sqlQuery := "SELECT " + group + ", " + totals + " FROM " + table
+	if whereClause != "" {
+		sqlQuery += " WHERE " + whereClause
+	}
+	sqlQuery += " GROUP BY " + group + " ORDER BY " + group
+	return sqlQuery, nil
+}
+
+// scanAggregateRows merges the per-decision rows of an aggregateSQL query
+// into one row per group.
+func scanAggregateRows(rows *sql.Rows, groupBy []string) ([]*evidence.AggregateRow, error) {
+	var (
+		result []*evidence.AggregateRow
+		last   []string
+	)
+	for rows.Next() {
+		values := make([]string, len(groupBy))
+		var (
+			decision                                  string
+			requests, prompt, completion, totalTokens int64
+			cost                                      float64
+		)
+		dest := make([]interface{}, 0, len(groupBy)+6)
+		for i := range values {
+			dest = append(dest, &values[i])
+		}
+		dest = append(dest, &decision, &requests, &prompt, &completion, &totalTokens, &cost)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, err
+		}
+
+		if len(result) == 0 || !slices.Equal(values, last) {
+			row := &evidence.AggregateRow{Decisions: make(map[string]int64)}
+			for i, dim := range groupBy {
+				setDimension(row, dim, values[i])
+			}
+			result = append(result, row)
+			last = values
+		}
+		row := result[len(result)-1]
+		row.Requests += requests
+		row.PromptTokens += prompt
+		row.CompletionTokens += completion
+		row.TotalTokens += totalTokens
+		row.Cost += cost
+		row.Decisions[decision] += requests
+	}
+	return result, rows.Err()
+}
+
+// setDimension sets the field of row for an aggregation dimension.
+func setDimension(row *evidence.AggregateRow, dim, value string) {
+	switch dim {
+	case evidence.DimensionUser:
+		row.User = value
+	case evidence.DimensionTeam:
+		row.Team = value
+	case evidence.DimensionProvider:
+		row.Provider = value
+	case evidence.DimensionModel:
+		row.Model = value
+	case evidence.DimensionDay:
+		row.Day = value
+	case evidence.DimensionCostTag:
+		row.CostTag = value
+	}
+}
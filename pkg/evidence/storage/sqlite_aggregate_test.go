@@ -0,0 +1,227 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+// storeAggregateRecords stores records over two days for aggregation tests.
+func storeAggregateRecords(t *testing.T, storage *SQLiteStorage) time.Time {
+	t.Helper()
+
+	ctx := context.Background()
+	day1 := time.Date(2025, 11, 1, 10, 0, 0, 0, time.UTC)
+	day2 := day1.AddDate(0, 0, 1)
+
+	records := []*evidence.EvidenceRecord{
+		{RequestTime: day1, UserID: "alice", TeamID: "red", Provider: "openai", Model: "gpt-4", PolicyDecision: "allow", CostTag: "search", PromptTokens: 10, CompletionTokens: 20, TotalTokens: 30, ActualCost: 0.5},
+		{RequestTime: day1, UserID: "bob", TeamID: "red", Provider: "openai", Model: "gpt-4", PolicyDecision: "block", PromptTokens: 5, TotalTokens: 5},
+		{RequestTime: day1, UserID: "alice", TeamID: "red", Provider: "anthropic", Model: "claude-3", PolicyDecision: "allow", PromptTokens: 1, CompletionTokens: 2, TotalTokens: 3, ActualCost: 0.25},
+		{RequestTime: day2, UserID: "alice", Provider: "openai", Model: "gpt-4", PolicyDecision: "allow", CostTag: "search", PromptTokens: 100, CompletionTokens: 100, TotalTokens: 200, ActualCost: 2},
+	}
+	for i, record := range records {
+		record.ID = fmt.Sprintf("record-%d", i)
+		record.RequestID = fmt.Sprintf("req-%d", i)
+		if err := storage.Store(ctx, record); err != nil {
+			t.Fatalf("Store() failed: %v", err)
+		}
+	}
+	return day1
+}
+
+// TestSQLiteStorage_Aggregate tests grouping records by dimensions.
+func TestSQLiteStorage_Aggregate(t *testing.T) {
+	storage, _ := createTempDB(t)
+	defer storage.Close()
+	storeAggregateRecords(t, storage)
+
+	ctx := context.Background()
+	rows, err := storage.Aggregate(ctx, &evidence.AggregateQuery{
+		GroupBy: []string{evidence.DimensionModel, evidence.DimensionDay},
+	})
+	if err != nil {
+		t.Fatalf("Aggregate() failed: %v", err)
+	}
+
+	if len(rows) != 3 {
+		t.Fatalf("Expected 3 rows, got %d", len(rows))
+	}
+	gpt4 := rows[1]
+	if gpt4.Model != "gpt-4" || gpt4.Day != "2025-11-01" {
+		t.Fatalf("Expected gpt-4 on 2025-11-01, got %s on %s", gpt4.Model, gpt4.Day)
+	}
+	if gpt4.Requests != 2 || gpt4.TotalTokens != 35 || gpt4.Cost != 0.5 {
+		t.Errorf("Unexpected totals: %+v", gpt4)
+	}
+	if gpt4.Decisions["allow"] != 1 || gpt4.Decisions["block"] != 1 {
+		t.Errorf("Unexpected decisions: %v", gpt4.Decisions)
+	}
+
+	// Filters apply before grouping
+	rows, err = storage.Aggregate(ctx, &evidence.AggregateQuery{
+		Query:   evidence.Query{TeamID: "red"},
+		GroupBy: []string{evidence.DimensionUser},
+	})
+	if err != nil {
+		t.Fatalf("Aggregate() failed: %v", err)
+	}
+	if len(rows) != 2 || rows[0].User != "alice" || rows[0].Requests != 2 || rows[1].User != "bob" {
+		t.Errorf("Unexpected rows for team red: %+v", rows)
+	}
+
+	// Untagged records are grouped under the empty tag
+	rows, err = storage.Aggregate(ctx, &evidence.AggregateQuery{
+		GroupBy: []string{evidence.DimensionCostTag},
+	})
+	if err != nil {
+		t.Fatalf("Aggregate() failed: %v", err)
+	}
+	if len(rows) != 2 || rows[0].CostTag != "" || rows[1].CostTag != "search" || rows[1].Cost != 2.5 {
+		t.Errorf("Unexpected rows by cost tag: %+v", rows)
+	}
+
+	// Without dimensions, everything is one group
+	rows, err = storage.Aggregate(ctx, &evidence.AggregateQuery{})
+	if err != nil {
+		t.Fatalf("Aggregate() failed: %v", err)
+	}
+	if len(rows) != 1 || rows[0].Requests != 4 || rows[0].Cost != 2.75 {
+		t.Errorf("Unexpected total row: %+v", rows)
+	}
+}
+
+// TestSQLiteStorage_Rollups tests refreshing and aggregating daily rollups.
+func TestSQLiteStorage_Rollups(t *testing.T) {
+	storage, _ := createTempDB(t)
+	defer storage.Close()
+	day1 := storeAggregateRecords(t, storage)
+
+	ctx := context.Background()
+	latest, err := storage.LatestRollup(ctx)
+	if err != nil {
+		t.Fatalf("LatestRollup() failed: %v", err)
+	}
+	if !latest.IsZero() {
+		t.Errorf("Expected no rollups, got %v", latest)
+	}
+
+	if err := storage.RefreshRollups(ctx, time.Time{}); err != nil {
+		t.Fatalf("RefreshRollups() failed: %v", err)
+	}
+	latest, err = storage.LatestRollup(ctx)
+	if err != nil {
+		t.Fatalf("LatestRollup() failed: %v", err)
+	}
+	if want := time.Date(2025, 11, 2, 0, 0, 0, 0, time.UTC); !latest.Equal(want) {
+		t.Errorf("Expected latest rollup %v, got %v", want, latest)
+	}
+
+	query := &evidence.AggregateQuery{GroupBy: []string{evidence.DimensionCostTag, evidence.DimensionModel, evidence.DimensionDay}}
+	live, err := storage.Aggregate(ctx, query)
+	if err != nil {
+		t.Fatalf("Aggregate() failed: %v", err)
+	}
+	rolled, err := storage.AggregateRollups(ctx, query)
+	if err != nil {
+		t.Fatalf("AggregateRollups() failed: %v", err)
+	}
+	if len(rolled) != len(live) {
+		t.Fatalf("Expected %d rollup rows, got %d", len(live), len(rolled))
+	}
+	for i := range live {
+		if fmt.Sprint(*live[i]) != fmt.Sprint(*rolled[i]) {
+			t.Errorf("Row %d: rollup %+v differs from live %+v", i, *rolled[i], *live[i])
+		}
+	}
+
+	// Rollups of pruned days survive a refresh from a later day
+	end := day1.Add(time.Hour)
+	if deleted, err := storage.Delete(ctx, &evidence.Query{EndTime: &end}); err != nil {
+		t.Fatalf("Delete() failed: %v", err)
+	} else if deleted != 3 {
+		t.Fatalf("Expected 3 deleted, got %d", deleted)
+	}
+	if err := storage.RefreshRollups(ctx, day1.AddDate(0, 0, 1)); err != nil {
+		t.Fatalf("RefreshRollups() failed: %v", err)
+	}
+	rolled, err = storage.AggregateRollups(ctx, &evidence.AggregateQuery{})
+	if err != nil {
+		t.Fatalf("AggregateRollups() failed: %v", err)
+	}
+	if len(rolled) != 1 || rolled[0].Requests != 4 {
+		t.Errorf("Expected 4 requests in rollups after pruning, got %+v", rolled)
+	}
+
+	// Record-level filters are not available in rollups
+	if _, err := storage.AggregateRollups(ctx, &evidence.AggregateQuery{
+		Query: evidence.Query{APIKey: "sk-123"},
+	}); err == nil {
+		t.Error("Expected error for API key filter on rollups")
+	}
+}
+
+// TestSQLiteStorage_AnonymizeRollups tests merging a user's rollups into
+// the rollups without a user.
+func TestSQLiteStorage_AnonymizeRollups(t *testing.T) {
+	storage, _ := createTempDB(t)
+	defer storage.Close()
+	storeAggregateRecords(t, storage)
+
+	ctx := context.Background()
+	if err := storage.RefreshRollups(ctx, time.Time{}); err != nil {
+		t.Fatalf("RefreshRollups() failed: %v", err)
+	}
+	if err := storage.AnonymizeRollups(ctx, "alice"); err != nil {
+		t.Fatalf("AnonymizeRollups() failed: %v", err)
+	}
+
+	rows, err := storage.AggregateRollups(ctx, &evidence.AggregateQuery{GroupBy: []string{evidence.DimensionUser}})
+	if err != nil {
+		t.Fatalf("AggregateRollups() failed: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 users, got %+v", rows)
+	}
+	if rows[0].User != "" || rows[0].Requests != 3 || rows[0].Cost != 2.75 {
+		t.Errorf("Expected alice's 3 requests without user, got %+v", *rows[0])
+	}
+	if rows[1].User != "bob" || rows[1].Requests != 1 {
+		t.Errorf("Expected bob's rollups unchanged, got %+v", *rows[1])
+	}
+}
+
+// TestSQLiteStorage_PseudonymizeRollups tests replacing the user IDs of
+// earlier rollups.
+func TestSQLiteStorage_PseudonymizeRollups(t *testing.T) {
+	storage, _ := createTempDB(t)
+	defer storage.Close()
+	day1 := storeAggregateRecords(t, storage)
+
+	ctx := context.Background()
+	if err := storage.RefreshRollups(ctx, time.Time{}); err != nil {
+		t.Fatalf("RefreshRollups() failed: %v", err)
+	}
+	// Every user maps to the same pseudonym, so their rollups merge
+	pseudonym := func(string) string { return "anon" }
+	if err := storage.PseudonymizeRollups(ctx, day1.AddDate(0, 0, 1), pseudonym); err != nil {
+		t.Fatalf("PseudonymizeRollups() failed: %v", err)
+	}
+
+	rows, err := storage.AggregateRollups(ctx, &evidence.AggregateQuery{GroupBy: []string{evidence.DimensionDay, evidence.DimensionUser}})
+	if err != nil {
+		t.Fatalf("AggregateRollups() failed: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("Expected 2 rows, got %+v", rows)
+	}
+	if rows[0].User != "anon" || rows[0].Requests != 3 || rows[0].Cost != 0.75 {
+		t.Errorf("Expected the first day's 3 requests under the pseudonym, got %+v", *rows[0])
+	}
+	if rows[1].User != "alice" || rows[1].Requests != 1 {
+		t.Errorf("Expected the second day's rollups unchanged, got %+v", *rows[1])
+	}
+}
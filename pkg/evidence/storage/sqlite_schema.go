@@ -1,7 +1,7 @@
 package storage
 
 // SchemaVersion is the current database schema version.
-const SchemaVersion = 1
+const SchemaVersion = 15
 
 // Schema contains the SQL statements to create the evidence database schema.
 const Schema = `
@@ -74,7 +74,79 @@ CREATE TABLE IF NOT EXISTS evidence (
 
     -- Conversation context
     turn_number INTEGER,
-    context_usage REAL
+    context_usage REAL,
+
+    -- Hash chain
+    chain_id TEXT,
+    sequence INTEGER,
+    prev_hash TEXT,
+    record_hash TEXT,
+
+    -- Signature
+    signing_key_id TEXT,
+    signature TEXT,
+
+    -- Full-body capture
+    request_body_ref TEXT,
+    response_body_ref TEXT,
+    body_truncated INTEGER NOT NULL DEFAULT 0,
+
+    -- Team
+    team_id TEXT,
+
+    -- Anonymization
+    anonymized_at TIMESTAMP,
+
+    -- Streaming
+    streamed INTEGER NOT NULL DEFAULT 0,
+    stream_chunks INTEGER NOT NULL DEFAULT 0,
+    time_to_first_token INTEGER NOT NULL DEFAULT 0,
+    stream_duration INTEGER NOT NULL DEFAULT 0,
+    client_disconnected INTEGER NOT NULL DEFAULT 0,
+
+    -- Tool use (JSON arrays)
+    tool_calls TEXT,
+    tool_results TEXT,
+
+    -- Correlation
+    trace_id TEXT,
+    decision_id TEXT,
+
+    -- Anonymization hash
+    anonymized_hash TEXT,
+
+    -- Cost allocation
+    cost_tag TEXT,
+
+    -- Limits bypass
+    limit_bypass TEXT,
+
+    -- Response content analysis (JSON)
+    response_analysis TEXT,
+
+    -- Data classification
+    data_classification TEXT,
+    data_classification_level INTEGER NOT NULL DEFAULT 0,
+
+    -- Analysis findings (JSON)
+    findings TEXT
+);
+
+-- Daily rollups (see RefreshRollups)
+CREATE TABLE IF NOT EXISTS evidence_rollup_daily (
+    day TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    team_id TEXT NOT NULL,
+    provider TEXT NOT NULL,
+    model TEXT NOT NULL,
+    policy_decision TEXT NOT NULL,
+    cost_tag TEXT NOT NULL DEFAULT '',
+    requests INTEGER NOT NULL,
+    prompt_tokens INTEGER NOT NULL,
+    completion_tokens INTEGER NOT NULL,
+    total_tokens INTEGER NOT NULL,
+    cost REAL NOT NULL,
+    PRIMARY KEY (day, user_id, team_id, provider, model, cost_tag, policy_decision)
 );
 
 -- Schema version table
@@ -85,6 +157,7 @@ CREATE TABLE IF NOT EXISTS schema_version (
 
 -- Indexes for common queries
 CREATE INDEX IF NOT EXISTS idx_evidence_request_time ON evidence(request_time);
+CREATE INDEX IF NOT EXISTS idx_evidence_recorded_time ON evidence(recorded_time);
 CREATE INDEX IF NOT EXISTS idx_evidence_user_id ON evidence(user_id);
 CREATE INDEX IF NOT EXISTS idx_evidence_provider ON evidence(provider);
 CREATE INDEX IF NOT EXISTS idx_evidence_model ON evidence(model);
@@ -94,6 +167,166 @@ CREATE INDEX IF NOT EXISTS idx_evidence_total_tokens ON evidence(total_tokens);
 CREATE INDEX IF NOT EXISTS idx_evidence_request_id ON evidence(request_id);
 `
 
+// Migrations contains the SQL statements that upgrade a database from the
+// previous schema version to the version used as the key. Databases created
+// with the current Schema need no migrations.
+var Migrations = map[int]string{
+	2: `
+ALTER TABLE evidence ADD COLUMN chain_id TEXT;
+ALTER TABLE evidence ADD COLUMN sequence INTEGER;
+ALTER TABLE evidence ADD COLUMN prev_hash TEXT;
+ALTER TABLE evidence ADD COLUMN record_hash TEXT;
+`,
+	3: `
+ALTER TABLE evidence ADD COLUMN signing_key_id TEXT;
+ALTER TABLE evidence ADD COLUMN signature TEXT;
+`,
+	4: `
+ALTER TABLE evidence ADD COLUMN request_body_ref TEXT;
+ALTER TABLE evidence ADD COLUMN response_body_ref TEXT;
+ALTER TABLE evidence ADD COLUMN body_truncated INTEGER NOT NULL DEFAULT 0;
+`,
+	5: `
+ALTER TABLE evidence ADD COLUMN team_id TEXT;
+CREATE TABLE IF NOT EXISTS evidence_rollup_daily (
+    day TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    team_id TEXT NOT NULL,
+    provider TEXT NOT NULL,
+    model TEXT NOT NULL,
+    policy_decision TEXT NOT NULL,
+    requests INTEGER NOT NULL,
+    prompt_tokens INTEGER NOT NULL,
+    completion_tokens INTEGER NOT NULL,
+    total_tokens INTEGER NOT NULL,
+    cost REAL NOT NULL,
+    PRIMARY KEY (day, user_id, team_id, provider, model, policy_decision)
+);
+`,
+	6: `
+ALTER TABLE evidence ADD COLUMN anonymized_at TIMESTAMP;
+`,
+	7: `
+ALTER TABLE evidence ADD COLUMN streamed INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE evidence ADD COLUMN stream_chunks INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE evidence ADD COLUMN time_to_first_token INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE evidence ADD COLUMN stream_duration INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE evidence ADD COLUMN client_disconnected INTEGER NOT NULL DEFAULT 0;
+`,
+	8: `
+ALTER TABLE evidence ADD COLUMN tool_calls TEXT;
+ALTER TABLE evidence ADD COLUMN tool_results TEXT;
+`,
+	9: `
+ALTER TABLE evidence ADD COLUMN trace_id TEXT;
+ALTER TABLE evidence ADD COLUMN decision_id TEXT;
+`,
+	10: `
+ALTER TABLE evidence ADD COLUMN anonymized_hash TEXT;
+`,
+	// The rollup primary key gains cost_tag, so the table is rebuilt;
+	// existing rollups are kept as untagged.
+	11: `
+ALTER TABLE evidence ADD COLUMN cost_tag TEXT;
+CREATE TABLE evidence_rollup_daily_new (
+    day TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    team_id TEXT NOT NULL,
+    provider TEXT NOT NULL,
+    model TEXT NOT NULL,
+    policy_decision TEXT NOT NULL,
+    cost_tag TEXT NOT NULL DEFAULT '',
+    requests INTEGER NOT NULL,
+    prompt_tokens INTEGER NOT NULL,
+    completion_tokens INTEGER NOT NULL,
+    total_tokens INTEGER NOT NULL,
+    cost REAL NOT NULL,
+    PRIMARY KEY (day, user_id, team_id, provider, model, cost_tag, policy_decision)
+);
+INSERT INTO evidence_rollup_daily_new (
+    day, user_id, team_id, provider, model, policy_decision,
+    requests, prompt_tokens, completion_tokens, total_tokens, cost
+)
+SELECT day, user_id, team_id, provider, model, policy_decision,
+    requests, prompt_tokens, completion_tokens, total_tokens, cost
+FROM evidence_rollup_daily;
+DROP TABLE evidence_rollup_daily;
+ALTER TABLE evidence_rollup_daily_new RENAME TO evidence_rollup_daily;
+`,
+	12: `
+ALTER TABLE evidence ADD COLUMN limit_bypass TEXT;
+`,
+	13: `
+ALTER TABLE evidence ADD COLUMN response_analysis TEXT;
+`,
+	14: `
+ALTER TABLE evidence ADD COLUMN data_classification TEXT;
+ALTER TABLE evidence ADD COLUMN data_classification_level INTEGER NOT NULL DEFAULT 0;
+`,
+	15: `
+ALTER TABLE evidence ADD COLUMN findings TEXT;
+`,
+}
+
+// MigratedIndexes contains the indexes on columns added by migrations. They
+// are created after migrating, since the columns do not exist before.
+const MigratedIndexes = `
+CREATE INDEX IF NOT EXISTS idx_evidence_chain ON evidence(chain_id, sequence);
+CREATE INDEX IF NOT EXISTS idx_evidence_team_id ON evidence(team_id);
+CREATE INDEX IF NOT EXISTS idx_evidence_trace_id ON evidence(trace_id);
+CREATE INDEX IF NOT EXISTS idx_evidence_decision_id ON evidence(decision_id);
+CREATE INDEX IF NOT EXISTS idx_evidence_cost_tag ON evidence(cost_tag);
+CREATE INDEX IF NOT EXISTS idx_evidence_limit_bypass ON evidence(limit_bypass);
+CREATE INDEX IF NOT EXISTS idx_evidence_data_classification ON evidence(data_classification);
+`
+
+// SearchTable returns the statement creating the full-text index of the
+// prompts and response excerpts with the given FTS module ("fts5" or
+// "fts4"). Its rowids are those of the evidence table. The index is created
+// outside of the versioned schema because the available module depends on
+// how SQLite was built.
+func SearchTable(module string) string {
+	return `CREATE VIRTUAL TABLE evidence_fts USING ` + module + `(
+    system_prompt, user_prompt, response_content, tokenize=unicode61
+);`
+}
+
+// SearchIndex contains the triggers keeping the full-text index in sync
+// with the evidence table, and indexes the existing records.
+const SearchIndex = `
+CREATE TRIGGER IF NOT EXISTS evidence_fts_insert AFTER INSERT ON evidence BEGIN
+    INSERT INTO evidence_fts (rowid, system_prompt, user_prompt, response_content)
+    VALUES (new.rowid, new.system_prompt, new.user_prompt, new.response_content);
+END;
+
+CREATE TRIGGER IF NOT EXISTS evidence_fts_delete AFTER DELETE ON evidence BEGIN
+    DELETE FROM evidence_fts WHERE rowid = old.rowid;
+END;
+
+CREATE TRIGGER IF NOT EXISTS evidence_fts_update
+AFTER UPDATE OF system_prompt, user_prompt, response_content ON evidence BEGIN
+    DELETE FROM evidence_fts WHERE rowid = old.rowid;
+    INSERT INTO evidence_fts (rowid, system_prompt, user_prompt, response_content)
+    VALUES (new.rowid, new.system_prompt, new.user_prompt, new.response_content);
+END;
+
+INSERT INTO evidence_fts (rowid, system_prompt, user_prompt, response_content)
+SELECT rowid, system_prompt, user_prompt, response_content FROM evidence;
+`
+
+// WriteOnceTriggers contains the triggers rejecting updates and deletes of
+// evidence records in write-once (WORM) mode. They are never dropped: once
+// a database is write-once, it stays write-once.
+const WriteOnceTriggers = `
+CREATE TRIGGER IF NOT EXISTS evidence_worm_update BEFORE UPDATE ON evidence BEGIN
+    SELECT RAISE(ABORT, 'evidence is write-once');
+END;
+
+CREATE TRIGGER IF NOT EXISTS evidence_worm_delete BEFORE DELETE ON evidence BEGIN
+    SELECT RAISE(ABORT, 'evidence is write-once');
+END;
+`
+
 // InsertSchemaVersion inserts the schema version into the schema_version table.
 const InsertSchemaVersion = `
 INSERT INTO schema_version (version, applied_at)
@@ -2,8 +2,10 @@ package storage
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 
@@ -735,6 +737,112 @@ func TestSQLiteStorage_ConcurrentWrites(t *testing.T) {
 	}
 }
 
+// TestSQLiteStorage_StoreBatch tests that batches are stored atomically.
+func TestSQLiteStorage_StoreBatch(t *testing.T) {
+	storage, _ := createTempDB(t)
+	defer storage.Close()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	newRecord := func(id string) *evidence.EvidenceRecord {
+		return &evidence.EvidenceRecord{ID: id, RequestID: "req-" + id, RequestTime: now, Model: "gpt-4", Provider: "openai"}
+	}
+
+	if err := storage.StoreBatch(ctx, []*evidence.EvidenceRecord{newRecord("a"), newRecord("b"), newRecord("c")}); err != nil {
+		t.Fatalf("StoreBatch() failed: %v", err)
+	}
+	if count, _ := storage.Count(ctx, &evidence.Query{}); count != 3 {
+		t.Errorf("Expected 3 records, got %d", count)
+	}
+
+	// A duplicate ID fails the whole batch
+	if err := storage.StoreBatch(ctx, []*evidence.EvidenceRecord{newRecord("d"), newRecord("a")}); err == nil {
+		t.Fatal("Expected error for duplicate ID")
+	}
+	if count, _ := storage.Count(ctx, &evidence.Query{}); count != 3 {
+		t.Errorf("Expected the failed batch to be rolled back, got %d records", count)
+	}
+}
+
+// TestSQLiteStorage_Update tests replacing stored records.
+func TestSQLiteStorage_Update(t *testing.T) {
+	storage, _ := createTempDB(t)
+	defer storage.Close()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	record := &evidence.EvidenceRecord{ID: "a", RequestID: "req-a", RequestTime: now, Model: "gpt-4", Provider: "openai",
+		UserID: "alice", UserPrompt: "my email is alice@example.com"}
+	if err := storage.Store(ctx, record); err != nil {
+		t.Fatalf("Store() failed: %v", err)
+	}
+
+	updated := *record
+	updated.UserID = "anon-1"
+	updated.UserPrompt = "my email is [REDACTED:email]"
+	updated.AnonymizedAt = &now
+	missing := &evidence.EvidenceRecord{ID: "missing", RequestTime: now}
+	if err := storage.Update(ctx, []*evidence.EvidenceRecord{&updated, missing}); err != nil {
+		t.Fatalf("Update() failed: %v", err)
+	}
+
+	anonymized := true
+	results, err := storage.Query(ctx, &evidence.Query{Anonymized: &anonymized})
+	if err != nil {
+		t.Fatalf("Query() failed: %v", err)
+	}
+	if len(results) != 1 || results[0].UserID != "anon-1" || results[0].AnonymizedAt == nil {
+		t.Fatalf("Expected the updated record, got %+v", results)
+	}
+
+	// The search index follows the update; missing records are not stored
+	if count, _ := storage.Count(ctx, &evidence.Query{Search: "alice"}); count != 0 {
+		t.Errorf("Expected the old prompt to be unindexed, got %d matches", count)
+	}
+	if count, _ := storage.Count(ctx, &evidence.Query{}); count != 1 {
+		t.Errorf("Expected 1 record, got %d", count)
+	}
+}
+
+// TestSQLiteStorage_ToolCalls tests storing tool calls and results and
+// filtering by called tool.
+func TestSQLiteStorage_ToolCalls(t *testing.T) {
+	storage, _ := createTempDB(t)
+	defer storage.Close()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	records := []*evidence.EvidenceRecord{
+		{ID: "a", RequestID: "req-a", RequestTime: now,
+			ToolCalls:   []evidence.ToolCallRecord{{ID: "call-1", Name: "send_email", ArgumentsHash: "abc", Arguments: `{"to":"bob"}`}},
+			ToolResults: []evidence.ToolResultRecord{{ToolCallID: "call-0", Name: "lookup", ContentHash: "def"}}},
+		{ID: "b", RequestID: "req-b", RequestTime: now,
+			ToolCalls: []evidence.ToolCallRecord{{ID: "call-2", Name: "send_email_draft", ArgumentsHash: "ghi"}}},
+		{ID: "c", RequestID: "req-c", RequestTime: now},
+	}
+	for _, record := range records {
+		if err := storage.Store(ctx, record); err != nil {
+			t.Fatalf("Store() failed: %v", err)
+		}
+	}
+
+	results, err := storage.Query(ctx, &evidence.Query{ToolName: "send_email"})
+	if err != nil {
+		t.Fatalf("Query() failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ID != "a" {
+		t.Fatalf("Expected record a, got %+v", results)
+	}
+	if !reflect.DeepEqual(results[0].ToolCalls, records[0].ToolCalls) || !reflect.DeepEqual(results[0].ToolResults, records[0].ToolResults) {
+		t.Errorf("tool use = %+v / %+v, want %+v / %+v", results[0].ToolCalls, results[0].ToolResults, records[0].ToolCalls, records[0].ToolResults)
+	}
+
+	results, _ = storage.Query(ctx, &evidence.Query{IDs: []string{"c"}})
+	if len(results) != 1 || results[0].ToolCalls != nil || results[0].ToolResults != nil {
+		t.Errorf("Expected no tool use for record c, got %+v", results)
+	}
+}
+
 // TestSQLiteStorage_Close tests closing the storage.
 func TestSQLiteStorage_Close(t *testing.T) {
 	storage, _ := createTempDB(t)
@@ -794,6 +902,45 @@ func BenchmarkSQLiteStorage_Store(b *testing.B) {
 	}
 }
 
+// BenchmarkSQLiteStorage_StoreBatch benchmarks storing records in batches
+// of 100.
+func BenchmarkSQLiteStorage_StoreBatch(b *testing.B) {
+	config := &SQLiteConfig{
+		Path:         filepath.Join(b.TempDir(), "bench.db"),
+		MaxOpenConns: 10,
+		MaxIdleConns: 5,
+		WALMode:      true,
+		BusyTimeout:  5 * time.Second,
+	}
+
+	storage, err := NewSQLiteStorage(config)
+	if err != nil {
+		b.Fatalf("Failed to create storage: %v", err)
+	}
+	defer storage.Close()
+
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Millisecond)
+
+	batch := make([]*evidence.EvidenceRecord, 0, 100)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		batch = append(batch, &evidence.EvidenceRecord{
+			ID:          fmt.Sprintf("record-%d", i),
+			RequestID:   fmt.Sprintf("req-%d", i),
+			RequestTime: now,
+			Model:       "gpt-4",
+			Provider:    "openai",
+		})
+		if len(batch) == cap(batch) || i == b.N-1 {
+			if err := storage.StoreBatch(ctx, batch); err != nil {
+				b.Fatalf("StoreBatch() failed: %v", err)
+			}
+			batch = batch[:0]
+		}
+	}
+}
+
 // BenchmarkSQLiteStorage_Query benchmarks querying records.
 func BenchmarkSQLiteStorage_Query(b *testing.B) {
 	tmpDir := b.TempDir()
@@ -0,0 +1,140 @@
+package stream
+
+import (
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+// avroKeySchema is the Avro schema for record keys (the request ID).
+const avroKeySchema = `"string"`
+
+// EvidenceAvroSchema is the Avro schema evidence records are produced with.
+//
+// Every field is required so that values can be sent in plain JSON
+// encoding: empty strings and zero values stand in for missing data.
+// Timestamps are milliseconds since the Unix epoch and durations are
+// milliseconds.
+const EvidenceAvroSchema = `{
+  "type": "record",
+  "name": "EvidenceRecord",
+  "namespace": "io.mercator.evidence",
+  "fields": [
+    {"name": "id", "type": "string"},
+    {"name": "request_id", "type": "string"},
+    {"name": "request_time", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+    {"name": "response_time", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+    {"name": "recorded_time", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+    {"name": "request_hash", "type": "string"},
+    {"name": "request_method", "type": "string"},
+    {"name": "request_path", "type": "string"},
+    {"name": "model", "type": "string"},
+    {"name": "provider", "type": "string"},
+    {"name": "provider_model", "type": "string"},
+    {"name": "messages", "type": "int"},
+    {"name": "tools_used", "type": {"type": "array", "items": "string"}},
+    {"name": "estimated_tokens", "type": "int"},
+    {"name": "estimated_cost", "type": "double"},
+    {"name": "risk_score", "type": "int"},
+    {"name": "complexity_score", "type": "int"},
+    {"name": "pii_detected", "type": "boolean"},
+    {"name": "pii_types", "type": {"type": "array", "items": "string"}},
+    {"name": "policy_decision", "type": "string"},
+    {"name": "matched_rules", "type": {"type": "array", "items": {
+      "type": "record",
+      "name": "MatchedRule",
+      "fields": [
+        {"name": "policy_id", "type": "string"},
+        {"name": "rule_id", "type": "string"},
+        {"name": "action", "type": "string"},
+        {"name": "reason", "type": "string"}
+      ]
+    }}},
+    {"name": "block_reason", "type": "string"},
+    {"name": "policy_version", "type": "string"},
+    {"name": "response_hash", "type": "string"},
+    {"name": "response_status", "type": "int"},
+    {"name": "finish_reason", "type": "string"},
+    {"name": "prompt_tokens", "type": "int"},
+    {"name": "completion_tokens", "type": "int"},
+    {"name": "total_tokens", "type": "int"},
+    {"name": "actual_cost", "type": "double"},
+    {"name": "provider_latency_ms", "type": "long"},
+    {"name": "user_id", "type": "string"},
+    {"name": "api_key", "type": "string"},
+    {"name": "ip_address", "type": "string"},
+    {"name": "error", "type": "string"},
+    {"name": "error_type", "type": "string"}
+  ]
+}`
+
+// avroValue converts a record to the JSON form of EvidenceAvroSchema.
+// Prompt and response content is not included.
+func avroValue(r *evidence.EvidenceRecord) map[string]any {
+	rules := make([]map[string]any, len(r.MatchedRules))
+	for i, rule := range r.MatchedRules {
+		rules[i] = map[string]any{
+			"policy_id": rule.PolicyID,
+			"rule_id":   rule.RuleID,
+			"action":    rule.Action,
+			"reason":    rule.Reason,
+		}
+	}
+
+	return map[string]any{
+		"id":                  r.ID,
+		"request_id":          r.RequestID,
+		"request_time":        epochMillis(r.RequestTime),
+		"response_time":       epochMillis(r.ResponseTime),
+		"recorded_time":       epochMillis(r.RecordedTime),
+		"request_hash":        r.RequestHash,
+		"request_method":      r.RequestMethod,
+		"request_path":        r.RequestPath,
+		"model":               r.Model,
+		"provider":            r.Provider,
+		"provider_model":      r.ProviderModel,
+		"messages":            r.Messages,
+		"tools_used":          nonNil(r.ToolsUsed),
+		"estimated_tokens":    r.EstimatedTokens,
+		"estimated_cost":      r.EstimatedCost,
+		"risk_score":          r.RiskScore,
+		"complexity_score":    r.ComplexityScore,
+		"pii_detected":        r.PIIDetected,
+		"pii_types":           nonNil(r.PIITypes),
+		"policy_decision":     r.PolicyDecision,
+		"matched_rules":       rules,
+		"block_reason":        r.BlockReason,
+		"policy_version":      r.PolicyVersion,
+		"response_hash":       r.ResponseHash,
+		"response_status":     r.ResponseStatus,
+		"finish_reason":       r.FinishReason,
+		"prompt_tokens":       r.PromptTokens,
+		"completion_tokens":   r.CompletionTokens,
+		"total_tokens":        r.TotalTokens,
+		"actual_cost":         r.ActualCost,
+		"provider_latency_ms": r.ProviderLatency.Milliseconds(),
+		"user_id":             r.UserID,
+		"api_key":             r.APIKey,
+		"ip_address":          r.IPAddress,
+		"error":               r.Error,
+		"error_type":          r.ErrorType,
+	}
+}
+
+// epochMillis returns t as milliseconds since the Unix epoch, or 0 for the
+// zero time.
+func epochMillis(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixMilli()
+}
+
+// nonNil returns s, or an empty slice if s is nil, so arrays are never
+// encoded as null.
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
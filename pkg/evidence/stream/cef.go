@@ -0,0 +1,186 @@
+package stream
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+// Event formats for syslog export.
+const (
+	// FormatCEF is ArcSight Common Event Format.
+	FormatCEF = "cef"
+
+	// FormatLEEF is IBM QRadar Log Event Extended Format (version 2.0).
+	FormatLEEF = "leef"
+)
+
+// Device identification used in CEF and LEEF headers.
+const (
+	deviceVendor  = "Mercator"
+	deviceProduct = "Jupiter"
+)
+
+// leefDelimiter separates LEEF 2.0 attributes.
+const leefDelimiter = '^'
+
+// eventSummary is the vendor-neutral summary of an evidence record that CEF
+// and LEEF events are built from.
+type eventSummary struct {
+	id       string // event class, e.g. "llm-request-block"
+	name     string
+	severity int // 0-10
+	time     time.Time
+	attrs    []eventAttr
+}
+
+// eventAttr is a single key/value pair of an event, with both its CEF and
+// LEEF key.
+type eventAttr struct {
+	cef   string
+	leef  string
+	value string
+}
+
+// summarizeRecord builds the event summary for an evidence record. Prompt
+// and response content are never included.
+func summarizeRecord(r *evidence.EvidenceRecord) eventSummary {
+	decision := r.PolicyDecision
+	if decision == "" {
+		decision = "allow"
+	}
+
+	s := eventSummary{
+		id:       "llm-request-" + decision,
+		name:     "LLM request " + decision,
+		severity: 3,
+		time:     r.RequestTime,
+	}
+	switch {
+	case r.Error != "":
+		s.id, s.name, s.severity = "llm-request-error", "LLM request failed", 5
+	case decision == "block":
+		s.name, s.severity = "LLM request blocked", 7
+	case decision == "transform" || decision == "redact":
+		s.severity = 4
+	}
+
+	rules := make([]string, 0, len(r.MatchedRules))
+	for _, rule := range r.MatchedRules {
+		rules = append(rules, rule.PolicyID+"/"+rule.RuleID)
+	}
+
+	add := func(cef, leef, value string) {
+		if value != "" {
+			s.attrs = append(s.attrs, eventAttr{cef: cef, leef: leef, value: value})
+		}
+	}
+	add("externalId", "requestId", r.RequestID)
+	add("cs1", "evidenceId", r.ID)
+	add("suser", "usrName", r.UserID)
+	add("src", "src", r.IPAddress)
+	add("requestMethod", "requestMethod", r.RequestMethod)
+	add("request", "url", r.RequestPath)
+	add("cs2", "model", r.Model)
+	add("cs3", "provider", r.Provider)
+	add("act", "action", decision)
+	add("reason", "reason", r.BlockReason)
+	add("cs4", "matchedRules", strings.Join(rules, ","))
+	add("cs5", "piiTypes", strings.Join(r.PIITypes, ","))
+	add("cn1", "totalTokens", strconv.Itoa(r.TotalTokens))
+	add("cfp1", "cost", strconv.FormatFloat(r.ActualCost, 'f', 6, 64))
+	add("cn2", "responseStatus", nonZero(r.ResponseStatus))
+	add("cs6", "error", r.Error)
+
+	return s
+}
+
+// cefLabels names the CEF custom fields used by summarizeRecord.
+var cefLabels = map[string]string{
+	"cs1":  "evidenceId",
+	"cs2":  "model",
+	"cs3":  "provider",
+	"cs4":  "matchedRules",
+	"cs5":  "piiTypes",
+	"cs6":  "error",
+	"cn1":  "totalTokens",
+	"cn2":  "responseStatus",
+	"cfp1": "cost",
+}
+
+// formatCEF renders an event summary as a CEF:0 message.
+func formatCEF(s eventSummary, version string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "CEF:0|%s|%s|%s|%s|%s|%d|",
+		cefHeaderEscape(deviceVendor),
+		cefHeaderEscape(deviceProduct),
+		cefHeaderEscape(version),
+		cefHeaderEscape(s.id),
+		cefHeaderEscape(s.name),
+		s.severity,
+	)
+
+	if !s.time.IsZero() {
+		fmt.Fprintf(&b, "rt=%d ", s.time.UnixMilli())
+	}
+	for i, attr := range s.attrs {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		if label, ok := cefLabels[attr.cef]; ok {
+			fmt.Fprintf(&b, "%sLabel=%s ", attr.cef, label)
+		}
+		b.WriteString(attr.cef)
+		b.WriteByte('=')
+		b.WriteString(cefExtensionEscape(attr.value))
+	}
+	return b.String()
+}
+
+// formatLEEF renders an event summary as a LEEF:2.0 message.
+func formatLEEF(s eventSummary, version string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "LEEF:2.0|%s|%s|%s|%s|%c|",
+		leefHeaderEscape(deviceVendor),
+		leefHeaderEscape(deviceProduct),
+		leefHeaderEscape(version),
+		leefHeaderEscape(s.id),
+		leefDelimiter,
+	)
+
+	fmt.Fprintf(&b, "sev=%d", s.severity)
+	if !s.time.IsZero() {
+		// QRadar's default devTime format is "MMM dd yyyy HH:mm:ss.SSS zzz"
+		fmt.Fprintf(&b, "%cdevTime=%s", leefDelimiter, s.time.UTC().Format("Jan 02 2006 15:04:05.000 MST"))
+	}
+	for _, attr := range s.attrs {
+		b.WriteByte(leefDelimiter)
+		b.WriteString(attr.leef)
+		b.WriteByte('=')
+		b.WriteString(leefValueEscape(attr.value))
+	}
+	return b.String()
+}
+
+var (
+	cefHeaderReplacer    = strings.NewReplacer(`\`, `\\`, `|`, `\|`, "\r", " ", "\n", " ")
+	cefExtensionReplacer = strings.NewReplacer(`\`, `\\`, `=`, `\=`, "\r", `\r`, "\n", `\n`)
+	leefHeaderReplacer   = strings.NewReplacer(`|`, " ", "\r", " ", "\n", " ")
+	leefValueReplacer    = strings.NewReplacer(string(leefDelimiter), " ", "\r", " ", "\n", " ", "\t", " ")
+)
+
+func cefHeaderEscape(s string) string    { return cefHeaderReplacer.Replace(s) }
+func cefExtensionEscape(s string) string { return cefExtensionReplacer.Replace(s) }
+func leefHeaderEscape(s string) string   { return leefHeaderReplacer.Replace(s) }
+func leefValueEscape(s string) string    { return leefValueReplacer.Replace(s) }
+
+// nonZero formats n, or returns "" for zero so the attribute is omitted.
+func nonZero(n int) string {
+	if n == 0 {
+		return ""
+	}
+	return strconv.Itoa(n)
+}
@@ -0,0 +1,43 @@
+// Package stream publishes evidence records to external systems in real
+// time, alongside evidence storage.
+//
+// The Publisher implements recorder.RecordObserver. Every record written by
+// the evidence recorder is queued for each configured Exporter and delivered
+// in batches, either when a batch is full or when the flush interval
+// elapses. Delivery is asynchronous: a slow or unavailable exporter never
+// blocks evidence recording, and records that do not fit in an exporter's
+// queue are dropped and counted.
+//
+// # Exporters
+//
+//   - KafkaExporter produces records to a topic through a Kafka REST Proxy,
+//     serialized as JSON or as Avro (EvidenceAvroSchema) via the proxy's
+//     Schema Registry integration.
+//   - ElasticsearchExporter bulk-indexes records into Elasticsearch or
+//     OpenSearch, managing an index template and using daily or
+//     ILM rollover index naming.
+//   - SyslogExporter sends summary events (no prompt or response content)
+//     in CEF or LEEF format to a syslog collector over UDP, TCP, or TLS.
+//   - WebhookExporter POSTs a notification for each record matching its
+//     criteria (e.g., blocked requests, costly requests, or requests
+//     containing PII), with an optional text/template payload and an
+//     HMAC-SHA256 signature, for real-time security response.
+//
+// Custom exporters implement the Exporter interface.
+//
+// # Usage
+//
+//	kafka, err := stream.NewKafkaExporter(&stream.KafkaConfig{
+//	    URL:           "http://kafka-rest:8082",
+//	    Topic:         "mercator.evidence",
+//	    Serialization: stream.SerializationAvro,
+//	})
+//	if err != nil {
+//	    return err
+//	}
+//
+//	publisher := stream.NewPublisher(stream.DefaultConfig(), kafka)
+//	defer publisher.Close()
+//
+//	evidenceRecorder.AddObserver(publisher)
+package stream
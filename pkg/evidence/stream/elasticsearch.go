@@ -0,0 +1,440 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+// Elasticsearch index naming modes.
+const (
+	// IndexModeDaily writes to one index per day, named
+	// "<prefix>-YYYY.MM.DD" from the record's request time.
+	IndexModeDaily = "daily"
+
+	// IndexModeRollover writes to the "<prefix>" alias, backed by indices
+	// named "<prefix>-000001", "<prefix>-000002", ... that an ILM policy
+	// rolls over.
+	IndexModeRollover = "rollover"
+)
+
+// DefaultIndexPrefix is the default index name prefix.
+const DefaultIndexPrefix = "mercator-evidence"
+
+// ElasticsearchConfig configures an ElasticsearchExporter.
+type ElasticsearchConfig struct {
+	// URL is the cluster URL (e.g., "https://es.example.com:9200").
+	URL string
+
+	// IndexPrefix is the prefix of evidence index names. It is also the
+	// index template name and, in rollover mode, the write alias.
+	// Default: "mercator-evidence"
+	IndexPrefix string
+
+	// IndexMode is the index naming mode: "daily" or "rollover".
+	// Default: "daily"
+	IndexMode string
+
+	// ILMPolicy is the name of an existing ILM policy applied to evidence
+	// indices through the index template. Required for rollover mode to
+	// actually roll over.
+	ILMPolicy string
+
+	// Username and Password enable basic authentication.
+	Username string
+	Password string
+
+	// APIKey enables API key authentication (the base64-encoded "id:key").
+	APIKey string
+
+	// Client is the HTTP client used for delivery.
+	// Default: http.DefaultClient
+	Client *http.Client
+}
+
+// ElasticsearchExporter bulk-indexes evidence records into Elasticsearch or
+// OpenSearch.
+//
+// Before the first batch it installs a composable index template for
+// "<prefix>-*" with mappings for evidence fields and, in rollover mode,
+// bootstraps the first backing index behind the write alias. Documents are
+// indexed with the record ID as document ID, so retried batches do not
+// create duplicates, and carry an "@timestamp" field set to the request time.
+type ElasticsearchExporter struct {
+	config *ElasticsearchConfig
+	client *http.Client
+	url    string
+
+	// setupMu serializes index setup; ready is set once it succeeds.
+	setupMu sync.Mutex
+	ready   bool
+}
+
+// NewElasticsearchExporter creates an Elasticsearch exporter.
+func NewElasticsearchExporter(config *ElasticsearchConfig) (*ElasticsearchExporter, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("elasticsearch url is required")
+	}
+	if config.IndexPrefix == "" {
+		config.IndexPrefix = DefaultIndexPrefix
+	}
+	switch config.IndexMode {
+	case "":
+		config.IndexMode = IndexModeDaily
+	case IndexModeDaily, IndexModeRollover:
+	default:
+		return nil, fmt.Errorf("unsupported index mode %q", config.IndexMode)
+	}
+
+	client := config.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &ElasticsearchExporter{
+		config: config,
+		client: client,
+		url:    strings.TrimSuffix(config.URL, "/"),
+	}, nil
+}
+
+// Name returns the exporter name.
+func (e *ElasticsearchExporter) Name() string {
+	return "elasticsearch:" + e.config.IndexPrefix
+}
+
+// indexName returns the index (or alias) a record is written to.
+func (e *ElasticsearchExporter) indexName(record *evidence.EvidenceRecord) string {
+	if e.config.IndexMode == IndexModeRollover {
+		return e.config.IndexPrefix
+	}
+	t := record.RequestTime
+	if t.IsZero() {
+		t = record.RecordedTime
+	}
+	return e.config.IndexPrefix + "-" + t.UTC().Format("2006.01.02")
+}
+
+// esDocument is an evidence record as indexed, with a Kibana-friendly
+// timestamp field.
+type esDocument struct {
+	Timestamp time.Time `json:"@timestamp"`
+	*evidence.EvidenceRecord
+}
+
+// bulkResponse is the subset of the _bulk response used for error reporting.
+type bulkResponse struct {
+	Errors bool `json:"errors"`
+	Items  []map[string]struct {
+		Status int `json:"status"`
+		Error  *struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"error"`
+	} `json:"items"`
+}
+
+// Export indexes a batch of records with a single _bulk request.
+func (e *ElasticsearchExporter) Export(ctx context.Context, records []*evidence.EvidenceRecord) error {
+	if err := e.setup(ctx); err != nil {
+		return err
+	}
+
+	var body bytes.Buffer
+	enc := json.NewEncoder(&body)
+	for _, record := range records {
+		action := map[string]map[string]string{
+			"index": {"_index": e.indexName(record), "_id": record.ID},
+		}
+		if err := enc.Encode(action); err != nil {
+			return fmt.Errorf("failed to encode bulk action: %w", err)
+		}
+		if err := enc.Encode(esDocument{Timestamp: record.RequestTime, EvidenceRecord: record}); err != nil {
+			return fmt.Errorf("failed to encode record %s: %w", record.ID, err)
+		}
+	}
+
+	data, err := e.do(ctx, http.MethodPost, "/_bulk", "application/x-ndjson", body.Bytes())
+	if err != nil {
+		return err
+	}
+
+	var result bulkResponse
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("failed to parse bulk response: %w", err)
+	}
+	if !result.Errors {
+		return nil
+	}
+
+	failed := 0
+	var firstErr string
+	for _, item := range result.Items {
+		for _, status := range item {
+			if status.Error == nil {
+				continue
+			}
+			if failed == 0 {
+				firstErr = status.Error.Type + ": " + status.Error.Reason
+			}
+			failed++
+		}
+	}
+	return fmt.Errorf("bulk indexing failed for %d of %d records (%s)", failed, len(records), firstErr)
+}
+
+// Close is a no-op for Elasticsearch exporters.
+func (e *ElasticsearchExporter) Close() error {
+	return nil
+}
+
+// setup installs the index template and bootstraps the rollover index once.
+// A failed setup is retried on the next batch.
+func (e *ElasticsearchExporter) setup(ctx context.Context) error {
+	e.setupMu.Lock()
+	defer e.setupMu.Unlock()
+
+	if e.ready {
+		return nil
+	}
+
+	template, err := json.Marshal(e.indexTemplate())
+	if err != nil {
+		return fmt.Errorf("failed to encode index template: %w", err)
+	}
+	if _, err := e.do(ctx, http.MethodPut, "/_index_template/"+e.config.IndexPrefix, "application/json", template); err != nil {
+		return fmt.Errorf("failed to install index template: %w", err)
+	}
+
+	if e.config.IndexMode == IndexModeRollover {
+		if err := e.bootstrapRollover(ctx); err != nil {
+			return err
+		}
+	}
+
+	e.ready = true
+	return nil
+}
+
+// bootstrapRollover creates the first backing index behind the write alias
+// unless the alias already exists.
+func (e *ElasticsearchExporter) bootstrapRollover(ctx context.Context) error {
+	alias := e.config.IndexPrefix
+
+	status, err := e.head(ctx, "/_alias/"+alias)
+	if err != nil {
+		return fmt.Errorf("failed to check write alias: %w", err)
+	}
+	if status == http.StatusOK {
+		return nil
+	}
+
+	index, err := json.Marshal(map[string]any{
+		"aliases": map[string]any{alias: map[string]any{"is_write_index": true}},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := e.do(ctx, http.MethodPut, "/"+alias+"-000001", "application/json", index); err != nil {
+		return fmt.Errorf("failed to bootstrap rollover index: %w", err)
+	}
+	return nil
+}
+
+// indexTemplate returns the composable index template for evidence indices.
+func (e *ElasticsearchExporter) indexTemplate() map[string]any {
+	settings := map[string]any{}
+	if e.config.ILMPolicy != "" {
+		settings["index.lifecycle.name"] = e.config.ILMPolicy
+		if e.config.IndexMode == IndexModeRollover {
+			settings["index.lifecycle.rollover_alias"] = e.config.IndexPrefix
+		}
+	}
+
+	keyword := map[string]any{"type": "keyword"}
+	text := map[string]any{"type": "text"}
+	date := map[string]any{"type": "date"}
+	integer := map[string]any{"type": "integer"}
+	long := map[string]any{"type": "long"}
+	double := map[string]any{"type": "double"}
+	boolean := map[string]any{"type": "boolean"}
+
+	properties := map[string]any{
+		"@timestamp":         date,
+		"id":                 keyword,
+		"request_id":         keyword,
+		"request_time":       date,
+		"policy_eval_time":   date,
+		"provider_call_time": date,
+		"response_time":      date,
+		"recorded_time":      date,
+		"request_hash":       keyword,
+		"request_method":     keyword,
+		"request_path":       keyword,
+		"request_headers":    map[string]any{"type": "object", "enabled": false},
+		"model":              keyword,
+		"provider":           keyword,
+		"messages":           integer,
+		"system_prompt":      text,
+		"user_prompt":        text,
+		"tools_used":         keyword,
+		"estimated_tokens":   integer,
+		"estimated_cost":     double,
+		"risk_score":         integer,
+		"complexity_score":   integer,
+		"pii_detected":       boolean,
+		"pii_types":          keyword,
+		"policy_decision":    keyword,
+		"matched_rules": map[string]any{
+			"properties": map[string]any{
+				"policy_id":       keyword,
+				"rule_id":         keyword,
+				"action":          keyword,
+				"reason":          text,
+				"evaluation_time": long,
+			},
+		},
+		"block_reason":        text,
+		"policy_version":      keyword,
+		"policy_version_info": map[string]any{"type": "object", "enabled": false},
+		"response_hash":       keyword,
+		"response_status":     integer,
+		"response_content":    text,
+		"finish_reason":       keyword,
+		"prompt_tokens":       integer,
+		"completion_tokens":   integer,
+		"total_tokens":        integer,
+		"actual_cost":         double,
+		"provider_latency":    long,
+		"provider_model":      keyword,
+		"user_id":             keyword,
+		"api_key":             keyword,
+		"ip_address":          keyword,
+		"error":               text,
+		"error_type":          keyword,
+		"turn_number":         integer,
+		"context_usage":       double,
+		"chain_id":            keyword,
+		"sequence":            long,
+		"prev_hash":           keyword,
+		"record_hash":         keyword,
+		"signing_key_id":      keyword,
+		"signature":           map[string]any{"type": "keyword", "index": false},
+		"request_body_ref":    keyword,
+		"response_body_ref":   keyword,
+		"body_truncated":      boolean,
+		"team_id":             keyword,
+		"streamed":            boolean,
+		"stream_chunks":       integer,
+		"time_to_first_token": long,
+		"stream_duration":     long,
+		"client_disconnected": boolean,
+		"trace_id":            keyword,
+		"decision_id":         keyword,
+		"cost_tag":            keyword,
+		"limit_bypass":        keyword,
+		"response_analysis": map[string]any{
+			"properties": map[string]any{
+				"pii_types":            keyword,
+				"secret_types":         keyword,
+				"sensitive_categories": keyword,
+			},
+		},
+		"data_classification":       keyword,
+		"data_classification_level": integer,
+		"findings": map[string]any{
+			"properties": map[string]any{
+				"stage":      keyword,
+				"detector":   keyword,
+				"type":       keyword,
+				"source":     keyword,
+				"rule":       keyword,
+				"confidence": double,
+				"start":      integer,
+				"end":        integer,
+			},
+		},
+		"tool_calls": map[string]any{
+			"properties": map[string]any{
+				"id":             keyword,
+				"name":           keyword,
+				"arguments_hash": keyword,
+				"arguments":      map[string]any{"type": "text", "index": false},
+			},
+		},
+		"tool_results": map[string]any{
+			"properties": map[string]any{
+				"tool_call_id": keyword,
+				"name":         keyword,
+				"content_hash": keyword,
+				"content":      map[string]any{"type": "text", "index": false},
+			},
+		},
+	}
+
+	return map[string]any{
+		"index_patterns": []string{e.config.IndexPrefix + "-*"},
+		"template": map[string]any{
+			"settings": settings,
+			"mappings": map[string]any{"properties": properties},
+		},
+	}
+}
+
+// head sends a HEAD request and returns the status code.
+func (e *ElasticsearchExporter) head(ctx context.Context, path string) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, e.url+path, nil)
+	if err != nil {
+		return 0, err
+	}
+	e.authorize(req)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("request to %s failed: %w", req.URL.Redacted(), err)
+	}
+	resp.Body.Close()
+	return resp.StatusCode, nil
+}
+
+// do sends a request and returns the response body for 2xx responses.
+func (e *ElasticsearchExporter) do(ctx context.Context, method, path, contentType string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, e.url+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create elasticsearch request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	e.authorize(req)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to %s failed: %w", req.URL.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("elasticsearch %s %s returned status %d", method, path, resp.StatusCode)
+	}
+	return data, nil
+}
+
+// authorize adds the configured credentials to a request.
+func (e *ElasticsearchExporter) authorize(req *http.Request) {
+	switch {
+	case e.config.APIKey != "":
+		req.Header.Set("Authorization", "ApiKey "+e.config.APIKey)
+	case e.config.Username != "":
+		req.SetBasicAuth(e.config.Username, e.config.Password)
+	}
+}
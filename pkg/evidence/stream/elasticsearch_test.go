@@ -0,0 +1,186 @@
+package stream
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+// fakeElasticsearch records requests and answers like a cluster.
+type fakeElasticsearch struct {
+	mu        sync.Mutex
+	requests  []string // "METHOD path"
+	bulk      [][]byte
+	aliases   map[string]bool
+	failBulk  bool
+	templates map[string][]byte
+}
+
+func newFakeElasticsearch(t *testing.T) (*fakeElasticsearch, *httptest.Server) {
+	t.Helper()
+	f := &fakeElasticsearch{aliases: map[string]bool{}, templates: map[string][]byte{}}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	return f, server
+}
+
+func (f *fakeElasticsearch) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.Method+" "+r.URL.Path)
+
+	switch {
+	case r.Method == http.MethodPut && strings.HasPrefix(r.URL.Path, "/_index_template/"):
+		f.templates[strings.TrimPrefix(r.URL.Path, "/_index_template/")] = body
+		w.Write([]byte(`{"acknowledged":true}`))
+	case r.Method == http.MethodHead:
+		if f.aliases[strings.TrimPrefix(r.URL.Path, "/_alias/")] {
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	case r.Method == http.MethodPut:
+		w.Write([]byte(`{"acknowledged":true}`))
+	case r.URL.Path == "/_bulk":
+		f.bulk = append(f.bulk, body)
+		if f.failBulk {
+			w.Write([]byte(`{"errors":true,"items":[{"index":{"status":201}},{"index":{"status":400,"error":{"type":"mapper_parsing_exception","reason":"bad field"}}}]}`))
+			return
+		}
+		w.Write([]byte(`{"errors":false,"items":[]}`))
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestElasticsearchExporter_DailyIndices(t *testing.T) {
+	fake, server := newFakeElasticsearch(t)
+
+	exporter, err := NewElasticsearchExporter(&ElasticsearchConfig{URL: server.URL, ILMPolicy: "evidence-90d"})
+	if err != nil {
+		t.Fatalf("NewElasticsearchExporter() error = %v", err)
+	}
+
+	day := time.Date(2025, 11, 16, 23, 0, 0, 0, time.UTC)
+	records := []*evidence.EvidenceRecord{
+		{ID: "e1", RequestTime: day, Model: "gpt-4"},
+		{ID: "e2", RequestTime: day.Add(2 * time.Hour), Model: "claude-3"},
+	}
+	for i := 0; i < 2; i++ {
+		if err := exporter.Export(context.Background(), records); err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+	}
+
+	// The template is installed once, before the first bulk request
+	if len(fake.requests) != 3 || fake.requests[0] != "PUT /_index_template/mercator-evidence" {
+		t.Fatalf("unexpected requests: %v", fake.requests)
+	}
+
+	var template struct {
+		IndexPatterns []string `json:"index_patterns"`
+		Template      struct {
+			Settings map[string]string `json:"settings"`
+		} `json:"template"`
+	}
+	json.Unmarshal(fake.templates["mercator-evidence"], &template)
+	if template.IndexPatterns[0] != "mercator-evidence-*" {
+		t.Errorf("index_patterns = %v", template.IndexPatterns)
+	}
+	if template.Template.Settings["index.lifecycle.name"] != "evidence-90d" {
+		t.Errorf("template settings = %v, want ILM policy", template.Template.Settings)
+	}
+
+	lines := ndjsonLines(t, fake.bulk[0])
+	if len(lines) != 4 {
+		t.Fatalf("expected 4 bulk lines, got %d", len(lines))
+	}
+	if lines[0]["index"].(map[string]any)["_index"] != "mercator-evidence-2025.11.16" {
+		t.Errorf("first action = %v", lines[0])
+	}
+	if lines[2]["index"].(map[string]any)["_index"] != "mercator-evidence-2025.11.17" {
+		t.Errorf("second action = %v", lines[2])
+	}
+	if lines[1]["@timestamp"] != "2025-11-16T23:00:00Z" || lines[1]["model"] != "gpt-4" {
+		t.Errorf("unexpected document: %v", lines[1])
+	}
+}
+
+func TestElasticsearchExporter_RolloverBootstrap(t *testing.T) {
+	fake, server := newFakeElasticsearch(t)
+
+	exporter, _ := NewElasticsearchExporter(&ElasticsearchConfig{
+		URL:         server.URL,
+		IndexPrefix: "evidence",
+		IndexMode:   IndexModeRollover,
+		ILMPolicy:   "evidence-rollover",
+	})
+	if err := exporter.Export(context.Background(), []*evidence.EvidenceRecord{{ID: "e1"}}); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	want := []string{
+		"PUT /_index_template/evidence",
+		"HEAD /_alias/evidence",
+		"PUT /evidence-000001",
+		"POST /_bulk",
+	}
+	if len(fake.requests) != len(want) {
+		t.Fatalf("requests = %v, want %v", fake.requests, want)
+	}
+	for i := range want {
+		if fake.requests[i] != want[i] {
+			t.Errorf("request %d = %q, want %q", i, fake.requests[i], want[i])
+		}
+	}
+
+	lines := ndjsonLines(t, fake.bulk[0])
+	if lines[0]["index"].(map[string]any)["_index"] != "evidence" {
+		t.Errorf("rollover mode must write to the alias, got %v", lines[0])
+	}
+}
+
+func TestElasticsearchExporter_BulkItemErrors(t *testing.T) {
+	fake, server := newFakeElasticsearch(t)
+	fake.failBulk = true
+
+	exporter, _ := NewElasticsearchExporter(&ElasticsearchConfig{URL: server.URL})
+	err := exporter.Export(context.Background(), []*evidence.EvidenceRecord{{ID: "e1"}, {ID: "e2"}})
+	if err == nil {
+		t.Fatal("expected error for failed bulk items")
+	}
+}
+
+func TestNewElasticsearchExporter_Validation(t *testing.T) {
+	if _, err := NewElasticsearchExporter(&ElasticsearchConfig{}); err == nil {
+		t.Error("expected error for missing url")
+	}
+	if _, err := NewElasticsearchExporter(&ElasticsearchConfig{URL: "http://localhost:9200", IndexMode: "hourly"}); err == nil {
+		t.Error("expected error for invalid index mode")
+	}
+}
+
+func ndjsonLines(t *testing.T, data []byte) []map[string]any {
+	t.Helper()
+	var lines []map[string]any
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		var line map[string]any
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			t.Fatalf("invalid bulk line %q: %v", scanner.Text(), err)
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
@@ -0,0 +1,165 @@
+package stream
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/kafkarest"
+)
+
+// fakeRESTProxy records produce requests and assigns schema IDs.
+type fakeRESTProxy struct {
+	mu           sync.Mutex
+	contentTypes []string
+	requests     []map[string]json.RawMessage
+}
+
+func (f *fakeRESTProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body map[string]json.RawMessage
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	f.mu.Lock()
+	f.contentTypes = append(f.contentTypes, r.Header.Get("Content-Type"))
+	f.requests = append(f.requests, body)
+	f.mu.Unlock()
+
+	var records []json.RawMessage
+	json.Unmarshal(body["records"], &records)
+	offsets := make([]map[string]any, len(records))
+	for i := range records {
+		offsets[i] = map[string]any{"partition": 0, "offset": i}
+	}
+	json.NewEncoder(w).Encode(map[string]any{
+		"key_schema_id":   1,
+		"value_schema_id": 2,
+		"offsets":         offsets,
+	})
+}
+
+func TestKafkaExporter_JSON(t *testing.T) {
+	proxy := &fakeRESTProxy{}
+	server := httptest.NewServer(proxy)
+	defer server.Close()
+
+	exporter, err := NewKafkaExporter(&KafkaConfig{URL: server.URL, Topic: "evidence"})
+	if err != nil {
+		t.Fatalf("NewKafkaExporter() error = %v", err)
+	}
+
+	records := []*evidence.EvidenceRecord{
+		{ID: "e1", RequestID: "req-1", Model: "gpt-4"},
+		{ID: "e2", RequestID: "req-2", Model: "claude-3"},
+	}
+	if err := exporter.Export(context.Background(), records); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	if len(proxy.requests) != 1 {
+		t.Fatalf("expected one produce request per batch, got %d", len(proxy.requests))
+	}
+	if proxy.contentTypes[0] != kafkarest.ContentTypeJSON {
+		t.Errorf("Content-Type = %q, want %q", proxy.contentTypes[0], kafkarest.ContentTypeJSON)
+	}
+
+	var produced []kafkarest.Record
+	json.Unmarshal(proxy.requests[0]["records"], &produced)
+	if len(produced) != 2 || produced[1].Key != "req-2" {
+		t.Errorf("unexpected produced records: %+v", produced)
+	}
+}
+
+func TestKafkaExporter_AvroReusesSchemaIDs(t *testing.T) {
+	proxy := &fakeRESTProxy{}
+	server := httptest.NewServer(proxy)
+	defer server.Close()
+
+	exporter, err := NewKafkaExporter(&KafkaConfig{URL: server.URL, Topic: "evidence", Serialization: SerializationAvro})
+	if err != nil {
+		t.Fatalf("NewKafkaExporter() error = %v", err)
+	}
+
+	record := &evidence.EvidenceRecord{ID: "e1", RequestID: "req-1", RequestTime: time.UnixMilli(1700000000000)}
+	for i := 0; i < 2; i++ {
+		if err := exporter.Export(context.Background(), []*evidence.EvidenceRecord{record}); err != nil {
+			t.Fatalf("Export() error = %v", err)
+		}
+	}
+
+	first, second := proxy.requests[0], proxy.requests[1]
+	if proxy.contentTypes[0] != kafkarest.ContentTypeAvro {
+		t.Errorf("Content-Type = %q, want %q", proxy.contentTypes[0], kafkarest.ContentTypeAvro)
+	}
+	if _, ok := first["value_schema"]; !ok {
+		t.Error("first batch must include the value schema")
+	}
+	if _, ok := second["value_schema"]; ok {
+		t.Error("later batches must reference the registered schema ID")
+	}
+	if string(second["value_schema_id"]) != "2" || string(second["key_schema_id"]) != "1" {
+		t.Errorf("schema ids = %s/%s, want 1/2", second["key_schema_id"], second["value_schema_id"])
+	}
+
+	var produced []struct {
+		Value map[string]any `json:"value"`
+	}
+	json.Unmarshal(first["records"], &produced)
+	if produced[0].Value["request_time"] != float64(1700000000000) {
+		t.Errorf("request_time = %v, want epoch millis", produced[0].Value["request_time"])
+	}
+}
+
+func TestEvidenceAvroSchema_MatchesValue(t *testing.T) {
+	var schema struct {
+		Fields []struct {
+			Name string `json:"name"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(EvidenceAvroSchema), &schema); err != nil {
+		t.Fatalf("schema is not valid JSON: %v", err)
+	}
+
+	value := avroValue(&evidence.EvidenceRecord{})
+	if len(value) != len(schema.Fields) {
+		t.Errorf("value has %d fields, schema has %d", len(value), len(schema.Fields))
+	}
+	for _, field := range schema.Fields {
+		if _, ok := value[field.Name]; !ok {
+			t.Errorf("value is missing schema field %q", field.Name)
+		}
+	}
+}
+
+func TestKafkaExporter_PartialFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"offsets":[{"partition":0,"offset":1},{"error_code":50002,"error":"broker unavailable"}]}`))
+	}))
+	defer server.Close()
+
+	exporter, _ := NewKafkaExporter(&KafkaConfig{URL: server.URL, Topic: "evidence"})
+	err := exporter.Export(context.Background(), []*evidence.EvidenceRecord{{ID: "e1"}, {ID: "e2"}})
+	if err == nil {
+		t.Fatal("expected error for per-record produce failure")
+	}
+}
+
+func TestNewKafkaExporter_Validation(t *testing.T) {
+	tests := map[string]*KafkaConfig{
+		"missing url":           {Topic: "evidence"},
+		"missing topic":         {URL: "http://localhost:8082"},
+		"invalid serialization": {URL: "http://localhost:8082", Topic: "evidence", Serialization: "protobuf"},
+	}
+	for name, cfg := range tests {
+		if _, err := NewKafkaExporter(cfg); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
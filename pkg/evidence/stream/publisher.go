@@ -0,0 +1,266 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/evidence/recorder"
+)
+
+// Exporter delivers batches of evidence records to an external system.
+type Exporter interface {
+	// Name identifies the exporter in logs and statistics.
+	Name() string
+
+	// Export delivers a batch of records. It must respect ctx cancellation.
+	// A returned error marks the whole batch as failed. The slice is reused
+	// after Export returns and must not be retained.
+	Export(ctx context.Context, records []*evidence.EvidenceRecord) error
+
+	// Close releases any resources held by the exporter.
+	Close() error
+}
+
+// Config contains configuration for the publisher.
+type Config struct {
+	// BufferSize is the number of records queued per exporter.
+	// When an exporter's queue is full, new records for it are dropped.
+	// Default: 10000
+	BufferSize int
+
+	// BatchSize is the maximum number of records passed to a single Export call.
+	// Default: 100
+	BatchSize int
+
+	// FlushInterval is the maximum time a record waits in a partial batch.
+	// Default: 1 second
+	FlushInterval time.Duration
+
+	// ExportTimeout is the timeout for a single Export call.
+	// Default: 10 seconds
+	ExportTimeout time.Duration
+}
+
+// DefaultConfig returns the default publisher configuration.
+func DefaultConfig() *Config {
+	return &Config{
+		BufferSize:    10000,
+		BatchSize:     100,
+		FlushInterval: time.Second,
+		ExportTimeout: 10 * time.Second,
+	}
+}
+
+// ExporterStats contains delivery counters for an exporter.
+type ExporterStats struct {
+	// Exported is the number of records delivered successfully.
+	Exported int64 `json:"exported"`
+
+	// Failed is the number of records in batches that failed to export.
+	Failed int64 `json:"failed"`
+
+	// Dropped is the number of records dropped because the queue was full.
+	Dropped int64 `json:"dropped"`
+
+	// Queued is the number of records waiting in the exporter's queue.
+	Queued int `json:"queued"`
+}
+
+// exporterWorker owns the queue and counters for a single exporter.
+type exporterWorker struct {
+	exporter Exporter
+	queue    chan *evidence.EvidenceRecord
+	exported atomic.Int64
+	failed   atomic.Int64
+	dropped  atomic.Int64
+}
+
+// Publisher fans stored evidence records out to exporters in batches.
+//
+// Each exporter has its own queue and delivery goroutine, so a slow
+// exporter only affects its own backlog. Publisher implements
+// recorder.RecordObserver.
+type Publisher struct {
+	config  *Config
+	workers []*exporterWorker
+	wg      sync.WaitGroup
+	logger  *slog.Logger
+
+	// mu guards closed against concurrent Publish and Close.
+	mu     sync.RWMutex
+	closed bool
+}
+
+var _ recorder.RecordObserver = (*Publisher)(nil)
+
+// NewPublisher creates a publisher delivering to the given exporters and
+// starts its delivery goroutines.
+func NewPublisher(config *Config, exporters ...Exporter) *Publisher {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	defaults := DefaultConfig()
+	if config.BufferSize <= 0 {
+		config.BufferSize = defaults.BufferSize
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaults.FlushInterval
+	}
+	if config.ExportTimeout <= 0 {
+		config.ExportTimeout = defaults.ExportTimeout
+	}
+
+	p := &Publisher{
+		config: config,
+		logger: slog.Default().With("component", "evidence.stream"),
+	}
+
+	for _, exporter := range exporters {
+		w := &exporterWorker{
+			exporter: exporter,
+			queue:    make(chan *evidence.EvidenceRecord, config.BufferSize),
+		}
+		p.workers = append(p.workers, w)
+
+		p.wg.Add(1)
+		go p.run(w)
+	}
+
+	p.logger.Info("evidence stream publisher initialized",
+		"exporters", len(exporters),
+		"buffer_size", config.BufferSize,
+		"batch_size", config.BatchSize,
+	)
+
+	return p
+}
+
+// ObserveRecord publishes a stored evidence record.
+func (p *Publisher) ObserveRecord(record *evidence.EvidenceRecord) {
+	p.Publish(record)
+}
+
+// Publish queues a record for delivery to every exporter without blocking.
+// Records are dropped for exporters whose queue is full, or if the
+// publisher is closed.
+func (p *Publisher) Publish(record *evidence.EvidenceRecord) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return
+	}
+
+	for _, w := range p.workers {
+		select {
+		case w.queue <- record:
+		default:
+			w.dropped.Add(1)
+			p.logger.Warn("evidence stream queue full, dropping record",
+				"exporter", w.exporter.Name(),
+				"record_id", record.ID,
+				"request_id", record.RequestID,
+			)
+		}
+	}
+}
+
+// Stats returns delivery counters keyed by exporter name.
+func (p *Publisher) Stats() map[string]ExporterStats {
+	stats := make(map[string]ExporterStats, len(p.workers))
+	for _, w := range p.workers {
+		stats[w.exporter.Name()] = ExporterStats{
+			Exported: w.exported.Load(),
+			Failed:   w.failed.Load(),
+			Dropped:  w.dropped.Load(),
+			Queued:   len(w.queue),
+		}
+	}
+	return stats
+}
+
+// Close stops accepting records, exports queued records, and closes all
+// exporters.
+func (p *Publisher) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
+	}
+	p.closed = true
+	for _, w := range p.workers {
+		close(w.queue)
+	}
+	p.mu.Unlock()
+
+	p.wg.Wait()
+
+	var errs []error
+	for _, w := range p.workers {
+		if err := w.exporter.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	p.logger.Info("evidence stream publisher shut down")
+	return errors.Join(errs...)
+}
+
+// run batches queued records for an exporter until its queue is closed and
+// drained. A batch is exported when it is full or when the flush interval
+// elapses.
+func (p *Publisher) run(w *exporterWorker) {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*evidence.EvidenceRecord, 0, p.config.BatchSize)
+	for {
+		select {
+		case record, ok := <-w.queue:
+			if !ok {
+				p.export(w, batch)
+				return
+			}
+			batch = append(batch, record)
+			if len(batch) >= p.config.BatchSize {
+				p.export(w, batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			p.export(w, batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// export delivers a batch to an exporter and updates its counters.
+func (p *Publisher) export(w *exporterWorker, batch []*evidence.EvidenceRecord) {
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.config.ExportTimeout)
+	err := w.exporter.Export(ctx, batch)
+	cancel()
+
+	if err != nil {
+		w.failed.Add(int64(len(batch)))
+		p.logger.Error("failed to export evidence records",
+			"exporter", w.exporter.Name(),
+			"records", len(batch),
+			"error", err,
+		)
+		return
+	}
+	w.exported.Add(int64(len(batch)))
+}
@@ -0,0 +1,116 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+// recordingExporter captures exported batches.
+type recordingExporter struct {
+	mu      sync.Mutex
+	batches [][]string
+	err     error
+	closed  bool
+}
+
+func (e *recordingExporter) Name() string { return "recording" }
+
+func (e *recordingExporter) Export(ctx context.Context, records []*evidence.EvidenceRecord) error {
+	ids := make([]string, len(records))
+	for i, r := range records {
+		ids[i] = r.ID
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.batches = append(e.batches, ids)
+	return e.err
+}
+
+func (e *recordingExporter) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.closed = true
+	return nil
+}
+
+func (e *recordingExporter) batchCount() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return len(e.batches)
+}
+
+func TestPublisher_Batching(t *testing.T) {
+	exporter := &recordingExporter{}
+	p := NewPublisher(&Config{BufferSize: 10, BatchSize: 2, FlushInterval: time.Hour}, exporter)
+
+	for _, id := range []string{"a", "b", "c"} {
+		p.ObserveRecord(&evidence.EvidenceRecord{ID: id})
+	}
+
+	// The full batch is exported without waiting for the flush interval
+	deadline := time.Now().Add(time.Second)
+	for exporter.batchCount() < 1 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// Close exports the partial batch and closes the exporter
+	if err := p.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	if len(exporter.batches) != 2 || len(exporter.batches[0]) != 2 || exporter.batches[1][0] != "c" {
+		t.Errorf("unexpected batches: %v", exporter.batches)
+	}
+	if !exporter.closed {
+		t.Error("expected exporter to be closed")
+	}
+
+	stats := p.Stats()["recording"]
+	if stats.Exported != 3 {
+		t.Errorf("Exported = %d, want 3", stats.Exported)
+	}
+
+	// Records published after Close are ignored
+	p.Publish(&evidence.EvidenceRecord{ID: "late"})
+}
+
+func TestPublisher_FlushInterval(t *testing.T) {
+	exporter := &recordingExporter{}
+	p := NewPublisher(&Config{BatchSize: 100, FlushInterval: 10 * time.Millisecond}, exporter)
+	defer p.Close()
+
+	p.Publish(&evidence.EvidenceRecord{ID: "a"})
+
+	deadline := time.Now().Add(time.Second)
+	for exporter.batchCount() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("partial batch was not flushed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestPublisher_FailuresAndDrops(t *testing.T) {
+	exporter := &recordingExporter{err: errors.New("unavailable")}
+	p := NewPublisher(&Config{BufferSize: 1, BatchSize: 1, FlushInterval: time.Hour}, exporter)
+
+	for i := 0; i < 50; i++ {
+		p.Publish(&evidence.EvidenceRecord{ID: "r"})
+	}
+	p.Close()
+
+	stats := p.Stats()["recording"]
+	if stats.Exported != 0 {
+		t.Errorf("Exported = %d, want 0", stats.Exported)
+	}
+	if stats.Failed+stats.Dropped != 50 {
+		t.Errorf("Failed+Dropped = %d, want 50", stats.Failed+stats.Dropped)
+	}
+	if stats.Dropped == 0 {
+		t.Error("expected records to be dropped with a full queue")
+	}
+}
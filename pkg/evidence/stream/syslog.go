@@ -0,0 +1,237 @@
+package stream
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+// Syslog transports.
+const (
+	SyslogUDP = "udp"
+	SyslogTCP = "tcp"
+	SyslogTLS = "tls"
+)
+
+// DefaultSyslogFacility is the default syslog facility (local0).
+const DefaultSyslogFacility = 16
+
+// SyslogConfig configures a SyslogExporter.
+type SyslogConfig struct {
+	// Network is the transport: "udp", "tcp", or "tls".
+	// Default: "udp"
+	Network string
+
+	// Address is the collector address as host:port.
+	Address string
+
+	// Format is the event format: "cef" or "leef".
+	// Default: "cef"
+	Format string
+
+	// Facility is the syslog facility code (0-23).
+	// Default: 16 (local0)
+	Facility int
+
+	// Hostname is the host name in the syslog header.
+	// Default: os.Hostname()
+	Hostname string
+
+	// Tag is the syslog tag (application name).
+	// Default: "mercator"
+	Tag string
+
+	// ProductVersion is the device version reported in event headers.
+	// Default: "1.0"
+	ProductVersion string
+
+	// TLSConfig is the client TLS configuration for the "tls" transport.
+	// Default: system roots, server name taken from Address
+	TLSConfig *tls.Config
+
+	// DialTimeout bounds connection establishment.
+	// Default: 5 seconds
+	DialTimeout time.Duration
+}
+
+// SyslogExporter sends evidence summary events in CEF or LEEF format to a
+// syslog collector, for SIEMs that only ingest syslog.
+//
+// Messages use the RFC 3164 header. Over TCP and TLS each message is
+// terminated by a newline (RFC 6587 non-transparent framing). The
+// connection is established lazily and re-established after a failure on
+// the next batch.
+type SyslogExporter struct {
+	config *SyslogConfig
+
+	// mu serializes writes and guards conn.
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// NewSyslogExporter creates a syslog exporter.
+func NewSyslogExporter(config *SyslogConfig) (*SyslogExporter, error) {
+	if config.Address == "" {
+		return nil, fmt.Errorf("syslog address is required")
+	}
+	if _, _, err := net.SplitHostPort(config.Address); err != nil {
+		return nil, fmt.Errorf("invalid syslog address %q: %w", config.Address, err)
+	}
+
+	switch config.Network {
+	case "":
+		config.Network = SyslogUDP
+	case SyslogUDP, SyslogTCP, SyslogTLS:
+	default:
+		return nil, fmt.Errorf("unsupported syslog network %q", config.Network)
+	}
+	switch config.Format {
+	case "":
+		config.Format = FormatCEF
+	case FormatCEF, FormatLEEF:
+	default:
+		return nil, fmt.Errorf("unsupported syslog format %q", config.Format)
+	}
+
+	if config.Facility == 0 {
+		config.Facility = DefaultSyslogFacility
+	}
+	if config.Facility < 0 || config.Facility > 23 {
+		return nil, fmt.Errorf("invalid syslog facility %d", config.Facility)
+	}
+	if config.Hostname == "" {
+		config.Hostname, _ = os.Hostname()
+	}
+	if config.Tag == "" {
+		config.Tag = "mercator"
+	}
+	if config.ProductVersion == "" {
+		config.ProductVersion = "1.0"
+	}
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = 5 * time.Second
+	}
+
+	return &SyslogExporter{config: config}, nil
+}
+
+// Name returns the exporter name.
+func (e *SyslogExporter) Name() string {
+	return "syslog:" + e.config.Network + "://" + e.config.Address
+}
+
+// Export sends one syslog message per record.
+func (e *SyslogExporter) Export(ctx context.Context, records []*evidence.EvidenceRecord) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.conn == nil {
+		if err := e.connect(ctx); err != nil {
+			return err
+		}
+	}
+
+	if deadline, ok := ctx.Deadline(); ok {
+		e.conn.SetWriteDeadline(deadline)
+	}
+
+	for _, record := range records {
+		if _, err := e.conn.Write(e.message(record)); err != nil {
+			e.conn.Close()
+			e.conn = nil
+			return fmt.Errorf("syslog write failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// Close closes the collector connection.
+func (e *SyslogExporter) Close() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.conn == nil {
+		return nil
+	}
+	err := e.conn.Close()
+	e.conn = nil
+	return err
+}
+
+// connect dials the collector.
+func (e *SyslogExporter) connect(ctx context.Context) error {
+	dialer := &net.Dialer{Timeout: e.config.DialTimeout}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	switch e.config.Network {
+	case SyslogTLS:
+		tlsConfig := e.config.TLSConfig
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: tlsConfig}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", e.config.Address)
+	default:
+		conn, err = dialer.DialContext(ctx, e.config.Network, e.config.Address)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to syslog collector %s: %w", e.config.Address, err)
+	}
+
+	e.conn = conn
+	return nil
+}
+
+// message renders a record as a complete syslog message, including framing.
+func (e *SyslogExporter) message(record *evidence.EvidenceRecord) []byte {
+	summary := summarizeRecord(record)
+
+	var event string
+	if e.config.Format == FormatLEEF {
+		event = formatLEEF(summary, e.config.ProductVersion)
+	} else {
+		event = formatCEF(summary, e.config.ProductVersion)
+	}
+
+	timestamp := record.RequestTime
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "<%d>%s %s %s: %s",
+		e.config.Facility*8+syslogSeverity(summary.severity),
+		timestamp.Format(time.Stamp),
+		e.config.Hostname,
+		e.config.Tag,
+		event,
+	)
+	if e.config.Network != SyslogUDP {
+		b.WriteByte('\n')
+	}
+	return []byte(b.String())
+}
+
+// syslogSeverity maps an event severity (0-10) to a syslog severity.
+func syslogSeverity(severity int) int {
+	switch {
+	case severity >= 7:
+		return 4 // warning
+	case severity >= 5:
+		return 3 // error
+	case severity >= 4:
+		return 5 // notice
+	default:
+		return 6 // informational
+	}
+}
@@ -0,0 +1,166 @@
+package stream
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+)
+
+func blockedRecord() *evidence.EvidenceRecord {
+	return &evidence.EvidenceRecord{
+		ID:             "ev-1",
+		RequestID:      "req-1",
+		RequestTime:    time.Date(2025, 11, 16, 9, 30, 0, 0, time.UTC),
+		UserID:         "alice",
+		IPAddress:      "10.0.0.7",
+		Model:          "gpt-4",
+		PolicyDecision: "block",
+		BlockReason:    "PII detected: ssn=redacted|pipe",
+		MatchedRules:   []evidence.MatchedRuleRecord{{PolicyID: "pii", RuleID: "block-ssn"}},
+		UserPrompt:     "my ssn is 123-45-6789",
+	}
+}
+
+func TestFormatCEF(t *testing.T) {
+	msg := formatCEF(summarizeRecord(blockedRecord()), "0.1.0")
+
+	if !strings.HasPrefix(msg, "CEF:0|Mercator|Jupiter|0.1.0|llm-request-block|LLM request blocked|7|rt=1763285400000 ") {
+		t.Errorf("unexpected CEF header: %s", msg)
+	}
+	for _, want := range []string{
+		"externalId=req-1",
+		"suser=alice",
+		"src=10.0.0.7",
+		"cs2Label=model cs2=gpt-4",
+		"act=block",
+		`reason=PII detected: ssn\=redacted|pipe`,
+		"cs4Label=matchedRules cs4=pii/block-ssn",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("CEF message missing %q: %s", want, msg)
+		}
+	}
+	if strings.Contains(msg, "123-45-6789") {
+		t.Error("CEF message must not include prompt content")
+	}
+}
+
+func TestFormatLEEF(t *testing.T) {
+	msg := formatLEEF(summarizeRecord(blockedRecord()), "0.1.0")
+
+	if !strings.HasPrefix(msg, "LEEF:2.0|Mercator|Jupiter|0.1.0|llm-request-block|^|sev=7^devTime=Nov 16 2025 09:30:00.000 UTC^") {
+		t.Errorf("unexpected LEEF header: %s", msg)
+	}
+	for _, want := range []string{"usrName=alice", "model=gpt-4", "action=block", "matchedRules=pii/block-ssn"} {
+		if !strings.Contains(msg, "^"+want) {
+			t.Errorf("LEEF message missing %q: %s", want, msg)
+		}
+	}
+}
+
+func TestSyslogExporter_UDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("ListenPacket() error = %v", err)
+	}
+	defer conn.Close()
+
+	exporter, err := NewSyslogExporter(&SyslogConfig{Address: conn.LocalAddr().String(), Hostname: "proxy-1"})
+	if err != nil {
+		t.Fatalf("NewSyslogExporter() error = %v", err)
+	}
+	defer exporter.Close()
+
+	if err := exporter.Export(context.Background(), []*evidence.EvidenceRecord{blockedRecord()}); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	buf := make([]byte, 4096)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("ReadFrom() error = %v", err)
+	}
+
+	// local0 (16) * 8 + warning (4) = 132
+	msg := string(buf[:n])
+	if !strings.HasPrefix(msg, "<132>Nov 16 09:30:00 proxy-1 mercator: CEF:0|") {
+		t.Errorf("unexpected syslog message: %s", msg)
+	}
+	if strings.HasSuffix(msg, "\n") {
+		t.Error("UDP messages must not be newline framed")
+	}
+}
+
+func TestSyslogExporter_TCPFramingAndReconnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	defer ln.Close()
+
+	lines := make(chan string, 10)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				scanner := bufio.NewScanner(conn)
+				for scanner.Scan() {
+					lines <- scanner.Text()
+				}
+			}()
+		}
+	}()
+
+	exporter, err := NewSyslogExporter(&SyslogConfig{Network: SyslogTCP, Address: ln.Addr().String(), Format: FormatLEEF})
+	if err != nil {
+		t.Fatalf("NewSyslogExporter() error = %v", err)
+	}
+	defer exporter.Close()
+
+	records := []*evidence.EvidenceRecord{blockedRecord(), {ID: "ev-2", RequestID: "req-2"}}
+	if err := exporter.Export(context.Background(), records); err != nil {
+		t.Fatalf("Export() error = %v", err)
+	}
+
+	// Drop the connection; the next batch reconnects
+	exporter.Close()
+	if err := exporter.Export(context.Background(), records[1:]); err != nil {
+		t.Fatalf("Export() after reconnect error = %v", err)
+	}
+
+	for i := 0; i < 3; i++ {
+		select {
+		case line := <-lines:
+			if !strings.Contains(line, "LEEF:2.0|Mercator|Jupiter|") {
+				t.Errorf("unexpected line: %s", line)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("received %d of 3 messages", i)
+		}
+	}
+}
+
+func TestNewSyslogExporter_Validation(t *testing.T) {
+	tests := map[string]*SyslogConfig{
+		"missing address":  {},
+		"invalid address":  {Address: "collector"},
+		"invalid network":  {Address: "collector:514", Network: "sctp"},
+		"invalid format":   {Address: "collector:514", Format: "gelf"},
+		"invalid facility": {Address: "collector:514", Facility: 24},
+	}
+	for name, cfg := range tests {
+		if _, err := NewSyslogExporter(cfg); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
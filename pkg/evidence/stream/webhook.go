@@ -0,0 +1,300 @@
+package stream
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence"
+	"mercator-hq/jupiter/pkg/policy/events"
+)
+
+// WebhookMatch selects the evidence records a webhook is notified of. A
+// record matches if it meets every criterion set; a match with no criteria
+// matches every record. Use separate webhooks to be notified of records
+// meeting any of several criteria.
+type WebhookMatch struct {
+	// Decisions are policy decisions to match (e.g., "block").
+	Decisions []string
+
+	// MinCost matches records whose cost (actual, or estimated if the
+	// request did not complete) is at least this amount in USD.
+	MinCost float64
+
+	// PIIDetected matches records whose request contained PII.
+	PIIDetected bool
+
+	// Users and Teams restrict the match to these users and teams.
+	Users []string
+	Teams []string
+}
+
+// Matches reports whether a record meets the criteria, and if so, the
+// reasons it does, e.g. "decision=block".
+func (m *WebhookMatch) Matches(record *evidence.EvidenceRecord) ([]string, bool) {
+	var reasons []string
+	if len(m.Users) > 0 && !slices.Contains(m.Users, record.UserID) {
+		return nil, false
+	}
+	if len(m.Teams) > 0 && !slices.Contains(m.Teams, record.TeamID) {
+		return nil, false
+	}
+	if len(m.Decisions) > 0 {
+		decision := record.PolicyDecision
+		if decision == "" {
+			decision = "allow"
+		}
+		if !slices.Contains(m.Decisions, decision) {
+			return nil, false
+		}
+		reasons = append(reasons, "decision="+decision)
+	}
+	if m.MinCost > 0 {
+		cost := recordCost(record)
+		if cost < m.MinCost {
+			return nil, false
+		}
+		reasons = append(reasons, "cost="+strconv.FormatFloat(cost, 'f', -1, 64))
+	}
+	if m.PIIDetected {
+		if !record.PIIDetected {
+			return nil, false
+		}
+		reasons = append(reasons, "pii="+strings.Join(record.PIITypes, ","))
+	}
+	return reasons, true
+}
+
+// recordCost returns the actual cost of a record, or its estimated cost if
+// the request did not complete.
+func recordCost(record *evidence.EvidenceRecord) float64 {
+	if record.ActualCost > 0 {
+		return record.ActualCost
+	}
+	return record.EstimatedCost
+}
+
+// WebhookConfig configures a WebhookExporter.
+type WebhookConfig struct {
+	// Name identifies the webhook in payloads, logs, and statistics.
+	Name string
+
+	// URL is the endpoint notifications are POSTed to.
+	URL string
+
+	// Match selects the records that trigger a notification.
+	Match WebhookMatch
+
+	// Template is an optional text/template rendering the request body from
+	// a WebhookEvent. The "json" function encodes a value as JSON.
+	// Default: the WebhookEvent as JSON, with a summary of the record
+	Template string
+
+	// ContentType is the Content-Type of the request body.
+	// Default: "application/json"
+	ContentType string
+
+	// Headers are extra headers sent with each request.
+	Headers map[string]string
+
+	// Secret, if set, signs each body with HMAC-SHA256 in the
+	// X-Mercator-Signature header (see events.Sign).
+	Secret string
+
+	// Client is the HTTP client used for delivery.
+	// Default: http.DefaultClient
+	Client *http.Client
+}
+
+// WebhookEvent is a notification that an evidence record matched a
+// webhook, and the data of payload templates.
+type WebhookEvent struct {
+	// Webhook is the name of the webhook.
+	Webhook string `json:"webhook"`
+
+	// Reasons are the criteria the record met, e.g. "decision=block".
+	Reasons []string `json:"reasons"`
+
+	// Summary summarizes the record without prompt or response content.
+	Summary WebhookSummary `json:"record"`
+
+	// Record is the full evidence record, for templates only.
+	Record *evidence.EvidenceRecord `json:"-"`
+}
+
+// WebhookSummary is the part of an evidence record included in default
+// webhook payloads. Prompt and response content is never included.
+type WebhookSummary struct {
+	ID             string    `json:"id"`
+	RequestID      string    `json:"request_id"`
+	RequestTime    time.Time `json:"request_time"`
+	UserID         string    `json:"user_id,omitempty"`
+	TeamID         string    `json:"team_id,omitempty"`
+	CostTag        string    `json:"cost_tag,omitempty"`
+	LimitBypass    string    `json:"limit_bypass,omitempty"`
+	Classification string    `json:"data_classification,omitempty"`
+	Provider       string    `json:"provider,omitempty"`
+	Model          string    `json:"model,omitempty"`
+	PolicyDecision string    `json:"policy_decision,omitempty"`
+	BlockReason    string    `json:"block_reason,omitempty"`
+	MatchedRules   []string  `json:"matched_rules,omitempty"`
+	PIITypes       []string  `json:"pii_types,omitempty"`
+	Cost           float64   `json:"cost"`
+	TraceID        string    `json:"trace_id,omitempty"`
+	DecisionID     string    `json:"decision_id,omitempty"`
+}
+
+// WebhookExporter notifies an HTTP endpoint of each evidence record that
+// matches its criteria, one POST per record. Records that do not match are
+// skipped, so its statistics count every record evaluated.
+type WebhookExporter struct {
+	config   *WebhookConfig
+	client   *http.Client
+	template *template.Template
+}
+
+// NewWebhookExporter creates a webhook exporter, parsing its template.
+func NewWebhookExporter(config *WebhookConfig) (*WebhookExporter, error) {
+	if config.Name == "" {
+		return nil, fmt.Errorf("webhook name is required")
+	}
+	if config.URL == "" {
+		return nil, fmt.Errorf("webhook %q url is required", config.Name)
+	}
+	if config.ContentType == "" {
+		config.ContentType = "application/json"
+	}
+
+	e := &WebhookExporter{config: config, client: config.Client}
+	if e.client == nil {
+		e.client = http.DefaultClient
+	}
+	if config.Template != "" {
+		tmpl, err := template.New(config.Name).Option("missingkey=error").Funcs(template.FuncMap{
+			"json": func(v any) (string, error) {
+				data, err := json.Marshal(v)
+				return string(data), err
+			},
+		}).Parse(config.Template)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template for webhook %q: %w", config.Name, err)
+		}
+		e.template = tmpl
+	}
+	return e, nil
+}
+
+// Name returns the exporter name.
+func (e *WebhookExporter) Name() string {
+	return "webhook:" + e.config.Name
+}
+
+// Export notifies the endpoint of the matching records. Delivery continues
+// after a failed notification; the errors of all failed notifications are
+// returned.
+func (e *WebhookExporter) Export(ctx context.Context, records []*evidence.EvidenceRecord) error {
+	var errs []error
+	for _, record := range records {
+		reasons, ok := e.config.Match.Matches(record)
+		if !ok {
+			continue
+		}
+		if err := e.notify(ctx, newWebhookEvent(e.config.Name, reasons, record)); err != nil {
+			errs = append(errs, fmt.Errorf("record %s: %w", record.ID, err))
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Close is a no-op for webhook exporters.
+func (e *WebhookExporter) Close() error {
+	return nil
+}
+
+// notify renders and POSTs the payload of an event.
+func (e *WebhookExporter) notify(ctx context.Context, event *WebhookEvent) error {
+	var payload []byte
+	if e.template != nil {
+		var buf bytes.Buffer
+		if err := e.template.Execute(&buf, event); err != nil {
+			return fmt.Errorf("failed to render template: %w", err)
+		}
+		payload = buf.Bytes()
+	} else {
+		var err error
+		if payload, err = json.Marshal(event); err != nil {
+			return fmt.Errorf("failed to encode event: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.config.URL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", e.config.ContentType)
+	for k, v := range e.config.Headers {
+		req.Header.Set(k, v)
+	}
+	if e.config.Secret != "" {
+		req.Header.Set(events.SignatureHeader, events.Sign(e.config.Secret, payload))
+	}
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %w", req.URL.Redacted(), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("request to %s returned status %d: %s", req.URL.Redacted(), resp.StatusCode, bytes.TrimSpace(body))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// newWebhookEvent builds the notification of a matching record.
+func newWebhookEvent(webhook string, reasons []string, r *evidence.EvidenceRecord) *WebhookEvent {
+	rules := make([]string, 0, len(r.MatchedRules))
+	for _, rule := range r.MatchedRules {
+		rules = append(rules, rule.PolicyID+"/"+rule.RuleID)
+	}
+	if reasons == nil {
+		reasons = []string{}
+	}
+	return &WebhookEvent{
+		Webhook: webhook,
+		Reasons: reasons,
+		Summary: WebhookSummary{
+			ID:             r.ID,
+			RequestID:      r.RequestID,
+			RequestTime:    r.RequestTime,
+			UserID:         r.UserID,
+			TeamID:         r.TeamID,
+			CostTag:        r.CostTag,
+			LimitBypass:    r.LimitBypass,
+			Classification: r.DataClassification,
+			Provider:       r.Provider,
+			Model:          r.Model,
+			PolicyDecision: r.PolicyDecision,
+			BlockReason:    r.BlockReason,
+			MatchedRules:   rules,
+			PIITypes:       r.PIITypes,
+			Cost:           recordCost(r),
+			TraceID:        r.TraceID,
+			DecisionID:     r.DecisionID,
+		},
+		Record: r,
+	}
+}
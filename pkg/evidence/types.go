@@ -14,6 +14,10 @@ type EvidenceRecord struct {
 	ID        string `json:"id"`         // UUID v4
 	RequestID string `json:"request_id"` // From proxy
 
+	// Correlation
+	TraceID    string `json:"trace_id,omitempty"`    // OpenTelemetry trace ID
+	DecisionID string `json:"decision_id,omitempty"` // Policy decision ID (engine.PolicyDecision.ID)
+
 	// Timestamps
 	RequestTime      time.Time `json:"request_time"`       // When request received
 	PolicyEvalTime   time.Time `json:"policy_eval_time"`   // When policy evaluated
@@ -43,6 +47,10 @@ type EvidenceRecord struct {
 	PIIDetected     bool     `json:"pii_detected"`     // PII found?
 	PIITypes        []string `json:"pii_types"`        // PII types found
 
+	// Data classification (from processing)
+	DataClassification      string `json:"data_classification,omitempty"`       // Highest label of request and response
+	DataClassificationLevel int    `json:"data_classification_level,omitempty"` // Level of the label (0 = least sensitive)
+
 	// Policy decisions
 	PolicyDecision    string              `json:"policy_decision"`     // "allow", "block", "transform"
 	MatchedRules      []MatchedRuleRecord `json:"matched_rules"`       // Rules that matched
@@ -58,6 +66,12 @@ type EvidenceRecord struct {
 	ResponseContent string `json:"response_content"` // Response text (first 500 chars)
 	FinishReason    string `json:"finish_reason"`    // stop, length, tool_calls
 
+	// Response content analysis (from processing)
+	ResponseAnalysis *ResponseAnalysisRecord `json:"response_analysis,omitempty"` // What the response disclosed, if anything
+
+	// Analysis findings (from processing)
+	Findings []FindingRecord `json:"findings,omitempty"` // What triggered each finding of the request and response analysis
+
 	// Actual usage
 	PromptTokens     int     `json:"prompt_tokens"`     // Actual prompt tokens
 	CompletionTokens int     `json:"completion_tokens"` // Actual completion tokens
@@ -68,10 +82,28 @@ type EvidenceRecord struct {
 	ProviderLatency time.Duration `json:"provider_latency"` // Provider round-trip time
 	ProviderModel   string        `json:"provider_model"`   // Actual model used
 
+	// Streaming (see recorder.StreamRecorder)
+	Streamed           bool          `json:"streamed,omitempty"`            // Response was streamed (SSE)
+	StreamChunks       int           `json:"stream_chunks,omitempty"`       // Chunks received from the provider
+	TimeToFirstToken   time.Duration `json:"time_to_first_token,omitempty"` // Provider call to first chunk
+	StreamDuration     time.Duration `json:"stream_duration,omitempty"`     // Provider call to end of stream
+	ClientDisconnected bool          `json:"client_disconnected,omitempty"` // Client went away mid-stream
+
+	// Tool use
+	ToolCalls   []ToolCallRecord   `json:"tool_calls,omitempty"`   // Tool calls made by the response
+	ToolResults []ToolResultRecord `json:"tool_results,omitempty"` // Tool results sent with the request
+
 	// User/API key
-	UserID    string `json:"user_id"`    // User identifier
-	APIKey    string `json:"api_key"`    // API key (hashed or redacted)
-	IPAddress string `json:"ip_address"` // Client IP
+	UserID    string `json:"user_id"`           // User identifier
+	TeamID    string `json:"team_id,omitempty"` // Team of the API key
+	APIKey    string `json:"api_key"`           // API key (hashed or redacted)
+	IPAddress string `json:"ip_address"`        // Client IP
+
+	// Cost allocation
+	CostTag string `json:"cost_tag,omitempty"` // Cost center or project the request is charged to
+
+	// Limits
+	LimitBypass string `json:"limit_bypass,omitempty"` // Exemption or break-glass token that bypassed rate limits and budgets ("exemption:<name>", "break_glass:<name>")
 
 	// Error info
 	Error     string `json:"error"`      // Error message if request failed
@@ -80,6 +112,25 @@ type EvidenceRecord struct {
 	// Conversation context
 	TurnNumber   int     `json:"turn_number"`   // Turn in conversation
 	ContextUsage float64 `json:"context_usage"` // Context window usage (0-1)
+
+	// Full-body capture (see package capture)
+	RequestBodyRef  string `json:"request_body_ref,omitempty"`  // Blob reference of the full request body
+	ResponseBodyRef string `json:"response_body_ref,omitempty"` // Blob reference of the full response body
+	BodyTruncated   bool   `json:"body_truncated,omitempty"`    // A captured body exceeded the size cap
+
+	// Hash chain (see package integrity)
+	ChainID    string `json:"chain_id,omitempty"`    // Recorder hash chain
+	Sequence   int64  `json:"sequence,omitempty"`    // Position in chain (from 1)
+	PrevHash   string `json:"prev_hash,omitempty"`   // RecordHash of previous record in chain
+	RecordHash string `json:"record_hash,omitempty"` // SHA-256 of this record's canonical form
+
+	// Signature (see package integrity)
+	SigningKeyID string `json:"signing_key_id,omitempty"` // Fingerprint of the signing key
+	Signature    string `json:"signature,omitempty"`      // Base64 Ed25519 signature
+
+	// Anonymization (see retention.Anonymizer)
+	AnonymizedAt   *time.Time `json:"anonymized_at,omitempty"`   // When identifiers and PII were removed
+	AnonymizedHash string     `json:"anonymized_hash,omitempty"` // SHA-256 of the anonymized record's canonical form
 }
 
 // MatchedRuleRecord captures details about a policy rule that matched during
@@ -92,6 +143,48 @@ type MatchedRuleRecord struct {
 	EvaluationTime time.Duration `json:"evaluation_time"` // Time to evaluate
 }
 
+// ResponseAnalysisRecord captures what content analysis found in a response,
+// such as PII the model leaked. It records types only, never the values.
+type ResponseAnalysisRecord struct {
+	PIITypes            []string `json:"pii_types,omitempty"`            // PII types in the response
+	SecretTypes         []string `json:"secret_types,omitempty"`         // Secret types in the response
+	SensitiveCategories []string `json:"sensitive_categories,omitempty"` // Sensitive content categories of the response
+}
+
+// FindingRecord captures what triggered a content analysis finding, so that
+// reviewers can verify false positives. It records the span of the matched
+// excerpt, never the excerpt itself.
+type FindingRecord struct {
+	Stage      string  `json:"stage"`          // "request" or "response"
+	Detector   string  `json:"detector"`       // pii, sensitive, injection, secrets, entities, topics, classification
+	Type       string  `json:"type"`           // PII type, secret type, category, etc.
+	Source     string  `json:"source"`         // pattern, classifier, model, wordlist, etc.
+	Rule       string  `json:"rule,omitempty"` // Pattern, term, topic or reason that triggered the finding
+	Confidence float64 `json:"confidence"`     // Confidence from 0.0 to 1.0
+	Start      int     `json:"start"`          // Starting byte offset of the excerpt
+	End        int     `json:"end"`            // Ending byte offset of the excerpt (0 for the whole content)
+}
+
+// ToolCallRecord captures a tool (function) call made by the model. The
+// arguments are hashed; they are kept in full only for records selected for
+// capture.
+type ToolCallRecord struct {
+	ID            string `json:"id"`                  // Tool call identifier
+	Name          string `json:"name"`                // Function name
+	ArgumentsHash string `json:"arguments_hash"`      // SHA-256 of the JSON arguments
+	Arguments     string `json:"arguments,omitempty"` // JSON arguments (capture only)
+}
+
+// ToolResultRecord captures the result of a tool call that the client
+// returned to the model in a subsequent turn. The content is hashed; it is
+// kept in full only for records selected for capture.
+type ToolResultRecord struct {
+	ToolCallID  string `json:"tool_call_id"`      // Tool call answered
+	Name        string `json:"name,omitempty"`    // Function name, if the call is in the request
+	ContentHash string `json:"content_hash"`      // SHA-256 of the result content
+	Content     string `json:"content,omitempty"` // Result content (capture only)
+}
+
 // PolicyVersionInfo contains detailed version information for Git-based policy management.
 // This provides a complete audit trail of which policies were active when a request was processed.
 type PolicyVersionInfo struct {
@@ -120,14 +213,32 @@ type Query struct {
 	StartTime *time.Time `json:"start_time,omitempty"` // Inclusive start time
 	EndTime   *time.Time `json:"end_time,omitempty"`   // Inclusive end time
 
+	// AfterID excludes the records at exactly StartTime whose IDs are not
+	// greater than AfterID, for keyset pagination in (time, ID) order.
+	AfterID string `json:"after_id,omitempty"`
+
+	// TimeField is the record time that StartTime, EndTime, and AfterID
+	// apply to: "request_time" (default) or "recorded_time".
+	TimeField string `json:"time_field,omitempty"`
+
+	// IDs restricts the query to the records with these IDs.
+	IDs []string `json:"ids,omitempty"`
+
+	// Correlation
+	TraceID    string `json:"trace_id,omitempty"`    // Filter by OpenTelemetry trace ID
+	DecisionID string `json:"decision_id,omitempty"` // Filter by policy decision ID
+
 	// Filters
 	UserID         string `json:"user_id,omitempty"`         // Filter by user ID
+	TeamID         string `json:"team_id,omitempty"`         // Filter by team ID
+	CostTag        string `json:"cost_tag,omitempty"`        // Filter by cost allocation tag
 	APIKey         string `json:"api_key,omitempty"`         // Filter by API key
 	Provider       string `json:"provider,omitempty"`        // Filter by provider
 	Model          string `json:"model,omitempty"`           // Filter by model
 	PolicyID       string `json:"policy_id,omitempty"`       // Filter by policy ID
 	RuleID         string `json:"rule_id,omitempty"`         // Filter by rule ID
 	PolicyDecision string `json:"policy_decision,omitempty"` // "allow", "block", etc.
+	ToolName       string `json:"tool_name,omitempty"`       // Filter by called tool
 
 	// Thresholds
 	MinCost   *float64 `json:"min_cost,omitempty"`   // Minimum cost
@@ -138,6 +249,14 @@ type Query struct {
 	// Status
 	Status string `json:"status,omitempty"` // "success", "error", "blocked"
 
+	// Anonymized restricts the query to anonymized (true) or not yet
+	// anonymized (false) records.
+	Anonymized *bool `json:"anonymized,omitempty"`
+
+	// Full-text search over the recorded prompts and response excerpts.
+	// Words and "quoted phrases" must all occur in a record.
+	Search string `json:"search,omitempty"`
+
 	// Pagination
 	Limit  int `json:"limit,omitempty"`  // Max records to return
 	Offset int `json:"offset,omitempty"` // Skip N records
@@ -194,6 +313,100 @@ type Storage interface {
 	Close() error
 }
 
+// Aggregation dimensions for AggregateQuery.GroupBy.
+const (
+	DimensionUser     = "user"
+	DimensionTeam     = "team"
+	DimensionProvider = "provider"
+	DimensionModel    = "model"
+	DimensionDay      = "day"      // UTC date of the request time
+	DimensionCostTag  = "cost_tag" // Cost allocation tag
+)
+
+// AggregateQuery defines a grouped aggregation over evidence records.
+type AggregateQuery struct {
+	// Query filters the aggregated records. Limit, Offset, and sorting
+	// are ignored.
+	Query
+
+	// GroupBy lists the dimensions to group by, in output order.
+	// An empty GroupBy aggregates all matching records into one row.
+	GroupBy []string `json:"group_by,omitempty"`
+}
+
+// AggregateRow contains the totals of one group of evidence records. Only
+// the dimensions grouped by are set.
+type AggregateRow struct {
+	User     string `json:"user,omitempty"`
+	Team     string `json:"team,omitempty"`
+	Provider string `json:"provider,omitempty"`
+	Model    string `json:"model,omitempty"`
+	Day      string `json:"day,omitempty"` // YYYY-MM-DD (UTC)
+	CostTag  string `json:"cost_tag,omitempty"`
+
+	Requests         int64            `json:"requests"`
+	PromptTokens     int64            `json:"prompt_tokens"`
+	CompletionTokens int64            `json:"completion_tokens"`
+	TotalTokens      int64            `json:"total_tokens"`
+	Cost             float64          `json:"cost"`
+	Decisions        map[string]int64 `json:"decisions"` // Requests per policy decision
+}
+
+// Aggregator is implemented by storage backends that can aggregate records
+// natively. Backends without it are aggregated by reading every record.
+type Aggregator interface {
+	// Aggregate returns one row per group, ordered by the group values.
+	Aggregate(ctx context.Context, query *AggregateQuery) ([]*AggregateRow, error)
+}
+
+// RollupStore is implemented by storage backends that maintain
+// pre-computed daily rollups of evidence records.
+type RollupStore interface {
+	// RefreshRollups recomputes the rollups of every day from since
+	// (truncated to the UTC day) onwards.
+	RefreshRollups(ctx context.Context, since time.Time) error
+
+	// LatestRollup returns the most recent day with a rollup, or the zero
+	// time if there are none.
+	LatestRollup(ctx context.Context) (time.Time, error)
+
+	// AggregateRollups aggregates the daily rollups. Only time, user,
+	// team, provider, model, and policy decision filters are supported,
+	// and times are truncated to whole days.
+	AggregateRollups(ctx context.Context, query *AggregateQuery) ([]*AggregateRow, error)
+
+	// AnonymizeRollups merges the rollups of a user into those without a
+	// user, keeping the totals but not the user ID.
+	AnonymizeRollups(ctx context.Context, userID string) error
+
+	// PseudonymizeRollups replaces the user IDs of the rollups of days
+	// before the given time (truncated to the UTC day) with
+	// pseudonym(userID), merging rollups that end up with the same key.
+	PseudonymizeRollups(ctx context.Context, before time.Time, pseudonym func(userID string) string) error
+}
+
+// BatchStorer is implemented by storage backends that store several records
+// at once faster than one by one, such as in a single transaction.
+type BatchStorer interface {
+	// StoreBatch persists records atomically: either all of them are
+	// stored or none is.
+	StoreBatch(ctx context.Context, records []*EvidenceRecord) error
+}
+
+// Updater is implemented by storage backends that can rewrite stored
+// records, as anonymization requires.
+type Updater interface {
+	// Update replaces the stored records with the IDs of records by
+	// records, atomically. Records that are not stored are ignored.
+	Update(ctx context.Context, records []*EvidenceRecord) error
+}
+
+// HoldChecker reports whether records are under legal hold. Records under
+// hold must not be deleted by retention pruning or erasure.
+type HoldChecker interface {
+	IsHeld(record *EvidenceRecord) bool
+}
+
 // Exporter defines the interface for exporting evidence records to various formats.
 type Exporter interface {
 	// Export writes evidence records to the provided writer in the exporter's format.
@@ -0,0 +1,154 @@
+package kafkarest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Kafka REST Proxy (v2 API) content types.
+const (
+	// ContentTypeJSON produces records with JSON values.
+	ContentTypeJSON = "application/vnd.kafka.json.v2+json"
+
+	// ContentTypeAvro produces records with Avro values, registering the
+	// schema with the proxy's Schema Registry.
+	ContentTypeAvro = "application/vnd.kafka.avro.v2+json"
+
+	// accept is the response content type requested from the proxy.
+	accept = "application/vnd.kafka.v2+json"
+)
+
+// Config configures a Client.
+type Config struct {
+	// URL is the base URL of the Kafka REST Proxy (e.g., "http://kafka-rest:8082").
+	URL string
+
+	// Topic is the topic records are produced to.
+	Topic string
+
+	// Headers are extra headers sent with each request (e.g., authorization).
+	Headers map[string]string
+
+	// Client is the HTTP client used for delivery.
+	// Default: http.DefaultClient
+	Client *http.Client
+}
+
+// Client produces records to a Kafka topic through a Kafka REST Proxy.
+type Client struct {
+	config   *Config
+	client   *http.Client
+	endpoint string
+}
+
+// NewClient creates a REST Proxy client for the configured topic.
+func NewClient(config *Config) *Client {
+	client := config.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Client{
+		config:   config,
+		client:   client,
+		endpoint: strings.TrimSuffix(config.URL, "/") + "/topics/" + url.PathEscape(config.Topic),
+	}
+}
+
+// Topic returns the topic the client produces to.
+func (c *Client) Topic() string {
+	return c.config.Topic
+}
+
+// Record is a single record in a produce request.
+type Record struct {
+	Key   string `json:"key,omitempty"`
+	Value any    `json:"value"`
+}
+
+// ProduceRequest is the body of a produce request. Schemas and schema IDs
+// are only used with ContentTypeAvro.
+type ProduceRequest struct {
+	KeySchema     string   `json:"key_schema,omitempty"`
+	KeySchemaID   int      `json:"key_schema_id,omitempty"`
+	ValueSchema   string   `json:"value_schema,omitempty"`
+	ValueSchemaID int      `json:"value_schema_id,omitempty"`
+	Records       []Record `json:"records"`
+}
+
+// ProduceResponse is the body of a produce response.
+type ProduceResponse struct {
+	// KeySchemaID and ValueSchemaID are the schema IDs assigned by the
+	// Schema Registry (Avro only).
+	KeySchemaID   *int `json:"key_schema_id"`
+	ValueSchemaID *int `json:"value_schema_id"`
+
+	// Offsets holds one entry per record, in request order.
+	Offsets []Offset `json:"offsets"`
+}
+
+// Offset is the outcome of producing a single record.
+type Offset struct {
+	Partition int    `json:"partition"`
+	Offset    int64  `json:"offset"`
+	ErrorCode *int   `json:"error_code"`
+	Error     string `json:"error"`
+}
+
+// Produce sends a batch of records in a single request.
+//
+// The proxy reports per-record failures with a 2xx status, so Produce
+// returns an error if any record failed or if the response cannot be
+// decoded. The decoded response is returned even when some records failed,
+// so callers can still pick up assigned schema IDs.
+func (c *Client) Produce(ctx context.Context, contentType string, body *ProduceRequest) (*ProduceResponse, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode records: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create kafka request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", accept)
+	for k, v := range c.config.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to %s failed: %w", req.URL.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("kafka rest proxy returned status %d for topic %s", resp.StatusCode, c.config.Topic)
+	}
+
+	var produced ProduceResponse
+	if err := json.NewDecoder(resp.Body).Decode(&produced); err != nil {
+		return nil, fmt.Errorf("failed to decode kafka rest proxy response for topic %s: %w", c.config.Topic, err)
+	}
+
+	failed := 0
+	var firstErr string
+	for _, offset := range produced.Offsets {
+		if offset.ErrorCode != nil {
+			if failed == 0 {
+				firstErr = fmt.Sprintf("code %d: %s", *offset.ErrorCode, offset.Error)
+			}
+			failed++
+		}
+	}
+	if failed > 0 {
+		return &produced, fmt.Errorf("kafka produce to %s failed for %d of %d records (%s)", c.config.Topic, failed, len(body.Records), firstErr)
+	}
+
+	return &produced, nil
+}
@@ -0,0 +1,84 @@
+package kafkarest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClient_Produce(t *testing.T) {
+	var gotPath, gotContentType, gotAuth string
+	var gotBody ProduceRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotContentType = r.Header.Get("Content-Type")
+		gotAuth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Write([]byte(`{"value_schema_id":7,"offsets":[{"partition":0,"offset":42}]}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(&Config{
+		URL:     server.URL + "/",
+		Topic:   "mercator.evidence",
+		Headers: map[string]string{"Authorization": "Basic abc"},
+	})
+	produced, err := client.Produce(context.Background(), ContentTypeAvro, &ProduceRequest{
+		ValueSchema: `"string"`,
+		Records:     []Record{{Key: "req-1", Value: "hello"}},
+	})
+	if err != nil {
+		t.Fatalf("Produce() error = %v", err)
+	}
+
+	if gotPath != "/topics/mercator.evidence" {
+		t.Errorf("path = %q", gotPath)
+	}
+	if gotContentType != ContentTypeAvro || gotAuth != "Basic abc" {
+		t.Errorf("headers = %q, %q", gotContentType, gotAuth)
+	}
+	if len(gotBody.Records) != 1 || gotBody.Records[0].Key != "req-1" || gotBody.ValueSchema != `"string"` {
+		t.Errorf("unexpected body: %+v", gotBody)
+	}
+	if produced.ValueSchemaID == nil || *produced.ValueSchemaID != 7 {
+		t.Errorf("value schema id = %v, want 7", produced.ValueSchemaID)
+	}
+}
+
+func TestClient_ProduceErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		body         string
+		wantErr      string
+		wantResponse bool
+	}{
+		{"error status", http.StatusInternalServerError, `{}`, "status 500", false},
+		{"undecodable body", http.StatusOK, `<html>gateway</html>`, "failed to decode", false},
+		{"record failure", http.StatusOK, `{"value_schema_id":7,"offsets":[{"partition":0,"offset":1},{"error_code":50002,"error":"broker unavailable"}]}`, "1 of 2 records", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClient(&Config{URL: server.URL, Topic: "evidence"})
+			produced, err := client.Produce(context.Background(), ContentTypeJSON, &ProduceRequest{
+				Records: []Record{{Key: "a"}, {Key: "b"}},
+			})
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("Produce() error = %v, want containing %q", err, tt.wantErr)
+			}
+			if (produced != nil) != tt.wantResponse {
+				t.Errorf("response = %+v, want returned: %v", produced, tt.wantResponse)
+			}
+		})
+	}
+}
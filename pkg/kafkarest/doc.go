@@ -0,0 +1,19 @@
+// Package kafkarest is a minimal client for the Kafka REST Proxy (v2 API).
+//
+// It is shared by the Kafka policy event sink, the Kafka evidence exporter
+// and the telemetry bus, which produce to Kafka over HTTP rather than
+// through a native Kafka client. A Client produces batches of keyed records
+// to a single topic and reports both request-level and per-record failures
+// as errors.
+//
+// # Usage
+//
+//	client := kafkarest.NewClient(&kafkarest.Config{
+//	    URL:   "http://kafka-rest:8082",
+//	    Topic: "mercator.evidence",
+//	})
+//
+//	_, err := client.Produce(ctx, kafkarest.ContentTypeJSON, &kafkarest.ProduceRequest{
+//	    Records: []kafkarest.Record{{Key: requestID, Value: value}},
+//	})
+package kafkarest
@@ -0,0 +1,423 @@
+package limits
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"mercator-hq/jupiter/pkg/limits/budget"
+)
+
+// ErrBudgetNotFound is returned when overriding or resetting a budget that
+// is not configured.
+var ErrBudgetNotFound = errors.New("budget not found")
+
+// ErrScopeNotFound is returned when resetting limits of a scope that has
+// neither a budget nor rate limits.
+var ErrScopeNotFound = errors.New("no limits configured")
+
+// ScopeUsage is the current consumption of the limits of one scope.
+type ScopeUsage struct {
+	Dimension  Dimension `json:"dimension"`
+	Identifier string    `json:"identifier"`
+
+	// Model is set for limits scoped to the identifier's use of a model.
+	Model string `json:"model,omitempty"`
+
+	// Budgets contains the usage of each configured budget window.
+	Budgets []BudgetWindowUsage `json:"budgets,omitempty"`
+
+	// TotalSpent is the all-time spending in USD.
+	TotalSpent float64 `json:"total_spent"`
+
+	// RateLimits contains the usage of token and concurrency limits.
+	RateLimits *RateLimitUsage `json:"rate_limits,omitempty"`
+
+	// Override is the active budget override, if any.
+	Override *BudgetOverride `json:"override,omitempty"`
+}
+
+// BudgetWindowUsage is the usage of one budget window.
+type BudgetWindowUsage struct {
+	Window     string    `json:"window"`
+	Limit      float64   `json:"limit"`
+	Used       float64   `json:"used"`
+	Remaining  float64   `json:"remaining"`
+	Percentage float64   `json:"percentage"`
+	Reset      time.Time `json:"reset"`
+}
+
+// RateLimitUsage is the usage of token and concurrency limits. Limits
+// that are not configured are omitted.
+type RateLimitUsage struct {
+	TokensPerMinute *LimitUsage `json:"tokens_per_minute,omitempty"`
+	TokensPerHour   *LimitUsage `json:"tokens_per_hour,omitempty"`
+	Concurrent      *LimitUsage `json:"concurrent,omitempty"`
+	Streams         *LimitUsage `json:"streams,omitempty"`
+}
+
+// LimitUsage is the usage of a count-based limit.
+type LimitUsage struct {
+	Limit int64 `json:"limit"`
+	Used  int64 `json:"used"`
+}
+
+// BudgetOverride temporarily replaces the limits of a budget, e.g. to
+// unblock a team during an incident. Zero limits keep the configured
+// value. The override is removed at ExpiresAt.
+//
+// Overrides are held in memory by the manager they are set on: they apply
+// to one proxy replica and do not survive a restart.
+type BudgetOverride struct {
+	Dimension  Dimension `json:"dimension"`
+	Identifier string    `json:"identifier"`
+
+	Hourly  float64 `json:"hourly,omitempty"`
+	Daily   float64 `json:"daily,omitempty"`
+	Monthly float64 `json:"monthly,omitempty"`
+
+	ExpiresAt time.Time `json:"expires_at"`
+
+	// Reason describes the override, e.g. an incident reference.
+	Reason string `json:"reason,omitempty"`
+
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// scope returns the budget scope the override applies to.
+func (o *BudgetOverride) scope() BudgetScope {
+	return BudgetScope{Dimension: o.Dimension, Identifier: o.Identifier}
+}
+
+// validate checks that an override sets a limit and has not expired.
+func (o *BudgetOverride) validate() error {
+	if o.Identifier == "" {
+		return errors.New("budget override requires an identifier")
+	}
+	if o.Hourly < 0 || o.Daily < 0 || o.Monthly < 0 {
+		return errors.New("budget override limits must be non-negative")
+	}
+	if o.Hourly == 0 && o.Daily == 0 && o.Monthly == 0 {
+		return errors.New("budget override requires an hourly, daily, or monthly limit")
+	}
+	if !o.ExpiresAt.After(time.Now()) {
+		return errors.New("budget override must expire in the future")
+	}
+	return nil
+}
+
+// budgetOverride is an active override and the timer that removes it.
+type budgetOverride struct {
+	override BudgetOverride
+	timer    *time.Timer
+}
+
+// Usage returns the current consumption of the limits of a scope and of
+// the identifier's model-scoped limits. An empty identifier returns every
+// scope with limits. Results are sorted by dimension, identifier and model.
+func (m *Manager) Usage(dimension Dimension, identifier string) []ScopeUsage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make(map[string]bool)
+	for key := range m.budgetConfigs {
+		keys[key] = true
+	}
+	for key := range m.rateLimitConfigs {
+		keys[key] = true
+	}
+
+	var usages []ScopeUsage
+	for key := range keys {
+		usage := m.scopeUsage(key)
+		if identifier != "" && (usage.Identifier != identifier || usage.Dimension != normalizeDimension(dimension)) {
+			continue
+		}
+		usages = append(usages, usage)
+	}
+
+	sort.Slice(usages, func(i, j int) bool {
+		a, b := usages[i], usages[j]
+		if a.Dimension != b.Dimension {
+			return a.Dimension < b.Dimension
+		}
+		if a.Identifier != b.Identifier {
+			return a.Identifier < b.Identifier
+		}
+		return a.Model < b.Model
+	})
+	return usages
+}
+
+// scopeUsage returns the usage of the limits tracked under key.
+// Caller must hold write lock.
+func (m *Manager) scopeUsage(key string) ScopeUsage {
+	var usage ScopeUsage
+	usage.Dimension, usage.Identifier, usage.Model = m.keyScope(key)
+
+	if tracker := m.getBudgetTracker(key); tracker != nil {
+		windows := []struct {
+			name   string
+			status *budget.Status
+		}{
+			{"hourly", tracker.GetHourlyStatus()},
+			{"daily", tracker.GetDailyStatus()},
+			{"monthly", tracker.GetMonthlyStatus()},
+		}
+		for _, w := range windows {
+			if w.status.Limit == 0 {
+				continue
+			}
+			usage.Budgets = append(usage.Budgets, BudgetWindowUsage{
+				Window:     w.name,
+				Limit:      w.status.Limit,
+				Used:       w.status.Used,
+				Remaining:  w.status.Remaining,
+				Percentage: w.status.Percentage,
+				Reset:      w.status.Reset,
+			})
+		}
+		usage.TotalSpent = tracker.GetTotalSpent()
+	}
+
+	if limiter := m.getRateLimiter(key); limiter != nil {
+		config := limiter.Config()
+		perMinute, perHour := limiter.TokensUsed()
+		rateLimits := &RateLimitUsage{}
+		if config.TokensPerMinute > 0 {
+			rateLimits.TokensPerMinute = &LimitUsage{Limit: int64(config.TokensPerMinute), Used: perMinute}
+		}
+		if config.TokensPerHour > 0 {
+			rateLimits.TokensPerHour = &LimitUsage{Limit: int64(config.TokensPerHour), Used: perHour}
+		}
+		if config.MaxConcurrent > 0 {
+			status := limiter.GetConcurrentStatus()
+			rateLimits.Concurrent = &LimitUsage{Limit: status.Limit, Used: status.Limit - status.Remaining}
+		}
+		if config.MaxConcurrentStreams > 0 {
+			status := limiter.GetStreamStatus()
+			rateLimits.Streams = &LimitUsage{Limit: status.Limit, Used: status.Limit - status.Remaining}
+		}
+		if *rateLimits != (RateLimitUsage{}) {
+			usage.RateLimits = rateLimits
+		}
+	}
+
+	if active, ok := m.overrides[key]; ok {
+		override := active.override
+		usage.Override = &override
+	}
+	return usage
+}
+
+// keyScope returns the dimension, identifier and, for model-scoped limits,
+// model of the limits tracked under key.
+func (m *Manager) keyScope(key string) (Dimension, string, string) {
+	if s, ok := m.modelScopes[key]; ok {
+		return DimensionAPIKey, s.identifier, s.model
+	}
+	scope := m.budgetScope(key)
+	return scope.Dimension, scope.Identifier, ""
+}
+
+// SetBudgetOverride replaces the limits of a configured budget until the
+// override expires. An override replaces any earlier override of the same
+// budget. It returns ErrBudgetNotFound if the budget is not configured.
+func (m *Manager) SetBudgetOverride(override BudgetOverride) (*BudgetOverride, error) {
+	override.Dimension = normalizeDimension(override.Dimension)
+	if err := override.validate(); err != nil {
+		return nil, err
+	}
+	if override.CreatedAt.IsZero() {
+		override.CreatedAt = time.Now()
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := override.scope().Key()
+	tracker := m.getBudgetTracker(key)
+	if tracker == nil {
+		return nil, fmt.Errorf("%w: %s", ErrBudgetNotFound, key)
+	}
+
+	limits := m.budgetConfigs[key]
+	if override.Hourly > 0 {
+		limits.Hourly = override.Hourly
+	}
+	if override.Daily > 0 {
+		limits.Daily = override.Daily
+	}
+	if override.Monthly > 0 {
+		limits.Monthly = override.Monthly
+	}
+	tracker.SetLimits(limits)
+
+	if previous, ok := m.overrides[key]; ok {
+		previous.timer.Stop()
+	}
+	active := &budgetOverride{override: override}
+	active.timer = time.AfterFunc(time.Until(override.ExpiresAt), func() {
+		m.expireOverride(key, active)
+	})
+	m.overrides[key] = active
+	m.queue.Notify()
+
+	m.logger.Info("budget override set",
+		"budget", key,
+		"hourly", limits.Hourly,
+		"daily", limits.Daily,
+		"monthly", limits.Monthly,
+		"expires_at", override.ExpiresAt,
+		"created_by", override.CreatedBy,
+		"reason", override.Reason,
+	)
+	return &override, nil
+}
+
+// BudgetOverrides returns the active budget overrides, sorted by
+// dimension and identifier.
+func (m *Manager) BudgetOverrides() []BudgetOverride {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	overrides := make([]BudgetOverride, 0, len(m.overrides))
+	for _, active := range m.overrides {
+		overrides = append(overrides, active.override)
+	}
+	sort.Slice(overrides, func(i, j int) bool {
+		if overrides[i].Dimension != overrides[j].Dimension {
+			return overrides[i].Dimension < overrides[j].Dimension
+		}
+		return overrides[i].Identifier < overrides[j].Identifier
+	})
+	return overrides
+}
+
+// RemoveBudgetOverride removes the override of a budget before it
+// expires, restoring the configured limits. It returns ErrBudgetNotFound
+// if the budget has no active override.
+func (m *Manager) RemoveBudgetOverride(scope BudgetScope) (*BudgetOverride, error) {
+	scope.Dimension = normalizeDimension(scope.Dimension)
+	key := scope.Key()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	active, ok := m.overrides[key]
+	if !ok {
+		return nil, fmt.Errorf("%w: no override for %s", ErrBudgetNotFound, key)
+	}
+	active.timer.Stop()
+	m.removeOverride(key)
+
+	m.logger.Info("budget override removed", "budget", key)
+	override := active.override
+	return &override, nil
+}
+
+// expireOverride removes an override when it expires, unless it has been
+// replaced in the meantime.
+func (m *Manager) expireOverride(key string, active *budgetOverride) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.overrides[key] != active {
+		return
+	}
+	m.removeOverride(key)
+	m.logger.Info("budget override expired", "budget", key)
+}
+
+// removeOverride restores the configured limits of a budget.
+// Caller must hold write lock.
+func (m *Manager) removeOverride(key string) {
+	delete(m.overrides, key)
+	if tracker := m.getBudgetTracker(key); tracker != nil {
+		tracker.SetLimits(m.budgetConfigs[key])
+	}
+	m.queue.Notify()
+}
+
+// stopOverrides stops the expiry timers of all overrides.
+func (m *Manager) stopOverrides() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, active := range m.overrides {
+		active.timer.Stop()
+	}
+}
+
+// ResetLimits clears the recorded usage of a scope: its budget windows
+// and persisted budget state, its rate limits, or both. The limits of the
+// identifier's use of specific models are reset with it. Rate limits only
+// exist for API keys.
+//
+// It returns ErrScopeNotFound if the scope has none of the requested
+// limits.
+func (m *Manager) ResetLimits(ctx context.Context, scope BudgetScope, budgets, rateLimits bool) error {
+	scope.Dimension = normalizeDimension(scope.Dimension)
+	key := scope.Key()
+
+	m.mu.Lock()
+	var (
+		trackers []string
+		reset    int
+	)
+	for k := range m.budgetConfigs {
+		if budgets && m.coversKey(scope, key, k) {
+			if tracker := m.getBudgetTracker(k); tracker != nil {
+				tracker.Reset()
+				trackers = append(trackers, k)
+				reset++
+			}
+		}
+	}
+	for k := range m.rateLimitConfigs {
+		if rateLimits && m.coversKey(scope, key, k) {
+			if limiter := m.getRateLimiter(k); limiter != nil {
+				limiter.Reset()
+				reset++
+			}
+		}
+	}
+	m.mu.Unlock()
+
+	if reset == 0 {
+		return fmt.Errorf("%w: %s", ErrScopeNotFound, key)
+	}
+	m.queue.Notify()
+
+	// Delete persisted state so it is not restored or merged back
+	for _, k := range trackers {
+		s := m.budgetScope(k)
+		if err := m.storage.Delete(ctx, s.Identifier, string(s.Dimension)); err != nil {
+			return fmt.Errorf("failed to delete budget state of %s: %w", k, err)
+		}
+	}
+
+	m.logger.Info("limits reset", "scope", key, "budgets", budgets, "rate_limits", rateLimits)
+	return nil
+}
+
+// coversKey reports whether the limits tracked under k belong to a scope:
+// the scope's own limits or, for API keys, its model-scoped limits.
+func (m *Manager) coversKey(scope BudgetScope, key, k string) bool {
+	if k == key {
+		return true
+	}
+	s, ok := m.modelScopes[k]
+	return ok && scope.Dimension == DimensionAPIKey && s.identifier == scope.Identifier
+}
+
+// normalizeDimension defaults an empty dimension to DimensionAPIKey.
+func normalizeDimension(dimension Dimension) Dimension {
+	if dimension == "" {
+		return DimensionAPIKey
+	}
+	return dimension
+}
@@ -0,0 +1,333 @@
+package limits
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"mercator-hq/jupiter/pkg/limits/budget"
+	"mercator-hq/jupiter/pkg/security/auth"
+)
+
+// maxAdminRequestSize limits the size of admin request bodies.
+const maxAdminRequestSize = 64 << 10
+
+// UsageHandler returns an HTTP handler reporting current consumption, e.g.
+// when mounted at /admin/limits/usage.
+//
+// GET lists the usage of every scope with limits, or with an identifier
+// parameter (and optionally dimension, default api_key), that scope and
+// its model-scoped limits.
+//
+// The handler must be served behind admin authentication.
+func (m *Manager) UsageHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := auth.AdminPrincipal(r.Context()); !ok {
+			http.Error(w, "admin authentication required", http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		query := r.URL.Query()
+		identifier := query.Get("identifier")
+		usages := m.Usage(Dimension(query.Get("dimension")), identifier)
+		if identifier != "" && len(usages) == 0 {
+			http.Error(w, fmt.Sprintf("no limits configured for %s", identifier), http.StatusNotFound)
+			return
+		}
+		if usages == nil {
+			usages = []ScopeUsage{}
+		}
+		writeAdminJSON(w, http.StatusOK, usages)
+	})
+}
+
+// UsageHistoryHandler returns an HTTP handler reporting the usage history
+// of an identifier, e.g. when mounted at /admin/limits/usage/history, for
+// usage dashboards.
+//
+// GET returns the requests, tokens and cost of the identifier parameter
+// (and optionally dimension, default api_key) bucketed by the granularity
+// parameter, "hour" (default) or "day" (UTC). The start and end parameters
+// (RFC 3339) bound the history; they default to the last 24 hours with
+// hourly buckets and the last 30 days with daily buckets.
+//
+// The handler must be served behind admin authentication.
+func (m *Manager) UsageHistoryHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := auth.AdminPrincipal(r.Context()); !ok {
+			http.Error(w, "admin authentication required", http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		query := r.URL.Query()
+		identifier := query.Get("identifier")
+		if identifier == "" {
+			http.Error(w, "identifier is required", http.StatusBadRequest)
+			return
+		}
+		var span time.Duration
+		granularity := query.Get("granularity")
+		switch granularity {
+		case "", GranularityHour:
+			granularity, span = GranularityHour, 24*time.Hour
+		case GranularityDay:
+			span = 30 * 24 * time.Hour
+		default:
+			http.Error(w, fmt.Sprintf("invalid granularity %q: must be hour or day", granularity), http.StatusBadRequest)
+			return
+		}
+
+		end := time.Now()
+		if value := query.Get("end"); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid end: %v", err), http.StatusBadRequest)
+				return
+			}
+			end = t
+		}
+		start := end.Add(-span)
+		if value := query.Get("start"); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("invalid start: %v", err), http.StatusBadRequest)
+				return
+			}
+			start = t
+		}
+		if !start.Before(end) {
+			http.Error(w, "start must be before end", http.StatusBadRequest)
+			return
+		}
+
+		history, err := m.UsageHistory(r.Context(), Dimension(query.Get("dimension")), identifier, granularity, start, end)
+		if errors.Is(err, ErrUsageHistoryUnsupported) {
+			http.Error(w, err.Error(), http.StatusNotImplemented)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeAdminJSON(w, http.StatusOK, history)
+	})
+}
+
+// ForecastHandler returns an HTTP handler projecting budget spending, e.g.
+// when mounted at /admin/limits/forecast.
+//
+// GET lists the forecast of every budget, or with an identifier parameter
+// (and optionally dimension, default api_key), of that scope and its
+// model-scoped budgets. The method parameter selects the projection,
+// "seasonal" (default) or "linear". With at_risk=true, only scopes with a
+// budget projected to exceed its limit are listed.
+//
+// The handler must be served behind admin authentication.
+func (m *Manager) ForecastHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := auth.AdminPrincipal(r.Context()); !ok {
+			http.Error(w, "admin authentication required", http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		query := r.URL.Query()
+		method := budget.ForecastMethod(query.Get("method"))
+		switch method {
+		case "":
+			method = budget.ForecastSeasonal
+		case budget.ForecastSeasonal, budget.ForecastLinear:
+		default:
+			http.Error(w, fmt.Sprintf("invalid method %q: must be seasonal or linear", method), http.StatusBadRequest)
+			return
+		}
+		atRisk, _ := strconv.ParseBool(query.Get("at_risk"))
+
+		identifier := query.Get("identifier")
+		forecasts := m.Forecast(Dimension(query.Get("dimension")), identifier, method)
+		if identifier != "" && len(forecasts) == 0 {
+			http.Error(w, fmt.Sprintf("no budget configured for %s", identifier), http.StatusNotFound)
+			return
+		}
+
+		result := []ScopeForecast{}
+		for _, forecast := range forecasts {
+			if !atRisk || forecast.Exceeds() {
+				result = append(result, forecast)
+			}
+		}
+		writeAdminJSON(w, http.StatusOK, result)
+	})
+}
+
+// overrideRequest is the body of a budget override request. The override
+// expires after Duration (e.g. "4h") or at ExpiresAt.
+type overrideRequest struct {
+	BudgetOverride
+	Duration string `json:"duration,omitempty"`
+}
+
+// OverridesHandler returns an HTTP handler for temporary budget overrides,
+// e.g. when mounted at /admin/limits/overrides.
+//
+// GET lists the active overrides. POST sets the override in the JSON body
+// and responds with it. DELETE removes the override of the budget given
+// by the identifier and dimension parameters.
+//
+// The handler must be served behind admin authentication: requests without
+// an authenticated admin principal are rejected, and the principal is
+// recorded as the override's creator.
+func (m *Manager) OverridesHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := auth.AdminPrincipal(r.Context()); !ok {
+			http.Error(w, "admin authentication required", http.StatusUnauthorized)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			writeAdminJSON(w, http.StatusOK, m.BudgetOverrides())
+		case http.MethodPost:
+			m.handleSetOverride(w, r)
+		case http.MethodDelete:
+			m.handleRemoveOverride(w, r)
+		default:
+			w.Header().Set("Allow", "GET, POST, DELETE")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// handleSetOverride sets a budget override.
+func (m *Manager) handleSetOverride(w http.ResponseWriter, r *http.Request) {
+	var req overrideRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAdminRequestSize)).Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+		return
+	}
+	if req.Duration != "" {
+		duration, err := time.ParseDuration(req.Duration)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid duration: %v", err), http.StatusBadRequest)
+			return
+		}
+		req.ExpiresAt = time.Now().Add(duration)
+	}
+	override := req.BudgetOverride
+	override.CreatedBy, _ = auth.AdminPrincipal(r.Context())
+	override.CreatedAt = time.Time{}
+
+	set, err := m.SetBudgetOverride(override)
+	if errors.Is(err, ErrBudgetNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeAdminJSON(w, http.StatusCreated, set)
+}
+
+// handleRemoveOverride removes a budget override.
+func (m *Manager) handleRemoveOverride(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	identifier := query.Get("identifier")
+	if identifier == "" {
+		http.Error(w, "identifier is required", http.StatusBadRequest)
+		return
+	}
+
+	removed, err := m.RemoveBudgetOverride(BudgetScope{
+		Dimension:  Dimension(query.Get("dimension")),
+		Identifier: identifier,
+	})
+	if errors.Is(err, ErrBudgetNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeAdminJSON(w, http.StatusOK, removed)
+}
+
+// resetRequest is the body of a reset request. If neither Budgets nor
+// RateLimits is set, both are reset.
+type resetRequest struct {
+	Dimension  Dimension `json:"dimension"`
+	Identifier string    `json:"identifier"`
+	Budgets    bool      `json:"budgets"`
+	RateLimits bool      `json:"rate_limits"`
+}
+
+// ResetHandler returns an HTTP handler that resets limit windows, e.g.
+// when mounted at /admin/limits/reset.
+//
+// POST clears the recorded usage of the scope in the JSON body and
+// responds with its usage after the reset.
+//
+// The handler must be served behind admin authentication.
+func (m *Manager) ResetHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := auth.AdminPrincipal(r.Context()); !ok {
+			http.Error(w, "admin authentication required", http.StatusUnauthorized)
+			return
+		}
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", "POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req resetRequest
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxAdminRequestSize)).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+			return
+		}
+		if req.Identifier == "" {
+			http.Error(w, "identifier is required", http.StatusBadRequest)
+			return
+		}
+		if !req.Budgets && !req.RateLimits {
+			req.Budgets, req.RateLimits = true, true
+		}
+
+		scope := BudgetScope{Dimension: req.Dimension, Identifier: req.Identifier}
+		err := m.ResetLimits(r.Context(), scope, req.Budgets, req.RateLimits)
+		if errors.Is(err, ErrScopeNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeAdminJSON(w, http.StatusOK, m.Usage(req.Dimension, req.Identifier))
+	})
+}
+
+func writeAdminJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,335 @@
+package limits
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/limits/budget"
+	"mercator-hq/jupiter/pkg/limits/enforcement"
+	"mercator-hq/jupiter/pkg/limits/ratelimit"
+	"mercator-hq/jupiter/pkg/security/auth"
+)
+
+func newAdminTestManager(t *testing.T) *Manager {
+	t.Helper()
+	manager := NewManager(Config{
+		RateLimits: map[string]ratelimit.Config{
+			"test-key": {TokensPerMinute: 1000, MaxConcurrent: 4},
+		},
+		Budgets: map[string]budget.Config{
+			"test-key": {Daily: 10.00},
+		},
+		ModelBudgets: map[string]map[string]budget.Config{
+			"test-key": {"o1": {Daily: 5.00}},
+		},
+		ScopeBudgets: map[BudgetScope]budget.Config{
+			{Dimension: DimensionTeam, Identifier: "platform"}: {Daily: 100.00},
+		},
+		Enforcement: enforcement.Config{DefaultAction: enforcement.ActionBlock},
+	})
+	t.Cleanup(func() { manager.Close() })
+	return manager
+}
+
+func TestManager_Usage(t *testing.T) {
+	manager := newAdminTestManager(t)
+	ctx := context.Background()
+
+	_ = manager.RecordUsage(ctx, &UsageRecord{Identifier: "test-key", Model: "o1", TotalTokens: 300, Cost: 4.00})
+
+	usages := manager.Usage("", "test-key")
+	if len(usages) != 2 {
+		t.Fatalf("Expected key and o1 usage, got %+v", usages)
+	}
+	key, model := usages[0], usages[1]
+	if key.Model != "" || model.Model != "o1" {
+		t.Fatalf("Expected key usage before model usage, got %+v", usages)
+	}
+	if len(key.Budgets) != 1 || key.Budgets[0].Window != "daily" || key.Budgets[0].Used != 4.00 {
+		t.Errorf("Unexpected key budgets: %+v", key.Budgets)
+	}
+	if key.RateLimits == nil || key.RateLimits.TokensPerMinute.Used != 300 || key.RateLimits.Concurrent.Limit != 4 {
+		t.Errorf("Unexpected key rate limits: %+v", key.RateLimits)
+	}
+
+	if all := manager.Usage("", ""); len(all) != 3 {
+		t.Errorf("Expected 3 scopes, got %d", len(all))
+	}
+	if team := manager.Usage(DimensionTeam, "platform"); len(team) != 1 || team[0].Dimension != DimensionTeam {
+		t.Errorf("Expected team usage, got %+v", team)
+	}
+}
+
+func TestManager_BudgetOverride(t *testing.T) {
+	manager := newAdminTestManager(t)
+	ctx := context.Background()
+
+	_ = manager.RecordUsage(ctx, &UsageRecord{Identifier: "test-key", Cost: 12.00})
+	if result, _ := manager.CheckLimits(ctx, "test-key", 0, 0, "gpt-4"); result.Allowed {
+		t.Fatal("Expected key to be blocked by its budget")
+	}
+
+	// Raising the budget unblocks the key until the override expires
+	_, err := manager.SetBudgetOverride(BudgetOverride{
+		Identifier: "test-key",
+		Daily:      50.00,
+		ExpiresAt:  time.Now().Add(100 * time.Millisecond),
+		Reason:     "INC-42",
+	})
+	if err != nil {
+		t.Fatalf("SetBudgetOverride failed: %v", err)
+	}
+	if result, _ := manager.CheckLimits(ctx, "test-key", 0, 0, "gpt-4"); !result.Allowed {
+		t.Errorf("Expected key to be allowed under the override, got %+v", result.Budget)
+	}
+	if overrides := manager.BudgetOverrides(); len(overrides) != 1 || overrides[0].Reason != "INC-42" {
+		t.Errorf("Expected one override, got %+v", overrides)
+	}
+
+	time.Sleep(200 * time.Millisecond)
+	if result, _ := manager.CheckLimits(ctx, "test-key", 0, 0, "gpt-4"); result.Allowed {
+		t.Error("Expected the configured budget to apply after the override expired")
+	}
+	if overrides := manager.BudgetOverrides(); len(overrides) != 0 {
+		t.Errorf("Expected expired override to be removed, got %+v", overrides)
+	}
+
+	// Overrides require a configured budget
+	_, err = manager.SetBudgetOverride(BudgetOverride{Identifier: "unknown", Daily: 1, ExpiresAt: time.Now().Add(time.Hour)})
+	if !errors.Is(err, ErrBudgetNotFound) {
+		t.Errorf("Expected ErrBudgetNotFound, got %v", err)
+	}
+}
+
+func TestManager_ResetLimits(t *testing.T) {
+	manager := newAdminTestManager(t)
+	ctx := context.Background()
+
+	_ = manager.RecordUsage(ctx, &UsageRecord{Identifier: "test-key", Model: "o1", TotalTokens: 900, Cost: 12.00})
+
+	if err := manager.ResetLimits(ctx, BudgetScope{Identifier: "test-key"}, true, false); err != nil {
+		t.Fatalf("ResetLimits failed: %v", err)
+	}
+	if result, _ := manager.CheckLimits(ctx, "test-key", 0, 0, "o1"); !result.Allowed {
+		t.Errorf("Expected key and model budgets to be reset, got %+v", result.Budget)
+	}
+	if result, _ := manager.CheckLimits(ctx, "test-key", 200, 0, "o1"); result.Allowed {
+		t.Error("Expected token usage to be kept when only budgets are reset")
+	}
+
+	if err := manager.ResetLimits(ctx, BudgetScope{Identifier: "test-key"}, false, true); err != nil {
+		t.Fatalf("ResetLimits failed: %v", err)
+	}
+	if result, _ := manager.CheckLimits(ctx, "test-key", 200, 0, "o1"); !result.Allowed {
+		t.Error("Expected token usage to be reset")
+	}
+
+	err := manager.ResetLimits(ctx, BudgetScope{Dimension: DimensionTeam, Identifier: "platform"}, false, true)
+	if !errors.Is(err, ErrScopeNotFound) {
+		t.Errorf("Expected ErrScopeNotFound for team rate limits, got %v", err)
+	}
+}
+
+func TestManager_Forecast(t *testing.T) {
+	manager := newAdminTestManager(t)
+	ctx := context.Background()
+
+	// 9 of the key's 10 daily budget spent within the last hour
+	_ = manager.RecordUsage(ctx, &UsageRecord{Identifier: "test-key", Cost: 9.00})
+
+	forecasts := manager.Forecast("", "test-key", budget.ForecastLinear)
+	if len(forecasts) != 2 || forecasts[0].Model != "" || forecasts[1].Model != "o1" {
+		t.Fatalf("Expected key and o1 forecasts, got %+v", forecasts)
+	}
+	key := forecasts[0]
+	if len(key.Budgets) != 1 || key.Budgets[0].Window != "daily" || key.Budgets[0].Used != 9.00 {
+		t.Fatalf("Unexpected key forecast: %+v", key.Budgets)
+	}
+	if !key.Exceeds() || key.Budgets[0].ProjectedPercentage <= 1 {
+		t.Errorf("Expected the key to be projected over budget, got %+v", key.Budgets[0])
+	}
+	if forecasts[1].Exceeds() {
+		t.Errorf("Expected the unused o1 budget not to be projected over budget")
+	}
+
+	if all := manager.Forecast("", "", budget.ForecastSeasonal); len(all) != 3 {
+		t.Errorf("Expected 3 scopes, got %d", len(all))
+	}
+
+	serve := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		manager.ForecastHandler().ServeHTTP(rec, req.WithContext(auth.WithAdminPrincipal(req.Context(), "finops@example.com")))
+		return rec
+	}
+	rec := serve("/?at_risk=true")
+	var atRisk []ScopeForecast
+	if err := json.Unmarshal(rec.Body.Bytes(), &atRisk); err != nil {
+		t.Fatalf("Failed to decode forecast: %v", err)
+	}
+	if len(atRisk) != 1 || atRisk[0].Identifier != "test-key" || atRisk[0].Budgets[0].ExceedsAt == nil {
+		t.Errorf("Expected only test-key at risk, got %+v", atRisk)
+	}
+	if rec := serve("/?method=exponential"); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid method status = %d, want 400", rec.Code)
+	}
+	if rec := serve("/?identifier=unknown"); rec.Code != http.StatusNotFound {
+		t.Errorf("unknown identifier status = %d, want 404", rec.Code)
+	}
+}
+
+func TestManager_UsageHistory(t *testing.T) {
+	manager := newAdminTestManager(t)
+	ctx := context.Background()
+
+	now := time.Now()
+	for _, record := range []*UsageRecord{
+		{Identifier: "test-key", RequestTokens: 100, ResponseTokens: 50, Cost: 0.5, Timestamp: now},
+		{Identifier: "test-key", RequestTokens: 10, ResponseTokens: 5, Cost: 0.25, Timestamp: now},
+		{Identifier: "test-key", RequestTokens: 1, ResponseTokens: 1, Cost: 0.125, Timestamp: now.Add(-2 * time.Hour)},
+		{Identifier: "other-key", RequestTokens: 1000, Timestamp: now},
+	} {
+		if err := manager.RecordUsage(ctx, record); err != nil {
+			t.Fatalf("RecordUsage failed: %v", err)
+		}
+	}
+
+	check := func(history *UsageHistory) {
+		t.Helper()
+		if len(history.Buckets) != 2 {
+			t.Fatalf("Expected 2 hourly buckets, got %+v", history.Buckets)
+		}
+		b := history.Buckets[1]
+		if b.Requests != 2 || b.PromptTokens != 110 || b.CompletionTokens != 55 || b.TotalTokens != 165 || b.Cost != 0.75 {
+			t.Errorf("Unexpected current hour usage: %+v", b)
+		}
+	}
+
+	// Usage not yet snapshotted is included, and not counted twice once it
+	// is
+	history, err := manager.UsageHistory(ctx, "", "test-key", GranularityHour, now.Add(-24*time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("UsageHistory failed: %v", err)
+	}
+	check(history)
+	if err := manager.Snapshot(ctx); err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	history, err = manager.UsageHistory(ctx, "", "test-key", GranularityHour, now.Add(-24*time.Hour), now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("UsageHistory failed: %v", err)
+	}
+	check(history)
+
+	serve := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		manager.UsageHistoryHandler().ServeHTTP(rec, req.WithContext(auth.WithAdminPrincipal(req.Context(), "platform@example.com")))
+		return rec
+	}
+	rec := serve("/?identifier=test-key&granularity=day")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("GET status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var daily UsageHistory
+	if err := json.Unmarshal(rec.Body.Bytes(), &daily); err != nil {
+		t.Fatalf("Failed to decode usage history: %v", err)
+	}
+	var requests int64
+	for _, b := range daily.Buckets {
+		requests += b.Requests
+		if !b.Start.Equal(b.Start.Truncate(24 * time.Hour)) {
+			t.Errorf("Daily bucket starts at %v, want a UTC day", b.Start)
+		}
+	}
+	if daily.Dimension != DimensionAPIKey || requests != 3 {
+		t.Errorf("Expected 3 api_key requests, got %d in %+v", requests, daily)
+	}
+
+	if rec := serve("/"); rec.Code != http.StatusBadRequest {
+		t.Errorf("missing identifier status = %d, want 400", rec.Code)
+	}
+	if rec := serve("/?identifier=test-key&granularity=minute"); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid granularity status = %d, want 400", rec.Code)
+	}
+	if rec := serve("/?identifier=test-key&start=2026-01-02T00:00:00Z&end=2026-01-01T00:00:00Z"); rec.Code != http.StatusBadRequest {
+		t.Errorf("inverted range status = %d, want 400", rec.Code)
+	}
+}
+
+func TestManager_AdminHandlers(t *testing.T) {
+	manager := newAdminTestManager(t)
+
+	serve := func(handler http.Handler, method, target, body string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		handler.ServeHTTP(rec, req.WithContext(auth.WithAdminPrincipal(req.Context(), "oncall@example.com")))
+		return rec
+	}
+
+	for _, handler := range []http.Handler{manager.UsageHandler(), manager.UsageHistoryHandler(), manager.ForecastHandler(), manager.OverridesHandler(), manager.ResetHandler()} {
+		unauthenticated := httptest.NewRecorder()
+		handler.ServeHTTP(unauthenticated, httptest.NewRequest(http.MethodGet, "/", nil))
+		if unauthenticated.Code != http.StatusUnauthorized {
+			t.Errorf("unauthenticated status = %d, want 401", unauthenticated.Code)
+		}
+	}
+
+	overrides := manager.OverridesHandler()
+	rec := serve(overrides, http.MethodPost, "/", `{"dimension": "team", "identifier": "platform", "daily": 500, "duration": "4h", "created_by": "someone-else"}`)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("POST status = %d: %s", rec.Code, rec.Body.String())
+	}
+	var override BudgetOverride
+	if err := json.Unmarshal(rec.Body.Bytes(), &override); err != nil {
+		t.Fatalf("Failed to decode override: %v", err)
+	}
+	if override.CreatedBy != "oncall@example.com" {
+		t.Errorf("CreatedBy = %q, want the authenticated principal", override.CreatedBy)
+	}
+	if until := time.Until(override.ExpiresAt); until < 3*time.Hour || until > 4*time.Hour {
+		t.Errorf("ExpiresAt = %v, want in 4h", override.ExpiresAt)
+	}
+	if rec := serve(overrides, http.MethodPost, "/", `{"identifier": "test-key", "daily": 5}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("POST without expiry status = %d, want 400", rec.Code)
+	}
+	if rec := serve(overrides, http.MethodPost, "/", `{"identifier": "unknown", "daily": 5, "duration": "1h"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("POST unknown budget status = %d, want 404", rec.Code)
+	}
+
+	rec = serve(manager.UsageHandler(), http.MethodGet, "/?dimension=team&identifier=platform", "")
+	var usages []ScopeUsage
+	if err := json.Unmarshal(rec.Body.Bytes(), &usages); err != nil {
+		t.Fatalf("Failed to decode usage: %v", err)
+	}
+	if len(usages) != 1 || usages[0].Override == nil || usages[0].Budgets[0].Limit != 500 {
+		t.Errorf("Expected team usage with the override, got %+v", usages)
+	}
+	if rec := serve(manager.UsageHandler(), http.MethodGet, "/?identifier=unknown", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("GET unknown status = %d, want 404", rec.Code)
+	}
+
+	if rec := serve(overrides, http.MethodDelete, "/?dimension=team&identifier=platform", ""); rec.Code != http.StatusOK {
+		t.Errorf("DELETE status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serve(overrides, http.MethodDelete, "/?dimension=team&identifier=platform", ""); rec.Code != http.StatusNotFound {
+		t.Errorf("second DELETE status = %d, want 404", rec.Code)
+	}
+
+	reset := manager.ResetHandler()
+	if rec := serve(reset, http.MethodPost, "/", `{"identifier": "test-key"}`); rec.Code != http.StatusOK {
+		t.Errorf("reset status = %d: %s", rec.Code, rec.Body.String())
+	}
+	if rec := serve(reset, http.MethodPost, "/", `{"identifier": "unknown"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("reset unknown status = %d, want 404", rec.Code)
+	}
+	if rec := serve(reset, http.MethodGet, "/", ""); rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET reset status = %d, want 405", rec.Code)
+	}
+}
@@ -0,0 +1,274 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strconv"
+	"strings"
+
+	"mercator-hq/jupiter/pkg/limits"
+	"mercator-hq/jupiter/pkg/policy/events"
+)
+
+// SlackChannel posts alerts to a Slack incoming webhook.
+type SlackChannel struct {
+	name   string
+	url    string
+	client *http.Client
+}
+
+// NewSlackChannel creates a channel posting to a Slack incoming webhook URL.
+func NewSlackChannel(name, url string) *SlackChannel {
+	return &SlackChannel{name: name, url: url, client: http.DefaultClient}
+}
+
+// Name returns the channel name.
+func (c *SlackChannel) Name() string {
+	return c.name
+}
+
+// Send posts the alert summary.
+func (c *SlackChannel) Send(ctx context.Context, alert *limits.BudgetAlert) error {
+	payload, err := json.Marshal(map[string]string{"text": alert.Summary()})
+	if err != nil {
+		return fmt.Errorf("failed to encode Slack message: %w", err)
+	}
+	return postJSON(ctx, c.client, c.url, payload, nil)
+}
+
+// DefaultPagerDutyURL is the PagerDuty Events API v2 endpoint.
+const DefaultPagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDutyConfig configures a PagerDutyChannel.
+type PagerDutyConfig struct {
+	// RoutingKey is the integration key of the PagerDuty service.
+	RoutingKey string
+
+	// URL is the Events API endpoint.
+	// Default: DefaultPagerDutyURL
+	URL string
+}
+
+// PagerDutyChannel triggers PagerDuty incidents through the Events API v2.
+// Alerts of the same budget window share a dedup key, so repeated alerts
+// update one incident. Warnings trigger with severity "warning", exceeded
+// budgets with severity "critical".
+type PagerDutyChannel struct {
+	name   string
+	config *PagerDutyConfig
+	client *http.Client
+}
+
+// NewPagerDutyChannel creates a PagerDuty channel.
+func NewPagerDutyChannel(name string, config *PagerDutyConfig) *PagerDutyChannel {
+	if config.URL == "" {
+		config.URL = DefaultPagerDutyURL
+	}
+	return &PagerDutyChannel{name: name, config: config, client: http.DefaultClient}
+}
+
+// Name returns the channel name.
+func (c *PagerDutyChannel) Name() string {
+	return c.name
+}
+
+// pagerDutyEvent is an Events API v2 event.
+type pagerDutyEvent struct {
+	RoutingKey  string           `json:"routing_key"`
+	EventAction string           `json:"event_action"`
+	DedupKey    string           `json:"dedup_key"`
+	Payload     pagerDutyPayload `json:"payload"`
+}
+
+type pagerDutyPayload struct {
+	Summary       string              `json:"summary"`
+	Source        string              `json:"source"`
+	Severity      string              `json:"severity"`
+	Component     string              `json:"component"`
+	CustomDetails *limits.BudgetAlert `json:"custom_details"`
+}
+
+// Send triggers an incident for the alert.
+func (c *PagerDutyChannel) Send(ctx context.Context, alert *limits.BudgetAlert) error {
+	severity := "warning"
+	switch alert.Level {
+	case limits.AlertExceeded:
+		severity = "critical"
+	case limits.AlertGrace:
+		severity = "error"
+	}
+
+	dedupKey := fmt.Sprintf("mercator-budget:%s:%s:%s", alert.Dimension, alert.Identifier, alert.Window)
+	if alert.Model != "" {
+		dedupKey += ":" + alert.Model
+	}
+
+	payload, err := json.Marshal(pagerDutyEvent{
+		RoutingKey:  c.config.RoutingKey,
+		EventAction: "trigger",
+		DedupKey:    dedupKey,
+		Payload: pagerDutyPayload{
+			Summary:       alert.Summary(),
+			Source:        "mercator",
+			Severity:      severity,
+			Component:     "limits",
+			CustomDetails: alert,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode PagerDuty event: %w", err)
+	}
+	return postJSON(ctx, c.client, c.config.URL, payload, nil)
+}
+
+// EmailConfig configures an EmailChannel.
+type EmailConfig struct {
+	// Host and Port address the SMTP server.
+	// Default port: 587
+	Host string
+	Port int
+
+	// Username and Password authenticate with PLAIN auth, if set.
+	Username string
+	Password string
+
+	// From is the sender address.
+	From string
+
+	// To are the recipient addresses.
+	To []string
+}
+
+// EmailChannel sends alerts by email over SMTP, using STARTTLS when the
+// server supports it.
+type EmailChannel struct {
+	name   string
+	config *EmailConfig
+
+	// sendMail sends a message; smtp.SendMail, replaced in tests.
+	sendMail func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewEmailChannel creates an email channel.
+func NewEmailChannel(name string, config *EmailConfig) *EmailChannel {
+	if config.Port == 0 {
+		config.Port = 587
+	}
+	return &EmailChannel{name: name, config: config, sendMail: smtp.SendMail}
+}
+
+// Name returns the channel name.
+func (c *EmailChannel) Name() string {
+	return c.name
+}
+
+// Send emails the alert to the recipients.
+func (c *EmailChannel) Send(ctx context.Context, alert *limits.BudgetAlert) error {
+	var body strings.Builder
+	fmt.Fprintf(&body, "%s\r\n\r\n", alert.Summary())
+	fmt.Fprintf(&body, "Scope: %s %s\r\n", alert.Dimension, alert.Identifier)
+	if alert.Model != "" {
+		fmt.Fprintf(&body, "Model: %s\r\n", alert.Model)
+	}
+	fmt.Fprintf(&body, "Window: %s\r\n", alert.Window)
+	fmt.Fprintf(&body, "Limit: $%.2f\r\n", alert.Limit)
+	fmt.Fprintf(&body, "Used: $%.2f (%.0f%%)\r\n", alert.Used, alert.Percentage*100)
+	fmt.Fprintf(&body, "Remaining: $%.2f\r\n", alert.Remaining)
+	fmt.Fprintf(&body, "Resets: %s\r\n", alert.Reset.UTC().Format("2006-01-02 15:04 MST"))
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", c.config.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(c.config.To, ", "))
+	fmt.Fprintf(&msg, "Subject: [Mercator] %s\r\n", alert.Summary())
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(body.String())
+
+	var auth smtp.Auth
+	if c.config.Username != "" {
+		auth = smtp.PlainAuth("", c.config.Username, c.config.Password, c.config.Host)
+	}
+	addr := net.JoinHostPort(c.config.Host, strconv.Itoa(c.config.Port))
+	if err := c.sendMail(addr, auth, c.config.From, c.config.To, msg.Bytes()); err != nil {
+		return fmt.Errorf("failed to send email via %s: %w", addr, err)
+	}
+	return nil
+}
+
+// WebhookConfig configures a WebhookChannel.
+type WebhookConfig struct {
+	// URL is the endpoint alerts are POSTed to.
+	URL string
+
+	// Headers are extra headers sent with each request.
+	Headers map[string]string
+
+	// Secret, if set, signs each payload with HMAC-SHA256 in the
+	// X-Mercator-Signature header (see events.Sign).
+	Secret string
+}
+
+// WebhookChannel POSTs each alert as a JSON document to an HTTP endpoint.
+type WebhookChannel struct {
+	name   string
+	config *WebhookConfig
+	client *http.Client
+}
+
+// NewWebhookChannel creates a webhook channel.
+func NewWebhookChannel(name string, config *WebhookConfig) *WebhookChannel {
+	return &WebhookChannel{name: name, config: config, client: http.DefaultClient}
+}
+
+// Name returns the channel name.
+func (c *WebhookChannel) Name() string {
+	return c.name
+}
+
+// Send POSTs the alert.
+func (c *WebhookChannel) Send(ctx context.Context, alert *limits.BudgetAlert) error {
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+
+	headers := make(map[string]string, len(c.config.Headers)+1)
+	for k, v := range c.config.Headers {
+		headers[k] = v
+	}
+	if c.config.Secret != "" {
+		headers[events.SignatureHeader] = events.Sign(c.config.Secret, payload)
+	}
+	return postJSON(ctx, c.client, c.config.URL, payload, headers)
+}
+
+// postJSON POSTs a JSON payload and treats any non-2xx status as an error.
+func postJSON(ctx context.Context, client *http.Client, url string, payload []byte, headers map[string]string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %w", req.URL.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("request to %s returned status %d: %s", req.URL.Redacted(), resp.StatusCode, bytes.TrimSpace(body))
+	}
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
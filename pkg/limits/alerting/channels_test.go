@@ -0,0 +1,148 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+
+	"mercator-hq/jupiter/pkg/limits"
+	"mercator-hq/jupiter/pkg/policy/events"
+)
+
+// captureServer records the body and headers of the last request.
+func captureServer(t *testing.T, status int) (*httptest.Server, *[]byte, *http.Header) {
+	t.Helper()
+	var body []byte
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header.Clone()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, &body, &header
+}
+
+func TestSlackChannel_Send(t *testing.T) {
+	server, body, _ := captureServer(t, http.StatusOK)
+	alert := testAlert(limits.AlertWarning, limits.DimensionTeam, "platform")
+
+	if err := NewSlackChannel("slack", server.URL).Send(context.Background(), &alert); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	var msg map[string]string
+	if err := json.Unmarshal(*body, &msg); err != nil {
+		t.Fatalf("Invalid Slack payload: %v", err)
+	}
+	if msg["text"] != alert.Summary() {
+		t.Errorf("text = %q, want %q", msg["text"], alert.Summary())
+	}
+}
+
+func TestPagerDutyChannel_Send(t *testing.T) {
+	server, body, _ := captureServer(t, http.StatusAccepted)
+	alert := testAlert(limits.AlertExceeded, limits.DimensionAPIKey, "sk-test")
+	alert.Model = "gpt-4"
+
+	channel := NewPagerDutyChannel("pagerduty", &PagerDutyConfig{RoutingKey: "key", URL: server.URL})
+	if err := channel.Send(context.Background(), &alert); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	var event pagerDutyEvent
+	if err := json.Unmarshal(*body, &event); err != nil {
+		t.Fatalf("Invalid PagerDuty event: %v", err)
+	}
+	if event.RoutingKey != "key" || event.EventAction != "trigger" {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+	if event.DedupKey != "mercator-budget:api_key:sk-test:daily:gpt-4" {
+		t.Errorf("dedup_key = %q", event.DedupKey)
+	}
+	if event.Payload.Severity != "critical" {
+		t.Errorf("severity = %q, want critical", event.Payload.Severity)
+	}
+}
+
+func TestWebhookChannel_Send(t *testing.T) {
+	server, body, header := captureServer(t, http.StatusOK)
+	alert := testAlert(limits.AlertWarning, limits.DimensionUser, "alice")
+
+	channel := NewWebhookChannel("hook", &WebhookConfig{
+		URL:     server.URL,
+		Headers: map[string]string{"X-Team": "finops"},
+		Secret:  "secret",
+	})
+	if err := channel.Send(context.Background(), &alert); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+
+	var got limits.BudgetAlert
+	if err := json.Unmarshal(*body, &got); err != nil {
+		t.Fatalf("Invalid webhook payload: %v", err)
+	}
+	if got.Identifier != "alice" || got.Level != limits.AlertWarning {
+		t.Errorf("Unexpected alert: %+v", got)
+	}
+	if header.Get("X-Team") != "finops" {
+		t.Errorf("Missing custom header")
+	}
+	if header.Get(events.SignatureHeader) != events.Sign("secret", *body) {
+		t.Errorf("Invalid signature %q", header.Get(events.SignatureHeader))
+	}
+}
+
+func TestWebhookChannel_ErrorStatus(t *testing.T) {
+	server, _, _ := captureServer(t, http.StatusInternalServerError)
+	alert := testAlert(limits.AlertWarning, limits.DimensionUser, "alice")
+
+	err := NewWebhookChannel("hook", &WebhookConfig{URL: server.URL}).Send(context.Background(), &alert)
+	if err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("Expected status error, got %v", err)
+	}
+}
+
+func TestEmailChannel_Send(t *testing.T) {
+	alert := testAlert(limits.AlertWarning, limits.DimensionTeam, "platform")
+
+	channel := NewEmailChannel("email", &EmailConfig{
+		Host:     "smtp.example.com",
+		Username: "mercator",
+		Password: "secret",
+		From:     "mercator@example.com",
+		To:       []string{"finops@example.com", "platform@example.com"},
+	})
+
+	var addr string
+	var to []string
+	var msg string
+	channel.sendMail = func(a string, auth smtp.Auth, from string, recipients []string, m []byte) error {
+		if auth == nil {
+			t.Error("Expected PLAIN auth")
+		}
+		addr, to, msg = a, recipients, string(m)
+		return nil
+	}
+
+	if err := channel.Send(context.Background(), &alert); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if addr != "smtp.example.com:587" {
+		t.Errorf("addr = %q, want default port 587", addr)
+	}
+	if len(to) != 2 {
+		t.Errorf("recipients = %v", to)
+	}
+	if !strings.Contains(msg, "Subject: [Mercator] "+alert.Summary()) {
+		t.Errorf("Missing subject in message:\n%s", msg)
+	}
+	if !strings.Contains(msg, "Scope: team platform") {
+		t.Errorf("Missing scope in message:\n%s", msg)
+	}
+}
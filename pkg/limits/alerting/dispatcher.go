@@ -0,0 +1,261 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"mercator-hq/jupiter/pkg/limits"
+)
+
+const (
+	// DefaultDedupInterval is the default interval between notifications
+	// of the same budget window and level.
+	DefaultDedupInterval = time.Hour
+
+	// DefaultQueueSize is the default number of alerts awaiting delivery.
+	DefaultQueueSize = 100
+
+	// DefaultTimeout is the default timeout of a delivery to one channel.
+	DefaultTimeout = 10 * time.Second
+)
+
+// Channel delivers alerts to a notification service.
+type Channel interface {
+	// Name identifies the channel in routes and logs.
+	Name() string
+
+	// Send delivers an alert.
+	Send(ctx context.Context, alert *limits.BudgetAlert) error
+}
+
+// Route sends the alerts it matches to channels. An alert matches if it
+// meets every criterion set; a route with no criteria matches every alert.
+type Route struct {
+	// Dimensions restricts the route to budgets of these dimensions.
+	Dimensions []limits.Dimension
+
+	// Identifiers restricts the route to budgets of these identifiers.
+	Identifiers []string
+
+	// Levels restricts the route to alerts of these levels.
+	Levels []limits.AlertLevel
+
+	// Channels are the names of the channels alerts are sent to.
+	Channels []string
+}
+
+// Matches reports whether an alert meets the route's criteria.
+func (r *Route) Matches(alert *limits.BudgetAlert) bool {
+	if len(r.Dimensions) > 0 && !slices.Contains(r.Dimensions, alert.Dimension) {
+		return false
+	}
+	if len(r.Identifiers) > 0 && !slices.Contains(r.Identifiers, alert.Identifier) {
+		return false
+	}
+	if len(r.Levels) > 0 && !slices.Contains(r.Levels, alert.Level) {
+		return false
+	}
+	return true
+}
+
+// Config configures a Dispatcher.
+type Config struct {
+	// Routes select the channels of each alert. An alert is sent to the
+	// channels of every route it matches, once per channel.
+	Routes []Route
+
+	// DedupInterval is the minimum interval between notifications of the
+	// same budget window and level.
+	// Default: DefaultDedupInterval
+	DedupInterval time.Duration
+
+	// QueueSize is the number of alerts awaiting delivery before new
+	// alerts are dropped.
+	// Default: DefaultQueueSize
+	QueueSize int
+
+	// Timeout bounds each delivery to a channel.
+	// Default: DefaultTimeout
+	Timeout time.Duration
+}
+
+// Dispatcher deduplicates budget alerts and delivers them to the channels
+// of their routes. It implements limits.AlertNotifier.
+//
+// Dispatcher is thread-safe.
+type Dispatcher struct {
+	config   Config
+	channels map[string]Channel
+	logger   *slog.Logger
+
+	mu sync.Mutex
+	// sent is when each budget window and level was last notified
+	sent map[alertKey]time.Time
+
+	queue chan limits.BudgetAlert
+	done  chan struct{}
+	wg    sync.WaitGroup
+
+	closeOnce sync.Once
+}
+
+// alertKey identifies the alerts of one budget window and level.
+type alertKey struct {
+	dimension  limits.Dimension
+	identifier string
+	model      string
+	window     string
+	level      limits.AlertLevel
+}
+
+// NewDispatcher creates a dispatcher delivering to channels. It returns an
+// error if a route refers to a channel that does not exist.
+func NewDispatcher(config Config, channels ...Channel) (*Dispatcher, error) {
+	if config.DedupInterval <= 0 {
+		config.DedupInterval = DefaultDedupInterval
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = DefaultQueueSize
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+
+	byName := make(map[string]Channel, len(channels))
+	for _, channel := range channels {
+		if _, ok := byName[channel.Name()]; ok {
+			return nil, fmt.Errorf("duplicate alert channel %q", channel.Name())
+		}
+		byName[channel.Name()] = channel
+	}
+	for i, route := range config.Routes {
+		for _, name := range route.Channels {
+			if _, ok := byName[name]; !ok {
+				return nil, fmt.Errorf("route %d: unknown alert channel %q", i, name)
+			}
+		}
+	}
+
+	d := &Dispatcher{
+		config:   config,
+		channels: byName,
+		logger:   slog.Default().With("component", "limits"),
+		sent:     make(map[alertKey]time.Time),
+		queue:    make(chan limits.BudgetAlert, config.QueueSize),
+		done:     make(chan struct{}),
+	}
+	d.wg.Add(1)
+	go d.loop()
+	return d, nil
+}
+
+// NotifyBudgetAlert queues an alert for delivery unless the same budget
+// window and level was notified within the dedup interval. It does not
+// block.
+func (d *Dispatcher) NotifyBudgetAlert(alert limits.BudgetAlert) {
+	key := alertKey{
+		dimension:  alert.Dimension,
+		identifier: alert.Identifier,
+		model:      alert.Model,
+		window:     alert.Window,
+		level:      alert.Level,
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if last, ok := d.sent[key]; ok && alert.Time.Sub(last) < d.config.DedupInterval {
+		return
+	}
+
+	select {
+	case <-d.done:
+		return
+	default:
+	}
+	select {
+	case d.queue <- alert:
+		d.sent[key] = alert.Time
+	default:
+		d.logger.Warn("budget alert dropped, queue full",
+			"dimension", alert.Dimension,
+			"identifier", alert.Identifier,
+			"window", alert.Window,
+			"level", alert.Level,
+		)
+	}
+}
+
+// Close stops the dispatcher after delivering the queued alerts.
+func (d *Dispatcher) Close() error {
+	d.closeOnce.Do(func() {
+		d.mu.Lock()
+		close(d.done)
+		d.mu.Unlock()
+		d.wg.Wait()
+	})
+	return nil
+}
+
+// loop delivers queued alerts until the dispatcher is closed.
+func (d *Dispatcher) loop() {
+	defer d.wg.Done()
+
+	for {
+		select {
+		case alert := <-d.queue:
+			d.deliver(&alert)
+		case <-d.done:
+			// Drain alerts queued before Close
+			for {
+				select {
+				case alert := <-d.queue:
+					d.deliver(&alert)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+// deliver sends an alert to the channels of the routes it matches.
+func (d *Dispatcher) deliver(alert *limits.BudgetAlert) {
+	var names []string
+	for i := range d.config.Routes {
+		route := &d.config.Routes[i]
+		if !route.Matches(alert) {
+			continue
+		}
+		for _, name := range route.Channels {
+			if !slices.Contains(names, name) {
+				names = append(names, name)
+			}
+		}
+	}
+
+	d.logger.Warn("budget alert",
+		"summary", alert.Summary(),
+		"channels", names,
+	)
+
+	for _, name := range names {
+		ctx, cancel := context.WithTimeout(context.Background(), d.config.Timeout)
+		err := d.channels[name].Send(ctx, alert)
+		cancel()
+		if err != nil {
+			d.logger.Error("failed to deliver budget alert",
+				"channel", name,
+				"dimension", alert.Dimension,
+				"identifier", alert.Identifier,
+				"window", alert.Window,
+				"level", alert.Level,
+				"error", err,
+			)
+		}
+	}
+}
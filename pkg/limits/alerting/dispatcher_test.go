@@ -0,0 +1,215 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/limits"
+	"mercator-hq/jupiter/pkg/limits/budget"
+)
+
+// recordingChannel records the alerts it is sent.
+type recordingChannel struct {
+	name string
+	err  error
+
+	mu     sync.Mutex
+	alerts []limits.BudgetAlert
+}
+
+func (c *recordingChannel) Name() string {
+	return c.name
+}
+
+func (c *recordingChannel) Send(ctx context.Context, alert *limits.BudgetAlert) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.alerts = append(c.alerts, *alert)
+	return c.err
+}
+
+func (c *recordingChannel) sent() []limits.BudgetAlert {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]limits.BudgetAlert(nil), c.alerts...)
+}
+
+func testAlert(level limits.AlertLevel, dimension limits.Dimension, identifier string) limits.BudgetAlert {
+	return limits.BudgetAlert{
+		Level:      level,
+		Dimension:  dimension,
+		Identifier: identifier,
+		Window:     "daily",
+		Limit:      100,
+		Used:       85,
+		Remaining:  15,
+		Percentage: 0.85,
+		Threshold:  0.8,
+		Time:       time.Now(),
+	}
+}
+
+func TestDispatcher_RoutesAlerts(t *testing.T) {
+	finops := &recordingChannel{name: "finops"}
+	oncall := &recordingChannel{name: "oncall"}
+
+	dispatcher, err := NewDispatcher(Config{
+		Routes: []Route{
+			{Dimensions: []limits.Dimension{limits.DimensionTeam}, Channels: []string{"finops"}},
+			{Levels: []limits.AlertLevel{limits.AlertExceeded}, Channels: []string{"oncall", "finops"}},
+		},
+	}, finops, oncall)
+	if err != nil {
+		t.Fatalf("NewDispatcher failed: %v", err)
+	}
+
+	dispatcher.NotifyBudgetAlert(testAlert(limits.AlertWarning, limits.DimensionTeam, "platform"))
+	dispatcher.NotifyBudgetAlert(testAlert(limits.AlertWarning, limits.DimensionAPIKey, "sk-test"))
+	dispatcher.NotifyBudgetAlert(testAlert(limits.AlertExceeded, limits.DimensionTeam, "platform"))
+	dispatcher.Close()
+
+	// finops: team warning, team exceeded (once, though matched twice)
+	if sent := finops.sent(); len(sent) != 2 || sent[0].Level != limits.AlertWarning || sent[1].Level != limits.AlertExceeded {
+		t.Errorf("finops alerts = %+v, want team warning and exceeded", sent)
+	}
+	if sent := oncall.sent(); len(sent) != 1 || sent[0].Identifier != "platform" {
+		t.Errorf("oncall alerts = %+v, want the exceeded team budget", sent)
+	}
+}
+
+func TestDispatcher_Deduplicates(t *testing.T) {
+	channel := &recordingChannel{name: "hook", err: errors.New("unavailable")}
+	dispatcher, err := NewDispatcher(Config{
+		Routes:        []Route{{Channels: []string{"hook"}}},
+		DedupInterval: time.Hour,
+	}, channel)
+	if err != nil {
+		t.Fatalf("NewDispatcher failed: %v", err)
+	}
+
+	alert := testAlert(limits.AlertWarning, limits.DimensionAPIKey, "sk-test")
+	for i := 0; i < 10; i++ {
+		dispatcher.NotifyBudgetAlert(alert)
+	}
+
+	// Another window and a new level are notified separately
+	monthly := alert
+	monthly.Window = "monthly"
+	dispatcher.NotifyBudgetAlert(monthly)
+	exceeded := alert
+	exceeded.Level = limits.AlertExceeded
+	dispatcher.NotifyBudgetAlert(exceeded)
+
+	// Notified again once the dedup interval has passed
+	later := alert
+	later.Time = alert.Time.Add(time.Hour)
+	dispatcher.NotifyBudgetAlert(later)
+	dispatcher.Close()
+
+	if sent := channel.sent(); len(sent) != 4 {
+		t.Errorf("Expected 4 alerts after deduplication, got %d: %+v", len(sent), sent)
+	}
+}
+
+func TestNewDispatcher_UnknownChannel(t *testing.T) {
+	_, err := NewDispatcher(Config{Routes: []Route{{Channels: []string{"missing"}}}})
+	if err == nil {
+		t.Error("Expected error for route to unknown channel")
+	}
+}
+
+func TestManager_NotifiesBudgetAlerts(t *testing.T) {
+	channel := &recordingChannel{name: "hook"}
+	dispatcher, err := NewDispatcher(Config{Routes: []Route{{Channels: []string{"hook"}}}}, channel)
+	if err != nil {
+		t.Fatalf("NewDispatcher failed: %v", err)
+	}
+
+	manager := limits.NewManager(limits.Config{
+		Budgets: map[string]budget.Config{
+			"sk-test": {Daily: 10, AlertThreshold: 0.8},
+		},
+		AlertNotifier: dispatcher,
+	})
+
+	ctx := context.Background()
+	record := func(cost float64) {
+		_ = manager.RecordUsage(ctx, &limits.UsageRecord{Identifier: "sk-test", Dimension: limits.DimensionAPIKey, Cost: cost})
+	}
+	check := func() {
+		if _, err := manager.CheckLimits(ctx, "sk-test", 0, 0, "gpt-4"); err != nil {
+			t.Fatalf("CheckLimits failed: %v", err)
+		}
+	}
+
+	check()
+	record(8.5)
+	check()
+	check()
+	record(2)
+	check()
+
+	// Closing the manager closes the dispatcher, delivering queued alerts
+	manager.Close()
+
+	sent := channel.sent()
+	if len(sent) != 2 {
+		t.Fatalf("Expected a warning and an exceeded alert, got %+v", sent)
+	}
+	if sent[0].Level != limits.AlertWarning || sent[0].Window != "daily" || sent[0].Threshold != 0.8 {
+		t.Errorf("Unexpected warning: %+v", sent[0])
+	}
+	if sent[1].Level != limits.AlertExceeded || sent[1].Used != 10.5 {
+		t.Errorf("Unexpected exceeded alert: %+v", sent[1])
+	}
+}
+
+func TestManager_NotifiesGraceAlerts(t *testing.T) {
+	channel := &recordingChannel{name: "hook"}
+	dispatcher, err := NewDispatcher(Config{Routes: []Route{{Channels: []string{"hook"}}}}, channel)
+	if err != nil {
+		t.Fatalf("NewDispatcher failed: %v", err)
+	}
+
+	manager := limits.NewManager(limits.Config{
+		Budgets: map[string]budget.Config{
+			"sk-test": {Daily: 10, AlertThreshold: 0.8, GraceOverage: 0.1},
+		},
+		AlertNotifier: dispatcher,
+	})
+
+	ctx := context.Background()
+	record := func(cost float64) {
+		_ = manager.RecordUsage(ctx, &limits.UsageRecord{Identifier: "sk-test", Dimension: limits.DimensionAPIKey, Cost: cost})
+	}
+	check := func(wantAllowed bool) {
+		result, err := manager.CheckLimits(ctx, "sk-test", 0, 0, "gpt-4")
+		if err != nil {
+			t.Fatalf("CheckLimits failed: %v", err)
+		}
+		if result.Allowed != wantAllowed {
+			t.Fatalf("Allowed = %v, want %v: %s", result.Allowed, wantAllowed, result.Reason)
+		}
+	}
+
+	record(10.5)
+	check(true)
+	record(1)
+	check(false)
+
+	manager.Close()
+
+	sent := channel.sent()
+	if len(sent) != 2 {
+		t.Fatalf("Expected a grace and an exceeded alert, got %+v", sent)
+	}
+	if sent[0].Level != limits.AlertGrace || sent[0].Used != 10.5 {
+		t.Errorf("Unexpected grace alert: %+v", sent[0])
+	}
+	if sent[1].Level != limits.AlertExceeded || sent[1].Used != 11.5 {
+		t.Errorf("Unexpected exceeded alert: %+v", sent[1])
+	}
+}
@@ -0,0 +1,42 @@
+// Package alerting delivers budget alerts to notification channels.
+//
+// The Dispatcher implements limits.AlertNotifier. The limits manager raises
+// an alert for every request checked while a budget is past its alert
+// threshold or limit; the dispatcher deduplicates them, so each budget
+// window notifies once per level (warning, exceeded) per dedup interval,
+// routes them to channels by scope, and delivers them asynchronously: a
+// slow or unavailable channel never delays requests, and alerts that do not
+// fit in the queue are dropped and logged.
+//
+// # Channels
+//
+//   - SlackChannel posts the alert summary to a Slack incoming webhook.
+//   - PagerDutyChannel triggers a PagerDuty incident through the Events API
+//     v2, deduplicated per budget window.
+//   - EmailChannel sends the alert by email over SMTP.
+//   - WebhookChannel POSTs the alert as JSON, optionally signed with
+//     HMAC-SHA256 in the X-Mercator-Signature header.
+//
+// Custom channels implement the Channel interface.
+//
+// # Usage
+//
+//	dispatcher, err := alerting.NewDispatcher(alerting.Config{
+//	    Routes: []alerting.Route{
+//	        {Dimensions: []limits.Dimension{limits.DimensionTeam}, Channels: []string{"finops"}},
+//	        {Levels: []limits.AlertLevel{limits.AlertExceeded}, Channels: []string{"oncall"}},
+//	    },
+//	},
+//	    alerting.NewSlackChannel("finops", "https://hooks.slack.com/services/..."),
+//	    alerting.NewPagerDutyChannel("oncall", &alerting.PagerDutyConfig{RoutingKey: key}),
+//	)
+//	if err != nil {
+//	    return err
+//	}
+//	defer dispatcher.Close()
+//
+//	manager := limits.NewManager(limits.Config{
+//	    Budgets:       budgets,
+//	    AlertNotifier: dispatcher,
+//	})
+package alerting
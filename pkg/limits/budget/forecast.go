@@ -0,0 +1,189 @@
+package budget
+
+import "time"
+
+// ForecastMethod selects how future spending is projected.
+type ForecastMethod string
+
+const (
+	// ForecastLinear projects the average hourly spending observed in the
+	// window forward.
+	ForecastLinear ForecastMethod = "linear"
+
+	// ForecastSeasonal projects each future bucket from the buckets one or
+	// more seasonal periods earlier: the same hour of the previous day for
+	// daily windows, the same weekday of previous weeks for monthly
+	// windows. Windows too short for a seasonal period, and buckets without
+	// an observed period, use the linear projection.
+	ForecastSeasonal ForecastMethod = "seasonal"
+)
+
+// Forecast is the projected spending of a budget window.
+type Forecast struct {
+	// Method is the projection method used.
+	Method ForecastMethod
+
+	// Window is the window duration.
+	Window time.Duration
+
+	// Limit is the budget limit of the window in USD.
+	Limit float64
+
+	// Used is the spending currently in the window in USD.
+	Used float64
+
+	// RunRate is the average spending in USD per hour since the oldest
+	// spending in the window.
+	RunRate float64
+
+	// Projected is the spending projected for the next full window, i.e.
+	// what the window will hold once the current spending has left it.
+	Projected float64
+
+	// ExceedsAt is when the spending in the window is projected to exceed
+	// Limit; zero if it is not projected to within the next window.
+	ExceedsAt time.Time
+}
+
+// Exceeds reports whether the window is projected to exceed its limit.
+func (f *Forecast) Exceeds() bool {
+	return !f.ExceedsAt.IsZero()
+}
+
+// Forecast projects the spending of the window over the next window
+// length with method, and when it will exceed limit.
+func (rw *RollingWindow) Forecast(limit float64, method ForecastMethod) *Forecast {
+	now := time.Now()
+	buckets := rw.Buckets()
+
+	forecast := &Forecast{
+		Method: ForecastLinear,
+		Window: rw.window,
+		Limit:  limit,
+	}
+	for _, b := range buckets {
+		forecast.Used += b.Amount
+	}
+	if len(buckets) == 0 {
+		return forecast
+	}
+
+	// Average the spending over the time it has been observed
+	observed := now.Sub(buckets[0].Start)
+	if observed < rw.bucketSize {
+		observed = rw.bucketSize
+	}
+	forecast.RunRate = forecast.Used / observed.Hours()
+	linear := forecast.RunRate * rw.bucketSize.Hours()
+
+	period := seasonalPeriod(rw.window)
+	if method == ForecastSeasonal && period > 0 {
+		forecast.Method = ForecastSeasonal
+	}
+
+	amounts := make(map[int64]float64, len(buckets))
+	for _, b := range buckets {
+		amounts[b.Start.UnixNano()] = b.Amount
+	}
+
+	// Step through the buckets of the next window. At the end of each, the
+	// window holds the current buckets that have not left it yet and the
+	// projected spending so far.
+	current := now.Truncate(rw.bucketSize)
+	steps := int(rw.window / rw.bucketSize)
+	if steps == 0 {
+		steps = 1
+	}
+	exceeded := forecast.Used > limit
+	if exceeded {
+		forecast.ExceedsAt = now
+	}
+	for i := 1; i <= steps; i++ {
+		start := current.Add(time.Duration(i) * rw.bucketSize)
+
+		projected := linear
+		if forecast.Method == ForecastSeasonal {
+			if amount, ok := seasonalAmount(amounts, start, period, current, buckets[0].Start, now.Add(-rw.window)); ok {
+				projected = amount
+			}
+		}
+		forecast.Projected += projected
+
+		if exceeded {
+			continue
+		}
+		end := start.Add(rw.bucketSize)
+		sum := forecast.Projected
+		for _, b := range buckets {
+			if !b.Start.Before(end.Add(-rw.window)) {
+				sum += b.Amount
+			}
+		}
+		if sum > limit {
+			forecast.ExceedsAt = end
+			exceeded = true
+		}
+	}
+	return forecast
+}
+
+// seasonalPeriod returns the seasonal period of a window: a week for
+// windows of at least a week, a day for windows of at least a day, and 0
+// for shorter windows.
+func seasonalPeriod(window time.Duration) time.Duration {
+	switch {
+	case window >= 7*24*time.Hour:
+		return 7 * 24 * time.Hour
+	case window >= 24*time.Hour:
+		return 24 * time.Hour
+	default:
+		return 0
+	}
+}
+
+// seasonalAmount averages the spending of the buckets whole periods before
+// start, up to the current bucket, counting buckets without spending since
+// observed as zero. It returns false if no such bucket was observed within
+// the window, i.e. at or after both observed and cutoff.
+func seasonalAmount(amounts map[int64]float64, start time.Time, period time.Duration, current, observed, cutoff time.Time) (float64, bool) {
+	past := start.Add(-period)
+	for past.After(current) {
+		past = past.Add(-period)
+	}
+
+	var sum float64
+	var count int
+	for ; !past.Before(observed) && !past.Before(cutoff); past = past.Add(-period) {
+		sum += amounts[past.UnixNano()]
+		count++
+	}
+	if count == 0 {
+		return 0, false
+	}
+	return sum / float64(count), true
+}
+
+// Forecast projects the spending of each configured window with method,
+// shortest window first.
+func (t *Tracker) Forecast(method ForecastMethod) []*Forecast {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	windows := []struct {
+		limit  float64
+		window *RollingWindow
+	}{
+		{t.config.Hourly, t.hourly},
+		{t.config.Daily, t.daily},
+		{t.config.Monthly, t.monthly},
+	}
+
+	var forecasts []*Forecast
+	for _, w := range windows {
+		if w.limit == 0 || w.window == nil {
+			continue
+		}
+		forecasts = append(forecasts, w.window.Forecast(w.limit, method))
+	}
+	return forecasts
+}
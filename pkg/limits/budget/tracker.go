@@ -16,6 +16,13 @@ import (
 // The tracker can trigger alerts when spending reaches a percentage of
 // the configured limit. Alerts are detected during Check() and indicated
 // in the returned Status.
+//
+// # Grace Overage
+//
+// With a grace overage, spending may exceed a limit by that fraction of
+// the limit before requests are rejected, so a usage spike does not block
+// requests at exactly 100%. Spending within the grace overage is reported
+// with Status.InGrace.
 type Tracker struct {
 	config Config
 
@@ -27,6 +34,9 @@ type Tracker struct {
 	// Total spending (all-time, not windowed)
 	totalSpent float64
 
+	// Total spending not yet taken by TakePending
+	pendingTotal float64
+
 	mu sync.RWMutex
 }
 
@@ -88,6 +98,7 @@ func (t *Tracker) Add(amount float64) {
 
 	// Add to total
 	t.totalSpent += amount
+	t.pendingTotal += amount
 }
 
 // Check verifies if spending is within all configured budget limits.
@@ -95,6 +106,10 @@ func (t *Tracker) Add(amount float64) {
 // Returns Status indicating if spending is allowed and which limit (if any)
 // was exceeded. Also indicates if alert threshold was reached.
 //
+// Spending over a limit but within the grace overage is allowed, with
+// InGrace and AlertTriggered set; spending is only rejected once it
+// exceeds the limit plus the grace overage.
+//
 // If multiple limits are exceeded, the most restrictive (shortest window)
 // is returned.
 func (t *Tracker) Check() *Status {
@@ -103,100 +118,22 @@ func (t *Tracker) Check() *Status {
 
 	// Check hourly limit first (most restrictive)
 	if t.config.Hourly > 0 && t.hourly != nil {
-		used := t.hourly.Sum()
-		percentage := used / t.config.Hourly
-
-		if used > t.config.Hourly {
-			return &Status{
-				Allowed:    false,
-				Reason:     "hourly budget limit exceeded",
-				Limit:      t.config.Hourly,
-				Used:       used,
-				Remaining:  0,
-				Percentage: percentage,
-				Reset:      t.calculateReset(t.hourly),
-				Window:     time.Hour,
-			}
-		}
-
-		// Check alert threshold
-		if t.config.AlertThreshold > 0 && percentage >= t.config.AlertThreshold {
-			return &Status{
-				Allowed:        true,
-				Limit:          t.config.Hourly,
-				Used:           used,
-				Remaining:      t.config.Hourly - used,
-				Percentage:     percentage,
-				Reset:          t.calculateReset(t.hourly),
-				Window:         time.Hour,
-				AlertTriggered: true,
-			}
+		if status := t.checkWindow(t.hourly, t.config.Hourly); status != nil {
+			return status
 		}
 	}
 
 	// Check daily limit
 	if t.config.Daily > 0 && t.daily != nil {
-		used := t.daily.Sum()
-		percentage := used / t.config.Daily
-
-		if used > t.config.Daily {
-			return &Status{
-				Allowed:    false,
-				Reason:     "daily budget limit exceeded",
-				Limit:      t.config.Daily,
-				Used:       used,
-				Remaining:  0,
-				Percentage: percentage,
-				Reset:      t.calculateReset(t.daily),
-				Window:     24 * time.Hour,
-			}
-		}
-
-		// Check alert threshold
-		if t.config.AlertThreshold > 0 && percentage >= t.config.AlertThreshold {
-			return &Status{
-				Allowed:        true,
-				Limit:          t.config.Daily,
-				Used:           used,
-				Remaining:      t.config.Daily - used,
-				Percentage:     percentage,
-				Reset:          t.calculateReset(t.daily),
-				Window:         24 * time.Hour,
-				AlertTriggered: true,
-			}
+		if status := t.checkWindow(t.daily, t.config.Daily); status != nil {
+			return status
 		}
 	}
 
 	// Check monthly limit
 	if t.config.Monthly > 0 && t.monthly != nil {
-		used := t.monthly.Sum()
-		percentage := used / t.config.Monthly
-
-		if used > t.config.Monthly {
-			return &Status{
-				Allowed:    false,
-				Reason:     "monthly budget limit exceeded",
-				Limit:      t.config.Monthly,
-				Used:       used,
-				Remaining:  0,
-				Percentage: percentage,
-				Reset:      t.calculateReset(t.monthly),
-				Window:     30 * 24 * time.Hour,
-			}
-		}
-
-		// Check alert threshold
-		if t.config.AlertThreshold > 0 && percentage >= t.config.AlertThreshold {
-			return &Status{
-				Allowed:        true,
-				Limit:          t.config.Monthly,
-				Used:           used,
-				Remaining:      t.config.Monthly - used,
-				Percentage:     percentage,
-				Reset:          t.calculateReset(t.monthly),
-				Window:         30 * 24 * time.Hour,
-				AlertTriggered: true,
-			}
+		if status := t.checkWindow(t.monthly, t.config.Monthly); status != nil {
+			return status
 		}
 	}
 
@@ -206,20 +143,99 @@ func (t *Tracker) Check() *Status {
 	}
 }
 
+// checkWindow checks the spending in one window against limit. It returns
+// nil if spending is below both the limit and the alert threshold.
+// Caller must hold read lock.
+func (t *Tracker) checkWindow(window *RollingWindow, limit float64) *Status {
+	used := window.Sum()
+	percentage := used / limit
+	name := WindowName(window.window)
+
+	if used > t.hardLimit(limit) {
+		return &Status{
+			Allowed:    false,
+			Reason:     name + " budget limit exceeded",
+			Limit:      limit,
+			Used:       used,
+			Remaining:  0,
+			Percentage: percentage,
+			Reset:      t.calculateReset(window),
+			Window:     window.window,
+		}
+	}
+
+	// Over the limit, within the grace overage
+	if used > limit {
+		return &Status{
+			Allowed:        true,
+			Reason:         name + " budget limit exceeded, within grace overage",
+			Limit:          limit,
+			Used:           used,
+			Remaining:      0,
+			Percentage:     percentage,
+			Reset:          t.calculateReset(window),
+			Window:         window.window,
+			AlertTriggered: true,
+			InGrace:        true,
+		}
+	}
+
+	// Check alert threshold
+	if t.config.AlertThreshold > 0 && percentage >= t.config.AlertThreshold {
+		return &Status{
+			Allowed:        true,
+			Limit:          limit,
+			Used:           used,
+			Remaining:      limit - used,
+			Percentage:     percentage,
+			Reset:          t.calculateReset(window),
+			Window:         window.window,
+			AlertTriggered: true,
+		}
+	}
+
+	return nil
+}
+
+// hardLimit returns the spending above which limit rejects requests: the
+// limit plus the grace overage.
+func (t *Tracker) hardLimit(limit float64) float64 {
+	return limit * (1 + t.config.GraceOverage)
+}
+
+// TimeUntilAllowed returns how long until spending is within every
+// configured limit again, as spending leaves the rolling windows.
+func (t *Tracker) TimeUntilAllowed() time.Duration {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	var wait time.Duration
+	if t.config.Hourly > 0 && t.hourly != nil {
+		wait = maxDuration(wait, t.hourly.TimeUntilWithin(t.hardLimit(t.config.Hourly)))
+	}
+	if t.config.Daily > 0 && t.daily != nil {
+		wait = maxDuration(wait, t.daily.TimeUntilWithin(t.hardLimit(t.config.Daily)))
+	}
+	if t.config.Monthly > 0 && t.monthly != nil {
+		wait = maxDuration(wait, t.monthly.TimeUntilWithin(t.hardLimit(t.config.Monthly)))
+	}
+	return wait
+}
+
 // GetHourlyStatus returns the current hourly budget status.
 func (t *Tracker) GetHourlyStatus() *Status {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
 	if t.config.Hourly == 0 || t.hourly == nil {
 		return &Status{Allowed: true}
 	}
 
-	t.mu.RLock()
-	defer t.mu.RUnlock()
-
 	used := t.hourly.Sum()
 	percentage := used / t.config.Hourly
 
 	return &Status{
-		Allowed:        used <= t.config.Hourly,
+		Allowed:        used <= t.hardLimit(t.config.Hourly),
 		Limit:          t.config.Hourly,
 		Used:           used,
 		Remaining:      max(0, t.config.Hourly-used),
@@ -227,23 +243,24 @@ func (t *Tracker) GetHourlyStatus() *Status {
 		Reset:          t.calculateReset(t.hourly),
 		Window:         time.Hour,
 		AlertTriggered: t.config.AlertThreshold > 0 && percentage >= t.config.AlertThreshold,
+		InGrace:        used > t.config.Hourly && used <= t.hardLimit(t.config.Hourly),
 	}
 }
 
 // GetDailyStatus returns the current daily budget status.
 func (t *Tracker) GetDailyStatus() *Status {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
 	if t.config.Daily == 0 || t.daily == nil {
 		return &Status{Allowed: true}
 	}
 
-	t.mu.RLock()
-	defer t.mu.RUnlock()
-
 	used := t.daily.Sum()
 	percentage := used / t.config.Daily
 
 	return &Status{
-		Allowed:        used <= t.config.Daily,
+		Allowed:        used <= t.hardLimit(t.config.Daily),
 		Limit:          t.config.Daily,
 		Used:           used,
 		Remaining:      max(0, t.config.Daily-used),
@@ -251,23 +268,24 @@ func (t *Tracker) GetDailyStatus() *Status {
 		Reset:          t.calculateReset(t.daily),
 		Window:         24 * time.Hour,
 		AlertTriggered: t.config.AlertThreshold > 0 && percentage >= t.config.AlertThreshold,
+		InGrace:        used > t.config.Daily && used <= t.hardLimit(t.config.Daily),
 	}
 }
 
 // GetMonthlyStatus returns the current monthly budget status.
 func (t *Tracker) GetMonthlyStatus() *Status {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
 	if t.config.Monthly == 0 || t.monthly == nil {
 		return &Status{Allowed: true}
 	}
 
-	t.mu.RLock()
-	defer t.mu.RUnlock()
-
 	used := t.monthly.Sum()
 	percentage := used / t.config.Monthly
 
 	return &Status{
-		Allowed:        used <= t.config.Monthly,
+		Allowed:        used <= t.hardLimit(t.config.Monthly),
 		Limit:          t.config.Monthly,
 		Used:           used,
 		Remaining:      max(0, t.config.Monthly-used),
@@ -275,6 +293,7 @@ func (t *Tracker) GetMonthlyStatus() *Status {
 		Reset:          t.calculateReset(t.monthly),
 		Window:         30 * 24 * time.Hour,
 		AlertTriggered: t.config.AlertThreshold > 0 && percentage >= t.config.AlertThreshold,
+		InGrace:        used > t.config.Monthly && used <= t.hardLimit(t.config.Monthly),
 	}
 }
 
@@ -285,8 +304,33 @@ func (t *Tracker) GetTotalSpent() float64 {
 	return t.totalSpent
 }
 
+// Config returns the tracker's limits.
+func (t *Tracker) Config() Config {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.config
+}
+
+// SetLimits replaces the tracker's limits, keeping the spending recorded
+// so far. Windows of newly configured limits start empty; windows of
+// removed limits are kept, so restoring the limits restores their usage.
+func (t *Tracker) SetLimits(config Config) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.config = config
+	if config.Hourly > 0 && t.hourly == nil {
+		t.hourly = NewRollingWindow(time.Hour, time.Minute)
+	}
+	if config.Daily > 0 && t.daily == nil {
+		t.daily = NewRollingWindow(24*time.Hour, time.Hour)
+	}
+	if config.Monthly > 0 && t.monthly == nil {
+		t.monthly = NewRollingWindow(30*24*time.Hour, 24*time.Hour)
+	}
+}
+
 // Reset clears all windows and resets total spent to zero.
-// This is primarily for testing.
 func (t *Tracker) Reset() {
 	t.mu.Lock()
 	defer t.mu.Unlock()
@@ -302,6 +346,83 @@ func (t *Tracker) Reset() {
 	}
 
 	t.totalSpent = 0
+	t.pendingTotal = 0
+}
+
+// Usage returns a snapshot of all spending in the configured windows.
+func (t *Tracker) Usage() *Usage {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	usage := &Usage{Total: t.totalSpent}
+	if t.hourly != nil {
+		usage.Hourly = t.hourly.Buckets()
+	}
+	if t.daily != nil {
+		usage.Daily = t.daily.Buckets()
+	}
+	if t.monthly != nil {
+		usage.Monthly = t.monthly.Buckets()
+	}
+	return usage
+}
+
+// TakePending returns the spending added since the previous call and
+// clears it. Persisting only this delta lets several proxy replicas add
+// their spending to shared state.
+func (t *Tracker) TakePending() *Usage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	usage := &Usage{Total: t.pendingTotal}
+	t.pendingTotal = 0
+	if t.hourly != nil {
+		usage.Hourly = t.hourly.TakePending()
+	}
+	if t.daily != nil {
+		usage.Daily = t.daily.TakePending()
+	}
+	if t.monthly != nil {
+		usage.Monthly = t.monthly.TakePending()
+	}
+	return usage
+}
+
+// RequeuePending returns spending taken by TakePending that could not be
+// persisted, so that the next TakePending includes it again.
+func (t *Tracker) RequeuePending(usage *Usage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.pendingTotal += usage.Total
+	if t.hourly != nil {
+		t.hourly.RequeuePending(usage.Hourly)
+	}
+	if t.daily != nil {
+		t.daily.RequeuePending(usage.Daily)
+	}
+	if t.monthly != nil {
+		t.monthly.RequeuePending(usage.Monthly)
+	}
+}
+
+// Restore replaces the tracked spending with usage, typically loaded or
+// merged from storage. Spending recorded since the last TakePending is
+// kept on top of it.
+func (t *Tracker) Restore(usage *Usage) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.totalSpent = usage.Total + t.pendingTotal
+	if t.hourly != nil {
+		t.hourly.Restore(usage.Hourly)
+	}
+	if t.daily != nil {
+		t.daily.Restore(usage.Daily)
+	}
+	if t.monthly != nil {
+		t.monthly.Restore(usage.Monthly)
+	}
 }
 
 // calculateReset estimates when the rolling window will reset.
@@ -318,6 +439,14 @@ func (t *Tracker) calculateReset(window *RollingWindow) time.Time {
 	return oldest.Add(window.window)
 }
 
+// maxDuration returns the longer of two durations.
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
+
 // max returns the maximum of two float64 values.
 func max(a, b float64) float64 {
 	if a > b {
@@ -1,6 +1,7 @@
 package budget
 
 import (
+	"math"
 	"sync"
 	"testing"
 	"time"
@@ -287,6 +288,47 @@ func TestTracker_AlertThreshold(t *testing.T) {
 	}
 }
 
+func TestTracker_GraceOverage(t *testing.T) {
+	tracker := NewTracker(Config{
+		Daily:          100.00,
+		AlertThreshold: 0.8,
+		GraceOverage:   0.05, // Block at $105
+	})
+
+	tracker.Add(103.00)
+	status := tracker.Check()
+	if !status.Allowed {
+		t.Fatalf("Expected spending within the grace overage to be allowed: %s", status.Reason)
+	}
+	if !status.InGrace || !status.AlertTriggered {
+		t.Errorf("Expected grace alert, got InGrace=%v AlertTriggered=%v", status.InGrace, status.AlertTriggered)
+	}
+	if status.Remaining != 0 {
+		t.Errorf("Expected 0 remaining, got %.2f", status.Remaining)
+	}
+	if status.Reason != "daily budget limit exceeded, within grace overage" {
+		t.Errorf("Unexpected reason: %s", status.Reason)
+	}
+	if daily := tracker.GetDailyStatus(); !daily.Allowed || !daily.InGrace {
+		t.Errorf("Expected daily status in grace, got %+v", daily)
+	}
+	if wait := tracker.TimeUntilAllowed(); wait != 0 {
+		t.Errorf("Expected no wait within the grace overage, got %v", wait)
+	}
+
+	tracker.Add(3.00) // $106
+	status = tracker.Check()
+	if status.Allowed || status.InGrace {
+		t.Error("Expected spending beyond the grace overage to be rejected")
+	}
+	if status.Reason != "daily budget limit exceeded" {
+		t.Errorf("Unexpected reason: %s", status.Reason)
+	}
+	if tracker.GetDailyStatus().Allowed {
+		t.Error("Expected daily status to be rejected")
+	}
+}
+
 func TestTracker_GetHourlyStatus(t *testing.T) {
 	tracker := NewTracker(Config{
 		Hourly: 10.00,
@@ -502,6 +544,195 @@ func TestTracker_ExceededStatusFields(t *testing.T) {
 	}
 }
 
+// ============================================================================
+// Persistence Tests
+// ============================================================================
+
+func TestTracker_TakePending(t *testing.T) {
+	tracker := NewTracker(Config{Hourly: 100, Daily: 1000})
+	tracker.Add(10)
+	tracker.Add(5)
+
+	pending := tracker.TakePending()
+	if pending.Total != 15 {
+		t.Errorf("Expected pending total 15, got %.2f", pending.Total)
+	}
+	if len(pending.Hourly) != 1 || pending.Hourly[0].Amount != 15 {
+		t.Errorf("Expected one hourly bucket of 15, got %+v", pending.Hourly)
+	}
+	if len(pending.Daily) != 1 || pending.Daily[0].Amount != 15 {
+		t.Errorf("Expected one daily bucket of 15, got %+v", pending.Daily)
+	}
+	if pending.Monthly != nil {
+		t.Errorf("Expected no monthly buckets without a monthly limit, got %+v", pending.Monthly)
+	}
+
+	// Taken spending is not returned again, but still counts
+	if again := tracker.TakePending(); again.Total != 0 || len(again.Hourly) != 0 {
+		t.Errorf("Expected no pending spending, got %+v", again)
+	}
+	if used := tracker.GetHourlyStatus().Used; used != 15 {
+		t.Errorf("Expected 15 used, got %.2f", used)
+	}
+
+	// Requeued spending is returned by the next take
+	tracker.RequeuePending(pending)
+	tracker.Add(1)
+	if again := tracker.TakePending(); again.Total != 16 || again.Hourly[0].Amount != 16 {
+		t.Errorf("Expected requeued pending total 16, got %+v", again)
+	}
+}
+
+func TestTracker_Restore(t *testing.T) {
+	tracker := NewTracker(Config{Hourly: 100, Daily: 1000})
+	tracker.Add(10)
+	pending := tracker.TakePending()
+
+	// Spending recorded while the snapshot is in flight
+	tracker.Add(2)
+
+	// Merged state includes the taken spending and another replica's
+	merged := &Usage{
+		Hourly: []Bucket{{Start: pending.Hourly[0].Start, Amount: 10 + 30}},
+		Daily:  []Bucket{{Start: pending.Daily[0].Start, Amount: 10 + 30}},
+		Total:  10 + 30,
+	}
+	tracker.Restore(merged)
+
+	if used := tracker.GetHourlyStatus().Used; used != 42 {
+		t.Errorf("Expected 42 used after restore, got %.2f", used)
+	}
+	if used := tracker.GetDailyStatus().Used; used != 42 {
+		t.Errorf("Expected 42 used after restore, got %.2f", used)
+	}
+	if total := tracker.GetTotalSpent(); total != 42 {
+		t.Errorf("Expected total 42 after restore, got %.2f", total)
+	}
+	if again := tracker.TakePending(); again.Total != 2 {
+		t.Errorf("Expected in-flight spending to stay pending, got %.2f", again.Total)
+	}
+}
+
+func TestTracker_RestoreIgnoresExpiredBuckets(t *testing.T) {
+	tracker := NewTracker(Config{Hourly: 100})
+	now := time.Now()
+	tracker.Restore(&Usage{
+		Hourly: []Bucket{
+			{Start: now.Add(-2 * time.Hour).Truncate(time.Minute), Amount: 50},
+			{Start: now.Add(-10 * time.Minute).Truncate(time.Minute), Amount: 5},
+		},
+		Total: 55,
+	})
+
+	if used := tracker.GetHourlyStatus().Used; used != 5 {
+		t.Errorf("Expected only the bucket within the window, got %.2f used", used)
+	}
+	usage := tracker.Usage()
+	if len(usage.Hourly) != 1 || usage.Total != 55 {
+		t.Errorf("Expected one hourly bucket and total 55, got %+v", usage)
+	}
+}
+
+func TestTracker_TimeUntilAllowed(t *testing.T) {
+	tracker := NewTracker(Config{Hourly: 10, Daily: 100})
+	if wait := tracker.TimeUntilAllowed(); wait != 0 {
+		t.Errorf("Expected no wait without spending, got %v", wait)
+	}
+
+	now := time.Now()
+	oldest := now.Add(-50 * time.Minute).Truncate(time.Minute)
+	tracker.Restore(&Usage{
+		Hourly: []Bucket{
+			{Start: oldest, Amount: 8},
+			{Start: now.Add(-10 * time.Minute).Truncate(time.Minute), Amount: 5},
+		},
+		Total: 13,
+	})
+
+	// Spending is within the limit once the oldest bucket leaves the window
+	want := time.Until(oldest.Add(time.Hour))
+	if wait := tracker.TimeUntilAllowed(); wait < want-time.Second || wait > want {
+		t.Errorf("Expected to wait %v, got %v", want, wait)
+	}
+}
+
+func TestRollingWindow_ForecastLinear(t *testing.T) {
+	rw := NewRollingWindow(24*time.Hour, time.Hour)
+	now := time.Now()
+	start := now.Truncate(time.Hour).Add(-11 * time.Hour)
+	rw.Restore([]Bucket{{Start: start, Amount: 33}})
+
+	forecast := rw.Forecast(50, ForecastLinear)
+	if forecast.Method != ForecastLinear || forecast.Used != 33 {
+		t.Fatalf("Unexpected forecast: %+v", forecast)
+	}
+
+	// 33 over 11-12 hours
+	wantRate := 33 / now.Sub(start).Hours()
+	if math.Abs(forecast.RunRate-wantRate) > 0.01 {
+		t.Errorf("RunRate = %.3f, want %.3f", forecast.RunRate, wantRate)
+	}
+	if math.Abs(forecast.Projected-wantRate*24) > 0.1 {
+		t.Errorf("Projected = %.2f, want %.2f", forecast.Projected, wantRate*24)
+	}
+	if !forecast.Exceeds() || !forecast.ExceedsAt.After(now) || forecast.ExceedsAt.After(start.Add(24*time.Hour)) {
+		t.Errorf("Expected to exceed before the spending leaves the window, got %v", forecast.ExceedsAt)
+	}
+
+	if forecast := rw.Forecast(100, ForecastLinear); forecast.Exceeds() {
+		t.Errorf("Expected not to exceed 100, got %v", forecast.ExceedsAt)
+	}
+	if forecast := rw.Forecast(20, ForecastLinear); !forecast.Exceeds() || forecast.ExceedsAt.After(time.Now()) {
+		t.Errorf("Expected a window already over its limit to exceed now, got %v", forecast.ExceedsAt)
+	}
+}
+
+func TestRollingWindow_ForecastSeasonal(t *testing.T) {
+	rw := NewRollingWindow(30*24*time.Hour, 24*time.Hour)
+	current := time.Now().Truncate(24 * time.Hour)
+
+	// Spending on the same weekday of each of the last four weeks
+	var buckets []Bucket
+	for week := 4; week >= 1; week-- {
+		buckets = append(buckets, Bucket{Start: current.Add(-time.Duration(week) * 7 * 24 * time.Hour), Amount: 10})
+	}
+	rw.Restore(buckets)
+
+	forecast := rw.Forecast(1000, ForecastSeasonal)
+	if forecast.Method != ForecastSeasonal {
+		t.Fatalf("Method = %s, want seasonal", forecast.Method)
+	}
+
+	// That weekday falls on 4 of the next 30 days, each projected from five
+	// weeks of which four had spending
+	if math.Abs(forecast.Projected-4*8) > 0.001 {
+		t.Errorf("Projected = %.2f, want 32", forecast.Projected)
+	}
+	if linear := rw.Forecast(1000, ForecastLinear); math.Abs(linear.Projected-forecast.Projected) < 1 {
+		t.Errorf("Expected linear projection to differ, got %.2f", linear.Projected)
+	}
+
+	// Hourly windows are too short for seasonality
+	hourly := NewRollingWindow(time.Hour, time.Minute)
+	hourly.Add(1)
+	if forecast := hourly.Forecast(10, ForecastSeasonal); forecast.Method != ForecastLinear {
+		t.Errorf("Method = %s, want linear for hourly windows", forecast.Method)
+	}
+}
+
+func TestTracker_Forecast(t *testing.T) {
+	tracker := NewTracker(Config{Daily: 100, Monthly: 1000})
+	tracker.Add(5)
+
+	forecasts := tracker.Forecast(ForecastLinear)
+	if len(forecasts) != 2 || forecasts[0].Window != 24*time.Hour || forecasts[1].Window != 30*24*time.Hour {
+		t.Fatalf("Expected daily and monthly forecasts, got %+v", forecasts)
+	}
+	if forecasts[0].Used != 5 {
+		t.Errorf("Used = %.2f, want 5", forecasts[0].Used)
+	}
+}
+
 // ============================================================================
 // Benchmarks
 // ============================================================================
@@ -16,6 +16,26 @@ type Config struct {
 	// AlertThreshold is the percentage (0.0-1.0) at which to trigger alerts.
 	// For example, 0.8 means alert when 80% of budget is used.
 	AlertThreshold float64
+
+	// GraceOverage is the fraction (0.0-1.0) of each limit that spending
+	// may exceed it by before requests are rejected. For example, 0.05
+	// allows spending up to 105% of the budget, with warnings.
+	GraceOverage float64
+}
+
+// WindowName returns the name of a budget window: "hourly", "daily" or
+// "monthly", or the duration for other windows.
+func WindowName(window time.Duration) string {
+	switch window {
+	case time.Hour:
+		return "hourly"
+	case 24 * time.Hour:
+		return "daily"
+	case 30 * 24 * time.Hour:
+		return "monthly"
+	default:
+		return window.String()
+	}
 }
 
 // Status contains the current budget status for a time window.
@@ -23,7 +43,8 @@ type Status struct {
 	// Allowed indicates if spending is within the budget.
 	Allowed bool
 
-	// Reason explains why spending was rejected (if Allowed=false).
+	// Reason explains why spending was rejected (if Allowed=false) or is
+	// over the limit within the grace overage (if InGrace=true).
 	Reason string
 
 	// Limit is the configured budget limit in USD.
@@ -46,4 +67,33 @@ type Status struct {
 
 	// AlertTriggered indicates if the alert threshold was reached.
 	AlertTriggered bool
+
+	// InGrace indicates that spending exceeded the limit but is still
+	// within the grace overage, so spending is allowed.
+	InGrace bool
+}
+
+// Usage is a snapshot of the spending recorded by a Tracker. It is used to
+// persist budget state and to share it between proxy replicas.
+type Usage struct {
+	// Hourly contains the buckets of the hourly window.
+	Hourly []Bucket
+
+	// Daily contains the buckets of the daily window.
+	Daily []Bucket
+
+	// Monthly contains the buckets of the monthly window.
+	Monthly []Bucket
+
+	// Total is the all-time spending in USD.
+	Total float64
+}
+
+// Bucket is the spending within one bucket of a rolling window.
+type Bucket struct {
+	// Start is when the bucket started.
+	Start time.Time
+
+	// Amount is the spending in USD within the bucket.
+	Amount float64
 }
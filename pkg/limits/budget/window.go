@@ -1,6 +1,7 @@
 package budget
 
 import (
+	"sort"
 	"sync"
 	"time"
 )
@@ -23,9 +24,10 @@ import (
 //
 // RollingWindow is thread-safe using sync.RWMutex.
 type RollingWindow struct {
-	window     time.Duration // Total window duration
-	bucketSize time.Duration // Granularity of each bucket
-	buckets    []bucket      // Circular buffer of buckets
+	window     time.Duration     // Total window duration
+	bucketSize time.Duration     // Granularity of each bucket
+	buckets    []bucket          // Circular buffer of buckets
+	pending    map[int64]float64 // Spending not yet taken by TakePending, by bucket start (UnixNano)
 	mu         sync.RWMutex
 }
 
@@ -80,6 +82,11 @@ func (rw *RollingWindow) Add(amount float64) {
 	// Find or create current bucket
 	currentBucket := rw.findOrCreateBucketLocked(now)
 	currentBucket.amount += amount
+
+	if rw.pending == nil {
+		rw.pending = make(map[int64]float64)
+	}
+	rw.pending[currentBucket.timestamp.UnixNano()] += amount
 }
 
 // Sum returns the total spending across all buckets in the window.
@@ -113,6 +120,104 @@ func (rw *RollingWindow) Reset() {
 	for i := 0; i < len(rw.buckets); i++ {
 		rw.buckets[i] = bucket{}
 	}
+	rw.pending = nil
+}
+
+// Buckets returns the non-empty buckets within the window, oldest first.
+func (rw *RollingWindow) Buckets() []Bucket {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	rw.pruneLocked(time.Now())
+
+	var buckets []Bucket
+	for i := 0; i < len(rw.buckets); i++ {
+		if !rw.buckets[i].timestamp.IsZero() {
+			buckets = append(buckets, Bucket{Start: rw.buckets[i].timestamp, Amount: rw.buckets[i].amount})
+		}
+	}
+	sortBuckets(buckets)
+	return buckets
+}
+
+// TakePending returns the spending added since the previous call, by
+// bucket, and clears it. It lets a caller persist only new spending.
+func (rw *RollingWindow) TakePending() []Bucket {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	rw.pruneLocked(time.Now())
+
+	buckets := make([]Bucket, 0, len(rw.pending))
+	for start, amount := range rw.pending {
+		buckets = append(buckets, Bucket{Start: time.Unix(0, start), Amount: amount})
+	}
+	rw.pending = nil
+	sortBuckets(buckets)
+	return buckets
+}
+
+// RequeuePending returns spending taken by TakePending that could not be
+// persisted, so that the next TakePending includes it again.
+func (rw *RollingWindow) RequeuePending(buckets []Bucket) {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	if len(buckets) > 0 && rw.pending == nil {
+		rw.pending = make(map[int64]float64)
+	}
+	for _, b := range buckets {
+		rw.pending[b.Start.UnixNano()] += b.Amount
+	}
+	rw.pruneLocked(time.Now())
+}
+
+// Restore replaces the window contents with buckets, typically loaded from
+// storage, and then adds the pending spending that buckets does not include
+// yet. Buckets outside the window are ignored.
+func (rw *RollingWindow) Restore(buckets []Bucket) {
+	rw.mu.Lock()
+	defer rw.mu.Unlock()
+
+	for i := 0; i < len(rw.buckets); i++ {
+		rw.buckets[i] = bucket{}
+	}
+
+	cutoff := time.Now().Add(-rw.window)
+	for _, b := range buckets {
+		if b.Start.Before(cutoff) {
+			continue
+		}
+		rw.findOrCreateBucketLocked(b.Start).amount += b.Amount
+	}
+	for start, amount := range rw.pending {
+		rw.findOrCreateBucketLocked(time.Unix(0, start)).amount += amount
+	}
+}
+
+// TimeUntilWithin returns how long until the spending in the window is
+// within limit, as buckets leave the window.
+func (rw *RollingWindow) TimeUntilWithin(limit float64) time.Duration {
+	buckets := rw.Buckets()
+
+	var sum float64
+	for _, b := range buckets {
+		sum += b.Amount
+	}
+
+	now := time.Now()
+	var wait time.Duration
+	for _, b := range buckets {
+		if sum <= limit {
+			break
+		}
+		sum -= b.Amount
+		wait = b.Start.Add(rw.window).Sub(now)
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return wait
 }
 
 // OldestTimestamp returns the timestamp of the oldest bucket in the window.
@@ -143,6 +248,18 @@ func (rw *RollingWindow) pruneLocked(now time.Time) {
 			rw.buckets[i] = bucket{} // Clear expired bucket
 		}
 	}
+	for start := range rw.pending {
+		if time.Unix(0, start).Before(cutoff) {
+			delete(rw.pending, start)
+		}
+	}
+}
+
+// sortBuckets sorts buckets by start time, oldest first.
+func sortBuckets(buckets []Bucket) {
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Start.Before(buckets[j].Start)
+	})
 }
 
 // findOrCreateBucketLocked finds the bucket for the current time or creates a new one.
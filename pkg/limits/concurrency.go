@@ -0,0 +1,123 @@
+package limits
+
+import (
+	"context"
+	"time"
+)
+
+// ConcurrencyLimits are concurrency limits that come with a request rather
+// than being configured for its identifier, such as those stored with an
+// API key in the key store. They are enforced in addition to the
+// MaxConcurrent and MaxConcurrentStreams rate limits of the identifier.
+// Zero means no limit.
+type ConcurrencyLimits struct {
+	// MaxConcurrent limits the identifier's simultaneous in-flight
+	// requests.
+	MaxConcurrent int
+
+	// MaxConcurrentStreams limits the identifier's simultaneous streaming
+	// requests. Streams also count towards MaxConcurrent.
+	MaxConcurrentStreams int
+}
+
+// AcquireConcurrency acquires the concurrency slots of a request: a
+// concurrent request slot and, for streaming requests, a streaming
+// connection slot, under both the rate limits configured for identifier and
+// keyLimits. It returns ErrConcurrencyLimitExceeded or
+// ErrStreamLimitExceeded if a limit is reached, in which case no slots are
+// held.
+//
+// If this returns nil, the caller MUST call ReleaseConcurrency with the
+// same arguments when done.
+func (m *Manager) AcquireConcurrency(identifier string, stream bool, keyLimits ConcurrencyLimits) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	rateLimiter := m.getRateLimiter(identifier)
+
+	// Slots acquired so far, released if a later limit is reached
+	var acquired []func()
+	fail := func(err error) error {
+		for i := len(acquired) - 1; i >= 0; i-- {
+			acquired[i]()
+		}
+		return err
+	}
+
+	if rateLimiter != nil {
+		if !rateLimiter.AcquireConcurrent() {
+			return ErrConcurrencyLimitExceeded
+		}
+		acquired = append(acquired, rateLimiter.ReleaseConcurrent)
+	}
+	if keyLimits.MaxConcurrent > 0 {
+		if !m.keyConcurrent.Acquire(identifier, keyLimits.MaxConcurrent) {
+			return fail(ErrConcurrencyLimitExceeded)
+		}
+		acquired = append(acquired, func() { m.keyConcurrent.Release(identifier) })
+	}
+	if !stream {
+		return nil
+	}
+
+	if rateLimiter != nil {
+		if !rateLimiter.AcquireStream() {
+			return fail(ErrStreamLimitExceeded)
+		}
+		acquired = append(acquired, rateLimiter.ReleaseStream)
+	}
+	if keyLimits.MaxConcurrentStreams > 0 {
+		if !m.keyStreams.Acquire(identifier, keyLimits.MaxConcurrentStreams) {
+			return fail(ErrStreamLimitExceeded)
+		}
+	}
+	return nil
+}
+
+// ReleaseConcurrency releases the concurrency slots of a request.
+// This MUST be called after a successful AcquireConcurrency() or
+// WaitForConcurrency(), with the same arguments.
+func (m *Manager) ReleaseConcurrency(identifier string, stream bool, keyLimits ConcurrencyLimits) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	rateLimiter := m.getRateLimiter(identifier)
+	if stream {
+		if keyLimits.MaxConcurrentStreams > 0 {
+			m.keyStreams.Release(identifier)
+		}
+		if rateLimiter != nil {
+			rateLimiter.ReleaseStream()
+		}
+	}
+	if keyLimits.MaxConcurrent > 0 {
+		m.keyConcurrent.Release(identifier)
+	}
+	if rateLimiter != nil {
+		rateLimiter.ReleaseConcurrent()
+	}
+	m.queue.Notify()
+}
+
+// WaitForConcurrency acquires the concurrency slots of a request like
+// AcquireConcurrency. With the queue enforcement action, a request over a
+// concurrency limit waits in the queue for slots to be released. It
+// returns the error of the first acquisition attempt if no slots were
+// acquired.
+//
+// If this returns nil, the caller MUST call ReleaseConcurrency with the
+// same arguments when done.
+func (m *Manager) WaitForConcurrency(ctx context.Context, identifier string, stream bool, keyLimits ConcurrencyLimits) error {
+	err := m.AcquireConcurrency(identifier, stream, keyLimits)
+	if err == nil || m.queue == nil {
+		return err
+	}
+
+	waitErr := m.queue.Wait(ctx, m.queuePriority(ctx, identifier), identifier, 0, func() (bool, time.Duration) {
+		return m.AcquireConcurrency(identifier, stream, keyLimits) == nil, 0
+	})
+	if waitErr != nil {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package limits
+
+import "context"
+
+// contextKey is the type of the context keys of this package.
+type contextKey string
+
+// costTagKey is the context key of the cost allocation tag.
+const costTagKey contextKey = "cost_tag"
+
+// WithCostTag returns a copy of ctx carrying the cost allocation tag of a
+// request: the cost center or project its spending is charged to. The
+// proxy sets it once the tag is validated, so that usage records and
+// evidence records are attributed to it.
+func WithCostTag(ctx context.Context, tag string) context.Context {
+	return context.WithValue(ctx, costTagKey, tag)
+}
+
+// CostTagFromContext returns the cost allocation tag of ctx, or "" if the
+// request is untagged.
+func CostTagFromContext(ctx context.Context) string {
+	tag, _ := ctx.Value(costTagKey).(string)
+	return tag
+}
@@ -5,10 +5,15 @@
 // The limits package implements multi-dimensional budget tracking and rate limiting
 // to prevent cost overruns and enforce usage quotas. It supports:
 //
-//   - Budget tracking (per-API key, per-user, per-team)
+//   - Budget tracking (per-API key, per-user, per-team, per-org)
+//   - Hierarchical budgets: a request must pass the budgets of its key's
+//     user, team and org
+//   - Per-model limits and budgets for an identifier's use of a model
 //   - Rate limiting (request-based, token-based, concurrent)
 //   - Rolling time windows (hourly, daily, monthly)
 //   - Enforcement actions (block, queue, downgrade, alert)
+//   - Admin API for usage, spend forecasts, temporary budget overrides and
+//     window resets
 //
 // # Architecture
 //
@@ -18,6 +23,7 @@
 //   - budget: Rolling window budget tracking
 //   - storage: Persistence backends (memory, SQLite, PostgreSQL)
 //   - enforcement: Enforcement action execution
+//   - alerting: Budget alert notifications (Slack, PagerDuty, email, webhook)
 //
 // # Usage
 //
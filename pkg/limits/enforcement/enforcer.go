@@ -33,10 +33,10 @@ func NewEnforcer(config Config) *Enforcer {
 		config.DefaultAction = ActionBlock
 	}
 	if config.QueueDepth == 0 {
-		config.QueueDepth = 100
+		config.QueueDepth = DefaultQueueDepth
 	}
 	if config.QueueTimeout == 0 {
-		config.QueueTimeout = 30 * time.Second
+		config.QueueTimeout = DefaultQueueTimeout
 	}
 	if config.ModelDowngrades == nil {
 		config.ModelDowngrades = make(map[string]string)
@@ -102,12 +102,9 @@ func (e *Enforcer) enforceBlock(reason string, retryAfter time.Duration) *Result
 	}
 }
 
-// enforceQueue queues the request until capacity is available.
-// Note: Actual queuing logic would be implemented in the Manager.
-// This just indicates that queuing should be attempted.
+// enforceQueue indicates that the request should wait in a Queue until
+// capacity is available. The waiting itself is done by the caller.
 func (e *Enforcer) enforceQueue(ctx context.Context, reason string, retryAfter time.Duration) *Result {
-	// For now, return a result indicating queuing should be attempted.
-	// The actual queue implementation would be in the Manager.
 	return &Result{
 		Allowed:    false, // Not immediately allowed
 		Action:     ActionQueue,
@@ -2,6 +2,8 @@ package enforcement
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"testing"
 	"time"
 )
@@ -179,3 +181,204 @@ func TestEnforcer_InvalidAction(t *testing.T) {
 		t.Errorf("Expected fallback to Block action, got %s", result.Action)
 	}
 }
+
+// waitAsync starts a queued wait and returns its result channel.
+func waitAsync(q *Queue, priority int, tenant string, try TryFunc) <-chan error {
+	done := make(chan error, 1)
+	go func() { done <- q.Wait(context.Background(), priority, tenant, 0, try) }()
+	return done
+}
+
+// waitForLen waits until the queue holds n requests.
+func waitForLen(t *testing.T, q *Queue, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for q.Len() != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected %d queued requests, got %d", n, q.Len())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestQueue_AdmitsByPriority(t *testing.T) {
+	queue := NewQueue(10, time.Second)
+	defer queue.Close()
+
+	var (
+		mu       sync.Mutex
+		capacity int
+	)
+	try := func() (bool, time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		if capacity > 0 {
+			capacity--
+			return true, 0
+		}
+		return false, 0
+	}
+
+	low := waitAsync(queue, 0, "", try)
+	waitForLen(t, queue, 1)
+	high := waitAsync(queue, 10, "", try)
+	waitForLen(t, queue, 2)
+
+	// One slot frees up: the later, higher priority request gets it
+	mu.Lock()
+	capacity = 1
+	mu.Unlock()
+	queue.Notify()
+
+	select {
+	case err := <-high:
+		if err != nil {
+			t.Fatalf("Expected high priority request to be admitted, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected high priority request to be admitted")
+	}
+	select {
+	case err := <-low:
+		t.Fatalf("Expected low priority request to keep waiting, got %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	queue.Close()
+	if err := <-low; !errors.Is(err, ErrQueueClosed) {
+		t.Errorf("Expected ErrQueueClosed, got %v", err)
+	}
+}
+
+func TestQueue_FairShareWithinPriority(t *testing.T) {
+	queue := NewQueue(10, time.Second)
+	defer queue.Close()
+
+	var (
+		mu       sync.Mutex
+		capacity int
+	)
+	try := func() (bool, time.Duration) {
+		mu.Lock()
+		defer mu.Unlock()
+		if capacity > 0 {
+			capacity--
+			return true, 0
+		}
+		return false, 0
+	}
+
+	// Tenant a queues three requests before tenant b queues one
+	var a []<-chan error
+	for i := 0; i < 3; i++ {
+		a = append(a, waitAsync(queue, 0, "a", try))
+		waitForLen(t, queue, i+1)
+	}
+	b := waitAsync(queue, 0, "b", try)
+	waitForLen(t, queue, 4)
+
+	// Two slots free up: b takes turns with a rather than waiting for all
+	// of a's requests
+	mu.Lock()
+	capacity = 2
+	mu.Unlock()
+	queue.Notify()
+
+	for _, done := range []<-chan error{a[0], b} {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("Expected request to be admitted, got %v", err)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Expected a's first and b's request to be admitted")
+		}
+	}
+	if n := queue.Len(); n != 2 {
+		t.Errorf("Expected a's other requests to keep waiting, got %d queued", n)
+	}
+}
+
+func TestQueue_DisplacesLowerPriority(t *testing.T) {
+	queue := NewQueue(2, time.Second)
+	defer queue.Close()
+
+	never := func() (bool, time.Duration) { return false, 0 }
+
+	a1 := waitAsync(queue, 0, "a", never)
+	waitForLen(t, queue, 1)
+	a2 := waitAsync(queue, 0, "a", never)
+	waitForLen(t, queue, 2)
+
+	// A new tenant displaces the tail of the tenant that filled the queue
+	b := waitAsync(queue, 0, "b", never)
+	if err := <-a2; !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Expected a's second request to be displaced, got %v", err)
+	}
+	waitForLen(t, queue, 2)
+
+	// Lower priorities cannot displace anything
+	if err := queue.Wait(context.Background(), -1, "c", 0, never); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Expected ErrQueueFull for lower priority, got %v", err)
+	}
+
+	// Higher priorities displace the request that goes last
+	high := waitAsync(queue, 10, "d", never)
+	if err := <-b; !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Expected b's request to be displaced, got %v", err)
+	}
+
+	queue.Close()
+	for _, done := range []<-chan error{a1, high} {
+		if err := <-done; !errors.Is(err, ErrQueueClosed) {
+			t.Errorf("Expected ErrQueueClosed, got %v", err)
+		}
+	}
+}
+
+func TestQueue_RetriesAfterDelay(t *testing.T) {
+	queue := NewQueue(10, time.Second)
+	defer queue.Close()
+
+	start := time.Now()
+	err := queue.Wait(context.Background(), 0, "", 0, func() (bool, time.Duration) {
+		if time.Since(start) < 50*time.Millisecond {
+			return false, 50 * time.Millisecond
+		}
+		return true, 0
+	})
+	if err != nil {
+		t.Fatalf("Expected request to be admitted after its retry delay, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Expected request to wait for its retry delay, waited %v", elapsed)
+	}
+}
+
+func TestQueue_FullAndTimeout(t *testing.T) {
+	queue := NewQueue(1, 50*time.Millisecond)
+	defer queue.Close()
+
+	never := func() (bool, time.Duration) { return false, 0 }
+
+	first := waitAsync(queue, 0, "", never)
+	waitForLen(t, queue, 1)
+	if err := queue.Wait(context.Background(), 0, "", 0, never); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Expected ErrQueueFull, got %v", err)
+	}
+	if err := <-first; !errors.Is(err, ErrQueueTimeout) {
+		t.Errorf("Expected ErrQueueTimeout, got %v", err)
+	}
+	if n := queue.Len(); n != 0 {
+		t.Errorf("Expected timed out request to leave the queue, got %d queued", n)
+	}
+
+	// Requests that cannot be admitted before the deadline are not queued
+	start := time.Now()
+	if err := queue.Wait(context.Background(), 0, "", time.Minute, never); !errors.Is(err, ErrQueueTimeout) {
+		t.Errorf("Expected ErrQueueTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Millisecond {
+		t.Errorf("Expected immediate rejection, waited %v", elapsed)
+	}
+}
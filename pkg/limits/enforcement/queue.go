@@ -0,0 +1,372 @@
+package enforcement
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrQueueFull is returned by Queue.Wait when the queue holds QueueDepth
+	// requests that go before the request, or when the request is displaced
+	// from a full queue by one that goes before it.
+	ErrQueueFull = errors.New("queue full")
+
+	// ErrQueueTimeout is returned by Queue.Wait when the request's wait
+	// deadline passes, or would pass, before capacity is available.
+	ErrQueueTimeout = errors.New("queue wait timed out")
+
+	// ErrQueueClosed is returned by Queue.Wait when the queue is closed.
+	ErrQueueClosed = errors.New("queue closed")
+)
+
+// TryFunc attempts to admit a queued request. It returns true once the
+// request has acquired capacity, or false and how long until capacity may
+// be available (0 if unknown).
+type TryFunc func() (admitted bool, retryAfter time.Duration)
+
+// Queue holds requests that exceeded a limit until capacity frees up.
+//
+// Queued requests are admitted in priority order: each time capacity may
+// have freed up, the queue tries its requests highest priority first. A
+// request is tried again once the retry delay returned by its TryFunc has
+// passed, or earlier when Notify reports freed capacity.
+//
+// Within a priority, the queue shares capacity fairly between tenants
+// (e.g. API keys) rather than serving requests in arrival order: each
+// request is stamped with a virtual start time one past the later of the
+// queue's virtual time and its tenant's previous request, so a tenant that
+// queues many requests at once takes turns with tenants that queue few.
+//
+// A full queue admits a request only by displacing the queued request that
+// goes last, if the new request goes before it: higher priorities displace
+// lower ones, and within a priority, new tenants displace the tail of a
+// tenant that filled the queue.
+//
+// Queue is thread-safe.
+type Queue struct {
+	depth   int
+	timeout time.Duration
+
+	mu      sync.Mutex
+	waiters waiterHeap
+	seq     uint64
+
+	// Fair queuing state: the virtual time, advanced to the start time of
+	// each admitted request, and the tenants with queued requests
+	vtime   uint64
+	tenants map[string]*tenantState
+
+	// notified is set by Notify to retry all requests right away
+	notified atomic.Bool
+
+	wake      chan struct{}
+	done      chan struct{}
+	loopDone  chan struct{}
+	closeOnce sync.Once
+}
+
+// waiter is a queued request.
+type waiter struct {
+	priority int
+	tenant   string
+	start    uint64 // virtual start time
+	seq      uint64
+	try      TryFunc
+
+	// retryAt is when the request is tried next; zero to wait for Notify
+	retryAt time.Time
+
+	// result receives nil when the request is admitted, or ErrQueueFull
+	// when it is displaced
+	result chan error
+
+	// index is the position in the heap, -1 once removed
+	index int
+}
+
+// tenantState tracks the queued requests of a tenant.
+type tenantState struct {
+	queued int
+	last   uint64 // virtual start time of the tenant's latest request
+}
+
+// NewQueue creates a queue holding up to depth requests, each for at most
+// timeout. Zero values use DefaultQueueDepth and DefaultQueueTimeout.
+func NewQueue(depth int, timeout time.Duration) *Queue {
+	if depth <= 0 {
+		depth = DefaultQueueDepth
+	}
+	if timeout <= 0 {
+		timeout = DefaultQueueTimeout
+	}
+
+	q := &Queue{
+		depth:    depth,
+		timeout:  timeout,
+		tenants:  make(map[string]*tenantState),
+		wake:     make(chan struct{}, 1),
+		done:     make(chan struct{}),
+		loopDone: make(chan struct{}),
+	}
+	go q.loop()
+	return q
+}
+
+// Wait queues a request of tenant until try admits it. retryAfter is the
+// earliest time the request can be admitted; pass 0 if capacity may be
+// released sooner, for example by requests that finish.
+//
+// The request waits until the queue timeout or the context deadline,
+// whichever comes first. If retryAfter ends after that deadline, Wait
+// returns ErrQueueTimeout right away rather than holding the request.
+func (q *Queue) Wait(ctx context.Context, priority int, tenant string, retryAfter time.Duration, try TryFunc) error {
+	now := time.Now()
+	deadline := now.Add(q.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	if now.Add(retryAfter).After(deadline) {
+		return ErrQueueTimeout
+	}
+
+	q.mu.Lock()
+	select {
+	case <-q.done:
+		q.mu.Unlock()
+		return ErrQueueClosed
+	default:
+	}
+	q.seq++
+	w := &waiter{
+		priority: priority,
+		tenant:   tenant,
+		start:    q.vtime + 1,
+		seq:      q.seq,
+		try:      try,
+		retryAt:  now.Add(retryAfter),
+		result:   make(chan error, 1),
+	}
+	if ts, ok := q.tenants[tenant]; ok && ts.last >= q.vtime {
+		w.start = ts.last + 1
+	}
+	if len(q.waiters) >= q.depth {
+		last := q.last()
+		if last == nil || !w.before(last) {
+			q.mu.Unlock()
+			return ErrQueueFull
+		}
+		q.remove(last)
+		last.result <- ErrQueueFull
+	}
+	q.push(w)
+	q.mu.Unlock()
+
+	// Schedule the request's first try
+	q.signal()
+
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+
+	var err error
+	select {
+	case err := <-w.result:
+		return err
+	case <-timer.C:
+		err = ErrQueueTimeout
+	case <-ctx.Done():
+		err = ctx.Err()
+	case <-q.done:
+		err = ErrQueueClosed
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if w.index < 0 {
+		// Admitted or displaced while giving up
+		return <-w.result
+	}
+	q.remove(w)
+	return err
+}
+
+// Notify reports that capacity may have freed up, so queued requests are
+// tried again without waiting for their retry delay. It does not block.
+func (q *Queue) Notify() {
+	if q == nil {
+		return
+	}
+	q.notified.Store(true)
+	q.signal()
+}
+
+// Len returns the number of queued requests.
+func (q *Queue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.waiters)
+}
+
+// Close stops the queue. Queued requests return ErrQueueClosed.
+func (q *Queue) Close() {
+	q.closeOnce.Do(func() {
+		close(q.done)
+		<-q.loopDone
+	})
+}
+
+// signal wakes the dispatch loop.
+func (q *Queue) signal() {
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+}
+
+// loop tries queued requests when woken or when the next retry is due.
+func (q *Queue) loop() {
+	defer close(q.loopDone)
+
+	timer := time.NewTimer(time.Hour)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-q.done:
+			return
+		case <-q.wake:
+		case <-timer.C:
+		}
+
+		timer.Stop()
+		if next := q.dispatch(); !next.IsZero() {
+			timer.Reset(time.Until(next))
+		}
+	}
+}
+
+// dispatch tries the requests that are due, highest priority first, and
+// returns when the next request is due (zero if none is).
+func (q *Queue) dispatch() time.Time {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	notified := q.notified.Swap(false)
+	now := time.Now()
+
+	ordered := make([]*waiter, len(q.waiters))
+	copy(ordered, q.waiters)
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].before(ordered[j]) })
+
+	var next time.Time
+	for _, w := range ordered {
+		if !notified && (w.retryAt.IsZero() || w.retryAt.After(now)) {
+			if !w.retryAt.IsZero() {
+				next = earliest(next, w.retryAt)
+			}
+			continue
+		}
+
+		admitted, retryAfter := w.try()
+		if admitted {
+			q.remove(w)
+			q.vtime = max(q.vtime, w.start)
+			w.result <- nil
+			continue
+		}
+		// Requests with no known retry delay wait for Notify
+		w.retryAt = time.Time{}
+		if retryAfter > 0 {
+			w.retryAt = now.Add(retryAfter)
+			next = earliest(next, w.retryAt)
+		}
+	}
+	return next
+}
+
+// push queues w. Caller must hold q.mu.
+func (q *Queue) push(w *waiter) {
+	ts, ok := q.tenants[w.tenant]
+	if !ok {
+		ts = &tenantState{}
+		q.tenants[w.tenant] = ts
+	}
+	ts.queued++
+	ts.last = max(ts.last, w.start)
+	heap.Push(&q.waiters, w)
+}
+
+// remove removes w from the queue. Caller must hold q.mu.
+func (q *Queue) remove(w *waiter) {
+	heap.Remove(&q.waiters, w.index)
+	if ts := q.tenants[w.tenant]; ts != nil {
+		ts.queued--
+		if ts.queued <= 0 {
+			delete(q.tenants, w.tenant)
+		}
+	}
+}
+
+// last returns the queued request that goes last, or nil if the queue is
+// empty. Caller must hold q.mu.
+func (q *Queue) last() *waiter {
+	var last *waiter
+	for _, w := range q.waiters {
+		if last == nil || last.before(w) {
+			last = w
+		}
+	}
+	return last
+}
+
+// earliest returns the earlier of two times, ignoring zero times.
+func earliest(a, b time.Time) time.Time {
+	if a.IsZero() || b.Before(a) {
+		return b
+	}
+	return a
+}
+
+// before reports whether w is tried before other.
+func (w *waiter) before(other *waiter) bool {
+	if w.priority != other.priority {
+		return w.priority > other.priority
+	}
+	if w.start != other.start {
+		return w.start < other.start
+	}
+	return w.seq < other.seq
+}
+
+// waiterHeap orders waiters by priority, then virtual start time, then
+// arrival. It implements heap.Interface.
+type waiterHeap []*waiter
+
+func (h waiterHeap) Len() int           { return len(h) }
+func (h waiterHeap) Less(i, j int) bool { return h[i].before(h[j]) }
+
+func (h waiterHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *waiterHeap) Push(x interface{}) {
+	w := x.(*waiter)
+	w.index = len(*h)
+	*h = append(*h, w)
+}
+
+func (h *waiterHeap) Pop() interface{} {
+	old := *h
+	w := old[len(old)-1]
+	old[len(old)-1] = nil
+	w.index = -1
+	*h = old[:len(old)-1]
+	return w
+}
@@ -22,6 +22,14 @@ const (
 	ActionAlert Action = "alert"
 )
 
+const (
+	// DefaultQueueDepth is the default maximum number of queued requests.
+	DefaultQueueDepth = 100
+
+	// DefaultQueueTimeout is the default maximum time a request is queued.
+	DefaultQueueTimeout = 30 * time.Second
+)
+
 // Config contains configuration for the enforcer.
 type Config struct {
 	// DefaultAction is the action to take when no specific action is configured.
@@ -30,9 +38,20 @@ type Config struct {
 	// QueueDepth is the maximum number of requests to queue (when action=queue).
 	QueueDepth int
 
-	// QueueTimeout is how long to wait for queue capacity before giving up.
+	// QueueTimeout is how long a queued request waits for capacity before
+	// it is rejected.
 	QueueTimeout time.Duration
 
+	// QueuePriorities sets the priority of queued requests by identifier.
+	// Requests with a higher priority are admitted first. Default: 0.
+	QueuePriorities map[string]int
+
+	// PriorityTiers maps priority classes (e.g. "prod", "batch",
+	// "experimentation") to queue priorities. Requests of a class, such as
+	// that of their API key, are queued with its priority instead of their
+	// identifier's QueuePriorities entry.
+	PriorityTiers map[string]int
+
 	// ModelDowngrades maps expensive models to cheaper alternatives.
 	// Example: "gpt-4" -> "gpt-3.5-turbo"
 	ModelDowngrades map[string]string
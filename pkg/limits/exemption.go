@@ -0,0 +1,104 @@
+package limits
+
+import (
+	"context"
+	"crypto/subtle"
+	"time"
+)
+
+// Exemption exempts an identifier, such as the API key of an internal
+// health check, from rate limits and budgets.
+type Exemption struct {
+	// Identifier is the exempt identifier (API key or user ID).
+	Identifier string
+
+	// Name identifies the exemption in the audit log, e.g.
+	// "health-check".
+	Name string
+}
+
+// BreakGlassToken lets any request presenting it bypass rate limits and
+// budgets, e.g. to keep an incident response working while limits are
+// exhausted.
+type BreakGlassToken struct {
+	// Name identifies the token in the audit log, e.g. "oncall".
+	Name string
+
+	// Token is the secret presented with the request.
+	Token string
+
+	// ExpiresAt is when the token stops being accepted. Zero means never.
+	ExpiresAt time.Time
+}
+
+// Bypass kinds.
+const (
+	// BypassExemption is a bypass by an exempt identifier.
+	BypassExemption = "exemption"
+
+	// BypassBreakGlass is a bypass by a break-glass token.
+	BypassBreakGlass = "break_glass"
+)
+
+// Bypass describes why a request bypassed rate limits and budgets.
+type Bypass struct {
+	// Kind is BypassExemption or BypassBreakGlass.
+	Kind string
+
+	// Name is the name of the exemption or break-glass token.
+	Name string
+}
+
+// String returns the bypass as "<kind>:<name>", as recorded in the audit
+// log.
+func (b Bypass) String() string {
+	return b.Kind + ":" + b.Name
+}
+
+// Exempt reports whether identifier is exempt from rate limits and budgets.
+func (m *Manager) Exempt(identifier string) (Bypass, bool) {
+	if identifier == "" {
+		return Bypass{}, false
+	}
+	exemption, ok := m.exemptions[identifier]
+	if !ok {
+		return Bypass{}, false
+	}
+	return Bypass{Kind: BypassExemption, Name: exemption.Name}, true
+}
+
+// BreakGlass reports whether token is an unexpired break-glass token.
+// Tokens are compared in constant time.
+func (m *Manager) BreakGlass(token string) (Bypass, bool) {
+	if token == "" {
+		return Bypass{}, false
+	}
+
+	now := time.Now()
+	for _, t := range m.breakGlassTokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(t.Token)) != 1 {
+			continue
+		}
+		if !t.ExpiresAt.IsZero() && now.After(t.ExpiresAt) {
+			return Bypass{}, false
+		}
+		return Bypass{Kind: BypassBreakGlass, Name: t.Name}, true
+	}
+	return Bypass{}, false
+}
+
+// bypassKey is the context key of a limits bypass.
+const bypassKey contextKey = "limit_bypass"
+
+// WithBypass returns a copy of ctx recording that the request bypassed
+// rate limits and budgets, so that its evidence record names the bypass.
+func WithBypass(ctx context.Context, bypass Bypass) context.Context {
+	return context.WithValue(ctx, bypassKey, bypass)
+}
+
+// BypassFromContext returns the limits bypass of ctx, if the request
+// bypassed rate limits and budgets.
+func BypassFromContext(ctx context.Context) (Bypass, bool) {
+	bypass, ok := ctx.Value(bypassKey).(Bypass)
+	return bypass, ok
+}
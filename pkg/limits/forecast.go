@@ -0,0 +1,102 @@
+package limits
+
+import (
+	"sort"
+	"time"
+
+	"mercator-hq/jupiter/pkg/limits/budget"
+)
+
+// ScopeForecast is the projected spending of the budgets of one scope.
+type ScopeForecast struct {
+	Dimension  Dimension `json:"dimension"`
+	Identifier string    `json:"identifier"`
+
+	// Model is set for budgets scoped to the identifier's use of a model.
+	Model string `json:"model,omitempty"`
+
+	// Budgets contains the forecast of each configured budget window.
+	Budgets []BudgetWindowForecast `json:"budgets"`
+}
+
+// BudgetWindowForecast is the projected spending of one budget window.
+type BudgetWindowForecast struct {
+	Window string                `json:"window"`
+	Method budget.ForecastMethod `json:"method"`
+	Limit  float64               `json:"limit"`
+	Used   float64               `json:"used"`
+
+	// RunRate is the average spending in USD per hour.
+	RunRate float64 `json:"run_rate"`
+
+	// Projected is the spending projected for the next full window, and
+	// ProjectedPercentage the share of the limit it represents.
+	Projected           float64 `json:"projected"`
+	ProjectedPercentage float64 `json:"projected_percentage"`
+
+	// ExceedsAt is when the window is projected to exceed its limit.
+	ExceedsAt *time.Time `json:"exceeds_at,omitempty"`
+}
+
+// Exceeds reports whether any budget of the scope is projected to exceed
+// its limit.
+func (f *ScopeForecast) Exceeds() bool {
+	for _, b := range f.Budgets {
+		if b.ExceedsAt != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Forecast projects the spending of the budgets of a scope and of the
+// identifier's model-scoped budgets, based on their current run rate. An
+// empty identifier forecasts every budget. Results are sorted by
+// dimension, identifier and model.
+func (m *Manager) Forecast(dimension Dimension, identifier string, method budget.ForecastMethod) []ScopeForecast {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var forecasts []ScopeForecast
+	for key := range m.budgetConfigs {
+		var forecast ScopeForecast
+		forecast.Dimension, forecast.Identifier, forecast.Model = m.keyScope(key)
+		if identifier != "" && (forecast.Identifier != identifier || forecast.Dimension != normalizeDimension(dimension)) {
+			continue
+		}
+
+		tracker := m.getBudgetTracker(key)
+		if tracker == nil {
+			continue
+		}
+		for _, f := range tracker.Forecast(method) {
+			window := BudgetWindowForecast{
+				Window:              budget.WindowName(f.Window),
+				Method:              f.Method,
+				Limit:               f.Limit,
+				Used:                f.Used,
+				RunRate:             f.RunRate,
+				Projected:           f.Projected,
+				ProjectedPercentage: f.Projected / f.Limit,
+			}
+			if f.Exceeds() {
+				exceedsAt := f.ExceedsAt
+				window.ExceedsAt = &exceedsAt
+			}
+			forecast.Budgets = append(forecast.Budgets, window)
+		}
+		forecasts = append(forecasts, forecast)
+	}
+
+	sort.Slice(forecasts, func(i, j int) bool {
+		a, b := forecasts[i], forecasts[j]
+		if a.Dimension != b.Dimension {
+			return a.Dimension < b.Dimension
+		}
+		if a.Identifier != b.Identifier {
+			return a.Identifier < b.Identifier
+		}
+		return a.Model < b.Model
+	})
+	return forecasts
+}
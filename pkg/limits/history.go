@@ -0,0 +1,187 @@
+package limits
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"mercator-hq/jupiter/pkg/limits/storage"
+)
+
+// ErrUsageHistoryUnsupported is returned when querying usage history with
+// a storage backend that does not keep it.
+var ErrUsageHistoryUnsupported = errors.New("storage backend does not keep usage history")
+
+// Usage history granularities.
+const (
+	// GranularityHour buckets usage history by hour.
+	GranularityHour = "hour"
+
+	// GranularityDay buckets usage history by UTC day.
+	GranularityDay = "day"
+)
+
+// UsageHistory is the usage of an identifier over time.
+type UsageHistory struct {
+	Dimension   Dimension `json:"dimension"`
+	Identifier  string    `json:"identifier"`
+	Granularity string    `json:"granularity"`
+	Start       time.Time `json:"start"`
+	End         time.Time `json:"end"`
+
+	// Buckets contains the usage of each hour or day with usage, oldest
+	// first.
+	Buckets []UsageHistoryBucket `json:"buckets"`
+}
+
+// UsageHistoryBucket is the usage of an identifier within one hour or day.
+type UsageHistoryBucket struct {
+	Start            time.Time `json:"start"`
+	Requests         int64     `json:"requests"`
+	PromptTokens     int64     `json:"prompt_tokens"`
+	CompletionTokens int64     `json:"completion_tokens"`
+	TotalTokens      int64     `json:"total_tokens"`
+
+	// Cost is the spending in USD.
+	Cost float64 `json:"cost"`
+}
+
+// UsageHistory returns the usage of an identifier in [start, end), bucketed
+// by granularity (GranularityHour or GranularityDay). Hourly buckets are
+// read from the storage backend, which must implement storage.UsageStore,
+// together with the usage not yet written to it by a snapshot.
+func (m *Manager) UsageHistory(ctx context.Context, dimension Dimension, identifier string, granularity string, start, end time.Time) (*UsageHistory, error) {
+	if granularity != GranularityHour && granularity != GranularityDay {
+		return nil, fmt.Errorf("invalid granularity %q: must be hour or day", granularity)
+	}
+	store, ok := m.storage.(storage.UsageStore)
+	if !ok {
+		return nil, ErrUsageHistoryUnsupported
+	}
+	dimension = normalizeDimension(dimension)
+
+	// Keep a concurrent flush from moving usage between the pending usage
+	// and the backend while both are read
+	m.historyMu.RLock()
+	defer m.historyMu.RUnlock()
+
+	hourly, err := store.QueryUsage(ctx, identifier, string(dimension), start, end)
+	if err != nil {
+		return nil, err
+	}
+	m.usageMu.Lock()
+	for _, pending := range m.pendingUsage {
+		if pending.Identifier == identifier && pending.Dimension == string(dimension) &&
+			!pending.Start.Before(start) && pending.Start.Before(end) {
+			hourly = append(hourly, *pending)
+		}
+	}
+	m.usageMu.Unlock()
+
+	buckets := make(map[int64]*UsageHistoryBucket)
+	for _, u := range hourly {
+		bucketStart := u.Start.UTC().Truncate(time.Hour)
+		if granularity == GranularityDay {
+			bucketStart = bucketStart.Truncate(24 * time.Hour)
+		}
+		b := buckets[bucketStart.Unix()]
+		if b == nil {
+			b = &UsageHistoryBucket{Start: bucketStart}
+			buckets[bucketStart.Unix()] = b
+		}
+		b.Requests += u.Requests
+		b.PromptTokens += u.PromptTokens
+		b.CompletionTokens += u.CompletionTokens
+		b.TotalTokens += u.PromptTokens + u.CompletionTokens
+		b.Cost += u.Cost
+	}
+
+	history := &UsageHistory{
+		Dimension:   dimension,
+		Identifier:  identifier,
+		Granularity: granularity,
+		Start:       start,
+		End:         end,
+		Buckets:     make([]UsageHistoryBucket, 0, len(buckets)),
+	}
+	for _, b := range buckets {
+		history.Buckets = append(history.Buckets, *b)
+	}
+	sort.Slice(history.Buckets, func(i, j int) bool {
+		return history.Buckets[i].Start.Before(history.Buckets[j].Start)
+	})
+	return history, nil
+}
+
+// recordHistory adds a usage record to the usage not yet written to the
+// storage backend. It is a no-op if the backend keeps no usage history.
+func (m *Manager) recordHistory(record *UsageRecord) {
+	if _, ok := m.storage.(storage.UsageStore); !ok {
+		return
+	}
+
+	timestamp := record.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	start := timestamp.Truncate(time.Hour)
+	dimension := string(normalizeDimension(record.Dimension))
+	key := dimension + ":" + record.Identifier + ":" + strconv.FormatInt(start.Unix(), 10)
+
+	m.usageMu.Lock()
+	defer m.usageMu.Unlock()
+	pending := m.pendingUsage[key]
+	if pending == nil {
+		pending = &storage.UsageBucket{Identifier: record.Identifier, Dimension: dimension, Start: start}
+		m.pendingUsage[key] = pending
+	}
+	pending.Requests++
+	pending.PromptTokens += int64(record.RequestTokens)
+	pending.CompletionTokens += int64(record.ResponseTokens)
+	pending.Cost += record.Cost
+}
+
+// flushHistory writes the usage recorded since the previous flush to the
+// storage backend. Usage that fails to be written is retried by the next
+// flush.
+func (m *Manager) flushHistory(ctx context.Context) error {
+	store, ok := m.storage.(storage.UsageStore)
+	if !ok {
+		return nil
+	}
+
+	m.historyMu.Lock()
+	defer m.historyMu.Unlock()
+
+	m.usageMu.Lock()
+	pending := m.pendingUsage
+	m.pendingUsage = make(map[string]*storage.UsageBucket)
+	m.usageMu.Unlock()
+	if len(pending) == 0 {
+		return nil
+	}
+
+	usage := make([]storage.UsageBucket, 0, len(pending))
+	for _, u := range pending {
+		usage = append(usage, *u)
+	}
+	if err := store.AddUsage(ctx, usage); err != nil {
+		m.usageMu.Lock()
+		for key, u := range pending {
+			if current := m.pendingUsage[key]; current != nil {
+				current.Requests += u.Requests
+				current.PromptTokens += u.PromptTokens
+				current.CompletionTokens += u.CompletionTokens
+				current.Cost += u.Cost
+			} else {
+				m.pendingUsage[key] = u
+			}
+		}
+		m.usageMu.Unlock()
+		return fmt.Errorf("failed to write usage history: %w", err)
+	}
+	return nil
+}
@@ -2,8 +2,13 @@ package limits
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"mercator-hq/jupiter/pkg/limits/budget"
 	"mercator-hq/jupiter/pkg/limits/enforcement"
@@ -34,17 +39,64 @@ type Manager struct {
 	rateLimiters map[string]*ratelimit.Limiter
 	budgets      map[string]*budget.Tracker
 
-	// Enforcement engine
+	// Concurrency limits that come with requests (see ConcurrencyLimits)
+	keyConcurrent *ratelimit.KeyedConcurrentLimiter
+	keyStreams    *ratelimit.KeyedConcurrentLimiter
+
+	// Enforcement engine, and the queue of requests waiting for capacity
+	// (nil unless the enforcement action is queue)
 	enforcer *enforcement.Enforcer
+	queue    *enforcement.Queue
 
 	// Storage backend
 	storage storage.Backend
 
+	// Rate limiter state store (nil keeps state in process)
+	rateLimitStore ratelimit.Store
+
+	// Budget snapshotting
+	snapshotInterval time.Duration
+	maxStaleness     time.Duration
+	lastMerged       atomic.Int64 // Unix time (ns) of the last complete budget merge
+	done             chan struct{}
+	loopDone         chan struct{}
+	closeOnce        sync.Once
+	logger           *slog.Logger
+
 	// Configuration
 	rateLimitConfigs  map[string]ratelimit.Config
 	budgetConfigs     map[string]budget.Config
 	enforcementConfig enforcement.Config
 
+	// Budget hierarchy: the scopes of user, team and org budgets by
+	// tracker key, and the ancestor tracker keys of each identifier
+	budgetScopes      map[string]BudgetScope
+	budgetAncestors   map[string][]string
+	budgetInheritance BudgetInheritance
+
+	// budgetWarningMessage enables system message injection of budget
+	// warnings
+	budgetWarningMessage bool
+
+	// alertNotifier is notified of budget alerts; nil to only log them
+	alertNotifier AlertNotifier
+
+	// Identifiers and tokens that bypass rate limits and budgets
+	exemptions       map[string]Exemption
+	breakGlassTokens []BreakGlassToken
+
+	// pendingUsage is the hourly usage not yet written to the storage
+	// backend's usage history, keyed by dimension, identifier and hour.
+	// usageMu protects it; historyMu serializes flushes with queries.
+	pendingUsage map[string]*storage.UsageBucket
+	usageMu      sync.Mutex
+	historyMu    sync.RWMutex
+
+	// Model-scoped limits by key, and temporary budget overrides set
+	// through the admin API
+	modelScopes map[string]modelScope
+	overrides   map[string]*budgetOverride
+
 	mu sync.RWMutex
 }
 
@@ -56,13 +108,83 @@ type Config struct {
 	// Budgets maps identifiers to budget configurations.
 	Budgets map[string]budget.Config
 
+	// ModelRateLimits maps identifiers to rate limits for their use of
+	// specific models, keyed by model name. They are enforced in addition
+	// to the identifier's RateLimits. Concurrent request limits are not
+	// supported per model.
+	ModelRateLimits map[string]map[string]ratelimit.Config
+
+	// ModelBudgets maps identifiers to budgets for their spending on
+	// specific models, keyed by model name. They are enforced in addition
+	// to the identifier's Budgets.
+	ModelBudgets map[string]map[string]budget.Config
+
+	// ScopeBudgets contains the budgets of user, team and organization
+	// scopes. They apply to the identifiers that list the scope in
+	// BudgetAncestors.
+	ScopeBudgets map[BudgetScope]budget.Config
+
+	// BudgetAncestors maps identifiers to the scopes above them, nearest
+	// first (e.g., an API key's user, team and organization). Spending is
+	// recorded against the budget of every ancestor.
+	BudgetAncestors map[string][]BudgetScope
+
+	// BudgetInheritance selects which budgets along an identifier's
+	// ancestry are enforced.
+	// Default: InheritAll
+	BudgetInheritance BudgetInheritance
+
+	// BudgetWarningMessage asks handlers to inject a system message telling
+	// the model how much budget remains into requests that crossed a
+	// budget's alert threshold. See BudgetWarningMessage.
+	BudgetWarningMessage bool
+
+	// AlertNotifier is notified when spending crosses a budget's alert
+	// threshold or limit, e.g. an alerting.Dispatcher. It is closed with
+	// the manager if it implements io.Closer. Default: none.
+	AlertNotifier AlertNotifier
+
 	// Enforcement configures enforcement actions.
 	Enforcement enforcement.Config
 
+	// Exemptions lists identifiers exempt from rate limits and budgets,
+	// such as internal health checks. See Manager.Exempt.
+	Exemptions []Exemption
+
+	// BreakGlassTokens lists tokens that let any request bypass rate limits
+	// and budgets. See Manager.BreakGlass.
+	BreakGlassTokens []BreakGlassToken
+
 	// Storage configures the storage backend.
 	Storage storage.Backend
+
+	// RateLimitStore holds rate limiter state. Use a ratelimit.RedisStore to
+	// share limits between proxy replicas. Default: in-process state.
+	RateLimitStore ratelimit.Store
+
+	// SnapshotInterval is how often budget and rate limit state is written
+	// to Storage. Usage is recorded in memory; the request path never waits
+	// for storage. With a backend that implements storage.BudgetMerger, each
+	// snapshot also picks up spending recorded by other replicas.
+	// Default: 10 seconds
+	SnapshotInterval time.Duration
+
+	// MaxStaleness bounds how far behind the spending of other replicas a
+	// replica's budgets may fall. With a storage.BudgetMerger backend, once
+	// no snapshot has merged every budget for this long, requests against
+	// budgets are rejected until a merge succeeds again. Zero keeps
+	// enforcing budgets on the last merged state however old it is.
+	// Default: 0 (unbounded)
+	MaxStaleness time.Duration
 }
 
+// DefaultSnapshotInterval is the default interval between limit snapshots.
+const DefaultSnapshotInterval = 10 * time.Second
+
+// snapshotTimeout bounds restoring limit state at startup and each
+// snapshot.
+const snapshotTimeout = 5 * time.Second
+
 // NewManager creates a new limits manager with the given configuration.
 //
 // Example:
@@ -89,39 +211,136 @@ func NewManager(config Config) *Manager {
 	if config.Storage == nil {
 		config.Storage = storage.NewMemoryBackend()
 	}
+	if config.SnapshotInterval <= 0 {
+		config.SnapshotInterval = DefaultSnapshotInterval
+	}
+
+	// Model-scoped limits get their own limiters and trackers
+	modelScopes := make(map[string]modelScope)
+	rateLimitConfigs := make(map[string]ratelimit.Config, len(config.RateLimits))
+	for identifier, rateLimitConfig := range config.RateLimits {
+		rateLimitConfigs[identifier] = rateLimitConfig
+	}
+	for identifier, models := range config.ModelRateLimits {
+		for model, rateLimitConfig := range models {
+			rateLimitConfigs[modelScopeKey(identifier, model)] = rateLimitConfig
+			modelScopes[modelScopeKey(identifier, model)] = modelScope{identifier: identifier, model: model}
+		}
+	}
+	budgetConfigs := make(map[string]budget.Config, len(config.Budgets))
+	for identifier, budgetConfig := range config.Budgets {
+		budgetConfigs[identifier] = budgetConfig
+	}
+	for identifier, models := range config.ModelBudgets {
+		for model, budgetConfig := range models {
+			budgetConfigs[modelScopeKey(identifier, model)] = budgetConfig
+			modelScopes[modelScopeKey(identifier, model)] = modelScope{identifier: identifier, model: model}
+		}
+	}
+
+	// Scoped budgets are keyed by their dimension and identifier
+	budgetScopes := make(map[string]BudgetScope, len(config.ScopeBudgets))
+	for scope, budgetConfig := range config.ScopeBudgets {
+		budgetConfigs[scope.Key()] = budgetConfig
+		budgetScopes[scope.Key()] = scope
+	}
+	budgetAncestors := make(map[string][]string, len(config.BudgetAncestors))
+	for identifier, ancestors := range config.BudgetAncestors {
+		keys := make([]string, len(ancestors))
+		for i, scope := range ancestors {
+			keys[i] = scope.Key()
+		}
+		budgetAncestors[identifier] = keys
+	}
+	if config.BudgetInheritance == "" {
+		config.BudgetInheritance = InheritAll
+	}
+	exemptions := make(map[string]Exemption, len(config.Exemptions))
+	for _, exemption := range config.Exemptions {
+		exemptions[exemption.Identifier] = exemption
+	}
 
 	manager := &Manager{
 		rateLimiters:      make(map[string]*ratelimit.Limiter),
 		budgets:           make(map[string]*budget.Tracker),
+		keyConcurrent:     ratelimit.NewKeyedConcurrentLimiter(),
+		keyStreams:        ratelimit.NewKeyedConcurrentLimiter(),
 		enforcer:          enforcement.NewEnforcer(config.Enforcement),
 		storage:           config.Storage,
-		rateLimitConfigs:  config.RateLimits,
-		budgetConfigs:     config.Budgets,
+		rateLimitStore:    config.RateLimitStore,
+		snapshotInterval:  config.SnapshotInterval,
+		maxStaleness:      config.MaxStaleness,
+		done:              make(chan struct{}),
+		loopDone:          make(chan struct{}),
+		logger:            slog.Default().With("component", "limits"),
+		rateLimitConfigs:  rateLimitConfigs,
+		budgetConfigs:     budgetConfigs,
 		enforcementConfig: config.Enforcement,
+		budgetScopes:      budgetScopes,
+		budgetAncestors:   budgetAncestors,
+		budgetInheritance: config.BudgetInheritance,
+		modelScopes:       modelScopes,
+		overrides:         make(map[string]*budgetOverride),
+
+		budgetWarningMessage: config.BudgetWarningMessage,
+		alertNotifier:        config.AlertNotifier,
+		exemptions:           exemptions,
+		breakGlassTokens:     config.BreakGlassTokens,
+		pendingUsage:         make(map[string]*storage.UsageBucket),
+	}
+	if config.Enforcement.DefaultAction == enforcement.ActionQueue {
+		manager.queue = enforcement.NewQueue(config.Enforcement.QueueDepth, config.Enforcement.QueueTimeout)
 	}
 
 	// Pre-initialize limiters and trackers for configured identifiers
-	for identifier, rateLimitConfig := range config.RateLimits {
-		manager.rateLimiters[identifier] = ratelimit.NewLimiter(rateLimitConfig)
+	for key, rateLimitConfig := range rateLimitConfigs {
+		manager.rateLimiters[key] = ratelimit.NewLimiterWithStore(key, rateLimitConfig, config.RateLimitStore)
 	}
 
-	for identifier, budgetConfig := range config.Budgets {
-		manager.budgets[identifier] = budget.NewTracker(budgetConfig)
+	for key, budgetConfig := range budgetConfigs {
+		manager.budgets[key] = budget.NewTracker(budgetConfig)
 	}
 
+	// Restore persisted budget and rate limit state. Limits start empty if
+	// storage is unavailable; the next successful snapshot catches up.
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+	manager.restoreState(ctx)
+	cancel()
+	manager.lastMerged.Store(time.Now().UnixNano())
+
+	go manager.snapshotLoop()
+
 	return manager
 }
 
 // CheckLimits checks if a request is allowed based on rate limits and budgets.
 //
+// The estimated tokens of an allowed request are reserved against token
+// rate limits right away, so concurrent requests cannot overshoot them.
+// The result's Reservation is reconciled with the actual usage by
+// RecordUsage, or released with ReleaseReservation.
+//
 // This method checks all configured limits for the given identifier:
 //   - Rate limits (requests per second/minute/hour)
 //   - Token limits (tokens per minute/hour)
 //   - Concurrent request limits
 //   - Budget limits (hourly/daily/monthly)
 //
-// If any limit is exceeded, it returns a LimitCheckResult with Allowed=false
-// and the reason for rejection. Otherwise, it returns Allowed=true.
+// Limits configured for the identifier's use of the requested model are
+// checked as well, after the identifier's own limits, followed by the
+// budgets of the identifier's ancestors (user, team, org) selected by the
+// budget inheritance mode.
+//
+// If any limit is exceeded, it returns a LimitCheckResult with Allowed=false,
+// the reason for rejection, and in RetryAfter the earliest time the request
+// could pass all limits, as buckets refill and windows slide. Otherwise, it
+// returns Allowed=true.
+//
+// With the downgrade action, an exceeded budget allows the request with
+// Action=ActionDowngrade and DowngradeTo set to the cheaper model to serve
+// it with, provided it passes the remaining limits. Exceeded rate limits
+// block the request, as serving it with a cheaper model would not stay
+// within them.
 //
 // Parameters:
 //   - ctx: Context for cancellation and deadlines
@@ -137,24 +356,239 @@ func (m *Manager) CheckLimits(ctx context.Context, identifier string, estimatedT
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	// Get or create rate limiter for this identifier
-	rateLimiter := m.getRateLimiter(identifier)
-	budgetTracker := m.getBudgetTracker(identifier)
+	reservation := &Reservation{tokens: make(map[string]*ratelimit.TokenReservation)}
+	result, err := m.checkLimits(ctx, identifier, estimatedTokens, model, reservation)
+	if err != nil || !result.Allowed {
+		return result, err
+	}
+	if len(reservation.tokens) > 0 {
+		result.Reservation = reservation
+	}
+	return result, nil
+}
+
+// PreviewLimits reports whether a request would pass all rate limits and
+// budgets, like CheckLimits, without consuming any capacity, reserving
+// tokens, or notifying budget alerts. It is for pre-flight estimates of
+// requests that are not sent.
+func (m *Manager) PreviewLimits(ctx context.Context, identifier string, estimatedTokens int, estimatedCost float64, model string) (*LimitCheckResult, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.checkLimits(ctx, identifier, estimatedTokens, model, nil)
+}
+
+// checkLimits checks the limits of a request, reserving its estimated
+// tokens in reservation. A nil reservation previews the check without
+// consuming any capacity.
+// Caller must hold read lock.
+func (m *Manager) checkLimits(ctx context.Context, identifier string, estimatedTokens int, model string, reservation *Reservation) (*LimitCheckResult, error) {
+	var alert, downgrade *LimitCheckResult
+	for _, s := range limitScopes(identifier, model) {
+		violation, scopeAlert, err := m.checkScope(ctx, identifier, s, estimatedTokens, model, reservation)
+		if err != nil {
+			m.releaseReservation(reservation)
+			return nil, err
+		}
+		if violation != nil && violation.Allowed {
+			// Downgraded requests are still subject to the other limits
+			if downgrade == nil {
+				downgrade = violation
+			}
+			continue
+		}
+		if violation != nil {
+			m.releaseReservation(reservation)
+			m.setRetryAfter(violation, identifier, estimatedTokens, model)
+			return violation, nil
+		}
+		if alert == nil {
+			alert = scopeAlert
+		}
+	}
+
+	// Check the budgets of the identifier's ancestors
+	for _, key := range m.enforcedAncestors(identifier) {
+		violation, scopeAlert, err := m.checkBudget(ctx, m.getBudgetTracker(key), m.budgetScope(key), "", model, reservation != nil)
+		if err != nil {
+			m.releaseReservation(reservation)
+			return nil, err
+		}
+		if violation != nil && violation.Allowed {
+			if downgrade == nil {
+				downgrade = violation
+			}
+			continue
+		}
+		if violation != nil {
+			m.releaseReservation(reservation)
+			m.setRetryAfter(violation, identifier, estimatedTokens, model)
+			return violation, nil
+		}
+		if alert == nil {
+			alert = scopeAlert
+		}
+	}
+
+	result := downgrade
+	if result == nil {
+		result = alert
+	}
+	if result == nil {
+		// All limits passed
+		result = &LimitCheckResult{
+			Allowed: true,
+		}
+	}
+	return result, nil
+}
+
+// setRetryAfter sets the RetryAfter of a rejected request to the time
+// until it would pass every limit that applies to it, so clients retrying
+// then are not rejected by another limit.
+// Caller must hold read lock.
+func (m *Manager) setRetryAfter(result *LimitCheckResult, identifier string, estimatedTokens int, model string) {
+	if result.Allowed {
+		return
+	}
+	result.RetryAfter = m.timeUntilAllowed(identifier, estimatedTokens, model)
+}
+
+// timeUntilAllowed returns how long until a request would pass every rate
+// limit and budget that applies to it, without consuming any capacity.
+// Caller must hold read lock.
+func (m *Manager) timeUntilAllowed(identifier string, estimatedTokens int, model string) time.Duration {
+	var wait time.Duration
+	for _, s := range limitScopes(identifier, model) {
+		if rateLimiter := m.getRateLimiter(s.key); rateLimiter != nil {
+			wait = max(wait, rateLimiter.TimeUntilAllowed(estimatedTokens))
+		}
+		if budgetTracker := m.getBudgetTracker(s.key); budgetTracker != nil {
+			wait = max(wait, budgetTracker.TimeUntilAllowed())
+		}
+	}
+	for _, key := range m.enforcedAncestors(identifier) {
+		if budgetTracker := m.getBudgetTracker(key); budgetTracker != nil {
+			wait = max(wait, budgetTracker.TimeUntilAllowed())
+		}
+	}
+	return wait
+}
+
+// ReleaseReservation releases the tokens reserved by CheckLimits for a
+// request that was not served, such as one that failed before reaching
+// the provider. Releasing a nil or already settled reservation is a no-op.
+func (m *Manager) ReleaseReservation(reservation *Reservation) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	m.releaseReservation(reservation)
+	m.queue.Notify()
+}
+
+// releaseReservation releases a reservation.
+// Caller must hold read or write lock.
+func (m *Manager) releaseReservation(reservation *Reservation) {
+	if reservation == nil {
+		return
+	}
+	for key, tokens := range reservation.tokens {
+		if rateLimiter := m.getRateLimiter(key); rateLimiter != nil {
+			rateLimiter.ReconcileTokens(tokens, 0)
+		}
+		delete(reservation.tokens, key)
+	}
+}
+
+// limitScope is a set of limits that applies to a request.
+type limitScope struct {
+	// key identifies the rate limiter and budget tracker of the scope.
+	key string
+
+	// model is the model the limits apply to; empty for the identifier's
+	// limits across all models.
+	model string
+}
+
+// limitScopes returns the scopes whose limits apply to a request: the
+// identifier's own limits, then its limits for the model.
+func limitScopes(identifier, model string) []limitScope {
+	scopes := []limitScope{{key: identifier}}
+	if model != "" {
+		scopes = append(scopes, limitScope{key: modelScopeKey(identifier, model), model: model})
+	}
+	return scopes
+}
+
+// modelScope identifies limits scoped to an identifier's use of a model.
+type modelScope struct {
+	identifier string
+	model      string
+}
+
+// modelScopeKey returns the key of the limits scoped to an identifier's
+// use of a model.
+func modelScopeKey(identifier, model string) string {
+	return identifier + ":model:" + model
+}
+
+// enforcedAncestors returns the keys of the ancestor budgets enforced for
+// an identifier's requests. With InheritNearest, ancestors are enforced
+// only if the identifier has no budget of its own, and then only the
+// nearest ancestor that has one.
+func (m *Manager) enforcedAncestors(identifier string) []string {
+	ancestors := m.budgetAncestors[identifier]
+	if m.budgetInheritance != InheritNearest {
+		return ancestors
+	}
+	if _, ok := m.budgetConfigs[identifier]; ok {
+		return nil
+	}
+	for _, key := range ancestors {
+		if _, ok := m.budgetConfigs[key]; ok {
+			return []string{key}
+		}
+	}
+	return nil
+}
+
+// budgetScope returns the scope of the budget tracked under key. Keys
+// without a configured scope belong to API keys.
+func (m *Manager) budgetScope(key string) BudgetScope {
+	if scope, ok := m.budgetScopes[key]; ok {
+		return scope
+	}
+	return BudgetScope{Dimension: DimensionAPIKey, Identifier: key}
+}
+
+// checkScope checks the limits of one scope. It returns the result to
+// return if a limit is exceeded, or the alert result if a budget alert
+// threshold was reached. A nil reservation previews the check without
+// consuming any capacity.
+// Caller must hold read lock.
+func (m *Manager) checkScope(ctx context.Context, identifier string, s limitScope, estimatedTokens int, model string, reservation *Reservation) (violation, alert *LimitCheckResult, err error) {
+	rateLimiter := m.getRateLimiter(s.key)
+	budgetTracker := m.getBudgetTracker(s.key)
 
 	// Check rate limits (request-based)
 	if rateLimiter != nil {
-		rateLimitResult := rateLimiter.CheckRequest()
+		var rateLimitResult *ratelimit.CheckResult
+		if reservation == nil {
+			rateLimitResult = rateLimiter.PeekRequest()
+		} else {
+			rateLimitResult = rateLimiter.CheckRequest()
+		}
 		if !rateLimitResult.Allowed {
 			// Rate limit exceeded - enforce action
 			enforcementResult, err := m.enforcer.Enforce(
 				ctx,
-				m.enforcementConfig.DefaultAction,
+				m.rateLimitAction(),
 				rateLimitResult.Reason,
 				model,
 				rateLimitResult.RetryAfter,
 			)
 			if err != nil {
-				return nil, fmt.Errorf("enforcement failed: %w", err)
+				return nil, nil, fmt.Errorf("enforcement failed: %w", err)
 			}
 
 			return &LimitCheckResult{
@@ -163,6 +597,7 @@ func (m *Manager) CheckLimits(ctx context.Context, identifier string, estimatedT
 				RateLimit: &RateLimitInfo{
 					Dimension:  string(DimensionAPIKey),
 					Identifier: identifier,
+					Model:      s.model,
 					Limit:      rateLimitResult.Limit,
 					Remaining:  rateLimitResult.Remaining,
 					Reset:      rateLimitResult.Reset,
@@ -171,21 +606,30 @@ func (m *Manager) CheckLimits(ctx context.Context, identifier string, estimatedT
 				Action:      EnforcementAction(enforcementResult.Action),
 				RetryAfter:  enforcementResult.RetryAfter,
 				DowngradeTo: enforcementResult.DowngradedModel,
-			}, nil
+			}, nil, nil
 		}
 
-		// Check token-based limits
-		tokenLimitResult := rateLimiter.CheckTokens(estimatedTokens)
+		// Check token-based limits, reserving the estimated tokens
+		var tokenLimitResult *ratelimit.CheckResult
+		if reservation == nil {
+			tokenLimitResult = rateLimiter.CheckTokens(estimatedTokens)
+		} else {
+			var tokens *ratelimit.TokenReservation
+			tokenLimitResult, tokens = rateLimiter.ReserveTokens(estimatedTokens)
+			if tokens != nil {
+				reservation.tokens[s.key] = tokens
+			}
+		}
 		if !tokenLimitResult.Allowed {
 			enforcementResult, err := m.enforcer.Enforce(
 				ctx,
-				m.enforcementConfig.DefaultAction,
+				m.rateLimitAction(),
 				tokenLimitResult.Reason,
 				model,
 				tokenLimitResult.RetryAfter,
 			)
 			if err != nil {
-				return nil, fmt.Errorf("enforcement failed: %w", err)
+				return nil, nil, fmt.Errorf("enforcement failed: %w", err)
 			}
 
 			return &LimitCheckResult{
@@ -194,6 +638,7 @@ func (m *Manager) CheckLimits(ctx context.Context, identifier string, estimatedT
 				RateLimit: &RateLimitInfo{
 					Dimension:  string(DimensionAPIKey),
 					Identifier: identifier,
+					Model:      s.model,
 					Limit:      tokenLimitResult.Limit,
 					Remaining:  tokenLimitResult.Remaining,
 					Reset:      tokenLimitResult.Reset,
@@ -201,66 +646,162 @@ func (m *Manager) CheckLimits(ctx context.Context, identifier string, estimatedT
 				Action:      EnforcementAction(enforcementResult.Action),
 				RetryAfter:  enforcementResult.RetryAfter,
 				DowngradeTo: enforcementResult.DowngradedModel,
-			}, nil
+			}, nil, nil
 		}
 	}
 
 	// Check budget limits
-	if budgetTracker != nil {
-		budgetStatus := budgetTracker.Check()
-		if !budgetStatus.Allowed {
-			enforcementResult, err := m.enforcer.Enforce(
-				ctx,
-				m.enforcementConfig.DefaultAction,
-				budgetStatus.Reason,
-				model,
-				0, // No retry after for budget limits
-			)
-			if err != nil {
-				return nil, fmt.Errorf("enforcement failed: %w", err)
-			}
+	return m.checkBudget(ctx, budgetTracker, BudgetScope{Dimension: DimensionAPIKey, Identifier: identifier}, s.model, model, reservation != nil)
+}
 
-			return &LimitCheckResult{
-				Allowed: enforcementResult.Allowed,
-				Reason:  budgetStatus.Reason,
-				Budget: &BudgetInfo{
-					Dimension:  string(DimensionAPIKey),
-					Identifier: identifier,
-					Limit:      budgetStatus.Limit,
-					Used:       budgetStatus.Used,
-					Remaining:  budgetStatus.Remaining,
-					Percentage: budgetStatus.Percentage,
-					Reset:      budgetStatus.Reset,
-					Window:     budgetStatus.Window,
-				},
-				Action:      EnforcementAction(enforcementResult.Action),
-				DowngradeTo: enforcementResult.DowngradedModel,
-			}, nil
-		}
+// rateLimitAction returns the action enforced when a rate limit is
+// exceeded. Downgrading applies to budgets only: a cheaper model does not
+// free request or token capacity, so such requests are blocked instead.
+func (m *Manager) rateLimitAction() enforcement.Action {
+	if m.enforcementConfig.DefaultAction == enforcement.ActionDowngrade {
+		return enforcement.ActionBlock
+	}
+	return m.enforcementConfig.DefaultAction
+}
 
-		// Check if alert threshold reached
-		if budgetStatus.AlertTriggered {
-			return &LimitCheckResult{
-				Allowed: true,
-				Budget: &BudgetInfo{
-					Dimension:  string(DimensionAPIKey),
-					Identifier: identifier,
-					Limit:      budgetStatus.Limit,
-					Used:       budgetStatus.Used,
-					Remaining:  budgetStatus.Remaining,
-					Percentage: budgetStatus.Percentage,
-					Reset:      budgetStatus.Reset,
-					Window:     budgetStatus.Window,
-				},
-				Action: ActionAlert,
-			}, nil
+// BudgetWarningMessage reports whether budget warnings are injected into
+// requests as a system message, in addition to the X-Budget-Warning
+// response header.
+func (m *Manager) BudgetWarningMessage() bool {
+	return m.budgetWarningMessage
+}
+
+// DowngradeModel returns the cheaper model configured for model in
+// Enforcement.ModelDowngrades, and whether one is configured.
+func (m *Manager) DowngradeModel(model string) (string, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	downgraded, ok := m.enforcementConfig.ModelDowngrades[model]
+	return downgraded, ok
+}
+
+// checkBudget checks one budget. scopeModel is the model the budget
+// applies to, empty for budgets across all models. Budget alerts are
+// notified only if notify is set.
+// Caller must hold read lock.
+func (m *Manager) checkBudget(ctx context.Context, budgetTracker *budget.Tracker, scope BudgetScope, scopeModel, model string, notify bool) (violation, alert *LimitCheckResult, err error) {
+	if budgetTracker == nil {
+		return nil, nil, nil
+	}
+
+	if m.budgetsStale() {
+		return m.staleBudget(ctx, scope, scopeModel, model)
+	}
+
+	budgetStatus := budgetTracker.Check()
+	if notify && (!budgetStatus.Allowed || budgetStatus.AlertTriggered) {
+		m.notifyBudgetAlert(budgetTracker, scope, scopeModel, budgetStatus)
+	}
+	if !budgetStatus.Allowed {
+		enforcementResult, err := m.enforcer.Enforce(
+			ctx,
+			m.enforcementConfig.DefaultAction,
+			budgetStatus.Reason,
+			model,
+			budgetTracker.TimeUntilAllowed(),
+		)
+		if err != nil {
+			return nil, nil, fmt.Errorf("enforcement failed: %w", err)
 		}
+
+		return &LimitCheckResult{
+			Allowed: enforcementResult.Allowed,
+			Reason:  budgetStatus.Reason,
+			Budget: &BudgetInfo{
+				Dimension:  string(scope.Dimension),
+				Identifier: scope.Identifier,
+				Model:      scopeModel,
+				Limit:      budgetStatus.Limit,
+				Used:       budgetStatus.Used,
+				Remaining:  budgetStatus.Remaining,
+				Percentage: budgetStatus.Percentage,
+				Reset:      budgetStatus.Reset,
+				Window:     budgetStatus.Window,
+			},
+			Action:      EnforcementAction(enforcementResult.Action),
+			DowngradeTo: enforcementResult.DowngradedModel,
+		}, nil, nil
+	}
+
+	// Check if alert threshold reached
+	if budgetStatus.AlertTriggered {
+		return nil, &LimitCheckResult{
+			Allowed: true,
+			Budget: &BudgetInfo{
+				Dimension:  string(scope.Dimension),
+				Identifier: scope.Identifier,
+				Model:      scopeModel,
+				Limit:      budgetStatus.Limit,
+				Used:       budgetStatus.Used,
+				Remaining:  budgetStatus.Remaining,
+				Percentage: budgetStatus.Percentage,
+				Reset:      budgetStatus.Reset,
+				Window:     budgetStatus.Window,
+				InGrace:    budgetStatus.InGrace,
+			},
+			Action: ActionAlert,
+		}, nil
+	}
+
+	return nil, nil, nil
+}
+
+// staleBudget rejects a request against a budget whose state is older than
+// MaxStaleness, since the spending of other replicas may have exhausted it.
+func (m *Manager) staleBudget(ctx context.Context, scope BudgetScope, scopeModel, model string) (violation, alert *LimitCheckResult, err error) {
+	reason := fmt.Sprintf("budget state is stale: not merged with other replicas for %s", m.BudgetStaleness().Round(time.Second))
+	enforcementResult, err := m.enforcer.Enforce(ctx, m.enforcementConfig.DefaultAction, reason, model, m.snapshotInterval)
+	if err != nil {
+		return nil, nil, fmt.Errorf("enforcement failed: %w", err)
 	}
 
-	// All limits passed
 	return &LimitCheckResult{
-		Allowed: true,
-	}, nil
+		Allowed: enforcementResult.Allowed,
+		Reason:  reason,
+		Budget: &BudgetInfo{
+			Dimension:  string(scope.Dimension),
+			Identifier: scope.Identifier,
+			Model:      scopeModel,
+		},
+		Action:      EnforcementAction(enforcementResult.Action),
+		DowngradeTo: enforcementResult.DowngradedModel,
+	}, nil, nil
+}
+
+// notifyBudgetAlert notifies the alert notifier of a budget past its alert
+// threshold or limit.
+func (m *Manager) notifyBudgetAlert(budgetTracker *budget.Tracker, scope BudgetScope, scopeModel string, status *budget.Status) {
+	if m.alertNotifier == nil {
+		return
+	}
+
+	level := AlertWarning
+	switch {
+	case !status.Allowed:
+		level = AlertExceeded
+	case status.InGrace:
+		level = AlertGrace
+	}
+	m.alertNotifier.NotifyBudgetAlert(BudgetAlert{
+		Level:      level,
+		Dimension:  scope.Dimension,
+		Identifier: scope.Identifier,
+		Model:      scopeModel,
+		Window:     budget.WindowName(status.Window),
+		Limit:      status.Limit,
+		Used:       status.Used,
+		Remaining:  status.Remaining,
+		Percentage: status.Percentage,
+		Threshold:  budgetTracker.Config().AlertThreshold,
+		Reset:      status.Reset,
+		Time:       time.Now(),
+	})
 }
 
 // RecordUsage records actual usage after a request completes.
@@ -277,28 +818,57 @@ func (m *Manager) RecordUsage(ctx context.Context, record *UsageRecord) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	identifier := record.Identifier
+	// Record against the identifier's limits and its limits for the model
+	for _, s := range limitScopes(record.Identifier, record.Model) {
+		// Record tokens for rate limiting, settling the tokens reserved
+		// by CheckLimits
+		rateLimiter := m.getRateLimiter(s.key)
+		if rateLimiter != nil {
+			if tokens := record.Reservation.take(s.key); tokens != nil {
+				rateLimiter.ReconcileTokens(tokens, record.TotalTokens)
+			} else {
+				rateLimiter.RecordTokens(record.TotalTokens)
+			}
+		}
 
-	// Record tokens for rate limiting
-	rateLimiter := m.getRateLimiter(identifier)
-	if rateLimiter != nil {
-		rateLimiter.RecordTokens(record.TotalTokens)
+		// Record cost for budget tracking. It is persisted by the next snapshot.
+		budgetTracker := m.getBudgetTracker(s.key)
+		if budgetTracker != nil {
+			budgetTracker.Add(record.Cost)
+		}
 	}
 
-	// Record cost for budget tracking
-	budgetTracker := m.getBudgetTracker(identifier)
-	if budgetTracker != nil {
-		budgetTracker.Add(record.Cost)
+	// Charge the identifier's ancestors, whether or not their budgets are
+	// enforced
+	for _, key := range m.budgetAncestors[record.Identifier] {
+		if budgetTracker := m.getBudgetTracker(key); budgetTracker != nil {
+			budgetTracker.Add(record.Cost)
+		}
 	}
 
-	// Persist state to storage (async to avoid blocking)
-	go func() {
-		_ = m.persistState(context.Background(), identifier)
-	}()
+	// Add the record to the usage history. It is persisted by the next
+	// snapshot.
+	m.recordHistory(record)
+
+	// Release reservations of scopes the record did not cover
+	m.releaseReservation(record.Reservation)
+
+	// Unused reserved tokens were refunded
+	m.queue.Notify()
 
 	return nil
 }
 
+// take removes and returns the token reservation of a rate limiter.
+func (r *Reservation) take(key string) *ratelimit.TokenReservation {
+	if r == nil {
+		return nil
+	}
+	tokens := r.tokens[key]
+	delete(r.tokens, key)
+	return tokens
+}
+
 // AcquireConcurrent attempts to acquire a concurrent request slot.
 // Returns true if acquired, false if the concurrent limit is reached.
 //
@@ -325,16 +895,316 @@ func (m *Manager) ReleaseConcurrent(identifier string) {
 	if rateLimiter != nil {
 		rateLimiter.ReleaseConcurrent()
 	}
+	m.queue.Notify()
 }
 
-// Close releases any resources held by the manager.
+// WaitForCapacity queues a request that CheckLimits rejected with
+// ActionQueue until it passes all limits, returning the result of the
+// check that admitted it. Requests wait in priority order (see
+// enforcement.Config.PriorityTiers and QueuePriorities), sharing capacity
+// fairly between identifiers of the same priority, for at most the queue
+// timeout or the context deadline.
+//
+// If the request cannot be admitted in time, or the queue is full, the
+// rejection is returned with ActionBlock. Results with other actions are
+// returned unchanged.
+func (m *Manager) WaitForCapacity(ctx context.Context, identifier string, estimatedTokens int, estimatedCost float64, model string, rejected *LimitCheckResult) (*LimitCheckResult, error) {
+	if m.queue == nil || rejected.Allowed || rejected.Action != ActionQueue {
+		return rejected, nil
+	}
+
+	// Budgets only free up as spending leaves their windows, while
+	// reserved tokens can be refunded at any time
+	var retryAfter time.Duration
+	if rejected.Budget != nil {
+		retryAfter = rejected.RetryAfter
+	}
+
+	var (
+		admitted *LimitCheckResult
+		checkErr error
+	)
+	err := m.queue.Wait(ctx, m.queuePriority(ctx, identifier), identifier, retryAfter, func() (bool, time.Duration) {
+		// Probe first: a failed check consumes request rate limit tokens
+		m.mu.RLock()
+		wait := m.timeUntilAllowed(identifier, estimatedTokens, model)
+		m.mu.RUnlock()
+		if wait > 0 {
+			return false, wait
+		}
+
+		result, err := m.CheckLimits(ctx, identifier, estimatedTokens, estimatedCost, model)
+		if err != nil {
+			checkErr = err
+			return true, 0
+		}
+		if result.Allowed {
+			admitted = result
+			return true, 0
+		}
+		return false, result.RetryAfter
+	})
+	if err != nil {
+		blocked := *rejected
+		blocked.Action = ActionBlock
+		blocked.Reason = fmt.Sprintf("%s (%v)", rejected.Reason, err)
+		return &blocked, nil
+	}
+	if checkErr != nil {
+		return nil, checkErr
+	}
+	return admitted, nil
+}
+
+// WaitForConcurrent acquires a concurrent request slot like
+// AcquireConcurrent. With the queue enforcement action, a request over the
+// concurrent limit waits in the queue for a slot to be released. Returns
+// false if no slot was acquired.
+//
+// If this returns true, the caller MUST call ReleaseConcurrent() when done.
+func (m *Manager) WaitForConcurrent(ctx context.Context, identifier string) bool {
+	if m.AcquireConcurrent(identifier) {
+		return true
+	}
+	if m.queue == nil {
+		return false
+	}
+
+	err := m.queue.Wait(ctx, m.queuePriority(ctx, identifier), identifier, 0, func() (bool, time.Duration) {
+		return m.AcquireConcurrent(identifier), 0
+	})
+	return err == nil
+}
+
+// Close writes a final limit snapshot and releases any resources held by
+// the manager. Close is idempotent.
 func (m *Manager) Close() error {
-	if m.storage != nil {
-		return m.storage.Close()
+	var err error
+	m.closeOnce.Do(func() {
+		close(m.done)
+		<-m.loopDone
+		m.stopOverrides()
+		if m.queue != nil {
+			m.queue.Close()
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+		if snapErr := m.Snapshot(ctx); snapErr != nil {
+			m.logger.Warn("failed to write final limit snapshot", "error", snapErr)
+		}
+		cancel()
+
+		if closer, ok := m.alertNotifier.(io.Closer); ok {
+			closer.Close()
+		}
+
+		if m.storage != nil {
+			err = m.storage.Close()
+		}
+	})
+	return err
+}
+
+// Snapshot writes the budget and rate limit state of all identifiers to
+// storage.
+//
+// With a storage.BudgetMerger backend, the spending recorded since the
+// previous snapshot is merged into the shared state and the trackers are
+// updated with the merged totals of all replicas. Otherwise the full state
+// of each tracker with new spending is saved, along with the state of the
+// identifier's rate limiters (see persistsRateLimits). Spending that fails
+// to persist is retried by the next snapshot.
+//
+// With a storage.UsageStore backend, the usage recorded since the previous
+// snapshot is also added to the usage history (see UsageHistory).
+func (m *Manager) Snapshot(ctx context.Context) error {
+	m.mu.RLock()
+	trackers := make(map[string]*budget.Tracker, len(m.budgets))
+	for identifier, tracker := range m.budgets {
+		trackers[identifier] = tracker
+	}
+	limiters := make(map[string]*ratelimit.Limiter)
+	if m.persistsRateLimits() {
+		for identifier, limiter := range m.rateLimiters {
+			limiters[identifier] = limiter
+		}
+	}
+	m.mu.RUnlock()
+	started := time.Now()
+
+	keys := make(map[string]bool, len(trackers)+len(limiters))
+	for key := range trackers {
+		keys[key] = true
+	}
+	for key := range limiters {
+		keys[key] = true
+	}
+
+	var firstErr error
+	failed := 0
+	for key := range keys {
+		if err := m.snapshotKey(ctx, key, trackers[key], limiters[key]); err != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	historyErr := m.flushHistory(ctx)
+	if firstErr != nil {
+		return errors.Join(fmt.Errorf("failed to snapshot %d of %d limit states: %w", failed, len(keys), firstErr), historyErr)
+	}
+	m.lastMerged.Store(started.UnixNano())
+	return historyErr
+}
+
+// BudgetStaleness returns how long ago every budget was last merged with
+// the shared state of other replicas, or 0 if budgets are not shared
+// (the backend is not a storage.BudgetMerger). Spending by other replicas
+// since then is not reflected in this replica's budgets.
+func (m *Manager) BudgetStaleness() time.Duration {
+	if _, ok := m.storage.(storage.BudgetMerger); !ok {
+		return 0
+	}
+	return time.Since(time.Unix(0, m.lastMerged.Load()))
+}
+
+// budgetsStale reports whether budget state is older than MaxStaleness.
+func (m *Manager) budgetsStale() bool {
+	return m.maxStaleness > 0 && m.BudgetStaleness() > m.maxStaleness
+}
+
+// persistsRateLimits reports whether rate limiter state is snapshotted.
+// It is only for in-process limiters with a backend that is not shared
+// between replicas: each replica enforces its own rate limits, and limits
+// kept in a shared RateLimitStore outlive the process already.
+func (m *Manager) persistsRateLimits() bool {
+	if m.rateLimitStore != nil {
+		return false
+	}
+	_, shared := m.storage.(storage.BudgetMerger)
+	return !shared
+}
+
+// snapshotKey persists the budget tracker and rate limiter of one key.
+// Either may be nil.
+func (m *Manager) snapshotKey(ctx context.Context, key string, tracker *budget.Tracker, limiter *ratelimit.Limiter) error {
+	scope := m.budgetScope(key)
+	identifier, dimension := scope.Identifier, string(scope.Dimension)
+
+	if merger, ok := m.storage.(storage.BudgetMerger); ok {
+		if tracker == nil {
+			return nil
+		}
+		pending := tracker.TakePending()
+		merged, err := merger.MergeBudget(ctx, identifier, dimension, budgetStateFromUsage(pending))
+		if err != nil {
+			tracker.RequeuePending(pending)
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		tracker.Restore(usageFromBudgetState(merged))
+		return nil
+	}
+
+	var pending *budget.Usage
+	if tracker != nil {
+		pending = tracker.TakePending()
+	}
+	var rateState *ratelimit.State
+	if limiter != nil {
+		rateState = limiter.State()
+	}
+	if (pending == nil || isEmptyUsage(pending)) && (rateState == nil || rateState.IsEmpty()) {
+		return nil
+	}
+
+	state := &storage.LimitState{
+		Identifier:  identifier,
+		Dimension:   dimension,
+		LastUpdated: time.Now(),
+	}
+	if tracker != nil {
+		state.Budget = budgetStateFromUsage(tracker.Usage())
+	}
+	if rateState != nil {
+		state.RateLimit = rateLimitStateFromLimiter(rateState)
+	}
+	if err := m.storage.Save(ctx, state); err != nil {
+		if pending != nil {
+			tracker.RequeuePending(pending)
+		}
+		return fmt.Errorf("%s: %w", key, err)
 	}
 	return nil
 }
 
+// restoreState loads persisted budget and rate limit state into the
+// trackers and limiters.
+func (m *Manager) restoreState(ctx context.Context) {
+	merger, isMerger := m.storage.(storage.BudgetMerger)
+
+	for key, tracker := range m.budgets {
+		scope := m.budgetScope(key)
+		identifier, dimension := scope.Identifier, string(scope.Dimension)
+		var (
+			state *storage.BudgetState
+			err   error
+		)
+		if isMerger {
+			state, err = merger.MergeBudget(ctx, identifier, dimension, nil)
+		} else {
+			var limitState *storage.LimitState
+			limitState, err = m.storage.Load(ctx, identifier, dimension)
+			if limitState != nil {
+				state = limitState.Budget
+			}
+		}
+		if err != nil {
+			m.logger.Warn("failed to restore budget state", "budget", key, "error", err)
+			continue
+		}
+		if state != nil {
+			tracker.Restore(usageFromBudgetState(state))
+		}
+	}
+
+	if !m.persistsRateLimits() {
+		return
+	}
+	for key, limiter := range m.rateLimiters {
+		scope := m.budgetScope(key)
+		limitState, err := m.storage.Load(ctx, scope.Identifier, string(scope.Dimension))
+		if err != nil {
+			m.logger.Warn("failed to restore rate limit state", "identifier", key, "error", err)
+			continue
+		}
+		if limitState != nil && limitState.RateLimit != nil {
+			limiter.Restore(limiterStateFromRateLimitState(limitState.RateLimit))
+		}
+	}
+}
+
+// snapshotLoop snapshots limit state until the manager is closed.
+func (m *Manager) snapshotLoop() {
+	defer close(m.loopDone)
+
+	ticker := time.NewTicker(m.snapshotInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+			if err := m.Snapshot(ctx); err != nil {
+				m.logger.Warn("failed to snapshot limit state", "error", err)
+			}
+			cancel()
+		case <-m.done:
+			return
+		}
+	}
+}
+
 // getRateLimiter gets the rate limiter for an identifier (creates if needed).
 // Caller must hold read or write lock.
 func (m *Manager) getRateLimiter(identifier string) *ratelimit.Limiter {
@@ -347,7 +1217,7 @@ func (m *Manager) getRateLimiter(identifier string) *ratelimit.Limiter {
 		}
 
 		// Create new limiter (upgrade to write lock would be needed in production)
-		limiter = ratelimit.NewLimiter(config)
+		limiter = ratelimit.NewLimiterWithStore(identifier, config, m.rateLimitStore)
 		m.rateLimiters[identifier] = limiter
 	}
 	return limiter
@@ -371,16 +1241,96 @@ func (m *Manager) getBudgetTracker(identifier string) *budget.Tracker {
 	return tracker
 }
 
-// persistState saves the current state to storage.
-func (m *Manager) persistState(ctx context.Context, identifier string) error {
-	// Build state from current limiters/trackers
-	// This is a simplified version - full implementation would serialize all state
-	state := &storage.LimitState{
-		Identifier: identifier,
-		Dimension:  string(DimensionAPIKey),
-		RateLimit:  nil, // TODO: Serialize rate limit state
-		Budget:     nil, // TODO: Serialize budget state
+// budgetStateFromUsage converts tracker usage to its storage form.
+func budgetStateFromUsage(usage *budget.Usage) *storage.BudgetState {
+	return &storage.BudgetState{
+		HourlyBuckets:  storageBuckets(usage.Hourly),
+		DailyBuckets:   storageBuckets(usage.Daily),
+		MonthlyBuckets: storageBuckets(usage.Monthly),
+		TotalSpent:     usage.Total,
+	}
+}
+
+// usageFromBudgetState converts stored budget state to tracker usage.
+func usageFromBudgetState(state *storage.BudgetState) *budget.Usage {
+	return &budget.Usage{
+		Hourly:  budgetBuckets(state.HourlyBuckets),
+		Daily:   budgetBuckets(state.DailyBuckets),
+		Monthly: budgetBuckets(state.MonthlyBuckets),
+		Total:   state.TotalSpent,
+	}
+}
+
+func storageBuckets(buckets []budget.Bucket) []storage.BudgetBucket {
+	out := make([]storage.BudgetBucket, len(buckets))
+	for i, b := range buckets {
+		out[i] = storage.BudgetBucket{Timestamp: b.Start, Amount: b.Amount}
+	}
+	return out
+}
+
+func budgetBuckets(buckets []storage.BudgetBucket) []budget.Bucket {
+	out := make([]budget.Bucket, len(buckets))
+	for i, b := range buckets {
+		out[i] = budget.Bucket{Start: b.Timestamp, Amount: b.Amount}
+	}
+	return out
+}
+
+// rateLimitStateFromLimiter converts limiter state to its storage form.
+func rateLimitStateFromLimiter(state *ratelimit.State) *storage.RateLimitState {
+	out := &storage.RateLimitState{
+		Buckets: make(map[string]*storage.TokenBucketState, len(state.Buckets)),
+		Windows: make(map[string]*storage.SlidingWindowState, len(state.Windows)),
+	}
+	for name, b := range state.Buckets {
+		out.Buckets[name] = &storage.TokenBucketState{
+			Tokens:     b.Tokens,
+			LastRefill: b.LastRefill,
+			Algorithm:  b.Algorithm,
+			TAT:        b.TAT,
+		}
+	}
+	for name, buckets := range state.Windows {
+		window := &storage.SlidingWindowState{Buckets: make([]storage.WindowBucket, len(buckets))}
+		for i, b := range buckets {
+			window.Buckets[i] = storage.WindowBucket{Timestamp: b.Start, Value: b.Value}
+		}
+		out.Windows[name] = window
 	}
+	return out
+}
+
+// limiterStateFromRateLimitState converts stored rate limit state to
+// limiter state.
+func limiterStateFromRateLimitState(state *storage.RateLimitState) *ratelimit.State {
+	out := &ratelimit.State{
+		Buckets: make(map[string]ratelimit.BucketState, len(state.Buckets)),
+		Windows: make(map[string][]ratelimit.WindowBucket, len(state.Windows)),
+	}
+	for name, b := range state.Buckets {
+		algorithm := b.Algorithm
+		if algorithm == "" {
+			algorithm = ratelimit.AlgorithmTokenBucket
+		}
+		out.Buckets[name] = ratelimit.BucketState{
+			Algorithm:  algorithm,
+			Tokens:     b.Tokens,
+			LastRefill: b.LastRefill,
+			TAT:        b.TAT,
+		}
+	}
+	for name, window := range state.Windows {
+		buckets := make([]ratelimit.WindowBucket, len(window.Buckets))
+		for i, b := range window.Buckets {
+			buckets[i] = ratelimit.WindowBucket{Start: b.Timestamp, Value: b.Value}
+		}
+		out.Windows[name] = buckets
+	}
+	return out
+}
 
-	return m.storage.Save(ctx, state)
+// isEmptyUsage reports whether usage records no spending.
+func isEmptyUsage(usage *budget.Usage) bool {
+	return usage.Total == 0 && len(usage.Hourly) == 0 && len(usage.Daily) == 0 && len(usage.Monthly) == 0
 }
@@ -2,12 +2,18 @@ package limits
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
 	"mercator-hq/jupiter/pkg/limits/budget"
 	"mercator-hq/jupiter/pkg/limits/enforcement"
 	"mercator-hq/jupiter/pkg/limits/ratelimit"
+	"mercator-hq/jupiter/pkg/limits/storage"
 )
 
 func TestNewManager_Basic(t *testing.T) {
@@ -218,9 +224,6 @@ func TestManager_RecordUsage(t *testing.T) {
 		t.Fatalf("RecordUsage failed: %v", err)
 	}
 
-	// Give async persistence a moment
-	time.Sleep(10 * time.Millisecond)
-
 	// Verify usage was recorded (check via budget tracker)
 	tracker := manager.budgets["test-key"]
 	if tracker == nil {
@@ -233,6 +236,575 @@ func TestManager_RecordUsage(t *testing.T) {
 	}
 }
 
+func TestManager_CheckLimits_RetryAfter(t *testing.T) {
+	manager := NewManager(Config{
+		RateLimits: map[string]ratelimit.Config{
+			"test-key": {TokensPerMinute: 1000},
+		},
+		Budgets: map[string]budget.Config{
+			"test-key": {Daily: 10.00},
+		},
+		Enforcement: enforcement.Config{DefaultAction: enforcement.ActionBlock},
+	})
+	defer manager.Close()
+	ctx := context.Background()
+
+	// Tokens leave the per-minute window within a minute
+	_ = manager.RecordUsage(ctx, &UsageRecord{Identifier: "test-key", TotalTokens: 1000, Cost: 1.00})
+	result, _ := manager.CheckLimits(ctx, "test-key", 100, 0, "gpt-4")
+	if result.Allowed {
+		t.Fatal("Expected request to be blocked by the token limit")
+	}
+	if result.RetryAfter < 59*time.Second || result.RetryAfter > time.Minute {
+		t.Errorf("Expected to retry within a minute, got %v", result.RetryAfter)
+	}
+
+	// A request blocked by the token limit must also wait for the budget
+	_ = manager.RecordUsage(ctx, &UsageRecord{Identifier: "test-key", Cost: 15.00})
+	result, _ = manager.CheckLimits(ctx, "test-key", 100, 0, "gpt-4")
+	if result.Allowed || result.RateLimit == nil {
+		t.Fatalf("Expected request to be blocked by the token limit, got %+v", result)
+	}
+	if result.RetryAfter < 23*time.Hour || result.RetryAfter > 24*time.Hour {
+		t.Errorf("Expected to retry once the spending leaves the daily window, got %v", result.RetryAfter)
+	}
+}
+
+func TestManager_WaitForCapacity(t *testing.T) {
+	manager := NewManager(Config{
+		RateLimits: map[string]ratelimit.Config{
+			"test-key": {TokensPerMinute: 1000, MaxConcurrent: 1},
+		},
+		Budgets: map[string]budget.Config{
+			"test-key": {Daily: 10.00},
+		},
+		Enforcement: enforcement.Config{
+			DefaultAction: enforcement.ActionQueue,
+			QueueTimeout:  time.Second,
+		},
+	})
+	defer manager.Close()
+	ctx := context.Background()
+
+	first, _ := manager.CheckLimits(ctx, "test-key", 800, 0, "gpt-4")
+	if !first.Allowed {
+		t.Fatal("Expected first request to be allowed")
+	}
+	second, _ := manager.CheckLimits(ctx, "test-key", 300, 0, "gpt-4")
+	if second.Allowed || second.Action != ActionQueue {
+		t.Fatalf("Expected second request to be queued, got %+v", second)
+	}
+
+	// The queued request is admitted once the first one refunds its tokens
+	admitted := make(chan *LimitCheckResult, 1)
+	go func() {
+		result, _ := manager.WaitForCapacity(ctx, "test-key", 300, 0, "gpt-4", second)
+		admitted <- result
+	}()
+	time.Sleep(20 * time.Millisecond)
+	_ = manager.RecordUsage(ctx, &UsageRecord{Identifier: "test-key", TotalTokens: 200, Reservation: first.Reservation})
+
+	select {
+	case result := <-admitted:
+		if !result.Allowed || result.Reservation == nil {
+			t.Errorf("Expected queued request to be admitted with a reservation, got %+v", result)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected queued request to be admitted")
+	}
+
+	// Requests over the concurrent limit wait for a slot
+	if !manager.WaitForConcurrent(ctx, "test-key") {
+		t.Fatal("Expected a concurrent slot")
+	}
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		manager.ReleaseConcurrent("test-key")
+	}()
+	if !manager.WaitForConcurrent(ctx, "test-key") {
+		t.Error("Expected to acquire the released slot")
+	}
+	manager.ReleaseConcurrent("test-key")
+
+	// Requests that cannot pass before the queue timeout are rejected
+	_ = manager.RecordUsage(ctx, &UsageRecord{Identifier: "test-key", Cost: 15.00})
+	rejected, _ := manager.CheckLimits(ctx, "test-key", 0, 0, "gpt-4")
+	result, err := manager.WaitForCapacity(ctx, "test-key", 0, 0, "gpt-4", rejected)
+	if err != nil {
+		t.Fatalf("WaitForCapacity failed: %v", err)
+	}
+	if result.Allowed || result.Action != ActionBlock {
+		t.Errorf("Expected request over the daily budget to be blocked, got %+v", result)
+	}
+}
+
+func TestManager_TokenReservations(t *testing.T) {
+	manager := NewManager(Config{
+		RateLimits: map[string]ratelimit.Config{
+			"test-key": {TokensPerMinute: 1000},
+		},
+		Enforcement: enforcement.Config{DefaultAction: enforcement.ActionBlock},
+	})
+	defer manager.Close()
+	ctx := context.Background()
+
+	// In-flight requests hold their estimated tokens
+	first, _ := manager.CheckLimits(ctx, "test-key", 600, 0, "gpt-4")
+	if !first.Allowed || first.Reservation == nil {
+		t.Fatalf("Expected first request to be allowed with a reservation, got %+v", first)
+	}
+	second, _ := manager.CheckLimits(ctx, "test-key", 600, 0, "gpt-4")
+	if second.Allowed {
+		t.Fatal("Expected concurrent request to be blocked by the reserved tokens")
+	}
+
+	// The actual usage replaces the estimate
+	_ = manager.RecordUsage(ctx, &UsageRecord{
+		Identifier:  "test-key",
+		Model:       "gpt-4",
+		TotalTokens: 150,
+		Reservation: first.Reservation,
+	})
+	third, _ := manager.CheckLimits(ctx, "test-key", 600, 0, "gpt-4")
+	if !third.Allowed {
+		t.Fatal("Expected request to be allowed after unused tokens were refunded")
+	}
+
+	// Requests that are not served release their reservation
+	manager.ReleaseReservation(third.Reservation)
+	manager.ReleaseReservation(third.Reservation)
+	if result := manager.rateLimiters["test-key"].CheckTokens(850); !result.Allowed {
+		t.Errorf("Expected only the 150 recorded tokens to count, got %d remaining", result.Remaining)
+	}
+}
+
+func TestManager_PreviewLimits(t *testing.T) {
+	manager := NewManager(Config{
+		RateLimits: map[string]ratelimit.Config{
+			"test-key": {RequestsPerMinute: 1, TokensPerMinute: 1000},
+		},
+		Budgets: map[string]budget.Config{
+			"test-key": {Daily: 10.00},
+		},
+		Enforcement: enforcement.Config{DefaultAction: enforcement.ActionBlock},
+	})
+	defer manager.Close()
+	ctx := context.Background()
+
+	// Previews consume no capacity
+	for i := 0; i < 3; i++ {
+		result, err := manager.PreviewLimits(ctx, "test-key", 600, 0.05, "gpt-4")
+		if err != nil {
+			t.Fatalf("PreviewLimits failed: %v", err)
+		}
+		if !result.Allowed || result.Reservation != nil {
+			t.Fatalf("Expected preview %d to be allowed without a reservation, got %+v", i+1, result)
+		}
+	}
+	if result, _ := manager.PreviewLimits(ctx, "test-key", 1500, 0.05, "gpt-4"); result.Allowed {
+		t.Error("Expected preview over the token limit to be blocked")
+	}
+
+	first, _ := manager.CheckLimits(ctx, "test-key", 600, 0.05, "gpt-4")
+	if !first.Allowed {
+		t.Fatalf("Expected request to be allowed after previews, got %+v", first)
+	}
+	result, _ := manager.PreviewLimits(ctx, "test-key", 100, 0.05, "gpt-4")
+	if result.Allowed || result.RateLimit == nil || result.RetryAfter <= 0 {
+		t.Errorf("Expected preview to be blocked by the request limit with a retry after, got %+v", result)
+	}
+
+	_ = manager.RecordUsage(ctx, &UsageRecord{Identifier: "test-key", Cost: 15.00, Reservation: first.Reservation})
+	manager.rateLimiters["test-key"].Reset()
+	result, _ = manager.PreviewLimits(ctx, "test-key", 100, 0.05, "gpt-4")
+	if result.Allowed || result.Budget == nil {
+		t.Errorf("Expected preview to be blocked by the budget, got %+v", result)
+	}
+}
+
+func TestManager_ModelRateLimits(t *testing.T) {
+	manager := NewManager(Config{
+		RateLimits: map[string]ratelimit.Config{
+			"test-key": {RequestsPerSecond: 100},
+		},
+		ModelRateLimits: map[string]map[string]ratelimit.Config{
+			"test-key": {"o1": {RequestsPerSecond: 1}},
+		},
+		Enforcement: enforcement.Config{DefaultAction: enforcement.ActionBlock},
+	})
+	defer manager.Close()
+	ctx := context.Background()
+
+	// Exhaust the o1 limit (burst capacity is 2x, so 2 requests)
+	for i := 0; i < 2; i++ {
+		result, _ := manager.CheckLimits(ctx, "test-key", 0, 0, "o1")
+		if !result.Allowed {
+			t.Fatalf("Expected o1 request %d to be allowed", i+1)
+		}
+	}
+
+	result, err := manager.CheckLimits(ctx, "test-key", 0, 0, "o1")
+	if err != nil {
+		t.Fatalf("CheckLimits failed: %v", err)
+	}
+	if result.Allowed {
+		t.Error("Expected o1 request to be blocked by its model limit")
+	}
+	if result.RateLimit == nil || result.RateLimit.Model != "o1" || result.RateLimit.Identifier != "test-key" {
+		t.Errorf("Expected rate limit info for test-key and o1, got %+v", result.RateLimit)
+	}
+
+	// Other models only have the key limit
+	result, _ = manager.CheckLimits(ctx, "test-key", 0, 0, "gpt-4o-mini")
+	if !result.Allowed {
+		t.Error("Expected gpt-4o-mini request to be allowed")
+	}
+}
+
+func TestManager_ModelBudgets(t *testing.T) {
+	manager := NewManager(Config{
+		Budgets: map[string]budget.Config{
+			"test-key": {Daily: 100.00},
+		},
+		ModelBudgets: map[string]map[string]budget.Config{
+			"test-key": {"o1": {Daily: 5.00}},
+		},
+		Enforcement: enforcement.Config{DefaultAction: enforcement.ActionBlock},
+	})
+	defer manager.Close()
+	ctx := context.Background()
+
+	_ = manager.RecordUsage(ctx, &UsageRecord{Identifier: "test-key", Model: "o1", Cost: 6.00})
+	_ = manager.RecordUsage(ctx, &UsageRecord{Identifier: "test-key", Model: "gpt-4o-mini", Cost: 1.00})
+
+	result, _ := manager.CheckLimits(ctx, "test-key", 0, 0, "o1")
+	if result.Allowed {
+		t.Error("Expected o1 request to be blocked by its model budget")
+	}
+	if result.Budget == nil || result.Budget.Model != "o1" || result.Budget.Used != 6.00 {
+		t.Errorf("Expected o1 budget info with 6.00 used, got %+v", result.Budget)
+	}
+
+	result, _ = manager.CheckLimits(ctx, "test-key", 0, 0, "gpt-4o-mini")
+	if !result.Allowed {
+		t.Error("Expected gpt-4o-mini request to be allowed")
+	}
+
+	// The key budget covers spending on all models
+	if used := manager.budgets["test-key"].GetDailyStatus().Used; used != 7.00 {
+		t.Errorf("Expected key daily usage 7.00, got %.2f", used)
+	}
+}
+
+func TestManager_HierarchicalBudgets(t *testing.T) {
+	team := BudgetScope{Dimension: DimensionTeam, Identifier: "platform"}
+	org := BudgetScope{Dimension: DimensionOrg, Identifier: "acme"}
+	manager := NewManager(Config{
+		Budgets: map[string]budget.Config{
+			"key-a": {Daily: 100.00},
+			"key-b": {Daily: 100.00},
+		},
+		ScopeBudgets: map[BudgetScope]budget.Config{
+			team: {Daily: 10.00, AlertThreshold: 0.5},
+			org:  {Daily: 50.00},
+		},
+		BudgetAncestors: map[string][]BudgetScope{
+			"key-a": {team, org},
+			"key-b": {team, org},
+		},
+		Enforcement: enforcement.Config{DefaultAction: enforcement.ActionBlock},
+	})
+	defer manager.Close()
+	ctx := context.Background()
+
+	_ = manager.RecordUsage(ctx, &UsageRecord{Identifier: "key-a", Cost: 6.00})
+
+	// key-b has spent nothing, but its team has reached its alert threshold
+	result, _ := manager.CheckLimits(ctx, "key-b", 0, 0, "gpt-4")
+	if !result.Allowed || result.Action != ActionAlert {
+		t.Fatalf("Expected team alert, got %+v", result)
+	}
+	if result.Budget.Dimension != string(DimensionTeam) || result.Budget.Identifier != "platform" {
+		t.Errorf("Expected team budget info, got %+v", result.Budget)
+	}
+
+	_ = manager.RecordUsage(ctx, &UsageRecord{Identifier: "key-b", Cost: 5.00})
+
+	// Both keys are within their own budgets but the team budget is spent
+	for _, key := range []string{"key-a", "key-b"} {
+		result, _ = manager.CheckLimits(ctx, key, 0, 0, "gpt-4")
+		if result.Allowed {
+			t.Errorf("Expected %s to be blocked by the team budget", key)
+		}
+		if result.Budget == nil || result.Budget.Dimension != string(DimensionTeam) {
+			t.Errorf("Expected team budget info, got %+v", result.Budget)
+		}
+	}
+
+	// Spending is recorded at every level
+	if used := manager.budgets[org.Key()].GetDailyStatus().Used; used != 11.00 {
+		t.Errorf("Expected org daily usage 11.00, got %.2f", used)
+	}
+}
+
+func TestManager_HierarchicalBudgets_InheritNearest(t *testing.T) {
+	team := BudgetScope{Dimension: DimensionTeam, Identifier: "platform"}
+	manager := NewManager(Config{
+		Budgets: map[string]budget.Config{
+			"own-budget": {Daily: 100.00},
+		},
+		ScopeBudgets: map[BudgetScope]budget.Config{
+			team: {Daily: 10.00},
+		},
+		BudgetAncestors: map[string][]BudgetScope{
+			"own-budget": {team},
+			"no-budget":  {team},
+		},
+		BudgetInheritance: InheritNearest,
+		Enforcement:       enforcement.Config{DefaultAction: enforcement.ActionBlock},
+	})
+	defer manager.Close()
+	ctx := context.Background()
+
+	_ = manager.RecordUsage(ctx, &UsageRecord{Identifier: "own-budget", Cost: 20.00})
+
+	// The key's own budget overrides the team budget
+	result, _ := manager.CheckLimits(ctx, "own-budget", 0, 0, "gpt-4")
+	if !result.Allowed {
+		t.Errorf("Expected key with its own budget to be allowed, got %+v", result.Budget)
+	}
+
+	// A key without a budget falls back to the team budget
+	result, _ = manager.CheckLimits(ctx, "no-budget", 0, 0, "gpt-4")
+	if result.Allowed {
+		t.Error("Expected key without a budget to be blocked by the team budget")
+	}
+}
+
+func TestManager_SnapshotSurvivesRestart(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "limits.db")
+	config := Config{
+		Budgets: map[string]budget.Config{
+			"test-key": {Daily: 100.00},
+		},
+	}
+
+	backend, err := storage.NewSQLiteBackend(dbPath)
+	if err != nil {
+		t.Fatalf("NewSQLiteBackend failed: %v", err)
+	}
+	config.Storage = backend
+	manager := NewManager(config)
+	_ = manager.RecordUsage(context.Background(), &UsageRecord{Identifier: "test-key", Cost: 7.50})
+
+	// Close writes a final snapshot
+	if err := manager.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	backend, err = storage.NewSQLiteBackend(dbPath)
+	if err != nil {
+		t.Fatalf("NewSQLiteBackend failed: %v", err)
+	}
+	config.Storage = backend
+	restarted := NewManager(config)
+	defer restarted.Close()
+
+	status := restarted.budgets["test-key"].GetDailyStatus()
+	if status.Used != 7.50 {
+		t.Errorf("Expected restored daily usage 7.50, got %.2f", status.Used)
+	}
+}
+
+func TestManager_SnapshotRestoresRateLimits(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "limits.json")
+	config := Config{
+		RateLimits: map[string]ratelimit.Config{
+			"test-key": {RequestsPerMinute: 2},
+		},
+		Enforcement: enforcement.Config{DefaultAction: enforcement.ActionBlock},
+	}
+	ctx := context.Background()
+
+	config.Storage = storage.NewMemoryBackendWithConfig(storage.MemoryBackendConfig{SnapshotPath: path})
+	manager := NewManager(config)
+	for i := 0; i < 2; i++ {
+		if result, _ := manager.CheckLimits(ctx, "test-key", 0, 0, "gpt-4"); !result.Allowed {
+			t.Fatalf("Request %d: expected to be allowed", i+1)
+		}
+	}
+	if err := manager.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// A restart does not reset the window
+	config.Storage = storage.NewMemoryBackendWithConfig(storage.MemoryBackendConfig{SnapshotPath: path})
+	restarted := NewManager(config)
+	defer restarted.Close()
+
+	if result, _ := restarted.CheckLimits(ctx, "test-key", 0, 0, "gpt-4"); result.Allowed {
+		t.Error("Expected restored rate limit to be exhausted")
+	}
+}
+
+// sharedBudgets is budget state shared by several mergeBackends.
+type sharedBudgets struct {
+	mu     sync.Mutex
+	states map[string]*storage.BudgetState
+	fail   bool
+}
+
+// mergeBackend is a storage.BudgetMerger that sums budget deltas in memory.
+type mergeBackend struct {
+	*storage.MemoryBackend
+	shared *sharedBudgets
+}
+
+func (b *mergeBackend) MergeBudget(ctx context.Context, identifier string, dimension string, delta *storage.BudgetState) (*storage.BudgetState, error) {
+	b.shared.mu.Lock()
+	defer b.shared.mu.Unlock()
+	if b.shared.fail {
+		return nil, fmt.Errorf("storage unavailable")
+	}
+
+	state := b.shared.states[identifier]
+	if state == nil {
+		state = &storage.BudgetState{}
+		b.shared.states[identifier] = state
+	}
+	if delta != nil {
+		state.DailyBuckets = mergeBuckets(state.DailyBuckets, delta.DailyBuckets)
+		state.TotalSpent += delta.TotalSpent
+	}
+	merged := *state
+	merged.DailyBuckets = append([]storage.BudgetBucket(nil), state.DailyBuckets...)
+	return &merged, nil
+}
+
+func mergeBuckets(stored, delta []storage.BudgetBucket) []storage.BudgetBucket {
+	for _, d := range delta {
+		found := false
+		for i := range stored {
+			if stored[i].Timestamp.Equal(d.Timestamp) {
+				stored[i].Amount += d.Amount
+				found = true
+			}
+		}
+		if !found {
+			stored = append(stored, d)
+		}
+	}
+	return stored
+}
+
+func TestManager_SnapshotMergesReplicas(t *testing.T) {
+	shared := &sharedBudgets{states: make(map[string]*storage.BudgetState)}
+	newReplica := func() *Manager {
+		return NewManager(Config{
+			Budgets: map[string]budget.Config{
+				"test-key": {Daily: 4.00},
+			},
+			Enforcement: enforcement.Config{DefaultAction: enforcement.ActionBlock},
+			Storage:     &mergeBackend{MemoryBackend: storage.NewMemoryBackend(), shared: shared},
+		})
+	}
+	replicaA := newReplica()
+	replicaB := newReplica()
+	defer replicaA.Close()
+	defer replicaB.Close()
+	ctx := context.Background()
+
+	_ = replicaA.RecordUsage(ctx, &UsageRecord{Identifier: "test-key", Cost: 2.00})
+	_ = replicaB.RecordUsage(ctx, &UsageRecord{Identifier: "test-key", Cost: 3.00})
+
+	// Each replica is within budget on its own
+	if result, _ := replicaA.CheckLimits(ctx, "test-key", 0, 0, "gpt-4"); !result.Allowed {
+		t.Fatal("Expected request to be allowed before snapshots")
+	}
+
+	for _, m := range []*Manager{replicaA, replicaB, replicaA} {
+		if err := m.Snapshot(ctx); err != nil {
+			t.Fatalf("Snapshot failed: %v", err)
+		}
+	}
+
+	for name, m := range map[string]*Manager{"A": replicaA, "B": replicaB} {
+		if used := m.budgets["test-key"].GetDailyStatus().Used; used != 5.00 {
+			t.Errorf("Replica %s: expected merged daily usage 5.00, got %.2f", name, used)
+		}
+		if result, _ := m.CheckLimits(ctx, "test-key", 0, 0, "gpt-4"); result.Allowed {
+			t.Errorf("Replica %s: expected shared budget to be exceeded", name)
+		}
+	}
+
+	// Spending that fails to persist is retried by the next snapshot
+	_ = replicaA.RecordUsage(ctx, &UsageRecord{Identifier: "test-key", Cost: 1.00})
+	shared.mu.Lock()
+	shared.fail = true
+	shared.mu.Unlock()
+	if err := replicaA.Snapshot(ctx); err == nil {
+		t.Error("Expected snapshot to fail")
+	}
+	shared.mu.Lock()
+	shared.fail = false
+	shared.mu.Unlock()
+	if err := replicaA.Snapshot(ctx); err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	if total := shared.states["test-key"].TotalSpent; total != 6.00 {
+		t.Errorf("Expected stored total 6.00, got %.2f", total)
+	}
+}
+
+func TestManager_MaxStaleness(t *testing.T) {
+	shared := &sharedBudgets{states: make(map[string]*storage.BudgetState)}
+	manager := NewManager(Config{
+		Budgets: map[string]budget.Config{
+			"test-key": {Daily: 100.00},
+		},
+		Enforcement:  enforcement.Config{DefaultAction: enforcement.ActionBlock},
+		Storage:      &mergeBackend{MemoryBackend: storage.NewMemoryBackend(), shared: shared},
+		MaxStaleness: 50 * time.Millisecond,
+	})
+	defer manager.Close()
+	ctx := context.Background()
+
+	if result, _ := manager.CheckLimits(ctx, "test-key", 0, 0, "gpt-4"); !result.Allowed {
+		t.Fatalf("Expected request to be allowed with fresh budget state: %s", result.Reason)
+	}
+
+	// Merges fail for longer than MaxStaleness
+	shared.mu.Lock()
+	shared.fail = true
+	shared.mu.Unlock()
+	time.Sleep(60 * time.Millisecond)
+	if err := manager.Snapshot(ctx); err == nil {
+		t.Fatal("Expected snapshot to fail")
+	}
+
+	if staleness := manager.BudgetStaleness(); staleness < 50*time.Millisecond {
+		t.Errorf("Expected staleness of at least 50ms, got %v", staleness)
+	}
+	result, _ := manager.CheckLimits(ctx, "test-key", 0, 0, "gpt-4")
+	if result.Allowed {
+		t.Fatal("Expected request to be rejected with stale budget state")
+	}
+	if !strings.Contains(result.Reason, "stale") {
+		t.Errorf("Expected stale reason, got %q", result.Reason)
+	}
+
+	// A successful merge lifts the rejection
+	shared.mu.Lock()
+	shared.fail = false
+	shared.mu.Unlock()
+	if err := manager.Snapshot(ctx); err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	if result, _ := manager.CheckLimits(ctx, "test-key", 0, 0, "gpt-4"); !result.Allowed {
+		t.Errorf("Expected request to be allowed after merge: %s", result.Reason)
+	}
+}
+
 func TestManager_ConcurrentLimits(t *testing.T) {
 	config := Config{
 		RateLimits: map[string]ratelimit.Config{
@@ -266,6 +838,75 @@ func TestManager_ConcurrentLimits(t *testing.T) {
 	}
 }
 
+func TestManager_ConcurrencyLimits(t *testing.T) {
+	manager := NewManager(Config{
+		RateLimits: map[string]ratelimit.Config{
+			"test-key": {MaxConcurrent: 3, MaxConcurrentStreams: 2},
+		},
+	})
+	defer manager.Close()
+
+	keyLimits := ConcurrencyLimits{MaxConcurrentStreams: 1}
+
+	// The key's own stream limit is stricter than the configured one
+	if err := manager.AcquireConcurrency("test-key", true, keyLimits); err != nil {
+		t.Fatalf("AcquireConcurrency() failed: %v", err)
+	}
+	if err := manager.AcquireConcurrency("test-key", true, keyLimits); !errors.Is(err, ErrStreamLimitExceeded) {
+		t.Errorf("Expected ErrStreamLimitExceeded, got %v", err)
+	}
+
+	// The rejected stream released its concurrent request slot
+	for i := 0; i < 2; i++ {
+		if err := manager.AcquireConcurrency("test-key", false, keyLimits); err != nil {
+			t.Errorf("Failed to acquire request slot %d: %v", i, err)
+		}
+	}
+	if err := manager.AcquireConcurrency("test-key", false, keyLimits); !errors.Is(err, ErrConcurrencyLimitExceeded) {
+		t.Errorf("Expected ErrConcurrencyLimitExceeded, got %v", err)
+	}
+
+	manager.ReleaseConcurrency("test-key", true, keyLimits)
+	if err := manager.AcquireConcurrency("test-key", true, keyLimits); err != nil {
+		t.Errorf("Expected stream slot after release, got %v", err)
+	}
+
+	// Keys without configured rate limits are limited by their own limits
+	other := ConcurrencyLimits{MaxConcurrent: 1}
+	if err := manager.AcquireConcurrency("other-key", false, other); err != nil {
+		t.Fatalf("AcquireConcurrency() failed: %v", err)
+	}
+	if err := manager.AcquireConcurrency("other-key", false, other); !errors.Is(err, ErrConcurrencyLimitExceeded) {
+		t.Errorf("Expected ErrConcurrencyLimitExceeded for other-key, got %v", err)
+	}
+}
+
+func TestManager_QueuePriority(t *testing.T) {
+	manager := NewManager(Config{
+		Enforcement: enforcement.Config{
+			QueuePriorities: map[string]int{"test-key": 5},
+			PriorityTiers:   map[string]int{"prod": 100, "batch": -10},
+		},
+	})
+	defer manager.Close()
+
+	ctx := context.Background()
+	tests := []struct {
+		class string
+		want  int
+	}{
+		{"", 5},
+		{"prod", 100},
+		{"batch", -10},
+		{"unknown", 5},
+	}
+	for _, tt := range tests {
+		if got := manager.queuePriority(WithPriorityClass(ctx, tt.class), "test-key"); got != tt.want {
+			t.Errorf("queuePriority(%q) = %d, want %d", tt.class, got, tt.want)
+		}
+	}
+}
+
 func TestManager_NoLimits(t *testing.T) {
 	// Manager with no limits configured
 	config := Config{}
@@ -372,6 +1013,59 @@ func TestManager_Downgrade(t *testing.T) {
 	}
 }
 
+func TestManager_Downgrade_RateLimitBlocks(t *testing.T) {
+	manager := NewManager(Config{
+		RateLimits: map[string]ratelimit.Config{
+			"test-key": {RequestsPerMinute: 1},
+		},
+		Budgets: map[string]budget.Config{
+			"test-key": {Daily: 1.00},
+		},
+		Enforcement: enforcement.Config{
+			DefaultAction: enforcement.ActionDowngrade,
+			ModelDowngrades: map[string]string{
+				"gpt-4": "gpt-3.5-turbo",
+			},
+		},
+	})
+	defer manager.Close()
+
+	ctx := context.Background()
+	_ = manager.RecordUsage(ctx, &UsageRecord{
+		Identifier: "test-key",
+		Dimension:  DimensionAPIKey,
+		Cost:       2.00,
+	})
+
+	// Over budget: downgraded, but still counted against the rate limit
+	result, err := manager.CheckLimits(ctx, "test-key", 0, 0, "gpt-4")
+	if err != nil {
+		t.Fatalf("CheckLimits failed: %v", err)
+	}
+	if !result.Allowed || result.Action != ActionDowngrade {
+		t.Fatalf("Expected downgrade, got allowed=%v action=%s", result.Allowed, result.Action)
+	}
+
+	// Over the rate limit: blocked rather than downgraded
+	result, err = manager.CheckLimits(ctx, "test-key", 0, 0, "gpt-4")
+	if err != nil {
+		t.Fatalf("CheckLimits failed: %v", err)
+	}
+	if result.Allowed {
+		t.Error("Expected request over the rate limit to be blocked")
+	}
+	if result.Action != ActionBlock {
+		t.Errorf("Expected action Block, got %s", result.Action)
+	}
+
+	if model, ok := manager.DowngradeModel("gpt-4"); !ok || model != "gpt-3.5-turbo" {
+		t.Errorf("DowngradeModel(gpt-4) = %q, %v; want gpt-3.5-turbo, true", model, ok)
+	}
+	if _, ok := manager.DowngradeModel("claude-3-haiku"); ok {
+		t.Error("Expected no downgrade for claude-3-haiku")
+	}
+}
+
 func BenchmarkManager_CheckLimits(b *testing.B) {
 	config := Config{
 		RateLimits: map[string]ratelimit.Config{
@@ -0,0 +1,33 @@
+package limits
+
+import "context"
+
+// priorityClassKey is the context key of the priority class of a request.
+const priorityClassKey contextKey = "priority_class"
+
+// WithPriorityClass returns a copy of ctx carrying the priority class of a
+// request, such as "prod" or "batch", e.g. the class of its API key. Under
+// contention, queued requests of higher priority classes are served first
+// (see enforcement.Config.PriorityTiers).
+func WithPriorityClass(ctx context.Context, class string) context.Context {
+	return context.WithValue(ctx, priorityClassKey, class)
+}
+
+// PriorityClassFromContext returns the priority class of ctx, or "" if the
+// request has none.
+func PriorityClassFromContext(ctx context.Context) string {
+	class, _ := ctx.Value(priorityClassKey).(string)
+	return class
+}
+
+// queuePriority returns the queue priority of a request of identifier: the
+// priority of its priority class if it has a configured one, and the
+// identifier's queue priority otherwise.
+func (m *Manager) queuePriority(ctx context.Context, identifier string) int {
+	if class := PriorityClassFromContext(ctx); class != "" {
+		if priority, ok := m.enforcementConfig.PriorityTiers[class]; ok {
+			return priority
+		}
+	}
+	return m.enforcementConfig.QueuePriorities[identifier]
+}
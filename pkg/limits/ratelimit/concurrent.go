@@ -1,6 +1,7 @@
 package ratelimit
 
 import (
+	"sync"
 	"sync/atomic"
 )
 
@@ -108,3 +109,71 @@ func (cl *ConcurrentLimiter) Remaining() int64 {
 func (cl *ConcurrentLimiter) Reset() {
 	atomic.StoreInt64(&cl.current, 0)
 }
+
+// KeyedConcurrentLimiter limits the number of simultaneous in-flight
+// requests per key, with the limit of each key given on acquisition. This
+// suits limits that travel with the request, such as those of an API key
+// from a key store, rather than being configured up front.
+//
+// Counters are created on first use and dropped once a key has no
+// requests in flight, so idle keys use no memory.
+//
+// # Thread Safety
+//
+// KeyedConcurrentLimiter is safe for concurrent use.
+type KeyedConcurrentLimiter struct {
+	mu      sync.Mutex
+	current map[string]int64
+}
+
+// NewKeyedConcurrentLimiter creates a new per-key concurrent request
+// limiter.
+//
+// Example:
+//
+//	limiter := NewKeyedConcurrentLimiter()
+//	if limiter.Acquire(apiKey, info.MaxConcurrent) {
+//	    defer limiter.Release(apiKey)
+//	    // Process request
+//	}
+func NewKeyedConcurrentLimiter() *KeyedConcurrentLimiter {
+	return &KeyedConcurrentLimiter{
+		current: make(map[string]int64),
+	}
+}
+
+// Acquire attempts to acquire a concurrency slot for key, allowing at most
+// limit simultaneous requests. Returns true if acquired, false if the limit
+// is reached. A limit of 0 or less allows no requests.
+//
+// If this returns true, the caller MUST call Release(key) when done.
+func (kl *KeyedConcurrentLimiter) Acquire(key string, limit int) bool {
+	kl.mu.Lock()
+	defer kl.mu.Unlock()
+
+	if kl.current[key] >= int64(limit) {
+		return false
+	}
+	kl.current[key]++
+	return true
+}
+
+// Release releases a concurrency slot of key.
+// This MUST be called after a successful Acquire(key, ...).
+func (kl *KeyedConcurrentLimiter) Release(key string) {
+	kl.mu.Lock()
+	defer kl.mu.Unlock()
+
+	if kl.current[key] <= 1 {
+		delete(kl.current, key)
+		return
+	}
+	kl.current[key]--
+}
+
+// Current returns the current number of in-flight requests of key.
+func (kl *KeyedConcurrentLimiter) Current(key string) int64 {
+	kl.mu.Lock()
+	defer kl.mu.Unlock()
+	return kl.current[key]
+}
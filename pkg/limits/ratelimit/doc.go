@@ -5,6 +5,7 @@
 // The ratelimit package implements multiple rate limiting strategies:
 //
 //   - Token Bucket: Request-based rate limiting with constant refill rate
+//   - GCRA: Request-based rate limiting with even pacing instead of bursts
 //   - Sliding Window: Token-based rate limiting over rolling time windows
 //   - Concurrent Limiter: Semaphore-based concurrent request limiting
 //
@@ -20,6 +21,20 @@
 //	    // Rate limit exceeded
 //	}
 //
+// # GCRA
+//
+// The generic cell rate algorithm (a leaky bucket used as a meter) admits
+// requests no sooner than one emission interval apart, with an optional
+// burst tolerance:
+//
+//	gcra := ratelimit.NewGCRA(1, 10) // One request every 100ms
+//	if gcra.Take(1) {
+//	    // Request allowed
+//	}
+//
+// Config.Algorithm selects GCRA instead of token buckets for a Limiter's
+// request-based limits. A Limiter's GCRA limits have no burst tolerance.
+//
 // # Sliding Window
 //
 // The sliding window tracks token usage over rolling time windows:
@@ -30,6 +45,10 @@
 //	    // Rate limit exceeded
 //	}
 //
+// Limiter.ReserveTokens adds a request's estimated tokens to the windows
+// when it is admitted, and Limiter.ReconcileTokens settles the reservation
+// with the actual usage once the response arrives.
+//
 // # Concurrent Limiter
 //
 // The concurrent limiter enforces maximum simultaneous requests:
@@ -40,6 +59,31 @@
 //	    // Process request
 //	}
 //
+// A Limiter applies Config.MaxConcurrent to all requests and
+// Config.MaxConcurrentStreams to streaming requests. KeyedConcurrentLimiter
+// enforces limits that are only known per request, such as those stored
+// with an API key:
+//
+//	if keyed.Acquire(apiKey, info.MaxConcurrent) {
+//	    defer keyed.Release(apiKey)
+//	    // Process request
+//	}
+//
+// # Distributed Limits
+//
+// By default limiter state lives in process. A RedisStore keeps token
+// buckets, GCRA limiters and sliding windows in Redis so every proxy replica enforces the
+// same limits; each check is one atomic Lua script:
+//
+//	store, err := ratelimit.NewRedisStore(&ratelimit.RedisConfig{
+//	    Address: "redis:6379",
+//	})
+//	limiter := ratelimit.NewLimiterWithStore("api-key-123", config, store)
+//
+// If Redis is unreachable, limiters fall back to local in-process state and
+// retry Redis after RedisConfig.FallbackRetry. Concurrent limits are always
+// local.
+//
 // # Thread Safety
 //
 // All rate limiters are thread-safe and use fine-grained locking to minimize
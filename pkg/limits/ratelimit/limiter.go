@@ -16,17 +16,18 @@ import (
 // All limits are evaluated together - if any limit is exceeded, the request
 // is rejected with details about which limit was hit.
 type Limiter struct {
-	// Request-based limits (token buckets)
-	reqPerSecond *TokenBucket
-	reqPerMinute *TokenBucket
-	reqPerHour   *TokenBucket
+	// Request-based limits (token buckets or GCRA)
+	reqPerSecond Bucket
+	reqPerMinute Bucket
+	reqPerHour   Bucket
 
 	// Token-based limits (sliding windows)
-	tokensPerMinute *SlidingWindow
-	tokensPerHour   *SlidingWindow
+	tokensPerMinute Window
+	tokensPerHour   Window
 
-	// Concurrent limit
+	// Concurrent limits
 	concurrent *ConcurrentLimiter
+	streams    *ConcurrentLimiter
 
 	// Configuration
 	config Config
@@ -45,48 +46,88 @@ type Limiter struct {
 //	    MaxConcurrent:     50,
 //	})
 func NewLimiter(config Config) *Limiter {
+	return NewLimiterWithStore("", config, nil)
+}
+
+// NewLimiterWithStore creates a rate limiter whose token buckets and sliding
+// windows are created by store. The key identifies the limited entity within
+// the store; a nil store keeps all state in process.
+//
+// The concurrent limits always stay in process: in-flight requests are
+// released by the replica that acquired them.
+func NewLimiterWithStore(key string, config Config, store Store) *Limiter {
+	if store == nil {
+		store = memoryStore{}
+	}
+
 	limiter := &Limiter{
 		config: config,
 	}
 
-	// Initialize request-based limits (token buckets)
-	if config.RequestsPerSecond > 0 {
-		// Allow burst up to 2x the per-second rate
-		capacity := int64(config.RequestsPerSecond * 2)
-		limiter.reqPerSecond = NewTokenBucket(capacity, float64(config.RequestsPerSecond))
-	}
+	// Initialize request-based limits
+	if config.Algorithm == AlgorithmGCRA {
+		// Space requests evenly at the rate, without bursts
+		if config.RequestsPerSecond > 0 {
+			limiter.reqPerSecond = store.GCRA(key+":rps", 1, float64(config.RequestsPerSecond))
+		}
+		if config.RequestsPerMinute > 0 {
+			limiter.reqPerMinute = store.GCRA(key+":rpm", 1, float64(config.RequestsPerMinute)/60.0)
+		}
+		if config.RequestsPerHour > 0 {
+			limiter.reqPerHour = store.GCRA(key+":rph", 1, float64(config.RequestsPerHour)/3600.0)
+		}
+	} else {
+		if config.RequestsPerSecond > 0 {
+			// Allow burst up to 2x the per-second rate
+			capacity := burstCapacity(float64(config.RequestsPerSecond)*2, config.BurstMultiplier)
+			limiter.reqPerSecond = store.TokenBucket(key+":rps", capacity, float64(config.RequestsPerSecond))
+		}
 
-	if config.RequestsPerMinute > 0 {
-		// Allow burst up to the full minute rate
-		capacity := int64(config.RequestsPerMinute)
-		limiter.reqPerMinute = NewTokenBucket(capacity, float64(config.RequestsPerMinute)/60.0)
-	}
+		if config.RequestsPerMinute > 0 {
+			// Allow burst up to the full minute rate
+			capacity := burstCapacity(float64(config.RequestsPerMinute), config.BurstMultiplier)
+			limiter.reqPerMinute = store.TokenBucket(key+":rpm", capacity, float64(config.RequestsPerMinute)/60.0)
+		}
 
-	if config.RequestsPerHour > 0 {
-		// Allow burst up to 5 minutes worth
-		capacity := int64(config.RequestsPerHour / 12)
-		limiter.reqPerHour = NewTokenBucket(capacity, float64(config.RequestsPerHour)/3600.0)
+		if config.RequestsPerHour > 0 {
+			// Allow burst up to 5 minutes worth
+			capacity := burstCapacity(float64(config.RequestsPerHour/12), config.BurstMultiplier)
+			limiter.reqPerHour = store.TokenBucket(key+":rph", capacity, float64(config.RequestsPerHour)/3600.0)
+		}
 	}
 
 	// Initialize token-based limits (sliding windows)
 	if config.TokensPerMinute > 0 {
 		// 1-second granularity for per-minute window
-		limiter.tokensPerMinute = NewSlidingWindow(time.Minute, time.Second)
+		limiter.tokensPerMinute = store.SlidingWindow(key+":tpm", time.Minute, time.Second)
 	}
 
 	if config.TokensPerHour > 0 {
 		// 1-minute granularity for per-hour window
-		limiter.tokensPerHour = NewSlidingWindow(time.Hour, time.Minute)
+		limiter.tokensPerHour = store.SlidingWindow(key+":tph", time.Hour, time.Minute)
 	}
 
-	// Initialize concurrent limiter
+	// Initialize concurrent limiters
 	if config.MaxConcurrent > 0 {
 		limiter.concurrent = NewConcurrentLimiter(config.MaxConcurrent)
 	}
+	if config.MaxConcurrentStreams > 0 {
+		limiter.streams = NewConcurrentLimiter(config.MaxConcurrentStreams)
+	}
 
 	return limiter
 }
 
+// burstCapacity returns the capacity of a token bucket whose default
+// capacity is scaled by multiplier. A zero multiplier keeps the default;
+// the capacity is at least 1 so the bucket can admit a request.
+func burstCapacity(capacity, multiplier float64) int64 {
+	if multiplier > 0 {
+		capacity *= multiplier
+	}
+	return max(1, int64(capacity))
+}
+
 // CheckRequest checks if a request is allowed based on request-based limits.
 // This should be called before processing the request.
 //
@@ -143,6 +184,39 @@ func (l *Limiter) CheckRequest() *CheckResult {
 	}
 }
 
+// PeekRequest reports whether a request would pass the request-based
+// limits, like CheckRequest, without consuming any capacity.
+func (l *Limiter) PeekRequest() *CheckResult {
+	limits := []struct {
+		bucket Bucket
+		reason string
+		reset  time.Duration
+	}{
+		{l.reqPerSecond, "requests per second limit exceeded", time.Second},
+		{l.reqPerMinute, "requests per minute limit exceeded", time.Minute},
+		{l.reqPerHour, "requests per hour limit exceeded", time.Hour},
+	}
+	for _, limit := range limits {
+		if limit.bucket == nil {
+			continue
+		}
+		if retryAfter := limit.bucket.TimeUntilAvailable(1); retryAfter > 0 {
+			return &CheckResult{
+				Allowed:    false,
+				Reason:     limit.reason,
+				Limit:      limit.bucket.Capacity(),
+				Remaining:  limit.bucket.Remaining(),
+				Reset:      time.Now().Add(limit.reset),
+				RetryAfter: retryAfter,
+			}
+		}
+	}
+
+	return &CheckResult{
+		Allowed: true,
+	}
+}
+
 // CheckTokens checks if a request is allowed based on token-based limits.
 // This should be called before processing the request.
 //
@@ -161,7 +235,7 @@ func (l *Limiter) CheckTokens(estimatedTokens int) *CheckResult {
 				Limit:      int64(l.config.TokensPerMinute),
 				Remaining:  int64(l.config.TokensPerMinute) - currentUsage,
 				Reset:      time.Now().Add(time.Minute),
-				RetryAfter: time.Minute, // Conservative estimate
+				RetryAfter: l.tokensPerMinute.TimeUntilAvailable(int64(estimatedTokens), int64(l.config.TokensPerMinute)),
 			}
 		}
 	}
@@ -176,7 +250,7 @@ func (l *Limiter) CheckTokens(estimatedTokens int) *CheckResult {
 				Limit:      int64(l.config.TokensPerHour),
 				Remaining:  int64(l.config.TokensPerHour) - currentUsage,
 				Reset:      time.Now().Add(time.Hour),
-				RetryAfter: time.Hour, // Conservative estimate
+				RetryAfter: l.tokensPerHour.TimeUntilAvailable(int64(estimatedTokens), int64(l.config.TokensPerHour)),
 			}
 		}
 	}
@@ -187,6 +261,25 @@ func (l *Limiter) CheckTokens(estimatedTokens int) *CheckResult {
 	}
 }
 
+// TimeUntilAllowed returns how long until a request with the estimated
+// number of tokens would pass every request and token limit, as buckets
+// refill and windows slide. It does not consume any capacity.
+func (l *Limiter) TimeUntilAllowed(estimatedTokens int) time.Duration {
+	var wait time.Duration
+	for _, b := range []Bucket{l.reqPerSecond, l.reqPerMinute, l.reqPerHour} {
+		if b != nil {
+			wait = max(wait, b.TimeUntilAvailable(1))
+		}
+	}
+	if l.tokensPerMinute != nil {
+		wait = max(wait, l.tokensPerMinute.TimeUntilAvailable(int64(estimatedTokens), int64(l.config.TokensPerMinute)))
+	}
+	if l.tokensPerHour != nil {
+		wait = max(wait, l.tokensPerHour.TimeUntilAvailable(int64(estimatedTokens), int64(l.config.TokensPerHour)))
+	}
+	return wait
+}
+
 // RecordTokens records actual token usage after a request completes.
 // This updates the sliding window counters.
 //
@@ -202,6 +295,89 @@ func (l *Limiter) RecordTokens(actualTokens int) {
 	}
 }
 
+// TokenReservation holds the tokens reserved for a request by
+// ReserveTokens until its actual usage is known.
+type TokenReservation struct {
+	tokens int64
+
+	// Buckets the tokens were added to, for refunds
+	minuteAt time.Time
+	hourAt   time.Time
+}
+
+// ReserveTokens checks token-based limits like CheckTokens and, if the
+// request is allowed, adds its estimated tokens to the windows right away
+// so concurrent requests cannot overshoot the limits. The reservation must
+// be settled with ReconcileTokens once the actual usage is known.
+//
+// The returned reservation is nil if the request is not allowed.
+func (l *Limiter) ReserveTokens(estimatedTokens int) (*CheckResult, *TokenReservation) {
+	reservation := &TokenReservation{tokens: int64(estimatedTokens)}
+
+	// Reserve tokens per minute
+	if l.tokensPerMinute != nil {
+		limit := int64(l.config.TokensPerMinute)
+		sum, at, ok := l.tokensPerMinute.Reserve(reservation.tokens, limit)
+		if !ok {
+			return &CheckResult{
+				Allowed:    false,
+				Reason:     "tokens per minute limit exceeded",
+				Limit:      limit,
+				Remaining:  limit - sum,
+				Reset:      time.Now().Add(time.Minute),
+				RetryAfter: l.tokensPerMinute.TimeUntilAvailable(reservation.tokens, limit),
+			}, nil
+		}
+		reservation.minuteAt = at
+	}
+
+	// Reserve tokens per hour
+	if l.tokensPerHour != nil {
+		limit := int64(l.config.TokensPerHour)
+		sum, at, ok := l.tokensPerHour.Reserve(reservation.tokens, limit)
+		if !ok {
+			// Release the per-minute reservation
+			if l.tokensPerMinute != nil {
+				l.tokensPerMinute.AddAt(-reservation.tokens, reservation.minuteAt)
+			}
+			return &CheckResult{
+				Allowed:    false,
+				Reason:     "tokens per hour limit exceeded",
+				Limit:      limit,
+				Remaining:  limit - sum,
+				Reset:      time.Now().Add(time.Hour),
+				RetryAfter: l.tokensPerHour.TimeUntilAvailable(reservation.tokens, limit),
+			}, nil
+		}
+		reservation.hourAt = at
+	}
+
+	return &CheckResult{
+		Allowed: true,
+	}, reservation
+}
+
+// ReconcileTokens settles a reservation with the actual number of tokens
+// used: unused reserved tokens are refunded and tokens beyond the
+// reservation are charged. Reconciling with 0 releases the reservation of
+// a request that was not served.
+func (l *Limiter) ReconcileTokens(reservation *TokenReservation, actualTokens int) {
+	diff := int64(actualTokens) - reservation.tokens
+	switch {
+	case diff > 0:
+		// Charge the excess now
+		l.RecordTokens(int(diff))
+	case diff < 0:
+		// Refund the bucket the tokens were reserved in
+		if l.tokensPerMinute != nil {
+			l.tokensPerMinute.AddAt(diff, reservation.minuteAt)
+		}
+		if l.tokensPerHour != nil {
+			l.tokensPerHour.AddAt(diff, reservation.hourAt)
+		}
+	}
+}
+
 // AcquireConcurrent attempts to acquire a concurrency slot.
 // Returns true if acquired, false if limit reached.
 //
@@ -235,7 +411,59 @@ func (l *Limiter) GetConcurrentStatus() *CheckResult {
 	}
 }
 
-// Reset resets all limits. This is primarily for testing.
+// AcquireStream attempts to acquire a streaming connection slot.
+// Returns true if acquired, false if limit reached. Streams also need a
+// concurrency slot from AcquireConcurrent.
+//
+// If this returns true, the caller MUST call ReleaseStream() when done.
+func (l *Limiter) AcquireStream() bool {
+	if l.streams == nil {
+		return true // No stream limit configured
+	}
+
+	return l.streams.Acquire()
+}
+
+// ReleaseStream releases a streaming connection slot.
+// This MUST be called after a successful AcquireStream().
+func (l *Limiter) ReleaseStream() {
+	if l.streams != nil {
+		l.streams.Release()
+	}
+}
+
+// GetStreamStatus returns the current streaming connection status.
+func (l *Limiter) GetStreamStatus() *CheckResult {
+	if l.streams == nil {
+		return &CheckResult{Allowed: true}
+	}
+
+	return &CheckResult{
+		Allowed:   true,
+		Limit:     l.streams.Limit(),
+		Remaining: l.streams.Remaining(),
+	}
+}
+
+// Config returns the limiter's configuration.
+func (l *Limiter) Config() Config {
+	return l.config
+}
+
+// TokensUsed returns the tokens used, including reservations, in the
+// per-minute and per-hour windows. Windows without a configured limit
+// report 0.
+func (l *Limiter) TokensUsed() (perMinute, perHour int64) {
+	if l.tokensPerMinute != nil {
+		perMinute = l.tokensPerMinute.Sum()
+	}
+	if l.tokensPerHour != nil {
+		perHour = l.tokensPerHour.Sum()
+	}
+	return perMinute, perHour
+}
+
+// Reset resets all limits, e.g. to unblock an identifier.
 func (l *Limiter) Reset() {
 	if l.reqPerSecond != nil {
 		l.reqPerSecond.Reset()
@@ -255,4 +483,7 @@ func (l *Limiter) Reset() {
 	if l.concurrent != nil {
 		l.concurrent.Reset()
 	}
+	if l.streams != nil {
+		l.streams.Reset()
+	}
 }
@@ -115,6 +115,89 @@ func TestTokenBucket_Concurrent(t *testing.T) {
 	}
 }
 
+// ============================================================================
+// GCRA Tests
+// ============================================================================
+
+func TestGCRA_Pacing(t *testing.T) {
+	gcra := NewGCRA(1, 10) // One request every 100ms
+
+	if !gcra.Take(1) {
+		t.Fatal("Expected first request to be admitted")
+	}
+	// Unlike a token bucket, a second request right away is rejected
+	if gcra.Take(1) {
+		t.Error("Expected back-to-back request to be rejected")
+	}
+	if remaining := gcra.Remaining(); remaining != 0 {
+		t.Errorf("Expected 0 remaining, got %d", remaining)
+	}
+
+	wait := gcra.TimeUntilAvailable(1)
+	if wait <= 0 || wait > 100*time.Millisecond {
+		t.Errorf("Expected to wait up to 100ms, got %v", wait)
+	}
+
+	time.Sleep(wait + 10*time.Millisecond)
+	if !gcra.Take(1) {
+		t.Error("Expected request to be admitted after the emission interval")
+	}
+}
+
+func TestGCRA_Burst(t *testing.T) {
+	gcra := NewGCRA(3, 1) // One request per second, up to 3 back to back
+
+	if gcra.Capacity() != 3 {
+		t.Errorf("Expected capacity 3, got %d", gcra.Capacity())
+	}
+	if remaining := gcra.Remaining(); remaining != 3 {
+		t.Errorf("Expected 3 remaining, got %d", remaining)
+	}
+	if !gcra.Take(2) || !gcra.Take(1) {
+		t.Fatal("Expected a burst of 3 to be admitted")
+	}
+	if gcra.Take(1) {
+		t.Error("Expected request beyond the burst to be rejected")
+	}
+
+	// Two more requests need two emission intervals
+	wait := gcra.TimeUntilAvailable(2)
+	if wait < 1900*time.Millisecond || wait > 2*time.Second {
+		t.Errorf("Expected ~2s, got %v", wait)
+	}
+
+	gcra.Reset()
+	if wait := gcra.TimeUntilAvailable(3); wait != 0 {
+		t.Errorf("Expected no wait after reset, got %v", wait)
+	}
+}
+
+func TestGCRA_Concurrent(t *testing.T) {
+	gcra := NewGCRA(50, 1)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	successCount := 0
+
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if gcra.Take(1) {
+				mu.Lock()
+				successCount++
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if successCount != 50 {
+		t.Errorf("Expected 50 successes, got %d", successCount)
+	}
+}
+
 // ============================================================================
 // Sliding Window Tests
 // ============================================================================
@@ -190,6 +273,28 @@ func TestSlidingWindow_RollingWindow(t *testing.T) {
 	}
 }
 
+func TestSlidingWindow_TimeUntilAvailable(t *testing.T) {
+	sw := NewSlidingWindow(time.Second, 100*time.Millisecond)
+
+	sw.Add(300)
+	time.Sleep(300 * time.Millisecond)
+	sw.Add(200)
+
+	if wait := sw.TimeUntilAvailable(100, 1000); wait != 0 {
+		t.Errorf("Expected no wait within the limit, got %v", wait)
+	}
+
+	// Only the first bucket has to leave the window
+	if wait := sw.TimeUntilAvailable(300, 700); wait < 400*time.Millisecond || wait > 700*time.Millisecond {
+		t.Errorf("Expected to wait for the first bucket (~700ms), got %v", wait)
+	}
+
+	// Both buckets have to leave the window
+	if wait := sw.TimeUntilAvailable(600, 700); wait < 700*time.Millisecond || wait > time.Second {
+		t.Errorf("Expected to wait for the second bucket (~1s), got %v", wait)
+	}
+}
+
 func TestSlidingWindow_Reset(t *testing.T) {
 	sw := NewSlidingWindow(time.Minute, time.Second)
 
@@ -330,10 +435,68 @@ func TestConcurrentLimiter_Reset(t *testing.T) {
 	}
 }
 
+func TestKeyedConcurrentLimiter(t *testing.T) {
+	limiter := NewKeyedConcurrentLimiter()
+
+	// Each key has its own limit
+	for i := 0; i < 2; i++ {
+		if !limiter.Acquire("key-a", 2) {
+			t.Errorf("Failed to acquire slot %d of key-a", i)
+		}
+	}
+	if limiter.Acquire("key-a", 2) {
+		t.Error("Expected 3rd acquisition of key-a to fail")
+	}
+	if !limiter.Acquire("key-b", 1) {
+		t.Error("Expected key-b to be limited separately")
+	}
+
+	// A raised limit applies right away
+	if !limiter.Acquire("key-a", 3) {
+		t.Error("Expected acquisition under a raised limit to succeed")
+	}
+	if limiter.Current("key-a") != 3 {
+		t.Errorf("Expected current 3, got %d", limiter.Current("key-a"))
+	}
+
+	// Idle keys are dropped
+	for i := 0; i < 3; i++ {
+		limiter.Release("key-a")
+	}
+	limiter.Release("key-b")
+	if len(limiter.current) != 0 {
+		t.Errorf("Expected idle keys to be dropped, got %v", limiter.current)
+	}
+}
+
 // ============================================================================
 // Limiter Integration Tests
 // ============================================================================
 
+func TestLimiter_StreamLimit(t *testing.T) {
+	limiter := NewLimiter(Config{MaxConcurrent: 3, MaxConcurrentStreams: 1})
+
+	if !limiter.AcquireStream() {
+		t.Fatal("Failed to acquire stream slot")
+	}
+	if limiter.AcquireStream() {
+		t.Error("Expected 2nd stream to be rejected")
+	}
+	if status := limiter.GetStreamStatus(); status.Limit != 1 || status.Remaining != 0 {
+		t.Errorf("Unexpected stream status: %+v", status)
+	}
+
+	limiter.ReleaseStream()
+	if !limiter.AcquireStream() {
+		t.Error("Expected stream slot after release")
+	}
+
+	// Without a stream limit, streams are only limited by MaxConcurrent
+	if !NewLimiter(Config{MaxConcurrent: 1}).AcquireStream() {
+		t.Error("Expected streams to be unlimited without MaxConcurrentStreams")
+	}
+}
+
 func TestLimiter_RequestLimits(t *testing.T) {
 	limiter := NewLimiter(Config{
 		RequestsPerSecond: 10,
@@ -370,6 +533,87 @@ func TestLimiter_RequestLimits(t *testing.T) {
 	}
 }
 
+func TestLimiter_PeekRequest(t *testing.T) {
+	limiter := NewLimiter(Config{RequestsPerMinute: 2})
+
+	// Peeking does not consume capacity
+	for i := 0; i < 5; i++ {
+		if result := limiter.PeekRequest(); !result.Allowed {
+			t.Fatalf("Expected peek %d to be allowed", i+1)
+		}
+	}
+
+	limiter.CheckRequest()
+	limiter.CheckRequest()
+	result := limiter.PeekRequest()
+	if result.Allowed {
+		t.Fatal("Expected peek to be blocked after exhausting the limit")
+	}
+	if result.Reason != "requests per minute limit exceeded" {
+		t.Errorf("Expected 'requests per minute limit exceeded', got %s", result.Reason)
+	}
+	if result.RetryAfter <= 0 {
+		t.Errorf("Expected positive retry after, got %v", result.RetryAfter)
+	}
+}
+
+func TestLimiter_BurstMultiplier(t *testing.T) {
+	limiter := NewLimiter(Config{
+		RequestsPerMinute: 10,
+		BurstMultiplier:   1.5,
+	})
+
+	// The bucket admits 15 requests at once instead of 10
+	for i := 0; i < 15; i++ {
+		if result := limiter.CheckRequest(); !result.Allowed {
+			t.Fatalf("Expected request %d to be allowed: %s", i+1, result.Reason)
+		}
+	}
+	result := limiter.CheckRequest()
+	if result.Allowed {
+		t.Fatal("Expected request beyond the burst to be blocked")
+	}
+	if result.Limit != 15 {
+		t.Errorf("Expected limit 15, got %d", result.Limit)
+	}
+
+	// Small multipliers still admit a request
+	if !NewLimiter(Config{RequestsPerSecond: 1, BurstMultiplier: 0.1}).CheckRequest().Allowed {
+		t.Error("Expected a bucket capacity of at least 1")
+	}
+}
+
+func TestLimiter_GCRARequestLimits(t *testing.T) {
+	limiter := NewLimiter(Config{
+		Algorithm:         AlgorithmGCRA,
+		RequestsPerSecond: 10,
+	})
+
+	if result := limiter.CheckRequest(); !result.Allowed {
+		t.Fatal("Expected first request to be allowed")
+	}
+
+	// No burst: the next request must wait for the emission interval
+	result := limiter.CheckRequest()
+	if result.Allowed {
+		t.Fatal("Expected back-to-back request to be blocked")
+	}
+	if result.Reason != "requests per second limit exceeded" {
+		t.Errorf("Expected 'requests per second limit exceeded', got %s", result.Reason)
+	}
+	if result.Limit != 1 {
+		t.Errorf("Expected limit 1, got %d", result.Limit)
+	}
+	if result.RetryAfter <= 0 || result.RetryAfter > 100*time.Millisecond {
+		t.Errorf("Expected retry within 100ms, got %v", result.RetryAfter)
+	}
+
+	time.Sleep(result.RetryAfter + 10*time.Millisecond)
+	if result := limiter.CheckRequest(); !result.Allowed {
+		t.Error("Expected paced request to be allowed")
+	}
+}
+
 func TestLimiter_TokenLimits(t *testing.T) {
 	limiter := NewLimiter(Config{
 		TokensPerMinute: 1000,
@@ -403,6 +647,83 @@ func TestLimiter_TokenLimits(t *testing.T) {
 	}
 }
 
+func TestLimiter_ReserveTokens(t *testing.T) {
+	limiter := NewLimiter(Config{
+		TokensPerMinute: 1000,
+		TokensPerHour:   5000,
+	})
+
+	// Reservations count against the limit before usage is recorded
+	_, first := limiter.ReserveTokens(600)
+	if first == nil {
+		t.Fatal("Expected first reservation to succeed")
+	}
+	result, second := limiter.ReserveTokens(600)
+	if result.Allowed || second != nil {
+		t.Fatal("Expected concurrent reservation to exceed the limit")
+	}
+	if result.Remaining != 400 {
+		t.Errorf("Expected 400 remaining, got %d", result.Remaining)
+	}
+
+	// Unused tokens are refunded
+	limiter.ReconcileTokens(first, 200)
+	if sum := limiter.tokensPerMinute.Sum(); sum != 200 {
+		t.Errorf("Expected 200 tokens after refund, got %d", sum)
+	}
+	if sum := limiter.tokensPerHour.Sum(); sum != 200 {
+		t.Errorf("Expected 200 hourly tokens after refund, got %d", sum)
+	}
+
+	// Tokens beyond the reservation are charged
+	_, third := limiter.ReserveTokens(100)
+	limiter.ReconcileTokens(third, 700)
+	if sum := limiter.tokensPerMinute.Sum(); sum != 900 {
+		t.Errorf("Expected 900 tokens after overrun, got %d", sum)
+	}
+}
+
+func TestLimiter_ReserveTokens_HourlyLimitReleasesMinute(t *testing.T) {
+	limiter := NewLimiter(Config{
+		TokensPerMinute: 1000,
+		TokensPerHour:   500,
+	})
+
+	result, reservation := limiter.ReserveTokens(600)
+	if result.Allowed || reservation != nil {
+		t.Fatal("Expected reservation to exceed the hourly limit")
+	}
+	if result.Reason != "tokens per hour limit exceeded" {
+		t.Errorf("Expected 'tokens per hour limit exceeded', got %s", result.Reason)
+	}
+	if sum := limiter.tokensPerMinute.Sum(); sum != 0 {
+		t.Errorf("Expected per-minute reservation to be released, got %d", sum)
+	}
+}
+
+func TestLimiter_TimeUntilAllowed(t *testing.T) {
+	limiter := NewLimiter(Config{
+		RequestsPerMinute: 60,
+		TokensPerMinute:   1000,
+	})
+
+	if wait := limiter.TimeUntilAllowed(100); wait != 0 {
+		t.Errorf("Expected no wait, got %v", wait)
+	}
+
+	limiter.RecordTokens(1000)
+	result := limiter.CheckTokens(100)
+	if result.Allowed {
+		t.Fatal("Expected request to be blocked")
+	}
+	if result.RetryAfter < 59*time.Second || result.RetryAfter > time.Minute {
+		t.Errorf("Expected to retry once the tokens leave the window (~60s), got %v", result.RetryAfter)
+	}
+	if wait := limiter.TimeUntilAllowed(100); wait < 59*time.Second || wait > time.Minute {
+		t.Errorf("Expected ~60s until allowed, got %v", wait)
+	}
+}
+
 func TestLimiter_ConcurrentLimit(t *testing.T) {
 	limiter := NewLimiter(Config{
 		MaxConcurrent: 5,
@@ -481,6 +802,57 @@ func TestLimiter_Reset(t *testing.T) {
 	}
 }
 
+func TestLimiter_StateRestore(t *testing.T) {
+	config := Config{
+		RequestsPerMinute: 3,
+		TokensPerMinute:   100,
+	}
+	limiter := NewLimiter(config)
+
+	if state := limiter.State(); !state.IsEmpty() {
+		t.Errorf("Expected empty state for an unused limiter, got %+v", state)
+	}
+
+	for i := 0; i < 3; i++ {
+		limiter.CheckRequest()
+	}
+	limiter.RecordTokens(80)
+
+	state := limiter.State()
+	if _, ok := state.Buckets["rpm"]; !ok {
+		t.Error("Expected rpm bucket in state")
+	}
+	if _, ok := state.Windows["tpm"]; !ok {
+		t.Error("Expected tpm window in state")
+	}
+
+	restored := NewLimiter(config)
+	restored.Restore(state)
+
+	if result := restored.CheckRequest(); result.Allowed {
+		t.Error("Expected restored request limit to be exhausted")
+	}
+	if result := restored.CheckTokens(50); result.Allowed {
+		t.Error("Expected restored token usage to count towards the limit")
+	}
+	if result := restored.CheckTokens(20); !result.Allowed {
+		t.Errorf("Expected remaining tokens to be allowed: %s", result.Reason)
+	}
+}
+
+func TestLimiter_RestoreSkipsChangedAlgorithm(t *testing.T) {
+	limiter := NewLimiter(Config{RequestsPerMinute: 1})
+	limiter.CheckRequest()
+	state := limiter.State()
+
+	restored := NewLimiter(Config{RequestsPerMinute: 1, Algorithm: AlgorithmGCRA})
+	restored.Restore(state)
+
+	if result := restored.CheckRequest(); !result.Allowed {
+		t.Error("Expected token bucket state not to be restored into a GCRA limit")
+	}
+}
+
 // ============================================================================
 // Benchmarks
 // ============================================================================
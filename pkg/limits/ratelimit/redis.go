@@ -0,0 +1,678 @@
+package ratelimit
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// RedisConfig configures a RedisStore.
+type RedisConfig struct {
+	// Address is the Redis server address (host:port).
+	Address string
+
+	// Username is the ACL username (Redis 6+). Optional.
+	Username string
+
+	// Password authenticates the connection. Optional.
+	Password string
+
+	// DB is the database number to select.
+	DB int
+
+	// TLS enables TLS for connections to Redis.
+	TLS bool
+
+	// KeyPrefix is prepended to all limiter keys.
+	// Default: "mercator:ratelimit:"
+	KeyPrefix string
+
+	// PoolSize is the maximum number of idle connections kept open.
+	// Default: 10
+	PoolSize int
+
+	// DialTimeout bounds connection establishment.
+	// Default: 500ms
+	DialTimeout time.Duration
+
+	// OpTimeout bounds each command round trip.
+	// Default: 100ms
+	OpTimeout time.Duration
+
+	// FallbackRetry is how long limiters use their local fallback after a
+	// Redis failure before trying Redis again.
+	// Default: 5s
+	FallbackRetry time.Duration
+}
+
+// tokenBucketScript refills and takes from a bucket stored as a hash with
+// "tokens" and "ts" (milliseconds) fields, using the Redis server clock so
+// replicas with skewed clocks agree. Taking 0 tokens only refills.
+//
+// KEYS[1]: bucket key
+// ARGV: capacity, refill rate (tokens/sec), tokens to take, TTL (ms)
+// Returns: {allowed (0/1), remaining tokens as a string}
+const tokenBucketScript = `
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+  tokens = capacity
+  ts = now
+end
+if now > ts then
+  tokens = math.min(capacity, tokens + (now - ts) / 1000 * rate)
+end
+local allowed = 0
+if tokens >= n then
+  tokens = tokens - n
+  allowed = 1
+end
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', tostring(now))
+redis.call('PEXPIRE', KEYS[1], ARGV[4])
+return {allowed, tostring(tokens)}
+`
+
+// gcraScript admits requests under GCRA, storing the theoretical arrival
+// time (milliseconds) in the "tat" field of a hash, using the Redis server
+// clock. Admitting 0 requests only reads the state.
+//
+// KEYS[1]: limiter key
+// ARGV: emission interval (ms), burst, requests to admit
+// Returns: {allowed (0/1), how far the TAT is ahead of now (ms) as a string}
+const gcraScript = `
+local interval = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + tonumber(t[2]) / 1000
+local tat = tonumber(redis.call('HGET', KEYS[1], 'tat'))
+if tat == nil or tat < now then
+  tat = now
+end
+local allowed = 0
+if tat + n * interval - now <= burst * interval then
+  allowed = 1
+  if n > 0 then
+    tat = tat + n * interval
+    redis.call('HSET', KEYS[1], 'tat', tostring(tat))
+    redis.call('PEXPIRE', KEYS[1], math.ceil(tat - now) + 1000)
+  end
+end
+return {allowed, tostring(tat - now)}
+`
+
+// slidingWindowScript adds to and sums a window stored as a hash of bucket
+// start time (milliseconds) to value. Buckets older than the window are
+// removed. Adding 0 only sums.
+//
+// KEYS[1]: window key
+// ARGV: window (ms), bucket size (ms), value to add
+// Returns: the window sum
+const slidingWindowScript = `
+local window = tonumber(ARGV[1])
+local size = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+if n ~= 0 then
+  redis.call('HINCRBY', KEYS[1], tostring(now - (now % size)), n)
+end
+local cutoff = now - window
+local sum = 0
+local entries = redis.call('HGETALL', KEYS[1])
+for i = 1, #entries, 2 do
+  if tonumber(entries[i]) < cutoff then
+    redis.call('HDEL', KEYS[1], entries[i])
+  else
+    sum = sum + tonumber(entries[i + 1])
+  end
+end
+redis.call('PEXPIRE', KEYS[1], window + size)
+return sum
+`
+
+// reserveWindowScript adds to a window, stored as for slidingWindowScript,
+// only if the sum stays within a limit.
+//
+// KEYS[1]: window key
+// ARGV: window (ms), bucket size (ms), value to add, limit
+// Returns: {added (0/1), window sum, bucket start (ms)}
+const reserveWindowScript = `
+local window = tonumber(ARGV[1])
+local size = tonumber(ARGV[2])
+local n = tonumber(ARGV[3])
+local limit = tonumber(ARGV[4])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local cutoff = now - window
+local sum = 0
+local entries = redis.call('HGETALL', KEYS[1])
+for i = 1, #entries, 2 do
+  if tonumber(entries[i]) < cutoff then
+    redis.call('HDEL', KEYS[1], entries[i])
+  else
+    sum = sum + tonumber(entries[i + 1])
+  end
+end
+local start = now - (now % size)
+local added = 0
+if sum + n <= limit then
+  redis.call('HINCRBY', KEYS[1], tostring(start), n)
+  sum = sum + n
+  added = 1
+end
+redis.call('PEXPIRE', KEYS[1], window + size)
+return {added, sum, start}
+`
+
+// adjustWindowScript adds to an existing window bucket. Buckets that have
+// been removed from the window are left alone.
+//
+// KEYS[1]: window key
+// ARGV: bucket start (ms), value to add
+// Returns: 1 if the bucket was adjusted, 0 otherwise
+const adjustWindowScript = `
+if redis.call('HEXISTS', KEYS[1], ARGV[1]) == 0 then
+  return 0
+end
+redis.call('HINCRBY', KEYS[1], ARGV[1], ARGV[2])
+return 1
+`
+
+// windowWaitScript returns how long until a value can be added to a
+// window, stored as for slidingWindowScript, within a limit as buckets
+// leave the window.
+//
+// KEYS[1]: window key
+// ARGV: window (ms), value to add, limit
+// Returns: the wait in milliseconds
+const windowWaitScript = `
+local window = tonumber(ARGV[1])
+local n = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+local t = redis.call('TIME')
+local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+local cutoff = now - window
+local sum = 0
+local starts = {}
+local values = {}
+local entries = redis.call('HGETALL', KEYS[1])
+for i = 1, #entries, 2 do
+  local start = tonumber(entries[i])
+  if start >= cutoff then
+    sum = sum + tonumber(entries[i + 1])
+    table.insert(starts, start)
+    values[start] = tonumber(entries[i + 1])
+  end
+end
+table.sort(starts)
+local wait = 0
+for _, start in ipairs(starts) do
+  if sum + n <= limit then
+    break
+  end
+  sum = sum - values[start]
+  wait = start + window + 1 - now
+end
+if wait < 0 then
+  wait = 0
+end
+return wait
+`
+
+// redisScript is a Lua script evaluated by its SHA1 digest, falling back to
+// sending the source when the server has not cached it.
+type redisScript struct {
+	source string
+	sha    string
+}
+
+// newRedisScript creates a script.
+func newRedisScript(source string) *redisScript {
+	sum := sha1.Sum([]byte(source))
+	return &redisScript{source: source, sha: hex.EncodeToString(sum[:])}
+}
+
+var (
+	tokenBucketLua   = newRedisScript(tokenBucketScript)
+	gcraLua          = newRedisScript(gcraScript)
+	slidingWindowLua = newRedisScript(slidingWindowScript)
+	reserveWindowLua = newRedisScript(reserveWindowScript)
+	adjustWindowLua  = newRedisScript(adjustWindowScript)
+	windowWaitLua    = newRedisScript(windowWaitScript)
+)
+
+// RedisStore creates token buckets, GCRA limiters and sliding windows kept
+// in Redis, so every proxy replica enforces the same limits. Each check is
+// a single Lua script, which Redis runs atomically.
+//
+// When Redis is unreachable, limiters fall back to a local in-process
+// limiter with the same configuration, so each replica keeps enforcing its
+// own share of traffic. Redis is retried after FallbackRetry; once it is
+// reachable again the shared state is authoritative and local state is
+// ignored.
+type RedisStore struct {
+	config *RedisConfig
+	client *redisClient
+	logger *slog.Logger
+
+	// downUntil is the Unix time (ns) before which Redis is not retried.
+	downUntil atomic.Int64
+
+	// fallbacks counts operations served by the local fallback.
+	fallbacks atomic.Int64
+}
+
+// NewRedisStore creates a Redis-backed store. It does not connect until the
+// first limiter operation; call Ping to verify connectivity at startup.
+func NewRedisStore(config *RedisConfig) (*RedisStore, error) {
+	if config.Address == "" {
+		return nil, fmt.Errorf("redis address is required")
+	}
+	if config.KeyPrefix == "" {
+		config.KeyPrefix = "mercator:ratelimit:"
+	}
+	if config.PoolSize <= 0 {
+		config.PoolSize = 10
+	}
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = 500 * time.Millisecond
+	}
+	if config.OpTimeout <= 0 {
+		config.OpTimeout = 100 * time.Millisecond
+	}
+	if config.FallbackRetry <= 0 {
+		config.FallbackRetry = 5 * time.Second
+	}
+
+	return &RedisStore{
+		config: config,
+		client: newRedisClient(config),
+		logger: slog.Default().With("component", "limits.redis"),
+	}, nil
+}
+
+// Ping checks that Redis is reachable.
+func (s *RedisStore) Ping(ctx context.Context) error {
+	_, err := s.client.do(ctx, "PING")
+	return err
+}
+
+// Do sends a command to Redis and returns its reply: bulk strings as
+// string, integers as int64, arrays as []interface{} and nil replies as
+// nil. It lets other components, such as storage.RedisBackend, share the
+// store's connection pool. Unlike limiter operations, Do has no local
+// fallback.
+func (s *RedisStore) Do(ctx context.Context, args ...string) (interface{}, error) {
+	return s.client.do(ctx, args...)
+}
+
+// Available reports whether limiters are currently using Redis rather than
+// their local fallback.
+func (s *RedisStore) Available() bool {
+	return time.Now().UnixNano() >= s.downUntil.Load()
+}
+
+// Fallbacks returns the number of operations served by local fallbacks.
+func (s *RedisStore) Fallbacks() int64 {
+	return s.fallbacks.Load()
+}
+
+// Close closes idle connections.
+func (s *RedisStore) Close() error {
+	s.client.close()
+	return nil
+}
+
+// TokenBucket implements Store.
+func (s *RedisStore) TokenBucket(key string, capacity int64, refillRate float64) Bucket {
+	// Keep the key until a drained bucket would be full again.
+	ttl := time.Minute
+	if refillRate > 0 {
+		ttl = time.Duration(float64(capacity)/refillRate*float64(time.Second)) + time.Second
+	}
+
+	return &redisBucket{
+		store:      s,
+		key:        s.config.KeyPrefix + key,
+		capacity:   capacity,
+		refillRate: refillRate,
+		ttl:        ttl,
+		local:      NewTokenBucket(capacity, refillRate),
+	}
+}
+
+// GCRA implements Store.
+func (s *RedisStore) GCRA(key string, burst int64, rate float64) Bucket {
+	return &redisGCRA{
+		store:    s,
+		key:      s.config.KeyPrefix + key,
+		burst:    burst,
+		interval: time.Duration(float64(time.Second) / rate),
+		local:    NewGCRA(burst, rate),
+	}
+}
+
+// SlidingWindow implements Store.
+func (s *RedisStore) SlidingWindow(key string, window, bucketSize time.Duration) Window {
+	return &redisWindow{
+		store:      s,
+		key:        s.config.KeyPrefix + key,
+		window:     window,
+		bucketSize: bucketSize,
+		local:      NewSlidingWindow(window, bucketSize),
+	}
+}
+
+// eval runs a script on one key. It returns an error without contacting
+// Redis while the store is in fallback.
+func (s *RedisStore) eval(script *redisScript, key string, args ...string) (interface{}, error) {
+	if !s.Available() {
+		s.fallbacks.Add(1)
+		return nil, errRedisUnavailable
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.DialTimeout+s.config.OpTimeout)
+	defer cancel()
+
+	cmd := append([]string{"EVALSHA", script.sha, "1", key}, args...)
+	reply, err := s.client.do(ctx, cmd...)
+	var replyErr redisError
+	if errors.As(err, &replyErr) && strings.HasPrefix(string(replyErr), "NOSCRIPT") {
+		cmd[0], cmd[1] = "EVAL", script.source
+		reply, err = s.client.do(ctx, cmd...)
+	}
+	if err != nil {
+		s.fail(err)
+		return nil, err
+	}
+	return reply, nil
+}
+
+// del deletes a key, ignoring failures (the key expires on its own).
+func (s *RedisStore) del(key string) {
+	if !s.Available() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.DialTimeout+s.config.OpTimeout)
+	defer cancel()
+	if _, err := s.client.do(ctx, "DEL", key); err != nil {
+		s.fail(err)
+	}
+}
+
+// fail switches limiters to their local fallback for FallbackRetry.
+func (s *RedisStore) fail(err error) {
+	s.fallbacks.Add(1)
+	until := time.Now().Add(s.config.FallbackRetry).UnixNano()
+	if previous := s.downUntil.Swap(until); previous < time.Now().UnixNano() {
+		s.logger.Warn("redis unavailable, using local rate limits",
+			"address", s.config.Address,
+			"retry_in", s.config.FallbackRetry,
+			"error", err)
+	}
+}
+
+// errRedisUnavailable is returned by eval while the store is in fallback.
+var errRedisUnavailable = errors.New("redis unavailable")
+
+// redisBucket is a token bucket stored in Redis.
+type redisBucket struct {
+	store      *RedisStore
+	key        string
+	capacity   int64
+	refillRate float64
+	ttl        time.Duration
+	local      *TokenBucket
+}
+
+// take runs the bucket script, returning whether n tokens were taken and
+// the tokens left.
+func (b *redisBucket) take(n int64) (bool, float64, error) {
+	reply, err := b.store.eval(tokenBucketLua, b.key,
+		strconv.FormatInt(b.capacity, 10),
+		strconv.FormatFloat(b.refillRate, 'f', -1, 64),
+		strconv.FormatInt(n, 10),
+		strconv.FormatInt(b.ttl.Milliseconds(), 10),
+	)
+	if err != nil {
+		return false, 0, err
+	}
+
+	items, ok := reply.([]interface{})
+	if !ok || len(items) != 2 {
+		return false, 0, fmt.Errorf("unexpected token bucket reply %v", reply)
+	}
+	allowed, _ := items[0].(int64)
+	tokensStr, _ := items[1].(string)
+	tokens, err := strconv.ParseFloat(tokensStr, 64)
+	if err != nil {
+		return false, 0, fmt.Errorf("unexpected token bucket reply %v", reply)
+	}
+	return allowed == 1, tokens, nil
+}
+
+// Take implements Bucket.
+func (b *redisBucket) Take(n int64) bool {
+	allowed, _, err := b.take(n)
+	if err != nil {
+		return b.local.Take(n)
+	}
+	return allowed
+}
+
+// Remaining implements Bucket.
+func (b *redisBucket) Remaining() int64 {
+	_, tokens, err := b.take(0)
+	if err != nil {
+		return b.local.Remaining()
+	}
+	return int64(math.Floor(tokens))
+}
+
+// Capacity implements Bucket.
+func (b *redisBucket) Capacity() int64 {
+	return b.capacity
+}
+
+// TimeUntilAvailable implements Bucket.
+func (b *redisBucket) TimeUntilAvailable(n int64) time.Duration {
+	_, tokens, err := b.take(0)
+	if err != nil {
+		return b.local.TimeUntilAvailable(n)
+	}
+	if tokens >= float64(n) || b.refillRate <= 0 {
+		return 0
+	}
+	return time.Duration((float64(n) - tokens) / b.refillRate * float64(time.Second))
+}
+
+// Reset implements Bucket.
+func (b *redisBucket) Reset() {
+	b.local.Reset()
+	b.store.del(b.key)
+}
+
+// redisGCRA is a GCRA limiter stored in Redis.
+type redisGCRA struct {
+	store    *RedisStore
+	key      string
+	burst    int64
+	interval time.Duration
+	local    *GCRA
+}
+
+// take runs the GCRA script, returning whether n requests were admitted
+// and how far the theoretical arrival time is ahead of now.
+func (g *redisGCRA) take(n int64) (bool, time.Duration, error) {
+	reply, err := g.store.eval(gcraLua, g.key,
+		strconv.FormatFloat(float64(g.interval)/float64(time.Millisecond), 'f', -1, 64),
+		strconv.FormatInt(g.burst, 10),
+		strconv.FormatInt(n, 10),
+	)
+	if err != nil {
+		return false, 0, err
+	}
+
+	items, ok := reply.([]interface{})
+	if !ok || len(items) != 2 {
+		return false, 0, fmt.Errorf("unexpected GCRA reply %v", reply)
+	}
+	allowed, _ := items[0].(int64)
+	aheadStr, _ := items[1].(string)
+	ahead, err := strconv.ParseFloat(aheadStr, 64)
+	if err != nil {
+		return false, 0, fmt.Errorf("unexpected GCRA reply %v", reply)
+	}
+	return allowed == 1, time.Duration(ahead * float64(time.Millisecond)), nil
+}
+
+// Take implements Bucket.
+func (g *redisGCRA) Take(n int64) bool {
+	allowed, _, err := g.take(n)
+	if err != nil {
+		return g.local.Take(n)
+	}
+	return allowed
+}
+
+// Remaining implements Bucket.
+func (g *redisGCRA) Remaining() int64 {
+	_, ahead, err := g.take(0)
+	if err != nil {
+		return g.local.Remaining()
+	}
+	return gcraRemaining(ahead, g.burst, g.interval)
+}
+
+// Capacity implements Bucket.
+func (g *redisGCRA) Capacity() int64 {
+	return g.burst
+}
+
+// TimeUntilAvailable implements Bucket.
+func (g *redisGCRA) TimeUntilAvailable(n int64) time.Duration {
+	_, ahead, err := g.take(0)
+	if err != nil {
+		return g.local.TimeUntilAvailable(n)
+	}
+	return gcraWait(ahead, n, g.burst, g.interval)
+}
+
+// Reset implements Bucket.
+func (g *redisGCRA) Reset() {
+	g.local.Reset()
+	g.store.del(g.key)
+}
+
+// redisWindow is a sliding window counter stored in Redis.
+type redisWindow struct {
+	store      *RedisStore
+	key        string
+	window     time.Duration
+	bucketSize time.Duration
+	local      *SlidingWindow
+}
+
+// add runs the window script and returns the window sum.
+func (w *redisWindow) add(value int64) (int64, error) {
+	reply, err := w.store.eval(slidingWindowLua, w.key,
+		strconv.FormatInt(w.window.Milliseconds(), 10),
+		strconv.FormatInt(w.bucketSize.Milliseconds(), 10),
+		strconv.FormatInt(value, 10),
+	)
+	if err != nil {
+		return 0, err
+	}
+	sum, ok := reply.(int64)
+	if !ok {
+		return 0, fmt.Errorf("unexpected sliding window reply %v", reply)
+	}
+	return sum, nil
+}
+
+// Add implements Window.
+func (w *redisWindow) Add(value int64) {
+	if _, err := w.add(value); err != nil {
+		w.local.Add(value)
+	}
+}
+
+// Sum implements Window.
+func (w *redisWindow) Sum() int64 {
+	sum, err := w.add(0)
+	if err != nil {
+		return w.local.Sum()
+	}
+	return sum
+}
+
+// Reserve implements Window.
+func (w *redisWindow) Reserve(value, limit int64) (int64, time.Time, bool) {
+	reply, err := w.store.eval(reserveWindowLua, w.key,
+		strconv.FormatInt(w.window.Milliseconds(), 10),
+		strconv.FormatInt(w.bucketSize.Milliseconds(), 10),
+		strconv.FormatInt(value, 10),
+		strconv.FormatInt(limit, 10),
+	)
+	if err != nil {
+		return w.local.Reserve(value, limit)
+	}
+
+	items, ok := reply.([]interface{})
+	if !ok || len(items) != 3 {
+		return w.local.Reserve(value, limit)
+	}
+	added, _ := items[0].(int64)
+	sum, _ := items[1].(int64)
+	start, _ := items[2].(int64)
+	return sum, time.UnixMilli(start), added == 1
+}
+
+// AddAt implements Window.
+func (w *redisWindow) AddAt(value int64, at time.Time) {
+	_, err := w.store.eval(adjustWindowLua, w.key,
+		strconv.FormatInt(at.UnixMilli(), 10),
+		strconv.FormatInt(value, 10),
+	)
+	if err != nil {
+		w.local.AddAt(value, at)
+	}
+}
+
+// TimeUntilAvailable implements Window.
+func (w *redisWindow) TimeUntilAvailable(value, limit int64) time.Duration {
+	reply, err := w.store.eval(windowWaitLua, w.key,
+		strconv.FormatInt(w.window.Milliseconds(), 10),
+		strconv.FormatInt(value, 10),
+		strconv.FormatInt(limit, 10),
+	)
+	if err != nil {
+		return w.local.TimeUntilAvailable(value, limit)
+	}
+	wait, ok := reply.(int64)
+	if !ok {
+		return w.local.TimeUntilAvailable(value, limit)
+	}
+	return time.Duration(wait) * time.Millisecond
+}
+
+// Reset implements Window.
+func (w *redisWindow) Reset() {
+	w.local.Reset()
+	w.store.del(w.key)
+}
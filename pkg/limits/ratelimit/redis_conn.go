@@ -0,0 +1,209 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// redisError is an error reply returned by the Redis server.
+type redisError string
+
+// Error implements the error interface.
+func (e redisError) Error() string {
+	return string(e)
+}
+
+// redisClient is a minimal RESP2 client with a fixed-size connection pool.
+// It supports only what the limiters need: plain commands and script
+// evaluation.
+type redisClient struct {
+	config *RedisConfig
+	dialer *net.Dialer
+	idle   chan *redisConn
+}
+
+// redisConn is a single connection to the Redis server.
+type redisConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+	w    *bufio.Writer
+}
+
+// newRedisClient creates a client. Connections are dialed lazily.
+func newRedisClient(config *RedisConfig) *redisClient {
+	return &redisClient{
+		config: config,
+		dialer: &net.Dialer{Timeout: config.DialTimeout},
+		idle:   make(chan *redisConn, config.PoolSize),
+	}
+}
+
+// do sends a command and returns its reply. Error replies are returned as
+// redisError; any other error means the connection failed.
+func (c *redisClient) do(ctx context.Context, args ...string) (interface{}, error) {
+	conn, err := c.get(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := conn.roundTrip(c.config.OpTimeout, args)
+	var replyErr redisError
+	if err != nil && !errors.As(err, &replyErr) {
+		_ = conn.conn.Close()
+		return nil, err
+	}
+	c.put(conn)
+	return reply, err
+}
+
+// get returns an idle connection or dials a new one.
+func (c *redisClient) get(ctx context.Context) (*redisConn, error) {
+	select {
+	case conn := <-c.idle:
+		return conn, nil
+	default:
+	}
+
+	var (
+		netConn net.Conn
+		err     error
+	)
+	if c.config.TLS {
+		tlsDialer := &tls.Dialer{NetDialer: c.dialer}
+		netConn, err = tlsDialer.DialContext(ctx, "tcp", c.config.Address)
+	} else {
+		netConn, err = c.dialer.DialContext(ctx, "tcp", c.config.Address)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to redis: %w", err)
+	}
+
+	conn := &redisConn{
+		conn: netConn,
+		r:    bufio.NewReader(netConn),
+		w:    bufio.NewWriter(netConn),
+	}
+
+	if c.config.Password != "" {
+		auth := []string{"AUTH", c.config.Password}
+		if c.config.Username != "" {
+			auth = []string{"AUTH", c.config.Username, c.config.Password}
+		}
+		if _, err := conn.roundTrip(c.config.OpTimeout, auth); err != nil {
+			_ = netConn.Close()
+			return nil, fmt.Errorf("redis authentication failed: %w", err)
+		}
+	}
+	if c.config.DB != 0 {
+		if _, err := conn.roundTrip(c.config.OpTimeout, []string{"SELECT", strconv.Itoa(c.config.DB)}); err != nil {
+			_ = netConn.Close()
+			return nil, fmt.Errorf("failed to select redis database: %w", err)
+		}
+	}
+
+	return conn, nil
+}
+
+// put returns a connection to the pool, closing it if the pool is full.
+func (c *redisClient) put(conn *redisConn) {
+	select {
+	case c.idle <- conn:
+	default:
+		_ = conn.conn.Close()
+	}
+}
+
+// close closes all idle connections.
+func (c *redisClient) close() {
+	for {
+		select {
+		case conn := <-c.idle:
+			_ = conn.conn.Close()
+		default:
+			return
+		}
+	}
+}
+
+// roundTrip writes a command and reads its reply.
+func (rc *redisConn) roundTrip(timeout time.Duration, args []string) (interface{}, error) {
+	if timeout > 0 {
+		_ = rc.conn.SetDeadline(time.Now().Add(timeout))
+	}
+
+	fmt.Fprintf(rc.w, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(rc.w, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if err := rc.w.Flush(); err != nil {
+		return nil, err
+	}
+
+	return readReply(rc.r)
+}
+
+// readReply reads a single RESP2 reply. Bulk strings are returned as string,
+// integers as int64, arrays as []interface{} and nil replies as nil.
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	if len(line) < 3 || !strings.HasSuffix(line, "\r\n") {
+		return nil, fmt.Errorf("malformed redis reply %q", line)
+	}
+	kind, payload := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return payload, nil
+	case '-':
+		return nil, redisError(payload)
+	case ':':
+		return strconv.ParseInt(payload, 10, 64)
+	case '$':
+		n, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("malformed bulk length %q", payload)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("malformed array length %q", payload)
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, n)
+		for i := range items {
+			item, err := readReply(r)
+			var replyErr redisError
+			if err != nil && !errors.As(err, &replyErr) {
+				return nil, err
+			}
+			if err != nil {
+				item = replyErr
+			}
+			items[i] = item
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("unexpected redis reply type %q", kind)
+	}
+}
@@ -0,0 +1,352 @@
+package ratelimit
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis is a RESP server that emulates the limiter scripts in Go.
+type fakeRedis struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	buckets  map[string]float64
+	tats     map[string]float64
+	windows  map[string]int64
+	scripts  map[string]bool
+	commands []string
+}
+
+func newFakeRedis(t *testing.T) *fakeRedis {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	f := &fakeRedis{
+		listener: listener,
+		buckets:  make(map[string]float64),
+		tats:     make(map[string]float64),
+		windows:  make(map[string]int64),
+		scripts:  make(map[string]bool),
+	}
+	go f.serve()
+	t.Cleanup(func() { _ = listener.Close() })
+	return f
+}
+
+func (f *fakeRedis) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	for {
+		reply, err := readReply(r)
+		if err != nil {
+			return
+		}
+		items := reply.([]interface{})
+		args := make([]string, len(items))
+		for i, item := range items {
+			args[i] = item.(string)
+		}
+		_, _ = io.WriteString(conn, f.exec(args))
+	}
+}
+
+func (f *fakeRedis) exec(args []string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.commands = append(f.commands, args[0])
+
+	switch args[0] {
+	case "PING":
+		return "+PONG\r\n"
+	case "DEL":
+		delete(f.buckets, args[1])
+		delete(f.tats, args[1])
+		delete(f.windows, args[1])
+		return ":1\r\n"
+	case "EVALSHA":
+		if !f.scripts[args[1]] {
+			return "-NOSCRIPT No matching script.\r\n"
+		}
+		return f.run(args[1], args[3], args[4:])
+	case "EVAL":
+		script := newRedisScript(args[1])
+		f.scripts[script.sha] = true
+		return f.run(script.sha, args[3], args[4:])
+	}
+	return "-ERR unknown command\r\n"
+}
+
+// run emulates a script without refill or expiry.
+func (f *fakeRedis) run(sha, key string, argv []string) string {
+	switch sha {
+	case tokenBucketLua.sha:
+		capacity, _ := strconv.ParseFloat(argv[0], 64)
+		n, _ := strconv.ParseFloat(argv[2], 64)
+		tokens, ok := f.buckets[key]
+		if !ok {
+			tokens = capacity
+		}
+		allowed := 0
+		if tokens >= n {
+			tokens -= n
+			allowed = 1
+		}
+		f.buckets[key] = tokens
+		s := strconv.FormatFloat(tokens, 'f', -1, 64)
+		return fmt.Sprintf("*2\r\n:%d\r\n$%d\r\n%s\r\n", allowed, len(s), s)
+	case gcraLua.sha:
+		// The clock stands still, so the TAT is how far it is ahead of now.
+		interval, _ := strconv.ParseFloat(argv[0], 64)
+		burst, _ := strconv.ParseFloat(argv[1], 64)
+		n, _ := strconv.ParseFloat(argv[2], 64)
+		tat := f.tats[key]
+		allowed := 0
+		if tat+n*interval <= burst*interval {
+			tat += n * interval
+			allowed = 1
+		}
+		f.tats[key] = tat
+		s := strconv.FormatFloat(tat, 'f', -1, 64)
+		return fmt.Sprintf("*2\r\n:%d\r\n$%d\r\n%s\r\n", allowed, len(s), s)
+	case slidingWindowLua.sha:
+		n, _ := strconv.ParseInt(argv[2], 10, 64)
+		f.windows[key] += n
+		return fmt.Sprintf(":%d\r\n", f.windows[key])
+	case reserveWindowLua.sha:
+		n, _ := strconv.ParseInt(argv[2], 10, 64)
+		limit, _ := strconv.ParseInt(argv[3], 10, 64)
+		added := 0
+		if f.windows[key]+n <= limit {
+			f.windows[key] += n
+			added = 1
+		}
+		return fmt.Sprintf("*3\r\n:%d\r\n:%d\r\n:0\r\n", added, f.windows[key])
+	case windowWaitLua.sha:
+		window, _ := strconv.ParseInt(argv[0], 10, 64)
+		n, _ := strconv.ParseInt(argv[1], 10, 64)
+		limit, _ := strconv.ParseInt(argv[2], 10, 64)
+		if f.windows[key]+n <= limit {
+			return ":0\r\n"
+		}
+		return fmt.Sprintf(":%d\r\n", window)
+	case adjustWindowLua.sha:
+		n, _ := strconv.ParseInt(argv[1], 10, 64)
+		if _, ok := f.windows[key]; !ok {
+			return ":0\r\n"
+		}
+		f.windows[key] += n
+		return ":1\r\n"
+	}
+	return "-ERR unknown script\r\n"
+}
+
+func (f *fakeRedis) count(command string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, c := range f.commands {
+		if c == command {
+			n++
+		}
+	}
+	return n
+}
+
+func TestRedisStore_SharedTokenBucket(t *testing.T) {
+	server := newFakeRedis(t)
+
+	// Two stores model two proxy replicas.
+	replicaA, _ := NewRedisStore(&RedisConfig{Address: server.listener.Addr().String()})
+	replicaB, _ := NewRedisStore(&RedisConfig{Address: server.listener.Addr().String()})
+	defer replicaA.Close()
+	defer replicaB.Close()
+
+	bucketA := replicaA.TokenBucket("key-1:rpm", 3, 0.05)
+	bucketB := replicaB.TokenBucket("key-1:rpm", 3, 0.05)
+
+	if !bucketA.Take(1) || !bucketB.Take(1) || !bucketA.Take(1) {
+		t.Fatal("Expected first 3 takes across replicas to succeed")
+	}
+	if bucketB.Take(1) {
+		t.Error("Expected shared bucket to be exhausted")
+	}
+	if remaining := bucketA.Remaining(); remaining != 0 {
+		t.Errorf("Expected 0 remaining, got %d", remaining)
+	}
+	if wait := bucketA.TimeUntilAvailable(1); wait != 20*time.Second {
+		t.Errorf("Expected 20s until available, got %v", wait)
+	}
+
+	// Scripts are sent once and then evaluated by digest.
+	if n := server.count("EVAL"); n != 1 {
+		t.Errorf("Expected script to be loaded once, got %d EVAL calls", n)
+	}
+
+	bucketB.Reset()
+	if !bucketA.Take(3) {
+		t.Error("Expected reset bucket to be full")
+	}
+}
+
+func TestRedisStore_SharedGCRA(t *testing.T) {
+	server := newFakeRedis(t)
+
+	replicaA, _ := NewRedisStore(&RedisConfig{Address: server.listener.Addr().String()})
+	replicaB, _ := NewRedisStore(&RedisConfig{Address: server.listener.Addr().String()})
+	defer replicaA.Close()
+	defer replicaB.Close()
+
+	gcraA := replicaA.GCRA("key-1:rps", 2, 4)
+	gcraB := replicaB.GCRA("key-1:rps", 2, 4)
+
+	if !gcraA.Take(1) || !gcraB.Take(1) {
+		t.Fatal("Expected a burst of 2 across replicas to succeed")
+	}
+	if gcraA.Take(1) {
+		t.Error("Expected shared limiter to reject requests beyond the burst")
+	}
+	if remaining := gcraB.Remaining(); remaining != 0 {
+		t.Errorf("Expected 0 remaining, got %d", remaining)
+	}
+	if wait := gcraB.TimeUntilAvailable(1); wait != 250*time.Millisecond {
+		t.Errorf("Expected 250ms until available, got %v", wait)
+	}
+
+	gcraA.Reset()
+	if remaining := gcraB.Remaining(); remaining != 2 {
+		t.Errorf("Expected reset limiter to allow a full burst, got %d", remaining)
+	}
+}
+
+func TestRedisStore_SharedSlidingWindow(t *testing.T) {
+	server := newFakeRedis(t)
+	store, _ := NewRedisStore(&RedisConfig{Address: server.listener.Addr().String()})
+	defer store.Close()
+
+	windowA := store.SlidingWindow("key-1:tpm", time.Minute, time.Second)
+	windowB := store.SlidingWindow("key-1:tpm", time.Minute, time.Second)
+
+	windowA.Add(500)
+	windowB.Add(250)
+	if sum := windowA.Sum(); sum != 750 {
+		t.Errorf("Expected shared sum 750, got %d", sum)
+	}
+	if wait := windowB.TimeUntilAvailable(300, 1000); wait != time.Minute {
+		t.Errorf("Expected to wait for the window to slide, got %v", wait)
+	}
+	if wait := windowB.TimeUntilAvailable(250, 1000); wait != 0 {
+		t.Errorf("Expected no wait within the limit, got %v", wait)
+	}
+}
+
+func TestRedisStore_ReserveWindow(t *testing.T) {
+	server := newFakeRedis(t)
+	store, _ := NewRedisStore(&RedisConfig{Address: server.listener.Addr().String()})
+	defer store.Close()
+
+	windowA := store.SlidingWindow("key-1:tpm", time.Minute, time.Second)
+	windowB := store.SlidingWindow("key-1:tpm", time.Minute, time.Second)
+
+	_, at, ok := windowA.Reserve(600, 1000)
+	if !ok {
+		t.Fatal("Expected reservation within the limit to succeed")
+	}
+	if sum, _, ok := windowB.Reserve(600, 1000); ok || sum != 600 {
+		t.Errorf("Expected shared reservation to be rejected with sum 600, got %v, %d", ok, sum)
+	}
+
+	windowA.AddAt(-400, at)
+	if sum := windowB.Sum(); sum != 200 {
+		t.Errorf("Expected shared sum 200 after refund, got %d", sum)
+	}
+}
+
+func TestRedisStore_LocalFallback(t *testing.T) {
+	// Reserve an address with nothing listening on it.
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	addr := listener.Addr().String()
+	_ = listener.Close()
+
+	store, _ := NewRedisStore(&RedisConfig{
+		Address:       addr,
+		DialTimeout:   50 * time.Millisecond,
+		FallbackRetry: time.Hour,
+	})
+	defer store.Close()
+
+	bucket := store.TokenBucket("key-1:rps", 2, 1)
+	if !bucket.Take(1) || !bucket.Take(1) {
+		t.Fatal("Expected local fallback to allow requests within capacity")
+	}
+	if bucket.Take(1) {
+		t.Error("Expected local fallback to enforce capacity")
+	}
+	if store.Available() {
+		t.Error("Expected store to report Redis unavailable")
+	}
+	if store.Fallbacks() < 3 {
+		t.Errorf("Expected fallbacks to be counted, got %d", store.Fallbacks())
+	}
+
+	window := store.SlidingWindow("key-1:tpm", time.Minute, time.Second)
+	window.Add(100)
+	if sum := window.Sum(); sum != 100 {
+		t.Errorf("Expected local window sum 100, got %d", sum)
+	}
+}
+
+func TestLimiter_WithRedisStore(t *testing.T) {
+	server := newFakeRedis(t)
+	store, _ := NewRedisStore(&RedisConfig{Address: server.listener.Addr().String(), KeyPrefix: "test:"})
+	defer store.Close()
+
+	config := Config{RequestsPerMinute: 2, TokensPerMinute: 1000}
+	replicaA := NewLimiterWithStore("key-1", config, store)
+	replicaB := NewLimiterWithStore("key-1", config, store)
+
+	if !replicaA.CheckRequest().Allowed || !replicaB.CheckRequest().Allowed {
+		t.Fatal("Expected requests within the shared limit to be allowed")
+	}
+	result := replicaA.CheckRequest()
+	if result.Allowed {
+		t.Fatal("Expected third request across replicas to be rejected")
+	}
+	if result.RetryAfter <= 0 {
+		t.Error("Expected a retry-after duration")
+	}
+
+	replicaA.RecordTokens(900)
+	if replicaB.CheckTokens(200).Allowed {
+		t.Error("Expected token usage to be shared across replicas")
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	for key := range server.buckets {
+		if !strings.HasPrefix(key, "test:key-1:") {
+			t.Errorf("Expected prefixed key, got %q", key)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package ratelimit
 
 import (
+	"sort"
 	"sync"
 	"time"
 )
@@ -108,6 +109,83 @@ func (sw *SlidingWindow) Sum() int64 {
 	return sum
 }
 
+// Reserve adds value to the current bucket if the window total stays
+// within limit.
+func (sw *SlidingWindow) Reserve(value, limit int64) (int64, time.Time, bool) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	now := time.Now()
+	sw.pruneLocked(now)
+
+	var sum int64
+	for i := 0; i < len(sw.buckets); i++ {
+		if !sw.buckets[i].timestamp.IsZero() {
+			sum += sw.buckets[i].value
+		}
+	}
+	if sum+value > limit {
+		return sum, time.Time{}, false
+	}
+
+	currentBucket := sw.findOrCreateBucketLocked(now)
+	currentBucket.value += value
+	return sum + value, currentBucket.timestamp, true
+}
+
+// AddAt adds value to the bucket starting at at, if it is still in the
+// window.
+func (sw *SlidingWindow) AddAt(value int64, at time.Time) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	sw.pruneLocked(time.Now())
+	for i := 0; i < len(sw.buckets); i++ {
+		if !sw.buckets[i].timestamp.IsZero() && sw.buckets[i].timestamp.Equal(at) {
+			sw.buckets[i].value += value
+			return
+		}
+	}
+}
+
+// TimeUntilAvailable returns how long until value can be added without
+// the window total exceeding limit, as buckets slide out of the window.
+func (sw *SlidingWindow) TimeUntilAvailable(value, limit int64) time.Duration {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	now := time.Now()
+	sw.pruneLocked(now)
+
+	var (
+		sum     int64
+		buckets []bucket
+	)
+	for i := 0; i < len(sw.buckets); i++ {
+		if !sw.buckets[i].timestamp.IsZero() {
+			sum += sw.buckets[i].value
+			buckets = append(buckets, sw.buckets[i])
+		}
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].timestamp.Before(buckets[j].timestamp)
+	})
+
+	// Buckets leave the window oldest first
+	var wait time.Duration
+	for _, b := range buckets {
+		if sum+value <= limit {
+			break
+		}
+		sum -= b.value
+		wait = b.timestamp.Add(sw.window).Sub(now)
+	}
+	if wait < 0 {
+		wait = 0
+	}
+	return wait
+}
+
 // Reset clears all buckets.
 func (sw *SlidingWindow) Reset() {
 	sw.mu.Lock()
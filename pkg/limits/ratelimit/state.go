@@ -0,0 +1,238 @@
+package ratelimit
+
+import (
+	"sort"
+	"time"
+)
+
+// State is a snapshot of the in-process state of a Limiter's buckets and
+// windows. It is persisted so that a restart does not reset the limits.
+//
+// Limits kept by a shared Store such as RedisStore are not included: their
+// state outlives the process already. Limits at rest (full buckets, empty
+// windows) are omitted too, since a restored limit starts at rest.
+type State struct {
+	// Buckets contains the request-based limits, keyed by limit ("rps",
+	// "rpm", "rph").
+	Buckets map[string]BucketState
+
+	// Windows contains the token-based limits, keyed by limit ("tpm",
+	// "tph").
+	Windows map[string][]WindowBucket
+}
+
+// BucketState is the state of a request-based limit.
+type BucketState struct {
+	// Algorithm is the algorithm of the limit: AlgorithmTokenBucket or
+	// AlgorithmGCRA. State is only restored into a limit of the same
+	// algorithm.
+	Algorithm string
+
+	// Tokens and LastRefill are the state of a token bucket.
+	Tokens     int64
+	LastRefill time.Time
+
+	// TAT is the theoretical arrival time of a GCRA limit.
+	TAT time.Time
+}
+
+// WindowBucket is the usage within one bucket of a sliding window.
+type WindowBucket struct {
+	// Start is when the bucket started.
+	Start time.Time
+
+	// Value is the usage within the bucket.
+	Value int64
+}
+
+// IsEmpty reports whether the state holds no limit.
+func (s *State) IsEmpty() bool {
+	return len(s.Buckets) == 0 && len(s.Windows) == 0
+}
+
+// State returns a snapshot of the limiter's in-process state.
+func (l *Limiter) State() *State {
+	state := &State{
+		Buckets: make(map[string]BucketState),
+		Windows: make(map[string][]WindowBucket),
+	}
+
+	for name, b := range l.buckets() {
+		switch b := b.(type) {
+		case *TokenBucket:
+			if s, ok := b.state(); ok {
+				state.Buckets[name] = s
+			}
+		case *GCRA:
+			if s, ok := b.state(); ok {
+				state.Buckets[name] = s
+			}
+		}
+	}
+	for name, w := range l.windows() {
+		if w, ok := w.(*SlidingWindow); ok {
+			if buckets := w.state(); len(buckets) > 0 {
+				state.Windows[name] = buckets
+			}
+		}
+	}
+	return state
+}
+
+// Restore replaces the limiter's in-process state with state, typically
+// loaded from storage at startup. Limits missing from state, kept by a
+// shared Store, or whose algorithm changed are left alone.
+func (l *Limiter) Restore(state *State) {
+	for name, b := range l.buckets() {
+		s, ok := state.Buckets[name]
+		if !ok {
+			continue
+		}
+		switch b := b.(type) {
+		case *TokenBucket:
+			if s.Algorithm == AlgorithmTokenBucket {
+				b.restore(s)
+			}
+		case *GCRA:
+			if s.Algorithm == AlgorithmGCRA {
+				b.restore(s)
+			}
+		}
+	}
+	for name, w := range l.windows() {
+		if w, ok := w.(*SlidingWindow); ok {
+			if buckets, ok := state.Windows[name]; ok {
+				w.restore(buckets)
+			}
+		}
+	}
+}
+
+// buckets returns the configured request-based limits by name.
+func (l *Limiter) buckets() map[string]Bucket {
+	buckets := make(map[string]Bucket)
+	if l.reqPerSecond != nil {
+		buckets["rps"] = l.reqPerSecond
+	}
+	if l.reqPerMinute != nil {
+		buckets["rpm"] = l.reqPerMinute
+	}
+	if l.reqPerHour != nil {
+		buckets["rph"] = l.reqPerHour
+	}
+	return buckets
+}
+
+// windows returns the configured token-based limits by name.
+func (l *Limiter) windows() map[string]Window {
+	windows := make(map[string]Window)
+	if l.tokensPerMinute != nil {
+		windows["tpm"] = l.tokensPerMinute
+	}
+	if l.tokensPerHour != nil {
+		windows["tph"] = l.tokensPerHour
+	}
+	return windows
+}
+
+// state returns the bucket's state, or false if the bucket is full.
+func (tb *TokenBucket) state() (BucketState, bool) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	tb.refillLocked()
+	if tb.tokens >= tb.capacity {
+		return BucketState{}, false
+	}
+	return BucketState{
+		Algorithm:  AlgorithmTokenBucket,
+		Tokens:     tb.tokens,
+		LastRefill: tb.lastRefill,
+	}, true
+}
+
+// restore replaces the bucket's state. Tokens beyond the capacity, e.g.
+// after the limit was lowered, are dropped.
+func (tb *TokenBucket) restore(s BucketState) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	tb.tokens = min(max(s.Tokens, 0), tb.capacity)
+	tb.lastRefill = s.LastRefill
+	if now := time.Now(); tb.lastRefill.After(now) {
+		tb.lastRefill = now
+	}
+}
+
+// state returns the limiter's state, or false if a full burst would be
+// admitted.
+func (g *GCRA) state() (BucketState, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if !g.tat.After(time.Now()) {
+		return BucketState{}, false
+	}
+	return BucketState{Algorithm: AlgorithmGCRA, TAT: g.tat}, true
+}
+
+// restore replaces the limiter's state. A TAT beyond the burst tolerance,
+// e.g. after the rate was lowered, is brought within it.
+func (g *GCRA) restore(s BucketState) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	latest := time.Now().Add(time.Duration(g.burst) * g.interval)
+	g.tat = s.TAT
+	if g.tat.After(latest) {
+		g.tat = latest
+	}
+}
+
+// state returns the buckets within the window, oldest first.
+func (sw *SlidingWindow) state() []WindowBucket {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	sw.pruneLocked(time.Now())
+	var buckets []WindowBucket
+	for _, b := range sw.buckets {
+		if !b.timestamp.IsZero() && b.value != 0 {
+			buckets = append(buckets, WindowBucket{Start: b.timestamp, Value: b.value})
+		}
+	}
+	sort.Slice(buckets, func(i, j int) bool {
+		return buckets[i].Start.Before(buckets[j].Start)
+	})
+	return buckets
+}
+
+// restore replaces the window's buckets with those still within the
+// window, keeping the newest if there are more than the window holds.
+func (sw *SlidingWindow) restore(buckets []WindowBucket) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	for i := range sw.buckets {
+		sw.buckets[i] = bucket{}
+	}
+	sw.head = 0
+
+	cutoff := time.Now().Add(-sw.window)
+	sorted := append([]WindowBucket(nil), buckets...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start.After(sorted[j].Start)
+	})
+
+	n := 0
+	for _, b := range sorted {
+		if n == len(sw.buckets) {
+			break
+		}
+		if b.Start.Before(cutoff) {
+			continue
+		}
+		sw.buckets[n] = bucket{timestamp: b.Start.Truncate(sw.bucketSize), value: b.Value}
+		n++
+	}
+}
@@ -0,0 +1,87 @@
+package ratelimit
+
+import "time"
+
+// Bucket is a token bucket used for request-based limits.
+//
+// TokenBucket and GCRA are the in-process implementations; RedisStore
+// provides buckets shared between proxy replicas.
+type Bucket interface {
+	// Take attempts to consume n tokens. Returns true if they were available.
+	Take(n int64) bool
+
+	// Remaining returns the number of tokens currently available.
+	Remaining() int64
+
+	// Capacity returns the maximum bucket capacity.
+	Capacity() int64
+
+	// TimeUntilAvailable returns how long until n tokens will be available.
+	TimeUntilAvailable(n int64) time.Duration
+
+	// Reset refills the bucket to capacity.
+	Reset()
+}
+
+// Window is a sliding window counter used for token-based limits.
+//
+// SlidingWindow is the in-process implementation; RedisStore provides
+// windows shared between proxy replicas.
+type Window interface {
+	// Add increments the counter by value.
+	Add(value int64)
+
+	// Sum returns the total across the window.
+	Sum() int64
+
+	// Reserve adds value if the window total stays within limit. It
+	// returns the window total, the start of the bucket value was added
+	// to (for AddAt), and whether value was added.
+	Reserve(value, limit int64) (sum int64, at time.Time, ok bool)
+
+	// AddAt adds value to the bucket starting at at, as returned by
+	// Reserve. Nothing is added once the bucket has left the window.
+	AddAt(value int64, at time.Time)
+
+	// TimeUntilAvailable returns how long until value can be added
+	// without the window total exceeding limit. If value exceeds limit, it
+	// returns how long until the window is empty.
+	TimeUntilAvailable(value, limit int64) time.Duration
+
+	// Reset clears the window.
+	Reset()
+}
+
+// Store creates the buckets and windows backing a Limiter.
+//
+// The key identifies the limited entity and the limit (e.g.,
+// "api-key-123:rps"); stores sharing state between replicas use it to
+// address the shared counter.
+type Store interface {
+	// TokenBucket returns the bucket for key.
+	TokenBucket(key string, capacity int64, refillRate float64) Bucket
+
+	// GCRA returns the GCRA limiter for key.
+	GCRA(key string, burst int64, rate float64) Bucket
+
+	// SlidingWindow returns the window for key.
+	SlidingWindow(key string, window, bucketSize time.Duration) Window
+}
+
+// memoryStore creates in-process limiters. It is the default Store.
+type memoryStore struct{}
+
+// TokenBucket implements Store.
+func (memoryStore) TokenBucket(_ string, capacity int64, refillRate float64) Bucket {
+	return NewTokenBucket(capacity, refillRate)
+}
+
+// GCRA implements Store.
+func (memoryStore) GCRA(_ string, burst int64, rate float64) Bucket {
+	return NewGCRA(burst, rate)
+}
+
+// SlidingWindow implements Store.
+func (memoryStore) SlidingWindow(_ string, window, bucketSize time.Duration) Window {
+	return NewSlidingWindow(window, bucketSize)
+}
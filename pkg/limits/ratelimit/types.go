@@ -2,9 +2,33 @@ package ratelimit
 
 import "time"
 
+// Algorithms for request-based limits.
+const (
+	// AlgorithmTokenBucket allows bursts up to the bucket capacity while
+	// maintaining the average rate. This is the default.
+	AlgorithmTokenBucket = "token_bucket"
+
+	// AlgorithmGCRA paces requests evenly at the rate, without bursts.
+	AlgorithmGCRA = "gcra"
+)
+
 // Config contains configuration for all rate limiters for a single identifier.
 // This is passed to the Limiter to configure all rate limiting dimensions.
 type Config struct {
+	// Algorithm selects how request-based limits are enforced:
+	// AlgorithmTokenBucket or AlgorithmGCRA. Token-based limits always
+	// use sliding windows.
+	// Default: AlgorithmTokenBucket
+	Algorithm string
+
+	// BurstMultiplier scales the burst capacity of the token buckets of
+	// request-based limits: 2x the per-second rate, the full per-minute
+	// rate, and 5 minutes' worth of the per-hour rate. For example, 1.5
+	// allows bursts 50% larger. It does not change the average rate and
+	// does not apply to AlgorithmGCRA.
+	// Default: 1
+	BurstMultiplier float64
+
 	// RequestsPerSecond limits requests per second using token bucket.
 	RequestsPerSecond int
 
@@ -22,6 +46,10 @@ type Config struct {
 
 	// MaxConcurrent limits simultaneous requests.
 	MaxConcurrent int
+
+	// MaxConcurrentStreams limits simultaneous streaming requests. Streams
+	// also count towards MaxConcurrent.
+	MaxConcurrentStreams int
 }
 
 // CheckResult contains the result of a rate limit check.
@@ -8,6 +8,7 @@
 //   - Memory: Fast in-memory storage (default, no persistence)
 //   - SQLite: Lightweight file-based persistence with snapshots
 //   - PostgreSQL: Production-grade persistence for distributed deployments
+//   - Redis: Low-latency budget coordination for distributed deployments
 //
 // # Usage
 //
@@ -26,6 +27,21 @@
 //	// Load state
 //	state, err := backend.Load(ctx, "api-key-123", "api_key")
 //
+// # Shared Budgets
+//
+// Backends shared between proxy replicas implement BudgetMerger. Instead of
+// overwriting each other's state with Save, replicas merge the budget usage
+// they recorded since their previous merge and read back the combined state.
+// PostgresBackend stores each rolling window bucket as a row and adds
+// amounts in a single transaction; RedisBackend keeps the buckets in a hash
+// and adds amounts in a single Lua script.
+//
+// # Usage History
+//
+// Backends that keep per-identifier usage history for reporting implement
+// UsageStore. Usage is kept in hourly buckets for UsageRetention; buckets
+// added for the same hour are summed.
+//
 // # Thread Safety
 //
 // All storage backends are thread-safe and support concurrent access
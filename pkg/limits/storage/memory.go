@@ -2,21 +2,34 @@ package storage
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
 	"sync"
 	"time"
 )
 
 // MemoryBackend implements Backend using in-memory storage.
 // This is the default backend and provides fast access with no persistence.
-// All data is lost when the process exits.
+// All data is lost when the process exits, unless a snapshot file is
+// configured: the states are then written to the file periodically and on
+// Close, and loaded from it on startup. Usage history is never written to
+// the snapshot file.
 //
 // MemoryBackend is thread-safe and supports concurrent access using sync.RWMutex.
 type MemoryBackend struct {
 	// states maps composite key (dimension:identifier) to limit state.
 	states map[string]*LimitState
 
-	// mu protects access to states map.
+	// usage maps composite key (dimension:identifier) to hourly usage
+	// buckets by start (Unix seconds).
+	usage map[string]map[int64]*UsageBucket
+
+	// mu protects access to states and usage maps.
 	mu sync.RWMutex
 
 	// maxEntries is the maximum number of entries before eviction (LRU).
@@ -25,7 +38,16 @@ type MemoryBackend struct {
 	// cleanupInterval is how often to run cleanup.
 	cleanupInterval time.Duration
 
-	// done signals the cleanup goroutine to stop.
+	// snapshotPath is the file the states are written to, if any.
+	snapshotPath string
+
+	// snapshotInterval is how often to write the snapshot file.
+	snapshotInterval time.Duration
+
+	// snapshotMu serializes writes of the snapshot file.
+	snapshotMu sync.Mutex
+
+	// done signals the background goroutines to stop.
 	done chan struct{}
 }
 
@@ -44,6 +66,14 @@ type MemoryBackendConfig struct {
 	// Entries not updated within this period are eligible for cleanup.
 	// Default: 24 hours
 	RetentionPeriod time.Duration
+
+	// SnapshotPath is the file to persist states to so they survive a
+	// restart. Empty disables persistence.
+	SnapshotPath string
+
+	// SnapshotInterval is how often to write the snapshot file.
+	// Default: 30 seconds
+	SnapshotInterval time.Duration
 }
 
 // NewMemoryBackend creates a new in-memory storage backend with default settings.
@@ -67,16 +97,34 @@ func NewMemoryBackendWithConfig(cfg MemoryBackendConfig) *MemoryBackend {
 	if cfg.RetentionPeriod == 0 {
 		cfg.RetentionPeriod = 24 * time.Hour
 	}
+	if cfg.SnapshotInterval == 0 {
+		cfg.SnapshotInterval = 30 * time.Second
+	}
 
 	backend := &MemoryBackend{
-		states:          make(map[string]*LimitState),
-		maxEntries:      cfg.MaxEntries,
-		cleanupInterval: cfg.CleanupInterval,
-		done:            make(chan struct{}),
+		states:           make(map[string]*LimitState),
+		usage:            make(map[string]map[int64]*UsageBucket),
+		maxEntries:       cfg.MaxEntries,
+		cleanupInterval:  cfg.CleanupInterval,
+		snapshotPath:     cfg.SnapshotPath,
+		snapshotInterval: cfg.SnapshotInterval,
+		done:             make(chan struct{}),
+	}
+
+	// Restore persisted states. A snapshot that cannot be read is not
+	// fatal: the backend starts empty and overwrites it.
+	if backend.snapshotPath != "" {
+		if err := backend.loadSnapshot(); err != nil {
+			slog.Warn("failed to load limits snapshot, starting empty",
+				"path", backend.snapshotPath, "error", err)
+		}
 	}
 
 	// Start background cleanup goroutine
 	go backend.cleanupLoop(cfg.RetentionPeriod)
+	if backend.snapshotPath != "" {
+		go backend.snapshotLoop()
+	}
 
 	return backend
 }
@@ -179,7 +227,8 @@ func (m *MemoryBackend) List(ctx context.Context, dimension string) ([]*LimitSta
 	return states, nil
 }
 
-// Cleanup removes expired state entries based on retention policy.
+// Cleanup removes expired state entries based on retention policy, and
+// usage buckets older than UsageRetention.
 func (m *MemoryBackend) Cleanup(ctx context.Context, olderThan time.Time) (int, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -192,14 +241,81 @@ func (m *MemoryBackend) Cleanup(ctx context.Context, olderThan time.Time) (int,
 		}
 	}
 
+	cutoff := time.Now().Add(-UsageRetention).Unix()
+	for key, buckets := range m.usage {
+		for start := range buckets {
+			if start < cutoff {
+				delete(buckets, start)
+			}
+		}
+		if len(buckets) == 0 {
+			delete(m.usage, key)
+		}
+	}
+
 	return deleted, nil
 }
 
-// Close releases any resources held by the backend.
+// AddUsage adds usage to the usage history.
+func (m *MemoryBackend) AddUsage(ctx context.Context, usage []UsageBucket) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, u := range usage {
+		if u.Identifier == "" || u.Dimension == "" {
+			return fmt.Errorf("identifier and dimension cannot be empty")
+		}
+		key := m.makeKey(u.Identifier, u.Dimension)
+		buckets := m.usage[key]
+		if buckets == nil {
+			buckets = make(map[int64]*UsageBucket)
+			m.usage[key] = buckets
+		}
+		start := u.Start.Truncate(time.Hour)
+		b := buckets[start.Unix()]
+		if b == nil {
+			b = &UsageBucket{Identifier: u.Identifier, Dimension: u.Dimension, Start: start}
+			buckets[start.Unix()] = b
+		}
+		b.add(u)
+	}
+	return nil
+}
+
+// QueryUsage returns the usage history of an identifier and dimension.
+func (m *MemoryBackend) QueryUsage(ctx context.Context, identifier string, dimension string, start, end time.Time) ([]UsageBucket, error) {
+	if identifier == "" {
+		return nil, fmt.Errorf("identifier cannot be empty")
+	}
+	if dimension == "" {
+		return nil, fmt.Errorf("dimension cannot be empty")
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var usage []UsageBucket
+	for _, b := range m.usage[m.makeKey(identifier, dimension)] {
+		if !b.Start.Before(start) && b.Start.Before(end) {
+			usage = append(usage, *b)
+		}
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		return usage[i].Start.Before(usage[j].Start)
+	})
+	return usage, nil
+}
+
+// Close releases any resources held by the backend. With a snapshot
+// file, the states are written to it a final time.
 func (m *MemoryBackend) Close() error {
-	// Signal cleanup goroutine to stop
+	// Signal background goroutines to stop
 	close(m.done)
-	return nil
+
+	if m.snapshotPath == "" {
+		return nil
+	}
+	return m.writeSnapshot()
 }
 
 // Size returns the current number of stored states.
@@ -254,3 +370,77 @@ func (m *MemoryBackend) cleanupLoop(retentionPeriod time.Duration) {
 		}
 	}
 }
+
+// snapshotLoop periodically writes the snapshot file.
+func (m *MemoryBackend) snapshotLoop() {
+	ticker := time.NewTicker(m.snapshotInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := m.writeSnapshot(); err != nil {
+				slog.Warn("failed to write limits snapshot", "path", m.snapshotPath, "error", err)
+			}
+		case <-m.done:
+			return
+		}
+	}
+}
+
+// writeSnapshot writes the states to a temporary file and renames it over
+// the snapshot file, so that a crash never leaves a partial file.
+func (m *MemoryBackend) writeSnapshot() error {
+	m.snapshotMu.Lock()
+	defer m.snapshotMu.Unlock()
+
+	m.mu.RLock()
+	states := make([]*LimitState, 0, len(m.states))
+	for _, state := range m.states {
+		states = append(states, state)
+	}
+	data, err := json.Marshal(states)
+	m.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("failed to marshal limit states: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(m.snapshotPath), 0o755); err != nil {
+		return fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+	tmp := m.snapshotPath + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	if err := os.Rename(tmp, m.snapshotPath); err != nil {
+		return fmt.Errorf("failed to write snapshot: %w", err)
+	}
+	return nil
+}
+
+// loadSnapshot loads the states from the snapshot file. A missing file is
+// not an error.
+func (m *MemoryBackend) loadSnapshot() error {
+	data, err := os.ReadFile(m.snapshotPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read snapshot: %w", err)
+	}
+
+	var states []*LimitState
+	if err := json.Unmarshal(data, &states); err != nil {
+		return fmt.Errorf("failed to unmarshal snapshot: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, state := range states {
+		if state == nil || state.Identifier == "" || state.Dimension == "" {
+			continue
+		}
+		m.states[m.makeKey(state.Identifier, state.Dimension)] = state
+	}
+	return nil
+}
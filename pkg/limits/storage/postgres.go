@@ -0,0 +1,715 @@
+package storage
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Budget periods stored in the budget bucket table.
+const (
+	periodHourly  = "hourly"
+	periodDaily   = "daily"
+	periodMonthly = "monthly"
+	periodTotal   = "total"
+)
+
+// Retention of budget buckets per period. Buckets older than the window
+// no longer count towards any budget.
+var budgetPeriodWindows = map[string]time.Duration{
+	periodHourly:  time.Hour,
+	periodDaily:   24 * time.Hour,
+	periodMonthly: 30 * 24 * time.Hour,
+}
+
+const (
+	pgCreateStatesTable = `CREATE TABLE IF NOT EXISTS limit_states (
+		identifier TEXT NOT NULL,
+		dimension TEXT NOT NULL,
+		rate_limit_state TEXT NOT NULL DEFAULT '',
+		budget_state TEXT NOT NULL DEFAULT '',
+		last_updated BIGINT NOT NULL,
+		created_at BIGINT NOT NULL,
+		PRIMARY KEY (dimension, identifier)
+	)`
+
+	pgCreateStatesIndex = `CREATE INDEX IF NOT EXISTS limit_states_last_updated_idx ON limit_states (last_updated)`
+
+	pgCreateBucketsTable = `CREATE TABLE IF NOT EXISTS limit_budget_buckets (
+		dimension TEXT NOT NULL,
+		identifier TEXT NOT NULL,
+		period TEXT NOT NULL,
+		bucket_start BIGINT NOT NULL,
+		amount DOUBLE PRECISION NOT NULL,
+		PRIMARY KEY (dimension, identifier, period, bucket_start)
+	)`
+
+	pgSaveState = `INSERT INTO limit_states (identifier, dimension, rate_limit_state, budget_state, last_updated, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (dimension, identifier) DO UPDATE SET
+			rate_limit_state = excluded.rate_limit_state,
+			budget_state = excluded.budget_state,
+			last_updated = excluded.last_updated`
+
+	pgLoadState = `SELECT identifier, dimension, rate_limit_state, budget_state, last_updated, created_at
+		FROM limit_states
+		WHERE identifier = $1 AND dimension = $2`
+
+	pgListStates = `SELECT identifier, dimension, rate_limit_state, budget_state, last_updated, created_at
+		FROM limit_states
+		WHERE dimension = $1`
+
+	pgDeleteState = `DELETE FROM limit_states WHERE identifier = $1 AND dimension = $2`
+
+	pgDeleteBuckets = `DELETE FROM limit_budget_buckets WHERE identifier = $1 AND dimension = $2`
+
+	pgCleanupStates = `DELETE FROM limit_states WHERE last_updated < $1`
+
+	pgCleanupBuckets = `DELETE FROM limit_budget_buckets WHERE period <> 'total' AND bucket_start < $1`
+
+	pgAddBuckets = `INSERT INTO limit_budget_buckets (dimension, identifier, period, bucket_start, amount)
+		SELECT $1, $2, d.period, d.bucket_start, d.amount
+		FROM unnest($3::text[], $4::bigint[], $5::float8[]) AS d(period, bucket_start, amount)
+		ON CONFLICT (dimension, identifier, period, bucket_start) DO UPDATE SET
+			amount = limit_budget_buckets.amount + excluded.amount`
+
+	pgPruneBuckets = `DELETE FROM limit_budget_buckets
+		WHERE dimension = $1 AND identifier = $2 AND (
+			(period = 'hourly' AND bucket_start < $3) OR
+			(period = 'daily' AND bucket_start < $4) OR
+			(period = 'monthly' AND bucket_start < $5))`
+
+	pgSelectBuckets = `SELECT period, bucket_start, amount
+		FROM limit_budget_buckets
+		WHERE dimension = $1 AND identifier = $2`
+
+	pgCreateUsageTable = `CREATE TABLE IF NOT EXISTS limit_usage (
+		dimension TEXT NOT NULL,
+		identifier TEXT NOT NULL,
+		bucket_start BIGINT NOT NULL,
+		requests BIGINT NOT NULL,
+		prompt_tokens BIGINT NOT NULL,
+		completion_tokens BIGINT NOT NULL,
+		cost DOUBLE PRECISION NOT NULL,
+		PRIMARY KEY (dimension, identifier, bucket_start)
+	)`
+
+	pgCreateUsageIndex = `CREATE INDEX IF NOT EXISTS limit_usage_bucket_start_idx ON limit_usage (bucket_start)`
+
+	pgAddUsage = `INSERT INTO limit_usage (dimension, identifier, bucket_start, requests, prompt_tokens, completion_tokens, cost)
+		SELECT $1, $2, d.bucket_start, d.requests, d.prompt_tokens, d.completion_tokens, d.cost
+		FROM unnest($3::bigint[], $4::bigint[], $5::bigint[], $6::bigint[], $7::float8[])
+			AS d(bucket_start, requests, prompt_tokens, completion_tokens, cost)
+		ON CONFLICT (dimension, identifier, bucket_start) DO UPDATE SET
+			requests = limit_usage.requests + excluded.requests,
+			prompt_tokens = limit_usage.prompt_tokens + excluded.prompt_tokens,
+			completion_tokens = limit_usage.completion_tokens + excluded.completion_tokens,
+			cost = limit_usage.cost + excluded.cost`
+
+	pgPruneUsage = `DELETE FROM limit_usage WHERE bucket_start < $1`
+
+	pgSelectUsage = `SELECT bucket_start, requests, prompt_tokens, completion_tokens, cost
+		FROM limit_usage
+		WHERE dimension = $1 AND identifier = $2 AND bucket_start >= $3 AND bucket_start < $4
+		ORDER BY bucket_start`
+)
+
+// PostgresBackend implements Backend using PostgreSQL for persistence.
+// It is intended for deployments with several proxy replicas: all replicas
+// share the same state, and budget usage recorded by each replica is
+// combined with MergeBudget rather than overwritten.
+//
+// The backend uses the lib/pq driver with at most PoolSize open
+// connections. It is not meant to be called on the request path; the limits
+// manager snapshots budget state to it periodically.
+type PostgresBackend struct {
+	db        *sql.DB
+	opTimeout time.Duration
+	closeOnce sync.Once
+}
+
+// PostgresBackendConfig configures the PostgreSQL backend.
+type PostgresBackendConfig struct {
+	// Host is the PostgreSQL server hostname.
+	Host string
+
+	// Port is the PostgreSQL server port.
+	// Default: 5432
+	Port int
+
+	// Database is the name of the database to use.
+	Database string
+
+	// User is the PostgreSQL user for authentication.
+	User string
+
+	// Password is the PostgreSQL password for authentication.
+	Password string
+
+	// SSLMode controls TLS: "disable", "require", "verify-ca" or
+	// "verify-full".
+	// Default: "require"
+	SSLMode string
+
+	// PoolSize is the maximum number of open connections. Operations wait
+	// for a free connection when all of them are in use.
+	// Default: 4
+	PoolSize int
+
+	// DialTimeout bounds connecting and authenticating. It is rounded up
+	// to whole seconds.
+	// Default: 5 seconds
+	DialTimeout time.Duration
+
+	// OpTimeout bounds each operation, including waiting for a connection.
+	// Default: 5 seconds
+	OpTimeout time.Duration
+}
+
+// NewPostgresBackend connects to PostgreSQL and creates the schema if it
+// doesn't exist.
+func NewPostgresBackend(cfg PostgresBackendConfig) (*PostgresBackend, error) {
+	// Apply defaults
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("host cannot be empty")
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 5432
+	}
+	if cfg.SSLMode == "" {
+		cfg.SSLMode = "require"
+	}
+	if cfg.PoolSize <= 0 {
+		cfg.PoolSize = 4
+	}
+	if cfg.DialTimeout == 0 {
+		cfg.DialTimeout = 5 * time.Second
+	}
+	if cfg.OpTimeout == 0 {
+		cfg.OpTimeout = 5 * time.Second
+	}
+
+	switch cfg.SSLMode {
+	case "disable", "require", "verify-ca", "verify-full":
+	default:
+		return nil, fmt.Errorf("invalid ssl mode %q", cfg.SSLMode)
+	}
+
+	connector, err := pq.NewConnector(postgresDSN(&cfg))
+	if err != nil {
+		return nil, fmt.Errorf("invalid connection settings: %w", err)
+	}
+	db := sql.OpenDB(connector)
+	db.SetMaxOpenConns(cfg.PoolSize)
+	db.SetMaxIdleConns(cfg.PoolSize)
+
+	backend := &PostgresBackend{db: db, opTimeout: cfg.OpTimeout}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.DialTimeout+cfg.OpTimeout)
+	defer cancel()
+	if err := backend.initSchema(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize schema: %w", err)
+	}
+
+	return backend, nil
+}
+
+// postgresDSN returns the connection URL of cfg.
+func postgresDSN(cfg *PostgresBackendConfig) string {
+	query := url.Values{}
+	query.Set("sslmode", cfg.SSLMode)
+	query.Set("connect_timeout", strconv.Itoa(int((cfg.DialTimeout+time.Second-1)/time.Second)))
+	dsn := url.URL{
+		Scheme:   "postgres",
+		Host:     net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port)),
+		Path:     "/" + cfg.Database,
+		RawQuery: query.Encode(),
+	}
+	if cfg.User != "" {
+		dsn.User = url.UserPassword(cfg.User, cfg.Password)
+	}
+	return dsn.String()
+}
+
+// initSchema creates the tables if they don't exist.
+func (p *PostgresBackend) initSchema(ctx context.Context) error {
+	for _, stmt := range []string{
+		pgCreateStatesTable,
+		pgCreateStatesIndex,
+		pgCreateBucketsTable,
+		pgCreateUsageTable,
+		pgCreateUsageIndex,
+	} {
+		if _, err := p.db.ExecContext(ctx, stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withTimeout bounds an operation by the configured operation timeout.
+func (p *PostgresBackend) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, p.opTimeout)
+}
+
+// Save persists the limit state for an identifier.
+func (p *PostgresBackend) Save(ctx context.Context, state *LimitState) error {
+	if state == nil {
+		return fmt.Errorf("state cannot be nil")
+	}
+	if state.Identifier == "" {
+		return fmt.Errorf("identifier cannot be empty")
+	}
+	if state.Dimension == "" {
+		return fmt.Errorf("dimension cannot be empty")
+	}
+
+	// Serialize rate limit state
+	var rateLimitJSON []byte
+	var err error
+	if state.RateLimit != nil {
+		rateLimitJSON, err = json.Marshal(state.RateLimit)
+		if err != nil {
+			return fmt.Errorf("failed to marshal rate limit state: %w", err)
+		}
+	}
+
+	// Serialize budget state
+	var budgetJSON []byte
+	if state.Budget != nil {
+		budgetJSON, err = json.Marshal(state.Budget)
+		if err != nil {
+			return fmt.Errorf("failed to marshal budget state: %w", err)
+		}
+	}
+
+	// Update timestamps
+	now := time.Now()
+	if state.CreatedAt.IsZero() {
+		state.CreatedAt = now
+	}
+	if state.LastUpdated.IsZero() {
+		state.LastUpdated = now
+	}
+
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	_, err = p.db.ExecContext(ctx, pgSaveState,
+		state.Identifier,
+		state.Dimension,
+		string(rateLimitJSON),
+		string(budgetJSON),
+		state.LastUpdated.Unix(),
+		state.CreatedAt.Unix(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+
+	return nil
+}
+
+// Load retrieves the limit state for an identifier and dimension.
+func (p *PostgresBackend) Load(ctx context.Context, identifier string, dimension string) (*LimitState, error) {
+	if identifier == "" {
+		return nil, fmt.Errorf("identifier cannot be empty")
+	}
+	if dimension == "" {
+		return nil, fmt.Errorf("dimension cannot be empty")
+	}
+
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	state, err := scanState(p.db.QueryRowContext(ctx, pgLoadState, identifier, dimension))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load state: %w", err)
+	}
+
+	return state, nil
+}
+
+// Delete removes the limit state and budget buckets for an identifier and
+// dimension, in one transaction.
+func (p *PostgresBackend) Delete(ctx context.Context, identifier string, dimension string) error {
+	if identifier == "" {
+		return fmt.Errorf("identifier cannot be empty")
+	}
+	if dimension == "" {
+		return fmt.Errorf("dimension cannot be empty")
+	}
+
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, pgDeleteState, identifier, dimension); err != nil {
+		return fmt.Errorf("failed to delete state: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, pgDeleteBuckets, identifier, dimension); err != nil {
+		return fmt.Errorf("failed to delete budget buckets: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// List returns all limit states for a dimension.
+func (p *PostgresBackend) List(ctx context.Context, dimension string) ([]*LimitState, error) {
+	if dimension == "" {
+		return nil, fmt.Errorf("dimension cannot be empty")
+	}
+
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	rows, err := p.db.QueryContext(ctx, pgListStates, dimension)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list states: %w", err)
+	}
+	defer rows.Close()
+
+	var states []*LimitState
+	for rows.Next() {
+		state, err := scanState(rows)
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, state)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list states: %w", err)
+	}
+
+	return states, nil
+}
+
+// Cleanup removes state entries not updated since olderThan, and windowed
+// budget buckets that started before it, in one transaction.
+func (p *PostgresBackend) Cleanup(ctx context.Context, olderThan time.Time) (int, error) {
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, pgCleanupStates, olderThan.Unix())
+	if err != nil {
+		return 0, fmt.Errorf("failed to cleanup: %w", err)
+	}
+	deleted, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, pgCleanupBuckets, olderThan.Unix()); err != nil {
+		return 0, fmt.Errorf("failed to cleanup budget buckets: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return int(deleted), nil
+}
+
+// MergeBudget adds budget usage to the shared state and returns the merged
+// state across all replicas.
+//
+// delta holds only usage recorded since the caller's previous merge; its
+// bucket amounts are added to the stored buckets with the same start time,
+// and its TotalSpent to the stored total. Buckets that have left their
+// window are pruned. The update and the read run in one transaction.
+func (p *PostgresBackend) MergeBudget(ctx context.Context, identifier string, dimension string, delta *BudgetState) (*BudgetState, error) {
+	if identifier == "" {
+		return nil, fmt.Errorf("identifier cannot be empty")
+	}
+	if dimension == "" {
+		return nil, fmt.Errorf("dimension cannot be empty")
+	}
+
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if periods, starts, amounts := encodeBudgetDelta(delta); len(periods) > 0 {
+		_, err := tx.ExecContext(ctx, pgAddBuckets,
+			dimension, identifier, pq.Array(periods), pq.Array(starts), pq.Array(amounts))
+		if err != nil {
+			return nil, fmt.Errorf("failed to add budget usage: %w", err)
+		}
+	}
+
+	now := time.Now()
+	_, err = tx.ExecContext(ctx, pgPruneBuckets,
+		dimension, identifier,
+		now.Add(-budgetPeriodWindows[periodHourly]).Unix(),
+		now.Add(-budgetPeriodWindows[periodDaily]).Unix(),
+		now.Add(-budgetPeriodWindows[periodMonthly]).Unix(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to prune budget buckets: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, pgSelectBuckets, dimension, identifier)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read budget buckets: %w", err)
+	}
+	defer rows.Close()
+
+	merged := &BudgetState{}
+	for rows.Next() {
+		var period string
+		var start int64
+		var amount float64
+		if err := rows.Scan(&period, &start, &amount); err != nil {
+			return nil, fmt.Errorf("failed to scan budget bucket: %w", err)
+		}
+		b := BudgetBucket{Timestamp: time.Unix(start, 0), Amount: amount}
+		switch period {
+		case periodHourly:
+			merged.HourlyBuckets = append(merged.HourlyBuckets, b)
+		case periodDaily:
+			merged.DailyBuckets = append(merged.DailyBuckets, b)
+		case periodMonthly:
+			merged.MonthlyBuckets = append(merged.MonthlyBuckets, b)
+		case periodTotal:
+			merged.TotalSpent = amount
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read budget buckets: %w", err)
+	}
+	rows.Close()
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return merged, nil
+}
+
+// AddUsage adds usage to the usage history and prunes buckets older than
+// UsageRetention, in one transaction.
+func (p *PostgresBackend) AddUsage(ctx context.Context, usage []UsageBucket) error {
+	groups := groupUsage(usage)
+	for _, group := range groups {
+		if group[0].Identifier == "" || group[0].Dimension == "" {
+			return fmt.Errorf("identifier and dimension cannot be empty")
+		}
+	}
+
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, group := range groups {
+		var starts, requests, promptTokens, completionTokens []int64
+		var costs []float64
+		for _, b := range group {
+			starts = append(starts, b.Start.Unix())
+			requests = append(requests, b.Requests)
+			promptTokens = append(promptTokens, b.PromptTokens)
+			completionTokens = append(completionTokens, b.CompletionTokens)
+			costs = append(costs, b.Cost)
+		}
+		_, err := tx.ExecContext(ctx, pgAddUsage,
+			group[0].Dimension, group[0].Identifier,
+			pq.Array(starts), pq.Array(requests), pq.Array(promptTokens), pq.Array(completionTokens), pq.Array(costs))
+		if err != nil {
+			return fmt.Errorf("failed to add usage: %w", err)
+		}
+	}
+	if _, err := tx.ExecContext(ctx, pgPruneUsage, time.Now().Add(-UsageRetention).Unix()); err != nil {
+		return fmt.Errorf("failed to prune usage: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// QueryUsage returns the usage history of an identifier and dimension.
+func (p *PostgresBackend) QueryUsage(ctx context.Context, identifier string, dimension string, start, end time.Time) ([]UsageBucket, error) {
+	if identifier == "" {
+		return nil, fmt.Errorf("identifier cannot be empty")
+	}
+	if dimension == "" {
+		return nil, fmt.Errorf("dimension cannot be empty")
+	}
+
+	ctx, cancel := p.withTimeout(ctx)
+	defer cancel()
+	rows, err := p.db.QueryContext(ctx, pgSelectUsage, dimension, identifier, start.Unix(), end.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []UsageBucket
+	for rows.Next() {
+		b := UsageBucket{Identifier: identifier, Dimension: dimension}
+		var bucketStart int64
+		if err := rows.Scan(&bucketStart, &b.Requests, &b.PromptTokens, &b.CompletionTokens, &b.Cost); err != nil {
+			return nil, fmt.Errorf("failed to scan usage: %w", err)
+		}
+		b.Start = time.Unix(bucketStart, 0)
+		usage = append(usage, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query usage: %w", err)
+	}
+	return usage, nil
+}
+
+// Close closes the connection pool.
+// Close is idempotent and safe to call multiple times.
+func (p *PostgresBackend) Close() error {
+	var err error
+	p.closeOnce.Do(func() {
+		err = p.db.Close()
+	})
+	return err
+}
+
+// rowScanner is implemented by *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// scanState converts a limit_states row to a LimitState.
+func scanState(row rowScanner) (*LimitState, error) {
+	var (
+		state                     LimitState
+		rateLimitJSON, budgetJSON string
+		lastUpdated, createdAt    int64
+	)
+	err := row.Scan(&state.Identifier, &state.Dimension, &rateLimitJSON, &budgetJSON, &lastUpdated, &createdAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan state: %w", err)
+	}
+	state.LastUpdated = time.Unix(lastUpdated, 0)
+	state.CreatedAt = time.Unix(createdAt, 0)
+
+	// Deserialize rate limit state
+	if rateLimitJSON != "" {
+		state.RateLimit = &RateLimitState{}
+		if err := json.Unmarshal([]byte(rateLimitJSON), state.RateLimit); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal rate limit state: %w", err)
+		}
+	}
+
+	// Deserialize budget state
+	if budgetJSON != "" {
+		state.Budget = &BudgetState{}
+		if err := json.Unmarshal([]byte(budgetJSON), state.Budget); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal budget state: %w", err)
+		}
+	}
+
+	return &state, nil
+}
+
+// encodeBudgetDelta flattens a budget delta into parallel period, bucket
+// start and amount columns. Buckets with the same start are combined, since
+// one statement cannot update a row twice; empty buckets are skipped.
+func encodeBudgetDelta(delta *BudgetState) (periods []string, starts []int64, amounts []float64) {
+	if delta == nil {
+		return nil, nil, nil
+	}
+	type key struct {
+		period string
+		start  int64
+	}
+	var order []key
+	sums := make(map[key]float64)
+	add := func(period string, start int64, amount float64) {
+		k := key{period, start}
+		if _, ok := sums[k]; !ok {
+			order = append(order, k)
+		}
+		sums[k] += amount
+	}
+	for _, b := range delta.HourlyBuckets {
+		add(periodHourly, b.Timestamp.Unix(), b.Amount)
+	}
+	for _, b := range delta.DailyBuckets {
+		add(periodDaily, b.Timestamp.Unix(), b.Amount)
+	}
+	for _, b := range delta.MonthlyBuckets {
+		add(periodMonthly, b.Timestamp.Unix(), b.Amount)
+	}
+	add(periodTotal, 0, delta.TotalSpent)
+
+	for _, k := range order {
+		if sums[k] == 0 {
+			continue
+		}
+		periods = append(periods, k.period)
+		starts = append(starts, k.start)
+		amounts = append(amounts, sums[k])
+	}
+	return periods, starts, amounts
+}
+
+// groupUsage groups usage buckets by dimension and identifier, combining
+// buckets with the same start hour, since one statement cannot update a row
+// twice.
+func groupUsage(usage []UsageBucket) [][]UsageBucket {
+	type key struct {
+		dimension, identifier string
+	}
+	var order []key
+	groups := make(map[key][]UsageBucket)
+	for _, u := range usage {
+		k := key{u.Dimension, u.Identifier}
+		if _, ok := groups[k]; !ok {
+			order = append(order, k)
+		}
+		u.Start = u.Start.Truncate(time.Hour)
+		merged := false
+		for i := range groups[k] {
+			if groups[k][i].Start.Equal(u.Start) {
+				groups[k][i].add(u)
+				merged = true
+				break
+			}
+		}
+		if !merged {
+			groups[k] = append(groups[k], u)
+		}
+	}
+
+	result := make([][]UsageBucket, 0, len(order))
+	for _, k := range order {
+		result = append(result, groups[k])
+	}
+	return result
+}
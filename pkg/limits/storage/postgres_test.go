@@ -0,0 +1,677 @@
+package storage
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// fakePostgres is a PostgreSQL server that emulates the statements used by
+// PostgresBackend in Go. It speaks the subset of the protocol used by
+// lib/pq: simple queries, unnamed prepared statements and transactions.
+type fakePostgres struct {
+	listener net.Listener
+
+	mu      sync.Mutex
+	states  map[string][]string
+	buckets map[string]float64    // dimension|identifier|period|start -> amount
+	usage   map[string][4]float64 // dimension|identifier|start -> requests, prompt and completion tokens, cost
+	fail    string                // statement that fails
+	conns   int
+	open    int
+	maxOpen int
+}
+
+// fakeQuery is a statement with its text-format parameters.
+type fakeQuery struct {
+	sql  string
+	args []string
+}
+
+// fakeSnapshot is the data of a fakePostgres at the start of a transaction.
+type fakeSnapshot struct {
+	states  map[string][]string
+	buckets map[string]float64
+	usage   map[string][4]float64
+}
+
+// fakeColumns is the number of columns returned by each query.
+var fakeColumns = map[string]int{
+	pgLoadState:     6,
+	pgListStates:    6,
+	pgSelectBuckets: 3,
+	pgSelectUsage:   5,
+}
+
+func newFakePostgres(t *testing.T) *fakePostgres {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	f := &fakePostgres{
+		listener: listener,
+		states:   make(map[string][]string),
+		buckets:  make(map[string]float64),
+		usage:    make(map[string][4]float64),
+	}
+	go f.serve()
+	t.Cleanup(func() { _ = listener.Close() })
+	return f
+}
+
+func (f *fakePostgres) config() PostgresBackendConfig {
+	addr := f.listener.Addr().(*net.TCPAddr)
+	return PostgresBackendConfig{
+		Host:     "127.0.0.1",
+		Port:     addr.Port,
+		Database: "mercator",
+		User:     "mercator",
+		Password: "s3cret",
+		SSLMode:  "disable",
+	}
+}
+
+func (f *fakePostgres) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		f.mu.Lock()
+		f.conns++
+		f.open++
+		f.maxOpen = max(f.maxOpen, f.open)
+		f.mu.Unlock()
+		go f.handle(conn)
+	}
+}
+
+// fakeConn is a client connection to a fakePostgres.
+type fakeConn struct {
+	r *bufio.Reader
+	w *bufio.Writer
+
+	snapshot *fakeSnapshot // set in a transaction
+	failed   bool          // the transaction failed
+}
+
+func (f *fakePostgres) handle(conn net.Conn) {
+	defer func() {
+		conn.Close()
+		f.mu.Lock()
+		f.open--
+		f.mu.Unlock()
+	}()
+
+	if _, err := readStartup(conn); err != nil {
+		return
+	}
+	fc := &fakeConn{r: bufio.NewReader(conn), w: bufio.NewWriter(conn)}
+	fc.write('R', []byte{0, 0, 0, 0})
+	fc.write('S', []byte("server_version\x0016.0\x00"))
+	fc.write('Z', []byte{'I'})
+	if fc.w.Flush() != nil {
+		return
+	}
+
+	var current fakeQuery
+	skip := false
+	for {
+		kind, payload, err := fc.read()
+		if err != nil || kind == 'X' {
+			return
+		}
+		switch kind {
+		case 'Q':
+			f.simpleQuery(fc, strings.TrimRight(string(payload), "\x00"))
+		case 'P':
+			current = fakeQuery{sql: strings.SplitN(string(payload[1:]), "\x00", 2)[0]}
+			fc.write('1', nil)
+		case 'D':
+			params := binary.BigEndian.AppendUint16(nil, uint16(countParams(current.sql)))
+			for range countParams(current.sql) {
+				params = binary.BigEndian.AppendUint32(params, 0)
+			}
+			fc.write('t', params)
+			if n := fakeColumns[current.sql]; n > 0 {
+				fc.write('T', rowDescription(n))
+			} else {
+				fc.write('n', nil)
+			}
+		case 'B':
+			current.args = parseBindArgs(payload)
+			fc.write('2', nil)
+		case 'E':
+			if !skip {
+				skip = !f.execute(fc, current)
+			}
+		case 'S':
+			skip = false
+			fc.write('Z', []byte{fc.status()})
+			if fc.w.Flush() != nil {
+				return
+			}
+		}
+	}
+}
+
+// simpleQuery runs a statement without parameters.
+func (f *fakePostgres) simpleQuery(fc *fakeConn, sql string) {
+	f.mu.Lock()
+	switch strings.Fields(sql)[0] {
+	case "BEGIN":
+		fc.snapshot = f.snapshot()
+		fc.write('C', []byte("BEGIN\x00"))
+	case "COMMIT":
+		fc.snapshot, fc.failed = nil, false
+		fc.write('C', []byte("COMMIT\x00"))
+	case "ROLLBACK":
+		if fc.snapshot != nil {
+			f.restore(fc.snapshot)
+		}
+		fc.snapshot, fc.failed = nil, false
+		fc.write('C', []byte("ROLLBACK\x00"))
+	default:
+		_, tag := f.exec(fakeQuery{sql: sql})
+		fc.write('C', append([]byte(tag), 0))
+	}
+	f.mu.Unlock()
+	fc.write('Z', []byte{fc.status()})
+	_ = fc.w.Flush()
+}
+
+// execute runs a bound statement, reporting whether it succeeded.
+func (f *fakePostgres) execute(fc *fakeConn, q fakeQuery) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if fc.failed || q.sql == f.fail {
+		fc.failed = fc.snapshot != nil
+		fc.write('E', pgErrorPayload("40001", "injected failure"))
+		return false
+	}
+	rows, tag := f.exec(q)
+	for _, row := range rows {
+		msg := binary.BigEndian.AppendUint16(nil, uint16(len(row)))
+		for _, v := range row {
+			msg = binary.BigEndian.AppendUint32(msg, uint32(len(v)))
+			msg = append(msg, v...)
+		}
+		fc.write('D', msg)
+	}
+	fc.write('C', append([]byte(tag), 0))
+	return true
+}
+
+// snapshot copies the data. Caller must hold f.mu.
+func (f *fakePostgres) snapshot() *fakeSnapshot {
+	s := &fakeSnapshot{
+		states:  make(map[string][]string, len(f.states)),
+		buckets: make(map[string]float64, len(f.buckets)),
+		usage:   make(map[string][4]float64, len(f.usage)),
+	}
+	for k, v := range f.states {
+		s.states[k] = v
+	}
+	for k, v := range f.buckets {
+		s.buckets[k] = v
+	}
+	for k, v := range f.usage {
+		s.usage[k] = v
+	}
+	return s
+}
+
+// restore resets the data to a snapshot. Caller must hold f.mu.
+func (f *fakePostgres) restore(s *fakeSnapshot) {
+	f.states, f.buckets, f.usage = s.states, s.buckets, s.usage
+}
+
+// status returns the transaction status reported by ReadyForQuery.
+func (fc *fakeConn) status() byte {
+	switch {
+	case fc.failed:
+		return 'E'
+	case fc.snapshot != nil:
+		return 'T'
+	}
+	return 'I'
+}
+
+func (fc *fakeConn) write(kind byte, payload []byte) {
+	_ = fc.w.WriteByte(kind)
+	_ = binary.Write(fc.w, binary.BigEndian, uint32(len(payload)+4))
+	_, _ = fc.w.Write(payload)
+}
+
+func (fc *fakeConn) read() (byte, []byte, error) {
+	kind, err := fc.r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	var size uint32
+	if err := binary.Read(fc.r, binary.BigEndian, &size); err != nil {
+		return 0, nil, err
+	}
+	payload := make([]byte, size-4)
+	_, err = io.ReadFull(fc.r, payload)
+	return kind, payload, err
+}
+
+// exec emulates a single statement. Caller must hold f.mu.
+func (f *fakePostgres) exec(q fakeQuery) ([][]string, string) {
+	a := q.args
+	switch q.sql {
+	case pgCreateStatesTable, pgCreateBucketsTable, pgCreateUsageTable:
+		return nil, "CREATE TABLE"
+	case pgCreateStatesIndex, pgCreateUsageIndex:
+		return nil, "CREATE INDEX"
+	case pgSaveState:
+		key := a[1] + "|" + a[0]
+		if existing, ok := f.states[key]; ok {
+			a = append(append([]string{}, a[:5]...), existing[5])
+		}
+		f.states[key] = a
+		return nil, "INSERT 0 1"
+	case pgLoadState:
+		if row, ok := f.states[a[1]+"|"+a[0]]; ok {
+			return [][]string{row}, "SELECT 1"
+		}
+		return nil, "SELECT 0"
+	case pgListStates:
+		var rows [][]string
+		for _, row := range f.states {
+			if row[1] == a[0] {
+				rows = append(rows, row)
+			}
+		}
+		return rows, fmt.Sprintf("SELECT %d", len(rows))
+	case pgDeleteState:
+		n := 0
+		if _, ok := f.states[a[1]+"|"+a[0]]; ok {
+			delete(f.states, a[1]+"|"+a[0])
+			n = 1
+		}
+		return nil, fmt.Sprintf("DELETE %d", n)
+	case pgDeleteBuckets:
+		return nil, f.deleteBuckets(func(k []string, _ int64) bool { return k[0] == a[1] && k[1] == a[0] })
+	case pgCleanupStates:
+		cutoff, _ := strconv.ParseInt(a[0], 10, 64)
+		n := 0
+		for key, row := range f.states {
+			if updated, _ := strconv.ParseInt(row[4], 10, 64); updated < cutoff {
+				delete(f.states, key)
+				n++
+			}
+		}
+		return nil, fmt.Sprintf("DELETE %d", n)
+	case pgCleanupBuckets:
+		cutoff, _ := strconv.ParseInt(a[0], 10, 64)
+		return nil, f.deleteBuckets(func(k []string, start int64) bool { return k[2] != periodTotal && start < cutoff })
+	case pgAddBuckets:
+		periods, starts, amounts := parseArray(a[2]), parseArray(a[3]), parseArray(a[4])
+		for i := range periods {
+			amount, _ := strconv.ParseFloat(amounts[i], 64)
+			f.buckets[strings.Join([]string{a[0], a[1], periods[i], starts[i]}, "|")] += amount
+		}
+		return nil, fmt.Sprintf("INSERT 0 %d", len(periods))
+	case pgPruneBuckets:
+		cutoffs := map[string]int64{}
+		for i, period := range []string{periodHourly, periodDaily, periodMonthly} {
+			cutoffs[period], _ = strconv.ParseInt(a[2+i], 10, 64)
+		}
+		return nil, f.deleteBuckets(func(k []string, start int64) bool {
+			cutoff, ok := cutoffs[k[2]]
+			return k[0] == a[0] && k[1] == a[1] && ok && start < cutoff
+		})
+	case pgAddUsage:
+		starts := parseArray(a[2])
+		columns := [][]string{parseArray(a[3]), parseArray(a[4]), parseArray(a[5]), parseArray(a[6])}
+		for i, start := range starts {
+			key := strings.Join([]string{a[0], a[1], start}, "|")
+			row := f.usage[key]
+			for c := range columns {
+				value, _ := strconv.ParseFloat(columns[c][i], 64)
+				row[c] += value
+			}
+			f.usage[key] = row
+		}
+		return nil, fmt.Sprintf("INSERT 0 %d", len(starts))
+	case pgPruneUsage:
+		cutoff, _ := strconv.ParseInt(a[0], 10, 64)
+		n := 0
+		for key := range f.usage {
+			if start, _ := strconv.ParseInt(strings.Split(key, "|")[2], 10, 64); start < cutoff {
+				delete(f.usage, key)
+				n++
+			}
+		}
+		return nil, fmt.Sprintf("DELETE %d", n)
+	case pgSelectUsage:
+		from, _ := strconv.ParseInt(a[2], 10, 64)
+		to, _ := strconv.ParseInt(a[3], 10, 64)
+		var rows [][]string
+		for key, row := range f.usage {
+			k := strings.Split(key, "|")
+			start, _ := strconv.ParseInt(k[2], 10, 64)
+			if k[0] == a[0] && k[1] == a[1] && start >= from && start < to {
+				rows = append(rows, []string{k[2],
+					strconv.FormatFloat(row[0], 'f', -1, 64), strconv.FormatFloat(row[1], 'f', -1, 64),
+					strconv.FormatFloat(row[2], 'f', -1, 64), strconv.FormatFloat(row[3], 'g', -1, 64)})
+			}
+		}
+		sort.Slice(rows, func(i, j int) bool { return rows[i][0] < rows[j][0] })
+		return rows, fmt.Sprintf("SELECT %d", len(rows))
+	case pgSelectBuckets:
+		var rows [][]string
+		for key, amount := range f.buckets {
+			k := strings.Split(key, "|")
+			if k[0] == a[0] && k[1] == a[1] {
+				rows = append(rows, []string{k[2], k[3], strconv.FormatFloat(amount, 'g', -1, 64)})
+			}
+		}
+		sort.Slice(rows, func(i, j int) bool { return rows[i][1] < rows[j][1] })
+		return rows, fmt.Sprintf("SELECT %d", len(rows))
+	}
+	return nil, "UNKNOWN"
+}
+
+func (f *fakePostgres) deleteBuckets(match func(key []string, start int64) bool) string {
+	n := 0
+	for key := range f.buckets {
+		k := strings.Split(key, "|")
+		start, _ := strconv.ParseInt(k[3], 10, 64)
+		if match(k, start) {
+			delete(f.buckets, key)
+			n++
+		}
+	}
+	return fmt.Sprintf("DELETE %d", n)
+}
+
+func readStartup(r io.Reader) ([]byte, error) {
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	body := make([]byte, binary.BigEndian.Uint32(header)-4)
+	_, err := io.ReadFull(r, body)
+	return body, err
+}
+
+// countParams returns the number of parameters of a statement.
+func countParams(sql string) int {
+	n := 0
+	for i := 1; strings.Contains(sql, "$"+strconv.Itoa(i)); i++ {
+		n = i
+	}
+	return n
+}
+
+// rowDescription describes n text columns.
+func rowDescription(n int) []byte {
+	msg := binary.BigEndian.AppendUint16(nil, uint16(n))
+	for i := range n {
+		msg = append(msg, fmt.Sprintf("c%d", i)...)
+		msg = append(msg, 0)
+		msg = binary.BigEndian.AppendUint32(msg, 0)  // table
+		msg = binary.BigEndian.AppendUint16(msg, 0)  // column
+		msg = binary.BigEndian.AppendUint32(msg, 25) // text
+		msg = binary.BigEndian.AppendUint16(msg, 0xffff)
+		msg = binary.BigEndian.AppendUint32(msg, 0xffffffff)
+		msg = binary.BigEndian.AppendUint16(msg, 0)
+	}
+	return msg
+}
+
+func parseBindArgs(payload []byte) []string {
+	// Skip the portal and statement names and the format codes
+	payload = payload[2:]
+	payload = payload[2+2*int(binary.BigEndian.Uint16(payload)):]
+	n := int(binary.BigEndian.Uint16(payload))
+	payload = payload[2:]
+	args := make([]string, n)
+	for i := range args {
+		size := int(binary.BigEndian.Uint32(payload))
+		args[i] = string(payload[4 : 4+size])
+		payload = payload[4+size:]
+	}
+	return args
+}
+
+func parseArray(literal string) []string {
+	values := strings.Split(strings.Trim(literal, "{}"), ",")
+	for i, v := range values {
+		values[i] = strings.Trim(v, `"`)
+	}
+	return values
+}
+
+func pgErrorPayload(code, message string) []byte {
+	return []byte("SERROR\x00C" + code + "\x00M" + message + "\x00\x00")
+}
+
+func TestPostgresBackend_SaveLoad(t *testing.T) {
+	server := newFakePostgres(t)
+	backend, err := NewPostgresBackend(server.config())
+	if err != nil {
+		t.Fatalf("NewPostgresBackend failed: %v", err)
+	}
+	defer backend.Close()
+	ctx := context.Background()
+
+	bucketTime := time.Now().Truncate(time.Minute)
+	state := &LimitState{
+		Identifier: "key-1",
+		Dimension:  "api_key",
+		Budget: &BudgetState{
+			HourlyBuckets: []BudgetBucket{{Timestamp: bucketTime, Amount: 1.5}},
+			TotalSpent:    1.5,
+		},
+	}
+	if err := backend.Save(ctx, state); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := backend.Load(ctx, "key-1", "api_key")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded == nil || loaded.Budget == nil {
+		t.Fatal("Expected saved state to be loaded")
+	}
+	if loaded.Budget.TotalSpent != 1.5 || !loaded.Budget.HourlyBuckets[0].Timestamp.Equal(bucketTime) {
+		t.Errorf("Unexpected budget state %+v", loaded.Budget)
+	}
+	if loaded.RateLimit != nil {
+		t.Error("Expected no rate limit state")
+	}
+
+	missing, err := backend.Load(ctx, "key-2", "api_key")
+	if err != nil || missing != nil {
+		t.Errorf("Expected no state for unknown key, got %v, %v", missing, err)
+	}
+
+	states, err := backend.List(ctx, "api_key")
+	if err != nil || len(states) != 1 {
+		t.Errorf("Expected 1 listed state, got %d (%v)", len(states), err)
+	}
+
+	if err := backend.Delete(ctx, "key-1", "api_key"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if loaded, _ := backend.Load(ctx, "key-1", "api_key"); loaded != nil {
+		t.Error("Expected state to be deleted")
+	}
+}
+
+func TestPostgresBackend_MergeBudget(t *testing.T) {
+	server := newFakePostgres(t)
+	ctx := context.Background()
+
+	// Two backends model two proxy replicas.
+	replicaA, err := NewPostgresBackend(server.config())
+	if err != nil {
+		t.Fatalf("NewPostgresBackend failed: %v", err)
+	}
+	defer replicaA.Close()
+	replicaB, _ := NewPostgresBackend(server.config())
+	defer replicaB.Close()
+
+	now := time.Now()
+	minute := now.Truncate(time.Minute)
+	hour := now.Truncate(time.Hour)
+	delta := func(amount float64) *BudgetState {
+		return &BudgetState{
+			HourlyBuckets: []BudgetBucket{{Timestamp: minute, Amount: amount}},
+			DailyBuckets:  []BudgetBucket{{Timestamp: hour, Amount: amount}},
+			TotalSpent:    amount,
+		}
+	}
+
+	if _, err := replicaA.MergeBudget(ctx, "key-1", "api_key", delta(2)); err != nil {
+		t.Fatalf("MergeBudget failed: %v", err)
+	}
+	merged, err := replicaB.MergeBudget(ctx, "key-1", "api_key", delta(3))
+	if err != nil {
+		t.Fatalf("MergeBudget failed: %v", err)
+	}
+	if merged.TotalSpent != 5 {
+		t.Errorf("Expected merged total 5, got %.2f", merged.TotalSpent)
+	}
+	if len(merged.HourlyBuckets) != 1 || merged.HourlyBuckets[0].Amount != 5 || !merged.HourlyBuckets[0].Timestamp.Equal(minute) {
+		t.Errorf("Expected one hourly bucket of 5, got %+v", merged.HourlyBuckets)
+	}
+	if len(merged.DailyBuckets) != 1 || merged.DailyBuckets[0].Amount != 5 {
+		t.Errorf("Expected one daily bucket of 5, got %+v", merged.DailyBuckets)
+	}
+
+	// A merge without usage reads the shared state
+	merged, err = replicaA.MergeBudget(ctx, "key-1", "api_key", nil)
+	if err != nil || merged.TotalSpent != 5 {
+		t.Errorf("Expected shared total 5, got %+v (%v)", merged, err)
+	}
+
+	// Buckets outside their window are pruned
+	stale := &BudgetState{HourlyBuckets: []BudgetBucket{{Timestamp: minute.Add(-2 * time.Hour), Amount: 7}}}
+	merged, _ = replicaA.MergeBudget(ctx, "key-1", "api_key", stale)
+	if len(merged.HourlyBuckets) != 1 || merged.HourlyBuckets[0].Amount != 5 {
+		t.Errorf("Expected stale hourly bucket to be pruned, got %+v", merged.HourlyBuckets)
+	}
+
+	// Failed merges change nothing and keep the connection usable
+	server.mu.Lock()
+	server.fail = pgSelectBuckets
+	server.mu.Unlock()
+	_, err = replicaA.MergeBudget(ctx, "key-1", "api_key", delta(100))
+	var serverErr *pq.Error
+	if !errors.As(err, &serverErr) || serverErr.Code != "40001" {
+		t.Fatalf("Expected server error, got %v", err)
+	}
+	server.mu.Lock()
+	server.fail = ""
+	conns := server.conns
+	server.mu.Unlock()
+
+	merged, err = replicaA.MergeBudget(ctx, "key-1", "api_key", nil)
+	if err != nil || merged.TotalSpent != 5 {
+		t.Errorf("Expected failed merge to be rolled back, got %+v (%v)", merged, err)
+	}
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.conns != conns {
+		t.Error("Expected connection to be reused after a server error")
+	}
+}
+
+func TestPostgresBackend_Usage(t *testing.T) {
+	server := newFakePostgres(t)
+	backend, err := NewPostgresBackend(server.config())
+	if err != nil {
+		t.Fatalf("NewPostgresBackend failed: %v", err)
+	}
+	defer backend.Close()
+
+	checkUsageStore(t, backend)
+}
+
+func TestEncodeBudgetDelta_CombinesBuckets(t *testing.T) {
+	start := time.Unix(1700000040, 0)
+	periods, starts, amounts := encodeBudgetDelta(&BudgetState{
+		HourlyBuckets: []BudgetBucket{
+			{Timestamp: start, Amount: 1},
+			{Timestamp: start, Amount: 2},
+			{Timestamp: start.Add(time.Minute), Amount: 0},
+		},
+	})
+	if len(periods) != 1 || periods[0] != periodHourly || starts[0] != 1700000040 || amounts[0] != 3 {
+		t.Errorf("Unexpected encoding %v %v %v", periods, starts, amounts)
+	}
+}
+
+func TestPostgresBackend_PoolSize(t *testing.T) {
+	server := newFakePostgres(t)
+	cfg := server.config()
+	cfg.PoolSize = 2
+	backend, err := NewPostgresBackend(cfg)
+	if err != nil {
+		t.Fatalf("NewPostgresBackend failed: %v", err)
+	}
+	defer backend.Close()
+
+	var wg sync.WaitGroup
+	for i := range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			delta := &BudgetState{TotalSpent: 1}
+			if _, err := backend.MergeBudget(context.Background(), fmt.Sprintf("key-%d", i), "api_key", delta); err != nil {
+				t.Errorf("MergeBudget failed: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+	if server.maxOpen > 2 {
+		t.Errorf("Expected at most 2 open connections, got %d", server.maxOpen)
+	}
+}
+
+func TestPostgresDSN(t *testing.T) {
+	dsn := postgresDSN(&PostgresBackendConfig{
+		Host:        "db.internal",
+		Port:        5433,
+		Database:    "limits",
+		User:        "mercator",
+		Password:    "p@ss word/1",
+		SSLMode:     "verify-full",
+		DialTimeout: 1500 * time.Millisecond,
+	})
+	u, err := url.Parse(dsn)
+	if err != nil {
+		t.Fatalf("Invalid DSN %q: %v", dsn, err)
+	}
+	password, _ := u.User.Password()
+	if u.Host != "db.internal:5433" || u.Path != "/limits" || password != "p@ss word/1" {
+		t.Errorf("Unexpected DSN %q", dsn)
+	}
+	if q := u.Query(); q.Get("sslmode") != "verify-full" || q.Get("connect_timeout") != "2" {
+		t.Errorf("Unexpected DSN parameters %q", u.RawQuery)
+	}
+}
@@ -0,0 +1,475 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// mergeBudgetScript adds budget deltas to a hash of budget buckets and
+// returns the merged buckets. Fields are "<period>:<bucket start>" (Unix
+// seconds), plus "total". Buckets that have left their window are removed.
+//
+// KEYS[1]: budget key
+// ARGV: hourly, daily and monthly cutoffs (Unix seconds), TTL (seconds),
+// then field and amount pairs to add
+// Returns: flat list of field and amount pairs
+const mergeBudgetScript = `
+for i = 5, #ARGV, 2 do
+  redis.call('HINCRBYFLOAT', KEYS[1], ARGV[i], ARGV[i + 1])
+end
+local cutoffs = {hourly = tonumber(ARGV[1]), daily = tonumber(ARGV[2]), monthly = tonumber(ARGV[3])}
+local fields = redis.call('HGETALL', KEYS[1])
+local merged = {}
+for i = 1, #fields, 2 do
+  local period, start = string.match(fields[i], '^(%a+):(%d+)$')
+  if period and cutoffs[period] and tonumber(start) < cutoffs[period] then
+    redis.call('HDEL', KEYS[1], fields[i])
+  else
+    table.insert(merged, fields[i])
+    table.insert(merged, fields[i + 1])
+  end
+end
+if #merged > 0 then
+  redis.call('EXPIRE', KEYS[1], ARGV[4])
+end
+return merged
+`
+
+// addUsageScript adds usage to the hourly bucket fields of a usage hash,
+// "<bucket start>:<counter>" (Unix seconds), and sets its expiry.
+//
+// KEYS[1]: usage key
+// ARGV: TTL (seconds), bucket start, requests, prompt tokens, completion
+// tokens, cost
+const addUsageScript = `
+local prefix = ARGV[2] .. ':'
+redis.call('HINCRBY', KEYS[1], prefix .. 'requests', ARGV[3])
+redis.call('HINCRBY', KEYS[1], prefix .. 'prompt_tokens', ARGV[4])
+redis.call('HINCRBY', KEYS[1], prefix .. 'completion_tokens', ARGV[5])
+redis.call('HINCRBYFLOAT', KEYS[1], prefix .. 'cost', ARGV[6])
+redis.call('EXPIRE', KEYS[1], ARGV[1])
+return 1
+`
+
+// RedisClient sends commands to Redis. ratelimit.RedisStore implements it,
+// so the rate limit store's connection pool can be shared.
+type RedisClient interface {
+	// Do sends a command and returns its reply: bulk strings as string,
+	// integers as int64, arrays as []interface{} and nil replies as nil.
+	Do(ctx context.Context, args ...string) (interface{}, error)
+}
+
+// RedisBackend implements Backend using Redis. Like PostgresBackend, it is
+// intended for deployments with several proxy replicas and implements
+// BudgetMerger, but each merge is a single Lua script round trip, cheap
+// enough to coordinate budgets every second or so.
+//
+// Budget buckets are kept in a hash per identifier; a merge adds the
+// caller's deltas and reads back the totals of all replicas atomically.
+// Budget hashes expire after a month without spending, when every bucket
+// has left its window. Limit states are stored as JSON strings. Usage
+// history is kept in a hash per identifier and UTC day, which expires after
+// UsageRetention.
+type RedisBackend struct {
+	client    RedisClient
+	keyPrefix string
+}
+
+// RedisBackendConfig configures the Redis backend.
+type RedisBackendConfig struct {
+	// Client sends commands to Redis, e.g. a ratelimit.RedisStore.
+	Client RedisClient
+
+	// KeyPrefix is prepended to all keys.
+	// Default: "mercator:limits:"
+	KeyPrefix string
+}
+
+// budgetTTL is how long a budget hash is kept after its last merge: the
+// longest budget window, plus a margin.
+var budgetTTL = budgetPeriodWindows[periodMonthly] + 24*time.Hour
+
+// NewRedisBackend creates a Redis backend. It does not connect until the
+// first operation.
+func NewRedisBackend(cfg RedisBackendConfig) (*RedisBackend, error) {
+	if cfg.Client == nil {
+		return nil, fmt.Errorf("redis client cannot be nil")
+	}
+	if cfg.KeyPrefix == "" {
+		cfg.KeyPrefix = "mercator:limits:"
+	}
+
+	return &RedisBackend{
+		client:    cfg.Client,
+		keyPrefix: cfg.KeyPrefix,
+	}, nil
+}
+
+// Save persists the limit state for an identifier.
+func (r *RedisBackend) Save(ctx context.Context, state *LimitState) error {
+	if state == nil {
+		return fmt.Errorf("state cannot be nil")
+	}
+	if state.Identifier == "" {
+		return fmt.Errorf("identifier cannot be empty")
+	}
+	if state.Dimension == "" {
+		return fmt.Errorf("dimension cannot be empty")
+	}
+
+	now := time.Now()
+	if state.CreatedAt.IsZero() {
+		state.CreatedAt = now
+	}
+	if state.LastUpdated.IsZero() {
+		state.LastUpdated = now
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal limit state: %w", err)
+	}
+
+	if _, err := r.client.Do(ctx, "SET", r.stateKey(state.Identifier, state.Dimension), string(data)); err != nil {
+		return fmt.Errorf("failed to save state: %w", err)
+	}
+	if _, err := r.client.Do(ctx, "SADD", r.indexKey(state.Dimension), state.Identifier); err != nil {
+		return fmt.Errorf("failed to index state: %w", err)
+	}
+	if _, err := r.client.Do(ctx, "SADD", r.keyPrefix+"dimensions", state.Dimension); err != nil {
+		return fmt.Errorf("failed to index state: %w", err)
+	}
+	return nil
+}
+
+// Load retrieves the limit state for an identifier and dimension.
+func (r *RedisBackend) Load(ctx context.Context, identifier string, dimension string) (*LimitState, error) {
+	if identifier == "" {
+		return nil, fmt.Errorf("identifier cannot be empty")
+	}
+	if dimension == "" {
+		return nil, fmt.Errorf("dimension cannot be empty")
+	}
+
+	reply, err := r.client.Do(ctx, "GET", r.stateKey(identifier, dimension))
+	if err != nil {
+		return nil, fmt.Errorf("failed to load state: %w", err)
+	}
+	if reply == nil {
+		return nil, nil
+	}
+	data, ok := reply.(string)
+	if !ok {
+		return nil, fmt.Errorf("unexpected state reply %v", reply)
+	}
+
+	state := &LimitState{}
+	if err := json.Unmarshal([]byte(data), state); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal limit state: %w", err)
+	}
+	return state, nil
+}
+
+// Delete removes the limit state and budget buckets for an identifier and
+// dimension.
+func (r *RedisBackend) Delete(ctx context.Context, identifier string, dimension string) error {
+	if identifier == "" {
+		return fmt.Errorf("identifier cannot be empty")
+	}
+	if dimension == "" {
+		return fmt.Errorf("dimension cannot be empty")
+	}
+
+	if _, err := r.client.Do(ctx, "DEL", r.stateKey(identifier, dimension), r.budgetKey(identifier, dimension)); err != nil {
+		return fmt.Errorf("failed to delete state: %w", err)
+	}
+	if _, err := r.client.Do(ctx, "SREM", r.indexKey(dimension), identifier); err != nil {
+		return fmt.Errorf("failed to delete state: %w", err)
+	}
+	return nil
+}
+
+// List returns all limit states for a dimension.
+func (r *RedisBackend) List(ctx context.Context, dimension string) ([]*LimitState, error) {
+	if dimension == "" {
+		return nil, fmt.Errorf("dimension cannot be empty")
+	}
+
+	identifiers, err := r.members(ctx, r.indexKey(dimension))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list states: %w", err)
+	}
+
+	var states []*LimitState
+	for _, identifier := range identifiers {
+		state, err := r.Load(ctx, identifier, dimension)
+		if err != nil {
+			return nil, err
+		}
+		if state != nil {
+			states = append(states, state)
+		}
+	}
+	return states, nil
+}
+
+// Cleanup removes limit states not updated since olderThan. Budget buckets
+// expire on their own.
+func (r *RedisBackend) Cleanup(ctx context.Context, olderThan time.Time) (int, error) {
+	dimensions, err := r.members(ctx, r.keyPrefix+"dimensions")
+	if err != nil {
+		return 0, fmt.Errorf("failed to list dimensions: %w", err)
+	}
+
+	deleted := 0
+	for _, dimension := range dimensions {
+		states, err := r.List(ctx, dimension)
+		if err != nil {
+			return deleted, err
+		}
+		for _, state := range states {
+			if !state.LastUpdated.Before(olderThan) {
+				continue
+			}
+			if _, err := r.client.Do(ctx, "DEL", r.stateKey(state.Identifier, dimension)); err != nil {
+				return deleted, fmt.Errorf("failed to delete state: %w", err)
+			}
+			if _, err := r.client.Do(ctx, "SREM", r.indexKey(dimension), state.Identifier); err != nil {
+				return deleted, fmt.Errorf("failed to delete state: %w", err)
+			}
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// MergeBudget adds budget usage to the shared state and returns the merged
+// state across all replicas. See PostgresBackend.MergeBudget.
+func (r *RedisBackend) MergeBudget(ctx context.Context, identifier string, dimension string, delta *BudgetState) (*BudgetState, error) {
+	if identifier == "" {
+		return nil, fmt.Errorf("identifier cannot be empty")
+	}
+	if dimension == "" {
+		return nil, fmt.Errorf("dimension cannot be empty")
+	}
+
+	now := time.Now()
+	args := []string{
+		"EVAL", mergeBudgetScript, "1", r.budgetKey(identifier, dimension),
+		strconv.FormatInt(now.Add(-budgetPeriodWindows[periodHourly]).Unix(), 10),
+		strconv.FormatInt(now.Add(-budgetPeriodWindows[periodDaily]).Unix(), 10),
+		strconv.FormatInt(now.Add(-budgetPeriodWindows[periodMonthly]).Unix(), 10),
+		strconv.FormatInt(int64(budgetTTL/time.Second), 10),
+	}
+	periods, starts, amounts := encodeBudgetDelta(delta)
+	for i := range periods {
+		field := periodTotal
+		if periods[i] != periodTotal {
+			field = periods[i] + ":" + strconv.FormatInt(starts[i], 10)
+		}
+		args = append(args, field, strconv.FormatFloat(amounts[i], 'g', -1, 64))
+	}
+
+	reply, err := r.client.Do(ctx, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge budget state: %w", err)
+	}
+	items, ok := reply.([]interface{})
+	if !ok && reply != nil {
+		return nil, fmt.Errorf("unexpected budget reply %v", reply)
+	}
+	if len(items)%2 != 0 {
+		return nil, fmt.Errorf("unexpected budget reply with %d items", len(items))
+	}
+
+	merged := &BudgetState{}
+	for i := 0; i < len(items); i += 2 {
+		field, _ := items[i].(string)
+		value, _ := items[i+1].(string)
+		amount, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bucket amount %q: %w", value, err)
+		}
+		if field == periodTotal {
+			merged.TotalSpent = amount
+			continue
+		}
+
+		period, startStr, _ := strings.Cut(field, ":")
+		start, err := strconv.ParseInt(startStr, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bucket field %q: %w", field, err)
+		}
+		b := BudgetBucket{Timestamp: time.Unix(start, 0), Amount: amount}
+		switch period {
+		case periodHourly:
+			merged.HourlyBuckets = append(merged.HourlyBuckets, b)
+		case periodDaily:
+			merged.DailyBuckets = append(merged.DailyBuckets, b)
+		case periodMonthly:
+			merged.MonthlyBuckets = append(merged.MonthlyBuckets, b)
+		}
+	}
+	return merged, nil
+}
+
+// AddUsage adds usage to the usage history.
+func (r *RedisBackend) AddUsage(ctx context.Context, usage []UsageBucket) error {
+	ttl := strconv.FormatInt(int64(UsageRetention/time.Second), 10)
+	for _, u := range usage {
+		if u.Identifier == "" || u.Dimension == "" {
+			return fmt.Errorf("identifier and dimension cannot be empty")
+		}
+		start := u.Start.Truncate(time.Hour)
+		if _, err := r.client.Do(ctx,
+			"EVAL", addUsageScript, "1", r.usageKey(u.Identifier, u.Dimension, start),
+			ttl,
+			strconv.FormatInt(start.Unix(), 10),
+			strconv.FormatInt(u.Requests, 10),
+			strconv.FormatInt(u.PromptTokens, 10),
+			strconv.FormatInt(u.CompletionTokens, 10),
+			strconv.FormatFloat(u.Cost, 'f', -1, 64),
+		); err != nil {
+			return fmt.Errorf("failed to add usage: %w", err)
+		}
+	}
+	return nil
+}
+
+// QueryUsage returns the usage history of an identifier and dimension. It
+// reads one hash per day of the range, so the range is limited to
+// UsageRetention.
+func (r *RedisBackend) QueryUsage(ctx context.Context, identifier string, dimension string, start, end time.Time) ([]UsageBucket, error) {
+	if identifier == "" {
+		return nil, fmt.Errorf("identifier cannot be empty")
+	}
+	if dimension == "" {
+		return nil, fmt.Errorf("dimension cannot be empty")
+	}
+	if oldest := time.Now().Add(-UsageRetention); start.Before(oldest) {
+		start = oldest
+	}
+
+	buckets := make(map[int64]*UsageBucket)
+	for day := start.UTC().Truncate(24 * time.Hour); day.Before(end); day = day.Add(24 * time.Hour) {
+		fields, err := r.hash(ctx, r.usageKey(identifier, dimension, day))
+		if err != nil {
+			return nil, fmt.Errorf("failed to query usage: %w", err)
+		}
+		for field, value := range fields {
+			startStr, counter, _ := strings.Cut(field, ":")
+			bucketStart, err := strconv.ParseInt(startStr, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid usage field %q: %w", field, err)
+			}
+			if bucketStart < start.Unix() || bucketStart >= end.Unix() {
+				continue
+			}
+			b := buckets[bucketStart]
+			if b == nil {
+				b = &UsageBucket{Identifier: identifier, Dimension: dimension, Start: time.Unix(bucketStart, 0)}
+				buckets[bucketStart] = b
+			}
+			if counter == "cost" {
+				if b.Cost, err = strconv.ParseFloat(value, 64); err != nil {
+					return nil, fmt.Errorf("invalid usage cost %q: %w", value, err)
+				}
+				continue
+			}
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid usage value %q: %w", value, err)
+			}
+			switch counter {
+			case "requests":
+				b.Requests = n
+			case "prompt_tokens":
+				b.PromptTokens = n
+			case "completion_tokens":
+				b.CompletionTokens = n
+			}
+		}
+	}
+
+	usage := make([]UsageBucket, 0, len(buckets))
+	for _, b := range buckets {
+		usage = append(usage, *b)
+	}
+	sort.Slice(usage, func(i, j int) bool {
+		return usage[i].Start.Before(usage[j].Start)
+	})
+	return usage, nil
+}
+
+// Close closes the client if it implements io.Closer.
+func (r *RedisBackend) Close() error {
+	if closer, ok := r.client.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+// members returns the members of a set.
+func (r *RedisBackend) members(ctx context.Context, key string) ([]string, error) {
+	reply, err := r.client.Do(ctx, "SMEMBERS", key)
+	if err != nil {
+		return nil, err
+	}
+	items, ok := reply.([]interface{})
+	if !ok && reply != nil {
+		return nil, fmt.Errorf("unexpected set reply %v", reply)
+	}
+	members := make([]string, 0, len(items))
+	for _, item := range items {
+		if member, ok := item.(string); ok {
+			members = append(members, member)
+		}
+	}
+	return members, nil
+}
+
+// hash returns the fields of a hash.
+func (r *RedisBackend) hash(ctx context.Context, key string) (map[string]string, error) {
+	reply, err := r.client.Do(ctx, "HGETALL", key)
+	if err != nil {
+		return nil, err
+	}
+	items, ok := reply.([]interface{})
+	if !ok && reply != nil {
+		return nil, fmt.Errorf("unexpected hash reply %v", reply)
+	}
+	fields := make(map[string]string, len(items)/2)
+	for i := 0; i+1 < len(items); i += 2 {
+		field, _ := items[i].(string)
+		value, _ := items[i+1].(string)
+		fields[field] = value
+	}
+	return fields, nil
+}
+
+// stateKey returns the key of a limit state.
+func (r *RedisBackend) stateKey(identifier, dimension string) string {
+	return r.keyPrefix + "state:" + dimension + ":" + identifier
+}
+
+// budgetKey returns the key of a budget bucket hash.
+func (r *RedisBackend) budgetKey(identifier, dimension string) string {
+	return r.keyPrefix + "budget:" + dimension + ":" + identifier
+}
+
+// usageKey returns the key of the usage hash of the UTC day of t.
+func (r *RedisBackend) usageKey(identifier, dimension string, t time.Time) string {
+	return r.keyPrefix + "usage:" + dimension + ":" + identifier + ":" + t.UTC().Format("2006-01-02")
+}
+
+// indexKey returns the key of the set of identifiers with a state in a
+// dimension.
+func (r *RedisBackend) indexKey(dimension string) string {
+	return r.keyPrefix + "index:" + dimension
+}
@@ -0,0 +1,235 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedisClient is a RedisClient that implements the commands used by
+// RedisBackend in memory, emulating the budget merge script in Go.
+type fakeRedisClient struct {
+	mu      sync.Mutex
+	strings map[string]string
+	sets    map[string]map[string]bool
+	hashes  map[string]map[string]float64
+	ttls    map[string]string
+	fail    bool
+}
+
+func newFakeRedisClient() *fakeRedisClient {
+	return &fakeRedisClient{
+		strings: make(map[string]string),
+		sets:    make(map[string]map[string]bool),
+		hashes:  make(map[string]map[string]float64),
+		ttls:    make(map[string]string),
+	}
+}
+
+func (f *fakeRedisClient) Do(ctx context.Context, args ...string) (interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.fail {
+		return nil, fmt.Errorf("connection refused")
+	}
+
+	switch args[0] {
+	case "GET":
+		if value, ok := f.strings[args[1]]; ok {
+			return value, nil
+		}
+		return nil, nil
+	case "SET":
+		f.strings[args[1]] = args[2]
+		return "OK", nil
+	case "DEL":
+		for _, key := range args[1:] {
+			delete(f.strings, key)
+			delete(f.hashes, key)
+		}
+		return int64(len(args) - 1), nil
+	case "SADD":
+		if f.sets[args[1]] == nil {
+			f.sets[args[1]] = make(map[string]bool)
+		}
+		f.sets[args[1]][args[2]] = true
+		return int64(1), nil
+	case "SREM":
+		delete(f.sets[args[1]], args[2])
+		return int64(1), nil
+	case "SMEMBERS":
+		var members []interface{}
+		for member := range f.sets[args[1]] {
+			members = append(members, member)
+		}
+		return members, nil
+	case "HGETALL":
+		var fields []interface{}
+		for field, value := range f.hashes[args[1]] {
+			fields = append(fields, field, strconv.FormatFloat(value, 'f', -1, 64))
+		}
+		return fields, nil
+	case "EVAL":
+		switch args[1] {
+		case mergeBudgetScript:
+			return f.mergeBudget(args[3], args[4:]), nil
+		case addUsageScript:
+			return f.addUsage(args[3], args[4:]), nil
+		}
+		return nil, fmt.Errorf("unexpected script")
+	}
+	return nil, fmt.Errorf("unexpected command %s", args[0])
+}
+
+// mergeBudget emulates mergeBudgetScript.
+func (f *fakeRedisClient) mergeBudget(key string, argv []string) []interface{} {
+	hash := f.hashes[key]
+	if hash == nil {
+		hash = make(map[string]float64)
+		f.hashes[key] = hash
+	}
+	for i := 4; i+1 < len(argv); i += 2 {
+		amount, _ := strconv.ParseFloat(argv[i+1], 64)
+		hash[argv[i]] += amount
+	}
+
+	cutoffs := map[string]string{periodHourly: argv[0], periodDaily: argv[1], periodMonthly: argv[2]}
+	var merged []interface{}
+	for field, amount := range hash {
+		period, start, found := strings.Cut(field, ":")
+		if found {
+			cutoff, _ := strconv.ParseInt(cutoffs[period], 10, 64)
+			if s, _ := strconv.ParseInt(start, 10, 64); s < cutoff {
+				delete(hash, field)
+				continue
+			}
+		}
+		merged = append(merged, field, strconv.FormatFloat(amount, 'f', -1, 64))
+	}
+	return merged
+}
+
+// addUsage emulates addUsageScript.
+func (f *fakeRedisClient) addUsage(key string, argv []string) int64 {
+	hash := f.hashes[key]
+	if hash == nil {
+		hash = make(map[string]float64)
+		f.hashes[key] = hash
+	}
+	for i, counter := range []string{"requests", "prompt_tokens", "completion_tokens", "cost"} {
+		amount, _ := strconv.ParseFloat(argv[2+i], 64)
+		hash[argv[1]+":"+counter] += amount
+	}
+	f.ttls[key] = argv[0]
+	return 1
+}
+
+func TestRedisBackend_SaveLoadDelete(t *testing.T) {
+	backend, err := NewRedisBackend(RedisBackendConfig{Client: newFakeRedisClient()})
+	if err != nil {
+		t.Fatalf("NewRedisBackend failed: %v", err)
+	}
+	defer backend.Close()
+	ctx := context.Background()
+
+	state := &LimitState{
+		Identifier: "key-123",
+		Dimension:  "api_key",
+		Budget:     &BudgetState{TotalSpent: 4.5},
+	}
+	if err := backend.Save(ctx, state); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	loaded, err := backend.Load(ctx, "key-123", "api_key")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded == nil || loaded.Budget == nil || loaded.Budget.TotalSpent != 4.5 {
+		t.Fatalf("Expected loaded budget 4.5, got %+v", loaded)
+	}
+
+	states, err := backend.List(ctx, "api_key")
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(states) != 1 {
+		t.Errorf("Expected 1 state, got %d", len(states))
+	}
+
+	if err := backend.Delete(ctx, "key-123", "api_key"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if loaded, _ := backend.Load(ctx, "key-123", "api_key"); loaded != nil {
+		t.Error("Expected state to be deleted")
+	}
+}
+
+func TestRedisBackend_MergeBudget(t *testing.T) {
+	client := newFakeRedisClient()
+	replicaA, _ := NewRedisBackend(RedisBackendConfig{Client: client})
+	replicaB, _ := NewRedisBackend(RedisBackendConfig{Client: client})
+	ctx := context.Background()
+
+	now := time.Now().Truncate(time.Hour)
+	if _, err := replicaA.MergeBudget(ctx, "key-123", "api_key", &BudgetState{
+		HourlyBuckets: []BudgetBucket{{Timestamp: now, Amount: 2}},
+		TotalSpent:    2,
+	}); err != nil {
+		t.Fatalf("MergeBudget failed: %v", err)
+	}
+	merged, err := replicaB.MergeBudget(ctx, "key-123", "api_key", &BudgetState{
+		HourlyBuckets: []BudgetBucket{
+			{Timestamp: now, Amount: 3},
+			{Timestamp: now.Add(-2 * time.Hour), Amount: 7},
+		},
+		TotalSpent: 10,
+	})
+	if err != nil {
+		t.Fatalf("MergeBudget failed: %v", err)
+	}
+
+	if merged.TotalSpent != 12 {
+		t.Errorf("Expected merged total 12, got %.2f", merged.TotalSpent)
+	}
+	if len(merged.HourlyBuckets) != 1 {
+		t.Fatalf("Expected the bucket outside the hourly window to be pruned, got %+v", merged.HourlyBuckets)
+	}
+	if b := merged.HourlyBuckets[0]; !b.Timestamp.Equal(now) || b.Amount != 5 {
+		t.Errorf("Expected hourly bucket of 5 at %v, got %+v", now, b)
+	}
+
+	// A nil delta reads the merged state
+	read, err := replicaA.MergeBudget(ctx, "key-123", "api_key", nil)
+	if err != nil {
+		t.Fatalf("MergeBudget failed: %v", err)
+	}
+	if read.TotalSpent != 12 {
+		t.Errorf("Expected read total 12, got %.2f", read.TotalSpent)
+	}
+
+	client.fail = true
+	if _, err := replicaA.MergeBudget(ctx, "key-123", "api_key", nil); err == nil {
+		t.Error("Expected error when Redis is unreachable")
+	}
+}
+
+func TestRedisBackend_Usage(t *testing.T) {
+	client := newFakeRedisClient()
+	backend, err := NewRedisBackend(RedisBackendConfig{Client: client})
+	if err != nil {
+		t.Fatalf("NewRedisBackend failed: %v", err)
+	}
+
+	checkUsageStore(t, backend)
+
+	for key, ttl := range client.ttls {
+		if !strings.HasPrefix(key, "mercator:limits:usage:api_key:") || ttl != strconv.Itoa(int(UsageRetention/time.Second)) {
+			t.Errorf("Unexpected usage key %q with TTL %s", key, ttl)
+		}
+	}
+}
@@ -123,6 +123,19 @@ func (s *SQLiteBackend) initSchema() error {
 
 	CREATE INDEX IF NOT EXISTS idx_last_updated ON limit_states(last_updated);
 	CREATE INDEX IF NOT EXISTS idx_dimension ON limit_states(dimension);
+
+	CREATE TABLE IF NOT EXISTS limit_usage (
+		dimension TEXT NOT NULL,
+		identifier TEXT NOT NULL,
+		bucket_start INTEGER NOT NULL,
+		requests INTEGER NOT NULL,
+		prompt_tokens INTEGER NOT NULL,
+		completion_tokens INTEGER NOT NULL,
+		cost REAL NOT NULL,
+		PRIMARY KEY (dimension, identifier, bucket_start)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_usage_bucket_start ON limit_usage(bucket_start);
 	`
 
 	_, err := s.db.Exec(schema)
@@ -402,6 +415,87 @@ func (s *SQLiteBackend) Cleanup(ctx context.Context, olderThan time.Time) (int,
 	return int(deleted), nil
 }
 
+// AddUsage adds usage to the usage history and prunes buckets older than
+// UsageRetention, in one transaction.
+func (s *SQLiteBackend) AddUsage(ctx context.Context, usage []UsageBucket) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, u := range usage {
+		if u.Identifier == "" || u.Dimension == "" {
+			return fmt.Errorf("identifier and dimension cannot be empty")
+		}
+		_, err := tx.ExecContext(ctx, `
+			INSERT INTO limit_usage (dimension, identifier, bucket_start, requests, prompt_tokens, completion_tokens, cost)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+			ON CONFLICT (dimension, identifier, bucket_start) DO UPDATE SET
+				requests = requests + excluded.requests,
+				prompt_tokens = prompt_tokens + excluded.prompt_tokens,
+				completion_tokens = completion_tokens + excluded.completion_tokens,
+				cost = cost + excluded.cost
+		`, u.Dimension, u.Identifier, u.Start.Truncate(time.Hour).Unix(),
+			u.Requests, u.PromptTokens, u.CompletionTokens, u.Cost)
+		if err != nil {
+			return fmt.Errorf("failed to add usage: %w", err)
+		}
+	}
+
+	cutoff := time.Now().Add(-UsageRetention).Unix()
+	if _, err := tx.ExecContext(ctx, `DELETE FROM limit_usage WHERE bucket_start < ?`, cutoff); err != nil {
+		return fmt.Errorf("failed to prune usage: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit usage: %w", err)
+	}
+	return nil
+}
+
+// QueryUsage returns the usage history of an identifier and dimension.
+func (s *SQLiteBackend) QueryUsage(ctx context.Context, identifier string, dimension string, start, end time.Time) ([]UsageBucket, error) {
+	if identifier == "" {
+		return nil, fmt.Errorf("identifier cannot be empty")
+	}
+	if dimension == "" {
+		return nil, fmt.Errorf("dimension cannot be empty")
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	rows, err := s.db.QueryContext(ctx, `
+		SELECT bucket_start, requests, prompt_tokens, completion_tokens, cost
+		FROM limit_usage
+		WHERE dimension = ? AND identifier = ? AND bucket_start >= ? AND bucket_start < ?
+		ORDER BY bucket_start
+	`, dimension, identifier, start.Unix(), end.Unix())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query usage: %w", err)
+	}
+	defer rows.Close()
+
+	var usage []UsageBucket
+	for rows.Next() {
+		b := UsageBucket{Identifier: identifier, Dimension: dimension}
+		var bucketStart int64
+		if err := rows.Scan(&bucketStart, &b.Requests, &b.PromptTokens, &b.CompletionTokens, &b.Cost); err != nil {
+			return nil, fmt.Errorf("failed to scan usage: %w", err)
+		}
+		b.Start = time.Unix(bucketStart, 0)
+		usage = append(usage, b)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rows: %w", err)
+	}
+	return usage, nil
+}
+
 // Close releases any resources held by the backend.
 // Close is idempotent and safe to call multiple times.
 func (s *SQLiteBackend) Close() error {
@@ -715,3 +715,14 @@ func newTestSQLiteBackend(t *testing.T) (*SQLiteBackend, func()) {
 
 	return backend, cleanup
 }
+
+// TestSQLiteBackend_Usage tests the usage history.
+func TestSQLiteBackend_Usage(t *testing.T) {
+	backend, err := NewSQLiteBackend(filepath.Join(t.TempDir(), "limits.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteBackend failed: %v", err)
+	}
+	defer backend.Close()
+
+	checkUsageStore(t, backend)
+}
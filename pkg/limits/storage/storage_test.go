@@ -2,6 +2,8 @@ package storage
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -343,6 +345,120 @@ func TestMemoryBackend_Concurrent(t *testing.T) {
 	}
 }
 
+func TestMemoryBackend_SnapshotFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "limits.json")
+	ctx := context.Background()
+
+	backend := NewMemoryBackendWithConfig(MemoryBackendConfig{SnapshotPath: path})
+	state := &LimitState{
+		Identifier: "key-123",
+		Dimension:  "api_key",
+		RateLimit: &RateLimitState{
+			Buckets: map[string]*TokenBucketState{
+				"rpm": {Algorithm: "token_bucket", Tokens: 2, LastRefill: time.Now()},
+			},
+		},
+		Budget: &BudgetState{TotalSpent: 12.5},
+	}
+	if err := backend.Save(ctx, state); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	// Close writes the snapshot file
+	if err := backend.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	restarted := NewMemoryBackendWithConfig(MemoryBackendConfig{SnapshotPath: path})
+	defer restarted.Close()
+
+	loaded, err := restarted.Load(ctx, "key-123", "api_key")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if loaded == nil {
+		t.Fatal("Expected state to survive restart")
+	}
+	if loaded.Budget == nil || loaded.Budget.TotalSpent != 12.5 {
+		t.Errorf("Expected restored budget 12.5, got %+v", loaded.Budget)
+	}
+	if bucket := loaded.RateLimit.Buckets["rpm"]; bucket == nil || bucket.Tokens != 2 {
+		t.Errorf("Expected restored rpm bucket with 2 tokens, got %+v", bucket)
+	}
+}
+
+func TestMemoryBackend_CorruptSnapshotFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "limits.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	backend := NewMemoryBackendWithConfig(MemoryBackendConfig{SnapshotPath: path})
+	if backend.Size() != 0 {
+		t.Errorf("Expected empty backend after corrupt snapshot, got %d entries", backend.Size())
+	}
+
+	// The corrupt file is overwritten
+	if err := backend.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	restarted := NewMemoryBackendWithConfig(MemoryBackendConfig{SnapshotPath: path})
+	defer restarted.Close()
+	if restarted.Size() != 0 {
+		t.Errorf("Expected empty backend, got %d entries", restarted.Size())
+	}
+}
+
+// checkUsageStore tests that a UsageStore adds usage to the buckets of the
+// same hour and queries them by range.
+func checkUsageStore(t *testing.T, store UsageStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	hour := time.Now().Truncate(time.Hour)
+	usage := []UsageBucket{
+		{Identifier: "key-123", Dimension: "api_key", Start: hour.Add(10 * time.Minute), Requests: 1, PromptTokens: 10, CompletionTokens: 5, Cost: 0.25},
+		{Identifier: "key-123", Dimension: "api_key", Start: hour, Requests: 2, PromptTokens: 20, CompletionTokens: 10, Cost: 0.5},
+		{Identifier: "key-123", Dimension: "api_key", Start: hour.Add(-2 * time.Hour), Requests: 1, PromptTokens: 1, CompletionTokens: 1, Cost: 0.125},
+		{Identifier: "key-456", Dimension: "api_key", Start: hour, Requests: 7},
+	}
+	if err := store.AddUsage(ctx, usage); err != nil {
+		t.Fatalf("AddUsage failed: %v", err)
+	}
+	if err := store.AddUsage(ctx, usage[:1]); err != nil {
+		t.Fatalf("AddUsage failed: %v", err)
+	}
+
+	got, err := store.QueryUsage(ctx, "key-123", "api_key", hour.Add(-3*time.Hour), hour.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("QueryUsage failed: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("Expected 2 buckets, got %+v", got)
+	}
+	if !got[0].Start.Equal(hour.Add(-2*time.Hour)) || got[0].Requests != 1 {
+		t.Errorf("Unexpected oldest bucket: %+v", got[0])
+	}
+	if b := got[1]; !b.Start.Equal(hour) || b.Requests != 4 || b.PromptTokens != 40 || b.CompletionTokens != 20 || b.Cost != 1 {
+		t.Errorf("Expected the current hour's usage to be combined, got %+v", b)
+	}
+
+	got, err = store.QueryUsage(ctx, "key-123", "api_key", hour, hour.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("QueryUsage failed: %v", err)
+	}
+	if len(got) != 1 || got[0].Identifier != "key-123" || got[0].Dimension != "api_key" {
+		t.Errorf("Expected only the current hour, got %+v", got)
+	}
+}
+
+func TestMemoryBackend_Usage(t *testing.T) {
+	backend := NewMemoryBackend()
+	defer backend.Close()
+
+	checkUsageStore(t, backend)
+}
+
 func TestMemoryBackend_Validation(t *testing.T) {
 	backend := NewMemoryBackend()
 	defer backend.Close()
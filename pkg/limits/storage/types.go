@@ -33,6 +33,62 @@ type Backend interface {
 	Close() error
 }
 
+// BudgetMerger is implemented by backends shared between proxy replicas.
+// Replicas merge the budget usage they recorded instead of overwriting each
+// other's state with Save.
+type BudgetMerger interface {
+	// MergeBudget adds the usage recorded since the caller's previous merge
+	// to the stored budget state and returns the merged state.
+	MergeBudget(ctx context.Context, identifier string, dimension string, delta *BudgetState) (*BudgetState, error)
+}
+
+// UsageRetention is how long UsageStore backends keep usage history.
+const UsageRetention = 90 * 24 * time.Hour
+
+// UsageStore is implemented by backends that keep the usage history of
+// identifiers for usage reporting, in hourly buckets. All built-in
+// backends implement it. Buckets older than UsageRetention are pruned.
+type UsageStore interface {
+	// AddUsage adds usage to the stored buckets with the same identifier,
+	// dimension and start. Replicas sharing the backend add their own
+	// usage, so the stored buckets hold the usage of all of them.
+	AddUsage(ctx context.Context, usage []UsageBucket) error
+
+	// QueryUsage returns the buckets of an identifier and dimension that
+	// start within [start, end), oldest first.
+	QueryUsage(ctx context.Context, identifier string, dimension string, start, end time.Time) ([]UsageBucket, error)
+}
+
+// UsageBucket is the usage of an identifier within one hour.
+type UsageBucket struct {
+	// Identifier is the dimension identifier (API key, user ID, team ID).
+	Identifier string
+
+	// Dimension is the limiting dimension (api_key, user, team).
+	Dimension string
+
+	// Start is the start of the hour.
+	Start time.Time
+
+	// Requests is the number of requests.
+	Requests int64
+
+	// PromptTokens and CompletionTokens are the tokens used.
+	PromptTokens     int64
+	CompletionTokens int64
+
+	// Cost is the spending in USD.
+	Cost float64
+}
+
+// add adds the usage of other to b.
+func (b *UsageBucket) add(other UsageBucket) {
+	b.Requests += other.Requests
+	b.PromptTokens += other.PromptTokens
+	b.CompletionTokens += other.CompletionTokens
+	b.Cost += other.Cost
+}
+
 // LimitState represents the persisted state for a single identifier.
 // This includes both rate limit counters and budget usage.
 type LimitState struct {
@@ -69,6 +125,14 @@ type RateLimitState struct {
 
 	// MaxConcurrent is the concurrent request limit.
 	MaxConcurrent int
+
+	// Buckets contains the state of each request-based limit, keyed by
+	// limit ("rps", "rpm", "rph").
+	Buckets map[string]*TokenBucketState
+
+	// Windows contains the state of each token-based limit, keyed by limit
+	// ("tpm", "tph").
+	Windows map[string]*SlidingWindowState
 }
 
 // TokenBucketState contains the state for a token bucket rate limiter.
@@ -85,6 +149,13 @@ type TokenBucketState struct {
 
 	// LastRefill is when tokens were last refilled.
 	LastRefill time.Time
+
+	// Algorithm is the rate limiting algorithm: "token_bucket" or "gcra".
+	// Empty means "token_bucket".
+	Algorithm string
+
+	// TAT is the theoretical arrival time of a GCRA limit.
+	TAT time.Time
 }
 
 // SlidingWindowState contains the state for a sliding window counter.
@@ -4,9 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"time"
+
+	"mercator-hq/jupiter/pkg/limits/ratelimit"
 )
 
-// Dimension represents a limiting dimension (API key, user, team).
+// Dimension represents a limiting dimension (API key, user, team, org).
 type Dimension string
 
 const (
@@ -18,6 +20,43 @@ const (
 
 	// DimensionTeam limits by team ID.
 	DimensionTeam Dimension = "team"
+
+	// DimensionOrg limits by organization ID.
+	DimensionOrg Dimension = "org"
+)
+
+// BudgetScope identifies a budget by its dimension and identifier.
+type BudgetScope struct {
+	// Dimension is the limiting dimension of the budget.
+	Dimension Dimension
+
+	// Identifier is the specific identifier within the dimension.
+	Identifier string
+}
+
+// Key returns the key of the scope's budget tracker. API key budgets are
+// keyed by the key itself; other dimensions are prefixed with their name
+// (e.g., "team:platform") so identifiers cannot collide across dimensions.
+func (s BudgetScope) Key() string {
+	if s.Dimension == DimensionAPIKey || s.Dimension == "" {
+		return s.Identifier
+	}
+	return string(s.Dimension) + ":" + s.Identifier
+}
+
+// BudgetInheritance selects which budgets along an identifier's ancestry
+// (API key → user → team → org) are enforced.
+type BudgetInheritance string
+
+const (
+	// InheritAll enforces every budget along the ancestry: a request must
+	// pass its own budget and the budget of each ancestor.
+	InheritAll BudgetInheritance = "all"
+
+	// InheritNearest enforces only the nearest configured budget, so a
+	// budget set on a key or user overrides the budgets of its ancestors.
+	// Spending is still recorded against every ancestor.
+	InheritNearest BudgetInheritance = "nearest"
 )
 
 // EnforcementAction defines what to do when a limit is exceeded.
@@ -59,11 +98,25 @@ type LimitCheckResult struct {
 	// Action specifies the enforcement action to take.
 	Action EnforcementAction
 
-	// RetryAfter specifies how long to wait before retrying (if blocked).
+	// RetryAfter specifies how long to wait before retrying (if blocked):
+	// the time until the request would pass every limit that applies to it.
 	RetryAfter time.Duration
 
 	// DowngradeTo suggests a cheaper model (if action=downgrade).
 	DowngradeTo string
+
+	// Reservation holds the estimated tokens reserved against token rate
+	// limits for an allowed request. Nil if no tokens were reserved.
+	Reservation *Reservation
+}
+
+// Reservation holds the tokens reserved for a request by CheckLimits
+// until its actual usage is known. Pass it in UsageRecord.Reservation so
+// RecordUsage reconciles it with the actual usage, or to
+// Manager.ReleaseReservation if the request is not served.
+type Reservation struct {
+	// tokens holds the reservation of each rate limiter by limiter key.
+	tokens map[string]*ratelimit.TokenReservation
 }
 
 // RateLimitInfo contains current rate limit status for a dimension.
@@ -75,6 +128,10 @@ type RateLimitInfo struct {
 	// Identifier is the specific identifier within the dimension.
 	Identifier string
 
+	// Model is the model the limit applies to. Empty for limits that
+	// apply to all models.
+	Model string
+
 	// Limit is the maximum allowed requests in the window.
 	Limit int64
 
@@ -91,12 +148,16 @@ type RateLimitInfo struct {
 // BudgetInfo contains current budget status for a dimension.
 // This is used to populate HTTP response headers (X-Budget-*).
 type BudgetInfo struct {
-	// Dimension is the limiting dimension (api_key, user, team).
+	// Dimension is the limiting dimension (api_key, user, team, org).
 	Dimension string
 
 	// Identifier is the specific identifier within the dimension.
 	Identifier string
 
+	// Model is the model the budget applies to. Empty for budgets that
+	// apply to all models.
+	Model string
+
 	// Limit is the maximum budget in USD for the window.
 	Limit float64
 
@@ -114,6 +175,73 @@ type BudgetInfo struct {
 
 	// Window is the time window duration.
 	Window time.Duration
+
+	// InGrace indicates that spending exceeded the limit but is within the
+	// budget's grace overage, so the request was allowed.
+	InGrace bool
+}
+
+// AlertLevel is the severity of a budget alert.
+type AlertLevel string
+
+const (
+	// AlertWarning reports that spending crossed a budget's alert threshold.
+	AlertWarning AlertLevel = "warning"
+
+	// AlertGrace reports that spending exceeded a budget's limit but is
+	// still within its grace overage, so requests are allowed.
+	AlertGrace AlertLevel = "grace"
+
+	// AlertExceeded reports that spending exceeded a budget's limit.
+	AlertExceeded AlertLevel = "exceeded"
+)
+
+// BudgetAlert reports that spending in a budget window crossed the
+// budget's alert threshold or limit.
+type BudgetAlert struct {
+	Level      AlertLevel `json:"level"`
+	Dimension  Dimension  `json:"dimension"`
+	Identifier string     `json:"identifier"`
+
+	// Model is set for budgets scoped to the identifier's use of a model.
+	Model string `json:"model,omitempty"`
+
+	// Window is the budget window: "hourly", "daily" or "monthly".
+	Window string `json:"window"`
+
+	Limit      float64 `json:"limit"`
+	Used       float64 `json:"used"`
+	Remaining  float64 `json:"remaining"`
+	Percentage float64 `json:"percentage"`
+
+	// Threshold is the budget's alert threshold (0.0-1.0).
+	Threshold float64 `json:"threshold"`
+
+	// Reset is when the window resets.
+	Reset time.Time `json:"reset"`
+
+	// Time is when the alert was raised.
+	Time time.Time `json:"time"`
+}
+
+// Summary describes the alert in one line, e.g. "Budget warning: team
+// platform daily budget 85% used ($85.00 of $100.00)".
+func (a *BudgetAlert) Summary() string {
+	scope := fmt.Sprintf("%s %s", a.Dimension, a.Identifier)
+	if a.Model != "" {
+		scope += " (" + a.Model + ")"
+	}
+	return fmt.Sprintf("Budget %s: %s %s budget %.0f%% used ($%.2f of $%.2f)",
+		a.Level, scope, a.Window, a.Percentage*100, a.Used, a.Limit)
+}
+
+// AlertNotifier is notified of budget alerts.
+//
+// The manager notifies it of every request checked while a budget is past
+// its alert threshold or limit, so implementations must not block and are
+// expected to deduplicate repeated alerts.
+type AlertNotifier interface {
+	NotifyBudgetAlert(alert BudgetAlert)
 }
 
 // UsageRecord tracks a single request's usage across all dimensions.
@@ -146,6 +274,15 @@ type UsageRecord struct {
 
 	// Model is the specific model used (gpt-4, claude-3-opus, etc.).
 	Model string
+
+	// CostTag is the cost allocation tag (cost center or project) the
+	// request is charged to, if any. See WithCostTag.
+	CostTag string
+
+	// Reservation is the reservation returned by CheckLimits for the
+	// request, if any. Its reserved tokens are reconciled with
+	// TotalTokens rather than added to them.
+	Reservation *Reservation
 }
 
 // NewUsageRecord creates a UsageRecord from response data.
@@ -189,6 +326,14 @@ var (
 	// ErrQueueFull is returned when the request queue is full.
 	ErrQueueFull = errors.New("request queue full")
 
+	// ErrConcurrencyLimitExceeded is returned when a concurrent request
+	// limit is reached.
+	ErrConcurrencyLimitExceeded = errors.New("too many concurrent requests")
+
+	// ErrStreamLimitExceeded is returned when a concurrent streaming
+	// connection limit is reached.
+	ErrStreamLimitExceeded = errors.New("too many concurrent streams")
+
 	// ErrConfigInvalid is returned when the limits configuration is invalid.
 	ErrConfigInvalid = errors.New("invalid limits configuration")
 )
@@ -34,6 +34,10 @@ const (
 	RedactStrategyMask    RedactStrategy = "mask"    // Replace with ***
 	RedactStrategyRemove  RedactStrategy = "remove"  // Remove entirely
 	RedactStrategyReplace RedactStrategy = "replace" // Replace with specific text
+
+	// RedactStrategyPlaceholder replaces detected PII with typed
+	// placeholders ([EMAIL_1], [SSN_1]) that can be restored in the response.
+	RedactStrategyPlaceholder RedactStrategy = "placeholder"
 )
 
 // Action represents an action node in the AST.
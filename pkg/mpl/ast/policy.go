@@ -14,6 +14,7 @@ type Policy struct {
 	Created     time.Time // Creation timestamp
 	Updated     time.Time // Last update timestamp
 	Tags        []string  // Tags for categorization
+	FailSafe    string    // Fail-safe mode override ("fail-open" or "fail-closed"); empty uses the engine setting
 
 	// Content
 	Variables map[string]*Variable // Variable definitions
@@ -32,6 +32,7 @@ func (b *builder) buildPolicy(yp *yamlPolicy) (*ast.Policy, error) {
 		Description: yp.Description,
 		Author:      yp.Author,
 		Tags:        yp.Tags,
+		FailSafe:    yp.FailSafe,
 		Includes:    yp.Includes,
 		SourceFile:  b.sourcePath,
 		Variables:   make(map[string]*ast.Variable),
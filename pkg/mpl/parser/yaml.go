@@ -17,6 +17,7 @@ type yamlPolicy struct {
 	Created     string                 `yaml:"created"`
 	Updated   string                 `yaml:"updated"`
 	Tags      []string               `yaml:"tags"`
+	FailSafe  string                 `yaml:"fail_safe"`
 	Variables map[string]interface{} `yaml:"variables"`
 	Rules     []yamlRule             `yaml:"rules"`
 	Includes  []string               `yaml:"includes"`
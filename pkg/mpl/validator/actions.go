@@ -190,21 +190,34 @@ func (v *ActionValidator) validateRedactAction(action *ast.Action, ruleName stri
 		if strategy.Type == ast.ValueTypeString {
 			strategyStr := strategy.Value.(string)
 			validStrategies := map[string]bool{
-				"mask":    true,
-				"remove":  true,
-				"replace": true,
+				"mask":        true,
+				"remove":      true,
+				"replace":     true,
+				"placeholder": true,
 			}
 			if !validStrategies[strategyStr] {
 				v.errors.AddErrorWithSuggestion(
 					mplErrors.ErrorTypeValidation,
 					fmt.Sprintf("Rule %q 'redact' action has invalid strategy %q", ruleName, strategyStr),
 					action.Location,
-					"Valid strategies: mask, remove, replace",
+					"Valid strategies: mask, remove, replace, placeholder",
 				)
 			}
 		}
 	}
 
+	// Optional: restore (boolean)
+	if action.HasParameter("restore") {
+		restore := action.GetParameter("restore")
+		if restore.Type != ast.ValueTypeBoolean && restore.Type != ast.ValueTypeVariable {
+			v.errors.AddError(
+				mplErrors.ErrorTypeValidation,
+				fmt.Sprintf("Rule %q 'redact' action 'restore' must be a boolean", ruleName),
+				action.Location,
+			)
+		}
+	}
+
 	// Optional: replacement (required if strategy is 'replace')
 	if action.HasParameter("strategy") {
 		strategy := action.GetParameter("strategy")
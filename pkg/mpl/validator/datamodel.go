@@ -1,7 +1,9 @@
 package validator
 
 import (
+	"fmt"
 	"strings"
+	"sync"
 
 	"mercator-hq/jupiter/pkg/mpl/ast"
 )
@@ -12,6 +14,7 @@ type FieldInfo struct {
 	Type        ast.ValueType         // Field type
 	Description string                // Human-readable description
 	Children    map[string]*FieldInfo // Child fields for objects
+	Values      *FieldInfo            // Values of objects keyed by name, such as category scores
 }
 
 // DataModel defines all valid fields available in MPL conditions.
@@ -78,6 +81,12 @@ var requestFields = &FieldInfo{
 			Type:        ast.ValueTypeNumber,
 			Description: "Number of completions to generate",
 		},
+		"analyzers": {
+			Name:        "request.analyzers",
+			Type:        ast.ValueTypeObject,
+			Description: "Fields of custom request analyzers, by analyzer",
+			Children:    map[string]*FieldInfo{},
+		},
 	},
 }
 
@@ -119,6 +128,13 @@ var responseFields = &FieldInfo{
 				},
 			},
 		},
+		"content_analysis": responseContentAnalysisFields(),
+		"analyzers": {
+			Name:        "response.analyzers",
+			Type:        ast.ValueTypeObject,
+			Description: "Fields of custom response analyzers, by analyzer",
+			Children:    map[string]*FieldInfo{},
+		},
 	},
 }
 
@@ -182,91 +198,7 @@ var processingFields = &FieldInfo{
 				},
 			},
 		},
-		"content_analysis": {
-			Name:        "processing.content_analysis",
-			Type:        ast.ValueTypeObject,
-			Description: "Content analysis results",
-			Children: map[string]*FieldInfo{
-				"pii_detection": {
-					Name:        "processing.content_analysis.pii_detection",
-					Type:        ast.ValueTypeObject,
-					Description: "PII detection results",
-					Children: map[string]*FieldInfo{
-						"detected": {
-							Name:        "processing.content_analysis.pii_detection.detected",
-							Type:        ast.ValueTypeBoolean,
-							Description: "Whether PII was detected",
-						},
-						"types": {
-							Name:        "processing.content_analysis.pii_detection.types",
-							Type:        ast.ValueTypeArray,
-							Description: "Types of PII detected",
-						},
-					},
-				},
-				"sentiment": {
-					Name:        "processing.content_analysis.sentiment",
-					Type:        ast.ValueTypeObject,
-					Description: "Sentiment analysis",
-					Children: map[string]*FieldInfo{
-						"score": {
-							Name:        "processing.content_analysis.sentiment.score",
-							Type:        ast.ValueTypeNumber,
-							Description: "Sentiment score (-1 to 1)",
-						},
-						"label": {
-							Name:        "processing.content_analysis.sentiment.label",
-							Type:        ast.ValueTypeString,
-							Description: "Sentiment label (positive, negative, neutral)",
-						},
-					},
-				},
-				"sensitive_content": {
-					Name:        "processing.content_analysis.sensitive_content",
-					Type:        ast.ValueTypeObject,
-					Description: "Sensitive content detection",
-					Children: map[string]*FieldInfo{
-						"detected": {
-							Name:        "processing.content_analysis.sensitive_content.detected",
-							Type:        ast.ValueTypeBoolean,
-							Description: "Whether sensitive content was detected",
-						},
-						"severity": {
-							Name:        "processing.content_analysis.sensitive_content.severity",
-							Type:        ast.ValueTypeString,
-							Description: "Severity level (low, medium, high, critical)",
-						},
-						"categories": {
-							Name:        "processing.content_analysis.sensitive_content.categories",
-							Type:        ast.ValueTypeArray,
-							Description: "Categories of sensitive content detected",
-						},
-					},
-				},
-				"prompt_injection": {
-					Name:        "processing.content_analysis.prompt_injection",
-					Type:        ast.ValueTypeObject,
-					Description: "Prompt injection detection",
-					Children: map[string]*FieldInfo{
-						"detected": {
-							Name:        "processing.content_analysis.prompt_injection.detected",
-							Type:        ast.ValueTypeBoolean,
-							Description: "Whether prompt injection was detected",
-						},
-						"confidence": {
-							Name:        "processing.content_analysis.prompt_injection.confidence",
-							Type:        ast.ValueTypeNumber,
-							Description: "Confidence score (0.0-1.0)",
-						},
-						"patterns": {
-							Name:        "processing.content_analysis.prompt_injection.patterns",
-							Type:        ast.ValueTypeArray,
-							Description: "Injection patterns detected",
-						},
-					},
-				},
-			},
-		},
+		"content_analysis": contentAnalysisFields("processing.content_analysis", "Content analysis results"),
 		"conversation_context": {
 			Name:        "processing.conversation_context",
 			Type:        ast.ValueTypeObject,
@@ -292,6 +224,232 @@ var processingFields = &FieldInfo{
 	},
 }
 
+// contentAnalysisFields defines the content analysis fields under prefix,
+// such as processing.content_analysis for the request.
+func contentAnalysisFields(prefix, description string) *FieldInfo {
+	return &FieldInfo{
+		Name:        prefix,
+		Type:        ast.ValueTypeObject,
+		Description: description,
+		Children: map[string]*FieldInfo{
+			"pii_detection": {
+				Name:        prefix + ".pii_detection",
+				Type:        ast.ValueTypeObject,
+				Description: "PII detection results",
+				Children: map[string]*FieldInfo{
+					"detected": {
+						Name:        prefix + ".pii_detection.detected",
+						Type:        ast.ValueTypeBoolean,
+						Description: "Whether PII was detected",
+					},
+					"types": {
+						Name:        prefix + ".pii_detection.types",
+						Type:        ast.ValueTypeArray,
+						Description: "Types of PII detected",
+					},
+					"severity": {
+						Name:        prefix + ".pii_detection.severity",
+						Type:        ast.ValueTypeString,
+						Description: "Highest severity of PII detected (low, medium, high, critical)",
+					},
+				},
+			},
+			"sentiment": {
+				Name:        prefix + ".sentiment",
+				Type:        ast.ValueTypeObject,
+				Description: "Sentiment analysis",
+				Children: map[string]*FieldInfo{
+					"score": {
+						Name:        prefix + ".sentiment.score",
+						Type:        ast.ValueTypeNumber,
+						Description: "Sentiment score (-1 to 1)",
+					},
+					"label": {
+						Name:        prefix + ".sentiment.label",
+						Type:        ast.ValueTypeString,
+						Description: "Sentiment label (positive, negative, neutral)",
+					},
+				},
+			},
+			"sensitive_content": {
+				Name:        prefix + ".sensitive_content",
+				Type:        ast.ValueTypeObject,
+				Description: "Sensitive content detection",
+				Children: map[string]*FieldInfo{
+					"detected": {
+						Name:        prefix + ".sensitive_content.detected",
+						Type:        ast.ValueTypeBoolean,
+						Description: "Whether sensitive content was detected",
+					},
+					"severity": {
+						Name:        prefix + ".sensitive_content.severity",
+						Type:        ast.ValueTypeString,
+						Description: "Severity level (low, medium, high, critical)",
+					},
+					"categories": {
+						Name:        prefix + ".sensitive_content.categories",
+						Type:        ast.ValueTypeArray,
+						Description: "Categories of sensitive content detected",
+					},
+					"scores": {
+						Name:        prefix + ".sensitive_content.scores",
+						Type:        ast.ValueTypeObject,
+						Description: "Score of each category, e.g. scores.violence",
+						Values: &FieldInfo{
+							Name:        prefix + ".sensitive_content.scores.<category>",
+							Type:        ast.ValueTypeNumber,
+							Description: "Category score (0.0-1.0)",
+						},
+					},
+				},
+			},
+			"secrets": {
+				Name:        prefix + ".secrets",
+				Type:        ast.ValueTypeObject,
+				Description: "Detection of credentials embedded in prompts",
+				Children: map[string]*FieldInfo{
+					"detected": {
+						Name:        prefix + ".secrets.detected",
+						Type:        ast.ValueTypeBoolean,
+						Description: "Whether secrets were detected",
+					},
+					"types": {
+						Name:        prefix + ".secrets.types",
+						Type:        ast.ValueTypeArray,
+						Description: "Types of secrets detected (aws_access_key, github_token, private_key, etc.)",
+					},
+					"count": {
+						Name:        prefix + ".secrets.count",
+						Type:        ast.ValueTypeNumber,
+						Description: "Number of secrets detected",
+					},
+				},
+			},
+			"prompt_injection": {
+				Name:        prefix + ".prompt_injection",
+				Type:        ast.ValueTypeObject,
+				Description: "Prompt injection detection",
+				Children: map[string]*FieldInfo{
+					"detected": {
+						Name:        prefix + ".prompt_injection.detected",
+						Type:        ast.ValueTypeBoolean,
+						Description: "Whether prompt injection was detected",
+					},
+					"confidence": {
+						Name:        prefix + ".prompt_injection.confidence",
+						Type:        ast.ValueTypeNumber,
+						Description: "Confidence score (0.0-1.0)",
+					},
+					"patterns": {
+						Name:        prefix + ".prompt_injection.patterns",
+						Type:        ast.ValueTypeArray,
+						Description: "Injection patterns detected",
+					},
+					"classifier_score": {
+						Name:        prefix + ".prompt_injection.classifier_score",
+						Type:        ast.ValueTypeNumber,
+						Description: "Injection classifier score (0.0-1.0), if evaluated inline",
+					},
+				},
+			},
+			"language": {
+				Name:        prefix + ".language",
+				Type:        ast.ValueTypeString,
+				Description: "Detected ISO 639-1 language code (e.g., en, es, ja)",
+			},
+			"language_confidence": {
+				Name:        prefix + ".language_confidence",
+				Type:        ast.ValueTypeNumber,
+				Description: "Language detection confidence (0.0-1.0)",
+			},
+			"entities": {
+				Name:        prefix + ".entities",
+				Type:        ast.ValueTypeObject,
+				Description: "Named entities mentioned in the content",
+				Children: map[string]*FieldInfo{
+					"organizations": {
+						Name:        prefix + ".entities.organizations",
+						Type:        ast.ValueTypeArray,
+						Description: "Organizations mentioned",
+					},
+					"people": {
+						Name:        prefix + ".entities.people",
+						Type:        ast.ValueTypeArray,
+						Description: "People mentioned",
+					},
+					"locations": {
+						Name:        prefix + ".entities.locations",
+						Type:        ast.ValueTypeArray,
+						Description: "Locations mentioned",
+					},
+					"count": {
+						Name:        prefix + ".entities.count",
+						Type:        ast.ValueTypeNumber,
+						Description: "Number of entity mentions",
+					},
+				},
+			},
+			"classification": {
+				Name:        prefix + ".classification",
+				Type:        ast.ValueTypeObject,
+				Description: "Data classification of the content",
+				Children: map[string]*FieldInfo{
+					"label": {
+						Name:        prefix + ".classification.label",
+						Type:        ast.ValueTypeString,
+						Description: "Classification label (e.g., public, internal, confidential, restricted)",
+					},
+					"level": {
+						Name:        prefix + ".classification.level",
+						Type:        ast.ValueTypeNumber,
+						Description: "Position of the label among the configured labels, from 0 for the least sensitive",
+					},
+					"reasons": {
+						Name:        prefix + ".classification.reasons",
+						Type:        ast.ValueTypeArray,
+						Description: "Findings that set the label (e.g., pii:email, secret:github_token)",
+					},
+				},
+			},
+			"topic": {
+				Name:        prefix + ".topic",
+				Type:        ast.ValueTypeObject,
+				Description: "Configured topic nearest to the content by embedding similarity",
+				Children: map[string]*FieldInfo{
+					"name": {
+						Name:        prefix + ".topic.name",
+						Type:        ast.ValueTypeString,
+						Description: "Name of the nearest topic",
+					},
+					"kind": {
+						Name:        prefix + ".topic.kind",
+						Type:        ast.ValueTypeString,
+						Description: "Kind of the nearest topic (banned, allowed)",
+					},
+					"score": {
+						Name:        prefix + ".topic.score",
+						Type:        ast.ValueTypeNumber,
+						Description: "Cosine similarity to the nearest topic",
+					},
+					"matched": {
+						Name:        prefix + ".topic.matched",
+						Type:        ast.ValueTypeBoolean,
+						Description: "Whether the similarity reaches the configured threshold",
+					},
+				},
+			},
+		},
+	}
+}
+
+// responseContentAnalysisFields defines the content analysis fields of the
+// response, which is not analyzed for prompt injection.
+func responseContentAnalysisFields() *FieldInfo {
+	fields := contentAnalysisFields("response.content_analysis", "Content analysis of the response")
+	delete(fields.Children, "prompt_injection")
+	return fields
+}
+
 // contextFields defines the context.* namespace
 var contextFields = &FieldInfo{
 	Name:        "context",
@@ -370,21 +528,55 @@ var contextFields = &FieldInfo{
 	},
 }
 
+// dataModelMu protects the analyzer fields of the data model, which are
+// registered at runtime.
+var dataModelMu sync.RWMutex
+
+// RegisterAnalyzerFields adds the fields of a custom analyzer to the data
+// model, as request.analyzers.<analyzer>.<field> and
+// response.analyzers.<analyzer>.<field>. Registering an analyzer again
+// replaces its fields.
+func RegisterAnalyzerFields(analyzer string, fields map[string]ast.ValueType) {
+	dataModelMu.Lock()
+	defer dataModelMu.Unlock()
+
+	for _, namespace := range []string{"request", "response"} {
+		prefix := namespace + ".analyzers." + analyzer
+		info := &FieldInfo{
+			Name:        prefix,
+			Type:        ast.ValueTypeObject,
+			Description: fmt.Sprintf("Fields of the %s analyzer", analyzer),
+			Children:    make(map[string]*FieldInfo, len(fields)),
+		}
+		for name, typ := range fields {
+			info.Children[name] = &FieldInfo{
+				Name:        prefix + "." + name,
+				Type:        typ,
+				Description: fmt.Sprintf("Field %s of the %s analyzer", name, analyzer),
+			}
+		}
+		DataModel.Children[namespace].Children["analyzers"].Children[analyzer] = info
+	}
+}
+
 // LookupField finds a field in the data model by its path.
 // Returns the field info and true if found, nil and false otherwise.
 func LookupField(path string) (*FieldInfo, bool) {
+	dataModelMu.RLock()
+	defer dataModelMu.RUnlock()
+
 	parts := strings.Split(path, ".")
 	current := DataModel
 
 	for _, part := range parts {
-		if current.Children == nil {
-			return nil, false
+		if next, ok := current.Children[part]; ok {
+			current = next
+			continue
 		}
-		next, ok := current.Children[part]
-		if !ok {
+		if current.Values == nil {
 			return nil, false
 		}
-		current = next
+		current = current.Values
 	}
 
 	return current, true
@@ -393,6 +585,9 @@ func LookupField(path string) (*FieldInfo, bool) {
 // GetAllFieldPaths returns all valid field paths in the data model.
 // This is used for error suggestions.
 func GetAllFieldPaths() []string {
+	dataModelMu.RLock()
+	defer dataModelMu.RUnlock()
+
 	var paths []string
 	collectPaths(DataModel, "", &paths)
 	return paths
@@ -79,6 +79,10 @@
 //	processing.*      - Processing metadata (risk_score, token_estimate, content_analysis)
 //	context.*         - Request context (environment, time, user_attributes)
 //
+// Custom analyzers of the processing pipeline contribute fields under
+// request.analyzers.<analyzer>.* and response.analyzers.<analyzer>.*, which
+// are added with RegisterAnalyzerFields.
+//
 // Lookup a field:
 //
 //	field, ok := validator.LookupField("request.model")
@@ -104,6 +104,16 @@ func (v *StructuralValidator) validateMetadata(policy *ast.Policy) {
 		)
 	}
 
+	// Fail-safe override must be a known mode
+	if policy.FailSafe != "" && policy.FailSafe != "fail-open" && policy.FailSafe != "fail-closed" {
+		v.errors.AddErrorWithSuggestion(
+			mplErrors.ErrorTypeStructural,
+			fmt.Sprintf("Invalid fail_safe mode %q", policy.FailSafe),
+			policy.Location,
+			"Valid modes: 'fail-open', 'fail-closed'",
+		)
+	}
+
 	// Rules are required
 	if len(policy.Rules) == 0 {
 		v.errors.AddErrorWithSuggestion(
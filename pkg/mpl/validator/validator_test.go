@@ -348,6 +348,12 @@ func TestLookupField(t *testing.T) {
 		{"request.max_tokens", true, ast.ValueTypeNumber},
 		{"processing.risk_score", true, ast.ValueTypeNumber},
 		{"context.environment", true, ast.ValueTypeString},
+		{"processing.content_analysis.sensitive_content.scores.self_harm", true, ast.ValueTypeNumber},
+		{"response.content_analysis.secrets.detected", true, ast.ValueTypeBoolean},
+		{"processing.content_analysis.topic.kind", true, ast.ValueTypeString},
+		{"processing.content_analysis.entities.organizations", true, ast.ValueTypeArray},
+		{"response.content_analysis.classification.level", true, ast.ValueTypeNumber},
+		{"response.content_analysis.prompt_injection.detected", false, ""},
 		{"invalid.field", false, ""},
 		{"request.nonexistent", false, ""},
 	}
@@ -368,6 +374,34 @@ func TestLookupField(t *testing.T) {
 	}
 }
 
+func TestRegisterAnalyzerFields(t *testing.T) {
+	RegisterAnalyzerFields("sentiment", map[string]ast.ValueType{
+		"label": ast.ValueTypeString,
+		"score": ast.ValueTypeNumber,
+	})
+
+	tests := []struct {
+		path      string
+		wantFound bool
+		wantType  ast.ValueType
+	}{
+		{"request.analyzers.sentiment.label", true, ast.ValueTypeString},
+		{"response.analyzers.sentiment.score", true, ast.ValueTypeNumber},
+		{"request.analyzers.sentiment.other", false, ""},
+		{"request.analyzers.unregistered.score", false, ""},
+	}
+	for _, tt := range tests {
+		field, found := LookupField(tt.path)
+		if found != tt.wantFound {
+			t.Errorf("LookupField(%q) found = %v, want %v", tt.path, found, tt.wantFound)
+			continue
+		}
+		if found && field.Type != tt.wantType {
+			t.Errorf("LookupField(%q) type = %v, want %v", tt.path, field.Type, tt.wantType)
+		}
+	}
+}
+
 func TestGetAllFieldPaths(t *testing.T) {
 	paths := GetAllFieldPaths()
 
@@ -0,0 +1,18 @@
+// Package natsclient is a minimal publish-only client for the NATS client
+// protocol.
+//
+// It is shared by the NATS policy event sink and the telemetry bus, which
+// publish to NATS without a full NATS client library. A Client publishes
+// messages to a single subject over a lazily established connection,
+// upgrading to TLS when required, authenticating with the credentials of
+// the server URL, and answering server keep-alive PINGs.
+//
+// # Usage
+//
+//	client, err := natsclient.NewClient(&natsclient.Config{
+//	    URL:     "nats://token@localhost:4222",
+//	    Subject: "mercator.logs",
+//	})
+//
+//	err = client.Publish(ctx, first, second)
+package natsclient
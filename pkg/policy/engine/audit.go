@@ -0,0 +1,58 @@
+package engine
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"mercator-hq/jupiter/pkg/security/auth"
+	"mercator-hq/jupiter/pkg/telemetry/audit"
+)
+
+// AuditObserver records policy blocks in the security audit event stream.
+type AuditObserver struct {
+	logger *audit.Logger
+}
+
+var _ DecisionObserver = (*AuditObserver)(nil)
+
+// NewAuditObserver creates an observer that writes a policy_block event
+// to logger for every blocking decision.
+func NewAuditObserver(logger *audit.Logger) *AuditObserver {
+	return &AuditObserver{logger: logger}
+}
+
+// ObserveDecision implements DecisionObserver.
+func (o *AuditObserver) ObserveDecision(ctx context.Context, stage DecisionStage, evalCtx *EvaluationContext, decision *PolicyDecision) {
+	if decision.Action != ActionBlock {
+		return
+	}
+
+	var rules []string
+	for _, rule := range decision.MatchedRules {
+		if rule.ConditionResult {
+			rules = append(rules, rule.PolicyID+"/"+rule.RuleID)
+		}
+	}
+
+	event := audit.Event{
+		Type:      audit.EventPolicyBlock,
+		RequestID: evalCtx.RequestID,
+		Reason:    decision.BlockReason,
+		Details: map[string]string{
+			"stage":       string(stage),
+			"decision_id": decision.ID,
+			"status_code": strconv.Itoa(decision.BlockStatusCode),
+		},
+	}
+	if len(rules) > 0 {
+		event.Details["rules"] = strings.Join(rules, ",")
+	}
+	if info, ok := auth.GetAPIKeyInfo(ctx); ok {
+		event.Principal = info.UserID
+		if event.Principal == "" {
+			event.Principal = info.TeamID
+		}
+	}
+	o.logger.Log(ctx, event)
+}
@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"mercator-hq/jupiter/pkg/mpl/ast"
+	"mercator-hq/jupiter/pkg/processing"
+	"mercator-hq/jupiter/pkg/proxy/types"
+	"mercator-hq/jupiter/pkg/security/auth"
+	"mercator-hq/jupiter/pkg/telemetry/audit"
+)
+
+// TestAuditObserver tests that blocking decisions are recorded as audit
+// events.
+func TestAuditObserver(t *testing.T) {
+	policy := &ast.Policy{
+		Name: "models",
+		Rules: []*ast.Rule{
+			createIndexTestRule("deny-gpt4",
+				createStringCondition("request.model", ast.OperatorEqual, "gpt-4"),
+				&ast.Action{Type: ast.ActionTypeDeny, Parameters: map[string]*ast.ValueNode{
+					"message": {Type: ast.ValueTypeString, Value: "model not allowed"},
+				}}),
+		},
+	}
+
+	eng, err := NewInterpreterEngine(DefaultEngineConfig(), &staticSource{policies: []*ast.Policy{policy}}, slog.Default())
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	defer eng.Close()
+
+	var buf bytes.Buffer
+	eng.AddDecisionObserver(NewAuditObserver(audit.NewWithWriters(&buf)))
+
+	ctx := auth.WithAPIKeyInfo(context.Background(), &auth.APIKeyInfo{UserID: "alice"})
+	for _, model := range []string{"gpt-4", "gpt-4o-mini"} {
+		if _, err := eng.EvaluateRequest(ctx, &processing.EnrichedRequest{
+			RequestID:       "audit-" + model,
+			OriginalRequest: &types.ChatCompletionRequest{Model: model},
+		}); err != nil {
+			t.Fatalf("evaluation failed: %v", err)
+		}
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected 1 audit event, got %q", buf.String())
+	}
+	var event audit.Event
+	if err := json.Unmarshal([]byte(lines[0]), &event); err != nil {
+		t.Fatalf("invalid audit event: %v", err)
+	}
+	if event.Type != audit.EventPolicyBlock || event.RequestID != "audit-gpt-4" || event.Principal != "alice" {
+		t.Errorf("unexpected event %+v", event)
+	}
+	if event.Reason != "model not allowed" || event.Details["stage"] != "request" || event.Details["decision_id"] == "" {
+		t.Errorf("unexpected event %+v", event)
+	}
+}
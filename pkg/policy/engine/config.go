@@ -57,6 +57,27 @@ type EngineConfig struct {
 	// Default: 50.
 	MaxRulesPerPolicy int
 
+	// EnableRuleIndex enables indexing of rule conditions so that only rules
+	// that can plausibly match a request are evaluated. Rules skipped by the
+	// index cannot match, so decisions are unchanged, but they are omitted
+	// from PolicyDecision.MatchedRules (which otherwise lists non-matching
+	// rules too), evaluation traces, and per-rule metrics.
+	// Default: false.
+	EnableRuleIndex bool
+
+	// ParallelWorkers is the maximum number of goroutines used to match rule
+	// conditions concurrently. Actions are still executed sequentially in
+	// priority order, so decisions are identical to sequential evaluation.
+	// Values of 0 or 1 disable parallel evaluation.
+	// Default: 0.
+	ParallelWorkers int
+
+	// ExplanationHistory is the number of recent decision explanations kept in
+	// memory for lookup by request ID. Condition outcomes are recorded as rules
+	// are matched, so this adds overhead per request.
+	// Default: 0 (disabled).
+	ExplanationHistory int
+
 	// BusinessHours defines business hours for time-based conditions.
 	// Default: Mon-Fri, 9am-5pm UTC.
 	BusinessHours *BusinessHoursConfig
@@ -114,6 +135,12 @@ func (c *EngineConfig) Validate() error {
 	if c.MaxRulesPerPolicy <= 0 {
 		return fmt.Errorf("%w: max rules per policy must be positive", ErrInvalidConfig)
 	}
+	if c.ParallelWorkers < 0 {
+		return fmt.Errorf("%w: parallel workers cannot be negative", ErrInvalidConfig)
+	}
+	if c.ExplanationHistory < 0 {
+		return fmt.Errorf("%w: explanation history cannot be negative", ErrInvalidConfig)
+	}
 
 	return nil
 }
@@ -159,3 +186,21 @@ func (c *EngineConfig) WithMaxRulesPerPolicy(max int) *EngineConfig {
 	c.MaxRulesPerPolicy = max
 	return c
 }
+
+// WithRuleIndex enables or disables rule indexing.
+func (c *EngineConfig) WithRuleIndex(enabled bool) *EngineConfig {
+	c.EnableRuleIndex = enabled
+	return c
+}
+
+// WithParallelWorkers sets the number of workers used for parallel condition matching.
+func (c *EngineConfig) WithParallelWorkers(workers int) *EngineConfig {
+	c.ParallelWorkers = workers
+	return c
+}
+
+// WithExplanationHistory sets the number of decision explanations retained.
+func (c *EngineConfig) WithExplanationHistory(size int) *EngineConfig {
+	c.ExplanationHistory = size
+	return c
+}
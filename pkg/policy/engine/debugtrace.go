@@ -0,0 +1,257 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	"mercator-hq/jupiter/pkg/mpl/ast"
+	"mercator-hq/jupiter/pkg/security/auth"
+)
+
+// tracerName is the instrumentation name used for engine debug spans.
+const tracerName = "mercator-jupiter/policy-engine"
+
+// DebugTraceRegistry tracks the API keys for which engine debug tracing is
+// enabled. It can be updated at runtime without reloading the engine.
+type DebugTraceRegistry struct {
+	mu   sync.RWMutex
+	keys map[string]struct{}
+}
+
+// NewDebugTraceRegistry creates an empty debug trace registry.
+func NewDebugTraceRegistry() *DebugTraceRegistry {
+	return &DebugTraceRegistry{
+		keys: make(map[string]struct{}),
+	}
+}
+
+// Enable turns on debug tracing for an API key.
+func (r *DebugTraceRegistry) Enable(apiKey string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.keys[apiKey] = struct{}{}
+}
+
+// Disable turns off debug tracing for an API key.
+func (r *DebugTraceRegistry) Disable(apiKey string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.keys, apiKey)
+}
+
+// Enabled returns true if debug tracing is enabled for an API key.
+func (r *DebugTraceRegistry) Enabled(apiKey string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.keys) == 0 {
+		return false
+	}
+	_, ok := r.keys[apiKey]
+	return ok
+}
+
+// Keys returns the API keys with debug tracing enabled, masked for display.
+func (r *DebugTraceRegistry) Keys() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	keys := make([]string, 0, len(r.keys))
+	for key := range r.keys {
+		keys = append(keys, maskAPIKey(key))
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// maskAPIKey shows only the first four characters of an API key.
+func maskAPIKey(key string) string {
+	if len(key) <= 4 {
+		return "****"
+	}
+	return key[:4] + "****"
+}
+
+// debugTraceContextKey is the context key that forces debug tracing.
+type debugTraceContextKey struct{}
+
+// ContextWithDebugTrace returns a context that enables debug tracing for a
+// single evaluation regardless of the API key.
+func ContextWithDebugTrace(ctx context.Context) context.Context {
+	return context.WithValue(ctx, debugTraceContextKey{}, true)
+}
+
+// EnableDebugTrace enables debug tracing for requests authenticated with apiKey.
+func (e *InterpreterEngine) EnableDebugTrace(apiKey string) {
+	e.debugTrace.Enable(apiKey)
+}
+
+// DisableDebugTrace disables debug tracing for requests authenticated with apiKey.
+func (e *InterpreterEngine) DisableDebugTrace(apiKey string) {
+	e.debugTrace.Disable(apiKey)
+}
+
+// SetTracer sets the OpenTelemetry tracer used for debug spans.
+// By default the global tracer provider is used.
+func (e *InterpreterEngine) SetTracer(tracer trace.Tracer) {
+	e.hooksMu.Lock()
+	defer e.hooksMu.Unlock()
+	e.tracer = tracer
+}
+
+// debugTracer returns the tracer for debug spans.
+func (e *InterpreterEngine) debugTracer() trace.Tracer {
+	e.hooksMu.RLock()
+	defer e.hooksMu.RUnlock()
+	if e.tracer != nil {
+		return e.tracer
+	}
+	return otel.Tracer(tracerName)
+}
+
+// debugTraceActive returns true if debug tracing is enabled for this evaluation,
+// either explicitly via the context or by the authenticated API key.
+func (e *InterpreterEngine) debugTraceActive(ctx context.Context) bool {
+	if forced, _ := ctx.Value(debugTraceContextKey{}).(bool); forced {
+		return true
+	}
+	if info, ok := auth.GetAPIKeyInfo(ctx); ok && info != nil {
+		return e.debugTrace.Enabled(info.Key)
+	}
+	return false
+}
+
+// startRuleSpan starts a debug span for a rule evaluation.
+func (e *InterpreterEngine) startRuleSpan(ctx context.Context, policy *ast.Policy, rule *ast.Rule, evalCtx *EvaluationContext) trace.Span {
+	_, span := e.debugTracer().Start(ctx, "policy.rule",
+		trace.WithAttributes(
+			attribute.String("mercator.request_id", evalCtx.RequestID),
+			attribute.String("mercator.policy.id", policy.Name),
+			attribute.String("mercator.policy.rule", rule.Name),
+		),
+	)
+	return span
+}
+
+// endRuleSpan records condition-level events and the rule outcome, then ends the span.
+// evaluated is the MatchedRule entry produced for the rule (nil if none was recorded).
+func (e *InterpreterEngine) endRuleSpan(span trace.Span, evaluated *MatchedRule, err error) {
+	defer span.End()
+
+	if evaluated != nil {
+		if evaluated.condition != nil {
+			addConditionEvents(span, evaluated.condition, "")
+		}
+		span.SetAttributes(attribute.Bool("mercator.policy.matched", evaluated.ConditionResult))
+		for _, action := range evaluated.ActionsExecuted {
+			attrs := []attribute.KeyValue{
+				attribute.String("action.type", string(action.ActionType)),
+				attribute.Bool("action.success", action.Success),
+			}
+			if action.Error != nil {
+				attrs = append(attrs, attribute.String("action.error", action.Error.Error()))
+			}
+			span.AddEvent("action", trace.WithAttributes(attrs...))
+		}
+	}
+
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// addConditionEvents adds one span event per condition node, depth first.
+// path identifies the node's position in the condition tree (e.g. "0.1").
+//
+// Spans are exported to the tracing backend, so events record only field
+// paths, operators, and outcomes. Expected and actual values may contain
+// request content and are left to DecisionExplanation, which is served from
+// admin endpoints only.
+func addConditionEvents(span trace.Span, exp *ConditionExplanation, path string) {
+	attrs := []attribute.KeyValue{
+		attribute.String("condition.path", path),
+		attribute.String("condition.type", string(exp.Type)),
+		attribute.Bool("condition.matched", exp.Matched),
+	}
+	if exp.Field != "" {
+		attrs = append(attrs,
+			attribute.String("condition.field", exp.Field),
+			attribute.String("condition.operator", string(exp.Operator)),
+		)
+	}
+	if exp.Function != "" {
+		attrs = append(attrs, attribute.String("condition.function", exp.Function))
+	}
+	if exp.Error != "" {
+		attrs = append(attrs, attribute.String("condition.error", exp.Error))
+	}
+	span.AddEvent("condition", trace.WithAttributes(attrs...))
+
+	for i, child := range exp.Children {
+		childPath := fmt.Sprint(i)
+		if path != "" {
+			childPath = path + "." + childPath
+		}
+		addConditionEvents(span, child, childPath)
+	}
+}
+
+// debugTraceRequest is the request body for the debug trace admin endpoint.
+type debugTraceRequest struct {
+	APIKey  string `json:"api_key"`
+	Enabled bool   `json:"enabled"`
+}
+
+// DebugTraceHandler returns an HTTP handler for toggling debug tracing per API key.
+//
+//	GET  lists API keys (masked) with debug tracing enabled
+//	POST {"api_key": "...", "enabled": true|false} toggles tracing for a key
+func (e *InterpreterEngine) DebugTraceHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			e.writeDebugTraceKeys(w)
+
+		case http.MethodPost:
+			var req debugTraceRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+				return
+			}
+			if req.APIKey == "" {
+				http.Error(w, "api_key is required", http.StatusBadRequest)
+				return
+			}
+			if req.Enabled {
+				e.EnableDebugTrace(req.APIKey)
+			} else {
+				e.DisableDebugTrace(req.APIKey)
+			}
+			e.logger.Info("policy engine debug trace toggled",
+				"api_key", maskAPIKey(req.APIKey),
+				"enabled", req.Enabled,
+			)
+			e.writeDebugTraceKeys(w)
+
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// writeDebugTraceKeys writes the masked API keys with debug tracing enabled.
+func (e *InterpreterEngine) writeDebugTraceKeys(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"api_keys": e.debugTrace.Keys(),
+	})
+}
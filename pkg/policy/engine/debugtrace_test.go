@@ -0,0 +1,181 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"mercator-hq/jupiter/pkg/mpl/ast"
+	"mercator-hq/jupiter/pkg/processing"
+	"mercator-hq/jupiter/pkg/proxy/types"
+)
+
+// TestEngine_DebugTraceSpans tests that rule spans with condition events are emitted.
+func TestEngine_DebugTraceSpans(t *testing.T) {
+	policies := []*ast.Policy{
+		{
+			Name: "models",
+			Rules: []*ast.Rule{
+				createIndexTestRule("block-gpt4",
+					&ast.ConditionNode{
+						Type: ast.ConditionTypeAll,
+						Children: []*ast.ConditionNode{
+							createStringCondition("request.model", ast.OperatorEqual, "gpt-4"),
+							createSimpleCondition("request.tokens", ast.OperatorGreaterThan, float64(10)),
+						},
+					},
+					&ast.Action{Type: ast.ActionTypeDeny}),
+			},
+		},
+	}
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	eng, err := NewInterpreterEngine(DefaultEngineConfig(), &staticSource{policies: policies}, slog.Default())
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	defer eng.Close()
+	eng.SetTracer(provider.Tracer("test"))
+
+	req := &processing.EnrichedRequest{
+		RequestID:       "trace-1",
+		OriginalRequest: &types.ChatCompletionRequest{Model: "gpt-4"},
+		TokenEstimate:   &processing.TokenEstimate{TotalTokens: 100},
+	}
+
+	// Tracing is off by default
+	if _, err := eng.EvaluateRequest(context.Background(), req); err != nil {
+		t.Fatalf("evaluation failed: %v", err)
+	}
+	if got := len(recorder.Ended()); got != 0 {
+		t.Fatalf("spans = %d, want 0 when debug trace is disabled", got)
+	}
+
+	// Enabled per evaluation via context
+	if _, err := eng.EvaluateRequest(ContextWithDebugTrace(context.Background()), req); err != nil {
+		t.Fatalf("evaluation failed: %v", err)
+	}
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("spans = %d, want 1", len(spans))
+	}
+	span := spans[0]
+	if span.Name() != "policy.rule" {
+		t.Errorf("span name = %q, want policy.rule", span.Name())
+	}
+
+	var conditions, actions int
+	for _, event := range span.Events() {
+		switch event.Name {
+		case "condition":
+			conditions++
+			for _, attr := range event.Attributes {
+				if attr.Key == "condition.actual" || attr.Key == "condition.expected" {
+					t.Errorf("condition event exports value attribute %s", attr.Key)
+				}
+			}
+		case "action":
+			actions++
+		}
+	}
+	if conditions != 3 {
+		t.Errorf("condition events = %d, want 3 (all + 2 children)", conditions)
+	}
+	if actions != 1 {
+		t.Errorf("action events = %d, want 1", actions)
+	}
+}
+
+// TestEngine_DecisionID tests that decisions get unique IDs that are set on
+// the current span.
+func TestEngine_DecisionID(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	eng, err := NewInterpreterEngine(DefaultEngineConfig(), &staticSource{}, slog.Default())
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	defer eng.Close()
+
+	req := &processing.EnrichedRequest{
+		RequestID:       "decision-1",
+		OriginalRequest: &types.ChatCompletionRequest{Model: "gpt-4"},
+	}
+	ctx, span := provider.Tracer("test").Start(context.Background(), "request")
+	first, err := eng.EvaluateRequest(ctx, req)
+	span.End()
+	if err != nil {
+		t.Fatalf("evaluation failed: %v", err)
+	}
+	second, err := eng.EvaluateRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("evaluation failed: %v", err)
+	}
+
+	if first.ID == "" || first.ID == second.ID {
+		t.Errorf("decision IDs = %q, %q, want unique IDs", first.ID, second.ID)
+	}
+	var got string
+	for _, attr := range recorder.Ended()[0].Attributes() {
+		if attr.Key == "mercator.policy.decision_id" {
+			got = attr.Value.AsString()
+		}
+	}
+	if got != first.ID {
+		t.Errorf("span decision_id = %q, want %q", got, first.ID)
+	}
+}
+
+// TestDebugTraceHandler tests toggling debug tracing via the admin endpoint.
+func TestDebugTraceHandler(t *testing.T) {
+	eng, err := NewInterpreterEngine(DefaultEngineConfig(), &staticSource{}, slog.Default())
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	defer eng.Close()
+
+	handler := eng.DebugTraceHandler()
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/policy/debug-trace",
+		strings.NewReader(`{"api_key": "sk-test-key", "enabled": true}`)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	if !eng.debugTrace.Enabled("sk-test-key") {
+		t.Error("expected debug trace enabled for key")
+	}
+	if strings.Contains(rec.Body.String(), "sk-test-key") {
+		t.Error("response should not contain the unmasked API key")
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/policy/debug-trace", nil))
+	if rec.Code != http.StatusOK || !strings.Contains(rec.Body.String(), `"sk-t****"`) {
+		t.Errorf("GET = %d %s, want the masked enabled key", rec.Code, rec.Body.String())
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/policy/debug-trace",
+		strings.NewReader(`{"api_key": "sk-test-key", "enabled": false}`)))
+	if eng.debugTrace.Enabled("sk-test-key") {
+		t.Error("expected debug trace disabled for key")
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/policy/debug-trace",
+		strings.NewReader(`{"enabled": true}`)))
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want 400 for missing api_key", rec.Code)
+	}
+}
@@ -62,6 +62,58 @@
 //   - fail-closed: On policy error, block request (return 500)
 //   - fail-safe-default: On policy error, apply default action from config
 //
+// A policy can override the engine mode with its fail_safe field (fail-open
+// or fail-closed). The mode of the policy that failed is applied, so cost
+// policies can fail open while data-exfiltration policies fail closed.
+//
+// # Rule Indexing
+//
+// When EnableRuleIndex is set, rules whose conditions require a
+// field to equal a string literal (request.model == "gpt-4", request.model in
+// [...]) are bucketed by field and value at load time. Each evaluation then
+// only visits rules in the buckets matching the request, plus all rules that
+// could not be indexed, preserving priority order. Indexing is off by default:
+// decisions are the same either way, but skipped rules are left out of
+// MatchedRules, evaluation traces, and per-rule metrics.
+//
+// # Parallel Evaluation
+//
+// Setting ParallelWorkers above 1 matches the conditions of candidate rules
+// concurrently, ParallelWorkers rules at a time. A batch is matched only when
+// evaluation reaches it, so requests that short-circuit early skip the rest.
+// Actions are then executed one rule at a time in priority order, so
+// short-circuiting, tags, and routing resolve exactly as in sequential
+// evaluation.
+//
+// # Decision Explanations
+//
+// A structured DecisionExplanation lists the evaluated rules, each condition's
+// outcome with the actual field values, and the executed actions. It is
+// attached to the decision when the context is wrapped with ContextWithExplain,
+// and the most recent ExplanationHistory explanations are retained for lookup
+// by request ID through ExplanationHandler, which serves the explanations of
+// both the request and the response decision of a request.
+//
+// # Debug Tracing
+//
+// Debug tracing emits an OpenTelemetry span per evaluated rule, with one event
+// per condition node (field path, operator, and outcome) and one per executed
+// action. Condition values are not exported to spans. It is enabled at runtime per API key via EnableDebugTrace or
+// DebugTraceHandler, or for a single evaluation with ContextWithDebugTrace.
+//
+// # Decision Observers
+//
+// Observers registered with AddDecisionObserver are called with every request
+// and response decision. The events package uses this hook to stream decisions
+// to webhook, Kafka, and NATS sinks.
+//
+// # Shadow Evaluation
+//
+// A ShadowEvaluator re-evaluates every production decision against a second
+// engine loaded with preview policies (e.g., from an unmerged branch) and
+// records where the two disagree. Shadow evaluation runs asynchronously and
+// never affects enforcement; the divergence report is served by Handler.
+//
 // # Performance Targets
 //
 //   - Single rule: <50ms p99 latency (interpreted mode)
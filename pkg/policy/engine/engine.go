@@ -7,6 +7,10 @@ import (
 	"sync"
 	"time"
 
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+
 	"mercator-hq/jupiter/pkg/mpl/ast"
 	"mercator-hq/jupiter/pkg/processing"
 )
@@ -78,7 +82,10 @@ type InterpreterEngine struct {
 	// policies contains all loaded policies
 	policies []*ast.Policy
 
-	// policiesMu protects the policies slice for concurrent access
+	// index narrows rule evaluation to plausibly matching rules
+	index *ruleIndex
+
+	// policiesMu protects the policies slice and index for concurrent access
 	policiesMu sync.RWMutex
 
 	// matcher evaluates conditions
@@ -96,6 +103,24 @@ type InterpreterEngine struct {
 	// source provides policies
 	source PolicySource
 
+	// metrics receives per-rule evaluation metrics (optional)
+	metrics MetricsRecorder
+
+	// tracer creates debug spans (defaults to the global tracer provider)
+	tracer trace.Tracer
+
+	// observers are notified of every policy decision
+	observers []DecisionObserver
+
+	// hooksMu protects the metrics recorder, tracer, and observers
+	hooksMu sync.RWMutex
+
+	// debugTrace tracks API keys with debug tracing enabled
+	debugTrace *DebugTraceRegistry
+
+	// explanations retains recent decision explanations by request ID
+	explanations *ExplanationStore
+
 	// stopCh signals shutdown
 	stopCh chan struct{}
 
@@ -126,6 +151,9 @@ func NewInterpreterEngine(config *EngineConfig, source PolicySource, logger *slo
 		logger: logger,
 		source: source,
 		stopCh: make(chan struct{}),
+
+		explanations: NewExplanationStore(config.ExplanationHistory),
+		debugTrace:   NewDebugTraceRegistry(),
 	}
 
 	// Initialize condition matcher and action executor
@@ -168,9 +196,16 @@ func (e *InterpreterEngine) EvaluateRequest(ctx context.Context, enriched *proce
 	decision, err := e.evaluatePolicies(ctx, evalCtx)
 	if err != nil {
 		// Apply fail-safe mode
-		return e.handleEvaluationError(err, evalCtx)
+		decision, err = e.handleEvaluationError(err, evalCtx)
+		if err != nil {
+			return nil, err
+		}
 	}
 
+	e.identifyDecision(ctx, decision)
+	e.recordExplanation(ctx, StageRequest, evalCtx, decision)
+	e.notifyObservers(ctx, StageRequest, evalCtx, decision)
+
 	return decision, nil
 }
 
@@ -197,17 +232,32 @@ func (e *InterpreterEngine) EvaluateResponse(ctx context.Context, enriched *proc
 	decision, err := e.evaluatePolicies(ctx, evalCtx)
 	if err != nil {
 		// Apply fail-safe mode
-		return e.handleEvaluationError(err, evalCtx)
+		decision, err = e.handleEvaluationError(err, evalCtx)
+		if err != nil {
+			return nil, err
+		}
 	}
 
+	e.identifyDecision(ctx, decision)
+	e.recordExplanation(ctx, StageResponse, evalCtx, decision)
+	e.notifyObservers(ctx, StageResponse, evalCtx, decision)
+
 	return decision, nil
 }
 
+// identifyDecision assigns a decision its ID and records the ID on the
+// current trace span, if any.
+func (e *InterpreterEngine) identifyDecision(ctx context.Context, decision *PolicyDecision) {
+	decision.ID = uuid.New().String()
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("mercator.policy.decision_id", decision.ID))
+}
+
 // evaluatePolicies evaluates all loaded policies against the evaluation context.
 func (e *InterpreterEngine) evaluatePolicies(ctx context.Context, evalCtx *EvaluationContext) (*PolicyDecision, error) {
-	// Get policies (read lock)
+	// Get policies and index (read lock)
 	e.policiesMu.RLock()
 	policies := e.policies
+	index := e.index
 	e.policiesMu.RUnlock()
 
 	if len(policies) == 0 {
@@ -219,49 +269,114 @@ func (e *InterpreterEngine) evaluatePolicies(ctx context.Context, evalCtx *Evalu
 	policyCtx, cancel := context.WithTimeout(ctx, e.config.PolicyTimeout)
 	defer cancel()
 
-	// Evaluate each policy
-	for _, policy := range policies {
-		// Check if context is cancelled
-		select {
-		case <-policyCtx.Done():
-			return nil, &TimeoutError{
-				PolicyID: policy.Name,
-				Timeout:  e.config.PolicyTimeout,
+	// Select candidate rules (all enabled rules when indexing is disabled)
+	rules := index.rules
+	if e.config.EnableRuleIndex {
+		rules = index.candidates(evalCtx)
+	}
+
+	// Report per-rule metrics once evaluation finishes (including on error)
+	recorder := e.metricsRecorder()
+	if recorder != nil {
+		defer e.recordRuleMetrics(ctx, recorder, evalCtx)
+	}
+
+	// Emit a span per rule when debug tracing is enabled for this request
+	traced := e.debugTraceActive(ctx)
+
+	// Record condition outcomes as they are matched for explanations and spans
+	if traced || e.captureConditions(ctx) {
+		policyCtx = context.WithValue(policyCtx, captureConditionsKey{}, true)
+	}
+
+	// Match conditions concurrently; actions are still applied in priority order
+	var matches *parallelMatches
+	if e.useParallelEvaluation(len(rules)) {
+		matches = e.newParallelMatches(policyCtx, rules, evalCtx)
+	}
+
+	var current *ast.Policy
+	var policyStart time.Time
+	for pos, candidate := range rules {
+		policy, rule := candidate.policy, candidate.rule
+
+		if policy != current {
+			if current != nil {
+				e.endPolicy(recorder, current, policyStart, evalCtx)
+			}
+
+			// Check if context is cancelled
+			select {
+			case <-policyCtx.Done():
+				return nil, &TimeoutError{
+					PolicyID: policy.Name,
+					Timeout:  e.config.PolicyTimeout,
+				}
+			default:
 			}
-		default:
+
+			// Trace policy start
+			current = policy
+			policyStart = time.Now()
+			evalCtx.AddTraceStep("policy_start", policy.Name, "", fmt.Sprintf("evaluating policy %q", policy.Name), 0)
 		}
 
-		// Trace policy start
-		policyStart := time.Now()
-		evalCtx.AddTraceStep("policy_start", policy.Name, "", fmt.Sprintf("evaluating policy %q", policy.Name), 0)
+		var outcome *conditionOutcome
+		if matches != nil {
+			outcome = matches.outcome(pos)
+		}
 
-		// Evaluate enabled rules in this policy
-		for _, rule := range policy.EnabledRules() {
-			if err := e.evaluateRule(policyCtx, policy, rule, evalCtx); err != nil {
-				return nil, err
-			}
+		var span trace.Span
+		evaluatedBefore := len(evalCtx.MatchedRules)
+		if traced {
+			span = e.startRuleSpan(policyCtx, policy, rule, evalCtx)
+		}
+
+		err := e.evaluateRule(policyCtx, policy, rule, evalCtx, outcome)
 
-			// Stop if evaluation is short-circuited
-			if evalCtx.Stopped {
-				evalCtx.AddTraceStep("policy_stop", policy.Name, rule.Name, "evaluation short-circuited", time.Since(policyStart))
-				break
+		if span != nil {
+			var evaluated *MatchedRule
+			if len(evalCtx.MatchedRules) > evaluatedBefore {
+				evaluated = evalCtx.MatchedRules[len(evalCtx.MatchedRules)-1]
 			}
+			e.endRuleSpan(span, evaluated, err)
 		}
 
-		evalCtx.AddTraceStep("policy_end", policy.Name, "", fmt.Sprintf("completed policy %q", policy.Name), time.Since(policyStart))
+		if err != nil {
+			return nil, err
+		}
 
 		// Stop if evaluation is short-circuited
 		if evalCtx.Stopped {
+			evalCtx.AddTraceStep("policy_stop", policy.Name, rule.Name, "evaluation short-circuited", time.Since(policyStart))
 			break
 		}
 	}
 
+	if current != nil {
+		e.endPolicy(recorder, current, policyStart, evalCtx)
+	}
+
 	return e.buildDecision(evalCtx), nil
 }
 
+// endPolicy traces and records the completion of a policy.
+func (e *InterpreterEngine) endPolicy(recorder MetricsRecorder, policy *ast.Policy, policyStart time.Time, evalCtx *EvaluationContext) {
+	duration := time.Since(policyStart)
+	evalCtx.AddTraceStep("policy_end", policy.Name, "", fmt.Sprintf("completed policy %q", policy.Name), duration)
+	if recorder != nil {
+		recorder.RecordPolicyDuration(policy.Name, duration)
+	}
+}
+
 // evaluateRule evaluates a single rule.
-func (e *InterpreterEngine) evaluateRule(ctx context.Context, policy *ast.Policy, rule *ast.Rule, evalCtx *EvaluationContext) error {
+// If outcome is non-nil, it holds the already computed condition result for the rule.
+func (e *InterpreterEngine) evaluateRule(ctx context.Context, policy *ast.Policy, rule *ast.Rule, evalCtx *EvaluationContext, outcome *conditionOutcome) error {
 	ruleStart := time.Now()
+	if outcome != nil {
+		// Account for time spent matching conditions ahead of time
+		ruleStart = ruleStart.Add(-outcome.duration)
+	}
 
 	// Set rule timeout
 	ruleCtx, cancel := context.WithTimeout(ctx, e.config.RuleTimeout)
@@ -279,17 +394,20 @@ func (e *InterpreterEngine) evaluateRule(ctx context.Context, policy *ast.Policy
 
 	// Evaluate conditions
 	if rule.HasConditions() {
-		condMatched, err := e.matcher.Match(ruleCtx, rule.Conditions, evalCtx)
+		if outcome == nil {
+			result := e.matchConditions(ruleCtx, policy, rule, evalCtx)
+			outcome = &result
+		}
+		condMatched, err := outcome.matched, outcome.err
+		matched.condition = outcome.condition
 		if err != nil {
 			// Check for timeout
-			select {
-			case <-ruleCtx.Done():
+			if outcome.timedOut {
 				return &TimeoutError{
 					PolicyID: policy.Name,
 					RuleID:   rule.Name,
 					Timeout:  e.config.RuleTimeout,
 				}
-			default:
 			}
 
 			matched.Error = err
@@ -402,14 +520,16 @@ func (e *InterpreterEngine) buildDecision(evalCtx *EvaluationContext) *PolicyDec
 }
 
 // handleEvaluationError handles evaluation errors according to the fail-safe mode.
+// The mode declared by the failing policy (if any) overrides the engine setting.
 func (e *InterpreterEngine) handleEvaluationError(err error, evalCtx *EvaluationContext) (*PolicyDecision, error) {
+	mode := e.errorFailSafeMode(err)
 	e.logger.Error("policy evaluation error",
 		"error", err,
 		"request_id", evalCtx.RequestID,
-		"fail_safe_mode", e.config.FailSafeMode,
+		"fail_safe_mode", mode,
 	)
 
-	switch e.config.FailSafeMode {
+	switch mode {
 	case FailOpen:
 		// Allow request
 		e.logger.Info("fail-open: allowing request after evaluation error",
@@ -446,10 +566,28 @@ func (e *InterpreterEngine) handleEvaluationError(err error, evalCtx *Evaluation
 
 	default:
 		// This should never happen if config is validated
-		return nil, fmt.Errorf("unknown fail-safe mode: %q", e.config.FailSafeMode)
+		return nil, fmt.Errorf("unknown fail-safe mode: %q", mode)
 	}
 }
 
+// errorFailSafeMode returns the fail-safe mode for an evaluation error: the
+// override of the policy that failed, or the engine setting.
+func (e *InterpreterEngine) errorFailSafeMode(err error) FailSafeMode {
+	policyID := failedPolicyID(err)
+	if policyID == "" {
+		return e.config.FailSafeMode
+	}
+
+	e.policiesMu.RLock()
+	defer e.policiesMu.RUnlock()
+	for _, policy := range e.policies {
+		if policy.Name == policyID && policy.FailSafe != "" {
+			return FailSafeMode(policy.FailSafe)
+		}
+	}
+	return e.config.FailSafeMode
+}
+
 // ReloadPolicies reloads policies from the source.
 func (e *InterpreterEngine) ReloadPolicies(ctx context.Context) error {
 	e.logger.Info("reloading policies")
@@ -490,14 +628,19 @@ func (e *InterpreterEngine) ReloadPolicies(ctx context.Context) error {
 	// Normalize priorities (sort policies and rules by priority)
 	NormalizePolicyPriorities(policies)
 
-	// Atomically replace policies (write lock)
+	// Build rule index over enabled rules in priority order
+	index := newRuleIndex(policies)
+
+	// Atomically replace policies and index (write lock)
 	e.policiesMu.Lock()
 	e.policies = policies
+	e.index = index
 	e.policiesMu.Unlock()
 
 	e.logger.Info("policies reloaded successfully",
 		"policy_count", len(policies),
 		"rule_count", totalRules,
+		"indexed_fields", len(index.keyed),
 	)
 
 	return nil
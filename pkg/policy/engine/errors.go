@@ -130,6 +130,28 @@ func (e *ProviderNotFoundError) Error() string {
 	return fmt.Sprintf("provider not found: %q", e.ProviderName)
 }
 
+// failedPolicyID returns the ID of the policy that caused an evaluation
+// error, or "" if the error is not attributable to a policy.
+func failedPolicyID(err error) string {
+	var timeoutErr *TimeoutError
+	var conditionErr *ConditionError
+	var actionErr *ActionError
+	var evalErr *EvaluationError
+
+	switch {
+	case errors.As(err, &timeoutErr):
+		return timeoutErr.PolicyID
+	case errors.As(err, &conditionErr):
+		return conditionErr.PolicyID
+	case errors.As(err, &actionErr):
+		return actionErr.PolicyID
+	case errors.As(err, &evalErr):
+		return evalErr.PolicyID
+	default:
+		return ""
+	}
+}
+
 // FieldNotFoundError indicates a condition references a non-existent field.
 type FieldNotFoundError struct {
 	FieldName string
@@ -177,15 +177,19 @@ func (e *DefaultExecutor) executeRedact(ctx context.Context, action *ast.Action,
 		replacement = "***"
 	}
 
+	// Placeholders can be restored in the response
+	restore := strategy == string(ast.RedactStrategyPlaceholder) && action.GetBoolParameter("restore")
+
 	// Add redaction to evaluation context
 	// Actual content redaction will be applied by the proxy when forwarding the request
-	evalCtx.AddRedaction(field, strategy, pattern, replacement, 0)
+	evalCtx.AddRedaction(field, strategy, pattern, replacement, 0, restore)
 
 	e.logger.Info("action redact: content redaction configured",
 		"request_id", evalCtx.RequestID,
 		"field", field,
 		"strategy", strategy,
 		"pattern", pattern,
+		"restore", restore,
 	)
 
 	return &ActionResult{
@@ -196,6 +200,7 @@ func (e *DefaultExecutor) executeRedact(ctx context.Context, action *ast.Action,
 			"strategy":    strategy,
 			"pattern":     pattern,
 			"replacement": replacement,
+			"restore":     restore,
 		},
 	}, nil
 }
@@ -480,6 +480,7 @@ func TestExecutor_Redact(t *testing.T) {
 		wantField    string
 		wantStrategy string
 		wantPattern  string
+		wantRestore  bool
 	}{
 		{
 			name: "redact with defaults",
@@ -505,6 +506,19 @@ func TestExecutor_Redact(t *testing.T) {
 			wantStrategy: "replace",
 			wantPattern:  "\\b\\d{3}-\\d{2}-\\d{4}\\b",
 		},
+		{
+			name: "redact with restored placeholders",
+			action: &ast.Action{
+				Type: ast.ActionTypeRedact,
+				Parameters: map[string]*ast.ValueNode{
+					"strategy": {Type: ast.ValueTypeString, Value: "placeholder"},
+					"restore":  {Type: ast.ValueTypeBoolean, Value: true},
+				},
+			},
+			wantField:    "prompt",
+			wantStrategy: "placeholder",
+			wantRestore:  true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -525,7 +539,12 @@ func TestExecutor_Redact(t *testing.T) {
 
 			// Verify redaction was added
 			if len(evalCtx.Redactions) == 0 {
-				t.Error("expected redaction to be added")
+				t.Fatal("expected redaction to be added")
+			}
+			redaction := evalCtx.Redactions[0]
+			if redaction.Field != tt.wantField || redaction.Strategy != tt.wantStrategy ||
+				redaction.Pattern != tt.wantPattern || redaction.Restore != tt.wantRestore {
+				t.Errorf("unexpected redaction: %+v", redaction)
 			}
 		})
 	}
@@ -0,0 +1,336 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"mercator-hq/jupiter/pkg/mpl/ast"
+	"mercator-hq/jupiter/pkg/processing"
+)
+
+// DecisionExplanation is a structured explanation of a policy decision.
+// It lists every evaluated rule, the outcome of each condition with the
+// actual field values observed, and the actions that were executed.
+type DecisionExplanation struct {
+	// RequestID is the request the decision was made for.
+	RequestID string `json:"request_id"`
+
+	// Stage is the pipeline stage the decision was made in.
+	Stage DecisionStage `json:"stage"`
+
+	// Action is the final policy action.
+	Action PolicyAction `json:"action"`
+
+	// BlockReason explains why the request was blocked (if blocked).
+	BlockReason string `json:"block_reason,omitempty"`
+
+	// Rules contains one entry per evaluated rule, in evaluation order.
+	Rules []*RuleExplanation `json:"rules"`
+
+	// Findings explains the content analysis findings of the evaluated
+	// request or response: the pattern, model or rule that triggered each
+	// finding, with its confidence and the span of the matched excerpt.
+	Findings []processing.Finding `json:"findings,omitempty"`
+
+	// EvaluationTime is the total evaluation time.
+	EvaluationTime time.Duration `json:"evaluation_time_ns"`
+
+	// Timestamp is when the explanation was generated.
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// RuleExplanation explains the evaluation of a single rule.
+type RuleExplanation struct {
+	// PolicyID is the policy containing the rule.
+	PolicyID string `json:"policy_id"`
+
+	// RuleID is the rule identifier.
+	RuleID string `json:"rule_id"`
+
+	// Matched indicates whether the rule's conditions matched.
+	Matched bool `json:"matched"`
+
+	// Condition is the explanation of the rule's root condition (nil if none).
+	Condition *ConditionExplanation `json:"condition,omitempty"`
+
+	// Actions contains the actions executed by the rule.
+	Actions []*ActionExplanation `json:"actions,omitempty"`
+
+	// Error contains any error raised while evaluating the rule.
+	Error string `json:"error,omitempty"`
+}
+
+// ConditionExplanation explains the outcome of a condition node.
+type ConditionExplanation struct {
+	// Type is the condition type ("simple", "all", "any", "not", "function").
+	Type ast.ConditionType `json:"type"`
+
+	// Field is the field evaluated (simple conditions).
+	Field string `json:"field,omitempty"`
+
+	// Operator is the comparison operator (simple conditions).
+	Operator ast.Operator `json:"operator,omitempty"`
+
+	// ExpectedValue is the value from the policy (simple conditions).
+	ExpectedValue interface{} `json:"expected,omitempty"`
+
+	// ActualValue is the value observed on the request or response.
+	ActualValue interface{} `json:"actual,omitempty"`
+
+	// Function is the function name (function conditions).
+	Function string `json:"function,omitempty"`
+
+	// Matched indicates whether this condition was satisfied.
+	Matched bool `json:"matched"`
+
+	// Error contains any error raised while evaluating the condition.
+	Error string `json:"error,omitempty"`
+
+	// Children explains the child conditions that were evaluated (all/any/not).
+	// Children skipped by short-circuiting are omitted.
+	Children []*ConditionExplanation `json:"children,omitempty"`
+}
+
+// ActionExplanation describes an executed action.
+type ActionExplanation struct {
+	// Type is the action type.
+	Type ast.ActionType `json:"type"`
+
+	// Success indicates whether the action executed successfully.
+	Success bool `json:"success"`
+
+	// Error contains any action error.
+	Error string `json:"error,omitempty"`
+}
+
+// explainContextKey is the context key that requests an explanation.
+type explainContextKey struct{}
+
+// ContextWithExplain returns a context that asks the engine to attach a
+// DecisionExplanation to the resulting PolicyDecision.
+func ContextWithExplain(ctx context.Context) context.Context {
+	return context.WithValue(ctx, explainContextKey{}, true)
+}
+
+// ExplainRequested returns true if the context asks for an explanation.
+func ExplainRequested(ctx context.Context) bool {
+	requested, _ := ctx.Value(explainContextKey{}).(bool)
+	return requested
+}
+
+// conditionCaptureKey is the context key under which the matcher records
+// condition outcomes.
+type conditionCaptureKey struct{}
+
+// withConditionCapture returns a context in which the matcher appends the
+// explanation of each condition it evaluates to parent's children.
+func withConditionCapture(ctx context.Context, parent *ConditionExplanation) context.Context {
+	return context.WithValue(ctx, conditionCaptureKey{}, parent)
+}
+
+// capturedCondition returns the condition explanation being recorded in ctx, if any.
+func capturedCondition(ctx context.Context) (*ConditionExplanation, bool) {
+	exp, ok := ctx.Value(conditionCaptureKey{}).(*ConditionExplanation)
+	return exp, ok
+}
+
+// captureConditionsKey is the context key that asks matchConditions to
+// capture the condition outcomes of every rule.
+type captureConditionsKey struct{}
+
+// captureConditions returns true if condition outcomes should be recorded
+// while matching, because the evaluation will be explained.
+func (e *InterpreterEngine) captureConditions(ctx context.Context) bool {
+	return ExplainRequested(ctx) || e.config.ExplanationHistory > 0
+}
+
+// newConditionExplanation describes a condition node before it is matched.
+func newConditionExplanation(condition *ast.ConditionNode) *ConditionExplanation {
+	exp := &ConditionExplanation{Type: condition.Type}
+	switch condition.Type {
+	case ast.ConditionTypeSimple:
+		exp.Field = condition.Field
+		exp.Operator = condition.Operator
+		if condition.Value != nil {
+			exp.ExpectedValue = condition.Value.Value
+		}
+	case ast.ConditionTypeFunction:
+		exp.Function = condition.Function
+	}
+	return exp
+}
+
+// explain builds an explanation for a completed evaluation from the
+// condition outcomes captured while the rules were matched.
+func (e *InterpreterEngine) explain(stage DecisionStage, evalCtx *EvaluationContext, decision *PolicyDecision) *DecisionExplanation {
+	explanation := &DecisionExplanation{
+		RequestID:      evalCtx.RequestID,
+		Stage:          stage,
+		Action:         decision.Action,
+		BlockReason:    decision.BlockReason,
+		EvaluationTime: decision.EvaluationTime,
+		Timestamp:      time.Now(),
+	}
+
+	if evalCtx.Request != nil && evalCtx.Request.ContentAnalysis != nil {
+		explanation.Findings = evalCtx.Request.ContentAnalysis.Findings
+	}
+	if evalCtx.Response != nil && evalCtx.Response.ContentAnalysis != nil {
+		explanation.Findings = evalCtx.Response.ContentAnalysis.Findings
+	}
+
+	for _, matched := range decision.MatchedRules {
+		ruleExp := &RuleExplanation{
+			PolicyID:  matched.PolicyID,
+			RuleID:    matched.RuleID,
+			Matched:   matched.ConditionResult,
+			Condition: matched.condition,
+		}
+		if matched.Error != nil {
+			ruleExp.Error = matched.Error.Error()
+		}
+		for _, action := range matched.ActionsExecuted {
+			actionExp := &ActionExplanation{
+				Type:    action.ActionType,
+				Success: action.Success,
+			}
+			if action.Error != nil {
+				actionExp.Error = action.Error.Error()
+			}
+			ruleExp.Actions = append(ruleExp.Actions, actionExp)
+		}
+
+		explanation.Rules = append(explanation.Rules, ruleExp)
+	}
+
+	return explanation
+}
+
+// ExplanationStore keeps the most recent decision explanations keyed by
+// request ID and stage, so the response decision of a request does not
+// replace its request decision. When full, the oldest explanation is evicted.
+type ExplanationStore struct {
+	mu       sync.Mutex
+	capacity int
+
+	// ring holds keys in insertion order; next is the slot that is
+	// overwritten (and its explanation evicted) by the next new key.
+	ring    []explanationKey
+	next    int
+	entries map[explanationKey]*DecisionExplanation
+}
+
+// explanationKey identifies the explanation of a decision.
+type explanationKey struct {
+	requestID string
+	stage     DecisionStage
+}
+
+// NewExplanationStore creates a store that holds up to capacity explanations.
+func NewExplanationStore(capacity int) *ExplanationStore {
+	s := &ExplanationStore{
+		capacity: capacity,
+		entries:  make(map[explanationKey]*DecisionExplanation),
+	}
+	if capacity > 0 {
+		s.ring = make([]explanationKey, capacity)
+	}
+	return s
+}
+
+// Put stores an explanation, replacing the explanation of the same request
+// and stage and evicting the oldest entry if the store is full.
+func (s *ExplanationStore) Put(explanation *DecisionExplanation) {
+	if s.capacity <= 0 || explanation == nil || explanation.RequestID == "" {
+		return
+	}
+	key := explanationKey{requestID: explanation.RequestID, stage: explanation.Stage}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.entries[key]; !exists {
+		if oldest := s.ring[s.next]; oldest.requestID != "" {
+			delete(s.entries, oldest)
+		}
+		s.ring[s.next] = key
+		s.next = (s.next + 1) % s.capacity
+	}
+	s.entries[key] = explanation
+}
+
+// Get returns the explanations for a request ID, the request decision
+// before the response decision.
+func (s *ExplanationStore) Get(requestID string) ([]*DecisionExplanation, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var explanations []*DecisionExplanation
+	for _, stage := range []DecisionStage{StageRequest, StageResponse} {
+		if explanation, ok := s.entries[explanationKey{requestID: requestID, stage: stage}]; ok {
+			explanations = append(explanations, explanation)
+		}
+	}
+	return explanations, len(explanations) > 0
+}
+
+// Len returns the number of stored explanations.
+func (s *ExplanationStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// GetExplanation returns the stored explanations for a request ID, one per
+// evaluated stage. Explanations are only retained when ExplanationHistory
+// is configured.
+func (e *InterpreterEngine) GetExplanation(requestID string) ([]*DecisionExplanation, bool) {
+	return e.explanations.Get(requestID)
+}
+
+// ExplanationHandler returns an HTTP handler that serves the stored decision
+// explanations of a request as a JSON array, one per evaluated stage. It
+// expects the request ID in the "request_id" path
+// wildcard, e.g. when mounted at /admin/policy/explanations/{request_id}.
+func (e *InterpreterEngine) ExplanationHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		requestID := r.PathValue("request_id")
+		if requestID == "" {
+			http.Error(w, "request_id is required", http.StatusBadRequest)
+			return
+		}
+
+		explanations, ok := e.GetExplanation(requestID)
+		if !ok {
+			http.Error(w, fmt.Sprintf("no explanation recorded for request %q", requestID), http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(explanations)
+	})
+}
+
+// recordExplanation builds, stores, and (if requested) attaches an explanation.
+func (e *InterpreterEngine) recordExplanation(ctx context.Context, stage DecisionStage, evalCtx *EvaluationContext, decision *PolicyDecision) {
+	requested := ExplainRequested(ctx)
+	if !requested && e.config.ExplanationHistory <= 0 {
+		return
+	}
+
+	explanation := e.explain(stage, evalCtx, decision)
+	e.explanations.Put(explanation)
+	if requested {
+		decision.Explanation = explanation
+	}
+}
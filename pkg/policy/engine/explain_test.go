@@ -0,0 +1,264 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mercator-hq/jupiter/pkg/mpl/ast"
+	"mercator-hq/jupiter/pkg/processing"
+	"mercator-hq/jupiter/pkg/proxy/types"
+)
+
+// TestEngine_Explanation tests that explanations capture condition values and actions.
+func TestEngine_Explanation(t *testing.T) {
+	policies := []*ast.Policy{
+		{
+			Name: "limits",
+			Rules: []*ast.Rule{
+				createIndexTestRule("block-large-gpt4",
+					&ast.ConditionNode{
+						Type: ast.ConditionTypeAll,
+						Children: []*ast.ConditionNode{
+							createStringCondition("request.model", ast.OperatorEqual, "gpt-4"),
+							createSimpleCondition("request.tokens", ast.OperatorGreaterThan, float64(1000)),
+						},
+					},
+					&ast.Action{Type: ast.ActionTypeDeny, Parameters: map[string]*ast.ValueNode{
+						"message": {Type: ast.ValueTypeString, Value: "too large"},
+					}}),
+			},
+		},
+	}
+
+	cfg := DefaultEngineConfig().WithExplanationHistory(10)
+	eng, err := NewInterpreterEngine(cfg, &staticSource{policies: policies}, slog.Default())
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	defer eng.Close()
+
+	req := &processing.EnrichedRequest{
+		RequestID:       "explain-1",
+		OriginalRequest: &types.ChatCompletionRequest{Model: "gpt-4"},
+		TokenEstimate:   &processing.TokenEstimate{TotalTokens: 500},
+	}
+
+	// Without the context flag the explanation is only stored
+	decision, err := eng.EvaluateRequest(context.Background(), req)
+	if err != nil {
+		t.Fatalf("evaluation failed: %v", err)
+	}
+	if decision.Explanation != nil {
+		t.Error("expected no explanation attached without ContextWithExplain")
+	}
+
+	explanations, ok := eng.GetExplanation("explain-1")
+	if !ok || len(explanations) != 1 {
+		t.Fatalf("expected one stored explanation, got %+v", explanations)
+	}
+	explanation := explanations[0]
+	if explanation.Stage != StageRequest {
+		t.Errorf("stage = %q, want %q", explanation.Stage, StageRequest)
+	}
+	if len(explanation.Rules) != 1 {
+		t.Fatalf("rules = %d, want 1", len(explanation.Rules))
+	}
+
+	rule := explanation.Rules[0]
+	if rule.Matched {
+		t.Error("expected rule not to match")
+	}
+	if rule.Condition == nil || len(rule.Condition.Children) != 2 {
+		t.Fatalf("expected two child conditions, got %+v", rule.Condition)
+	}
+	if !rule.Condition.Children[0].Matched || rule.Condition.Children[0].ActualValue != "gpt-4" {
+		t.Errorf("model condition = %+v, want matched with actual gpt-4", rule.Condition.Children[0])
+	}
+	tokens := rule.Condition.Children[1]
+	if tokens.Matched || tokens.ActualValue != 500 {
+		t.Errorf("tokens condition = %+v, want failed with actual 500", tokens)
+	}
+
+	// With the context flag the explanation is attached to the decision
+	req.RequestID = "explain-2"
+	req.TokenEstimate.TotalTokens = 5000
+	decision, err = eng.EvaluateRequest(ContextWithExplain(context.Background()), req)
+	if err != nil {
+		t.Fatalf("evaluation failed: %v", err)
+	}
+	if decision.Explanation == nil {
+		t.Fatal("expected explanation attached to decision")
+	}
+	if decision.Explanation.Action != ActionBlock {
+		t.Errorf("action = %v, want %v", decision.Explanation.Action, ActionBlock)
+	}
+	actions := decision.Explanation.Rules[0].Actions
+	if len(actions) != 1 || actions[0].Type != ast.ActionTypeDeny || !actions[0].Success {
+		t.Errorf("actions = %+v, want successful deny", actions)
+	}
+}
+
+// TestEngine_ExplanationFailSafeOverride tests that explanations report the
+// condition outcomes of the evaluation itself, including a policy's fail-safe
+// override for missing fields.
+func TestEngine_ExplanationFailSafeOverride(t *testing.T) {
+	policy := &ast.Policy{
+		Name:     "lenient",
+		FailSafe: "fail-open",
+		Rules: []*ast.Rule{
+			createIndexTestRule("tag-team",
+				createStringCondition("request.no_such_field", ast.OperatorEqual, "x"),
+				&ast.Action{Type: ast.ActionTypeTag, Parameters: map[string]*ast.ValueNode{}}),
+		},
+	}
+
+	cfg := DefaultEngineConfig()
+	cfg.FailSafeMode = FailSafeDefault
+	eng, err := NewInterpreterEngine(cfg, &staticSource{policies: []*ast.Policy{policy}}, slog.Default())
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	defer eng.Close()
+
+	decision, err := eng.EvaluateRequest(ContextWithExplain(context.Background()), &processing.EnrichedRequest{
+		RequestID:       "explain-failsafe",
+		OriginalRequest: &types.ChatCompletionRequest{Model: "gpt-4"},
+	})
+	if err != nil {
+		t.Fatalf("evaluation failed: %v", err)
+	}
+
+	rule := decision.Explanation.Rules[0]
+	if !rule.Matched || rule.Condition == nil || !rule.Condition.Matched {
+		t.Errorf("rule = %+v, condition = %+v, want matched under fail-open", rule, rule.Condition)
+	}
+}
+
+// TestEngine_ExplanationFindings tests that explanations include the findings
+// of the content analysis.
+func TestEngine_ExplanationFindings(t *testing.T) {
+	eng, err := NewInterpreterEngine(DefaultEngineConfig(), &staticSource{}, slog.Default())
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	defer eng.Close()
+
+	finding := processing.Finding{Detector: "injection", Type: "direct", Source: "config", Rule: "ignore previous instructions", Confidence: 0.85, End: 28}
+	decision, err := eng.EvaluateRequest(ContextWithExplain(context.Background()), &processing.EnrichedRequest{
+		RequestID:       "explain-findings",
+		OriginalRequest: &types.ChatCompletionRequest{Model: "gpt-4"},
+		ContentAnalysis: &processing.ContentAnalysis{Findings: []processing.Finding{finding}},
+	})
+	if err != nil {
+		t.Fatalf("evaluation failed: %v", err)
+	}
+
+	if findings := decision.Explanation.Findings; len(findings) != 1 || findings[0] != finding {
+		t.Errorf("findings = %+v, want [%+v]", findings, finding)
+	}
+}
+
+// TestEngine_ExplanationStages tests that the request and response
+// explanations of a request are both retained.
+func TestEngine_ExplanationStages(t *testing.T) {
+	cfg := DefaultEngineConfig().WithExplanationHistory(10)
+	eng, err := NewInterpreterEngine(cfg, &staticSource{}, slog.Default())
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	defer eng.Close()
+
+	if _, err := eng.EvaluateRequest(context.Background(), &processing.EnrichedRequest{
+		RequestID:       "explain-stages",
+		OriginalRequest: &types.ChatCompletionRequest{Model: "gpt-4"},
+	}); err != nil {
+		t.Fatalf("request evaluation failed: %v", err)
+	}
+	if _, err := eng.EvaluateResponse(context.Background(), &processing.EnrichedResponse{
+		RequestID: "explain-stages",
+	}); err != nil {
+		t.Fatalf("response evaluation failed: %v", err)
+	}
+
+	explanations, ok := eng.GetExplanation("explain-stages")
+	if !ok || len(explanations) != 2 {
+		t.Fatalf("expected two stored explanations, got %+v", explanations)
+	}
+	if explanations[0].Stage != StageRequest || explanations[1].Stage != StageResponse {
+		t.Errorf("stages = %q, %q, want request, response", explanations[0].Stage, explanations[1].Stage)
+	}
+}
+
+// TestExplanationStore_Eviction tests that the oldest explanation is evicted.
+func TestExplanationStore_Eviction(t *testing.T) {
+	store := NewExplanationStore(2)
+	store.Put(&DecisionExplanation{RequestID: "a", Stage: StageRequest})
+	store.Put(&DecisionExplanation{RequestID: "b", Stage: StageRequest})
+	store.Put(&DecisionExplanation{RequestID: "c", Stage: StageRequest})
+
+	if store.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", store.Len())
+	}
+	if _, ok := store.Get("a"); ok {
+		t.Error("expected oldest explanation to be evicted")
+	}
+	if _, ok := store.Get("c"); !ok {
+		t.Error("expected newest explanation to be present")
+	}
+
+	// Replacing a stored explanation does not evict another one
+	store.Put(&DecisionExplanation{RequestID: "c", Stage: StageRequest, Action: ActionBlock})
+	if store.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", store.Len())
+	}
+	if got, _ := store.Get("c"); len(got) != 1 || got[0].Action != ActionBlock {
+		t.Errorf("Get(%q) = %+v, want the replacement", "c", got)
+	}
+
+	// Eviction keeps cycling through the oldest entries
+	store.Put(&DecisionExplanation{RequestID: "d", Stage: StageRequest})
+	store.Put(&DecisionExplanation{RequestID: "e", Stage: StageRequest})
+	for id, want := range map[string]bool{"b": false, "c": false, "d": true, "e": true} {
+		if _, ok := store.Get(id); ok != want {
+			t.Errorf("Get(%q) present = %v, want %v", id, ok, want)
+		}
+	}
+}
+
+// TestExplanationHandler tests the admin explanation endpoint.
+func TestExplanationHandler(t *testing.T) {
+	cfg := DefaultEngineConfig().WithExplanationHistory(10)
+	eng, err := NewInterpreterEngine(cfg, &staticSource{}, slog.Default())
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	defer eng.Close()
+	eng.explanations.Put(&DecisionExplanation{RequestID: "req-1", Stage: StageRequest, Action: ActionAllow})
+	eng.explanations.Put(&DecisionExplanation{RequestID: "req-1", Stage: StageResponse, Action: ActionBlock})
+
+	mux := http.NewServeMux()
+	mux.Handle("/admin/policy/explanations/{request_id}", eng.ExplanationHandler())
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/policy/explanations/req-1", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rec.Code)
+	}
+	var got []DecisionExplanation
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(got) != 2 || got[0].Action != ActionAllow || got[1].Stage != StageResponse || got[1].Action != ActionBlock {
+		t.Errorf("explanations = %+v", got)
+	}
+
+	rec = httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/policy/explanations/missing", nil))
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", rec.Code)
+	}
+}
@@ -0,0 +1,65 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"mercator-hq/jupiter/pkg/mpl/ast"
+	"mercator-hq/jupiter/pkg/processing"
+	"mercator-hq/jupiter/pkg/proxy/types"
+)
+
+// TestEngine_PolicyFailSafeOverride tests that a policy's own fail-safe mode
+// overrides the engine setting when one of its conditions cannot be evaluated.
+func TestEngine_PolicyFailSafeOverride(t *testing.T) {
+	tests := []struct {
+		name       string
+		engineMode FailSafeMode
+		policyMode string
+		field      string
+		want       PolicyAction
+	}{
+		{name: "engine fail-closed", engineMode: FailClosed, field: "request.model", want: ActionBlock},
+		{name: "engine fail-open", engineMode: FailOpen, field: "request.model", want: ActionAllow},
+		{name: "policy fail-open overrides engine", engineMode: FailClosed, policyMode: "fail-open", field: "request.model", want: ActionAllow},
+		{name: "policy fail-closed overrides engine", engineMode: FailOpen, policyMode: "fail-closed", field: "request.model", want: ActionBlock},
+		{name: "policy fail-closed on missing field", engineMode: FailSafeDefault, policyMode: "fail-closed", field: "request.no_such_field", want: ActionBlock},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// An invalid pattern makes condition evaluation fail
+			policy := &ast.Policy{
+				Name:     "exfiltration",
+				FailSafe: tt.policyMode,
+				Rules: []*ast.Rule{
+					createIndexTestRule("bad-pattern",
+						createStringCondition(tt.field, ast.OperatorMatches, "("),
+						&ast.Action{Type: ast.ActionTypeTag, Parameters: map[string]*ast.ValueNode{}}),
+				},
+			}
+
+			cfg := DefaultEngineConfig()
+			cfg.FailSafeMode = tt.engineMode
+			cfg.DefaultAction = ActionAllow
+
+			eng, err := NewInterpreterEngine(cfg, &staticSource{policies: []*ast.Policy{policy}}, slog.Default())
+			if err != nil {
+				t.Fatalf("failed to create engine: %v", err)
+			}
+			defer eng.Close()
+
+			decision, err := eng.EvaluateRequest(context.Background(), &processing.EnrichedRequest{
+				RequestID:       "failsafe",
+				OriginalRequest: &types.ChatCompletionRequest{Model: "gpt-4"},
+			})
+			if err != nil {
+				t.Fatalf("evaluation failed: %v", err)
+			}
+			if decision.Action != tt.want {
+				t.Errorf("action = %v, want %v", decision.Action, tt.want)
+			}
+		})
+	}
+}
@@ -290,13 +290,32 @@ func extractFieldReflection(obj interface{}, fieldPath []string) (interface{}, e
 
 	// Traverse field path
 	for _, fieldName := range fieldPath {
+		for v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return nil, fmt.Errorf("nil pointer in field path")
+			}
+			v = v.Elem()
+		}
+
+		// Look up map keys, such as category scores
+		if v.Kind() == reflect.Map && v.Type().Key().Kind() == reflect.String {
+			value := v.MapIndex(reflect.ValueOf(fieldName).Convert(v.Type().Key()))
+			if !value.IsValid() {
+				return nil, fmt.Errorf("key %q not found", fieldName)
+			}
+			v = value
+			continue
+		}
+
 		if v.Kind() != reflect.Struct {
 			return nil, fmt.Errorf("cannot access field %q on non-struct type %s", fieldName, v.Kind())
 		}
 
-		// Find field (case-insensitive)
+		// Find field (case-insensitive, so sensitive_content matches
+		// SensitiveContent)
+		goName := strings.ReplaceAll(fieldName, "_", "")
 		f := v.FieldByNameFunc(func(name string) bool {
-			return strings.EqualFold(name, fieldName)
+			return strings.EqualFold(name, goName)
 		})
 
 		if !f.IsValid() {
@@ -0,0 +1,62 @@
+package engine
+
+import (
+	"testing"
+
+	"mercator-hq/jupiter/pkg/processing"
+)
+
+func TestExtractContentAnalysisField_NestedMap(t *testing.T) {
+	analysis := &processing.ContentAnalysis{
+		SensitiveContent: &processing.SensitiveContent{
+			Scores: map[string]float64{"violence": 0.75},
+		},
+	}
+
+	value, err := extractContentAnalysisField([]string{"sensitive_content", "scores", "violence"}, analysis)
+	if err != nil {
+		t.Fatalf("extractContentAnalysisField() error = %v", err)
+	}
+	if value != 0.75 {
+		t.Errorf("Expected violence score 0.75, got %v", value)
+	}
+
+	if _, err := extractContentAnalysisField([]string{"sensitive_content", "scores", "hate"}, analysis); err == nil {
+		t.Error("Expected error for missing category score")
+	}
+}
+
+func TestExtractField_Analyzers(t *testing.T) {
+	evalCtx := &EvaluationContext{
+		Request: &processing.EnrichedRequest{
+			Analyzers: map[string]processing.AnalyzerFields{
+				"toxicity": {"score": 0.8, "labels": []interface{}{"insult"}},
+			},
+		},
+		Response: &processing.EnrichedResponse{
+			Analyzers: map[string]processing.AnalyzerFields{
+				"toxicity": {"toxic": true},
+			},
+		},
+	}
+
+	value, err := extractField("request.analyzers.toxicity.score", evalCtx)
+	if err != nil {
+		t.Fatalf("extractField() error = %v", err)
+	}
+	if value != 0.8 {
+		t.Errorf("Expected score 0.8, got %v", value)
+	}
+
+	value, err = extractField("response.analyzers.toxicity.toxic", evalCtx)
+	if err != nil {
+		t.Fatalf("extractField() error = %v", err)
+	}
+	if value != true {
+		t.Errorf("Expected toxic true, got %v", value)
+	}
+
+	if _, err := extractField("request.analyzers.pii.score", evalCtx); err == nil {
+		t.Error("Expected error for missing analyzer")
+	}
+}
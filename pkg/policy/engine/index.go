@@ -0,0 +1,203 @@
+package engine
+
+import (
+	"sort"
+
+	"mercator-hq/jupiter/pkg/mpl/ast"
+)
+
+// indexedRule is a single enabled rule together with the policy that owns it.
+type indexedRule struct {
+	policy *ast.Policy
+	rule   *ast.Rule
+}
+
+// ruleIndex narrows the set of rules evaluated for a request.
+//
+// Rules whose conditions require a field to equal one of a fixed set of string
+// literals (for example request.model == "gpt-4" at the top level or inside an
+// "all" block) are bucketed by field and value. At evaluation time only the
+// buckets matching the actual field values are considered, together with all
+// rules that could not be indexed. Candidates are always returned in the
+// original priority order, so evaluation semantics are unchanged.
+type ruleIndex struct {
+	// rules contains all enabled rules in evaluation (priority) order.
+	rules []indexedRule
+
+	// keyed maps field path -> literal value -> positions in rules.
+	keyed map[string]map[string][]int
+
+	// unkeyed contains positions of rules without an indexable constraint.
+	unkeyed []int
+}
+
+// newRuleIndex builds a rule index over policies that are already sorted by priority.
+func newRuleIndex(policies []*ast.Policy) *ruleIndex {
+	idx := &ruleIndex{
+		keyed: make(map[string]map[string][]int),
+	}
+
+	for _, policy := range policies {
+		for _, rule := range policy.EnabledRules() {
+			pos := len(idx.rules)
+			idx.rules = append(idx.rules, indexedRule{policy: policy, rule: rule})
+
+			field, values, ok := indexKey(rule.Conditions)
+			if !ok {
+				idx.unkeyed = append(idx.unkeyed, pos)
+				continue
+			}
+
+			buckets, exists := idx.keyed[field]
+			if !exists {
+				buckets = make(map[string][]int)
+				idx.keyed[field] = buckets
+			}
+			for _, value := range values {
+				buckets[value] = append(buckets[value], pos)
+			}
+		}
+	}
+
+	return idx
+}
+
+// candidates returns the rules that can plausibly match the evaluation context,
+// in evaluation order.
+//
+// If an indexed field cannot be extracted or is not a string, every rule keyed
+// on that field is kept so that fail-safe handling of missing fields still applies.
+func (idx *ruleIndex) candidates(evalCtx *EvaluationContext) []indexedRule {
+	if len(idx.keyed) == 0 {
+		return idx.rules
+	}
+
+	selected := make([]bool, len(idx.rules))
+	for _, pos := range idx.unkeyed {
+		selected[pos] = true
+	}
+
+	for field, buckets := range idx.keyed {
+		actual, err := extractField(field, evalCtx)
+		value, isString := actual.(string)
+		if err != nil || !isString {
+			for _, positions := range buckets {
+				for _, pos := range positions {
+					selected[pos] = true
+				}
+			}
+			continue
+		}
+
+		for _, pos := range buckets[value] {
+			selected[pos] = true
+		}
+	}
+
+	result := make([]indexedRule, 0, len(idx.rules))
+	for pos, ok := range selected {
+		if ok {
+			result = append(result, idx.rules[pos])
+		}
+	}
+	return result
+}
+
+// size returns the total number of indexed rules.
+func (idx *ruleIndex) size() int {
+	return len(idx.rules)
+}
+
+// indexKey extracts an indexable constraint from a rule condition.
+// It returns the field path and the set of string values the field must equal
+// for the condition to match. Only constraints that are required for a match
+// are considered, so rules are never excluded incorrectly.
+func indexKey(condition *ast.ConditionNode) (string, []string, bool) {
+	if condition == nil {
+		return "", nil, false
+	}
+
+	switch condition.Type {
+	case ast.ConditionTypeSimple:
+		return simpleIndexKey(condition)
+
+	case ast.ConditionTypeAll:
+		// Any required child constraint also constrains the parent
+		for _, child := range condition.Children {
+			if field, values, ok := indexKey(child); ok {
+				return field, values, true
+			}
+		}
+		return "", nil, false
+
+	case ast.ConditionTypeAny:
+		// Indexable only if every branch constrains the same field
+		var field string
+		var values []string
+		for i, child := range condition.Children {
+			childField, childValues, ok := indexKey(child)
+			if !ok || (i > 0 && childField != field) {
+				return "", nil, false
+			}
+			field = childField
+			values = append(values, childValues...)
+		}
+		if len(values) == 0 {
+			return "", nil, false
+		}
+		return field, dedupeStrings(values), true
+
+	default:
+		// Negations and functions cannot be indexed safely
+		return "", nil, false
+	}
+}
+
+// simpleIndexKey extracts an index key from a simple "==" or "in" condition
+// with string literal values.
+func simpleIndexKey(condition *ast.ConditionNode) (string, []string, bool) {
+	if condition.Value == nil || condition.Value.IsVariable() {
+		return "", nil, false
+	}
+
+	switch condition.Operator {
+	case ast.OperatorEqual:
+		value, ok := condition.Value.Value.(string)
+		if !ok {
+			return "", nil, false
+		}
+		return condition.Field, []string{value}, true
+
+	case ast.OperatorIn:
+		elems, ok := condition.Value.Value.([]interface{})
+		if !ok || len(elems) == 0 {
+			return "", nil, false
+		}
+		values := make([]string, 0, len(elems))
+		for _, elem := range elems {
+			value, ok := elem.(string)
+			if !ok {
+				return "", nil, false
+			}
+			values = append(values, value)
+		}
+		return condition.Field, dedupeStrings(values), true
+
+	default:
+		return "", nil, false
+	}
+}
+
+// dedupeStrings returns the sorted unique values of s.
+func dedupeStrings(s []string) []string {
+	seen := make(map[string]bool, len(s))
+	result := make([]string, 0, len(s))
+	for _, v := range s {
+		if !seen[v] {
+			seen[v] = true
+			result = append(result, v)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
@@ -0,0 +1,265 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"mercator-hq/jupiter/pkg/mpl/ast"
+	"mercator-hq/jupiter/pkg/processing"
+	"mercator-hq/jupiter/pkg/proxy/types"
+)
+
+// TestIndexKey tests extraction of indexable constraints from conditions.
+func TestIndexKey(t *testing.T) {
+	tests := []struct {
+		name       string
+		condition  *ast.ConditionNode
+		wantField  string
+		wantValues []string
+		wantOK     bool
+	}{
+		{
+			name:       "equal string",
+			condition:  createStringCondition("request.model", ast.OperatorEqual, "gpt-4"),
+			wantField:  "request.model",
+			wantValues: []string{"gpt-4"},
+			wantOK:     true,
+		},
+		{
+			name: "in string list",
+			condition: &ast.ConditionNode{
+				Type:     ast.ConditionTypeSimple,
+				Field:    "request.model",
+				Operator: ast.OperatorIn,
+				Value: &ast.ValueNode{
+					Type:  ast.ValueTypeArray,
+					Value: []interface{}{"gpt-4", "gpt-3.5-turbo", "gpt-4"},
+				},
+			},
+			wantField:  "request.model",
+			wantValues: []string{"gpt-3.5-turbo", "gpt-4"},
+			wantOK:     true,
+		},
+		{
+			name: "all with one indexable child",
+			condition: &ast.ConditionNode{
+				Type: ast.ConditionTypeAll,
+				Children: []*ast.ConditionNode{
+					createSimpleCondition("request.tokens", ast.OperatorGreaterThan, float64(100)),
+					createStringCondition("request.model", ast.OperatorEqual, "gpt-4"),
+				},
+			},
+			wantField:  "request.model",
+			wantValues: []string{"gpt-4"},
+			wantOK:     true,
+		},
+		{
+			name: "any over same field",
+			condition: &ast.ConditionNode{
+				Type: ast.ConditionTypeAny,
+				Children: []*ast.ConditionNode{
+					createStringCondition("request.model", ast.OperatorEqual, "gpt-4"),
+					createStringCondition("request.model", ast.OperatorEqual, "claude-3-opus"),
+				},
+			},
+			wantField:  "request.model",
+			wantValues: []string{"claude-3-opus", "gpt-4"},
+			wantOK:     true,
+		},
+		{
+			name: "any over different fields",
+			condition: &ast.ConditionNode{
+				Type: ast.ConditionTypeAny,
+				Children: []*ast.ConditionNode{
+					createStringCondition("request.model", ast.OperatorEqual, "gpt-4"),
+					createStringCondition("request.model_family", ast.OperatorEqual, "claude"),
+				},
+			},
+			wantOK: false,
+		},
+		{
+			name: "not is never indexed",
+			condition: &ast.ConditionNode{
+				Type: ast.ConditionTypeNot,
+				Children: []*ast.ConditionNode{
+					createStringCondition("request.model", ast.OperatorEqual, "gpt-4"),
+				},
+			},
+			wantOK: false,
+		},
+		{
+			name:      "numeric equality",
+			condition: createSimpleCondition("request.tokens", ast.OperatorEqual, float64(100)),
+			wantOK:    false,
+		},
+		{
+			name:      "no condition",
+			condition: nil,
+			wantOK:    false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field, values, ok := indexKey(tt.condition)
+			if ok != tt.wantOK {
+				t.Fatalf("indexKey() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if field != tt.wantField {
+				t.Errorf("field = %q, want %q", field, tt.wantField)
+			}
+			if len(values) != len(tt.wantValues) {
+				t.Fatalf("values = %v, want %v", values, tt.wantValues)
+			}
+			for i := range values {
+				if values[i] != tt.wantValues[i] {
+					t.Errorf("values[%d] = %q, want %q", i, values[i], tt.wantValues[i])
+				}
+			}
+		})
+	}
+}
+
+// TestRuleIndex_Candidates tests that only plausibly matching rules are selected.
+func TestRuleIndex_Candidates(t *testing.T) {
+	policy := &ast.Policy{
+		Name: "models",
+		Rules: []*ast.Rule{
+			createIndexTestRule("gpt4-rule", createStringCondition("request.model", ast.OperatorEqual, "gpt-4")),
+			createIndexTestRule("claude-rule", createStringCondition("request.model", ast.OperatorEqual, "claude-3-opus")),
+			createIndexTestRule("tokens-rule", createSimpleCondition("request.tokens", ast.OperatorGreaterThan, float64(10))),
+		},
+	}
+
+	idx := newRuleIndex([]*ast.Policy{policy})
+	if idx.size() != 3 {
+		t.Fatalf("size() = %d, want 3", idx.size())
+	}
+
+	evalCtx := createTestEvalContext(100)
+	got := ruleNames(idx.candidates(evalCtx))
+	want := []string{"gpt4-rule", "tokens-rule"}
+	if !equalStrings(got, want) {
+		t.Errorf("candidates = %v, want %v", got, want)
+	}
+
+	// Missing field keeps every rule keyed on it
+	evalCtx.Request.OriginalRequest = nil
+	got = ruleNames(idx.candidates(evalCtx))
+	want = []string{"gpt4-rule", "claude-rule", "tokens-rule"}
+	if !equalStrings(got, want) {
+		t.Errorf("candidates with missing field = %v, want %v", got, want)
+	}
+}
+
+// TestEngine_RuleIndexPreservesDecision tests that indexing does not change the decision.
+func TestEngine_RuleIndexPreservesDecision(t *testing.T) {
+	policies := []*ast.Policy{
+		{
+			Name: "block-models",
+			Rules: []*ast.Rule{
+				createIndexTestRule("block-claude", createStringCondition("request.model", ast.OperatorEqual, "claude-3-opus"),
+					&ast.Action{Type: ast.ActionTypeDeny, Parameters: map[string]*ast.ValueNode{
+						"message": {Type: ast.ValueTypeString, Value: "claude blocked"},
+					}}),
+				createIndexTestRule("block-gpt4", createStringCondition("request.model", ast.OperatorEqual, "gpt-4"),
+					&ast.Action{Type: ast.ActionTypeDeny, Parameters: map[string]*ast.ValueNode{
+						"message": {Type: ast.ValueTypeString, Value: "gpt-4 blocked"},
+					}}),
+			},
+		},
+	}
+
+	for _, indexed := range []bool{true, false} {
+		cfg := DefaultEngineConfig().WithRuleIndex(indexed)
+		eng, err := NewInterpreterEngine(cfg, &staticSource{policies: policies}, slog.Default())
+		if err != nil {
+			t.Fatalf("failed to create engine: %v", err)
+		}
+
+		decision, err := eng.EvaluateRequest(context.Background(), &processing.EnrichedRequest{
+			RequestID:       "idx-1",
+			OriginalRequest: &types.ChatCompletionRequest{Model: "gpt-4"},
+		})
+		eng.Close()
+		if err != nil {
+			t.Fatalf("evaluation failed: %v", err)
+		}
+
+		if decision.Action != ActionBlock {
+			t.Errorf("indexed=%v: action = %v, want %v", indexed, decision.Action, ActionBlock)
+		}
+		if decision.BlockReason != "gpt-4 blocked" {
+			t.Errorf("indexed=%v: reason = %q, want %q", indexed, decision.BlockReason, "gpt-4 blocked")
+		}
+
+		wantEvaluated := 2
+		if indexed {
+			wantEvaluated = 1
+		}
+		if len(decision.MatchedRules) != wantEvaluated {
+			t.Errorf("indexed=%v: evaluated %d rules, want %d", indexed, len(decision.MatchedRules), wantEvaluated)
+		}
+	}
+}
+
+// staticSource is a minimal PolicySource for in-package tests.
+type staticSource struct {
+	policies []*ast.Policy
+}
+
+func (s *staticSource) LoadPolicies(ctx context.Context) ([]*ast.Policy, error) {
+	policies := make([]*ast.Policy, len(s.policies))
+	copy(policies, s.policies)
+	return policies, nil
+}
+
+func (s *staticSource) Watch(ctx context.Context) (<-chan PolicyEvent, error) {
+	return make(chan PolicyEvent), nil
+}
+
+func createStringCondition(field string, operator ast.Operator, value string) *ast.ConditionNode {
+	return &ast.ConditionNode{
+		Type:     ast.ConditionTypeSimple,
+		Field:    field,
+		Operator: operator,
+		Value: &ast.ValueNode{
+			Type:  ast.ValueTypeString,
+			Value: value,
+		},
+	}
+}
+
+func createIndexTestRule(name string, condition *ast.ConditionNode, actions ...*ast.Action) *ast.Rule {
+	return &ast.Rule{
+		Name:       name,
+		Enabled:    true,
+		Conditions: condition,
+		Actions:    actions,
+		Priority:   1,
+	}
+}
+
+func ruleNames(rules []indexedRule) []string {
+	names := make([]string, len(rules))
+	for i, r := range rules {
+		names[i] = r.rule.Name
+	}
+	return names
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -31,12 +31,40 @@ func NewDefaultMatcher(logger *slog.Logger, config *EngineConfig) *DefaultMatche
 	}
 }
 
+// failSafeModeKey is the context key for a per-policy fail-safe override.
+type failSafeModeKey struct{}
+
+// withFailSafeMode returns a context that overrides the matcher's fail-safe
+// mode for missing fields.
+func withFailSafeMode(ctx context.Context, mode FailSafeMode) context.Context {
+	return context.WithValue(ctx, failSafeModeKey{}, mode)
+}
+
 // Match evaluates a condition node and returns whether it matched.
+// When the context captures conditions, the outcome of the node and of every
+// child evaluated under it is recorded.
 func (m *DefaultMatcher) Match(ctx context.Context, condition *ast.ConditionNode, evalCtx *EvaluationContext) (bool, error) {
 	if condition == nil {
 		return true, nil // No condition means always match
 	}
 
+	parent, capturing := capturedCondition(ctx)
+	if !capturing {
+		return m.match(ctx, condition, evalCtx)
+	}
+
+	exp := newConditionExplanation(condition)
+	parent.Children = append(parent.Children, exp)
+	matched, err := m.match(withConditionCapture(ctx, exp), condition, evalCtx)
+	exp.Matched = matched
+	if err != nil {
+		exp.Error = err.Error()
+	}
+	return matched, err
+}
+
+// match dispatches a condition node to its type's matcher.
+func (m *DefaultMatcher) match(ctx context.Context, condition *ast.ConditionNode, evalCtx *EvaluationContext) (bool, error) {
 	switch condition.Type {
 	case ast.ConditionTypeSimple:
 		return m.matchSimple(ctx, condition, evalCtx)
@@ -63,15 +91,19 @@ func (m *DefaultMatcher) matchSimple(ctx context.Context, condition *ast.Conditi
 	// Extract field value from evaluation context
 	fieldValue, err := extractField(condition.Field, evalCtx)
 	if err != nil {
-		// Field not found - respect fail-safe mode
+		// Field not found - respect fail-safe mode (the policy's override, if any)
+		mode := m.failSafeMode
+		if override, ok := ctx.Value(failSafeModeKey{}).(FailSafeMode); ok {
+			mode = override
+		}
 		m.logger.Debug("field not found, applying fail-safe mode",
 			"field", condition.Field,
 			"error", err,
-			"fail_safe_mode", m.failSafeMode,
+			"fail_safe_mode", mode,
 		)
 
 		// Apply fail-safe mode for missing fields
-		switch m.failSafeMode {
+		switch mode {
 		case FailOpen:
 			// Treat missing field as match (allow)
 			return true, nil
@@ -86,6 +118,10 @@ func (m *DefaultMatcher) matchSimple(ctx context.Context, condition *ast.Conditi
 		}
 	}
 
+	if exp, ok := capturedCondition(ctx); ok {
+		exp.ActualValue = fieldValue
+	}
+
 	// Get expected value
 	expectedValue := condition.Value.Value
 
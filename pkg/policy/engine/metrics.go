@@ -0,0 +1,90 @@
+package engine
+
+import (
+	"context"
+	"time"
+)
+
+// MetricsRecorder receives per-rule and per-policy evaluation measurements.
+// It is implemented by the telemetry metrics collector; the engine depends
+// only on this interface so it does not import Prometheus.
+type MetricsRecorder interface {
+	// RecordRuleEvaluation records that a rule was evaluated and whether its
+	// conditions matched.
+	RecordRuleEvaluation(policyID, ruleID string, matched bool, duration time.Duration)
+
+	// RecordActionExecution records the execution of a single rule action.
+	RecordActionExecution(policyID, ruleID, actionType string, success bool)
+
+	// RecordPolicyDuration records the time spent evaluating a policy's rules.
+	RecordPolicyDuration(policyID string, duration time.Duration)
+}
+
+// TenantMetricsRecorder is a MetricsRecorder that also attributes rule
+// evaluations and action executions to the tenant and team of the request,
+// as set with ContextWithMetricsTenant.
+type TenantMetricsRecorder interface {
+	MetricsRecorder
+
+	// RecordTenantRuleEvaluation records a rule evaluation for a request of
+	// tenant and team.
+	RecordTenantRuleEvaluation(tenant, team, policyID, ruleID string, matched bool, duration time.Duration)
+
+	// RecordTenantActionExecution records an action execution for a request
+	// of tenant and team.
+	RecordTenantActionExecution(tenant, team, policyID, ruleID, actionType string, success bool)
+}
+
+// metricsTenantKey is the context key of the tenant and team evaluations
+// are attributed to in metrics.
+type metricsTenantKey struct{}
+
+// metricsTenant is the tenant and team evaluations are attributed to.
+type metricsTenant struct {
+	tenant string
+	team   string
+}
+
+// ContextWithMetricsTenant returns a context whose evaluations are reported
+// to a TenantMetricsRecorder with tenant and team.
+func ContextWithMetricsTenant(ctx context.Context, tenant, team string) context.Context {
+	return context.WithValue(ctx, metricsTenantKey{}, metricsTenant{tenant: tenant, team: team})
+}
+
+// SetMetricsRecorder sets the recorder used for per-rule evaluation metrics.
+// Passing nil disables metrics recording.
+func (e *InterpreterEngine) SetMetricsRecorder(recorder MetricsRecorder) {
+	e.hooksMu.Lock()
+	defer e.hooksMu.Unlock()
+	e.metrics = recorder
+}
+
+// metricsRecorder returns the current metrics recorder, or nil if none is set.
+func (e *InterpreterEngine) metricsRecorder() MetricsRecorder {
+	e.hooksMu.RLock()
+	defer e.hooksMu.RUnlock()
+	return e.metrics
+}
+
+// recordRuleMetrics reports every evaluated rule and executed action,
+// attributed to the tenant and team of ctx if recorder supports it.
+func (e *InterpreterEngine) recordRuleMetrics(ctx context.Context, recorder MetricsRecorder, evalCtx *EvaluationContext) {
+	tenant, ok := ctx.Value(metricsTenantKey{}).(metricsTenant)
+	tenantRecorder, _ := recorder.(TenantMetricsRecorder)
+	if !ok || tenantRecorder == nil {
+		for _, rule := range evalCtx.MatchedRules {
+			recorder.RecordRuleEvaluation(rule.PolicyID, rule.RuleID, rule.ConditionResult, rule.EvaluationTime)
+			for _, action := range rule.ActionsExecuted {
+				recorder.RecordActionExecution(rule.PolicyID, rule.RuleID, string(action.ActionType), action.Success)
+			}
+		}
+		return
+	}
+
+	for _, rule := range evalCtx.MatchedRules {
+		tenantRecorder.RecordTenantRuleEvaluation(tenant.tenant, tenant.team, rule.PolicyID, rule.RuleID, rule.ConditionResult, rule.EvaluationTime)
+		for _, action := range rule.ActionsExecuted {
+			tenantRecorder.RecordTenantActionExecution(tenant.tenant, tenant.team, rule.PolicyID, rule.RuleID, string(action.ActionType), action.Success)
+		}
+	}
+}
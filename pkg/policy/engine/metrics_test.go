@@ -0,0 +1,89 @@
+package engine
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/mpl/ast"
+	"mercator-hq/jupiter/pkg/processing"
+	"mercator-hq/jupiter/pkg/proxy/types"
+)
+
+// tenantRecorder records the tenant and team of rule evaluations.
+type tenantRecorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (r *tenantRecorder) record(call string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+}
+
+func (r *tenantRecorder) RecordRuleEvaluation(policyID, ruleID string, matched bool, duration time.Duration) {
+	r.record("rule:" + ruleID)
+}
+
+func (r *tenantRecorder) RecordActionExecution(policyID, ruleID, actionType string, success bool) {
+	r.record("action:" + actionType)
+}
+
+func (r *tenantRecorder) RecordPolicyDuration(policyID string, duration time.Duration) {}
+
+func (r *tenantRecorder) RecordTenantRuleEvaluation(tenant, team, policyID, ruleID string, matched bool, duration time.Duration) {
+	r.record(tenant + "/" + team + " rule:" + ruleID)
+}
+
+func (r *tenantRecorder) RecordTenantActionExecution(tenant, team, policyID, ruleID, actionType string, success bool) {
+	r.record(tenant + "/" + team + " action:" + actionType)
+}
+
+// TestEngine_TenantMetrics tests that rule metrics are attributed to the
+// tenant and team of the context.
+func TestEngine_TenantMetrics(t *testing.T) {
+	policy := &ast.Policy{
+		Name: "limits",
+		Rules: []*ast.Rule{
+			createIndexTestRule("tag-gpt4",
+				createStringCondition("request.model", ast.OperatorEqual, "gpt-4"),
+				&ast.Action{Type: ast.ActionTypeTag, Parameters: map[string]*ast.ValueNode{}}),
+		},
+	}
+
+	eng, err := NewInterpreterEngine(DefaultEngineConfig(), &staticSource{policies: []*ast.Policy{policy}}, slog.Default())
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	defer eng.Close()
+
+	recorder := &tenantRecorder{}
+	eng.SetMetricsRecorder(recorder)
+
+	req := &processing.EnrichedRequest{
+		RequestID:       "tenant-metrics",
+		OriginalRequest: &types.ChatCompletionRequest{Model: "gpt-4"},
+	}
+	if _, err := eng.EvaluateRequest(context.Background(), req); err != nil {
+		t.Fatalf("evaluation failed: %v", err)
+	}
+	if _, err := eng.EvaluateRequest(ContextWithMetricsTenant(context.Background(), "acme", "search"), req); err != nil {
+		t.Fatalf("evaluation failed: %v", err)
+	}
+
+	want := []string{
+		"rule:tag-gpt4", "action:tag",
+		"acme/search rule:tag-gpt4", "acme/search action:tag",
+	}
+	if len(recorder.calls) != len(want) {
+		t.Fatalf("calls = %v, want %v", recorder.calls, want)
+	}
+	for i := range want {
+		if recorder.calls[i] != want[i] {
+			t.Errorf("call %d = %q, want %q", i, recorder.calls[i], want[i])
+		}
+	}
+}
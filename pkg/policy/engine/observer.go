@@ -0,0 +1,46 @@
+package engine
+
+import (
+	"context"
+)
+
+// DecisionStage identifies the pipeline stage a decision was made in.
+type DecisionStage string
+
+const (
+	// StageRequest is the pre-request pipeline (EvaluateRequest).
+	StageRequest DecisionStage = "request"
+
+	// StageResponse is the post-response pipeline (EvaluateResponse).
+	StageResponse DecisionStage = "response"
+)
+
+// DecisionObserver is notified of every policy decision made by the engine.
+//
+// ObserveDecision is called synchronously on the evaluation path, so
+// implementations must return quickly and must not modify the evaluation
+// context or decision. Slow work such as network delivery should be queued.
+type DecisionObserver interface {
+	ObserveDecision(ctx context.Context, stage DecisionStage, evalCtx *EvaluationContext, decision *PolicyDecision)
+}
+
+// AddDecisionObserver registers an observer for policy decisions.
+func (e *InterpreterEngine) AddDecisionObserver(observer DecisionObserver) {
+	if observer == nil {
+		return
+	}
+	e.hooksMu.Lock()
+	defer e.hooksMu.Unlock()
+	e.observers = append(e.observers, observer)
+}
+
+// notifyObservers reports a decision to all registered observers.
+func (e *InterpreterEngine) notifyObservers(ctx context.Context, stage DecisionStage, evalCtx *EvaluationContext, decision *PolicyDecision) {
+	e.hooksMu.RLock()
+	observers := e.observers
+	e.hooksMu.RUnlock()
+
+	for _, observer := range observers {
+		observer.ObserveDecision(ctx, stage, evalCtx, decision)
+	}
+}
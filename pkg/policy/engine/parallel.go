@@ -0,0 +1,138 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"mercator-hq/jupiter/pkg/mpl/ast"
+)
+
+// conditionOutcome is the result of evaluating a single rule's conditions.
+type conditionOutcome struct {
+	// matched indicates whether the conditions matched.
+	matched bool
+
+	// err is any error returned by the matcher.
+	err error
+
+	// timedOut indicates that the rule timeout expired during matching.
+	timedOut bool
+
+	// duration is the time spent matching.
+	duration time.Duration
+
+	// condition explains the rule's root condition. It is only captured when
+	// the evaluation is explained or traced.
+	condition *ConditionExplanation
+}
+
+// matchConditions evaluates a rule's conditions under the rule timeout,
+// applying the policy's fail-safe override to missing fields.
+func (e *InterpreterEngine) matchConditions(ctx context.Context, policy *ast.Policy, rule *ast.Rule, evalCtx *EvaluationContext) conditionOutcome {
+	start := time.Now()
+
+	ruleCtx, cancel := context.WithTimeout(ctx, e.config.RuleTimeout)
+	defer cancel()
+	if policy.FailSafe != "" {
+		ruleCtx = withFailSafeMode(ruleCtx, FailSafeMode(policy.FailSafe))
+	}
+
+	var captured *ConditionExplanation
+	if capture, _ := ctx.Value(captureConditionsKey{}).(bool); capture {
+		captured = &ConditionExplanation{}
+		ruleCtx = withConditionCapture(ruleCtx, captured)
+	}
+
+	matched, err := e.matcher.Match(ruleCtx, rule.Conditions, evalCtx)
+	outcome := conditionOutcome{
+		matched:  matched,
+		err:      err,
+		duration: time.Since(start),
+	}
+	if captured != nil && len(captured.Children) > 0 {
+		outcome.condition = captured.Children[0]
+	}
+
+	if err != nil {
+		select {
+		case <-ruleCtx.Done():
+			outcome.timedOut = true
+		default:
+		}
+	}
+
+	return outcome
+}
+
+// parallelMatches matches rule conditions concurrently, one batch of
+// ParallelWorkers rules at a time.
+//
+// Condition matching only reads from the evaluation context, so rules in a
+// batch can be matched concurrently. Actions are still executed sequentially
+// in priority order by the caller, which keeps the merged decision identical
+// to sequential evaluation. A batch is only matched once evaluation reaches
+// it, so a request that short-circuits on an early rule does not pay for
+// matching the rest.
+type parallelMatches struct {
+	engine   *InterpreterEngine
+	ctx      context.Context
+	rules    []indexedRule
+	evalCtx  *EvaluationContext
+	outcomes []*conditionOutcome
+
+	// matched is the number of leading rules whose batch has been matched.
+	matched int
+}
+
+// newParallelMatches prepares lazy concurrent matching for rules.
+func (e *InterpreterEngine) newParallelMatches(ctx context.Context, rules []indexedRule, evalCtx *EvaluationContext) *parallelMatches {
+	return &parallelMatches{
+		engine:   e,
+		ctx:      ctx,
+		rules:    rules,
+		evalCtx:  evalCtx,
+		outcomes: make([]*conditionOutcome, len(rules)),
+	}
+}
+
+// outcome returns the condition result for the rule at pos, matching the
+// batch containing it first if needed. It returns nil for rules without
+// conditions.
+func (p *parallelMatches) outcome(pos int) *conditionOutcome {
+	for pos >= p.matched {
+		p.matchBatch()
+	}
+	return p.outcomes[pos]
+}
+
+// matchBatch matches the next ParallelWorkers rules concurrently.
+func (p *parallelMatches) matchBatch() {
+	start := p.matched
+	end := start + p.engine.config.ParallelWorkers
+	if end > len(p.rules) {
+		end = len(p.rules)
+	}
+
+	var wg sync.WaitGroup
+	for pos := start; pos < end; pos++ {
+		candidate := p.rules[pos]
+		if !candidate.rule.HasConditions() {
+			continue
+		}
+		wg.Add(1)
+		go func(pos int) {
+			defer wg.Done()
+			outcome := p.engine.matchConditions(p.ctx, candidate.policy, candidate.rule, p.evalCtx)
+			p.outcomes[pos] = &outcome
+		}(pos)
+	}
+	wg.Wait()
+
+	p.matched = end
+}
+
+// useParallelEvaluation returns true if conditions should be matched concurrently.
+func (e *InterpreterEngine) useParallelEvaluation(ruleCount int) bool {
+	return e.config.ParallelWorkers > 1 && ruleCount > 1
+}
@@ -0,0 +1,132 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"mercator-hq/jupiter/pkg/mpl/ast"
+	"mercator-hq/jupiter/pkg/processing"
+	"mercator-hq/jupiter/pkg/proxy/types"
+)
+
+// TestEngine_ParallelEvaluationMatchesSequential tests that parallel condition
+// matching produces the same decision as sequential evaluation.
+func TestEngine_ParallelEvaluationMatchesSequential(t *testing.T) {
+	var rules []*ast.Rule
+	for i := 0; i < 20; i++ {
+		rules = append(rules, createIndexTestRule(
+			fmt.Sprintf("tag-%02d", i),
+			createStringCondition("request.model", ast.OperatorMatches, "^gpt-[0-9]+$"),
+			&ast.Action{Type: ast.ActionTypeTag, Parameters: map[string]*ast.ValueNode{
+				"key":   {Type: ast.ValueTypeString, Value: "last"},
+				"value": {Type: ast.ValueTypeString, Value: fmt.Sprintf("tag-%02d", i)},
+			}},
+		))
+	}
+	rules = append(rules, createIndexTestRule(
+		"tag-99-block",
+		createStringCondition("request.model", ast.OperatorStartsWith, "gpt"),
+		&ast.Action{Type: ast.ActionTypeDeny, Parameters: map[string]*ast.ValueNode{
+			"message": {Type: ast.ValueTypeString, Value: "blocked"},
+		}},
+	))
+	policies := []*ast.Policy{{Name: "many-rules", Rules: rules}}
+
+	evaluate := func(workers int) *PolicyDecision {
+		cfg := DefaultEngineConfig().WithParallelWorkers(workers)
+		eng, err := NewInterpreterEngine(cfg, &staticSource{policies: policies}, slog.Default())
+		if err != nil {
+			t.Fatalf("failed to create engine: %v", err)
+		}
+		defer eng.Close()
+
+		decision, err := eng.EvaluateRequest(context.Background(), &processing.EnrichedRequest{
+			RequestID:       "parallel-1",
+			OriginalRequest: &types.ChatCompletionRequest{Model: "gpt-4"},
+		})
+		if err != nil {
+			t.Fatalf("evaluation failed: %v", err)
+		}
+		return decision
+	}
+
+	sequential := evaluate(0)
+	parallel := evaluate(4)
+
+	if parallel.Action != sequential.Action {
+		t.Errorf("action = %v, want %v", parallel.Action, sequential.Action)
+	}
+	if parallel.BlockReason != sequential.BlockReason {
+		t.Errorf("block reason = %q, want %q", parallel.BlockReason, sequential.BlockReason)
+	}
+	if parallel.Tags["last"] != sequential.Tags["last"] {
+		t.Errorf("tag = %q, want %q", parallel.Tags["last"], sequential.Tags["last"])
+	}
+	if len(parallel.MatchedRules) != len(sequential.MatchedRules) {
+		t.Fatalf("matched rules = %d, want %d", len(parallel.MatchedRules), len(sequential.MatchedRules))
+	}
+	for i := range parallel.MatchedRules {
+		if parallel.MatchedRules[i].RuleName != sequential.MatchedRules[i].RuleName {
+			t.Errorf("rule[%d] = %q, want %q", i, parallel.MatchedRules[i].RuleName, sequential.MatchedRules[i].RuleName)
+		}
+	}
+}
+
+// TestParallelMatches_MatchesLazily tests that later batches are only matched
+// once evaluation reaches them.
+func TestParallelMatches_MatchesLazily(t *testing.T) {
+	var rules []indexedRule
+	policy := &ast.Policy{Name: "lazy"}
+	for i := 0; i < 10; i++ {
+		rule := createIndexTestRule(
+			fmt.Sprintf("rule-%d", i),
+			createStringCondition("request.model", ast.OperatorEqual, "gpt-4"),
+			&ast.Action{Type: ast.ActionTypeAllow},
+		)
+		rules = append(rules, indexedRule{policy: policy, rule: rule})
+	}
+
+	cfg := DefaultEngineConfig().WithParallelWorkers(4)
+	eng, err := NewInterpreterEngine(cfg, &staticSource{}, slog.Default())
+	if err != nil {
+		t.Fatalf("failed to create engine: %v", err)
+	}
+	defer eng.Close()
+
+	evalCtx := &EvaluationContext{
+		RequestID: "lazy-1",
+		Request: &processing.EnrichedRequest{
+			RequestID:       "lazy-1",
+			OriginalRequest: &types.ChatCompletionRequest{Model: "gpt-4"},
+		},
+		Tags: make(map[string]string),
+	}
+	matches := eng.newParallelMatches(context.Background(), rules, evalCtx)
+
+	if outcome := matches.outcome(0); outcome == nil || !outcome.matched {
+		t.Fatalf("outcome(0) = %+v, want matched", outcome)
+	}
+	if matches.matched != 4 {
+		t.Errorf("matched rules = %d, want first batch of 4", matches.matched)
+	}
+	if matches.outcomes[4] != nil {
+		t.Error("rule in second batch matched before evaluation reached it")
+	}
+
+	if outcome := matches.outcome(9); outcome == nil || !outcome.matched {
+		t.Fatalf("outcome(9) = %+v, want matched", outcome)
+	}
+	if matches.matched != 10 {
+		t.Errorf("matched rules = %d, want 10", matches.matched)
+	}
+}
+
+// TestEngineConfig_ParallelWorkersValidation tests validation of the worker count.
+func TestEngineConfig_ParallelWorkersValidation(t *testing.T) {
+	cfg := DefaultEngineConfig().WithParallelWorkers(-1)
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected error for negative parallel workers")
+	}
+}
@@ -15,6 +15,10 @@ func ApplyRedaction(content string, redaction Redaction) (string, error) {
 		return applyRemoveRedaction(content, redaction)
 	case "replace":
 		return applyReplaceRedaction(content, redaction)
+	case "placeholder":
+		// Placeholders replace the PII found by content analysis, which
+		// the request processor applies with RedactRequest
+		return content, fmt.Errorf("placeholder redactions are applied by the request processor")
 	default:
 		return content, fmt.Errorf("unknown redaction strategy: %q", redaction.Strategy)
 	}
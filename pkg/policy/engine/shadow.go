@@ -0,0 +1,375 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"mercator-hq/jupiter/pkg/processing"
+)
+
+// ShadowConfig contains configuration for shadow (preview) evaluation.
+type ShadowConfig struct {
+	// QueueSize is the number of pending shadow evaluations. When the queue
+	// is full, new evaluations are skipped so production traffic is never slowed.
+	// Default: 1000
+	QueueSize int
+
+	// History is the number of recent divergent decisions kept for the report.
+	// Default: 100
+	History int
+}
+
+// DefaultShadowConfig returns the default shadow evaluation configuration.
+func DefaultShadowConfig() *ShadowConfig {
+	return &ShadowConfig{
+		QueueSize: 1000,
+		History:   100,
+	}
+}
+
+// DecisionSummary is the comparable outcome of a policy decision.
+type DecisionSummary struct {
+	// Action is the final policy action.
+	Action PolicyAction `json:"action"`
+
+	// BlockReason explains why the request was blocked (if blocked).
+	BlockReason string `json:"block_reason,omitempty"`
+
+	// MatchedRules lists the rules whose conditions matched, as "policy/rule".
+	MatchedRules []string `json:"matched_rules,omitempty"`
+
+	// RoutingTarget is the routing target as "provider/model" (if routed).
+	RoutingTarget string `json:"routing_target,omitempty"`
+
+	// Tags are the tags added by policy actions.
+	Tags map[string]string `json:"tags,omitempty"`
+}
+
+// SummarizeDecision extracts the comparable outcome of a decision.
+func SummarizeDecision(decision *PolicyDecision) DecisionSummary {
+	summary := DecisionSummary{
+		Action:      decision.Action,
+		BlockReason: decision.BlockReason,
+	}
+	for _, rule := range decision.MatchedRules {
+		if rule.ConditionResult {
+			summary.MatchedRules = append(summary.MatchedRules, rule.PolicyID+"/"+rule.RuleID)
+		}
+	}
+	sort.Strings(summary.MatchedRules)
+	if decision.RoutingTarget != nil {
+		summary.RoutingTarget = decision.RoutingTarget.Provider + "/" + decision.RoutingTarget.Model
+	}
+	if len(decision.Tags) > 0 {
+		summary.Tags = make(map[string]string, len(decision.Tags))
+		for k, v := range decision.Tags {
+			summary.Tags[k] = v
+		}
+	}
+	return summary
+}
+
+// CompareDecisions returns the names of the fields that differ between two
+// decision summaries ("action", "block_reason", "matched_rules",
+// "routing_target", "tags"). An empty result means the decisions agree.
+func CompareDecisions(primary, shadow DecisionSummary) []string {
+	var diffs []string
+	if primary.Action != shadow.Action {
+		diffs = append(diffs, "action")
+	}
+	if primary.BlockReason != shadow.BlockReason {
+		diffs = append(diffs, "block_reason")
+	}
+	if !reflect.DeepEqual(primary.MatchedRules, shadow.MatchedRules) {
+		diffs = append(diffs, "matched_rules")
+	}
+	if primary.RoutingTarget != shadow.RoutingTarget {
+		diffs = append(diffs, "routing_target")
+	}
+	if len(primary.Tags) != len(shadow.Tags) || (len(primary.Tags) > 0 && !reflect.DeepEqual(primary.Tags, shadow.Tags)) {
+		diffs = append(diffs, "tags")
+	}
+	return diffs
+}
+
+// DecisionDiff records a request where the shadow policies decided differently.
+type DecisionDiff struct {
+	// RequestID is the request that diverged.
+	RequestID string `json:"request_id"`
+
+	// Stage is the pipeline stage ("request" or "response").
+	Stage DecisionStage `json:"stage"`
+
+	// Differences lists the fields that differ.
+	Differences []string `json:"differences"`
+
+	// Primary is the production decision.
+	Primary DecisionSummary `json:"primary"`
+
+	// Shadow is the preview decision.
+	Shadow DecisionSummary `json:"shadow"`
+
+	// Timestamp is when the divergence was detected.
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// ShadowReport summarizes shadow evaluation against production decisions.
+type ShadowReport struct {
+	// Evaluated is the number of decisions evaluated in shadow mode.
+	Evaluated int64 `json:"evaluated"`
+
+	// Divergent is the number of decisions where the shadow policies differed.
+	Divergent int64 `json:"divergent"`
+
+	// ActionChanges counts divergent decisions by "primary->shadow" action.
+	ActionChanges map[string]int64 `json:"action_changes,omitempty"`
+
+	// Errors is the number of shadow evaluations that failed.
+	Errors int64 `json:"errors"`
+
+	// Skipped is the number of decisions skipped because the queue was full.
+	Skipped int64 `json:"skipped"`
+
+	// Recent contains the most recent divergent decisions, newest first.
+	Recent []*DecisionDiff `json:"recent"`
+}
+
+// shadowJob is a pending shadow evaluation.
+type shadowJob struct {
+	stage    DecisionStage
+	request  *processing.EnrichedRequest
+	response *processing.EnrichedResponse
+	primary  DecisionSummary
+}
+
+// ShadowEvaluator evaluates a second (preview) policy set alongside production.
+//
+// It implements DecisionObserver: register it on the production engine with
+// AddDecisionObserver. Every production decision is re-evaluated
+// asynchronously against the shadow engine and divergent decisions are
+// recorded. Shadow decisions never affect requests.
+type ShadowEvaluator struct {
+	shadow Engine
+	config *ShadowConfig
+	jobs   chan shadowJob
+	wg     sync.WaitGroup
+	logger *slog.Logger
+
+	evaluated atomic.Int64
+	errors    atomic.Int64
+	skipped   atomic.Int64
+
+	// mu protects the divergence history and counters below.
+	mu            sync.Mutex
+	divergent     int64
+	actionChanges map[string]int64
+	recent        []*DecisionDiff
+
+	// closeMu guards closed against concurrent ObserveDecision and Close.
+	closeMu sync.RWMutex
+	closed  bool
+}
+
+var _ DecisionObserver = (*ShadowEvaluator)(nil)
+
+// NewShadowEvaluator creates a shadow evaluator for the given preview engine
+// and starts its background worker.
+func NewShadowEvaluator(shadow Engine, config *ShadowConfig, logger *slog.Logger) *ShadowEvaluator {
+	if config == nil {
+		config = DefaultShadowConfig()
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	s := &ShadowEvaluator{
+		shadow:        shadow,
+		config:        config,
+		jobs:          make(chan shadowJob, config.QueueSize),
+		logger:        logger.With("component", "policy.shadow"),
+		actionChanges: make(map[string]int64),
+	}
+
+	s.wg.Add(1)
+	go s.worker()
+
+	return s
+}
+
+// ObserveDecision queues a production decision for shadow evaluation.
+func (s *ShadowEvaluator) ObserveDecision(ctx context.Context, stage DecisionStage, evalCtx *EvaluationContext, decision *PolicyDecision) {
+	job := shadowJob{
+		stage:    stage,
+		request:  evalCtx.Request,
+		response: evalCtx.Response,
+		primary:  SummarizeDecision(decision),
+	}
+
+	s.closeMu.RLock()
+	defer s.closeMu.RUnlock()
+	if s.closed {
+		return
+	}
+
+	select {
+	case s.jobs <- job:
+	default:
+		s.skipped.Add(1)
+	}
+}
+
+// Close stops accepting decisions and waits for queued evaluations to finish.
+// It does not close the shadow engine.
+func (s *ShadowEvaluator) Close() error {
+	s.closeMu.Lock()
+	if !s.closed {
+		s.closed = true
+		close(s.jobs)
+	}
+	s.closeMu.Unlock()
+
+	s.wg.Wait()
+	return nil
+}
+
+// worker evaluates queued decisions against the shadow engine.
+func (s *ShadowEvaluator) worker() {
+	defer s.wg.Done()
+	for job := range s.jobs {
+		s.evaluate(job)
+	}
+}
+
+// evaluate runs a single shadow evaluation and records any divergence.
+func (s *ShadowEvaluator) evaluate(job shadowJob) {
+	ctx := context.Background()
+
+	var (
+		decision  *PolicyDecision
+		err       error
+		requestID string
+	)
+	switch {
+	case job.request != nil:
+		requestID = job.request.RequestID
+		decision, err = s.shadow.EvaluateRequest(ctx, job.request)
+	case job.response != nil:
+		requestID = job.response.RequestID
+		decision, err = s.shadow.EvaluateResponse(ctx, job.response)
+	default:
+		return
+	}
+
+	s.evaluated.Add(1)
+	if err != nil {
+		s.errors.Add(1)
+		s.logger.Warn("shadow evaluation failed", "request_id", requestID, "error", err)
+		return
+	}
+
+	shadow := SummarizeDecision(decision)
+	diffs := CompareDecisions(job.primary, shadow)
+	if len(diffs) == 0 {
+		return
+	}
+
+	s.record(&DecisionDiff{
+		RequestID:   requestID,
+		Stage:       job.stage,
+		Differences: diffs,
+		Primary:     job.primary,
+		Shadow:      shadow,
+		Timestamp:   time.Now(),
+	})
+
+	s.logger.Info("shadow policy decision diverged",
+		"request_id", requestID,
+		"stage", job.stage,
+		"differences", diffs,
+		"primary_action", job.primary.Action,
+		"shadow_action", shadow.Action,
+	)
+}
+
+// record stores a divergent decision, evicting the oldest when full.
+func (s *ShadowEvaluator) record(diff *DecisionDiff) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.divergent++
+	if diff.Primary.Action != diff.Shadow.Action {
+		s.actionChanges[fmt.Sprintf("%s->%s", diff.Primary.Action, diff.Shadow.Action)]++
+	}
+
+	if s.config.History <= 0 {
+		return
+	}
+	if len(s.recent) >= s.config.History {
+		s.recent = s.recent[1:]
+	}
+	s.recent = append(s.recent, diff)
+}
+
+// Report returns a snapshot of the shadow evaluation results.
+func (s *ShadowEvaluator) Report() *ShadowReport {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report := &ShadowReport{
+		Evaluated:     s.evaluated.Load(),
+		Divergent:     s.divergent,
+		Errors:        s.errors.Load(),
+		Skipped:       s.skipped.Load(),
+		ActionChanges: make(map[string]int64, len(s.actionChanges)),
+		Recent:        make([]*DecisionDiff, 0, len(s.recent)),
+	}
+	for k, v := range s.actionChanges {
+		report.ActionChanges[k] = v
+	}
+	for i := len(s.recent) - 1; i >= 0; i-- {
+		report.Recent = append(report.Recent, s.recent[i])
+	}
+	return report
+}
+
+// Reset clears all recorded results, e.g. after the preview policies change.
+func (s *ShadowEvaluator) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evaluated.Store(0)
+	s.errors.Store(0)
+	s.skipped.Store(0)
+	s.divergent = 0
+	s.actionChanges = make(map[string]int64)
+	s.recent = nil
+}
+
+// Handler returns an HTTP handler that serves the shadow evaluation report.
+//
+//	GET    returns the divergence report as JSON
+//	DELETE resets the report
+func (s *ShadowEvaluator) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodDelete:
+			s.Reset()
+		default:
+			w.Header().Set("Allow", "GET, DELETE")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.Report())
+	})
+}
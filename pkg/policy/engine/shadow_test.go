@@ -0,0 +1,175 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mercator-hq/jupiter/pkg/mpl/ast"
+	"mercator-hq/jupiter/pkg/processing"
+	"mercator-hq/jupiter/pkg/proxy/types"
+)
+
+// TestShadowEvaluator_ReportsDivergence tests that a preview policy set that
+// blocks more traffic than production is reported without affecting decisions.
+func TestShadowEvaluator_ReportsDivergence(t *testing.T) {
+	production := []*ast.Policy{
+		{
+			Name: "models",
+			Rules: []*ast.Rule{
+				createIndexTestRule("block-gpt3",
+					createStringCondition("request.model", ast.OperatorEqual, "gpt-3"),
+					&ast.Action{Type: ast.ActionTypeDeny}),
+			},
+		},
+	}
+	preview := []*ast.Policy{
+		{
+			Name: "models",
+			Rules: []*ast.Rule{
+				createIndexTestRule("block-gpt3",
+					createStringCondition("request.model", ast.OperatorEqual, "gpt-3"),
+					&ast.Action{Type: ast.ActionTypeDeny}),
+				createIndexTestRule("block-gpt4",
+					createStringCondition("request.model", ast.OperatorEqual, "gpt-4"),
+					&ast.Action{Type: ast.ActionTypeDeny}),
+			},
+		},
+	}
+
+	primary, err := NewInterpreterEngine(DefaultEngineConfig(), &staticSource{policies: production}, slog.Default())
+	if err != nil {
+		t.Fatalf("failed to create primary engine: %v", err)
+	}
+	defer primary.Close()
+
+	shadowEngine, err := NewInterpreterEngine(DefaultEngineConfig(), &staticSource{policies: preview}, slog.Default())
+	if err != nil {
+		t.Fatalf("failed to create shadow engine: %v", err)
+	}
+	defer shadowEngine.Close()
+
+	shadow := NewShadowEvaluator(shadowEngine, DefaultShadowConfig(), slog.Default())
+	primary.AddDecisionObserver(shadow)
+
+	for _, model := range []string{"gpt-3", "gpt-4", "claude-3"} {
+		decision, err := primary.EvaluateRequest(context.Background(), &processing.EnrichedRequest{
+			RequestID:       "shadow-" + model,
+			OriginalRequest: &types.ChatCompletionRequest{Model: model},
+		})
+		if err != nil {
+			t.Fatalf("evaluation failed: %v", err)
+		}
+		if model == "gpt-4" && decision.Action != ActionAllow {
+			t.Errorf("shadow policies must not affect production decision, got %v", decision.Action)
+		}
+	}
+
+	// Close drains the queue so the report is complete
+	shadow.Close()
+
+	report := shadow.Report()
+	if report.Evaluated != 3 {
+		t.Errorf("Evaluated = %d, want 3", report.Evaluated)
+	}
+	if report.Divergent != 1 {
+		t.Fatalf("Divergent = %d, want 1", report.Divergent)
+	}
+	if report.ActionChanges["allow->block"] != 1 {
+		t.Errorf("ActionChanges = %v, want allow->block: 1", report.ActionChanges)
+	}
+
+	diff := report.Recent[0]
+	if diff.RequestID != "shadow-gpt-4" || diff.Stage != StageRequest {
+		t.Errorf("unexpected diff identity: %+v", diff)
+	}
+	if !equalStrings(diff.Differences, []string{"action", "block_reason", "matched_rules"}) {
+		t.Errorf("Differences = %v", diff.Differences)
+	}
+	if !equalStrings(diff.Shadow.MatchedRules, []string{"models/block-gpt4"}) {
+		t.Errorf("shadow matched rules = %v", diff.Shadow.MatchedRules)
+	}
+
+	// Decisions after Close are ignored
+	primary.EvaluateRequest(context.Background(), &processing.EnrichedRequest{
+		RequestID:       "after-close",
+		OriginalRequest: &types.ChatCompletionRequest{Model: "gpt-4"},
+	})
+	if shadow.Report().Evaluated != 3 {
+		t.Error("expected no evaluations after Close")
+	}
+}
+
+func TestCompareDecisions(t *testing.T) {
+	base := DecisionSummary{
+		Action:       ActionAllow,
+		MatchedRules: []string{"p/a"},
+		Tags:         map[string]string{"team": "ml"},
+	}
+
+	if diffs := CompareDecisions(base, base); len(diffs) != 0 {
+		t.Errorf("identical decisions differ: %v", diffs)
+	}
+
+	other := base
+	other.RoutingTarget = "openai/gpt-4o"
+	other.Tags = map[string]string{"team": "infra"}
+	if diffs := CompareDecisions(base, other); !equalStrings(diffs, []string{"routing_target", "tags"}) {
+		t.Errorf("CompareDecisions() = %v", diffs)
+	}
+
+	// nil and empty tags are equivalent
+	a, b := DecisionSummary{Action: ActionAllow}, DecisionSummary{Action: ActionAllow, Tags: map[string]string{}}
+	if diffs := CompareDecisions(a, b); len(diffs) != 0 {
+		t.Errorf("nil vs empty tags differ: %v", diffs)
+	}
+}
+
+func TestShadowEvaluator_Handler(t *testing.T) {
+	s := NewShadowEvaluator(&staticEngine{}, &ShadowConfig{QueueSize: 1, History: 1}, slog.Default())
+	defer s.Close()
+
+	s.record(&DecisionDiff{RequestID: "a"})
+	s.record(&DecisionDiff{RequestID: "b"})
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/policy/preview", nil))
+
+	var report ShadowReport
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+	if report.Divergent != 2 || len(report.Recent) != 1 || report.Recent[0].RequestID != "b" {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/admin/policy/preview", nil))
+	if s.Report().Divergent != 0 {
+		t.Error("expected DELETE to reset the report")
+	}
+
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/policy/preview", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+// staticEngine is an Engine that always allows.
+type staticEngine struct{}
+
+func (e *staticEngine) EvaluateRequest(ctx context.Context, enriched *processing.EnrichedRequest) (*PolicyDecision, error) {
+	return &PolicyDecision{Action: ActionAllow}, nil
+}
+
+func (e *staticEngine) EvaluateResponse(ctx context.Context, enriched *processing.EnrichedResponse) (*PolicyDecision, error) {
+	return &PolicyDecision{Action: ActionAllow}, nil
+}
+
+func (e *staticEngine) ReloadPolicies(ctx context.Context) error { return nil }
+func (e *staticEngine) GetPolicies() []*ast.Policy               { return nil }
+func (e *staticEngine) Close() error                             { return nil }
@@ -0,0 +1,215 @@
+package source
+
+import (
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"time"
+
+	"mercator-hq/jupiter/pkg/mpl/ast"
+	"mercator-hq/jupiter/pkg/mpl/parser"
+	"mercator-hq/jupiter/pkg/policy/engine"
+)
+
+// DefaultDatabaseTable is the default table holding policy documents.
+const DefaultDatabaseTable = "policy_overrides"
+
+// DefaultDatabasePollInterval is the default interval between change checks.
+const DefaultDatabasePollInterval = 10 * time.Second
+
+// tableNamePattern restricts table names to plain SQL identifiers.
+var tableNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// DatabaseConfig configures a database-backed policy source.
+type DatabaseConfig struct {
+	// Table is the table holding policy documents.
+	// Default: "policy_overrides"
+	Table string
+
+	// PollInterval is how often the table is checked for changes.
+	// Default: 10s
+	PollInterval time.Duration
+}
+
+// DatabaseSource loads policies stored as MPL documents in a SQL table.
+//
+// It is intended for emergency overrides that must take effect without a
+// Git cycle: an operator inserts or enables a row and the change is picked
+// up on the next poll. The table has the schema created by EnsureSchema:
+//
+//	name     TEXT PRIMARY KEY  -- identifier used in logs
+//	document TEXT NOT NULL     -- MPL policy YAML
+//	enabled  INTEGER NOT NULL  -- 0 disables the row
+type DatabaseSource struct {
+	db       *sql.DB
+	table    string
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+// NewDatabaseSource creates a policy source backed by a SQL table.
+func NewDatabaseSource(db *sql.DB, config *DatabaseConfig, logger *slog.Logger) (*DatabaseSource, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database cannot be nil")
+	}
+	if config == nil {
+		config = &DatabaseConfig{}
+	}
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	table := config.Table
+	if table == "" {
+		table = DefaultDatabaseTable
+	}
+	if !tableNamePattern.MatchString(table) {
+		return nil, fmt.Errorf("invalid table name %q", table)
+	}
+
+	interval := config.PollInterval
+	if interval <= 0 {
+		interval = DefaultDatabasePollInterval
+	}
+
+	return &DatabaseSource{
+		db:       db,
+		table:    table,
+		interval: interval,
+		logger:   logger,
+	}, nil
+}
+
+// EnsureSchema creates the policy table if it does not exist.
+func (s *DatabaseSource) EnsureSchema(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		name TEXT PRIMARY KEY,
+		document TEXT NOT NULL,
+		enabled INTEGER NOT NULL DEFAULT 1
+	)`, s.table))
+	if err != nil {
+		return fmt.Errorf("failed to create policy table: %w", err)
+	}
+	return nil
+}
+
+// dbPolicy is a row of the policy table.
+type dbPolicy struct {
+	name     string
+	document string
+}
+
+// rows returns the enabled policy documents ordered by name.
+func (s *DatabaseSource) rows(ctx context.Context) ([]dbPolicy, error) {
+	rows, err := s.db.QueryContext(ctx, fmt.Sprintf(
+		"SELECT name, document FROM %s WHERE enabled != 0 ORDER BY name", s.table))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query policy table: %w", err)
+	}
+	defer rows.Close()
+
+	var result []dbPolicy
+	for rows.Next() {
+		var row dbPolicy
+		if err := rows.Scan(&row.name, &row.document); err != nil {
+			return nil, fmt.Errorf("failed to scan policy row: %w", err)
+		}
+		result = append(result, row)
+	}
+	return result, rows.Err()
+}
+
+// LoadPolicies parses all enabled policy documents. Rows that fail to parse
+// are skipped with a warning, like invalid files in a policy directory.
+func (s *DatabaseSource) LoadPolicies(ctx context.Context) ([]*ast.Policy, error) {
+	rows, err := s.rows(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	p := parser.NewParser()
+	policies := make([]*ast.Policy, 0, len(rows))
+	for _, row := range rows {
+		sourceName := fmt.Sprintf("db:%s/%s", s.table, row.name)
+		policy, err := p.ParseBytes([]byte(row.document), sourceName)
+		if err != nil {
+			s.logger.Warn("failed to parse database policy, skipping",
+				"name", row.name,
+				"error", err,
+			)
+			continue
+		}
+		policy.SourceFile = sourceName
+		policies = append(policies, policy)
+	}
+
+	s.logger.Info("loaded policies from database",
+		"table", s.table,
+		"policy_count", len(policies),
+	)
+
+	return policies, nil
+}
+
+// Watch polls the table and sends a modified event when its enabled
+// contents change. The channel is closed when the context is cancelled.
+func (s *DatabaseSource) Watch(ctx context.Context) (<-chan engine.PolicyEvent, error) {
+	last, err := s.fingerprint(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	eventCh := make(chan engine.PolicyEvent)
+	go func() {
+		defer close(eventCh)
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			current, err := s.fingerprint(ctx)
+			if err != nil {
+				s.logger.Warn("failed to poll policy table", "error", err)
+				continue
+			}
+			if current == last {
+				continue
+			}
+			last = current
+
+			select {
+			case eventCh <- engine.PolicyEvent{Type: engine.PolicyEventModified, Path: "db:" + s.table}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return eventCh, nil
+}
+
+// fingerprint hashes the enabled rows so changes can be detected cheaply.
+func (s *DatabaseSource) fingerprint(ctx context.Context) ([sha256.Size]byte, error) {
+	rows, err := s.rows(ctx)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+
+	h := sha256.New()
+	for _, row := range rows {
+		fmt.Fprintf(h, "%d:%s%d:%s", len(row.name), row.name, len(row.document), row.document)
+	}
+
+	var sum [sha256.Size]byte
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
@@ -1,7 +1,8 @@
 // Package source provides policy sources for the policy engine.
 //
 // A policy source is responsible for loading and watching policies.
-// This package provides file-based and in-memory implementations.
+// This package provides file-based, database-backed, in-memory, and
+// layered implementations.
 //
 // # File Source
 //
@@ -25,6 +26,24 @@
 //	    policies, err := source.LoadPolicies(ctx)
 //	}
 //
+// # Layered Sources
+//
+// MultiSource merges several sources with explicit precedence. When two
+// layers define a policy with the same name, the higher precedence layer
+// wins. Layers with a namespace prefix their policy names and never
+// override other layers:
+//
+//	multi, err := source.NewMultiSource(logger,
+//	    source.Layer{Name: "org", Source: orgRepo},
+//	    source.Layer{Name: "team", Source: teamFiles, Namespace: "team-a", Precedence: 10},
+//	    source.Layer{Name: "emergency", Source: overrides, Precedence: 100},
+//	)
+//
+// # Database Source
+//
+// DatabaseSource loads MPL documents from a SQL table and polls it for
+// changes, so emergency overrides take effect without a Git cycle.
+//
 // # In-Memory Source
 //
 // The in-memory source is useful for testing:
@@ -0,0 +1,194 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+
+	"mercator-hq/jupiter/pkg/mpl/ast"
+	"mercator-hq/jupiter/pkg/policy/engine"
+)
+
+// Layer is a named policy source with a precedence and optional namespace.
+type Layer struct {
+	// Name identifies the layer in logs and provenance (e.g., "org", "emergency").
+	Name string
+
+	// Source provides the layer's policies.
+	Source engine.PolicySource
+
+	// Precedence decides which layer wins when two layers define a policy
+	// with the same name. Higher values win.
+	Precedence int
+
+	// Namespace is prefixed to the names of the layer's policies as
+	// "namespace.name". Policies in different namespaces never override
+	// each other. Empty means the global namespace.
+	Namespace string
+
+	// Optional layers are skipped with a warning when they fail to load
+	// instead of failing the whole load.
+	Optional bool
+}
+
+// MultiSource merges policies from several layered sources.
+//
+// Policies are identified by their (namespaced) name. When several layers
+// define the same policy, the layer with the highest precedence wins and the
+// others are dropped. This lets a high-precedence layer (e.g., emergency
+// overrides from a database) replace a policy from the org-wide repository
+// without going through a Git change.
+type MultiSource struct {
+	layers []Layer
+	logger *slog.Logger
+
+	mu      sync.RWMutex
+	origins map[string]string
+}
+
+// NewMultiSource creates a policy source that merges the given layers.
+func NewMultiSource(logger *slog.Logger, layers ...Layer) (*MultiSource, error) {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	seen := make(map[string]bool, len(layers))
+	for i, layer := range layers {
+		if layer.Name == "" {
+			return nil, fmt.Errorf("layer %d: name is required", i)
+		}
+		if seen[layer.Name] {
+			return nil, fmt.Errorf("duplicate layer name %q", layer.Name)
+		}
+		if layer.Source == nil {
+			return nil, fmt.Errorf("layer %q: source is required", layer.Name)
+		}
+		seen[layer.Name] = true
+	}
+
+	// Load lower precedence layers first so higher layers override them.
+	sorted := make([]Layer, len(layers))
+	copy(sorted, layers)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Precedence < sorted[j].Precedence
+	})
+
+	return &MultiSource{
+		layers:  sorted,
+		logger:  logger,
+		origins: make(map[string]string),
+	}, nil
+}
+
+// LoadPolicies loads every layer and merges the results by precedence.
+func (s *MultiSource) LoadPolicies(ctx context.Context) ([]*ast.Policy, error) {
+	merged := make(map[string]*ast.Policy)
+	origins := make(map[string]string)
+	var order []string
+
+	for _, layer := range s.layers {
+		policies, err := layer.Source.LoadPolicies(ctx)
+		if err != nil {
+			if layer.Optional {
+				s.logger.Warn("failed to load optional policy layer, skipping",
+					"layer", layer.Name,
+					"error", err,
+				)
+				continue
+			}
+			return nil, fmt.Errorf("failed to load policy layer %q: %w", layer.Name, err)
+		}
+
+		for _, policy := range policies {
+			name := qualifiedName(layer.Namespace, policy.Name)
+			if previous, ok := origins[name]; ok {
+				s.logger.Info("policy overridden by higher precedence layer",
+					"policy", name,
+					"layer", layer.Name,
+					"overridden_layer", previous,
+				)
+			} else {
+				order = append(order, name)
+			}
+
+			if layer.Namespace != "" {
+				// Copy so the layer's own policy is not renamed
+				namespaced := *policy
+				namespaced.Name = name
+				policy = &namespaced
+			}
+			merged[name] = policy
+			origins[name] = layer.Name
+		}
+	}
+
+	policies := make([]*ast.Policy, 0, len(order))
+	for _, name := range order {
+		policies = append(policies, merged[name])
+	}
+
+	s.mu.Lock()
+	s.origins = origins
+	s.mu.Unlock()
+
+	s.logger.Info("merged policy layers",
+		"layer_count", len(s.layers),
+		"policy_count", len(policies),
+	)
+
+	return policies, nil
+}
+
+// Origin returns the name of the layer that provided a policy in the last
+// load, or "" if the policy is unknown.
+func (s *MultiSource) Origin(policyName string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.origins[policyName]
+}
+
+// Watch fans in change events from all layers. The returned channel is
+// closed when every layer's channel has closed.
+func (s *MultiSource) Watch(ctx context.Context) (<-chan engine.PolicyEvent, error) {
+	channels := make([]<-chan engine.PolicyEvent, 0, len(s.layers))
+	for _, layer := range s.layers {
+		ch, err := layer.Source.Watch(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to watch policy layer %q: %w", layer.Name, err)
+		}
+		channels = append(channels, ch)
+	}
+
+	eventCh := make(chan engine.PolicyEvent)
+	var wg sync.WaitGroup
+	for _, ch := range channels {
+		wg.Add(1)
+		go func(ch <-chan engine.PolicyEvent) {
+			defer wg.Done()
+			for event := range ch {
+				select {
+				case eventCh <- event:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(ch)
+	}
+
+	go func() {
+		wg.Wait()
+		close(eventCh)
+	}()
+
+	return eventCh, nil
+}
+
+// qualifiedName returns a policy name within a namespace.
+func qualifiedName(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "." + name
+}
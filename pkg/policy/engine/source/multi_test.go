@@ -0,0 +1,132 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"mercator-hq/jupiter/pkg/mpl/ast"
+)
+
+// failingSource is a policy source that always fails to load.
+type failingSource struct{ MemorySource }
+
+func (s *failingSource) LoadPolicies(ctx context.Context) ([]*ast.Policy, error) {
+	return nil, errors.New("unavailable")
+}
+
+func TestMultiSource_Precedence(t *testing.T) {
+	org := NewMemorySource(
+		&ast.Policy{Name: "pii", Version: "1.0.0"},
+		&ast.Policy{Name: "routing", Version: "1.0.0"},
+	)
+	team := NewMemorySource(&ast.Policy{Name: "pii", Version: "2.0.0"})
+	emergency := NewMemorySource(&ast.Policy{Name: "routing", Version: "9.9.9"})
+
+	// Layers are given out of precedence order on purpose
+	s, err := NewMultiSource(nil,
+		Layer{Name: "emergency", Source: emergency, Precedence: 100},
+		Layer{Name: "org", Source: org, Precedence: 0},
+		Layer{Name: "team", Source: team, Precedence: 10},
+	)
+	if err != nil {
+		t.Fatalf("NewMultiSource() error = %v", err)
+	}
+
+	policies, err := s.LoadPolicies(context.Background())
+	if err != nil {
+		t.Fatalf("LoadPolicies() error = %v", err)
+	}
+	if len(policies) != 2 {
+		t.Fatalf("got %d policies, want 2", len(policies))
+	}
+
+	versions := map[string]string{}
+	for _, p := range policies {
+		versions[p.Name] = p.Version
+	}
+	if versions["pii"] != "2.0.0" || versions["routing"] != "9.9.9" {
+		t.Errorf("unexpected merge result: %v", versions)
+	}
+	if s.Origin("routing") != "emergency" || s.Origin("pii") != "team" {
+		t.Errorf("unexpected origins: routing=%q pii=%q", s.Origin("routing"), s.Origin("pii"))
+	}
+}
+
+func TestMultiSource_Namespaces(t *testing.T) {
+	shared := &ast.Policy{Name: "pii"}
+	s, err := NewMultiSource(nil,
+		Layer{Name: "org", Source: NewMemorySource(&ast.Policy{Name: "pii"})},
+		Layer{Name: "team-a", Source: NewMemorySource(shared), Namespace: "team-a", Precedence: 10},
+	)
+	if err != nil {
+		t.Fatalf("NewMultiSource() error = %v", err)
+	}
+
+	policies, err := s.LoadPolicies(context.Background())
+	if err != nil {
+		t.Fatalf("LoadPolicies() error = %v", err)
+	}
+	if len(policies) != 2 {
+		t.Fatalf("namespaced policies must not override global ones, got %d policies", len(policies))
+	}
+	if policies[1].Name != "team-a.pii" {
+		t.Errorf("namespaced name = %q, want team-a.pii", policies[1].Name)
+	}
+	if shared.Name != "pii" {
+		t.Error("namespacing must not modify the layer's policy")
+	}
+}
+
+func TestMultiSource_OptionalLayer(t *testing.T) {
+	org := NewMemorySource(&ast.Policy{Name: "pii"})
+
+	s, _ := NewMultiSource(nil,
+		Layer{Name: "org", Source: org},
+		Layer{Name: "overrides", Source: &failingSource{}, Precedence: 100, Optional: true},
+	)
+	policies, err := s.LoadPolicies(context.Background())
+	if err != nil || len(policies) != 1 {
+		t.Fatalf("optional layer failure: policies=%d err=%v", len(policies), err)
+	}
+
+	s, _ = NewMultiSource(nil,
+		Layer{Name: "org", Source: org},
+		Layer{Name: "overrides", Source: &failingSource{}},
+	)
+	if _, err := s.LoadPolicies(context.Background()); err == nil {
+		t.Error("expected error from required layer")
+	}
+}
+
+func TestNewMultiSource_Validation(t *testing.T) {
+	src := NewMemorySource()
+	tests := map[string][]Layer{
+		"missing name":   {{Source: src}},
+		"missing source": {{Name: "org"}},
+		"duplicate name": {{Name: "org", Source: src}, {Name: "org", Source: src}},
+	}
+	for name, layers := range tests {
+		if _, err := NewMultiSource(nil, layers...); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestMultiSource_Watch(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	s, _ := NewMultiSource(nil,
+		Layer{Name: "a", Source: NewMemorySource()},
+		Layer{Name: "b", Source: NewMemorySource()},
+	)
+	eventCh, err := s.Watch(ctx)
+	if err != nil {
+		t.Fatalf("Watch() error = %v", err)
+	}
+
+	// The merged channel closes once every layer's channel has closed
+	cancel()
+	for range eventCh {
+	}
+}
@@ -27,6 +27,11 @@ const (
 // PolicyDecision represents the result of evaluating policies against a request or response.
 // It contains the final action, all matched rules, and metadata about the evaluation.
 type PolicyDecision struct {
+	// ID uniquely identifies the decision. It is recorded with the evidence
+	// record of the request and set on the current trace span, so either can
+	// be found from the other.
+	ID string
+
 	// Action is the final policy action (allow, block, transform, route).
 	Action PolicyAction
 
@@ -59,6 +64,10 @@ type PolicyDecision struct {
 
 	// Trace contains detailed evaluation trace (if enabled).
 	Trace *EvaluationTrace
+
+	// Explanation contains a structured explanation of the decision
+	// (only when requested via ContextWithExplain).
+	Explanation *DecisionExplanation
 }
 
 // MatchedRule represents a single rule that matched during evaluation.
@@ -86,6 +95,10 @@ type MatchedRule struct {
 
 	// Error contains any error that occurred during rule evaluation.
 	Error error
+
+	// condition explains the rule's condition outcomes as they were matched.
+	// It is only captured when the evaluation is explained or traced.
+	condition *ConditionExplanation
 }
 
 // ActionResult represents the result of executing a single action.
@@ -131,6 +144,10 @@ type Redaction struct {
 
 	// MatchCount is the number of matches that were redacted.
 	MatchCount int
+
+	// Restore controls whether the placeholders of the "placeholder"
+	// strategy are replaced with the original content in the response.
+	Restore bool
 }
 
 // RoutingTarget specifies the provider and model to route a request to.
@@ -275,13 +292,14 @@ func (ctx *EvaluationContext) AddTransformation(field, operation string, value i
 }
 
 // AddRedaction adds a content redaction to the evaluation context.
-func (ctx *EvaluationContext) AddRedaction(field, strategy, pattern, replacement string, matchCount int) {
+func (ctx *EvaluationContext) AddRedaction(field, strategy, pattern, replacement string, matchCount int, restore bool) {
 	ctx.Redactions = append(ctx.Redactions, Redaction{
 		Field:       field,
 		Strategy:    strategy,
 		Pattern:     pattern,
 		Replacement: replacement,
 		MatchCount:  matchCount,
+		Restore:     restore,
 	})
 }
 
@@ -0,0 +1,204 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"mercator-hq/jupiter/pkg/policy/engine"
+)
+
+// Sink delivers decision events to an external system.
+type Sink interface {
+	// Name identifies the sink in logs and statistics.
+	Name() string
+
+	// Send delivers a single event. It must respect ctx cancellation.
+	Send(ctx context.Context, event *DecisionEvent) error
+
+	// Close releases any resources held by the sink.
+	Close() error
+}
+
+// Config contains configuration for the event bus.
+type Config struct {
+	// BufferSize is the number of events queued per sink.
+	// When a sink's queue is full, new events for that sink are dropped.
+	// Default: 1000
+	BufferSize int
+
+	// SendTimeout is the timeout for delivering a single event to a sink.
+	// Default: 5 seconds
+	SendTimeout time.Duration
+}
+
+// DefaultConfig returns the default event bus configuration.
+func DefaultConfig() *Config {
+	return &Config{
+		BufferSize:  1000,
+		SendTimeout: 5 * time.Second,
+	}
+}
+
+// SinkStats contains delivery counters for a sink.
+type SinkStats struct {
+	// Delivered is the number of events delivered successfully.
+	Delivered int64 `json:"delivered"`
+
+	// Failed is the number of events the sink failed to deliver.
+	Failed int64 `json:"failed"`
+
+	// Dropped is the number of events dropped because the queue was full.
+	Dropped int64 `json:"dropped"`
+}
+
+// sinkWorker owns the queue and counters for a single sink.
+type sinkWorker struct {
+	sink      Sink
+	queue     chan *DecisionEvent
+	delivered atomic.Int64
+	failed    atomic.Int64
+	dropped   atomic.Int64
+}
+
+// Bus fans policy decision events out to sinks.
+//
+// Each sink has its own queue and delivery goroutine, so a slow sink only
+// affects its own backlog. Bus implements engine.DecisionObserver.
+type Bus struct {
+	config  *Config
+	workers []*sinkWorker
+	wg      sync.WaitGroup
+	logger  *slog.Logger
+
+	// mu guards closed against concurrent Publish and Close.
+	mu     sync.RWMutex
+	closed bool
+}
+
+var _ engine.DecisionObserver = (*Bus)(nil)
+
+// NewBus creates an event bus delivering to the given sinks and starts its
+// delivery goroutines.
+func NewBus(config *Config, sinks ...Sink) *Bus {
+	if config == nil {
+		config = DefaultConfig()
+	}
+
+	b := &Bus{
+		config: config,
+		logger: slog.Default().With("component", "policy.events"),
+	}
+
+	for _, sink := range sinks {
+		w := &sinkWorker{
+			sink:  sink,
+			queue: make(chan *DecisionEvent, config.BufferSize),
+		}
+		b.workers = append(b.workers, w)
+
+		b.wg.Add(1)
+		go b.run(w)
+	}
+
+	b.logger.Info("policy decision event bus initialized",
+		"sinks", len(sinks),
+		"buffer_size", config.BufferSize,
+	)
+
+	return b
+}
+
+// ObserveDecision converts a policy decision into an event and publishes it.
+func (b *Bus) ObserveDecision(ctx context.Context, stage engine.DecisionStage, evalCtx *engine.EvaluationContext, decision *engine.PolicyDecision) {
+	b.Publish(NewDecisionEvent(stage, evalCtx, decision))
+}
+
+// Publish queues an event for delivery to every sink without blocking.
+// Events are dropped for sinks whose queue is full, or if the bus is closed.
+func (b *Bus) Publish(event *DecisionEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return
+	}
+
+	for _, w := range b.workers {
+		select {
+		case w.queue <- event:
+		default:
+			w.dropped.Add(1)
+			b.logger.Warn("decision event queue full, dropping event",
+				"sink", w.sink.Name(),
+				"event_id", event.ID,
+				"request_id", event.RequestID,
+			)
+		}
+	}
+}
+
+// Stats returns delivery counters keyed by sink name.
+func (b *Bus) Stats() map[string]SinkStats {
+	stats := make(map[string]SinkStats, len(b.workers))
+	for _, w := range b.workers {
+		stats[w.sink.Name()] = SinkStats{
+			Delivered: w.delivered.Load(),
+			Failed:    w.failed.Load(),
+			Dropped:   w.dropped.Load(),
+		}
+	}
+	return stats
+}
+
+// Close stops accepting events, delivers queued events, and closes all sinks.
+func (b *Bus) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	for _, w := range b.workers {
+		close(w.queue)
+	}
+	b.mu.Unlock()
+
+	b.wg.Wait()
+
+	var errs []error
+	for _, w := range b.workers {
+		if err := w.sink.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	b.logger.Info("policy decision event bus shut down")
+	return errors.Join(errs...)
+}
+
+// run delivers queued events to a sink until its queue is closed and drained.
+func (b *Bus) run(w *sinkWorker) {
+	defer b.wg.Done()
+
+	for event := range w.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), b.config.SendTimeout)
+		err := w.sink.Send(ctx, event)
+		cancel()
+
+		if err != nil {
+			w.failed.Add(1)
+			b.logger.Error("failed to deliver decision event",
+				"sink", w.sink.Name(),
+				"event_id", event.ID,
+				"request_id", event.RequestID,
+				"error", err,
+			)
+			continue
+		}
+		w.delivered.Add(1)
+	}
+}
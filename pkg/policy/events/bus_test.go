@@ -0,0 +1,454 @@
+package events
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/kafkarest"
+	"mercator-hq/jupiter/pkg/policy/engine"
+	"mercator-hq/jupiter/pkg/processing"
+)
+
+// recordingSink captures delivered events.
+type recordingSink struct {
+	mu     sync.Mutex
+	events []*DecisionEvent
+	err    error
+	block  chan struct{}
+	closed bool
+}
+
+func (s *recordingSink) Name() string { return "recording" }
+
+func (s *recordingSink) Send(ctx context.Context, event *DecisionEvent) error {
+	if s.block != nil {
+		<-s.block
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *recordingSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func testDecision() (*engine.EvaluationContext, *engine.PolicyDecision) {
+	evalCtx := &engine.EvaluationContext{
+		RequestID: "req-1",
+		Request: &processing.EnrichedRequest{
+			RequestID:    "req-1",
+			RiskScore:    7,
+			CostEstimate: &processing.CostEstimate{TotalCost: 0.25},
+		},
+	}
+	decision := &engine.PolicyDecision{
+		Action:          engine.ActionBlock,
+		BlockReason:     "pii detected",
+		BlockStatusCode: http.StatusForbidden,
+		Tags:            map[string]string{"team": "ml"},
+		MatchedRules: []*engine.MatchedRule{
+			{
+				PolicyID:        "pii",
+				RuleID:          "block-ssn",
+				ConditionResult: true,
+				ActionsExecuted: []*engine.ActionResult{{ActionType: "deny", Success: true}},
+			},
+		},
+		EvaluationTime: time.Millisecond,
+	}
+	return evalCtx, decision
+}
+
+func TestNewDecisionEvent(t *testing.T) {
+	evalCtx, decision := testDecision()
+	event := NewDecisionEvent(engine.StageRequest, evalCtx, decision)
+
+	if event.ID == "" {
+		t.Error("expected event ID")
+	}
+	if event.RequestID != "req-1" || event.Stage != engine.StageRequest {
+		t.Errorf("unexpected identity: %q %q", event.RequestID, event.Stage)
+	}
+	if event.Action != engine.ActionBlock || event.BlockStatusCode != http.StatusForbidden {
+		t.Errorf("unexpected outcome: %q %d", event.Action, event.BlockStatusCode)
+	}
+	if event.EstimatedCost != 0.25 || event.RiskScore != 7 {
+		t.Errorf("unexpected enrichment: cost=%v risk=%d", event.EstimatedCost, event.RiskScore)
+	}
+	if len(event.Rules) != 1 || event.Rules[0].RuleID != "block-ssn" || len(event.Rules[0].Actions) != 1 {
+		t.Errorf("unexpected rules: %+v", event.Rules)
+	}
+
+	// The event must not alias the decision's tags.
+	decision.Tags["team"] = "changed"
+	if event.Tags["team"] != "ml" {
+		t.Error("event tags alias decision tags")
+	}
+}
+
+func TestBus_DeliversAndDrainsOnClose(t *testing.T) {
+	sink := &recordingSink{}
+	bus := NewBus(DefaultConfig(), sink)
+
+	evalCtx, decision := testDecision()
+	for i := 0; i < 5; i++ {
+		bus.ObserveDecision(context.Background(), engine.StageRequest, evalCtx, decision)
+	}
+
+	if err := bus.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if len(sink.events) != 5 {
+		t.Errorf("delivered %d events, want 5", len(sink.events))
+	}
+	if !sink.closed {
+		t.Error("sink was not closed")
+	}
+	if stats := bus.Stats()["recording"]; stats.Delivered != 5 {
+		t.Errorf("Delivered = %d, want 5", stats.Delivered)
+	}
+
+	// Publishing after close is a no-op.
+	bus.Publish(&DecisionEvent{ID: "late"})
+}
+
+func TestBus_DropsWhenQueueFull(t *testing.T) {
+	sink := &recordingSink{block: make(chan struct{})}
+	bus := NewBus(&Config{BufferSize: 1, SendTimeout: time.Second}, sink)
+
+	// The first event is picked up by the worker (which blocks), the second
+	// fills the queue, and the rest are dropped.
+	bus.Publish(&DecisionEvent{ID: "1"})
+	time.Sleep(20 * time.Millisecond)
+	for i := 2; i <= 5; i++ {
+		bus.Publish(&DecisionEvent{ID: strconv.Itoa(i)})
+	}
+
+	if got := bus.Stats()["recording"].Dropped; got != 3 {
+		t.Errorf("Dropped = %d, want 3", got)
+	}
+
+	close(sink.block)
+	bus.Close()
+}
+
+func TestBus_CountsFailures(t *testing.T) {
+	sink := &recordingSink{err: errors.New("unavailable")}
+	bus := NewBus(DefaultConfig(), sink)
+	bus.Publish(&DecisionEvent{ID: "1"})
+	bus.Close()
+
+	if stats := bus.Stats()["recording"]; stats.Failed != 1 || stats.Delivered != 0 {
+		t.Errorf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestWebhookSink_Send(t *testing.T) {
+	var gotBody []byte
+	var gotSignature, gotHeader string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = io.ReadAll(r.Body)
+		gotSignature = r.Header.Get(SignatureHeader)
+		gotHeader = r.Header.Get("X-Source")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(&WebhookConfig{
+		URL:     server.URL,
+		Headers: map[string]string{"X-Source": "mercator"},
+		Secret:  "s3cret",
+	})
+
+	if err := sink.Send(context.Background(), &DecisionEvent{ID: "evt-1", RequestID: "req-1"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	var event DecisionEvent
+	if err := json.Unmarshal(gotBody, &event); err != nil || event.ID != "evt-1" {
+		t.Fatalf("unexpected body %s (err %v)", gotBody, err)
+	}
+	if gotSignature != Sign("s3cret", gotBody) {
+		t.Errorf("signature = %q, want %q", gotSignature, Sign("s3cret", gotBody))
+	}
+	if gotHeader != "mercator" {
+		t.Errorf("X-Source = %q", gotHeader)
+	}
+}
+
+func TestWebhookSink_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	sink := NewWebhookSink(&WebhookConfig{URL: server.URL})
+	if err := sink.Send(context.Background(), &DecisionEvent{ID: "evt-1"}); err == nil {
+		t.Fatal("expected error for 500 response")
+	}
+}
+
+func TestKafkaSink_Send(t *testing.T) {
+	var gotPath, gotContentType string
+	var gotBody struct {
+		Records []struct {
+			Key   string        `json:"key"`
+			Value DecisionEvent `json:"value"`
+		} `json:"records"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotContentType = r.Header.Get("Content-Type")
+		json.NewDecoder(r.Body).Decode(&gotBody)
+		w.Write([]byte(`{"offsets":[{"partition":0,"offset":42}]}`))
+	}))
+	defer server.Close()
+
+	sink := NewKafkaSink(&KafkaConfig{URL: server.URL + "/", Topic: "policy-decisions"})
+	if err := sink.Send(context.Background(), &DecisionEvent{ID: "evt-1", RequestID: "req-1"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if gotPath != "/topics/policy-decisions" {
+		t.Errorf("path = %q", gotPath)
+	}
+	if gotContentType != kafkarest.ContentTypeJSON {
+		t.Errorf("content type = %q", gotContentType)
+	}
+	if len(gotBody.Records) != 1 || gotBody.Records[0].Key != "req-1" || gotBody.Records[0].Value.ID != "evt-1" {
+		t.Errorf("unexpected records: %+v", gotBody.Records)
+	}
+}
+
+func TestKafkaSink_RecordError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"offsets":[{"partition":null,"offset":null,"error_code":50002,"error":"topic not found"}]}`))
+	}))
+	defer server.Close()
+
+	sink := NewKafkaSink(&KafkaConfig{URL: server.URL, Topic: "missing"})
+	err := sink.Send(context.Background(), &DecisionEvent{ID: "evt-1"})
+	if err == nil || !strings.Contains(err.Error(), "topic not found") {
+		t.Fatalf("expected record error, got %v", err)
+	}
+}
+
+func TestNATSSink_Send(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer listener.Close()
+
+	received := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		reader := bufio.NewReader(conn)
+
+		conn.Write([]byte("INFO {\"server_id\":\"test\"}\r\n"))
+		connect, _ := reader.ReadString('\n')
+		if !strings.HasPrefix(connect, "CONNECT ") || !strings.Contains(connect, `"auth_token":"tok"`) {
+			conn.Write([]byte("-ERR 'Authorization Violation'\r\n"))
+			return
+		}
+		reader.ReadString('\n') // PING
+		conn.Write([]byte("PONG\r\n"))
+
+		pub, _ := reader.ReadString('\n')
+		fields := strings.Fields(pub)
+		if len(fields) != 3 || fields[0] != "PUB" {
+			return
+		}
+		size, _ := strconv.Atoi(fields[2])
+		payload := make([]byte, size+2)
+		io.ReadFull(reader, payload)
+		received <- fields[1] + " " + string(payload[:size])
+	}()
+
+	sink, err := NewNATSSink(&NATSConfig{
+		URL:     "nats://tok@" + listener.Addr().String(),
+		Subject: "mercator.decisions",
+	})
+	if err != nil {
+		t.Fatalf("NewNATSSink() error = %v", err)
+	}
+	defer sink.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := sink.Send(ctx, &DecisionEvent{ID: "evt-1"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	select {
+	case msg := <-received:
+		if !strings.HasPrefix(msg, "mercator.decisions ") || !strings.Contains(msg, `"id":"evt-1"`) {
+			t.Errorf("unexpected message %q", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for PUB")
+	}
+}
+
+// serveNATS accepts one client connection, completes the handshake (over TLS
+// if tlsConfig is set), and sends the subject and payload of each PUB.
+func serveNATS(t *testing.T, tlsConfig *tls.Config) (net.Listener, <-chan string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	received := make(chan string, 10)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		if tlsConfig == nil {
+			conn.Write([]byte("INFO {\"server_id\":\"test\"}\r\n"))
+		} else {
+			conn.Write([]byte("INFO {\"server_id\":\"test\",\"tls_required\":true}\r\n"))
+			tlsConn := tls.Server(conn, tlsConfig)
+			if err := tlsConn.Handshake(); err != nil {
+				return
+			}
+			conn = tlsConn
+		}
+		reader := bufio.NewReader(conn)
+		reader.ReadString('\n') // CONNECT
+		reader.ReadString('\n') // PING
+		conn.Write([]byte("PONG\r\n"))
+
+		for {
+			pub, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			fields := strings.Fields(pub)
+			if len(fields) != 3 || fields[0] != "PUB" {
+				return
+			}
+			size, _ := strconv.Atoi(fields[2])
+			payload := make([]byte, size+2)
+			io.ReadFull(reader, payload)
+			received <- fields[1] + " " + string(payload[:size])
+		}
+	}()
+	return listener, received
+}
+
+func TestNATSSink_TLS(t *testing.T) {
+	// Borrow the test server certificate for 127.0.0.1.
+	certServer := httptest.NewTLSServer(http.NotFoundHandler())
+	defer certServer.Close()
+	roots := x509.NewCertPool()
+	roots.AddCert(certServer.Certificate())
+
+	listener, received := serveNATS(t, &tls.Config{Certificates: certServer.TLS.Certificates})
+	sink, err := NewNATSSink(&NATSConfig{
+		URL:       "tls://" + listener.Addr().String(),
+		Subject:   "mercator.decisions",
+		TLSConfig: &tls.Config{RootCAs: roots},
+	})
+	if err != nil {
+		t.Fatalf("NewNATSSink() error = %v", err)
+	}
+	defer sink.Close()
+
+	if err := sink.Send(context.Background(), &DecisionEvent{ID: "evt-1"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	select {
+	case msg := <-received:
+		if !strings.Contains(msg, `"id":"evt-1"`) {
+			t.Errorf("unexpected message %q", msg)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for PUB")
+	}
+}
+
+func TestNATSSink_RequireTLS(t *testing.T) {
+	listener, _ := serveNATS(t, nil)
+	sink, err := NewNATSSink(&NATSConfig{URL: "tls://" + listener.Addr().String(), Subject: "a"})
+	if err != nil {
+		t.Fatalf("NewNATSSink() error = %v", err)
+	}
+	defer sink.Close()
+
+	err = sink.Send(context.Background(), &DecisionEvent{ID: "evt-1"})
+	if err == nil || !strings.Contains(err.Error(), "does not support TLS") {
+		t.Fatalf("expected TLS error, got %v", err)
+	}
+}
+
+func TestNATSSink_ClearsWriteDeadline(t *testing.T) {
+	listener, received := serveNATS(t, nil)
+	sink, err := NewNATSSink(&NATSConfig{URL: "nats://" + listener.Addr().String(), Subject: "a"})
+	if err != nil {
+		t.Fatalf("NewNATSSink() error = %v", err)
+	}
+	defer sink.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	err = sink.Send(ctx, &DecisionEvent{ID: "evt-1"})
+	cancel()
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	// A later send without a deadline must not hit the expired one.
+	time.Sleep(150 * time.Millisecond)
+	if err := sink.Send(context.Background(), &DecisionEvent{ID: "evt-2"}); err != nil {
+		t.Fatalf("Send() after deadline error = %v", err)
+	}
+	for _, id := range []string{"evt-1", "evt-2"} {
+		select {
+		case msg := <-received:
+			if !strings.Contains(msg, id) {
+				t.Errorf("message = %q, want %s", msg, id)
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for %s", id)
+		}
+	}
+}
+
+func TestNewNATSSink_InvalidConfig(t *testing.T) {
+	if _, err := NewNATSSink(&NATSConfig{URL: "http://localhost", Subject: "a"}); err == nil {
+		t.Error("expected error for non-nats scheme")
+	}
+	if _, err := NewNATSSink(&NATSConfig{URL: "nats://localhost", Subject: "has space"}); err == nil {
+		t.Error("expected error for invalid subject")
+	}
+}
@@ -0,0 +1,30 @@
+// Package events publishes policy decisions as structured events to external
+// systems such as SIEMs and FinOps pipelines.
+//
+// The Bus implements engine.DecisionObserver. Every decision made by the
+// policy engine is converted into a DecisionEvent and queued for delivery to
+// each configured Sink. Delivery is asynchronous and independent of evidence
+// storage: a slow or unavailable sink never blocks policy evaluation, and
+// events that do not fit in a sink's queue are dropped and counted.
+//
+// # Sinks
+//
+// Three sinks are provided:
+//
+//   - WebhookSink POSTs each event as JSON to an HTTP endpoint, optionally
+//     signed with HMAC-SHA256 in the X-Mercator-Signature header.
+//   - KafkaSink produces events to a topic through a Kafka REST Proxy.
+//   - NATSSink publishes events to a subject using the NATS client protocol,
+//     over TLS for "tls://" URLs or when the server requires it.
+//
+// Custom sinks implement the Sink interface.
+//
+// # Usage
+//
+//	bus := events.NewBus(events.DefaultConfig(), events.NewWebhookSink(&events.WebhookConfig{
+//	    URL: "https://siem.example.com/ingest",
+//	}))
+//	defer bus.Close()
+//
+//	policyEngine.AddDecisionObserver(bus)
+package events
@@ -0,0 +1,140 @@
+package events
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+
+	"mercator-hq/jupiter/pkg/policy/engine"
+)
+
+// DecisionEvent is the structured representation of a single policy decision.
+type DecisionEvent struct {
+	// ID uniquely identifies the event.
+	ID string `json:"id"`
+
+	// Timestamp is when the decision was made.
+	Timestamp time.Time `json:"timestamp"`
+
+	// RequestID is the request the decision was made for.
+	RequestID string `json:"request_id"`
+
+	// Stage is the pipeline stage ("request" or "response").
+	Stage engine.DecisionStage `json:"stage"`
+
+	// Action is the final policy action.
+	Action engine.PolicyAction `json:"action"`
+
+	// BlockReason explains why the request was blocked (if blocked).
+	BlockReason string `json:"block_reason,omitempty"`
+
+	// BlockStatusCode is the HTTP status returned for blocked requests.
+	BlockStatusCode int `json:"block_status_code,omitempty"`
+
+	// Model is the requested (or responding) model, if known.
+	Model string `json:"model,omitempty"`
+
+	// EstimatedCost is the estimated cost of the request or response in USD.
+	EstimatedCost float64 `json:"estimated_cost,omitempty"`
+
+	// RiskScore is the request risk score (request stage only).
+	RiskScore int `json:"risk_score,omitempty"`
+
+	// Rules lists the rules evaluated for the decision.
+	Rules []RuleEvent `json:"rules,omitempty"`
+
+	// Tags are the tags attached to the request by policy actions.
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// RoutingTarget is the provider/model selected by routing actions.
+	RoutingTarget *RoutingEvent `json:"routing_target,omitempty"`
+
+	// EvaluationTime is the total policy evaluation time.
+	EvaluationTime time.Duration `json:"evaluation_time_ns"`
+}
+
+// RuleEvent describes an evaluated rule.
+type RuleEvent struct {
+	// PolicyID is the policy containing the rule.
+	PolicyID string `json:"policy_id"`
+
+	// RuleID is the rule identifier.
+	RuleID string `json:"rule_id"`
+
+	// Matched indicates whether the rule's conditions matched.
+	Matched bool `json:"matched"`
+
+	// Actions lists the types of the actions executed by the rule.
+	Actions []string `json:"actions,omitempty"`
+}
+
+// RoutingEvent describes a routing decision.
+type RoutingEvent struct {
+	// Provider is the target provider.
+	Provider string `json:"provider,omitempty"`
+
+	// Model is the target model.
+	Model string `json:"model,omitempty"`
+}
+
+// NewDecisionEvent builds an event from a policy decision and the context it
+// was evaluated in. The returned event does not reference the decision, so it
+// is safe to deliver after the evaluation has completed.
+func NewDecisionEvent(stage engine.DecisionStage, evalCtx *engine.EvaluationContext, decision *engine.PolicyDecision) *DecisionEvent {
+	event := &DecisionEvent{
+		ID:              uuid.New().String(),
+		Timestamp:       time.Now().UTC(),
+		RequestID:       evalCtx.RequestID,
+		Stage:           stage,
+		Action:          decision.Action,
+		BlockReason:     decision.BlockReason,
+		BlockStatusCode: decision.BlockStatusCode,
+		EvaluationTime:  decision.EvaluationTime,
+	}
+
+	if req := evalCtx.Request; req != nil {
+		if req.OriginalRequest != nil {
+			event.Model = req.OriginalRequest.Model
+		}
+		if req.CostEstimate != nil {
+			event.EstimatedCost = req.CostEstimate.TotalCost
+		}
+		event.RiskScore = req.RiskScore
+	}
+	if resp := evalCtx.Response; resp != nil {
+		if resp.OriginalResponse != nil {
+			event.Model = resp.OriginalResponse.Model
+		}
+		if resp.CostEstimate != nil {
+			event.EstimatedCost = resp.CostEstimate.TotalCost
+		}
+	}
+
+	for _, rule := range decision.MatchedRules {
+		ruleEvent := RuleEvent{
+			PolicyID: rule.PolicyID,
+			RuleID:   rule.RuleID,
+			Matched:  rule.ConditionResult,
+		}
+		for _, action := range rule.ActionsExecuted {
+			ruleEvent.Actions = append(ruleEvent.Actions, string(action.ActionType))
+		}
+		event.Rules = append(event.Rules, ruleEvent)
+	}
+
+	if len(decision.Tags) > 0 {
+		event.Tags = make(map[string]string, len(decision.Tags))
+		for k, v := range decision.Tags {
+			event.Tags[k] = v
+		}
+	}
+
+	if decision.RoutingTarget != nil {
+		event.RoutingTarget = &RoutingEvent{
+			Provider: decision.RoutingTarget.Provider,
+			Model:    decision.RoutingTarget.Model,
+		}
+	}
+
+	return event
+}
@@ -0,0 +1,61 @@
+package events
+
+import (
+	"context"
+	"net/http"
+
+	"mercator-hq/jupiter/pkg/kafkarest"
+)
+
+// KafkaConfig configures a KafkaSink.
+type KafkaConfig struct {
+	// URL is the base URL of the Kafka REST Proxy (e.g., "http://kafka-rest:8082").
+	URL string
+
+	// Topic is the topic events are produced to.
+	Topic string
+
+	// Headers are extra headers sent with each request (e.g., authorization).
+	Headers map[string]string
+
+	// Client is the HTTP client used for delivery.
+	// Default: http.DefaultClient
+	Client *http.Client
+}
+
+// KafkaSink produces decision events to a Kafka topic through a Kafka REST
+// Proxy. Events are keyed by request ID so that the request and response
+// decisions for a request land in the same partition.
+type KafkaSink struct {
+	client *kafkarest.Client
+}
+
+// NewKafkaSink creates a Kafka sink.
+func NewKafkaSink(config *KafkaConfig) *KafkaSink {
+	return &KafkaSink{
+		client: kafkarest.NewClient(&kafkarest.Config{
+			URL:     config.URL,
+			Topic:   config.Topic,
+			Headers: config.Headers,
+			Client:  config.Client,
+		}),
+	}
+}
+
+// Name returns the sink name.
+func (s *KafkaSink) Name() string {
+	return "kafka:" + s.client.Topic()
+}
+
+// Send produces the event to the configured topic.
+func (s *KafkaSink) Send(ctx context.Context, event *DecisionEvent) error {
+	_, err := s.client.Produce(ctx, kafkarest.ContentTypeJSON, &kafkarest.ProduceRequest{
+		Records: []kafkarest.Record{{Key: event.RequestID, Value: event}},
+	})
+	return err
+}
+
+// Close is a no-op for Kafka sinks.
+func (s *KafkaSink) Close() error {
+	return nil
+}
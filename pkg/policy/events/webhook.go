@@ -0,0 +1,105 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// SignatureHeader carries the HMAC-SHA256 signature of a webhook payload,
+// formatted as "sha256=<hex>".
+const SignatureHeader = "X-Mercator-Signature"
+
+// WebhookConfig configures a WebhookSink.
+type WebhookConfig struct {
+	// URL is the endpoint events are POSTed to.
+	URL string
+
+	// Headers are extra headers sent with each request.
+	Headers map[string]string
+
+	// Secret, if set, is used to sign each payload with HMAC-SHA256.
+	Secret string
+
+	// Client is the HTTP client used for delivery.
+	// Default: http.DefaultClient
+	Client *http.Client
+}
+
+// WebhookSink POSTs each decision event as a JSON document to an HTTP endpoint.
+type WebhookSink struct {
+	config *WebhookConfig
+	client *http.Client
+}
+
+// NewWebhookSink creates a webhook sink.
+func NewWebhookSink(config *WebhookConfig) *WebhookSink {
+	client := config.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &WebhookSink{config: config, client: client}
+}
+
+// Name returns the sink name.
+func (s *WebhookSink) Name() string {
+	return "webhook:" + s.config.URL
+}
+
+// Send POSTs the event to the webhook endpoint.
+func (s *WebhookSink) Send(ctx context.Context, event *DecisionEvent) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.config.URL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range s.config.Headers {
+		req.Header.Set(k, v)
+	}
+	if s.config.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(s.config.Secret, payload))
+	}
+
+	return doRequest(s.client, req)
+}
+
+// Close is a no-op for webhook sinks.
+func (s *WebhookSink) Close() error {
+	return nil
+}
+
+// Sign returns the signature header value for a payload.
+// Receivers verify a delivery by computing the same value with the shared secret.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// doRequest sends an HTTP request and treats any non-2xx status as an error.
+func doRequest(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %w", req.URL.Redacted(), err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("request to %s returned status %d: %s", req.URL.Redacted(), resp.StatusCode, bytes.TrimSpace(body))
+	}
+
+	io.Copy(io.Discard, resp.Body)
+	return nil
+}
@@ -0,0 +1,139 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// sparseDirs returns the directories to check out in sparse mode, or nil if
+// the whole tree should be checked out.
+func (r *Repository) sparseDirs() []string {
+	if !r.config.Clone.Sparse {
+		return nil
+	}
+
+	// Checking out the repository root means checking out everything.
+	dirs := []string{cleanRepoPath(r.config.Path)}
+	for _, dir := range r.config.Clone.SparsePaths {
+		dirs = append(dirs, cleanRepoPath(dir))
+	}
+	for _, dir := range dirs {
+		if dir == "" {
+			return nil
+		}
+	}
+	return dirs
+}
+
+// cleanRepoPath normalizes a repository-relative directory ("./a/b/" -> "a/b").
+func cleanRepoPath(p string) string {
+	p = path.Clean("/" + strings.TrimSpace(p))
+	return strings.TrimPrefix(p, "/")
+}
+
+// inSparseDirs reports whether a repository path is inside the sparse checkout.
+// With no sparse directories every path is included.
+func inSparseDirs(p string, dirs []string) bool {
+	if len(dirs) == 0 {
+		return true
+	}
+	p = cleanRepoPath(p)
+	for _, dir := range dirs {
+		if p == dir || strings.HasPrefix(p, dir+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// pullSparse fetches the tracked branch and fast-forwards a sparse worktree.
+//
+// go-git's Worktree.Pull always resets the full tree, so sparse repositories
+// fetch and reset explicitly. Like Pull, it refuses non-fast-forward updates.
+func (r *Repository) pullSparse(ctx context.Context, auth transport.AuthMethod, dirs []string) error {
+	err := r.repo.FetchContext(ctx, &gogit.FetchOptions{
+		RemoteName: "origin",
+		Auth:       auth,
+	})
+	if err != nil && err != gogit.NoErrAlreadyUpToDate {
+		return err
+	}
+
+	remoteRef, err := r.repo.Reference(plumbing.NewRemoteReferenceName("origin", r.config.Branch), true)
+	if err != nil {
+		return fmt.Errorf("failed to resolve remote branch: %w", err)
+	}
+
+	head, err := r.repo.Head()
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+	if head.Hash() == remoteRef.Hash() {
+		return gogit.NoErrAlreadyUpToDate
+	}
+
+	headCommit, err := r.repo.CommitObject(head.Hash())
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD commit: %w", err)
+	}
+	remoteCommit, err := r.repo.CommitObject(remoteRef.Hash())
+	if err != nil {
+		return fmt.Errorf("failed to get remote commit: %w", err)
+	}
+	ff, err := headCommit.IsAncestor(remoteCommit)
+	if err != nil {
+		return fmt.Errorf("failed to compare commits: %w", err)
+	}
+	if !ff {
+		return gogit.ErrNonFastForwardUpdate
+	}
+
+	worktree, err := r.repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+	return worktree.ResetSparsely(&gogit.ResetOptions{
+		Commit: remoteRef.Hash(),
+		Mode:   gogit.HardReset,
+	}, dirs)
+}
+
+// updateSubmodules initializes and updates submodules inside the checked-out
+// paths. Nested submodules are resolved recursively.
+func (r *Repository) updateSubmodules(ctx context.Context, auth transport.AuthMethod) error {
+	worktree, err := r.repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+
+	submodules, err := worktree.Submodules()
+	if err != nil {
+		return fmt.Errorf("failed to list submodules: %w", err)
+	}
+
+	dirs := r.sparseDirs()
+	for _, sub := range submodules {
+		subPath := sub.Config().Path
+		if !inSparseDirs(subPath, dirs) {
+			continue
+		}
+
+		err := sub.UpdateContext(ctx, &gogit.SubmoduleUpdateOptions{
+			Init:              true,
+			RecurseSubmodules: gogit.DefaultSubmoduleRecursionDepth,
+			Auth:              auth,
+			Depth:             r.config.Clone.Depth,
+		})
+		if err != nil && err != gogit.NoErrAlreadyUpToDate {
+			return fmt.Errorf("failed to update submodule %s: %w", subPath, err)
+		}
+	}
+
+	return nil
+}
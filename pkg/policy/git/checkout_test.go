@@ -0,0 +1,161 @@
+package git
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+
+	"mercator-hq/jupiter/pkg/config"
+)
+
+// createMonorepo creates a repository with policies, shared fragments, and
+// unrelated application code.
+func createMonorepo(t *testing.T, dir string) *gogit.Repository {
+	t.Helper()
+
+	repo, err := gogit.PlainInit(dir, false)
+	if err != nil {
+		t.Fatalf("failed to init repo: %v", err)
+	}
+
+	files := map[string]string{
+		"policies/main.yaml":         "policies",
+		"shared/fragments/pii.yaml":  "fragment",
+		"services/api/main.go":       "package main",
+		"services/api/testdata/a.md": "docs",
+	}
+	worktree, _ := repo.Worktree()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("failed to write %s: %v", name, err)
+		}
+		if _, err := worktree.Add(name); err != nil {
+			t.Fatalf("failed to add %s: %v", name, err)
+		}
+	}
+
+	_, err = worktree.Commit("initial commit", &gogit.CommitOptions{
+		Author: &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	return repo
+}
+
+func TestRepository_SparseCheckout(t *testing.T) {
+	sourceDir := t.TempDir()
+	source := createMonorepo(t, sourceDir)
+
+	localPath := t.TempDir()
+	r, err := NewRepository(&config.GitPolicyConfig{
+		Repository: sourceDir,
+		Branch:     "master",
+		Path:       "./policies/",
+		Auth:       config.GitAuthConfig{Type: "none"},
+		Poll:       config.GitPollConfig{Timeout: 10 * time.Second},
+		Clone: config.GitCloneConfig{
+			LocalPath:   localPath,
+			Sparse:      true,
+			SparsePaths: []string{"shared/fragments"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewRepository() error = %v", err)
+	}
+	if err := r.Clone(context.Background()); err != nil {
+		t.Fatalf("Clone() error = %v", err)
+	}
+
+	assertExists := func(name string, want bool) {
+		t.Helper()
+		_, err := os.Stat(filepath.Join(localPath, name))
+		if exists := err == nil; exists != want {
+			t.Errorf("%s exists = %v, want %v", name, exists, want)
+		}
+	}
+	assertExists("policies/main.yaml", true)
+	assertExists("shared/fragments/pii.yaml", true)
+	assertExists("services/api/main.go", false)
+
+	// New commits are pulled without materializing files outside the sparse paths
+	commitFile(t, source, sourceDir, "services/api/new.go", &gogit.CommitOptions{})
+	commitFile(t, source, sourceDir, "policies/extra.yaml", &gogit.CommitOptions{})
+
+	result, err := r.Pull(context.Background())
+	if err != nil {
+		t.Fatalf("Pull() error = %v", err)
+	}
+	if !result.HadChanges {
+		t.Fatal("expected changes")
+	}
+	assertExists("policies/extra.yaml", true)
+	assertExists("services/api/new.go", false)
+	assertExists("services/api/main.go", false)
+
+	// A second pull is a no-op
+	result, err = r.Pull(context.Background())
+	if err != nil {
+		t.Fatalf("second Pull() error = %v", err)
+	}
+	if result.HadChanges {
+		t.Error("expected no changes on second pull")
+	}
+}
+
+func TestRepository_SparseDirs(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		clone config.GitCloneConfig
+		want  []string
+	}{
+		{name: "disabled", path: "policies", clone: config.GitCloneConfig{}, want: nil},
+		{name: "policy path", path: "./policies/", clone: config.GitCloneConfig{Sparse: true}, want: []string{"policies"}},
+		{name: "extra paths", path: "policies", clone: config.GitCloneConfig{Sparse: true, SparsePaths: []string{"shared/"}}, want: []string{"policies", "shared"}},
+		{name: "root path", path: "", clone: config.GitCloneConfig{Sparse: true, SparsePaths: []string{"shared"}}, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &Repository{config: &config.GitPolicyConfig{Path: tt.path, Clone: tt.clone}}
+			got := r.sparseDirs()
+			if len(got) != len(tt.want) {
+				t.Fatalf("sparseDirs() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("sparseDirs() = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestInSparseDirs(t *testing.T) {
+	dirs := []string{"policies", "shared/fragments"}
+	tests := map[string]bool{
+		"policies":                 true,
+		"policies/vendor/common":   true,
+		"shared/fragments/":        true,
+		"shared":                   false,
+		"policies-old":             false,
+		"services/api/submodule":   false,
+		"./shared/fragments/x/../": true,
+	}
+	for path, want := range tests {
+		if got := inSparseDirs(path, dirs); got != want {
+			t.Errorf("inSparseDirs(%q) = %v, want %v", path, got, want)
+		}
+	}
+	if !inSparseDirs("anything", nil) {
+		t.Error("all paths are included without sparse dirs")
+	}
+}
@@ -36,6 +36,26 @@
 //   - SSH key-based: Public key authentication
 //   - None: Public repositories
 //
+// # Large Repositories
+//
+// For monorepos, set Clone.Depth for a shallow clone and Clone.Sparse to check
+// out only Path plus any Clone.SparsePaths (e.g. shared policy fragments).
+// Clone.Submodules initializes and updates submodules after clone and pull;
+// in sparse mode only submodules inside the checked-out paths are resolved.
+//
+// # Signed Commits
+//
+// When Verify is enabled, every fetched commit must carry a GPG or SSH
+// signature from an allowed signer before its policies are applied. Allowed
+// GPG signers are read from an armored keyring and SSH signers from a git
+// allowed_signers file. Pull verifies each commit between the previous and
+// the new HEAD; Clone verifies only the cloned HEAD, so the history it starts
+// from is trusted. In strict mode (the default) unsigned or unknown-signer
+// commits are rejected: Clone fails, and Pull resets the branch to the
+// previous commit and returns a *SignatureError. In warn mode the commits are
+// applied, each failure is logged at Warn level with its SHA and signer, and
+// the first failure is reported in PullResult.Signature.
+//
 // # Branch-Based Environments
 //
 // Use different branches for different environments:
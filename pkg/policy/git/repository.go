@@ -2,7 +2,9 @@ package git
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"sync"
@@ -21,6 +23,8 @@ type Repository struct {
 	localPath string
 	auth      AuthProvider
 	repo      *gogit.Repository
+	verifier  *Verifier
+	logger    *slog.Logger
 	mu        sync.RWMutex
 	metrics   *RepositoryMetrics
 }
@@ -46,6 +50,14 @@ func NewRepository(cfg *config.GitPolicyConfig) (*Repository, error) {
 		return nil, fmt.Errorf("failed to create auth provider: %w", err)
 	}
 
+	var verifier *Verifier
+	if cfg.Verify.Enabled {
+		verifier, err = NewVerifier(&cfg.Verify)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create signature verifier: %w", err)
+		}
+	}
+
 	localPath := cfg.Clone.LocalPath
 	if localPath == "" {
 		// Default to temp directory if not specified
@@ -56,6 +68,8 @@ func NewRepository(cfg *config.GitPolicyConfig) (*Repository, error) {
 		config:    cfg,
 		localPath: localPath,
 		auth:      auth,
+		verifier:  verifier,
+		logger:    slog.Default().With("component", "policy.git"),
 		metrics:   &RepositoryMetrics{},
 	}, nil
 }
@@ -63,7 +77,9 @@ func NewRepository(cfg *config.GitPolicyConfig) (*Repository, error) {
 // Clone initializes the repository by cloning it locally.
 // If the repository already exists and CleanOnStart is false, it opens the existing repo.
 // If CleanOnStart is true, it removes any existing repository before cloning.
-// Returns an error if cloning fails.
+// When strict signature verification is configured, the HEAD commit must be
+// signed by an allowed signer. History before the cloned HEAD is not verified.
+// Returns an error if cloning or verification fails.
 func (r *Repository) Clone(ctx context.Context) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -89,7 +105,7 @@ func (r *Repository) Clone(ctx context.Context) error {
 			return fmt.Errorf("failed to open existing repo: %w", err)
 		}
 		r.repo = repo
-		return nil
+		return r.verifyHeadInternal()
 	}
 
 	// Create parent directory
@@ -98,12 +114,14 @@ func (r *Repository) Clone(ctx context.Context) error {
 	}
 
 	// Clone options
+	sparseDirs := r.sparseDirs()
 	cloneOpts := &gogit.CloneOptions{
 		URL:           r.config.Repository,
 		ReferenceName: plumbing.NewBranchReferenceName(r.config.Branch),
 		SingleBranch:  r.config.Clone.Depth > 0, // Only single branch for shallow clones
 		Depth:         r.config.Clone.Depth,
-		Progress:      nil, // Can add progress reporting if needed
+		NoCheckout:    len(sparseDirs) > 0, // Sparse checkout is done after cloning
+		Progress:      nil,                 // Can add progress reporting if needed
 	}
 
 	// Add auth if configured
@@ -123,11 +141,36 @@ func (r *Repository) Clone(ctx context.Context) error {
 	}
 
 	r.repo = repo
-	return nil
+
+	if len(sparseDirs) > 0 {
+		worktree, err := repo.Worktree()
+		if err != nil {
+			return fmt.Errorf("failed to get worktree: %w", err)
+		}
+		err = worktree.Checkout(&gogit.CheckoutOptions{
+			Branch:                    plumbing.NewBranchReferenceName(r.config.Branch),
+			SparseCheckoutDirectories: sparseDirs,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to sparse checkout %v: %w", sparseDirs, err)
+		}
+	}
+
+	if r.config.Clone.Submodules {
+		if err := r.updateSubmodules(cloneCtx, auth); err != nil {
+			return err
+		}
+	}
+
+	return r.verifyHeadInternal()
 }
 
 // Pull fetches latest changes from the remote repository.
 // It returns a PullResult indicating whether changes were found and what files changed.
+// When signature verification is configured, every commit between the
+// previous and the new HEAD is verified; in strict mode a rejected commit
+// resets the branch back to the previous HEAD and a *SignatureError is
+// returned.
 // This method is thread-safe and can be called concurrently.
 // Returns an error if the pull operation fails.
 func (r *Repository) Pull(ctx context.Context) (*PullResult, error) {
@@ -151,34 +194,42 @@ func (r *Repository) Pull(ctx context.Context) (*PullResult, error) {
 	}
 	fromSHA := ref.Hash().String()
 
-	// Pull changes
-	worktree, err := r.repo.Worktree()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get worktree: %w", err)
-	}
-
-	pullOpts := &gogit.PullOptions{
-		RemoteName: "origin",
-		Force:      false, // Never force pull (fail-safe)
-	}
-
 	// Add auth
 	auth, err := r.auth.GetAuth()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get auth: %w", err)
 	}
-	pullOpts.Auth = auth
 
 	// Pull with timeout
 	pullCtx, cancel := context.WithTimeout(ctx, r.config.Poll.Timeout)
 	defer cancel()
 
-	err = worktree.PullContext(pullCtx, pullOpts)
+	if sparseDirs := r.sparseDirs(); len(sparseDirs) > 0 {
+		err = r.pullSparse(pullCtx, auth, sparseDirs)
+	} else {
+		var worktree *gogit.Worktree
+		worktree, err = r.repo.Worktree()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get worktree: %w", err)
+		}
+		err = worktree.PullContext(pullCtx, &gogit.PullOptions{
+			RemoteName: "origin",
+			Force:      false, // Never force pull (fail-safe)
+			Auth:       auth,
+		})
+	}
 	if err != nil && err != gogit.NoErrAlreadyUpToDate {
 		r.metrics.FailedPulls++
 		return nil, fmt.Errorf("failed to pull: %w", err)
 	}
 
+	if err == nil && r.config.Clone.Submodules {
+		if err := r.updateSubmodules(pullCtx, auth); err != nil {
+			r.metrics.FailedPulls++
+			return nil, fmt.Errorf("failed to pull: %w", err)
+		}
+	}
+
 	r.metrics.SuccessfulPulls++
 
 	// Get new HEAD
@@ -194,6 +245,19 @@ func (r *Repository) Pull(ctx context.Context) (*PullResult, error) {
 		HadChanges: fromSHA != toSHA,
 	}
 
+	// Verify the fetched commits before exposing their policies
+	if result.HadChanges && r.verifier != nil {
+		signature, err := r.verifyRangeInternal(fromSHA, toSHA)
+		result.Signature = signature
+		if err != nil && r.verifier.Strict() {
+			r.metrics.RejectedCommits++
+			if resetErr := r.resetInternal(fromSHA); resetErr != nil {
+				return nil, fmt.Errorf("%w (reset to %s failed: %v)", err, shortSHA(fromSHA), resetErr)
+			}
+			return nil, err
+		}
+	}
+
 	// Get changed files if there were changes
 	if result.HadChanges {
 		changedFiles, err := r.getChangedFilesInternal(fromSHA, toSHA)
@@ -207,6 +271,152 @@ func (r *Repository) Pull(ctx context.Context) (*PullResult, error) {
 	return result, nil
 }
 
+// VerifyCommit verifies the signature of a commit against the configured
+// allowed signers. It returns an error if verification is not configured.
+// This method is thread-safe and can be called concurrently.
+func (r *Repository) VerifyCommit(sha string) (*SignatureInfo, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.verifier == nil {
+		return nil, fmt.Errorf("signature verification is not configured")
+	}
+	return r.verifyCommitInternal(sha)
+}
+
+// verifyCommitInternal verifies a commit without acquiring locks.
+func (r *Repository) verifyCommitInternal(sha string) (*SignatureInfo, error) {
+	if r.repo == nil {
+		return nil, fmt.Errorf("repository not initialized")
+	}
+
+	commit, err := r.repo.CommitObject(plumbing.NewHash(sha))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commit: %w", err)
+	}
+	return r.verifier.Verify(commit)
+}
+
+// verifyHeadInternal verifies the HEAD commit after a clone or open.
+// Only strict verification failures are returned; in warn mode they are logged.
+func (r *Repository) verifyHeadInternal() error {
+	if r.verifier == nil {
+		return nil
+	}
+
+	ref, err := r.repo.Head()
+	if err != nil {
+		return fmt.Errorf("failed to get HEAD: %w", err)
+	}
+
+	signature, err := r.verifyCommitInternal(ref.Hash().String())
+	if err != nil {
+		if r.verifier.Strict() {
+			r.metrics.RejectedCommits++
+			return err
+		}
+		r.warnUnverified(signature, err)
+	}
+	return nil
+}
+
+// verifyRangeInternal verifies every commit reachable from toSHA but not from
+// fromSHA, as listed by "git rev-list fromSHA..toSHA". It returns the result
+// for the first commit that failed verification, or for toSHA if all passed.
+// In warn mode every failure is logged and the returned error is that of the
+// first failure.
+func (r *Repository) verifyRangeInternal(fromSHA, toSHA string) (*SignatureInfo, error) {
+	excluded := make(map[plumbing.Hash]bool)
+	if err := r.walkCommits(plumbing.NewHash(fromSHA), nil, func(commit *object.Commit) {
+		excluded[commit.Hash] = true
+	}); err != nil {
+		return nil, err
+	}
+
+	var commits []*object.Commit
+	if err := r.walkCommits(plumbing.NewHash(toSHA), excluded, func(commit *object.Commit) {
+		commits = append(commits, commit)
+	}); err != nil {
+		return nil, err
+	}
+
+	var (
+		result   *SignatureInfo
+		firstErr error
+	)
+	for _, commit := range commits {
+		signature, err := r.verifier.Verify(commit)
+		if err == nil {
+			if result == nil && commit.Hash.String() == toSHA {
+				result = signature
+			}
+			continue
+		}
+		if r.verifier.Strict() {
+			return signature, err
+		}
+		r.warnUnverified(signature, err)
+		if firstErr == nil {
+			result, firstErr = signature, err
+		}
+	}
+	return result, firstErr
+}
+
+// walkCommits visits start and its ancestors breadth first, skipping commits
+// in stop and their ancestors. Parents missing from a shallow clone end the walk
+// along that path.
+func (r *Repository) walkCommits(start plumbing.Hash, stop map[plumbing.Hash]bool, visit func(*object.Commit)) error {
+	seen := map[plumbing.Hash]bool{start: true}
+	queue := []plumbing.Hash{start}
+	for i := 0; i < len(queue); i++ {
+		if stop[queue[i]] {
+			continue
+		}
+		commit, err := r.repo.CommitObject(queue[i])
+		if errors.Is(err, plumbing.ErrObjectNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get commit %s: %w", shortSHA(queue[i].String()), err)
+		}
+		visit(commit)
+		for _, parent := range commit.ParentHashes {
+			if !seen[parent] {
+				seen[parent] = true
+				queue = append(queue, parent)
+			}
+		}
+	}
+	return nil
+}
+
+// warnUnverified logs a commit that failed verification and is applied
+// because verification runs in warn mode.
+func (r *Repository) warnUnverified(signature *SignatureInfo, err error) {
+	attrs := []any{"error", err}
+	if signature != nil {
+		attrs = append(attrs,
+			"sha", signature.SHA,
+			"method", signature.Method,
+			"signer", signature.Signer,
+		)
+	}
+	r.logger.Warn("applying commit that failed signature verification", attrs...)
+}
+
+// resetInternal hard-resets the current branch to a commit without acquiring locks.
+func (r *Repository) resetInternal(sha string) error {
+	worktree, err := r.repo.Worktree()
+	if err != nil {
+		return fmt.Errorf("failed to get worktree: %w", err)
+	}
+	return worktree.ResetSparsely(&gogit.ResetOptions{
+		Commit: plumbing.NewHash(sha),
+		Mode:   gogit.HardReset,
+	}, r.sparseDirs())
+}
+
 // GetCurrentCommit returns metadata about the current HEAD commit.
 // This includes commit SHA, author, timestamp, message, and branch information.
 // This method is thread-safe and can be called concurrently.
@@ -372,7 +582,8 @@ func (r *Repository) SwitchBranch(branch string) error {
 	}
 
 	err = worktree.Checkout(&gogit.CheckoutOptions{
-		Branch: plumbing.NewBranchReferenceName(branch),
+		Branch:                    plumbing.NewBranchReferenceName(branch),
+		SparseCheckoutDirectories: r.sparseDirs(),
 	})
 	if err != nil {
 		return fmt.Errorf("failed to checkout branch %s: %w", branch, err)
@@ -411,7 +622,8 @@ func (r *Repository) Rollback(ctx context.Context, targetSHA string) error {
 	}
 
 	err = worktree.Checkout(&gogit.CheckoutOptions{
-		Hash: targetHash,
+		Hash:                      targetHash,
+		SparseCheckoutDirectories: r.sparseDirs(),
 	})
 	if err != nil {
 		return fmt.Errorf("failed to checkout commit %s: %w", targetSHA, err)
@@ -21,6 +21,11 @@ type PullResult struct {
 	ToSHA        string
 	ChangedFiles []string
 	HadChanges   bool
+
+	// Signature is the verification result for the pulled commits, if
+	// verification is configured: the first commit between FromSHA and ToSHA
+	// that failed verification (warn mode only), or ToSHA if all passed.
+	Signature *SignatureInfo
 }
 
 // RepositoryMetrics tracks Git operation metrics.
@@ -31,6 +36,7 @@ type RepositoryMetrics struct {
 	LastPullTime    time.Time
 	FailedPulls     int64
 	SuccessfulPulls int64
+	RejectedCommits int64
 }
 
 // CommitHistory tracks policy version history.
@@ -0,0 +1,357 @@
+package git
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"strings"
+
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	pgperrors "github.com/ProtonMail/go-crypto/openpgp/errors"
+	"github.com/ProtonMail/go-crypto/openpgp/packet"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"golang.org/x/crypto/ssh"
+
+	"mercator-hq/jupiter/pkg/config"
+)
+
+// Signature methods reported in SignatureInfo.
+const (
+	SignatureMethodGPG = "gpg"
+	SignatureMethodSSH = "ssh"
+)
+
+var (
+	// ErrUnsignedCommit is returned when a commit carries no signature.
+	ErrUnsignedCommit = errors.New("commit is not signed")
+
+	// ErrUnknownSigner is returned when a commit is signed by a key that is
+	// not in the allowlist.
+	ErrUnknownSigner = errors.New("commit signed by unknown key")
+)
+
+// SignatureInfo describes the outcome of verifying a commit signature.
+// For a verified commit Signer is the allowed signer (SSH principals or GPG
+// key fingerprint); for an unknown signer it identifies the signing key.
+type SignatureInfo struct {
+	SHA      string `json:"sha"`
+	Method   string `json:"method,omitempty"`
+	Signer   string `json:"signer,omitempty"`
+	Verified bool   `json:"verified"`
+	Error    string `json:"error,omitempty"`
+}
+
+// SignatureError is returned when a commit is rejected by signature verification.
+type SignatureError struct {
+	SHA string
+	Err error
+}
+
+func (e *SignatureError) Error() string {
+	return fmt.Sprintf("signature verification failed for commit %s: %v", shortSHA(e.SHA), e.Err)
+}
+
+func (e *SignatureError) Unwrap() error {
+	return e.Err
+}
+
+// allowedSigner is an entry from an allowed signers file.
+type allowedSigner struct {
+	principals string
+	key        ssh.PublicKey
+}
+
+// Verifier checks commit signatures against an allowlist of GPG and SSH keys.
+type Verifier struct {
+	gpgKeyring string
+	sshSigners []allowedSigner
+	strict     bool
+}
+
+// NewVerifier creates a verifier from configuration, loading the GPG keyring
+// and SSH allowed signers files.
+func NewVerifier(cfg *config.GitVerifyConfig) (*Verifier, error) {
+	if cfg.GPGKeyringPath == "" && cfg.SSHAllowedSignersPath == "" {
+		return nil, fmt.Errorf("no allowed signers configured")
+	}
+
+	v := &Verifier{strict: cfg.Mode != "warn"}
+
+	if cfg.GPGKeyringPath != "" {
+		data, err := os.ReadFile(cfg.GPGKeyringPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read GPG keyring: %w", err)
+		}
+		v.gpgKeyring = string(data)
+	}
+
+	if cfg.SSHAllowedSignersPath != "" {
+		data, err := os.ReadFile(cfg.SSHAllowedSignersPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read SSH allowed signers: %w", err)
+		}
+		signers, err := parseAllowedSigners(data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse SSH allowed signers: %w", err)
+		}
+		v.sshSigners = signers
+	}
+
+	return v, nil
+}
+
+// Strict returns true if verification failures reject the commit.
+func (v *Verifier) Strict() bool {
+	return v.strict
+}
+
+// Verify checks the signature of a commit. It returns the signature details
+// and a non-nil error if the commit is unsigned, signed by an unknown key,
+// or carries an invalid signature.
+func (v *Verifier) Verify(commit *object.Commit) (*SignatureInfo, error) {
+	info := &SignatureInfo{SHA: commit.Hash.String()}
+
+	err := v.verify(commit, info)
+	if err != nil {
+		info.Error = err.Error()
+		return info, &SignatureError{SHA: info.SHA, Err: err}
+	}
+
+	info.Verified = true
+	return info, nil
+}
+
+// verify dispatches to the GPG or SSH verifier based on the signature armor.
+func (v *Verifier) verify(commit *object.Commit, info *SignatureInfo) error {
+	signature := strings.TrimSpace(commit.PGPSignature)
+	switch {
+	case signature == "":
+		return ErrUnsignedCommit
+
+	case strings.HasPrefix(signature, "-----BEGIN SSH SIGNATURE-----"):
+		info.Method = SignatureMethodSSH
+		message, err := encodeWithoutSignature(commit)
+		if err != nil {
+			return err
+		}
+		signer, err := verifySSHSignature(signature, message, v.sshSigners)
+		info.Signer = signer
+		return err
+
+	default:
+		info.Method = SignatureMethodGPG
+		if v.gpgKeyring == "" {
+			info.Signer = gpgIssuer(signature)
+			return ErrUnknownSigner
+		}
+		entity, err := commit.Verify(v.gpgKeyring)
+		if err != nil {
+			if errors.Is(err, pgperrors.ErrUnknownIssuer) {
+				info.Signer = gpgIssuer(signature)
+				return ErrUnknownSigner
+			}
+			return fmt.Errorf("invalid GPG signature: %w", err)
+		}
+		info.Signer = strings.ToUpper(hex.EncodeToString(entity.PrimaryKey.Fingerprint))
+		return nil
+	}
+}
+
+// gpgIssuer returns the issuer key of an armored GPG signature, or "" if it
+// cannot be read.
+func gpgIssuer(armored string) string {
+	block, err := armor.Decode(strings.NewReader(armored))
+	if err != nil {
+		return ""
+	}
+	p, err := packet.Read(block.Body)
+	if err != nil {
+		return ""
+	}
+	sig, ok := p.(*packet.Signature)
+	if !ok {
+		return ""
+	}
+	if len(sig.IssuerFingerprint) > 0 {
+		return strings.ToUpper(hex.EncodeToString(sig.IssuerFingerprint))
+	}
+	if sig.IssuerKeyId != nil {
+		return fmt.Sprintf("%016X", *sig.IssuerKeyId)
+	}
+	return ""
+}
+
+// encodeWithoutSignature returns the commit payload that was signed.
+func encodeWithoutSignature(commit *object.Commit) ([]byte, error) {
+	encoded := &plumbing.MemoryObject{}
+	if err := commit.EncodeWithoutSignature(encoded); err != nil {
+		return nil, fmt.Errorf("failed to encode commit: %w", err)
+	}
+	reader, err := encoded.Reader()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encoded commit: %w", err)
+	}
+	defer reader.Close()
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(reader); err != nil {
+		return nil, fmt.Errorf("failed to read encoded commit: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// parseAllowedSigners parses an allowed signers file. Each line has the form
+//
+//	principals [options] keytype base64-key [comment]
+//
+// Entries restricted by a namespaces option that excludes "git" are ignored.
+func parseAllowedSigners(data []byte) ([]allowedSigner, error) {
+	var signers []allowedSigner
+
+	for i, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		principals, rest, ok := strings.Cut(line, " ")
+		if !ok {
+			return nil, fmt.Errorf("line %d: missing public key", i+1)
+		}
+
+		key, _, options, _, err := ssh.ParseAuthorizedKey([]byte(strings.TrimSpace(rest)))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		if !allowsGitNamespace(options) {
+			continue
+		}
+
+		signers = append(signers, allowedSigner{principals: principals, key: key})
+	}
+
+	return signers, nil
+}
+
+// allowsGitNamespace returns false if a namespaces option excludes "git".
+func allowsGitNamespace(options []string) bool {
+	for _, option := range options {
+		value, ok := strings.CutPrefix(option, "namespaces=")
+		if !ok {
+			continue
+		}
+		for _, ns := range strings.Split(strings.Trim(value, `"`), ",") {
+			if ns == "git" {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// sshSigMagic is the preamble of SSH signatures (see OpenSSH PROTOCOL.sshsig).
+const sshSigMagic = "SSHSIG"
+
+// sshSignature is the SSHSIG blob that follows the magic preamble.
+type sshSignature struct {
+	Version       uint32
+	PublicKey     []byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Signature     []byte
+}
+
+// sshSignedData is the structure that is actually signed.
+type sshSignedData struct {
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Hash          []byte
+}
+
+// verifySSHSignature verifies an armored SSH signature over message and
+// returns the principals of the matching allowed signer. If the key is not
+// allowed, it returns the key fingerprint with ErrUnknownSigner.
+func verifySSHSignature(armored string, message []byte, signers []allowedSigner) (string, error) {
+	block, _ := pem.Decode([]byte(armored))
+	if block == nil || block.Type != "SSH SIGNATURE" {
+		return "", fmt.Errorf("invalid SSH signature armor")
+	}
+	if !bytes.HasPrefix(block.Bytes, []byte(sshSigMagic)) {
+		return "", fmt.Errorf("invalid SSH signature preamble")
+	}
+
+	var sig sshSignature
+	if err := ssh.Unmarshal(block.Bytes[len(sshSigMagic):], &sig); err != nil {
+		return "", fmt.Errorf("invalid SSH signature: %w", err)
+	}
+	if sig.Version != 1 {
+		return "", fmt.Errorf("unsupported SSH signature version %d", sig.Version)
+	}
+	if sig.Namespace != "git" {
+		return "", fmt.Errorf("unexpected SSH signature namespace %q", sig.Namespace)
+	}
+
+	publicKey, err := ssh.ParsePublicKey(sig.PublicKey)
+	if err != nil {
+		return "", fmt.Errorf("invalid SSH signature public key: %w", err)
+	}
+
+	var principals string
+	found := false
+	for _, signer := range signers {
+		if bytes.Equal(signer.key.Marshal(), publicKey.Marshal()) {
+			principals = signer.principals
+			found = true
+			break
+		}
+	}
+	if !found {
+		return ssh.FingerprintSHA256(publicKey), ErrUnknownSigner
+	}
+
+	var h hash.Hash
+	switch sig.HashAlgorithm {
+	case "sha256":
+		h = sha256.New()
+	case "sha512":
+		h = sha512.New()
+	default:
+		return "", fmt.Errorf("unsupported SSH signature hash %q", sig.HashAlgorithm)
+	}
+	h.Write(message)
+
+	signed := append([]byte(sshSigMagic), ssh.Marshal(sshSignedData{
+		Namespace:     sig.Namespace,
+		Reserved:      sig.Reserved,
+		HashAlgorithm: sig.HashAlgorithm,
+		Hash:          h.Sum(nil),
+	})...)
+
+	var signature ssh.Signature
+	if err := ssh.Unmarshal(sig.Signature, &signature); err != nil {
+		return "", fmt.Errorf("invalid SSH signature: %w", err)
+	}
+	if err := publicKey.Verify(signed, &signature); err != nil {
+		return "", fmt.Errorf("invalid SSH signature: %w", err)
+	}
+
+	return principals, nil
+}
+
+// shortSHA abbreviates a commit SHA for messages.
+func shortSHA(sha string) string {
+	if len(sha) > 8 {
+		return sha[:8]
+	}
+	return sha
+}
@@ -0,0 +1,384 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/pem"
+	"errors"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ProtonMail/go-crypto/openpgp"
+	"github.com/ProtonMail/go-crypto/openpgp/armor"
+	gogit "github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"golang.org/x/crypto/ssh"
+
+	"mercator-hq/jupiter/pkg/config"
+)
+
+// sshTestSigner signs commits with an SSH key in the SSHSIG format used by git.
+type sshTestSigner struct {
+	signer ssh.Signer
+}
+
+func newSSHTestSigner(t *testing.T) *sshTestSigner {
+	t.Helper()
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	return &sshTestSigner{signer: signer}
+}
+
+func (s *sshTestSigner) Sign(message io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(message)
+	if err != nil {
+		return nil, err
+	}
+	digest := sha512.Sum512(data)
+	signed := append([]byte(sshSigMagic), ssh.Marshal(sshSignedData{
+		Namespace:     "git",
+		HashAlgorithm: "sha512",
+		Hash:          digest[:],
+	})...)
+	sig, err := s.signer.Sign(rand.Reader, signed)
+	if err != nil {
+		return nil, err
+	}
+	blob := append([]byte(sshSigMagic), ssh.Marshal(sshSignature{
+		Version:       1,
+		PublicKey:     s.signer.PublicKey().Marshal(),
+		Namespace:     "git",
+		HashAlgorithm: "sha512",
+		Signature:     ssh.Marshal(sig),
+	})...)
+	return pem.EncodeToMemory(&pem.Block{Type: "SSH SIGNATURE", Bytes: blob}), nil
+}
+
+// allowedSignersFile writes an allowed signers file for the given signers.
+func allowedSignersFile(t *testing.T, signers ...*sshTestSigner) string {
+	t.Helper()
+	var buf bytes.Buffer
+	buf.WriteString("# policy signers\n")
+	for _, s := range signers {
+		buf.WriteString("policy@example.com ")
+		buf.Write(ssh.MarshalAuthorizedKey(s.signer.PublicKey()))
+	}
+	path := filepath.Join(t.TempDir(), "allowed_signers")
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		t.Fatalf("failed to write allowed signers: %v", err)
+	}
+	return path
+}
+
+// commitFile adds a file to the repository and commits it with the given options.
+func commitFile(t *testing.T, repo *gogit.Repository, dir, name string, opts *gogit.CommitOptions) string {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(name), 0644); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatalf("failed to get worktree: %v", err)
+	}
+	if _, err := worktree.Add(name); err != nil {
+		t.Fatalf("failed to add file: %v", err)
+	}
+	opts.Author = &object.Signature{Name: "Test User", Email: "test@example.com", When: time.Now()}
+	hash, err := worktree.Commit("add "+name, opts)
+	if err != nil {
+		t.Fatalf("failed to commit: %v", err)
+	}
+	return hash.String()
+}
+
+func TestVerifier_SSHSignatures(t *testing.T) {
+	dir := t.TempDir()
+	repo := createTestRepo(t, dir)
+
+	trusted := newSSHTestSigner(t)
+	untrusted := newSSHTestSigner(t)
+
+	v, err := NewVerifier(&config.GitVerifyConfig{
+		SSHAllowedSignersPath: allowedSignersFile(t, trusted),
+	})
+	if err != nil {
+		t.Fatalf("NewVerifier() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		signer  gogit.Signer
+		wantErr error
+	}{
+		{name: "allowed signer", signer: trusted},
+		{name: "unknown signer", signer: untrusted, wantErr: ErrUnknownSigner},
+		{name: "unsigned", wantErr: ErrUnsignedCommit},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &gogit.CommitOptions{}
+			if tt.signer != nil {
+				opts.Signer = tt.signer
+			}
+			sha := commitFile(t, repo, dir, strings.ReplaceAll(tt.name, " ", "-")+".mpl", opts)
+			commit, _ := repo.CommitObject(plumbing.NewHash(sha))
+
+			info, err := v.Verify(commit)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+				}
+				if info.Verified {
+					t.Error("expected Verified = false")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if !info.Verified || info.Method != SignatureMethodSSH || info.Signer != "policy@example.com" {
+				t.Errorf("unexpected signature info: %+v", info)
+			}
+		})
+	}
+}
+
+func TestVerifier_GPGSignatures(t *testing.T) {
+	dir := t.TempDir()
+	repo := createTestRepo(t, dir)
+
+	trusted, err := openpgp.NewEntity("Policy Signer", "", "policy@example.com", nil)
+	if err != nil {
+		t.Fatalf("failed to create entity: %v", err)
+	}
+	untrusted, err := openpgp.NewEntity("Someone Else", "", "other@example.com", nil)
+	if err != nil {
+		t.Fatalf("failed to create entity: %v", err)
+	}
+
+	var keyring bytes.Buffer
+	w, _ := armor.Encode(&keyring, openpgp.PublicKeyType, nil)
+	if err := trusted.Serialize(w); err != nil {
+		t.Fatalf("failed to serialize key: %v", err)
+	}
+	w.Close()
+	keyringPath := filepath.Join(t.TempDir(), "signers.asc")
+	os.WriteFile(keyringPath, keyring.Bytes(), 0644)
+
+	v, err := NewVerifier(&config.GitVerifyConfig{GPGKeyringPath: keyringPath})
+	if err != nil {
+		t.Fatalf("NewVerifier() error = %v", err)
+	}
+
+	sha := commitFile(t, repo, dir, "trusted.mpl", &gogit.CommitOptions{SignKey: trusted})
+	commit, _ := repo.CommitObject(plumbing.NewHash(sha))
+	info, err := v.Verify(commit)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if info.Method != SignatureMethodGPG || info.Signer == "" {
+		t.Errorf("unexpected signature info: %+v", info)
+	}
+
+	sha = commitFile(t, repo, dir, "untrusted.mpl", &gogit.CommitOptions{SignKey: untrusted})
+	commit, _ = repo.CommitObject(plumbing.NewHash(sha))
+	if _, err := v.Verify(commit); !errors.Is(err, ErrUnknownSigner) {
+		t.Errorf("Verify() error = %v, want ErrUnknownSigner", err)
+	}
+}
+
+func TestParseAllowedSigners_Namespaces(t *testing.T) {
+	gitSigner := newSSHTestSigner(t)
+	fileSigner := newSSHTestSigner(t)
+
+	data := "a@example.com namespaces=\"git,file\" " + string(ssh.MarshalAuthorizedKey(gitSigner.signer.PublicKey())) +
+		"b@example.com namespaces=\"file\" " + string(ssh.MarshalAuthorizedKey(fileSigner.signer.PublicKey()))
+
+	signers, err := parseAllowedSigners([]byte(data))
+	if err != nil {
+		t.Fatalf("parseAllowedSigners() error = %v", err)
+	}
+	if len(signers) != 1 || signers[0].principals != "a@example.com" {
+		t.Errorf("unexpected signers: %+v", signers)
+	}
+}
+
+func TestRepository_PullVerifiesSignatures(t *testing.T) {
+	signer := newSSHTestSigner(t)
+	signersPath := allowedSignersFile(t, signer)
+
+	newRepo := func(t *testing.T, sourceDir, mode string) *Repository {
+		t.Helper()
+		r, err := NewRepository(&config.GitPolicyConfig{
+			Repository: sourceDir,
+			Branch:     "master",
+			Auth:       config.GitAuthConfig{Type: "none"},
+			Poll:       config.GitPollConfig{Timeout: 10 * time.Second},
+			Clone:      config.GitCloneConfig{LocalPath: t.TempDir()},
+			Verify: config.GitVerifyConfig{
+				Enabled:               true,
+				Mode:                  mode,
+				SSHAllowedSignersPath: signersPath,
+			},
+		})
+		if err != nil {
+			t.Fatalf("NewRepository() error = %v", err)
+		}
+		if err := r.Clone(context.Background()); err != nil {
+			t.Fatalf("Clone() error = %v", err)
+		}
+		return r
+	}
+
+	t.Run("strict rejects unsigned commit", func(t *testing.T) {
+		sourceDir := t.TempDir()
+		source := createTestRepo(t, sourceDir)
+		signedSHA := commitFile(t, source, sourceDir, "signed.mpl", &gogit.CommitOptions{Signer: signer})
+
+		r := newRepo(t, sourceDir, "strict")
+
+		commitFile(t, source, sourceDir, "unsigned.mpl", &gogit.CommitOptions{})
+
+		_, err := r.Pull(context.Background())
+		var sigErr *SignatureError
+		if !errors.As(err, &sigErr) || !errors.Is(err, ErrUnsignedCommit) {
+			t.Fatalf("Pull() error = %v, want unsigned SignatureError", err)
+		}
+
+		current, err := r.GetCurrentCommit()
+		if err != nil {
+			t.Fatalf("GetCurrentCommit() error = %v", err)
+		}
+		if current.SHA != signedSHA {
+			t.Errorf("HEAD = %s, want %s", current.SHA, signedSHA)
+		}
+		if _, err := os.Stat(filepath.Join(r.GetLocalPath(), "unsigned.mpl")); !os.IsNotExist(err) {
+			t.Error("unsigned policy file should not be in the working tree")
+		}
+		if r.GetMetrics().RejectedCommits != 1 {
+			t.Errorf("RejectedCommits = %d, want 1", r.GetMetrics().RejectedCommits)
+		}
+	})
+
+	t.Run("strict accepts signed commit", func(t *testing.T) {
+		sourceDir := t.TempDir()
+		source := createTestRepo(t, sourceDir)
+		commitFile(t, source, sourceDir, "first.mpl", &gogit.CommitOptions{Signer: signer})
+
+		r := newRepo(t, sourceDir, "strict")
+		commitFile(t, source, sourceDir, "second.mpl", &gogit.CommitOptions{Signer: signer})
+
+		result, err := r.Pull(context.Background())
+		if err != nil {
+			t.Fatalf("Pull() error = %v", err)
+		}
+		if result.Signature == nil || !result.Signature.Verified {
+			t.Errorf("expected verified signature, got %+v", result.Signature)
+		}
+	})
+
+	t.Run("warn applies unsigned commit", func(t *testing.T) {
+		sourceDir := t.TempDir()
+		source := createTestRepo(t, sourceDir)
+
+		r := newRepo(t, sourceDir, "warn")
+		commitFile(t, source, sourceDir, "unsigned.mpl", &gogit.CommitOptions{})
+
+		result, err := r.Pull(context.Background())
+		if err != nil {
+			t.Fatalf("Pull() error = %v", err)
+		}
+		if !result.HadChanges || result.Signature == nil || result.Signature.Verified {
+			t.Errorf("expected unverified signature info, got %+v", result.Signature)
+		}
+	})
+
+	t.Run("strict rejects unsigned commit behind signed head", func(t *testing.T) {
+		sourceDir := t.TempDir()
+		source := createTestRepo(t, sourceDir)
+		signedSHA := commitFile(t, source, sourceDir, "first.mpl", &gogit.CommitOptions{Signer: signer})
+
+		r := newRepo(t, sourceDir, "strict")
+		unsignedSHA := commitFile(t, source, sourceDir, "unsigned.mpl", &gogit.CommitOptions{})
+		commitFile(t, source, sourceDir, "second.mpl", &gogit.CommitOptions{Signer: signer})
+
+		_, err := r.Pull(context.Background())
+		var sigErr *SignatureError
+		if !errors.As(err, &sigErr) || sigErr.SHA != unsignedSHA {
+			t.Fatalf("Pull() error = %v, want SignatureError for %s", err, shortSHA(unsignedSHA))
+		}
+		current, _ := r.GetCurrentCommit()
+		if current.SHA != signedSHA {
+			t.Errorf("HEAD = %s, want %s", current.SHA, signedSHA)
+		}
+	})
+
+	t.Run("warn logs every failed commit", func(t *testing.T) {
+		sourceDir := t.TempDir()
+		source := createTestRepo(t, sourceDir)
+
+		r := newRepo(t, sourceDir, "warn")
+		var logs bytes.Buffer
+		r.logger = slog.New(slog.NewTextHandler(&logs, nil))
+
+		unsignedSHA := commitFile(t, source, sourceDir, "unsigned.mpl", &gogit.CommitOptions{})
+		stranger := newSSHTestSigner(t)
+		strangerSHA := commitFile(t, source, sourceDir, "stranger.mpl", &gogit.CommitOptions{Signer: stranger})
+
+		result, err := r.Pull(context.Background())
+		if err != nil {
+			t.Fatalf("Pull() error = %v", err)
+		}
+		if result.Signature == nil || result.Signature.Verified {
+			t.Errorf("expected unverified signature info, got %+v", result.Signature)
+		}
+
+		output := logs.String()
+		if !strings.Contains(output, "sha="+unsignedSHA) {
+			t.Errorf("expected warning for unsigned commit, got:\n%s", output)
+		}
+		fingerprint := ssh.FingerprintSHA256(stranger.signer.PublicKey())
+		if !strings.Contains(output, "sha="+strangerSHA) || !strings.Contains(output, "signer="+fingerprint) {
+			t.Errorf("expected warning with signer %s, got:\n%s", fingerprint, output)
+		}
+	})
+
+	t.Run("strict clone rejects unsigned head", func(t *testing.T) {
+		sourceDir := t.TempDir()
+		createTestRepo(t, sourceDir)
+
+		r, err := NewRepository(&config.GitPolicyConfig{
+			Repository: sourceDir,
+			Branch:     "master",
+			Auth:       config.GitAuthConfig{Type: "none"},
+			Poll:       config.GitPollConfig{Timeout: 10 * time.Second},
+			Clone:      config.GitCloneConfig{LocalPath: t.TempDir()},
+			Verify: config.GitVerifyConfig{
+				Enabled:               true,
+				SSHAllowedSignersPath: signersPath,
+			},
+		})
+		if err != nil {
+			t.Fatalf("NewRepository() error = %v", err)
+		}
+		if err := r.Clone(context.Background()); !errors.Is(err, ErrUnsignedCommit) {
+			t.Errorf("Clone() error = %v, want ErrUnsignedCommit", err)
+		}
+	})
+}
@@ -0,0 +1,161 @@
+package processing
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"mercator-hq/jupiter/pkg/config"
+	"mercator-hq/jupiter/pkg/mpl/ast"
+	"mercator-hq/jupiter/pkg/mpl/validator"
+)
+
+// AnalyzerFields are the fields an analyzer contributes to an enriched
+// request or response, by field name.
+type AnalyzerFields map[string]interface{}
+
+// Analyzer is a custom analyzer of the processing pipeline. Its fields are
+// added to enriched requests and responses, where policies reference them as
+// request.analyzers.<name>.<field> and response.analyzers.<name>.<field>.
+// Implementations must be safe for concurrent use.
+type Analyzer interface {
+	// Name identifies the analyzer in policy fields.
+	Name() string
+
+	// Fields returns the fields the analyzer contributes and their types.
+	// Other fields returned by the analyzer are dropped.
+	Fields() map[string]ast.ValueType
+
+	// AnalyzeRequest returns the fields of an enriched request. It is called
+	// once the built-in analysis is done. It returns nil fields if the
+	// analyzer does not analyze requests.
+	AnalyzeRequest(ctx context.Context, req *EnrichedRequest) (AnalyzerFields, error)
+
+	// AnalyzeResponse returns the fields of an enriched response. It is
+	// called once the built-in analysis is done. It returns nil fields if
+	// the analyzer does not analyze responses.
+	AnalyzeResponse(ctx context.Context, resp *EnrichedResponse) (AnalyzerFields, error)
+}
+
+// AnalyzerFactory creates an analyzer from its configuration.
+type AnalyzerFactory func(cfg *config.AnalyzerConfig) (Analyzer, error)
+
+var (
+	// analyzerTypesMu protects analyzerTypes
+	analyzerTypesMu sync.RWMutex
+
+	// analyzerTypes are the analyzer factories by analyzer type
+	analyzerTypes = map[string]AnalyzerFactory{
+		"http": newHTTPAnalyzer,
+	}
+)
+
+// RegisterAnalyzerType registers a Go analyzer type, so that analyzers of
+// that type can be configured in processing.analyzers. It is typically
+// called from an init function. It panics if the type is empty or already
+// registered, or factory is nil.
+func RegisterAnalyzerType(typ string, factory AnalyzerFactory) {
+	analyzerTypesMu.Lock()
+	defer analyzerTypesMu.Unlock()
+
+	if typ == "" || factory == nil {
+		panic("processing: invalid analyzer type registration")
+	}
+	if _, ok := analyzerTypes[typ]; ok {
+		panic(fmt.Sprintf("processing: analyzer type %q already registered", typ))
+	}
+	analyzerTypes[typ] = factory
+}
+
+// NewAnalyzer creates a configured analyzer with the factory of its type.
+func NewAnalyzer(cfg *config.AnalyzerConfig) (Analyzer, error) {
+	analyzerTypesMu.RLock()
+	factory, ok := analyzerTypes[cfg.Type]
+	analyzerTypesMu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("unknown analyzer type %q", cfg.Type)
+	}
+	return factory(cfg)
+}
+
+// pluggedAnalyzer is an analyzer added to a processor.
+type pluggedAnalyzer struct {
+	Analyzer
+	fields  map[string]ast.ValueType
+	timeout time.Duration
+}
+
+// AddAnalyzer adds a custom analyzer to the processing pipeline and its
+// fields to the MPL data model. Each analysis is canceled after timeout, if
+// positive. It must be called before the processor is used.
+func (p *Processor) AddAnalyzer(analyzer Analyzer, timeout time.Duration) {
+	fields := analyzer.Fields()
+	validator.RegisterAnalyzerFields(analyzer.Name(), fields)
+	p.analyzers = append(p.analyzers, &pluggedAnalyzer{
+		Analyzer: analyzer,
+		fields:   fields,
+		timeout:  timeout,
+	})
+}
+
+// runAnalyzers runs the custom analyzers concurrently with analyze and
+// returns their fields by analyzer name. Analyzers that fail or return no
+// fields are left out.
+func (p *Processor) runAnalyzers(stage string, analyze func(ctx context.Context, analyzer Analyzer) (AnalyzerFields, error)) map[string]AnalyzerFields {
+	if len(p.analyzers) == 0 {
+		return nil
+	}
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]AnalyzerFields, len(p.analyzers))
+	)
+	for _, analyzer := range p.analyzers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ctx := context.Background()
+			if analyzer.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, analyzer.timeout)
+				defer cancel()
+			}
+
+			fields, err := analyze(ctx, analyzer.Analyzer)
+			if err != nil {
+				slog.Warn("analyzer failed",
+					"analyzer", analyzer.Name(),
+					"stage", stage,
+					"error", err,
+				)
+				return
+			}
+
+			// Keep the declared fields only
+			declared := make(AnalyzerFields, len(fields))
+			for name, value := range fields {
+				if _, ok := analyzer.fields[name]; ok {
+					declared[name] = value
+				}
+			}
+			if len(declared) == 0 {
+				return
+			}
+
+			mu.Lock()
+			defer mu.Unlock()
+			results[analyzer.Name()] = declared
+		}()
+	}
+	wg.Wait()
+
+	if len(results) == 0 {
+		return nil
+	}
+	return results
+}
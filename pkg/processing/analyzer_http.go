@@ -0,0 +1,149 @@
+package processing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+
+	"mercator-hq/jupiter/pkg/config"
+	"mercator-hq/jupiter/pkg/mpl/ast"
+	"mercator-hq/jupiter/pkg/proxy/types"
+)
+
+// maxAnalyzerResponseSize is the maximum size of an http analyzer response.
+const maxAnalyzerResponseSize = 1 << 20
+
+// httpAnalyzer is an external analyzer called over HTTP. It POSTs a JSON
+// analysis request to its URL and expects {"fields": {...}} in return.
+type httpAnalyzer struct {
+	name   string
+	url    string
+	apiKey string
+	stages []string
+	fields map[string]ast.ValueType
+	client *http.Client
+}
+
+// httpAnalysisRequest is the body of an http analyzer request.
+type httpAnalysisRequest struct {
+	// Stage is "request" or "response".
+	Stage string `json:"stage"`
+
+	RequestID string `json:"request_id"`
+	Model     string `json:"model,omitempty"`
+
+	// Messages are the messages of the request (request stage).
+	Messages []types.Message `json:"messages,omitempty"`
+
+	// Content is the response content, including tool call arguments
+	// (response stage).
+	Content string `json:"content,omitempty"`
+
+	// RiskScore is the risk score of the request (request stage).
+	RiskScore int `json:"risk_score,omitempty"`
+}
+
+// httpAnalysisResponse is the body of an http analyzer response.
+type httpAnalysisResponse struct {
+	Fields AnalyzerFields `json:"fields"`
+}
+
+// newHTTPAnalyzer creates an http analyzer.
+func newHTTPAnalyzer(cfg *config.AnalyzerConfig) (Analyzer, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("analyzer %s: url is required", cfg.Name)
+	}
+
+	fields := make(map[string]ast.ValueType, len(cfg.Fields))
+	for name, typ := range cfg.Fields {
+		fields[name] = ast.ValueType(typ)
+	}
+
+	return &httpAnalyzer{
+		name:   cfg.Name,
+		url:    cfg.URL,
+		apiKey: cfg.APIKey,
+		stages: cfg.Stages,
+		fields: fields,
+		client: &http.Client{},
+	}, nil
+}
+
+func (a *httpAnalyzer) Name() string {
+	return a.name
+}
+
+func (a *httpAnalyzer) Fields() map[string]ast.ValueType {
+	return a.fields
+}
+
+func (a *httpAnalyzer) AnalyzeRequest(ctx context.Context, req *EnrichedRequest) (AnalyzerFields, error) {
+	if !slices.Contains(a.stages, "request") {
+		return nil, nil
+	}
+
+	body := &httpAnalysisRequest{
+		Stage:     "request",
+		RequestID: req.RequestID,
+		RiskScore: req.RiskScore,
+	}
+	if req.OriginalRequest != nil {
+		body.Model = req.OriginalRequest.Model
+		body.Messages = req.OriginalRequest.Messages
+	}
+	return a.analyze(ctx, body)
+}
+
+func (a *httpAnalyzer) AnalyzeResponse(ctx context.Context, resp *EnrichedResponse) (AnalyzerFields, error) {
+	if !slices.Contains(a.stages, "response") {
+		return nil, nil
+	}
+
+	body := &httpAnalysisRequest{
+		Stage:     "response",
+		RequestID: resp.RequestID,
+	}
+	if resp.OriginalResponse != nil {
+		body.Model = resp.OriginalResponse.Model
+		body.Content = combineResponseContent(resp.OriginalResponse)
+	}
+	return a.analyze(ctx, body)
+}
+
+// analyze posts an analysis request and returns the fields of the response.
+func (a *httpAnalyzer) analyze(ctx context.Context, body *httpAnalysisRequest) (AnalyzerFields, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode analysis request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.url, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create analysis request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if a.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+a.apiKey)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("analysis request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("analyzer returned status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	var result httpAnalysisResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxAnalyzerResponseSize)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode analysis response: %w", err)
+	}
+	return result.Fields, nil
+}
@@ -0,0 +1,183 @@
+package processing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/config"
+	"mercator-hq/jupiter/pkg/mpl/ast"
+	"mercator-hq/jupiter/pkg/mpl/validator"
+	"mercator-hq/jupiter/pkg/providers"
+	"mercator-hq/jupiter/pkg/proxy"
+	"mercator-hq/jupiter/pkg/proxy/types"
+)
+
+// stubAnalyzer is an analyzer returning fixed request fields.
+type stubAnalyzer struct {
+	name   string
+	fields AnalyzerFields
+	err    error
+}
+
+func (a *stubAnalyzer) Name() string {
+	return a.name
+}
+
+func (a *stubAnalyzer) Fields() map[string]ast.ValueType {
+	return map[string]ast.ValueType{"label": ast.ValueTypeString, "score": ast.ValueTypeNumber}
+}
+
+func (a *stubAnalyzer) AnalyzeRequest(ctx context.Context, req *EnrichedRequest) (AnalyzerFields, error) {
+	return a.fields, a.err
+}
+
+func (a *stubAnalyzer) AnalyzeResponse(ctx context.Context, resp *EnrichedResponse) (AnalyzerFields, error) {
+	return nil, nil
+}
+
+func TestProcessor_AddAnalyzer(t *testing.T) {
+	processor := NewProcessor(&config.ProcessingConfig{})
+	processor.AddAnalyzer(&stubAnalyzer{
+		name:   "stub",
+		fields: AnalyzerFields{"label": "finance", "score": 0.9, "undeclared": true},
+	}, time.Second)
+	processor.AddAnalyzer(&stubAnalyzer{name: "broken", err: errors.New("unavailable")}, time.Second)
+
+	req := &types.ChatCompletionRequest{
+		Model:    "gpt-4",
+		Messages: []types.Message{{Role: "user", Content: "What is our revenue?"}},
+	}
+	enriched, err := processor.ProcessRequest(&proxy.RequestMetadata{RequestID: "req-1"}, req)
+	if err != nil {
+		t.Fatalf("ProcessRequest() error = %v", err)
+	}
+
+	fields, ok := enriched.Analyzers["stub"]
+	if !ok {
+		t.Fatalf("expected stub analyzer fields, got %v", enriched.Analyzers)
+	}
+	if fields["label"] != "finance" || fields["score"] != 0.9 {
+		t.Errorf("unexpected fields: %v", fields)
+	}
+	if _, ok := fields["undeclared"]; ok {
+		t.Error("expected undeclared field to be dropped")
+	}
+	if _, ok := enriched.Analyzers["broken"]; ok {
+		t.Error("expected no fields of the failed analyzer")
+	}
+
+	if field, ok := validator.LookupField("request.analyzers.stub.score"); !ok || field.Type != ast.ValueTypeNumber {
+		t.Errorf("expected request.analyzers.stub.score in the data model, got %v", field)
+	}
+}
+
+func TestHTTPAnalyzer(t *testing.T) {
+	var stages []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("unexpected authorization %q", got)
+		}
+		var body httpAnalysisRequest
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode analysis request: %v", err)
+		}
+		stages = append(stages, body.Stage)
+
+		switch body.Stage {
+		case "request":
+			if len(body.Messages) != 1 || body.Model != "gpt-4" {
+				t.Errorf("unexpected analysis request: %+v", body)
+			}
+			_, _ = w.Write([]byte(`{"fields": {"toxic": false, "score": 0.2}}`))
+		case "response":
+			if body.Content != "Hello" {
+				t.Errorf("unexpected response content %q", body.Content)
+			}
+			_, _ = w.Write([]byte(`{"fields": {"toxic": true, "score": 0.95}}`))
+		}
+	}))
+	defer server.Close()
+
+	cfg := &config.ProcessingConfig{
+		Analyzers: []config.AnalyzerConfig{{
+			Name:    "toxicity",
+			Type:    "http",
+			URL:     server.URL,
+			APIKey:  "secret",
+			Stages:  []string{"request", "response"},
+			Fields:  map[string]string{"toxic": "boolean", "score": "number"},
+			Timeout: time.Second,
+		}},
+	}
+	processor := NewProcessor(cfg)
+
+	req := &types.ChatCompletionRequest{
+		Model:    "gpt-4",
+		Messages: []types.Message{{Role: "user", Content: "Hi"}},
+	}
+	enrichedReq, err := processor.ProcessRequest(&proxy.RequestMetadata{RequestID: "req-1"}, req)
+	if err != nil {
+		t.Fatalf("ProcessRequest() error = %v", err)
+	}
+	if got := enrichedReq.Analyzers["toxicity"]; got["toxic"] != false || got["score"] != 0.2 {
+		t.Errorf("unexpected request fields: %v", got)
+	}
+
+	resp := &providers.CompletionResponse{Model: "gpt-4", Content: "Hello", FinishReason: "stop"}
+	enrichedResp, err := processor.ProcessResponse("req-1", &proxy.ResponseMetadata{}, resp)
+	if err != nil {
+		t.Fatalf("ProcessResponse() error = %v", err)
+	}
+	if got := enrichedResp.Analyzers["toxicity"]; got["toxic"] != true || got["score"] != 0.95 {
+		t.Errorf("unexpected response fields: %v", got)
+	}
+
+	if len(stages) != 2 {
+		t.Errorf("expected 2 analysis requests, got %v", stages)
+	}
+}
+
+func TestHTTPAnalyzer_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+		_, _ = w.Write([]byte(`{"fields": {"toxic": true}}`))
+	}))
+	defer server.Close()
+
+	processor := NewProcessor(&config.ProcessingConfig{
+		Analyzers: []config.AnalyzerConfig{{
+			Name:    "slow",
+			Type:    "http",
+			URL:     server.URL,
+			Stages:  []string{"request"},
+			Fields:  map[string]string{"toxic": "boolean"},
+			Timeout: 20 * time.Millisecond,
+		}},
+	})
+
+	req := &types.ChatCompletionRequest{
+		Model:    "gpt-4",
+		Messages: []types.Message{{Role: "user", Content: "Hi"}},
+	}
+	enriched, err := processor.ProcessRequest(&proxy.RequestMetadata{RequestID: "req-1"}, req)
+	if err != nil {
+		t.Fatalf("ProcessRequest() error = %v", err)
+	}
+	if enriched.Analyzers != nil {
+		t.Errorf("expected no analyzer fields after timeout, got %v", enriched.Analyzers)
+	}
+}
+
+func TestNewAnalyzer_UnknownType(t *testing.T) {
+	if _, err := NewAnalyzer(&config.AnalyzerConfig{Name: "custom", Type: "unknown"}); err == nil {
+		t.Error("expected error for unknown analyzer type")
+	}
+}
@@ -1,7 +1,9 @@
 package content
 
 import (
+	"log/slog"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 
@@ -9,13 +11,39 @@ import (
 )
 
 // Analyzer performs content analysis on text including PII detection,
-// sensitive content detection, prompt injection detection, and sentiment analysis.
+// sensitive content detection, prompt injection detection, secret detection,
+// and sentiment analysis.
 type Analyzer struct {
 	config *config.ContentConfig
 
 	// Compiled regex patterns for performance
 	piiPatterns       map[string]*regexp.Regexp
-	injectionPatterns []*regexp.Regexp
+	customPII         []customPIIPattern
+	injectionPatterns []injectionPattern
+	secretDetectors   []*secretDetector
+	sensitive         []sensitiveCategory
+	entityMatchers    []entityMatcher
+
+	// feedPatterns are the injection patterns of the pattern feed, which
+	// complement the configured patterns (protected by mu)
+	feedPatterns []injectionPattern
+
+	// injectionClassifier complements the injection patterns (optional)
+	injectionClassifier InjectionClassifier
+	shadowSlots         chan struct{}
+
+	// externalDetector complements the PII patterns (optional)
+	externalDetector ExternalDetector
+
+	// entityRecognizer complements the gazetteer (optional)
+	entityRecognizer     EntityRecognizer
+	entityRecognizerOpts EntityRecognizerOptions
+
+	// topicEmbedder embeds content for the topic similarity checks
+	// (optional), and centroids caches the embedded topics
+	topicEmbedder Embedder
+	topicMu       sync.Mutex
+	centroids     []topicCentroid
 
 	// mu protects the analyzer for concurrent access
 	mu sync.RWMutex
@@ -30,16 +58,40 @@ func NewAnalyzer(cfg *config.ContentConfig) *Analyzer {
 
 	// Compile PII detection patterns
 	a.compilePIIPatterns()
+	a.compileCustomPIIPatterns()
 
 	// Compile injection detection patterns
 	a.compileInjectionPatterns()
 
+	// Select secret detectors
+	a.compileSecretDetectors()
+
+	// Compile sensitive content categories
+	a.compileSensitiveCategories()
+
+	// Compile the gazetteer and rules of named entity recognition
+	a.compileEntityMatchers()
+
 	return a
 }
 
 // AnalyzeText performs comprehensive content analysis on text.
-// Returns analysis results including PII, sensitive content, prompt injection, and sentiment.
+// Returns analysis results including PII, sensitive content, prompt injection, secrets, and sentiment.
 func (a *Analyzer) AnalyzeText(text string) (*ContentAnalysis, error) {
+	return a.analyze(text, true)
+}
+
+// AnalyzeResponse performs content analysis on model output. Unlike
+// AnalyzeText, it does not detect prompt injection, which applies to
+// prompts: what matters in a response is what the model disclosed, such as
+// PII, secrets and toxic content.
+func (a *Analyzer) AnalyzeResponse(text string) (*ContentAnalysis, error) {
+	return a.analyze(text, false)
+}
+
+// analyze analyzes text, detecting prompt injection if detectInjection is
+// set.
+func (a *Analyzer) analyze(text string, detectInjection bool) (*ContentAnalysis, error) {
 	if text == "" {
 		return &ContentAnalysis{}, nil
 	}
@@ -49,6 +101,7 @@ func (a *Analyzer) AnalyzeText(text string) (*ContentAnalysis, error) {
 	// Detect PII
 	if a.config.PII.Enabled {
 		analysis.PIIDetection = a.detectPII(text)
+		a.detectExternalPII(text, analysis.PIIDetection)
 	}
 
 	// Detect sensitive content
@@ -57,13 +110,34 @@ func (a *Analyzer) AnalyzeText(text string) (*ContentAnalysis, error) {
 	}
 
 	// Detect prompt injection
-	if a.config.Injection.Enabled {
+	if a.config.Injection.Enabled && detectInjection {
 		analysis.PromptInjection = a.detectPromptInjection(text)
 	}
 
+	// Detect embedded secrets
+	if a.config.Secrets.Enabled {
+		analysis.Secrets = a.detectSecrets(text)
+	}
+
+	// Recognize named entities
+	if a.config.Entities.Enabled {
+		analysis.Entities = a.detectEntities(text)
+	}
+
+	// Compare with the banned and allowed topics
+	analysis.Topic = a.detectTopic(text)
+
+	// Label the content by its findings
+	if a.config.Classification.Enabled {
+		analysis.Classification = a.classify(analysis)
+	}
+
 	// Analyze sentiment
 	analysis.Sentiment = a.analyzeSentiment(text)
 
+	// Explain the findings
+	analysis.Findings = a.explain(analysis)
+
 	// Calculate text statistics
 	analysis.WordCount = countWords(text)
 	analysis.SentenceCount = countSentences(text)
@@ -71,12 +145,45 @@ func (a *Analyzer) AnalyzeText(text string) (*ContentAnalysis, error) {
 		analysis.AverageWordLength = float64(len(text)) / float64(analysis.WordCount)
 	}
 
-	// Detect language (simplified - just check for common indicators)
-	analysis.Language = detectLanguage(text)
+	// Detect language
+	analysis.Language, analysis.LanguageConfidence = detectLanguage(text)
 
 	return analysis, nil
 }
 
+// Redact returns text with the PII detected by the configured PII types
+// replaced by "[REDACTED:<type>]". Overlapping matches are redacted as one,
+// with the type of the first.
+func (a *Analyzer) Redact(text string) string {
+	if text == "" {
+		return text
+	}
+	spans := mergeLocations(a.detectPII(text).Locations)
+	if len(spans) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	pos := 0
+	for _, span := range spans {
+		b.WriteString(text[pos:span.Start])
+		b.WriteString("[REDACTED:" + span.Type + "]")
+		pos = span.End
+	}
+	b.WriteString(text[pos:])
+	return b.String()
+}
+
+// RedactPlaceholders returns text with the PII detected by the configured
+// PII types replaced by typed placeholders such as [EMAIL_1], recording
+// each replacement in placeholders so it can be restored.
+func (a *Analyzer) RedactPlaceholders(text string, placeholders *Placeholders) string {
+	if text == "" {
+		return text
+	}
+	return placeholders.Replace(text, a.detectPII(text).Locations)
+}
+
 // compilePIIPatterns compiles regex patterns for PII detection.
 func (a *Analyzer) compilePIIPatterns() {
 	// Email pattern
@@ -95,14 +202,118 @@ func (a *Analyzer) compilePIIPatterns() {
 	a.piiPatterns["ip_address"] = regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`)
 }
 
+// customPIIPattern is a compiled custom PII type.
+type customPIIPattern struct {
+	name     string
+	severity string
+	patterns []*regexp.Regexp
+}
+
+// builtinPIISeverity is the severity of each built-in PII type.
+var builtinPIISeverity = map[string]string{
+	"email":       "medium",
+	"phone":       "medium",
+	"ssn":         "high",
+	"credit_card": "high",
+	"ip_address":  "low",
+}
+
+// severityRank orders PII severities from low to critical.
+var severityRank = map[string]int{
+	"low":      1,
+	"medium":   2,
+	"high":     3,
+	"critical": 4,
+}
+
+// compileCustomPIIPatterns compiles the custom PII types. Keywords are
+// compiled into one case-insensitive pattern matching whole words, longest
+// keyword first. Invalid patterns are skipped, as configuration validation
+// rejects them.
+func (a *Analyzer) compileCustomPIIPatterns() {
+	for _, custom := range a.config.PII.Custom {
+		compiled := customPIIPattern{
+			name:     custom.Name,
+			severity: custom.Severity,
+		}
+		if compiled.severity == "" {
+			compiled.severity = config.DefaultContentPIICustomSeverity
+		}
+
+		if custom.Pattern != "" {
+			re, err := regexp.Compile(custom.Pattern)
+			if err != nil {
+				slog.Warn("skipping invalid custom PII pattern",
+					"name", custom.Name,
+					"error", err,
+				)
+			} else {
+				compiled.patterns = append(compiled.patterns, re)
+			}
+		}
+
+		if re := keywordPattern(custom.Keywords); re != nil {
+			compiled.patterns = append(compiled.patterns, re)
+		}
+
+		if len(compiled.patterns) > 0 {
+			a.customPII = append(a.customPII, compiled)
+		}
+	}
+}
+
+// keywordPattern compiles keywords into one case-insensitive pattern
+// matching whole words, longest keyword first. It returns nil if there are
+// no keywords.
+func keywordPattern(keywords []string) *regexp.Regexp {
+	quoted := make([]string, 0, len(keywords))
+	for _, keyword := range keywords {
+		if keyword = strings.TrimSpace(keyword); keyword != "" {
+			quoted = append(quoted, regexp.QuoteMeta(keyword))
+		}
+	}
+	if len(quoted) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(quoted, func(i, j int) bool {
+		return len(quoted[i]) > len(quoted[j])
+	})
+	return regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)
+}
+
+// injectionPattern is a compiled prompt injection pattern.
+type injectionPattern struct {
+	pattern       string
+	injectionType string
+	re            *regexp.Regexp
+}
+
 // compileInjectionPatterns compiles regex patterns for prompt injection detection.
 func (a *Analyzer) compileInjectionPatterns() {
-	a.injectionPatterns = make([]*regexp.Regexp, 0, len(a.config.Injection.Patterns))
+	a.injectionPatterns = make([]injectionPattern, 0, len(a.config.Injection.Patterns))
 
 	for _, pattern := range a.config.Injection.Patterns {
-		// Create case-insensitive pattern
-		re := regexp.MustCompile(`(?i)` + regexp.QuoteMeta(pattern))
-		a.injectionPatterns = append(a.injectionPatterns, re)
+		a.injectionPatterns = append(a.injectionPatterns, compileInjectionPattern(pattern, ""))
+	}
+}
+
+// compileInjectionPattern compiles a case-insensitive injection pattern. If
+// injectionType is empty, it is inferred from the pattern: direct for
+// instruction overrides, jailbreak for persona changes.
+func compileInjectionPattern(pattern, injectionType string) injectionPattern {
+	if injectionType == "" {
+		switch {
+		case containsAny([]string{pattern}, []string{"ignore", "disregard", "forget"}):
+			injectionType = "direct"
+		case containsAny([]string{pattern}, []string{"you are now"}):
+			injectionType = "jailbreak"
+		}
+	}
+	return injectionPattern{
+		pattern:       pattern,
+		injectionType: injectionType,
+		re:            regexp.MustCompile(`(?i)` + regexp.QuoteMeta(pattern)),
 	}
 }
 
@@ -125,86 +336,46 @@ func (a *Analyzer) detectPII(text string) *PIIDetection {
 
 		// Find all matches
 		matches := pattern.FindAllStringIndex(text, -1)
-		if len(matches) > 0 {
-			detection.HasPII = true
-			detection.PIITypes = append(detection.PIITypes, piiType)
-			detection.PIICount += len(matches)
-
-			// Record locations
-			for _, match := range matches {
-				detection.Locations = append(detection.Locations, PIILocation{
-					Type:       piiType,
-					Start:      match[0],
-					End:        match[1],
-					Confidence: 1.0, // Regex matches have high confidence
-				})
-			}
+		detection.record(piiType, builtinPIISeverity[piiType], "pattern", matches)
+	}
+
+	// Check custom PII types alongside the built-in types
+	for _, custom := range a.customPII {
+		var matches [][]int
+		for _, pattern := range custom.patterns {
+			matches = append(matches, pattern.FindAllStringIndex(text, -1)...)
 		}
+		detection.record(custom.name, custom.severity, "custom", matches)
 	}
 
 	return detection
 }
 
-// detectSensitiveContent detects sensitive content using keyword matching.
-func (a *Analyzer) detectSensitiveContent(text string) *SensitiveContent {
-	detection := &SensitiveContent{
-		Categories: make([]string, 0),
-		Severity:   "low",
+// record adds the matches of a PII type found by source to the detection,
+// raising its severity to the type's severity.
+func (d *PIIDetection) record(piiType, severity, source string, matches [][]int) {
+	if len(matches) == 0 {
+		return
 	}
 
-	textLower := strings.ToLower(text)
-
-	// Define keyword lists for each category
-	categoryKeywords := map[string][]string{
-		"profanity": {
-			"fuck", "shit", "damn", "ass", "bitch",
-		},
-		"violence": {
-			"kill", "murder", "attack", "weapon", "blood", "death",
-		},
-		"hate_speech": {
-			"hate", "racist", "discrimination",
-		},
-		"adult_content": {
-			"sex", "porn", "nude", "explicit",
-		},
-	}
-
-	totalMatches := 0
-
-	// Check each category
-	for _, category := range a.config.Sensitive.Categories {
-		keywords, ok := categoryKeywords[category]
-		if !ok {
-			continue
-		}
-
-		categoryMatches := 0
-		for _, keyword := range keywords {
-			if strings.Contains(textLower, keyword) {
-				categoryMatches++
-			}
-		}
-
-		if categoryMatches > 0 {
-			detection.HasSensitiveContent = true
-			detection.Categories = append(detection.Categories, category)
-			totalMatches += categoryMatches
-		}
+	d.HasPII = true
+	d.PIITypes = append(d.PIITypes, piiType)
+	d.PIICount += len(matches)
+	if severityRank[severity] > severityRank[d.Severity] {
+		d.Severity = severity
 	}
 
-	detection.MatchCount = totalMatches
-
-	// Determine severity based on match count
-	if totalMatches >= 5 {
-		detection.Severity = "critical"
-	} else if totalMatches >= 3 {
-		detection.Severity = "high"
-	} else if totalMatches >= 1 {
-		detection.Severity = "medium"
+	// Record locations
+	for _, match := range matches {
+		d.Locations = append(d.Locations, PIILocation{
+			Type:       piiType,
+			Start:      match[0],
+			End:        match[1],
+			Confidence: 1.0, // Regex matches have high confidence
+			Severity:   severity,
+			Source:     source,
+		})
 	}
-
-	return detection
 }
 
 // detectPromptInjection detects prompt injection attempts.
@@ -216,19 +387,39 @@ func (a *Analyzer) detectPromptInjection(text string) *PromptInjection {
 	a.mu.RLock()
 	defer a.mu.RUnlock()
 
-	// Check against configured patterns
-	for i, pattern := range a.injectionPatterns {
-		if pattern.MatchString(text) {
+	// Check against configured and feed patterns
+	matchedTypes := make(map[string]bool)
+	sources := []struct {
+		name     string
+		patterns []injectionPattern
+	}{
+		{"config", a.injectionPatterns},
+		{"feed", a.feedPatterns},
+	}
+	for _, source := range sources {
+		for _, pattern := range source.patterns {
+			loc := pattern.re.FindStringIndex(text)
+			if loc == nil {
+				continue
+			}
 			detection.HasPromptInjection = true
-			detection.MatchedPatterns = append(detection.MatchedPatterns, a.config.Injection.Patterns[i])
+			detection.MatchedPatterns = append(detection.MatchedPatterns, pattern.pattern)
+			detection.Matches = append(detection.Matches, InjectionMatch{
+				Pattern: pattern.pattern,
+				Type:    pattern.injectionType,
+				Source:  source.name,
+				Start:   loc[0],
+				End:     loc[1],
+			})
+			matchedTypes[pattern.injectionType] = true
 		}
 	}
 
 	if detection.HasPromptInjection {
 		// Determine injection type based on patterns
-		if containsAny(detection.MatchedPatterns, []string{"ignore", "disregard", "forget"}) {
+		if matchedTypes["direct"] {
 			detection.InjectionType = "direct"
-		} else if containsAny(detection.MatchedPatterns, []string{"you are now"}) {
+		} else if matchedTypes["jailbreak"] {
 			detection.InjectionType = "jailbreak"
 		} else {
 			detection.InjectionType = "indirect"
@@ -244,6 +435,9 @@ func (a *Analyzer) detectPromptInjection(text string) *PromptInjection {
 		}
 	}
 
+	// Complement the patterns with the classifier
+	a.classifyInjection(text, detection)
+
 	return detection
 }
 
@@ -330,14 +524,6 @@ func countSentences(text string) int {
 	return count
 }
 
-// detectLanguage performs simple language detection.
-// For MVP, we just return "en" (English) as default.
-func detectLanguage(text string) string {
-	// For MVP, assume English
-	// Future enhancement: use language detection library
-	return "en"
-}
-
 // containsAny checks if any of the needles are in the haystack.
 func containsAny(haystack []string, needles []string) bool {
 	for _, h := range haystack {
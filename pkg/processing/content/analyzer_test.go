@@ -382,3 +382,86 @@ func TestCountSentences(t *testing.T) {
 		})
 	}
 }
+
+func TestAnalyzer_Redact(t *testing.T) {
+	cfg := &config.ContentConfig{
+		PII: config.PIIConfig{
+			Enabled: true,
+			Types:   []string{"email", "phone"},
+		},
+	}
+
+	analyzer := NewAnalyzer(cfg)
+
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{
+			name: "no PII",
+			text: "Hello, how are you today?",
+			want: "Hello, how are you today?",
+		},
+		{
+			name: "multiple PII types",
+			text: "Email: user@example.com, Phone: 555-123-4567.",
+			want: "Email: [REDACTED:email], Phone: [REDACTED:phone].",
+		},
+		{
+			name: "empty",
+			text: "",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := analyzer.Redact(tt.text); got != tt.want {
+				t.Errorf("Redact(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnalyzer_DetectCustomPII(t *testing.T) {
+	cfg := &config.ContentConfig{
+		PII: config.PIIConfig{
+			Enabled: true,
+			Types:   []string{"email"},
+			Custom: []config.CustomPIIConfig{
+				{Name: "employee_id", Pattern: `\bEMP-\d{6}\b`, Severity: "high"},
+				{Name: "project_code", Keywords: []string{"Falcon", "Project Falcon"}, Severity: "critical"},
+				{Name: "account", Pattern: `\bACC\d{8}\b`},
+			},
+		},
+	}
+
+	analyzer := NewAnalyzer(cfg)
+
+	detection := analyzer.detectPII("Contact user@example.com about EMP-123456 and EMP-654321.")
+	if !detection.HasPII || detection.PIICount != 3 || detection.Severity != "high" {
+		t.Errorf("expected 3 PII matches with severity high, got %+v", detection)
+	}
+	if len(detection.PIITypes) != 2 || detection.PIITypes[0] != "email" || detection.PIITypes[1] != "employee_id" {
+		t.Errorf("expected email and employee_id, got %v", detection.PIITypes)
+	}
+
+	// Keywords match whole words case-insensitively, longest first
+	detection = analyzer.detectPII("Status of project falcon? Not Falconry.")
+	if detection.PIICount != 1 || detection.Severity != "critical" {
+		t.Fatalf("expected 1 critical keyword match, got %+v", detection)
+	}
+	if loc := detection.Locations[0]; loc.Start != 10 || loc.End != 24 {
+		t.Errorf("expected match of \"project falcon\", got [%d:%d]", loc.Start, loc.End)
+	}
+
+	// Custom types default to medium severity and are redacted
+	detection = analyzer.detectPII("Account ACC12345678")
+	if detection.Severity != "medium" || detection.Locations[0].Severity != "medium" {
+		t.Errorf("expected default severity medium, got %+v", detection)
+	}
+	if got := analyzer.Redact("Account ACC12345678"); got != "Account [REDACTED:account]" {
+		t.Errorf("expected custom PII to be redacted, got %q", got)
+	}
+}
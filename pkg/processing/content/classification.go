@@ -0,0 +1,94 @@
+package content
+
+import (
+	"slices"
+
+	"mercator-hq/jupiter/pkg/config"
+)
+
+// classify labels analyzed content with the highest label of the
+// classification rules it matches, or the default label if it matches
+// none.
+func (a *Analyzer) classify(analysis *ContentAnalysis) *Classification {
+	cfg := &a.config.Classification
+	labels := cfg.Labels
+	if len(labels) == 0 {
+		labels = config.DefaultClassificationLabels
+	}
+
+	classification := &Classification{Label: cfg.Default}
+	if classification.Label == "" {
+		classification.Label = labels[0]
+	}
+	classification.Level = max(slices.Index(labels, classification.Label), 0)
+
+	for i := range cfg.Rules {
+		rule := &cfg.Rules[i]
+		level := slices.Index(labels, rule.Label)
+		if level < classification.Level {
+			continue
+		}
+		reasons := classificationReasons(rule, analysis)
+		if len(reasons) == 0 {
+			continue
+		}
+
+		if level > classification.Level || classification.Reasons == nil {
+			classification.Label = rule.Label
+			classification.Level = level
+			classification.Reasons = nil
+		}
+		for _, reason := range reasons {
+			if !slices.Contains(classification.Reasons, reason) {
+				classification.Reasons = append(classification.Reasons, reason)
+			}
+		}
+	}
+	return classification
+}
+
+// classificationReasons returns the findings of analysis matching rule,
+// such as "pii:email" or "secret:github_token".
+func classificationReasons(rule *config.ClassificationRuleConfig, analysis *ContentAnalysis) []string {
+	var reasons []string
+	if pii := analysis.PIIDetection; pii != nil && pii.HasPII {
+		for _, piiType := range pii.PIITypes {
+			if matchesAny(rule.PIITypes, piiType) {
+				reasons = append(reasons, "pii:"+piiType)
+			}
+		}
+		if rule.PIISeverity != "" && severityRank[pii.Severity] >= severityRank[rule.PIISeverity] {
+			reasons = append(reasons, "pii_severity:"+pii.Severity)
+		}
+	}
+	if secrets := analysis.Secrets; secrets != nil && secrets.Detected {
+		for _, secretType := range secrets.Types {
+			if matchesAny(rule.SecretTypes, secretType) {
+				reasons = append(reasons, "secret:"+secretType)
+			}
+		}
+	}
+	if sensitive := analysis.SensitiveContent; sensitive != nil && sensitive.HasSensitiveContent {
+		for _, category := range sensitive.Categories {
+			if matchesAny(rule.SensitiveCategories, category) {
+				reasons = append(reasons, "sensitive:"+category)
+			}
+		}
+	}
+	if entities := analysis.Entities; entities != nil {
+		for _, organization := range entities.Organizations {
+			if matchesAny(rule.Organizations, organization) {
+				reasons = append(reasons, "organization:"+organization)
+			}
+		}
+	}
+	if topic := analysis.Topic; topic != nil && topic.Matched && matchesAny(rule.Topics, topic.Name) {
+		reasons = append(reasons, "topic:"+topic.Name)
+	}
+	return reasons
+}
+
+// matchesAny reports whether values contains value or "*".
+func matchesAny(values []string, value string) bool {
+	return slices.Contains(values, "*") || slices.Contains(values, value)
+}
@@ -0,0 +1,105 @@
+package content
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// InjectionClassifier scores text for prompt injection and jailbreak
+// attempts, such as a small ONNX model run with an ONNX runtime. It
+// complements the injection patterns with attempts they do not match.
+type InjectionClassifier interface {
+	// Classify returns the probability from 0.0 to 1.0 that text is a
+	// prompt injection. It must return when ctx is done.
+	Classify(ctx context.Context, text string) (float64, error)
+}
+
+// shadowClassifierTimeout bounds shadow classifications, which run
+// outside the request's latency budget.
+const shadowClassifierTimeout = time.Second
+
+// maxShadowClassifications is the number of shadow classifications that
+// can run at once. Further classifications are skipped rather than queued.
+const maxShadowClassifications = 16
+
+// SetInjectionClassifier sets the classifier evaluated alongside the
+// injection patterns, as configured by the injection classifier
+// configuration. It must be called before the analyzer is used.
+func (a *Analyzer) SetInjectionClassifier(classifier InjectionClassifier) {
+	a.injectionClassifier = classifier
+	a.shadowSlots = make(chan struct{}, maxShadowClassifications)
+}
+
+// classifyInjection evaluates the injection classifier on text. Inline, a
+// score at or above the threshold reports an injection the patterns missed;
+// a classification that fails or exceeds the timeout leaves the pattern
+// results unchanged. In shadow mode, the classifier runs asynchronously
+// and its results are only logged.
+func (a *Analyzer) classifyInjection(text string, detection *PromptInjection) {
+	cfg := &a.config.Injection.Classifier
+	if a.injectionClassifier == nil || !cfg.Enabled {
+		return
+	}
+
+	if cfg.Mode != "inline" {
+		a.shadowClassifyInjection(text, detection.HasPromptInjection)
+		return
+	}
+
+	ctx := context.Background()
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+	}
+	score, err := a.injectionClassifier.Classify(ctx, text)
+	if err != nil {
+		slog.Debug("injection classifier failed, using patterns only", "error", err)
+		return
+	}
+
+	detection.ClassifierScore = score
+	if score < cfg.Threshold {
+		return
+	}
+	if !detection.HasPromptInjection {
+		detection.HasPromptInjection = true
+		detection.InjectionType = "classifier"
+	}
+	detection.Confidence = max(detection.Confidence, score)
+}
+
+// shadowClassifyInjection classifies text asynchronously and logs the
+// result with whether the patterns detected an injection.
+func (a *Analyzer) shadowClassifyInjection(text string, patternDetected bool) {
+	select {
+	case a.shadowSlots <- struct{}{}:
+	default:
+		slog.Debug("skipping shadow injection classification, too many in flight")
+		return
+	}
+
+	go func() {
+		defer func() { <-a.shadowSlots }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), shadowClassifierTimeout)
+		defer cancel()
+
+		start := time.Now()
+		score, err := a.injectionClassifier.Classify(ctx, text)
+		if err != nil {
+			slog.Warn("shadow injection classification failed", "error", err)
+			return
+		}
+
+		classifierDetected := score >= a.config.Injection.Classifier.Threshold
+		slog.Info("shadow injection classification",
+			"score", score,
+			"classifier_detected", classifierDetected,
+			"pattern_detected", patternDetected,
+			"agreement", classifierDetected == patternDetected,
+			"duration", time.Since(start),
+		)
+	}()
+}
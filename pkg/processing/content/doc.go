@@ -3,8 +3,9 @@
 // This package implements rule-based content analysis including:
 //
 //   - PII (Personally Identifiable Information) detection
-//   - Sensitive content detection (profanity, violence, hate speech)
+//   - Sensitive content scoring (violence, self-harm, sexual, hate, harassment, profanity)
 //   - Prompt injection detection (jailbreak attempts)
+//   - Secret detection (cloud keys, API tokens, private keys, connection strings)
 //   - Sentiment analysis
 //   - Language detection
 //
@@ -32,6 +33,170 @@
 //			"count", analysis.PIIDetection.PIICount)
 //	}
 //
+// # Custom PII Types
+//
+// Operators can define their own PII types in configuration, such as
+// employee IDs or customer account numbers. Each custom type is matched by
+// a regex pattern, a keyword list, or both, and is detected alongside the
+// built-in types with its own severity:
+//
+//	processing:
+//	  content:
+//	    pii:
+//	      custom:
+//	        - name: employee_id
+//	          pattern: '\bEMP-\d{6}\b'
+//	          severity: high
+//	        - name: project_code
+//	          keywords: ["Project Falcon", "Project Osprey"]
+//	          severity: critical
+//
+// PIIDetection.Severity reports the highest severity found, so policies can
+// act on it with processing.content_analysis.pii_detection.severity.
+//
+// # Injection Classifier
+//
+// An ML classifier, such as a small ONNX model, can complement the injection
+// patterns. No classifier is included: programs embedding the processing
+// pipeline register one with Analyzer.SetInjectionClassifier, and enable it
+// in processing.content.injection.classifier. Configuration files of the
+// proxy cannot enable it. Inline, the classifier runs
+// within the configured latency budget (5ms by default) and a score at or
+// above the threshold reports an injection; classifications that fail or
+// time out leave the pattern results unchanged. In shadow mode, the
+// classifier runs asynchronously and its scores are logged next to the
+// pattern results, to evaluate a model before it affects policy decisions.
+//
+// # Pattern Feed
+//
+// A PatternFeedUpdater keeps injection and jailbreak patterns up to date from
+// a signed remote feed, configured under processing.content.injection.feed,
+// so detection keeps up with new attacks without a proxy release. The feed
+// is a versioned list of phrases, each optionally typed:
+//
+//	version: "2026-10-15"
+//	patterns:
+//	  - pattern: "enable developer mode"
+//	    type: jailbreak
+//	  - pattern: "ignore all prior rules"
+//
+// Feed patterns complement the configured patterns and replace those of the
+// previous feed version. A feed that cannot be fetched or fails signature
+// verification is ignored, keeping the last good pattern set.
+//
+// # Secret Detection
+//
+// Credentials pasted into prompts are detected by their format: AWS access
+// keys, GitHub and Slack tokens, private key blocks, JWTs, and database
+// connection strings with embedded passwords. Secrets without a recognizable
+// format, such as AWS secret keys and values assigned to "api_key" or
+// "password", are only reported if their Shannon entropy meets
+// processing.content.secrets.min_entropy, which filters out placeholders like
+// "xxxxxxxx". The secrets themselves are never recorded, only their type and
+// position. Policies match on processing.content_analysis.secrets.detected
+// and processing.content_analysis.secrets.types.
+//
+// # Sensitive Content Categories
+//
+// Sensitive content is scored per category by the number of distinct terms
+// of the category it contains: 0.5 for one term, 0.75 for two, and so on.
+// Categories scoring at least processing.content.sensitive.min_score are
+// reported. The built-in categories are violence, self_harm, sexual, hate,
+// harassment and profanity. Wordlist files, one term per line, replace the
+// terms of a built-in category or define a new one. Policies match on
+// processing.content_analysis.sensitive_content.categories or on the score
+// of a single category, such as
+// processing.content_analysis.sensitive_content.scores.self_harm.
+//
+// # Language Detection
+//
+// The language of content is detected from its script and character
+// n-grams, for over 60 languages. Languages with a script of their own,
+// such as Greek, Korean or Thai, are identified by script; languages sharing
+// a script, such as the Latin and Cyrillic script languages, are told apart
+// by comparing the ranked 1- to 3-grams of the content with a profile of
+// each language. The detected ISO 639-1 code and a confidence from 0.0 to
+// 1.0 are available to policies as processing.content_analysis.language and
+// processing.content_analysis.language_confidence. Short text, such as a
+// few words, is detected with low confidence, so rules routing on language
+// should also require a minimum confidence.
+//
+// # Named Entities
+//
+// The organizations, people and locations mentioned in content are
+// recognized by a gazetteer of well-known names, extended with gazetteer
+// files under processing.content.entities.gazetteers, and by rules:
+// capitalized names ending with a company suffix ("Initech Holdings"),
+// names following an honorific ("Dr. Grace Hopper") or a common given
+// name ("Maria Lopez"). No NER model is included: programs embedding the
+// processing pipeline can complement the gazetteer with one, registered with
+// Analyzer.SetEntityRecognizer, within its latency budget (10ms by default). Policies match on
+// processing.content_analysis.entities.organizations, .people and
+// .locations, which list each name once.
+//
+// # Data Classification
+//
+// Findings can be mapped to the labels of a data classification scheme,
+// configured under processing.content.classification from least to most
+// sensitive (public, internal, confidential and restricted by default).
+// Each rule assigns a label to content with any of its findings: PII types
+// or severity, secret types, sensitive content categories, organizations
+// or topics. Content is labeled with the highest label of the rules it
+// matches, available to policies as
+// processing.content_analysis.classification.label, with its position as
+// .level for comparisons such as "at least confidential". Evidence records
+// the highest label of the request and the response.
+//
+// # Topic Similarity
+//
+// Content can be compared with banned and allowed topics configured under
+// processing.content.topics, each described by example phrases. An
+// Embedder, registered with Analyzer.SetTopicEmbedder, embeds the examples
+// of each topic into a centroid on first use, and each analyzed text is
+// reported with its nearest topic by cosine similarity. Policies match on
+// processing.content_analysis.topic.name, .kind, .score and .matched, the
+// latter set when the score reaches the configured threshold:
+//
+//	conditions:
+//	  - field: "processing.content_analysis.topic.kind"
+//	    operator: "=="
+//	    value: "banned"
+//	  - field: "processing.content_analysis.topic.matched"
+//	    operator: "=="
+//	    value: true
+//
+// Content whose embedding fails or times out is analyzed without topic
+// similarity.
+//
+// # Response Analysis
+//
+// AnalyzeResponse analyzes model output with the same detectors, except
+// prompt injection, which applies to prompts. The processor runs it over
+// the response content and tool call arguments, so response-phase policies
+// can act on PII the model leaked with
+// response.content_analysis.pii_detection.detected, and evidence records
+// the PII, secret and sensitive content types found.
+//
+// # Findings
+//
+// ContentAnalysis.Findings explains each detection: the detector that made
+// it, the pattern, wordlist term, feed entry or rule that triggered it, its
+// confidence, and its byte offsets in the analyzed text, if any. Findings
+// never contain the matched text itself. Evidence records the findings of
+// the request and the response, and policy decision explanations include
+// them.
+//
+// # Placeholder Redaction
+//
+// Detected PII can be replaced in place with typed placeholders, and the
+// placeholders restored in the provider's response:
+//
+//	placeholders := content.NewPlaceholders()
+//	redacted := analyzer.RedactPlaceholders("Email jane@example.com", placeholders)
+//	// redacted == "Email [EMAIL_1]"
+//
+//	restored := placeholders.Restore(response)
+//
 // # Performance
 //
 // All analysis operations complete in <5ms for typical requests:
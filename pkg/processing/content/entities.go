@@ -0,0 +1,296 @@
+package content
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// EntityRecognizer recognizes named entities in text, such as a token
+// classification model run with an ONNX runtime. It complements the
+// gazetteer with names it does not list.
+type EntityRecognizer interface {
+	// Recognize returns the entities mentioned in text, with byte offsets
+	// into text. Entity types may be model labels such as "ORG", "PER" or
+	// "LOC". It must return when ctx is done.
+	Recognize(ctx context.Context, text string) ([]EntityMention, error)
+}
+
+// entityTypes are the recognized entity types.
+var entityTypes = []string{"organization", "person", "location"}
+
+// capitalizedWord matches a capitalized word or an acronym.
+const capitalizedWord = `[A-Z][\p{L}\p{N}'&-]*`
+
+// leadingWords are capitalized words that start sentences rather than
+// names, trimmed from the start of organizations recognized by suffix.
+var leadingWords = map[string]bool{
+	"A": true, "An": true, "And": true, "At": true, "By": true, "For": true,
+	"From": true, "In": true, "My": true, "Our": true, "The": true,
+	"To": true, "With": true, "Your": true,
+}
+
+// entityLabels maps NER model labels to entity types.
+var entityLabels = map[string]string{
+	"ORG":          "organization",
+	"ORGANIZATION": "organization",
+	"PER":          "person",
+	"PERSON":       "person",
+	"LOC":          "location",
+	"LOCATION":     "location",
+	"GPE":          "location",
+}
+
+// entityMatcher recognizes entities of a type by a pattern. If group is
+// set, the entity is the text of that submatch.
+type entityMatcher struct {
+	entityType string
+	source     string
+	pattern    *regexp.Regexp
+	group      int
+}
+
+// EntityRecognizerOptions configures the evaluation of an NER model.
+type EntityRecognizerOptions struct {
+	// MinScore is the minimum score (0.0 to 1.0) of the entities the model
+	// recognizes.
+	// Default: 0.6
+	MinScore float64
+
+	// Timeout is the latency budget of the model. Content whose
+	// recognition exceeds it is analyzed with the gazetteer only.
+	// Default: 10ms
+	Timeout time.Duration
+}
+
+// Default NER model options
+const (
+	defaultEntityMinScore = 0.6
+	defaultEntityTimeout  = 10 * time.Millisecond
+)
+
+// SetEntityRecognizer sets the NER model evaluated alongside the
+// gazetteer when entity recognition is enabled. It must be called before
+// the analyzer is used.
+func (a *Analyzer) SetEntityRecognizer(recognizer EntityRecognizer, opts EntityRecognizerOptions) {
+	if opts.MinScore == 0 {
+		opts.MinScore = defaultEntityMinScore
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = defaultEntityTimeout
+	}
+	a.entityRecognizer = recognizer
+	a.entityRecognizerOpts = opts
+}
+
+// compileEntityMatchers compiles the gazetteer, extended with the
+// configured gazetteer files, and the rules of the configured entity types.
+// A gazetteer file that cannot be read is skipped.
+func (a *Analyzer) compileEntityMatchers() {
+	if !a.config.Entities.Enabled {
+		return
+	}
+
+	for _, entityType := range entityTypes {
+		if !a.recognizesEntityType(entityType) {
+			continue
+		}
+
+		names := slices.Clone(gazetteer[entityType])
+		if path, ok := a.config.Entities.Gazetteers[entityType]; ok {
+			custom, err := loadWordlist(path)
+			if err != nil {
+				slog.Warn("failed to load gazetteer, using built-in names",
+					"type", entityType,
+					"path", path,
+					"error", err,
+				)
+			}
+			names = append(names, custom...)
+		}
+		if pattern := namePattern(names); pattern != nil {
+			a.entityMatchers = append(a.entityMatchers, entityMatcher{
+				entityType: entityType,
+				source:     "gazetteer",
+				pattern:    pattern,
+			})
+		}
+	}
+
+	if a.recognizesEntityType("organization") {
+		a.entityMatchers = append(a.entityMatchers,
+			entityMatcher{
+				entityType: "organization",
+				source:     "rule",
+				pattern: regexp.MustCompile(`\b(?:` + capitalizedWord + ` +){0,3}` + capitalizedWord +
+					` +(?:` + strings.Join(organizationSuffixes, "|") + `)\b`),
+			},
+			entityMatcher{
+				entityType: "organization",
+				source:     "rule",
+				pattern:    regexp.MustCompile(`\b(?:University|Bank|Institute) of ` + capitalizedWord + `(?: +` + capitalizedWord + `)?`),
+			},
+		)
+	}
+	if a.recognizesEntityType("person") {
+		a.entityMatchers = append(a.entityMatchers,
+			entityMatcher{
+				entityType: "person",
+				source:     "rule",
+				pattern: regexp.MustCompile(`\b(?:` + strings.Join(honorifics, "|") + `)\.? +(` +
+					capitalizedWord + `(?: +` + capitalizedWord + `){0,2})`),
+				group: 1,
+			},
+			entityMatcher{
+				entityType: "person",
+				source:     "rule",
+				pattern:    regexp.MustCompile(`\b(?:` + strings.Join(firstNames, "|") + `) +` + capitalizedWord),
+			},
+		)
+	}
+}
+
+// recognizesEntityType reports whether entityType is configured. All types
+// are recognized if none are configured.
+func (a *Analyzer) recognizesEntityType(entityType string) bool {
+	if !slices.Contains(entityTypes, entityType) {
+		return false
+	}
+	types := a.config.Entities.Types
+	return len(types) == 0 || slices.Contains(types, entityType)
+}
+
+// detectEntities recognizes the organizations, people and locations
+// mentioned in text. Where mentions overlap, the longest is kept, with
+// gazetteer names preferred over rules and the model.
+func (a *Analyzer) detectEntities(text string) *EntityDetection {
+	var mentions []EntityMention
+	for _, m := range a.entityMatchers {
+		for _, loc := range m.pattern.FindAllStringSubmatchIndex(text, -1) {
+			start, end := loc[2*m.group], loc[2*m.group+1]
+			if m.source == "rule" && m.group == 0 {
+				start = trimLeadingWords(text, start, end)
+			}
+			mentions = append(mentions, EntityMention{
+				Type:       m.entityType,
+				Text:       text[start:end],
+				Start:      start,
+				End:        end,
+				Source:     m.source,
+				Confidence: 1.0,
+			})
+		}
+	}
+	mentions = append(mentions, a.recognizeEntities(text)...)
+
+	sourceRank := map[string]int{"gazetteer": 0, "rule": 1, "model": 2}
+	sort.SliceStable(mentions, func(i, j int) bool {
+		if mentions[i].Start != mentions[j].Start {
+			return mentions[i].Start < mentions[j].Start
+		}
+		if li, lj := mentions[i].End-mentions[i].Start, mentions[j].End-mentions[j].Start; li != lj {
+			return li > lj
+		}
+		return sourceRank[mentions[i].Source] < sourceRank[mentions[j].Source]
+	})
+
+	detection := &EntityDetection{}
+	end := 0
+	for _, mention := range mentions {
+		if mention.Start < end {
+			continue
+		}
+		end = mention.End
+		detection.add(mention)
+	}
+	return detection
+}
+
+// recognizeEntities evaluates the NER model on text. Entities of types
+// that are not configured or scoring below the minimum score are left out.
+// If recognition fails or exceeds the timeout, no entities are returned.
+func (a *Analyzer) recognizeEntities(text string) []EntityMention {
+	if a.entityRecognizer == nil {
+		return nil
+	}
+	opts := &a.entityRecognizerOpts
+
+	ctx, cancel := context.WithTimeout(context.Background(), opts.Timeout)
+	defer cancel()
+	recognized, err := a.entityRecognizer.Recognize(ctx, text)
+	if err != nil {
+		slog.Debug("entity recognizer failed, using gazetteer only", "error", err)
+		return nil
+	}
+
+	mentions := make([]EntityMention, 0, len(recognized))
+	for _, mention := range recognized {
+		if entityType, ok := entityLabels[strings.ToUpper(mention.Type)]; ok {
+			mention.Type = entityType
+		}
+		if mention.Start < 0 || mention.End > len(text) || mention.Start >= mention.End ||
+			mention.Confidence < opts.MinScore || !a.recognizesEntityType(mention.Type) {
+			continue
+		}
+		mention.Text = text[mention.Start:mention.End]
+		mention.Source = "model"
+		mentions = append(mentions, mention)
+	}
+	return mentions
+}
+
+// add adds a mention to the detection, listing its name once per type.
+func (d *EntityDetection) add(mention EntityMention) {
+	d.Count++
+	d.Mentions = append(d.Mentions, mention)
+
+	var names *[]string
+	switch mention.Type {
+	case "organization":
+		names = &d.Organizations
+	case "person":
+		names = &d.People
+	case "location":
+		names = &d.Locations
+	default:
+		return
+	}
+	if !slices.Contains(*names, mention.Text) {
+		*names = append(*names, mention.Text)
+	}
+}
+
+// trimLeadingWords returns the start of text[start:end] without its
+// leading sentence words, such as "The" in "The Acme Corp".
+func trimLeadingWords(text string, start, end int) int {
+	for {
+		word, _, found := strings.Cut(text[start:end], " ")
+		if !found || !leadingWords[word] {
+			return start
+		}
+		start += len(word) + 1
+	}
+}
+
+// namePattern compiles names into a case-sensitive pattern matching any of
+// them as whole words, longest first.
+func namePattern(names []string) *regexp.Regexp {
+	quoted := make([]string, 0, len(names))
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			quoted = append(quoted, regexp.QuoteMeta(name))
+		}
+	}
+	if len(quoted) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(quoted, func(i, j int) bool {
+		return len(quoted[i]) > len(quoted[j])
+	})
+	return regexp.MustCompile(`\b(?:` + strings.Join(quoted, "|") + `)\b`)
+}
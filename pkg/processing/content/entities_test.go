@@ -0,0 +1,160 @@
+package content
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/config"
+)
+
+func TestAnalyzer_DetectEntities(t *testing.T) {
+	analyzer := NewAnalyzer(&config.ContentConfig{
+		Entities: config.EntitiesConfig{Enabled: true},
+	})
+
+	tests := []struct {
+		name              string
+		text              string
+		wantOrganizations []string
+		wantPeople        []string
+		wantLocations     []string
+	}{
+		{
+			name:              "gazetteer",
+			text:              "Compare Microsoft and Google offices in London and Paris. Did Satya Nadella visit London?",
+			wantOrganizations: []string{"Microsoft", "Google"},
+			wantPeople:        []string{"Satya Nadella"},
+			wantLocations:     []string{"London", "Paris"},
+		},
+		{
+			name:              "rules",
+			text:              "The Initech Holdings deal was signed by Dr. Grace Hopper and Maria Lopez at the University of Lagos",
+			wantOrganizations: []string{"Initech Holdings", "University of Lagos"},
+			wantPeople:        []string{"Grace Hopper", "Maria Lopez"},
+		},
+		{
+			name:              "longest mention",
+			text:              "Goldman Sachs Group opened a New York office",
+			wantOrganizations: []string{"Goldman Sachs Group"},
+			wantLocations:     []string{"New York"},
+		},
+		{
+			name: "lowercase words",
+			text: "an apple a day keeps the doctor away",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			analysis, err := analyzer.AnalyzeText(tt.text)
+			if err != nil {
+				t.Fatalf("AnalyzeText failed: %v", err)
+			}
+			entities := analysis.Entities
+			if entities == nil {
+				t.Fatal("expected entities, got nil")
+			}
+			if got := strings.Join(entities.Organizations, ","); got != strings.Join(tt.wantOrganizations, ",") {
+				t.Errorf("expected organizations %v, got %v", tt.wantOrganizations, entities.Organizations)
+			}
+			if got := strings.Join(entities.People, ","); got != strings.Join(tt.wantPeople, ",") {
+				t.Errorf("expected people %v, got %v", tt.wantPeople, entities.People)
+			}
+			if got := strings.Join(entities.Locations, ","); got != strings.Join(tt.wantLocations, ",") {
+				t.Errorf("expected locations %v, got %v", tt.wantLocations, entities.Locations)
+			}
+			for _, m := range entities.Mentions {
+				if tt.text[m.Start:m.End] != m.Text {
+					t.Errorf("mention %q does not match its location %d-%d", m.Text, m.Start, m.End)
+				}
+			}
+		})
+	}
+}
+
+func TestAnalyzer_DetectEntitiesGazetteerFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "customers.txt")
+	if err := os.WriteFile(path, []byte("# Key accounts\nAcme\n\nGlobex\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	analyzer := NewAnalyzer(&config.ContentConfig{
+		Entities: config.EntitiesConfig{
+			Enabled:    true,
+			Types:      []string{"organization"},
+			Gazetteers: map[string]string{"organization": path},
+		},
+	})
+
+	analysis, _ := analyzer.AnalyzeText("Send the Globex renewal to Tim Cook in Berlin")
+	entities := analysis.Entities
+	if strings.Join(entities.Organizations, ",") != "Globex" {
+		t.Errorf("expected Globex from the gazetteer file, got %v", entities.Organizations)
+	}
+	if len(entities.People) != 0 || len(entities.Locations) != 0 {
+		t.Errorf("expected organizations only, got %+v", entities)
+	}
+}
+
+// fakeRecognizer recognizes a fixed set of entities, after delay.
+type fakeRecognizer struct {
+	mentions []EntityMention
+	delay    time.Duration
+	err      error
+}
+
+func (r *fakeRecognizer) Recognize(ctx context.Context, text string) ([]EntityMention, error) {
+	select {
+	case <-time.After(r.delay):
+		return r.mentions, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestAnalyzer_DetectEntitiesModel(t *testing.T) {
+	text := "Ping Zorblatt Labs about Quux"
+	recognizer := &fakeRecognizer{mentions: []EntityMention{
+		{Type: "ORG", Start: 5, End: 18, Confidence: 0.9},
+		{Type: "PER", Start: 25, End: 29, Confidence: 0.3},
+		{Type: "MISC", Start: 0, End: 4, Confidence: 0.9},
+	}}
+
+	tests := []struct {
+		name              string
+		delay             time.Duration
+		err               error
+		wantOrganizations []string
+	}{
+		{"recognized", 0, nil, []string{"Zorblatt Labs"}},
+		{"timeout", 100 * time.Millisecond, nil, nil},
+		{"error", 0, errors.New("model unavailable"), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recognizer.delay, recognizer.err = tt.delay, tt.err
+			analyzer := NewAnalyzer(&config.ContentConfig{
+				Entities: config.EntitiesConfig{Enabled: true},
+			})
+			analyzer.SetEntityRecognizer(recognizer, EntityRecognizerOptions{
+				MinScore: 0.6,
+				Timeout:  20 * time.Millisecond,
+			})
+
+			analysis, _ := analyzer.AnalyzeText(text)
+			entities := analysis.Entities
+			if got := strings.Join(entities.Organizations, ","); got != strings.Join(tt.wantOrganizations, ",") {
+				t.Errorf("expected organizations %v, got %v", tt.wantOrganizations, entities.Organizations)
+			}
+			if len(entities.People) != 0 || entities.Count != len(tt.wantOrganizations) {
+				t.Errorf("expected low-score and unknown entities to be left out, got %+v", entities)
+			}
+		})
+	}
+}
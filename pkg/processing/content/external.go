@@ -0,0 +1,80 @@
+package content
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"mercator-hq/jupiter/pkg/config"
+)
+
+// ExternalDetector detects PII with an external service, such as a DLP
+// service, complementing the built-in PII patterns.
+type ExternalDetector interface {
+	// Detect returns the locations of the PII in text. It must return when
+	// ctx is done.
+	Detect(ctx context.Context, text string) ([]PIILocation, error)
+}
+
+// SetExternalDetector sets the external PII detector whose findings are
+// merged into the PII detection results. It must be called before the
+// analyzer is used.
+func (a *Analyzer) SetExternalDetector(detector ExternalDetector) {
+	a.externalDetector = detector
+}
+
+// detectExternalPII merges the findings of the external detector into
+// detection. Findings at the location of a pattern match of the same type
+// are not counted twice. If the external detector fails, detection keeps
+// the pattern results only.
+func (a *Analyzer) detectExternalPII(text string, detection *PIIDetection) {
+	if a.externalDetector == nil {
+		return
+	}
+
+	locations, err := a.externalDetector.Detect(context.Background(), text)
+	if err != nil {
+		slog.Warn("external PII detection failed, using patterns only", "error", err)
+		return
+	}
+
+	for _, loc := range locations {
+		if loc.Start < 0 || loc.End > len(text) || loc.Start >= loc.End || hasLocation(detection.Locations, loc) {
+			continue
+		}
+		if loc.Severity == "" {
+			loc.Severity = builtinPIISeverity[loc.Type]
+		}
+		if loc.Severity == "" {
+			loc.Severity = config.DefaultContentPIICustomSeverity
+		}
+		if loc.Source == "" {
+			loc.Source = "external"
+		}
+		detection.merge(loc)
+	}
+}
+
+// merge adds a single PII location to the detection.
+func (d *PIIDetection) merge(loc PIILocation) {
+	d.HasPII = true
+	d.PIICount++
+	d.Locations = append(d.Locations, loc)
+	if !slices.Contains(d.PIITypes, loc.Type) {
+		d.PIITypes = append(d.PIITypes, loc.Type)
+	}
+	if severityRank[loc.Severity] > severityRank[d.Severity] {
+		d.Severity = loc.Severity
+	}
+}
+
+// hasLocation reports whether locations contains a location of the same
+// type and span as loc.
+func hasLocation(locations []PIILocation, loc PIILocation) bool {
+	for _, l := range locations {
+		if l.Type == loc.Type && l.Start == loc.Start && l.End == loc.End {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,50 @@
+package content
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"mercator-hq/jupiter/pkg/config"
+)
+
+// fakeDetector returns locations, or fails with err.
+type fakeDetector struct {
+	locations []PIILocation
+	err       error
+}
+
+func (d *fakeDetector) Detect(ctx context.Context, text string) ([]PIILocation, error) {
+	return d.locations, d.err
+}
+
+func TestAnalyzer_DetectExternalPII(t *testing.T) {
+	text := "Jane Doe, jane@example.com"
+	analyzer := NewAnalyzer(&config.ContentConfig{
+		PII: config.PIIConfig{Enabled: true, Types: []string{"email"}},
+	})
+	analyzer.SetExternalDetector(&fakeDetector{locations: []PIILocation{
+		{Type: "person", Start: 0, End: 8, Confidence: 0.85},
+		{Type: "email", Start: 10, End: 26, Confidence: 1.0},
+		{Type: "person", Start: 20, End: 99, Confidence: 0.5},
+	}})
+
+	analysis, err := analyzer.AnalyzeText(text)
+	if err != nil {
+		t.Fatalf("AnalyzeText failed: %v", err)
+	}
+	pii := analysis.PIIDetection
+	if pii.PIICount != 2 || len(pii.PIITypes) != 2 || pii.PIITypes[1] != "person" {
+		t.Errorf("expected email and person, each once, got %+v", pii)
+	}
+	if pii.Severity != "medium" {
+		t.Errorf("expected severity medium, got %q", pii.Severity)
+	}
+
+	// A failed external detection keeps the pattern results
+	analyzer.SetExternalDetector(&fakeDetector{err: errors.New("unavailable")})
+	analysis, _ = analyzer.AnalyzeText(text)
+	if analysis.PIIDetection.PIICount != 1 {
+		t.Errorf("expected pattern results only, got %+v", analysis.PIIDetection)
+	}
+}
@@ -0,0 +1,117 @@
+package content
+
+import (
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"mercator-hq/jupiter/pkg/config"
+	"mercator-hq/jupiter/pkg/processing/feed"
+)
+
+// injectionTypes are the injection types of feed patterns.
+var injectionTypes = map[string]bool{"direct": true, "indirect": true, "jailbreak": true}
+
+// InjectionPattern is a prompt injection or jailbreak pattern of the
+// pattern feed.
+type InjectionPattern struct {
+	// Pattern is the phrase matched, case-insensitively.
+	Pattern string `yaml:"pattern"`
+
+	// Type is the injection type: direct, indirect or jailbreak. If empty,
+	// it is inferred from the pattern as for configured patterns.
+	Type string `yaml:"type,omitempty"`
+}
+
+// PatternFeed is a versioned set of prompt injection and jailbreak patterns.
+// It is YAML or JSON.
+type PatternFeed struct {
+	// Version identifies the pattern set, such as its publication date.
+	Version string `yaml:"version"`
+
+	// Patterns are the injection patterns.
+	Patterns []InjectionPattern `yaml:"patterns"`
+}
+
+// ParsePatternFeed parses a pattern feed. It returns an error if the feed has
+// no version or patterns, or a pattern is empty or of an unknown type.
+func ParsePatternFeed(data []byte) (*PatternFeed, error) {
+	var patternFeed PatternFeed
+	if err := yaml.Unmarshal(data, &patternFeed); err != nil {
+		return nil, fmt.Errorf("failed to parse pattern feed: %w", err)
+	}
+	if patternFeed.Version == "" {
+		return nil, fmt.Errorf("pattern feed has no version")
+	}
+	if len(patternFeed.Patterns) == 0 {
+		return nil, fmt.Errorf("pattern feed %s has no patterns", patternFeed.Version)
+	}
+	for i, pattern := range patternFeed.Patterns {
+		if strings.TrimSpace(pattern.Pattern) == "" {
+			return nil, fmt.Errorf("pattern feed %s: pattern %d is empty", patternFeed.Version, i)
+		}
+		if pattern.Type != "" && !injectionTypes[pattern.Type] {
+			return nil, fmt.Errorf("pattern feed %s: pattern %d has unknown type %q",
+				patternFeed.Version, i, pattern.Type)
+		}
+	}
+	return &patternFeed, nil
+}
+
+// SetFeedPatterns replaces the injection patterns of the pattern feed, which
+// complement the configured patterns. Patterns that are also configured are
+// skipped. This is thread-safe and can be called while the analyzer is in
+// use.
+func (a *Analyzer) SetFeedPatterns(patterns []InjectionPattern) {
+	configured := make(map[string]bool, len(a.config.Injection.Patterns))
+	for _, pattern := range a.config.Injection.Patterns {
+		configured[strings.ToLower(pattern)] = true
+	}
+
+	compiled := make([]injectionPattern, 0, len(patterns))
+	for _, pattern := range patterns {
+		key := strings.ToLower(pattern.Pattern)
+		if configured[key] {
+			continue
+		}
+		configured[key] = true
+		compiled = append(compiled, compileInjectionPattern(pattern.Pattern, pattern.Type))
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.feedPatterns = compiled
+}
+
+// PatternFeedUpdater keeps the injection patterns of an Analyzer up to date
+// with a signed pattern feed. A feed that cannot be fetched or fails
+// verification is ignored, keeping the last good pattern set.
+type PatternFeedUpdater struct {
+	*feed.Updater
+}
+
+// NewPatternFeedUpdater creates an updater of the feed patterns of analyzer.
+// It returns an error if the public key cannot be loaded.
+func NewPatternFeedUpdater(analyzer *Analyzer, cfg *config.InjectionFeedConfig) (*PatternFeedUpdater, error) {
+	updater, err := feed.New(feed.Config{
+		Name:            "injection pattern feed",
+		URL:             cfg.URL,
+		PublicKey:       cfg.PublicKey,
+		SignatureURL:    cfg.SignatureURL,
+		RefreshInterval: cfg.RefreshInterval,
+		Timeout:         cfg.Timeout,
+		CachePath:       cfg.CachePath,
+	}, func(data []byte) (string, error) {
+		patternFeed, err := ParsePatternFeed(data)
+		if err != nil {
+			return "", err
+		}
+		analyzer.SetFeedPatterns(patternFeed.Patterns)
+		return patternFeed.Version, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &PatternFeedUpdater{Updater: updater}, nil
+}
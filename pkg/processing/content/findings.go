@@ -0,0 +1,108 @@
+package content
+
+// explain lists the findings of analysis with what triggered each of them.
+// It returns nil if nothing was found.
+func (a *Analyzer) explain(analysis *ContentAnalysis) []Finding {
+	var findings []Finding
+
+	if pii := analysis.PIIDetection; pii != nil {
+		for _, loc := range pii.Locations {
+			findings = append(findings, Finding{
+				Detector:   "pii",
+				Type:       loc.Type,
+				Source:     loc.Source,
+				Rule:       loc.Type,
+				Confidence: loc.Confidence,
+				Start:      loc.Start,
+				End:        loc.End,
+			})
+		}
+	}
+
+	if sensitive := analysis.SensitiveContent; sensitive != nil {
+		for _, match := range sensitive.Matches {
+			findings = append(findings, Finding{
+				Detector:   "sensitive",
+				Type:       match.Category,
+				Source:     "wordlist",
+				Rule:       match.Term,
+				Confidence: sensitive.Scores[match.Category],
+				Start:      match.Start,
+				End:        match.End,
+			})
+		}
+	}
+
+	if injection := analysis.PromptInjection; injection != nil {
+		for _, match := range injection.Matches {
+			findings = append(findings, Finding{
+				Detector:   "injection",
+				Type:       match.Type,
+				Source:     match.Source,
+				Rule:       match.Pattern,
+				Confidence: injection.Confidence,
+				Start:      match.Start,
+				End:        match.End,
+			})
+		}
+		if injection.ClassifierScore > 0 && injection.ClassifierScore >= a.config.Injection.Classifier.Threshold {
+			findings = append(findings, Finding{
+				Detector:   "injection",
+				Type:       "classifier",
+				Source:     "classifier",
+				Confidence: injection.ClassifierScore,
+			})
+		}
+	}
+
+	if secrets := analysis.Secrets; secrets != nil {
+		for _, loc := range secrets.Locations {
+			findings = append(findings, Finding{
+				Detector:   "secrets",
+				Type:       loc.Type,
+				Source:     "pattern",
+				Rule:       loc.Type,
+				Confidence: 1.0,
+				Start:      loc.Start,
+				End:        loc.End,
+			})
+		}
+	}
+
+	if entities := analysis.Entities; entities != nil {
+		for _, mention := range entities.Mentions {
+			findings = append(findings, Finding{
+				Detector:   "entities",
+				Type:       mention.Type,
+				Source:     mention.Source,
+				Confidence: mention.Confidence,
+				Start:      mention.Start,
+				End:        mention.End,
+			})
+		}
+	}
+
+	if topic := analysis.Topic; topic != nil && topic.Matched {
+		findings = append(findings, Finding{
+			Detector:   "topics",
+			Type:       topic.Kind,
+			Source:     "embedding",
+			Rule:       topic.Name,
+			Confidence: topic.Score,
+		})
+	}
+
+	if classification := analysis.Classification; classification != nil {
+		for _, reason := range classification.Reasons {
+			findings = append(findings, Finding{
+				Detector:   "classification",
+				Type:       classification.Label,
+				Source:     "rule",
+				Rule:       reason,
+				Confidence: 1.0,
+			})
+		}
+	}
+
+	return findings
+}
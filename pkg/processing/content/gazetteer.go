@@ -0,0 +1,106 @@
+package content
+
+// gazetteer lists the built-in names of each entity type. Names are matched
+// whole-word and case-sensitively, so "Apple" is recognized but "apple" is
+// not.
+var gazetteer = map[string][]string{
+	"organization": {
+		// Technology
+		"Adobe", "Alibaba", "Alphabet", "Amazon", "AMD", "Anthropic", "Apple",
+		"Baidu", "Cisco", "Cloudflare", "Databricks", "Dell", "DeepMind",
+		"Facebook", "GitHub", "GitLab", "Google", "Hugging Face", "IBM",
+		"Intel", "LinkedIn", "Meta", "Microsoft", "Mistral AI", "Netflix",
+		"Nvidia", "NVIDIA", "OpenAI", "Oracle", "Palantir", "PayPal",
+		"Qualcomm", "Salesforce", "Samsung", "SAP", "Shopify", "Snowflake",
+		"Sony", "Spotify", "Stripe", "Tencent", "Tesla", "TikTok", "Twitter",
+		"Uber", "VMware",
+		// Finance
+		"American Express", "Bank of America", "Barclays", "BlackRock",
+		"Citigroup", "Deutsche Bank", "Goldman Sachs", "HSBC",
+		"JPMorgan Chase", "Mastercard", "Morgan Stanley", "UBS", "Visa",
+		"Wells Fargo",
+		// Healthcare and industry
+		"AstraZeneca", "Boeing", "Chevron", "ExxonMobil", "General Electric",
+		"Johnson & Johnson", "Moderna", "Novartis", "Pfizer", "Roche",
+		"Shell", "Siemens", "Toyota", "Volkswagen", "Walmart",
+		// Consulting
+		"Accenture", "Deloitte", "KPMG", "McKinsey", "PwC",
+		// Government and international
+		"CIA", "European Commission", "European Union", "FBI", "IMF", "NASA",
+		"NATO", "NSA", "United Nations", "World Bank", "World Health Organization",
+		"WHO",
+	},
+	"location": {
+		// Continents and regions
+		"Africa", "Antarctica", "Asia", "Europe", "Latin America",
+		"Middle East", "North America", "Oceania", "South America",
+		"Southeast Asia",
+		// Countries
+		"Argentina", "Australia", "Austria", "Bangladesh", "Belgium",
+		"Brazil", "Canada", "Chile", "China", "Colombia", "Czech Republic",
+		"Denmark", "Egypt", "Ethiopia", "Finland", "France", "Germany",
+		"Greece", "Hungary", "India", "Indonesia", "Iran", "Iraq", "Ireland",
+		"Israel", "Italy", "Japan", "Kenya", "Malaysia", "Mexico",
+		"Morocco", "Netherlands", "New Zealand", "Nigeria", "North Korea",
+		"Norway", "Pakistan", "Peru", "Philippines", "Poland", "Portugal",
+		"Romania", "Russia", "Saudi Arabia", "Singapore", "South Africa",
+		"South Korea", "Spain", "Sweden", "Switzerland", "Syria", "Taiwan",
+		"Thailand", "Turkey", "Ukraine", "United Arab Emirates",
+		"United Kingdom", "United States", "USA", "UK", "Venezuela",
+		"Vietnam",
+		// US states
+		"Alabama", "Alaska", "Arizona", "California", "Colorado",
+		"Connecticut", "Florida", "Georgia", "Hawaii", "Illinois", "Massachusetts",
+		"Michigan", "Minnesota", "Nevada", "New Jersey", "New York", "Ohio",
+		"Oregon", "Pennsylvania", "Texas", "Utah", "Virginia", "Washington",
+		// Cities
+		"Amsterdam", "Athens", "Atlanta", "Bangkok", "Barcelona", "Beijing",
+		"Berlin", "Boston", "Brussels", "Buenos Aires", "Cairo", "Chicago",
+		"Copenhagen", "Delhi", "Dubai", "Dublin", "Frankfurt", "Geneva",
+		"Hong Kong", "Istanbul", "Jakarta", "Lagos", "Lisbon", "London",
+		"Los Angeles", "Madrid", "Melbourne", "Mexico City", "Miami", "Milan",
+		"Moscow", "Mumbai", "Munich", "Nairobi", "New Delhi", "Oslo", "Paris",
+		"Prague", "Rome", "San Francisco", "Santiago", "Seattle", "Seoul",
+		"Shanghai", "Stockholm", "Sydney", "Tokyo", "Toronto", "Vancouver",
+		"Vienna", "Warsaw", "Zurich",
+	},
+	"person": {
+		"Barack Obama", "Bill Gates", "Donald Trump", "Elon Musk",
+		"Emmanuel Macron", "Jeff Bezos", "Joe Biden", "Mark Zuckerberg",
+		"Narendra Modi", "Olaf Scholz", "Sam Altman", "Satya Nadella",
+		"Sundar Pichai", "Tim Cook", "Vladimir Putin", "Xi Jinping",
+	},
+}
+
+// firstNames are common given names. A given name followed by a
+// capitalized word, such as "Maria Lopez", is recognized as a person.
+var firstNames = []string{
+	"Aaron", "Adam", "Ahmed", "Aisha", "Alex", "Alexander", "Alice",
+	"Amanda", "Amy", "Ana", "Andrea", "Andrew", "Angela", "Anna", "Anne",
+	"Anthony", "Ashley", "Barbara", "Ben", "Benjamin", "Brian", "Carlos",
+	"Carol", "Catherine", "Charles", "Chen", "Chris", "Christopher",
+	"Daniel", "David", "Deborah", "Diana", "Dmitri", "Elena", "Elizabeth",
+	"Emily", "Emma", "Eric", "Fatima", "Francesco", "Frank", "Gary",
+	"George", "Hannah", "Hans", "Hiroshi", "Isabella", "Ivan", "Jack",
+	"James", "Jane", "Jason", "Jennifer", "Jessica", "John", "Jonathan",
+	"Jose", "Joseph", "Joshua", "Juan", "Julia", "Karen", "Kevin", "Kim",
+	"Laura", "Lisa", "Lucas", "Luis", "Maria", "Mark", "Mary", "Matthew",
+	"Mei", "Michael", "Michelle", "Mohammed", "Muhammad", "Nancy",
+	"Nicole", "Olivia", "Omar", "Patricia", "Paul", "Pedro", "Peter",
+	"Priya", "Rachel", "Rahul", "Raj", "Richard", "Robert", "Ryan", "Sarah",
+	"Sofia", "Sophie", "Stephanie", "Steven", "Susan", "Thomas", "Timothy",
+	"Wei", "William", "Yuki",
+}
+
+// organizationSuffixes end the names of organizations, such as "Acme Corp"
+// or "Initech Holdings".
+var organizationSuffixes = []string{
+	"AG", "Association", "Bank", "Co", "Company", "Corp", "Corporation",
+	"Foundation", "GmbH", "Group", "Holdings", "Inc", "Institute", "LLC",
+	"LLP", "Ltd", "PLC", "SA", "Technologies", "University",
+}
+
+// honorifics precede the names of people, such as "Dr. Grace Hopper".
+var honorifics = []string{
+	"Dr", "Miss", "Mr", "Mrs", "Ms", "Mx", "Prof", "Professor", "Sir",
+}
@@ -0,0 +1,218 @@
+package content
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// maxProfileNGrams is the number of most frequent n-grams kept in a
+// language profile and in the profile of the analyzed text.
+const maxProfileNGrams = 300
+
+// maxLanguageRunes is the number of runes of the text used for language
+// detection. Longer text is truncated, which bounds the cost of detection
+// without affecting its accuracy.
+const maxLanguageRunes = 2000
+
+// minLanguageLetters is the number of letters below which the confidence
+// of a detection is reduced, since short text matches several languages.
+const minLanguageLetters = 20
+
+// languageDistanceScale sharpens the differences between the profile
+// distances of candidate languages when they are turned into a confidence.
+const languageDistanceScale = 40.0
+
+// kanaScript combines Hiragana and Katakana, which identify Japanese even
+// when mixed with Han characters.
+const kanaScript = "Kana"
+
+// languageScripts are the scripts recognized by the language detector.
+var languageScripts = func() []string {
+	scripts := []string{"Latin", "Cyrillic", "Arabic", "Devanagari", "Han", "Hiragana", "Katakana"}
+	for script := range scriptLanguages {
+		scripts = append(scripts, script)
+	}
+	sort.Strings(scripts)
+	return scripts
+}()
+
+// languageProfile is the ranked n-gram profile of a language.
+type languageProfile struct {
+	language string
+	ranks    map[string]int
+}
+
+// languageProfiles are the profiles built from languageSamples, by the
+// script of the sample.
+var languageProfiles = func() map[string][]*languageProfile {
+	profiles := make(map[string][]*languageProfile)
+	for language, sample := range languageSamples {
+		script, _, _ := dominantScript(sample)
+		profiles[script] = append(profiles[script], &languageProfile{
+			language: language,
+			ranks:    rankNGrams(sample),
+		})
+	}
+	for _, candidates := range profiles {
+		sort.Slice(candidates, func(i, j int) bool {
+			return candidates[i].language < candidates[j].language
+		})
+	}
+	return profiles
+}()
+
+// detectLanguage detects the language of text, returning its ISO 639-1
+// code and a confidence from 0.0 to 1.0. It returns an empty code for text
+// without letters.
+//
+// The script of the text identifies languages with a script of their own,
+// such as Greek or Korean. Languages sharing a script, such as the Latin
+// and Cyrillic script languages, are told apart by comparing the ranked
+// character n-grams of the text with the profile of each language.
+func detectLanguage(text string) (string, float64) {
+	if runes := []rune(text); len(runes) > maxLanguageRunes {
+		text = string(runes[:maxLanguageRunes])
+	}
+
+	script, scriptLetters, letters := dominantScript(text)
+	if letters == 0 {
+		return "", 0
+	}
+
+	confidence := float64(scriptLetters) / float64(letters)
+	if letters < minLanguageLetters {
+		confidence *= float64(letters) / minLanguageLetters
+	}
+
+	switch script {
+	case kanaScript:
+		return "ja", confidence
+	case "Han":
+		return "zh", confidence
+	}
+	if language, ok := scriptLanguages[script]; ok {
+		return language, confidence
+	}
+
+	language, score := closestLanguage(rankNGrams(text), languageProfiles[script])
+	return language, confidence * score
+}
+
+// dominantScript returns the script of most letters of text, the number
+// of letters in that script and the total number of letters. Text mixing
+// Han characters with kana is reported as kanaScript.
+func dominantScript(text string) (string, int, int) {
+	counts := make(map[string]int)
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, script := range languageScripts {
+			if unicode.Is(unicode.Scripts[script], r) {
+				counts[script]++
+				break
+			}
+		}
+	}
+
+	kana := counts["Hiragana"] + counts["Katakana"]
+	if kana > 0 {
+		counts[kanaScript] = kana + counts["Han"]
+		delete(counts, "Hiragana")
+		delete(counts, "Katakana")
+		delete(counts, "Han")
+	}
+
+	best, bestCount := "", 0
+	for script, count := range counts {
+		if count > bestCount || (count == bestCount && script < best) {
+			best, bestCount = script, count
+		}
+	}
+	return best, bestCount, letters
+}
+
+// rankNGrams returns the ranks of the most frequent 1- to 3-grams of the
+// words of text, most frequent first. Words are padded with a space, so
+// n-grams at the start and end of words are distinguished.
+func rankNGrams(text string) map[string]int {
+	counts := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsMark(r)
+	})
+	for _, word := range words {
+		runes := []rune(" " + word + " ")
+		for n := 1; n <= 3; n++ {
+			for i := 0; i+n <= len(runes); i++ {
+				gram := string(runes[i : i+n])
+				if gram != " " {
+					counts[gram]++
+				}
+			}
+		}
+	}
+
+	grams := make([]string, 0, len(counts))
+	for gram := range counts {
+		grams = append(grams, gram)
+	}
+	sort.Slice(grams, func(i, j int) bool {
+		if counts[grams[i]] != counts[grams[j]] {
+			return counts[grams[i]] > counts[grams[j]]
+		}
+		return grams[i] < grams[j]
+	})
+	if len(grams) > maxProfileNGrams {
+		grams = grams[:maxProfileNGrams]
+	}
+
+	ranks := make(map[string]int, len(grams))
+	for rank, gram := range grams {
+		ranks[gram] = rank
+	}
+	return ranks
+}
+
+// closestLanguage returns the language of the profile closest to ranks by
+// out-of-place distance, with the share of the closest profile in a
+// softmax over the distances of all candidates.
+func closestLanguage(ranks map[string]int, candidates []*languageProfile) (string, float64) {
+	if len(candidates) == 0 || len(ranks) == 0 {
+		return "", 0
+	}
+
+	distances := make([]float64, len(candidates))
+	best := 0
+	for i, candidate := range candidates {
+		distance := 0
+		for gram, rank := range ranks {
+			if profileRank, ok := candidate.ranks[gram]; ok {
+				distance += abs(rank - profileRank)
+			} else {
+				distance += maxProfileNGrams
+			}
+		}
+		distances[i] = float64(distance) / float64(len(ranks)*maxProfileNGrams)
+		if distances[i] < distances[best] {
+			best = i
+		}
+	}
+
+	total := 0.0
+	for _, distance := range distances {
+		total += math.Exp(-languageDistanceScale * (distance - distances[best]))
+	}
+	return candidates[best].language, 1 / total
+}
+
+// abs returns the absolute value of n.
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
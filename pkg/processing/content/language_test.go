@@ -0,0 +1,71 @@
+package content
+
+import "testing"
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Can you help me write a function that sorts a list of numbers?", "en"},
+		{"¿Puedes ayudarme a escribir una función que ordene una lista de números?", "es"},
+		{"Peux-tu m'aider à écrire une fonction qui trie une liste de nombres ?", "fr"},
+		{"Kannst du mir helfen, eine Funktion zu schreiben, die eine Liste von Zahlen sortiert?", "de"},
+		{"Você pode me ajudar a escrever uma função que ordena uma lista de números?", "pt"},
+		{"Czy możesz mi pomóc napisać funkcję, która sortuje listę liczb?", "pl"},
+		{"Можешь помочь мне написать функцию, которая сортирует список чисел?", "ru"},
+		{"Чи можеш ти допомогти мені написати функцію, яка сортує список чисел?", "uk"},
+		{"هل يمكنك مساعدتي في كتابة دالة تقوم بفرز قائمة من الأرقام؟", "ar"},
+		{"क्या आप मुझे एक फ़ंक्शन लिखने में मदद कर सकते हैं जो संख्याओं की सूची को क्रमबद्ध करता है?", "hi"},
+		{"数字のリストを並べ替える関数を書くのを手伝ってもらえますか？", "ja"},
+		{"你能帮我写一个对数字列表排序的函数吗？", "zh"},
+		{"숫자 목록을 정렬하는 함수를 작성하도록 도와줄 수 있나요?", "ko"},
+		{"Μπορείς να με βοηθήσεις να γράψω μια συνάρτηση;", "el"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			language, confidence := detectLanguage(tt.text)
+			if language != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, language)
+			}
+			if confidence < 0.4 || confidence > 1.0 {
+				t.Errorf("Expected confidence in [0.4, 1.0], got %f", confidence)
+			}
+		})
+	}
+}
+
+func TestDetectLanguage_LowConfidence(t *testing.T) {
+	if language, confidence := detectLanguage("12345 !!!"); language != "" || confidence != 0 {
+		t.Errorf("Expected no language for text without letters, got %q (%f)", language, confidence)
+	}
+
+	// Short text matches several languages
+	if language, confidence := detectLanguage("ok"); language == "" || confidence > 0.2 {
+		t.Errorf("Expected low confidence guess for short text, got %q (%f)", language, confidence)
+	}
+
+	// Confidence is reduced for mixed scripts
+	_, mixed := detectLanguage("Пожалуйста, помогите мне with this function please")
+	if mixed > 0.8 {
+		t.Errorf("Expected reduced confidence for mixed scripts, got %f", mixed)
+	}
+}
+
+func TestLanguageProfiles(t *testing.T) {
+	count := len(scriptLanguages) + 2 // ja and zh are identified by Han and kana
+	for _, profiles := range languageProfiles {
+		count += len(profiles)
+	}
+	if count < 50 {
+		t.Errorf("Expected at least 50 languages, got %d", count)
+	}
+
+	// Every sample is detected as its own language
+	for language, sample := range languageSamples {
+		if got, _ := detectLanguage(sample); got != language {
+			t.Errorf("Expected sample of %q to be detected as itself, got %q", language, got)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package content
+
+// languageSamples are short samples of common words and phrases of each
+// language whose script is shared with other languages. The n-gram
+// profiles of the language detector are built from them.
+var languageSamples = map[string]string{
+	// Latin script
+	"en": "the quick brown fox jumps over the lazy dog. this is a sentence that we are writing in english and it should be easy to read. what do you think about the weather today? i would like to know how many people have been there with them and their friends. there is nothing which could not be done if you want to",
+	"es": "el rápido zorro marrón salta sobre el perro perezoso. esta es una frase que estamos escribiendo en español y debería ser fácil de leer. qué piensas del tiempo de hoy? me gustaría saber cuántas personas han estado allí con ellos y sus amigos. no hay nada que no se pueda hacer si quieres, porque la vida es para los que",
+	"fr": "le renard brun rapide saute par dessus le chien paresseux. c'est une phrase que nous écrivons en français et elle devrait être facile à lire. que penses-tu du temps qu'il fait aujourd'hui? je voudrais savoir combien de personnes ont été là avec eux et leurs amis. il n'y a rien qui ne puisse être fait si vous le voulez, parce que",
+	"de": "der schnelle braune fuchs springt über den faulen hund. das ist ein satz, den wir auf deutsch schreiben, und er sollte leicht zu lesen sein. was denkst du über das wetter heute? ich möchte wissen, wie viele menschen mit ihnen und ihren freunden dort gewesen sind. es gibt nichts, was nicht gemacht werden kann, wenn du es willst",
+	"it": "la volpe marrone veloce salta sopra il cane pigro. questa è una frase che stiamo scrivendo in italiano e dovrebbe essere facile da leggere. cosa pensi del tempo di oggi? vorrei sapere quante persone sono state lì con loro e i loro amici. non c'è niente che non si possa fare se lo vuoi, perché la vita è per chi ha il coraggio della",
+	"pt": "a rápida raposa marrom pula sobre o cão preguiçoso. esta é uma frase que estamos escrevendo em português e deve ser fácil de ler. o que você acha do tempo hoje? eu gostaria de saber quantas pessoas estiveram lá com eles e seus amigos. não há nada que não possa ser feito se você quiser, porque a vida é para quem não tem",
+	"nl": "de snelle bruine vos springt over de luie hond. dit is een zin die we in het nederlands schrijven en die gemakkelijk te lezen moet zijn. wat vind je van het weer vandaag? ik zou graag willen weten hoeveel mensen daar met hen en hun vrienden zijn geweest. er is niets dat niet gedaan kan worden als je het wilt, want het leven is voor",
+	"sv": "den snabba bruna räven hoppar över den lata hunden. det här är en mening som vi skriver på svenska och den borde vara lätt att läsa. vad tycker du om vädret i dag? jag skulle vilja veta hur många människor som har varit där med dem och deras vänner. det finns ingenting som inte kan göras om du vill det, för livet är",
+	"da": "den hurtige brune ræv springer over den dovne hund. dette er en sætning, som vi skriver på dansk, og den burde være let at læse. hvad synes du om vejret i dag? jeg vil gerne vide, hvor mange mennesker der har været der med dem og deres venner. der er ikke noget, som ikke kan gøres, hvis du vil det, fordi livet er",
+	"nb": "den raske brune reven hopper over den late hunden. dette er en setning som vi skriver på norsk, og den burde være lett å lese. hva synes du om været i dag? jeg vil gjerne vite hvor mange mennesker som har vært der med dem og vennene deres. det er ingenting som ikke kan gjøres hvis du vil det, fordi livet er for dem som ikke",
+	"fi": "nopea ruskea kettu hyppää laiskan koiran yli. tämä on lause, jonka kirjoitamme suomeksi, ja sen pitäisi olla helppo lukea. mitä mieltä olet tämän päivän säästä? haluaisin tietää, kuinka monta ihmistä on ollut siellä heidän ja heidän ystäviensä kanssa. ei ole mitään, mitä ei voisi tehdä, jos sinä haluat sitä, koska elämä on",
+	"pl": "szybki brązowy lis przeskakuje nad leniwym psem. to jest zdanie, które piszemy po polsku i powinno być łatwe do przeczytania. co myślisz o dzisiejszej pogodzie? chciałbym wiedzieć, ilu ludzi było tam z nimi i ich przyjaciółmi. nie ma nic, czego nie można zrobić, jeśli tego chcesz, ponieważ życie jest dla tych, którzy się nie",
+	"cs": "rychlá hnědá liška skáče přes líného psa. toto je věta, kterou píšeme česky, a měla by být snadno čitelná. co si myslíš o dnešním počasí? rád bych věděl, kolik lidí tam bylo s nimi a jejich přáteli. není nic, co by se nedalo udělat, pokud to chceš, protože život je pro ty, kteří se nebojí a kteří vědí, že",
+	"sk": "rýchla hnedá líška skáče cez lenivého psa. toto je veta, ktorú píšeme po slovensky, a mala by byť ľahko čitateľná. čo si myslíš o dnešnom počasí? rád by som vedel, koľko ľudí tam bolo s nimi a ich priateľmi. nie je nič, čo by sa nedalo urobiť, ak to chceš, pretože život je pre tých, ktorí sa neboja a ktorí vedia, že",
+	"hu": "a gyors barna róka átugrik a lusta kutya fölött. ez egy mondat, amelyet magyarul írunk, és könnyen olvashatónak kell lennie. mit gondolsz a mai időjárásról? szeretném tudni, hány ember volt ott velük és a barátaikkal. nincs semmi, amit ne lehetne megcsinálni, ha akarod, mert az élet azoké, akik nem félnek és akik tudják, hogy",
+	"ro": "vulpea maro rapidă sare peste câinele leneș. aceasta este o propoziție pe care o scriem în limba română și ar trebui să fie ușor de citit. ce crezi despre vremea de astăzi? aș vrea să știu câți oameni au fost acolo cu ei și cu prietenii lor. nu există nimic care să nu poată fi făcut dacă vrei, pentru că viața este pentru cei care",
+	"tr": "hızlı kahverengi tilki tembel köpeğin üzerinden atlar. bu türkçe yazdığımız bir cümle ve okunması kolay olmalı. bugünkü hava hakkında ne düşünüyorsun? onlarla ve arkadaşlarıyla birlikte orada kaç kişinin bulunduğunu bilmek isterim. eğer istersen yapılamayacak hiçbir şey yoktur, çünkü hayat korkmayanlar için ve bunu bilenler içindir",
+	"vi": "con cáo nâu nhanh nhẹn nhảy qua con chó lười biếng. đây là một câu mà chúng tôi đang viết bằng tiếng việt và nó phải dễ đọc. bạn nghĩ gì về thời tiết hôm nay? tôi muốn biết có bao nhiêu người đã ở đó với họ và bạn bè của họ. không có gì là không thể làm được nếu bạn muốn, bởi vì cuộc sống là dành cho những người",
+	"id": "rubah cokelat yang cepat melompati anjing yang malas. ini adalah kalimat yang kami tulis dalam bahasa indonesia dan seharusnya mudah dibaca. apa pendapatmu tentang cuaca hari ini? saya ingin tahu berapa banyak orang yang sudah berada di sana bersama mereka dan teman-teman mereka. tidak ada yang tidak bisa dilakukan jika kamu mau, karena hidup adalah untuk",
+	"ms": "musang perang yang pantas melompat ke atas anjing yang malas. ini ialah ayat yang kami tulis dalam bahasa melayu dan sepatutnya mudah dibaca. apakah pendapat anda tentang cuaca hari ini? saya ingin tahu berapa ramai orang yang telah berada di sana bersama mereka dan kawan-kawan mereka. tiada apa-apa yang tidak boleh dilakukan jika anda mahu, kerana kehidupan",
+	"tl": "ang mabilis na kayumangging soro ay tumalon sa ibabaw ng tamad na aso. ito ay isang pangungusap na isinusulat namin sa tagalog at dapat itong madaling basahin. ano ang palagay mo sa panahon ngayon? gusto kong malaman kung ilang tao ang naroon kasama nila at ng kanilang mga kaibigan. walang bagay na hindi magagawa kung gusto mo, dahil ang buhay ay para sa mga",
+	"sw": "mbweha mwepesi wa kahawia anaruka juu ya mbwa mvivu. hii ni sentensi ambayo tunaandika kwa kiswahili na inapaswa kuwa rahisi kusoma. unafikiri nini kuhusu hali ya hewa leo? ningependa kujua ni watu wangapi wamekuwa huko pamoja nao na marafiki zao. hakuna kitu ambacho hakiwezi kufanyika ukitaka, kwa sababu maisha ni kwa wale ambao hawaogopi na wanaojua kwamba",
+	"hr": "brza smeđa lisica skače preko lijenog psa. ovo je rečenica koju pišemo na hrvatskom i trebala bi biti laka za čitanje. što misliš o današnjem vremenu? želio bih znati koliko je ljudi bilo tamo s njima i njihovim prijateljima. nema ničega što se ne može učiniti ako to želiš, jer život je za one koji se ne boje i koji znaju da",
+	"sl": "hitra rjava lisica skoči čez lenega psa. to je stavek, ki ga pišemo v slovenščini, in bi ga moralo biti lahko prebrati. kaj misliš o današnjem vremenu? rad bi vedel, koliko ljudi je bilo tam z njimi in njihovimi prijatelji. ni ničesar, česar ne bi bilo mogoče narediti, če to želiš, ker je življenje za tiste, ki se ne bojijo in ki vedo, da",
+	"lt": "greita ruda lapė šokinėja per tingų šunį. tai sakinys, kurį rašome lietuviškai, ir jį turėtų būti lengva perskaityti. ką manai apie šiandienos orą? norėčiau žinoti, kiek žmonių ten buvo su jais ir jų draugais. nėra nieko, ko negalima padaryti, jei tu to nori, nes gyvenimas yra tiems, kurie nebijo ir kurie žino, kad",
+	"lv": "ātrā brūnā lapsa lec pāri slinkajam sunim. šis ir teikums, ko mēs rakstām latviski, un to vajadzētu būt viegli izlasīt. ko tu domā par šodienas laikapstākļiem? es gribētu zināt, cik daudz cilvēku tur ir bijuši kopā ar viņiem un viņu draugiem. nav nekā, ko nevarētu izdarīt, ja tu to vēlies, jo dzīve ir tiem, kuri nebaidās un kuri zina, ka",
+	"et": "kiire pruun rebane hüppab üle laisa koera. see on lause, mida me kirjutame eesti keeles, ja seda peaks olema lihtne lugeda. mida sa arvad tänasest ilmast? ma tahaksin teada, kui palju inimesi on seal olnud koos nendega ja nende sõpradega. pole midagi, mida ei saaks teha, kui sa seda tahad, sest elu on neile, kes ei karda ja kes teavad, et",
+	"ca": "la ràpida guineu marró salta per sobre del gos mandrós. aquesta és una frase que estem escrivint en català i hauria de ser fàcil de llegir. què penses del temps que fa avui? m'agradaria saber quantes persones hi han estat amb ells i els seus amics. no hi ha res que no es pugui fer si ho vols, perquè la vida és per als que no tenen por",
+	"eu": "azeri marroi azkarrak txakur alferraren gainetik salto egiten du. hau euskaraz idazten ari garen esaldi bat da eta irakurtzeko erraza izan beharko luke. zer iruditzen zaizu gaurko eguraldia? jakin nahiko nuke zenbat jende egon den han haiekin eta haien lagunekin. ez dago egin ezin den ezer nahi baduzu, bizitza beldurrik ez dutenentzat baita eta badakitenentzat",
+	"gl": "o raposo marrón rápido salta sobre o can preguiceiro. esta é unha frase que estamos a escribir en galego e debería ser doada de ler. que pensas do tempo de hoxe? gustaríame saber cantas persoas estiveron alí con eles e cos seus amigos. non hai nada que non se poida facer se ti queres, porque a vida é para os que non teñen medo e para os que",
+	"sq": "dhelpra e shpejtë kafe kërcen mbi qenin dembel. kjo është një fjali që po e shkruajmë në shqip dhe duhet të jetë e lehtë për t'u lexuar. çfarë mendon për motin e sotëm? do të doja të dija sa njerëz kanë qenë atje me ta dhe me miqtë e tyre. nuk ka asgjë që nuk mund të bëhet nëse ti e do, sepse jeta është për ata që nuk kanë frikë",
+	"af": "die vinnige bruin jakkals spring oor die lui hond. dit is 'n sin wat ons in afrikaans skryf en dit behoort maklik te wees om te lees. wat dink jy van die weer vandag? ek wil graag weet hoeveel mense saam met hulle en hul vriende daar was. daar is niks wat nie gedoen kan word as jy dit wil hê nie, want die lewe is vir diegene wat nie bang is nie",
+	"ga": "léimeann an sionnach donn tapa thar an madra leisciúil. is abairt í seo atá á scríobh againn i ngaeilge agus ba cheart go mbeadh sé éasca í a léamh. cad a cheapann tú faoin aimsir inniu? ba mhaith liom a fháil amach cé mhéad duine a bhí ann leo agus lena gcairde. níl aon rud nach féidir a dhéanamh má tá sé uait, mar is do na daoine nach bhfuil eagla orthu an saol",
+	"cy": "mae'r llwynog brown cyflym yn neidio dros y ci diog. dyma frawddeg rydyn ni'n ei hysgrifennu yn gymraeg a dylai fod yn hawdd ei darllen. beth wyt ti'n ei feddwl am y tywydd heddiw? hoffwn i wybod faint o bobl sydd wedi bod yno gyda nhw a'u ffrindiau. does dim byd na ellir ei wneud os wyt ti eisiau, oherwydd mae bywyd ar gyfer y rhai sydd ddim yn ofni",
+	"is": "hinn snöggi brúni refur stekkur yfir lata hundinn. þetta er setning sem við erum að skrifa á íslensku og hún ætti að vera auðveld að lesa. hvað finnst þér um veðrið í dag? mig langar að vita hversu margir hafa verið þar með þeim og vinum þeirra. það er ekkert sem ekki er hægt að gera ef þú vilt það, því lífið er fyrir þá sem eru ekki hræddir",
+
+	// Cyrillic script
+	"ru": "быстрая коричневая лиса прыгает через ленивую собаку. это предложение, которое мы пишем на русском языке, и его должно быть легко читать. что ты думаешь о сегодняшней погоде? я хотел бы знать, сколько людей было там вместе с ними и их друзьями. нет ничего, что нельзя было бы сделать, если ты этого хочешь, потому что жизнь для тех, кто не боится",
+	"uk": "швидка коричнева лисиця стрибає через ледачого собаку. це речення, яке ми пишемо українською мовою, і його має бути легко читати. що ти думаєш про сьогоднішню погоду? я хотів би знати, скільки людей було там разом з ними та їхніми друзями. немає нічого, що не можна було б зробити, якщо ти цього хочеш, тому що життя для тих, хто не боїться і хто знає, що",
+	"bg": "бързата кафява лисица прескача мързеливото куче. това е изречение, което пишем на български език, и трябва да е лесно за четене. какво мислиш за времето днес? бих искал да знам колко хора са били там с тях и с техните приятели. няма нищо, което да не може да се направи, ако го искаш, защото животът е за тези, които не се страхуват и които знаят, че",
+	"sr": "брза смеђа лисица скаче преко лењог пса. ово је реченица коју пишемо на српском језику и требало би да буде лака за читање. шта мислиш о данашњем времену? волео бих да знам колико је људи било тамо са њима и њиховим пријатељима. нема ничега што се не може урадити ако то желиш, јер живот је за оне који се не плаше и који знају да",
+	"mk": "брзата кафеава лисица скока преку мрзливото куче. ова е реченица што ја пишуваме на македонски јазик и треба да биде лесна за читање. што мислиш за времето денес? би сакал да знам колку луѓе биле таму со нив и со нивните пријатели. нема ништо што не може да се направи ако го сакаш, бидејќи животот е за оние што не се плашат и што знаат дека",
+	"be": "хуткая карычневая ліса скача праз лянівага сабаку. гэта сказ, які мы пішам на беларускай мове, і яго павінна быць лёгка чытаць. што ты думаеш пра сённяшняе надвор'е? я хацеў бы ведаць, колькі людзей было там разам з імі і іх сябрамі. няма нічога, што нельга было б зрабіць, калі ты гэтага хочаш, таму што жыццё для тых, хто не баіцца",
+	"kk": "жылдам қоңыр түлкі жалқау иттің үстінен секіреді. бұл біз қазақ тілінде жазып отырған сөйлем және оны оқу оңай болуы керек. бүгінгі ауа райы туралы не ойлайсың? мен олармен және олардың достарымен бірге онда қанша адам болғанын білгім келеді. егер сен қаласаң, жасауға болмайтын ештеңе жоқ, өйткені өмір қорықпайтындар үшін және білетіндер үшін",
+
+	// Arabic script
+	"ar": "الثعلب البني السريع يقفز فوق الكلب الكسول. هذه جملة نكتبها باللغة العربية ويجب أن تكون سهلة القراءة. ما رأيك في الطقس اليوم؟ أود أن أعرف كم عدد الأشخاص الذين كانوا هناك معهم ومع أصدقائهم. لا يوجد شيء لا يمكن فعله إذا كنت تريد ذلك، لأن الحياة لمن لا يخافون والذين يعرفون أن",
+	"fa": "روباه قهوه ای سریع از روی سگ تنبل می پرد. این جمله ای است که ما به زبان فارسی می نویسیم و باید خواندن آن آسان باشد. نظرت درباره هوای امروز چیست؟ می خواهم بدانم چند نفر با آنها و دوستانشان آنجا بوده اند. هیچ چیزی نیست که نتوان انجام داد اگر تو بخواهی، چون زندگی برای کسانی است که نمی ترسند و می دانند که",
+	"ur": "تیز بھورا لومڑ سست کتے کے اوپر سے چھلانگ لگاتا ہے۔ یہ ایک جملہ ہے جو ہم اردو میں لکھ رہے ہیں اور اسے پڑھنا آسان ہونا چاہیے۔ آج کے موسم کے بارے میں آپ کا کیا خیال ہے؟ میں جاننا چاہتا ہوں کہ ان کے اور ان کے دوستوں کے ساتھ وہاں کتنے لوگ تھے۔ کوئی ایسی چیز نہیں جو نہ کی جا سکے اگر آپ چاہیں، کیونکہ زندگی ان کے لیے ہے جو نہیں ڈرتے",
+
+	// Devanagari script
+	"hi": "तेज़ भूरी लोमड़ी आलसी कुत्ते के ऊपर से कूदती है। यह एक वाक्य है जो हम हिंदी में लिख रहे हैं और इसे पढ़ना आसान होना चाहिए। आज के मौसम के बारे में आप क्या सोचते हैं? मैं जानना चाहता हूँ कि उनके और उनके दोस्तों के साथ वहाँ कितने लोग थे। ऐसा कुछ भी नहीं है जो नहीं किया जा सकता अगर आप चाहें, क्योंकि जीवन उनके लिए है जो डरते नहीं हैं",
+	"mr": "वेगवान तपकिरी कोल्हा आळशी कुत्र्यावरून उडी मारतो. हे एक वाक्य आहे जे आम्ही मराठीत लिहित आहोत आणि ते वाचायला सोपे असले पाहिजे. आजच्या हवामानाबद्दल तुम्हाला काय वाटते? मला जाणून घ्यायचे आहे की त्यांच्याबरोबर आणि त्यांच्या मित्रांबरोबर तिथे किती लोक होते. असे काहीही नाही जे करता येत नाही जर तुम्हाला हवे असेल, कारण आयुष्य त्यांच्यासाठी आहे जे घाबरत नाहीत",
+	"ne": "छिटो खैरो स्याल अल्छी कुकुरमाथि उफ्रन्छ। यो एउटा वाक्य हो जुन हामी नेपालीमा लेख्दैछौं र यसलाई पढ्न सजिलो हुनुपर्छ। आजको मौसमको बारेमा तपाईं के सोच्नुहुन्छ? म जान्न चाहन्छु कि उनीहरू र उनीहरूका साथीहरूसँग त्यहाँ कति मानिसहरू थिए। यस्तो केही छैन जुन गर्न सकिँदैन यदि तपाईं चाहनुहुन्छ भने, किनभने जीवन ती मानिसहरूका लागि हो जो डराउँदैनन्",
+}
+
+// scriptLanguages maps scripts used by a single language (as far as the
+// detector is concerned) to that language.
+var scriptLanguages = map[string]string{
+	"Greek":     "el",
+	"Hebrew":    "he",
+	"Georgian":  "ka",
+	"Armenian":  "hy",
+	"Thai":      "th",
+	"Hangul":    "ko",
+	"Bengali":   "bn",
+	"Tamil":     "ta",
+	"Telugu":    "te",
+	"Kannada":   "kn",
+	"Malayalam": "ml",
+	"Gujarati":  "gu",
+	"Gurmukhi":  "pa",
+	"Sinhala":   "si",
+	"Khmer":     "km",
+	"Lao":       "lo",
+	"Myanmar":   "my",
+	"Ethiopic":  "am",
+	"Tibetan":   "bo",
+}
@@ -0,0 +1,128 @@
+package content
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Placeholders maps detected PII to typed placeholders such as [EMAIL_1]
+// and [SSN_1], so PII can be replaced in a request before it is sent to a
+// provider and restored in the response.
+//
+// The same value is always replaced by the same placeholder, so a request
+// whose messages are redacted with one Placeholders stays consistent across
+// messages. Placeholders is not safe for concurrent use.
+type Placeholders struct {
+	// placeholders maps each value to its placeholder
+	placeholders map[string]string
+
+	// values maps each placeholder to its value
+	values map[string]string
+
+	// counts is the number of placeholders of each type
+	counts map[string]int
+}
+
+// NewPlaceholders creates an empty placeholder mapping.
+func NewPlaceholders() *Placeholders {
+	return &Placeholders{
+		placeholders: make(map[string]string),
+		values:       make(map[string]string),
+		counts:       make(map[string]int),
+	}
+}
+
+// Len returns the number of placeholders.
+func (p *Placeholders) Len() int {
+	return len(p.values)
+}
+
+// Value returns the value replaced by placeholder.
+func (p *Placeholders) Value(placeholder string) (string, bool) {
+	value, ok := p.values[placeholder]
+	return value, ok
+}
+
+// Replace returns text with the PII at locations replaced by typed
+// placeholders. Overlapping locations are replaced as one, with the type of
+// the first.
+func (p *Placeholders) Replace(text string, locations []PIILocation) string {
+	spans := mergeLocations(locations)
+	if len(spans) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	pos := 0
+	for _, span := range spans {
+		b.WriteString(text[pos:span.Start])
+		b.WriteString(p.placeholder(span.Type, text[span.Start:span.End]))
+		pos = span.End
+	}
+	b.WriteString(text[pos:])
+	return b.String()
+}
+
+// Restore returns text with every placeholder replaced by the value it
+// stands for.
+func (p *Placeholders) Restore(text string) string {
+	if len(p.values) == 0 {
+		return text
+	}
+
+	// Restore longer placeholders first, so [EMAIL_10] is not restored as
+	// [EMAIL_1] followed by "0]"
+	placeholders := make([]string, 0, len(p.values))
+	for placeholder := range p.values {
+		placeholders = append(placeholders, placeholder)
+	}
+	sort.Slice(placeholders, func(i, j int) bool {
+		return len(placeholders[i]) > len(placeholders[j])
+	})
+
+	oldnew := make([]string, 0, 2*len(placeholders))
+	for _, placeholder := range placeholders {
+		oldnew = append(oldnew, placeholder, p.values[placeholder])
+	}
+	return strings.NewReplacer(oldnew...).Replace(text)
+}
+
+// placeholder returns the placeholder of a value, assigning the next
+// placeholder of its type if the value has none.
+func (p *Placeholders) placeholder(piiType, value string) string {
+	if placeholder, ok := p.placeholders[value]; ok {
+		return placeholder
+	}
+
+	p.counts[piiType]++
+	placeholder := "[" + strings.ToUpper(piiType) + "_" + strconv.Itoa(p.counts[piiType]) + "]"
+	p.placeholders[value] = placeholder
+	p.values[placeholder] = value
+	return placeholder
+}
+
+// mergeLocations returns locations sorted by start, with overlapping
+// locations merged into one with the type of the first.
+func mergeLocations(locations []PIILocation) []PIILocation {
+	if len(locations) == 0 {
+		return nil
+	}
+
+	sorted := make([]PIILocation, len(locations))
+	copy(sorted, locations)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Start < sorted[j].Start
+	})
+
+	merged := []PIILocation{sorted[0]}
+	for _, loc := range sorted[1:] {
+		last := &merged[len(merged)-1]
+		if loc.Start < last.End {
+			last.End = max(last.End, loc.End)
+			continue
+		}
+		merged = append(merged, loc)
+	}
+	return merged
+}
@@ -0,0 +1,53 @@
+package content
+
+import (
+	"testing"
+
+	"mercator-hq/jupiter/pkg/config"
+)
+
+func TestPlaceholders(t *testing.T) {
+	analyzer := NewAnalyzer(&config.ContentConfig{
+		PII: config.PIIConfig{
+			Enabled: true,
+			Types:   []string{"email", "ssn"},
+		},
+	})
+	placeholders := NewPlaceholders()
+
+	got := analyzer.RedactPlaceholders("Mail a@example.com and b@example.com, SSN 123-45-6789.", placeholders)
+	want := "Mail [EMAIL_1] and [EMAIL_2], SSN [SSN_1]."
+	if got != want {
+		t.Errorf("RedactPlaceholders() = %q, want %q", got, want)
+	}
+
+	// The same value gets the same placeholder
+	got = analyzer.RedactPlaceholders("Reply to b@example.com", placeholders)
+	if got != "Reply to [EMAIL_2]" || placeholders.Len() != 3 {
+		t.Errorf("expected reused placeholder, got %q with %d placeholders", got, placeholders.Len())
+	}
+	if value, ok := placeholders.Value("[SSN_1]"); !ok || value != "123-45-6789" {
+		t.Errorf("Value([SSN_1]) = %q, %v", value, ok)
+	}
+
+	restored := placeholders.Restore("I wrote to [EMAIL_2] about [SSN_1] and [PHONE_1].")
+	if restored != "I wrote to b@example.com about 123-45-6789 and [PHONE_1]." {
+		t.Errorf("Restore() = %q", restored)
+	}
+}
+
+func TestPlaceholders_Restore(t *testing.T) {
+	placeholders := NewPlaceholders()
+	locations := make([]PIILocation, 0, 10)
+	text := ""
+	for i := 0; i < 10; i++ {
+		value := string(rune('a'+i)) + "@example.com"
+		locations = append(locations, PIILocation{Type: "email", Start: len(text), End: len(text) + len(value)})
+		text += value + " "
+	}
+
+	redacted := placeholders.Replace(text, locations)
+	if restored := placeholders.Restore(redacted); restored != text {
+		t.Errorf("Restore(%q) = %q, want %q", redacted, restored, text)
+	}
+}
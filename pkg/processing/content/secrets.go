@@ -0,0 +1,147 @@
+package content
+
+import (
+	"math"
+	"regexp"
+	"slices"
+)
+
+// secretDetector detects one type of secret.
+type secretDetector struct {
+	// secretType is the secret type reported for a match
+	secretType string
+
+	// pattern matches the secret
+	pattern *regexp.Regexp
+
+	// group is the submatch of pattern holding the secret, or 0 for the
+	// whole match
+	group int
+
+	// checkEntropy requires the secret to meet the minimum entropy, for
+	// secrets without a recognizable format
+	checkEntropy bool
+}
+
+// secretDetectors are the secret detectors, most specific first. A match
+// overlapping the match of an earlier detector is not reported, so a GitHub
+// token assigned to "token=" is reported as github_token only.
+var secretDetectors = []*secretDetector{
+	{
+		secretType: "private_key",
+		pattern:    regexp.MustCompile(`-----BEGIN [A-Z0-9 ]*PRIVATE KEY(?: BLOCK)?-----(?:[\s\S]*?-----END [A-Z0-9 ]*PRIVATE KEY(?: BLOCK)?-----)?`),
+	},
+	{
+		secretType: "aws_access_key",
+		pattern:    regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA|ANPA|ANVA|AIPA)[A-Z0-9]{16}\b`),
+	},
+	{
+		secretType:   "aws_secret_key",
+		pattern:      regexp.MustCompile(`(?i)aws[_\-\s.]?secret[_\-\s.]?(?:access[_\-\s.]?)?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})`),
+		group:        1,
+		checkEntropy: true,
+	},
+	{
+		secretType: "github_token",
+		pattern:    regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{22,255})\b`),
+	},
+	{
+		secretType: "slack_token",
+		pattern:    regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9-]{10,}|https://hooks\.slack\.com/services/T[A-Z0-9]+/B[A-Z0-9]+/[A-Za-z0-9]+`),
+	},
+	{
+		secretType: "jwt",
+		pattern:    regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`),
+	},
+	{
+		secretType: "connection_string",
+		pattern:    regexp.MustCompile(`(?i)\b(?:postgres(?:ql)?|mysql|mariadb|mongodb(?:\+srv)?|rediss?|amqps?|mssql|sqlserver)://[^\s:/@]+:[^\s/@]+@[^\s'"]+|\b(?:Server|Data Source)=[^;]+;[^'"\n]*?(?:Password|Pwd)=[^;\s'"]+`),
+	},
+	{
+		secretType:   "generic_secret",
+		pattern:      regexp.MustCompile(`(?i)\b(?:api[_-]?key|api[_-]?secret|access[_-]?token|auth[_-]?token|client[_-]?secret|secret[_-]?key|password|passwd|token)["']?\s*[:=]\s*["']?([A-Za-z0-9_\-/+=.]{16,})`),
+		group:        1,
+		checkEntropy: true,
+	},
+}
+
+// compileSecretDetectors selects the detectors of the configured secret
+// types.
+func (a *Analyzer) compileSecretDetectors() {
+	for _, detector := range secretDetectors {
+		if slices.Contains(a.config.Secrets.Types, detector.secretType) {
+			a.secretDetectors = append(a.secretDetectors, detector)
+		}
+	}
+}
+
+// detectSecrets detects credentials embedded in text. Secrets without a
+// recognizable format are only reported if their entropy meets the
+// configured minimum, which filters out placeholders and example values.
+func (a *Analyzer) detectSecrets(text string) *SecretDetection {
+	detection := &SecretDetection{
+		Types:     make([]string, 0),
+		Locations: make([]SecretLocation, 0),
+	}
+
+	for _, detector := range a.secretDetectors {
+		found := false
+		for _, match := range detector.pattern.FindAllStringSubmatchIndex(text, -1) {
+			start, end := match[2*detector.group], match[2*detector.group+1]
+			if start < 0 || overlapsSecret(detection.Locations, start, end) {
+				continue
+			}
+
+			entropy := shannonEntropy(text[start:end])
+			if detector.checkEntropy && entropy < a.config.Secrets.MinEntropy {
+				continue
+			}
+
+			detection.Locations = append(detection.Locations, SecretLocation{
+				Type:    detector.secretType,
+				Start:   start,
+				End:     end,
+				Entropy: entropy,
+			})
+			found = true
+		}
+		if found {
+			detection.Types = append(detection.Types, detector.secretType)
+		}
+	}
+
+	detection.Count = len(detection.Locations)
+	detection.Detected = detection.Count > 0
+	return detection
+}
+
+// overlapsSecret reports whether [start, end) overlaps a detected secret.
+func overlapsSecret(locations []SecretLocation, start, end int) bool {
+	for _, loc := range locations {
+		if start < loc.End && loc.Start < end {
+			return true
+		}
+	}
+	return false
+}
+
+// shannonEntropy returns the Shannon entropy of s in bits per character.
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+
+	counts := make(map[rune]int)
+	total := 0
+	for _, r := range s {
+		counts[r]++
+		total++
+	}
+
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(total)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
@@ -0,0 +1,170 @@
+package content
+
+import (
+	"bufio"
+	"fmt"
+	"log/slog"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// sensitiveTerms are the built-in terms of each sensitive content category.
+// Terms match whole words, case-insensitively.
+var sensitiveTerms = map[string][]string{
+	"profanity": {
+		"fuck", "fucking", "fucked", "shit", "bullshit", "damn", "ass",
+		"asshole", "bitch", "bastard", "crap", "dick", "piss",
+	},
+	"violence": {
+		"kill", "killing", "killed", "murder", "murdered", "attack", "attacked",
+		"weapon", "weapons", "blood", "bloody", "death", "shoot", "shooting",
+		"stab", "stabbing", "bomb", "bombing", "assault", "massacre", "torture",
+		"behead", "violence", "violent",
+	},
+	"self_harm": {
+		"suicide", "suicidal", "self-harm", "self harm", "kill myself",
+		"end my life", "cut myself", "cutting myself", "hurt myself",
+		"overdose", "want to die", "no reason to live", "slit my wrists",
+	},
+	"sexual": {
+		"sex", "sexual", "porn", "pornography", "pornographic", "nude", "nudes",
+		"naked", "explicit", "erotic", "xxx", "orgasm", "nsfw",
+	},
+	"hate": {
+		"hate", "hateful", "racist", "racism", "bigot", "bigotry",
+		"discrimination", "supremacist", "xenophobic", "homophobic",
+		"antisemitic", "slur", "subhuman", "ethnic cleansing",
+	},
+	"harassment": {
+		"idiot", "stupid", "loser", "worthless", "pathetic", "moron", "shut up",
+		"nobody likes you", "kill yourself", "kys", "i will find you",
+		"you deserve to die", "dox", "doxx",
+	},
+}
+
+// sensitiveAliases maps former category names to the built-in category
+// whose terms they match.
+var sensitiveAliases = map[string]string{
+	"hate_speech":   "hate",
+	"adult_content": "sexual",
+}
+
+// sensitiveCategory is a compiled sensitive content category.
+type sensitiveCategory struct {
+	// name is the category name reported in detection results
+	name string
+
+	// pattern matches the terms of the category
+	pattern *regexp.Regexp
+}
+
+// compileSensitiveCategories compiles the configured categories from their
+// wordlists or built-in terms. A wordlist that cannot be loaded is logged
+// and the built-in terms of the category, if any, are used instead.
+func (a *Analyzer) compileSensitiveCategories() {
+	for _, name := range a.config.Sensitive.Categories {
+		builtin := name
+		if alias, ok := sensitiveAliases[name]; ok {
+			builtin = alias
+		}
+		terms := sensitiveTerms[builtin]
+
+		if path, ok := a.config.Sensitive.Wordlists[name]; ok {
+			wordlist, err := loadWordlist(path)
+			if err != nil {
+				slog.Warn("failed to load sensitive content wordlist",
+					"category", name,
+					"path", path,
+					"error", err,
+				)
+			} else {
+				terms = wordlist
+			}
+		}
+
+		if pattern := keywordPattern(terms); pattern != nil {
+			a.sensitive = append(a.sensitive, sensitiveCategory{name: name, pattern: pattern})
+		}
+	}
+}
+
+// loadWordlist reads the terms of a wordlist file, one per line. Blank
+// lines and lines starting with "#" are skipped.
+func loadWordlist(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var terms []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		terms = append(terms, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read wordlist: %w", err)
+	}
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("wordlist has no terms")
+	}
+	return terms, nil
+}
+
+// detectSensitiveContent scores text in each sensitive content category by
+// the number of distinct terms it matches. A category scores 0.5 for one
+// term, 0.75 for two, and so on, and is reported if its score meets the
+// configured minimum.
+func (a *Analyzer) detectSensitiveContent(text string) *SensitiveContent {
+	detection := &SensitiveContent{
+		Categories: make([]string, 0),
+		Scores:     make(map[string]float64, len(a.sensitive)),
+		Severity:   "low",
+	}
+
+	totalMatches := 0
+	for _, category := range a.sensitive {
+		terms := make(map[string]bool)
+		var matches []SensitiveMatch
+		for _, loc := range category.pattern.FindAllStringIndex(text, -1) {
+			term := strings.ToLower(text[loc[0]:loc[1]])
+			terms[term] = true
+			matches = append(matches, SensitiveMatch{
+				Category: category.name,
+				Term:     term,
+				Start:    loc[0],
+				End:      loc[1],
+			})
+		}
+
+		score := 1 - math.Pow(0.5, float64(len(terms)))
+		detection.Scores[category.name] = score
+		if len(terms) == 0 || score < a.config.Sensitive.MinScore {
+			continue
+		}
+
+		detection.HasSensitiveContent = true
+		detection.Categories = append(detection.Categories, category.name)
+		detection.Matches = append(detection.Matches, matches...)
+		totalMatches += len(terms)
+	}
+
+	detection.MatchCount = totalMatches
+
+	// Determine severity based on match count
+	if totalMatches >= 5 {
+		detection.Severity = "critical"
+	} else if totalMatches >= 3 {
+		detection.Severity = "high"
+	} else if totalMatches >= 1 {
+		detection.Severity = "medium"
+	}
+
+	return detection
+}
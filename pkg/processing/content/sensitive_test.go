@@ -0,0 +1,74 @@
+package content
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"mercator-hq/jupiter/pkg/config"
+)
+
+func TestAnalyzer_DetectSensitiveCategories(t *testing.T) {
+	cfg := &config.ContentConfig{
+		Sensitive: config.SensitiveConfig{
+			Enabled:    true,
+			Categories: []string{"violence", "self_harm", "sexual", "hate", "harassment"},
+			MinScore:   0.5,
+		},
+	}
+	analyzer := NewAnalyzer(cfg)
+
+	detection := analyzer.detectSensitiveContent("I want to end my life, nobody likes you, you worthless loser")
+	if !slices.Equal(detection.Categories, []string{"self_harm", "harassment"}) {
+		t.Errorf("Expected self_harm and harassment, got %v", detection.Categories)
+	}
+	if detection.Scores["self_harm"] != 0.5 || detection.Scores["harassment"] != 0.875 {
+		t.Errorf("Expected self_harm 0.5 and harassment 0.875, got %v", detection.Scores)
+	}
+	if score, ok := detection.Scores["violence"]; !ok || score != 0 {
+		t.Errorf("Expected violence score 0, got %v", detection.Scores)
+	}
+
+	// Terms match whole words only
+	detection = analyzer.detectSensitiveContent("Please classify these Essex assessments")
+	if detection.HasSensitiveContent {
+		t.Errorf("Expected no sensitive content, got %v", detection.Categories)
+	}
+
+	// Categories below the minimum score are scored but not reported
+	cfg.Sensitive.MinScore = 0.7
+	detection = analyzer.detectSensitiveContent("I want to end my life, nobody likes you, you worthless loser")
+	if !slices.Equal(detection.Categories, []string{"harassment"}) || detection.Scores["self_harm"] != 0.5 {
+		t.Errorf("Expected only harassment reported, got %v with scores %v", detection.Categories, detection.Scores)
+	}
+}
+
+func TestAnalyzer_SensitiveWordlists(t *testing.T) {
+	dir := t.TempDir()
+	wordlist := filepath.Join(dir, "gambling.txt")
+	if err := os.WriteFile(wordlist, []byte("# Gambling terms\nslot machine\n\nroulette\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := &config.ContentConfig{
+		Sensitive: config.SensitiveConfig{
+			Enabled:    true,
+			Categories: []string{"gambling", "violence"},
+			Wordlists: map[string]string{
+				"gambling": wordlist,
+				"violence": filepath.Join(dir, "missing.txt"),
+			},
+			MinScore: 0.5,
+		},
+	}
+	analyzer := NewAnalyzer(cfg)
+
+	detection := analyzer.detectSensitiveContent("Play Roulette and the slot machine, or attack")
+	if !slices.Equal(detection.Categories, []string{"gambling", "violence"}) {
+		t.Errorf("Expected gambling and violence, got %v", detection.Categories)
+	}
+	if detection.Scores["gambling"] != 0.75 {
+		t.Errorf("Expected gambling score 0.75, got %v", detection.Scores["gambling"])
+	}
+}
@@ -0,0 +1,173 @@
+package content
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+)
+
+// Embedder computes embeddings of text, such as with a local model or an
+// embeddings provider.
+type Embedder interface {
+	// Embed returns an embedding of each of texts, in order. It must return
+	// when ctx is done.
+	Embed(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// topicCentroidTimeout bounds the embedding of the topic examples, which
+// may be many more than the texts embedded per request.
+const topicCentroidTimeout = 5 * time.Second
+
+// topicCentroid is the average embedding of the examples of a topic.
+type topicCentroid struct {
+	name   string
+	kind   string
+	vector []float64
+}
+
+// SetTopicEmbedder sets the embedder of the topic similarity checks, as
+// configured by the topics configuration. It must be called before the
+// analyzer is used.
+func (a *Analyzer) SetTopicEmbedder(embedder Embedder) {
+	a.topicEmbedder = embedder
+}
+
+// detectTopic returns the topic nearest to text by cosine similarity of
+// their embeddings, or nil if topics are not configured or text could not
+// be embedded.
+func (a *Analyzer) detectTopic(text string) *TopicSimilarity {
+	cfg := &a.config.Topics
+	if a.topicEmbedder == nil || !cfg.Enabled {
+		return nil
+	}
+
+	centroids, err := a.topicCentroids()
+	if err != nil {
+		slog.Warn("topic similarity unavailable", "error", err)
+		return nil
+	}
+
+	ctx := context.Background()
+	if cfg.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+		defer cancel()
+	}
+	embeddings, err := a.topicEmbedder.Embed(ctx, []string{text})
+	if err != nil || len(embeddings) != 1 {
+		slog.Debug("failed to embed content, skipping topic similarity", "error", err)
+		return nil
+	}
+
+	var nearest *TopicSimilarity
+	for _, centroid := range centroids {
+		score := cosineSimilarity(embeddings[0], centroid.vector)
+		if nearest == nil || score > nearest.Score {
+			nearest = &TopicSimilarity{Name: centroid.name, Kind: centroid.kind, Score: score}
+		}
+	}
+	if nearest != nil {
+		nearest.Matched = nearest.Score >= cfg.Threshold
+	}
+	return nearest
+}
+
+// topicCentroids returns the centroids of the configured topics, embedding
+// their examples on first use. If embedding fails, it is retried on the
+// next call.
+func (a *Analyzer) topicCentroids() ([]topicCentroid, error) {
+	a.topicMu.Lock()
+	defer a.topicMu.Unlock()
+
+	if a.centroids != nil {
+		return a.centroids, nil
+	}
+
+	type topic struct {
+		name, kind string
+		examples   []string
+	}
+	var topics []topic
+	var examples []string
+	for _, t := range a.config.Topics.Banned {
+		topics = append(topics, topic{t.Name, "banned", t.Examples})
+		examples = append(examples, t.Examples...)
+	}
+	for _, t := range a.config.Topics.Allowed {
+		topics = append(topics, topic{t.Name, "allowed", t.Examples})
+		examples = append(examples, t.Examples...)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), topicCentroidTimeout)
+	defer cancel()
+	embeddings, err := a.topicEmbedder.Embed(ctx, examples)
+	if err != nil {
+		return nil, fmt.Errorf("failed to embed topic examples: %w", err)
+	}
+	if len(embeddings) != len(examples) {
+		return nil, fmt.Errorf("embedder returned %d embeddings for %d topic examples", len(embeddings), len(examples))
+	}
+
+	centroids := make([]topicCentroid, 0, len(topics))
+	for _, t := range topics {
+		vectors := embeddings[:len(t.examples)]
+		embeddings = embeddings[len(t.examples):]
+		centroids = append(centroids, topicCentroid{
+			name:   t.name,
+			kind:   t.kind,
+			vector: centroid(vectors),
+		})
+	}
+
+	a.centroids = centroids
+	return centroids, nil
+}
+
+// centroid returns the average of the normalized vectors, so every example
+// weighs the same whatever the magnitude of its embedding.
+func centroid(vectors [][]float64) []float64 {
+	if len(vectors) == 0 {
+		return nil
+	}
+
+	sum := make([]float64, len(vectors[0]))
+	for _, v := range vectors {
+		norm := vectorNorm(v)
+		if norm == 0 || len(v) != len(sum) {
+			continue
+		}
+		for i, x := range v {
+			sum[i] += x / norm
+		}
+	}
+	return sum
+}
+
+// cosineSimilarity returns the cosine of the angle between a and b, or 0 if
+// either is zero or their dimensions differ.
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+	normA, normB := vectorNorm(a), vectorNorm(b)
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	dot := 0.0
+	for i := range a {
+		dot += a[i] * b[i]
+	}
+	return dot / (normA * normB)
+}
+
+// vectorNorm returns the Euclidean norm of v.
+func vectorNorm(v []float64) float64 {
+	sum := 0.0
+	for _, x := range v {
+		sum += x * x
+	}
+	return math.Sqrt(sum)
+}
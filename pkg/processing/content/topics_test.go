@@ -0,0 +1,130 @@
+package content
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"testing"
+
+	"mercator-hq/jupiter/pkg/config"
+)
+
+// fakeEmbedder embeds texts as the vectors of a fixed table, failing the
+// calls listed in fail.
+type fakeEmbedder struct {
+	vectors map[string][]float64
+
+	mu    sync.Mutex
+	calls int
+	fail  map[int]bool
+}
+
+func (e *fakeEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	e.mu.Lock()
+	e.calls++
+	call := e.calls
+	e.mu.Unlock()
+
+	if e.fail[call] {
+		return nil, errors.New("embedder unavailable")
+	}
+	embeddings := make([][]float64, len(texts))
+	for i, text := range texts {
+		embeddings[i] = e.vectors[text]
+	}
+	return embeddings, nil
+}
+
+func newTopicAnalyzer(embedder Embedder) *Analyzer {
+	analyzer := NewAnalyzer(&config.ContentConfig{
+		Topics: config.TopicsConfig{
+			Enabled:   true,
+			Threshold: 0.8,
+			Banned:    []config.TopicConfig{{Name: "weapons", Examples: []string{"bomb", "rifle"}}},
+			Allowed:   []config.TopicConfig{{Name: "cooking", Examples: []string{"bread"}}},
+		},
+	})
+	analyzer.SetTopicEmbedder(embedder)
+	return analyzer
+}
+
+func TestAnalyzer_DetectTopic(t *testing.T) {
+	embedder := &fakeEmbedder{vectors: map[string][]float64{
+		"bomb":                {1, 0, 0},
+		"rifle":               {0, 2, 0},
+		"bread":               {0, 0, 1},
+		"build a bomb":        {1, 1, 0},
+		"explosive sourdough": {1, 0, 1},
+		"bake bread":          {0, 0.1, 1},
+	}}
+	analyzer := newTopicAnalyzer(embedder)
+
+	tests := []struct {
+		text        string
+		wantName    string
+		wantKind    string
+		wantMatched bool
+	}{
+		{"build a bomb", "weapons", "banned", true},
+		{"explosive sourdough", "cooking", "allowed", false},
+		{"bake bread", "cooking", "allowed", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			analysis, err := analyzer.AnalyzeText(tt.text)
+			if err != nil {
+				t.Fatalf("AnalyzeText failed: %v", err)
+			}
+			topic := analysis.Topic
+			if topic == nil {
+				t.Fatal("expected topic, got nil")
+			}
+			if topic.Name != tt.wantName || topic.Kind != tt.wantKind || topic.Matched != tt.wantMatched {
+				t.Errorf("expected %s/%s matched=%t, got %+v", tt.wantName, tt.wantKind, tt.wantMatched, topic)
+			}
+		})
+	}
+
+	// The examples are embedded once, then each text
+	if embedder.calls != 4 {
+		t.Errorf("expected 4 embedder calls, got %d", embedder.calls)
+	}
+}
+
+func TestAnalyzer_DetectTopicEmbedderFailure(t *testing.T) {
+	embedder := &fakeEmbedder{
+		vectors: map[string][]float64{"bomb": {1, 0}, "rifle": {1, 0}, "bread": {0, 1}, "bomb threat": {1, 0}},
+		fail:    map[int]bool{1: true, 3: true},
+	}
+	analyzer := newTopicAnalyzer(embedder)
+
+	// Embedding the examples fails, then the text
+	for i := 0; i < 2; i++ {
+		analysis, err := analyzer.AnalyzeText("bomb threat")
+		if err != nil {
+			t.Fatalf("AnalyzeText failed: %v", err)
+		}
+		if analysis.Topic != nil {
+			t.Errorf("call %d: expected no topic, got %+v", i, analysis.Topic)
+		}
+	}
+
+	analysis, _ := analyzer.AnalyzeText("bomb threat")
+	if analysis.Topic == nil || analysis.Topic.Name != "weapons" {
+		t.Errorf("expected weapons topic after recovery, got %+v", analysis.Topic)
+	}
+}
+
+func TestCosineSimilarity(t *testing.T) {
+	if got := cosineSimilarity([]float64{1, 0}, []float64{2, 0}); math.Abs(got-1) > 1e-9 {
+		t.Errorf("cosineSimilarity of parallel vectors = %v, want 1", got)
+	}
+	if got := cosineSimilarity([]float64{1, 0}, []float64{0, 1}); got != 0 {
+		t.Errorf("cosineSimilarity of orthogonal vectors = %v, want 0", got)
+	}
+	if got := cosineSimilarity([]float64{1, 0}, []float64{1, 0, 0}); got != 0 {
+		t.Errorf("cosineSimilarity of mismatched dimensions = %v, want 0", got)
+	}
+}
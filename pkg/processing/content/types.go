@@ -1,7 +1,7 @@
 package content
 
 // ContentAnalysis contains content safety and analysis results.
-// This includes PII detection, sensitive content, prompt injection, and sentiment.
+// This includes PII detection, sensitive content, prompt injection, secrets, and sentiment.
 type ContentAnalysis struct {
 	// PIIDetection contains PII detection results.
 	PIIDetection *PIIDetection
@@ -12,12 +12,38 @@ type ContentAnalysis struct {
 	// PromptInjection contains prompt injection detection results.
 	PromptInjection *PromptInjection
 
+	// Secrets contains detection results for credentials embedded in the
+	// content.
+	Secrets *SecretDetection
+
+	// Entities contains the organizations, people and locations mentioned
+	// in the content.
+	Entities *EntityDetection
+
+	// Classification is the data classification label of the content, or
+	// nil if classification is not enabled.
+	Classification *Classification
+
+	// Topic is the configured topic nearest to the content, or nil if
+	// topic similarity is not configured.
+	Topic *TopicSimilarity
+
 	// Sentiment contains sentiment analysis results.
 	Sentiment *Sentiment
 
-	// Language is the detected language code (e.g., "en", "es", "fr").
+	// Findings explains what each detector found: the pattern, model or
+	// rule that triggered each finding, with its confidence and the span of
+	// the matched excerpt.
+	Findings []Finding
+
+	// Language is the detected ISO 639-1 language code (e.g., "en", "es",
+	// "fr"), or empty if the content has no letters.
 	Language string
 
+	// LanguageConfidence is the confidence of the language detection (0.0
+	// to 1.0). It is low for short or mixed-language content.
+	LanguageConfidence float64
+
 	// WordCount is the total number of words in the content.
 	WordCount int
 
@@ -40,6 +66,10 @@ type PIIDetection struct {
 	// PIICount is the total number of PII instances found.
 	PIICount int
 
+	// Severity is the highest severity of the PII found (low, medium, high,
+	// critical), or empty if no PII was found.
+	Severity string
+
 	// Locations contains the positions of detected PII.
 	Locations []PIILocation
 }
@@ -57,10 +87,18 @@ type PIILocation struct {
 
 	// Confidence is the detection confidence from 0.0 to 1.0.
 	Confidence float64
+
+	// Severity is the severity of the PII type (low, medium, high, critical).
+	Severity string
+
+	// Source is what detected the PII: pattern for the built-in patterns,
+	// custom for custom PII types, or external for an external detector.
+	Source string
 }
 
 // SensitiveContent contains sensitive content detection results.
-// Uses keyword matching for profanity, violence, hate speech, etc.
+// Uses wordlist matching for violence, self-harm, sexual content, hate,
+// harassment, profanity and custom categories.
 type SensitiveContent struct {
 	// HasSensitiveContent indicates whether sensitive content was detected.
 	HasSensitiveContent bool
@@ -68,11 +106,34 @@ type SensitiveContent struct {
 	// Categories lists the categories of sensitive content found.
 	Categories []string
 
+	// Scores is the score (0.0 to 1.0) of each configured category, by
+	// category name.
+	Scores map[string]float64
+
 	// Severity indicates the severity level (low, medium, high, critical).
 	Severity string
 
 	// MatchCount is the number of sensitive keywords matched.
 	MatchCount int
+
+	// Matches contains the positions of the terms matched in the reported
+	// categories.
+	Matches []SensitiveMatch
+}
+
+// SensitiveMatch describes a sensitive content term matched in the content.
+type SensitiveMatch struct {
+	// Category is the sensitive content category of the term.
+	Category string
+
+	// Term is the wordlist term matched, in lowercase.
+	Term string
+
+	// Start is the starting byte offset in the text.
+	Start int
+
+	// End is the ending byte offset in the text.
+	End int
 }
 
 // PromptInjection contains prompt injection detection results.
@@ -81,7 +142,8 @@ type PromptInjection struct {
 	// HasPromptInjection indicates whether prompt injection was detected.
 	HasPromptInjection bool
 
-	// InjectionType describes the type of injection (direct, indirect, jailbreak).
+	// InjectionType describes the type of injection (direct, indirect,
+	// jailbreak, or classifier if only the classifier detected it).
 	InjectionType string
 
 	// Confidence is the detection confidence from 0.0 to 1.0.
@@ -89,6 +151,67 @@ type PromptInjection struct {
 
 	// MatchedPatterns lists the injection patterns that matched.
 	MatchedPatterns []string
+
+	// ClassifierScore is the injection classifier's score from 0.0 to 1.0,
+	// if the classifier was evaluated inline.
+	ClassifierScore float64
+
+	// Matches contains the position of the first match of each matched
+	// pattern.
+	Matches []InjectionMatch
+}
+
+// InjectionMatch describes a prompt injection pattern matched in the
+// content.
+type InjectionMatch struct {
+	// Pattern is the injection pattern matched.
+	Pattern string
+
+	// Type is the injection type of the pattern (direct, indirect,
+	// jailbreak).
+	Type string
+
+	// Source is where the pattern comes from: config or feed.
+	Source string
+
+	// Start is the starting byte offset in the text.
+	Start int
+
+	// End is the ending byte offset in the text.
+	End int
+}
+
+// SecretDetection contains detection results for credentials embedded in
+// content, such as cloud access keys, API tokens, and private keys.
+type SecretDetection struct {
+	// Detected indicates whether secrets were detected.
+	Detected bool
+
+	// Types lists the types of secrets found (aws_access_key, github_token,
+	// private_key, etc.).
+	Types []string
+
+	// Count is the total number of secrets found.
+	Count int
+
+	// Locations contains the positions of detected secrets.
+	Locations []SecretLocation
+}
+
+// SecretLocation describes the position of a detected secret in the content.
+// The secret itself is not recorded.
+type SecretLocation struct {
+	// Type is the secret type (aws_access_key, github_token, etc.).
+	Type string
+
+	// Start is the starting character index.
+	Start int
+
+	// End is the ending character index.
+	End int
+
+	// Entropy is the Shannon entropy of the secret in bits per character.
+	Entropy float64
 }
 
 // Sentiment contains sentiment analysis results.
@@ -103,3 +226,106 @@ type Sentiment struct {
 	// Confidence is the analysis confidence from 0.0 to 1.0.
 	Confidence float64
 }
+
+// TopicSimilarity describes the configured topic nearest to the content by
+// cosine similarity of their embeddings.
+type TopicSimilarity struct {
+	// Name is the name of the nearest topic.
+	Name string
+
+	// Kind is "banned" or "allowed".
+	Kind string
+
+	// Score is the cosine similarity of the content to the topic (up to
+	// 1.0 for identical meaning).
+	Score float64
+
+	// Matched indicates whether the score reaches the configured threshold.
+	Matched bool
+}
+
+// EntityDetection contains the named entities mentioned in the content.
+type EntityDetection struct {
+	// Organizations lists the organizations mentioned, once each.
+	Organizations []string
+
+	// People lists the people mentioned, once each.
+	People []string
+
+	// Locations lists the locations mentioned, once each.
+	Locations []string
+
+	// Count is the total number of entity mentions.
+	Count int
+
+	// Mentions contains each entity mention in the content.
+	Mentions []EntityMention
+}
+
+// EntityMention describes a named entity mentioned in the content.
+type EntityMention struct {
+	// Type is the entity type (organization, person, location).
+	Type string
+
+	// Text is the name as mentioned.
+	Text string
+
+	// Start is the starting byte offset in the text.
+	Start int
+
+	// End is the ending byte offset in the text.
+	End int
+
+	// Source is what recognized the entity (gazetteer, rule, model).
+	Source string
+
+	// Confidence is the confidence of the recognition (0.0 to 1.0).
+	Confidence float64
+}
+
+// Classification is the data classification of the content, the highest
+// label of the classification rules its findings match.
+type Classification struct {
+	// Label is the classification label (e.g., "confidential").
+	Label string
+
+	// Level is the position of the label among the configured labels, from
+	// 0 for the least sensitive.
+	Level int
+
+	// Reasons lists the findings that set the label, such as "pii:email"
+	// or "secret:github_token". It is empty for the default label.
+	Reasons []string
+}
+
+// Finding explains a single finding of content analysis, so that reviewers
+// can verify false positives. It never contains the matched text, only its
+// span, so it can be recorded without disclosing PII or secrets.
+type Finding struct {
+	// Detector is the detector that made the finding (pii, sensitive,
+	// injection, secrets, entities, topics, classification).
+	Detector string `json:"detector"`
+
+	// Type is the type of the finding, such as the PII type, secret type,
+	// sensitive content category or injection type.
+	Type string `json:"type"`
+
+	// Source is what triggered the finding: pattern, custom, external,
+	// config, feed, classifier, wordlist, gazetteer, rule, model or
+	// embedding.
+	Source string `json:"source"`
+
+	// Rule identifies the pattern, term, topic or classification reason
+	// that triggered the finding, if any.
+	Rule string `json:"rule,omitempty"`
+
+	// Confidence is the confidence of the finding from 0.0 to 1.0.
+	Confidence float64 `json:"confidence"`
+
+	// Start is the starting byte offset of the matched excerpt.
+	Start int `json:"start"`
+
+	// End is the ending byte offset of the matched excerpt. It is 0 for
+	// findings about the content as a whole, such as topics.
+	End int `json:"end"`
+}
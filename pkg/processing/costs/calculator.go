@@ -16,15 +16,31 @@ type Calculator struct {
 	// config contains cost calculation configuration
 	config *config.CostsConfig
 
+	// catalog is the pricing catalog, whose prices the configured prices
+	// override (optional)
+	catalog *Catalog
+
+	// pricing is the catalog pricing overlaid with the configured pricing
+	pricing map[string]map[string]pricingEntry
+
 	// mu protects the calculator for concurrent access
 	mu sync.RWMutex
 }
 
+// pricingEntry is the pricing of a model, with the version of the catalog
+// it comes from, or no version if it is configured.
+type pricingEntry struct {
+	config.ModelPricingConfig
+	catalogVersion string
+}
+
 // NewCalculator creates a new cost calculator with the given configuration.
 func NewCalculator(cfg *config.CostsConfig) *Calculator {
-	return &Calculator{
+	c := &Calculator{
 		config: cfg,
 	}
+	c.mergePricing()
+	return c
 }
 
 // CalculateRequestCost calculates the estimated cost for a request based on token estimates.
@@ -36,22 +52,23 @@ func (c *Calculator) CalculateRequestCost(estimate *tokens.Estimate, model, prov
 
 	pricing, err := c.GetModelPricing(model, provider)
 	if err != nil {
-		// Use default pricing and log warning
-		pricing, _ = c.GetModelPricing("default", "default")
+		return nil, err
 	}
 
+	rates := pricing.Rates(estimate.PromptTokens, false)
 	costEst := &CostEstimate{
-		Model:       model,
-		Provider:    provider,
-		PricingTier: "standard",
-		Currency:    "USD",
+		Model:          model,
+		Provider:       provider,
+		PricingTier:    rates.Tier,
+		Currency:       "USD",
+		PricingVersion: pricing.CatalogVersion,
 	}
 
 	// Calculate prompt cost
-	costEst.PromptCost = calculateTokenCost(estimate.PromptTokens, pricing.PromptCostPer1KTokens)
+	costEst.PromptCost = calculateTokenCost(estimate.PromptTokens, rates.Prompt)
 
 	// Calculate estimated completion cost
-	costEst.CompletionCost = calculateTokenCost(estimate.EstimatedCompletionTokens, pricing.CompletionCostPer1KTokens)
+	costEst.CompletionCost = calculateTokenCost(estimate.EstimatedCompletionTokens, rates.Completion)
 
 	// Calculate total cost
 	costEst.TotalCost = costEst.PromptCost + costEst.CompletionCost
@@ -60,7 +77,8 @@ func (c *Calculator) CalculateRequestCost(estimate *tokens.Estimate, model, prov
 }
 
 // CalculateResponseCost calculates the actual cost for a response based on actual token usage.
-// Returns a cost estimate with actual costs from provider usage data.
+// Returns a cost estimate with actual costs from provider usage data, priced at
+// the long context and batch rates where they apply.
 func (c *Calculator) CalculateResponseCost(usage *TokenUsage, model, provider string) (*CostEstimate, error) {
 	if usage == nil {
 		return nil, fmt.Errorf("usage cannot be nil")
@@ -69,33 +87,38 @@ func (c *Calculator) CalculateResponseCost(usage *TokenUsage, model, provider st
 	pricing, err := c.GetModelPricing(model, provider)
 	if err != nil {
 		// Use default pricing and log warning
-		pricing, _ = c.GetModelPricing("default", "default")
+		pricing, err = c.GetModelPricing("default", "default")
+		if err != nil {
+			return nil, err
+		}
 	}
 
+	rates := pricing.Rates(usage.PromptTokens, usage.Batch)
 	costEst := &CostEstimate{
-		Model:       model,
-		Provider:    provider,
-		PricingTier: "standard",
-		Currency:    "USD",
+		Model:          model,
+		Provider:       provider,
+		PricingTier:    rates.Tier,
+		Currency:       "USD",
+		PricingVersion: pricing.CatalogVersion,
 	}
 
 	// Calculate prompt cost (considering cached tokens if applicable)
 	promptTokens := usage.PromptTokens
-	if usage.CachedTokens > 0 && pricing.CachedPromptCostPer1KTokens > 0 {
+	if usage.CachedTokens > 0 && rates.CachedPrompt > 0 {
 		// Some tokens are cached at a discounted rate
 		uncachedTokens := promptTokens - usage.CachedTokens
 		if uncachedTokens < 0 {
 			uncachedTokens = 0
 		}
 
-		costEst.PromptCost = calculateTokenCost(uncachedTokens, pricing.PromptCostPer1KTokens) +
-			calculateTokenCost(usage.CachedTokens, pricing.CachedPromptCostPer1KTokens)
+		costEst.PromptCost = calculateTokenCost(uncachedTokens, rates.Prompt) +
+			calculateTokenCost(usage.CachedTokens, rates.CachedPrompt)
 	} else {
-		costEst.PromptCost = calculateTokenCost(promptTokens, pricing.PromptCostPer1KTokens)
+		costEst.PromptCost = calculateTokenCost(promptTokens, rates.Prompt)
 	}
 
 	// Calculate completion cost
-	costEst.CompletionCost = calculateTokenCost(usage.CompletionTokens, pricing.CompletionCostPer1KTokens)
+	costEst.CompletionCost = calculateTokenCost(usage.CompletionTokens, rates.Completion)
 
 	// Calculate total cost
 	costEst.TotalCost = costEst.PromptCost + costEst.CompletionCost
@@ -114,6 +137,8 @@ func (c *Calculator) CalculateProviderResponseCost(resp *providers.CompletionRes
 		PromptTokens:     resp.Usage.PromptTokens,
 		CompletionTokens: resp.Usage.CompletionTokens,
 		TotalTokens:      resp.Usage.TotalTokens,
+		CachedTokens:     resp.Usage.CachedPromptTokens,
+		Batch:            resp.Usage.Batch,
 	}
 
 	return c.CalculateResponseCost(usage, resp.Model, provider)
@@ -121,48 +146,31 @@ func (c *Calculator) CalculateProviderResponseCost(resp *providers.CompletionRes
 
 // GetModelPricing retrieves pricing information for a specific model and provider.
 // It first tries exact match, then model prefix match, then default pricing.
+// Configured prices take precedence over those of the pricing catalog.
 func (c *Calculator) GetModelPricing(model, provider string) (*ModelPricing, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	// Try exact provider and model match
-	if providerPricing, ok := c.config.Pricing[provider]; ok {
-		if modelConfig, ok := providerPricing[model]; ok {
-			return &ModelPricing{
-				Model:                       model,
-				Provider:                    provider,
-				PromptCostPer1KTokens:       modelConfig.Prompt,
-				CompletionCostPer1KTokens:   modelConfig.Completion,
-				CachedPromptCostPer1KTokens: modelConfig.CachedPrompt,
-				Currency:                    "USD",
-			}, nil
+	if providerPricing, ok := c.pricing[provider]; ok {
+		if entry, ok := providerPricing[model]; ok {
+			return entry.modelPricing(model, provider), nil
 		}
 
 		// Try model prefix match (e.g., "gpt-4" matches "gpt-4-0613")
-		for modelPattern, modelConfig := range providerPricing {
+		for modelPattern, entry := range providerPricing {
 			if strings.HasPrefix(model, modelPattern) {
-				return &ModelPricing{
-					Model:                       model,
-					Provider:                    provider,
-					PromptCostPer1KTokens:       modelConfig.Prompt,
-					CompletionCostPer1KTokens:   modelConfig.Completion,
-					CachedPromptCostPer1KTokens: modelConfig.CachedPrompt,
-					Currency:                    "USD",
-				}, nil
+				return entry.modelPricing(model, provider), nil
 			}
 		}
 	}
 
 	// Fall back to default pricing
-	if defaultProvider, ok := c.config.Pricing["default"]; ok {
-		if defaultModel, ok := defaultProvider["default"]; ok {
-			return &ModelPricing{
-				Model:                     model,
-				Provider:                  provider,
-				PromptCostPer1KTokens:     defaultModel.Prompt,
-				CompletionCostPer1KTokens: defaultModel.Completion,
-				Currency:                  "USD",
-			}, nil
+	if defaultProvider, ok := c.pricing["default"]; ok {
+		if entry, ok := defaultProvider["default"]; ok {
+			pricing := entry.modelPricing(model, provider)
+			pricing.CachedPromptCostPer1KTokens = 0
+			return pricing, nil
 		}
 	}
 
@@ -176,6 +184,66 @@ func (c *Calculator) UpdatePricing(newConfig *config.CostsConfig) {
 	defer c.mu.Unlock()
 
 	c.config = newConfig
+	c.mergePricing()
+}
+
+// SetCatalog replaces the pricing catalog. This is thread-safe and can be
+// called while the calculator is in use.
+func (c *Calculator) SetCatalog(catalog *Catalog) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.catalog = catalog
+	c.mergePricing()
+}
+
+// CatalogVersion returns the version of the pricing catalog in use, or an
+// empty string if there is none.
+func (c *Calculator) CatalogVersion() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.catalog == nil {
+		return ""
+	}
+	return c.catalog.Version
+}
+
+// mergePricing overlays the configured pricing on the catalog pricing. The
+// caller must hold mu or have exclusive access to the calculator.
+func (c *Calculator) mergePricing() {
+	pricing := make(map[string]map[string]pricingEntry)
+	add := func(prices map[string]map[string]config.ModelPricingConfig, version string) {
+		for provider, models := range prices {
+			if pricing[provider] == nil {
+				pricing[provider] = make(map[string]pricingEntry, len(models))
+			}
+			for model, modelPricing := range models {
+				pricing[provider][model] = pricingEntry{ModelPricingConfig: modelPricing, catalogVersion: version}
+			}
+		}
+	}
+
+	if c.catalog != nil {
+		add(c.catalog.Pricing, c.catalog.Version)
+	}
+	add(c.config.Pricing, "")
+	c.pricing = pricing
+}
+
+// modelPricing returns the pricing of model from the entry.
+func (e pricingEntry) modelPricing(model, provider string) *ModelPricing {
+	return &ModelPricing{
+		Model:                       model,
+		Provider:                    provider,
+		PromptCostPer1KTokens:       e.Prompt,
+		CompletionCostPer1KTokens:   e.Completion,
+		CachedPromptCostPer1KTokens: e.CachedPrompt,
+		BatchDiscount:               e.BatchDiscount,
+		LongContext:                 e.LongContext,
+		Currency:                    "USD",
+		CatalogVersion:              e.catalogVersion,
+	}
 }
 
 // ModelPricing contains pricing information for a specific model.
@@ -200,6 +268,70 @@ type ModelPricing struct {
 
 	// Currency is the currency code (always "USD" for MVP).
 	Currency string
+
+	// BatchDiscount is the discount of requests billed at the batch rate,
+	// as a fraction of the prices.
+	BatchDiscount float64
+
+	// LongContext contains the prices of requests with long prompts.
+	LongContext []config.LongContextPricingConfig
+
+	// CatalogVersion is the version of the pricing catalog the pricing
+	// comes from, or empty if it is configured.
+	CatalogVersion string
+}
+
+// Rates are the prices per 1000 tokens in USD applying to a request.
+type Rates struct {
+	// Prompt is the cost per 1000 prompt tokens.
+	Prompt float64
+
+	// Completion is the cost per 1000 completion tokens.
+	Completion float64
+
+	// CachedPrompt is the cost per 1000 cached prompt tokens.
+	CachedPrompt float64
+
+	// Tier is the pricing tier of the prices: "standard", "long_context",
+	// "batch" or "batch_long_context".
+	Tier string
+}
+
+// Rates returns the prices applying to a request with promptTokens prompt
+// tokens, billed at the batch rate if batch is set. The long context tier
+// with the highest threshold below promptTokens replaces the standard
+// prices, and the batch discount applies on top of them.
+func (p *ModelPricing) Rates(promptTokens int, batch bool) Rates {
+	rates := Rates{
+		Prompt:       p.PromptCostPer1KTokens,
+		Completion:   p.CompletionCostPer1KTokens,
+		CachedPrompt: p.CachedPromptCostPer1KTokens,
+		Tier:         "standard",
+	}
+
+	threshold := 0
+	for _, tier := range p.LongContext {
+		if promptTokens > tier.AboveTokens && tier.AboveTokens >= threshold {
+			threshold = tier.AboveTokens
+			rates.Prompt = tier.Prompt
+			rates.Completion = tier.Completion
+			rates.CachedPrompt = tier.CachedPrompt
+			rates.Tier = "long_context"
+		}
+	}
+
+	if batch && p.BatchDiscount > 0 {
+		factor := 1 - p.BatchDiscount
+		rates.Prompt *= factor
+		rates.Completion *= factor
+		rates.CachedPrompt *= factor
+		if rates.Tier == "standard" {
+			rates.Tier = "batch"
+		} else {
+			rates.Tier = "batch_long_context"
+		}
+	}
+	return rates
 }
 
 // calculateTokenCost calculates the cost for a given number of tokens.
@@ -1,6 +1,7 @@
 package costs
 
 import (
+	"math"
 	"testing"
 
 	"mercator-hq/jupiter/pkg/config"
@@ -438,6 +439,88 @@ func TestCalculator_UpdatePricing(t *testing.T) {
 	}
 }
 
+func TestCalculator_PricingTiers(t *testing.T) {
+	cfg := &config.CostsConfig{
+		Pricing: map[string]map[string]config.ModelPricingConfig{
+			"google": {
+				"gemini-1.5-pro": {
+					Prompt:        1.0,
+					Completion:    2.0,
+					CachedPrompt:  0.25,
+					BatchDiscount: 0.5,
+					LongContext: []config.LongContextPricingConfig{
+						{AboveTokens: 1000, Prompt: 2.0, Completion: 4.0, CachedPrompt: 0.5},
+					},
+				},
+			},
+		},
+	}
+	calculator := NewCalculator(cfg)
+
+	tests := []struct {
+		name     string
+		usage    *TokenUsage
+		wantCost float64
+		wantTier string
+	}{
+		{
+			name:     "standard",
+			usage:    &TokenUsage{PromptTokens: 1000, CompletionTokens: 1000},
+			wantCost: 3.0,
+			wantTier: "standard",
+		},
+		{
+			name:     "cached prompt",
+			usage:    &TokenUsage{PromptTokens: 1000, CompletionTokens: 1000, CachedTokens: 800},
+			wantCost: 0.2 + 0.2 + 2.0,
+			wantTier: "standard",
+		},
+		{
+			name:     "long context",
+			usage:    &TokenUsage{PromptTokens: 2000, CompletionTokens: 1000},
+			wantCost: 4.0 + 4.0,
+			wantTier: "long_context",
+		},
+		{
+			name:     "batch",
+			usage:    &TokenUsage{PromptTokens: 1000, CompletionTokens: 1000, Batch: true},
+			wantCost: 1.5,
+			wantTier: "batch",
+		},
+		{
+			name:     "batch long context with cached prompt",
+			usage:    &TokenUsage{PromptTokens: 2000, CompletionTokens: 1000, CachedTokens: 1000, Batch: true},
+			wantCost: 1.0 + 0.25 + 2.0,
+			wantTier: "batch_long_context",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cost, err := calculator.CalculateResponseCost(tt.usage, "gemini-1.5-pro-002", "google")
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if math.Abs(cost.TotalCost-tt.wantCost) > 1e-9 {
+				t.Errorf("expected total cost $%.4f, got $%.4f", tt.wantCost, cost.TotalCost)
+			}
+			if cost.PricingTier != tt.wantTier {
+				t.Errorf("expected pricing tier %q, got %q", tt.wantTier, cost.PricingTier)
+			}
+		})
+	}
+
+	// Request estimates are priced by the estimated prompt length
+	estimate := &tokens.Estimate{PromptTokens: 4000, EstimatedCompletionTokens: 500}
+	cost, err := calculator.CalculateRequestCost(estimate, "gemini-1.5-pro", "google")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if cost.PricingTier != "long_context" || math.Abs(cost.TotalCost-10.0) > 1e-9 {
+		t.Errorf("expected long_context estimate of $10.0000, got %s $%.4f", cost.PricingTier, cost.TotalCost)
+	}
+}
+
 func TestCalculateTokenCost(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -0,0 +1,73 @@
+package costs
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+
+	"mercator-hq/jupiter/pkg/config"
+	"mercator-hq/jupiter/pkg/processing/feed"
+)
+
+// Catalog is a versioned document of model prices by provider, in the same
+// layout as the pricing configuration. It is YAML or JSON.
+type Catalog struct {
+	// Version identifies the catalog, such as its publication date.
+	Version string `yaml:"version"`
+
+	// Pricing contains model pricing by provider and model.
+	Pricing map[string]map[string]config.ModelPricingConfig `yaml:"pricing"`
+}
+
+// ParseCatalog parses a pricing catalog. It returns an error if the catalog
+// has no version or prices, or invalid prices.
+func ParseCatalog(data []byte) (*Catalog, error) {
+	var catalog Catalog
+	if err := yaml.Unmarshal(data, &catalog); err != nil {
+		return nil, fmt.Errorf("failed to parse pricing catalog: %w", err)
+	}
+	if catalog.Version == "" {
+		return nil, fmt.Errorf("pricing catalog has no version")
+	}
+	if len(catalog.Pricing) == 0 {
+		return nil, fmt.Errorf("pricing catalog %s has no prices", catalog.Version)
+	}
+	if err := config.ValidatePricing(catalog.Pricing); err != nil {
+		return nil, fmt.Errorf("invalid pricing catalog %s: %w", catalog.Version, err)
+	}
+	return &catalog, nil
+}
+
+// CatalogUpdater keeps the pricing catalog of a Calculator up to date,
+// fetching it periodically from a URL or file. A catalog that cannot be
+// fetched or fails signature verification is ignored, keeping the last good
+// catalog, which is saved to the cache path if one is configured.
+type CatalogUpdater struct {
+	*feed.Updater
+}
+
+// NewCatalogUpdater creates an updater of the pricing catalog of
+// calculator. It returns an error if the public key cannot be loaded.
+func NewCatalogUpdater(calculator *Calculator, cfg *config.PricingCatalogConfig) (*CatalogUpdater, error) {
+	updater, err := feed.New(feed.Config{
+		Name:            "pricing catalog",
+		URL:             cfg.URL,
+		File:            cfg.File,
+		PublicKey:       cfg.PublicKey,
+		SignatureURL:    cfg.SignatureURL,
+		RefreshInterval: cfg.RefreshInterval,
+		Timeout:         cfg.Timeout,
+		CachePath:       cfg.CachePath,
+	}, func(data []byte) (string, error) {
+		catalog, err := ParseCatalog(data)
+		if err != nil {
+			return "", err
+		}
+		calculator.SetCatalog(catalog)
+		return catalog.Version, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &CatalogUpdater{Updater: updater}, nil
+}
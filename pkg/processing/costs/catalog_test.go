@@ -0,0 +1,159 @@
+package costs
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"mercator-hq/jupiter/pkg/config"
+)
+
+const testCatalog = `version: "2026-10-01"
+pricing:
+  openai:
+    gpt-4o:
+      prompt: 0.0025
+      completion: 0.01
+  default:
+    default:
+      prompt: 0.5
+      completion: 0.5
+`
+
+func TestCalculator_SetCatalog(t *testing.T) {
+	calculator := NewCalculator(&config.CostsConfig{
+		Pricing: map[string]map[string]config.ModelPricingConfig{
+			"default": {"default": {Prompt: 0.001, Completion: 0.002}},
+		},
+	})
+	catalog, err := ParseCatalog([]byte(testCatalog))
+	if err != nil {
+		t.Fatalf("ParseCatalog() error = %v", err)
+	}
+	calculator.SetCatalog(catalog)
+
+	if got := calculator.CatalogVersion(); got != "2026-10-01" {
+		t.Errorf("CatalogVersion() = %q, want %q", got, "2026-10-01")
+	}
+
+	pricing, err := calculator.GetModelPricing("gpt-4o-2024-08-06", "openai")
+	if err != nil {
+		t.Fatalf("GetModelPricing() error = %v", err)
+	}
+	if pricing.PromptCostPer1KTokens != 0.0025 || pricing.CatalogVersion != "2026-10-01" {
+		t.Errorf("catalog pricing = %+v, want prompt 0.0025 from version 2026-10-01", pricing)
+	}
+
+	// Configured prices override the catalog
+	pricing, err = calculator.GetModelPricing("unknown", "unknown")
+	if err != nil {
+		t.Fatalf("GetModelPricing() error = %v", err)
+	}
+	if pricing.PromptCostPer1KTokens != 0.001 || pricing.CatalogVersion != "" {
+		t.Errorf("default pricing = %+v, want configured prompt 0.001", pricing)
+	}
+
+	// The catalog survives a configuration reload
+	calculator.UpdatePricing(&config.CostsConfig{})
+	if _, err := calculator.GetModelPricing("gpt-4o", "openai"); err != nil {
+		t.Errorf("GetModelPricing() after UpdatePricing() error = %v", err)
+	}
+}
+
+func TestParseCatalog_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"no version":     "pricing:\n  openai:\n    gpt-4o: {prompt: 1, completion: 1}\n",
+		"no prices":      "version: v1\n",
+		"negative price": "version: v1\npricing:\n  openai:\n    gpt-4o: {prompt: -1, completion: 1}\n",
+		"malformed":      "version: [",
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseCatalog([]byte(data)); err == nil {
+				t.Error("ParseCatalog() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestCatalogUpdater_Update(t *testing.T) {
+	dir := t.TempDir()
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(dir, "catalog.pub")
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKey})
+	if err := os.WriteFile(keyPath, keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	catalog := testCatalog
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, []byte(catalog)))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/catalog.yaml":
+			_, _ = w.Write([]byte(catalog))
+		case "/catalog.yaml.sig":
+			_, _ = w.Write([]byte(signature))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	cfg := &config.PricingCatalogConfig{
+		Enabled:      true,
+		URL:          server.URL + "/catalog.yaml",
+		SignatureURL: server.URL + "/catalog.yaml.sig",
+		PublicKey:    keyPath,
+		CachePath:    filepath.Join(dir, "cache", "catalog.yaml"),
+	}
+	calculator := NewCalculator(&config.CostsConfig{})
+	updater, err := NewCatalogUpdater(calculator, cfg)
+	if err != nil {
+		t.Fatalf("NewCatalogUpdater() error = %v", err)
+	}
+
+	if err := updater.Update(context.Background()); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if got := calculator.CatalogVersion(); got != "2026-10-01" {
+		t.Fatalf("CatalogVersion() = %q, want %q", got, "2026-10-01")
+	}
+
+	// A catalog with an invalid signature is rejected, keeping the last
+	// good catalog
+	catalog = `version: "2026-11-01"
+pricing:
+  openai:
+    gpt-4o: {prompt: 0, completion: 0}
+`
+	if err := updater.Update(context.Background()); err == nil {
+		t.Error("Update() with invalid signature error = nil, want error")
+	}
+	if got := calculator.CatalogVersion(); got != "2026-10-01" {
+		t.Errorf("CatalogVersion() after rejected update = %q, want %q", got, "2026-10-01")
+	}
+
+	// The last good catalog is loaded from the cache when the catalog
+	// cannot be fetched
+	server.Close()
+	calculator = NewCalculator(&config.CostsConfig{})
+	updater, err = NewCatalogUpdater(calculator, cfg)
+	if err != nil {
+		t.Fatalf("NewCatalogUpdater() error = %v", err)
+	}
+	updater.Start(context.Background())
+	defer updater.Stop()
+	if got := calculator.CatalogVersion(); got != "2026-10-01" {
+		t.Errorf("CatalogVersion() from cache = %q, want %q", got, "2026-10-01")
+	}
+}
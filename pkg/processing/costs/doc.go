@@ -15,6 +15,24 @@
 //   - Input (prompt) tokens: Typically lower cost
 //   - Output (completion) tokens: Typically 2-3x input cost
 //   - Cached tokens: Discounted rate (where supported)
+//   - Long context: Higher rates for all tokens of requests whose prompt
+//     exceeds a threshold, such as 128K tokens
+//   - Batch: A discount on all rates for requests billed at the batch rate,
+//     such as Anthropic's batch and OpenAI's flex service tiers
+//
+// The pricing tier of a cost estimate reports which rates apply: standard,
+// long_context, batch or batch_long_context:
+//
+//	gemini-1.5-pro:
+//	  prompt: 0.00125
+//	  completion: 0.005
+//	  cached_prompt: 0.0003125
+//	  batch_discount: 0.5
+//	  long_context:
+//	    - above_tokens: 128000
+//	      prompt: 0.0025
+//	      completion: 0.01
+//	      cached_prompt: 0.000625
 //
 // # Usage
 //
@@ -46,4 +64,31 @@
 //
 // Pricing can be updated dynamically by reloading configuration. The calculator
 // uses read-write locks for thread-safe access to pricing data.
+//
+// # Pricing Catalog
+//
+// Model prices can also come from a pricing catalog, a versioned YAML or JSON
+// document in the layout of the pricing configuration:
+//
+//	version: "2026-10-01"
+//	pricing:
+//	  openai:
+//	    gpt-4o:
+//	      prompt: 0.0025
+//	      completion: 0.01
+//
+// A CatalogUpdater, built on the feed package, fetches the catalog from an
+// HTTPS URL, or reads a bundled file, every refresh interval. Remote catalogs are verified with a detached
+// Ed25519 signature, base64-encoded, served at the signature URL. A catalog
+// that cannot be fetched or fails verification is ignored, keeping the last
+// good catalog, which is saved to the cache path and loaded from it at
+// startup. Configured prices override those of the catalog, and cost
+// estimates report the catalog version their prices come from:
+//
+//	updater, err := costs.NewCatalogUpdater(calculator, &cfg.Processing.Costs.Catalog)
+//	if err != nil {
+//		return err
+//	}
+//	updater.Start(ctx)
+//	defer updater.Stop()
 package costs
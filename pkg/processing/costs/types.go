@@ -18,11 +18,16 @@ type CostEstimate struct {
 	// Provider is the provider name (openai, anthropic, etc.).
 	Provider string
 
-	// PricingTier identifies the pricing tier used.
+	// PricingTier identifies the pricing tier used: "standard",
+	// "long_context", "batch" or "batch_long_context".
 	PricingTier string
 
 	// Currency is the currency code (always "USD" for MVP).
 	Currency string
+
+	// PricingVersion is the version of the pricing catalog the prices come
+	// from, or empty if they are configured.
+	PricingVersion string
 }
 
 // TokenUsage contains actual token counts from the provider response.
@@ -39,4 +44,7 @@ type TokenUsage struct {
 
 	// CachedTokens is the number of cached tokens (if provider supports caching).
 	CachedTokens int
+
+	// Batch reports whether the request was billed at the batch rate.
+	Batch bool
 }
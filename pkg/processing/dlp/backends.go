@@ -0,0 +1,164 @@
+package dlp
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+)
+
+// presidioBackend analyzes text with a Presidio analyzer.
+type presidioBackend struct {
+	client   *http.Client
+	url      string
+	apiKey   string
+	language string
+}
+
+// presidioResult is a Presidio analyzer finding. Presidio reports
+// character offsets.
+type presidioResult struct {
+	EntityType string  `json:"entity_type"`
+	Start      int     `json:"start"`
+	End        int     `json:"end"`
+	Score      float64 `json:"score"`
+}
+
+func (b *presidioBackend) analyze(ctx context.Context, text string) ([]finding, error) {
+	var results []presidioResult
+	body := map[string]string{"text": text, "language": b.language}
+	if err := postJSON(ctx, b.client, b.url+"/analyze", b.apiKey, body, &results); err != nil {
+		return nil, err
+	}
+
+	offsets := runeOffsets(text)
+	findings := make([]finding, 0, len(results))
+	for _, r := range results {
+		if r.Start < 0 || r.End > len(offsets)-1 || r.Start >= r.End {
+			continue
+		}
+		findings = append(findings, finding{
+			Type:  r.EntityType,
+			Start: offsets[r.Start],
+			End:   offsets[r.End],
+			Score: r.Score,
+		})
+	}
+	return findings, nil
+}
+
+// googleBackend analyzes text with Google Cloud DLP.
+type googleBackend struct {
+	client    *http.Client
+	url       string
+	apiKey    string
+	projectID string
+}
+
+// googleInspectResponse is a Google Cloud DLP content:inspect response.
+type googleInspectResponse struct {
+	Result struct {
+		Findings []struct {
+			InfoType struct {
+				Name string `json:"name"`
+			} `json:"infoType"`
+			Likelihood string `json:"likelihood"`
+			Location   struct {
+				ByteRange struct {
+					Start protoInt `json:"start"`
+					End   protoInt `json:"end"`
+				} `json:"byteRange"`
+			} `json:"location"`
+		} `json:"findings"`
+	} `json:"result"`
+}
+
+// likelihoodScores maps Google Cloud DLP likelihoods to scores.
+var likelihoodScores = map[string]float64{
+	"VERY_UNLIKELY": 0.1,
+	"UNLIKELY":      0.3,
+	"POSSIBLE":      0.5,
+	"LIKELY":        0.7,
+	"VERY_LIKELY":   0.9,
+}
+
+func (b *googleBackend) analyze(ctx context.Context, text string) ([]finding, error) {
+	var resp googleInspectResponse
+	body := map[string]any{
+		"item":          map[string]string{"value": text},
+		"inspectConfig": map[string]any{"includeQuote": false},
+	}
+	url := b.url + "/v2/projects/" + b.projectID + "/content:inspect"
+	if err := postJSON(ctx, b.client, url, b.apiKey, body, &resp); err != nil {
+		return nil, err
+	}
+
+	findings := make([]finding, 0, len(resp.Result.Findings))
+	for _, f := range resp.Result.Findings {
+		findings = append(findings, finding{
+			Type:  f.InfoType.Name,
+			Start: int(f.Location.ByteRange.Start),
+			End:   int(f.Location.ByteRange.End),
+			Score: likelihoodScores[f.Likelihood],
+		})
+	}
+	return findings, nil
+}
+
+// httpBackend analyzes text with a service implementing the generic HTTP
+// contract.
+type httpBackend struct {
+	client *http.Client
+	url    string
+	apiKey string
+}
+
+// httpResponse is the generic HTTP contract response.
+type httpResponse struct {
+	Findings []struct {
+		Type  string  `json:"type"`
+		Start int     `json:"start"`
+		End   int     `json:"end"`
+		Score float64 `json:"score"`
+	} `json:"findings"`
+}
+
+func (b *httpBackend) analyze(ctx context.Context, text string) ([]finding, error) {
+	var resp httpResponse
+	if err := postJSON(ctx, b.client, b.url, b.apiKey, map[string]string{"text": text}, &resp); err != nil {
+		return nil, err
+	}
+
+	findings := make([]finding, 0, len(resp.Findings))
+	for _, f := range resp.Findings {
+		findings = append(findings, finding(f))
+	}
+	return findings, nil
+}
+
+// protoInt is a 64-bit integer in proto3 JSON, which encodes it as a
+// string.
+type protoInt int64
+
+func (i *protoInt) UnmarshalJSON(data []byte) error {
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	v, err := strconv.ParseInt(n.String(), 10, 64)
+	if err != nil {
+		return err
+	}
+	*i = protoInt(v)
+	return nil
+}
+
+// runeOffsets returns the byte offset of each character of text, followed
+// by len(text), to convert character offsets to byte offsets.
+func runeOffsets(text string) []int {
+	offsets := make([]int, 0, len(text)+1)
+	for i := range text {
+		offsets = append(offsets, i)
+	}
+	return append(offsets, len(text))
+}
@@ -0,0 +1,205 @@
+package dlp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"mercator-hq/jupiter/pkg/config"
+	"mercator-hq/jupiter/pkg/processing/content"
+)
+
+// ErrCircuitOpen is returned while the circuit breaker is open after
+// repeated DLP service failures.
+var ErrCircuitOpen = errors.New("dlp: circuit breaker open")
+
+// finding is a PII finding of a DLP service, with byte offsets into the
+// analyzed text.
+type finding struct {
+	Type  string
+	Start int
+	End   int
+	Score float64
+}
+
+// backend analyzes text with a DLP service.
+type backend interface {
+	analyze(ctx context.Context, text string) ([]finding, error)
+}
+
+// Client detects PII with an external DLP service. It implements
+// content.ExternalDetector and is safe for concurrent use.
+type Client struct {
+	backend  backend
+	timeout  time.Duration
+	minScore float64
+
+	// mu protects the circuit breaker state
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	failures  int
+	openUntil time.Time
+	probing   bool
+}
+
+// NewClient creates a DLP client for the configured backend.
+func NewClient(cfg *config.DLPConfig) (*Client, error) {
+	httpClient := &http.Client{}
+
+	var b backend
+	switch cfg.Backend {
+	case "", "presidio":
+		b = &presidioBackend{client: httpClient, url: strings.TrimRight(cfg.URL, "/"), apiKey: cfg.APIKey, language: cfg.Language}
+	case "google":
+		baseURL := cfg.URL
+		if baseURL == "" {
+			baseURL = "https://dlp.googleapis.com"
+		}
+		b = &googleBackend{client: httpClient, url: strings.TrimRight(baseURL, "/"), apiKey: cfg.APIKey, projectID: cfg.ProjectID}
+	case "http":
+		b = &httpBackend{client: httpClient, url: cfg.URL, apiKey: cfg.APIKey}
+	default:
+		return nil, fmt.Errorf("unknown DLP backend %q", cfg.Backend)
+	}
+
+	return &Client{
+		backend:   b,
+		timeout:   cfg.Timeout,
+		minScore:  cfg.MinScore,
+		threshold: cfg.FailureThreshold,
+		cooldown:  cfg.Cooldown,
+	}, nil
+}
+
+// Detect returns the PII the DLP service finds in text, with findings below
+// the minimum score left out. It fails fast with ErrCircuitOpen while the
+// circuit breaker is open.
+func (c *Client) Detect(ctx context.Context, text string) ([]content.PIILocation, error) {
+	if !c.allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	findings, err := c.backend.analyze(ctx, text)
+	c.record(err)
+	if err != nil {
+		return nil, err
+	}
+
+	locations := make([]content.PIILocation, 0, len(findings))
+	for _, f := range findings {
+		if f.Score < c.minScore {
+			continue
+		}
+		locations = append(locations, content.PIILocation{
+			Type:       normalizeType(f.Type),
+			Start:      f.Start,
+			End:        f.End,
+			Confidence: f.Score,
+		})
+	}
+	return locations, nil
+}
+
+// allow reports whether a request may be sent. Once the cooldown of an
+// open breaker ends, a single probe request is allowed at a time.
+func (c *Client) allow() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.threshold <= 0 || c.failures < c.threshold {
+		return true
+	}
+	if time.Now().Before(c.openUntil) || c.probing {
+		return false
+	}
+	c.probing = true
+	return true
+}
+
+// record updates the circuit breaker with the result of a request.
+func (c *Client) record(err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.probing = false
+	if err == nil {
+		c.failures = 0
+		return
+	}
+
+	c.failures++
+	if c.threshold > 0 && c.failures >= c.threshold {
+		c.openUntil = time.Now().Add(c.cooldown)
+		slog.Warn("DLP circuit breaker open",
+			"consecutive_failures", c.failures,
+			"cooldown", c.cooldown,
+			"error", err,
+		)
+	}
+}
+
+// typeNames maps DLP entity types to the built-in PII types.
+var typeNames = map[string]string{
+	"EMAIL_ADDRESS":             "email",
+	"PHONE_NUMBER":              "phone",
+	"US_SSN":                    "ssn",
+	"US_SOCIAL_SECURITY_NUMBER": "ssn",
+	"CREDIT_CARD":               "credit_card",
+	"CREDIT_CARD_NUMBER":        "credit_card",
+	"IP_ADDRESS":                "ip_address",
+}
+
+// normalizeType returns the PII type of a DLP entity type: the built-in
+// type if there is one, and the lower-cased entity type otherwise.
+func normalizeType(entityType string) string {
+	if name, ok := typeNames[strings.ToUpper(entityType)]; ok {
+		return name
+	}
+	return strings.ToLower(entityType)
+}
+
+// postJSON POSTs body as JSON to url and decodes the JSON response into out.
+func postJSON(ctx context.Context, client *http.Client, url, apiKey string, body, out any) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode DLP request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to create DLP request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("DLP request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("DLP service returned status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode DLP response: %w", err)
+	}
+	return nil
+}
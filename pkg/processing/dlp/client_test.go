@@ -0,0 +1,158 @@
+package dlp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/config"
+)
+
+func TestClient_Backends(t *testing.T) {
+	text := "Café owner jane@example.com"
+
+	tests := []struct {
+		name     string
+		backend  string
+		path     string
+		response string
+	}{
+		{
+			name:     "presidio",
+			backend:  "presidio",
+			path:     "/analyze",
+			response: `[{"entity_type":"EMAIL_ADDRESS","start":11,"end":27,"score":0.95},{"entity_type":"PERSON","start":0,"end":4,"score":0.3}]`,
+		},
+		{
+			name:     "google",
+			backend:  "google",
+			path:     "/v2/projects/acme/content:inspect",
+			response: `{"result":{"findings":[{"infoType":{"name":"EMAIL_ADDRESS"},"likelihood":"VERY_LIKELY","location":{"byteRange":{"start":"12","end":"28"}}}]}}`,
+		},
+		{
+			name:     "http",
+			backend:  "http",
+			path:     "/scan",
+			response: `{"findings":[{"type":"email","start":12,"end":28,"score":0.9}]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != tt.path {
+					t.Errorf("unexpected path %s", r.URL.Path)
+				}
+				if r.Header.Get("Authorization") != "Bearer secret" {
+					t.Errorf("unexpected authorization %q", r.Header.Get("Authorization"))
+				}
+				var body map[string]any
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("failed to decode request: %v", err)
+				}
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			url := server.URL
+			if tt.backend == "http" {
+				url += "/scan"
+			}
+			client, err := NewClient(&config.DLPConfig{
+				Backend:   tt.backend,
+				URL:       url,
+				APIKey:    "secret",
+				ProjectID: "acme",
+				Language:  "en",
+				MinScore:  0.5,
+				Timeout:   time.Second,
+			})
+			if err != nil {
+				t.Fatalf("NewClient failed: %v", err)
+			}
+
+			locations, err := client.Detect(context.Background(), text)
+			if err != nil {
+				t.Fatalf("Detect failed: %v", err)
+			}
+			if len(locations) != 1 {
+				t.Fatalf("expected 1 finding above the minimum score, got %+v", locations)
+			}
+			loc := locations[0]
+			if loc.Type != "email" || text[loc.Start:loc.End] != "jane@example.com" {
+				t.Errorf("expected email at jane@example.com, got %+v (%q)", loc, text[loc.Start:loc.End])
+			}
+		})
+	}
+}
+
+func TestClient_CircuitBreaker(t *testing.T) {
+	var requests atomic.Int32
+	var healthy atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if !healthy.Load() {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"findings":[]}`))
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&config.DLPConfig{
+		Backend:          "http",
+		URL:              server.URL,
+		Timeout:          time.Second,
+		FailureThreshold: 2,
+		Cooldown:         50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.Detect(ctx, "text"); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("expected service error, got %v", err)
+		}
+	}
+	if _, err := client.Detect(ctx, "text"); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("expected open circuit, got %v", err)
+	}
+	if requests.Load() != 2 {
+		t.Errorf("expected 2 requests while the circuit is open, got %d", requests.Load())
+	}
+
+	// After the cooldown, a probe closes the circuit
+	healthy.Store(true)
+	time.Sleep(60 * time.Millisecond)
+	if _, err := client.Detect(ctx, "text"); err != nil {
+		t.Fatalf("expected probe to succeed, got %v", err)
+	}
+	if _, err := client.Detect(ctx, "text"); err != nil {
+		t.Errorf("expected closed circuit, got %v", err)
+	}
+}
+
+func TestClient_Timeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewClient(&config.DLPConfig{Backend: "http", URL: server.URL, Timeout: 20 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+	if _, err := client.Detect(context.Background(), "text"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got %v", err)
+	}
+}
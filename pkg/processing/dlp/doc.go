@@ -0,0 +1,38 @@
+// Package dlp integrates external data loss prevention services into
+// content analysis.
+//
+// A Client sends content to a DLP service and reports its findings as PII
+// locations, which the content analyzer merges into its PII detection
+// results alongside the built-in patterns. Supported services:
+//
+//   - Microsoft Presidio (the analyzer's /analyze endpoint)
+//   - Google Cloud DLP (content:inspect)
+//   - Any service implementing the generic HTTP contract
+//
+// # Generic HTTP Contract
+//
+// The http backend POSTs the content as JSON:
+//
+//	{"text": "Call me at 555-123-4567"}
+//
+// and expects the findings, with byte offsets into the text:
+//
+//	{"findings": [{"type": "phone", "start": 11, "end": 23, "score": 0.9}]}
+//
+// # Failure Handling
+//
+// Each request is bounded by the configured timeout. After the configured
+// number of consecutive failures, the circuit breaker opens and requests
+// fail fast with ErrCircuitOpen until the cooldown ends; then a single
+// request probes the service and closes the breaker if it succeeds. Failed
+// requests never fail content analysis: the analyzer falls back to its
+// built-in patterns.
+//
+// # Usage
+//
+//	client, err := dlp.NewClient(&cfg.Processing.Content.DLP)
+//	if err != nil {
+//		return err
+//	}
+//	analyzer.SetExternalDetector(client)
+package dlp
@@ -12,7 +12,10 @@
 //   - tokens: Token estimation and counting using model-specific algorithms
 //   - costs: Cost calculation based on provider-specific pricing
 //   - content: Content analysis including PII detection, sensitive content, prompt injection
+//   - dlp: External DLP services (Presidio, Google Cloud DLP) merged into PII detection
+//   - embeddings: Local and provider embeddings for topic similarity checks
 //   - conversation: Conversation history parsing and context window analysis
+//   - risk: Risk scores from configurable, hot-reloadable weighted risk signals
 //
 // # Basic Usage
 //
@@ -47,6 +50,24 @@
 //		log.Warn("low token efficiency", "ratio", enriched.TokenEfficiency)
 //	}
 //
+// # Custom Analyzers
+//
+// Custom analyzers implementing Analyzer contribute fields to enriched
+// requests and responses, which policies reference as
+// request.analyzers.<name>.<field> and response.analyzers.<name>.<field>.
+// Analyzers configured in processing.analyzers are either external HTTP
+// analyzers or Go analyzers whose type is registered with
+// RegisterAnalyzerType:
+//
+//	func init() {
+//		processing.RegisterAnalyzerType("keyword", newKeywordAnalyzer)
+//	}
+//
+// Analyzers can also be added to a processor with AddAnalyzer. Their fields
+// are added to the MPL data model, so that policies referencing them
+// validate. Analyzers run concurrently after the built-in analysis; an
+// analyzer that fails or times out contributes no fields.
+//
 // # Performance
 //
 // All processing operations are designed to complete in <10ms per request:
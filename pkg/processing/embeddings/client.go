@@ -0,0 +1,105 @@
+package embeddings
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+
+	"mercator-hq/jupiter/pkg/config"
+	"mercator-hq/jupiter/pkg/processing/content"
+)
+
+// Client embeds text with an OpenAI-compatible embeddings endpoint. It is
+// safe for concurrent use.
+type Client struct {
+	client *http.Client
+	url    string
+	apiKey string
+	model  string
+}
+
+// NewClient creates an embeddings client for the configured provider.
+func NewClient(cfg *config.TopicsConfig) *Client {
+	return &Client{
+		client: &http.Client{},
+		url:    strings.TrimRight(cfg.URL, "/") + "/embeddings",
+		apiKey: cfg.APIKey,
+		model:  cfg.Model,
+	}
+}
+
+// New creates the embedder configured by cfg.
+func New(cfg *config.TopicsConfig) (content.Embedder, error) {
+	switch cfg.Embedder {
+	case "", "local":
+		return NewLocal(), nil
+	case "provider":
+		return NewClient(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown embedder %q", cfg.Embedder)
+	}
+}
+
+// embeddingsRequest is an OpenAI embeddings request.
+type embeddingsRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// embeddingsResponse is an OpenAI embeddings response.
+type embeddingsResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float64 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Embed returns the provider's embedding of each of texts.
+func (c *Client) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	data, err := json.Marshal(embeddingsRequest{Model: c.model, Input: texts})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode embeddings request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create embeddings request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("embeddings request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, fmt.Errorf("embeddings provider returned status %d: %s", resp.StatusCode, bytes.TrimSpace(msg))
+	}
+
+	var result embeddingsResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode embeddings response: %w", err)
+	}
+	if len(result.Data) != len(texts) {
+		return nil, fmt.Errorf("embeddings provider returned %d embeddings for %d texts", len(result.Data), len(texts))
+	}
+
+	sort.Slice(result.Data, func(i, j int) bool {
+		return result.Data[i].Index < result.Data[j].Index
+	})
+	embeddings := make([][]float64, len(result.Data))
+	for i, d := range result.Data {
+		embeddings[i] = d.Embedding
+	}
+	return embeddings, nil
+}
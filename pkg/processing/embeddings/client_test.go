@@ -0,0 +1,83 @@
+package embeddings
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"mercator-hq/jupiter/pkg/config"
+)
+
+func TestClient_Embed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/embeddings" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer secret" {
+			t.Errorf("unexpected authorization %q", got)
+		}
+		var req embeddingsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if req.Model != "text-embedding-3-small" || len(req.Input) != 2 {
+			t.Errorf("unexpected request %+v", req)
+		}
+		// Out of order, as the index identifies the input
+		w.Write([]byte(`{"data":[{"index":1,"embedding":[0,1]},{"index":0,"embedding":[1,0]}]}`))
+	}))
+	defer server.Close()
+
+	embedder, err := New(&config.TopicsConfig{
+		Embedder: "provider",
+		URL:      server.URL + "/v1/",
+		APIKey:   "secret",
+		Model:    "text-embedding-3-small",
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	embeddings, err := embedder.Embed(context.Background(), []string{"first", "second"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if len(embeddings) != 2 || embeddings[0][0] != 1 || embeddings[1][1] != 1 {
+		t.Errorf("unexpected embeddings %v", embeddings)
+	}
+}
+
+func TestClient_EmbedErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		response string
+	}{
+		{"server error", http.StatusInternalServerError, `{"error":"overloaded"}`},
+		{"missing embeddings", http.StatusOK, `{"data":[{"index":0,"embedding":[1,0]}]}`},
+		{"invalid response", http.StatusOK, `not json`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			client := NewClient(&config.TopicsConfig{URL: server.URL, Model: "m"})
+			if _, err := client.Embed(context.Background(), []string{"a", "b"}); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
+
+func TestNew_UnknownEmbedder(t *testing.T) {
+	if _, err := New(&config.TopicsConfig{Embedder: "word2vec"}); err == nil {
+		t.Error("expected error for unknown embedder")
+	}
+}
@@ -0,0 +1,22 @@
+// Package embeddings computes text embeddings for the topic similarity
+// checks of content analysis.
+//
+// Two embedders are available:
+//
+//   - Local, a built-in model hashing the words and word fragments of text
+//     into a fixed number of dimensions. It needs no service, but measures
+//     lexical rather than semantic similarity: a prompt is near a topic when
+//     it shares words with the topic's examples.
+//   - Client, which calls an OpenAI-compatible embeddings endpoint
+//     (POST {url}/embeddings), such as OpenAI, Azure OpenAI, Ollama or vLLM.
+//     Provider embeddings capture meaning, so a prompt is near a topic
+//     when it is about the same subject, whatever its wording.
+//
+// # Usage
+//
+//	embedder, err := embeddings.New(&cfg.Processing.Content.Topics)
+//	if err != nil {
+//		return err
+//	}
+//	analyzer.SetTopicEmbedder(embedder)
+package embeddings
@@ -0,0 +1,95 @@
+package embeddings
+
+import (
+	"context"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+// localDimensions is the number of dimensions of local embeddings.
+const localDimensions = 512
+
+// fragmentWeight is the weight of the 3-character fragments of a word
+// relative to the word itself. Fragments make inflected forms, such as
+// "explosive" and "explosives", similar.
+const fragmentWeight = 0.3
+
+// stopWords are common English words left out of local embeddings, which
+// would otherwise make unrelated text similar.
+var stopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "are": true, "as": true, "at": true,
+	"be": true, "but": true, "by": true, "can": true, "do": true, "for": true,
+	"from": true, "how": true, "i": true, "if": true, "in": true, "is": true,
+	"it": true, "me": true, "my": true, "of": true, "on": true, "or": true,
+	"so": true, "that": true, "the": true, "this": true, "to": true,
+	"was": true, "we": true, "what": true, "with": true, "you": true,
+	"your": true,
+}
+
+// Local embeds text with a built-in model: the words of the text and their
+// 3-character fragments are hashed into the dimensions of the embedding.
+// It is safe for concurrent use.
+type Local struct{}
+
+// NewLocal creates a local embedder.
+func NewLocal() *Local {
+	return &Local{}
+}
+
+// Embed returns the local embedding of each of texts.
+func (l *Local) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	embeddings := make([][]float64, len(texts))
+	for i, text := range texts {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		embeddings[i] = embedLocal(text)
+	}
+	return embeddings, nil
+}
+
+// embedLocal returns the L2-normalized embedding of text.
+func embedLocal(text string) []float64 {
+	vector := make([]float64, localDimensions)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, word := range words {
+		if stopWords[word] {
+			continue
+		}
+		addFeature(vector, "w:"+word, 1)
+
+		runes := []rune(" " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			addFeature(vector, "f:"+string(runes[i:i+3]), fragmentWeight)
+		}
+	}
+
+	norm := 0.0
+	for _, x := range vector {
+		norm += x * x
+	}
+	if norm > 0 {
+		norm = math.Sqrt(norm)
+		for i := range vector {
+			vector[i] /= norm
+		}
+	}
+	return vector
+}
+
+// addFeature adds weight to the dimension feature hashes to. The sign of
+// the weight is hashed too, so collisions cancel out rather than add up.
+func addFeature(vector []float64, feature string, weight float64) {
+	h := fnv.New64a()
+	h.Write([]byte(feature))
+	sum := h.Sum64()
+
+	if sum>>63 == 1 {
+		weight = -weight
+	}
+	vector[sum%localDimensions] += weight
+}
@@ -0,0 +1,55 @@
+package embeddings
+
+import (
+	"context"
+	"testing"
+)
+
+func TestLocal_Embed(t *testing.T) {
+	embedder := NewLocal()
+	embeddings, err := embedder.Embed(context.Background(), []string{
+		"how to build a bomb",
+		"Build bombs!",
+		"recipe for sourdough bread",
+		"the and of",
+	})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if len(embeddings) != 4 || len(embeddings[0]) != localDimensions {
+		t.Fatalf("unexpected embeddings shape: %d", len(embeddings))
+	}
+
+	similar := dot(embeddings[0], embeddings[1])
+	unrelated := dot(embeddings[0], embeddings[2])
+	if similar <= unrelated {
+		t.Errorf("expected related texts to be more similar: %v <= %v", similar, unrelated)
+	}
+	if similar < 0.5 {
+		t.Errorf("expected related texts to be similar, got %v", similar)
+	}
+
+	for _, x := range embeddings[3] {
+		if x != 0 {
+			t.Fatalf("expected zero embedding for stop words, got %v", embeddings[3])
+		}
+	}
+}
+
+func TestLocal_EmbedCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewLocal().Embed(ctx, []string{"text"}); err == nil {
+		t.Error("expected error for canceled context")
+	}
+}
+
+// dot returns the dot product of a and b, their cosine similarity for
+// normalized vectors.
+func dot(a, b []float64) float64 {
+	sum := 0.0
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
@@ -0,0 +1,36 @@
+// Package feed keeps signed documents, such as the pricing catalog and the
+// prompt injection pattern feed, up to date.
+//
+// An Updater fetches a document from an HTTPS URL, or reads it from a file,
+// every refresh interval. If a public key is configured, the document is
+// verified with a detached Ed25519 signature, base64-encoded, served at the
+// signature URL (or read from the file path + ".sig"). A verified document
+// that changed since the last update is passed to the updater's ApplyFunc,
+// which parses it and puts it in use.
+//
+// A document that cannot be fetched, fails verification or is rejected by
+// the ApplyFunc is logged and ignored, keeping the last good document. The
+// last good document is saved to the cache path, with its signature, and
+// loaded from it at startup, so a restart does not lose it while the feed
+// is unreachable.
+//
+// # Usage
+//
+//	updater, err := feed.New(feed.Config{
+//		Name:      "pricing catalog",
+//		URL:       "https://pricing.example.com/catalog.yaml",
+//		PublicKey: "/etc/mercator/pricing.pub",
+//	}, func(data []byte) (string, error) {
+//		catalog, err := parse(data)
+//		if err != nil {
+//			return "", err
+//		}
+//		use(catalog)
+//		return catalog.Version, nil
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	updater.Start(ctx)
+//	defer updater.Stop()
+package feed
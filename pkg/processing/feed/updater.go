@@ -0,0 +1,343 @@
+package feed
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"mercator-hq/jupiter/pkg/evidence/integrity"
+)
+
+// maxDocumentSize is the maximum size of a document or its signature.
+const maxDocumentSize = 10 << 20
+
+// Config configures an Updater.
+type Config struct {
+	// Name names the document in logs and errors, e.g. "pricing catalog".
+	Name string
+
+	// URL is the URL of the document. Either URL or File is required.
+	URL string
+
+	// File is the path to the document file.
+	File string
+
+	// PublicKey is the path to the PEM-encoded Ed25519 public key verifying
+	// the document signature. If empty, documents are not verified.
+	PublicKey string
+
+	// SignatureURL is the URL of the document signature. Defaults to URL +
+	// ".sig". The signature of a file is read from File + ".sig".
+	SignatureURL string
+
+	// RefreshInterval is how often the document is fetched again. If zero,
+	// the document is only fetched by Start and Update.
+	RefreshInterval time.Duration
+
+	// Timeout is the maximum duration of a fetch.
+	Timeout time.Duration
+
+	// CachePath is the path where the last good document is saved
+	// (optional).
+	CachePath string
+}
+
+// ApplyFunc parses a verified document and puts it in use, returning its
+// version. It returns an error if the document is invalid, in which case the
+// document in use is kept.
+type ApplyFunc func(data []byte) (version string, err error)
+
+// Updater keeps a signed document up to date. It is thread-safe.
+type Updater struct {
+	cfg    Config
+	apply  ApplyFunc
+	client *http.Client
+
+	// key verifies document signatures (optional)
+	key ed25519.PublicKey
+
+	// mu serializes updates and protects the applied document state
+	mu      sync.Mutex
+	version string
+	digest  [sha256.Size]byte
+
+	done chan struct{}
+}
+
+// New creates an updater that applies the documents of cfg with apply. It
+// returns an error if the public key cannot be loaded.
+func New(cfg Config, apply ApplyFunc) (*Updater, error) {
+	if cfg.SignatureURL == "" && cfg.URL != "" {
+		cfg.SignatureURL = cfg.URL + ".sig"
+	}
+
+	u := &Updater{
+		cfg:    cfg,
+		apply:  apply,
+		client: &http.Client{Timeout: cfg.Timeout},
+		done:   make(chan struct{}),
+	}
+	if cfg.PublicKey != "" {
+		key, err := integrity.LoadPublicKey(cfg.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s public key: %w", cfg.Name, err)
+		}
+		u.key = key
+	}
+	return u, nil
+}
+
+// Start applies the cached document, fetches the document and keeps
+// fetching it every refresh interval until ctx is done or Stop is called. A
+// document that cannot be fetched at startup is logged, leaving the cached
+// document, if any, in use.
+func (u *Updater) Start(ctx context.Context) {
+	if u.cfg.CachePath != "" {
+		if err := u.loadCache(); err != nil && !errors.Is(err, os.ErrNotExist) {
+			slog.Warn("failed to load cached "+u.cfg.Name,
+				"path", u.cfg.CachePath,
+				"error", err,
+			)
+		}
+	}
+
+	if err := u.Update(ctx); err != nil {
+		slog.Error("failed to update "+u.cfg.Name,
+			"version", u.Version(),
+			"error", err,
+		)
+	}
+
+	if u.cfg.RefreshInterval > 0 {
+		go u.refreshLoop(ctx)
+	}
+}
+
+// Stop stops updating the document.
+func (u *Updater) Stop() {
+	select {
+	case <-u.done:
+	default:
+		close(u.done)
+	}
+}
+
+// Version returns the version of the document in use, or an empty string
+// if no document was applied.
+func (u *Updater) Version() string {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return u.version
+}
+
+// Update fetches the document and, if it is verified and changed since the
+// last update, applies it and saves it to the cache. On error, the document
+// in use is kept.
+func (u *Updater) Update(ctx context.Context) error {
+	data, signature, err := u.fetch(ctx)
+	if err != nil {
+		return err
+	}
+	if err := u.verify(data, signature); err != nil {
+		return err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	digest := sha256.Sum256(data)
+	if digest == u.digest {
+		return nil
+	}
+	previous := u.version
+	if err := u.applyLocked(data); err != nil {
+		return err
+	}
+	slog.Info(u.cfg.Name+" updated",
+		"version", u.version,
+		"previous_version", previous,
+	)
+
+	if u.cfg.CachePath != "" {
+		if err := u.saveCache(data, signature); err != nil {
+			slog.Warn("failed to cache "+u.cfg.Name,
+				"path", u.cfg.CachePath,
+				"error", err,
+			)
+		}
+	}
+	return nil
+}
+
+// applyLocked applies a verified document. The caller must hold mu.
+func (u *Updater) applyLocked(data []byte) error {
+	version, err := u.apply(data)
+	if err != nil {
+		return fmt.Errorf("invalid %s: %w", u.cfg.Name, err)
+	}
+	u.version = version
+	u.digest = sha256.Sum256(data)
+	return nil
+}
+
+// refreshLoop updates the document every refresh interval.
+func (u *Updater) refreshLoop(ctx context.Context) {
+	ticker := time.NewTicker(u.cfg.RefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-u.done:
+			return
+		case <-ticker.C:
+			if err := u.Update(ctx); err != nil {
+				slog.Error("failed to update "+u.cfg.Name+", keeping last good version",
+					"version", u.Version(),
+					"error", err,
+				)
+			}
+		}
+	}
+}
+
+// fetch returns the document and, if a public key is configured, its
+// signature.
+func (u *Updater) fetch(ctx context.Context) (data, signature []byte, err error) {
+	if u.cfg.File != "" {
+		// #nosec G304 - The document path comes from configuration
+		if data, err = os.ReadFile(u.cfg.File); err != nil {
+			return nil, nil, fmt.Errorf("failed to read %s: %w", u.cfg.Name, err)
+		}
+		if u.key != nil {
+			// #nosec G304 - The signature path is derived from configuration
+			if signature, err = os.ReadFile(u.cfg.File + ".sig"); err != nil {
+				return nil, nil, fmt.Errorf("failed to read %s signature: %w", u.cfg.Name, err)
+			}
+		}
+		return data, signature, nil
+	}
+
+	if data, err = u.get(ctx, u.cfg.URL); err != nil {
+		return nil, nil, fmt.Errorf("failed to fetch %s: %w", u.cfg.Name, err)
+	}
+	if u.key != nil {
+		if signature, err = u.get(ctx, u.cfg.SignatureURL); err != nil {
+			return nil, nil, fmt.Errorf("failed to fetch %s signature: %w", u.cfg.Name, err)
+		}
+	}
+	return data, signature, nil
+}
+
+// get returns the body of a GET request to url.
+func (u *Updater) get(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxDocumentSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxDocumentSize {
+		return nil, fmt.Errorf("response exceeds %d bytes", maxDocumentSize)
+	}
+	return data, nil
+}
+
+// verify verifies the signature of the document, if a public key is
+// configured.
+func (u *Updater) verify(data, signature []byte) error {
+	if u.key == nil {
+		return nil
+	}
+	sig, err := base64.StdEncoding.DecodeString(string(bytes.TrimSpace(signature)))
+	if err != nil {
+		return fmt.Errorf("failed to decode %s signature: %w", u.cfg.Name, err)
+	}
+	if !ed25519.Verify(u.key, data, sig) {
+		return fmt.Errorf("%s signature is invalid", u.cfg.Name)
+	}
+	return nil
+}
+
+// loadCache verifies and applies the cached document.
+func (u *Updater) loadCache() error {
+	data, err := os.ReadFile(u.cfg.CachePath)
+	if err != nil {
+		return err
+	}
+	var signature []byte
+	if u.key != nil {
+		if signature, err = os.ReadFile(u.cfg.CachePath + ".sig"); err != nil {
+			return err
+		}
+	}
+	if err := u.verify(data, signature); err != nil {
+		return err
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if err := u.applyLocked(data); err != nil {
+		return err
+	}
+	slog.Info(u.cfg.Name+" loaded from cache",
+		"version", u.version,
+		"path", u.cfg.CachePath,
+	)
+	return nil
+}
+
+// saveCache saves the document and its signature to the cache path,
+// replacing the files atomically.
+func (u *Updater) saveCache(data, signature []byte) error {
+	if err := os.MkdirAll(filepath.Dir(u.cfg.CachePath), 0o750); err != nil {
+		return err
+	}
+	if signature != nil {
+		if err := writeFileAtomic(u.cfg.CachePath+".sig", signature); err != nil {
+			return err
+		}
+	}
+	return writeFileAtomic(u.cfg.CachePath, data)
+}
+
+// writeFileAtomic writes data to a temporary file and renames it to path.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
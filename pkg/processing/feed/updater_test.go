@@ -0,0 +1,145 @@
+package feed
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testFeed serves a signed document.
+type testFeed struct {
+	key      ed25519.PrivateKey
+	document string
+	signed   string
+}
+
+func (f *testFeed) publish(document string) {
+	f.document = document
+	f.signed = document
+}
+
+func (f *testFeed) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/feed.yaml":
+		_, _ = w.Write([]byte(f.document))
+	case "/feed.yaml.sig":
+		_, _ = w.Write([]byte(base64.StdEncoding.EncodeToString(ed25519.Sign(f.key, []byte(f.signed)))))
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func writePublicKey(t *testing.T, dir string, key ed25519.PublicKey) string {
+	t.Helper()
+	path := filepath.Join(dir, "feed.pub")
+	data := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: key})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestUpdater_Update(t *testing.T) {
+	dir := t.TempDir()
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := &testFeed{key: privateKey}
+	source.publish("v1")
+	server := httptest.NewServer(source)
+	defer server.Close()
+
+	var applied []string
+	apply := func(data []byte) (string, error) {
+		if strings.HasPrefix(string(data), "bad") {
+			return "", errors.New("malformed")
+		}
+		applied = append(applied, string(data))
+		return string(data), nil
+	}
+	cfg := Config{
+		Name:      "test feed",
+		URL:       server.URL + "/feed.yaml",
+		PublicKey: writePublicKey(t, dir, publicKey),
+		CachePath: filepath.Join(dir, "cache", "feed.yaml"),
+	}
+	updater, err := New(cfg, apply)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	if err := updater.Update(context.Background()); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if updater.Version() != "v1" {
+		t.Errorf("Version() = %q, want v1", updater.Version())
+	}
+
+	// An unchanged document is not applied again
+	if err := updater.Update(context.Background()); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if len(applied) != 1 {
+		t.Errorf("expected 1 applied document, got %v", applied)
+	}
+
+	// A document with an invalid signature is rejected
+	source.document = "v2"
+	if err := updater.Update(context.Background()); err == nil {
+		t.Error("Update() with invalid signature error = nil, want error")
+	}
+
+	// A document rejected by the apply function is ignored
+	source.publish("bad v3")
+	if err := updater.Update(context.Background()); err == nil {
+		t.Error("Update() with invalid document error = nil, want error")
+	}
+	if updater.Version() != "v1" {
+		t.Errorf("Version() after rejected updates = %q, want v1", updater.Version())
+	}
+
+	// The last good document is loaded from the cache when the feed is
+	// unreachable
+	server.Close()
+	applied = nil
+	updater, err = New(cfg, apply)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	updater.Start(context.Background())
+	defer updater.Stop()
+	if updater.Version() != "v1" || len(applied) != 1 {
+		t.Errorf("expected v1 applied from cache, got version %q, applied %v", updater.Version(), applied)
+	}
+}
+
+func TestUpdater_File(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "feed.yaml")
+	if err := os.WriteFile(path, []byte("v1"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	updater, err := New(Config{Name: "test feed", File: path}, func(data []byte) (string, error) {
+		return string(data), nil
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := updater.Update(context.Background()); err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if updater.Version() != "v1" {
+		t.Errorf("Version() = %q, want v1", updater.Version())
+	}
+}
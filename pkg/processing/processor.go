@@ -1,7 +1,9 @@
 package processing
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
@@ -9,6 +11,9 @@ import (
 	"mercator-hq/jupiter/pkg/processing/content"
 	"mercator-hq/jupiter/pkg/processing/conversation"
 	"mercator-hq/jupiter/pkg/processing/costs"
+	"mercator-hq/jupiter/pkg/processing/dlp"
+	"mercator-hq/jupiter/pkg/processing/embeddings"
+	"mercator-hq/jupiter/pkg/processing/risk"
 	"mercator-hq/jupiter/pkg/processing/tokens"
 	"mercator-hq/jupiter/pkg/providers"
 	"mercator-hq/jupiter/pkg/proxy"
@@ -22,16 +27,88 @@ type Processor struct {
 	costCalculator       *costs.Calculator
 	contentAnalyzer      *content.Analyzer
 	conversationAnalyzer *conversation.Analyzer
+	riskScorer           *risk.Scorer
+	analyzers            []*pluggedAnalyzer
 }
 
 // NewProcessor creates a new processor with the given configuration.
 func NewProcessor(cfg *config.ProcessingConfig) *Processor {
-	return &Processor{
-		tokenEstimator:       tokens.NewSimpleEstimator(&cfg.Tokens),
+	return NewProcessorWithEstimator(cfg, tokens.NewSimpleEstimator(&cfg.Tokens))
+}
+
+// NewProcessorWithEstimator creates a new processor that estimates tokens
+// with estimator, such as a tokens.AnthropicEstimator.
+func NewProcessorWithEstimator(cfg *config.ProcessingConfig, estimator tokens.Estimator) *Processor {
+	p := &Processor{
+		tokenEstimator:       estimator,
 		costCalculator:       costs.NewCalculator(&cfg.Costs),
 		contentAnalyzer:      content.NewAnalyzer(&cfg.Content),
 		conversationAnalyzer: conversation.NewAnalyzer(&cfg.Conversation),
+		riskScorer:           risk.NewScorer(&cfg.Risk),
+	}
+
+	// Merge the findings of an external DLP service into content analysis
+	if cfg.Content.DLP.Enabled {
+		client, err := dlp.NewClient(&cfg.Content.DLP)
+		if err != nil {
+			slog.Warn("DLP service disabled", "error", err)
+		} else {
+			p.contentAnalyzer.SetExternalDetector(client)
+		}
+	}
+
+	// Compare content with the banned and allowed topics
+	if cfg.Content.Topics.Enabled {
+		embedder, err := embeddings.New(&cfg.Content.Topics)
+		if err != nil {
+			slog.Warn("topic similarity disabled", "error", err)
+		} else {
+			p.contentAnalyzer.SetTopicEmbedder(embedder)
+		}
+	}
+
+	// Plug in the configured custom analyzers
+	for i := range cfg.Analyzers {
+		analyzerCfg := &cfg.Analyzers[i]
+		analyzer, err := NewAnalyzer(analyzerCfg)
+		if err != nil {
+			slog.Warn("analyzer disabled", "analyzer", analyzerCfg.Name, "error", err)
+			continue
+		}
+		p.AddAnalyzer(analyzer, analyzerCfg.Timeout)
 	}
+
+	return p
+}
+
+// SetInjectionClassifier sets the ML classifier that complements the prompt
+// injection patterns. It must be called before the processor is used.
+func (p *Processor) SetInjectionClassifier(classifier content.InjectionClassifier) {
+	p.contentAnalyzer.SetInjectionClassifier(classifier)
+}
+
+// SetEntityRecognizer sets the NER model that complements the entity
+// gazetteer. It must be called before the processor is used.
+func (p *Processor) SetEntityRecognizer(recognizer content.EntityRecognizer, opts content.EntityRecognizerOptions) {
+	p.contentAnalyzer.SetEntityRecognizer(recognizer, opts)
+}
+
+// RiskScorer returns the risk scorer, whose risk model can be reloaded
+// with a risk.FileWatcher.
+func (p *Processor) RiskScorer() *risk.Scorer {
+	return p.riskScorer
+}
+
+// ContentAnalyzer returns the content analyzer, whose injection patterns can
+// be kept up to date with a content.PatternFeedUpdater.
+func (p *Processor) ContentAnalyzer() *content.Analyzer {
+	return p.contentAnalyzer
+}
+
+// CostCalculator returns the cost calculator, whose pricing catalog can be
+// kept up to date with a costs.CatalogUpdater.
+func (p *Processor) CostCalculator() *costs.Calculator {
+	return p.costCalculator
 }
 
 // ProcessRequest enriches a request with all available metadata.
@@ -87,8 +164,11 @@ func (p *Processor) ProcessRequest(requestMeta *proxy.RequestMetadata, req *type
 	// Determine model family
 	enriched.ModelFamily = inferModelFamily(req.Model)
 
-	// Set pricing tier (default to standard for MVP)
+	// Set pricing tier, such as long_context for long prompts
 	enriched.PricingTier = "standard"
+	if enriched.CostEstimate != nil {
+		enriched.PricingTier = enriched.CostEstimate.PricingTier
+	}
 
 	// Estimate latency based on token count (rough estimate)
 	enriched.EstimatedLatency = estimateLatency(tokenEst.TotalTokens, req.Model)
@@ -96,14 +176,111 @@ func (p *Processor) ProcessRequest(requestMeta *proxy.RequestMetadata, req *type
 	// Calculate complexity score (1-10) based on various factors
 	enriched.ComplexityScore = calculateComplexityScore(req, tokenEst)
 
-	// Calculate risk score (1-10) based on content analysis
-	enriched.RiskScore = calculateRiskScore(enriched.ContentAnalysis)
+	// Calculate risk score (1-10) from the weighted risk signals
+	caller := requestMeta.UserID
+	if caller == "" {
+		caller = requestMeta.TeamID
+	}
+	enriched.RiskScore = p.riskScorer.Score(risk.Request{
+		Analysis:     enriched.ContentAnalysis,
+		PromptTokens: tokenEst.PromptTokens,
+		Model:        req.Model,
+		Caller:       caller,
+	})
+
+	// Run the custom analyzers on the enriched request
+	enriched.Analyzers = p.runAnalyzers("request", func(ctx context.Context, analyzer Analyzer) (AnalyzerFields, error) {
+		return analyzer.AnalyzeRequest(ctx, enriched)
+	})
 
 	enriched.ProcessingDuration = time.Since(startTime)
 
 	return enriched, nil
 }
 
+// EstimateCost estimates the tokens and cost of a request without analyzing
+// its content, for pre-flight estimates of requests that are not sent.
+func (p *Processor) EstimateCost(req *types.ChatCompletionRequest) (*TokenEstimate, *CostEstimate, error) {
+	tokenEst, err := p.tokenEstimator.EstimateRequest(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to estimate tokens: %w", err)
+	}
+
+	costEst, err := p.costCalculator.CalculateRequestCost(tokenEst, req.Model, inferProvider(req.Model))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to estimate cost: %w", err)
+	}
+
+	return &TokenEstimate{
+		PromptTokens:              tokenEst.PromptTokens,
+		EstimatedCompletionTokens: tokenEst.EstimatedCompletionTokens,
+		TotalTokens:               tokenEst.TotalTokens,
+		SystemPromptTokens:        tokenEst.SystemPromptTokens,
+		MessageTokens:             tokenEst.MessageTokens,
+		ToolTokens:                tokenEst.ToolTokens,
+		OverheadTokens:            tokenEst.OverheadTokens,
+		Model:                     tokenEst.Model,
+		Confidence:                tokenEst.Confidence,
+	}, costEst, nil
+}
+
+// RedactRequest returns a copy of req with the PII in its message content
+// replaced by typed placeholders such as [EMAIL_1], and the placeholders
+// used. The same value is replaced by the same placeholder in every message.
+// Pass the placeholders to RestoreResponse to restore the PII in the
+// provider's response.
+func (p *Processor) RedactRequest(req *types.ChatCompletionRequest) (*types.ChatCompletionRequest, *Placeholders) {
+	placeholders := content.NewPlaceholders()
+
+	redacted := *req
+	redacted.Messages = make([]types.Message, len(req.Messages))
+	for i, msg := range req.Messages {
+		msg.Content = p.redactMessageContent(msg.Content, placeholders)
+		redacted.Messages[i] = msg
+	}
+
+	return &redacted, placeholders
+}
+
+// redactMessageContent replaces the PII in string content and in the text
+// parts of multimodal content.
+func (p *Processor) redactMessageContent(msgContent interface{}, placeholders *Placeholders) interface{} {
+	switch c := msgContent.(type) {
+	case string:
+		return p.contentAnalyzer.RedactPlaceholders(c, placeholders)
+
+	case []interface{}:
+		parts := make([]interface{}, len(c))
+		for i, part := range c {
+			partMap, ok := part.(map[string]interface{})
+			text, isText := partMap["text"].(string)
+			if !ok || !isText || partMap["type"] != "text" {
+				parts[i] = part
+				continue
+			}
+			redactedPart := make(map[string]interface{}, len(partMap))
+			for k, v := range partMap {
+				redactedPart[k] = v
+			}
+			redactedPart["text"] = p.contentAnalyzer.RedactPlaceholders(text, placeholders)
+			parts[i] = redactedPart
+		}
+		return parts
+
+	default:
+		return msgContent
+	}
+}
+
+// RestoreResponse replaces the placeholders of a request redacted with
+// RedactRequest in the content of resp with the PII they stand for.
+func (p *Processor) RestoreResponse(resp *providers.CompletionResponse, placeholders *Placeholders) {
+	if resp == nil || placeholders == nil || placeholders.Len() == 0 {
+		return
+	}
+	resp.Content = placeholders.Restore(resp.Content)
+}
+
 // ProcessResponse enriches a response with all available metadata.
 // This includes actual token usage, actual costs, and response quality metrics.
 func (p *Processor) ProcessResponse(requestID string, responseMeta *proxy.ResponseMetadata, resp *providers.CompletionResponse) (*EnrichedResponse, error) {
@@ -119,6 +296,8 @@ func (p *Processor) ProcessResponse(requestID string, responseMeta *proxy.Respon
 		PromptTokens:     resp.Usage.PromptTokens,
 		CompletionTokens: resp.Usage.CompletionTokens,
 		TotalTokens:      resp.Usage.TotalTokens,
+		CachedTokens:     resp.Usage.CachedPromptTokens,
+		Batch:            resp.Usage.Batch,
 	}
 
 	// Calculate actual cost (use provider from response metadata if available)
@@ -128,9 +307,10 @@ func (p *Processor) ProcessResponse(requestID string, responseMeta *proxy.Respon
 		enriched.CostEstimate = costEst
 	}
 
-	// Analyze response content
-	if resp.Content != "" {
-		contentAnalysis, err := p.contentAnalyzer.AnalyzeText(resp.Content)
+	// Analyze what the model said, including tool call arguments, for PII
+	// leakage, secrets and toxicity
+	if responseText := combineResponseContent(resp); responseText != "" {
+		contentAnalysis, err := p.contentAnalyzer.AnalyzeResponse(responseText)
 		if err == nil {
 			enriched.ContentAnalysis = contentAnalysis
 		}
@@ -154,6 +334,11 @@ func (p *Processor) ProcessResponse(requestID string, responseMeta *proxy.Respon
 	// Calculate quality metrics (simplified for MVP)
 	enriched.QualityMetrics = calculateQualityMetrics(resp, enriched.ContentAnalysis)
 
+	// Run the custom analyzers on the enriched response
+	enriched.Analyzers = p.runAnalyzers("response", func(ctx context.Context, analyzer Analyzer) (AnalyzerFields, error) {
+		return analyzer.AnalyzeResponse(ctx, enriched)
+	})
+
 	enriched.ProcessingDuration = time.Since(startTime)
 
 	return enriched, nil
@@ -222,6 +407,22 @@ func combineMessageContent(messages []types.Message) string {
 	return strings.Join(parts, " ")
 }
 
+// combineResponseContent combines the content of a response with the
+// arguments of its tool calls into a single string for analysis.
+func combineResponseContent(resp *providers.CompletionResponse) string {
+	var parts []string
+	if resp.Content != "" {
+		parts = append(parts, resp.Content)
+	}
+	for _, call := range resp.ToolCalls {
+		if call.Function.Arguments != "" {
+			parts = append(parts, call.Function.Arguments)
+		}
+	}
+
+	return strings.Join(parts, " ")
+}
+
 // estimateLatency estimates response latency based on token count and model.
 // This is a very rough estimate for planning purposes.
 func estimateLatency(tokens int, model string) time.Duration {
@@ -275,52 +476,6 @@ func calculateComplexityScore(req *types.ChatCompletionRequest, tokenEst *tokens
 	return score
 }
 
-// calculateRiskScore calculates a risk score from 1-10 based on content analysis.
-func calculateRiskScore(analysis *ContentAnalysis) int {
-	if analysis == nil {
-		return 1
-	}
-
-	score := 1
-
-	// Factor in PII detection
-	if analysis.PIIDetection != nil && analysis.PIIDetection.HasPII {
-		score += 2
-		if analysis.PIIDetection.PIICount > 5 {
-			score += 2
-		}
-	}
-
-	// Factor in sensitive content
-	if analysis.SensitiveContent != nil && analysis.SensitiveContent.HasSensitiveContent {
-		switch analysis.SensitiveContent.Severity {
-		case "critical":
-			score += 5
-		case "high":
-			score += 3
-		case "medium":
-			score += 2
-		case "low":
-			score += 1
-		}
-	}
-
-	// Factor in prompt injection
-	if analysis.PromptInjection != nil && analysis.PromptInjection.HasPromptInjection {
-		score += 3
-		if analysis.PromptInjection.Confidence > 0.9 {
-			score += 2
-		}
-	}
-
-	// Cap at 10
-	if score > 10 {
-		score = 10
-	}
-
-	return score
-}
-
 // analyzeFinishReason analyzes the finish reason and provides actionable insights.
 func analyzeFinishReason(reason string) *FinishReasonAnalysis {
 	analysis := &FinishReasonAnalysis{
@@ -0,0 +1,35 @@
+// Package risk computes request risk scores from weighted risk signals.
+//
+// A Scorer rates each request from 1 to 10 as 1 plus the weighted sum of
+// its risk signals, each from 0.0 to 1.0:
+//
+//   - pii: PII detected (0.5), or more than 5 PII items (1.0)
+//   - injection: prompt injection detected (0.6), or detected with a
+//     confidence above 0.9 (1.0)
+//   - toxicity: sensitive content by severity, from 0.2 (low) to 1.0
+//     (critical)
+//   - secrets: credentials embedded in the prompt (1.0)
+//   - token_size: prompt tokens relative to large_prompt_tokens
+//   - model_sensitivity: the configured sensitivity of the model
+//   - caller_history: the average risk of the caller's previous requests
+//
+// The weights are defined in the processing.risk configuration. The default
+// weights score content analysis only, as the formula used before the risk
+// model was configurable.
+//
+// # Hot Reload
+//
+// A FileWatcher loads weights, model sensitivity and large_prompt_tokens
+// from processing.risk.file and reloads them when the file changes. A file
+// that fails to load or validate is logged and the previous model is kept:
+//
+//	weights:
+//	  pii: 3
+//	  injection: 6
+//	  toxicity: 4
+//	  secrets: 3
+//	  model_sensitivity: 2
+//	model_sensitivity:
+//	  gpt-4: 0.5
+//	  internal-finetune: 1.0
+package risk
@@ -0,0 +1,181 @@
+package risk
+
+import (
+	"container/list"
+	"math"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"mercator-hq/jupiter/pkg/config"
+	"mercator-hq/jupiter/pkg/processing/content"
+)
+
+// historyDecay is the weight of the latest request in a caller's average
+// risk, an exponential moving average.
+const historyDecay = 0.3
+
+// Request holds the inputs of a risk score.
+type Request struct {
+	// Analysis is the content analysis of the request, if any.
+	Analysis *content.ContentAnalysis
+
+	// PromptTokens is the estimated number of prompt tokens.
+	PromptTokens int
+
+	// Model is the requested model.
+	Model string
+
+	// Caller identifies the caller for the caller history signal, such as
+	// a user or team ID. Requests without a caller have no history.
+	Caller string
+}
+
+// Scorer computes risk scores. Its configuration can be replaced while it
+// is in use. It is safe for concurrent use.
+type Scorer struct {
+	config atomic.Pointer[config.RiskConfig]
+
+	// mu protects the caller history
+	mu      sync.Mutex
+	lru     *list.List
+	history map[string]*list.Element
+}
+
+// callerRisk is the average risk of a caller's requests.
+type callerRisk struct {
+	caller string
+	risk   float64
+}
+
+// NewScorer creates a risk scorer with the given configuration.
+func NewScorer(cfg *config.RiskConfig) *Scorer {
+	s := &Scorer{
+		lru:     list.New(),
+		history: make(map[string]*list.Element),
+	}
+	s.SetConfig(cfg)
+	return s
+}
+
+// Config returns the current risk configuration.
+func (s *Scorer) Config() config.RiskConfig {
+	return *s.config.Load()
+}
+
+// SetConfig replaces the risk configuration. Scores computed afterwards
+// use the new configuration; the caller history is kept.
+func (s *Scorer) SetConfig(cfg *config.RiskConfig) {
+	c := *cfg
+	config.ApplyRiskDefaults(&c)
+	s.config.Store(&c)
+}
+
+// Score returns the risk score of a request, from 1 to 10, and records
+// the request in the caller's history.
+func (s *Scorer) Score(req Request) int {
+	cfg := s.config.Load()
+	w := cfg.Weights
+
+	score := 1.0
+	score += w.PII * piiSignal(req.Analysis)
+	score += w.Injection * injectionSignal(req.Analysis)
+	score += w.Toxicity * toxicitySignal(req.Analysis)
+	score += w.Secrets * secretsSignal(req.Analysis)
+	if cfg.LargePromptTokens > 0 {
+		score += w.TokenSize * min(1, float64(req.PromptTokens)/float64(cfg.LargePromptTokens))
+	}
+	score += w.ModelSensitivity * modelSensitivity(cfg.ModelSensitivity, req.Model)
+
+	if req.Caller != "" {
+		// The caller's history is updated with the risk of this request
+		// alone, so that history does not compound
+		risk := (min(score, 10) - 1) / 9
+		score += w.CallerHistory * s.recordCaller(req.Caller, risk, cfg.HistorySize)
+	}
+
+	return int(math.Round(max(1, min(score, 10))))
+}
+
+// recordCaller returns the average risk of the caller's previous requests
+// and adds risk to it. Without previous requests, the average is 0.
+func (s *Scorer) recordCaller(caller string, risk float64, size int) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if elem, ok := s.history[caller]; ok {
+		entry := elem.Value.(*callerRisk)
+		previous := entry.risk
+		entry.risk = previous*(1-historyDecay) + risk*historyDecay
+		s.lru.MoveToFront(elem)
+		return previous
+	}
+
+	s.history[caller] = s.lru.PushFront(&callerRisk{caller: caller, risk: risk})
+	for s.lru.Len() > size {
+		oldest := s.lru.Back()
+		s.lru.Remove(oldest)
+		delete(s.history, oldest.Value.(*callerRisk).caller)
+	}
+	return 0
+}
+
+// piiSignal scores PII detection.
+func piiSignal(analysis *content.ContentAnalysis) float64 {
+	if analysis == nil || analysis.PIIDetection == nil || !analysis.PIIDetection.HasPII {
+		return 0
+	}
+	if analysis.PIIDetection.PIICount > 5 {
+		return 1
+	}
+	return 0.5
+}
+
+// injectionSignal scores prompt injection detection.
+func injectionSignal(analysis *content.ContentAnalysis) float64 {
+	if analysis == nil || analysis.PromptInjection == nil || !analysis.PromptInjection.HasPromptInjection {
+		return 0
+	}
+	if analysis.PromptInjection.Confidence > 0.9 {
+		return 1
+	}
+	return 0.6
+}
+
+// toxicitySignal scores sensitive content by severity.
+func toxicitySignal(analysis *content.ContentAnalysis) float64 {
+	if analysis == nil || analysis.SensitiveContent == nil || !analysis.SensitiveContent.HasSensitiveContent {
+		return 0
+	}
+	switch analysis.SensitiveContent.Severity {
+	case "critical":
+		return 1
+	case "high":
+		return 0.6
+	case "medium":
+		return 0.4
+	case "low":
+		return 0.2
+	}
+	return 0
+}
+
+// secretsSignal scores credentials embedded in the prompt.
+func secretsSignal(analysis *content.ContentAnalysis) float64 {
+	if analysis == nil || analysis.Secrets == nil || !analysis.Secrets.Detected {
+		return 0
+	}
+	return 1
+}
+
+// modelSensitivity returns the sensitivity of the longest model name
+// prefix matching model.
+func modelSensitivity(sensitivities map[string]float64, model string) float64 {
+	sensitivity, longest := 0.0, -1
+	for prefix, s := range sensitivities {
+		if strings.HasPrefix(model, prefix) && len(prefix) > longest {
+			sensitivity, longest = s, len(prefix)
+		}
+	}
+	return sensitivity
+}
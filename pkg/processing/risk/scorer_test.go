@@ -0,0 +1,132 @@
+package risk
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/config"
+	"mercator-hq/jupiter/pkg/processing/content"
+)
+
+func TestScorer_DefaultWeights(t *testing.T) {
+	scorer := NewScorer(&config.RiskConfig{})
+
+	tests := []struct {
+		name     string
+		analysis *content.ContentAnalysis
+		want     int
+	}{
+		{"no analysis", nil, 1},
+		{"clean content", &content.ContentAnalysis{}, 1},
+		{
+			name:     "pii",
+			analysis: &content.ContentAnalysis{PIIDetection: &content.PIIDetection{HasPII: true, PIICount: 1}},
+			want:     3,
+		},
+		{
+			name: "confident injection and critical toxicity",
+			analysis: &content.ContentAnalysis{
+				PromptInjection:  &content.PromptInjection{HasPromptInjection: true, Confidence: 0.95},
+				SensitiveContent: &content.SensitiveContent{HasSensitiveContent: true, Severity: "critical"},
+			},
+			want: 10,
+		},
+		{
+			name: "secrets and medium toxicity",
+			analysis: &content.ContentAnalysis{
+				Secrets:          &content.SecretDetection{Detected: true},
+				SensitiveContent: &content.SensitiveContent{HasSensitiveContent: true, Severity: "medium"},
+			},
+			want: 6,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := scorer.Score(Request{Analysis: tt.analysis, PromptTokens: 100000, Model: "gpt-4"}); got != tt.want {
+				t.Errorf("Expected risk score %d, got %d", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestScorer_ConfiguredSignals(t *testing.T) {
+	scorer := NewScorer(&config.RiskConfig{
+		Weights: config.RiskWeightsConfig{
+			TokenSize:        2,
+			ModelSensitivity: 4,
+			CallerHistory:    3,
+		},
+		ModelSensitivity:  map[string]float64{"gpt": 0.25, "gpt-4": 1.0},
+		LargePromptTokens: 1000,
+		HistorySize:       1,
+	})
+
+	// Content analysis has no weight
+	pii := &content.ContentAnalysis{PIIDetection: &content.PIIDetection{HasPII: true}}
+	if got := scorer.Score(Request{Analysis: pii, Model: "gpt-3.5-turbo"}); got != 2 {
+		t.Errorf("Expected gpt-3.5 sensitivity to score 2, got %d", got)
+	}
+
+	// The longest model prefix applies; token size is capped at 1.0
+	if got := scorer.Score(Request{Model: "gpt-4o", PromptTokens: 5000}); got != 7 {
+		t.Errorf("Expected gpt-4 sensitivity and large prompt to score 7, got %d", got)
+	}
+
+	// The risk of the caller's previous requests (4/9) adds to later requests
+	if got := scorer.Score(Request{Model: "gpt-4", Caller: "alice"}); got != 5 {
+		t.Errorf("Expected first request of alice to score 5, got %d", got)
+	}
+	if got := scorer.Score(Request{Model: "claude", Caller: "alice"}); got != 2 {
+		t.Errorf("Expected history of alice to score 2, got %d", got)
+	}
+
+	// Least recently seen callers are forgotten
+	scorer.Score(Request{Model: "claude", Caller: "bob"})
+	if got := scorer.Score(Request{Model: "claude", Caller: "alice"}); got != 1 {
+		t.Errorf("Expected forgotten history of alice to score 1, got %d", got)
+	}
+}
+
+func TestFileWatcher_Reload(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "risk.yaml")
+	writeFile := func(data string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeFile("weights:\n  model_sensitivity: 9\nmodel_sensitivity:\n  gpt-4: 1.0\n")
+
+	scorer := NewScorer(&config.RiskConfig{})
+	watcher := NewFileWatcher(path, scorer)
+	if err := watcher.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	defer watcher.Stop()
+
+	request := Request{Model: "gpt-4"}
+	if got := scorer.Score(request); got != 10 {
+		t.Fatalf("Expected loaded risk model to score 10, got %d", got)
+	}
+
+	// Invalid files keep the previous risk model
+	writeFile("weights:\n  pii: -1\n")
+	time.Sleep(100 * time.Millisecond)
+	if got := scorer.Score(request); got != 10 {
+		t.Errorf("Expected previous risk model to be kept, got %d", got)
+	}
+
+	writeFile("weights:\n  model_sensitivity: 3\nmodel_sensitivity:\n  gpt-4: 1.0\n")
+	deadline := time.Now().Add(2 * time.Second)
+	for scorer.Score(request) != 4 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected reloaded risk model to score 4, got %d", scorer.Score(request))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,139 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+	"gopkg.in/yaml.v3"
+
+	"mercator-hq/jupiter/pkg/config"
+)
+
+// riskFile is the content of a risk file.
+type riskFile struct {
+	Weights           config.RiskWeightsConfig `yaml:"weights"`
+	ModelSensitivity  map[string]float64       `yaml:"model_sensitivity"`
+	LargePromptTokens int                      `yaml:"large_prompt_tokens"`
+}
+
+// FileWatcher loads the risk model of a Scorer from a file and reloads it
+// when the file changes.
+type FileWatcher struct {
+	path   string
+	scorer *Scorer
+
+	// base is the configuration the file's settings replace
+	base config.RiskConfig
+
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+}
+
+// NewFileWatcher creates a watcher that loads the risk file at path into
+// scorer.
+func NewFileWatcher(path string, scorer *Scorer) *FileWatcher {
+	return &FileWatcher{
+		path:   path,
+		scorer: scorer,
+		base:   scorer.Config(),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start loads the risk file and starts watching it for changes until ctx
+// is done or Stop is called. It returns an error if the file cannot be
+// loaded or watched.
+func (w *FileWatcher) Start(ctx context.Context) error {
+	if err := w.reload(); err != nil {
+		return err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %w", err)
+	}
+
+	// Watch the directory, so files replaced by renaming them, such as
+	// mounted ConfigMaps, are reloaded too
+	if err := watcher.Add(filepath.Dir(w.path)); err != nil {
+		_ = watcher.Close() // Best effort close on error path
+		return fmt.Errorf("failed to watch risk file: %w", err)
+	}
+	w.watcher = watcher
+
+	go w.watchLoop(ctx)
+	return nil
+}
+
+// Stop stops watching the risk file.
+func (w *FileWatcher) Stop() {
+	select {
+	case <-w.done:
+	default:
+		close(w.done)
+	}
+}
+
+// watchLoop reloads the risk file when it changes.
+func (w *FileWatcher) watchLoop(ctx context.Context) {
+	defer func() { _ = w.watcher.Close() }()
+
+	name := filepath.Clean(w.path)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.done:
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(event.Name) != name || !event.Has(fsnotify.Write|fsnotify.Create) {
+				continue
+			}
+			if err := w.reload(); err != nil {
+				slog.Error("failed to reload risk file, keeping previous risk model",
+					"path", w.path,
+					"error", err,
+				)
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			slog.Warn("risk file watcher error", "path", w.path, "error", err)
+		}
+	}
+}
+
+// reload loads the risk file into the scorer.
+func (w *FileWatcher) reload() error {
+	// #nosec G304 - The risk file path comes from configuration
+	data, err := os.ReadFile(w.path)
+	if err != nil {
+		return fmt.Errorf("failed to read risk file: %w", err)
+	}
+
+	var file riskFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse risk file: %w", err)
+	}
+
+	cfg := w.base
+	cfg.Weights = file.Weights
+	cfg.ModelSensitivity = file.ModelSensitivity
+	cfg.LargePromptTokens = file.LargePromptTokens
+	config.ApplyRiskDefaults(&cfg)
+	if err := config.ValidateRisk(&cfg); err != nil {
+		return err
+	}
+
+	w.scorer.SetConfig(&cfg)
+	slog.Info("risk model loaded", "path", w.path)
+	return nil
+}
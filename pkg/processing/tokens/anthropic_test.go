@@ -0,0 +1,82 @@
+package tokens
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"mercator-hq/jupiter/pkg/config"
+	"mercator-hq/jupiter/pkg/proxy/types"
+)
+
+// fakeCounter counts every request as tokens, or fails with err.
+type fakeCounter struct {
+	mu     sync.Mutex
+	tokens int
+	err    error
+	calls  int
+}
+
+func (c *fakeCounter) CountTokens(ctx context.Context, req *types.ChatCompletionRequest) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls++
+	return c.tokens, c.err
+}
+
+func TestAnthropicEstimator_EstimateRequest(t *testing.T) {
+	cfg := &config.TokensConfig{Models: map[string]float64{"default": 4.0}, CacheSize: 1}
+	counter := &fakeCounter{tokens: 57}
+	estimator := NewAnthropicEstimator(counter, NewSimpleEstimator(cfg), cfg)
+
+	maxTokens := 200
+	request := func(model, content string) *types.ChatCompletionRequest {
+		return &types.ChatCompletionRequest{
+			Model:     model,
+			MaxTokens: &maxTokens,
+			Messages:  []types.Message{{Role: "user", Content: content}},
+		}
+	}
+
+	estimate, err := estimator.EstimateRequest(request("claude-3-opus", "Hello"))
+	if err != nil {
+		t.Fatalf("EstimateRequest failed: %v", err)
+	}
+	if estimate.PromptTokens != 57 || estimate.TotalTokens != 257 || estimate.Confidence != 1.0 {
+		t.Errorf("Expected counted prompt tokens 57 of 257 total, got %+v", estimate)
+	}
+
+	// Repeated requests are served from the cache
+	if _, err := estimator.EstimateRequest(request("claude-3-opus", "Hello")); err != nil {
+		t.Fatalf("EstimateRequest failed: %v", err)
+	}
+	if counter.calls != 1 {
+		t.Errorf("Expected 1 count for a repeated request, got %d", counter.calls)
+	}
+
+	// The least recently used count is evicted
+	_, _ = estimator.EstimateRequest(request("claude-3-opus", "Hi"))
+	_, _ = estimator.EstimateRequest(request("claude-3-opus", "Hello"))
+	if counter.calls != 3 {
+		t.Errorf("Expected evicted request to be counted again, got %d counts", counter.calls)
+	}
+
+	// Other models are estimated without counting
+	fallback, _ := NewSimpleEstimator(cfg).EstimateRequest(request("gpt-4", "Hello"))
+	estimate, _ = estimator.EstimateRequest(request("gpt-4", "Hello"))
+	if counter.calls != 3 || estimate.PromptTokens != fallback.PromptTokens {
+		t.Errorf("Expected gpt-4 request to be estimated, got %+v after %d counts", estimate, counter.calls)
+	}
+
+	// Failed counts fall back to the estimate
+	counter.err = errors.New("rate limited")
+	fallback, _ = NewSimpleEstimator(cfg).EstimateRequest(request("claude-3-haiku", "Hey"))
+	estimate, err = estimator.EstimateRequest(request("claude-3-haiku", "Hey"))
+	if err != nil {
+		t.Fatalf("Expected fallback estimate, got error: %v", err)
+	}
+	if estimate.PromptTokens != fallback.PromptTokens || estimate.Confidence == 1.0 {
+		t.Errorf("Expected fallback estimate %+v, got %+v", fallback, estimate)
+	}
+}
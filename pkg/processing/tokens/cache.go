@@ -0,0 +1,83 @@
+package tokens
+
+import (
+	"container/list"
+	"sync"
+)
+
+// tokenCache is a least recently used cache of token counts. It is
+// thread-safe. A nil cache caches nothing.
+type tokenCache[K comparable] struct {
+	mu      sync.Mutex
+	size    int
+	lru     *list.List
+	entries map[K]*list.Element
+}
+
+// cachedCount is a cached token count.
+type cachedCount[K comparable] struct {
+	key    K
+	tokens int
+}
+
+// newTokenCache creates a cache of up to size token counts. It returns nil
+// if size is not positive.
+func newTokenCache[K comparable](size int) *tokenCache[K] {
+	if size <= 0 {
+		return nil
+	}
+	return &tokenCache[K]{
+		size:    size,
+		lru:     list.New(),
+		entries: make(map[K]*list.Element),
+	}
+}
+
+// get returns the cached token count of key.
+func (c *tokenCache[K]) get(key K) (int, bool) {
+	if c == nil {
+		return 0, false
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return 0, false
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*cachedCount[K]).tokens, true
+}
+
+// add caches the token count of key, evicting the least recently used
+// counts if the cache is full.
+func (c *tokenCache[K]) add(key K, tokens int) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[key]; ok {
+		elem.Value.(*cachedCount[K]).tokens = tokens
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&cachedCount[K]{key: key, tokens: tokens})
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cachedCount[K]).key)
+	}
+}
+
+// len returns the number of cached token counts.
+func (c *tokenCache[K]) len() int {
+	if c == nil {
+		return 0
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
@@ -33,6 +33,24 @@
 //	fmt.Printf("Estimated tokens: %d (confidence: %.2f)\n",
 //		estimate.TotalTokens, estimate.Confidence)
 //
+// # Message Cache
+//
+// Multi-turn conversations resend their whole history with every request.
+// SimpleEstimator caches the token count of each message by a hash of its
+// content and the model (least recently used first out), so only the new
+// messages of a conversation are estimated, keeping estimation under 1ms
+// even for long histories.
+//
+// # Anthropic Token Counting
+//
+// Character-based estimates of Claude requests drift from the tokens
+// Anthropic bills by 10-15%. AnthropicEstimator counts the prompt tokens of
+// Claude requests with Anthropic's token counting endpoint instead, caching
+// counts by request content and falling back to another estimator for
+// other models and when counting fails:
+//
+//	estimator := tokens.NewAnthropicEstimator(counter, tokens.NewSimpleEstimator(cfg), cfg)
+//
 // # Future Enhancements
 //
 // Future versions will support:
@@ -40,5 +58,4 @@
 //   - tiktoken-based estimation (exact token matching)
 //   - BPE (Byte-Pair Encoding) tokenizers
 //   - Multimodal token estimation (images, audio)
-//   - Caching for performance optimization
 package tokens
@@ -3,6 +3,7 @@ package tokens
 import (
 	"encoding/json"
 	"fmt"
+	"hash/maphash"
 	"strings"
 	"sync"
 
@@ -13,18 +14,28 @@ import (
 // SimpleEstimator implements character-based token estimation.
 // It uses model-specific characters-per-token ratios to estimate token counts.
 // This achieves <5% error for most requests and is very fast (<1ms).
+//
+// Message token counts are cached by message content, so the unchanged
+// history of a multi-turn conversation is not estimated again.
 type SimpleEstimator struct {
 	// config contains token estimation configuration
 	config *config.TokensConfig
 
+	// messages caches message token counts by content hash (optional)
+	messages *tokenCache[uint64]
+	seed     maphash.Seed
+
 	// mu protects the estimator for concurrent access
 	mu sync.RWMutex
 }
 
 // NewSimpleEstimator creates a new simple character-based token estimator.
+// It caches up to cfg.MessageCacheSize message token counts.
 func NewSimpleEstimator(cfg *config.TokensConfig) *SimpleEstimator {
 	return &SimpleEstimator{
-		config: cfg,
+		config:   cfg,
+		messages: newTokenCache[uint64](cfg.MessageCacheSize),
+		seed:     maphash.MakeSeed(),
 	}
 }
 
@@ -57,39 +68,101 @@ func (e *SimpleEstimator) EstimateMessages(messages []types.Message, model strin
 	totalTokens := 0
 
 	for _, msg := range messages {
-		// Estimate role tokens (~1 token per role)
-		totalTokens += 1
-
-		// Estimate content tokens
-		contentStr := e.extractContent(msg.Content)
-		contentTokens, err := e.EstimateText(contentStr, model)
+		messageTokens, err := e.messageTokens(msg, model)
 		if err != nil {
-			return 0, fmt.Errorf("failed to estimate message content: %w", err)
+			return 0, err
 		}
-		totalTokens += contentTokens
+		totalTokens += messageTokens
+	}
 
-		// Estimate name tokens if present
-		if msg.Name != "" {
-			nameTokens, _ := e.EstimateText(msg.Name, model)
-			totalTokens += nameTokens
-		}
+	// Add conversation formatting overhead (~3 tokens)
+	totalTokens += 3
 
-		// Estimate tool call tokens if present
-		if len(msg.ToolCalls) > 0 {
-			toolCallTokens := e.estimateToolCalls(msg.ToolCalls, model)
-			totalTokens += toolCallTokens
-		}
+	return totalTokens, nil
+}
 
-		// Add message formatting overhead (~3 tokens per message)
-		totalTokens += 3
+// messageTokens estimates tokens for a single message, from the message
+// cache if a message with the same content was estimated before.
+func (e *SimpleEstimator) messageTokens(msg types.Message, model string) (int, error) {
+	if e.messages == nil {
+		return e.estimateMessage(msg, model)
 	}
 
-	// Add conversation formatting overhead (~3 tokens)
+	key := e.messageKey(msg, model)
+	if tokens, ok := e.messages.get(key); ok {
+		return tokens, nil
+	}
+	tokens, err := e.estimateMessage(msg, model)
+	if err != nil {
+		return 0, err
+	}
+	e.messages.add(key, tokens)
+	return tokens, nil
+}
+
+// estimateMessage estimates tokens for a single message, including its
+// formatting overhead.
+func (e *SimpleEstimator) estimateMessage(msg types.Message, model string) (int, error) {
+	// Estimate role tokens (~1 token per role)
+	totalTokens := 1
+
+	// Estimate content tokens
+	contentStr := e.extractContent(msg.Content)
+	contentTokens, err := e.EstimateText(contentStr, model)
+	if err != nil {
+		return 0, fmt.Errorf("failed to estimate message content: %w", err)
+	}
+	totalTokens += contentTokens
+
+	// Estimate name tokens if present
+	if msg.Name != "" {
+		nameTokens, _ := e.EstimateText(msg.Name, model)
+		totalTokens += nameTokens
+	}
+
+	// Estimate tool call tokens if present
+	if len(msg.ToolCalls) > 0 {
+		toolCallTokens := e.estimateToolCalls(msg.ToolCalls, model)
+		totalTokens += toolCallTokens
+	}
+
+	// Add message formatting overhead (~3 tokens per message)
 	totalTokens += 3
 
 	return totalTokens, nil
 }
 
+// messageKey returns the message cache key of a message: a hash of the
+// model and the parts of the message that count towards its tokens.
+func (e *SimpleEstimator) messageKey(msg types.Message, model string) uint64 {
+	var h maphash.Hash
+	h.SetSeed(e.seed)
+
+	write := func(s string) {
+		_, _ = h.WriteString(s)
+		_ = h.WriteByte(0)
+	}
+	write(model)
+	write(msg.Name)
+	switch content := msg.Content.(type) {
+	case nil:
+	case string:
+		_ = h.WriteByte('s')
+		write(content)
+	default:
+		// Multimodal content parts
+		data, _ := json.Marshal(content)
+		_ = h.WriteByte('p')
+		_, _ = h.Write(data)
+		_ = h.WriteByte(0)
+	}
+	for _, tc := range msg.ToolCalls {
+		write(tc.Function.Name)
+		write(tc.Function.Arguments)
+	}
+	return h.Sum64()
+}
+
 // EstimateTools estimates tokens for tool/function definitions.
 func (e *SimpleEstimator) EstimateTools(tools []types.Tool, model string) (int, error) {
 	if len(tools) == 0 {
@@ -1,6 +1,8 @@
 package tokens
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"mercator-hq/jupiter/pkg/config"
@@ -181,6 +183,73 @@ func TestSimpleEstimator_EstimateMessages(t *testing.T) {
 	}
 }
 
+func TestSimpleEstimator_MessageCache(t *testing.T) {
+	cfg := &config.TokensConfig{
+		MessageCacheSize: 3,
+		Models:           map[string]float64{"default": 4.0},
+	}
+	estimator := NewSimpleEstimator(cfg)
+	uncached := NewSimpleEstimator(&config.TokensConfig{Models: cfg.Models})
+
+	history := []types.Message{
+		{Role: "user", Content: "What is the capital of France?"},
+		{Role: "assistant", Content: "The capital of France is Paris."},
+	}
+	first, err := estimator.EstimateMessages(history, "gpt-4")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := estimator.messages.len(); got != 2 {
+		t.Fatalf("expected 2 cached messages, got %d", got)
+	}
+
+	// The next turn only estimates the new message
+	history = append(history, types.Message{Role: "user", Content: []interface{}{
+		map[string]interface{}{"type": "text", "text": "What about Germany?"},
+	}})
+	second, err := estimator.EstimateMessages(history, "gpt-4")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want, _ := uncached.EstimateMessages(history, "gpt-4")
+	if second != want || second <= first {
+		t.Errorf("expected %d tokens as without the cache, got %d", want, second)
+	}
+	if got := estimator.messages.len(); got != 3 {
+		t.Errorf("expected 3 cached messages, got %d", got)
+	}
+
+	// Counts are cached per model and evicted beyond the cache size
+	if _, err := estimator.EstimateMessages(history[:1], "claude-3-opus"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := estimator.messages.len(); got != 3 {
+		t.Errorf("expected cache size 3, got %d", got)
+	}
+}
+
+func BenchmarkSimpleEstimator_EstimateRequest_LongHistory(b *testing.B) {
+	estimator := NewSimpleEstimator(&config.TokensConfig{
+		MessageCacheSize: 10000,
+		Models:           map[string]float64{"default": 4.0},
+	})
+
+	req := &types.ChatCompletionRequest{Model: "gpt-4"}
+	for i := 0; i < 100; i++ {
+		req.Messages = append(req.Messages, types.Message{
+			Role:    "user",
+			Content: strings.Repeat(fmt.Sprintf("Message %d of a long conversation. ", i), 50),
+		})
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := estimator.EstimateRequest(req); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func TestSimpleEstimator_EstimateTools(t *testing.T) {
 	cfg := &config.TokensConfig{
 		Models: map[string]float64{
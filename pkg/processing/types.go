@@ -18,7 +18,14 @@ type (
 	PIILocation      = content.PIILocation
 	SensitiveContent = content.SensitiveContent
 	PromptInjection  = content.PromptInjection
+	SecretDetection  = content.SecretDetection
+	TopicSimilarity  = content.TopicSimilarity
+	EntityDetection  = content.EntityDetection
+	EntityMention    = content.EntityMention
+	Classification   = content.Classification
 	Sentiment        = content.Sentiment
+	Finding          = content.Finding
+	Placeholders     = content.Placeholders
 )
 
 // Re-export conversation types for convenience
@@ -55,7 +62,7 @@ type EnrichedRequest struct {
 	// ModelFamily identifies the model family (GPT-4, Claude 3, Llama, etc.).
 	ModelFamily string
 
-	// PricingTier identifies the provider pricing tier (standard, enterprise, custom).
+	// PricingTier identifies the provider pricing tier (standard, long_context).
 	PricingTier string
 
 	// EstimatedLatency is the predicted latency based on token count and model.
@@ -67,6 +74,9 @@ type EnrichedRequest struct {
 	// RiskScore rates request risk from 1-10 based on content analysis.
 	RiskScore int
 
+	// Analyzers contains the fields of custom analyzers by analyzer name.
+	Analyzers map[string]AnalyzerFields
+
 	// ProcessingDuration is the time taken to enrich this request.
 	ProcessingDuration time.Duration
 }
@@ -101,6 +111,9 @@ type EnrichedResponse struct {
 	// QualityMetrics contains response quality scores.
 	QualityMetrics *QualityMetrics
 
+	// Analyzers contains the fields of custom analyzers by analyzer name.
+	Analyzers map[string]AnalyzerFields
+
 	// ProcessingDuration is the time taken to enrich this response.
 	ProcessingDuration time.Duration
 }
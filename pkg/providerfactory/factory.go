@@ -7,8 +7,13 @@ import (
 
 	"mercator-hq/jupiter/pkg/providers"
 	"mercator-hq/jupiter/pkg/providers/anthropic"
+	"mercator-hq/jupiter/pkg/providers/bedrock"
+	"mercator-hq/jupiter/pkg/providers/gemini"
 	"mercator-hq/jupiter/pkg/providers/generic"
 	"mercator-hq/jupiter/pkg/providers/openai"
+	"mercator-hq/jupiter/pkg/providers/replicate"
+	"mercator-hq/jupiter/pkg/providers/tgi"
+	"mercator-hq/jupiter/pkg/providers/vertex"
 )
 
 // NewProvider creates a new provider instance based on the configuration.
@@ -17,12 +22,18 @@ import (
 // Supported provider types:
 //   - "openai": OpenAI API
 //   - "anthropic": Anthropic Messages API
+//   - "bedrock": AWS Bedrock (Converse or InvokeModel APIs, SigV4-signed)
+//   - "vertex": Google Vertex AI Gemini models (generateContent API)
+//   - "gemini": Gemini API of Google AI Studio (API key)
 //   - "generic": OpenAI-compatible APIs (Ollama, LM Studio, vLLM, etc.)
 //
 // The provider type is determined from the config.Type field. If not specified,
 // it is inferred from the provider name:
 //   - "openai" -> OpenAI
 //   - "anthropic" -> Anthropic
+//   - "bedrock" -> Bedrock
+//   - "vertex" -> Vertex AI
+//   - "gemini" -> Gemini API
 //   - Everything else -> Generic
 //
 // Example:
@@ -64,6 +75,21 @@ func NewProvider(config providers.ProviderConfig) (providers.Provider, error) {
 	case "anthropic":
 		provider, err = anthropic.NewProvider(config)
 
+	case "bedrock":
+		provider, err = bedrock.NewProvider(config)
+
+	case "vertex":
+		provider, err = vertex.NewProvider(config)
+
+	case "gemini":
+		provider, err = gemini.NewProvider(config)
+
+	case "tgi":
+		provider, err = tgi.NewProvider(config)
+
+	case "replicate":
+		provider, err = replicate.NewProvider(config)
+
 	case "generic":
 		provider, err = generic.NewProvider(config)
 
@@ -71,7 +97,7 @@ func NewProvider(config providers.ProviderConfig) (providers.Provider, error) {
 		return nil, &providers.ConfigError{
 			Provider: config.Name,
 			Field:    "type",
-			Message:  fmt.Sprintf("unsupported provider type: %q (supported: openai, anthropic, generic)", providerType),
+			Message:  fmt.Sprintf("unsupported provider type: %q (supported: openai, anthropic, bedrock, vertex, gemini, tgi, replicate, generic)", providerType),
 		}
 	}
 
@@ -132,6 +158,16 @@ func inferProviderType(name string) string {
 		return "openai"
 	case "anthropic":
 		return "anthropic"
+	case "bedrock":
+		return "bedrock"
+	case "vertex":
+		return "vertex"
+	case "gemini":
+		return "gemini"
+	case "tgi":
+		return "tgi"
+	case "replicate":
+		return "replicate"
 	case "ollama", "lmstudio", "vllm", "localai":
 		return "generic"
 	default:
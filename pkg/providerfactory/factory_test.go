@@ -197,6 +197,9 @@ func TestInferProviderType(t *testing.T) {
 	}{
 		{"openai", "openai"},
 		{"anthropic", "anthropic"},
+		{"gemini", "gemini"},
+		{"tgi", "tgi"},
+		{"replicate", "replicate"},
 		{"ollama", "generic"},
 		{"lmstudio", "generic"},
 		{"vllm", "generic"},
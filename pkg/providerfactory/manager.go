@@ -10,38 +10,60 @@ import (
 )
 
 // Manager manages a collection of provider instances.
-// It handles provider lifecycle (creation, health monitoring, shutdown).
+// It handles provider lifecycle (creation, health monitoring, shutdown)
+// and routes model names to the providers that serve them.
 //
 // Manager is thread-safe and can be used concurrently.
 type Manager struct {
 	providers map[string]providers.Provider
+	routes    map[string]ModelRoute
 	mu        sync.RWMutex
 	ctx       context.Context
 	cancel    context.CancelFunc
 }
 
+// ModelRoute is the provider a model name is routed to, and the model the
+// provider serves it with.
+type ModelRoute struct {
+	// Provider is the name of the provider
+	Provider string
+
+	// Model is the upstream model sent to the provider
+	Model string
+}
+
 // NewManager creates a new provider manager.
 func NewManager() *Manager {
 	ctx, cancel := context.WithCancel(context.Background())
 
 	return &Manager{
 		providers: make(map[string]providers.Provider),
+		routes:    make(map[string]ModelRoute),
 		ctx:       ctx,
 		cancel:    cancel,
 	}
 }
 
-// AddProvider adds a provider to the manager.
+// AddProvider adds a provider to the manager, and routes the models of its
+// configuration to it.
 // If a provider with the same name already exists, it is replaced and the old one is closed.
+// Models that are routed to another provider are an error.
 func (m *Manager) AddProvider(config providers.ProviderConfig) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	for model := range config.Models {
+		if route, ok := m.routes[model]; ok && route.Provider != config.Name {
+			return fmt.Errorf("failed to add provider %q: model %q is already routed to provider %q", config.Name, model, route.Provider)
+		}
+	}
+
 	// Check if provider already exists
 	if existing, ok := m.providers[config.Name]; ok {
 		slog.Warn("replacing existing provider", "name", config.Name)
 		existing.Close()
 		delete(m.providers, config.Name)
+		m.removeRoutes(config.Name)
 	}
 
 	// Create provider with health checking
@@ -52,9 +74,19 @@ func (m *Manager) AddProvider(config providers.ProviderConfig) error {
 
 	m.providers[config.Name] = provider
 
+	// Route models to the provider; an empty upstream model keeps the
+	// requested name
+	for model, upstream := range config.Models {
+		if upstream == "" {
+			upstream = model
+		}
+		m.routes[model] = ModelRoute{Provider: config.Name, Model: upstream}
+	}
+
 	slog.Info("provider added to manager",
 		"name", config.Name,
 		"type", provider.GetType(),
+		"models", len(config.Models),
 		"total_providers", len(m.providers),
 	)
 
@@ -76,6 +108,7 @@ func (m *Manager) RemoveProvider(name string) error {
 	}
 
 	delete(m.providers, name)
+	m.removeRoutes(name)
 
 	slog.Info("provider removed from manager",
 		"name", name,
@@ -98,6 +131,44 @@ func (m *Manager) GetProvider(name string) (providers.Provider, error) {
 	return provider, nil
 }
 
+// removeRoutes removes the model routes of a provider.
+// The caller must hold the write lock.
+func (m *Manager) removeRoutes(name string) {
+	for model, route := range m.routes {
+		if route.Provider == name {
+			delete(m.routes, model)
+		}
+	}
+}
+
+// ResolveModel returns the provider a model name is routed to, and the
+// upstream model the provider serves it with. ok is false for models
+// without a route, which are not mapped.
+func (m *Manager) ResolveModel(model string) (provider, upstreamModel string, ok bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	route, ok := m.routes[model]
+	if !ok {
+		return "", "", false
+	}
+	return route.Provider, route.Model, true
+}
+
+// GetModelRoutes returns the model routes of all providers, by model name.
+// The returned map is a copy and safe to modify.
+func (m *Manager) GetModelRoutes() map[string]ModelRoute {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	routes := make(map[string]ModelRoute, len(m.routes))
+	for model, route := range m.routes {
+		routes[model] = route
+	}
+
+	return routes
+}
+
 // GetProviders returns a map of all providers.
 // The returned map is a copy and safe to modify.
 func (m *Manager) GetProviders() map[string]providers.Provider {
@@ -218,8 +289,9 @@ func (m *Manager) Close() error {
 		}
 	}
 
-	// Clear providers map
+	// Clear providers and routes
 	m.providers = make(map[string]providers.Provider)
+	m.routes = make(map[string]ModelRoute)
 
 	if len(errors) > 0 {
 		return fmt.Errorf("errors closing providers: %v", errors)
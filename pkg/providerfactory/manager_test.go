@@ -276,3 +276,101 @@ func TestManager_ReplaceProvider(t *testing.T) {
 		t.Errorf("expected 1 provider after replacement, got %d", manager.ProviderCount())
 	}
 }
+
+func TestManager_ResolveModel(t *testing.T) {
+	manager := NewManager()
+	defer manager.Close()
+
+	configs := []providers.ProviderConfig{
+		{
+			Name:    "anthropic",
+			Type:    "anthropic",
+			BaseURL: "https://api.anthropic.com/v1",
+			APIKey:  "test-key",
+			Models: map[string]string{
+				"gpt-4":         "claude-3-5-sonnet-20241022",
+				"default-smart": "claude-3-opus-20240229",
+			},
+		},
+		{
+			Name:    "vllm",
+			Type:    "generic",
+			BaseURL: "http://vllm:8000/v1",
+			Models:  map[string]string{"llama-3-70b": ""},
+		},
+	}
+	if err := manager.LoadFromConfig(configs); err != nil {
+		t.Fatalf("LoadFromConfig() failed: %v", err)
+	}
+
+	tests := []struct {
+		model        string
+		wantProvider string
+		wantUpstream string
+		wantOK       bool
+	}{
+		{"gpt-4", "anthropic", "claude-3-5-sonnet-20241022", true},
+		{"default-smart", "anthropic", "claude-3-opus-20240229", true},
+		{"llama-3-70b", "vllm", "llama-3-70b", true},
+		{"gpt-3.5-turbo", "", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			provider, upstream, ok := manager.ResolveModel(tt.model)
+			if provider != tt.wantProvider || upstream != tt.wantUpstream || ok != tt.wantOK {
+				t.Errorf("ResolveModel(%q) = %q, %q, %v, want %q, %q, %v",
+					tt.model, provider, upstream, ok, tt.wantProvider, tt.wantUpstream, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestManager_ModelRoutes_Lifecycle(t *testing.T) {
+	manager := NewManager()
+	defer manager.Close()
+
+	anthropic := providers.ProviderConfig{
+		Name:    "anthropic",
+		Type:    "anthropic",
+		BaseURL: "https://api.anthropic.com/v1",
+		APIKey:  "test-key",
+		Models:  map[string]string{"gpt-4": "claude-3-5-sonnet-20241022"},
+	}
+	if err := manager.AddProvider(anthropic); err != nil {
+		t.Fatalf("AddProvider() failed: %v", err)
+	}
+
+	// A model is routed to a single provider
+	err := manager.AddProvider(providers.ProviderConfig{
+		Name:    "openai",
+		Type:    "openai",
+		BaseURL: "https://api.openai.com/v1",
+		APIKey:  "test-key",
+		Models:  map[string]string{"gpt-4": ""},
+	})
+	if err == nil {
+		t.Fatal("expected error for model routed to two providers, got nil")
+	}
+	if manager.ProviderCount() != 1 {
+		t.Errorf("expected 1 provider, got %d", manager.ProviderCount())
+	}
+
+	// Replacing a provider replaces its routes
+	anthropic.Models = map[string]string{"default-smart": "claude-3-opus-20240229"}
+	if err := manager.AddProvider(anthropic); err != nil {
+		t.Fatalf("AddProvider() (replace) failed: %v", err)
+	}
+	routes := manager.GetModelRoutes()
+	if _, ok := routes["gpt-4"]; ok || len(routes) != 1 {
+		t.Errorf("routes after replacement = %v, want only default-smart", routes)
+	}
+
+	// Removing a provider removes its routes
+	if err := manager.RemoveProvider("anthropic"); err != nil {
+		t.Fatalf("RemoveProvider() failed: %v", err)
+	}
+	if _, _, ok := manager.ResolveModel("default-smart"); ok {
+		t.Error("expected no route after removal")
+	}
+}
@@ -182,6 +182,33 @@ func (p *Provider) StreamCompletion(ctx context.Context, req *providers.Completi
 	return chunks, nil
 }
 
+// CountTokens returns the number of input tokens of a completion request,
+// as counted by Anthropic's token counting endpoint. Counting is free of
+// charge but subject to its own rate limits.
+func (p *Provider) CountTokens(ctx context.Context, req *providers.CompletionRequest) (int, error) {
+	if err := validateRequest(req); err != nil {
+		return 0, err
+	}
+
+	anthropicReq, err := transformRequest(req)
+	if err != nil {
+		return 0, err
+	}
+
+	url := fmt.Sprintf("%s/v1/messages/count_tokens", p.GetConfig().BaseURL)
+	headers := map[string]string{
+		"x-api-key":         p.GetConfig().APIKey,
+		"anthropic-version": DefaultAnthropicVersion,
+		"Content-Type":      "application/json",
+	}
+
+	var countResp AnthropicCountTokensResponse
+	if err := p.DoJSONRequest(ctx, "POST", url, newCountTokensRequest(anthropicReq), &countResp, headers); err != nil {
+		return 0, err
+	}
+	return countResp.InputTokens, nil
+}
+
 // validateRequest validates the completion request.
 func validateRequest(req *providers.CompletionRequest) error {
 	if req == nil {
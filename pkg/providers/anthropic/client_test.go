@@ -2,6 +2,9 @@ package anthropic
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	testhelpers "mercator-hq/jupiter/internal/providers"
@@ -57,6 +60,50 @@ func TestAnthropicProvider_SendCompletion(t *testing.T) {
 	}
 }
 
+func TestAnthropicProvider_CountTokens(t *testing.T) {
+	var body map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages/count_tokens" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("x-api-key") == "" || r.Header.Get("anthropic-version") != DefaultAnthropicVersion {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		_ = json.NewEncoder(w).Encode(map[string]int{"input_tokens": 42})
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(testhelpers.TestConfigWithURL("anthropic", "anthropic", server.URL))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	defer provider.Close()
+
+	count, err := provider.CountTokens(context.Background(), &providers.CompletionRequest{
+		Model: "claude-3-opus-20240229",
+		Messages: []providers.Message{
+			{Role: providers.RoleSystem, Content: "Be brief."},
+			{Role: providers.RoleUser, Content: "Hello"},
+		},
+		MaxTokens: 1024,
+	})
+	if err != nil {
+		t.Fatalf("CountTokens failed: %v", err)
+	}
+	if count != 42 {
+		t.Errorf("expected 42 input tokens, got %d", count)
+	}
+	if body["system"] != "Be brief." {
+		t.Errorf("expected system prompt to be counted, got %v", body["system"])
+	}
+	if _, ok := body["max_tokens"]; ok {
+		t.Error("expected count_tokens request without max_tokens")
+	}
+}
+
 func TestAnthropicProvider_ValidationError(t *testing.T) {
 	config := testhelpers.TestConfig("anthropic", "anthropic")
 	provider, err := NewProvider(config)
@@ -169,3 +216,32 @@ func findSubstring(s, substr string) bool {
 	}
 	return false
 }
+
+func TestTransformResponse_CacheUsage(t *testing.T) {
+	resp, err := transformResponse(&AnthropicResponse{
+		ID:         "msg_1",
+		Model:      "claude-3-5-sonnet-20241022",
+		StopReason: "end_turn",
+		Usage: AnthropicUsage{
+			InputTokens:              10,
+			OutputTokens:             20,
+			CacheCreationInputTokens: 100,
+			CacheReadInputTokens:     1000,
+			ServiceTier:              "batch",
+		},
+	})
+	if err != nil {
+		t.Fatalf("transformResponse failed: %v", err)
+	}
+
+	want := providers.TokenUsage{
+		PromptTokens:       1110,
+		CompletionTokens:   20,
+		TotalTokens:        1130,
+		CachedPromptTokens: 1000,
+		Batch:              true,
+	}
+	if resp.Usage != want {
+		t.Errorf("expected usage %+v, got %+v", want, resp.Usage)
+	}
+}
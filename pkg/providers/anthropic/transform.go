@@ -64,8 +64,41 @@ type AnthropicResponse struct {
 
 // AnthropicUsage represents token usage in Anthropic format.
 type AnthropicUsage struct {
-	InputTokens  int `json:"input_tokens"`
-	OutputTokens int `json:"output_tokens"`
+	InputTokens              int    `json:"input_tokens"`
+	OutputTokens             int    `json:"output_tokens"`
+	CacheCreationInputTokens int    `json:"cache_creation_input_tokens,omitempty"`
+	CacheReadInputTokens     int    `json:"cache_read_input_tokens,omitempty"`
+	ServiceTier              string `json:"service_tier,omitempty"`
+}
+
+// tokenUsage transforms Anthropic token usage to provider-agnostic format.
+// Anthropic counts the input tokens written to and read from the prompt
+// cache separately from the other input tokens.
+func tokenUsage(usage *AnthropicUsage) providers.TokenUsage {
+	promptTokens := usage.InputTokens + usage.CacheCreationInputTokens + usage.CacheReadInputTokens
+	return providers.TokenUsage{
+		PromptTokens:       promptTokens,
+		CompletionTokens:   usage.OutputTokens,
+		TotalTokens:        promptTokens + usage.OutputTokens,
+		CachedPromptTokens: usage.CacheReadInputTokens,
+		Batch:              usage.ServiceTier == "batch",
+	}
+}
+
+// AnthropicCountTokensRequest represents an Anthropic token counting
+// request. It carries the parts of a messages request that count towards
+// its input tokens.
+type AnthropicCountTokensRequest struct {
+	Model    string             `json:"model"`
+	Messages []AnthropicMessage `json:"messages"`
+	System   string             `json:"system,omitempty"`
+	Tools    []AnthropicTool    `json:"tools,omitempty"`
+}
+
+// AnthropicCountTokensResponse represents an Anthropic token counting
+// response.
+type AnthropicCountTokensResponse struct {
+	InputTokens int `json:"input_tokens"`
 }
 
 // Anthropic streaming response types
@@ -192,6 +225,17 @@ func transformRequest(req *providers.CompletionRequest) (*AnthropicRequest, erro
 	return anthropicReq, nil
 }
 
+// newCountTokensRequest returns the token counting request of a messages
+// request.
+func newCountTokensRequest(req *AnthropicRequest) *AnthropicCountTokensRequest {
+	return &AnthropicCountTokensRequest{
+		Model:    req.Model,
+		Messages: req.Messages,
+		System:   req.System,
+		Tools:    req.Tools,
+	}
+}
+
 // validateMessageSequence validates that messages alternate between user and assistant.
 func validateMessageSequence(messages []AnthropicMessage) error {
 	if len(messages) == 0 {
@@ -258,13 +302,9 @@ func transformResponse(resp *AnthropicResponse) (*providers.CompletionResponse,
 		Model:        resp.Model,
 		Content:      content,
 		FinishReason: normalizeStopReason(resp.StopReason),
-		Usage: providers.TokenUsage{
-			PromptTokens:     resp.Usage.InputTokens,
-			CompletionTokens: resp.Usage.OutputTokens,
-			TotalTokens:      resp.Usage.InputTokens + resp.Usage.OutputTokens,
-		},
-		ToolCalls: toolCalls,
-		Metadata:  make(map[string]string),
+		Usage:        tokenUsage(&resp.Usage),
+		ToolCalls:    toolCalls,
+		Metadata:     make(map[string]string),
 	}
 
 	return result, nil
@@ -311,11 +351,8 @@ func transformStreamChunk(event *AnthropicStreamEvent, state *streamState) (*pro
 			chunk.FinishReason = normalizeStopReason(event.Delta2.StopReason)
 		}
 		if event.Usage != nil {
-			chunk.Usage = &providers.TokenUsage{
-				PromptTokens:     event.Usage.InputTokens,
-				CompletionTokens: event.Usage.OutputTokens,
-				TotalTokens:      event.Usage.InputTokens + event.Usage.OutputTokens,
-			}
+			usage := tokenUsage(event.Usage)
+			chunk.Usage = &usage
 		}
 		return chunk, nil
 
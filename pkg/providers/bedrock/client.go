@@ -0,0 +1,270 @@
+package bedrock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"mercator-hq/jupiter/pkg/providers"
+	"mercator-hq/jupiter/pkg/security/sigv4"
+)
+
+// Provider is the AWS Bedrock provider adapter.
+// It implements the providers.Provider interface for the Bedrock Runtime
+// Converse and InvokeModel APIs.
+type Provider struct {
+	*providers.HTTPProvider
+
+	// api is the Bedrock API requests are sent to
+	api string
+}
+
+const (
+	// APIConverse is the model-agnostic Converse API
+	APIConverse = "converse"
+
+	// APIInvoke is the InvokeModel API, with each model family's native
+	// request format
+	APIInvoke = "invoke"
+
+	// signingService is the SigV4 service name of Bedrock
+	signingService = "bedrock"
+
+	// requestIDHeader is the response header of the Bedrock request ID,
+	// used as the response ID
+	requestIDHeader = "X-Amzn-Requestid"
+)
+
+// NewProvider creates a new Bedrock provider instance. Requests are signed
+// with AWS Signature Version 4, with credentials from the standard AWS
+// credential chain (see sigv4.Chain).
+func NewProvider(config providers.ProviderConfig) (*Provider, error) {
+	return newProvider(config, sigv4.NewChain(nil))
+}
+
+// newProvider creates a provider signing requests with the credentials of
+// credentials.
+func newProvider(config providers.ProviderConfig, credentials sigv4.CredentialsProvider) (*Provider, error) {
+	// Validate configuration
+	if config.Name == "" {
+		return nil, &providers.ConfigError{
+			Provider: "bedrock",
+			Field:    "name",
+			Message:  "provider name is required",
+		}
+	}
+
+	if config.Region == "" {
+		config.Region = sigv4.Region()
+	}
+	if config.Region == "" {
+		return nil, &providers.ConfigError{
+			Provider: config.Name,
+			Field:    "region",
+			Message:  "region is required for Bedrock (or set AWS_REGION)",
+		}
+	}
+
+	switch config.API {
+	case "":
+		config.API = APIConverse
+	case APIConverse, APIInvoke:
+	default:
+		return nil, &providers.ConfigError{
+			Provider: config.Name,
+			Field:    "api",
+			Message:  fmt.Sprintf("unsupported Bedrock API %q (supported: converse, invoke)", config.API),
+		}
+	}
+
+	customEndpoint := config.BaseURL != ""
+	if !customEndpoint {
+		config.BaseURL = fmt.Sprintf("https://bedrock-runtime.%s.amazonaws.com", config.Region)
+	}
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
+
+	// Set defaults if not provided
+	if config.MaxRetries == 0 {
+		config.MaxRetries = 3
+	}
+	if config.MaxIdleConns == 0 {
+		config.MaxIdleConns = 100
+	}
+	if config.MaxIdleConnsPerHost == 0 {
+		config.MaxIdleConnsPerHost = 10
+	}
+
+	// Create base HTTP provider, signing each request
+	httpProvider := providers.NewHTTPProvider(config)
+	signer := sigv4.NewSignerWithProvider(credentials, config.Region, signingService)
+	httpProvider.SetRequestSigner(func(req *http.Request, body []byte) error {
+		return signer.Sign(req, body, time.Now())
+	})
+
+	// The runtime endpoint has no cheap read-only operation; health checks
+	// list the foundation models of the control plane instead.
+	if !customEndpoint {
+		httpProvider.SetHealthCheckURL(fmt.Sprintf("https://bedrock.%s.amazonaws.com/foundation-models?byOutputModality=TEXT", config.Region))
+	}
+
+	p := &Provider{
+		HTTPProvider: httpProvider,
+		api:          config.API,
+	}
+
+	slog.Info("Bedrock provider initialized",
+		"provider", config.Name,
+		"region", config.Region,
+		"api", config.API,
+		"base_url", config.BaseURL,
+	)
+
+	return p, nil
+}
+
+// SendCompletion sends a completion request to Bedrock.
+func (p *Provider) SendCompletion(ctx context.Context, req *providers.CompletionRequest) (*providers.CompletionResponse, error) {
+	// Validate request
+	if err := validateRequest(req); err != nil {
+		return nil, err
+	}
+
+	if p.api == APIInvoke {
+		return p.invoke(ctx, req)
+	}
+	return p.converse(ctx, req)
+}
+
+// StreamCompletion sends a streaming completion request to Bedrock. The
+// event stream of the response is normalized to stream chunks.
+func (p *Provider) StreamCompletion(ctx context.Context, req *providers.CompletionRequest) (<-chan *providers.StreamChunk, error) {
+	// Validate request
+	if err := validateRequest(req); err != nil {
+		return nil, err
+	}
+
+	var stream *streamReader
+	var err error
+	if p.api == APIInvoke {
+		stream, err = p.invokeStream(ctx, req)
+	} else {
+		stream, err = p.converseStream(ctx, req)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Create output channel
+	chunks := make(chan *providers.StreamChunk, 100) // Buffered channel
+
+	// Start goroutine to read stream and send chunks
+	go func() {
+		defer close(chunks)
+		defer stream.Close()
+
+		for {
+			chunk, err := stream.Read(ctx)
+			if err == io.EOF {
+				// Stream ended normally
+				return
+			}
+			if err != nil {
+				// Send error chunk and exit
+				select {
+				case chunks <- &providers.StreamChunk{Error: err}:
+				case <-ctx.Done():
+				}
+				return
+			}
+
+			// Send chunk
+			select {
+			case chunks <- chunk:
+			case <-ctx.Done():
+				return
+			}
+
+			// Check if this is the final chunk
+			if chunk.FinishReason != "" {
+				return
+			}
+		}
+	}()
+
+	return chunks, nil
+}
+
+// modelURL returns the URL of an operation on a model. Model IDs contain
+// colons (e.g. "anthropic.claude-3-haiku-20240307-v1:0"), which are
+// escaped as the AWS SDKs do, so that the path is signed as sent.
+func (p *Provider) modelURL(model, operation string) string {
+	escaped := strings.ReplaceAll(url.PathEscape(model), ":", "%3A")
+	return fmt.Sprintf("%s/model/%s/%s", p.GetConfig().BaseURL, escaped, operation)
+}
+
+// postJSON sends a JSON request and decodes the JSON response. It returns
+// the Bedrock request ID of the response.
+func (p *Provider) postJSON(ctx context.Context, url string, reqBody, respBody interface{}) (string, error) {
+	bodyBytes, err := json.Marshal(reqBody)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"Accept":       "application/json",
+	}
+	resp, err := p.DoRequest(ctx, "POST", url, bodyBytes, headers)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	responseBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", &providers.ParseError{
+			Provider: p.GetName(),
+			Cause:    fmt.Errorf("failed to read response: %w", err),
+		}
+	}
+	if err := json.Unmarshal(responseBytes, respBody); err != nil {
+		return "", &providers.ParseError{
+			Provider:    p.GetName(),
+			RawResponse: string(responseBytes),
+			Cause:       fmt.Errorf("failed to unmarshal response: %w", err),
+		}
+	}
+	return resp.Header.Get(requestIDHeader), nil
+}
+
+// validateRequest validates the completion request.
+func validateRequest(req *providers.CompletionRequest) error {
+	if req == nil {
+		return &providers.ValidationError{
+			Field:   "request",
+			Message: "request cannot be nil",
+		}
+	}
+
+	if req.Model == "" {
+		return &providers.ValidationError{
+			Field:   "model",
+			Message: "model is required",
+		}
+	}
+
+	if len(req.Messages) == 0 {
+		return &providers.ValidationError{
+			Field:   "messages",
+			Message: "at least one message is required",
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,424 @@
+package bedrock
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mercator-hq/jupiter/pkg/providers"
+	"mercator-hq/jupiter/pkg/security/sigv4"
+)
+
+const testModel = "anthropic.claude-3-haiku-20240307-v1:0"
+
+func newTestProvider(t *testing.T, baseURL, api string) *Provider {
+	t.Helper()
+	provider, err := newProvider(providers.ProviderConfig{
+		Name:    "bedrock",
+		Type:    "bedrock",
+		BaseURL: baseURL,
+		Region:  "us-west-2",
+		API:     api,
+	}, sigv4.StaticCredentials(sigv4.Credentials{AccessKeyID: "AKID", SecretAccessKey: "secret"}))
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	return provider
+}
+
+// bedrockHandler checks the signature and path of requests, decodes their
+// body into body, and serves them with serve.
+func bedrockHandler(t *testing.T, path string, body interface{}, serve func(w http.ResponseWriter)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		authorization := r.Header.Get("Authorization")
+		if !strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKID/") ||
+			!strings.Contains(authorization, "/us-west-2/bedrock/aws4_request") || r.Header.Get("X-Amz-Date") == "" {
+			t.Errorf("Authorization = %q, want a SigV4 signature for bedrock", authorization)
+		}
+		if r.RequestURI != path {
+			t.Errorf("request URI = %q, want %q", r.RequestURI, path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(body); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		w.Header().Set(requestIDHeader, "req-123")
+		serve(w)
+	}
+}
+
+func TestBedrockProvider_Converse(t *testing.T) {
+	var body ConverseRequest
+	server := httptest.NewServer(bedrockHandler(t, "/model/anthropic.claude-3-haiku-20240307-v1%3A0/converse", &body, func(w http.ResponseWriter) {
+		_, _ = w.Write([]byte(`{
+			"output": {"message": {"role": "assistant", "content": [
+				{"text": "Checking the weather."},
+				{"toolUse": {"toolUseId": "tooluse_1", "name": "get_weather", "input": {"city": "Paris"}}}
+			]}},
+			"stopReason": "tool_use",
+			"usage": {"inputTokens": 20, "outputTokens": 10, "totalTokens": 35, "cacheReadInputTokens": 5}
+		}`))
+	}))
+	defer server.Close()
+
+	provider := newTestProvider(t, server.URL, "")
+	resp, err := provider.SendCompletion(context.Background(), &providers.CompletionRequest{
+		Model: testModel,
+		Messages: []providers.Message{
+			{Role: providers.RoleSystem, Content: "Be brief."},
+			{Role: providers.RoleUser, Content: "Weather in Paris and Rome?"},
+			{Role: providers.RoleAssistant, ToolCalls: []providers.ToolCall{
+				{ID: "call_1", Type: "function", Function: providers.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+				{ID: "call_2", Type: "function", Function: providers.FunctionCall{Name: "get_weather", Arguments: `{"city":"Rome"}`}},
+			}},
+			{Role: providers.RoleTool, ToolCallID: "call_1", Content: "sunny"},
+			{Role: providers.RoleTool, ToolCallID: "call_2", Content: "rainy"},
+		},
+		MaxTokens:  256,
+		Tools:      []providers.Tool{{Type: "function", Function: providers.FunctionDefinition{Name: "get_weather"}}},
+		ToolChoice: "required",
+	})
+	if err != nil {
+		t.Fatalf("SendCompletion failed: %v", err)
+	}
+
+	// Verify request
+	if len(body.System) != 1 || body.System[0].Text != "Be brief." {
+		t.Errorf("system = %+v, want the system message", body.System)
+	}
+	if len(body.Messages) != 3 || len(body.Messages[1].Content) != 2 || len(body.Messages[2].Content) != 2 {
+		t.Fatalf("messages = %+v, want the tool calls and results merged", body.Messages)
+	}
+	if result := body.Messages[2].Content[1].ToolResult; body.Messages[2].Role != "user" || result == nil || result.ToolUseID != "call_2" {
+		t.Errorf("last message = %+v, want the tool results of a user message", body.Messages[2])
+	}
+	if body.InferenceConfig == nil || body.InferenceConfig.MaxTokens != 256 {
+		t.Errorf("inferenceConfig = %+v, want maxTokens 256", body.InferenceConfig)
+	}
+	if body.ToolConfig == nil || body.ToolConfig.Tools[0].ToolSpec.InputSchema.JSON["type"] != "object" || body.ToolConfig.ToolChoice["any"] == nil {
+		t.Errorf("toolConfig = %+v, want the tool with a default schema and the any choice", body.ToolConfig)
+	}
+
+	// Verify response
+	if resp.ID != "req-123" || resp.Model != testModel || resp.Content != "Checking the weather." {
+		t.Errorf("response = %+v, want the text content", resp)
+	}
+	if resp.FinishReason != providers.FinishReasonToolCalls || len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Function.Arguments != `{"city": "Paris"}` {
+		t.Errorf("tool calls = %+v (%s), want the get_weather call", resp.ToolCalls, resp.FinishReason)
+	}
+	if resp.Usage.PromptTokens != 25 || resp.Usage.TotalTokens != 35 || resp.Usage.CachedPromptTokens != 5 {
+		t.Errorf("usage = %+v, want the cached tokens counted", resp.Usage)
+	}
+}
+
+// collect reads all chunks of a stream.
+func collect(t *testing.T, chunks <-chan *providers.StreamChunk) ([]*providers.StreamChunk, string) {
+	t.Helper()
+	var all []*providers.StreamChunk
+	var text strings.Builder
+	for chunk := range chunks {
+		all = append(all, chunk)
+		text.WriteString(chunk.Delta)
+	}
+	return all, text.String()
+}
+
+func TestBedrockProvider_ConverseStream(t *testing.T) {
+	var body ConverseRequest
+	server := httptest.NewServer(bedrockHandler(t, "/model/anthropic.claude-3-haiku-20240307-v1%3A0/converse-stream", &body, func(w http.ResponseWriter) {
+		for _, message := range [][]byte{
+			event("messageStart", `{"role":"assistant","p":"abc"}`),
+			event("contentBlockDelta", `{"delta":{"text":"Hel"},"contentBlockIndex":0}`),
+			event("contentBlockDelta", `{"delta":{"text":"lo"},"contentBlockIndex":0}`),
+			event("contentBlockStop", `{"contentBlockIndex":0}`),
+			event("contentBlockStart", `{"start":{"toolUse":{"toolUseId":"tooluse_1","name":"get_weather"}},"contentBlockIndex":1}`),
+			event("contentBlockDelta", `{"delta":{"toolUse":{"input":"{\"city\":"}},"contentBlockIndex":1}`),
+			event("contentBlockStop", `{"contentBlockIndex":1}`),
+			event("messageStop", `{"stopReason":"tool_use"}`),
+			event("metadata", `{"usage":{"inputTokens":12,"outputTokens":8,"totalTokens":20},"metrics":{"latencyMs":300}}`),
+		} {
+			_, _ = w.Write(message)
+		}
+	}))
+	defer server.Close()
+
+	provider := newTestProvider(t, server.URL, APIConverse)
+	chunks, err := provider.StreamCompletion(context.Background(), &providers.CompletionRequest{
+		Model:    testModel,
+		Messages: []providers.Message{{Role: providers.RoleUser, Content: "Hello"}},
+		Stream:   true,
+	})
+	if err != nil {
+		t.Fatalf("StreamCompletion failed: %v", err)
+	}
+
+	all, text := collect(t, chunks)
+	if text != "Hello" || len(all) != 5 {
+		t.Fatalf("stream = %q in %d chunks, want Hello in 5", text, len(all))
+	}
+	if call := all[2].ToolCalls; len(call) != 1 || call[0].ID != "tooluse_1" || call[0].Function.Name != "get_weather" {
+		t.Errorf("tool call start = %+v", call)
+	}
+	if call := all[3].ToolCalls; len(call) != 1 || call[0].ID != "tooluse_1" || call[0].Function.Arguments != `{"city":` {
+		t.Errorf("tool call delta = %+v", call)
+	}
+	final := all[4]
+	if final.FinishReason != providers.FinishReasonToolCalls || final.Usage == nil || final.Usage.TotalTokens != 20 {
+		t.Errorf("final chunk = %+v, want the finish reason and usage", final)
+	}
+	if final.ID != "req-123" || final.Model != testModel {
+		t.Errorf("final chunk ID = %q, model = %q", final.ID, final.Model)
+	}
+}
+
+func TestBedrockProvider_StreamException(t *testing.T) {
+	server := httptest.NewServer(bedrockHandler(t, "/model/anthropic.claude-3-haiku-20240307-v1%3A0/converse-stream", &ConverseRequest{}, func(w http.ResponseWriter) {
+		_, _ = w.Write(event("contentBlockDelta", `{"delta":{"text":"Hel"},"contentBlockIndex":0}`))
+		_, _ = w.Write(encodeEvent(map[string]string{
+			":message-type":   "exception",
+			":exception-type": "throttlingException",
+		}, []byte(`{"message":"Too many requests"}`)))
+	}))
+	defer server.Close()
+
+	provider := newTestProvider(t, server.URL, APIConverse)
+	chunks, err := provider.StreamCompletion(context.Background(), &providers.CompletionRequest{
+		Model:    testModel,
+		Messages: []providers.Message{{Role: providers.RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("StreamCompletion failed: %v", err)
+	}
+
+	all, _ := collect(t, chunks)
+	var streamErr *providers.StreamError
+	if len(all) != 2 || !errors.As(all[1].Error, &streamErr) || !strings.Contains(streamErr.Message, "throttlingException: Too many requests") {
+		t.Errorf("chunks = %+v, want the exception after the text", all)
+	}
+}
+
+func TestBedrockProvider_Invoke(t *testing.T) {
+	tests := []struct {
+		name      string
+		model     string
+		response  string
+		checkBody func(t *testing.T, body map[string]interface{})
+		want      providers.CompletionResponse
+	}{
+		{
+			name:     "claude",
+			model:    testModel,
+			response: `{"id":"msg_1","type":"message","content":[{"type":"text","text":"Hi!"}],"stop_reason":"end_turn","usage":{"input_tokens":10,"output_tokens":3}}`,
+			checkBody: func(t *testing.T, body map[string]interface{}) {
+				if body["anthropic_version"] != claudeVersion || body["system"] != "Be brief." || body["max_tokens"] != float64(4096) {
+					t.Errorf("body = %v, want the Claude format", body)
+				}
+			},
+			want: providers.CompletionResponse{ID: "msg_1", Content: "Hi!", FinishReason: "stop", Usage: providers.TokenUsage{PromptTokens: 10, CompletionTokens: 3, TotalTokens: 13}},
+		},
+		{
+			name:     "llama",
+			model:    "us.meta.llama3-1-8b-instruct-v1:0",
+			response: `{"generation":"Hi!","prompt_token_count":10,"generation_token_count":3,"stop_reason":"stop"}`,
+			checkBody: func(t *testing.T, body map[string]interface{}) {
+				want := "<|begin_of_text|><|start_header_id|>system<|end_header_id|>\n\nBe brief.<|eot_id|>" +
+					"<|start_header_id|>user<|end_header_id|>\n\nHello<|eot_id|><|start_header_id|>assistant<|end_header_id|>\n\n"
+				if body["prompt"] != want {
+					t.Errorf("prompt = %q, want the Llama 3 template", body["prompt"])
+				}
+			},
+			want: providers.CompletionResponse{ID: "req-123", Content: "Hi!", FinishReason: "stop", Usage: providers.TokenUsage{PromptTokens: 10, CompletionTokens: 3, TotalTokens: 13}},
+		},
+		{
+			name:     "titan",
+			model:    "amazon.titan-text-express-v1",
+			response: `{"inputTextTokenCount":10,"results":[{"tokenCount":3,"outputText":"Hi!","completionReason":"LENGTH"}]}`,
+			checkBody: func(t *testing.T, body map[string]interface{}) {
+				if body["inputText"] != "Be brief.\n\nUser: Hello\nBot:" {
+					t.Errorf("inputText = %q, want the User/Bot prompt", body["inputText"])
+				}
+			},
+			want: providers.CompletionResponse{ID: "req-123", Content: "Hi!", FinishReason: "length", Usage: providers.TokenUsage{PromptTokens: 10, CompletionTokens: 3, TotalTokens: 13}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body map[string]interface{}
+			path := "/model/" + strings.ReplaceAll(tt.model, ":", "%3A") + "/invoke"
+			server := httptest.NewServer(bedrockHandler(t, path, &body, func(w http.ResponseWriter) {
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			provider := newTestProvider(t, server.URL, APIInvoke)
+			resp, err := provider.SendCompletion(context.Background(), &providers.CompletionRequest{
+				Model: tt.model,
+				Messages: []providers.Message{
+					{Role: providers.RoleSystem, Content: "Be brief."},
+					{Role: providers.RoleUser, Content: "Hello"},
+				},
+			})
+			if err != nil {
+				t.Fatalf("SendCompletion failed: %v", err)
+			}
+			tt.checkBody(t, body)
+
+			if resp.ID != tt.want.ID || resp.Content != tt.want.Content || resp.FinishReason != tt.want.FinishReason || resp.Usage != tt.want.Usage {
+				t.Errorf("response = %+v, want %+v", resp, tt.want)
+			}
+		})
+	}
+}
+
+// chunkEvent encodes an InvokeModelWithResponseStream chunk event.
+func chunkEvent(payload string) []byte {
+	data, _ := json.Marshal(map[string]string{"bytes": base64.StdEncoding.EncodeToString([]byte(payload))})
+	return event("chunk", string(data))
+}
+
+func TestBedrockProvider_InvokeStream(t *testing.T) {
+	tests := []struct {
+		name   string
+		model  string
+		events []string
+		finish string
+	}{
+		{
+			name:  "claude",
+			model: testModel,
+			events: []string{
+				`{"type":"message_start","message":{"id":"msg_1","usage":{"input_tokens":10}}}`,
+				`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+				`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hel"}}`,
+				`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"lo"}}`,
+				`{"type":"content_block_stop","index":0}`,
+				`{"type":"message_delta","delta":{"stop_reason":"max_tokens"},"usage":{"output_tokens":3}}`,
+				`{"type":"message_stop","amazon-bedrock-invocationMetrics":{"inputTokenCount":10,"outputTokenCount":3,"invocationLatency":300,"firstByteLatency":100}}`,
+			},
+			finish: providers.FinishReasonLength,
+		},
+		{
+			name:  "llama",
+			model: "meta.llama3-8b-instruct-v1:0",
+			events: []string{
+				`{"generation":"Hel","prompt_token_count":10,"generation_token_count":1,"stop_reason":null}`,
+				`{"generation":"lo","prompt_token_count":null,"generation_token_count":3,"stop_reason":"stop","amazon-bedrock-invocationMetrics":{"inputTokenCount":10,"outputTokenCount":3}}`,
+			},
+			finish: providers.FinishReasonStop,
+		},
+		{
+			name:  "titan",
+			model: "amazon.titan-text-lite-v1",
+			events: []string{
+				`{"outputText":"Hel","index":0,"totalOutputTextTokenCount":1,"completionReason":null,"inputTextTokenCount":10}`,
+				`{"outputText":"lo","index":0,"totalOutputTextTokenCount":3,"completionReason":"FINISH","amazon-bedrock-invocationMetrics":{"inputTokenCount":10,"outputTokenCount":3}}`,
+			},
+			finish: providers.FinishReasonStop,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body map[string]interface{}
+			path := "/model/" + strings.ReplaceAll(tt.model, ":", "%3A") + "/invoke-with-response-stream"
+			server := httptest.NewServer(bedrockHandler(t, path, &body, func(w http.ResponseWriter) {
+				for _, payload := range tt.events {
+					_, _ = w.Write(chunkEvent(payload))
+				}
+			}))
+			defer server.Close()
+
+			provider := newTestProvider(t, server.URL, APIInvoke)
+			chunks, err := provider.StreamCompletion(context.Background(), &providers.CompletionRequest{
+				Model:    tt.model,
+				Messages: []providers.Message{{Role: providers.RoleUser, Content: "Hello"}},
+				Stream:   true,
+			})
+			if err != nil {
+				t.Fatalf("StreamCompletion failed: %v", err)
+			}
+
+			all, text := collect(t, chunks)
+			if text != "Hello" {
+				t.Errorf("stream = %q, want Hello", text)
+			}
+			final := all[len(all)-1]
+			if final.Error != nil || final.FinishReason != tt.finish || final.Usage == nil || final.Usage.TotalTokens != 13 {
+				t.Errorf("final chunk = %+v, want finish reason %q and usage", final, tt.finish)
+			}
+		})
+	}
+}
+
+func TestBedrockProvider_InvokeUnsupported(t *testing.T) {
+	provider := newTestProvider(t, "http://127.0.0.1:1", APIInvoke)
+
+	var validationErr *providers.ValidationError
+	_, err := provider.SendCompletion(context.Background(), &providers.CompletionRequest{
+		Model:    "mistral.mistral-large-2402-v1:0",
+		Messages: []providers.Message{{Role: providers.RoleUser, Content: "Hello"}},
+	})
+	if !errors.As(err, &validationErr) || validationErr.Field != "model" {
+		t.Errorf("SendCompletion() error = %v, want a model validation error", err)
+	}
+
+	_, err = provider.SendCompletion(context.Background(), &providers.CompletionRequest{
+		Model:    "amazon.titan-text-express-v1",
+		Messages: []providers.Message{{Role: providers.RoleUser, Content: "Hello"}},
+		Tools:    []providers.Tool{{Type: "function", Function: providers.FunctionDefinition{Name: "get_weather"}}},
+	})
+	if !errors.As(err, &validationErr) || validationErr.Field != "tools" {
+		t.Errorf("SendCompletion() error = %v, want a tools validation error", err)
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+
+	var configErr *providers.ConfigError
+	if _, err := NewProvider(providers.ProviderConfig{Name: "bedrock"}); !errors.As(err, &configErr) || configErr.Field != "region" {
+		t.Errorf("NewProvider() without a region error = %v, want a region error", err)
+	}
+	if _, err := NewProvider(providers.ProviderConfig{Name: "bedrock", Region: "us-east-1", API: "chat"}); !errors.As(err, &configErr) || configErr.Field != "api" {
+		t.Errorf("NewProvider() with an invalid API error = %v, want an api error", err)
+	}
+
+	t.Setenv("AWS_REGION", "eu-west-1")
+	provider, err := NewProvider(providers.ProviderConfig{Name: "bedrock"})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	if config := provider.GetConfig(); config.BaseURL != "https://bedrock-runtime.eu-west-1.amazonaws.com" || config.API != APIConverse {
+		t.Errorf("config = %+v, want the regional endpoint and the converse API", config)
+	}
+}
+
+func TestModelFamily(t *testing.T) {
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{"anthropic.claude-3-5-sonnet-20240620-v1:0", familyClaude},
+		{"eu.anthropic.claude-3-5-sonnet-20240620-v1:0", familyClaude},
+		{"arn:aws:bedrock:us-east-1::foundation-model/anthropic.claude-v2", familyClaude},
+		{"meta.llama3-70b-instruct-v1:0", familyLlama},
+		{"amazon.titan-text-premier-v1:0", familyTitan},
+		{"amazon.titan-embed-text-v1", ""},
+		{"cohere.command-r-v1:0", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			got, err := modelFamily(tt.model)
+			if got != tt.want || (err != nil) != (tt.want == "") {
+				t.Errorf("modelFamily() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
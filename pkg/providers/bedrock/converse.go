@@ -0,0 +1,405 @@
+package bedrock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"mercator-hq/jupiter/pkg/providers"
+)
+
+// Converse API request/response types
+
+// ConverseRequest represents a Bedrock Converse request. The model is
+// given in the URL.
+type ConverseRequest struct {
+	Messages        []ConverseMessage `json:"messages"`
+	System          []ContentBlock    `json:"system,omitempty"`
+	InferenceConfig *InferenceConfig  `json:"inferenceConfig,omitempty"`
+	ToolConfig      *ToolConfig       `json:"toolConfig,omitempty"`
+}
+
+// ConverseMessage represents a message in Converse format.
+type ConverseMessage struct {
+	Role    string         `json:"role"`
+	Content []ContentBlock `json:"content"`
+}
+
+// ContentBlock represents a content block in Converse format. Exactly one
+// field is set.
+type ContentBlock struct {
+	Text       string           `json:"text,omitempty"`
+	ToolUse    *ToolUseBlock    `json:"toolUse,omitempty"`
+	ToolResult *ToolResultBlock `json:"toolResult,omitempty"`
+}
+
+// ToolUseBlock represents a tool call by the model.
+type ToolUseBlock struct {
+	ToolUseID string          `json:"toolUseId"`
+	Name      string          `json:"name"`
+	Input     json.RawMessage `json:"input"`
+}
+
+// ToolResultBlock represents the result of a tool call.
+type ToolResultBlock struct {
+	ToolUseID string         `json:"toolUseId"`
+	Content   []ContentBlock `json:"content"`
+}
+
+// InferenceConfig represents the inference parameters of a Converse
+// request.
+type InferenceConfig struct {
+	MaxTokens     int      `json:"maxTokens,omitempty"`
+	Temperature   float64  `json:"temperature,omitempty"`
+	TopP          float64  `json:"topP,omitempty"`
+	StopSequences []string `json:"stopSequences,omitempty"`
+}
+
+// ToolConfig represents the tools of a Converse request.
+type ToolConfig struct {
+	Tools      []ConverseTool         `json:"tools"`
+	ToolChoice map[string]interface{} `json:"toolChoice,omitempty"`
+}
+
+// ConverseTool represents a tool definition in Converse format.
+type ConverseTool struct {
+	ToolSpec ToolSpec `json:"toolSpec"`
+}
+
+// ToolSpec represents the specification of a tool.
+type ToolSpec struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema ToolInputSchema `json:"inputSchema"`
+}
+
+// ToolInputSchema wraps the JSON Schema of the input of a tool.
+type ToolInputSchema struct {
+	JSON map[string]interface{} `json:"json"`
+}
+
+// ConverseResponse represents a Bedrock Converse response.
+type ConverseResponse struct {
+	Output struct {
+		Message ConverseMessage `json:"message"`
+	} `json:"output"`
+	StopReason string        `json:"stopReason"`
+	Usage      ConverseUsage `json:"usage"`
+}
+
+// ConverseUsage represents token usage in Converse format.
+type ConverseUsage struct {
+	InputTokens           int `json:"inputTokens"`
+	OutputTokens          int `json:"outputTokens"`
+	TotalTokens           int `json:"totalTokens"`
+	CacheReadInputTokens  int `json:"cacheReadInputTokens,omitempty"`
+	CacheWriteInputTokens int `json:"cacheWriteInputTokens,omitempty"`
+}
+
+// tokenUsage transforms Converse token usage to provider-agnostic format.
+// The input tokens read from and written to the prompt cache are counted
+// separately from the other input tokens.
+func (u *ConverseUsage) tokenUsage() *providers.TokenUsage {
+	promptTokens := u.InputTokens + u.CacheReadInputTokens + u.CacheWriteInputTokens
+	return &providers.TokenUsage{
+		PromptTokens:       promptTokens,
+		CompletionTokens:   u.OutputTokens,
+		TotalTokens:        promptTokens + u.OutputTokens,
+		CachedPromptTokens: u.CacheReadInputTokens,
+	}
+}
+
+// Converse stream event types, named by the :event-type header
+
+// converseStreamEvent represents the payload of an event of a
+// ConverseStream response. The fields set depend on the event type.
+type converseStreamEvent struct {
+	ContentBlockIndex int `json:"contentBlockIndex"`
+
+	// For contentBlockStart events
+	Start *struct {
+		ToolUse *struct {
+			ToolUseID string `json:"toolUseId"`
+			Name      string `json:"name"`
+		} `json:"toolUse,omitempty"`
+	} `json:"start,omitempty"`
+
+	// For contentBlockDelta events
+	Delta *struct {
+		Text    string `json:"text,omitempty"`
+		ToolUse *struct {
+			Input string `json:"input"`
+		} `json:"toolUse,omitempty"`
+	} `json:"delta,omitempty"`
+
+	// For messageStop events
+	StopReason string `json:"stopReason,omitempty"`
+
+	// For metadata events
+	Usage *ConverseUsage `json:"usage,omitempty"`
+}
+
+// converse sends a Converse request.
+func (p *Provider) converse(ctx context.Context, req *providers.CompletionRequest) (*providers.CompletionResponse, error) {
+	converseReq, err := transformConverseRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var converseResp ConverseResponse
+	requestID, err := p.postJSON(ctx, p.modelURL(req.Model, "converse"), converseReq, &converseResp)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := transformConverseResponse(&converseResp)
+	if err != nil {
+		return nil, &providers.ParseError{
+			Provider: p.GetName(),
+			Cause:    err,
+		}
+	}
+	resp.ID = requestID
+	resp.Model = req.Model
+
+	slog.Debug("completion request succeeded",
+		"provider", p.GetName(),
+		"model", resp.Model,
+		"tokens", resp.Usage.TotalTokens,
+	)
+
+	return resp, nil
+}
+
+// converseStream sends a ConverseStream request.
+func (p *Provider) converseStream(ctx context.Context, req *providers.CompletionRequest) (*streamReader, error) {
+	converseReq, err := transformConverseRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	return newStreamReader(ctx, p.HTTPProvider, p.modelURL(req.Model, "converse-stream"), req.Model, converseReq, transformConverseStreamEvent)
+}
+
+// transformConverseRequest transforms a provider-agnostic request to
+// Converse format.
+func transformConverseRequest(req *providers.CompletionRequest) (*ConverseRequest, error) {
+	converseReq := &ConverseRequest{
+		Messages: make([]ConverseMessage, 0, len(req.Messages)),
+	}
+
+	if req.MaxTokens != 0 || req.Temperature != 0 || req.TopP != 0 || len(req.Stop) > 0 {
+		converseReq.InferenceConfig = &InferenceConfig{
+			MaxTokens:     req.MaxTokens,
+			Temperature:   req.Temperature,
+			TopP:          req.TopP,
+			StopSequences: req.Stop,
+		}
+	}
+
+	for _, msg := range req.Messages {
+		var role string
+		var content []ContentBlock
+
+		switch msg.Role {
+		case providers.RoleSystem:
+			converseReq.System = append(converseReq.System, ContentBlock{Text: msg.Content})
+			continue
+
+		case providers.RoleTool:
+			// Tool results are content blocks of a user message
+			role = providers.RoleUser
+			content = []ContentBlock{{ToolResult: &ToolResultBlock{
+				ToolUseID: msg.ToolCallID,
+				Content:   []ContentBlock{{Text: msg.Content}},
+			}}}
+
+		case providers.RoleUser, providers.RoleAssistant:
+			role = msg.Role
+			if msg.Content != "" {
+				content = append(content, ContentBlock{Text: msg.Content})
+			}
+			for _, call := range msg.ToolCalls {
+				input := json.RawMessage(call.Function.Arguments)
+				if len(input) == 0 {
+					input = json.RawMessage("{}")
+				}
+				if !json.Valid(input) {
+					return nil, &providers.ValidationError{
+						Field:   "messages",
+						Message: fmt.Sprintf("arguments of tool call %q are not valid JSON", call.ID),
+					}
+				}
+				content = append(content, ContentBlock{ToolUse: &ToolUseBlock{
+					ToolUseID: call.ID,
+					Name:      call.Function.Name,
+					Input:     input,
+				}})
+			}
+
+		default:
+			return nil, &providers.ValidationError{
+				Field:   "messages",
+				Message: fmt.Sprintf("unsupported message role %q", msg.Role),
+			}
+		}
+
+		// Consecutive messages of a role, such as the results of parallel
+		// tool calls, are merged into one
+		if n := len(converseReq.Messages); n > 0 && converseReq.Messages[n-1].Role == role {
+			converseReq.Messages[n-1].Content = append(converseReq.Messages[n-1].Content, content...)
+			continue
+		}
+		converseReq.Messages = append(converseReq.Messages, ConverseMessage{Role: role, Content: content})
+	}
+
+	// Transform tools
+	if len(req.Tools) > 0 {
+		converseReq.ToolConfig = &ToolConfig{
+			Tools:      make([]ConverseTool, len(req.Tools)),
+			ToolChoice: transformToolChoice(req.ToolChoice),
+		}
+		for i, tool := range req.Tools {
+			schema := tool.Function.Parameters
+			if schema == nil {
+				schema = map[string]interface{}{"type": "object"}
+			}
+			converseReq.ToolConfig.Tools[i] = ConverseTool{ToolSpec: ToolSpec{
+				Name:        tool.Function.Name,
+				Description: tool.Function.Description,
+				InputSchema: ToolInputSchema{JSON: schema},
+			}}
+		}
+	}
+
+	return converseReq, nil
+}
+
+// transformToolChoice transforms an OpenAI tool choice ("auto",
+// "required", or a named function) to Converse format. Other choices leave
+// the choice to the model.
+func transformToolChoice(choice interface{}) map[string]interface{} {
+	switch choice := choice.(type) {
+	case string:
+		switch choice {
+		case "auto":
+			return map[string]interface{}{"auto": map[string]interface{}{}}
+		case "required":
+			return map[string]interface{}{"any": map[string]interface{}{}}
+		}
+	case map[string]interface{}:
+		if function, ok := choice["function"].(map[string]interface{}); ok {
+			if name, ok := function["name"].(string); ok {
+				return map[string]interface{}{"tool": map[string]interface{}{"name": name}}
+			}
+		}
+	}
+	return nil
+}
+
+// transformConverseResponse transforms a Converse response to
+// provider-agnostic format.
+func transformConverseResponse(resp *ConverseResponse) (*providers.CompletionResponse, error) {
+	var content string
+	var toolCalls []providers.ToolCall
+
+	for _, block := range resp.Output.Message.Content {
+		if block.ToolUse != nil {
+			toolCalls = append(toolCalls, providers.ToolCall{
+				ID:   block.ToolUse.ToolUseID,
+				Type: providers.ToolTypeFunction,
+				Function: providers.FunctionCall{
+					Name:      block.ToolUse.Name,
+					Arguments: string(block.ToolUse.Input),
+				},
+			})
+			continue
+		}
+		content += block.Text
+	}
+
+	return &providers.CompletionResponse{
+		Content:      content,
+		FinishReason: normalizeStopReason(resp.StopReason),
+		Usage:        *resp.Usage.tokenUsage(),
+		ToolCalls:    toolCalls,
+		Created:      time.Now().Unix(),
+		Metadata:     make(map[string]string),
+	}, nil
+}
+
+// transformConverseStreamEvent transforms a ConverseStream event to a
+// stream chunk.
+func transformConverseStreamEvent(message *eventMessage, state *streamState) (*providers.StreamChunk, error) {
+	var event converseStreamEvent
+	if err := json.Unmarshal(message.Payload, &event); err != nil {
+		return nil, fmt.Errorf("failed to parse %s event: %w", message.eventType(), err)
+	}
+
+	switch message.eventType() {
+	case "contentBlockStart":
+		if event.Start == nil || event.Start.ToolUse == nil {
+			return nil, nil
+		}
+		// Start of a tool call
+		state.toolCallIDs[event.ContentBlockIndex] = event.Start.ToolUse.ToolUseID
+		return &providers.StreamChunk{ToolCalls: []providers.ToolCall{{
+			ID:   event.Start.ToolUse.ToolUseID,
+			Type: providers.ToolTypeFunction,
+			Function: providers.FunctionCall{
+				Name: event.Start.ToolUse.Name,
+			},
+		}}}, nil
+
+	case "contentBlockDelta":
+		if event.Delta == nil {
+			return nil, nil
+		}
+		if event.Delta.ToolUse != nil {
+			// Incremental tool call arguments
+			return &providers.StreamChunk{ToolCalls: []providers.ToolCall{{
+				ID:   state.toolCallIDs[event.ContentBlockIndex],
+				Type: providers.ToolTypeFunction,
+				Function: providers.FunctionCall{
+					Arguments: event.Delta.ToolUse.Input,
+				},
+			}}}, nil
+		}
+		if event.Delta.Text == "" {
+			return nil, nil
+		}
+		return &providers.StreamChunk{Delta: event.Delta.Text}, nil
+
+	case "messageStop":
+		state.finishReason = normalizeStopReason(event.StopReason)
+		return nil, nil
+
+	case "metadata":
+		if event.Usage != nil {
+			state.usage = event.Usage.tokenUsage()
+		}
+		return nil, nil
+
+	default:
+		// messageStart, contentBlockStop
+		return nil, nil
+	}
+}
+
+// normalizeStopReason normalizes Bedrock stop reasons to provider-agnostic
+// values. The Converse API and Claude share them.
+func normalizeStopReason(reason string) string {
+	switch reason {
+	case "end_turn", "stop_sequence":
+		return providers.FinishReasonStop
+	case "max_tokens":
+		return providers.FinishReasonLength
+	case "tool_use":
+		return providers.FinishReasonToolCalls
+	case "guardrail_intervened", "content_filtered":
+		return providers.FinishReasonContentFilter
+	default:
+		return reason
+	}
+}
@@ -0,0 +1,88 @@
+// Package bedrock implements the AWS Bedrock provider adapter.
+//
+// This package provides an implementation of the providers.Provider interface
+// for the Bedrock Runtime API. It supports:
+//
+//   - Converse API (every Bedrock chat model, with tool calling)
+//   - InvokeModel API (Claude, Llama, and Titan Text native request formats)
+//   - Streaming responses (AWS event stream encoding)
+//   - AWS Signature Version 4 request signing
+//   - Token usage tracking
+//
+// # Basic Usage
+//
+//	config := providers.ProviderConfig{
+//	    Name:   "bedrock",
+//	    Type:   "bedrock",
+//	    Region: "us-east-1",
+//	}
+//
+//	provider, err := bedrock.NewProvider(config)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer provider.Close()
+//
+//	req := &providers.CompletionRequest{
+//	    Model: "anthropic.claude-3-5-haiku-20241022-v1:0",
+//	    Messages: []providers.Message{
+//	        {Role: "user", Content: "Hello!"},
+//	    },
+//	}
+//
+//	resp, err := provider.SendCompletion(context.Background(), req)
+//
+// Model IDs, cross-region inference profile IDs (e.g.
+// "us.anthropic.claude-3-5-haiku-20241022-v1:0"), and model ARNs are sent
+// as given.
+//
+// # Authentication
+//
+// Requests are signed with AWS Signature Version 4 for the "bedrock"
+// service. Credentials are resolved from the standard AWS credential chain
+// (see sigv4.Chain): environment variables, web identity federation, the
+// shared credentials file, ECS task roles, and EC2 instance profiles.
+// Temporary credentials are refreshed before they expire. The API key of
+// the provider configuration is not used.
+//
+// The region is taken from the configuration, or the AWS_REGION or
+// AWS_DEFAULT_REGION environment variables. The base URL defaults to the
+// regional runtime endpoint, https://bedrock-runtime.{region}.amazonaws.com;
+// set it to use a VPC endpoint.
+//
+// # APIs
+//
+// The Converse API ("converse", the default) takes the same request format
+// for every model, and supports system prompts, tool calling, and stop
+// sequences for all of them.
+//
+// The InvokeModel API ("invoke") takes each model family's native format:
+//
+//   - Claude (anthropic.*): Anthropic Messages format, with tool calling
+//   - Llama (meta.llama*): a prompt in the Llama 3 chat template
+//   - Titan Text (amazon.titan-text-*): a User/Bot conversation prompt
+//
+// # Streaming
+//
+// Bedrock streams responses as binary event stream messages rather than
+// Server-Sent Events. The adapter decodes them, verifying their checksums,
+// and normalizes the events of both APIs to stream chunks: text and tool
+// call deltas, then a final chunk with the finish reason and token usage.
+// Exceptions sent mid-stream (e.g. throttlingException) are reported as
+// StreamError chunks.
+//
+// # Error Handling
+//
+// The adapter maps Bedrock errors to common error types:
+//
+//   - 401/403 -> AuthError (including signing failures and missing credentials)
+//   - 429 -> RateLimitError
+//   - 400 -> ProviderError (ValidationException)
+//   - 5xx -> ProviderError (retried automatically)
+//
+// # Health Checks
+//
+// Health checks list the text foundation models of the region, which
+// requires the bedrock:ListFoundationModels permission. With a custom base
+// URL, the base URL is checked instead.
+package bedrock
@@ -0,0 +1,165 @@
+package bedrock
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// Bedrock streams responses in the AWS event stream encoding
+// (application/vnd.amazon.eventstream): a sequence of binary messages, each
+//
+//	total length (uint32) | headers length (uint32) | prelude CRC (uint32)
+//	headers | payload | message CRC (uint32)
+//
+// with big-endian integers and CRC-32 (IEEE) checksums. Each header is a
+// name length (uint8), a name, a value type (uint8), and a value.
+
+const (
+	// preludeLength is the length of the total and headers lengths and the
+	// prelude CRC.
+	preludeLength = 12
+
+	// maxMessageLength bounds the length of a message, so that a corrupt
+	// prelude does not allocate a huge buffer.
+	maxMessageLength = 16 << 20
+)
+
+// Event stream header value types
+const (
+	headerBoolTrue  = 0
+	headerBoolFalse = 1
+	headerByte      = 2
+	headerInt16     = 3
+	headerInt32     = 4
+	headerInt64     = 5
+	headerBytes     = 6
+	headerString    = 7
+	headerTimestamp = 8
+	headerUUID      = 9
+)
+
+// eventMessage is a decoded event stream message. Only string headers are
+// kept, which include the :message-type, :event-type, and :exception-type
+// headers Bedrock sets.
+type eventMessage struct {
+	Headers map[string]string
+	Payload []byte
+}
+
+// messageType returns the :message-type header, "event" or "exception".
+func (m *eventMessage) messageType() string {
+	return m.Headers[":message-type"]
+}
+
+// eventType returns the :event-type header of an event, e.g. "chunk" or
+// "contentBlockDelta".
+func (m *eventMessage) eventType() string {
+	return m.Headers[":event-type"]
+}
+
+// exceptionType returns the :exception-type header of an exception, e.g.
+// "throttlingException".
+func (m *eventMessage) exceptionType() string {
+	return m.Headers[":exception-type"]
+}
+
+// eventDecoder reads event stream messages.
+type eventDecoder struct {
+	r io.Reader
+}
+
+func newEventDecoder(r io.Reader) *eventDecoder {
+	return &eventDecoder{r: r}
+}
+
+// Decode reads the next message. It returns io.EOF at the end of the
+// stream, and io.ErrUnexpectedEOF if the stream ends within a message.
+func (d *eventDecoder) Decode() (*eventMessage, error) {
+	var prelude [preludeLength]byte
+	if _, err := io.ReadFull(d.r, prelude[:]); err != nil {
+		return nil, err
+	}
+
+	totalLength := binary.BigEndian.Uint32(prelude[0:4])
+	headersLength := binary.BigEndian.Uint32(prelude[4:8])
+	if crc := crc32.ChecksumIEEE(prelude[:8]); crc != binary.BigEndian.Uint32(prelude[8:12]) {
+		return nil, fmt.Errorf("event stream prelude checksum mismatch")
+	}
+	if totalLength > maxMessageLength || totalLength < preludeLength+4 ||
+		headersLength > totalLength-preludeLength-4 {
+		return nil, fmt.Errorf("invalid event stream message length %d (headers %d)", totalLength, headersLength)
+	}
+
+	message := make([]byte, totalLength)
+	copy(message, prelude[:])
+	if _, err := io.ReadFull(d.r, message[preludeLength:]); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	end := totalLength - 4
+	if crc := crc32.ChecksumIEEE(message[:end]); crc != binary.BigEndian.Uint32(message[end:]) {
+		return nil, fmt.Errorf("event stream message checksum mismatch")
+	}
+
+	headers, err := decodeHeaders(message[preludeLength : preludeLength+headersLength])
+	if err != nil {
+		return nil, err
+	}
+	return &eventMessage{
+		Headers: headers,
+		Payload: message[preludeLength+headersLength : end],
+	}, nil
+}
+
+// decodeHeaders decodes the headers of a message, keeping string values.
+func decodeHeaders(data []byte) (map[string]string, error) {
+	headers := make(map[string]string)
+	errTruncated := fmt.Errorf("truncated event stream header")
+
+	for len(data) > 0 {
+		nameLength := int(data[0])
+		if len(data) < 1+nameLength+1 {
+			return nil, errTruncated
+		}
+		name := string(data[1 : 1+nameLength])
+		valueType := data[1+nameLength]
+		data = data[2+nameLength:]
+
+		var size int
+		switch valueType {
+		case headerBoolTrue, headerBoolFalse:
+			size = 0
+		case headerByte:
+			size = 1
+		case headerInt16:
+			size = 2
+		case headerInt32:
+			size = 4
+		case headerInt64, headerTimestamp:
+			size = 8
+		case headerUUID:
+			size = 16
+		case headerBytes, headerString:
+			if len(data) < 2 {
+				return nil, errTruncated
+			}
+			size = int(binary.BigEndian.Uint16(data))
+			data = data[2:]
+		default:
+			return nil, fmt.Errorf("unknown event stream header type %d", valueType)
+		}
+		if len(data) < size {
+			return nil, errTruncated
+		}
+		if valueType == headerString {
+			headers[name] = string(data[:size])
+		}
+		data = data[size:]
+	}
+	return headers, nil
+}
@@ -0,0 +1,125 @@
+package bedrock
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"sort"
+	"testing"
+)
+
+// encodeEvent encodes an event stream message with string headers.
+func encodeEvent(headers map[string]string, payload []byte) []byte {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var h bytes.Buffer
+	for _, name := range names {
+		h.WriteByte(byte(len(name)))
+		h.WriteString(name)
+		h.WriteByte(headerString)
+		_ = binary.Write(&h, binary.BigEndian, uint16(len(headers[name])))
+		h.WriteString(headers[name])
+	}
+
+	var m bytes.Buffer
+	_ = binary.Write(&m, binary.BigEndian, uint32(preludeLength+h.Len()+len(payload)+4))
+	_ = binary.Write(&m, binary.BigEndian, uint32(h.Len()))
+	_ = binary.Write(&m, binary.BigEndian, crc32.ChecksumIEEE(m.Bytes()))
+	m.Write(h.Bytes())
+	m.Write(payload)
+	_ = binary.Write(&m, binary.BigEndian, crc32.ChecksumIEEE(m.Bytes()))
+	return m.Bytes()
+}
+
+// event encodes an event message of the given type.
+func event(eventType, payload string) []byte {
+	return encodeEvent(map[string]string{
+		":message-type": "event",
+		":event-type":   eventType,
+		":content-type": "application/json",
+	}, []byte(payload))
+}
+
+func TestEventDecoder(t *testing.T) {
+	var stream bytes.Buffer
+	stream.Write(event("messageStart", `{"role":"assistant"}`))
+	stream.Write(event("contentBlockDelta", `{"delta":{"text":"Hi"},"contentBlockIndex":0}`))
+
+	decoder := newEventDecoder(&stream)
+	message, err := decoder.Decode()
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if message.messageType() != "event" || message.eventType() != "messageStart" || string(message.Payload) != `{"role":"assistant"}` {
+		t.Errorf("Decode() = %+v, want the messageStart event", message)
+	}
+	if message, err = decoder.Decode(); err != nil || message.eventType() != "contentBlockDelta" {
+		t.Fatalf("Decode() = %+v, %v, want the contentBlockDelta event", message, err)
+	}
+	if _, err := decoder.Decode(); err != io.EOF {
+		t.Errorf("Decode() at the end error = %v, want io.EOF", err)
+	}
+}
+
+func TestEventDecoder_NonStringHeaders(t *testing.T) {
+	// A bool, an int32 and a timestamp header around a string header
+	var h bytes.Buffer
+	h.Write([]byte{4, 'f', 'l', 'a', 'g', headerBoolTrue})
+	h.Write([]byte{3, 'i', 'n', 't', headerInt32, 0, 0, 0, 7})
+	h.Write([]byte{11, ':', 'e', 'v', 'e', 'n', 't', '-', 't', 'y', 'p', 'e', headerString, 0, 5, 'c', 'h', 'u', 'n', 'k'})
+	h.Write([]byte{4, 't', 'i', 'm', 'e', headerTimestamp, 0, 0, 0, 0, 0, 0, 0, 1})
+
+	headers, err := decodeHeaders(h.Bytes())
+	if err != nil {
+		t.Fatalf("decodeHeaders() error = %v", err)
+	}
+	if len(headers) != 1 || headers[":event-type"] != "chunk" {
+		t.Errorf("decodeHeaders() = %v, want the string header", headers)
+	}
+
+	if _, err := decodeHeaders(h.Bytes()[:h.Len()-3]); err == nil {
+		t.Error("decodeHeaders() of truncated headers succeeded")
+	}
+}
+
+func TestEventDecoder_Corrupt(t *testing.T) {
+	message := event("chunk", `{"bytes":""}`)
+
+	tests := []struct {
+		name   string
+		stream []byte
+		want   error
+	}{
+		{
+			name:   "prelude checksum",
+			stream: append([]byte{message[0] ^ 1}, message[1:]...),
+		},
+		{
+			name:   "message checksum",
+			stream: append(append([]byte(nil), message[:len(message)-1]...), message[len(message)-1]^1),
+		},
+		{
+			name:   "truncated",
+			stream: message[:len(message)-5],
+			want:   io.ErrUnexpectedEOF,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := newEventDecoder(bytes.NewReader(tt.stream)).Decode()
+			if err == nil {
+				t.Fatal("Decode() succeeded")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("Decode() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,573 @@
+package bedrock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"mercator-hq/jupiter/pkg/providers"
+)
+
+// Model families supported by the InvokeModel API, which takes each
+// family's native request format
+const (
+	familyClaude = "claude"
+	familyLlama  = "llama"
+	familyTitan  = "titan"
+)
+
+// claudeVersion is the Anthropic API version of Claude requests on Bedrock.
+const claudeVersion = "bedrock-2023-05-31"
+
+// inferenceProfilePrefixes are the geographic prefixes of cross-region
+// inference profile IDs, e.g. "us.anthropic.claude-3-5-haiku-20241022-v1:0".
+var inferenceProfilePrefixes = map[string]bool{
+	"us": true, "us-gov": true, "eu": true, "apac": true, "jp": true, "au": true, "ca": true, "global": true,
+}
+
+// modelFamily returns the family of a model ID, inference profile ID, or
+// model ARN.
+func modelFamily(model string) (string, error) {
+	id := model
+	if i := strings.LastIndex(id, "/"); i >= 0 {
+		id = id[i+1:]
+	}
+	if prefix, rest, ok := strings.Cut(id, "."); ok && inferenceProfilePrefixes[prefix] {
+		id = rest
+	}
+
+	switch {
+	case strings.HasPrefix(id, "anthropic."):
+		return familyClaude, nil
+	case strings.HasPrefix(id, "meta.llama"):
+		return familyLlama, nil
+	case strings.HasPrefix(id, "amazon.titan-text"):
+		return familyTitan, nil
+	default:
+		return "", &providers.ValidationError{
+			Field:   "model",
+			Message: fmt.Sprintf("model %q is not supported by the invoke API (supported: Claude, Llama, Titan Text); use the converse API", model),
+		}
+	}
+}
+
+// Claude request/response types (Anthropic Messages format)
+
+// ClaudeRequest represents an InvokeModel request to a Claude model.
+type ClaudeRequest struct {
+	AnthropicVersion string          `json:"anthropic_version"`
+	Messages         []ClaudeMessage `json:"messages"`
+	System           string          `json:"system,omitempty"`
+	MaxTokens        int             `json:"max_tokens"`
+	Temperature      float64         `json:"temperature,omitempty"`
+	TopP             float64         `json:"top_p,omitempty"`
+	StopSequences    []string        `json:"stop_sequences,omitempty"`
+	Tools            []ClaudeTool    `json:"tools,omitempty"`
+}
+
+// ClaudeMessage represents a message in Claude format.
+type ClaudeMessage struct {
+	Role    string        `json:"role"`
+	Content []ClaudeBlock `json:"content"`
+}
+
+// ClaudeBlock represents a content block in Claude format.
+type ClaudeBlock struct {
+	Type string `json:"type"` // "text", "tool_use", or "tool_result"
+	Text string `json:"text,omitempty"`
+
+	// For tool_use blocks
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+
+	// For tool_result blocks
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Content   string `json:"content,omitempty"`
+}
+
+// ClaudeTool represents a tool definition in Claude format.
+type ClaudeTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+}
+
+// ClaudeResponse represents the InvokeModel response of a Claude model.
+type ClaudeResponse struct {
+	ID         string        `json:"id"`
+	Content    []ClaudeBlock `json:"content"`
+	StopReason string        `json:"stop_reason"`
+	Usage      struct {
+		InputTokens              int `json:"input_tokens"`
+		OutputTokens             int `json:"output_tokens"`
+		CacheCreationInputTokens int `json:"cache_creation_input_tokens,omitempty"`
+		CacheReadInputTokens     int `json:"cache_read_input_tokens,omitempty"`
+	} `json:"usage"`
+}
+
+// claudeStreamEvent represents an event of a Claude response stream.
+type claudeStreamEvent struct {
+	Type  string `json:"type"`
+	Index int    `json:"index"`
+
+	// For content_block_start events
+	ContentBlock *ClaudeBlock `json:"content_block,omitempty"`
+
+	// For content_block_delta and message_delta events
+	Delta *struct {
+		Type        string `json:"type"`
+		Text        string `json:"text,omitempty"`
+		PartialJSON string `json:"partial_json,omitempty"`
+		StopReason  string `json:"stop_reason,omitempty"`
+	} `json:"delta,omitempty"`
+}
+
+// Llama request/response types
+
+// LlamaRequest represents an InvokeModel request to a Llama model.
+type LlamaRequest struct {
+	Prompt      string  `json:"prompt"`
+	MaxGenLen   int     `json:"max_gen_len,omitempty"`
+	Temperature float64 `json:"temperature,omitempty"`
+	TopP        float64 `json:"top_p,omitempty"`
+}
+
+// LlamaResponse represents the InvokeModel response of a Llama model, and
+// each chunk of its response stream.
+type LlamaResponse struct {
+	Generation           string `json:"generation"`
+	PromptTokenCount     int    `json:"prompt_token_count"`
+	GenerationTokenCount int    `json:"generation_token_count"`
+	StopReason           string `json:"stop_reason"`
+}
+
+// Titan request/response types
+
+// TitanRequest represents an InvokeModel request to a Titan Text model.
+type TitanRequest struct {
+	InputText            string                 `json:"inputText"`
+	TextGenerationConfig *TitanGenerationConfig `json:"textGenerationConfig,omitempty"`
+}
+
+// TitanGenerationConfig represents the inference parameters of a Titan
+// request.
+type TitanGenerationConfig struct {
+	MaxTokenCount int      `json:"maxTokenCount,omitempty"`
+	Temperature   float64  `json:"temperature,omitempty"`
+	TopP          float64  `json:"topP,omitempty"`
+	StopSequences []string `json:"stopSequences,omitempty"`
+}
+
+// TitanResponse represents the InvokeModel response of a Titan model.
+type TitanResponse struct {
+	InputTextTokenCount int `json:"inputTextTokenCount"`
+	Results             []struct {
+		TokenCount       int    `json:"tokenCount"`
+		OutputText       string `json:"outputText"`
+		CompletionReason string `json:"completionReason"`
+	} `json:"results"`
+}
+
+// titanStreamChunk represents a chunk of a Titan response stream.
+type titanStreamChunk struct {
+	OutputText       string `json:"outputText"`
+	CompletionReason string `json:"completionReason"`
+}
+
+// invocationMetrics are the token counts Bedrock adds to the last chunk of
+// an InvokeModelWithResponseStream response, for every model family.
+type invocationMetrics struct {
+	InputTokenCount  int `json:"inputTokenCount"`
+	OutputTokenCount int `json:"outputTokenCount"`
+}
+
+// invoke sends an InvokeModel request.
+func (p *Provider) invoke(ctx context.Context, req *providers.CompletionRequest) (*providers.CompletionResponse, error) {
+	family, err := modelFamily(req.Model)
+	if err != nil {
+		return nil, err
+	}
+	invokeReq, err := transformInvokeRequest(family, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var invokeResp json.RawMessage
+	requestID, err := p.postJSON(ctx, p.modelURL(req.Model, "invoke"), invokeReq, &invokeResp)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := transformInvokeResponse(family, invokeResp)
+	if err != nil {
+		return nil, &providers.ParseError{
+			Provider:    p.GetName(),
+			RawResponse: string(invokeResp),
+			Cause:       err,
+		}
+	}
+	if resp.ID == "" {
+		resp.ID = requestID
+	}
+	resp.Model = req.Model
+
+	slog.Debug("completion request succeeded",
+		"provider", p.GetName(),
+		"model", resp.Model,
+		"tokens", resp.Usage.TotalTokens,
+	)
+
+	return resp, nil
+}
+
+// invokeStream sends an InvokeModelWithResponseStream request.
+func (p *Provider) invokeStream(ctx context.Context, req *providers.CompletionRequest) (*streamReader, error) {
+	family, err := modelFamily(req.Model)
+	if err != nil {
+		return nil, err
+	}
+	invokeReq, err := transformInvokeRequest(family, req)
+	if err != nil {
+		return nil, err
+	}
+	url := p.modelURL(req.Model, "invoke-with-response-stream")
+	return newStreamReader(ctx, p.HTTPProvider, url, req.Model, invokeReq, invokeStreamTransform(family))
+}
+
+// transformInvokeRequest transforms a provider-agnostic request to the
+// native format of a model family.
+func transformInvokeRequest(family string, req *providers.CompletionRequest) (interface{}, error) {
+	if family != familyClaude && len(req.Tools) > 0 {
+		return nil, &providers.ValidationError{
+			Field:   "tools",
+			Message: "tools are only supported for Claude models by the invoke API; use the converse API",
+		}
+	}
+
+	switch family {
+	case familyClaude:
+		return transformClaudeRequest(req)
+	case familyLlama:
+		return &LlamaRequest{
+			Prompt:      llamaPrompt(req.Messages),
+			MaxGenLen:   req.MaxTokens,
+			Temperature: req.Temperature,
+			TopP:        req.TopP,
+		}, nil
+	default:
+		titanReq := &TitanRequest{InputText: titanPrompt(req.Messages)}
+		if req.MaxTokens != 0 || req.Temperature != 0 || req.TopP != 0 || len(req.Stop) > 0 {
+			titanReq.TextGenerationConfig = &TitanGenerationConfig{
+				MaxTokenCount: req.MaxTokens,
+				Temperature:   req.Temperature,
+				TopP:          req.TopP,
+				StopSequences: req.Stop,
+			}
+		}
+		return titanReq, nil
+	}
+}
+
+// transformClaudeRequest transforms a provider-agnostic request to Claude
+// format.
+func transformClaudeRequest(req *providers.CompletionRequest) (*ClaudeRequest, error) {
+	claudeReq := &ClaudeRequest{
+		AnthropicVersion: claudeVersion,
+		Messages:         make([]ClaudeMessage, 0, len(req.Messages)),
+		MaxTokens:        req.MaxTokens,
+		Temperature:      req.Temperature,
+		TopP:             req.TopP,
+		StopSequences:    req.Stop,
+	}
+
+	// Set default max_tokens if not provided (required by Claude)
+	if claudeReq.MaxTokens == 0 {
+		claudeReq.MaxTokens = 4096
+	}
+
+	var system []string
+	for _, msg := range req.Messages {
+		var role string
+		var content []ClaudeBlock
+
+		switch msg.Role {
+		case providers.RoleSystem:
+			system = append(system, msg.Content)
+			continue
+
+		case providers.RoleTool:
+			role = providers.RoleUser
+			content = []ClaudeBlock{{Type: "tool_result", ToolUseID: msg.ToolCallID, Content: msg.Content}}
+
+		default:
+			role = msg.Role
+			if msg.Content != "" {
+				content = append(content, ClaudeBlock{Type: "text", Text: msg.Content})
+			}
+			for _, call := range msg.ToolCalls {
+				input := json.RawMessage(call.Function.Arguments)
+				if len(input) == 0 {
+					input = json.RawMessage("{}")
+				}
+				if !json.Valid(input) {
+					return nil, &providers.ValidationError{
+						Field:   "messages",
+						Message: fmt.Sprintf("arguments of tool call %q are not valid JSON", call.ID),
+					}
+				}
+				content = append(content, ClaudeBlock{Type: "tool_use", ID: call.ID, Name: call.Function.Name, Input: input})
+			}
+		}
+
+		// Consecutive messages of a role are merged into one
+		if n := len(claudeReq.Messages); n > 0 && claudeReq.Messages[n-1].Role == role {
+			claudeReq.Messages[n-1].Content = append(claudeReq.Messages[n-1].Content, content...)
+			continue
+		}
+		claudeReq.Messages = append(claudeReq.Messages, ClaudeMessage{Role: role, Content: content})
+	}
+	claudeReq.System = strings.Join(system, "\n\n")
+
+	for _, tool := range req.Tools {
+		schema := tool.Function.Parameters
+		if schema == nil {
+			schema = map[string]interface{}{"type": "object"}
+		}
+		claudeReq.Tools = append(claudeReq.Tools, ClaudeTool{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			InputSchema: schema,
+		})
+	}
+
+	return claudeReq, nil
+}
+
+// llamaPrompt formats messages with the Llama 3 chat template.
+func llamaPrompt(messages []providers.Message) string {
+	var b strings.Builder
+	b.WriteString("<|begin_of_text|>")
+	for _, msg := range messages {
+		role := msg.Role
+		if role == providers.RoleTool {
+			role = "ipython"
+		}
+		fmt.Fprintf(&b, "<|start_header_id|>%s<|end_header_id|>\n\n%s<|eot_id|>", role, msg.Content)
+	}
+	b.WriteString("<|start_header_id|>assistant<|end_header_id|>\n\n")
+	return b.String()
+}
+
+// titanPrompt formats messages as the User/Bot conversation Titan Text
+// models are trained on, after the system instructions.
+func titanPrompt(messages []providers.Message) string {
+	var b strings.Builder
+	for _, msg := range messages {
+		switch msg.Role {
+		case providers.RoleSystem:
+			b.WriteString(msg.Content + "\n\n")
+		case providers.RoleAssistant:
+			b.WriteString("Bot: " + msg.Content + "\n")
+		default:
+			b.WriteString("User: " + msg.Content + "\n")
+		}
+	}
+	b.WriteString("Bot:")
+	return b.String()
+}
+
+// transformInvokeResponse transforms the InvokeModel response of a model
+// family to provider-agnostic format.
+func transformInvokeResponse(family string, data []byte) (*providers.CompletionResponse, error) {
+	resp := &providers.CompletionResponse{
+		Created:  time.Now().Unix(),
+		Metadata: make(map[string]string),
+	}
+
+	switch family {
+	case familyClaude:
+		var claudeResp ClaudeResponse
+		if err := json.Unmarshal(data, &claudeResp); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal Claude response: %w", err)
+		}
+		for _, block := range claudeResp.Content {
+			switch block.Type {
+			case "text":
+				resp.Content += block.Text
+			case "tool_use":
+				resp.ToolCalls = append(resp.ToolCalls, providers.ToolCall{
+					ID:   block.ID,
+					Type: providers.ToolTypeFunction,
+					Function: providers.FunctionCall{
+						Name:      block.Name,
+						Arguments: string(block.Input),
+					},
+				})
+			}
+		}
+		resp.ID = claudeResp.ID
+		resp.FinishReason = normalizeStopReason(claudeResp.StopReason)
+		usage := claudeResp.Usage
+		promptTokens := usage.InputTokens + usage.CacheCreationInputTokens + usage.CacheReadInputTokens
+		resp.Usage = providers.TokenUsage{
+			PromptTokens:       promptTokens,
+			CompletionTokens:   usage.OutputTokens,
+			TotalTokens:        promptTokens + usage.OutputTokens,
+			CachedPromptTokens: usage.CacheReadInputTokens,
+		}
+
+	case familyLlama:
+		var llamaResp LlamaResponse
+		if err := json.Unmarshal(data, &llamaResp); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal Llama response: %w", err)
+		}
+		resp.Content = llamaResp.Generation
+		resp.FinishReason = llamaResp.StopReason
+		resp.Usage = *invocationUsage(llamaResp.PromptTokenCount, llamaResp.GenerationTokenCount)
+
+	case familyTitan:
+		var titanResp TitanResponse
+		if err := json.Unmarshal(data, &titanResp); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal Titan response: %w", err)
+		}
+		if len(titanResp.Results) == 0 {
+			return nil, fmt.Errorf("no results in Titan response")
+		}
+		result := titanResp.Results[0]
+		resp.Content = result.OutputText
+		resp.FinishReason = normalizeTitanCompletionReason(result.CompletionReason)
+		resp.Usage = *invocationUsage(titanResp.InputTextTokenCount, result.TokenCount)
+	}
+
+	return resp, nil
+}
+
+// invokeStreamTransform returns the transform of the chunks of a model
+// family's response stream. Each chunk event carries a base64-encoded
+// payload in the model's native format; the last one also carries the
+// invocation metrics.
+func invokeStreamTransform(family string) eventTransform {
+	return func(message *eventMessage, state *streamState) (*providers.StreamChunk, error) {
+		if message.eventType() != "chunk" {
+			return nil, nil
+		}
+		var event struct {
+			Bytes []byte `json:"bytes"`
+		}
+		if err := json.Unmarshal(message.Payload, &event); err != nil {
+			return nil, fmt.Errorf("failed to parse chunk event: %w", err)
+		}
+
+		var metrics struct {
+			Metrics *invocationMetrics `json:"amazon-bedrock-invocationMetrics"`
+		}
+		if err := json.Unmarshal(event.Bytes, &metrics); err != nil {
+			return nil, fmt.Errorf("failed to parse chunk: %w", err)
+		}
+		if metrics.Metrics != nil {
+			state.usage = invocationUsage(metrics.Metrics.InputTokenCount, metrics.Metrics.OutputTokenCount)
+		}
+
+		switch family {
+		case familyClaude:
+			return transformClaudeStreamChunk(event.Bytes, state)
+		case familyLlama:
+			var chunk LlamaResponse
+			if err := json.Unmarshal(event.Bytes, &chunk); err != nil {
+				return nil, fmt.Errorf("failed to parse Llama chunk: %w", err)
+			}
+			state.finishReason = chunk.StopReason
+			return textChunk(chunk.Generation), nil
+		default:
+			var chunk titanStreamChunk
+			if err := json.Unmarshal(event.Bytes, &chunk); err != nil {
+				return nil, fmt.Errorf("failed to parse Titan chunk: %w", err)
+			}
+			state.finishReason = normalizeTitanCompletionReason(chunk.CompletionReason)
+			return textChunk(chunk.OutputText), nil
+		}
+	}
+}
+
+// transformClaudeStreamChunk transforms an event of a Claude response
+// stream to a stream chunk.
+func transformClaudeStreamChunk(data []byte, state *streamState) (*providers.StreamChunk, error) {
+	var event claudeStreamEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, fmt.Errorf("failed to parse Claude chunk: %w", err)
+	}
+
+	switch event.Type {
+	case "content_block_start":
+		if event.ContentBlock == nil || event.ContentBlock.Type != "tool_use" {
+			return nil, nil
+		}
+		// Start of a tool call
+		state.toolCallIDs[event.Index] = event.ContentBlock.ID
+		return &providers.StreamChunk{ToolCalls: []providers.ToolCall{{
+			ID:       event.ContentBlock.ID,
+			Type:     providers.ToolTypeFunction,
+			Function: providers.FunctionCall{Name: event.ContentBlock.Name},
+		}}}, nil
+
+	case "content_block_delta":
+		if event.Delta == nil {
+			return nil, nil
+		}
+		if event.Delta.Type == "input_json_delta" {
+			// Incremental tool call arguments
+			return &providers.StreamChunk{ToolCalls: []providers.ToolCall{{
+				ID:       state.toolCallIDs[event.Index],
+				Type:     providers.ToolTypeFunction,
+				Function: providers.FunctionCall{Arguments: event.Delta.PartialJSON},
+			}}}, nil
+		}
+		return textChunk(event.Delta.Text), nil
+
+	case "message_delta":
+		if event.Delta != nil {
+			state.finishReason = normalizeStopReason(event.Delta.StopReason)
+		}
+		return nil, nil
+
+	default:
+		// message_start, content_block_stop, message_stop, ping
+		return nil, nil
+	}
+}
+
+// textChunk returns a chunk of generated text, or nil if text is empty.
+func textChunk(text string) *providers.StreamChunk {
+	if text == "" {
+		return nil
+	}
+	return &providers.StreamChunk{Delta: text}
+}
+
+// invocationUsage returns the token usage of input and output token counts.
+func invocationUsage(inputTokens, outputTokens int) *providers.TokenUsage {
+	return &providers.TokenUsage{
+		PromptTokens:     inputTokens,
+		CompletionTokens: outputTokens,
+		TotalTokens:      inputTokens + outputTokens,
+	}
+}
+
+// normalizeTitanCompletionReason normalizes Titan completion reasons to
+// provider-agnostic values.
+func normalizeTitanCompletionReason(reason string) string {
+	switch reason {
+	case "FINISH", "STOP_CRITERIA_MET":
+		return providers.FinishReasonStop
+	case "LENGTH":
+		return providers.FinishReasonLength
+	case "CONTENT_FILTERED":
+		return providers.FinishReasonContentFilter
+	default:
+		return strings.ToLower(reason)
+	}
+}
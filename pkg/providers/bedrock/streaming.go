@@ -0,0 +1,185 @@
+package bedrock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"mercator-hq/jupiter/pkg/providers"
+)
+
+// eventTransform transforms an event of a Bedrock event stream to a stream
+// chunk. It returns nil for events that carry no content, and records the
+// finish reason and usage in state, to be sent in the final chunk.
+type eventTransform func(message *eventMessage, state *streamState) (*providers.StreamChunk, error)
+
+// streamState tracks state across stream events.
+type streamState struct {
+	id      string
+	model   string
+	created int64
+
+	// toolCallIDs are the IDs of the tool calls of the content blocks
+	toolCallIDs map[int]string
+
+	// finishReason and usage are sent in the final chunk, once both are
+	// known or the stream ends
+	finishReason string
+	usage        *providers.TokenUsage
+	done         bool
+}
+
+// streamReader reads Bedrock's binary event streams.
+type streamReader struct {
+	provider  *providers.HTTPProvider
+	resp      io.ReadCloser
+	decoder   *eventDecoder
+	transform eventTransform
+	state     *streamState
+	closed    bool
+}
+
+// newStreamReader sends a streaming request and returns a reader of the
+// event stream of the response.
+func newStreamReader(ctx context.Context, provider *providers.HTTPProvider, url, model string, req interface{}, transform eventTransform) (*streamReader, error) {
+	// Marshal request
+	bodyBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Perform request
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"Accept":       "application/vnd.amazon.eventstream",
+	}
+	resp, err := provider.DoRequest(ctx, "POST", url, bodyBytes, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	return &streamReader{
+		provider:  provider,
+		resp:      resp.Body,
+		decoder:   newEventDecoder(resp.Body),
+		transform: transform,
+		state: &streamState{
+			id:          resp.Header.Get(requestIDHeader),
+			model:       model,
+			created:     time.Now().Unix(),
+			toolCallIDs: make(map[int]string),
+		},
+	}, nil
+}
+
+// Read reads the next chunk from the stream.
+// Returns nil, io.EOF when the stream ends normally.
+// Returns nil, error if an error occurs.
+func (s *streamReader) Read(ctx context.Context) (*providers.StreamChunk, error) {
+	if s.closed {
+		return nil, io.EOF
+	}
+
+	for {
+		// Check context cancellation
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		// Read next event stream message
+		message, err := s.decoder.Decode()
+		if err == io.EOF {
+			// Send the finish reason if the usage never came
+			if s.state.finishReason != "" && !s.state.done {
+				s.state.done = true
+				return s.chunk(&providers.StreamChunk{FinishReason: s.state.finishReason}), nil
+			}
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, &providers.StreamError{
+				Provider: s.provider.GetName(),
+				Message:  "failed to read stream",
+				Cause:    err,
+			}
+		}
+
+		if err := s.exception(message); err != nil {
+			return nil, err
+		}
+
+		// Transform event to chunk
+		chunk, err := s.transform(message, s.state)
+		if err != nil {
+			return nil, &providers.ParseError{
+				Provider:    s.provider.GetName(),
+				RawResponse: string(message.Payload),
+				Cause:       err,
+			}
+		}
+
+		// The final chunk carries the finish reason and usage
+		if s.state.finishReason != "" && s.state.usage != nil && !s.state.done {
+			if chunk == nil {
+				chunk = &providers.StreamChunk{}
+			}
+			chunk.FinishReason = s.state.finishReason
+			chunk.Usage = s.state.usage
+			s.state.done = true
+		}
+
+		// Some events don't produce chunks (messageStart, contentBlockStop, etc.)
+		if chunk == nil {
+			continue
+		}
+
+		return s.chunk(chunk), nil
+	}
+}
+
+// chunk sets the response fields of a chunk.
+func (s *streamReader) chunk(chunk *providers.StreamChunk) *providers.StreamChunk {
+	chunk.ID = s.state.id
+	chunk.Model = s.state.model
+	chunk.Created = s.state.created
+	return chunk
+}
+
+// exception returns the error of an exception message, such as a
+// throttlingException or modelStreamErrorException sent mid-stream.
+func (s *streamReader) exception(message *eventMessage) error {
+	messageType := message.messageType()
+	if messageType != "exception" && messageType != "error" {
+		return nil
+	}
+
+	var body struct {
+		Message string `json:"message"`
+	}
+	_ = json.Unmarshal(message.Payload, &body)
+	exceptionType := message.exceptionType()
+	if exceptionType == "" {
+		exceptionType = message.Headers[":error-code"]
+	}
+	if body.Message == "" {
+		body.Message = message.Headers[":error-message"]
+	}
+	return &providers.StreamError{
+		Provider: s.provider.GetName(),
+		Message:  fmt.Sprintf("%s: %s", exceptionType, body.Message),
+	}
+}
+
+// Close closes the stream and releases resources.
+func (s *streamReader) Close() error {
+	if s.closed {
+		return nil
+	}
+
+	s.closed = true
+	return s.resp.Close()
+}
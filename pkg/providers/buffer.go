@@ -0,0 +1,30 @@
+package providers
+
+import (
+	"bytes"
+	"sync"
+)
+
+// maxPooledBufferSize is the capacity above which buffers are not returned
+// to the pool, so that an occasional large chunk does not stay allocated.
+const maxPooledBufferSize = 64 * 1024
+
+// bufferPool holds the byte buffers of stream chunks.
+var bufferPool = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// GetBuffer returns an empty buffer from the pool. Return it with PutBuffer
+// once its content is no longer used.
+func GetBuffer() *bytes.Buffer {
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// PutBuffer resets a buffer and returns it to the pool.
+func PutBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
@@ -75,6 +75,13 @@
 //	healthy := manager.GetHealthyProviders()
 //	fmt.Printf("Healthy providers: %d\n", len(healthy))
 //
+// Providers can serve model names other than their own, such as OpenAI
+// model names or aliases, with ProviderConfig.Models. The manager routes
+// these names to the provider:
+//
+//	// "gpt-4" -> anthropic, "claude-3-5-sonnet-20241022"
+//	provider, model, ok := manager.ResolveModel("gpt-4")
+//
 // # Streaming
 //
 // Stream responses from providers:
@@ -173,6 +180,21 @@
 //	    MaxRetries: 3,  // Retry up to 3 times
 //	}
 //
+// # Tracing
+//
+// Provider requests are traced as "provider.request" client spans, children
+// of the span of the request context, with the latency breakdown of each
+// attempt as child spans: provider.dns, provider.connect,
+// provider.tls_handshake, and provider.wait, the time until the first
+// response byte. The W3C traceparent header of the span is sent to the
+// provider, so provider-side traces join the proxy's.
+//
+// Providers configured with TraceBaggage also send the request ID and
+// tenant set with ContextWithTraceBaggage in a W3C baggage header:
+//
+//	ctx = providers.ContextWithTraceBaggage(ctx, requestID, "team-a")
+//	resp, err := provider.SendCompletion(ctx, req)
+//
 // # Thread Safety
 //
 // All provider implementations and the Manager are thread-safe and can be
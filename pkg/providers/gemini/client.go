@@ -0,0 +1,290 @@
+package gemini
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"mercator-hq/jupiter/pkg/providers"
+)
+
+// Provider is the Gemini API provider adapter.
+// It implements the providers.Provider interface for the generateContent
+// and streamGenerateContent methods of Gemini models. It serves the Gemini
+// API of Google AI Studio, and is embedded by the Vertex AI adapter, which
+// shares its request and response format.
+type Provider struct {
+	*providers.HTTPProvider
+
+	// modelURL returns the URL of a method of a model
+	modelURL ModelURLFunc
+
+	// safetySettings are sent with every request
+	safetySettings []SafetySetting
+}
+
+// ModelURLFunc returns the URL of a method (e.g. "generateContent") of a
+// model.
+type ModelURLFunc func(model, method string) string
+
+const (
+	// defaultBaseURL is the Gemini API endpoint
+	defaultBaseURL = "https://generativelanguage.googleapis.com"
+
+	// apiVersion is the version of the Gemini API
+	apiVersion = "v1beta"
+
+	// apiKeyHeader is the header of the API key
+	apiKeyHeader = "x-goog-api-key"
+)
+
+// NewProvider creates a new Gemini API provider instance. Requests are
+// authorized with the API key of the configuration.
+func NewProvider(config providers.ProviderConfig) (*Provider, error) {
+	// Validate configuration
+	if config.Name == "" {
+		return nil, &providers.ConfigError{
+			Provider: "gemini",
+			Field:    "name",
+			Message:  "provider name is required",
+		}
+	}
+
+	if config.APIKey == "" {
+		return nil, &providers.ConfigError{
+			Provider: config.Name,
+			Field:    "api_key",
+			Message:  "API key is required for Gemini",
+		}
+	}
+
+	if config.BaseURL == "" {
+		config.BaseURL = defaultBaseURL
+	}
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
+
+	// Set defaults if not provided
+	if config.MaxRetries == 0 {
+		config.MaxRetries = 3
+	}
+	if config.MaxIdleConns == 0 {
+		config.MaxIdleConns = 100
+	}
+	if config.MaxIdleConnsPerHost == 0 {
+		config.MaxIdleConnsPerHost = 10
+	}
+
+	// Create base HTTP provider. The API key is sent in its own header
+	// rather than as a bearer token, so that it never appears in URLs.
+	httpProvider := providers.NewHTTPProvider(config)
+	apiKey := config.APIKey
+	httpProvider.SetRequestSigner(func(req *http.Request, body []byte) error {
+		req.Header.Set(apiKeyHeader, apiKey)
+		return nil
+	})
+	httpProvider.SetHealthCheckURL(fmt.Sprintf("%s/%s/models?pageSize=1", config.BaseURL, apiVersion))
+
+	baseURL := config.BaseURL
+	p := NewProviderWithEndpoint(httpProvider, func(model, method string) string {
+		// Models may be given as resource names ("models/gemini-2.0-flash",
+		// "tunedModels/my-model")
+		if !strings.Contains(model, "/") {
+			model = "models/" + url.PathEscape(model)
+		}
+		return fmt.Sprintf("%s/%s/%s:%s", baseURL, apiVersion, model, method)
+	}, config.SafetySettings)
+
+	slog.Info("Gemini provider initialized",
+		"provider", config.Name,
+		"base_url", config.BaseURL,
+	)
+
+	return p, nil
+}
+
+// NewProviderWithEndpoint creates a provider sending requests with
+// httpProvider, which authorizes them, to the model URLs of modelURL. It is
+// used by adapters of other Gemini endpoints, such as Vertex AI.
+func NewProviderWithEndpoint(httpProvider *providers.HTTPProvider, modelURL ModelURLFunc, safetySettings map[string]string) *Provider {
+	return &Provider{
+		HTTPProvider:   httpProvider,
+		modelURL:       modelURL,
+		safetySettings: transformSafetySettings(safetySettings),
+	}
+}
+
+// SendCompletion sends a completion request to Gemini.
+func (p *Provider) SendCompletion(ctx context.Context, req *providers.CompletionRequest) (*providers.CompletionResponse, error) {
+	// Validate request
+	if err := validateRequest(req); err != nil {
+		return nil, err
+	}
+
+	// Transform request to Gemini format
+	geminiReq, err := transformRequest(req, p.safetySettings)
+	if err != nil {
+		return nil, err
+	}
+
+	bodyBytes, err := json.Marshal(geminiReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"Accept":       "application/json",
+	}
+	resp, err := p.DoRequest(ctx, "POST", p.modelURL(req.Model, "generateContent"), bodyBytes, headers)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	responseBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &providers.ParseError{
+			Provider: p.GetName(),
+			Cause:    fmt.Errorf("failed to read response: %w", err),
+		}
+	}
+
+	var geminiResp GenerateContentResponse
+	if err := json.Unmarshal(responseBytes, &geminiResp); err != nil {
+		return nil, &providers.ParseError{
+			Provider:    p.GetName(),
+			RawResponse: string(responseBytes),
+			Cause:       fmt.Errorf("failed to unmarshal response: %w", err),
+		}
+	}
+
+	// Transform response to provider-agnostic format
+	completion := transformResponse(&geminiResp)
+	completion.Model = req.Model
+
+	slog.Debug("completion request succeeded",
+		"provider", p.GetName(),
+		"model", completion.Model,
+		"tokens", completion.Usage.TotalTokens,
+	)
+
+	return completion, nil
+}
+
+// StreamCompletion sends a streaming completion request to Gemini. The
+// Server-Sent Events of the response are normalized to stream chunks.
+func (p *Provider) StreamCompletion(ctx context.Context, req *providers.CompletionRequest) (<-chan *providers.StreamChunk, error) {
+	// Validate request
+	if err := validateRequest(req); err != nil {
+		return nil, err
+	}
+
+	// Transform request to Gemini format
+	geminiReq, err := transformRequest(req, p.safetySettings)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create stream reader
+	stream, err := newStreamReader(ctx, p.HTTPProvider, p.modelURL(req.Model, "streamGenerateContent")+"?alt=sse", req.Model, geminiReq)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create output channel
+	chunks := make(chan *providers.StreamChunk, 100) // Buffered channel
+
+	// Start goroutine to read stream and send chunks
+	go func() {
+		defer close(chunks)
+		defer stream.Close()
+
+		for {
+			chunk, err := stream.Read(ctx)
+			if err == io.EOF {
+				// Stream ended normally
+				return
+			}
+			if err != nil {
+				// Send error chunk and exit
+				select {
+				case chunks <- &providers.StreamChunk{Error: err}:
+				case <-ctx.Done():
+				}
+				return
+			}
+
+			// Send chunk
+			select {
+			case chunks <- chunk:
+			case <-ctx.Done():
+				return
+			}
+
+			// Check if this is the final chunk
+			if chunk.FinishReason != "" {
+				return
+			}
+		}
+	}()
+
+	return chunks, nil
+}
+
+// transformSafetySettings transforms the configured thresholds by harm
+// category to Gemini safety settings, sorted by category.
+func transformSafetySettings(settings map[string]string) []SafetySetting {
+	if len(settings) == 0 {
+		return nil
+	}
+	result := make([]SafetySetting, 0, len(settings))
+	for category, threshold := range settings {
+		result = append(result, SafetySetting{Category: category, Threshold: threshold})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Category < result[j].Category
+	})
+	return result
+}
+
+// newToolCallID returns an ID for a function call. Gemini does not
+// identify function calls, but clients match tool results to calls by ID.
+func newToolCallID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return "call_" + hex.EncodeToString(b)
+}
+
+// validateRequest validates the completion request.
+func validateRequest(req *providers.CompletionRequest) error {
+	if req == nil {
+		return &providers.ValidationError{
+			Field:   "request",
+			Message: "request cannot be nil",
+		}
+	}
+
+	if req.Model == "" {
+		return &providers.ValidationError{
+			Field:   "model",
+			Message: "model is required",
+		}
+	}
+
+	if len(req.Messages) == 0 {
+		return &providers.ValidationError{
+			Field:   "messages",
+			Message: "at least one message is required",
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,315 @@
+package gemini
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mercator-hq/jupiter/pkg/providers"
+)
+
+const testModel = "gemini-2.0-flash"
+
+func newTestProvider(t *testing.T, baseURL string, safetySettings map[string]string) *Provider {
+	t.Helper()
+	provider, err := NewProvider(providers.ProviderConfig{
+		Name:           "gemini",
+		Type:           "gemini",
+		BaseURL:        baseURL,
+		APIKey:         "test-key",
+		SafetySettings: safetySettings,
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	return provider
+}
+
+// geminiHandler checks the API key and path of requests, decodes their
+// body into body, and serves them with serve.
+func geminiHandler(t *testing.T, path string, body interface{}, serve func(w http.ResponseWriter)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if key := r.Header.Get("x-goog-api-key"); key != "test-key" || r.Header.Get("Authorization") != "" {
+			t.Errorf("x-goog-api-key = %q, Authorization = %q, want the API key only", key, r.Header.Get("Authorization"))
+		}
+		if r.RequestURI != path {
+			t.Errorf("request URI = %q, want %q", r.RequestURI, path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(body); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		serve(w)
+	}
+}
+
+func TestGeminiProvider_SendCompletion(t *testing.T) {
+	var body GenerateContentRequest
+	server := httptest.NewServer(geminiHandler(t, "/v1beta/models/gemini-2.0-flash:generateContent", &body, func(w http.ResponseWriter) {
+		_, _ = w.Write([]byte(`{
+			"candidates": [{
+				"content": {"role": "model", "parts": [
+					{"text": "Thinking about it.", "thought": true},
+					{"text": "Checking the weather."},
+					{"functionCall": {"name": "get_weather", "args": {"city": "Paris"}}}
+				]},
+				"finishReason": "STOP"
+			}],
+			"usageMetadata": {"promptTokenCount": 20, "candidatesTokenCount": 10, "thoughtsTokenCount": 4, "totalTokenCount": 34, "cachedContentTokenCount": 8},
+			"modelVersion": "gemini-2.0-flash-001",
+			"responseId": "resp-123"
+		}`))
+	}))
+	defer server.Close()
+
+	provider := newTestProvider(t, server.URL, map[string]string{
+		"HARM_CATEGORY_HATE_SPEECH": "BLOCK_ONLY_HIGH",
+		"HARM_CATEGORY_HARASSMENT":  "BLOCK_NONE",
+	})
+	resp, err := provider.SendCompletion(context.Background(), &providers.CompletionRequest{
+		Model: testModel,
+		Messages: []providers.Message{
+			{Role: providers.RoleSystem, Content: "Be brief."},
+			{Role: providers.RoleUser, Content: "Weather in Paris and Rome?"},
+			{Role: providers.RoleAssistant, ToolCalls: []providers.ToolCall{
+				{ID: "call_1", Type: "function", Function: providers.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+				{ID: "call_2", Type: "function", Function: providers.FunctionCall{Name: "get_forecast", Arguments: `{"city":"Rome"}`}},
+			}},
+			{Role: providers.RoleTool, ToolCallID: "call_1", Content: "sunny"},
+			{Role: providers.RoleTool, ToolCallID: "call_2", Content: `{"forecast": "rain"}`},
+		},
+		MaxTokens:   256,
+		Temperature: 0.5,
+		Tools:       []providers.Tool{{Type: "function", Function: providers.FunctionDefinition{Name: "get_weather"}}},
+		ToolChoice:  map[string]interface{}{"type": "function", "function": map[string]interface{}{"name": "get_weather"}},
+	})
+	if err != nil {
+		t.Fatalf("SendCompletion failed: %v", err)
+	}
+
+	// Verify request
+	if body.SystemInstruction == nil || len(body.SystemInstruction.Parts) != 1 || body.SystemInstruction.Parts[0].Text != "Be brief." {
+		t.Errorf("systemInstruction = %+v, want the system message", body.SystemInstruction)
+	}
+	if len(body.Contents) != 3 || body.Contents[1].Role != "model" || len(body.Contents[1].Parts) != 2 || len(body.Contents[2].Parts) != 2 {
+		t.Fatalf("contents = %+v, want the function calls and responses merged", body.Contents)
+	}
+	first, second := body.Contents[2].Parts[0].FunctionResponse, body.Contents[2].Parts[1].FunctionResponse
+	if body.Contents[2].Role != "user" || first == nil || second == nil {
+		t.Fatalf("last content = %+v, want the function responses of a user message", body.Contents[2])
+	}
+	if first.Name != "get_weather" || string(first.Response) != `{"content":"sunny"}` {
+		t.Errorf("first function response = %s %s, want the wrapped text", first.Name, first.Response)
+	}
+	if second.Name != "get_forecast" || string(second.Response) != `{"forecast":"rain"}` {
+		t.Errorf("second function response = %s %s, want the JSON object", second.Name, second.Response)
+	}
+	if body.GenerationConfig == nil || body.GenerationConfig.MaxOutputTokens != 256 || body.GenerationConfig.Temperature != 0.5 {
+		t.Errorf("generationConfig = %+v, want maxOutputTokens 256 and temperature 0.5", body.GenerationConfig)
+	}
+	if len(body.SafetySettings) != 2 || body.SafetySettings[0].Category != "HARM_CATEGORY_HARASSMENT" || body.SafetySettings[0].Threshold != "BLOCK_NONE" {
+		t.Errorf("safetySettings = %+v, want the configured thresholds sorted by category", body.SafetySettings)
+	}
+	if len(body.Tools) != 1 || body.Tools[0].FunctionDeclarations[0].Name != "get_weather" {
+		t.Errorf("tools = %+v, want the get_weather declaration", body.Tools)
+	}
+	if body.ToolConfig == nil || body.ToolConfig.FunctionCallingConfig.Mode != "ANY" || body.ToolConfig.FunctionCallingConfig.AllowedFunctionNames[0] != "get_weather" {
+		t.Errorf("toolConfig = %+v, want ANY restricted to get_weather", body.ToolConfig)
+	}
+
+	// Verify response
+	if resp.ID != "resp-123" || resp.Model != testModel || resp.Content != "Checking the weather." {
+		t.Errorf("response = %+v, want the text content without thoughts", resp)
+	}
+	if resp.FinishReason != providers.FinishReasonToolCalls || len(resp.ToolCalls) != 1 || resp.ToolCalls[0].Function.Arguments != `{"city": "Paris"}` {
+		t.Errorf("tool calls = %+v (%s), want the get_weather call", resp.ToolCalls, resp.FinishReason)
+	}
+	if !strings.HasPrefix(resp.ToolCalls[0].ID, "call_") {
+		t.Errorf("tool call ID = %q, want a generated ID", resp.ToolCalls[0].ID)
+	}
+	if resp.Usage.PromptTokens != 20 || resp.Usage.CompletionTokens != 14 || resp.Usage.TotalTokens != 34 || resp.Usage.CachedPromptTokens != 8 {
+		t.Errorf("usage = %+v, want the thinking tokens counted as completion tokens", resp.Usage)
+	}
+	if resp.Metadata["model_version"] != "gemini-2.0-flash-001" {
+		t.Errorf("metadata = %v, want the model version", resp.Metadata)
+	}
+}
+
+func TestGeminiProvider_Blocked(t *testing.T) {
+	tests := []struct {
+		name           string
+		response       string
+		wantContent    string
+		wantCategories string
+	}{
+		{
+			name: "prompt",
+			response: `{
+				"promptFeedback": {"blockReason": "SAFETY", "safetyRatings": [
+					{"category": "HARM_CATEGORY_HARASSMENT", "probability": "HIGH", "blocked": true},
+					{"category": "HARM_CATEGORY_HATE_SPEECH", "probability": "NEGLIGIBLE"}
+				]},
+				"usageMetadata": {"promptTokenCount": 7, "totalTokenCount": 7}
+			}`,
+			wantCategories: "HARM_CATEGORY_HARASSMENT",
+		},
+		{
+			name: "response",
+			response: `{
+				"candidates": [{
+					"content": {"role": "model", "parts": [{"text": "Partial"}]},
+					"finishReason": "SAFETY",
+					"safetyRatings": [{"category": "HARM_CATEGORY_DANGEROUS_CONTENT", "probability": "MEDIUM", "blocked": true}]
+				}]
+			}`,
+			wantContent:    "Partial",
+			wantCategories: "HARM_CATEGORY_DANGEROUS_CONTENT",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(geminiHandler(t, "/v1beta/models/gemini-2.0-flash:generateContent", &GenerateContentRequest{}, func(w http.ResponseWriter) {
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			resp, err := newTestProvider(t, server.URL, nil).SendCompletion(context.Background(), &providers.CompletionRequest{
+				Model:    testModel,
+				Messages: []providers.Message{{Role: providers.RoleUser, Content: "Hello"}},
+			})
+			if err != nil {
+				t.Fatalf("SendCompletion failed: %v", err)
+			}
+			if resp.FinishReason != providers.FinishReasonContentFilter || resp.Content != tt.wantContent {
+				t.Errorf("response = %+v, want a content_filter finish", resp)
+			}
+			if resp.Metadata["blocked_categories"] != tt.wantCategories {
+				t.Errorf("blocked categories = %q, want %q", resp.Metadata["blocked_categories"], tt.wantCategories)
+			}
+		})
+	}
+}
+
+// collect reads all chunks of a stream.
+func collect(t *testing.T, chunks <-chan *providers.StreamChunk) ([]*providers.StreamChunk, string) {
+	t.Helper()
+	var all []*providers.StreamChunk
+	var text strings.Builder
+	for chunk := range chunks {
+		all = append(all, chunk)
+		text.WriteString(chunk.Delta)
+	}
+	return all, text.String()
+}
+
+func TestGeminiProvider_StreamCompletion(t *testing.T) {
+	var body GenerateContentRequest
+	server := httptest.NewServer(geminiHandler(t, "/v1beta/models/gemini-2.0-flash:streamGenerateContent?alt=sse", &body, func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range []string{
+			`{"candidates":[{"content":{"role":"model","parts":[{"text":"Hel"}]}}],"usageMetadata":{"promptTokenCount":12},"responseId":"resp-456"}`,
+			`{"candidates":[{"content":{"role":"model","parts":[{"text":"lo"}]}}],"responseId":"resp-456"}`,
+			`{"candidates":[{"content":{"role":"model","parts":[{"functionCall":{"name":"get_weather","args":{"city":"Paris"}}}]}}],"responseId":"resp-456"}`,
+			`{"candidates":[{"content":{"role":"model","parts":[{"text":""}]},"finishReason":"STOP"}],"usageMetadata":{"promptTokenCount":12,"candidatesTokenCount":8,"totalTokenCount":20},"responseId":"resp-456"}`,
+		} {
+			fmt.Fprintf(w, "data: %s\r\n\r\n", event)
+		}
+	}))
+	defer server.Close()
+
+	chunks, err := newTestProvider(t, server.URL, nil).StreamCompletion(context.Background(), &providers.CompletionRequest{
+		Model:    testModel,
+		Messages: []providers.Message{{Role: providers.RoleUser, Content: "Hello"}},
+		Stream:   true,
+	})
+	if err != nil {
+		t.Fatalf("StreamCompletion failed: %v", err)
+	}
+
+	all, text := collect(t, chunks)
+	if text != "Hello" || len(all) != 4 {
+		t.Fatalf("stream = %q in %d chunks, want Hello in 4", text, len(all))
+	}
+	if call := all[2].ToolCalls; len(call) != 1 || call[0].ID == "" || call[0].Function.Name != "get_weather" || call[0].Function.Arguments != `{"city":"Paris"}` {
+		t.Errorf("tool call = %+v, want the whole get_weather call", call)
+	}
+	final := all[3]
+	if final.FinishReason != providers.FinishReasonToolCalls || final.Usage == nil || final.Usage.TotalTokens != 20 {
+		t.Errorf("final chunk = %+v, want the finish reason and usage", final)
+	}
+	if final.ID != "resp-456" || final.Model != testModel {
+		t.Errorf("final chunk ID = %q, model = %q", final.ID, final.Model)
+	}
+}
+
+func TestGeminiProvider_UnknownToolCall(t *testing.T) {
+	provider := newTestProvider(t, "http://127.0.0.1:1", nil)
+	_, err := provider.SendCompletion(context.Background(), &providers.CompletionRequest{
+		Model: testModel,
+		Messages: []providers.Message{
+			{Role: providers.RoleUser, Content: "Hello"},
+			{Role: providers.RoleTool, ToolCallID: "call_unknown", Content: "result"},
+		},
+	})
+	var validationErr *providers.ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Field != "messages" {
+		t.Errorf("SendCompletion() error = %v, want a messages validation error", err)
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	var configErr *providers.ConfigError
+	if _, err := NewProvider(providers.ProviderConfig{Name: "gemini"}); !errors.As(err, &configErr) || configErr.Field != "api_key" {
+		t.Errorf("NewProvider() without an API key error = %v, want an api_key error", err)
+	}
+
+	provider, err := NewProvider(providers.ProviderConfig{Name: "gemini", APIKey: "test-key"})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	if config := provider.GetConfig(); config.BaseURL != "https://generativelanguage.googleapis.com" {
+		t.Errorf("base URL = %q, want the Gemini API endpoint", config.BaseURL)
+	}
+
+	tests := []struct {
+		model string
+		want  string
+	}{
+		{"gemini-2.0-flash", "https://generativelanguage.googleapis.com/v1beta/models/gemini-2.0-flash:generateContent"},
+		{"models/gemini-2.0-flash", "https://generativelanguage.googleapis.com/v1beta/models/gemini-2.0-flash:generateContent"},
+		{"tunedModels/my-model-123", "https://generativelanguage.googleapis.com/v1beta/tunedModels/my-model-123:generateContent"},
+	}
+	for _, tt := range tests {
+		if url := provider.modelURL(tt.model, "generateContent"); url != tt.want {
+			t.Errorf("modelURL(%q) = %q, want %q", tt.model, url, tt.want)
+		}
+	}
+}
+
+func TestNormalizeFinishReason(t *testing.T) {
+	tests := []struct {
+		reason    string
+		toolCalls bool
+		want      string
+	}{
+		{"STOP", false, providers.FinishReasonStop},
+		{"STOP", true, providers.FinishReasonToolCalls},
+		{"MAX_TOKENS", false, providers.FinishReasonLength},
+		{"SAFETY", false, providers.FinishReasonContentFilter},
+		{"RECITATION", false, providers.FinishReasonContentFilter},
+		{"PROHIBITED_CONTENT", false, providers.FinishReasonContentFilter},
+		{"MALFORMED_FUNCTION_CALL", false, "malformed_function_call"},
+		{"", false, ""},
+	}
+
+	for _, tt := range tests {
+		if got := normalizeFinishReason(tt.reason, tt.toolCalls); got != tt.want {
+			t.Errorf("normalizeFinishReason(%q, %v) = %q, want %q", tt.reason, tt.toolCalls, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Package gemini implements the Gemini API provider adapter.
+//
+// This package provides an implementation of the providers.Provider interface
+// for the Gemini API of Google AI Studio (generativelanguage.googleapis.com),
+// authorized with an API key. It supports:
+//
+//   - generateContent and streamGenerateContent methods
+//   - System instructions and function calling
+//   - Safety settings, with blocked prompts and responses reported as
+//     content_filter
+//   - Token usage tracking, including thinking and cached tokens
+//
+// The Vertex AI adapter embeds this adapter: Vertex AI serves the same
+// request and response format, at other URLs and with Google Cloud
+// credentials (see NewProviderWithEndpoint).
+//
+// # Basic Usage
+//
+//	config := providers.ProviderConfig{
+//	    Name:   "gemini",
+//	    Type:   "gemini",
+//	    APIKey: "...",
+//	}
+//
+//	provider, err := gemini.NewProvider(config)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer provider.Close()
+//
+//	req := &providers.CompletionRequest{
+//	    Model: "gemini-2.0-flash",
+//	    Messages: []providers.Message{
+//	        {Role: "user", Content: "Hello!"},
+//	    },
+//	}
+//
+//	resp, err := provider.SendCompletion(context.Background(), req)
+//
+// Model names are sent as models/{model}; resource names such as
+// "tunedModels/my-model" are used as given. The API key is sent in the
+// x-goog-api-key header.
+//
+// # Request Mapping
+//
+// The adapter translates provider-agnostic requests to Gemini format:
+//
+//   - System messages -> systemInstruction
+//   - User and assistant messages -> contents with roles "user" and "model"
+//   - Tool calls -> functionCall parts; tool messages -> functionResponse
+//     parts, named after the tool call they answer
+//   - Tools -> functionDeclarations; tool choice -> functionCallingConfig
+//     (AUTO, ANY, NONE, or ANY restricted to a named function)
+//   - max_tokens, temperature, top_p, stop, and penalties -> generationConfig
+//
+// Tool results that are not JSON objects are wrapped as {"content": "..."},
+// as Gemini requires objects. Gemini does not identify function calls, so
+// the adapter generates tool call IDs.
+//
+// # Response Mapping
+//
+// Finish reasons are normalized: STOP -> stop (tool_calls when the model
+// called functions), MAX_TOKENS -> length, and SAFETY, RECITATION,
+// BLOCKLIST, PROHIBITED_CONTENT, and SPII -> content_filter. A blocked
+// prompt also finishes with content_filter; the block reason and blocked
+// harm categories are set in the response metadata.
+//
+// Token usage is taken from usageMetadata. Thinking tokens are counted as
+// completion tokens, and cached content tokens as cached prompt tokens.
+//
+// # Streaming
+//
+// Streams are requested as Server-Sent Events (alt=sse). Text deltas and
+// function calls are sent as they arrive; the chunk with the finish reason
+// carries the token usage.
+//
+// # Error Handling
+//
+// The adapter maps Gemini errors to common error types:
+//
+//   - 401/403 -> AuthError (PERMISSION_DENIED)
+//   - 429 -> RateLimitError (RESOURCE_EXHAUSTED)
+//   - 400 -> ProviderError (INVALID_ARGUMENT, including invalid API keys)
+//   - 5xx -> ProviderError (retried automatically)
+//
+// # Health Checks
+//
+// Health checks list the models available to the API key.
+package gemini
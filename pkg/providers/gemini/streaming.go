@@ -0,0 +1,217 @@
+package gemini
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"mercator-hq/jupiter/pkg/providers"
+)
+
+// streamState tracks state across stream chunks.
+type streamState struct {
+	id      string
+	model   string
+	created int64
+
+	// toolCalls reports whether the model called functions, which Gemini
+	// reports as a STOP finish reason
+	toolCalls bool
+
+	// usage is the latest usage reported, sent in the final chunk
+	usage *providers.TokenUsage
+}
+
+// streamReader reads Server-Sent Events (SSE) from the Gemini
+// streamGenerateContent API. Each event is a GenerateContentResponse.
+type streamReader struct {
+	provider *providers.HTTPProvider
+	resp     io.ReadCloser
+	scanner  *bufio.Scanner
+	state    *streamState
+	closed   bool
+}
+
+// newStreamReader sends a streaming request and returns a reader of the
+// events of the response.
+func newStreamReader(ctx context.Context, provider *providers.HTTPProvider, url, model string, req *GenerateContentRequest) (*streamReader, error) {
+	// Marshal request
+	bodyBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Perform request
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"Accept":       "text/event-stream",
+	}
+	resp, err := provider.DoRequest(ctx, "POST", url, bodyBytes, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create scanner for reading SSE lines. Events carrying function call
+	// arguments can exceed the default token size.
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+
+	return &streamReader{
+		provider: provider,
+		resp:     resp.Body,
+		scanner:  scanner,
+		state: &streamState{
+			model:   model,
+			created: time.Now().Unix(),
+		},
+	}, nil
+}
+
+// Read reads the next chunk from the stream.
+// Returns nil, io.EOF when the stream ends normally.
+// Returns nil, error if an error occurs.
+func (s *streamReader) Read(ctx context.Context) (*providers.StreamChunk, error) {
+	if s.closed {
+		return nil, io.EOF
+	}
+
+	for {
+		// Check context cancellation
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		// Read next SSE event
+		data, err := s.readEvent()
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, &providers.StreamError{
+				Provider: s.provider.GetName(),
+				Message:  "failed to read stream",
+				Cause:    err,
+			}
+		}
+
+		var resp GenerateContentResponse
+		if err := json.Unmarshal([]byte(data), &resp); err != nil {
+			return nil, &providers.ParseError{
+				Provider:    s.provider.GetName(),
+				RawResponse: data,
+				Cause:       fmt.Errorf("failed to parse stream event: %w", err),
+			}
+		}
+
+		// Transform event to chunk
+		chunk := s.transform(&resp)
+		if chunk == nil {
+			continue
+		}
+
+		chunk.ID = s.state.id
+		chunk.Model = s.state.model
+		chunk.Created = s.state.created
+		return chunk, nil
+	}
+}
+
+// transform transforms a response chunk to a stream chunk. It returns nil
+// for chunks that carry no content. The chunk with the finish reason
+// carries the latest usage.
+func (s *streamReader) transform(resp *GenerateContentResponse) *providers.StreamChunk {
+	if resp.ResponseID != "" {
+		s.state.id = resp.ResponseID
+	}
+	if resp.UsageMetadata != nil {
+		s.state.usage = resp.UsageMetadata.tokenUsage()
+	}
+
+	// A blocked prompt ends the stream
+	if resp.PromptFeedback != nil && resp.PromptFeedback.BlockReason != "" {
+		return &providers.StreamChunk{
+			FinishReason: providers.FinishReasonContentFilter,
+			Usage:        s.state.usage,
+		}
+	}
+	if len(resp.Candidates) == 0 {
+		return nil
+	}
+
+	candidate := resp.Candidates[0]
+	chunk := &providers.StreamChunk{}
+	var delta strings.Builder
+	for _, part := range candidate.Content.Parts {
+		if part.FunctionCall != nil {
+			// Function calls are sent whole
+			chunk.ToolCalls = append(chunk.ToolCalls, transformFunctionCall(part.FunctionCall))
+			s.state.toolCalls = true
+			continue
+		}
+		if !part.Thought {
+			delta.WriteString(part.Text)
+		}
+	}
+	chunk.Delta = delta.String()
+
+	if candidate.FinishReason != "" {
+		chunk.FinishReason = normalizeFinishReason(candidate.FinishReason, s.state.toolCalls)
+		chunk.Usage = s.state.usage
+	}
+
+	if chunk.Delta == "" && len(chunk.ToolCalls) == 0 && chunk.FinishReason == "" {
+		return nil
+	}
+	return chunk
+}
+
+// readEvent returns the data of the next SSE event.
+func (s *streamReader) readEvent() (string, error) {
+	var dataLines []string
+
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+
+		// Empty line marks end of event
+		if line == "" {
+			if len(dataLines) > 0 {
+				break
+			}
+			continue
+		}
+
+		// Parse SSE field
+		if strings.HasPrefix(line, "data:") {
+			dataLines = append(dataLines, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+		// Ignore other SSE fields (event, id, retry)
+	}
+
+	if err := s.scanner.Err(); err != nil {
+		return "", err
+	}
+
+	// No event found
+	if len(dataLines) == 0 {
+		return "", io.EOF
+	}
+
+	// Combine multi-line data
+	return strings.Join(dataLines, "\n"), nil
+}
+
+// Close closes the stream and releases resources.
+func (s *streamReader) Close() error {
+	if s.closed {
+		return nil
+	}
+
+	s.closed = true
+	return s.resp.Close()
+}
@@ -0,0 +1,411 @@
+package gemini
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"mercator-hq/jupiter/pkg/providers"
+)
+
+// Gemini API request/response types
+
+// GenerateContentRequest represents a Gemini generateContent request. The
+// model is given in the URL.
+type GenerateContentRequest struct {
+	Contents          []Content         `json:"contents"`
+	SystemInstruction *Content          `json:"systemInstruction,omitempty"`
+	GenerationConfig  *GenerationConfig `json:"generationConfig,omitempty"`
+	SafetySettings    []SafetySetting   `json:"safetySettings,omitempty"`
+	Tools             []Tool            `json:"tools,omitempty"`
+	ToolConfig        *ToolConfig       `json:"toolConfig,omitempty"`
+}
+
+// Content represents a message in Gemini format. The role is "user" or
+// "model".
+type Content struct {
+	Role  string `json:"role,omitempty"`
+	Parts []Part `json:"parts"`
+}
+
+// Part represents a part of a message. Exactly one of Text, FunctionCall,
+// and FunctionResponse is set.
+type Part struct {
+	Text             string            `json:"text,omitempty"`
+	FunctionCall     *FunctionCall     `json:"functionCall,omitempty"`
+	FunctionResponse *FunctionResponse `json:"functionResponse,omitempty"`
+
+	// Thought marks the thought summaries of thinking models, which are
+	// not part of the response content
+	Thought bool `json:"thought,omitempty"`
+}
+
+// FunctionCall represents a function call by the model.
+type FunctionCall struct {
+	Name string          `json:"name"`
+	Args json.RawMessage `json:"args,omitempty"`
+}
+
+// FunctionResponse represents the result of a function call.
+type FunctionResponse struct {
+	Name     string          `json:"name"`
+	Response json.RawMessage `json:"response"`
+}
+
+// GenerationConfig represents the generation parameters of a request.
+type GenerationConfig struct {
+	Temperature      float64  `json:"temperature,omitempty"`
+	TopP             float64  `json:"topP,omitempty"`
+	MaxOutputTokens  int      `json:"maxOutputTokens,omitempty"`
+	StopSequences    []string `json:"stopSequences,omitempty"`
+	PresencePenalty  float64  `json:"presencePenalty,omitempty"`
+	FrequencyPenalty float64  `json:"frequencyPenalty,omitempty"`
+}
+
+// SafetySetting represents the blocking threshold of a harm category.
+type SafetySetting struct {
+	Category  string `json:"category"`
+	Threshold string `json:"threshold"`
+}
+
+// Tool represents the function declarations of a request.
+type Tool struct {
+	FunctionDeclarations []FunctionDeclaration `json:"functionDeclarations"`
+}
+
+// FunctionDeclaration represents a function the model can call.
+type FunctionDeclaration struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// ToolConfig represents the function calling configuration of a request.
+type ToolConfig struct {
+	FunctionCallingConfig FunctionCallingConfig `json:"functionCallingConfig"`
+}
+
+// FunctionCallingConfig represents the function calling mode: "AUTO",
+// "ANY" (a function must be called), or "NONE".
+type FunctionCallingConfig struct {
+	Mode                 string   `json:"mode"`
+	AllowedFunctionNames []string `json:"allowedFunctionNames,omitempty"`
+}
+
+// GenerateContentResponse represents a Gemini generateContent response,
+// or a chunk of a streamGenerateContent response.
+type GenerateContentResponse struct {
+	Candidates     []Candidate     `json:"candidates"`
+	PromptFeedback *PromptFeedback `json:"promptFeedback,omitempty"`
+	UsageMetadata  *UsageMetadata  `json:"usageMetadata,omitempty"`
+	ModelVersion   string          `json:"modelVersion,omitempty"`
+	ResponseID     string          `json:"responseId,omitempty"`
+}
+
+// Candidate represents a generated response.
+type Candidate struct {
+	Content       Content        `json:"content"`
+	FinishReason  string         `json:"finishReason,omitempty"`
+	FinishMessage string         `json:"finishMessage,omitempty"`
+	SafetyRatings []SafetyRating `json:"safetyRatings,omitempty"`
+}
+
+// SafetyRating represents the rating of a response or prompt for a harm
+// category.
+type SafetyRating struct {
+	Category    string `json:"category"`
+	Probability string `json:"probability,omitempty"`
+	Blocked     bool   `json:"blocked,omitempty"`
+}
+
+// PromptFeedback reports why a prompt was blocked.
+type PromptFeedback struct {
+	BlockReason        string         `json:"blockReason,omitempty"`
+	BlockReasonMessage string         `json:"blockReasonMessage,omitempty"`
+	SafetyRatings      []SafetyRating `json:"safetyRatings,omitempty"`
+}
+
+// UsageMetadata represents token usage in Gemini format.
+type UsageMetadata struct {
+	PromptTokenCount        int `json:"promptTokenCount"`
+	CandidatesTokenCount    int `json:"candidatesTokenCount"`
+	TotalTokenCount         int `json:"totalTokenCount"`
+	CachedContentTokenCount int `json:"cachedContentTokenCount,omitempty"`
+	ThoughtsTokenCount      int `json:"thoughtsTokenCount,omitempty"`
+}
+
+// tokenUsage transforms Gemini token usage to provider-agnostic format.
+// The thinking tokens of thinking models are billed as output, and counted
+// as completion tokens.
+func (u *UsageMetadata) tokenUsage() *providers.TokenUsage {
+	completionTokens := u.CandidatesTokenCount + u.ThoughtsTokenCount
+	totalTokens := u.TotalTokenCount
+	if totalTokens == 0 {
+		totalTokens = u.PromptTokenCount + completionTokens
+	}
+	return &providers.TokenUsage{
+		PromptTokens:       u.PromptTokenCount,
+		CompletionTokens:   completionTokens,
+		TotalTokens:        totalTokens,
+		CachedPromptTokens: u.CachedContentTokenCount,
+	}
+}
+
+// Gemini roles
+const (
+	roleUser  = "user"
+	roleModel = "model"
+)
+
+// transformRequest transforms a provider-agnostic request to Gemini format.
+func transformRequest(req *providers.CompletionRequest, safetySettings []SafetySetting) (*GenerateContentRequest, error) {
+	geminiReq := &GenerateContentRequest{
+		Contents:       make([]Content, 0, len(req.Messages)),
+		SafetySettings: safetySettings,
+	}
+
+	if req.MaxTokens != 0 || req.Temperature != 0 || req.TopP != 0 || len(req.Stop) > 0 ||
+		req.PresencePenalty != 0 || req.FrequencyPenalty != 0 {
+		geminiReq.GenerationConfig = &GenerationConfig{
+			Temperature:      req.Temperature,
+			TopP:             req.TopP,
+			MaxOutputTokens:  req.MaxTokens,
+			StopSequences:    req.Stop,
+			PresencePenalty:  req.PresencePenalty,
+			FrequencyPenalty: req.FrequencyPenalty,
+		}
+	}
+
+	// Function responses are matched to calls by name, so the names of the
+	// tool calls are tracked by ID
+	toolCallNames := make(map[string]string)
+
+	for _, msg := range req.Messages {
+		var role string
+		var parts []Part
+
+		switch msg.Role {
+		case providers.RoleSystem:
+			if geminiReq.SystemInstruction == nil {
+				geminiReq.SystemInstruction = &Content{}
+			}
+			geminiReq.SystemInstruction.Parts = append(geminiReq.SystemInstruction.Parts, Part{Text: msg.Content})
+			continue
+
+		case providers.RoleTool:
+			// Function responses are parts of a user message
+			name := toolCallNames[msg.ToolCallID]
+			if name == "" {
+				name = msg.Name
+			}
+			if name == "" {
+				return nil, &providers.ValidationError{
+					Field:   "messages",
+					Message: fmt.Sprintf("tool message %q does not answer a tool call of the conversation", msg.ToolCallID),
+				}
+			}
+			role = roleUser
+			parts = []Part{{FunctionResponse: &FunctionResponse{
+				Name:     name,
+				Response: functionResponse(msg.Content),
+			}}}
+
+		case providers.RoleUser, providers.RoleAssistant:
+			role = roleUser
+			if msg.Role == providers.RoleAssistant {
+				role = roleModel
+			}
+			if msg.Content != "" {
+				parts = append(parts, Part{Text: msg.Content})
+			}
+			for _, call := range msg.ToolCalls {
+				args := json.RawMessage(call.Function.Arguments)
+				if len(args) == 0 {
+					args = json.RawMessage("{}")
+				}
+				if !json.Valid(args) {
+					return nil, &providers.ValidationError{
+						Field:   "messages",
+						Message: fmt.Sprintf("arguments of tool call %q are not valid JSON", call.ID),
+					}
+				}
+				toolCallNames[call.ID] = call.Function.Name
+				parts = append(parts, Part{FunctionCall: &FunctionCall{
+					Name: call.Function.Name,
+					Args: args,
+				}})
+			}
+
+		default:
+			return nil, &providers.ValidationError{
+				Field:   "messages",
+				Message: fmt.Sprintf("unsupported message role %q", msg.Role),
+			}
+		}
+
+		// Consecutive messages of a role, such as the results of parallel
+		// function calls, are merged into one
+		if n := len(geminiReq.Contents); n > 0 && geminiReq.Contents[n-1].Role == role {
+			geminiReq.Contents[n-1].Parts = append(geminiReq.Contents[n-1].Parts, parts...)
+			continue
+		}
+		geminiReq.Contents = append(geminiReq.Contents, Content{Role: role, Parts: parts})
+	}
+
+	// Transform tools
+	if len(req.Tools) > 0 {
+		declarations := make([]FunctionDeclaration, len(req.Tools))
+		for i, tool := range req.Tools {
+			declarations[i] = FunctionDeclaration{
+				Name:        tool.Function.Name,
+				Description: tool.Function.Description,
+				Parameters:  tool.Function.Parameters,
+			}
+		}
+		geminiReq.Tools = []Tool{{FunctionDeclarations: declarations}}
+		geminiReq.ToolConfig = transformToolChoice(req.ToolChoice)
+	}
+
+	return geminiReq, nil
+}
+
+// functionResponse returns the response of a function call: the content of
+// the tool message if it is a JSON object, or else the content wrapped in
+// an object, as Gemini requires.
+func functionResponse(content string) json.RawMessage {
+	trimmed := strings.TrimSpace(content)
+	if strings.HasPrefix(trimmed, "{") && json.Valid([]byte(trimmed)) {
+		return json.RawMessage(trimmed)
+	}
+	wrapped, _ := json.Marshal(map[string]string{"content": content})
+	return wrapped
+}
+
+// transformToolChoice transforms an OpenAI tool choice ("none", "auto",
+// "required", or a named function) to a function calling configuration.
+// Other choices leave the choice to the model.
+func transformToolChoice(choice interface{}) *ToolConfig {
+	switch choice := choice.(type) {
+	case string:
+		switch choice {
+		case "none":
+			return &ToolConfig{FunctionCallingConfig: FunctionCallingConfig{Mode: "NONE"}}
+		case "auto":
+			return &ToolConfig{FunctionCallingConfig: FunctionCallingConfig{Mode: "AUTO"}}
+		case "required":
+			return &ToolConfig{FunctionCallingConfig: FunctionCallingConfig{Mode: "ANY"}}
+		}
+	case map[string]interface{}:
+		if function, ok := choice["function"].(map[string]interface{}); ok {
+			if name, ok := function["name"].(string); ok {
+				return &ToolConfig{FunctionCallingConfig: FunctionCallingConfig{
+					Mode:                 "ANY",
+					AllowedFunctionNames: []string{name},
+				}}
+			}
+		}
+	}
+	return nil
+}
+
+// transformResponse transforms a Gemini response to provider-agnostic
+// format. Only the first candidate is used.
+func transformResponse(resp *GenerateContentResponse) *providers.CompletionResponse {
+	completion := &providers.CompletionResponse{
+		ID:       resp.ResponseID,
+		Created:  time.Now().Unix(),
+		Metadata: make(map[string]string),
+	}
+	if resp.UsageMetadata != nil {
+		completion.Usage = *resp.UsageMetadata.tokenUsage()
+	}
+	if resp.ModelVersion != "" {
+		completion.Metadata["model_version"] = resp.ModelVersion
+	}
+
+	// A blocked prompt has no candidates
+	if resp.PromptFeedback != nil && resp.PromptFeedback.BlockReason != "" {
+		completion.FinishReason = providers.FinishReasonContentFilter
+		completion.Metadata["block_reason"] = resp.PromptFeedback.BlockReason
+		if categories := blockedCategories(resp.PromptFeedback.SafetyRatings); categories != "" {
+			completion.Metadata["blocked_categories"] = categories
+		}
+		return completion
+	}
+	if len(resp.Candidates) == 0 {
+		return completion
+	}
+
+	candidate := resp.Candidates[0]
+	var content strings.Builder
+	for _, part := range candidate.Content.Parts {
+		if part.FunctionCall != nil {
+			completion.ToolCalls = append(completion.ToolCalls, transformFunctionCall(part.FunctionCall))
+			continue
+		}
+		if !part.Thought {
+			content.WriteString(part.Text)
+		}
+	}
+	completion.Content = content.String()
+	completion.FinishReason = normalizeFinishReason(candidate.FinishReason, len(completion.ToolCalls) > 0)
+	if categories := blockedCategories(candidate.SafetyRatings); categories != "" {
+		completion.Metadata["blocked_categories"] = categories
+	}
+
+	return completion
+}
+
+// transformFunctionCall transforms a Gemini function call to a tool call,
+// with a new ID.
+func transformFunctionCall(call *FunctionCall) providers.ToolCall {
+	args := string(call.Args)
+	if args == "" {
+		args = "{}"
+	}
+	return providers.ToolCall{
+		ID:   newToolCallID(),
+		Type: providers.ToolTypeFunction,
+		Function: providers.FunctionCall{
+			Name:      call.Name,
+			Arguments: args,
+		},
+	}
+}
+
+// blockedCategories returns the comma-separated harm categories of the
+// ratings that blocked a prompt or response, sorted.
+func blockedCategories(ratings []SafetyRating) string {
+	var categories []string
+	for _, rating := range ratings {
+		if rating.Blocked {
+			categories = append(categories, rating.Category)
+		}
+	}
+	sort.Strings(categories)
+	return strings.Join(categories, ",")
+}
+
+// normalizeFinishReason normalizes Gemini finish reasons to
+// provider-agnostic values. Gemini reports STOP for responses that call
+// functions.
+func normalizeFinishReason(reason string, toolCalls bool) string {
+	switch reason {
+	case "STOP":
+		if toolCalls {
+			return providers.FinishReasonToolCalls
+		}
+		return providers.FinishReasonStop
+	case "MAX_TOKENS":
+		return providers.FinishReasonLength
+	case "SAFETY", "RECITATION", "BLOCKLIST", "PROHIBITED_CONTENT", "SPII", "IMAGE_SAFETY":
+		return providers.FinishReasonContentFilter
+	case "":
+		return ""
+	default:
+		// OTHER, MALFORMED_FUNCTION_CALL, etc.
+		return strings.ToLower(reason)
+	}
+}
@@ -105,17 +105,20 @@ func (p *HTTPProvider) healthCheckImpl(ctx context.Context) error {
 	// Construct health check URL
 	// For most providers, we can use a HEAD request to the base URL
 	url := p.config.BaseURL
+	if p.healthCheckURL != "" {
+		url = p.healthCheckURL
+	}
 
 	// Prepare headers
 	headers := make(map[string]string)
-	if p.config.APIKey != "" {
+	if p.config.APIKey != "" && p.signRequest == nil {
 		// Different providers use different auth header formats
 		// This is a generic implementation - specific providers may override
 		headers["Authorization"] = "Bearer " + p.config.APIKey
 	}
 
-	// Perform HEAD request
-	resp, err := p.DoRequest(ctx, "GET", url, nil, headers)
+	// Perform HEAD request. Health checks are not traced.
+	resp, err := p.doRequest(ctx, "GET", url, nil, headers)
 	if err != nil {
 		return err
 	}
@@ -9,8 +9,14 @@ import (
 	"log/slog"
 	"math"
 	"net/http"
+	"net/url"
+	"strings"
 	"sync"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/net/http/httpproxy"
 )
 
 // HTTPProvider is the base implementation for HTTP-based provider adapters.
@@ -36,12 +42,25 @@ type HTTPProvider struct {
 
 	// healthCheckStopped is closed when the health checker has stopped
 	healthCheckStopped chan struct{}
+
+	// signRequest, if set, authenticates each request attempt
+	signRequest RequestSigner
+
+	// healthCheckURL overrides the base URL for health checks
+	healthCheckURL string
 }
 
+// RequestSigner authenticates an outgoing request, e.g. with AWS Signature
+// Version 4. body is the exact request payload, or nil.
+type RequestSigner func(req *http.Request, body []byte) error
+
 // NewHTTPProvider creates a new base HTTP provider with connection pooling.
+// With a proxy URL, all requests of the provider, including streaming
+// requests and health checks, are sent through the proxy.
 func NewHTTPProvider(config ProviderConfig) *HTTPProvider {
 	// Create HTTP transport with connection pooling
 	transport := &http.Transport{
+		Proxy:               proxyFunc(config),
 		MaxIdleConns:        config.MaxIdleConns,
 		MaxIdleConnsPerHost: config.MaxIdleConnsPerHost,
 		IdleConnTimeout:     config.IdleConnTimeout,
@@ -74,6 +93,74 @@ func NewHTTPProvider(config ProviderConfig) *HTTPProvider {
 	return p
 }
 
+// proxyFunc returns the function that selects the egress proxy of the
+// requests of a provider: its proxy URL, except for the hosts matching its
+// NO_PROXY rules and loopback addresses, which are connected to directly.
+// Without a proxy URL, requests are sent directly.
+//
+// An invalid proxy URL fails every request rather than bypassing the
+// proxy.
+func proxyFunc(config ProviderConfig) func(*http.Request) (*url.URL, error) {
+	if config.ProxyURL == "" {
+		return nil
+	}
+
+	if err := validateProxyURL(config.ProxyURL); err != nil {
+		slog.Error("invalid provider proxy URL", "provider", config.Name, "error", err)
+		return func(*http.Request) (*url.URL, error) {
+			return nil, err
+		}
+	}
+
+	proxy := (&httpproxy.Config{
+		HTTPProxy:  config.ProxyURL,
+		HTTPSProxy: config.ProxyURL,
+		NoProxy:    strings.Join(config.NoProxy, ","),
+	}).ProxyFunc()
+
+	proxyURL, _ := url.Parse(config.ProxyURL)
+	slog.Info("provider egress proxy configured",
+		"provider", config.Name,
+		"proxy", proxyURL.Redacted(),
+		"no_proxy", config.NoProxy,
+	)
+
+	return func(req *http.Request) (*url.URL, error) {
+		return proxy(req.URL)
+	}
+}
+
+// validateProxyURL checks that a provider proxy URL is an absolute http,
+// https, or socks5 URL.
+func validateProxyURL(proxyURL string) error {
+	u, err := url.Parse(proxyURL)
+	if err != nil {
+		return fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("invalid proxy URL %q: scheme must be http, https, or socks5", u.Redacted())
+	}
+	if u.Host == "" {
+		return fmt.Errorf("invalid proxy URL %q: missing host", u.Redacted())
+	}
+	return nil
+}
+
+// SetRequestSigner sets the function that signs each request attempt,
+// after its headers are set. Adapters whose APIs do not use API keys call
+// it from their constructor.
+func (p *HTTPProvider) SetRequestSigner(signer RequestSigner) {
+	p.signRequest = signer
+}
+
+// SetHealthCheckURL sets the URL requested by health checks, for providers
+// whose base URL does not answer GET requests.
+func (p *HTTPProvider) SetHealthCheckURL(url string) {
+	p.healthCheckURL = url
+}
+
 // GetName returns the provider's configured name.
 func (p *HTTPProvider) GetName() string {
 	return p.config.Name
@@ -145,7 +232,20 @@ func (p *HTTPProvider) recordRequest(success bool) {
 
 // DoRequest performs an HTTP request with retry logic and timeout handling.
 // It automatically retries transient errors (5xx, timeouts) with exponential backoff.
+//
+// The request is traced as a client span, whose W3C trace context is sent
+// to the provider in the traceparent header, with the latency breakdown of
+// each attempt as child spans.
 func (p *HTTPProvider) DoRequest(ctx context.Context, method, url string, body []byte, headers map[string]string) (*http.Response, error) {
+	ctx, span := p.startRequestSpan(ctx, method, url)
+	resp, err := p.doRequest(ctx, method, url, body, headers)
+	endRequestSpan(span, resp, err)
+	return resp, err
+}
+
+// doRequest performs an HTTP request with retries, without a span of its
+// own.
+func (p *HTTPProvider) doRequest(ctx context.Context, method, url string, body []byte, headers map[string]string) (*http.Response, error) {
 	var lastErr error
 
 	// Attempt request with retries
@@ -174,7 +274,7 @@ func (p *HTTPProvider) DoRequest(ctx context.Context, method, url string, body [
 			bodyReader = bytes.NewReader(body)
 		}
 
-		req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
+		req, err := http.NewRequestWithContext(withPhaseSpans(ctx), method, url, bodyReader)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create request: %w", err)
 		}
@@ -183,12 +283,27 @@ func (p *HTTPProvider) DoRequest(ctx context.Context, method, url string, body [
 		for key, value := range headers {
 			req.Header.Set(key, value)
 		}
+		p.injectTraceContext(ctx, req.Header)
+		if attempt > 0 {
+			trace.SpanFromContext(ctx).SetAttributes(attribute.Int("mercator.retry_count", attempt))
+		}
 
 		// Set default Content-Type if not provided
 		if req.Header.Get("Content-Type") == "" && body != nil {
 			req.Header.Set("Content-Type", "application/json")
 		}
 
+		// Sign the request over its final headers
+		if p.signRequest != nil {
+			if err := p.signRequest(req, body); err != nil {
+				p.recordRequest(false)
+				return nil, &AuthError{
+					Provider: p.config.Name,
+					Message:  err.Error(),
+				}
+			}
+		}
+
 		// Perform request
 		slog.Debug("sending request to provider",
 			"provider", p.config.Name,
@@ -252,8 +367,8 @@ func (p *HTTPProvider) DoRequest(ctx context.Context, method, url string, body [
 				Message:    string(errorBody),
 			}
 
-		case http.StatusBadRequest:
-			// Bad request - don't retry
+		case http.StatusBadRequest, http.StatusUnprocessableEntity:
+			// Bad request (or request validation error, e.g. from TGI) - don't retry
 			p.recordRequest(false)
 			return nil, &ProviderError{
 				Provider:   p.config.Name,
@@ -81,6 +81,11 @@ func TestHTTPProvider_NoRetryOn4xx(t *testing.T) {
 			statusCode: http.StatusBadRequest,
 			errorType:  "ProviderError",
 		},
+		{
+			name:       "422 unprocessable entity",
+			statusCode: http.StatusUnprocessableEntity,
+			errorType:  "ProviderError",
+		},
 		{
 			name:       "401 unauthorized",
 			statusCode: http.StatusUnauthorized,
@@ -489,3 +494,140 @@ func TestHTTPProvider_PoolLimitEnforcement(t *testing.T) {
 		t.Error("expected provider to be healthy after concurrent requests")
 	}
 }
+
+func TestHTTPProvider_RequestSigner(t *testing.T) {
+	attemptCount := int32(0)
+
+	// Create test server that fails once with 500, then succeeds, and
+	// records the signature of each attempt
+	var signatures []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signatures = append(signatures, r.Header.Get("X-Signature"))
+		if atomic.AddInt32(&attemptCount, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	provider := NewHTTPProvider(ProviderConfig{
+		Name:       "test-provider",
+		BaseURL:    server.URL,
+		Timeout:    5 * time.Second,
+		MaxRetries: 1,
+	})
+	signed := 0
+	provider.SetRequestSigner(func(req *http.Request, body []byte) error {
+		signed++
+		// Headers are final when the request is signed
+		if req.Header.Get("Content-Type") != "application/json" || string(body) != `{"test": true}` {
+			t.Errorf("signed request with Content-Type %q and body %q", req.Header.Get("Content-Type"), body)
+		}
+		req.Header.Set("X-Signature", fmt.Sprintf("sig-%d", signed))
+		return nil
+	})
+
+	resp, err := provider.DoRequest(context.Background(), "POST", server.URL, []byte(`{"test": true}`), nil)
+	if err != nil {
+		t.Fatalf("expected request to succeed after a retry, got error: %v", err)
+	}
+	resp.Body.Close()
+
+	// Each attempt is signed again
+	if len(signatures) != 2 || signatures[0] != "sig-1" || signatures[1] != "sig-2" {
+		t.Errorf("expected each attempt to be signed, got signatures %v", signatures)
+	}
+
+	// A signing failure is an authentication error, and nothing is sent
+	provider.SetRequestSigner(func(req *http.Request, body []byte) error {
+		return errors.New("missing credentials")
+	})
+	_, err = provider.DoRequest(context.Background(), "POST", server.URL, nil, nil)
+	var authErr *AuthError
+	if !errors.As(err, &authErr) {
+		t.Errorf("expected AuthError, got %v", err)
+	}
+	if len(signatures) != 2 {
+		t.Errorf("expected no request after a signing failure, got %d", len(signatures))
+	}
+}
+
+func TestHTTPProvider_Proxy(t *testing.T) {
+	// The proxy answers requests itself, recording their target
+	var target atomic.Value
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target.Store(r.URL.String())
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"message": "proxied"}`))
+	}))
+	defer proxy.Close()
+
+	provider := NewHTTPProvider(ProviderConfig{
+		Name:     "test-provider",
+		Type:     "openai",
+		BaseURL:  "http://api.example.com/v1",
+		Timeout:  5 * time.Second,
+		ProxyURL: proxy.URL,
+	})
+
+	resp, err := provider.DoRequest(context.Background(), "POST", "http://api.example.com/v1/chat/completions", []byte(`{}`), nil)
+	if err != nil {
+		t.Fatalf("DoRequest() error = %v", err)
+	}
+	resp.Body.Close()
+
+	if got := target.Load(); got != "http://api.example.com/v1/chat/completions" {
+		t.Errorf("proxy received request for %v, want the provider URL", got)
+	}
+}
+
+func TestHTTPProvider_ProxySelection(t *testing.T) {
+	tests := []struct {
+		name      string
+		proxyURL  string
+		noProxy   []string
+		url       string
+		wantProxy string
+		wantErr   bool
+	}{
+		{"no proxy", "", nil, "https://api.openai.com/v1", "", false},
+		{"http proxy", "http://proxy.corp.example:3128", nil, "https://api.openai.com/v1", "http://proxy.corp.example:3128", false},
+		{"socks5 proxy", "socks5://egress.corp.example:1080", nil, "http://api.example.com/v1", "socks5://egress.corp.example:1080", false},
+		{"no_proxy domain", "http://proxy.corp.example:3128", []string{".internal"}, "http://vllm.internal:8000/v1", "", false},
+		{"no_proxy CIDR", "http://proxy.corp.example:3128", []string{"10.0.0.0/8"}, "http://10.1.2.3:8000/v1", "", false},
+		{"no_proxy other host", "http://proxy.corp.example:3128", []string{".internal"}, "https://api.openai.com/v1", "http://proxy.corp.example:3128", false},
+		{"loopback", "http://proxy.corp.example:3128", nil, "http://127.0.0.1:8000/v1", "", false},
+		{"invalid proxy", "ftp://proxy.corp.example", nil, "https://api.openai.com/v1", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewHTTPProvider(ProviderConfig{
+				Name:     "test-provider",
+				ProxyURL: tt.proxyURL,
+				NoProxy:  tt.noProxy,
+			})
+			transport := provider.client.Transport.(*http.Transport)
+			if transport.Proxy == nil {
+				if tt.wantProxy != "" || tt.wantErr {
+					t.Fatalf("transport has no proxy, want %q", tt.wantProxy)
+				}
+				return
+			}
+
+			req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+			got, err := transport.Proxy(req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Proxy() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var gotProxy string
+			if got != nil {
+				gotProxy = got.String()
+			}
+			if gotProxy != tt.wantProxy {
+				t.Errorf("Proxy(%s) = %q, want %q", tt.url, gotProxy, tt.wantProxy)
+			}
+		})
+	}
+}
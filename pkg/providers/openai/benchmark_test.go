@@ -175,3 +175,59 @@ func BenchmarkOpenAIProvider_StreamCompletion(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkOpenAIProvider_StreamCompletionRaw(b *testing.B) {
+	// Create mock server
+	mock := testhelpers.NewMockServer()
+	defer mock.Close()
+
+	// Configure streaming response
+	chunks := []string{
+		testhelpers.MockOpenAIStreamChunk("Hello", ""),
+		testhelpers.MockOpenAIStreamChunk(", ", ""),
+		testhelpers.MockOpenAIStreamChunk("world", ""),
+		testhelpers.MockOpenAIStreamChunk("!", "stop"),
+	}
+
+	mock.SetResponse("/v1/chat/completions", testhelpers.MockResponse{
+		StatusCode:   200,
+		StreamChunks: chunks,
+	})
+
+	// Create provider
+	config := testhelpers.TestConfigWithURL("openai", "openai", mock.URL()+"/v1")
+	provider, err := NewProvider(config)
+	if err != nil {
+		b.Fatalf("failed to create provider: %v", err)
+	}
+	defer provider.Close()
+
+	// Create request
+	req := &providers.CompletionRequest{
+		Model: "gpt-4",
+		Messages: []providers.Message{
+			{Role: providers.RoleUser, Content: "Hello"},
+		},
+		Stream: true,
+	}
+
+	ctx := context.Background()
+
+	b.ResetTimer()
+
+	// Run benchmark
+	for i := 0; i < b.N; i++ {
+		chunksChan, err := provider.StreamCompletionRaw(ctx, req)
+		if err != nil {
+			b.Fatalf("StreamCompletionRaw failed: %v", err)
+		}
+
+		// Consume all chunks
+		for chunk := range chunksChan {
+			if chunk.Error != nil {
+				b.Fatalf("stream error: %v", chunk.Error)
+			}
+			chunk.Release()
+		}
+	}
+}
@@ -3,6 +3,7 @@ package openai
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
 
 	"mercator-hq/jupiter/pkg/providers"
@@ -169,6 +170,67 @@ func (p *Provider) StreamCompletion(ctx context.Context, req *providers.Completi
 	return chunks, nil
 }
 
+// StreamCompletionRaw sends a streaming completion request to OpenAI and
+// returns its chunks undecoded, to be forwarded as received.
+func (p *Provider) StreamCompletionRaw(ctx context.Context, req *providers.CompletionRequest) (<-chan *providers.RawStreamChunk, error) {
+	// Validate request
+	if err := validateRequest(req); err != nil {
+		return nil, err
+	}
+
+	// Transform to OpenAI format
+	openaiReq := transformRequest(req)
+	openaiReq.Stream = true
+
+	// Prepare request
+	url := fmt.Sprintf("%s/chat/completions", p.GetConfig().BaseURL)
+	headers := map[string]string{
+		"Authorization": "Bearer " + p.GetConfig().APIKey,
+		"Content-Type":  "application/json",
+		"Accept":        "text/event-stream",
+	}
+
+	// Create stream reader
+	stream, err := newStreamReader(ctx, p.HTTPProvider, url, openaiReq, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create output channel
+	chunks := make(chan *providers.RawStreamChunk, 100) // Buffered channel
+
+	// Start goroutine to read stream and send chunks until the stream ends,
+	// so that usage sent after the finish reason is forwarded too
+	go func() {
+		defer close(chunks)
+		defer stream.Close()
+
+		for {
+			chunk, err := stream.ReadRaw(ctx)
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				// Send error chunk and exit
+				select {
+				case chunks <- &providers.RawStreamChunk{Error: err}:
+				case <-ctx.Done():
+				}
+				return
+			}
+
+			select {
+			case chunks <- chunk:
+			case <-ctx.Done():
+				chunk.Release()
+				return
+			}
+		}
+	}()
+
+	return chunks, nil
+}
+
 // validateRequest validates the completion request.
 func validateRequest(req *providers.CompletionRequest) error {
 	if req == nil {
@@ -2,15 +2,31 @@ package openai
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
-	"strings"
 
 	"mercator-hq/jupiter/pkg/providers"
 )
 
+// sseDataPrefix is the prefix of SSE data lines, and sseDone the data of
+// the stream termination event.
+var (
+	sseDataPrefix = []byte("data: ")
+	sseDone       = []byte("[DONE]")
+)
+
+// Fields whose presence in a chunk requires decoding it on the passthrough
+// path. Chunks where they are null are not decoded.
+var (
+	finishReasonField = []byte(`"finish_reason"`)
+	finishReasonNull  = []byte(`"finish_reason":null`)
+	usageField        = []byte(`"usage"`)
+	usageNull         = []byte(`"usage":null`)
+)
+
 // streamReader reads Server-Sent Events (SSE) from OpenAI's streaming API.
 type streamReader struct {
 	provider *providers.HTTPProvider
@@ -74,33 +90,33 @@ func (s *streamReader) Read(ctx context.Context) (*providers.StreamChunk, error)
 			return nil, io.EOF
 		}
 
-		line := s.scanner.Text()
+		line := s.scanner.Bytes()
 
 		// Skip empty lines
-		if line == "" {
+		if len(line) == 0 {
 			continue
 		}
 
 		// Parse SSE line
-		if !strings.HasPrefix(line, "data: ") {
+		if !bytes.HasPrefix(line, sseDataPrefix) {
 			// Skip non-data lines (comments, event types, etc.)
 			continue
 		}
 
 		// Extract data
-		data := strings.TrimPrefix(line, "data: ")
+		data := line[len(sseDataPrefix):]
 
 		// Check for stream termination
-		if data == "[DONE]" {
+		if bytes.Equal(data, sseDone) {
 			return nil, io.EOF
 		}
 
 		// Parse JSON chunk
 		var openaiChunk OpenAIStreamResponse
-		if err := json.Unmarshal([]byte(data), &openaiChunk); err != nil {
+		if err := json.Unmarshal(data, &openaiChunk); err != nil {
 			return nil, &providers.ParseError{
 				Provider:    s.provider.GetName(),
-				RawResponse: data,
+				RawResponse: string(data),
 				Cause:       fmt.Errorf("failed to parse stream chunk: %w", err),
 			}
 		}
@@ -118,6 +134,86 @@ func (s *streamReader) Read(ctx context.Context) (*providers.StreamChunk, error)
 	}
 }
 
+// ReadRaw reads the next chunk from the stream without decoding it, except
+// for chunks that carry a finish reason or usage.
+// Returns nil, io.EOF when the stream ends normally.
+// Returns nil, error if an error occurs.
+func (s *streamReader) ReadRaw(ctx context.Context) (*providers.RawStreamChunk, error) {
+	if s.closed {
+		return nil, io.EOF
+	}
+
+	for {
+		// Check context cancellation
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		if !s.scanner.Scan() {
+			if err := s.scanner.Err(); err != nil {
+				return nil, &providers.StreamError{
+					Provider: s.provider.GetName(),
+					Message:  "failed to read stream",
+					Cause:    err,
+				}
+			}
+			return nil, io.EOF
+		}
+
+		line := s.scanner.Bytes()
+		if !bytes.HasPrefix(line, sseDataPrefix) {
+			// Skip empty and non-data lines
+			continue
+		}
+		data := line[len(sseDataPrefix):]
+		if bytes.Equal(data, sseDone) {
+			return nil, io.EOF
+		}
+
+		// The scanner reuses its buffer, so the data is copied
+		chunk := providers.NewRawStreamChunk(data)
+		if needsDecoding(data) {
+			var metadata openAIStreamMetadata
+			if err := json.Unmarshal(data, &metadata); err != nil {
+				chunk.Release()
+				return nil, &providers.ParseError{
+					Provider:    s.provider.GetName(),
+					RawResponse: string(data),
+					Cause:       fmt.Errorf("failed to parse stream chunk: %w", err),
+				}
+			}
+			if len(metadata.Choices) > 0 && metadata.Choices[0].FinishReason != "" {
+				chunk.FinishReason = normalizeFinishReason(metadata.Choices[0].FinishReason)
+			}
+			if metadata.Usage != nil {
+				usage := tokenUsage(metadata.Usage, metadata.ServiceTier)
+				chunk.Usage = &usage
+			}
+		}
+		return chunk, nil
+	}
+}
+
+// openAIStreamMetadata holds the fields of a stream chunk decoded on the
+// passthrough path.
+type openAIStreamMetadata struct {
+	Choices []struct {
+		FinishReason string `json:"finish_reason"`
+	} `json:"choices"`
+	Usage       *OpenAIUsage `json:"usage"`
+	ServiceTier string       `json:"service_tier"`
+}
+
+// needsDecoding reports whether a chunk may carry a finish reason or usage.
+// It may report chunks that carry neither, such as chunks formatted with
+// spaces after colons; those are decoded needlessly.
+func needsDecoding(data []byte) bool {
+	return (bytes.Contains(data, finishReasonField) && !bytes.Contains(data, finishReasonNull)) ||
+		(bytes.Contains(data, usageField) && !bytes.Contains(data, usageNull))
+}
+
 // Close closes the stream and releases resources.
 func (s *streamReader) Close() error {
 	if s.closed {
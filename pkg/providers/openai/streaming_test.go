@@ -611,3 +611,79 @@ func TestOpenAI_StreamingValidation(t *testing.T) {
 		})
 	}
 }
+
+// TestOpenAI_StreamCompletionRaw verifies that raw chunks are forwarded as
+// received, with the finish reason and the usage sent after it decoded
+func TestOpenAI_StreamCompletionRaw(t *testing.T) {
+	chunks := []string{
+		`{"id":"chatcmpl-123","object":"chat.completion.chunk","created":1234567890,"model":"gpt-4","system_fingerprint":"fp_1","choices":[{"index":0,"delta":{"role":"assistant","content":"Hello"},"finish_reason":null}]}`,
+		`{"id":"chatcmpl-123","object":"chat.completion.chunk","created":1234567890,"model":"gpt-4","choices":[{"index":0,"delta":{},"finish_reason":"stop"}],"usage":null}`,
+		`{"id":"chatcmpl-123","object":"chat.completion.chunk","created":1234567890,"model":"gpt-4","choices":[],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`,
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": keep-alive\n\n")
+		for _, chunk := range chunks {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer server.Close()
+
+	provider, err := NewProvider(providers.ProviderConfig{
+		Name:    "openai-test",
+		Type:    "openai",
+		BaseURL: server.URL,
+		APIKey:  "test-api-key",
+		Timeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	stream, err := provider.StreamCompletionRaw(context.Background(), &providers.CompletionRequest{
+		Model:    "gpt-4",
+		Messages: []providers.Message{{Role: providers.RoleUser, Content: "Say hello"}},
+		Stream:   true,
+	})
+	if err != nil {
+		t.Fatalf("failed to start stream: %v", err)
+	}
+
+	var received []*providers.RawStreamChunk
+	for chunk := range stream {
+		if chunk.Error != nil {
+			t.Fatalf("unexpected error: %v", chunk.Error)
+		}
+		received = append(received, chunk)
+	}
+	if len(received) != len(chunks) {
+		t.Fatalf("expected %d chunks, got %d", len(chunks), len(received))
+	}
+
+	for i, chunk := range received {
+		if string(chunk.Data()) != chunks[i] {
+			t.Errorf("chunk %d: expected %s, got %s", i, chunks[i], chunk.Data())
+		}
+		if want := "data: " + chunks[i] + "\n\n"; string(chunk.SSE()) != want {
+			t.Errorf("chunk %d: expected SSE event %q, got %q", i, want, chunk.SSE())
+		}
+	}
+	if received[0].FinishReason != "" || received[0].Usage != nil {
+		t.Errorf("chunk 0: expected no finish reason or usage, got %+v", received[0])
+	}
+	if received[1].FinishReason != providers.FinishReasonStop || received[1].Usage != nil {
+		t.Errorf("chunk 1: expected finish reason only, got %+v", received[1])
+	}
+	if usage := received[2].Usage; usage == nil || usage.TotalTokens != 15 {
+		t.Errorf("chunk 2: expected usage with 15 total tokens, got %+v", usage)
+	}
+
+	for _, chunk := range received {
+		chunk.Release()
+		if chunk.SSE() != nil {
+			t.Error("expected no data after Release")
+		}
+	}
+}
@@ -69,6 +69,10 @@ type OpenAIResponse struct {
 	Model   string         `json:"model"`
 	Choices []OpenAIChoice `json:"choices"`
 	Usage   OpenAIUsage    `json:"usage"`
+
+	// ServiceTier is the processing tier that served the request, such as
+	// "default" or "flex"
+	ServiceTier string `json:"service_tier,omitempty"`
 }
 
 // OpenAIChoice represents a completion choice in OpenAI format.
@@ -80,9 +84,30 @@ type OpenAIChoice struct {
 
 // OpenAIUsage represents token usage in OpenAI format.
 type OpenAIUsage struct {
-	PromptTokens     int `json:"prompt_tokens"`
-	CompletionTokens int `json:"completion_tokens"`
-	TotalTokens      int `json:"total_tokens"`
+	PromptTokens        int                  `json:"prompt_tokens"`
+	CompletionTokens    int                  `json:"completion_tokens"`
+	TotalTokens         int                  `json:"total_tokens"`
+	PromptTokensDetails *OpenAIPromptDetails `json:"prompt_tokens_details,omitempty"`
+}
+
+// OpenAIPromptDetails breaks down the prompt tokens in OpenAI format.
+type OpenAIPromptDetails struct {
+	CachedTokens int `json:"cached_tokens"`
+}
+
+// tokenUsage transforms OpenAI token usage to provider-agnostic format.
+// Flex processing is billed at the batch rate.
+func tokenUsage(usage *OpenAIUsage, serviceTier string) providers.TokenUsage {
+	result := providers.TokenUsage{
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens,
+		Batch:            serviceTier == "flex",
+	}
+	if usage.PromptTokensDetails != nil {
+		result.CachedPromptTokens = usage.PromptTokensDetails.CachedTokens
+	}
+	return result
 }
 
 // OpenAI streaming response types
@@ -95,6 +120,9 @@ type OpenAIStreamResponse struct {
 	Model   string               `json:"model"`
 	Choices []OpenAIStreamChoice `json:"choices"`
 	Usage   *OpenAIUsage         `json:"usage,omitempty"`
+
+	// ServiceTier is the processing tier that served the request
+	ServiceTier string `json:"service_tier,omitempty"`
 }
 
 // OpenAIStreamChoice represents a choice in a stream chunk.
@@ -172,13 +200,9 @@ func transformResponse(resp *OpenAIResponse) (*providers.CompletionResponse, err
 		Model:        resp.Model,
 		Content:      choice.Message.Content,
 		FinishReason: normalizeFinishReason(choice.FinishReason),
-		Usage: providers.TokenUsage{
-			PromptTokens:     resp.Usage.PromptTokens,
-			CompletionTokens: resp.Usage.CompletionTokens,
-			TotalTokens:      resp.Usage.TotalTokens,
-		},
-		Created:  resp.Created,
-		Metadata: make(map[string]string),
+		Usage:        tokenUsage(&resp.Usage, resp.ServiceTier),
+		Created:      resp.Created,
+		Metadata:     make(map[string]string),
 	}
 
 	// Transform tool calls if present
@@ -217,11 +241,8 @@ func transformStreamChunk(chunk *OpenAIStreamResponse) (*providers.StreamChunk,
 
 	// Include usage if present (final chunk)
 	if chunk.Usage != nil {
-		result.Usage = &providers.TokenUsage{
-			PromptTokens:     chunk.Usage.PromptTokens,
-			CompletionTokens: chunk.Usage.CompletionTokens,
-			TotalTokens:      chunk.Usage.TotalTokens,
-		}
+		usage := tokenUsage(chunk.Usage, chunk.ServiceTier)
+		result.Usage = &usage
 	}
 
 	// Transform tool calls if present
@@ -0,0 +1,65 @@
+package providers
+
+import "strings"
+
+// Prompt format constants, the chat templates of providers that take a
+// single prompt rather than messages
+const (
+	PromptFormatChatML  = "chatml"
+	PromptFormatLlama3  = "llama3"
+	PromptFormatMistral = "mistral"
+)
+
+// IsValidPromptFormat reports whether format is a supported prompt format.
+func IsValidPromptFormat(format string) bool {
+	switch format {
+	case PromptFormatChatML, PromptFormatLlama3, PromptFormatMistral:
+		return true
+	default:
+		return false
+	}
+}
+
+// RenderPrompt renders messages as a prompt in the chat template of the
+// format (ChatML for unknown formats), ending with the start of the
+// assistant turn. The beginning of sequence token is left to the
+// tokenizer of the model.
+func RenderPrompt(messages []Message, format string) string {
+	var prompt strings.Builder
+
+	switch format {
+	case PromptFormatLlama3:
+		for _, msg := range messages {
+			prompt.WriteString("<|start_header_id|>" + msg.Role + "<|end_header_id|>\n\n" + msg.Content + "<|eot_id|>")
+		}
+		prompt.WriteString("<|start_header_id|>assistant<|end_header_id|>\n\n")
+
+	case PromptFormatMistral:
+		// Mistral has no system role: system messages are prepended to the
+		// next user message
+		var system []string
+		for _, msg := range messages {
+			switch msg.Role {
+			case RoleSystem:
+				system = append(system, msg.Content)
+			case RoleAssistant:
+				prompt.WriteString(msg.Content + "</s>")
+			default:
+				content := msg.Content
+				if len(system) > 0 {
+					content = strings.Join(append(system, content), "\n\n")
+					system = nil
+				}
+				prompt.WriteString("[INST] " + content + " [/INST]")
+			}
+		}
+
+	default:
+		for _, msg := range messages {
+			prompt.WriteString("<|im_start|>" + msg.Role + "\n" + msg.Content + "<|im_end|>\n")
+		}
+		prompt.WriteString("<|im_start|>assistant\n")
+	}
+
+	return prompt.String()
+}
@@ -0,0 +1,51 @@
+package providers
+
+import "testing"
+
+func TestRenderPrompt(t *testing.T) {
+	messages := []Message{
+		{Role: RoleSystem, Content: "Be brief."},
+		{Role: RoleUser, Content: "Hi"},
+		{Role: RoleAssistant, Content: "Hello!"},
+		{Role: RoleUser, Content: "Capital of France?"},
+	}
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: PromptFormatChatML,
+			want: "<|im_start|>system\nBe brief.<|im_end|>\n<|im_start|>user\nHi<|im_end|>\n" +
+				"<|im_start|>assistant\nHello!<|im_end|>\n<|im_start|>user\nCapital of France?<|im_end|>\n" +
+				"<|im_start|>assistant\n",
+		},
+		{
+			format: PromptFormatLlama3,
+			want: "<|start_header_id|>system<|end_header_id|>\n\nBe brief.<|eot_id|>" +
+				"<|start_header_id|>user<|end_header_id|>\n\nHi<|eot_id|>" +
+				"<|start_header_id|>assistant<|end_header_id|>\n\nHello!<|eot_id|>" +
+				"<|start_header_id|>user<|end_header_id|>\n\nCapital of France?<|eot_id|>" +
+				"<|start_header_id|>assistant<|end_header_id|>\n\n",
+		},
+		{
+			format: PromptFormatMistral,
+			want:   "[INST] Be brief.\n\nHi [/INST]Hello!</s>[INST] Capital of France? [/INST]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			if !IsValidPromptFormat(tt.format) {
+				t.Errorf("IsValidPromptFormat(%q) = false, want true", tt.format)
+			}
+			if prompt := RenderPrompt(messages, tt.format); prompt != tt.want {
+				t.Errorf("RenderPrompt() = %q, want %q", prompt, tt.want)
+			}
+		})
+	}
+
+	if IsValidPromptFormat("alpaca") {
+		t.Error("IsValidPromptFormat(\"alpaca\") = true, want false")
+	}
+}
@@ -102,3 +102,15 @@ type StreamReader interface {
 	// Close closes the stream and releases resources.
 	Close() error
 }
+
+// RawStreamer is implemented by providers that stream responses in the
+// OpenAI chat completion chunk format. Their chunks can be forwarded to
+// clients as received, without being decoded into StreamChunks and encoded
+// again, when nothing needs to inspect or rewrite the stream.
+type RawStreamer interface {
+	// StreamCompletionRaw sends a streaming completion request to the
+	// provider, like StreamCompletion, and returns the chunks undecoded.
+	// Errors are set in the Error field of the final chunk. Callers release
+	// each chunk once it has been written.
+	StreamCompletionRaw(ctx context.Context, req *CompletionRequest) (<-chan *RawStreamChunk, error)
+}
@@ -0,0 +1,402 @@
+package replicate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"mercator-hq/jupiter/pkg/providers"
+)
+
+// Provider is the Replicate provider adapter.
+// It implements the providers.Provider interface for the prediction API of
+// Replicate: completions create a prediction and wait for its output,
+// polling it or streaming it as Server-Sent Events.
+type Provider struct {
+	*providers.HTTPProvider
+
+	// format is the chat template that messages are rendered with, or ""
+	// to use the template of the model
+	format string
+
+	// pollInterval is the interval between polls of a prediction
+	pollInterval time.Duration
+
+	// wait is the Prefer header of prediction requests, which holds them
+	// until the output is ready, or "" to poll from the start
+	wait string
+}
+
+const (
+	// defaultBaseURL is the Replicate API endpoint
+	defaultBaseURL = "https://api.replicate.com/v1"
+
+	// defaultPollInterval is the interval between polls of a prediction
+	defaultPollInterval = 500 * time.Millisecond
+
+	// syncWait is how long prediction requests wait for the output before
+	// the adapter polls
+	syncWait = 30 * time.Second
+)
+
+// NewProvider creates a new Replicate provider instance.
+func NewProvider(config providers.ProviderConfig) (*Provider, error) {
+	// Validate configuration
+	if config.Name == "" {
+		return nil, &providers.ConfigError{
+			Provider: "replicate",
+			Field:    "name",
+			Message:  "provider name is required",
+		}
+	}
+
+	if config.APIKey == "" {
+		return nil, &providers.ConfigError{
+			Provider: config.Name,
+			Field:    "api_key",
+			Message:  "API key is required for Replicate",
+		}
+	}
+
+	if config.PromptFormat != "" && !providers.IsValidPromptFormat(config.PromptFormat) {
+		return nil, &providers.ConfigError{
+			Provider: config.Name,
+			Field:    "prompt_format",
+			Message:  fmt.Sprintf("unsupported prompt format %q (supported: chatml, llama3, mistral)", config.PromptFormat),
+		}
+	}
+
+	if config.BaseURL == "" {
+		config.BaseURL = defaultBaseURL
+	}
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
+
+	// Set defaults if not provided
+	if config.MaxRetries == 0 {
+		config.MaxRetries = 3
+	}
+	if config.MaxIdleConns == 0 {
+		config.MaxIdleConns = 100
+	}
+	if config.MaxIdleConnsPerHost == 0 {
+		config.MaxIdleConnsPerHost = 10
+	}
+
+	// Create base HTTP provider. Health checks read the account of the API
+	// token.
+	httpProvider := providers.NewHTTPProvider(config)
+	httpProvider.SetHealthCheckURL(config.BaseURL + "/account")
+
+	p := &Provider{
+		HTTPProvider: httpProvider,
+		format:       config.PromptFormat,
+		pollInterval: defaultPollInterval,
+	}
+
+	// Wait for the output in the prediction request, unless the request
+	// would time out first
+	if config.Timeout == 0 || config.Timeout > syncWait {
+		p.wait = fmt.Sprintf("wait=%d", int(syncWait.Seconds()))
+	}
+
+	slog.Info("Replicate provider initialized",
+		"provider", config.Name,
+		"base_url", config.BaseURL,
+	)
+
+	return p, nil
+}
+
+// SendCompletion creates a prediction and waits for its output.
+func (p *Provider) SendCompletion(ctx context.Context, req *providers.CompletionRequest) (*providers.CompletionResponse, error) {
+	// Validate request
+	if err := validateRequest(req); err != nil {
+		return nil, err
+	}
+
+	// Create prediction
+	prediction, err := p.createPrediction(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Poll until the prediction completes
+	for !prediction.done() {
+		select {
+		case <-ctx.Done():
+			p.cancelPrediction(ctx, prediction)
+			return nil, &providers.TimeoutError{
+				Provider: p.GetName(),
+				Timeout:  p.GetConfig().Timeout,
+			}
+		case <-time.After(p.pollInterval):
+		}
+
+		next, err := p.getPrediction(ctx, prediction)
+		if err != nil {
+			if ctx.Err() != nil {
+				p.cancelPrediction(ctx, prediction)
+			}
+			return nil, err
+		}
+		prediction = next
+	}
+
+	if err := p.predictionError(prediction); err != nil {
+		return nil, err
+	}
+
+	completion := &providers.CompletionResponse{
+		ID:           prediction.ID,
+		Model:        req.Model,
+		Content:      prediction.text(),
+		FinishReason: prediction.finishReason(req.MaxTokens),
+		Usage:        prediction.tokenUsage(),
+		Created:      time.Now().Unix(),
+	}
+
+	slog.Debug("completion request succeeded",
+		"provider", p.GetName(),
+		"model", completion.Model,
+		"prediction", prediction.ID,
+		"tokens", completion.Usage.TotalTokens,
+	)
+
+	return completion, nil
+}
+
+// StreamCompletion creates a prediction and streams its output. The
+// Server-Sent Events of the stream URL of the prediction are normalized to
+// stream chunks.
+func (p *Provider) StreamCompletion(ctx context.Context, req *providers.CompletionRequest) (<-chan *providers.StreamChunk, error) {
+	// Validate request
+	if err := validateRequest(req); err != nil {
+		return nil, err
+	}
+
+	// Create prediction
+	prediction, err := p.createPrediction(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+	if prediction.URLs.Stream == "" {
+		p.cancelPrediction(ctx, prediction)
+		return nil, &providers.ProviderError{
+			Provider: p.GetName(),
+			Message:  fmt.Sprintf("model %q does not support streaming", req.Model),
+		}
+	}
+
+	// Create stream reader
+	stream, err := newStreamReader(ctx, p, prediction, req)
+	if err != nil {
+		p.cancelPrediction(ctx, prediction)
+		return nil, err
+	}
+
+	// Create output channel
+	chunks := make(chan *providers.StreamChunk, 100) // Buffered channel
+
+	// Start goroutine to read stream and send chunks
+	go func() {
+		defer close(chunks)
+		defer stream.Close()
+
+		for {
+			chunk, err := stream.Read(ctx)
+			if err == io.EOF {
+				// Stream ended normally
+				return
+			}
+			if err != nil {
+				// Send error chunk and exit
+				if ctx.Err() != nil {
+					p.cancelPrediction(ctx, prediction)
+				}
+				select {
+				case chunks <- &providers.StreamChunk{Error: err}:
+				case <-ctx.Done():
+				}
+				return
+			}
+
+			// Send chunk
+			select {
+			case chunks <- chunk:
+			case <-ctx.Done():
+				p.cancelPrediction(ctx, prediction)
+				return
+			}
+
+			// Check if this is the final chunk
+			if chunk.FinishReason != "" {
+				return
+			}
+		}
+	}()
+
+	return chunks, nil
+}
+
+// GetType returns "replicate" as the provider type.
+func (p *Provider) GetType() string {
+	return "replicate"
+}
+
+// createPrediction creates a prediction of the model of the request.
+// Models are given as "owner/name", or "owner/name:version" for a version
+// of a model.
+func (p *Provider) createPrediction(ctx context.Context, req *providers.CompletionRequest, stream bool) (*Prediction, error) {
+	predictionReq := transformRequest(req, p.format)
+	predictionReq.Stream = stream
+
+	endpoint := p.GetConfig().BaseURL + "/predictions"
+	if _, version, ok := strings.Cut(req.Model, ":"); ok {
+		predictionReq.Version = version
+	} else {
+		owner, model, _ := strings.Cut(req.Model, "/")
+		endpoint = fmt.Sprintf("%s/models/%s/%s/predictions", p.GetConfig().BaseURL, url.PathEscape(owner), url.PathEscape(model))
+	}
+
+	bodyBytes, err := json.Marshal(predictionReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	headers := p.headers("application/json")
+	if !stream && p.wait != "" {
+		headers["Prefer"] = p.wait
+	}
+	return p.doPrediction(ctx, "POST", endpoint, bodyBytes, headers)
+}
+
+// getPrediction returns the current state of a prediction.
+func (p *Provider) getPrediction(ctx context.Context, prediction *Prediction) (*Prediction, error) {
+	getURL := prediction.URLs.Get
+	if getURL == "" {
+		getURL = p.GetConfig().BaseURL + "/predictions/" + url.PathEscape(prediction.ID)
+	}
+	return p.doPrediction(ctx, "GET", getURL, nil, p.headers("application/json"))
+}
+
+// cancelPrediction cancels a prediction that is no longer awaited, so that
+// it is not billed to completion. Failures are logged only.
+func (p *Provider) cancelPrediction(ctx context.Context, prediction *Prediction) {
+	cancelURL := prediction.URLs.Cancel
+	if cancelURL == "" {
+		cancelURL = p.GetConfig().BaseURL + "/predictions/" + url.PathEscape(prediction.ID) + "/cancel"
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	resp, err := p.DoRequest(ctx, "POST", cancelURL, nil, p.headers("application/json"))
+	if err != nil {
+		slog.Warn("failed to cancel prediction",
+			"provider", p.GetName(),
+			"prediction", prediction.ID,
+			"error", err,
+		)
+		return
+	}
+	resp.Body.Close()
+}
+
+// doPrediction performs a request returning a prediction.
+func (p *Provider) doPrediction(ctx context.Context, method, url string, body []byte, headers map[string]string) (*Prediction, error) {
+	resp, err := p.DoRequest(ctx, method, url, body, headers)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	responseBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &providers.ParseError{
+			Provider: p.GetName(),
+			Cause:    fmt.Errorf("failed to read response: %w", err),
+		}
+	}
+
+	var prediction Prediction
+	if err := json.Unmarshal(responseBytes, &prediction); err != nil {
+		return nil, &providers.ParseError{
+			Provider:    p.GetName(),
+			RawResponse: string(responseBytes),
+			Cause:       fmt.Errorf("failed to unmarshal prediction: %w", err),
+		}
+	}
+	return &prediction, nil
+}
+
+// predictionError returns the error of a failed or canceled prediction.
+func (p *Provider) predictionError(prediction *Prediction) error {
+	switch prediction.Status {
+	case statusFailed:
+		return &providers.ProviderError{
+			Provider: p.GetName(),
+			Message:  fmt.Sprintf("prediction %s failed: %s", prediction.ID, prediction.Error),
+		}
+	case statusCanceled:
+		return &providers.ProviderError{
+			Provider: p.GetName(),
+			Message:  fmt.Sprintf("prediction %s was canceled", prediction.ID),
+		}
+	default:
+		return nil
+	}
+}
+
+// headers returns the headers of a request accepting the content type.
+func (p *Provider) headers(accept string) map[string]string {
+	return map[string]string{
+		"Authorization": "Bearer " + p.GetConfig().APIKey,
+		"Content-Type":  "application/json",
+		"Accept":        accept,
+	}
+}
+
+// validateRequest validates the completion request.
+func validateRequest(req *providers.CompletionRequest) error {
+	if req == nil {
+		return &providers.ValidationError{
+			Field:   "request",
+			Message: "request cannot be nil",
+		}
+	}
+
+	if req.Model == "" {
+		return &providers.ValidationError{
+			Field:   "model",
+			Message: "model is required",
+		}
+	}
+
+	if name, _, _ := strings.Cut(req.Model, ":"); !strings.Contains(name, "/") {
+		return &providers.ValidationError{
+			Field:   "model",
+			Message: "model must be a Replicate model name (owner/name or owner/name:version)",
+		}
+	}
+
+	if len(req.Messages) == 0 {
+		return &providers.ValidationError{
+			Field:   "messages",
+			Message: "at least one message is required",
+		}
+	}
+
+	// Predictions take a prompt, without function calling
+	if len(req.Tools) > 0 {
+		return &providers.ValidationError{
+			Field:   "tools",
+			Message: "tools are not supported by Replicate predictions",
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,337 @@
+package replicate
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/providers"
+)
+
+const testModel = "meta/meta-llama-3-8b-instruct"
+
+func newTestProvider(t *testing.T, baseURL, format string) *Provider {
+	t.Helper()
+	provider, err := NewProvider(providers.ProviderConfig{
+		Name:         "replicate",
+		Type:         "replicate",
+		BaseURL:      baseURL,
+		APIKey:       "r8_test",
+		PromptFormat: format,
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	provider.pollInterval = time.Millisecond
+	return provider
+}
+
+// fakeReplicate serves the prediction API for a single prediction, which
+// completes after polls polls.
+type fakeReplicate struct {
+	t      *testing.T
+	server *httptest.Server
+
+	// polls is the number of polls before the prediction completes
+	polls int32
+
+	// completed is the prediction once completed
+	completed string
+
+	// stream is the Server-Sent Events of the stream URL
+	stream string
+
+	// created is the request that created the prediction, and prefer its
+	// Prefer header
+	created PredictionRequest
+	path    string
+	prefer  string
+
+	canceled atomic.Bool
+}
+
+func newFakeReplicate(t *testing.T, polls int32, completed string) *fakeReplicate {
+	f := &fakeReplicate{t: t, polls: polls, completed: completed}
+	f.server = httptest.NewServer(http.HandlerFunc(f.serve))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+// prediction returns the prediction with the status and fields.
+func (f *fakeReplicate) prediction(status, fields string) string {
+	return fmt.Sprintf(`{"id": "p1", "model": %q, "status": %q, "urls": {"get": "%s/predictions/p1", "cancel": "%s/predictions/p1/cancel", "stream": "%s/stream/p1"}%s}`,
+		testModel, status, f.server.URL, f.server.URL, f.server.URL, fields)
+}
+
+func (f *fakeReplicate) serve(w http.ResponseWriter, r *http.Request) {
+	if authorization := r.Header.Get("Authorization"); authorization != "Bearer r8_test" {
+		f.t.Errorf("Authorization = %q, want the API token", authorization)
+	}
+
+	switch {
+	case r.Method == "POST" && strings.HasSuffix(r.URL.Path, "/cancel"):
+		f.canceled.Store(true)
+		_, _ = w.Write([]byte(f.prediction(statusCanceled, "")))
+
+	case r.Method == "POST":
+		f.path = r.URL.Path
+		f.prefer = r.Header.Get("Prefer")
+		if err := json.NewDecoder(r.Body).Decode(&f.created); err != nil {
+			f.t.Errorf("failed to decode request: %v", err)
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(f.prediction(statusStarting, "")))
+
+	case r.URL.Path == "/predictions/p1":
+		if atomic.AddInt32(&f.polls, -1) >= 0 {
+			_, _ = w.Write([]byte(f.prediction(statusProcessing, "")))
+			return
+		}
+		_, _ = w.Write([]byte(f.completed))
+
+	case r.URL.Path == "/stream/p1":
+		if accept := r.Header.Get("Accept"); accept != "text/event-stream" {
+			f.t.Errorf("Accept = %q, want text/event-stream", accept)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte(f.stream))
+
+	default:
+		f.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestReplicateProvider_SendCompletion(t *testing.T) {
+	f := newFakeReplicate(t, 2, "")
+	f.completed = f.prediction(statusSucceeded, `, "output": ["Paris", " is the", " capital."], "metrics": {"predict_time": 0.8, "input_token_count": 20, "output_token_count": 6}`)
+
+	provider := newTestProvider(t, f.server.URL+"/", "")
+	resp, err := provider.SendCompletion(context.Background(), &providers.CompletionRequest{
+		Model: testModel,
+		Messages: []providers.Message{
+			{Role: providers.RoleSystem, Content: "Be brief."},
+			{Role: providers.RoleUser, Content: "Capital of France?"},
+		},
+		MaxTokens:         64,
+		TopK:              40,
+		RepetitionPenalty: 1.1,
+		Stop:              []string{"\n\n", "###"},
+	})
+	if err != nil {
+		t.Fatalf("SendCompletion failed: %v", err)
+	}
+
+	// Verify request
+	if f.path != "/models/meta/meta-llama-3-8b-instruct/predictions" || f.prefer != "wait=30" {
+		t.Errorf("create request = %s with Prefer %q, want the model predictions endpoint and wait=30", f.path, f.prefer)
+	}
+	want := PredictionInput{
+		Prompt:            "Capital of France?",
+		SystemPrompt:      "Be brief.",
+		MaxTokens:         64,
+		TopK:              40,
+		RepetitionPenalty: 1.1,
+		StopSequences:     "\n\n,###",
+	}
+	if f.created.Input != want || f.created.Version != "" || f.created.Stream {
+		t.Errorf("request = %+v, want input %+v", f.created, want)
+	}
+
+	// Verify response
+	if resp.ID != "p1" || resp.Model != testModel || resp.Content != "Paris is the capital." || resp.FinishReason != providers.FinishReasonStop {
+		t.Errorf("response = %+v, want the output of the prediction", resp)
+	}
+	if resp.Usage.PromptTokens != 20 || resp.Usage.CompletionTokens != 6 || resp.Usage.TotalTokens != 26 {
+		t.Errorf("usage = %+v, want the token counts of the metrics", resp.Usage)
+	}
+}
+
+func TestReplicateProvider_SendCompletion_Version(t *testing.T) {
+	f := newFakeReplicate(t, 0, "")
+	f.completed = f.prediction(statusSucceeded, `, "output": "Hello", "metrics": {"input_token_count": 5, "output_token_count": 8}`)
+
+	resp, err := newTestProvider(t, f.server.URL, "llama3").SendCompletion(context.Background(), &providers.CompletionRequest{
+		Model:     "acme/llama-ft:5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa",
+		Messages:  []providers.Message{{Role: providers.RoleUser, Content: "Hi"}},
+		MaxTokens: 8,
+	})
+	if err != nil {
+		t.Fatalf("SendCompletion failed: %v", err)
+	}
+
+	if f.path != "/predictions" || f.created.Version != "5c7d5dc6dd8bf75c1acaa8565735e7986bc5b66206b55cca93cb72c9bf15ccaa" {
+		t.Errorf("create request = %s with version %q, want the predictions endpoint and the version", f.path, f.created.Version)
+	}
+	wantPrompt := "<|start_header_id|>user<|end_header_id|>\n\nHi<|eot_id|><|start_header_id|>assistant<|end_header_id|>\n\n"
+	if f.created.Input.Prompt != wantPrompt || f.created.Input.PromptTemplate != "{prompt}" {
+		t.Errorf("input = %+v, want the rendered prompt sent as is", f.created.Input)
+	}
+	if resp.Content != "Hello" || resp.FinishReason != providers.FinishReasonLength {
+		t.Errorf("response = %+v, want the string output cut off at max_tokens", resp)
+	}
+}
+
+func TestReplicateProvider_SendCompletion_Failed(t *testing.T) {
+	f := newFakeReplicate(t, 1, "")
+	f.completed = f.prediction(statusFailed, `, "error": "CUDA out of memory"`)
+
+	_, err := newTestProvider(t, f.server.URL, "").SendCompletion(context.Background(), &providers.CompletionRequest{
+		Model:    testModel,
+		Messages: []providers.Message{{Role: providers.RoleUser, Content: "Hi"}},
+	})
+	var providerErr *providers.ProviderError
+	if !errors.As(err, &providerErr) || !strings.Contains(providerErr.Message, "CUDA out of memory") {
+		t.Errorf("SendCompletion() error = %v, want the prediction error", err)
+	}
+}
+
+func TestReplicateProvider_SendCompletion_CancelsOnTimeout(t *testing.T) {
+	f := newFakeReplicate(t, 1<<20, "")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := newTestProvider(t, f.server.URL, "").SendCompletion(ctx, &providers.CompletionRequest{
+		Model:    testModel,
+		Messages: []providers.Message{{Role: providers.RoleUser, Content: "Hi"}},
+	})
+	if err == nil {
+		t.Fatal("SendCompletion() succeeded, want a timeout")
+	}
+	if !f.canceled.Load() {
+		t.Error("prediction was not canceled")
+	}
+}
+
+// collect reads all chunks of a stream.
+func collect(t *testing.T, chunks <-chan *providers.StreamChunk) ([]*providers.StreamChunk, string) {
+	t.Helper()
+	var all []*providers.StreamChunk
+	var text strings.Builder
+	for chunk := range chunks {
+		all = append(all, chunk)
+		text.WriteString(chunk.Delta)
+	}
+	return all, text.String()
+}
+
+func TestReplicateProvider_StreamCompletion(t *testing.T) {
+	f := newFakeReplicate(t, 1, "")
+	f.completed = f.prediction(statusSucceeded, `, "metrics": {"input_token_count": 12, "output_token_count": 4}`)
+	f.stream = "event: output\nid: 1\ndata: Hello\n\n" +
+		"event: output\nid: 2\ndata:  world\n\n" +
+		"event: output\nid: 3\ndata: !\ndata: Bye\n\n" +
+		"event: done\ndata: {}\n\n"
+
+	chunks, err := newTestProvider(t, f.server.URL, "").StreamCompletion(context.Background(), &providers.CompletionRequest{
+		Model:    testModel,
+		Messages: []providers.Message{{Role: providers.RoleUser, Content: "Hi"}},
+		Stream:   true,
+	})
+	if err != nil {
+		t.Fatalf("StreamCompletion failed: %v", err)
+	}
+
+	all, text := collect(t, chunks)
+	if !f.created.Stream || f.prefer != "" {
+		t.Errorf("create request = %+v with Prefer %q, want a streaming prediction without waiting", f.created, f.prefer)
+	}
+	if text != "Hello world!\nBye" || len(all) != 4 {
+		t.Fatalf("stream = %q in %d chunks, want the output in 4 chunks", text, len(all))
+	}
+	final := all[3]
+	if final.ID != "p1" || final.FinishReason != providers.FinishReasonStop || final.Usage == nil || final.Usage.TotalTokens != 16 {
+		t.Errorf("final chunk = %+v, want the finish reason and the usage of the prediction", final)
+	}
+}
+
+func TestReplicateProvider_StreamCompletion_Error(t *testing.T) {
+	f := newFakeReplicate(t, 0, "")
+	f.stream = "event: output\ndata: Hel\n\n" +
+		"event: error\ndata: {\"detail\": \"Prediction interrupted\"}\n\n"
+
+	chunks, err := newTestProvider(t, f.server.URL, "").StreamCompletion(context.Background(), &providers.CompletionRequest{
+		Model:    testModel,
+		Messages: []providers.Message{{Role: providers.RoleUser, Content: "Hi"}},
+	})
+	if err != nil {
+		t.Fatalf("StreamCompletion failed: %v", err)
+	}
+
+	all, text := collect(t, chunks)
+	var streamErr *providers.StreamError
+	if text != "Hel" || !errors.As(all[len(all)-1].Error, &streamErr) || !strings.Contains(streamErr.Message, "Prediction interrupted") {
+		t.Errorf("stream = %q ending with %v, want the output and the prediction error", text, all[len(all)-1].Error)
+	}
+}
+
+func TestTransformRequest_Transcript(t *testing.T) {
+	req := transformRequest(&providers.CompletionRequest{
+		Model: testModel,
+		Messages: []providers.Message{
+			{Role: providers.RoleSystem, Content: "Be brief."},
+			{Role: providers.RoleUser, Content: "Hi"},
+			{Role: providers.RoleAssistant, Content: "Hello!"},
+			{Role: providers.RoleUser, Content: "Capital of France?"},
+		},
+	}, "")
+
+	if req.Input.SystemPrompt != "Be brief." || req.Input.Prompt != "User: Hi\n\nAssistant: Hello!\n\nUser: Capital of France?" || req.Input.PromptTemplate != "" {
+		t.Errorf("input = %+v, want the system prompt and the transcript", req.Input)
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    providers.ProviderConfig
+		wantField string
+	}{
+		{
+			name:      "missing API key",
+			config:    providers.ProviderConfig{Name: "replicate"},
+			wantField: "api_key",
+		},
+		{
+			name:      "unsupported prompt format",
+			config:    providers.ProviderConfig{Name: "replicate", APIKey: "r8_test", PromptFormat: "alpaca"},
+			wantField: "prompt_format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewProvider(tt.config)
+			var configErr *providers.ConfigError
+			if !errors.As(err, &configErr) || configErr.Field != tt.wantField {
+				t.Errorf("NewProvider() error = %v, want a %s error", err, tt.wantField)
+			}
+		})
+	}
+
+	provider, err := NewProvider(providers.ProviderConfig{Name: "replicate", APIKey: "r8_test", Timeout: 20 * time.Second})
+	if err != nil {
+		t.Fatalf("NewProvider() error = %v", err)
+	}
+	if provider.GetConfig().BaseURL != defaultBaseURL || provider.wait != "" {
+		t.Errorf("base URL = %q, wait = %q, want the default base URL and no wait within a 20s timeout", provider.GetConfig().BaseURL, provider.wait)
+	}
+}
+
+func TestValidateRequest_Model(t *testing.T) {
+	err := validateRequest(&providers.CompletionRequest{
+		Model:    "llama-3-8b",
+		Messages: []providers.Message{{Role: providers.RoleUser, Content: "Hi"}},
+	})
+	var validationErr *providers.ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Field != "model" {
+		t.Errorf("validateRequest() error = %v, want a model error", err)
+	}
+}
@@ -0,0 +1,82 @@
+// Package replicate implements the Replicate provider adapter.
+//
+// This package provides an implementation of the providers.Provider interface
+// for the prediction API of Replicate. Replicate runs models asynchronously:
+// a request creates a prediction, which the adapter waits for and returns
+// as a completion, so that policies and evidence treat Replicate-hosted
+// models like those of any other provider. It supports:
+//
+//   - Official models ("owner/name") and model versions
+//     ("owner/name:version")
+//   - Polling and streaming (Server-Sent Events) of predictions
+//   - Cancellation of predictions that are no longer awaited
+//   - Token usage tracking from prediction metrics
+//
+// # Basic Usage
+//
+//	config := providers.ProviderConfig{
+//	    Name:   "replicate",
+//	    Type:   "replicate",
+//	    APIKey: "r8_...",
+//	}
+//
+//	provider, err := replicate.NewProvider(config)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer provider.Close()
+//
+//	req := &providers.CompletionRequest{
+//	    Model: "meta/meta-llama-3-8b-instruct",
+//	    Messages: []providers.Message{
+//	        {Role: "user", Content: "Hello!"},
+//	    },
+//	}
+//
+//	resp, err := provider.SendCompletion(context.Background(), req)
+//
+// # Request Mapping
+//
+// Replicate language models take a prompt rather than messages. By default,
+// system messages are sent as the system_prompt input and the conversation
+// as the prompt, to which the model applies its own chat template; the
+// turns of multi-turn conversations are prefixed with their role. With a
+// configured prompt format, the conversation is rendered in that chat
+// template instead (see providers.RenderPrompt), and the template of the
+// model is disabled.
+//
+// max_tokens, temperature, top_p, top_k, penalties, and repetition_penalty
+// are sent as the inputs of the same names, and stop sequences as the
+// comma-separated stop_sequences input. Predictions have no function
+// calling: requests with tools are rejected.
+//
+// # Predictions
+//
+// Completions create a prediction that waits up to 30 seconds for its
+// output (Prefer: wait), then poll it until it succeeds, fails, or is
+// canceled. Streaming completions read the stream URL of the prediction.
+// A prediction whose request is canceled or times out is canceled, so that
+// it is not billed to completion.
+//
+// # Response Mapping
+//
+// The ID of responses is the ID of the prediction. Replicate does not
+// report finish reasons: responses finish with stop, or length when they
+// generated max_tokens tokens. Token usage is taken from the metrics of the
+// prediction; for streams, the prediction is read once the stream ends.
+//
+// # Error Handling
+//
+// The adapter maps Replicate errors to common error types:
+//
+//   - 401/403 -> AuthError
+//   - 429 -> RateLimitError
+//   - 400/422 -> ProviderError (invalid input or model version)
+//   - 5xx -> ProviderError (retried automatically)
+//   - Failed and canceled predictions -> ProviderError, or StreamError
+//     during streaming
+//
+// # Health Checks
+//
+// Health checks read the account of the API token.
+package replicate
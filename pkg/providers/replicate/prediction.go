@@ -0,0 +1,174 @@
+package replicate
+
+import (
+	"encoding/json"
+	"strings"
+
+	"mercator-hq/jupiter/pkg/providers"
+)
+
+// PredictionRequest is a request to create a prediction.
+type PredictionRequest struct {
+	// Version is the model version, for versioned models
+	// ("owner/name:version")
+	Version string          `json:"version,omitempty"`
+	Input   PredictionInput `json:"input"`
+	Stream  bool            `json:"stream,omitempty"`
+}
+
+// PredictionInput is the input of a language model prediction. Replicate
+// language models share these input names.
+type PredictionInput struct {
+	Prompt       string `json:"prompt"`
+	SystemPrompt string `json:"system_prompt,omitempty"`
+
+	// PromptTemplate overrides the chat template of the model; "{prompt}"
+	// sends a prompt rendered by the adapter as is
+	PromptTemplate string `json:"prompt_template,omitempty"`
+
+	MaxTokens         int     `json:"max_tokens,omitempty"`
+	Temperature       float64 `json:"temperature,omitempty"`
+	TopP              float64 `json:"top_p,omitempty"`
+	TopK              int     `json:"top_k,omitempty"`
+	PresencePenalty   float64 `json:"presence_penalty,omitempty"`
+	FrequencyPenalty  float64 `json:"frequency_penalty,omitempty"`
+	RepetitionPenalty float64 `json:"repetition_penalty,omitempty"`
+
+	// StopSequences is a comma-separated list of stop sequences
+	StopSequences string `json:"stop_sequences,omitempty"`
+}
+
+// Prediction is a Replicate prediction.
+type Prediction struct {
+	ID     string `json:"id"`
+	Model  string `json:"model"`
+	Status string `json:"status"`
+
+	// Output is a list of tokens for language models, or a string
+	Output json.RawMessage `json:"output,omitempty"`
+	Error  string          `json:"error,omitempty"`
+
+	URLs    PredictionURLs `json:"urls"`
+	Metrics *Metrics       `json:"metrics,omitempty"`
+}
+
+// PredictionURLs are the API URLs of a prediction.
+type PredictionURLs struct {
+	Get    string `json:"get"`
+	Cancel string `json:"cancel"`
+
+	// Stream is the Server-Sent Events URL of the output, for models that
+	// support streaming
+	Stream string `json:"stream,omitempty"`
+}
+
+// Metrics are the metrics of a completed prediction.
+type Metrics struct {
+	PredictTime      float64 `json:"predict_time"`
+	InputTokenCount  int     `json:"input_token_count"`
+	OutputTokenCount int     `json:"output_token_count"`
+}
+
+// Prediction statuses
+const (
+	statusStarting   = "starting"
+	statusProcessing = "processing"
+	statusSucceeded  = "succeeded"
+	statusFailed     = "failed"
+	statusCanceled   = "canceled"
+)
+
+// done reports whether the prediction has completed, successfully or not.
+func (p *Prediction) done() bool {
+	return p.Status == statusSucceeded || p.Status == statusFailed || p.Status == statusCanceled
+}
+
+// text returns the output of the prediction as text.
+func (p *Prediction) text() string {
+	var tokens []string
+	if err := json.Unmarshal(p.Output, &tokens); err == nil {
+		return strings.Join(tokens, "")
+	}
+	var text string
+	if err := json.Unmarshal(p.Output, &text); err == nil {
+		return text
+	}
+	return ""
+}
+
+// tokenUsage returns the token usage of the metrics of the prediction.
+func (p *Prediction) tokenUsage() providers.TokenUsage {
+	if p.Metrics == nil {
+		return providers.TokenUsage{}
+	}
+	return providers.TokenUsage{
+		PromptTokens:     p.Metrics.InputTokenCount,
+		CompletionTokens: p.Metrics.OutputTokenCount,
+		TotalTokens:      p.Metrics.InputTokenCount + p.Metrics.OutputTokenCount,
+	}
+}
+
+// finishReason returns the finish reason of a succeeded prediction.
+// Replicate does not report one: predictions that generated max_tokens
+// tokens are assumed to be cut off.
+func (p *Prediction) finishReason(maxTokens int) string {
+	if maxTokens > 0 && p.Metrics != nil && p.Metrics.OutputTokenCount >= maxTokens {
+		return providers.FinishReasonLength
+	}
+	return providers.FinishReasonStop
+}
+
+// transformRequest transforms a provider-agnostic request to a prediction
+// request. With a prompt format, the whole conversation is rendered in its
+// chat template; otherwise system messages are sent as the system prompt,
+// and the model applies its own template to the prompt.
+func transformRequest(req *providers.CompletionRequest, format string) *PredictionRequest {
+	input := PredictionInput{
+		MaxTokens:         req.MaxTokens,
+		Temperature:       req.Temperature,
+		TopP:              req.TopP,
+		TopK:              req.TopK,
+		PresencePenalty:   req.PresencePenalty,
+		FrequencyPenalty:  req.FrequencyPenalty,
+		RepetitionPenalty: req.RepetitionPenalty,
+		StopSequences:     strings.Join(req.Stop, ","),
+	}
+
+	if format != "" {
+		input.Prompt = providers.RenderPrompt(req.Messages, format)
+		input.PromptTemplate = "{prompt}"
+	} else {
+		var system []string
+		var conversation []providers.Message
+		for _, msg := range req.Messages {
+			if msg.Role == providers.RoleSystem {
+				system = append(system, msg.Content)
+				continue
+			}
+			conversation = append(conversation, msg)
+		}
+		input.SystemPrompt = strings.Join(system, "\n\n")
+		input.Prompt = renderTranscript(conversation)
+	}
+
+	return &PredictionRequest{Input: input}
+}
+
+// renderTranscript renders a conversation as the prompt of a model that
+// applies its own chat template. A single message is sent as is; the turns
+// of longer conversations are prefixed with their role.
+func renderTranscript(messages []providers.Message) string {
+	if len(messages) == 1 {
+		return messages[0].Content
+	}
+
+	turns := make([]string, 0, len(messages))
+	for _, msg := range messages {
+		role := "User"
+		if msg.Role == providers.RoleAssistant {
+			role = "Assistant"
+		}
+		turns = append(turns, role+": "+msg.Content)
+	}
+	return strings.Join(turns, "\n\n")
+}
@@ -0,0 +1,222 @@
+package replicate
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"mercator-hq/jupiter/pkg/providers"
+)
+
+// streamState tracks state across stream chunks.
+type streamState struct {
+	model     string
+	created   int64
+	maxTokens int
+
+	// prediction is the streamed prediction
+	prediction *Prediction
+}
+
+// streamReader reads Server-Sent Events (SSE) from the stream URL of a
+// prediction. Output events carry text; the done event ends the stream.
+type streamReader struct {
+	provider *Provider
+	resp     io.ReadCloser
+	scanner  *bufio.Scanner
+	state    *streamState
+	closed   bool
+}
+
+// newStreamReader requests the stream of a prediction and returns a reader
+// of its events.
+func newStreamReader(ctx context.Context, provider *Provider, prediction *Prediction, req *providers.CompletionRequest) (*streamReader, error) {
+	headers := provider.headers("text/event-stream")
+	headers["Cache-Control"] = "no-store"
+	resp, err := provider.DoRequest(ctx, "GET", prediction.URLs.Stream, nil, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	return &streamReader{
+		provider: provider,
+		resp:     resp.Body,
+		scanner:  bufio.NewScanner(resp.Body),
+		state: &streamState{
+			model:      req.Model,
+			created:    time.Now().Unix(),
+			maxTokens:  req.MaxTokens,
+			prediction: prediction,
+		},
+	}, nil
+}
+
+// Read reads the next chunk from the stream.
+// Returns nil, io.EOF when the stream ends normally.
+// Returns nil, error if an error occurs.
+func (s *streamReader) Read(ctx context.Context) (*providers.StreamChunk, error) {
+	if s.closed {
+		return nil, io.EOF
+	}
+
+	for {
+		// Check context cancellation
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		// Read next SSE event
+		event, data, err := s.readEvent()
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, &providers.StreamError{
+				Provider: s.provider.GetName(),
+				Message:  "failed to read stream",
+				Cause:    err,
+			}
+		}
+
+		var chunk *providers.StreamChunk
+		switch event {
+		case "output":
+			if data == "" {
+				continue
+			}
+			chunk = &providers.StreamChunk{Delta: data}
+
+		case "error":
+			return nil, &providers.StreamError{
+				Provider: s.provider.GetName(),
+				Message:  fmt.Sprintf("prediction %s failed: %s", s.state.prediction.ID, data),
+			}
+
+		case "done":
+			chunk, err = s.finish(ctx, data)
+			if err != nil {
+				return nil, err
+			}
+
+		default:
+			// Ignore other events (e.g. logs)
+			continue
+		}
+
+		chunk.ID = s.state.prediction.ID
+		chunk.Model = s.state.model
+		chunk.Created = s.state.created
+		return chunk, nil
+	}
+}
+
+// finish returns the final chunk of the stream, with the finish reason and
+// the token usage, which are read from the completed prediction.
+func (s *streamReader) finish(ctx context.Context, data string) (*providers.StreamChunk, error) {
+	var done struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.Unmarshal([]byte(data), &done)
+	if done.Reason == statusCanceled {
+		return nil, &providers.StreamError{
+			Provider: s.provider.GetName(),
+			Message:  fmt.Sprintf("prediction %s was canceled", s.state.prediction.ID),
+		}
+	}
+
+	// The metrics of the prediction are set once it completes, which may
+	// be shortly after the stream ends
+	prediction := s.state.prediction
+	for {
+		var err error
+		prediction, err = s.provider.getPrediction(ctx, prediction)
+		if err != nil {
+			// The output was streamed: report it without usage
+			slog.Warn("failed to read prediction metrics",
+				"provider", s.provider.GetName(),
+				"prediction", s.state.prediction.ID,
+				"error", err,
+			)
+			return &providers.StreamChunk{FinishReason: providers.FinishReasonStop}, nil
+		}
+		if prediction.done() {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(s.provider.pollInterval):
+		}
+	}
+
+	if err := s.provider.predictionError(prediction); err != nil {
+		return nil, err
+	}
+
+	usage := prediction.tokenUsage()
+	return &providers.StreamChunk{
+		FinishReason: prediction.finishReason(s.state.maxTokens),
+		Usage:        &usage,
+	}, nil
+}
+
+// readEvent returns the type and data of the next SSE event.
+func (s *streamReader) readEvent() (string, string, error) {
+	var event string
+	var dataLines []string
+
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+
+		// Empty line marks end of event
+		if line == "" {
+			if event != "" || len(dataLines) > 0 {
+				break
+			}
+			continue
+		}
+
+		// Parse SSE field
+		switch {
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			dataLines = append(dataLines, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+		// Ignore other SSE fields (id, retry)
+	}
+
+	if err := s.scanner.Err(); err != nil {
+		return "", "", err
+	}
+
+	// No event found
+	if event == "" && len(dataLines) == 0 {
+		return "", "", io.EOF
+	}
+
+	// Events without a type are output events; multi-line data is
+	// output containing newlines
+	if event == "" {
+		event = "output"
+	}
+	return event, strings.Join(dataLines, "\n"), nil
+}
+
+// Close closes the stream and releases resources.
+func (s *streamReader) Close() error {
+	if s.closed {
+		return nil
+	}
+
+	s.closed = true
+	return s.resp.Close()
+}
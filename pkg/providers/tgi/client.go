@@ -0,0 +1,295 @@
+package tgi
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"mercator-hq/jupiter/pkg/providers"
+)
+
+// Provider is the Hugging Face Text Generation Inference provider adapter.
+// It implements the providers.Provider interface for the native generate
+// and generate_stream APIs of TGI servers and Hugging Face Inference
+// Endpoints, which take the sampling parameters of open models (top_k,
+// repetition_penalty) that the OpenAI-compatible API does not.
+type Provider struct {
+	*providers.HTTPProvider
+
+	// format is the chat template that messages are rendered with
+	format string
+}
+
+// NewProvider creates a new TGI provider instance. The API key is
+// optional: it is only required by Inference Endpoints that are not public.
+func NewProvider(config providers.ProviderConfig) (*Provider, error) {
+	// Validate configuration
+	if config.Name == "" {
+		return nil, &providers.ConfigError{
+			Provider: "tgi",
+			Field:    "name",
+			Message:  "provider name is required",
+		}
+	}
+
+	if config.BaseURL == "" {
+		return nil, &providers.ConfigError{
+			Provider: config.Name,
+			Field:    "base_url",
+			Message:  "base URL is required for TGI",
+		}
+	}
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
+
+	format := config.PromptFormat
+	if format == "" {
+		format = providers.PromptFormatChatML
+	}
+	if !providers.IsValidPromptFormat(format) {
+		return nil, &providers.ConfigError{
+			Provider: config.Name,
+			Field:    "prompt_format",
+			Message:  fmt.Sprintf("unsupported prompt format %q (supported: chatml, llama3, mistral)", format),
+		}
+	}
+
+	// Set defaults if not provided
+	if config.MaxRetries == 0 {
+		config.MaxRetries = 3
+	}
+	if config.MaxIdleConns == 0 {
+		config.MaxIdleConns = 100
+	}
+	if config.MaxIdleConnsPerHost == 0 {
+		config.MaxIdleConnsPerHost = 10
+	}
+
+	// Create base HTTP provider. TGI reports readiness, including whether
+	// the model is loaded, on /health.
+	httpProvider := providers.NewHTTPProvider(config)
+	httpProvider.SetHealthCheckURL(config.BaseURL + "/health")
+
+	p := &Provider{
+		HTTPProvider: httpProvider,
+		format:       format,
+	}
+
+	slog.Info("TGI provider initialized",
+		"provider", config.Name,
+		"base_url", config.BaseURL,
+		"prompt_format", format,
+	)
+
+	return p, nil
+}
+
+// SendCompletion sends a completion request to the generate API.
+func (p *Provider) SendCompletion(ctx context.Context, req *providers.CompletionRequest) (*providers.CompletionResponse, error) {
+	// Validate request
+	if err := validateRequest(req); err != nil {
+		return nil, err
+	}
+
+	// Transform request to TGI format
+	tgiReq := transformRequest(req, p.format)
+
+	bodyBytes, err := json.Marshal(tgiReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	resp, err := p.DoRequest(ctx, "POST", p.GetConfig().BaseURL+"/generate", bodyBytes, p.headers("application/json"))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	responseBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &providers.ParseError{
+			Provider: p.GetName(),
+			Cause:    fmt.Errorf("failed to read response: %w", err),
+		}
+	}
+
+	tgiResp, err := decodeResponse(responseBytes)
+	if err != nil {
+		return nil, &providers.ParseError{
+			Provider:    p.GetName(),
+			RawResponse: string(responseBytes),
+			Cause:       fmt.Errorf("failed to unmarshal response: %w", err),
+		}
+	}
+
+	// Transform response to provider-agnostic format. The prompt tokens
+	// are reported in a header.
+	completion := transformResponse(tgiResp, req.Stop)
+	completion.ID = newResponseID()
+	completion.Model = req.Model
+	completion.Usage.PromptTokens, _ = strconv.Atoi(resp.Header.Get("x-prompt-tokens"))
+	if completion.Usage.CompletionTokens == 0 {
+		completion.Usage.CompletionTokens, _ = strconv.Atoi(resp.Header.Get("x-generated-tokens"))
+	}
+	completion.Usage.TotalTokens = completion.Usage.PromptTokens + completion.Usage.CompletionTokens
+
+	slog.Debug("completion request succeeded",
+		"provider", p.GetName(),
+		"model", completion.Model,
+		"tokens", completion.Usage.TotalTokens,
+	)
+
+	return completion, nil
+}
+
+// StreamCompletion sends a streaming completion request to the
+// generate_stream API. The Server-Sent Events of the response are
+// normalized to stream chunks.
+func (p *Provider) StreamCompletion(ctx context.Context, req *providers.CompletionRequest) (<-chan *providers.StreamChunk, error) {
+	// Validate request
+	if err := validateRequest(req); err != nil {
+		return nil, err
+	}
+
+	// Transform request to TGI format
+	tgiReq := transformRequest(req, p.format)
+
+	// Create stream reader
+	stream, err := newStreamReader(ctx, p.HTTPProvider, p.GetConfig().BaseURL+"/generate_stream", p.headers("text/event-stream"), req.Model, tgiReq, req.Stop)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create output channel
+	chunks := make(chan *providers.StreamChunk, 100) // Buffered channel
+
+	// Start goroutine to read stream and send chunks
+	go func() {
+		defer close(chunks)
+		defer stream.Close()
+
+		for {
+			chunk, err := stream.Read(ctx)
+			if err == io.EOF {
+				// Stream ended normally
+				return
+			}
+			if err != nil {
+				// Send error chunk and exit
+				select {
+				case chunks <- &providers.StreamChunk{Error: err}:
+				case <-ctx.Done():
+				}
+				return
+			}
+
+			// Send chunk
+			select {
+			case chunks <- chunk:
+			case <-ctx.Done():
+				return
+			}
+
+			// Check if this is the final chunk
+			if chunk.FinishReason != "" {
+				return
+			}
+		}
+	}()
+
+	return chunks, nil
+}
+
+// GetType returns "tgi" as the provider type.
+func (p *Provider) GetType() string {
+	return "tgi"
+}
+
+// headers returns the headers of a request accepting the content type.
+func (p *Provider) headers(accept string) map[string]string {
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"Accept":       accept,
+	}
+	if apiKey := p.GetConfig().APIKey; apiKey != "" {
+		headers["Authorization"] = "Bearer " + apiKey
+	}
+	return headers
+}
+
+// decodeResponse decodes a generate response. Hugging Face serverless
+// inference wraps it in an array.
+func decodeResponse(data []byte) (*GenerateResponse, error) {
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '[' {
+		var resps []GenerateResponse
+		if err := json.Unmarshal(trimmed, &resps); err != nil {
+			return nil, err
+		}
+		if len(resps) == 0 {
+			return nil, fmt.Errorf("empty response")
+		}
+		return &resps[0], nil
+	}
+
+	var resp GenerateResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// newResponseID returns an ID for a response. TGI does not identify
+// responses.
+func newResponseID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return "tgi-" + hex.EncodeToString(b)
+}
+
+// validateRequest validates the completion request.
+func validateRequest(req *providers.CompletionRequest) error {
+	if req == nil {
+		return &providers.ValidationError{
+			Field:   "request",
+			Message: "request cannot be nil",
+		}
+	}
+
+	if req.Model == "" {
+		return &providers.ValidationError{
+			Field:   "model",
+			Message: "model is required",
+		}
+	}
+
+	if len(req.Messages) == 0 {
+		return &providers.ValidationError{
+			Field:   "messages",
+			Message: "at least one message is required",
+		}
+	}
+
+	// The generate API has no function calling
+	if len(req.Tools) > 0 {
+		return &providers.ValidationError{
+			Field:   "tools",
+			Message: "tools are not supported by the TGI generate API",
+		}
+	}
+	for _, msg := range req.Messages {
+		if msg.Role == providers.RoleTool || len(msg.ToolCalls) > 0 {
+			return &providers.ValidationError{
+				Field:   "messages",
+				Message: "tool calls are not supported by the TGI generate API",
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,306 @@
+package tgi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mercator-hq/jupiter/pkg/providers"
+)
+
+const testModel = "meta-llama/Meta-Llama-3-8B-Instruct"
+
+func newTestProvider(t *testing.T, baseURL, format string) *Provider {
+	t.Helper()
+	provider, err := NewProvider(providers.ProviderConfig{
+		Name:         "tgi",
+		Type:         "tgi",
+		BaseURL:      baseURL,
+		APIKey:       "hf_test",
+		PromptFormat: format,
+	})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	return provider
+}
+
+// tgiHandler checks the token and path of requests, decodes their body
+// into body, and serves them with serve.
+func tgiHandler(t *testing.T, path string, body *GenerateRequest, serve func(w http.ResponseWriter)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if authorization := r.Header.Get("Authorization"); authorization != "Bearer hf_test" {
+			t.Errorf("Authorization = %q, want the API key", authorization)
+		}
+		if r.URL.Path != path {
+			t.Errorf("path = %q, want %q", r.URL.Path, path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(body); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		serve(w)
+	}
+}
+
+func TestTGIProvider_SendCompletion(t *testing.T) {
+	var body GenerateRequest
+	server := httptest.NewServer(tgiHandler(t, "/generate", &body, func(w http.ResponseWriter) {
+		w.Header().Set("x-prompt-tokens", "25")
+		_, _ = w.Write([]byte(`{
+			"generated_text": "Paris is the capital of France.\nUser:",
+			"details": {"finish_reason": "stop_sequence", "generated_tokens": 9, "seed": null}
+		}`))
+	}))
+	defer server.Close()
+
+	provider := newTestProvider(t, server.URL+"/", "llama3")
+	resp, err := provider.SendCompletion(context.Background(), &providers.CompletionRequest{
+		Model: testModel,
+		Messages: []providers.Message{
+			{Role: providers.RoleSystem, Content: "Be brief."},
+			{Role: providers.RoleUser, Content: "Capital of France?"},
+		},
+		MaxTokens:         64,
+		Temperature:       0.7,
+		TopP:              1,
+		TopK:              40,
+		RepetitionPenalty: 1.1,
+		PresencePenalty:   0.5,
+		Stop:              []string{"\nUser:"},
+	})
+	if err != nil {
+		t.Fatalf("SendCompletion failed: %v", err)
+	}
+
+	// Verify request
+	wantPrompt := "<|start_header_id|>system<|end_header_id|>\n\nBe brief.<|eot_id|>" +
+		"<|start_header_id|>user<|end_header_id|>\n\nCapital of France?<|eot_id|>" +
+		"<|start_header_id|>assistant<|end_header_id|>\n\n"
+	if body.Inputs != wantPrompt {
+		t.Errorf("inputs = %q, want %q", body.Inputs, wantPrompt)
+	}
+	wantParams := GenerateParameters{
+		MaxNewTokens:      64,
+		Temperature:       0.7,
+		TopK:              40,
+		RepetitionPenalty: 1.1,
+		Stop:              []string{"\nUser:"},
+		Details:           true,
+	}
+	if fmt.Sprint(body.Parameters) != fmt.Sprint(wantParams) {
+		t.Errorf("parameters = %+v, want %+v", body.Parameters, wantParams)
+	}
+
+	// Verify response
+	if resp.Content != "Paris is the capital of France." {
+		t.Errorf("content = %q, want the text without the stop sequence", resp.Content)
+	}
+	if resp.FinishReason != providers.FinishReasonStop || resp.Model != testModel || !strings.HasPrefix(resp.ID, "tgi-") {
+		t.Errorf("response = %+v, want a stop finish reason, the model, and an ID", resp)
+	}
+	if resp.Usage.PromptTokens != 25 || resp.Usage.CompletionTokens != 9 || resp.Usage.TotalTokens != 34 {
+		t.Errorf("usage = %+v, want 25 prompt and 9 completion tokens", resp.Usage)
+	}
+}
+
+func TestTGIProvider_SendCompletion_ServerlessArray(t *testing.T) {
+	server := httptest.NewServer(tgiHandler(t, "/generate", &GenerateRequest{}, func(w http.ResponseWriter) {
+		w.Header().Set("x-prompt-tokens", "12")
+		w.Header().Set("x-generated-tokens", "20")
+		_, _ = w.Write([]byte(`[{"generated_text": "Hello there"}]`))
+	}))
+	defer server.Close()
+
+	resp, err := newTestProvider(t, server.URL, "").SendCompletion(context.Background(), &providers.CompletionRequest{
+		Model:    testModel,
+		Messages: []providers.Message{{Role: providers.RoleUser, Content: "Hello"}},
+	})
+	if err != nil {
+		t.Fatalf("SendCompletion failed: %v", err)
+	}
+	if resp.Content != "Hello there" || resp.Usage.TotalTokens != 32 {
+		t.Errorf("response = %+v, want the first generation and the header usage", resp)
+	}
+}
+
+// collect reads all chunks of a stream.
+func collect(t *testing.T, chunks <-chan *providers.StreamChunk) ([]*providers.StreamChunk, string) {
+	t.Helper()
+	var all []*providers.StreamChunk
+	var text strings.Builder
+	for chunk := range chunks {
+		all = append(all, chunk)
+		text.WriteString(chunk.Delta)
+	}
+	return all, text.String()
+}
+
+func TestTGIProvider_StreamCompletion(t *testing.T) {
+	var body GenerateRequest
+	server := httptest.NewServer(tgiHandler(t, "/generate_stream", &body, func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, token := range []string{"Hello", " wor", "ld", "\n", "User"} {
+			fmt.Fprintf(w, "data:{\"token\":{\"id\":1,\"text\":%q,\"logprob\":-0.1,\"special\":false},\"generated_text\":null,\"details\":null}\n\n", token)
+		}
+		fmt.Fprint(w, "data:{\"token\":{\"id\":2,\"text\":\":\",\"logprob\":-0.1,\"special\":false},\"generated_text\":\"Hello world\\nUser:\",\"details\":{\"finish_reason\":\"stop_sequence\",\"generated_tokens\":6,\"seed\":null,\"input_length\":14}}\n\n")
+	}))
+	defer server.Close()
+
+	chunks, err := newTestProvider(t, server.URL, "").StreamCompletion(context.Background(), &providers.CompletionRequest{
+		Model:    testModel,
+		Messages: []providers.Message{{Role: providers.RoleUser, Content: "Hello"}},
+		Stop:     []string{"\nUser:"},
+		Stream:   true,
+	})
+	if err != nil {
+		t.Fatalf("StreamCompletion failed: %v", err)
+	}
+
+	if body.Inputs != "<|im_start|>user\nHello<|im_end|>\n<|im_start|>assistant\n" {
+		t.Errorf("inputs = %q, want a ChatML prompt", body.Inputs)
+	}
+
+	all, text := collect(t, chunks)
+	if text != "Hello world" {
+		t.Errorf("stream = %q, want the text without the stop sequence", text)
+	}
+	final := all[len(all)-1]
+	if final.FinishReason != providers.FinishReasonStop || final.Usage == nil || final.Usage.PromptTokens != 14 || final.Usage.TotalTokens != 20 {
+		t.Errorf("final chunk = %+v, want the finish reason and usage", final)
+	}
+	for _, chunk := range all {
+		if chunk.ID != all[0].ID || chunk.Model != testModel {
+			t.Errorf("chunk = %+v, want the ID and model of the stream", chunk)
+		}
+	}
+}
+
+func TestTGIProvider_StreamCompletion_EOSAndError(t *testing.T) {
+	tests := []struct {
+		name       string
+		events     []string
+		wantText   string
+		wantFinish string
+		wantError  bool
+	}{
+		{
+			name: "special end of sequence token",
+			events: []string{
+				`{"token":{"id":1,"text":"Hi","special":false}}`,
+				`{"token":{"id":2,"text":"</s>","special":true},"generated_text":"Hi","details":{"finish_reason":"eos_token","generated_tokens":2}}`,
+			},
+			wantText:   "Hi",
+			wantFinish: providers.FinishReasonStop,
+		},
+		{
+			name: "length",
+			events: []string{
+				`{"token":{"id":1,"text":"Hi","special":false},"generated_text":"Hi","details":{"finish_reason":"length","generated_tokens":1}}`,
+			},
+			wantText:   "Hi",
+			wantFinish: providers.FinishReasonLength,
+		},
+		{
+			name: "generation error",
+			events: []string{
+				`{"token":{"id":1,"text":"Hi","special":false}}`,
+				`{"error":"Request failed during generation: Server error: CUDA out of memory","error_type":"generation"}`,
+			},
+			wantText:  "Hi",
+			wantError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tgiHandler(t, "/generate_stream", &GenerateRequest{}, func(w http.ResponseWriter) {
+				for _, event := range tt.events {
+					fmt.Fprintf(w, "data:%s\n\n", event)
+				}
+			}))
+			defer server.Close()
+
+			chunks, err := newTestProvider(t, server.URL, "").StreamCompletion(context.Background(), &providers.CompletionRequest{
+				Model:    testModel,
+				Messages: []providers.Message{{Role: providers.RoleUser, Content: "Hello"}},
+			})
+			if err != nil {
+				t.Fatalf("StreamCompletion failed: %v", err)
+			}
+
+			all, text := collect(t, chunks)
+			if text != tt.wantText {
+				t.Errorf("stream = %q, want %q", text, tt.wantText)
+			}
+			final := all[len(all)-1]
+			var streamErr *providers.StreamError
+			if tt.wantError != errors.As(final.Error, &streamErr) {
+				t.Errorf("final chunk error = %v, want error %v", final.Error, tt.wantError)
+			}
+			if final.FinishReason != tt.wantFinish {
+				t.Errorf("finish reason = %q, want %q", final.FinishReason, tt.wantFinish)
+			}
+		})
+	}
+}
+
+func TestTGIProvider_Tools(t *testing.T) {
+	provider := newTestProvider(t, "http://127.0.0.1:1", "")
+	_, err := provider.SendCompletion(context.Background(), &providers.CompletionRequest{
+		Model:    testModel,
+		Messages: []providers.Message{{Role: providers.RoleUser, Content: "Weather?"}},
+		Tools:    []providers.Tool{{Type: "function", Function: providers.FunctionDefinition{Name: "get_weather"}}},
+	})
+	var validationErr *providers.ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Field != "tools" {
+		t.Errorf("SendCompletion() error = %v, want a tools validation error", err)
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    providers.ProviderConfig
+		wantField string
+	}{
+		{
+			name:      "missing base URL",
+			config:    providers.ProviderConfig{Name: "tgi"},
+			wantField: "base_url",
+		},
+		{
+			name:      "unsupported prompt format",
+			config:    providers.ProviderConfig{Name: "tgi", BaseURL: "http://tgi:8080", PromptFormat: "alpaca"},
+			wantField: "prompt_format",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewProvider(tt.config)
+			var configErr *providers.ConfigError
+			if !errors.As(err, &configErr) || configErr.Field != tt.wantField {
+				t.Errorf("NewProvider() error = %v, want a %s error", err, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestStopFilter(t *testing.T) {
+	filter := &stopFilter{stop: []string{"###", "\nUser:"}}
+
+	var sent strings.Builder
+	for _, text := range []string{"a #", "# b", "\nUs", "ed", "\nUser", ":"} {
+		sent.WriteString(filter.write(text))
+	}
+	sent.WriteString(filter.flush(true))
+
+	if got := sent.String(); got != "a ## b\nUsed" {
+		t.Errorf("sent text = %q, want the text without the final stop sequence", got)
+	}
+}
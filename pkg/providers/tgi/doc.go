@@ -0,0 +1,103 @@
+// Package tgi implements the Hugging Face Text Generation Inference (TGI)
+// provider adapter.
+//
+// This package provides an implementation of the providers.Provider interface
+// for the native generate and generate_stream APIs of TGI, served by
+// self-hosted TGI servers and by Hugging Face Inference Endpoints. Unlike
+// the OpenAI-compatible messages API of TGI, which the generic adapter
+// uses, the native API takes the sampling parameters of open models. It
+// supports:
+//
+//   - top_k, repetition_penalty, and stop sequences
+//   - Chat templates (ChatML, Llama 3, Mistral) to render messages as a
+//     prompt
+//   - Streaming, with stop sequences removed from the streamed text
+//   - Token usage tracking
+//
+// # Basic Usage
+//
+//	config := providers.ProviderConfig{
+//	    Name:         "llama",
+//	    Type:         "tgi",
+//	    BaseURL:      "http://tgi:8080",
+//	    PromptFormat: "llama3",
+//	}
+//
+//	provider, err := tgi.NewProvider(config)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer provider.Close()
+//
+//	req := &providers.CompletionRequest{
+//	    Model: "meta-llama/Meta-Llama-3-8B-Instruct",
+//	    Messages: []providers.Message{
+//	        {Role: "user", Content: "Hello!"},
+//	    },
+//	    TopK:              40,
+//	    RepetitionPenalty: 1.1,
+//	}
+//
+//	resp, err := provider.SendCompletion(context.Background(), req)
+//
+// A TGI server serves a single model: the model of requests is used for
+// routing and reporting only. The API key, if configured, is sent as a
+// bearer token, as required by protected Inference Endpoints.
+//
+// # Request Mapping
+//
+// The native API takes a single prompt, so messages are rendered in the
+// chat template of the configured prompt format (default: ChatML), which
+// must match the template the model was trained with. The beginning of
+// sequence token is added by the tokenizer of the model. Mistral has no
+// system role: system messages are prepended to the next user message.
+//
+// Parameters are mapped to the parameters of the generate API:
+//
+//   - max_tokens -> max_new_tokens
+//   - temperature, top_p, top_k -> temperature, top_p, top_k (top_p values
+//     of 1 and above, which TGI rejects, are not sent)
+//   - repetition_penalty, frequency_penalty -> repetition_penalty,
+//     frequency_penalty
+//   - stop -> stop
+//
+// presence_penalty is not supported by TGI and is not sent. The generate
+// API has no function calling: requests with tools or tool messages are
+// rejected.
+//
+// # Response Mapping
+//
+// Finish reasons are normalized: eos_token and stop_sequence -> stop, and
+// length -> length. TGI includes the stop sequence that ended a
+// generation in the generated text; the adapter removes it, as OpenAI
+// does.
+//
+// Prompt tokens are taken from the x-prompt-tokens header of responses, or
+// the input length of the last stream event, and completion tokens from
+// the generation details.
+//
+// # Streaming
+//
+// Streams are read from generate_stream as Server-Sent Events, one token
+// per event. Special tokens, such as the end of sequence token, are not
+// sent. Text that may be the start of a stop sequence is held back until
+// the next token shows it is not. The last event carries the finish reason
+// and the token usage.
+//
+// # Error Handling
+//
+// The adapter maps TGI errors to common error types:
+//
+//   - 401/403 -> AuthError
+//   - 429 -> RateLimitError (model overloaded)
+//   - 400/422 -> ProviderError (request validation errors)
+//   - 5xx -> ProviderError (retried automatically, e.g. while Inference
+//     Endpoints scale up from zero)
+//
+// Errors during streaming, which TGI sends as events, end the stream with a
+// StreamError.
+//
+// # Health Checks
+//
+// Health checks request /health, which succeeds once the model is loaded.
+package tgi
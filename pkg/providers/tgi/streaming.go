@@ -0,0 +1,234 @@
+package tgi
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"mercator-hq/jupiter/pkg/providers"
+)
+
+// streamState tracks state across stream chunks.
+type streamState struct {
+	id      string
+	model   string
+	created int64
+
+	// stop holds back generated text that may be the start of a stop
+	// sequence, which TGI streams as tokens before it ends the generation
+	stop *stopFilter
+}
+
+// streamReader reads Server-Sent Events (SSE) from the TGI generate_stream
+// API. Each event is a StreamEvent.
+type streamReader struct {
+	provider *providers.HTTPProvider
+	resp     io.ReadCloser
+	scanner  *bufio.Scanner
+	state    *streamState
+	closed   bool
+}
+
+// newStreamReader sends a streaming request and returns a reader of the
+// events of the response.
+func newStreamReader(ctx context.Context, provider *providers.HTTPProvider, url string, headers map[string]string, model string, req *GenerateRequest, stop []string) (*streamReader, error) {
+	// Marshal request
+	bodyBytes, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	// Perform request
+	resp, err := provider.DoRequest(ctx, "POST", url, bodyBytes, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	return &streamReader{
+		provider: provider,
+		resp:     resp.Body,
+		scanner:  bufio.NewScanner(resp.Body),
+		state: &streamState{
+			id:      newResponseID(),
+			model:   model,
+			created: time.Now().Unix(),
+			stop:    &stopFilter{stop: stop},
+		},
+	}, nil
+}
+
+// Read reads the next chunk from the stream.
+// Returns nil, io.EOF when the stream ends normally.
+// Returns nil, error if an error occurs.
+func (s *streamReader) Read(ctx context.Context) (*providers.StreamChunk, error) {
+	if s.closed {
+		return nil, io.EOF
+	}
+
+	for {
+		// Check context cancellation
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		default:
+		}
+
+		// Read next SSE event
+		data, err := s.readEvent()
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		if err != nil {
+			return nil, &providers.StreamError{
+				Provider: s.provider.GetName(),
+				Message:  "failed to read stream",
+				Cause:    err,
+			}
+		}
+
+		var event StreamEvent
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return nil, &providers.ParseError{
+				Provider:    s.provider.GetName(),
+				RawResponse: data,
+				Cause:       fmt.Errorf("failed to parse stream event: %w", err),
+			}
+		}
+
+		// Errors during generation are sent as events
+		if event.Error != "" {
+			return nil, &providers.StreamError{
+				Provider: s.provider.GetName(),
+				Message:  fmt.Sprintf("%s error: %s", event.ErrorType, event.Error),
+			}
+		}
+
+		// Transform event to chunk
+		chunk := s.transform(&event)
+		if chunk == nil {
+			continue
+		}
+
+		chunk.ID = s.state.id
+		chunk.Model = s.state.model
+		chunk.Created = s.state.created
+		return chunk, nil
+	}
+}
+
+// transform transforms a stream event to a stream chunk. It returns nil for
+// events that carry no text. The last event carries the finish reason and
+// the token usage.
+func (s *streamReader) transform(event *StreamEvent) *providers.StreamChunk {
+	chunk := &providers.StreamChunk{}
+	if !event.Token.Special {
+		chunk.Delta = s.state.stop.write(event.Token.Text)
+	}
+
+	if event.Details != nil {
+		chunk.Delta += s.state.stop.flush(event.Details.FinishReason == "stop_sequence")
+		chunk.FinishReason = normalizeFinishReason(event.Details.FinishReason)
+		chunk.Usage = &providers.TokenUsage{
+			PromptTokens:     event.Details.InputLength,
+			CompletionTokens: event.Details.GeneratedTokens,
+			TotalTokens:      event.Details.InputLength + event.Details.GeneratedTokens,
+		}
+	}
+
+	if chunk.Delta == "" && chunk.FinishReason == "" {
+		return nil
+	}
+	return chunk
+}
+
+// readEvent returns the data of the next SSE event.
+func (s *streamReader) readEvent() (string, error) {
+	var dataLines []string
+
+	for s.scanner.Scan() {
+		line := s.scanner.Text()
+
+		// Empty line marks end of event
+		if line == "" {
+			if len(dataLines) > 0 {
+				break
+			}
+			continue
+		}
+
+		// Parse SSE field
+		if strings.HasPrefix(line, "data:") {
+			dataLines = append(dataLines, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+		// Ignore other SSE fields (event, id, retry)
+	}
+
+	if err := s.scanner.Err(); err != nil {
+		return "", err
+	}
+
+	// No event found
+	if len(dataLines) == 0 {
+		return "", io.EOF
+	}
+
+	// Combine multi-line data
+	return strings.Join(dataLines, "\n"), nil
+}
+
+// Close closes the stream and releases resources.
+func (s *streamReader) Close() error {
+	if s.closed {
+		return nil
+	}
+
+	s.closed = true
+	return s.resp.Close()
+}
+
+// stopFilter removes the stop sequence that ends a generation from
+// streamed text. Text that may be the start of a stop sequence is held
+// back until the next token shows it is not, or the generation ends.
+type stopFilter struct {
+	stop    []string
+	pending string
+}
+
+// write adds generated text, and returns the text that can be sent.
+func (f *stopFilter) write(text string) string {
+	if len(f.stop) == 0 {
+		return text
+	}
+	f.pending += text
+
+	// Hold back the longest end of the pending text that starts a stop
+	// sequence
+	hold := 0
+	for _, s := range f.stop {
+		for n := min(len(s), len(f.pending)); n > hold; n-- {
+			if strings.HasSuffix(f.pending, s[:n]) {
+				hold = n
+				break
+			}
+		}
+	}
+
+	send := f.pending[:len(f.pending)-hold]
+	f.pending = f.pending[len(f.pending)-hold:]
+	return send
+}
+
+// flush returns the text held back at the end of the generation, without
+// the stop sequence if it ended the generation.
+func (f *stopFilter) flush(stopped bool) string {
+	text := f.pending
+	f.pending = ""
+	if stopped {
+		text = trimStopSequence(text, f.stop)
+	}
+	return text
+}
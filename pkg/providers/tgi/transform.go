@@ -0,0 +1,140 @@
+package tgi
+
+import (
+	"strings"
+
+	"mercator-hq/jupiter/pkg/providers"
+)
+
+// GenerateRequest is a request of the TGI generate and generate_stream
+// APIs.
+type GenerateRequest struct {
+	Inputs     string             `json:"inputs"`
+	Parameters GenerateParameters `json:"parameters"`
+}
+
+// GenerateParameters are the generation parameters of a TGI request.
+type GenerateParameters struct {
+	MaxNewTokens      int      `json:"max_new_tokens,omitempty"`
+	Temperature       float64  `json:"temperature,omitempty"`
+	TopP              float64  `json:"top_p,omitempty"`
+	TopK              int      `json:"top_k,omitempty"`
+	RepetitionPenalty float64  `json:"repetition_penalty,omitempty"`
+	FrequencyPenalty  float64  `json:"frequency_penalty,omitempty"`
+	Stop              []string `json:"stop,omitempty"`
+
+	// ReturnFullText is sent even when false: Hugging Face serverless
+	// inference returns the prompt with the generated text by default
+	ReturnFullText bool `json:"return_full_text"`
+
+	// Details requests the finish reason and token counts
+	Details bool `json:"details"`
+}
+
+// GenerateResponse is a response of the TGI generate API.
+type GenerateResponse struct {
+	GeneratedText string   `json:"generated_text"`
+	Details       *Details `json:"details,omitempty"`
+}
+
+// Details are the generation details of a response or of the last event
+// of a stream.
+type Details struct {
+	// FinishReason is "length", "eos_token", or "stop_sequence"
+	FinishReason    string `json:"finish_reason"`
+	GeneratedTokens int    `json:"generated_tokens"`
+
+	// InputLength is the number of prompt tokens, reported in streams only
+	// (the generate API reports it in the x-prompt-tokens header)
+	InputLength int `json:"input_length,omitempty"`
+}
+
+// StreamEvent is an event of the TGI generate_stream API. Every event
+// carries a token; the last one also carries the generated text and the
+// details.
+type StreamEvent struct {
+	Token         Token    `json:"token"`
+	GeneratedText *string  `json:"generated_text,omitempty"`
+	Details       *Details `json:"details,omitempty"`
+
+	// Error is set in the event of an error during generation
+	Error     string `json:"error,omitempty"`
+	ErrorType string `json:"error_type,omitempty"`
+}
+
+// Token is a generated token.
+type Token struct {
+	ID   int    `json:"id"`
+	Text string `json:"text"`
+
+	// Special tokens, such as the end of sequence token, are not part of
+	// the generated text
+	Special bool `json:"special"`
+}
+
+// transformRequest transforms a provider-agnostic request to TGI format,
+// with the messages rendered in the prompt format.
+func transformRequest(req *providers.CompletionRequest, format string) *GenerateRequest {
+	tgiReq := &GenerateRequest{
+		Inputs: providers.RenderPrompt(req.Messages, format),
+		Parameters: GenerateParameters{
+			MaxNewTokens:      req.MaxTokens,
+			Temperature:       req.Temperature,
+			TopK:              req.TopK,
+			RepetitionPenalty: req.RepetitionPenalty,
+			FrequencyPenalty:  req.FrequencyPenalty,
+			Stop:              req.Stop,
+			Details:           true,
+		},
+	}
+
+	// TGI rejects top_p values of 1 and above, which disable nucleus
+	// sampling anyway
+	if req.TopP < 1 {
+		tgiReq.Parameters.TopP = req.TopP
+	}
+
+	return tgiReq
+}
+
+// transformResponse transforms a TGI response to provider-agnostic format.
+// The stop sequence that ended the generation, which TGI includes in the
+// generated text, is removed.
+func transformResponse(resp *GenerateResponse, stop []string) *providers.CompletionResponse {
+	completion := &providers.CompletionResponse{
+		Content: resp.GeneratedText,
+	}
+
+	if resp.Details != nil {
+		completion.FinishReason = normalizeFinishReason(resp.Details.FinishReason)
+		completion.Usage.CompletionTokens = resp.Details.GeneratedTokens
+		if resp.Details.FinishReason == "stop_sequence" {
+			completion.Content = trimStopSequence(completion.Content, stop)
+		}
+	}
+
+	return completion
+}
+
+// trimStopSequence removes the stop sequence that text ends with, if any.
+func trimStopSequence(text string, stop []string) string {
+	for _, s := range stop {
+		if s != "" && strings.HasSuffix(text, s) {
+			return strings.TrimSuffix(text, s)
+		}
+	}
+	return text
+}
+
+// normalizeFinishReason normalizes a TGI finish reason to the common finish
+// reasons.
+func normalizeFinishReason(reason string) string {
+	switch reason {
+	case "eos_token", "stop_sequence":
+		return providers.FinishReasonStop
+	case "length":
+		return providers.FinishReasonLength
+	default:
+		return strings.ToLower(reason)
+	}
+}
@@ -0,0 +1,190 @@
+package providers
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/baggage"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName is the instrumentation scope of provider spans.
+const tracerName = "mercator-hq/jupiter/pkg/providers"
+
+// Baggage members sent to providers configured with TraceBaggage.
+const (
+	// BaggageRequestID is the request ID of the proxied request.
+	BaggageRequestID = "mercator.request_id"
+
+	// BaggageTenant is the tenant of the proxied request.
+	BaggageTenant = "mercator.tenant"
+)
+
+// traceBaggageKey is the context key of the request's trace baggage.
+type traceBaggageKey struct{}
+
+// traceBaggage is the request ID and tenant sent to providers as baggage.
+type traceBaggage struct {
+	requestID string
+	tenant    string
+}
+
+// ContextWithTraceBaggage returns a context whose provider requests carry
+// requestID and tenant in a W3C baggage header, to providers configured
+// with TraceBaggage. Empty values are left out.
+func ContextWithTraceBaggage(ctx context.Context, requestID, tenant string) context.Context {
+	return context.WithValue(ctx, traceBaggageKey{}, traceBaggage{requestID: requestID, tenant: tenant})
+}
+
+// startRequestSpan starts the client span of a provider request, ended by
+// endRequestSpan. The span covers retries and ends when the response
+// headers are received; reading the response body is not included.
+func (p *HTTPProvider) startRequestSpan(ctx context.Context, method, rawURL string) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		attribute.String("mercator.provider", p.config.Name),
+		attribute.String("http.method", method),
+	}
+	// The query is left out as some APIs take credentials in it
+	if u, err := url.Parse(rawURL); err == nil {
+		attrs = append(attrs,
+			attribute.String("http.url", u.Scheme+"://"+u.Host+u.Path),
+			attribute.String("server.address", u.Hostname()),
+		)
+	}
+
+	return otel.Tracer(tracerName).Start(ctx, "provider.request",
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(attrs...),
+	)
+}
+
+// endRequestSpan records the outcome of a provider request and ends its
+// span.
+func endRequestSpan(span trace.Span, resp *http.Response, err error) {
+	switch e := err.(type) {
+	case nil:
+		span.SetAttributes(attribute.Int("http.status_code", resp.StatusCode))
+	case *ProviderError:
+		span.SetAttributes(attribute.Int("http.status_code", e.StatusCode))
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// injectTraceContext adds the traceparent and tracestate headers of the
+// span of ctx to header, and the request's baggage if the provider is
+// configured with TraceBaggage. Baggage received from clients is not
+// forwarded.
+func (p *HTTPProvider) injectTraceContext(ctx context.Context, header http.Header) {
+	propagation.TraceContext{}.Inject(ctx, propagation.HeaderCarrier(header))
+
+	if !p.config.TraceBaggage {
+		return
+	}
+	values, _ := ctx.Value(traceBaggageKey{}).(traceBaggage)
+	var members []baggage.Member
+	for _, kv := range [][2]string{
+		{BaggageRequestID, values.requestID},
+		{BaggageTenant, values.tenant},
+	} {
+		if kv[1] == "" {
+			continue
+		}
+		if member, err := baggage.NewMemberRaw(kv[0], kv[1]); err == nil {
+			members = append(members, member)
+		}
+	}
+	if bag, err := baggage.New(members...); err == nil && bag.Len() > 0 {
+		propagation.Baggage{}.Inject(baggage.ContextWithBaggage(ctx, bag), propagation.HeaderCarrier(header))
+	}
+}
+
+// withPhaseSpans returns a context recording the latency breakdown of an
+// HTTP request attempt as child spans of the span of ctx: DNS lookup,
+// connection, TLS handshake, and the wait for the first response byte,
+// which is the time the provider spends on the request. It returns ctx
+// unchanged if the span is not recording.
+func withPhaseSpans(ctx context.Context) context.Context {
+	if !trace.SpanFromContext(ctx).IsRecording() {
+		return ctx
+	}
+	t := &phaseTracer{ctx: ctx, tracer: otel.Tracer(tracerName)}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { t.start(&t.dnsStart) },
+		DNSDone: func(info httptrace.DNSDoneInfo) {
+			t.end("provider.dns", &t.dnsStart, info.Err)
+		},
+		ConnectStart: func(network, addr string) { t.start(&t.connectStart) },
+		ConnectDone: func(network, addr string, err error) {
+			t.end("provider.connect", &t.connectStart, err, attribute.String("net.peer.address", addr))
+		},
+		TLSHandshakeStart: func() { t.start(&t.tlsStart) },
+		TLSHandshakeDone: func(_ tls.ConnectionState, err error) {
+			t.end("provider.tls_handshake", &t.tlsStart, err)
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			trace.SpanFromContext(ctx).SetAttributes(attribute.Bool("mercator.provider.conn_reused", info.Reused))
+		},
+		WroteRequest: func(info httptrace.WroteRequestInfo) {
+			if info.Err == nil {
+				t.start(&t.waitStart)
+			}
+		},
+		GotFirstResponseByte: func() { t.end("provider.wait", &t.waitStart, nil) },
+	})
+}
+
+// phaseTracer records the phases of an HTTP request attempt as spans. Its
+// hooks may be called from several goroutines, e.g. for parallel dials.
+type phaseTracer struct {
+	ctx    context.Context
+	tracer trace.Tracer
+
+	mu           sync.Mutex
+	dnsStart     time.Time
+	connectStart time.Time
+	tlsStart     time.Time
+	waitStart    time.Time
+}
+
+// start records the start of a phase, unless one is in progress.
+func (t *phaseTracer) start(at *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if at.IsZero() {
+		*at = time.Now()
+	}
+}
+
+// end records a span for the phase started at, if any.
+func (t *phaseTracer) end(name string, at *time.Time, err error, attrs ...attribute.KeyValue) {
+	t.mu.Lock()
+	start := *at
+	*at = time.Time{}
+	t.mu.Unlock()
+	if start.IsZero() {
+		return
+	}
+
+	_, span := t.tracer.Start(t.ctx, name,
+		trace.WithTimestamp(start),
+		trace.WithAttributes(attrs...),
+	)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
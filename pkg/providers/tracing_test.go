@@ -0,0 +1,119 @@
+package providers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/baggage"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans installs a global tracer provider recording spans for the
+// duration of the test.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestHTTPProvider_TracePropagation(t *testing.T) {
+	recorder := recordSpans(t)
+
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	provider := NewHTTPProvider(ProviderConfig{
+		Name:         "test-provider",
+		Timeout:      5 * time.Second,
+		TraceBaggage: true,
+	})
+
+	// Baggage received from clients must not reach providers
+	clientBaggage, _ := baggage.Parse("client.secret=1")
+	ctx, parent := otel.Tracer("test").Start(baggage.ContextWithBaggage(context.Background(), clientBaggage), "proxy")
+	ctx = ContextWithTraceBaggage(ctx, "req-123", "team a")
+
+	resp, err := provider.DoRequest(ctx, "POST", server.URL+"/v1/chat?key=secret", []byte(`{}`), nil)
+	if err != nil {
+		t.Fatalf("DoRequest() error = %v", err)
+	}
+	resp.Body.Close()
+	parent.End()
+
+	spans := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+	request, ok := spans["provider.request"]
+	if !ok {
+		t.Fatalf("no provider.request span in %v", spans)
+	}
+	if request.Parent().SpanID() != parent.SpanContext().SpanID() || request.SpanKind() != trace.SpanKindClient {
+		t.Errorf("provider.request is not a client span of the proxy span")
+	}
+	for _, attr := range request.Attributes() {
+		if attr.Key == "http.url" && strings.Contains(attr.Value.AsString(), "secret") {
+			t.Errorf("http.url = %q, want the query left out", attr.Value.AsString())
+		}
+	}
+	for _, name := range []string{"provider.connect", "provider.wait"} {
+		phase, ok := spans[name]
+		if !ok {
+			t.Errorf("no %s span", name)
+			continue
+		}
+		if phase.Parent().SpanID() != request.SpanContext().SpanID() {
+			t.Errorf("%s is not a child of provider.request", name)
+		}
+	}
+
+	wantTraceparent := "00-" + request.SpanContext().TraceID().String() + "-" + request.SpanContext().SpanID().String() + "-01"
+	if got := header.Get("traceparent"); got != wantTraceparent {
+		t.Errorf("traceparent = %q, want %q", got, wantTraceparent)
+	}
+	sent, err := baggage.Parse(header.Get("baggage"))
+	if err != nil {
+		t.Fatalf("invalid baggage header %q: %v", header.Get("baggage"), err)
+	}
+	if sent.Member(BaggageRequestID).Value() != "req-123" || sent.Member(BaggageTenant).Value() != "team a" || sent.Len() != 2 {
+		t.Errorf("baggage = %q, want the request ID and tenant only", header.Get("baggage"))
+	}
+}
+
+func TestHTTPProvider_TracePropagationWithoutBaggage(t *testing.T) {
+	recordSpans(t)
+
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	provider := NewHTTPProvider(ProviderConfig{Name: "test-provider", Timeout: 5 * time.Second})
+	ctx := ContextWithTraceBaggage(context.Background(), "req-123", "team-a")
+	if _, err := provider.DoRequest(ctx, "POST", server.URL, []byte(`{}`), nil); err == nil {
+		t.Fatal("DoRequest() succeeded, want a provider error")
+	}
+
+	if header.Get("traceparent") == "" {
+		t.Error("traceparent header not sent")
+	}
+	if got := header.Get("baggage"); got != "" {
+		t.Errorf("baggage = %q, want none", got)
+	}
+}
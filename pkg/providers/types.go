@@ -1,6 +1,9 @@
 package providers
 
-import "time"
+import (
+	"bytes"
+	"time"
+)
 
 // Message represents a single message in a conversation.
 // It is provider-agnostic and will be transformed to provider-specific formats.
@@ -73,6 +76,14 @@ type TokenUsage struct {
 
 	// TotalTokens is the total number of tokens used (prompt + completion)
 	TotalTokens int `json:"total_tokens"`
+
+	// CachedPromptTokens is the number of prompt tokens read from the
+	// provider's prompt cache, included in PromptTokens
+	CachedPromptTokens int `json:"cached_prompt_tokens,omitempty"`
+
+	// Batch reports whether the request was billed at the provider's batch
+	// rate, such as Anthropic's batch or OpenAI's flex service tier
+	Batch bool `json:"batch,omitempty"`
 }
 
 // CompletionRequest represents a provider-agnostic completion request.
@@ -112,6 +123,14 @@ type CompletionRequest struct {
 	// FrequencyPenalty reduces repetition based on frequency (-2.0 to 2.0)
 	FrequencyPenalty float64 `json:"frequency_penalty,omitempty"`
 
+	// TopK limits sampling to the K most likely tokens (not supported by
+	// every provider)
+	TopK int `json:"top_k,omitempty"`
+
+	// RepetitionPenalty penalizes repeated tokens (1.0 is no penalty, not
+	// supported by every provider)
+	RepetitionPenalty float64 `json:"repetition_penalty,omitempty"`
+
 	// User is an optional user identifier for abuse monitoring
 	User string `json:"user,omitempty"`
 
@@ -176,6 +195,69 @@ type StreamChunk struct {
 	Created int64 `json:"created"`
 }
 
+// RawStreamChunk is a chunk of a streaming response in the OpenAI chat
+// completion chunk format, as received from the provider. Only chunks that
+// carry a finish reason or usage are decoded, to fill those fields.
+//
+// The chunk is held in a pooled buffer: it must not be used after Release.
+type RawStreamChunk struct {
+	// FinishReason is the normalized finish reason, set in the final chunk
+	FinishReason string
+
+	// Usage is set in the chunk that reports token usage
+	Usage *TokenUsage
+
+	// Error is set if an error occurred during streaming
+	Error error
+
+	// frame is the chunk as a Server-Sent Event: "data: <json>\n\n"
+	frame *bytes.Buffer
+}
+
+// sseDataPrefix and sseEventEnd frame the JSON of a chunk as a
+// Server-Sent Event.
+const (
+	sseDataPrefix = "data: "
+	sseEventEnd   = "\n\n"
+)
+
+// NewRawStreamChunk returns a chunk holding a copy of data, the JSON of an
+// SSE data line, in a pooled buffer.
+func NewRawStreamChunk(data []byte) *RawStreamChunk {
+	frame := GetBuffer()
+	frame.Grow(len(sseDataPrefix) + len(data) + len(sseEventEnd))
+	frame.WriteString(sseDataPrefix)
+	frame.Write(data)
+	frame.WriteString(sseEventEnd)
+	return &RawStreamChunk{frame: frame}
+}
+
+// Data returns the JSON of the chunk.
+func (c *RawStreamChunk) Data() []byte {
+	if c.frame == nil {
+		return nil
+	}
+	b := c.frame.Bytes()
+	return b[len(sseDataPrefix) : len(b)-len(sseEventEnd)]
+}
+
+// SSE returns the chunk as a Server-Sent Event, ready to be written to a
+// client.
+func (c *RawStreamChunk) SSE() []byte {
+	if c.frame == nil {
+		return nil
+	}
+	return c.frame.Bytes()
+}
+
+// Release returns the buffer of the chunk to the pool.
+func (c *RawStreamChunk) Release() {
+	if c.frame != nil {
+		PutBuffer(c.frame)
+		c.frame = nil
+	}
+}
+
 // ProviderHealth tracks the health status of a provider.
 type ProviderHealth struct {
 	// IsHealthy indicates whether the provider is currently healthy
@@ -206,7 +288,8 @@ type ProviderConfig struct {
 	// Name is the provider identifier (e.g., "openai", "anthropic")
 	Name string
 
-	// Type is the provider type (openai, anthropic, generic)
+	// Type is the provider type (openai, anthropic, bedrock, vertex, gemini,
+	// tgi, replicate, generic)
 	Type string
 
 	// BaseURL is the API endpoint base URL
@@ -232,6 +315,47 @@ type ProviderConfig struct {
 
 	// IdleConnTimeout is how long an idle connection remains in the pool
 	IdleConnTimeout time.Duration
+
+	// TraceBaggage sends the request ID and tenant of requests in a W3C
+	// baggage header (see ContextWithTraceBaggage)
+	TraceBaggage bool
+
+	// Region is the cloud region of providers that require one (bedrock,
+	// vertex)
+	Region string
+
+	// API selects the API of providers that offer several (bedrock:
+	// "converse" or "invoke")
+	API string
+
+	// Project is the cloud project of providers that require one (vertex)
+	Project string
+
+	// CredentialsFile is the cloud credentials file of providers that
+	// read one (vertex)
+	CredentialsFile string
+
+	// SafetySettings are content safety thresholds by harm category
+	// (vertex, gemini)
+	SafetySettings map[string]string
+
+	// PromptFormat is the chat template that messages are rendered with by
+	// providers that take a single prompt (tgi, replicate)
+	PromptFormat string
+
+	// Models maps the model names routed to the provider, including
+	// aliases, to the upstream model it serves them with. An empty upstream
+	// model keeps the requested name. Routing is done by the provider
+	// manager; adapters receive the upstream model.
+	Models map[string]string
+
+	// ProxyURL is the egress proxy that requests are sent through
+	// (http://, https://, or socks5://). Empty sends requests directly.
+	ProxyURL string
+
+	// NoProxy lists the hosts that are connected to directly rather than
+	// through ProxyURL, in the format of the NO_PROXY environment variable
+	NoProxy []string
 }
 
 // Message role constants
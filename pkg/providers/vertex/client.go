@@ -0,0 +1,167 @@
+package vertex
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"mercator-hq/jupiter/pkg/providers"
+	"mercator-hq/jupiter/pkg/providers/gemini"
+	"mercator-hq/jupiter/pkg/security/gcpauth"
+)
+
+// Provider is the Google Vertex AI provider adapter.
+// It implements the providers.Provider interface for the Gemini
+// generateContent and streamGenerateContent APIs.
+//
+// Vertex AI takes the request and response format of the Gemini API: the
+// embedded Gemini adapter sends the requests, to Vertex AI URLs and with
+// Google Cloud access tokens.
+type Provider struct {
+	*gemini.Provider
+
+	// project and location are the Google Cloud project and location of
+	// the models
+	project  string
+	location string
+}
+
+const (
+	// defaultLocation is the location used when none is configured
+	defaultLocation = "us-central1"
+
+	// globalLocation is the location served by the global endpoint
+	globalLocation = "global"
+
+	// projectResolveTimeout bounds the lookup of the project of the
+	// credentials, which may query the metadata server
+	projectResolveTimeout = 10 * time.Second
+)
+
+// credentials supplies access tokens and the default project.
+// It is implemented by gcpauth.Credentials.
+type credentials interface {
+	Token(ctx context.Context) (string, error)
+	ProjectID(ctx context.Context) (string, error)
+}
+
+// NewProvider creates a new Vertex AI provider instance. Requests are
+// authorized with OAuth access tokens from the configured credentials file,
+// or from Application Default Credentials (see gcpauth.Credentials).
+func NewProvider(config providers.ProviderConfig) (*Provider, error) {
+	return newProvider(config, gcpauth.NewCredentials(nil, config.CredentialsFile))
+}
+
+// newProvider creates a provider authorizing requests with the tokens of
+// creds.
+func newProvider(config providers.ProviderConfig, creds credentials) (*Provider, error) {
+	// Validate configuration
+	if config.Name == "" {
+		return nil, &providers.ConfigError{
+			Provider: "vertex",
+			Field:    "name",
+			Message:  "provider name is required",
+		}
+	}
+
+	if config.Region == "" {
+		config.Region = defaultLocation
+	}
+
+	project := config.Project
+	if project == "" {
+		project = projectFromEnv()
+	}
+	if project == "" {
+		ctx, cancel := context.WithTimeout(context.Background(), projectResolveTimeout)
+		defer cancel()
+		var err error
+		if project, err = creds.ProjectID(ctx); err != nil {
+			return nil, &providers.ConfigError{
+				Provider: config.Name,
+				Field:    "project",
+				Message:  fmt.Sprintf("project is required for Vertex AI (or set GOOGLE_CLOUD_PROJECT): %v", err),
+			}
+		}
+	}
+
+	customEndpoint := config.BaseURL != ""
+	if !customEndpoint {
+		config.BaseURL = "https://" + config.Region + "-aiplatform.googleapis.com"
+		if config.Region == globalLocation {
+			config.BaseURL = "https://aiplatform.googleapis.com"
+		}
+	}
+	config.BaseURL = strings.TrimSuffix(config.BaseURL, "/")
+
+	// Set defaults if not provided
+	if config.MaxRetries == 0 {
+		config.MaxRetries = 3
+	}
+	if config.MaxIdleConns == 0 {
+		config.MaxIdleConns = 100
+	}
+	if config.MaxIdleConnsPerHost == 0 {
+		config.MaxIdleConnsPerHost = 10
+	}
+
+	// Create base HTTP provider, authorizing each request
+	httpProvider := providers.NewHTTPProvider(config)
+	httpProvider.SetRequestSigner(func(req *http.Request, body []byte) error {
+		token, err := creds.Token(req.Context())
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+
+	p := &Provider{
+		project:  project,
+		location: config.Region,
+	}
+	p.Provider = gemini.NewProviderWithEndpoint(httpProvider, p.modelURL, config.SafetySettings)
+
+	// The API has no endpoint at the root; health checks list the
+	// endpoints of the location instead.
+	if !customEndpoint {
+		httpProvider.SetHealthCheckURL(fmt.Sprintf("%s/v1/projects/%s/locations/%s/endpoints?pageSize=1",
+			config.BaseURL, url.PathEscape(project), url.PathEscape(config.Region)))
+	}
+
+	slog.Info("Vertex AI provider initialized",
+		"provider", config.Name,
+		"project", project,
+		"location", config.Region,
+		"base_url", config.BaseURL,
+	)
+
+	return p, nil
+}
+
+// modelURL returns the URL of a method of a model. Model names (e.g.
+// "gemini-2.0-flash") are Google publisher models of the location of the
+// provider; full resource names ("projects/.../models/...", such as tuned
+// model endpoints) are used as given.
+func (p *Provider) modelURL(model, method string) string {
+	resource := fmt.Sprintf("projects/%s/locations/%s/publishers/google/models/%s",
+		url.PathEscape(p.project), url.PathEscape(p.location), url.PathEscape(model))
+	if strings.HasPrefix(model, "projects/") {
+		resource = model
+	}
+	return fmt.Sprintf("%s/v1/%s:%s", p.GetConfig().BaseURL, resource, method)
+}
+
+// projectFromEnv returns the project of the GOOGLE_CLOUD_PROJECT or
+// CLOUDSDK_CORE_PROJECT environment variables, or "".
+func projectFromEnv() string {
+	if project := os.Getenv("GOOGLE_CLOUD_PROJECT"); project != "" {
+		return project
+	}
+	return os.Getenv("CLOUDSDK_CORE_PROJECT")
+}
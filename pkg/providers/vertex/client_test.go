@@ -0,0 +1,223 @@
+package vertex
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mercator-hq/jupiter/pkg/providers"
+	"mercator-hq/jupiter/pkg/providers/gemini"
+)
+
+const testModel = "gemini-2.0-flash"
+
+// staticCredentials supplies a fixed token and project.
+type staticCredentials struct {
+	token   string
+	project string
+	err     error
+}
+
+func (c staticCredentials) Token(ctx context.Context) (string, error) {
+	return c.token, c.err
+}
+
+func (c staticCredentials) ProjectID(ctx context.Context) (string, error) {
+	if c.project == "" {
+		return "", fmt.Errorf("no project")
+	}
+	return c.project, nil
+}
+
+func newTestProvider(t *testing.T, baseURL string, safetySettings map[string]string) *Provider {
+	t.Helper()
+	provider, err := newProvider(providers.ProviderConfig{
+		Name:           "vertex",
+		Type:           "vertex",
+		BaseURL:        baseURL,
+		Region:         "europe-west4",
+		Project:        "my-project",
+		SafetySettings: safetySettings,
+	}, staticCredentials{token: "ya29.token"})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+	return provider
+}
+
+// vertexHandler checks the token and path of requests, decodes their body
+// into body, and serves them with serve.
+func vertexHandler(t *testing.T, path string, body interface{}, serve func(w http.ResponseWriter)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if authorization := r.Header.Get("Authorization"); authorization != "Bearer ya29.token" {
+			t.Errorf("Authorization = %q, want the access token", authorization)
+		}
+		if r.RequestURI != path {
+			t.Errorf("request URI = %q, want %q", r.RequestURI, path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(body); err != nil {
+			t.Errorf("failed to decode request: %v", err)
+		}
+		serve(w)
+	}
+}
+
+func TestVertexProvider_SendCompletion(t *testing.T) {
+	var body gemini.GenerateContentRequest
+	server := httptest.NewServer(vertexHandler(t, "/v1/projects/my-project/locations/europe-west4/publishers/google/models/gemini-2.0-flash:generateContent", &body, func(w http.ResponseWriter) {
+		_, _ = w.Write([]byte(`{
+			"candidates": [{"content": {"role": "model", "parts": [{"text": "Hello!"}]}, "finishReason": "STOP"}],
+			"usageMetadata": {"promptTokenCount": 5, "candidatesTokenCount": 2, "totalTokenCount": 7},
+			"responseId": "resp-123"
+		}`))
+	}))
+	defer server.Close()
+
+	provider := newTestProvider(t, server.URL, map[string]string{"HARM_CATEGORY_HARASSMENT": "BLOCK_NONE"})
+	resp, err := provider.SendCompletion(context.Background(), &providers.CompletionRequest{
+		Model: testModel,
+		Messages: []providers.Message{
+			{Role: providers.RoleSystem, Content: "Be brief."},
+			{Role: providers.RoleUser, Content: "Hello"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("SendCompletion failed: %v", err)
+	}
+
+	// Verify request
+	if body.SystemInstruction == nil || len(body.Contents) != 1 || body.Contents[0].Role != "user" {
+		t.Errorf("request = %+v, want the system instruction and the user message", body)
+	}
+	if len(body.SafetySettings) != 1 || body.SafetySettings[0].Threshold != "BLOCK_NONE" {
+		t.Errorf("safetySettings = %+v, want the configured threshold", body.SafetySettings)
+	}
+
+	// Verify response
+	if resp.ID != "resp-123" || resp.Content != "Hello!" || resp.FinishReason != providers.FinishReasonStop || resp.Usage.TotalTokens != 7 {
+		t.Errorf("response = %+v, want the Gemini response", resp)
+	}
+}
+
+// collect reads all chunks of a stream.
+func collect(t *testing.T, chunks <-chan *providers.StreamChunk) ([]*providers.StreamChunk, string) {
+	t.Helper()
+	var all []*providers.StreamChunk
+	var text strings.Builder
+	for chunk := range chunks {
+		all = append(all, chunk)
+		text.WriteString(chunk.Delta)
+	}
+	return all, text.String()
+}
+
+func TestVertexProvider_StreamCompletion(t *testing.T) {
+	server := httptest.NewServer(vertexHandler(t, "/v1/projects/my-project/locations/europe-west4/publishers/google/models/gemini-2.0-flash:streamGenerateContent?alt=sse", &gemini.GenerateContentRequest{}, func(w http.ResponseWriter) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "data: {\"candidates\":[{\"content\":{\"role\":\"model\",\"parts\":[{\"text\":\"Hel\"}]}}]}\r\n\r\n")
+		fmt.Fprint(w, "data: {\"candidates\":[{\"content\":{\"role\":\"model\",\"parts\":[{\"text\":\"lo\"}]},\"finishReason\":\"STOP\"}],\"usageMetadata\":{\"promptTokenCount\":12,\"candidatesTokenCount\":8,\"totalTokenCount\":20}}\r\n\r\n")
+	}))
+	defer server.Close()
+
+	chunks, err := newTestProvider(t, server.URL, nil).StreamCompletion(context.Background(), &providers.CompletionRequest{
+		Model:    testModel,
+		Messages: []providers.Message{{Role: providers.RoleUser, Content: "Hello"}},
+		Stream:   true,
+	})
+	if err != nil {
+		t.Fatalf("StreamCompletion failed: %v", err)
+	}
+
+	all, text := collect(t, chunks)
+	if text != "Hello" || len(all) != 2 {
+		t.Fatalf("stream = %q in %d chunks, want Hello in 2", text, len(all))
+	}
+	if final := all[1]; final.FinishReason != providers.FinishReasonStop || final.Usage == nil || final.Usage.TotalTokens != 20 {
+		t.Errorf("final chunk = %+v, want the finish reason and usage", final)
+	}
+}
+
+func TestVertexProvider_TokenError(t *testing.T) {
+	provider, err := newProvider(providers.ProviderConfig{
+		Name:    "vertex",
+		BaseURL: "http://127.0.0.1:1",
+		Project: "my-project",
+	}, staticCredentials{err: fmt.Errorf("gcpauth: no Google credentials found")})
+	if err != nil {
+		t.Fatalf("failed to create provider: %v", err)
+	}
+
+	_, err = provider.SendCompletion(context.Background(), &providers.CompletionRequest{
+		Model:    testModel,
+		Messages: []providers.Message{{Role: providers.RoleUser, Content: "Hello"}},
+	})
+	var authErr *providers.AuthError
+	if !errors.As(err, &authErr) || !strings.Contains(authErr.Message, "no Google credentials") {
+		t.Errorf("SendCompletion() error = %v, want an AuthError", err)
+	}
+}
+
+func TestNewProvider(t *testing.T) {
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "")
+	t.Setenv("CLOUDSDK_CORE_PROJECT", "")
+
+	var configErr *providers.ConfigError
+	if _, err := newProvider(providers.ProviderConfig{Name: "vertex"}, staticCredentials{}); !errors.As(err, &configErr) || configErr.Field != "project" {
+		t.Errorf("newProvider() without a project error = %v, want a project error", err)
+	}
+
+	tests := []struct {
+		name        string
+		config      providers.ProviderConfig
+		credentials staticCredentials
+		env         string
+		wantBaseURL string
+		wantURL     string
+	}{
+		{
+			name:        "credentials project",
+			config:      providers.ProviderConfig{Name: "vertex"},
+			credentials: staticCredentials{project: "sa-project"},
+			wantBaseURL: "https://us-central1-aiplatform.googleapis.com",
+			wantURL:     "https://us-central1-aiplatform.googleapis.com/v1/projects/sa-project/locations/us-central1/publishers/google/models/gemini-2.0-flash:generateContent",
+		},
+		{
+			name:        "environment project and global location",
+			config:      providers.ProviderConfig{Name: "vertex", Region: "global"},
+			credentials: staticCredentials{project: "sa-project"},
+			env:         "env-project",
+			wantBaseURL: "https://aiplatform.googleapis.com",
+			wantURL:     "https://aiplatform.googleapis.com/v1/projects/env-project/locations/global/publishers/google/models/gemini-2.0-flash:generateContent",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("GOOGLE_CLOUD_PROJECT", tt.env)
+			provider, err := newProvider(tt.config, tt.credentials)
+			if err != nil {
+				t.Fatalf("newProvider() error = %v", err)
+			}
+			if config := provider.GetConfig(); config.BaseURL != tt.wantBaseURL {
+				t.Errorf("base URL = %q, want %q", config.BaseURL, tt.wantBaseURL)
+			}
+			if url := provider.modelURL(testModel, "generateContent"); url != tt.wantURL {
+				t.Errorf("modelURL() = %q, want %q", url, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestModelURL_ResourceName(t *testing.T) {
+	provider := newTestProvider(t, "https://psc.example.com/", nil)
+	model := "projects/my-project/locations/europe-west4/endpoints/1234567890"
+	want := "https://psc.example.com/v1/projects/my-project/locations/europe-west4/endpoints/1234567890:generateContent"
+	if url := provider.modelURL(model, "generateContent"); url != want {
+		t.Errorf("modelURL() = %q, want %q", url, want)
+	}
+}
@@ -0,0 +1,88 @@
+// Package vertex implements the Google Vertex AI provider adapter.
+//
+// This package provides an implementation of the providers.Provider interface
+// for the Gemini models of Vertex AI. It supports:
+//
+//   - generateContent and streamGenerateContent APIs
+//   - System instructions and function calling
+//   - Safety settings, with blocked prompts and responses reported as
+//     content_filter
+//   - Service account, gcloud user, and metadata server (workload identity)
+//     credentials
+//   - Token usage tracking
+//
+// # Basic Usage
+//
+//	config := providers.ProviderConfig{
+//	    Name:    "vertex",
+//	    Type:    "vertex",
+//	    Project: "my-project",
+//	    Region:  "us-central1",
+//	}
+//
+//	provider, err := vertex.NewProvider(config)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer provider.Close()
+//
+//	req := &providers.CompletionRequest{
+//	    Model: "gemini-2.0-flash",
+//	    Messages: []providers.Message{
+//	        {Role: "user", Content: "Hello!"},
+//	    },
+//	}
+//
+//	resp, err := provider.SendCompletion(context.Background(), req)
+//
+// Model names are Google publisher models of the configured location. Full
+// resource names ("projects/{project}/locations/{location}/endpoints/{id}",
+// e.g. for tuned models) are used as given.
+//
+// # Authentication
+//
+// Requests carry OAuth access tokens with the cloud-platform scope, from
+// the configured credentials file or from Application Default Credentials
+// (see gcpauth.Credentials): GOOGLE_APPLICATION_CREDENTIALS, the gcloud
+// credentials, or the metadata server of GCE, GKE workload identity, and
+// Cloud Run. Tokens are refreshed before they expire. The API key of the
+// provider configuration is not used.
+//
+// The project is taken from the configuration, the GOOGLE_CLOUD_PROJECT
+// environment variable, or the credentials. The location defaults to
+// us-central1, and the base URL to the regional endpoint,
+// https://{location}-aiplatform.googleapis.com (or
+// https://aiplatform.googleapis.com for the "global" location); set it to
+// use a Private Service Connect endpoint.
+//
+// # Request Mapping
+//
+// Vertex AI takes the request format of the Gemini API, and requests are
+// translated by the gemini package: system messages become the system
+// instruction, assistant messages "model" contents, and tool messages
+// functionResponse parts, named after the tool call they answer. Tool
+// results that are not JSON objects are wrapped as {"content": "..."}.
+// Gemini does not identify function calls, so the adapter generates tool
+// call IDs.
+//
+// # Streaming
+//
+// Streams are requested as Server-Sent Events (alt=sse). Text deltas and
+// function calls are sent as they arrive; the chunk with the finish reason
+// carries the token usage.
+//
+// # Error Handling
+//
+// The adapter maps Vertex AI errors to common error types:
+//
+//   - 401/403 -> AuthError (including token request failures)
+//   - 429 -> RateLimitError (RESOURCE_EXHAUSTED)
+//   - 400 -> ProviderError (INVALID_ARGUMENT)
+//   - 5xx -> ProviderError (retried automatically)
+//
+// # Health Checks
+//
+// Health checks list the endpoints of the location, which requires the
+// aiplatform.endpoints.list permission (included in roles/aiplatform.user).
+// With a custom base URL, the base URL is checked instead.
+package vertex
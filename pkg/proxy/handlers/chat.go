@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -10,11 +11,12 @@ import (
 	"mercator-hq/jupiter/pkg/proxy"
 	"mercator-hq/jupiter/pkg/proxy/middleware"
 	"mercator-hq/jupiter/pkg/proxy/types"
+	"mercator-hq/jupiter/pkg/security/auth"
 )
 
-// convertToProviderRequest converts an OpenAI request to provider format.
+// ConvertToProviderRequest converts an OpenAI request to provider format.
 // Handles chat messages including tool calls and multimodal content.
-func convertToProviderRequest(req *types.ChatCompletionRequest) *providers.CompletionRequest {
+func ConvertToProviderRequest(req *types.ChatCompletionRequest) *providers.CompletionRequest {
 	providerReq := &providers.CompletionRequest{
 		Model:    req.Model,
 		Messages: make([]providers.Message, 0, len(req.Messages)),
@@ -65,6 +67,14 @@ func convertToProviderRequest(req *types.ChatCompletionRequest) *providers.Compl
 		providerReq.FrequencyPenalty = *req.FrequencyPenalty
 	}
 
+	if req.TopK != nil {
+		providerReq.TopK = *req.TopK
+	}
+
+	if req.RepetitionPenalty != nil {
+		providerReq.RepetitionPenalty = *req.RepetitionPenalty
+	}
+
 	if req.User != "" {
 		providerReq.User = req.User
 	}
@@ -195,33 +205,51 @@ func convertTools(tools []types.Tool) []providers.Tool {
 	return providerTools
 }
 
-// selectProviderFromManager selects an appropriate provider for the request.
-// Uses model-based routing to select the best provider for the requested model.
-func selectProviderFromManager(pm ProviderManager, req *types.ChatCompletionRequest) (providers.Provider, error) {
+// selectProviderFromManager selects an appropriate provider for the request,
+// and returns the model to request from it.
+// Models routed by the manager (see ModelResolver) are sent to their
+// provider as its upstream model. Other models use model-based routing to
+// select the best provider, and are sent unchanged.
+func selectProviderFromManager(pm ProviderManager, req *types.ChatCompletionRequest) (providers.Provider, string, error) {
 	// Get all healthy providers
 	healthy := pm.GetHealthyProviders()
 
 	if len(healthy) == 0 {
-		return nil, &providers.ProviderError{
+		return nil, "", &providers.ProviderError{
 			Message:    "No healthy providers available",
 			StatusCode: 503,
 			Provider:   "none",
 		}
 	}
 
+	// Routed models are only served by their provider
+	if resolver, ok := pm.(ModelResolver); ok {
+		if name, model, ok := resolver.ResolveModel(req.Model); ok {
+			provider, exists := healthy[name]
+			if !exists {
+				return nil, "", &providers.ProviderError{
+					Message:    fmt.Sprintf("No healthy provider for model %q", req.Model),
+					StatusCode: 503,
+					Provider:   name,
+				}
+			}
+			return provider, model, nil
+		}
+	}
+
 	// Try model-based routing first
 	provider := selectProviderByModel(healthy, req.Model)
 	if provider != nil {
-		return provider, nil
+		return provider, req.Model, nil
 	}
 
 	// Fall back to first healthy provider
 	for _, p := range healthy {
-		return p, nil
+		return p, req.Model, nil
 	}
 
 	// Should never reach here
-	return nil, &providers.ProviderError{
+	return nil, "", &providers.ProviderError{
 		Message:    "Provider selection failed",
 		StatusCode: 500,
 		Provider:   "none",
@@ -259,8 +287,23 @@ func selectProviderByModel(healthyProviders map[string]providers.Provider, model
 	return nil
 }
 
-// handleChatRequest handles a chat completion request (non-streaming).
-func handleChatRequest(w http.ResponseWriter, r *http.Request, pm ProviderManager) {
+// withTraceBaggage returns a context whose provider requests carry the
+// request ID and tenant as trace baggage. The tenant is the team of the
+// API key, or its user.
+func withTraceBaggage(ctx context.Context, requestID string) context.Context {
+	var tenant string
+	if info, ok := auth.GetAPIKeyInfo(ctx); ok && info != nil {
+		tenant = info.TeamID
+		if tenant == "" {
+			tenant = info.UserID
+		}
+	}
+	return providers.ContextWithTraceBaggage(ctx, requestID, tenant)
+}
+
+// handleChatRequest handles a chat completion request. Streaming requests
+// are handled by handleStreamRequest.
+func handleChatRequest(w http.ResponseWriter, r *http.Request, pm ProviderManager, passthrough bool) {
 	ctx := r.Context()
 	requestID := middleware.GetRequestID(ctx)
 	startTime := time.Now()
@@ -293,9 +336,25 @@ func handleChatRequest(w http.ResponseWriter, r *http.Request, pm ProviderManage
 		return
 	}
 
+	// Serve requests over budget with a cheaper model
+	if model, ok := middleware.DowngradeModel(ctx, chatReq.Model); ok {
+		slog.InfoContext(ctx, "downgrading model for request over budget",
+			"request_id", requestID,
+			"model", chatReq.Model,
+			"downgraded_model", model,
+		)
+		w.Header().Set(middleware.DowngradedFromHeader, chatReq.Model)
+		chatReq.Model = model
+	}
+
+	// Warn the model when the budget is running low
+	if warning, ok := middleware.BudgetWarning(ctx); ok {
+		chatReq.Messages = append([]types.Message{{Role: "system", Content: warning}}, chatReq.Messages...)
+	}
+
 	// Handle streaming requests separately
 	if chatReq.Stream {
-		handleStreamRequest(w, r, pm, chatReq)
+		handleStreamRequest(w, r, pm, chatReq, passthrough)
 		return
 	}
 
@@ -307,7 +366,7 @@ func handleChatRequest(w http.ResponseWriter, r *http.Request, pm ProviderManage
 	)
 
 	// Select provider
-	provider, err := selectProviderFromManager(pm, chatReq)
+	provider, model, err := selectProviderFromManager(pm, chatReq)
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to select provider",
 			"request_id", requestID,
@@ -322,12 +381,13 @@ func handleChatRequest(w http.ResponseWriter, r *http.Request, pm ProviderManage
 		return
 	}
 
-	// Convert to provider format
-	providerReq := convertToProviderRequest(chatReq)
+	// Convert to provider format; responses keep the requested model
+	providerReq := ConvertToProviderRequest(chatReq)
+	providerReq.Model = model
 
 	// Forward request to provider
 	providerStartTime := time.Now()
-	providerResp, err := provider.SendCompletion(ctx, providerReq)
+	providerResp, err := provider.SendCompletion(withTraceBaggage(ctx, requestID), providerReq)
 	providerLatency := time.Since(providerStartTime)
 
 	if err != nil {
@@ -335,6 +395,7 @@ func handleChatRequest(w http.ResponseWriter, r *http.Request, pm ProviderManage
 			"request_id", requestID,
 			"provider", provider.GetName(),
 			"model", chatReq.Model,
+			"upstream_model", model,
 			"error", err,
 			"provider_latency_ms", providerLatency.Milliseconds(),
 		)
@@ -346,6 +407,9 @@ func handleChatRequest(w http.ResponseWriter, r *http.Request, pm ProviderManage
 		return
 	}
 
+	// Report actual usage of the upstream model for limit reconciliation
+	middleware.ReportUsage(ctx, provider.GetName(), model, providerResp.Usage)
+
 	// Convert provider response to OpenAI format
 	openaiResp := proxy.FormatChatCompletionResponse(providerResp, chatReq.Model)
 
@@ -355,6 +419,7 @@ func handleChatRequest(w http.ResponseWriter, r *http.Request, pm ProviderManage
 		"request_id", requestID,
 		"provider", provider.GetName(),
 		"model", chatReq.Model,
+		"upstream_model", model,
 		"finish_reason", providerResp.FinishReason,
 		"prompt_tokens", providerResp.Usage.PromptTokens,
 		"completion_tokens", providerResp.Usage.CompletionTokens,
@@ -372,8 +437,10 @@ func handleChatRequest(w http.ResponseWriter, r *http.Request, pm ProviderManage
 	}
 }
 
-// handleStreamRequest handles a streaming chat completion request.
-func handleStreamRequest(w http.ResponseWriter, r *http.Request, pm ProviderManager, chatReq *types.ChatCompletionRequest) {
+// handleStreamRequest handles a streaming chat completion request. With
+// passthrough, the chunks of providers that stream in the OpenAI format are
+// forwarded as received instead of being decoded and re-encoded.
+func handleStreamRequest(w http.ResponseWriter, r *http.Request, pm ProviderManager, chatReq *types.ChatCompletionRequest, passthrough bool) {
 	ctx := r.Context()
 	requestID := middleware.GetRequestID(ctx)
 	startTime := time.Now()
@@ -386,7 +453,7 @@ func handleStreamRequest(w http.ResponseWriter, r *http.Request, pm ProviderMana
 	)
 
 	// Select provider
-	provider, err := selectProviderFromManager(pm, chatReq)
+	provider, model, err := selectProviderFromManager(pm, chatReq)
 	if err != nil {
 		slog.ErrorContext(ctx, "failed to select provider",
 			"request_id", requestID,
@@ -401,8 +468,9 @@ func handleStreamRequest(w http.ResponseWriter, r *http.Request, pm ProviderMana
 		return
 	}
 
-	// Convert to provider format
-	providerReq := convertToProviderRequest(chatReq)
+	// Convert to provider format; chunks keep the requested model
+	providerReq := ConvertToProviderRequest(chatReq)
+	providerReq.Model = model
 
 	// Set SSE headers
 	proxy.SetSSEHeaders(w)
@@ -414,12 +482,32 @@ func handleStreamRequest(w http.ResponseWriter, r *http.Request, pm ProviderMana
 
 	// Forward streaming request to provider
 	providerStartTime := time.Now()
-	chunks, err := provider.StreamCompletion(ctx, providerReq)
+	var stats *streamStats
+	// Raw chunks name the upstream model, so the chunks of remapped models
+	// are re-encoded
+	rawStreamer, raw := provider.(providers.RawStreamer)
+	raw = raw && passthrough && model == chatReq.Model
+	if raw {
+		var chunks <-chan *providers.RawStreamChunk
+		chunks, err = rawStreamer.StreamCompletionRaw(withTraceBaggage(ctx, requestID), providerReq)
+		if err == nil {
+			stats = forwardRawChunks(ctx, w, provider.GetName(), model, chunks)
+		}
+	} else {
+		var chunks <-chan *providers.StreamChunk
+		chunks, err = provider.StreamCompletion(withTraceBaggage(ctx, requestID), providerReq)
+		if err == nil {
+			// Generate response ID for all chunks
+			responseID := fmt.Sprintf("chatcmpl-%s", requestID)
+			stats = forwardChunks(ctx, w, provider.GetName(), chatReq.Model, model, responseID, chunks)
+		}
+	}
 	if err != nil {
 		slog.ErrorContext(ctx, "provider streaming request failed",
 			"request_id", requestID,
 			"provider", provider.GetName(),
 			"model", chatReq.Model,
+			"upstream_model", model,
 			"error", err,
 		)
 
@@ -430,26 +518,73 @@ func handleStreamRequest(w http.ResponseWriter, r *http.Request, pm ProviderMana
 		return
 	}
 
-	// Generate response ID for all chunks
-	responseID := fmt.Sprintf("chatcmpl-%s", requestID)
+	if stats.disconnected {
+		slog.WarnContext(ctx, "client disconnected during streaming",
+			"request_id", requestID,
+			"provider", provider.GetName(),
+			"chunks_sent", stats.chunks,
+		)
+		return
+	}
+
+	// Write [DONE] marker
+	if err := proxy.WriteSSEDone(w); err != nil {
+		slog.ErrorContext(ctx, "failed to write SSE done marker",
+			"request_id", requestID,
+			"error", err,
+		)
+	}
+
+	// Log successful completion
+	totalLatency := time.Since(startTime)
+	providerLatency := time.Since(providerStartTime)
+	var firstChunkLatency time.Duration
+	if !stats.firstChunk.IsZero() {
+		firstChunkLatency = stats.firstChunk.Sub(providerStartTime)
+	}
+
+	slog.InfoContext(ctx, "streaming chat completion successful",
+		"request_id", requestID,
+		"provider", provider.GetName(),
+		"model", chatReq.Model,
+		"upstream_model", model,
+		"passthrough", raw,
+		"chunks_sent", stats.chunks,
+		"total_tokens", stats.totalTokens,
+		"provider_latency_ms", providerLatency.Milliseconds(),
+		"first_chunk_latency_ms", firstChunkLatency.Milliseconds(),
+		"total_latency_ms", totalLatency.Milliseconds(),
+	)
+}
+
+// streamStats summarizes the chunks forwarded to a client.
+type streamStats struct {
+	chunks       int
+	firstChunk   time.Time
+	totalTokens  int
+	disconnected bool
+}
 
-	// Stream chunks to client
-	chunkCount := 0
-	var firstChunkTime time.Time
-	totalTokens := 0
+// forwardChunks decodes provider chunks and writes them to the client in
+// the OpenAI format, until the stream ends, fails, or the client
+// disconnects. Chunks name the requested model; usage is reported for the
+// upstream model.
+func forwardChunks(ctx context.Context, w http.ResponseWriter, providerName, model, upstreamModel, responseID string, chunks <-chan *providers.StreamChunk) *streamStats {
+	requestID := middleware.GetRequestID(ctx)
+	stats := &streamStats{}
 
 	for chunk := range chunks {
 		// Record first chunk timing
-		if chunkCount == 0 {
-			firstChunkTime = time.Now()
+		if stats.chunks == 0 {
+			stats.firstChunk = time.Now()
 		}
 
 		// Check for errors in chunk
 		if chunk.Error != nil {
 			slog.ErrorContext(ctx, "error in stream chunk",
 				"request_id", requestID,
-				"provider", provider.GetName(),
-				"chunk_count", chunkCount,
+				"provider", providerName,
+				"chunk_count", stats.chunks,
 				"error", chunk.Error,
 			)
 
@@ -461,70 +596,98 @@ func handleStreamRequest(w http.ResponseWriter, r *http.Request, pm ProviderMana
 		}
 
 		// Convert chunk to OpenAI format
-		openaiChunk := proxy.FormatStreamChunk(chunk, chatReq.Model, responseID)
+		openaiChunk := proxy.FormatStreamChunk(chunk, model, responseID)
 
 		// Write SSE chunk
 		if err := proxy.WriteSSEChunk(w, openaiChunk); err != nil {
 			slog.ErrorContext(ctx, "failed to write SSE chunk",
 				"request_id", requestID,
-				"chunk_count", chunkCount,
+				"chunk_count", stats.chunks,
 				"error", err,
 			)
 			break
 		}
 
-		chunkCount++
+		stats.chunks++
 
 		// Track tokens if present in chunk
 		if chunk.Usage != nil {
-			totalTokens = chunk.Usage.TotalTokens
+			stats.totalTokens = chunk.Usage.TotalTokens
+			middleware.ReportUsage(ctx, providerName, upstreamModel, *chunk.Usage)
 		}
 
 		// Check if client disconnected
-		select {
-		case <-ctx.Done():
-			slog.WarnContext(ctx, "client disconnected during streaming",
+		if ctx.Err() != nil {
+			stats.disconnected = true
+			break
+		}
+	}
+
+	return stats
+}
+
+// forwardRawChunks writes provider chunks to the client as received,
+// releasing their buffers, until the stream ends, fails, or the client
+// disconnects.
+func forwardRawChunks(ctx context.Context, w http.ResponseWriter, providerName, model string, chunks <-chan *providers.RawStreamChunk) *streamStats {
+	requestID := middleware.GetRequestID(ctx)
+	stats := &streamStats{}
+
+	for chunk := range chunks {
+		if stats.chunks == 0 {
+			stats.firstChunk = time.Now()
+		}
+
+		if chunk.Error != nil {
+			slog.ErrorContext(ctx, "error in stream chunk",
 				"request_id", requestID,
-				"provider", provider.GetName(),
-				"chunks_sent", chunkCount,
+				"provider", providerName,
+				"chunk_count", stats.chunks,
+				"error", chunk.Error,
 			)
-			return
-		default:
-			// Continue streaming
+
+			errResp := proxy.HandleError(chunk.Error)
+			if err := proxy.WriteSSEError(w, errResp); err != nil {
+				slog.ErrorContext(ctx, "failed to write SSE error", "error", err)
+			}
+			break
 		}
-	}
 
-	// Write [DONE] marker
-	if err := proxy.WriteSSEDone(w); err != nil {
-		slog.ErrorContext(ctx, "failed to write SSE done marker",
-			"request_id", requestID,
-			"error", err,
-		)
-	}
+		err := proxy.WriteSSEEvent(w, chunk.SSE())
+		usage := chunk.Usage
+		chunk.Release()
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to write SSE chunk",
+				"request_id", requestID,
+				"chunk_count", stats.chunks,
+				"error", err,
+			)
+			break
+		}
 
-	// Log successful completion
-	totalLatency := time.Since(startTime)
-	providerLatency := time.Since(providerStartTime)
-	var firstChunkLatency time.Duration
-	if !firstChunkTime.IsZero() {
-		firstChunkLatency = firstChunkTime.Sub(providerStartTime)
+		stats.chunks++
+
+		if usage != nil {
+			stats.totalTokens = usage.TotalTokens
+			middleware.ReportUsage(ctx, providerName, model, *usage)
+		}
+
+		if ctx.Err() != nil {
+			stats.disconnected = true
+			break
+		}
 	}
 
-	slog.InfoContext(ctx, "streaming chat completion successful",
-		"request_id", requestID,
-		"provider", provider.GetName(),
-		"model", chatReq.Model,
-		"chunks_sent", chunkCount,
-		"total_tokens", totalTokens,
-		"provider_latency_ms", providerLatency.Milliseconds(),
-		"first_chunk_latency_ms", firstChunkLatency.Milliseconds(),
-		"total_latency_ms", totalLatency.Milliseconds(),
-	)
+	return stats
 }
 
 // ChatHandler wraps the chat request handling for use by the server.
 type ChatHandler struct {
 	ProviderManager ProviderManager
+
+	// StreamPassthrough forwards the chunks of providers that stream in the
+	// OpenAI format as received (proxy.streaming.mode "passthrough").
+	StreamPassthrough bool
 }
 
 // NewChatHandler creates a new chat handler.
@@ -534,7 +697,7 @@ func NewChatHandler(pm ProviderManager) *ChatHandler {
 
 // ServeHTTP implements http.Handler.
 func (h *ChatHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	handleChatRequest(w, r, h.ProviderManager)
+	handleChatRequest(w, r, h.ProviderManager, h.StreamPassthrough)
 }
 
 // StreamHandler wraps the streaming request handling for use by the server.
@@ -551,5 +714,5 @@ func NewStreamHandler(pm ProviderManager) *StreamHandler {
 func (h *StreamHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	// For MVP, streaming is handled by ChatHandler
 	// This is kept for future separation if needed
-	handleChatRequest(w, r, h.ProviderManager)
+	handleChatRequest(w, r, h.ProviderManager, false)
 }
@@ -8,7 +8,11 @@ import (
 	"strings"
 	"testing"
 
+	"mercator-hq/jupiter/pkg/limits"
+	"mercator-hq/jupiter/pkg/limits/budget"
+	"mercator-hq/jupiter/pkg/limits/enforcement"
 	"mercator-hq/jupiter/pkg/providers"
+	"mercator-hq/jupiter/pkg/proxy/middleware"
 	"mercator-hq/jupiter/pkg/proxy/types"
 )
 
@@ -226,6 +230,8 @@ func TestConvertTools(t *testing.T) {
 func TestConvertToProviderRequest(t *testing.T) {
 	temp := 0.7
 	maxTokens := 100
+	topK := 40
+	repetitionPenalty := 1.1
 
 	tests := []struct {
 		name string
@@ -266,6 +272,25 @@ func TestConvertToProviderRequest(t *testing.T) {
 				MaxTokens:   100,
 			},
 		},
+		{
+			name: "request with sampling extensions",
+			req: &types.ChatCompletionRequest{
+				Model: "llama-3-8b",
+				Messages: []types.Message{
+					{Role: "user", Content: "Hello"},
+				},
+				TopK:              &topK,
+				RepetitionPenalty: &repetitionPenalty,
+			},
+			want: &providers.CompletionRequest{
+				Model: "llama-3-8b",
+				Messages: []providers.Message{
+					{Role: "user", Content: "Hello"},
+				},
+				TopK:              40,
+				RepetitionPenalty: 1.1,
+			},
+		},
 		{
 			name: "request with tool calls",
 			req: &types.ChatCompletionRequest{
@@ -327,7 +352,7 @@ func TestConvertToProviderRequest(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := convertToProviderRequest(tt.req)
+			got := ConvertToProviderRequest(tt.req)
 
 			if got.Model != tt.want.Model {
 				t.Errorf("Model = %v, want %v", got.Model, tt.want.Model)
@@ -344,6 +369,14 @@ func TestConvertToProviderRequest(t *testing.T) {
 			if tt.want.MaxTokens != 0 && got.MaxTokens != tt.want.MaxTokens {
 				t.Errorf("MaxTokens = %v, want %v", got.MaxTokens, tt.want.MaxTokens)
 			}
+
+			if got.TopK != tt.want.TopK {
+				t.Errorf("TopK = %v, want %v", got.TopK, tt.want.TopK)
+			}
+
+			if got.RepetitionPenalty != tt.want.RepetitionPenalty {
+				t.Errorf("RepetitionPenalty = %v, want %v", got.RepetitionPenalty, tt.want.RepetitionPenalty)
+			}
 		})
 	}
 }
@@ -516,7 +549,7 @@ func TestHandleChatRequest_Integration(t *testing.T) {
 	w := httptest.NewRecorder()
 
 	// Call handler
-	handleChatRequest(w, req, pm)
+	handleChatRequest(w, req, pm, false)
 
 	// Check response
 	if w.Code != http.StatusOK {
@@ -529,3 +562,227 @@ func TestHandleChatRequest_Integration(t *testing.T) {
 		t.Errorf("Response is not valid JSON: %v", err)
 	}
 }
+
+func TestHandleChatRequest_ModelDowngrade(t *testing.T) {
+	pm := &mockProviderManager{
+		providers: map[string]providers.Provider{
+			"openai": &mockProvider{name: "openai"},
+		},
+	}
+
+	manager := limits.NewManager(limits.Config{
+		Budgets: map[string]budget.Config{
+			"test-key": {Daily: 1.00},
+		},
+		Enforcement: enforcement.Config{
+			DefaultAction: enforcement.ActionDowngrade,
+			ModelDowngrades: map[string]string{
+				"gpt-4": "gpt-3.5-turbo",
+			},
+		},
+	})
+	defer manager.Close()
+
+	_ = manager.RecordUsage(context.Background(), &limits.UsageRecord{
+		Identifier: "test-key",
+		Dimension:  limits.DimensionAPIKey,
+		Cost:       2.00,
+	})
+
+	handler := middleware.LimitsMiddleware(manager)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handleChatRequest(w, r, pm, false)
+	}))
+
+	body := `{"model":"gpt-4","messages":[{"role":"user","content":"Hello"}]}`
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer test-key")
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Status code = %v, want %v. Body: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	if got := w.Header().Get(middleware.DowngradedFromHeader); got != "gpt-4" {
+		t.Errorf("%s = %q, want gpt-4", middleware.DowngradedFromHeader, got)
+	}
+
+	var resp types.ChatCompletionResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Response is not valid JSON: %v", err)
+	}
+	if resp.Model != "gpt-3.5-turbo" {
+		t.Errorf("Model = %q, want gpt-3.5-turbo", resp.Model)
+	}
+}
+
+// routingProviderManager is a mock provider manager that routes models.
+type routingProviderManager struct {
+	mockProviderManager
+	routes map[string][2]string // model -> provider, upstream model
+}
+
+func (m *routingProviderManager) ResolveModel(model string) (string, string, bool) {
+	route, ok := m.routes[model]
+	return route[0], route[1], ok
+}
+
+// recordingProvider is a mock provider recording the model it is sent.
+type recordingProvider struct {
+	mockProvider
+	model string
+}
+
+func (m *recordingProvider) SendCompletion(ctx context.Context, req *providers.CompletionRequest) (*providers.CompletionResponse, error) {
+	m.model = req.Model
+	return m.mockProvider.SendCompletion(ctx, req)
+}
+
+func TestHandleChatRequest_ModelRoute(t *testing.T) {
+	tests := []struct {
+		name         string
+		model        string
+		wantStatus   int
+		wantProvider string
+		wantUpstream string
+	}{
+		{"routed model", "gpt-4", http.StatusOK, "anthropic", "claude-3-5-sonnet-20241022"},
+		{"alias", "default-smart", http.StatusOK, "vllm", "llama-3-70b"},
+		{"unrouted model", "gpt-3.5-turbo", http.StatusOK, "openai", "gpt-3.5-turbo"},
+		{"unhealthy provider", "default-fast", http.StatusBadGateway, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			healthy := map[string]*recordingProvider{
+				"openai":    {mockProvider: mockProvider{name: "openai"}},
+				"anthropic": {mockProvider: mockProvider{name: "anthropic"}},
+				"vllm":      {mockProvider: mockProvider{name: "vllm"}},
+			}
+			pm := &routingProviderManager{
+				mockProviderManager: mockProviderManager{providers: map[string]providers.Provider{}},
+				routes: map[string][2]string{
+					"gpt-4":         {"anthropic", "claude-3-5-sonnet-20241022"},
+					"default-smart": {"vllm", "llama-3-70b"},
+					"default-fast":  {"bedrock", "anthropic.claude-3-haiku-20240307-v1:0"},
+				},
+			}
+			for name, provider := range healthy {
+				pm.providers[name] = provider
+			}
+
+			body := `{"model":"` + tt.model + `","messages":[{"role":"user","content":"Hello"}]}`
+			req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			handleChatRequest(w, req, pm, false)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status code = %v, want %v. Body: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			if got := healthy[tt.wantProvider].model; got != tt.wantUpstream {
+				t.Errorf("%s was sent model %q, want %q", tt.wantProvider, got, tt.wantUpstream)
+			}
+
+			var resp types.ChatCompletionResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Response is not valid JSON: %v", err)
+			}
+			if resp.Model != tt.model {
+				t.Errorf("Model = %q, want the requested model %q", resp.Model, tt.model)
+			}
+		})
+	}
+}
+
+// rawMockProvider is a mock provider streaming raw OpenAI chunks.
+type rawMockProvider struct {
+	mockProvider
+	chunks []string
+}
+
+func (m *rawMockProvider) StreamCompletion(ctx context.Context, req *providers.CompletionRequest) (<-chan *providers.StreamChunk, error) {
+	ch := make(chan *providers.StreamChunk, 2)
+	ch <- &providers.StreamChunk{ID: "upstream", Delta: "Hello"}
+	ch <- &providers.StreamChunk{ID: "upstream", FinishReason: "stop"}
+	close(ch)
+	return ch, nil
+}
+
+func (m *rawMockProvider) StreamCompletionRaw(ctx context.Context, req *providers.CompletionRequest) (<-chan *providers.RawStreamChunk, error) {
+	ch := make(chan *providers.RawStreamChunk, len(m.chunks))
+	for _, data := range m.chunks {
+		ch <- providers.NewRawStreamChunk([]byte(data))
+	}
+	close(ch)
+	return ch, nil
+}
+
+func TestHandleStreamRequest_Passthrough(t *testing.T) {
+	chunks := []string{
+		`{"id":"chatcmpl-upstream","object":"chat.completion.chunk","model":"gpt-4-0613","system_fingerprint":"fp_1","choices":[{"index":0,"delta":{"content":"Hello"},"finish_reason":null}]}`,
+		`{"id":"chatcmpl-upstream","object":"chat.completion.chunk","model":"gpt-4-0613","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`,
+	}
+	pm := &mockProviderManager{
+		providers: map[string]providers.Provider{
+			"openai": &rawMockProvider{mockProvider: mockProvider{name: "openai"}, chunks: chunks},
+		},
+	}
+	body := `{"model":"gpt-4","stream":true,"messages":[{"role":"user","content":"Hello"}]}`
+
+	tests := []struct {
+		name        string
+		passthrough bool
+		want        func(t *testing.T, events []string)
+	}{
+		{
+			name:        "passthrough",
+			passthrough: true,
+			want: func(t *testing.T, events []string) {
+				for i, chunk := range chunks {
+					if events[i] != "data: "+chunk {
+						t.Errorf("event %d = %q, want the provider chunk", i, events[i])
+					}
+				}
+			},
+		},
+		{
+			name:        "decode",
+			passthrough: false,
+			want: func(t *testing.T, events []string) {
+				var chunk types.ChatCompletionStreamChunk
+				if err := json.Unmarshal([]byte(strings.TrimPrefix(events[0], "data: ")), &chunk); err != nil {
+					t.Fatalf("event 0 is not a chunk: %v", err)
+				}
+				if chunk.Model != "gpt-4" || !strings.HasPrefix(chunk.ID, "chatcmpl-") || chunk.Choices[0].Delta.Content != "Hello" {
+					t.Errorf("event 0 = %+v, want a re-encoded chunk", chunk)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			handleChatRequest(w, req, pm, tt.passthrough)
+
+			if got := w.Header().Get("Content-Type"); got != "text/event-stream" {
+				t.Fatalf("Content-Type = %q, want text/event-stream", got)
+			}
+			events := strings.Split(strings.TrimSuffix(w.Body.String(), "\n\n"), "\n\n")
+			if len(events) != 3 || events[2] != "data: [DONE]" {
+				t.Fatalf("events = %q, want 2 chunks and [DONE]", events)
+			}
+			tt.want(t, events)
+		})
+	}
+}
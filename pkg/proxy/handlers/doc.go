@@ -10,6 +10,10 @@
 //   - HandleChatCompletion: Non-streaming chat completions
 //   - HandleStreamingCompletion: Server-Sent Events (SSE) streaming
 //
+// Estimate handler:
+//   - EstimateHandler: Pre-flight token, cost, and limit estimates
+//     (POST /v1/estimate) for requests that are not forwarded
+//
 // Health check handlers:
 //   - HandleHealth: Liveness probe (always returns 200)
 //   - HandleReady: Readiness probe (checks provider health)
@@ -54,6 +58,12 @@
 // Each chunk is prefixed with "data: " and ends with "\n\n". The stream is
 // terminated with "data: [DONE]\n\n".
 //
+// Providers that stream in this format (providers.RawStreamer) are forwarded
+// on a passthrough path when ChatHandler.StreamPassthrough is set: chunks are
+// written as received from pooled buffers, and only chunks carrying a finish
+// reason or usage are decoded. Other providers' chunks are decoded and
+// re-encoded with the response ID "chatcmpl-<request ID>".
+//
 // # Context Propagation
 //
 // Handlers extract metadata from requests and store it in context.Context for
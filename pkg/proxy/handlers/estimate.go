@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+
+	"mercator-hq/jupiter/pkg/limits"
+	"mercator-hq/jupiter/pkg/processing"
+	"mercator-hq/jupiter/pkg/proxy"
+	"mercator-hq/jupiter/pkg/proxy/middleware"
+	"mercator-hq/jupiter/pkg/proxy/types"
+)
+
+// EstimateResponse is the pre-flight estimate of a chat completion request.
+type EstimateResponse struct {
+	Object string `json:"object"`
+	Model  string `json:"model"`
+
+	// Token estimates
+	PromptTokens              int `json:"prompt_tokens"`
+	EstimatedCompletionTokens int `json:"estimated_completion_tokens"`
+	TotalTokens               int `json:"total_tokens"`
+
+	// Cost estimates in USD
+	PromptCost     float64 `json:"prompt_cost"`
+	CompletionCost float64 `json:"completion_cost"`
+	TotalCost      float64 `json:"total_cost"`
+	Currency       string  `json:"currency"`
+	PricingTier    string  `json:"pricing_tier"`
+
+	// Allowed reports whether the request would pass rate limits and
+	// budgets if it were sent now.
+	Allowed bool `json:"allowed"`
+
+	// Reason explains why the request would be rejected or downgraded.
+	Reason string `json:"reason,omitempty"`
+
+	// Action is the enforcement action the request would be subject to.
+	Action string `json:"action,omitempty"`
+
+	// DowngradeTo is the cheaper model the request would be served with.
+	DowngradeTo string `json:"downgrade_to,omitempty"`
+
+	// RetryAfter is the number of seconds until the request would be
+	// allowed.
+	RetryAfter int `json:"retry_after,omitempty"`
+}
+
+// EstimateHandler estimates the tokens and cost of a chat completion
+// request and checks it against rate limits and budgets, without forwarding
+// it to a provider.
+type EstimateHandler struct {
+	Processor *processing.Processor
+
+	// Limits checks the request against rate limits and budgets. It
+	// should be the manager enforcing limits on completion requests; if
+	// nil, no limits are enforced and every request is reported as allowed.
+	Limits *limits.Manager
+}
+
+// NewEstimateHandler creates a new pre-flight estimate handler.
+func NewEstimateHandler(processor *processing.Processor, manager *limits.Manager) *EstimateHandler {
+	return &EstimateHandler{Processor: processor, Limits: manager}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *EstimateHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	requestID := middleware.GetRequestID(ctx)
+
+	if r.Method != http.MethodPost {
+		errResp := types.NewInvalidRequestError(
+			fmt.Sprintf("Method %s not allowed. Use POST instead.", r.Method),
+			"method",
+			"method_not_allowed",
+		)
+		if err := proxy.WriteErrorResponse(w, errResp); err != nil {
+			slog.ErrorContext(ctx, "failed to write error response", "error", err)
+		}
+		return
+	}
+
+	chatReq, err := proxy.ParseChatCompletionRequest(r)
+	if err != nil {
+		errResp := proxy.HandleError(err)
+		if err := proxy.WriteErrorResponse(w, errResp); err != nil {
+			slog.ErrorContext(ctx, "failed to write error response", "error", err)
+		}
+		return
+	}
+
+	tokenEst, costEst, err := h.Processor.EstimateCost(chatReq)
+	if err != nil {
+		slog.ErrorContext(ctx, "failed to estimate request",
+			"request_id", requestID,
+			"model", chatReq.Model,
+			"error", err,
+		)
+		if err := proxy.WriteErrorResponse(w, types.NewServerError("Failed to estimate request cost")); err != nil {
+			slog.ErrorContext(ctx, "failed to write error response", "error", err)
+		}
+		return
+	}
+
+	resp := &EstimateResponse{
+		Object:                    "chat.completion.estimate",
+		Model:                     chatReq.Model,
+		PromptTokens:              tokenEst.PromptTokens,
+		EstimatedCompletionTokens: tokenEst.EstimatedCompletionTokens,
+		TotalTokens:               tokenEst.TotalTokens,
+		PromptCost:                costEst.PromptCost,
+		CompletionCost:            costEst.CompletionCost,
+		TotalCost:                 costEst.TotalCost,
+		Currency:                  costEst.Currency,
+		PricingTier:               costEst.PricingTier,
+		Allowed:                   true,
+	}
+
+	if h.Limits != nil {
+		result, err := middleware.PreviewLimits(h.Limits, r, tokenEst.TotalTokens, costEst.TotalCost, chatReq.Model)
+		if err != nil {
+			slog.ErrorContext(ctx, "failed to check limits for estimate",
+				"request_id", requestID,
+				"error", err,
+			)
+			if err := proxy.WriteErrorResponse(w, types.NewServerError("Internal error checking limits")); err != nil {
+				slog.ErrorContext(ctx, "failed to write error response", "error", err)
+			}
+			return
+		}
+		resp.Allowed = result.Allowed
+		resp.Reason = result.Reason
+		resp.Action = string(result.Action)
+		resp.DowngradeTo = result.DowngradeTo
+		if !result.Allowed && result.RetryAfter > 0 {
+			resp.RetryAfter = int(math.Ceil(result.RetryAfter.Seconds()))
+		}
+	}
+
+	if err := proxy.WriteJSONResponse(w, http.StatusOK, resp); err != nil {
+		slog.ErrorContext(ctx, "failed to write response",
+			"request_id", requestID,
+			"error", err,
+		)
+	}
+}
@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mercator-hq/jupiter/pkg/config"
+	"mercator-hq/jupiter/pkg/limits"
+	"mercator-hq/jupiter/pkg/limits/budget"
+	"mercator-hq/jupiter/pkg/limits/enforcement"
+	"mercator-hq/jupiter/pkg/processing"
+)
+
+func TestEstimateHandler(t *testing.T) {
+	processor := processing.NewProcessor(&config.ProcessingConfig{
+		Costs: config.CostsConfig{
+			Pricing: map[string]map[string]config.ModelPricingConfig{
+				"openai": {"gpt-4": {Prompt: 0.03, Completion: 0.06}},
+			},
+		},
+	})
+	manager := limits.NewManager(limits.Config{
+		Budgets: map[string]budget.Config{
+			"test-key": {Daily: 1.00},
+		},
+		Enforcement: enforcement.Config{DefaultAction: enforcement.ActionBlock},
+	})
+	defer manager.Close()
+	handler := NewEstimateHandler(processor, manager)
+
+	estimate := func(t *testing.T) *EstimateResponse {
+		t.Helper()
+		body := `{"model":"gpt-4","max_tokens":500,"messages":[{"role":"user","content":"Hello"}]}`
+		req := httptest.NewRequest(http.MethodPost, "/v1/estimate", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer test-key")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d: %s", w.Code, w.Body.String())
+		}
+		var resp EstimateResponse
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("Failed to decode response: %v", err)
+		}
+		return &resp
+	}
+
+	resp := estimate(t)
+	if !resp.Allowed {
+		t.Errorf("Expected request within budget to be allowed, got %+v", resp)
+	}
+	if resp.EstimatedCompletionTokens != 500 || resp.TotalTokens != resp.PromptTokens+500 {
+		t.Errorf("Unexpected token estimate: %+v", resp)
+	}
+	wantCost := float64(resp.PromptTokens)/1000*0.03 + 500.0/1000*0.06
+	if math.Abs(resp.TotalCost-wantCost) > 1e-9 || resp.Currency != "USD" {
+		t.Errorf("Expected total cost %.6f USD, got %.6f %s", wantCost, resp.TotalCost, resp.Currency)
+	}
+
+	_ = manager.RecordUsage(context.Background(), &limits.UsageRecord{Identifier: "test-key", Cost: 2.00})
+	resp = estimate(t)
+	if resp.Allowed || resp.Action != string(limits.ActionBlock) || resp.Reason == "" {
+		t.Errorf("Expected request over budget to be reported as blocked, got %+v", resp)
+	}
+}
+
+func TestEstimateHandler_MethodNotAllowed(t *testing.T) {
+	handler := NewEstimateHandler(processing.NewProcessor(&config.ProcessingConfig{}), nil)
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/estimate", nil))
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", w.Code)
+	}
+}
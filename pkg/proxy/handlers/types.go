@@ -8,3 +8,10 @@ type ProviderManager interface {
 	GetHealthyProviders() map[string]providers.Provider
 	Close() error
 }
+
+// ModelResolver is implemented by provider managers that route model names,
+// including aliases, to a provider and the upstream model it serves them
+// with (see providerfactory.Manager).
+type ModelResolver interface {
+	ResolveModel(model string) (provider, upstreamModel string, ok bool)
+}
@@ -4,8 +4,10 @@ import (
 	"net/http"
 	"time"
 
+	"mercator-hq/jupiter/pkg/limits"
 	"mercator-hq/jupiter/pkg/providers"
 	"mercator-hq/jupiter/pkg/proxy/types"
+	"mercator-hq/jupiter/pkg/security/auth"
 )
 
 // RequestMetadata contains extracted metadata from an HTTP request.
@@ -32,6 +34,18 @@ type RequestMetadata struct {
 	// UserID is the identifier for the end-user making the request.
 	UserID string
 
+	// TeamID is the team of the authenticated API key, if any.
+	TeamID string
+
+	// CostTag is the cost allocation tag the request is charged to, if
+	// any (see middleware.CostAllocationMiddleware).
+	CostTag string
+
+	// LimitBypass names the exemption or break-glass token the request
+	// bypassed rate limits and budgets with, if any (see
+	// middleware.LimitsMiddleware).
+	LimitBypass string
+
 	// APIKey is the authentication key (redacted for logging).
 	APIKey string
 
@@ -105,6 +119,14 @@ func ExtractRequestMetadata(r *http.Request, req *types.ChatCompletionRequest) *
 		Timestamp:  time.Now(),
 	}
 
+	if info, ok := auth.GetAPIKeyInfo(r.Context()); ok {
+		metadata.TeamID = info.TeamID
+	}
+	metadata.CostTag = limits.CostTagFromContext(r.Context())
+	if bypass, ok := limits.BypassFromContext(r.Context()); ok {
+		metadata.LimitBypass = bypass.String()
+	}
+
 	// Extract optional parameters with defaults
 	if req.MaxTokens != nil {
 		metadata.MaxTokens = *req.MaxTokens
@@ -0,0 +1,181 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"unicode"
+
+	"mercator-hq/jupiter/pkg/limits"
+	"mercator-hq/jupiter/pkg/proxy"
+	"mercator-hq/jupiter/pkg/proxy/types"
+)
+
+const (
+	// DefaultCostTagHeader is the default header carrying the cost
+	// allocation tag of a request.
+	DefaultCostTagHeader = "X-Cost-Center"
+
+	// DefaultCostTagMetadataKey is the default key of the request metadata
+	// carrying the cost allocation tag of a request.
+	DefaultCostTagMetadataKey = "cost_center"
+
+	// maxCostTagLength is the maximum length of a cost allocation tag.
+	maxCostTagLength = 128
+)
+
+// CostAllocationConfig contains configuration for cost allocation tags.
+type CostAllocationConfig struct {
+	// Enabled controls whether requests are tagged.
+	Enabled bool
+
+	// Header is the request header carrying the tag. It takes precedence
+	// over the request metadata.
+	Header string
+
+	// MetadataKey is the key of the request body's metadata object
+	// carrying the tag. Empty disables reading the request body.
+	MetadataKey string
+
+	// AllowedTags lists the accepted tags. An empty list accepts any tag
+	// of at most 128 characters without whitespace.
+	AllowedTags []string
+
+	// Required rejects untagged requests.
+	Required bool
+}
+
+// CostAllocationMiddleware tags requests with the cost center or project
+// their spending is charged to. The tag is read from the configured header
+// or, failing that, from the metadata of a JSON request body:
+//
+//	X-Cost-Center: research
+//	{"model": "gpt-4", "messages": [...], "metadata": {"cost_center": "research"}}
+//
+// Requests with a tag that is malformed or not allowed, and untagged
+// requests when a tag is required, are rejected with 400 Bad Request.
+// Accepted tags are stored in the request context with limits.WithCostTag,
+// from where usage records and evidence records pick them up.
+//
+// Example usage:
+//
+//	handler = CostAllocationMiddleware(&CostAllocationConfig{
+//	    Enabled:     true,
+//	    Header:      DefaultCostTagHeader,
+//	    AllowedTags: []string{"research", "support"},
+//	})(handler)
+func CostAllocationMiddleware(config *CostAllocationConfig) func(http.Handler) http.Handler {
+	allowed := make(map[string]bool, len(config.AllowedTags))
+	for _, tag := range config.AllowedTags {
+		allowed[tag] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !config.Enabled {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			tag, param, err := extractCostTag(r, config)
+			if err != nil {
+				writeCostTagError(w, err.Error(), param, types.CodeInvalidValue)
+				return
+			}
+
+			if tag == "" {
+				if config.Required {
+					writeCostTagError(w, costTagRequiredMessage(config), param, types.CodeMissingField)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			if len(tag) > maxCostTagLength || strings.IndexFunc(tag, unicode.IsSpace) >= 0 {
+				writeCostTagError(w, fmt.Sprintf("invalid cost allocation tag %q: must be at most %d characters without whitespace", tag, maxCostTagLength), param, types.CodeInvalidValue)
+				return
+			}
+			if len(allowed) > 0 && !allowed[tag] {
+				writeCostTagError(w, fmt.Sprintf("cost allocation tag %q is not allowed", tag), param, types.CodeInvalidValue)
+				return
+			}
+
+			next.ServeHTTP(w, r.WithContext(limits.WithCostTag(r.Context(), tag)))
+		})
+	}
+}
+
+// extractCostTag returns the cost allocation tag of a request and the
+// parameter it was read from. The request body is restored after reading
+// its metadata.
+func extractCostTag(r *http.Request, config *CostAllocationConfig) (string, string, error) {
+	if config.Header != "" {
+		if tag := strings.TrimSpace(r.Header.Get(config.Header)); tag != "" {
+			return tag, config.Header, nil
+		}
+	}
+
+	if config.MetadataKey == "" || r.Body == nil || r.Method != http.MethodPost {
+		return "", config.Header, nil
+	}
+	param := "metadata." + config.MetadataKey
+
+	body, err := peekBody(r)
+	if err != nil {
+		return "", param, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	// Malformed bodies are left for the handler to reject
+	var req struct {
+		Metadata map[string]any `json:"metadata"`
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		return "", param, nil
+	}
+	value, ok := req.Metadata[config.MetadataKey]
+	if !ok {
+		return "", param, nil
+	}
+	tag, ok := value.(string)
+	if !ok {
+		return "", param, fmt.Errorf("%s must be a string", param)
+	}
+	return strings.TrimSpace(tag), param, nil
+}
+
+// peekBody reads the request body, up to the maximum request body size,
+// and restores it for the next handler. Oversized bodies are left for the
+// handler to reject.
+func peekBody(r *http.Request) ([]byte, error) {
+	body, err := io.ReadAll(io.LimitReader(r.Body, proxy.MaxRequestBodySize))
+	if err != nil {
+		return nil, err
+	}
+	r.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+	return body, nil
+}
+
+// costTagRequiredMessage describes where an untagged request can set its
+// tag.
+func costTagRequiredMessage(config *CostAllocationConfig) string {
+	var sources []string
+	if config.Header != "" {
+		sources = append(sources, "the "+config.Header+" header")
+	}
+	if config.MetadataKey != "" {
+		sources = append(sources, "metadata."+config.MetadataKey)
+	}
+	return fmt.Sprintf("cost allocation tag is required: set %s", strings.Join(sources, " or "))
+}
+
+// writeCostTagError rejects a request with an invalid request error.
+func writeCostTagError(w http.ResponseWriter, message, param, code string) {
+	_ = proxy.WriteErrorResponse(w, types.NewInvalidRequestError(message, param, code))
+}
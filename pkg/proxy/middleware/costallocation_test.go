@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mercator-hq/jupiter/pkg/limits"
+)
+
+func TestCostAllocationMiddleware(t *testing.T) {
+	config := &CostAllocationConfig{
+		Enabled:     true,
+		Header:      DefaultCostTagHeader,
+		MetadataKey: DefaultCostTagMetadataKey,
+		AllowedTags: []string{"search", "support"},
+	}
+
+	tests := []struct {
+		name       string
+		header     string
+		body       string
+		required   bool
+		wantStatus int
+		wantTag    string
+	}{
+		{"header", "search", `{}`, false, http.StatusOK, "search"},
+		{"metadata", "", `{"metadata":{"cost_center":"support"}}`, false, http.StatusOK, "support"},
+		{"header takes precedence", "search", `{"metadata":{"cost_center":"support"}}`, false, http.StatusOK, "search"},
+		{"untagged", "", `{"model":"gpt-4"}`, false, http.StatusOK, ""},
+		{"untagged but required", "", `{"model":"gpt-4"}`, true, http.StatusBadRequest, ""},
+		{"not allowed", "marketing", `{}`, false, http.StatusBadRequest, ""},
+		{"whitespace", "", `{"metadata":{"cost_center":"sea rch"}}`, false, http.StatusBadRequest, ""},
+		{"not a string", "", `{"metadata":{"cost_center":42}}`, false, http.StatusBadRequest, ""},
+		{"malformed body", "", `{`, false, http.StatusOK, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := *config
+			cfg.Required = tt.required
+
+			var gotTag, gotBody string
+			handler := CostAllocationMiddleware(&cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotTag = limits.CostTagFromContext(r.Context())
+				body, _ := io.ReadAll(r.Body)
+				gotBody = string(body)
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(tt.body))
+			if tt.header != "" {
+				req.Header.Set(DefaultCostTagHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if w.Code != http.StatusOK {
+				return
+			}
+			if gotTag != tt.wantTag {
+				t.Errorf("Tag = %q, want %q", gotTag, tt.wantTag)
+			}
+			if gotBody != tt.body {
+				t.Errorf("Body = %q, want it restored as %q", gotBody, tt.body)
+			}
+		})
+	}
+}
+
+func TestCostAllocationMiddleware_Disabled(t *testing.T) {
+	handler := CostAllocationMiddleware(&CostAllocationConfig{Required: true})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(`{}`)))
+	if w.Code != http.StatusOK {
+		t.Errorf("Status = %d, want 200 when disabled", w.Code)
+	}
+}
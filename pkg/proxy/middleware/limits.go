@@ -2,16 +2,28 @@ package middleware
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
+	"sync"
 	"time"
 
+	"mercator-hq/jupiter/pkg/config"
 	"mercator-hq/jupiter/pkg/limits"
+	"mercator-hq/jupiter/pkg/limits/alerting"
 	"mercator-hq/jupiter/pkg/limits/budget"
 	"mercator-hq/jupiter/pkg/limits/enforcement"
 	"mercator-hq/jupiter/pkg/limits/ratelimit"
 	"mercator-hq/jupiter/pkg/limits/storage"
+	"mercator-hq/jupiter/pkg/providers"
 	"mercator-hq/jupiter/pkg/proxy"
+	"mercator-hq/jupiter/pkg/proxy/types"
+	"mercator-hq/jupiter/pkg/security/auth"
+	"mercator-hq/jupiter/pkg/telemetry/audit"
 )
 
 // LimitsMiddleware checks rate limits and budgets before forwarding requests.
@@ -20,8 +32,12 @@ import (
 //   - Extracts identifier (API key, user, team) from request
 //   - Checks rate limits and budget limits
 //   - Sets rate limit headers (X-RateLimit-*, X-Budget-*)
-//   - Blocks or downgrades requests when limits exceeded
-//   - Records usage after request completes
+//   - Blocks or downgrades requests when limits exceeded, or with the queue
+//     action holds them until capacity frees up
+//   - Reconciles the tokens reserved for the request with the usage the
+//     handler reports with ReportUsage
+//   - Lets exempt identifiers and requests with a break-glass token
+//     (BreakGlassHeader) bypass limits, logging and auditing every bypass
 //
 // Example:
 //
@@ -43,6 +59,30 @@ func LimitsMiddleware(manager *limits.Manager) func(http.Handler) http.Handler {
 				return
 			}
 
+			// Let exempt requests bypass limits, recording the bypass in
+			// the audit log. Their usage is still recorded, so their
+			// spending shows up in budgets.
+			if bypass, ok := limitsBypass(manager, identifier, r); ok {
+				slog.WarnContext(ctx, "request bypassed limits",
+					"bypass", bypass.String(),
+					"method", r.Method,
+					"path", r.URL.Path,
+					"request_id", GetRequestID(ctx),
+				)
+				auditLimitsBypass(r, identifier, bypass)
+				ctx = limits.WithBypass(ctx, bypass)
+				report := &usageReport{}
+				next.ServeHTTP(w, r.WithContext(context.WithValue(ctx, usageReportKey, report)))
+				recordUsage(ctx, manager, identifier, nil, report)
+				return
+			}
+
+			// Queue requests by the priority class of their API key
+			if info, ok := auth.GetAPIKeyInfo(ctx); ok && info != nil && info.PriorityClass != "" {
+				ctx = limits.WithPriorityClass(ctx, info.PriorityClass)
+				r = r.WithContext(ctx)
+			}
+
 			// Get enriched request from context (set by earlier middleware)
 			enriched, ok := ctx.Value("enriched_request").(*enrichedRequestContext)
 			if !ok || enriched == nil {
@@ -51,6 +91,7 @@ func LimitsMiddleware(manager *limits.Manager) func(http.Handler) http.Handler {
 					estimatedTokens: 1000,    // Default estimate
 					estimatedCost:   0.01,    // Default cost
 					model:           "gpt-4", // Default model
+					stream:          isStreamingRequest(r),
 				}
 			}
 
@@ -67,6 +108,22 @@ func LimitsMiddleware(manager *limits.Manager) func(http.Handler) http.Handler {
 				return
 			}
 
+			// Hold queued requests until capacity frees up
+			if !result.Allowed && result.Action == limits.ActionQueue {
+				result, err = manager.WaitForCapacity(
+					ctx,
+					identifier,
+					enriched.estimatedTokens,
+					enriched.estimatedCost,
+					enriched.model,
+					result,
+				)
+				if err != nil {
+					http.Error(w, "Internal error checking limits", http.StatusInternalServerError)
+					return
+				}
+			}
+
 			// Set rate limit headers
 			setLimitHeaders(w, result)
 
@@ -76,32 +133,239 @@ func LimitsMiddleware(manager *limits.Manager) func(http.Handler) http.Handler {
 				return
 			}
 
+			// Pass budget warnings to the handler for system message
+			// injection
+			if warning := budgetWarning(result); warning != "" && manager.BudgetWarningMessage() {
+				ctx = context.WithValue(ctx, budgetWarningKey, warning)
+				r = r.WithContext(ctx)
+			}
+
 			// Handle downgrade action
 			if result.Action == limits.ActionDowngrade && result.DowngradeTo != "" {
-				// Store downgrade info in context for routing layer
+				// Store downgrade info in context for the handler, which
+				// rewrites the request with DowngradeModel
 				ctx = context.WithValue(ctx, DowngradedModelKey, result.DowngradeTo)
+				ctx = context.WithValue(ctx, limitsManagerKey, manager)
 				r = r.WithContext(ctx)
 			}
 
-			// Acquire concurrent slot if configured
-			if manager.AcquireConcurrent(identifier) {
-				defer manager.ReleaseConcurrent(identifier)
-
-				// Forward request
-				next.ServeHTTP(w, r)
-
-				// Record usage after request completes
-				// (This would ideally happen in response middleware with actual usage data)
-			} else {
+			// Acquire concurrency slots if configured, including those of
+			// the API key's own limits, waiting for them with the queue
+			// action
+			keyLimits := apiKeyConcurrencyLimits(ctx)
+			if err := manager.WaitForConcurrency(ctx, identifier, enriched.stream, keyLimits); err != nil {
 				// Concurrent limit exceeded
+				manager.ReleaseReservation(result.Reservation)
 				w.Header().Set("X-RateLimit-Limit", "concurrent")
-				http.Error(w, "Too many concurrent requests", http.StatusTooManyRequests)
+				message := "Too many concurrent requests"
+				if errors.Is(err, limits.ErrStreamLimitExceeded) {
+					message = "Too many concurrent streams"
+				}
+				http.Error(w, message, http.StatusTooManyRequests)
 				return
 			}
+			defer manager.ReleaseConcurrency(identifier, enriched.stream, keyLimits)
+
+			// Forward request, collecting the usage reported by the handler
+			report := &usageReport{}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), usageReportKey, report)))
+
+			// Settle the reserved tokens with the actual usage
+			recordUsage(ctx, manager, identifier, result.Reservation, report)
 		})
 	}
 }
 
+// BreakGlassHeader is the request header carrying a break-glass token,
+// which lets the request bypass rate limits and budgets.
+const BreakGlassHeader = "X-Mercator-Break-Glass"
+
+// limitsBypass returns the bypass of a request that is exempt from limits:
+// its identifier is exempt or it carries a valid break-glass token.
+func limitsBypass(manager *limits.Manager, identifier string, r *http.Request) (limits.Bypass, bool) {
+	if bypass, ok := manager.Exempt(identifier); ok {
+		return bypass, true
+	}
+	return manager.BreakGlass(r.Header.Get(BreakGlassHeader))
+}
+
+// auditLimitsBypass records a limits bypass in the audit event stream. API
+// keys are redacted from the identifier.
+func auditLimitsBypass(r *http.Request, identifier string, bypass limits.Bypass) {
+	if identifier == proxy.ExtractAPIKey(r) {
+		identifier = proxy.RedactAPIKey(identifier)
+	}
+	event := audit.Event{
+		Type:       audit.EventLimitBypass,
+		Outcome:    audit.OutcomeSuccess,
+		RequestID:  GetRequestID(r.Context()),
+		RemoteAddr: r.RemoteAddr,
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		Details: map[string]string{
+			"identifier": identifier,
+			"bypass":     bypass.Kind,
+			"name":       bypass.Name,
+		},
+	}
+	if info, ok := auth.GetAPIKeyInfo(r.Context()); ok && info != nil {
+		event.Principal = info.UserID
+		if event.Principal == "" {
+			event.Principal = info.TeamID
+		}
+	}
+	audit.Log(r.Context(), event)
+}
+
+// PreviewLimits reports whether LimitsMiddleware would admit r with the
+// estimated tokens and cost, without consuming any capacity. Requests
+// without an identifier and requests that bypass limits are admitted.
+func PreviewLimits(manager *limits.Manager, r *http.Request, estimatedTokens int, estimatedCost float64, model string) (*limits.LimitCheckResult, error) {
+	identifier := extractIdentifier(r)
+	if identifier == "" {
+		return &limits.LimitCheckResult{Allowed: true}, nil
+	}
+	if _, ok := limitsBypass(manager, identifier, r); ok {
+		return &limits.LimitCheckResult{Allowed: true}, nil
+	}
+	return manager.PreviewLimits(r.Context(), identifier, estimatedTokens, estimatedCost, model)
+}
+
+// DowngradedFromHeader is the response header naming the model a request
+// asked for when it was served with a cheaper model instead.
+const DowngradedFromHeader = "X-Mercator-Downgraded-From"
+
+// limitsManagerKey stores the limits.Manager of a downgraded request.
+const limitsManagerKey contextKey = "limits_manager"
+
+// DowngradeModel returns the model to serve a request for model with. If
+// LimitsMiddleware downgraded the request because a budget was exceeded,
+// it returns the cheaper model configured for model and true. Otherwise,
+// including when no cheaper model is configured for model, it returns
+// model and false.
+func DowngradeModel(ctx context.Context, model string) (string, bool) {
+	if _, ok := ctx.Value(DowngradedModelKey).(string); !ok {
+		return model, false
+	}
+	manager, ok := ctx.Value(limitsManagerKey).(*limits.Manager)
+	if !ok {
+		return model, false
+	}
+
+	downgraded, ok := manager.DowngradeModel(model)
+	if !ok || downgraded == model {
+		return model, false
+	}
+	return downgraded, true
+}
+
+// BudgetWarningHeader is the response header warning that a budget
+// crossed its alert threshold, e.g. "85% of daily budget used, $1.50
+// remaining".
+const BudgetWarningHeader = "X-Budget-Warning"
+
+// budgetWarningKey stores the budget warning to inject into a request.
+const budgetWarningKey contextKey = "budget_warning"
+
+// BudgetWarning returns the system message to inject into a request whose
+// budget crossed its alert threshold, if the limits manager enables
+// budget warning messages.
+func BudgetWarning(ctx context.Context) (string, bool) {
+	warning, ok := ctx.Value(budgetWarningKey).(string)
+	if !ok {
+		return "", false
+	}
+	return fmt.Sprintf("Budget warning: %s. Let the user know their budget is running low.", warning), true
+}
+
+// usageReportKey stores the usageReport of a request.
+const usageReportKey contextKey = "usage_report"
+
+// usageReport carries the actual usage of a request from the handler back
+// to LimitsMiddleware.
+type usageReport struct {
+	mu       sync.Mutex
+	reported bool
+	provider string
+	model    string
+	usage    providers.TokenUsage
+}
+
+// ReportUsage reports the provider's actual token usage for a request.
+// LimitsMiddleware reconciles the tokens it reserved for the request with
+// it once the handler returns. ReportUsage is a no-op for requests not
+// handled by LimitsMiddleware.
+func ReportUsage(ctx context.Context, provider, model string, usage providers.TokenUsage) {
+	report, ok := ctx.Value(usageReportKey).(*usageReport)
+	if !ok {
+		return
+	}
+
+	report.mu.Lock()
+	defer report.mu.Unlock()
+	report.reported = true
+	report.provider = provider
+	report.model = model
+	report.usage = usage
+}
+
+// recordUsage records the usage reported for a request. Requests without
+// reported usage, such as those that failed before reaching the provider,
+// release their reservation.
+func recordUsage(ctx context.Context, manager *limits.Manager, identifier string, reservation *limits.Reservation, report *usageReport) {
+	report.mu.Lock()
+	defer report.mu.Unlock()
+
+	if !report.reported {
+		manager.ReleaseReservation(reservation)
+		return
+	}
+
+	record := limits.NewUsageRecord(
+		limits.DimensionAPIKey,
+		identifier,
+		report.usage.PromptTokens,
+		report.usage.CompletionTokens,
+		0, // Costs are not known here
+		report.provider,
+		report.model,
+	)
+	record.CostTag = limits.CostTagFromContext(ctx)
+	record.Reservation = reservation
+	if err := manager.RecordUsage(ctx, record); err != nil {
+		manager.ReleaseReservation(reservation)
+	}
+}
+
+// apiKeyConcurrencyLimits returns the concurrency limits stored with the
+// request's API key in the key store, if it was authenticated with one.
+func apiKeyConcurrencyLimits(ctx context.Context) limits.ConcurrencyLimits {
+	info, ok := auth.GetAPIKeyInfo(ctx)
+	if !ok || info == nil {
+		return limits.ConcurrencyLimits{}
+	}
+	return limits.ConcurrencyLimits{
+		MaxConcurrent:        info.MaxConcurrent,
+		MaxConcurrentStreams: info.MaxConcurrentStreams,
+	}
+}
+
+// isStreamingRequest reports whether a request asks for a streamed
+// response ("stream": true in its JSON body).
+func isStreamingRequest(r *http.Request) bool {
+	if r.Method != http.MethodPost || r.Body == nil {
+		return false
+	}
+	body, err := peekBody(r)
+	if err != nil {
+		return false
+	}
+	var req struct {
+		Stream bool `json:"stream"`
+	}
+	return json.Unmarshal(body, &req) == nil && req.Stream
+}
+
 // extractIdentifier extracts the identifier from the request.
 // Priority: API key > User ID > Team ID
 func extractIdentifier(r *http.Request) string {
@@ -143,19 +407,58 @@ func setLimitHeaders(w http.ResponseWriter, result *limits.LimitCheckResult) {
 		w.Header().Set("X-Budget-Reset", fmt.Sprintf("%d", result.Budget.Reset.Unix()))
 	}
 
+	// Warn about budgets past their alert threshold
+	if warning := budgetWarning(result); warning != "" {
+		w.Header().Set(BudgetWarningHeader, warning)
+	}
+
 	// Set retry-after header if applicable
 	if result.RetryAfter > 0 {
-		w.Header().Set("Retry-After", fmt.Sprintf("%d", int(result.RetryAfter.Seconds())))
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfterSeconds(result.RetryAfter)))
+	}
+}
+
+// budgetWarning describes the budget of an allowed request that crossed
+// its alert threshold or limit, or returns "" if there is none.
+func budgetWarning(result *limits.LimitCheckResult) string {
+	if result.Action != limits.ActionAlert || result.Budget == nil {
+		return ""
+	}
+	if result.Budget.InGrace {
+		return fmt.Sprintf("%.0f%% of %s budget used, over the limit within the grace overage",
+			result.Budget.Percentage*100,
+			budget.WindowName(result.Budget.Window),
+		)
 	}
+	return fmt.Sprintf("%.0f%% of %s budget used, $%.2f remaining",
+		result.Budget.Percentage*100,
+		budget.WindowName(result.Budget.Window),
+		result.Budget.Remaining,
+	)
+}
+
+// retryAfterSeconds rounds a retry delay up to whole seconds, so clients
+// honoring Retry-After do not retry before the limit allows it.
+func retryAfterSeconds(retryAfter time.Duration) int {
+	return int(math.Ceil(retryAfter.Seconds()))
 }
 
 // handleLimitViolation handles a limit violation by returning appropriate error.
 func handleLimitViolation(w http.ResponseWriter, result *limits.LimitCheckResult) {
-	// Set headers
-	w.WriteHeader(http.StatusTooManyRequests)
+	code := types.CodeRateLimitExceeded
+	if result.Budget != nil && result.RateLimit == nil {
+		code = types.CodeBudgetExceeded
+	}
+
+	message := result.Reason
+	errResp := types.NewErrorResponse(message, types.ErrorTypeRateLimitExceeded, "", code)
+	if result.RetryAfter > 0 {
+		seconds := retryAfterSeconds(result.RetryAfter)
+		errResp.Error.Message = fmt.Sprintf("%s. Please try again in %s.", message, time.Duration(seconds)*time.Second)
+		errResp.Error.RetryAfter = seconds
+	}
 
-	// Write error response
-	fmt.Fprintf(w, `{"error": {"message": "%s", "type": "rate_limit_exceeded"}}`, result.Reason)
+	_ = proxy.WriteErrorResponse(w, errResp)
 }
 
 // enrichedRequestContext holds enriched request data for limit checking.
@@ -163,24 +466,58 @@ type enrichedRequestContext struct {
 	estimatedTokens int
 	estimatedCost   float64
 	model           string
+	stream          bool
 }
 
-// NewLimitsManagerFromConfig creates a limits manager from configuration.
-// This is a helper to initialize the manager with config-based limits.
-func NewLimitsManagerFromConfig(cfg *limitsConfig) (*limits.Manager, error) {
+// NewLimitsManagerFromConfig creates a limits manager from the limits
+// configuration and the API keys of security.authentication.keys. Rate
+// limits and budgets are only enforced when their section is enabled.
+func NewLimitsManagerFromConfig(limitsCfg *config.LimitsConfig, apiKeys []config.APIKeyConfig) (*limits.Manager, error) {
+	return newLimitsManager(&limitsConfig{LimitsConfig: *limitsCfg, APIKeys: apiKeys})
+}
+
+// newLimitsManager creates a limits manager from configuration.
+func newLimitsManager(cfg *limitsConfig) (*limits.Manager, error) {
 	// Convert config format to manager format
 	rateLimitsMap := make(map[string]ratelimit.Config)
 	budgetsMap := make(map[string]budget.Config)
+	modelRateLimitsMap := make(map[string]map[string]ratelimit.Config)
+	modelBudgetsMap := make(map[string]map[string]budget.Config)
+
+	if !cfg.RateLimits.Enabled {
+		cfg.RateLimits.ByAPIKey = nil
+	}
+	if !cfg.Budgets.Enabled {
+		cfg.Budgets.ByAPIKey = nil
+		cfg.Budgets.Hierarchy.Enabled = false
+	}
 
 	// Convert rate limits by API key
 	for identifier, limits := range cfg.RateLimits.ByAPIKey {
 		rateLimitsMap[identifier] = ratelimit.Config{
-			RequestsPerSecond: limits.RequestsPerSecond,
-			RequestsPerMinute: limits.RequestsPerMinute,
-			RequestsPerHour:   limits.RequestsPerHour,
-			TokensPerMinute:   limits.TokensPerMinute,
-			TokensPerHour:     limits.TokensPerHour,
-			MaxConcurrent:     limits.MaxConcurrent,
+			Algorithm:            limits.Algorithm,
+			BurstMultiplier:      limits.BurstMultiplier,
+			RequestsPerSecond:    limits.RequestsPerSecond,
+			RequestsPerMinute:    limits.RequestsPerMinute,
+			RequestsPerHour:      limits.RequestsPerHour,
+			TokensPerMinute:      limits.TokensPerMinute,
+			TokensPerHour:        limits.TokensPerHour,
+			MaxConcurrent:        limits.MaxConcurrent,
+			MaxConcurrentStreams: limits.MaxConcurrentStreams,
+		}
+		for model, modelLimits := range limits.Models {
+			if modelRateLimitsMap[identifier] == nil {
+				modelRateLimitsMap[identifier] = make(map[string]ratelimit.Config)
+			}
+			modelRateLimitsMap[identifier][model] = ratelimit.Config{
+				Algorithm:         modelLimits.Algorithm,
+				BurstMultiplier:   modelLimits.BurstMultiplier,
+				RequestsPerSecond: modelLimits.RequestsPerSecond,
+				RequestsPerMinute: modelLimits.RequestsPerMinute,
+				RequestsPerHour:   modelLimits.RequestsPerHour,
+				TokensPerMinute:   modelLimits.TokensPerMinute,
+				TokensPerHour:     modelLimits.TokensPerHour,
+			}
 		}
 	}
 
@@ -190,10 +527,32 @@ func NewLimitsManagerFromConfig(cfg *limitsConfig) (*limits.Manager, error) {
 			Hourly:         budgetLimits.Hourly,
 			Daily:          budgetLimits.Daily,
 			Monthly:        budgetLimits.Monthly,
-			AlertThreshold: cfg.Budgets.AlertThreshold,
+			AlertThreshold: alertThreshold(cfg, limits.DimensionAPIKey),
+			GraceOverage:   cfg.Budgets.GraceOverage,
+		}
+		for model, modelLimits := range budgetLimits.Models {
+			if modelBudgetsMap[identifier] == nil {
+				modelBudgetsMap[identifier] = make(map[string]budget.Config)
+			}
+			modelBudgetsMap[identifier][model] = budget.Config{
+				Hourly:         modelLimits.Hourly,
+				Daily:          modelLimits.Daily,
+				Monthly:        modelLimits.Monthly,
+				AlertThreshold: alertThreshold(cfg, limits.DimensionAPIKey),
+				GraceOverage:   cfg.Budgets.GraceOverage,
+			}
 		}
 	}
 
+	// Convert user, team and org budgets of the budget hierarchy
+	var (
+		scopeBudgets    map[limits.BudgetScope]budget.Config
+		budgetAncestors map[string][]limits.BudgetScope
+	)
+	if cfg.Budgets.Hierarchy.Enabled {
+		scopeBudgets, budgetAncestors = budgetHierarchy(cfg)
+	}
+
 	// Create storage backend
 	var storageBackend storage.Backend
 	switch cfg.Storage.Backend {
@@ -203,95 +562,260 @@ func NewLimitsManagerFromConfig(cfg *limitsConfig) (*limits.Manager, error) {
 			return nil, fmt.Errorf("failed to create SQLite backend: %w", err)
 		}
 		storageBackend = backend
+	case "postgres":
+		pg := cfg.Storage.Postgres
+		backend, err := storage.NewPostgresBackend(storage.PostgresBackendConfig{
+			Host:     pg.Host,
+			Port:     pg.Port,
+			Database: pg.Database,
+			User:     pg.User,
+			Password: pg.Password,
+			SSLMode:  pg.SSLMode,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create PostgreSQL backend: %w", err)
+		}
+		storageBackend = backend
+	case "redis":
+		redisCfg := cfg.Storage.Redis
+		client, err := ratelimit.NewRedisStore(&ratelimit.RedisConfig{
+			Address:     redisCfg.Address,
+			Username:    redisCfg.Username,
+			Password:    redisCfg.Password,
+			DB:          redisCfg.DB,
+			TLS:         redisCfg.TLS,
+			PoolSize:    redisCfg.PoolSize,
+			DialTimeout: redisCfg.DialTimeout,
+			OpTimeout:   redisCfg.OpTimeout,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Redis backend: %w", err)
+		}
+		backend, err := storage.NewRedisBackend(storage.RedisBackendConfig{
+			Client:    client,
+			KeyPrefix: redisCfg.KeyPrefix,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Redis backend: %w", err)
+		}
+		storageBackend = backend
 	case "memory":
 		storageBackend = storage.NewMemoryBackendWithConfig(storage.MemoryBackendConfig{
-			MaxEntries:      cfg.Storage.Memory.MaxEntries,
-			CleanupInterval: cfg.Storage.Memory.CleanupInterval,
+			MaxEntries:       cfg.Storage.Memory.MaxEntries,
+			CleanupInterval:  cfg.Storage.Memory.CleanupInterval,
+			SnapshotPath:     cfg.Storage.Memory.SnapshotPath,
+			SnapshotInterval: cfg.Storage.Memory.SnapshotInterval,
 		})
 	default:
 		storageBackend = storage.NewMemoryBackend()
 	}
 
+	// Create shared rate limit store
+	var rateLimitStore ratelimit.Store
+	if cfg.RateLimits.Backend == "redis" {
+		redisCfg := cfg.RateLimits.Redis
+		store, err := ratelimit.NewRedisStore(&ratelimit.RedisConfig{
+			Address:       redisCfg.Address,
+			Username:      redisCfg.Username,
+			Password:      redisCfg.Password,
+			DB:            redisCfg.DB,
+			TLS:           redisCfg.TLS,
+			KeyPrefix:     redisCfg.KeyPrefix,
+			PoolSize:      redisCfg.PoolSize,
+			DialTimeout:   redisCfg.DialTimeout,
+			OpTimeout:     redisCfg.OpTimeout,
+			FallbackRetry: redisCfg.FallbackRetry,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Redis rate limit store: %w", err)
+		}
+		rateLimitStore = store
+	}
+
+	// Create budget alert dispatcher
+	var alertNotifier limits.AlertNotifier
+	if len(cfg.Budgets.Alerts.Routes) > 0 {
+		dispatcher, err := newAlertDispatcher(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create budget alert dispatcher: %w", err)
+		}
+		alertNotifier = dispatcher
+	}
+
+	// Convert exemptions
+	exemptions := make([]limits.Exemption, 0, len(cfg.Exemptions.Identifiers))
+	for _, exemption := range cfg.Exemptions.Identifiers {
+		exemptions = append(exemptions, limits.Exemption{
+			Identifier: exemption.Identifier,
+			Name:       exemption.Name,
+		})
+	}
+	breakGlassTokens := make([]limits.BreakGlassToken, 0, len(cfg.Exemptions.BreakGlassTokens))
+	for _, token := range cfg.Exemptions.BreakGlassTokens {
+		breakGlassTokens = append(breakGlassTokens, limits.BreakGlassToken{
+			Name:      token.Name,
+			Token:     token.Token,
+			ExpiresAt: token.ExpiresAt,
+		})
+	}
+
 	// Create manager
 	manager := limits.NewManager(limits.Config{
-		RateLimits: rateLimitsMap,
-		Budgets:    budgetsMap,
+		RateLimits:           rateLimitsMap,
+		Budgets:              budgetsMap,
+		ModelRateLimits:      modelRateLimitsMap,
+		ModelBudgets:         modelBudgetsMap,
+		ScopeBudgets:         scopeBudgets,
+		BudgetAncestors:      budgetAncestors,
+		BudgetInheritance:    limits.BudgetInheritance(cfg.Budgets.Hierarchy.Inheritance),
+		BudgetWarningMessage: cfg.Budgets.WarningMessage,
+		AlertNotifier:        alertNotifier,
 		Enforcement: enforcement.Config{
 			DefaultAction:   enforcement.Action(cfg.Enforcement.Action),
 			QueueDepth:      cfg.Enforcement.QueueDepth,
 			QueueTimeout:    cfg.Enforcement.QueueTimeout,
+			QueuePriorities: queuePriorities(cfg),
+			PriorityTiers:   cfg.Enforcement.PriorityTiers,
 			ModelDowngrades: cfg.Enforcement.ModelDowngrades,
 		},
-		Storage: storageBackend,
+		Storage:          storageBackend,
+		RateLimitStore:   rateLimitStore,
+		SnapshotInterval: cfg.Storage.SnapshotInterval,
+		MaxStaleness:     cfg.Storage.MaxStaleness,
+		Exemptions:       exemptions,
+		BreakGlassTokens: breakGlassTokens,
 	})
 
 	return manager, nil
 }
 
-// limitsConfig mirrors the config package structure for limits.
-// This avoids circular dependencies.
-type limitsConfig struct {
-	Budgets struct {
-		Enabled        bool
-		AlertThreshold float64
-		ByAPIKey       map[string]struct {
-			Hourly  float64
-			Daily   float64
-			Monthly float64
-		}
-		ByUser map[string]struct {
-			Hourly  float64
-			Daily   float64
-			Monthly float64
+// queuePriorities returns the queue priorities of API keys: the priority
+// of their priority class if they have one, and their queue_priorities
+// entry otherwise.
+func queuePriorities(cfg *limitsConfig) map[string]int {
+	priorities := make(map[string]int, len(cfg.Enforcement.QueuePriorities))
+	for identifier, priority := range cfg.Enforcement.QueuePriorities {
+		priorities[identifier] = priority
+	}
+	for _, key := range cfg.APIKeys {
+		if priority, ok := cfg.Enforcement.PriorityTiers[key.PriorityClass]; ok && key.PriorityClass != "" {
+			priorities[key.Key] = priority
 		}
-		ByTeam map[string]struct {
-			Hourly  float64
-			Daily   float64
-			Monthly float64
+	}
+	return priorities
+}
+
+// newAlertDispatcher creates the dispatcher of budget alerts to the
+// configured channels.
+func newAlertDispatcher(cfg *limitsConfig) (*alerting.Dispatcher, error) {
+	alerts := &cfg.Budgets.Alerts
+
+	var channels []alerting.Channel
+	for _, c := range alerts.Channels {
+		switch c.Type {
+		case "slack":
+			channels = append(channels, alerting.NewSlackChannel(c.Name, c.URL))
+		case "pagerduty":
+			channels = append(channels, alerting.NewPagerDutyChannel(c.Name, &alerting.PagerDutyConfig{
+				RoutingKey: c.RoutingKey,
+				URL:        c.URL,
+			}))
+		case "email":
+			channels = append(channels, alerting.NewEmailChannel(c.Name, &alerting.EmailConfig{
+				Host:     c.SMTP.Host,
+				Port:     c.SMTP.Port,
+				Username: c.SMTP.Username,
+				Password: c.SMTP.Password,
+				From:     c.SMTP.From,
+				To:       c.To,
+			}))
+		case "webhook":
+			channels = append(channels, alerting.NewWebhookChannel(c.Name, &alerting.WebhookConfig{
+				URL:     c.URL,
+				Headers: c.Headers,
+				Secret:  c.Secret,
+			}))
+		default:
+			return nil, fmt.Errorf("unsupported alert channel type %q", c.Type)
 		}
 	}
-	RateLimits struct {
-		Enabled  bool
-		ByAPIKey map[string]struct {
-			RequestsPerSecond int
-			RequestsPerMinute int
-			RequestsPerHour   int
-			TokensPerMinute   int
-			TokensPerHour     int
-			MaxConcurrent     int
+
+	routes := make([]alerting.Route, 0, len(alerts.Routes))
+	for _, r := range alerts.Routes {
+		route := alerting.Route{Identifiers: r.Identifiers, Channels: r.Channels}
+		for _, dimension := range r.Dimensions {
+			route.Dimensions = append(route.Dimensions, limits.Dimension(dimension))
 		}
-		ByUser map[string]struct {
-			RequestsPerSecond int
-			RequestsPerMinute int
-			RequestsPerHour   int
-			TokensPerMinute   int
-			TokensPerHour     int
-			MaxConcurrent     int
+		for _, level := range r.Levels {
+			route.Levels = append(route.Levels, limits.AlertLevel(level))
 		}
-		ByTeam map[string]struct {
-			RequestsPerSecond int
-			RequestsPerMinute int
-			RequestsPerHour   int
-			TokensPerMinute   int
-			TokensPerHour     int
-			MaxConcurrent     int
+		routes = append(routes, route)
+	}
+
+	return alerting.NewDispatcher(alerting.Config{
+		Routes:        routes,
+		DedupInterval: alerts.DedupInterval,
+	}, channels...)
+}
+
+// alertThreshold returns the budget alert threshold of a hierarchy level.
+func alertThreshold(cfg *limitsConfig, level limits.Dimension) float64 {
+	if threshold, ok := cfg.Budgets.Hierarchy.AlertThresholds[string(level)]; ok {
+		return threshold
+	}
+	return cfg.Budgets.AlertThreshold
+}
+
+// budgetHierarchy converts user, team and org budgets to scoped budgets,
+// and places each API key below the scopes of its user, team and org that
+// have a budget.
+func budgetHierarchy(cfg *limitsConfig) (map[limits.BudgetScope]budget.Config, map[string][]limits.BudgetScope) {
+	scopeBudgets := make(map[limits.BudgetScope]budget.Config)
+	levels := []struct {
+		dimension limits.Dimension
+		budgets   map[string]config.BudgetLimits
+	}{
+		{limits.DimensionUser, cfg.Budgets.ByUser},
+		{limits.DimensionTeam, cfg.Budgets.ByTeam},
+		{limits.DimensionOrg, cfg.Budgets.ByOrg},
+	}
+	for _, level := range levels {
+		for identifier, budgetLimits := range level.budgets {
+			scopeBudgets[limits.BudgetScope{Dimension: level.dimension, Identifier: identifier}] = budget.Config{
+				Hourly:         budgetLimits.Hourly,
+				Daily:          budgetLimits.Daily,
+				Monthly:        budgetLimits.Monthly,
+				AlertThreshold: alertThreshold(cfg, level.dimension),
+				GraceOverage:   cfg.Budgets.GraceOverage,
+			}
 		}
 	}
-	Enforcement struct {
-		Action          string
-		QueueDepth      int
-		QueueTimeout    time.Duration
-		ModelDowngrades map[string]string
-	}
-	Storage struct {
-		Backend string
-		SQLite  struct {
-			Path             string
-			SnapshotInterval time.Duration
+
+	budgetAncestors := make(map[string][]limits.BudgetScope)
+	for _, key := range cfg.APIKeys {
+		candidates := []limits.BudgetScope{
+			{Dimension: limits.DimensionUser, Identifier: key.UserID},
+			{Dimension: limits.DimensionTeam, Identifier: key.TeamID},
+			{Dimension: limits.DimensionOrg, Identifier: cfg.Budgets.Hierarchy.TeamOrgs[key.TeamID]},
+		}
+		var ancestors []limits.BudgetScope
+		for _, scope := range candidates {
+			if _, ok := scopeBudgets[scope]; ok {
+				ancestors = append(ancestors, scope)
+			}
 		}
-		Memory struct {
-			MaxEntries      int
-			CleanupInterval time.Duration
+		if len(ancestors) > 0 {
+			budgetAncestors[key.Key] = ancestors
 		}
 	}
+
+	return scopeBudgets, budgetAncestors
+}
+
+// limitsConfig is the limits configuration together with the API keys of
+// security.authentication.keys, which place each API key below its user and
+// team in the budget hierarchy and assign its priority class.
+type limitsConfig struct {
+	config.LimitsConfig
+	APIKeys []config.APIKeyConfig
 }
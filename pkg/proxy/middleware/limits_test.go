@@ -1,16 +1,25 @@
 package middleware
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"mercator-hq/jupiter/pkg/config"
 	"mercator-hq/jupiter/pkg/limits"
 	"mercator-hq/jupiter/pkg/limits/budget"
+	"mercator-hq/jupiter/pkg/limits/enforcement"
 	"mercator-hq/jupiter/pkg/limits/ratelimit"
+	"mercator-hq/jupiter/pkg/providers"
+	"mercator-hq/jupiter/pkg/proxy/types"
+	"mercator-hq/jupiter/pkg/security/auth"
+	"mercator-hq/jupiter/pkg/telemetry/audit"
 )
 
 // Test-specific context key
@@ -111,6 +120,126 @@ func TestLimitsMiddleware_RateLimitExceeded(t *testing.T) {
 	}
 }
 
+// TestLimitsMiddleware_Exemptions tests that exempt identifiers and
+// break-glass tokens bypass exhausted limits.
+func TestLimitsMiddleware_Exemptions(t *testing.T) {
+	token := strings.Repeat("x", 32)
+	manager := limits.NewManager(limits.Config{
+		RateLimits: map[string]ratelimit.Config{
+			"health-key": {RequestsPerMinute: 1},
+			"test-key":   {RequestsPerMinute: 1},
+		},
+		Exemptions: []limits.Exemption{{Identifier: "health-key", Name: "health-check"}},
+		BreakGlassTokens: []limits.BreakGlassToken{
+			{Name: "oncall", Token: token},
+			{Name: "expired", Token: strings.Repeat("y", 32), ExpiresAt: time.Now().Add(-time.Hour)},
+		},
+	})
+	defer manager.Close()
+
+	var bypass limits.Bypass
+	var bypassed bool
+	handler := LimitsMiddleware(manager)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bypass, bypassed = limits.BypassFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	tests := []struct {
+		name       string
+		key        string
+		breakGlass string
+		wantStatus int
+		wantBypass string
+	}{
+		{name: "exempt", key: "health-key", wantStatus: http.StatusOK, wantBypass: "exemption:health-check"},
+		{name: "exempt again", key: "health-key", wantStatus: http.StatusOK, wantBypass: "exemption:health-check"},
+		{name: "limited", key: "test-key", wantStatus: http.StatusOK},
+		{name: "limit exhausted", key: "test-key", wantStatus: http.StatusTooManyRequests},
+		{name: "break glass", key: "test-key", breakGlass: token, wantStatus: http.StatusOK, wantBypass: "break_glass:oncall"},
+		{name: "expired break glass", key: "test-key", breakGlass: strings.Repeat("y", 32), wantStatus: http.StatusTooManyRequests},
+		{name: "unknown break glass", key: "test-key", breakGlass: "wrong", wantStatus: http.StatusTooManyRequests},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bypass, bypassed = limits.Bypass{}, false
+			req := httptest.NewRequest("GET", "/test", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.key)
+			if tt.breakGlass != "" {
+				req.Header.Set(BreakGlassHeader, tt.breakGlass)
+			}
+			w := httptest.NewRecorder()
+
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+			if tt.wantBypass == "" {
+				if bypassed {
+					t.Errorf("Expected no bypass, got %q", bypass)
+				}
+			} else if !bypassed || bypass.String() != tt.wantBypass {
+				t.Errorf("Expected bypass %q, got %q", tt.wantBypass, bypass)
+			}
+		})
+	}
+}
+
+// TestLimitsMiddleware_AuditBypass tests that bypasses are recorded in the
+// audit log without the API key.
+func TestLimitsMiddleware_AuditBypass(t *testing.T) {
+	var buf bytes.Buffer
+	audit.SetDefault(audit.NewWithWriters(&buf))
+	t.Cleanup(func() { audit.SetDefault(nil) })
+
+	token := strings.Repeat("x", 32)
+	manager := limits.NewManager(limits.Config{
+		Exemptions:       []limits.Exemption{{Identifier: "sk-health-check-key", Name: "health-check"}},
+		BreakGlassTokens: []limits.BreakGlassToken{{Name: "oncall", Token: token}},
+	})
+	defer manager.Close()
+
+	handler := RequestIDMiddleware(LimitsMiddleware(manager)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+
+	for _, breakGlass := range []string{"", token} {
+		req := httptest.NewRequest("POST", "/v1/chat/completions", nil)
+		req.Header.Set("Authorization", "Bearer sk-health-check-key")
+		if breakGlass != "" {
+			req.Header.Set("Authorization", "Bearer sk-regular-api-key")
+			req.Header.Set(BreakGlassHeader, breakGlass)
+		}
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 audit events, got %q", buf.String())
+	}
+	if strings.Contains(buf.String(), "sk-health-check-key") || strings.Contains(buf.String(), token) {
+		t.Errorf("Expected credentials to be redacted, got %q", buf.String())
+	}
+
+	want := []struct{ identifier, bypass, name string }{
+		{"sk-heal...-key", limits.BypassExemption, "health-check"},
+		{"sk-regu...-key", limits.BypassBreakGlass, "oncall"},
+	}
+	for i, line := range lines {
+		var event audit.Event
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			t.Fatalf("Invalid audit event: %v", err)
+		}
+		if event.Type != audit.EventLimitBypass || event.RequestID == "" || event.Path != "/v1/chat/completions" {
+			t.Errorf("Unexpected event %+v", event)
+		}
+		if event.Details["identifier"] != want[i].identifier || event.Details["bypass"] != want[i].bypass || event.Details["name"] != want[i].name {
+			t.Errorf("Expected details %+v, got %v", want[i], event.Details)
+		}
+	}
+}
+
 // TestLimitsMiddleware_BudgetExceeded tests that budget violations are blocked.
 func TestLimitsMiddleware_BudgetExceeded(t *testing.T) {
 	t.Skip("Budget limits require actual usage recording - tested in integration tests")
@@ -182,6 +311,85 @@ func TestLimitsMiddleware_BudgetHeaders(t *testing.T) {
 	t.Logf("X-Budget-Used: %s", w.Header().Get("X-Budget-Used"))
 }
 
+// TestLimitsMiddleware_BudgetWarning tests warnings for budgets past their alert threshold.
+func TestLimitsMiddleware_BudgetWarning(t *testing.T) {
+	for _, warningMessage := range []bool{false, true} {
+		manager := limits.NewManager(limits.Config{
+			Budgets: map[string]budget.Config{
+				"test-key": {Daily: 10.00, AlertThreshold: 0.8},
+			},
+			BudgetWarningMessage: warningMessage,
+		})
+		defer manager.Close()
+
+		_ = manager.RecordUsage(context.Background(), &limits.UsageRecord{
+			Identifier: "test-key",
+			Dimension:  limits.DimensionAPIKey,
+			Cost:       8.50,
+		})
+
+		var message string
+		var injected bool
+		handler := LimitsMiddleware(manager)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			message, injected = BudgetWarning(r.Context())
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		req := httptest.NewRequest("POST", "/test", nil)
+		req.Header.Set("Authorization", "Bearer test-key")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200, got %d", w.Code)
+		}
+		want := "85% of daily budget used, $1.50 remaining"
+		if got := w.Header().Get(BudgetWarningHeader); got != want {
+			t.Errorf("%s = %q, want %q", BudgetWarningHeader, got, want)
+		}
+		if injected != warningMessage {
+			t.Errorf("BudgetWarning injected = %v, want %v", injected, warningMessage)
+		}
+		if injected && !strings.Contains(message, want) {
+			t.Errorf("BudgetWarning = %q, want it to contain %q", message, want)
+		}
+	}
+}
+
+// TestLimitsMiddleware_BudgetGrace tests that requests over a budget but
+// within its grace overage are allowed with a warning.
+func TestLimitsMiddleware_BudgetGrace(t *testing.T) {
+	manager := limits.NewManager(limits.Config{
+		Budgets: map[string]budget.Config{
+			"test-key": {Daily: 10.00, AlertThreshold: 0.8, GraceOverage: 0.1},
+		},
+	})
+	defer manager.Close()
+
+	_ = manager.RecordUsage(context.Background(), &limits.UsageRecord{
+		Identifier: "test-key",
+		Dimension:  limits.DimensionAPIKey,
+		Cost:       10.50,
+	})
+
+	handler := LimitsMiddleware(manager)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("POST", "/test", nil)
+	req.Header.Set("Authorization", "Bearer test-key")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 within the grace overage, got %d", w.Code)
+	}
+	want := "105% of daily budget used, over the limit within the grace overage"
+	if got := w.Header().Get(BudgetWarningHeader); got != want {
+		t.Errorf("%s = %q, want %q", BudgetWarningHeader, got, want)
+	}
+}
+
 // TestLimitsMiddleware_ConcurrentLimit tests concurrent request limiting.
 func TestLimitsMiddleware_ConcurrentLimit(t *testing.T) {
 	manager := limits.NewManager(limits.Config{
@@ -225,9 +433,111 @@ func TestLimitsMiddleware_ConcurrentLimit(t *testing.T) {
 	manager.ReleaseConcurrent("test-key")
 }
 
+// TestLimitsMiddleware_APIKeyConcurrencyLimits tests the concurrency
+// limits stored with an API key in the key store.
+func TestLimitsMiddleware_APIKeyConcurrencyLimits(t *testing.T) {
+	manager := limits.NewManager(limits.Config{})
+	defer manager.Close()
+
+	validator := auth.NewAPIKeyValidator([]*auth.APIKeyInfo{
+		{Key: "test-key", Enabled: true, MaxConcurrent: 2, MaxConcurrentStreams: 1},
+	})
+	sources := []auth.APIKeySource{{Type: "header", Name: "Authorization", Scheme: "Bearer"}}
+
+	// The handler holds requests in flight until released
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	handler := auth.NewAPIKeyMiddleware(validator, sources).Handle(
+		LimitsMiddleware(manager)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			entered <- struct{}{}
+			<-release
+			w.WriteHeader(http.StatusOK)
+		})),
+	)
+
+	send := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-key")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		send(`{"model":"gpt-4","stream":true}`)
+	}()
+	<-entered
+
+	// A second stream exceeds the key's stream limit
+	if w := send(`{"model":"gpt-4","stream":true}`); w.Code != http.StatusTooManyRequests || !strings.Contains(w.Body.String(), "Too many concurrent streams") {
+		t.Errorf("Expected stream limit rejection, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// A non-streaming request takes the key's last concurrent slot
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		send(`{"model":"gpt-4"}`)
+	}()
+	<-entered
+
+	if w := send(`{"model":"gpt-4"}`); w.Code != http.StatusTooManyRequests || !strings.Contains(w.Body.String(), "Too many concurrent requests") {
+		t.Errorf("Expected concurrent limit rejection, got %d: %s", w.Code, w.Body.String())
+	}
+
+	close(release)
+	wg.Wait()
+}
+
 // TestLimitsMiddleware_ModelDowngrade tests model downgrade action.
 func TestLimitsMiddleware_ModelDowngrade(t *testing.T) {
-	t.Skip("Model downgrade requires specific enforcement configuration - tested in integration tests")
+	manager := limits.NewManager(limits.Config{
+		Budgets: map[string]budget.Config{
+			"test-key": {Daily: 1.00},
+		},
+		Enforcement: enforcement.Config{
+			DefaultAction: enforcement.ActionDowngrade,
+			ModelDowngrades: map[string]string{
+				"gpt-4":    "gpt-3.5-turbo",
+				"claude-3": "claude-3-haiku",
+			},
+		},
+	})
+	defer manager.Close()
+
+	_ = manager.RecordUsage(context.Background(), &limits.UsageRecord{
+		Identifier: "test-key",
+		Dimension:  limits.DimensionAPIKey,
+		Cost:       2.00,
+	})
+
+	var served string
+	var downgraded bool
+	handler := LimitsMiddleware(manager)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The handler's model need not be the one estimated by the middleware
+		served, downgraded = DowngradeModel(r.Context(), "claude-3")
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	req := httptest.NewRequest("POST", "/test", nil)
+	req.Header.Set("Authorization", "Bearer test-key")
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", w.Code)
+	}
+	if !downgraded || served != "claude-3-haiku" {
+		t.Errorf("DowngradeModel = %q, %v; want claude-3-haiku, true", served, downgraded)
+	}
+
+	// Requests not downgraded keep their model
+	if model, ok := DowngradeModel(context.Background(), "gpt-4"); ok || model != "gpt-4" {
+		t.Errorf("DowngradeModel without downgrade = %q, %v; want gpt-4, false", model, ok)
+	}
 }
 
 // TestLimitsMiddleware_RetryAfterHeader tests Retry-After header is set.
@@ -303,6 +613,52 @@ func TestLimitsMiddleware_EnrichedContext(t *testing.T) {
 	}
 }
 
+// TestLimitsMiddleware_ReconcilesReportedUsage tests that reserved tokens
+// are settled with the usage reported by the handler.
+func TestLimitsMiddleware_ReconcilesReportedUsage(t *testing.T) {
+	manager := limits.NewManager(limits.Config{
+		RateLimits: map[string]ratelimit.Config{
+			"test-key": {
+				TokensPerMinute: 2500,
+			},
+		},
+	})
+	defer manager.Close()
+
+	var usage *providers.TokenUsage
+	middleware := LimitsMiddleware(manager)
+	handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if usage != nil {
+			ReportUsage(r.Context(), "openai", "gpt-4", *usage)
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	serve := func() int {
+		req := httptest.NewRequest("GET", "/test", nil)
+		req.Header.Set("Authorization", "Bearer test-key")
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	// Requests without reported usage release their 1000 estimated tokens
+	for i := 0; i < 3; i++ {
+		if code := serve(); code != http.StatusOK {
+			t.Fatalf("Request %d: Expected status 200, got %d", i, code)
+		}
+	}
+
+	// Reported usage replaces the estimate
+	usage = &providers.TokenUsage{PromptTokens: 1500, CompletionTokens: 500, TotalTokens: 2000}
+	if code := serve(); code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", code)
+	}
+	if code := serve(); code != http.StatusTooManyRequests {
+		t.Errorf("Expected the reported 2000 tokens to exhaust the limit, got status %d", code)
+	}
+}
+
 // TestLimitsMiddleware_DefaultsWithoutEnrichedContext tests fallback to defaults.
 func TestLimitsMiddleware_DefaultsWithoutEnrichedContext(t *testing.T) {
 	manager := limits.NewManager(limits.Config{
@@ -446,3 +802,114 @@ func TestHandleLimitViolation(t *testing.T) {
 		t.Errorf("Expected error type 'rate_limit_exceeded', got: %s", body)
 	}
 }
+
+// TestHandleLimitViolation_RetryAfter tests that the retry delay is
+// included in the error body, rounded up to whole seconds.
+func TestHandleLimitViolation_RetryAfter(t *testing.T) {
+	w := httptest.NewRecorder()
+
+	result := &limits.LimitCheckResult{
+		Allowed:    false,
+		Reason:     "tokens per minute limit exceeded",
+		RateLimit:  &limits.RateLimitInfo{Limit: 1000},
+		RetryAfter: 41500 * time.Millisecond,
+	}
+
+	setLimitHeaders(w, result)
+	handleLimitViolation(w, result)
+
+	if got := w.Header().Get("Retry-After"); got != "42" {
+		t.Errorf("Expected Retry-After '42', got %q", got)
+	}
+
+	var errResp types.ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &errResp); err != nil {
+		t.Fatalf("Failed to decode error body: %v", err)
+	}
+	if errResp.Error.RetryAfter != 42 {
+		t.Errorf("Expected retry_after 42, got %d", errResp.Error.RetryAfter)
+	}
+	if errResp.Error.Message != "tokens per minute limit exceeded. Please try again in 42s." {
+		t.Errorf("Unexpected message: %q", errResp.Error.Message)
+	}
+	if errResp.Error.Code != types.CodeRateLimitExceeded {
+		t.Errorf("Expected code %q, got %q", types.CodeRateLimitExceeded, errResp.Error.Code)
+	}
+}
+
+// TestBudgetHierarchy tests that API keys are placed below the budgets of
+// their user, team and organization.
+func TestBudgetHierarchy(t *testing.T) {
+	cfg := &limitsConfig{}
+	cfg.Budgets.AlertThreshold = 0.8
+	cfg.Budgets.ByTeam = map[string]config.BudgetLimits{"platform": {Daily: 100}}
+	cfg.Budgets.ByOrg = map[string]config.BudgetLimits{"acme": {Monthly: 5000}}
+	cfg.Budgets.Hierarchy.TeamOrgs = map[string]string{"platform": "acme"}
+	cfg.Budgets.Hierarchy.AlertThresholds = map[string]float64{"org": 0.95}
+	cfg.APIKeys = []config.APIKeyConfig{
+		{Key: "key-alice", UserID: "alice", TeamID: "platform"},
+		{Key: "key-bob", UserID: "bob"},
+	}
+
+	scopeBudgets, ancestors := budgetHierarchy(cfg)
+
+	team := limits.BudgetScope{Dimension: limits.DimensionTeam, Identifier: "platform"}
+	org := limits.BudgetScope{Dimension: limits.DimensionOrg, Identifier: "acme"}
+	if got := ancestors["key-alice"]; len(got) != 2 || got[0] != team || got[1] != org {
+		t.Errorf("Expected key-alice below team and org, got %v", got)
+	}
+	if got, ok := ancestors["key-bob"]; ok {
+		t.Errorf("Expected no ancestors with budgets for key-bob, got %v", got)
+	}
+	if threshold := scopeBudgets[team].AlertThreshold; threshold != 0.8 {
+		t.Errorf("Expected team alert threshold 0.8, got %v", threshold)
+	}
+	if threshold := scopeBudgets[org].AlertThreshold; threshold != 0.95 {
+		t.Errorf("Expected org alert threshold 0.95, got %v", threshold)
+	}
+}
+
+// TestQueuePriorities tests the queue priorities of API keys with a
+// priority class.
+func TestQueuePriorities(t *testing.T) {
+	cfg := &limitsConfig{}
+	cfg.Enforcement.QueuePriorities = map[string]int{"key-batch": 1, "key-prod": 1}
+	cfg.Enforcement.PriorityTiers = map[string]int{"prod": 100}
+	cfg.APIKeys = []config.APIKeyConfig{
+		{Key: "key-prod", PriorityClass: "prod"},
+		{Key: "key-new", PriorityClass: "prod"},
+		{Key: "key-batch"},
+	}
+
+	priorities := queuePriorities(cfg)
+	want := map[string]int{"key-prod": 100, "key-new": 100, "key-batch": 1}
+	for key, priority := range want {
+		if priorities[key] != priority {
+			t.Errorf("priority of %s = %d, want %d", key, priorities[key], priority)
+		}
+	}
+}
+
+func TestNewLimitsManagerFromConfig_DisabledSections(t *testing.T) {
+	cfg := &config.LimitsConfig{}
+	cfg.RateLimits.Enabled = true
+	cfg.RateLimits.ByAPIKey = map[string]config.RateLimits{"key-a": {TokensPerMinute: 1000}}
+	cfg.Budgets.ByAPIKey = map[string]config.BudgetLimits{"key-a": {Daily: 10}}
+
+	manager, err := NewLimitsManagerFromConfig(cfg, nil)
+	if err != nil {
+		t.Fatalf("NewLimitsManagerFromConfig failed: %v", err)
+	}
+	defer manager.Close()
+
+	usages := manager.Usage(limits.DimensionAPIKey, "key-a")
+	if len(usages) != 1 {
+		t.Fatalf("Expected usage of key-a, got %+v", usages)
+	}
+	if usages[0].RateLimits == nil {
+		t.Error("Expected rate limits of the enabled section")
+	}
+	if len(usages[0].Budgets) != 0 {
+		t.Errorf("Expected no budgets while budgets are disabled, got %+v", usages[0].Budgets)
+	}
+}
@@ -136,16 +136,27 @@ func WriteErrorResponse(w http.ResponseWriter, errResp *types.ErrorResponse) err
 //
 //	data: {"id":"chatcmpl-123","object":"chat.completion.chunk",...}
 //
-// Followed by two newlines (\n\n).
+// Followed by two newlines (\n\n). The event is encoded in a pooled buffer
+// and written at once.
 func WriteSSEChunk(w http.ResponseWriter, chunk *types.ChatCompletionStreamChunk) error {
-	// Marshal chunk to JSON
-	data, err := json.Marshal(chunk)
-	if err != nil {
+	buf := providers.GetBuffer()
+	defer providers.PutBuffer(buf)
+
+	// Encode chunk as "data: <json>\n\n"; the encoder ends the JSON with
+	// the first newline
+	buf.WriteString("data: ")
+	if err := json.NewEncoder(buf).Encode(chunk); err != nil {
 		return fmt.Errorf("failed to marshal SSE chunk: %w", err)
 	}
+	buf.WriteByte('\n')
 
-	// Write SSE formatted chunk: "data: <json>\n\n"
-	if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+	return WriteSSEEvent(w, buf.Bytes())
+}
+
+// WriteSSEEvent writes an event already in Server-Sent Events format, such
+// as a chunk forwarded from a provider, and flushes it.
+func WriteSSEEvent(w http.ResponseWriter, event []byte) error {
+	if _, err := w.Write(event); err != nil {
 		return fmt.Errorf("failed to write SSE chunk: %w", err)
 	}
 
@@ -24,6 +24,10 @@ type ErrorDetail struct {
 
 	// Code is a machine-readable error code.
 	Code string `json:"code,omitempty"`
+
+	// RetryAfter is the number of seconds to wait before retrying, for
+	// rate limit errors. It matches the Retry-After header.
+	RetryAfter int `json:"retry_after,omitempty"`
 }
 
 // Error type constants matching OpenAI API specification.
@@ -84,6 +88,12 @@ const (
 
 	// CodeInternalError indicates an internal server error.
 	CodeInternalError = "internal_error"
+
+	// CodeRateLimitExceeded indicates a rate limit was exceeded.
+	CodeRateLimitExceeded = "rate_limit_exceeded"
+
+	// CodeBudgetExceeded indicates a budget was exceeded.
+	CodeBudgetExceeded = "budget_exceeded"
 )
 
 // NewErrorResponse creates a new error response with the given details.
@@ -42,6 +42,16 @@ type ChatCompletionRequest struct {
 	// Optional, defaults to 0.0.
 	FrequencyPenalty *float64 `json:"frequency_penalty,omitempty"`
 
+	// TopK limits sampling to the K most likely tokens (an extension of
+	// open model servers such as TGI and vLLM).
+	// Optional, ignored by providers that do not support it.
+	TopK *int `json:"top_k,omitempty"`
+
+	// RepetitionPenalty penalizes tokens that already appear in the text
+	// (an extension of open model servers such as TGI and vLLM); 1.0 is no penalty.
+	// Optional, ignored by providers that do not support it.
+	RepetitionPenalty *float64 `json:"repetition_penalty,omitempty"`
+
 	// User is a unique identifier for the end-user making the request.
 	// Used for abuse detection and tracking. Optional.
 	User string `json:"user,omitempty"`
@@ -62,6 +72,10 @@ type ChatCompletionRequest struct {
 	// Seed enables deterministic sampling (OpenAI beta feature).
 	// Optional, not supported by all providers.
 	Seed *int `json:"seed,omitempty"`
+
+	// Metadata is a set of key-value pairs attached to the request, such as
+	// its cost allocation tag. It is not forwarded to providers. Optional.
+	Metadata map[string]string `json:"metadata,omitempty"`
 }
 
 // Message represents a single message in a conversation.
@@ -204,6 +218,22 @@ func (r *ChatCompletionRequest) Validate() error {
 		}
 	}
 
+	// Validate top_k
+	if r.TopK != nil && *r.TopK < 1 {
+		return &ValidationError{
+			Field:   "top_k",
+			Message: "top_k must be greater than 0",
+		}
+	}
+
+	// Validate repetition_penalty
+	if r.RepetitionPenalty != nil && *r.RepetitionPenalty <= 0 {
+		return &ValidationError{
+			Field:   "repetition_penalty",
+			Message: "repetition_penalty must be greater than 0",
+		}
+	}
+
 	// Validate messages have required fields
 	for i, msg := range r.Messages {
 		if msg.Role == "" {
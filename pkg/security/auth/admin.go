@@ -0,0 +1,161 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	securityTLS "mercator-hq/jupiter/pkg/security/tls"
+	"mercator-hq/jupiter/pkg/telemetry/audit"
+)
+
+// AdminKey is a key that grants access to administrative endpoints.
+type AdminKey struct {
+	// Name identifies the key holder (e.g., "alice@example.com"). It is
+	// recorded as the principal of every admin request made with the key.
+	Name string
+
+	// Key is the secret key value.
+	Key string
+}
+
+// AdminAuthenticator authenticates requests to administrative endpoints.
+//
+// Requests present an admin key as "Authorization: Bearer <key>" or in the
+// X-Admin-Key header. Admin keys are separate from proxy API keys: an API
+// key never grants admin access.
+//
+// An authenticator created with NewAdminCertAuthenticator authenticates
+// requests by their verified client certificate instead.
+type AdminAuthenticator struct {
+	keys []adminKeyDigest
+
+	// clientCert authenticates requests by client certificate, with the
+	// identity extracted from identitySource as the principal
+	clientCert     bool
+	identitySource string
+}
+
+// adminKeyDigest holds the SHA-256 digest of an admin key, so keys are
+// compared in constant time regardless of their length.
+type adminKeyDigest struct {
+	name   string
+	digest [sha256.Size]byte
+}
+
+// NewAdminAuthenticator creates an authenticator for the given keys.
+func NewAdminAuthenticator(keys []AdminKey) (*AdminAuthenticator, error) {
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("at least one admin key is required")
+	}
+
+	a := &AdminAuthenticator{}
+	for _, key := range keys {
+		if key.Name == "" || key.Key == "" {
+			return nil, fmt.Errorf("admin keys require a name and a key")
+		}
+		a.keys = append(a.keys, adminKeyDigest{
+			name:   key.Name,
+			digest: sha256.Sum256([]byte(key.Key)),
+		})
+	}
+	return a, nil
+}
+
+// NewAdminCertAuthenticator creates an authenticator for client
+// certificates verified by the TLS listener. The certificate identity,
+// extracted from identitySource (see tls.ExtractClientIdentity), is the
+// admin principal. Certificates that were not verified against the client
+// CA are rejected.
+func NewAdminCertAuthenticator(identitySource string) *AdminAuthenticator {
+	return &AdminAuthenticator{clientCert: true, identitySource: identitySource}
+}
+
+// Authenticate returns the admin principal of the request: the name of the
+// admin key presented, or the identity of the client certificate.
+func (a *AdminAuthenticator) Authenticate(r *http.Request) (string, bool) {
+	if a.clientCert {
+		if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+			return "", false
+		}
+		identity := securityTLS.ExtractClientIdentity(r.TLS.VerifiedChains[0][0], a.identitySource)
+		return identity, identity != ""
+	}
+
+	presented := r.Header.Get("X-Admin-Key")
+	if presented == "" {
+		presented = strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	}
+	if presented == "" {
+		return "", false
+	}
+
+	digest := sha256.Sum256([]byte(presented))
+	name, found := "", 0
+	for _, key := range a.keys {
+		if subtle.ConstantTimeCompare(digest[:], key.digest[:]) == 1 {
+			name, found = key.name, 1
+		}
+	}
+	return name, found == 1
+}
+
+// Handle wraps an administrative handler. Unauthenticated requests are
+// rejected with 401; authenticated requests carry the key name as their
+// admin principal.
+func (a *AdminAuthenticator) Handle(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, ok := a.Authenticate(r)
+		if !ok {
+			slog.Warn("unauthenticated admin request",
+				"remote_addr", r.RemoteAddr,
+				"method", r.Method,
+				"path", r.URL.Path,
+			)
+			if a.clientCert {
+				auditAuthFailure(r, "missing or unverified client certificate")
+			} else {
+				auditAuthFailure(r, "missing or invalid admin key")
+				w.Header().Set("WWW-Authenticate", `Bearer realm="mercator-admin"`)
+			}
+			http.Error(w, "admin authentication required", http.StatusUnauthorized)
+			return
+		}
+
+		slog.Info("admin request",
+			"principal", principal,
+			"remote_addr", r.RemoteAddr,
+			"method", r.Method,
+			"path", r.URL.Path,
+		)
+		audit.Log(r.Context(), audit.Event{
+			Type:       audit.EventAdminAction,
+			Principal:  principal,
+			RemoteAddr: r.RemoteAddr,
+			Method:     r.Method,
+			Path:       r.URL.Path,
+		})
+		next.ServeHTTP(w, r.WithContext(WithAdminPrincipal(r.Context(), principal)))
+	})
+}
+
+// adminPrincipalKey is the context key for the admin principal.
+const adminPrincipalKey contextKey = "admin_principal"
+
+// WithAdminPrincipal returns a context carrying the authenticated admin
+// principal.
+func WithAdminPrincipal(ctx context.Context, principal string) context.Context {
+	return context.WithValue(ctx, adminPrincipalKey, principal)
+}
+
+// AdminPrincipal returns the authenticated admin principal of a request
+// context. Admin handlers use it, never the request body, to record who
+// performed an action.
+func AdminPrincipal(ctx context.Context) (string, bool) {
+	principal, ok := ctx.Value(adminPrincipalKey).(string)
+	return principal, ok && principal != ""
+}
@@ -0,0 +1,144 @@
+package auth
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mercator-hq/jupiter/pkg/telemetry/audit"
+)
+
+func TestAdminAuthenticator_Handle(t *testing.T) {
+	authenticator, err := NewAdminAuthenticator([]AdminKey{
+		{Name: "alice@example.com", Key: "admin-key-alice"},
+		{Name: "bob@example.com", Key: "admin-key-bob"},
+	})
+	if err != nil {
+		t.Fatalf("NewAdminAuthenticator failed: %v", err)
+	}
+
+	var principal string
+	handler := authenticator.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, _ = AdminPrincipal(r.Context())
+	}))
+
+	tests := []struct {
+		name          string
+		header        string
+		value         string
+		wantStatus    int
+		wantPrincipal string
+	}{
+		{"bearer token", "Authorization", "Bearer admin-key-bob", http.StatusOK, "bob@example.com"},
+		{"admin key header", "X-Admin-Key", "admin-key-alice", http.StatusOK, "alice@example.com"},
+		{"wrong key", "Authorization", "Bearer admin-key-mallory", http.StatusUnauthorized, ""},
+		{"no key", "", "", http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal = ""
+			req := httptest.NewRequest(http.MethodGet, "/admin/evidence/records", nil)
+			if tt.header != "" {
+				req.Header.Set(tt.header, tt.value)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if principal != tt.wantPrincipal {
+				t.Errorf("Expected principal %q, got %q", tt.wantPrincipal, principal)
+			}
+		})
+	}
+}
+
+func TestAdminAuthenticator_Audit(t *testing.T) {
+	var buf bytes.Buffer
+	audit.SetDefault(audit.NewWithWriters(&buf))
+	defer audit.SetDefault(nil)
+
+	authenticator, err := NewAdminAuthenticator([]AdminKey{{Name: "alice@example.com", Key: "admin-key-alice"}})
+	if err != nil {
+		t.Fatalf("NewAdminAuthenticator failed: %v", err)
+	}
+	handler := authenticator.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for _, key := range []string{"admin-key-alice", "admin-key-mallory"} {
+		req := httptest.NewRequest(http.MethodDelete, "/admin/evidence/holds/legal-1", nil)
+		req.Header.Set("X-Admin-Key", key)
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 audit events, got %q", buf.String())
+	}
+	if !strings.Contains(lines[0], `"type":"admin_action"`) || !strings.Contains(lines[0], `"principal":"alice@example.com"`) {
+		t.Errorf("unexpected admin action event %s", lines[0])
+	}
+	if !strings.Contains(lines[1], `"type":"auth_failure"`) || !strings.Contains(lines[1], `"outcome":"failure"`) {
+		t.Errorf("unexpected auth failure event %s", lines[1])
+	}
+}
+
+func TestNewAdminAuthenticator_RequiresKeys(t *testing.T) {
+	if _, err := NewAdminAuthenticator(nil); err == nil {
+		t.Error("Expected error for no keys")
+	}
+	if _, err := NewAdminAuthenticator([]AdminKey{{Name: "alice"}}); err == nil {
+		t.Error("Expected error for key without value")
+	}
+}
+
+func TestAdminCertAuthenticator(t *testing.T) {
+	authenticator := NewAdminCertAuthenticator("subject.CN")
+
+	var principal string
+	handler := authenticator.Handle(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, _ = AdminPrincipal(r.Context())
+	}))
+
+	cert := &x509.Certificate{Subject: pkix.Name{CommonName: "oncall.example.com"}}
+	tests := []struct {
+		name          string
+		state         *tls.ConnectionState
+		wantStatus    int
+		wantPrincipal string
+	}{
+		{"verified certificate", &tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{cert},
+			VerifiedChains:   [][]*x509.Certificate{{cert}},
+		}, http.StatusOK, "oncall.example.com"},
+		{"unverified certificate", &tls.ConnectionState{
+			PeerCertificates: []*x509.Certificate{cert},
+		}, http.StatusUnauthorized, ""},
+		{"no TLS", nil, http.StatusUnauthorized, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			principal = ""
+			req := httptest.NewRequest(http.MethodGet, "/admin/evidence/records", nil)
+			req.TLS = tt.state
+			// Admin keys are not accepted in client certificate mode
+			req.Header.Set("X-Admin-Key", "admin-key-alice")
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d", tt.wantStatus, rec.Code)
+			}
+			if principal != tt.wantPrincipal {
+				t.Errorf("Expected principal %q, got %q", tt.wantPrincipal, principal)
+			}
+		})
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"log/slog"
 	"net/http"
 	"strings"
+
+	"mercator-hq/jupiter/pkg/telemetry/audit"
 )
 
 // APIKeySource defines where to extract API keys from
@@ -40,6 +42,7 @@ func (m *APIKeyMiddleware) Handle(next http.Handler) http.Handler {
 				"remote_addr", r.RemoteAddr,
 				"path", r.URL.Path,
 			)
+			auditAuthFailure(r, "missing API key")
 			http.Error(w, "Missing or invalid API key", http.StatusUnauthorized)
 			return
 		}
@@ -52,6 +55,7 @@ func (m *APIKeyMiddleware) Handle(next http.Handler) http.Handler {
 				"remote_addr", r.RemoteAddr,
 				"path", r.URL.Path,
 			)
+			auditAuthFailure(r, "invalid API key")
 			http.Error(w, "Invalid API key", http.StatusUnauthorized)
 			return
 		}
@@ -64,8 +68,19 @@ func (m *APIKeyMiddleware) Handle(next http.Handler) http.Handler {
 		)
 
 		// Add key info to request context
-		ctx := context.WithValue(r.Context(), apiKeyInfoKey, keyInfo)
-		next.ServeHTTP(w, r.WithContext(ctx))
+		next.ServeHTTP(w, r.WithContext(WithAPIKeyInfo(r.Context(), keyInfo)))
+	})
+}
+
+// auditAuthFailure records a rejected request in the audit event stream.
+func auditAuthFailure(r *http.Request, reason string) {
+	audit.Log(r.Context(), audit.Event{
+		Type:       audit.EventAuthFailure,
+		Outcome:    audit.OutcomeFailure,
+		RemoteAddr: r.RemoteAddr,
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		Reason:     reason,
 	})
 }
 
@@ -104,6 +119,12 @@ type contextKey string
 // #nosec G101 - This is a context key constant, not a credential
 const apiKeyInfoKey contextKey = "api_key_info"
 
+// WithAPIKeyInfo returns a context carrying the info of the authenticated
+// API key.
+func WithAPIKeyInfo(ctx context.Context, info *APIKeyInfo) context.Context {
+	return context.WithValue(ctx, apiKeyInfoKey, info)
+}
+
 // GetAPIKeyInfo retrieves API key info from request context
 func GetAPIKeyInfo(ctx context.Context) (*APIKeyInfo, bool) {
 	info, ok := ctx.Value(apiKeyInfoKey).(*APIKeyInfo)
@@ -10,6 +10,16 @@ type APIKeyInfo struct {
 	Enabled   bool
 	RateLimit string
 	CreatedAt time.Time
+
+	// MaxConcurrent limits the key's simultaneous in-flight requests, and
+	// MaxConcurrentStreams its simultaneous streaming requests. 0 means no
+	// limit.
+	MaxConcurrent        int
+	MaxConcurrentStreams int
+
+	// PriorityClass is the key's priority tier, e.g. "prod" or "batch",
+	// which orders its queued requests under contention.
+	PriorityClass string
 }
 
 // APIKeyStore stores and validates API keys
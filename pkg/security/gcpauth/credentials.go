@@ -0,0 +1,415 @@
+package gcpauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Scope is the OAuth scope tokens are requested with.
+	Scope = "https://www.googleapis.com/auth/cloud-platform"
+
+	// refreshWindow is how long before they expire tokens are refreshed.
+	refreshWindow = 5 * time.Minute
+
+	// defaultTokenURI is the Google OAuth token endpoint, used when a
+	// credentials file does not name one.
+	defaultTokenURI = "https://oauth2.googleapis.com/token"
+
+	// defaultMetadataEndpoint is the metadata server endpoint.
+	defaultMetadataEndpoint = "http://metadata.google.internal"
+
+	// metadataTimeout bounds each metadata server request, so that token
+	// requests do not stall outside Google Cloud.
+	metadataTimeout = 3 * time.Second
+
+	// assertionLifetime is the lifetime of service account JWT assertions.
+	assertionLifetime = time.Hour
+)
+
+// Credential file types
+const (
+	typeServiceAccount = "service_account"
+	typeAuthorizedUser = "authorized_user"
+)
+
+// errNotConfigured is returned by credential sources that do not apply to
+// the environment, so that the next one is tried.
+var errNotConfigured = errors.New("not configured")
+
+// credentialsFile is the JSON credentials file of a service account key or
+// of gcloud user credentials.
+type credentialsFile struct {
+	Type string `json:"type"`
+
+	// Service account keys
+	ProjectID    string `json:"project_id"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	ClientEmail  string `json:"client_email"`
+	TokenURI     string `json:"token_uri"`
+
+	// User credentials
+	ClientID       string `json:"client_id"`
+	ClientSecret   string `json:"client_secret"`
+	RefreshToken   string `json:"refresh_token"`
+	QuotaProjectID string `json:"quota_project_id"`
+}
+
+// Credentials supplies access tokens from Application Default Credentials.
+// It is safe for concurrent use.
+type Credentials struct {
+	client *http.Client
+	file   string
+
+	// metadataEndpoint is the metadata server endpoint, replaced in tests
+	metadataEndpoint string
+
+	mu        sync.Mutex
+	token     string
+	expires   time.Time
+	projectID string
+	source    string
+}
+
+// NewCredentials creates credentials reading the credentials file at file,
+// or from the default sources if file is "". Token endpoints are requested
+// with client, or http.DefaultClient if nil.
+func NewCredentials(client *http.Client, file string) *Credentials {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	endpoint := defaultMetadataEndpoint
+	if host := os.Getenv("GCE_METADATA_HOST"); host != "" {
+		endpoint = "http://" + host
+	}
+	return &Credentials{
+		client:           client,
+		file:             file,
+		metadataEndpoint: endpoint,
+	}
+}
+
+// Source returns the source of the cached token, e.g. "service account" or
+// "metadata server", or "" before the first token is requested.
+func (c *Credentials) Source() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.source
+}
+
+// Token returns the cached access token, or requests a new one if it
+// expires soon. An expiring token is kept if it cannot be refreshed.
+func (c *Credentials) Token(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.token != "" && time.Until(c.expires) > refreshWindow {
+		return c.token, nil
+	}
+
+	token, expires, err := c.fetch(ctx)
+	if err != nil {
+		if c.token != "" && time.Now().Before(c.expires) {
+			return c.token, nil
+		}
+		return "", fmt.Errorf("gcpauth: %w", err)
+	}
+	c.token = token
+	c.expires = expires
+	return token, nil
+}
+
+// ProjectID returns the project of the credentials: the project of a
+// service account key, the quota project of user credentials, or the
+// project of the metadata server.
+func (c *Credentials) ProjectID(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.projectID != "" {
+		return c.projectID, nil
+	}
+
+	file, err := c.readFile()
+	if err != nil && !errors.Is(err, errNotConfigured) {
+		return "", fmt.Errorf("gcpauth: %w", err)
+	}
+	if file != nil {
+		c.projectID = file.ProjectID
+		if c.projectID == "" {
+			c.projectID = file.QuotaProjectID
+		}
+	} else {
+		body, err := c.metadata(ctx, "/computeMetadata/v1/project/project-id")
+		if err != nil {
+			return "", fmt.Errorf("gcpauth: failed to read project from metadata server: %w", err)
+		}
+		c.projectID = strings.TrimSpace(string(body))
+	}
+	if c.projectID == "" {
+		return "", fmt.Errorf("gcpauth: credentials do not name a project")
+	}
+	return c.projectID, nil
+}
+
+// fetch requests a new access token from the first source that applies.
+func (c *Credentials) fetch(ctx context.Context) (string, time.Time, error) {
+	file, err := c.readFile()
+	if err != nil && !errors.Is(err, errNotConfigured) {
+		return "", time.Time{}, err
+	}
+	if file == nil {
+		c.source = "metadata server"
+		return c.fromMetadata(ctx)
+	}
+
+	switch file.Type {
+	case typeServiceAccount:
+		c.source = "service account"
+		return c.fromServiceAccount(ctx, file)
+	case typeAuthorizedUser:
+		c.source = "user credentials"
+		return c.fromAuthorizedUser(ctx, file)
+	default:
+		return "", time.Time{}, fmt.Errorf("unsupported credentials type %q (supported: %s, %s)", file.Type, typeServiceAccount, typeAuthorizedUser)
+	}
+}
+
+// readFile reads the credentials file: the configured file, the file of
+// GOOGLE_APPLICATION_CREDENTIALS, or the gcloud application default
+// credentials if they exist.
+func (c *Credentials) readFile() (*credentialsFile, error) {
+	path := c.file
+	if path == "" {
+		path = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	if path == "" {
+		path = wellKnownFile()
+		if path == "" {
+			return nil, errNotConfigured
+		}
+		if _, err := os.Stat(path); err != nil {
+			return nil, errNotConfigured
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read credentials file: %w", err)
+	}
+	var file credentialsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials file %s: %w", path, err)
+	}
+	return &file, nil
+}
+
+// wellKnownFile returns the path of the gcloud application default
+// credentials file.
+func wellKnownFile() string {
+	if dir := os.Getenv("CLOUDSDK_CONFIG"); dir != "" {
+		return filepath.Join(dir, "application_default_credentials.json")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gcloud", "application_default_credentials.json")
+}
+
+// tokenResponse is the response of the token endpoints.
+type tokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// fromServiceAccount exchanges a JWT assertion signed with a service
+// account key for an access token.
+func (c *Credentials) fromServiceAccount(ctx context.Context, file *credentialsFile) (string, time.Time, error) {
+	tokenURI := file.TokenURI
+	if tokenURI == "" {
+		tokenURI = defaultTokenURI
+	}
+	assertion, err := signAssertion(file, tokenURI, time.Now())
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return c.exchange(ctx, tokenURI, url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	})
+}
+
+// fromAuthorizedUser exchanges the refresh token of user credentials for an
+// access token.
+func (c *Credentials) fromAuthorizedUser(ctx context.Context, file *credentialsFile) (string, time.Time, error) {
+	if file.RefreshToken == "" || file.ClientID == "" {
+		return "", time.Time{}, fmt.Errorf("user credentials are missing client_id or refresh_token")
+	}
+	tokenURI := file.TokenURI
+	if tokenURI == "" {
+		tokenURI = defaultTokenURI
+	}
+	return c.exchange(ctx, tokenURI, url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {file.ClientID},
+		"client_secret": {file.ClientSecret},
+		"refresh_token": {file.RefreshToken},
+	})
+}
+
+// exchange posts a token request to an OAuth token endpoint.
+func (c *Credentials) exchange(ctx context.Context, tokenURI string, form url.Values) (string, time.Time, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	var token tokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to decode token response (status %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", time.Time{}, fmt.Errorf("token request returned status %d: %s: %s", resp.StatusCode, token.Error, token.ErrorDescription)
+	}
+	return validToken(token)
+}
+
+// fromMetadata requests the token of the default service account from the
+// metadata server.
+func (c *Credentials) fromMetadata(ctx context.Context) (string, time.Time, error) {
+	body, err := c.metadata(ctx, "/computeMetadata/v1/instance/service-accounts/default/token?scopes="+url.QueryEscape(Scope))
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("no Google credentials found (metadata server: %w)", err)
+	}
+	var token tokenResponse
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to decode metadata token: %w", err)
+	}
+	return validToken(token)
+}
+
+// metadata returns the body of a successful metadata server response.
+func (c *Credentials) metadata(ctx context.Context, path string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, metadataTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.metadataEndpoint+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %d", req.URL.Redacted(), resp.StatusCode)
+	}
+	return body, nil
+}
+
+// validToken returns the token of a token response and its expiry time.
+func validToken(token tokenResponse) (string, time.Time, error) {
+	if token.AccessToken == "" {
+		return "", time.Time{}, fmt.Errorf("token response has no access token")
+	}
+	return token.AccessToken, time.Now().Add(time.Duration(token.ExpiresIn) * time.Second), nil
+}
+
+// signAssertion returns a JWT assertion for the service account of file,
+// signed with RS256.
+func signAssertion(file *credentialsFile, audience string, now time.Time) (string, error) {
+	if file.ClientEmail == "" || file.PrivateKey == "" {
+		return "", fmt.Errorf("service account key is missing client_email or private_key")
+	}
+	key, err := parsePrivateKey([]byte(file.PrivateKey))
+	if err != nil {
+		return "", err
+	}
+
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+		"kid": file.PrivateKeyID,
+	})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iss":   file.ClientEmail,
+		"scope": Scope,
+		"aud":   audience,
+		"iat":   now.Unix(),
+		"exp":   now.Add(assertionLifetime).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign assertion: %w", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parsePrivateKey parses a PEM encoded PKCS #8 or PKCS #1 RSA private key.
+func parsePrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("service account private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS8PrivateKey(block.Bytes); err == nil {
+		rsaKey, ok := key.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("service account private key is not an RSA key")
+		}
+		return rsaKey, nil
+	}
+	key, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse service account private key: %w", err)
+	}
+	return key, nil
+}
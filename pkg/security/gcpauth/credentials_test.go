@@ -0,0 +1,261 @@
+package gcpauth
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// isolateEnv clears the Google environment variables and points the
+// default credentials file at nothing.
+func isolateEnv(t *testing.T) {
+	t.Helper()
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+	t.Setenv("GCE_METADATA_HOST", "")
+	t.Setenv("CLOUDSDK_CONFIG", t.TempDir())
+}
+
+// writeFile writes a JSON credentials file and returns its path.
+func writeFile(t *testing.T, file map[string]string) string {
+	t.Helper()
+	data, err := json.Marshal(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestCredentials_ServiceAccount(t *testing.T) {
+	isolateEnv(t)
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		if r.Form.Get("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+			http.Error(w, `{"error":"unsupported_grant_type"}`, http.StatusBadRequest)
+			return
+		}
+
+		// Verify the assertion
+		parts := strings.Split(r.Form.Get("assertion"), ".")
+		if len(parts) != 3 {
+			t.Fatalf("assertion has %d parts, want 3", len(parts))
+		}
+		signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if err := rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature); err != nil {
+			t.Errorf("assertion signature: %v", err)
+		}
+		payload, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		var claims map[string]interface{}
+		if err := json.Unmarshal(payload, &claims); err != nil {
+			t.Fatal(err)
+		}
+		if claims["iss"] != "mercator@project.iam.gserviceaccount.com" || claims["scope"] != Scope || claims["aud"] != "http://"+r.Host+"/token" {
+			t.Errorf("assertion claims = %v", claims)
+		}
+
+		fmt.Fprint(w, `{"access_token":"sa-token","expires_in":3600,"token_type":"Bearer"}`)
+	}))
+	defer server.Close()
+
+	path := writeFile(t, map[string]string{
+		"type":           "service_account",
+		"project_id":     "project",
+		"private_key_id": "key-id",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+		"client_email":   "mercator@project.iam.gserviceaccount.com",
+		"token_uri":      server.URL + "/token",
+	})
+
+	credentials := NewCredentials(nil, path)
+	for i := 0; i < 2; i++ {
+		token, err := credentials.Token(context.Background())
+		if err != nil {
+			t.Fatalf("Token() error = %v", err)
+		}
+		if token != "sa-token" {
+			t.Errorf("Token() = %q, want sa-token", token)
+		}
+	}
+	if requests.Load() != 1 {
+		t.Errorf("token endpoint requested %d times, want 1 (cached)", requests.Load())
+	}
+	if credentials.Source() != "service account" {
+		t.Errorf("Source() = %q, want service account", credentials.Source())
+	}
+	if project, err := credentials.ProjectID(context.Background()); err != nil || project != "project" {
+		t.Errorf("ProjectID() = %q, %v, want project", project, err)
+	}
+}
+
+func TestCredentials_AuthorizedUser(t *testing.T) {
+	isolateEnv(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		if r.Form.Get("grant_type") != "refresh_token" || r.Form.Get("refresh_token") != "refresh" {
+			http.Error(w, `{"error":"invalid_grant","error_description":"Bad Request"}`, http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, `{"access_token":"user-token","expires_in":3599}`)
+	}))
+	defer server.Close()
+
+	// The gcloud application default credentials file
+	dir := t.TempDir()
+	t.Setenv("CLOUDSDK_CONFIG", dir)
+	data := fmt.Sprintf(`{"type":"authorized_user","client_id":"id","client_secret":"secret","refresh_token":"refresh","quota_project_id":"quota","token_uri":%q}`, server.URL)
+	if err := os.WriteFile(filepath.Join(dir, "application_default_credentials.json"), []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	credentials := NewCredentials(nil, "")
+	token, err := credentials.Token(context.Background())
+	if err != nil || token != "user-token" {
+		t.Errorf("Token() = %q, %v, want user-token", token, err)
+	}
+	if project, err := credentials.ProjectID(context.Background()); err != nil || project != "quota" {
+		t.Errorf("ProjectID() = %q, %v, want the quota project", project, err)
+	}
+
+	// A rejected refresh token
+	path := writeFile(t, map[string]string{
+		"type":          "authorized_user",
+		"client_id":     "id",
+		"refresh_token": "revoked",
+		"token_uri":     server.URL,
+	})
+	_, err = NewCredentials(nil, path).Token(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "invalid_grant") {
+		t.Errorf("Token() error = %v, want invalid_grant", err)
+	}
+}
+
+func TestCredentials_Metadata(t *testing.T) {
+	isolateEnv(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			http.Error(w, "missing Metadata-Flavor", http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/computeMetadata/v1/instance/service-accounts/default/token":
+			if r.URL.Query().Get("scopes") != Scope {
+				t.Errorf("scopes = %q, want %q", r.URL.Query().Get("scopes"), Scope)
+			}
+			fmt.Fprint(w, `{"access_token":"gce-token","expires_in":3599,"token_type":"Bearer"}`)
+		case "/computeMetadata/v1/project/project-id":
+			fmt.Fprint(w, "gce-project")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	t.Setenv("GCE_METADATA_HOST", strings.TrimPrefix(server.URL, "http://"))
+
+	credentials := NewCredentials(nil, "")
+	token, err := credentials.Token(context.Background())
+	if err != nil || token != "gce-token" || credentials.Source() != "metadata server" {
+		t.Errorf("Token() = %q, %v from %q, want the metadata server token", token, err, credentials.Source())
+	}
+	if project, err := credentials.ProjectID(context.Background()); err != nil || project != "gce-project" {
+		t.Errorf("ProjectID() = %q, %v, want gce-project", project, err)
+	}
+}
+
+func TestCredentials_KeepsExpiringToken(t *testing.T) {
+	isolateEnv(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	t.Setenv("GCE_METADATA_HOST", strings.TrimPrefix(server.URL, "http://"))
+
+	credentials := NewCredentials(nil, "")
+	credentials.token = "expiring"
+	credentials.expires = time.Now().Add(time.Minute)
+	if token, err := credentials.Token(context.Background()); err != nil || token != "expiring" {
+		t.Errorf("Token() = %q, %v, want the expiring token", token, err)
+	}
+
+	credentials.expires = time.Now().Add(-time.Minute)
+	if _, err := credentials.Token(context.Background()); err == nil {
+		t.Error("Token() with an expired token and no source succeeded")
+	}
+}
+
+func TestCredentials_InvalidFile(t *testing.T) {
+	isolateEnv(t)
+	tests := []struct {
+		name string
+		file map[string]string
+	}{
+		{
+			name: "unsupported type",
+			file: map[string]string{"type": "external_account"},
+		},
+		{
+			name: "missing key",
+			file: map[string]string{"type": "service_account", "client_email": "sa@example.com"},
+		},
+		{
+			name: "malformed key",
+			file: map[string]string{"type": "service_account", "client_email": "sa@example.com", "private_key": "not a key"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewCredentials(nil, writeFile(t, tt.file)).Token(context.Background()); err == nil {
+				t.Error("Token() succeeded")
+			}
+		})
+	}
+}
+
+func TestParsePrivateKey_PKCS1(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	parsed, err := parsePrivateKey(data)
+	if err != nil {
+		t.Fatalf("parsePrivateKey() error = %v", err)
+	}
+	if !parsed.Equal(key) {
+		t.Error("parsePrivateKey() returned a different key")
+	}
+}
@@ -0,0 +1,29 @@
+// Package gcpauth obtains OAuth 2.0 access tokens for Google Cloud APIs.
+//
+// It is a small, dependency-free implementation of Application Default
+// Credentials for the Google APIs Mercator talks to directly (Vertex AI for
+// model access):
+//
+//	credentials := gcpauth.NewCredentials(nil, "")
+//	token, err := credentials.Token(ctx)
+//	if err != nil {
+//	    return err
+//	}
+//	req.Header.Set("Authorization", "Bearer "+token)
+//
+// # Credential Sources
+//
+// Credentials are read from the first source that applies:
+//
+//   - the configured credentials file, or the file named by the
+//     GOOGLE_APPLICATION_CREDENTIALS environment variable
+//   - the gcloud application default credentials file
+//     (~/.config/gcloud/application_default_credentials.json)
+//   - the metadata server of GCE, GKE (including workload identity), and
+//     Cloud Run
+//
+// Credentials files hold either a service account key, exchanged for access
+// tokens with a signed JWT assertion, or gcloud user credentials, exchanged
+// with their refresh token. Tokens are requested with the cloud-platform
+// scope, cached, and refreshed shortly before they expire.
+package gcpauth
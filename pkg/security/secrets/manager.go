@@ -2,10 +2,13 @@ package secrets
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"regexp"
 	"strings"
+
+	"mercator-hq/jupiter/pkg/telemetry/audit"
 )
 
 var (
@@ -43,6 +46,7 @@ func (m *Manager) GetSecret(ctx context.Context, name string) (string, error) {
 	// Check cache first
 	if value, ok := m.cache.Get(name); ok {
 		slog.Debug("secret cache hit", "name", redactSecretName(name))
+		auditSecretAccess(ctx, name, "cache", nil)
 		return value, nil
 	}
 
@@ -78,17 +82,37 @@ func (m *Manager) GetSecret(ctx context.Context, name string) (string, error) {
 			"provider", provider.Provider(),
 			"name", redactSecretName(name),
 		)
+		auditSecretAccess(ctx, name, provider.Provider(), nil)
 
 		return value, nil
 	}
 
 	if lastErr != nil {
+		auditSecretAccess(ctx, name, "", lastErr)
 		return "", fmt.Errorf("failed to get secret %q: %w", name, lastErr)
 	}
 
+	auditSecretAccess(ctx, name, "", errors.New("no provider supports this secret"))
 	return "", fmt.Errorf("secret not found: %q (no provider supports this secret)", name)
 }
 
+// auditSecretAccess records an access to a secret in the audit event
+// stream. source is the provider or cache the secret was read from.
+func auditSecretAccess(ctx context.Context, name, source string, err error) {
+	event := audit.Event{
+		Type:    audit.EventSecretAccess,
+		Details: map[string]string{"secret": name},
+	}
+	if source != "" {
+		event.Details["source"] = source
+	}
+	if err != nil {
+		event.Outcome = audit.OutcomeFailure
+		event.Reason = err.Error()
+	}
+	audit.Log(ctx, event)
+}
+
 // ResolveReferences replaces ${secret:name} patterns with actual secret values.
 //
 // This is used to resolve secret references in configuration files.
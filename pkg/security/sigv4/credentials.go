@@ -0,0 +1,439 @@
+package sigv4
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// refreshWindow is how long before they expire temporary credentials
+	// are refreshed.
+	refreshWindow = 5 * time.Minute
+
+	// defaultIMDSEndpoint is the EC2 instance metadata service endpoint.
+	defaultIMDSEndpoint = "http://169.254.169.254"
+
+	// defaultContainerEndpoint is the ECS container credentials endpoint
+	// used with relative URIs.
+	defaultContainerEndpoint = "http://169.254.170.2"
+
+	// imdsTimeout bounds each instance metadata request, so that the chain
+	// does not stall outside EC2.
+	imdsTimeout = time.Second
+)
+
+// errNotConfigured is returned by credential sources that do not apply to
+// the environment, so that the chain moves on to the next one.
+var errNotConfigured = errors.New("not configured")
+
+// CredentialsProvider supplies the credentials requests are signed with.
+type CredentialsProvider interface {
+	// Retrieve returns valid credentials, refreshing temporary
+	// credentials before they expire.
+	Retrieve(ctx context.Context) (Credentials, error)
+}
+
+// StaticCredentials returns a provider of fixed credentials.
+func StaticCredentials(credentials Credentials) CredentialsProvider {
+	return staticProvider(credentials)
+}
+
+type staticProvider Credentials
+
+func (p staticProvider) Retrieve(ctx context.Context) (Credentials, error) {
+	if !Credentials(p).Valid() {
+		return Credentials{}, fmt.Errorf("sigv4: missing AWS credentials")
+	}
+	return Credentials(p), nil
+}
+
+// Chain resolves credentials from the standard AWS credential sources, in
+// order:
+//   - the AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+//     environment variables
+//   - web identity federation (AWS_WEB_IDENTITY_TOKEN_FILE and
+//     AWS_ROLE_ARN, as set for EKS service accounts), through STS
+//   - the shared credentials file (AWS_SHARED_CREDENTIALS_FILE, or
+//     ~/.aws/credentials) profile named by AWS_PROFILE, or "default"
+//   - ECS container credentials (AWS_CONTAINER_CREDENTIALS_RELATIVE_URI or
+//     AWS_CONTAINER_CREDENTIALS_FULL_URI)
+//   - EC2 instance profile credentials, through IMDSv2, unless
+//     AWS_EC2_METADATA_DISABLED is "true"
+//
+// The credentials found are cached, and resolved again shortly before
+// they expire.
+type Chain struct {
+	client *http.Client
+
+	// Endpoints of the credential services, replaced in tests
+	stsEndpoint       string
+	containerEndpoint string
+	imdsEndpoint      string
+
+	mu          sync.Mutex
+	credentials Credentials
+	source      string
+}
+
+// NewChain creates a credential chain. Credential services are requested
+// with client, or http.DefaultClient if nil.
+func NewChain(client *http.Client) *Chain {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Chain{
+		client:            client,
+		containerEndpoint: defaultContainerEndpoint,
+		imdsEndpoint:      defaultIMDSEndpoint,
+	}
+}
+
+// Source returns the name of the source of the cached credentials, e.g.
+// "environment" or "web identity", or "" before they are first resolved.
+func (c *Chain) Source() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.source
+}
+
+// Retrieve returns the cached credentials, or resolves them from the first
+// source that applies. Expiring credentials are kept if they cannot be
+// refreshed.
+func (c *Chain) Retrieve(ctx context.Context) (Credentials, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.credentials.Valid() && (c.credentials.Expires.IsZero() || time.Until(c.credentials.Expires) > refreshWindow) {
+		return c.credentials, nil
+	}
+
+	sources := []struct {
+		name     string
+		retrieve func(ctx context.Context) (Credentials, error)
+	}{
+		{"environment", c.fromEnv},
+		{"web identity", c.fromWebIdentity},
+		{"shared credentials file", c.fromSharedFile},
+		{"container", c.fromContainer},
+		{"instance profile", c.fromIMDS},
+	}
+
+	var errs []error
+	for _, source := range sources {
+		credentials, err := source.retrieve(ctx)
+		if errors.Is(err, errNotConfigured) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", source.name, err))
+			continue
+		}
+		c.credentials = credentials
+		c.source = source.name
+		return credentials, nil
+	}
+
+	if c.credentials.Valid() && time.Now().Before(c.credentials.Expires) {
+		return c.credentials, nil
+	}
+	if len(errs) == 0 {
+		return Credentials{}, fmt.Errorf("sigv4: no AWS credentials found")
+	}
+	return Credentials{}, fmt.Errorf("sigv4: no AWS credentials found: %w", errors.Join(errs...))
+}
+
+// fromEnv reads credentials from the environment variables.
+func (c *Chain) fromEnv(ctx context.Context) (Credentials, error) {
+	credentials := CredentialsFromEnv()
+	if !credentials.Valid() {
+		return Credentials{}, errNotConfigured
+	}
+	return credentials, nil
+}
+
+// stsResponse is the response of AssumeRoleWithWebIdentity.
+type stsResponse struct {
+	Credentials struct {
+		AccessKeyID     string    `xml:"AccessKeyId"`
+		SecretAccessKey string    `xml:"SecretAccessKey"`
+		SessionToken    string    `xml:"SessionToken"`
+		Expiration      time.Time `xml:"Expiration"`
+	} `xml:"AssumeRoleWithWebIdentityResult>Credentials"`
+}
+
+// fromWebIdentity exchanges a web identity token for role credentials.
+func (c *Chain) fromWebIdentity(ctx context.Context) (Credentials, error) {
+	tokenFile := os.Getenv("AWS_WEB_IDENTITY_TOKEN_FILE")
+	roleARN := os.Getenv("AWS_ROLE_ARN")
+	if tokenFile == "" || roleARN == "" {
+		return Credentials{}, errNotConfigured
+	}
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to read token: %w", err)
+	}
+	sessionName := os.Getenv("AWS_ROLE_SESSION_NAME")
+	if sessionName == "" {
+		sessionName = fmt.Sprintf("mercator-%d", time.Now().Unix())
+	}
+
+	endpoint := c.stsEndpoint
+	if endpoint == "" {
+		endpoint = "https://sts.amazonaws.com"
+		if region := Region(); region != "" {
+			endpoint = "https://sts." + region + ".amazonaws.com"
+		}
+	}
+	form := url.Values{
+		"Action":           {"AssumeRoleWithWebIdentity"},
+		"Version":          {"2011-06-15"},
+		"RoleArn":          {roleARN},
+		"RoleSessionName":  {sessionName},
+		"WebIdentityToken": {strings.TrimSpace(string(token))},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return Credentials{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	body, err := c.do(req)
+	if err != nil {
+		return Credentials{}, err
+	}
+	var resp stsResponse
+	if err := xml.Unmarshal(body, &resp); err != nil {
+		return Credentials{}, fmt.Errorf("failed to decode STS response: %w", err)
+	}
+	return validCredentials(Credentials{
+		AccessKeyID:     resp.Credentials.AccessKeyID,
+		SecretAccessKey: resp.Credentials.SecretAccessKey,
+		SessionToken:    resp.Credentials.SessionToken,
+		Expires:         resp.Credentials.Expiration,
+	})
+}
+
+// fromSharedFile reads the static credentials of a profile of the shared
+// credentials file.
+func (c *Chain) fromSharedFile(ctx context.Context) (Credentials, error) {
+	path := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if path == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return Credentials{}, errNotConfigured
+		}
+		path = filepath.Join(home, ".aws", "credentials")
+	}
+	profile := os.Getenv("AWS_PROFILE")
+	if profile == "" {
+		profile = "default"
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return Credentials{}, errNotConfigured
+		}
+		return Credentials{}, err
+	}
+	defer file.Close()
+
+	values, err := readProfile(file, profile)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("%s: %w", path, err)
+	}
+	if values == nil {
+		return Credentials{}, errNotConfigured
+	}
+	return validCredentials(Credentials{
+		AccessKeyID:     values["aws_access_key_id"],
+		SecretAccessKey: values["aws_secret_access_key"],
+		SessionToken:    values["aws_session_token"],
+	})
+}
+
+// readProfile returns the keys of a profile of an INI credentials file, or
+// nil if the profile is not in the file.
+func readProfile(r io.Reader, profile string) (map[string]string, error) {
+	var values map[string]string
+	inProfile := false
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			inProfile = strings.TrimSpace(line[1:len(line)-1]) == profile
+			if inProfile && values == nil {
+				values = make(map[string]string)
+			}
+			continue
+		}
+		if !inProfile {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok {
+			values[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+		}
+	}
+	return values, scanner.Err()
+}
+
+// containerResponse is the response of the container and instance
+// credentials endpoints.
+type containerResponse struct {
+	AccessKeyID     string    `json:"AccessKeyId"`
+	SecretAccessKey string    `json:"SecretAccessKey"`
+	Token           string    `json:"Token"`
+	Expiration      time.Time `json:"Expiration"`
+}
+
+func (r *containerResponse) credentials() (Credentials, error) {
+	return validCredentials(Credentials{
+		AccessKeyID:     r.AccessKeyID,
+		SecretAccessKey: r.SecretAccessKey,
+		SessionToken:    r.Token,
+		Expires:         r.Expiration,
+	})
+}
+
+// fromContainer requests the credentials of an ECS task role.
+func (c *Chain) fromContainer(ctx context.Context) (Credentials, error) {
+	endpoint := os.Getenv("AWS_CONTAINER_CREDENTIALS_FULL_URI")
+	if relative := os.Getenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI"); relative != "" {
+		endpoint = c.containerEndpoint + relative
+	}
+	if endpoint == "" {
+		return Credentials{}, errNotConfigured
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return Credentials{}, err
+	}
+	token := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN")
+	if tokenFile := os.Getenv("AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE"); tokenFile != "" {
+		data, err := os.ReadFile(tokenFile)
+		if err != nil {
+			return Credentials{}, fmt.Errorf("failed to read authorization token: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+
+	body, err := c.do(req)
+	if err != nil {
+		return Credentials{}, err
+	}
+	var resp containerResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return Credentials{}, fmt.Errorf("failed to decode container credentials: %w", err)
+	}
+	return resp.credentials()
+}
+
+// fromIMDS requests the instance profile credentials from the EC2 instance
+// metadata service, with an IMDSv2 session token.
+func (c *Chain) fromIMDS(ctx context.Context) (Credentials, error) {
+	if strings.EqualFold(os.Getenv("AWS_EC2_METADATA_DISABLED"), "true") {
+		return Credentials{}, errNotConfigured
+	}
+	endpoint := c.imdsEndpoint
+	if override := os.Getenv("AWS_EC2_METADATA_SERVICE_ENDPOINT"); override != "" {
+		endpoint = strings.TrimSuffix(override, "/")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 3*imdsTimeout)
+	defer cancel()
+
+	tokenCtx, tokenCancel := context.WithTimeout(ctx, imdsTimeout)
+	defer tokenCancel()
+	req, err := http.NewRequestWithContext(tokenCtx, http.MethodPut, endpoint+"/latest/api/token", nil)
+	if err != nil {
+		return Credentials{}, err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "21600")
+	token, err := c.do(req)
+	if err != nil {
+		// Not running on EC2
+		return Credentials{}, errNotConfigured
+	}
+
+	get := func(path string) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+path, nil)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("X-aws-ec2-metadata-token", string(token))
+		return c.do(req)
+	}
+
+	roles, err := get("/latest/meta-data/iam/security-credentials/")
+	if err != nil {
+		return Credentials{}, err
+	}
+	role, _, _ := strings.Cut(strings.TrimSpace(string(roles)), "\n")
+	if role == "" {
+		return Credentials{}, fmt.Errorf("no instance profile role")
+	}
+	body, err := get("/latest/meta-data/iam/security-credentials/" + url.PathEscape(role))
+	if err != nil {
+		return Credentials{}, err
+	}
+	var resp containerResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return Credentials{}, fmt.Errorf("failed to decode instance credentials: %w", err)
+	}
+	return resp.credentials()
+}
+
+// do sends a request to a credential service and returns the body of a
+// successful response.
+func (c *Chain) do(req *http.Request) ([]byte, error) {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s returned status %d", req.URL.Redacted(), resp.StatusCode)
+	}
+	return body, nil
+}
+
+// validCredentials returns credentials, or an error if they are incomplete.
+func validCredentials(credentials Credentials) (Credentials, error) {
+	if !credentials.Valid() {
+		return Credentials{}, fmt.Errorf("incomplete credentials")
+	}
+	return credentials, nil
+}
+
+// Region returns the region of the AWS_REGION or AWS_DEFAULT_REGION
+// environment variables, or "".
+func Region() string {
+	if region := os.Getenv("AWS_REGION"); region != "" {
+		return region
+	}
+	return os.Getenv("AWS_DEFAULT_REGION")
+}
@@ -0,0 +1,214 @@
+package sigv4
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// isolateEnv clears the AWS environment variables and points the shared
+// credentials file and metadata services at nothing.
+func isolateEnv(t *testing.T) {
+	t.Helper()
+	for _, name := range []string{
+		"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN",
+		"AWS_WEB_IDENTITY_TOKEN_FILE", "AWS_ROLE_ARN", "AWS_ROLE_SESSION_NAME",
+		"AWS_PROFILE", "AWS_REGION", "AWS_DEFAULT_REGION",
+		"AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "AWS_CONTAINER_CREDENTIALS_FULL_URI",
+		"AWS_CONTAINER_AUTHORIZATION_TOKEN", "AWS_CONTAINER_AUTHORIZATION_TOKEN_FILE",
+		"AWS_EC2_METADATA_SERVICE_ENDPOINT",
+	} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "missing"))
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "true")
+}
+
+func TestChain_Environment(t *testing.T) {
+	isolateEnv(t)
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+
+	chain := NewChain(nil)
+	credentials, err := chain.Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if credentials.AccessKeyID != "AKID" || chain.Source() != "environment" {
+		t.Errorf("Retrieve() = %+v from %q, want the environment credentials", credentials, chain.Source())
+	}
+}
+
+func TestChain_SharedFile(t *testing.T) {
+	isolateEnv(t)
+	path := filepath.Join(t.TempDir(), "credentials")
+	data := "[default]\naws_access_key_id = DEFAULT\naws_secret_access_key = secret\n\n" +
+		"# Bedrock access\n[bedrock]\naws_access_key_id = BEDROCK\naws_secret_access_key = secret\naws_session_token = token\n"
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", path)
+
+	credentials, err := NewChain(nil).Retrieve(context.Background())
+	if err != nil || credentials.AccessKeyID != "DEFAULT" {
+		t.Errorf("Retrieve() = %+v, %v, want the default profile", credentials, err)
+	}
+
+	t.Setenv("AWS_PROFILE", "bedrock")
+	credentials, err = NewChain(nil).Retrieve(context.Background())
+	if err != nil || credentials.AccessKeyID != "BEDROCK" || credentials.SessionToken != "token" {
+		t.Errorf("Retrieve() = %+v, %v, want the bedrock profile", credentials, err)
+	}
+
+	t.Setenv("AWS_PROFILE", "missing")
+	if _, err := NewChain(nil).Retrieve(context.Background()); err == nil {
+		t.Error("Retrieve() with a missing profile succeeded")
+	}
+}
+
+func TestChain_WebIdentity(t *testing.T) {
+	isolateEnv(t)
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(tokenFile, []byte("jwt\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_WEB_IDENTITY_TOKEN_FILE", tokenFile)
+	t.Setenv("AWS_ROLE_ARN", "arn:aws:iam::123456789012:role/mercator")
+
+	expires := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Error(err)
+		}
+		if r.Form.Get("Action") != "AssumeRoleWithWebIdentity" || r.Form.Get("WebIdentityToken") != "jwt" ||
+			r.Form.Get("RoleArn") != "arn:aws:iam::123456789012:role/mercator" {
+			http.Error(w, "unexpected request", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(w, `<AssumeRoleWithWebIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleWithWebIdentityResult>
+    <Credentials>
+      <AccessKeyId>ASIA</AccessKeyId>
+      <SecretAccessKey>secret</SecretAccessKey>
+      <SessionToken>session</SessionToken>
+      <Expiration>%s</Expiration>
+    </Credentials>
+  </AssumeRoleWithWebIdentityResult>
+</AssumeRoleWithWebIdentityResponse>`, expires.Format(time.RFC3339))
+	}))
+	defer server.Close()
+
+	chain := NewChain(nil)
+	chain.stsEndpoint = server.URL
+	credentials, err := chain.Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if credentials.AccessKeyID != "ASIA" || credentials.SessionToken != "session" || !credentials.Expires.Equal(expires) {
+		t.Errorf("Retrieve() = %+v, want the role credentials", credentials)
+	}
+}
+
+func TestChain_Container(t *testing.T) {
+	isolateEnv(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/credentials/task" || r.Header.Get("Authorization") != "auth" {
+			http.Error(w, "unexpected request", http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, `{"AccessKeyId":"ASIA","SecretAccessKey":"secret","Token":"session","Expiration":"2099-01-01T00:00:00Z"}`)
+	}))
+	defer server.Close()
+	t.Setenv("AWS_CONTAINER_CREDENTIALS_RELATIVE_URI", "/v2/credentials/task")
+	t.Setenv("AWS_CONTAINER_AUTHORIZATION_TOKEN", "auth")
+
+	chain := NewChain(nil)
+	chain.containerEndpoint = server.URL
+	credentials, err := chain.Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if credentials.AccessKeyID != "ASIA" || credentials.SessionToken != "session" || chain.Source() != "container" {
+		t.Errorf("Retrieve() = %+v from %q, want the task credentials", credentials, chain.Source())
+	}
+}
+
+func TestChain_InstanceProfile(t *testing.T) {
+	isolateEnv(t)
+	t.Setenv("AWS_EC2_METADATA_DISABLED", "")
+
+	var requests atomic.Int32
+	expires := time.Now().Add(time.Hour)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == "/latest/api/token":
+			fmt.Fprint(w, "imds-token")
+		case r.Header.Get("X-aws-ec2-metadata-token") != "imds-token":
+			http.Error(w, "missing token", http.StatusUnauthorized)
+		case r.URL.Path == "/latest/meta-data/iam/security-credentials/":
+			fmt.Fprint(w, "mercator-role\n")
+		case r.URL.Path == "/latest/meta-data/iam/security-credentials/mercator-role":
+			fmt.Fprintf(w, `{"Code":"Success","AccessKeyId":"ASIA","SecretAccessKey":"secret","Token":"session","Expiration":%q}`,
+				expires.UTC().Format(time.RFC3339))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	chain := NewChain(nil)
+	chain.imdsEndpoint = server.URL
+	credentials, err := chain.Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("Retrieve() error = %v", err)
+	}
+	if credentials.AccessKeyID != "ASIA" || chain.Source() != "instance profile" {
+		t.Errorf("Retrieve() = %+v from %q, want the instance credentials", credentials, chain.Source())
+	}
+
+	// The credentials are cached until shortly before they expire
+	if _, err := chain.Retrieve(context.Background()); err != nil || requests.Load() != 3 {
+		t.Errorf("second Retrieve() made %d requests (%v), want the cached credentials", requests.Load(), err)
+	}
+	expires = time.Now().Add(time.Minute)
+	chain.credentials.Expires = expires
+	if _, err := chain.Retrieve(context.Background()); err != nil || requests.Load() != 6 {
+		t.Errorf("Retrieve() of expiring credentials made %d requests (%v), want a refresh", requests.Load(), err)
+	}
+
+	// Expiring credentials are kept if they cannot be refreshed
+	server.Close()
+	chain.credentials.Expires = time.Now().Add(time.Minute)
+	if credentials, err := chain.Retrieve(context.Background()); err != nil || credentials.AccessKeyID != "ASIA" {
+		t.Errorf("Retrieve() = %+v, %v, want the cached credentials", credentials, err)
+	}
+}
+
+func TestChain_NoCredentials(t *testing.T) {
+	isolateEnv(t)
+	if _, err := NewChain(nil).Retrieve(context.Background()); err == nil {
+		t.Error("Retrieve() succeeded without credentials")
+	}
+}
+
+func TestSigner_Provider(t *testing.T) {
+	isolateEnv(t)
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "secret")
+	t.Setenv("AWS_SESSION_TOKEN", "session")
+
+	req, _ := http.NewRequest(http.MethodPost, "https://bedrock-runtime.us-east-1.amazonaws.com/model/m/converse", nil)
+	if err := NewSignerWithProvider(NewChain(nil), "us-east-1", "bedrock").Sign(req, []byte("{}"), testTime); err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+	if req.Header.Get("X-Amz-Security-Token") != "session" {
+		t.Errorf("X-Amz-Security-Token = %q, want the session token", req.Header.Get("X-Amz-Security-Token"))
+	}
+}
@@ -0,0 +1,30 @@
+// Package sigv4 signs HTTP requests with AWS Signature Version 4.
+//
+// It is a small, dependency-free signer for the AWS APIs Mercator talks to
+// directly (S3 for evidence archival, Bedrock for model access):
+//
+//	signer := sigv4.NewSigner(sigv4.CredentialsFromEnv(), "us-east-1", "s3")
+//	req, _ := http.NewRequest(http.MethodPut, url, bytes.NewReader(body))
+//	if err := signer.Sign(req, body, time.Now()); err != nil {
+//	    return err
+//	}
+//
+// # Credentials
+//
+// Static credentials are read from configuration or from the standard
+// AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, and AWS_SESSION_TOKEN
+// environment variables. Temporary credentials (session tokens) are sent in
+// the X-Amz-Security-Token header.
+//
+// A Chain resolves credentials the way the AWS SDKs do: environment
+// variables, web identity federation (EKS service accounts), the shared
+// credentials file, ECS task roles, and EC2 instance profiles. Temporary
+// credentials are cached and refreshed before they expire:
+//
+//	signer := sigv4.NewSignerWithProvider(sigv4.NewChain(nil), "us-east-1", "bedrock")
+//
+// # S3
+//
+// For the "s3" service the payload hash is sent in X-Amz-Content-Sha256 as
+// S3 requires, and the request path is canonicalized without double encoding.
+package sigv4
@@ -0,0 +1,229 @@
+package sigv4
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// algorithm is the signing algorithm identifier.
+	algorithm = "AWS4-HMAC-SHA256"
+
+	// timeFormat is the format of the X-Amz-Date header.
+	timeFormat = "20060102T150405Z"
+
+	// dateFormat is the format of the credential scope date.
+	dateFormat = "20060102"
+)
+
+// Credentials are AWS access credentials.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+
+	// Expires is when temporary credentials expire, or zero for static
+	// credentials.
+	Expires time.Time
+}
+
+// CredentialsFromEnv reads credentials from the standard AWS environment variables.
+func CredentialsFromEnv() Credentials {
+	return Credentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+}
+
+// Valid returns true if both the access key ID and secret are set.
+func (c Credentials) Valid() bool {
+	return c.AccessKeyID != "" && c.SecretAccessKey != ""
+}
+
+// Signer signs requests for a single AWS region and service.
+type Signer struct {
+	provider CredentialsProvider
+	region   string
+	service  string
+}
+
+// NewSigner creates a signer for the given region and service (e.g., "s3", "bedrock").
+func NewSigner(credentials Credentials, region, service string) *Signer {
+	return NewSignerWithProvider(StaticCredentials(credentials), region, service)
+}
+
+// NewSignerWithProvider creates a signer whose credentials are retrieved
+// from provider for each request, e.g. a Chain.
+func NewSignerWithProvider(provider CredentialsProvider, region, service string) *Signer {
+	return &Signer{
+		provider: provider,
+		region:   region,
+		service:  service,
+	}
+}
+
+// Sign adds the X-Amz-Date and Authorization headers (and the session token
+// and payload hash headers where required) to req. body must be the exact
+// request payload; it may be nil for requests without a body.
+func (s *Signer) Sign(req *http.Request, body []byte, now time.Time) error {
+	credentials, err := s.provider.Retrieve(req.Context())
+	if err != nil {
+		return err
+	}
+
+	now = now.UTC()
+	amzDate := now.Format(timeFormat)
+	payloadHash := hashHex(body)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	if credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", credentials.SessionToken)
+	}
+	if s.service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	signedHeaders, canonicalHeaders := s.canonicalHeaders(req)
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		s.canonicalURI(req),
+		canonicalQuery(req),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{now.Format(dateFormat), s.region, s.service, "aws4_request"}, "/")
+	stringToSign := strings.Join([]string{
+		algorithm,
+		amzDate,
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	signature := hex.EncodeToString(hmacSHA256(s.signingKey(credentials, now), stringToSign))
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		algorithm, credentials.AccessKeyID, scope, signedHeaders, signature))
+
+	return nil
+}
+
+// signingKey derives the request signing key for the given day.
+func (s *Signer) signingKey(credentials Credentials, now time.Time) []byte {
+	key := hmacSHA256([]byte("AWS4"+credentials.SecretAccessKey), now.Format(dateFormat))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, s.service)
+	return hmacSHA256(key, "aws4_request")
+}
+
+// canonicalURI returns the URI-encoded request path. S3 paths are encoded
+// once; other services encode the already escaped path a second time.
+func (s *Signer) canonicalURI(req *http.Request) string {
+	path := req.URL.EscapedPath()
+	if s.service == "s3" {
+		path = req.URL.Path
+	}
+	if path == "" {
+		return "/"
+	}
+
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// canonicalQuery returns the sorted, URI-encoded query string.
+func canonicalQuery(req *http.Request) string {
+	query := req.URL.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, escape(key)+"="+escape(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// canonicalHeaders returns the signed header list and the canonical header
+// block. The host, content type, and all X-Amz-* headers are signed.
+func (s *Signer) canonicalHeaders(req *http.Request) (string, string) {
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+
+	headers := map[string]string{"host": strings.TrimSpace(host)}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if lower != "content-type" && lower != "content-md5" && !strings.HasPrefix(lower, "x-amz-") {
+			continue
+		}
+		trimmed := make([]string, len(values))
+		for i, v := range values {
+			trimmed[i] = strings.Join(strings.Fields(v), " ")
+		}
+		headers[lower] = strings.Join(trimmed, ",")
+	}
+
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var block strings.Builder
+	for _, name := range names {
+		block.WriteString(name)
+		block.WriteByte(':')
+		block.WriteString(headers[name])
+		block.WriteByte('\n')
+	}
+	return strings.Join(names, ";"), block.String()
+}
+
+// escape URI-encodes a string per the SigV4 rules: every byte except
+// unreserved characters (A-Z a-z 0-9 - _ . ~) is percent-encoded.
+func escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// hashHex returns the hex-encoded SHA-256 of data.
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// hmacSHA256 returns HMAC-SHA256(key, data).
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
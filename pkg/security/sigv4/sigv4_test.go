@@ -0,0 +1,87 @@
+package sigv4
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// testCredentials are the credentials used by the AWS SigV4 test suite.
+var testCredentials = Credentials{
+	AccessKeyID:     "AKIDEXAMPLE",
+	SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+}
+
+var testTime = time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+func TestSigner_AWSTestSuite(t *testing.T) {
+	tests := []struct {
+		name string
+		url  string
+		want string
+	}{
+		{
+			name: "get-vanilla",
+			url:  "https://example.amazonaws.com/",
+			want: "5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31",
+		},
+		{
+			name: "get-vanilla-query-order-key-case",
+			url:  "https://example.amazonaws.com/?Param2=value2&Param1=value1",
+			want: "b97d918cfa904a5beff61c982a1b6f458b799221646efd99d3219ec94cdf2500",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, tt.url, nil)
+			signer := NewSigner(testCredentials, "us-east-1", "service")
+			if err := signer.Sign(req, nil, testTime); err != nil {
+				t.Fatalf("Sign() error = %v", err)
+			}
+
+			auth := req.Header.Get("Authorization")
+			wantPrefix := "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, SignedHeaders=host;x-amz-date, Signature="
+			if !strings.HasPrefix(auth, wantPrefix) {
+				t.Fatalf("Authorization = %q", auth)
+			}
+			if got := strings.TrimPrefix(auth, wantPrefix); got != tt.want {
+				t.Errorf("signature = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSigner_S3Headers(t *testing.T) {
+	creds := testCredentials
+	creds.SessionToken = "token"
+
+	req, _ := http.NewRequest(http.MethodPut, "https://bucket.s3.us-east-1.amazonaws.com/a/b%20c.jsonl", nil)
+	if err := NewSigner(creds, "us-east-1", "s3").Sign(req, []byte("payload"), testTime); err != nil {
+		t.Fatalf("Sign() error = %v", err)
+	}
+
+	if req.Header.Get("X-Amz-Content-Sha256") != hashHex([]byte("payload")) {
+		t.Error("expected payload hash header for s3")
+	}
+	if req.Header.Get("X-Amz-Security-Token") != "token" {
+		t.Error("expected session token header")
+	}
+	if !strings.Contains(req.Header.Get("Authorization"), "SignedHeaders=host;x-amz-content-sha256;x-amz-date;x-amz-security-token,") {
+		t.Errorf("unexpected signed headers: %s", req.Header.Get("Authorization"))
+	}
+}
+
+func TestSigner_MissingCredentials(t *testing.T) {
+	req, _ := http.NewRequest(http.MethodGet, "https://example.amazonaws.com/", nil)
+	if err := NewSigner(Credentials{}, "us-east-1", "s3").Sign(req, nil, testTime); err == nil {
+		t.Error("expected error without credentials")
+	}
+}
+
+func TestEscape(t *testing.T) {
+	if got := escape("a b/c:d~e"); got != "a%20b%2Fc%3Ad~e" {
+		t.Errorf("escape() = %q", got)
+	}
+}
@@ -74,6 +74,9 @@
 //  4. Logging: Logs request/response details
 //  5. Recovery: Recovers from panics and returns 500 error
 //
+// With a limits manager (SetLimitsManager), chat completion requests also
+// pass through LimitsMiddleware, which enforces rate limits and budgets.
+//
 // # TLS Support
 //
 // The server supports TLS 1.3 with configurable certificates:
@@ -13,9 +13,13 @@ import (
 	"syscall"
 
 	"mercator-hq/jupiter/pkg/config"
+	"mercator-hq/jupiter/pkg/limits"
 	"mercator-hq/jupiter/pkg/providers"
 	"mercator-hq/jupiter/pkg/proxy/handlers"
 	"mercator-hq/jupiter/pkg/proxy/middleware"
+	"mercator-hq/jupiter/pkg/security/auth"
+	securityTLS "mercator-hq/jupiter/pkg/security/tls"
+	"mercator-hq/jupiter/pkg/telemetry/tracing"
 )
 
 // Server is the main HTTP proxy server for LLM traffic.
@@ -23,11 +27,24 @@ type Server struct {
 	config          *config.ProxyConfig
 	securityConfig  *config.SecurityConfig
 	httpServer      *http.Server
+	adminServer     *http.Server
 	providerManager ProviderManager
 	shutdownChan    chan struct{}
 	shutdownOnce    sync.Once
 	mu              sync.RWMutex
 	isRunning       bool
+
+	// extraRoutes contains additional handlers registered via Handle
+	extraRoutes map[string]http.Handler
+
+	// operationalRoutes contains handlers registered via HandleOperational
+	operationalRoutes map[string]http.Handler
+
+	// adminRoutes contains handlers registered via HandleAdmin
+	adminRoutes map[string]http.Handler
+
+	// limitsManager enforces rate limits and budgets, if set
+	limitsManager *limits.Manager
 }
 
 // ProviderManager is the interface for managing LLM providers.
@@ -40,14 +57,62 @@ type ProviderManager interface {
 // NewServer creates a new proxy server.
 func NewServer(cfg *config.ProxyConfig, securityCfg *config.SecurityConfig, pm ProviderManager) *Server {
 	return &Server{
-		config:          cfg,
-		securityConfig:  securityCfg,
-		providerManager: pm,
-		shutdownChan:    make(chan struct{}),
-		isRunning:       false,
+		config:            cfg,
+		securityConfig:    securityCfg,
+		providerManager:   pm,
+		shutdownChan:      make(chan struct{}),
+		isRunning:         false,
+		extraRoutes:       make(map[string]http.Handler),
+		operationalRoutes: make(map[string]http.Handler),
+		adminRoutes:       make(map[string]http.Handler),
 	}
 }
 
+// Handle registers an additional handler for the given pattern (e.g. "/metrics").
+// Handlers must be registered before Start is called.
+func (s *Server) Handle(pattern string, handler http.Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.extraRoutes[pattern] = handler
+}
+
+// HandleOperational registers an operational handler that is not part of
+// the proxy API, such as the metrics endpoint. It is served on the admin
+// listener when one is configured (security.admin.listen_address), and on
+// the proxy listener otherwise. Unlike admin handlers, it is not
+// authenticated. Handlers must be registered before Start is called.
+func (s *Server) HandleOperational(pattern string, handler http.Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.operationalRoutes[pattern] = handler
+}
+
+// HandleAdmin registers an administrative handler under the "/admin" prefix.
+// For example, HandleAdmin("/policy/slow-rules", h) serves /admin/policy/slow-rules.
+// Handlers must be registered before Start is called.
+//
+// Admin handlers require an admin key (security.admin.keys), or a client
+// certificate when security.admin.auth is "client_cert". When admin
+// requests cannot be authenticated, admin handlers are not served. They are
+// served on the admin listener when one is configured, and on the proxy
+// listener otherwise.
+func (s *Server) HandleAdmin(path string, handler http.Handler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.adminRoutes[AdminPathPrefix+path] = handler
+}
+
+// SetLimitsManager enforces the rate limits and budgets of manager on chat
+// completion requests. It must be called before Start.
+func (s *Server) SetLimitsManager(manager *limits.Manager) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limitsManager = manager
+}
+
+// AdminPathPrefix is the URL prefix for administrative endpoints.
+const AdminPathPrefix = "/admin"
+
 // Start starts the HTTP server and blocks until shutdown.
 func (s *Server) Start(ctx context.Context) error {
 	s.mu.Lock()
@@ -80,8 +145,37 @@ func (s *Server) Start(ctx context.Context) error {
 		s.httpServer.TLSConfig = tlsConfig
 	}
 
-	// Start server in goroutine
-	errChan := make(chan error, 1)
+	// Create the admin server on its own listener, if configured
+	if s.hasAdminListener() {
+		adminServer, err := s.newAdminServer()
+		if err != nil {
+			return fmt.Errorf("failed to configure admin listener: %w", err)
+		}
+		s.adminServer = adminServer
+	}
+
+	// Start servers in goroutines
+	errChan := make(chan error, 2)
+	if s.adminServer != nil {
+		go func() {
+			slog.Info("starting admin server",
+				"address", s.adminServer.Addr,
+				"tls_enabled", s.adminServer.TLSConfig != nil,
+			)
+
+			var err error
+			if s.adminServer.TLSConfig != nil {
+				// Certificates are loaded in the TLS configuration
+				err = s.adminServer.ListenAndServeTLS("", "")
+			} else {
+				err = s.adminServer.ListenAndServe()
+			}
+
+			if err != nil && err != http.ErrServerClosed {
+				errChan <- fmt.Errorf("admin server error: %w", err)
+			}
+		}()
+	}
 	go func() {
 		slog.Info("starting proxy server",
 			"address", s.config.ListenAddress,
@@ -149,6 +243,16 @@ func (s *Server) Shutdown(ctx context.Context) error {
 			}
 		}
 
+		// Shutdown admin server
+		if s.adminServer != nil {
+			if err := s.adminServer.Shutdown(shutdownCtx); err != nil {
+				slog.Error("error during admin server shutdown", "error", err)
+				if shutdownErr == nil {
+					shutdownErr = fmt.Errorf("admin server shutdown error: %w", err)
+				}
+			}
+		}
+
 		s.mu.Lock()
 		s.isRunning = false
 		s.mu.Unlock()
@@ -165,17 +269,43 @@ func (s *Server) setupRoutes() http.Handler {
 
 	// Create handlers
 	chatHandler := handlers.NewChatHandler(s.providerManager)
+	chatHandler.StreamPassthrough = s.config.Streaming.Mode != "decode"
 	healthHandler := handlers.NewHealthHandler()
 	readyHandler := handlers.NewReadyHandler(s.providerManager)
 	wsHandler := handlers.NewWebSocketHandler(s.providerManager)
 	providerHealthHandler := handlers.NewProviderHealthHandler(s.providerManager)
 
+	// Tag completion requests with their cost allocation tag
+	costAllocation := middleware.CostAllocationMiddleware(s.convertCostAllocationConfig())
+
+	// Enforce rate limits and budgets on completion requests
+	var completions http.Handler = chatHandler
+	s.mu.RLock()
+	if s.limitsManager != nil {
+		completions = middleware.LimitsMiddleware(s.limitsManager)(completions)
+	}
+	s.mu.RUnlock()
+
 	// Register routes
-	mux.Handle("/v1/chat/completions", chatHandler)
+	mux.Handle("/v1/chat/completions", costAllocation(completions))
 	mux.Handle("/health", healthHandler)
 	mux.Handle("/ready", readyHandler)
 	mux.Handle("/health/providers", providerHealthHandler)
-	mux.Handle("/v1/chat/completions/ws", wsHandler)
+	mux.Handle("/v1/chat/completions/ws", costAllocation(wsHandler))
+
+	// Register additional routes, and the operational and admin routes
+	// when they are not isolated on the admin listener
+	s.mu.RLock()
+	for pattern, h := range s.extraRoutes {
+		mux.Handle(pattern, h)
+	}
+	if !s.hasAdminListener() {
+		for pattern, h := range s.operationalRoutes {
+			mux.Handle(pattern, h)
+		}
+		s.mountAdminRoutes(mux)
+	}
+	s.mu.RUnlock()
 
 	// Apply middleware chain
 	var handler http.Handler = mux
@@ -187,6 +317,10 @@ func (s *Server) setupRoutes() http.Handler {
 	corsConfig := s.convertCORSConfig()
 	handler = middleware.CORSMiddleware(corsConfig)(handler)
 
+	// Trace context middleware, continuing the traces of clients in
+	// provider requests
+	handler = tracing.HTTPMiddleware(handler)
+
 	// Request ID middleware
 	handler = middleware.RequestIDMiddleware(handler)
 
@@ -199,6 +333,114 @@ func (s *Server) setupRoutes() http.Handler {
 	return handler
 }
 
+// hasAdminListener reports whether the operational and admin routes are
+// served on a separate admin listener.
+func (s *Server) hasAdminListener() bool {
+	return s.securityConfig.Admin.ListenAddress != ""
+}
+
+// setupAdminRoutes configures the routes of the admin listener: the health
+// endpoints, and the operational and admin routes.
+func (s *Server) setupAdminRoutes() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/health", handlers.NewHealthHandler())
+	mux.Handle("/ready", handlers.NewReadyHandler(s.providerManager))
+
+	s.mu.RLock()
+	for pattern, h := range s.operationalRoutes {
+		mux.Handle(pattern, h)
+	}
+	s.mountAdminRoutes(mux)
+	s.mu.RUnlock()
+
+	// Apply middleware chain. The admin listener serves no browser or
+	// proxy clients, so CORS and trace continuation are not applied.
+	var handler http.Handler = mux
+	handler = middleware.TimeoutMiddleware(s.config.WriteTimeout)(handler)
+	handler = middleware.RequestIDMiddleware(handler)
+	handler = middleware.LoggingMiddleware(handler)
+	handler = middleware.RecoveryMiddleware(handler)
+
+	return handler
+}
+
+// newAdminServer creates the HTTP server of the admin listener, with the
+// timeouts of the proxy listener and its own TLS configuration.
+func (s *Server) newAdminServer() (*http.Server, error) {
+	adminServer := &http.Server{
+		Addr:           s.securityConfig.Admin.ListenAddress,
+		Handler:        s.setupAdminRoutes(),
+		ReadTimeout:    s.config.ReadTimeout,
+		WriteTimeout:   s.config.WriteTimeout,
+		IdleTimeout:    s.config.IdleTimeout,
+		MaxHeaderBytes: s.config.MaxHeaderBytes,
+	}
+
+	cfg := &s.securityConfig.Admin.TLS
+	tlsCfg := &securityTLS.Config{
+		Enabled:      cfg.Enabled,
+		CertFile:     cfg.CertFile,
+		KeyFile:      cfg.KeyFile,
+		MinVersion:   cfg.MinVersion,
+		CipherSuites: cfg.CipherSuites,
+		MTLS: securityTLS.MTLSConfig{
+			Enabled:          cfg.MTLS.Enabled,
+			ClientCAFile:     cfg.MTLS.ClientCAFile,
+			ClientAuthType:   cfg.MTLS.ClientAuthType,
+			VerifyClientCert: cfg.MTLS.VerifyClientCert,
+			IdentitySource:   cfg.MTLS.IdentitySource,
+		},
+	}
+	tlsConfig, err := tlsCfg.ToTLSConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure admin TLS: %w", err)
+	}
+	adminServer.TLSConfig = tlsConfig
+
+	return adminServer, nil
+}
+
+// adminAuthenticator returns the authenticator of admin requests, as
+// configured by security.admin.auth.
+func (s *Server) adminAuthenticator() (*auth.AdminAuthenticator, error) {
+	admin := &s.securityConfig.Admin
+	if admin.Auth == "client_cert" {
+		if !admin.TLS.MTLS.Enabled {
+			return nil, fmt.Errorf("client certificate authentication requires mTLS on the admin listener (security.admin.tls.mtls)")
+		}
+		return auth.NewAdminCertAuthenticator(admin.TLS.MTLS.IdentitySource), nil
+	}
+
+	keys := make([]auth.AdminKey, 0, len(admin.Keys))
+	for _, key := range admin.Keys {
+		keys = append(keys, auth.AdminKey{Name: key.Name, Key: key.Key})
+	}
+	authenticator, err := auth.NewAdminAuthenticator(keys)
+	if err != nil {
+		return nil, fmt.Errorf("no admin keys configured (security.admin.keys)")
+	}
+	return authenticator, nil
+}
+
+// mountAdminRoutes registers the admin handlers behind admin
+// authentication. Caller must hold the read lock.
+func (s *Server) mountAdminRoutes(mux *http.ServeMux) {
+	if len(s.adminRoutes) == 0 {
+		return
+	}
+
+	authenticator, err := s.adminAuthenticator()
+	if err != nil {
+		slog.Warn("admin endpoints disabled: "+err.Error(),
+			"endpoints", len(s.adminRoutes))
+		return
+	}
+
+	for pattern, h := range s.adminRoutes {
+		mux.Handle(pattern, authenticator.Handle(h))
+	}
+}
+
 // configureTLS configures TLS settings.
 func (s *Server) configureTLS() (*tls.Config, error) {
 	if s.securityConfig.TLS.CertFile == "" {
@@ -244,6 +486,15 @@ func (s *Server) Handler() http.Handler {
 	return s.setupRoutes()
 }
 
+// AdminHandler returns the HTTP handler of the admin listener, or nil when
+// no admin listener is configured.
+func (s *Server) AdminHandler() http.Handler {
+	if !s.hasAdminListener() {
+		return nil
+	}
+	return s.setupAdminRoutes()
+}
+
 // Health performs a health check on the server.
 func (s *Server) Health() error {
 	s.mu.RLock()
@@ -274,3 +525,15 @@ func (s *Server) convertCORSConfig() *middleware.CORSConfig {
 		AllowCredentials: s.config.CORS.AllowCredentials,
 	}
 }
+
+// convertCostAllocationConfig converts config.CostAllocationConfig to
+// middleware.CostAllocationConfig.
+func (s *Server) convertCostAllocationConfig() *middleware.CostAllocationConfig {
+	return &middleware.CostAllocationConfig{
+		Enabled:     s.config.CostAllocation.Enabled,
+		Header:      s.config.CostAllocation.Header,
+		MetadataKey: s.config.CostAllocation.MetadataKey,
+		AllowedTags: s.config.CostAllocation.AllowedTags,
+		Required:    s.config.CostAllocation.Required,
+	}
+}
@@ -0,0 +1,111 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/config"
+	"mercator-hq/jupiter/pkg/limits"
+	"mercator-hq/jupiter/pkg/limits/ratelimit"
+)
+
+func newTestServer(admin config.AdminConfig) *Server {
+	proxyCfg := &config.ProxyConfig{ListenAddress: "127.0.0.1:8080", WriteTimeout: 10 * time.Second}
+	securityCfg := &config.SecurityConfig{Admin: admin}
+	s := NewServer(proxyCfg, securityCfg, nil)
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	s.HandleOperational("/metrics", ok)
+	s.HandleAdmin("/evidence/records", ok)
+	return s
+}
+
+func serve(h http.Handler, path, adminKey string) int {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+	if adminKey != "" {
+		req.Header.Set("X-Admin-Key", adminKey)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec.Code
+}
+
+func TestServer_AdminRoutesOnProxyListener(t *testing.T) {
+	s := newTestServer(config.AdminConfig{
+		Keys: []config.AdminKeyConfig{{Name: "ops", Key: "admin-key-0123456789"}},
+	})
+
+	if s.AdminHandler() != nil {
+		t.Fatal("AdminHandler() should be nil without an admin listener")
+	}
+	handler := s.Handler()
+	if code := serve(handler, "/metrics", ""); code != http.StatusOK {
+		t.Errorf("/metrics = %d, want %d", code, http.StatusOK)
+	}
+	if code := serve(handler, "/admin/evidence/records", "admin-key-0123456789"); code != http.StatusOK {
+		t.Errorf("/admin/evidence/records = %d, want %d", code, http.StatusOK)
+	}
+}
+
+func TestServer_AdminListenerIsolation(t *testing.T) {
+	s := newTestServer(config.AdminConfig{
+		ListenAddress: "127.0.0.1:9090",
+		Keys:          []config.AdminKeyConfig{{Name: "ops", Key: "admin-key-0123456789"}},
+	})
+
+	// Operational and admin routes are not served by the proxy listener
+	handler := s.Handler()
+	for _, path := range []string{"/metrics", "/admin/evidence/records"} {
+		if code := serve(handler, path, "admin-key-0123456789"); code != http.StatusNotFound {
+			t.Errorf("proxy listener %s = %d, want %d", path, code, http.StatusNotFound)
+		}
+	}
+
+	adminHandler := s.AdminHandler()
+	if adminHandler == nil {
+		t.Fatal("AdminHandler() = nil with an admin listener")
+	}
+	tests := []struct {
+		path     string
+		adminKey string
+		want     int
+	}{
+		{"/metrics", "", http.StatusOK},
+		{"/health", "", http.StatusOK},
+		{"/admin/evidence/records", "admin-key-0123456789", http.StatusOK},
+		{"/admin/evidence/records", "", http.StatusUnauthorized},
+		{"/v1/chat/completions", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if code := serve(adminHandler, tt.path, tt.adminKey); code != tt.want {
+			t.Errorf("admin listener %s = %d, want %d", tt.path, code, tt.want)
+		}
+	}
+}
+
+func TestServer_LimitsManager(t *testing.T) {
+	manager := limits.NewManager(limits.Config{
+		RateLimits: map[string]ratelimit.Config{"test-key": {RequestsPerMinute: 1}},
+	})
+	defer manager.Close()
+
+	s := newTestServer(config.AdminConfig{})
+	s.SetLimitsManager(manager)
+	handler := s.Handler()
+
+	// The first request is admitted and fails without providers; the
+	// second exceeds the rate limit
+	codes := make([]int, 2)
+	for i := range codes {
+		req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", nil)
+		req.Header.Set("Authorization", "Bearer test-key")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		codes[i] = rec.Code
+	}
+	if codes[0] == http.StatusTooManyRequests || codes[1] != http.StatusTooManyRequests {
+		t.Errorf("Expected only the second request to be rate limited, got %v", codes)
+	}
+}
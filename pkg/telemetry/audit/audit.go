@@ -0,0 +1,194 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"mercator-hq/jupiter/pkg/config"
+)
+
+// EventType identifies the kind of a security audit event.
+type EventType string
+
+const (
+	// EventAuthFailure is a request rejected for missing or invalid
+	// credentials.
+	EventAuthFailure EventType = "auth_failure"
+
+	// EventPolicyBlock is a request or response blocked by a policy.
+	EventPolicyBlock EventType = "policy_block"
+
+	// EventAdminAction is an authenticated request to an administrative
+	// endpoint.
+	EventAdminAction EventType = "admin_action"
+
+	// EventSecretAccess is a secret read from the secrets manager.
+	EventSecretAccess EventType = "secret_access"
+
+	// EventConfigChange is a change of runtime settings, such as the log
+	// level, by an administrator or by an automatic revert.
+	EventConfigChange EventType = "config_change"
+
+	// EventLimitBypass is a request that bypassed rate limits and budgets
+	// with an exemption or a break-glass token.
+	EventLimitBypass EventType = "limit_bypass"
+)
+
+// Outcomes of audit events.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Event is a security audit event.
+type Event struct {
+	// Time is when the event occurred. Log sets it if zero.
+	Time time.Time `json:"time"`
+
+	// Type is the kind of event.
+	Type EventType `json:"type"`
+
+	// Outcome is "success" or "failure".
+	Outcome string `json:"outcome"`
+
+	// Principal is who acted: the admin key name, the API key's user or
+	// team, if known.
+	Principal string `json:"principal,omitempty"`
+
+	// RequestID is the request the event belongs to, if any.
+	RequestID string `json:"request_id,omitempty"`
+
+	// RemoteAddr, Method and Path describe the HTTP request, if any.
+	RemoteAddr string `json:"remote_addr,omitempty"`
+	Method     string `json:"method,omitempty"`
+	Path       string `json:"path,omitempty"`
+
+	// Reason explains a failure or block.
+	Reason string `json:"reason,omitempty"`
+
+	// Details are event specific attributes, such as the policy rule of a
+	// block or the name of a secret.
+	Details map[string]string `json:"details,omitempty"`
+}
+
+// Logger writes audit events as JSON lines to its sinks. Unlike application
+// logs, audit events are neither leveled nor sampled: every event is
+// written to every sink. It is safe for concurrent use.
+type Logger struct {
+	mu      sync.Mutex
+	sinks   []io.Writer
+	closers []io.Closer
+	failed  atomic.Int64
+}
+
+// New creates an audit logger writing to the configured sinks and to
+// writers, such as a message bus.
+func New(cfg *config.AuditConfig, writers ...io.Writer) (*Logger, error) {
+	l := &Logger{sinks: writers}
+	for _, sink := range cfg.Sinks {
+		switch sink.Type {
+		case "stdout":
+			l.sinks = append(l.sinks, os.Stdout)
+		case "stderr", "":
+			l.sinks = append(l.sinks, os.Stderr)
+		case "file":
+			// #nosec G304 - The audit file path comes from configuration
+			f, err := os.OpenFile(sink.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+			if err != nil {
+				_ = l.Close()
+				return nil, fmt.Errorf("failed to open audit file: %w", err)
+			}
+			l.sinks = append(l.sinks, f)
+			l.closers = append(l.closers, f)
+		default:
+			_ = l.Close()
+			return nil, fmt.Errorf("unsupported audit sink type: %s", sink.Type)
+		}
+	}
+	return l, nil
+}
+
+// NewWithWriters creates an audit logger writing to the given writers.
+func NewWithWriters(writers ...io.Writer) *Logger {
+	return &Logger{sinks: writers}
+}
+
+// Log writes an event to every sink. Sink failures are counted and logged,
+// and do not stop the event from reaching the other sinks.
+func (l *Logger) Log(ctx context.Context, event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now().UTC()
+	}
+	if event.Outcome == "" {
+		event.Outcome = OutcomeSuccess
+	}
+
+	line, err := json.Marshal(event)
+	if err != nil {
+		l.failed.Add(1)
+		slog.ErrorContext(ctx, "failed to encode audit event", "type", event.Type, "error", err)
+		return
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, sink := range l.sinks {
+		if _, err := sink.Write(line); err != nil {
+			l.failed.Add(1)
+			slog.ErrorContext(ctx, "failed to write audit event", "type", event.Type, "error", err)
+		}
+	}
+}
+
+// FailedCount returns the number of events that could not be written to a
+// sink.
+func (l *Logger) FailedCount() int64 {
+	return l.failed.Load()
+}
+
+// Close closes the file sinks.
+func (l *Logger) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var errs []error
+	for _, closer := range l.closers {
+		if err := closer.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	l.closers = nil
+	return errors.Join(errs...)
+}
+
+// defaultLogger is the audit logger of the package-level Log function.
+var defaultLogger atomic.Pointer[Logger]
+
+// SetDefault makes l the audit logger of the package-level Log function.
+// Passing nil disables audit logging.
+func SetDefault(l *Logger) {
+	defaultLogger.Store(l)
+}
+
+// Default returns the default audit logger, or nil if audit logging is
+// disabled.
+func Default() *Logger {
+	return defaultLogger.Load()
+}
+
+// Log writes an event with the default audit logger. It does nothing if
+// audit logging is disabled.
+func Log(ctx context.Context, event Event) {
+	if l := defaultLogger.Load(); l != nil {
+		l.Log(ctx, event)
+	}
+}
@@ -0,0 +1,82 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mercator-hq/jupiter/pkg/config"
+)
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func TestLogger_Log(t *testing.T) {
+	var first, second bytes.Buffer
+	logger := NewWithWriters(&first, failingWriter{}, &second)
+
+	logger.Log(context.Background(), Event{
+		Type:       EventAuthFailure,
+		Outcome:    OutcomeFailure,
+		RemoteAddr: "10.0.0.7:51234",
+		Path:       "/v1/chat/completions",
+		Reason:     "invalid API key",
+	})
+	logger.Log(context.Background(), Event{Type: EventAdminAction, Principal: "alice@example.com"})
+
+	for _, buf := range []*bytes.Buffer{&first, &second} {
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 2 {
+			t.Fatalf("expected 2 lines in every sink, got %q", buf.String())
+		}
+
+		var event Event
+		if err := json.Unmarshal([]byte(lines[1]), &event); err != nil {
+			t.Fatalf("invalid audit line %q: %v", lines[1], err)
+		}
+		if event.Type != EventAdminAction || event.Outcome != OutcomeSuccess || event.Principal != "alice@example.com" || event.Time.IsZero() {
+			t.Errorf("unexpected event %+v", event)
+		}
+	}
+	if got := logger.FailedCount(); got != 2 {
+		t.Errorf("FailedCount() = %d, want 2", got)
+	}
+}
+
+func TestNew_FileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	logger, err := New(&config.AuditConfig{Sinks: []config.AuditSinkConfig{{Type: "file", Path: path}}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	SetDefault(logger)
+	defer SetDefault(nil)
+	Log(context.Background(), Event{Type: EventSecretAccess, Details: map[string]string{"secret": "openai-api-key"}})
+	if err := logger.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read audit file: %v", err)
+	}
+	if !strings.Contains(string(data), `"type":"secret_access"`) || !strings.Contains(string(data), `"secret":"openai-api-key"`) {
+		t.Errorf("unexpected audit file content %q", data)
+	}
+}
+
+func TestNew_UnsupportedSink(t *testing.T) {
+	if _, err := New(&config.AuditConfig{Sinks: []config.AuditSinkConfig{{Type: "syslog"}}}); err == nil {
+		t.Error("expected error for unsupported sink type")
+	}
+}
@@ -0,0 +1,50 @@
+// Package audit provides the security audit event stream.
+//
+// # Overview
+//
+// Security-relevant events are written to a dedicated audit logger,
+// separate from application logs:
+//
+//   - auth_failure: requests rejected for a missing or invalid API key or
+//     admin key
+//   - policy_block: requests and responses blocked by a policy
+//   - admin_action: authenticated requests to administrative endpoints
+//   - secret_access: secrets read from the secrets manager, by name
+//   - config_change: runtime settings changed, such as the log level, and
+//     their automatic reverts
+//   - limit_bypass: requests that bypassed rate limits and budgets with an
+//     exemption or a break-glass token
+//
+// Audit events have their own sinks, configured under telemetry.audit, and
+// bypass the application log level and log sampling, so every event is
+// written to every sink:
+//
+//	telemetry:
+//	  audit:
+//	    enabled: true
+//	    sinks:
+//	      - type: file
+//	        path: /var/log/mercator/audit.log
+//	      - type: stderr
+//
+// # Usage
+//
+// The proxy installs the audit logger as the default, and components
+// report events with the package-level Log function, which does nothing
+// when audit logging is disabled:
+//
+//	logger, err := audit.New(&cfg.Telemetry.Audit)
+//	audit.SetDefault(logger)
+//
+//	audit.Log(ctx, audit.Event{
+//		Type:       audit.EventAuthFailure,
+//		Outcome:    audit.OutcomeFailure,
+//		RemoteAddr: r.RemoteAddr,
+//		Path:       r.URL.Path,
+//		Reason:     "invalid API key",
+//	})
+//
+// Each event is a JSON line:
+//
+//	{"time":"2026-10-16T09:30:00Z","type":"auth_failure","outcome":"failure","remote_addr":"10.0.0.7:51234","path":"/v1/chat/completions","reason":"invalid API key"}
+package audit
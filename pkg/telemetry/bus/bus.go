@@ -0,0 +1,249 @@
+package bus
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Publisher delivers batches of messages to a message bus.
+type Publisher interface {
+	// Name identifies the publisher in logs.
+	Name() string
+
+	// Publish delivers a batch of messages. It must respect ctx
+	// cancellation. A returned error marks the whole batch as failed.
+	Publish(ctx context.Context, messages [][]byte) error
+
+	// Close releases any resources held by the publisher.
+	Close() error
+}
+
+// Config contains configuration for a Shipper.
+type Config struct {
+	// BufferSize is the number of messages queued for delivery.
+	// Default: 10000
+	BufferSize int
+
+	// BatchSize is the maximum number of messages passed to a single
+	// Publish call.
+	// Default: 100
+	BatchSize int
+
+	// FlushInterval is the maximum time a message waits in a partial batch.
+	// Default: 1 second
+	FlushInterval time.Duration
+
+	// Timeout is the timeout for a single Publish call, and the maximum
+	// wait for queue space when Block is set.
+	// Default: 10 seconds
+	Timeout time.Duration
+
+	// Block makes writers wait up to Timeout for queue space when the
+	// queue is full, instead of dropping the message right away.
+	// Default: false
+	Block bool
+}
+
+// DefaultConfig returns the default shipper configuration.
+func DefaultConfig() *Config {
+	return &Config{
+		BufferSize:    10000,
+		BatchSize:     100,
+		FlushInterval: time.Second,
+		Timeout:       10 * time.Second,
+	}
+}
+
+// Stats contains the delivery counters of a Shipper.
+type Stats struct {
+	// Shipped is the number of messages delivered successfully.
+	Shipped int64 `json:"shipped"`
+
+	// Failed is the number of messages in batches that failed to publish.
+	Failed int64 `json:"failed"`
+
+	// Dropped is the number of messages dropped because the queue was full.
+	Dropped int64 `json:"dropped"`
+
+	// Queued is the number of messages waiting in the queue.
+	Queued int `json:"queued"`
+}
+
+// Shipper queues messages and delivers them to a Publisher in batches from
+// a background goroutine, so writers never wait on the bus.
+//
+// Shipper is an io.Writer: each Write is one message, with a trailing
+// newline removed. It can back a slog.JSONHandler, which writes every log
+// record in a single Write, or an audit logger.
+type Shipper struct {
+	config    *Config
+	publisher Publisher
+	queue     chan []byte
+	done      chan struct{}
+	logger    *slog.Logger
+
+	shipped atomic.Int64
+	failed  atomic.Int64
+	dropped atomic.Int64
+
+	// mu guards closed against concurrent Write and Close.
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewShipper creates a shipper delivering to publisher and starts its
+// delivery goroutine.
+func NewShipper(config *Config, publisher Publisher) *Shipper {
+	if config == nil {
+		config = DefaultConfig()
+	}
+	defaults := DefaultConfig()
+	if config.BufferSize <= 0 {
+		config.BufferSize = defaults.BufferSize
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = defaults.BatchSize
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = defaults.FlushInterval
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+
+	s := &Shipper{
+		config:    config,
+		publisher: publisher,
+		queue:     make(chan []byte, config.BufferSize),
+		done:      make(chan struct{}),
+		// Delivery failures are reported on stderr rather than through
+		// slog.Default, which may ship to this very bus.
+		logger: slog.New(slog.NewJSONHandler(os.Stderr, nil)).With(
+			"component", "telemetry.bus",
+			"publisher", publisher.Name(),
+		),
+	}
+
+	go s.run()
+	return s
+}
+
+// Write queues p as one message. It never fails: messages that do not fit
+// in the queue are counted as dropped. Write does not retain p.
+func (s *Shipper) Write(p []byte) (int, error) {
+	n := len(p)
+	message := bytes.Clone(bytes.TrimSuffix(p, []byte("\n")))
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		s.dropped.Add(1)
+		return n, nil
+	}
+
+	select {
+	case s.queue <- message:
+		return n, nil
+	default:
+	}
+
+	if s.config.Block {
+		timer := time.NewTimer(s.config.Timeout)
+		defer timer.Stop()
+		select {
+		case s.queue <- message:
+			return n, nil
+		case <-timer.C:
+		}
+	}
+
+	s.dropped.Add(1)
+	return n, nil
+}
+
+// Stats returns the delivery counters.
+func (s *Shipper) Stats() Stats {
+	return Stats{
+		Shipped: s.shipped.Load(),
+		Failed:  s.failed.Load(),
+		Dropped: s.dropped.Load(),
+		Queued:  len(s.queue),
+	}
+}
+
+// Close stops accepting messages, delivers the queued messages, and closes
+// the publisher.
+func (s *Shipper) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	close(s.queue)
+	s.mu.Unlock()
+
+	<-s.done
+
+	if err := s.publisher.Close(); err != nil {
+		return fmt.Errorf("failed to close bus publisher: %w", err)
+	}
+	return nil
+}
+
+// run batches queued messages until the queue is closed.
+func (s *Shipper) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([][]byte, 0, s.config.BatchSize)
+	for {
+		select {
+		case message, ok := <-s.queue:
+			if !ok {
+				s.flush(batch)
+				return
+			}
+			batch = append(batch, message)
+			if len(batch) >= s.config.BatchSize {
+				s.flush(batch)
+				batch = batch[:0]
+			}
+		case <-ticker.C:
+			s.flush(batch)
+			batch = batch[:0]
+		}
+	}
+}
+
+// flush publishes a batch.
+func (s *Shipper) flush(batch [][]byte) {
+	if len(batch) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.Timeout)
+	defer cancel()
+
+	if err := s.publisher.Publish(ctx, batch); err != nil {
+		s.failed.Add(int64(len(batch)))
+		s.logger.Error("failed to publish to bus", "messages", len(batch), "error", err)
+		return
+	}
+	s.shipped.Add(int64(len(batch)))
+}
+
+// NewHandler returns a slog.Handler that ships log records of level and
+// above to shipper as JSON objects.
+func NewHandler(shipper *Shipper, level slog.Leveler) slog.Handler {
+	return slog.NewJSONHandler(shipper, &slog.HandlerOptions{Level: level})
+}
@@ -0,0 +1,213 @@
+package bus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/kafkarest"
+)
+
+// recordingPublisher records published batches.
+type recordingPublisher struct {
+	mu      sync.Mutex
+	batches [][][]byte
+	block   chan struct{}
+	err     error
+}
+
+func (p *recordingPublisher) Name() string { return "recording" }
+
+func (p *recordingPublisher) Publish(ctx context.Context, messages [][]byte) error {
+	if p.block != nil {
+		<-p.block
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	batch := make([][]byte, len(messages))
+	copy(batch, messages)
+	p.batches = append(p.batches, batch)
+	return p.err
+}
+
+func (p *recordingPublisher) Close() error { return nil }
+
+func (p *recordingPublisher) messages() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var messages []string
+	for _, batch := range p.batches {
+		for _, message := range batch {
+			messages = append(messages, string(message))
+		}
+	}
+	return messages
+}
+
+func TestShipper_Batches(t *testing.T) {
+	publisher := &recordingPublisher{}
+	shipper := NewShipper(&Config{BatchSize: 2, FlushInterval: time.Hour}, publisher)
+
+	for _, msg := range []string{"a\n", "b\n", "c\n"} {
+		shipper.Write([]byte(msg))
+	}
+	if err := shipper.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	if len(publisher.batches) != 2 || len(publisher.batches[0]) != 2 {
+		t.Errorf("batches = %d (first %d), want 2 batches of up to 2", len(publisher.batches), len(publisher.batches[0]))
+	}
+	got := publisher.messages()
+	if len(got) != 3 || got[0] != "a" || got[2] != "c" {
+		t.Errorf("messages = %q, want [a b c] without newlines", got)
+	}
+	if stats := shipper.Stats(); stats.Shipped != 3 || stats.Dropped != 0 {
+		t.Errorf("Stats() = %+v, want 3 shipped", stats)
+	}
+
+	// Writes after Close are dropped
+	shipper.Write([]byte("late"))
+	if stats := shipper.Stats(); stats.Dropped != 1 {
+		t.Errorf("Dropped = %d, want 1", stats.Dropped)
+	}
+}
+
+func TestShipper_FlushInterval(t *testing.T) {
+	publisher := &recordingPublisher{}
+	shipper := NewShipper(&Config{BatchSize: 100, FlushInterval: 10 * time.Millisecond}, publisher)
+	defer shipper.Close()
+
+	shipper.Write([]byte("a"))
+	deadline := time.Now().Add(2 * time.Second)
+	for len(publisher.messages()) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("partial batch was not flushed")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestShipper_Backpressure(t *testing.T) {
+	tests := []struct {
+		name        string
+		block       bool
+		wantDropped int64
+	}{
+		{"drop", false, 2},
+		{"block", true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			publisher := &recordingPublisher{block: make(chan struct{})}
+			shipper := NewShipper(&Config{
+				BufferSize:    1,
+				BatchSize:     1,
+				FlushInterval: time.Hour,
+				Timeout:       time.Second,
+				Block:         tt.block,
+			}, publisher)
+
+			// The first message is held by the blocked publisher and the
+			// second fills the queue.
+			shipper.Write([]byte("1"))
+			time.Sleep(20 * time.Millisecond)
+			shipper.Write([]byte("2"))
+
+			done := make(chan struct{})
+			go func() {
+				shipper.Write([]byte("3"))
+				shipper.Write([]byte("4"))
+				close(done)
+			}()
+
+			if tt.block {
+				select {
+				case <-done:
+					t.Fatal("writes did not wait for queue space")
+				case <-time.After(50 * time.Millisecond):
+				}
+				close(publisher.block)
+				<-done
+			} else {
+				<-done
+				close(publisher.block)
+			}
+			shipper.Close()
+
+			if stats := shipper.Stats(); stats.Dropped != tt.wantDropped {
+				t.Errorf("Dropped = %d, want %d", stats.Dropped, tt.wantDropped)
+			}
+		})
+	}
+}
+
+func TestShipper_PublishFailure(t *testing.T) {
+	publisher := &recordingPublisher{err: errors.New("unavailable")}
+	shipper := NewShipper(&Config{BatchSize: 10}, publisher)
+
+	shipper.Write([]byte("a"))
+	shipper.Write([]byte("b"))
+	shipper.Close()
+
+	if stats := shipper.Stats(); stats.Failed != 2 || stats.Shipped != 0 {
+		t.Errorf("Stats() = %+v, want 2 failed", stats)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	publisher := &recordingPublisher{}
+	shipper := NewShipper(nil, publisher)
+
+	logger := slog.New(NewHandler(shipper, slog.LevelInfo)).With("component", "test")
+	logger.Debug("not shipped")
+	logger.Info("request completed", "status", 200)
+	shipper.Close()
+
+	messages := publisher.messages()
+	if len(messages) != 1 {
+		t.Fatalf("messages = %q, want 1", messages)
+	}
+	var record map[string]any
+	if err := json.Unmarshal([]byte(messages[0]), &record); err != nil {
+		t.Fatalf("message is not JSON: %v", err)
+	}
+	if record["msg"] != "request completed" || record["component"] != "test" || record["status"] != float64(200) {
+		t.Errorf("record = %v", record)
+	}
+}
+
+func TestKafkaPublisher(t *testing.T) {
+	var body struct {
+		Records []struct {
+			Value map[string]any `json:"value"`
+		} `json:"records"`
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/topics/mercator.logs" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Write([]byte(`{"offsets":[{"partition":0,"offset":1},{"partition":0,"offset":2}]}`))
+	}))
+	defer server.Close()
+
+	publisher := NewKafkaPublisher(&kafkarest.Config{URL: server.URL, Topic: "mercator.logs"})
+	err := publisher.Publish(context.Background(), [][]byte{[]byte(`{"msg":"a"}`), []byte(`{"msg":"b"}`)})
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if len(body.Records) != 2 || body.Records[1].Value["msg"] != "b" {
+		t.Errorf("records = %+v, want JSON values a and b", body.Records)
+	}
+	if publisher.Name() != "kafka:mercator.logs" {
+		t.Errorf("Name() = %q", publisher.Name())
+	}
+}
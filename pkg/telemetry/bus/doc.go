@@ -0,0 +1,33 @@
+// Package bus ships structured log records and telemetry events to a
+// message bus.
+//
+// # Overview
+//
+// A Shipper queues messages and delivers them in batches to a Publisher
+// from a background goroutine, so logging never waits on the network:
+//
+//   - KafkaPublisher produces to a Kafka topic through a Kafka REST Proxy
+//   - NATSPublisher publishes to a NATS subject
+//
+// A batch is published when it reaches the batch size or when the flush
+// interval elapses. When the queue is full, messages are dropped, or with
+// Config.Block writers wait up to Config.Timeout for space first. Dropped
+// and failed messages are counted in Stats, and delivery failures are
+// reported on stderr rather than through the logger being shipped.
+//
+// # Usage
+//
+// A Shipper is an io.Writer taking one message per Write. NewHandler wraps
+// it in a slog.Handler writing one JSON object per record, which the proxy
+// combines with its regular log handler:
+//
+//	shipper := bus.NewShipper(bus.DefaultConfig(), bus.NewKafkaPublisher(&kafkarest.Config{
+//		URL:   "http://kafka-rest:8082",
+//		Topic: "mercator.logs",
+//	}))
+//	defer shipper.Close()
+//
+//	logger := slog.New(logging.NewMultiHandler(handler, bus.NewHandler(shipper, slog.LevelInfo)))
+//
+// A Shipper can also be passed to audit.New as an extra audit sink.
+package bus
@@ -0,0 +1,69 @@
+package bus
+
+import (
+	"context"
+	"encoding/json"
+
+	"mercator-hq/jupiter/pkg/kafkarest"
+	"mercator-hq/jupiter/pkg/natsclient"
+)
+
+// KafkaPublisher produces messages to a Kafka topic through a Kafka REST
+// Proxy. Messages must be JSON values.
+type KafkaPublisher struct {
+	client *kafkarest.Client
+}
+
+// NewKafkaPublisher creates a Kafka publisher.
+func NewKafkaPublisher(config *kafkarest.Config) *KafkaPublisher {
+	return &KafkaPublisher{client: kafkarest.NewClient(config)}
+}
+
+// Name returns the publisher name.
+func (p *KafkaPublisher) Name() string {
+	return "kafka:" + p.client.Topic()
+}
+
+// Publish produces the batch in a single request.
+func (p *KafkaPublisher) Publish(ctx context.Context, messages [][]byte) error {
+	records := make([]kafkarest.Record, len(messages))
+	for i, message := range messages {
+		records[i] = kafkarest.Record{Value: json.RawMessage(message)}
+	}
+	_, err := p.client.Produce(ctx, kafkarest.ContentTypeJSON, &kafkarest.ProduceRequest{Records: records})
+	return err
+}
+
+// Close is a no-op for Kafka publishers.
+func (p *KafkaPublisher) Close() error {
+	return nil
+}
+
+// NATSPublisher publishes messages to a NATS subject.
+type NATSPublisher struct {
+	client *natsclient.Client
+}
+
+// NewNATSPublisher creates a NATS publisher.
+func NewNATSPublisher(config *natsclient.Config) (*NATSPublisher, error) {
+	client, err := natsclient.NewClient(config)
+	if err != nil {
+		return nil, err
+	}
+	return &NATSPublisher{client: client}, nil
+}
+
+// Name returns the publisher name.
+func (p *NATSPublisher) Name() string {
+	return "nats:" + p.client.Subject()
+}
+
+// Publish publishes the batch with a single write.
+func (p *NATSPublisher) Publish(ctx context.Context, messages [][]byte) error {
+	return p.client.Publish(ctx, messages...)
+}
+
+// Close closes the server connection.
+func (p *NATSPublisher) Close() error {
+	return p.client.Close()
+}
@@ -13,6 +13,9 @@
 //   - metrics: Prometheus metrics collection
 //   - tracing: OpenTelemetry distributed tracing
 //   - health: Health check endpoints
+//   - audit: Security audit event stream, separate from application logs
+//   - profiling: pprof and runtime debug endpoints behind admin authentication
+//   - bus: Shipping of log records and audit events to Kafka or NATS
 //
 // # Usage
 //
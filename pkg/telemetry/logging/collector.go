@@ -0,0 +1,184 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+	"sync"
+	"time"
+)
+
+// FieldMapping adapts the attributes of log lines to the field names of a
+// collector.
+type FieldMapping struct {
+	// Rename maps attribute names to field names. Attributes of groups are
+	// named with dots, e.g. "http.status". An empty field name drops the
+	// attribute.
+	Rename map[string]string
+
+	// Static are fields added to every line.
+	Static map[string]string
+}
+
+// collectorField is a log attribute flattened for a collector.
+type collectorField struct {
+	name  string
+	value slog.Value
+}
+
+// collectorEntry is a log record flattened for a collector.
+type collectorEntry struct {
+	time      time.Time
+	level     slog.Level
+	message   string
+	component string
+	fields    []collectorField
+}
+
+// collectorFormatter renders a log record in the format of a collector.
+type collectorFormatter interface {
+	format(entry *collectorEntry) []byte
+}
+
+// collectorHandler is a slog.Handler writing records flattened into named
+// fields, for collectors without nested attributes. Each record is written
+// in a single Write.
+type collectorHandler struct {
+	w         io.Writer
+	mu        *sync.Mutex
+	level     slog.Leveler
+	mapping   FieldMapping
+	formatter collectorFormatter
+	static    []collectorField
+
+	// fields are the attributes of the logger, and component and prefix
+	// its component and open groups.
+	fields    []collectorField
+	component string
+	prefix    string
+}
+
+// newCollectorHandler creates a handler writing lines of level and above
+// to w.
+func newCollectorHandler(w io.Writer, level slog.Leveler, mapping FieldMapping, formatter collectorFormatter) *collectorHandler {
+	if level == nil {
+		level = slog.LevelInfo
+	}
+	h := &collectorHandler{w: w, mu: &sync.Mutex{}, level: level, mapping: mapping, formatter: formatter}
+	for _, name := range slices.Sorted(maps.Keys(mapping.Static)) {
+		h.static = append(h.static, collectorField{name: name, value: slog.StringValue(mapping.Static[name])})
+	}
+	return h
+}
+
+// Enabled implements slog.Handler.
+func (h *collectorHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= h.level.Level()
+}
+
+// Handle implements slog.Handler.
+func (h *collectorHandler) Handle(_ context.Context, record slog.Record) error {
+	entry := &collectorEntry{
+		time:      record.Time,
+		level:     record.Level,
+		message:   record.Message,
+		component: h.component,
+		fields:    make([]collectorField, 0, len(h.fields)+record.NumAttrs()+len(h.static)),
+	}
+	if entry.time.IsZero() {
+		entry.time = time.Now()
+	}
+	entry.fields = append(entry.fields, h.fields...)
+	record.Attrs(func(attr slog.Attr) bool {
+		if h.prefix == "" && attr.Key == ComponentKey {
+			entry.component = attr.Value.String()
+		}
+		entry.fields = h.appendAttr(entry.fields, h.prefix, attr)
+		return true
+	})
+	entry.fields = append(entry.fields, h.static...)
+
+	message := h.formatter.format(entry)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(message)
+	return err
+}
+
+// WithAttrs implements slog.Handler.
+func (h *collectorHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.fields = make([]collectorField, len(h.fields), len(h.fields)+len(attrs))
+	copy(clone.fields, h.fields)
+	for _, attr := range attrs {
+		if h.prefix == "" && attr.Key == ComponentKey {
+			clone.component = attr.Value.String()
+		}
+		clone.fields = h.appendAttr(clone.fields, h.prefix, attr)
+	}
+	return &clone
+}
+
+// WithGroup implements slog.Handler.
+func (h *collectorHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.prefix = h.prefix + name + "."
+	return &clone
+}
+
+// appendAttr appends attr to fields, flattening groups into dotted names
+// and applying the field mapping.
+func (h *collectorHandler) appendAttr(fields []collectorField, prefix string, attr slog.Attr) []collectorField {
+	value := attr.Value.Resolve()
+	if value.Kind() == slog.KindGroup {
+		if attr.Key != "" {
+			prefix += attr.Key + "."
+		}
+		for _, member := range value.Group() {
+			fields = h.appendAttr(fields, prefix, member)
+		}
+		return fields
+	}
+	if attr.Key == "" {
+		return fields
+	}
+
+	name := prefix + attr.Key
+	if renamed, ok := h.mapping.Rename[name]; ok {
+		if renamed == "" {
+			return fields
+		}
+		name = renamed
+	}
+	return append(fields, collectorField{name: name, value: value})
+}
+
+// syslogSeverity maps a log level to a syslog severity, as used by GELF
+// and syslog.
+func syslogSeverity(level slog.Level) int {
+	switch {
+	case level >= slog.LevelError:
+		return 3 // error
+	case level >= slog.LevelWarn:
+		return 4 // warning
+	case level >= slog.LevelInfo:
+		return 6 // informational
+	default:
+		return 7 // debug
+	}
+}
+
+// defaultHostname returns the host name reported to collectors.
+func defaultHostname() string {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		return "localhost"
+	}
+	return host
+}
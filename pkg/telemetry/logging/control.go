@@ -0,0 +1,405 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"mercator-hq/jupiter/pkg/config"
+	"mercator-hq/jupiter/pkg/telemetry/audit"
+)
+
+// ControlState contains the runtime logging settings.
+type ControlState struct {
+	// Level is the global log level.
+	Level string `json:"level"`
+
+	// Components are the component levels overriding the global level.
+	Components map[string]string `json:"components"`
+
+	// Redaction is whether each PII redaction pattern is enabled, by name.
+	Redaction map[string]bool `json:"redaction"`
+
+	// RevertAt is when the settings revert to the configured ones, if a
+	// revert is pending.
+	RevertAt *time.Time `json:"revert_at,omitempty"`
+}
+
+// ControlUpdate is a change of the runtime logging settings. Zero fields
+// leave the settings unchanged.
+type ControlUpdate struct {
+	// Level sets the global log level.
+	Level string `json:"level,omitempty"`
+
+	// Components set component levels. An empty level removes the
+	// component's override.
+	Components map[string]string `json:"components,omitempty"`
+
+	// Redaction enables or disables PII redaction patterns, by name.
+	Redaction map[string]bool `json:"redaction,omitempty"`
+
+	// RevertAfter restores the configured settings once the duration
+	// elapses (e.g., "15m"), replacing any pending revert.
+	RevertAfter string `json:"revert_after,omitempty"`
+}
+
+// controlLevels is an immutable set of log levels.
+type controlLevels struct {
+	level      slog.Level
+	components map[string]slog.Level
+
+	// min is the lowest of level and the component levels.
+	min slog.Level
+}
+
+// newControlLevels creates a set of levels.
+func newControlLevels(level slog.Level, components map[string]slog.Level) *controlLevels {
+	levels := &controlLevels{level: level, components: components, min: level}
+	for _, l := range components {
+		if l < levels.min {
+			levels.min = l
+		}
+	}
+	return levels
+}
+
+// enabled reports whether a line of level is logged for component.
+func (l *controlLevels) enabled(component string, level slog.Level) bool {
+	if componentLevel, ok := l.components[component]; ok {
+		return level >= componentLevel
+	}
+	return level >= l.level
+}
+
+// Control changes the level and PII redaction of the application logs at
+// runtime, without a restart. Lines below the global level, or below the
+// level of their component, are dropped by the handlers wrapped with Wrap;
+// the redaction patterns apply to the handlers wrapped with Wrap and Redact.
+//
+// A change can revert automatically to the configured settings. Changes
+// and reverts are recorded in the audit log. Control is safe for
+// concurrent use.
+type Control struct {
+	levels    atomic.Pointer[controlLevels]
+	redactor  *Redactor
+	redacting atomic.Bool
+
+	// mu serializes changes and guards the pending revert
+	mu        sync.Mutex
+	baseline  ControlState
+	revert    *time.Timer
+	revertAt  time.Time
+	revertGen uint64
+}
+
+// NewControl creates a control with the configured level and redaction.
+// Redaction patterns are enabled if cfg.RedactPII is set.
+func NewControl(cfg *config.LoggingConfig) *Control {
+	c := &Control{redactor: NewRedactor(cfg.RedactPatterns)}
+
+	level, err := parseLevel(cfg.Level)
+	if err != nil {
+		level = slog.LevelInfo
+	}
+	c.levels.Store(newControlLevels(level, nil))
+	for _, name := range c.redactor.PatternNames() {
+		_ = c.redactor.SetPatternEnabled(name, cfg.RedactPII)
+	}
+	c.redacting.Store(cfg.RedactPII)
+
+	c.baseline = c.state()
+	return c
+}
+
+// State returns the current settings.
+func (c *Control) State() ControlState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state()
+}
+
+// state returns the current settings. c.mu must be held, or c not shared.
+func (c *Control) state() ControlState {
+	levels := c.levels.Load()
+	state := ControlState{
+		Level:      levelName(levels.level),
+		Components: make(map[string]string, len(levels.components)),
+		Redaction:  make(map[string]bool),
+	}
+	for component, level := range levels.components {
+		state.Components[component] = levelName(level)
+	}
+	for _, name := range c.redactor.PatternNames() {
+		state.Redaction[name] = c.redactor.PatternEnabled(name)
+	}
+	if c.revert != nil {
+		revertAt := c.revertAt
+		state.RevertAt = &revertAt
+	}
+	return state
+}
+
+// Update applies a change and returns the new settings. An invalid change
+// is rejected as a whole.
+func (c *Control) Update(update *ControlUpdate) (ControlState, error) {
+	var level *slog.Level
+	if update.Level != "" {
+		l, err := parseLevel(update.Level)
+		if err != nil {
+			return ControlState{}, err
+		}
+		level = &l
+	}
+
+	components := make(map[string]*slog.Level, len(update.Components))
+	for component, name := range update.Components {
+		if component == "" {
+			return ControlState{}, fmt.Errorf("component name is required")
+		}
+		if name == "" {
+			components[component] = nil
+			continue
+		}
+		l, err := parseLevel(name)
+		if err != nil {
+			return ControlState{}, fmt.Errorf("component %s: %w", component, err)
+		}
+		components[component] = &l
+	}
+
+	for name := range update.Redaction {
+		if _, ok := c.redactor.patterns[name]; !ok {
+			return ControlState{}, fmt.Errorf("unknown redaction pattern: %s", name)
+		}
+	}
+
+	var revertAfter time.Duration
+	if update.RevertAfter != "" {
+		d, err := time.ParseDuration(update.RevertAfter)
+		if err != nil || d <= 0 {
+			return ControlState{}, fmt.Errorf("invalid revert_after: %q must be a positive duration", update.RevertAfter)
+		}
+		revertAfter = d
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	current := c.levels.Load()
+	newLevel := current.level
+	if level != nil {
+		newLevel = *level
+	}
+	newComponents := make(map[string]slog.Level, len(current.components)+len(components))
+	for component, l := range current.components {
+		newComponents[component] = l
+	}
+	for component, l := range components {
+		if l == nil {
+			delete(newComponents, component)
+		} else {
+			newComponents[component] = *l
+		}
+	}
+	c.levels.Store(newControlLevels(newLevel, newComponents))
+
+	for name, enabled := range update.Redaction {
+		_ = c.redactor.SetPatternEnabled(name, enabled)
+	}
+	c.updateRedacting()
+
+	if revertAfter > 0 {
+		c.scheduleRevert(revertAfter)
+	}
+
+	return c.state(), nil
+}
+
+// Reset restores the configured settings, cancels any pending revert, and
+// returns the settings.
+func (c *Control) Reset() ControlState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.reset()
+	return c.state()
+}
+
+// Stop cancels any pending revert.
+func (c *Control) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cancelRevert()
+}
+
+// reset restores the configured settings. c.mu must be held.
+func (c *Control) reset() {
+	c.cancelRevert()
+
+	components := make(map[string]slog.Level, len(c.baseline.Components))
+	for component, name := range c.baseline.Components {
+		components[component], _ = parseLevel(name)
+	}
+	level, _ := parseLevel(c.baseline.Level)
+	c.levels.Store(newControlLevels(level, components))
+
+	for name, enabled := range c.baseline.Redaction {
+		_ = c.redactor.SetPatternEnabled(name, enabled)
+	}
+	c.updateRedacting()
+}
+
+// scheduleRevert replaces any pending revert with a revert after d.
+// c.mu must be held.
+func (c *Control) scheduleRevert(d time.Duration) {
+	c.cancelRevert()
+	c.revertGen++
+	gen := c.revertGen
+	c.revertAt = time.Now().Add(d).UTC()
+	c.revert = time.AfterFunc(d, func() { c.autoRevert(gen) })
+}
+
+// cancelRevert cancels any pending revert. c.mu must be held.
+func (c *Control) cancelRevert() {
+	if c.revert != nil {
+		c.revert.Stop()
+		c.revert = nil
+	}
+}
+
+// autoRevert restores the configured settings when the revert of
+// generation gen is still pending.
+func (c *Control) autoRevert(gen uint64) {
+	c.mu.Lock()
+	if c.revert == nil || c.revertGen != gen {
+		c.mu.Unlock()
+		return
+	}
+	c.reset()
+	state := c.state()
+	c.mu.Unlock()
+
+	// The default logger is resolved here as it is installed after the
+	// control is created.
+	slog.Default().Info("runtime logging settings reverted", "component", "logging.control", "level", state.Level)
+	audit.Log(context.Background(), audit.Event{
+		Type:    audit.EventConfigChange,
+		Reason:  "automatic revert",
+		Details: map[string]string{"setting": "logging", "action": "revert"},
+	})
+}
+
+// updateRedacting records whether any redaction pattern is enabled.
+func (c *Control) updateRedacting() {
+	redacting := false
+	for _, name := range c.redactor.PatternNames() {
+		if c.redactor.PatternEnabled(name) {
+			redacting = true
+			break
+		}
+	}
+	c.redacting.Store(redacting)
+}
+
+// Wrap returns a handler passing the lines enabled by the runtime levels
+// to next, redacted. next should accept all levels.
+func (c *Control) Wrap(next slog.Handler) slog.Handler {
+	return &controlHandler{next: next, control: c, leveled: true}
+}
+
+// Redact returns a handler passing every line to next, redacted, for
+// handlers with their own level, such as a message bus handler.
+func (c *Control) Redact(next slog.Handler) slog.Handler {
+	return &controlHandler{next: next, control: c}
+}
+
+// controlHandler is a slog.Handler applying the runtime settings of a
+// Control.
+type controlHandler struct {
+	next      slog.Handler
+	control   *Control
+	leveled   bool
+	component string
+	grouped   bool
+}
+
+// Enabled implements slog.Handler.
+func (h *controlHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	if h.leveled {
+		levels := h.control.levels.Load()
+		// The component of a line may be an attribute of the record,
+		// unknown until Handle.
+		if h.component != "" && !levels.enabled(h.component, level) {
+			return false
+		}
+		if level < levels.min {
+			return false
+		}
+	}
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler.
+func (h *controlHandler) Handle(ctx context.Context, record slog.Record) error {
+	if h.leveled {
+		component := h.component
+		if component == "" && !h.grouped {
+			component = recordComponent(record)
+		}
+		if !h.control.levels.Load().enabled(component, record.Level) {
+			return nil
+		}
+	}
+
+	if h.control.redacting.Load() {
+		redactor := h.control.redactor
+		redacted := slog.NewRecord(record.Time, record.Level, redactor.RedactString(record.Message), record.PC)
+		record.Attrs(func(attr slog.Attr) bool {
+			redacted.AddAttrs(redactor.RedactAttr(attr))
+			return true
+		})
+		record = redacted
+	}
+	return h.next.Handle(ctx, record)
+}
+
+// WithAttrs implements slog.Handler. The attributes are redacted with the
+// patterns enabled when the logger is derived.
+func (h *controlHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	if !h.grouped {
+		for _, attr := range attrs {
+			if attr.Key == ComponentKey {
+				clone.component = attr.Value.String()
+			}
+		}
+	}
+	if h.control.redacting.Load() {
+		redacted := make([]slog.Attr, len(attrs))
+		for i, attr := range attrs {
+			redacted[i] = h.control.redactor.RedactAttr(attr)
+		}
+		attrs = redacted
+	}
+	clone.next = h.next.WithAttrs(attrs)
+	return &clone
+}
+
+// WithGroup implements slog.Handler.
+func (h *controlHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.next = h.next.WithGroup(name)
+	clone.grouped = true
+	return &clone
+}
+
+// levelName returns the configuration name of level, e.g. "debug".
+func levelName(level slog.Level) string {
+	return strings.ToLower(level.String())
+}
@@ -0,0 +1,101 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"mercator-hq/jupiter/pkg/security/auth"
+	"mercator-hq/jupiter/pkg/telemetry/audit"
+)
+
+// maxControlRequestSize limits the size of control request bodies.
+const maxControlRequestSize = 64 << 10
+
+// Handler returns an HTTP handler for changing the logging settings at
+// runtime, e.g. when mounted at /admin/logging.
+//
+// GET returns the current ControlState. POST applies the ControlUpdate in
+// the JSON body and responds with the new settings. DELETE restores the
+// configured settings.
+//
+// The handler must be served behind admin authentication: requests without
+// an authenticated admin principal are rejected, and changes are recorded
+// in the audit log with the principal.
+func (c *Control) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		principal, ok := auth.AdminPrincipal(req.Context())
+		if !ok {
+			http.Error(w, "admin authentication required", http.StatusUnauthorized)
+			return
+		}
+
+		switch req.Method {
+		case http.MethodGet:
+			writeControlJSON(w, http.StatusOK, c.State())
+		case http.MethodPost:
+			var update ControlUpdate
+			if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, maxControlRequestSize)).Decode(&update); err != nil {
+				http.Error(w, fmt.Sprintf("invalid request body: %v", err), http.StatusBadRequest)
+				return
+			}
+			state, err := c.Update(&update)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			audit.Log(req.Context(), audit.Event{
+				Type:       audit.EventConfigChange,
+				Principal:  principal,
+				RemoteAddr: req.RemoteAddr,
+				Method:     req.Method,
+				Path:       req.URL.Path,
+				Details:    update.auditDetails(),
+			})
+			writeControlJSON(w, http.StatusOK, state)
+		case http.MethodDelete:
+			state := c.Reset()
+			audit.Log(req.Context(), audit.Event{
+				Type:       audit.EventConfigChange,
+				Principal:  principal,
+				RemoteAddr: req.RemoteAddr,
+				Method:     req.Method,
+				Path:       req.URL.Path,
+				Details:    map[string]string{"setting": "logging", "action": "reset"},
+			})
+			writeControlJSON(w, http.StatusOK, state)
+		default:
+			w.Header().Set("Allow", "GET, POST, DELETE")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+// auditDetails describes the change for the audit log, e.g.
+// "component.policy.engine": "debug" and "redaction.email": "false".
+func (u *ControlUpdate) auditDetails() map[string]string {
+	details := map[string]string{"setting": "logging", "action": "update"}
+	if u.Level != "" {
+		details["level"] = u.Level
+	}
+	for component, level := range u.Components {
+		if level == "" {
+			level = "default"
+		}
+		details["component."+component] = level
+	}
+	for name, enabled := range u.Redaction {
+		details["redaction."+name] = strconv.FormatBool(enabled)
+	}
+	if u.RevertAfter != "" {
+		details["revert_after"] = u.RevertAfter
+	}
+	return details
+}
+
+func writeControlJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,216 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/config"
+	"mercator-hq/jupiter/pkg/security/auth"
+	"mercator-hq/jupiter/pkg/telemetry/audit"
+)
+
+// newControlLogger returns a logger writing text lines to buf through
+// control.
+func newControlLogger(control *Control, buf *bytes.Buffer) *slog.Logger {
+	return slog.New(control.Wrap(slog.NewTextHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+}
+
+func TestControl_Levels(t *testing.T) {
+	control := NewControl(&config.LoggingConfig{Level: "info"})
+	defer control.Stop()
+
+	var buf bytes.Buffer
+	logger := newControlLogger(control, &buf)
+	engine := logger.With("component", "policy.engine")
+
+	logger.Debug("global debug")
+	engine.Debug("engine debug")
+	if buf.Len() != 0 {
+		t.Fatalf("debug lines logged at info level:\n%s", buf.String())
+	}
+
+	_, err := control.Update(&ControlUpdate{Components: map[string]string{"policy.engine": "debug", "limits": "error"}})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	logger.Debug("global debug")
+	engine.Debug("engine debug")
+	logger.Debug("record component debug", "component", "policy.engine")
+	logger.Warn("limits warning", "component", "limits")
+	if got := buf.String(); strings.Contains(got, "global debug") || strings.Contains(got, "limits warning") ||
+		!strings.Contains(got, "engine debug") || !strings.Contains(got, "record component debug") {
+		t.Errorf("output with component levels:\n%s", got)
+	}
+
+	state := control.Reset()
+	if state.Level != "info" || len(state.Components) != 0 {
+		t.Errorf("Reset() = %+v, want the configured settings", state)
+	}
+	buf.Reset()
+	engine.Debug("engine debug")
+	if buf.Len() != 0 {
+		t.Errorf("component level not reset:\n%s", buf.String())
+	}
+}
+
+func TestControl_Redaction(t *testing.T) {
+	control := NewControl(&config.LoggingConfig{Level: "info", RedactPII: true})
+	defer control.Stop()
+
+	var buf bytes.Buffer
+	logger := newControlLogger(control, &buf)
+
+	logger.Info("login by user@example.com", "client", "10.0.0.1", "error", errors.New("bad password for user@example.com"))
+	if got := buf.String(); strings.Contains(got, "user@example.com") || strings.Contains(got, "10.0.0.1") {
+		t.Errorf("PII not redacted:\n%s", got)
+	}
+
+	state, err := control.Update(&ControlUpdate{Redaction: map[string]bool{PatternEmail: false}})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if state.Redaction[PatternEmail] || !state.Redaction[PatternIPv4] {
+		t.Errorf("Redaction = %v, want email disabled only", state.Redaction)
+	}
+	buf.Reset()
+	logger.Info("login by user@example.com", "client", "10.0.0.1")
+	if got := buf.String(); !strings.Contains(got, "user@example.com") || strings.Contains(got, "10.0.0.1") {
+		t.Errorf("output with email redaction disabled:\n%s", got)
+	}
+}
+
+func TestControl_UpdateValidation(t *testing.T) {
+	control := NewControl(&config.LoggingConfig{Level: "info"})
+	defer control.Stop()
+
+	tests := []struct {
+		name   string
+		update ControlUpdate
+	}{
+		{"invalid level", ControlUpdate{Level: "verbose"}},
+		{"invalid component level", ControlUpdate{Level: "debug", Components: map[string]string{"limits": "trace"}}},
+		{"unknown pattern", ControlUpdate{Level: "debug", Redaction: map[string]bool{"passport": true}}},
+		{"invalid revert_after", ControlUpdate{Level: "debug", RevertAfter: "-5m"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := control.Update(&tt.update); err == nil {
+				t.Error("Update() succeeded, want error")
+			}
+			if state := control.State(); state.Level != "info" {
+				t.Errorf("Level = %q after a rejected update, want info", state.Level)
+			}
+		})
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for concurrent use.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestControl_RevertAfter(t *testing.T) {
+	var events syncBuffer
+	audit.SetDefault(audit.NewWithWriters(&events))
+	defer audit.SetDefault(nil)
+
+	control := NewControl(&config.LoggingConfig{Level: "warn"})
+	defer control.Stop()
+
+	state, err := control.Update(&ControlUpdate{Level: "debug", RevertAfter: "20ms"})
+	if err != nil {
+		t.Fatalf("Update() error = %v", err)
+	}
+	if state.Level != "debug" || state.RevertAt == nil {
+		t.Fatalf("Update() = %+v, want debug with a pending revert", state)
+	}
+
+	// The revert is audited after the settings are restored
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(events.String(), `"action":"revert"`) {
+		if time.Now().After(deadline) {
+			t.Fatalf("revert not audited: %s", events.String())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if state := control.State(); state.Level != "warn" || state.RevertAt != nil {
+		t.Errorf("State() = %+v after the revert, want warn without a pending revert", state)
+	}
+}
+
+func TestControl_Handler(t *testing.T) {
+	var events bytes.Buffer
+	audit.SetDefault(audit.NewWithWriters(&events))
+	defer audit.SetDefault(nil)
+
+	control := NewControl(&config.LoggingConfig{Level: "info"})
+	defer control.Stop()
+	handler := control.Handler()
+
+	serve := func(method, body string, admin bool) (*httptest.ResponseRecorder, ControlState) {
+		req := httptest.NewRequest(method, "/admin/logging", strings.NewReader(body))
+		if admin {
+			req = req.WithContext(auth.WithAdminPrincipal(req.Context(), "oncall"))
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		var state ControlState
+		json.Unmarshal(rec.Body.Bytes(), &state)
+		return rec, state
+	}
+
+	if rec, _ := serve(http.MethodGet, "", false); rec.Code != http.StatusUnauthorized {
+		t.Errorf("unauthenticated GET status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	rec, state := serve(http.MethodPost, `{"level":"debug","components":{"limits":"warn"},"revert_after":"1h"}`, true)
+	if rec.Code != http.StatusOK || state.Level != "debug" || state.Components["limits"] != "warn" || state.RevertAt == nil {
+		t.Fatalf("POST = %d %s", rec.Code, rec.Body.String())
+	}
+	var event audit.Event
+	if err := json.Unmarshal(events.Bytes(), &event); err != nil {
+		t.Fatalf("audit event: %v", err)
+	}
+	if event.Type != audit.EventConfigChange || event.Principal != "oncall" ||
+		event.Details["level"] != "debug" || event.Details["component.limits"] != "warn" || event.Details["revert_after"] != "1h" {
+		t.Errorf("audit event = %+v", event)
+	}
+
+	if rec, _ := serve(http.MethodPost, `{"level":"loud"}`, true); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid POST status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+
+	if _, state := serve(http.MethodGet, "", true); state.Level != "debug" {
+		t.Errorf("GET level = %q, want debug", state.Level)
+	}
+
+	rec, state = serve(http.MethodDelete, "", true)
+	if rec.Code != http.StatusOK || state.Level != "info" || len(state.Components) != 0 || state.RevertAt != nil {
+		t.Errorf("DELETE = %d %s", rec.Code, rec.Body.String())
+	}
+	if !strings.Contains(events.String(), `"action":"reset"`) {
+		t.Errorf("reset not audited: %s", events.String())
+	}
+}
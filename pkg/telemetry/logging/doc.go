@@ -40,6 +40,63 @@
 //   - IP addresses: 192.168.1.100 → 192.*.*.*
 //   - Credit cards: 4111-1111-1111-1111 → ****-****-****-1111
 //
+// # Sampling
+//
+// At thousands of requests per second, per-request lines dominate log
+// volume. SamplingHandler logs 1 in N debug and info lines per sampling
+// rule, matched on the message and on the "component" attribute of the
+// logger, while warnings and errors are always logged:
+//
+//	telemetry:
+//	  logging:
+//	    sampling:
+//	      enabled: true
+//	      rules:
+//	        - message: "request completed"
+//	          every: 100
+//	        - component: "evidence.recorder"
+//	          every: 10
+//
+// Lines matching no rule are logged. DroppedCount reports the lines
+// sampled out.
+//
+// # Runtime Changes
+//
+// Control changes the log level, per component levels, and redaction
+// patterns of the application logs without a restart. Handlers wrapped
+// with Control.Wrap drop the lines below the level of their component, or
+// the global level, and redact the others:
+//
+//	control := logging.NewControl(&cfg.Telemetry.Logging)
+//	logger := slog.New(control.Wrap(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: slog.LevelDebug})))
+//
+//	control.Update(&logging.ControlUpdate{
+//	    Components:  map[string]string{"policy.engine": "debug"},
+//	    Redaction:   map[string]bool{logging.PatternEmail: false},
+//	    RevertAfter: "15m",
+//	})
+//
+// Control.Handler serves the settings at /admin/logging, recording changes
+// and automatic reverts in the audit log.
+//
+// # Collector Outputs
+//
+// NewGELFHandler and NewSyslogHandler create handlers writing log lines for
+// Graylog and RFC 5424 syslog collectors, flattening attributes into named fields: GELF
+// additional fields, or the parameters of a structured data element. A
+// FieldMapping renames or drops attributes and adds static fields.
+//
+// Output sends one of these formats to a collector over UDP, TCP, or TLS,
+// from a background queue:
+//
+//	output, err := logging.NewOutput(&config.LogOutputConfig{
+//	    Type:    "gelf",
+//	    Network: "udp",
+//	    Address: "graylog:12201",
+//	    Fields:  map[string]string{"request_id": "correlation_id"},
+//	}, nil)
+//	logger := slog.New(control.Wrap(output.Handler()))
+//
 // # Performance
 //
 // Async buffering ensures logging doesn't block request processing:
@@ -87,6 +87,10 @@ type Config struct {
 	// RedactPatterns contains custom PII redaction patterns
 	RedactPatterns []config.RedactPattern
 
+	// Sampling contains the sampling rules of debug and info lines (see
+	// SamplingHandler)
+	Sampling []config.LogSamplingRuleConfig
+
 	// Writer is the output writer (defaults to os.Stdout)
 	Writer io.Writer
 }
@@ -150,6 +154,11 @@ func New(cfg Config) (*Logger, error) {
 		handler = slog.NewJSONHandler(buffer, opts)
 	}
 
+	// Sample high-volume lines
+	if len(cfg.Sampling) > 0 {
+		handler = NewSamplingHandler(handler, cfg.Sampling)
+	}
+
 	// Create logger
 	logger := &Logger{
 		slog:      slog.New(handler),
@@ -0,0 +1,27 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+func TestMultiHandler(t *testing.T) {
+	var info, warn bytes.Buffer
+	logger := slog.New(NewMultiHandler(
+		slog.NewTextHandler(&info, &slog.HandlerOptions{Level: slog.LevelInfo}),
+		slog.NewTextHandler(&warn, &slog.HandlerOptions{Level: slog.LevelWarn}),
+	)).With("component", "test")
+
+	logger.Debug("debug line")
+	logger.Info("info line")
+	logger.Warn("warn line")
+
+	if got := strings.Count(info.String(), "\n"); got != 2 {
+		t.Errorf("info handler lines = %d, want 2:\n%s", got, info.String())
+	}
+	if got := warn.String(); strings.Count(got, "\n") != 1 || !strings.Contains(got, "warn line") || !strings.Contains(got, "component=test") {
+		t.Errorf("warn handler output = %q, want only the warn line with its attributes", got)
+	}
+}
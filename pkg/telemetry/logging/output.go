@@ -0,0 +1,257 @@
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"mercator-hq/jupiter/pkg/config"
+	"mercator-hq/jupiter/pkg/telemetry/bus"
+)
+
+// GELF UDP chunking limits.
+const (
+	// gelfChunkSize is the maximum datagram size, safe for WAN paths.
+	gelfChunkSize = 1420
+
+	// gelfMaxChunks is the maximum number of chunks of a message.
+	gelfMaxChunks = 128
+)
+
+// outputDialTimeout bounds connection establishment to a collector.
+const outputDialTimeout = 5 * time.Second
+
+// Output sends logs to a GELF or syslog collector. Lines are queued and
+// delivered from a background goroutine, so logging never waits on the
+// collector; lines are dropped when the queue is full.
+type Output struct {
+	handler slog.Handler
+	shipper *bus.Shipper
+}
+
+// NewOutput creates an output for cfg. tlsConfig is the client TLS
+// configuration of the "tls" network; system roots are used if nil.
+//
+// The handler of an output without a level accepts every level, to be
+// wrapped with Control.Wrap; otherwise it accepts the configured level and
+// above, to be wrapped with Control.Redact.
+func NewOutput(cfg *config.LogOutputConfig, tlsConfig *tls.Config) (*Output, error) {
+	if _, _, err := net.SplitHostPort(cfg.Address); err != nil {
+		return nil, fmt.Errorf("invalid log output address %q: %w", cfg.Address, err)
+	}
+	network := cfg.Network
+	switch network {
+	case "":
+		network = "udp"
+	case "udp", "tcp", "tls":
+	default:
+		return nil, fmt.Errorf("unsupported log output network %q", cfg.Network)
+	}
+
+	level := slog.LevelDebug
+	if cfg.Level != "" {
+		var err error
+		if level, err = parseLevel(cfg.Level); err != nil {
+			return nil, err
+		}
+	}
+	mapping := FieldMapping{Rename: cfg.Fields, Static: cfg.StaticFields}
+
+	conn := &collectorConn{network: network, address: cfg.Address, tlsConfig: tlsConfig}
+	o := &Output{}
+	switch cfg.Type {
+	case "gelf":
+		conn.name = "gelf"
+		if network == "udp" {
+			conn.frame = gelfChunks
+		} else {
+			// GELF over TCP is delimited by a null byte
+			conn.frame = delimitFrame(0)
+		}
+		o.shipper = bus.NewShipper(nil, conn)
+		o.handler = NewGELFHandler(o.shipper, &GELFOptions{Level: level, Fields: mapping})
+	case "syslog":
+		conn.name = "syslog"
+		if network == "udp" {
+			conn.frame = datagramFrame
+		} else {
+			// Octet counting, as required over TLS by RFC 5425
+			conn.frame = octetCountingFrame
+		}
+		o.shipper = bus.NewShipper(nil, conn)
+		o.handler = NewSyslogHandler(o.shipper, &SyslogOptions{
+			Level:    level,
+			Facility: cfg.Facility,
+			AppName:  cfg.AppName,
+			Fields:   mapping,
+		})
+	default:
+		return nil, fmt.Errorf("unsupported log output type %q", cfg.Type)
+	}
+
+	return o, nil
+}
+
+// Handler returns the handler writing to the collector.
+func (o *Output) Handler() slog.Handler {
+	return o.handler
+}
+
+// Stats returns the delivery counters of the output.
+func (o *Output) Stats() bus.Stats {
+	return o.shipper.Stats()
+}
+
+// Close delivers the queued lines and closes the collector connection.
+func (o *Output) Close() error {
+	return o.shipper.Close()
+}
+
+// collectorConn is a bus.Publisher writing messages to a collector
+// connection. The connection is established lazily and re-established
+// after a failure on the next batch.
+type collectorConn struct {
+	name      string
+	network   string
+	address   string
+	tlsConfig *tls.Config
+
+	// frame returns the packets of a message: datagrams over UDP, or the
+	// framed message over a stream.
+	frame func(message []byte) ([][]byte, error)
+
+	// mu serializes writes and guards conn.
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// Name implements bus.Publisher.
+func (c *collectorConn) Name() string {
+	return c.name + ":" + c.network + "://" + c.address
+}
+
+// Publish implements bus.Publisher.
+func (c *collectorConn) Publish(ctx context.Context, messages [][]byte) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		if err := c.connect(ctx); err != nil {
+			return err
+		}
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		c.conn.SetWriteDeadline(deadline)
+	}
+
+	var frameErr error
+	for _, message := range messages {
+		packets, err := c.frame(message)
+		if err != nil {
+			frameErr = err
+			continue
+		}
+		for _, packet := range packets {
+			if _, err := c.conn.Write(packet); err != nil {
+				c.conn.Close()
+				c.conn = nil
+				return fmt.Errorf("%s write failed: %w", c.name, err)
+			}
+		}
+	}
+	return frameErr
+}
+
+// Close implements bus.Publisher.
+func (c *collectorConn) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
+
+// connect dials the collector.
+func (c *collectorConn) connect(ctx context.Context) error {
+	dialer := &net.Dialer{Timeout: outputDialTimeout}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	if c.network == "tls" {
+		tlsConfig := c.tlsConfig
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+		}
+		tlsDialer := &tls.Dialer{NetDialer: dialer, Config: tlsConfig}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", c.address)
+	} else {
+		conn, err = dialer.DialContext(ctx, c.network, c.address)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to connect to %s collector %s: %w", c.name, c.address, err)
+	}
+
+	c.conn = conn
+	return nil
+}
+
+// datagramFrame sends a message as a single datagram.
+func datagramFrame(message []byte) ([][]byte, error) {
+	return [][]byte{message}, nil
+}
+
+// delimitFrame returns a framing terminating messages with delimiter.
+func delimitFrame(delimiter byte) func([]byte) ([][]byte, error) {
+	return func(message []byte) ([][]byte, error) {
+		packet := make([]byte, 0, len(message)+1)
+		return [][]byte{append(append(packet, message...), delimiter)}, nil
+	}
+}
+
+// octetCountingFrame prefixes a message with its length (RFC 6587).
+func octetCountingFrame(message []byte) ([][]byte, error) {
+	packet := make([]byte, 0, len(message)+8)
+	packet = strconv.AppendInt(packet, int64(len(message)), 10)
+	packet = append(packet, ' ')
+	return [][]byte{append(packet, message...)}, nil
+}
+
+// gelfChunks splits a GELF message into UDP datagrams. Messages that fit
+// in one datagram are sent as is; larger ones are chunked with a random
+// message ID, and rejected beyond the 128 chunks a collector accepts.
+func gelfChunks(message []byte) ([][]byte, error) {
+	if len(message) <= gelfChunkSize {
+		return [][]byte{message}, nil
+	}
+
+	const headerSize = 12 // magic, message ID, sequence number and count
+	dataSize := gelfChunkSize - headerSize
+	count := (len(message) + dataSize - 1) / dataSize
+	if count > gelfMaxChunks {
+		return nil, fmt.Errorf("gelf message of %d bytes exceeds %d chunks", len(message), gelfMaxChunks)
+	}
+
+	var id [8]byte
+	rand.Read(id[:])
+	chunks := make([][]byte, 0, count)
+	for seq := 0; seq < count; seq++ {
+		data := message[seq*dataSize : min((seq+1)*dataSize, len(message))]
+		chunk := make([]byte, 0, headerSize+len(data))
+		chunk = append(chunk, 0x1e, 0x0f)
+		chunk = append(chunk, id[:]...)
+		chunk = append(chunk, byte(seq), byte(count))
+		chunks = append(chunks, append(chunk, data...))
+	}
+	return chunks, nil
+}
@@ -0,0 +1,251 @@
+package logging
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/config"
+)
+
+// writeRecorder records each Write as a message.
+type writeRecorder struct {
+	messages [][]byte
+}
+
+func (w *writeRecorder) Write(p []byte) (int, error) {
+	w.messages = append(w.messages, bytes.Clone(p))
+	return len(p), nil
+}
+
+func TestGELFHandler(t *testing.T) {
+	var w writeRecorder
+	handler := NewGELFHandler(&w, &GELFOptions{
+		Host: "proxy-1",
+		Fields: FieldMapping{
+			Rename: map[string]string{"request_id": "correlation_id", "user": "", "http.status": "status"},
+			Static: map[string]string{"environment": "production"},
+		},
+	})
+
+	logger := slog.New(handler).With("component", "proxy.server", "id", 7)
+	logger.Debug("not written")
+	logger.WithGroup("http").Warn("request failed",
+		"status", 502,
+		"latency", 1.5,
+		"request_id", "req-1",
+		"err", errors.New("upstream reset"),
+		slog.Group("tls", "version", "1.3"),
+	)
+	slog.New(handler).Info("login", "request_id", "req-2", "user", "alice")
+
+	if len(w.messages) != 2 {
+		t.Fatalf("wrote %d messages, want 2", len(w.messages))
+	}
+
+	var message map[string]any
+	if err := json.Unmarshal(w.messages[0], &message); err != nil {
+		t.Fatalf("invalid GELF message: %v", err)
+	}
+	want := map[string]any{
+		"version":           "1.1",
+		"host":              "proxy-1",
+		"short_message":     "request failed",
+		"level":             float64(4),
+		"_component":        "proxy.server",
+		"__id":              float64(7),
+		"_status":           float64(502),
+		"_http.latency":     1.5,
+		"_http.request_id":  "req-1",
+		"_http.err":         "upstream reset",
+		"_http.tls.version": "1.3",
+		"_environment":      "production",
+	}
+	for key, value := range want {
+		if message[key] != value {
+			t.Errorf("%s = %v, want %v", key, message[key], value)
+		}
+	}
+	if _, ok := message["timestamp"].(float64); !ok {
+		t.Errorf("timestamp = %v, want seconds", message["timestamp"])
+	}
+
+	message = nil
+	if err := json.Unmarshal(w.messages[1], &message); err != nil {
+		t.Fatalf("invalid GELF message: %v", err)
+	}
+	if message["_correlation_id"] != "req-2" || message["_user"] != nil {
+		t.Errorf("message = %v, want request_id renamed and user dropped", message)
+	}
+}
+
+func TestSyslogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewSyslogHandler(&buf, &SyslogOptions{
+		Level:    slog.LevelDebug,
+		Facility: 16,
+		Host:     "proxy-1",
+		AppName:  "mercator",
+		Fields:   FieldMapping{Static: map[string]string{"env": "prod"}},
+	}).WithAttrs([]slog.Attr{slog.String("component", "proxy.server")})
+
+	record := slog.NewRecord(time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC), slog.LevelInfo, "request completed", 0)
+	record.AddAttrs(slog.Int("status", 200), slog.String("path", `/v1/"chat"]`))
+	if err := handler.Handle(context.Background(), record); err != nil {
+		t.Fatalf("Handle() error = %v", err)
+	}
+
+	want := `<134>1 2025-01-02T15:04:05.000000Z proxy-1 mercator ` + strconv.Itoa(os.Getpid()) +
+		` proxy.server [mercator@32473 component="proxy.server" status="200" path="/v1/\"chat\"\]" env="prod"] request completed`
+	if got := buf.String(); got != want {
+		t.Errorf("message =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestGELFChunks(t *testing.T) {
+	small := []byte(`{"short_message":"a"}`)
+	if chunks, err := gelfChunks(small); err != nil || len(chunks) != 1 || !bytes.Equal(chunks[0], small) {
+		t.Errorf("gelfChunks(small) = %d chunks, %v, want the message as is", len(chunks), err)
+	}
+
+	large := bytes.Repeat([]byte("x"), 3*gelfChunkSize)
+	chunks, err := gelfChunks(large)
+	if err != nil {
+		t.Fatalf("gelfChunks() error = %v", err)
+	}
+	var joined []byte
+	for i, chunk := range chunks {
+		if len(chunk) > gelfChunkSize || chunk[0] != 0x1e || chunk[1] != 0x0f {
+			t.Fatalf("chunk %d has %d bytes and magic %x", i, len(chunk), chunk[:2])
+		}
+		if !bytes.Equal(chunk[2:10], chunks[0][2:10]) || int(chunk[10]) != i || int(chunk[11]) != len(chunks) {
+			t.Errorf("chunk %d header = %x", i, chunk[:12])
+		}
+		joined = append(joined, chunk[12:]...)
+	}
+	if !bytes.Equal(joined, large) {
+		t.Error("chunks do not reassemble into the message")
+	}
+
+	if _, err := gelfChunks(make([]byte, gelfMaxChunks*gelfChunkSize)); err == nil {
+		t.Error("gelfChunks() accepted a message beyond the chunk limit")
+	}
+}
+
+func TestOutput_SyslogTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		// Octet counting: "LEN SP MSG"
+		var messages []string
+		reader := bufio.NewReader(conn)
+		for {
+			length, err := reader.ReadString(' ')
+			if err != nil {
+				break
+			}
+			n, _ := strconv.Atoi(strings.TrimSpace(length))
+			message := make([]byte, n)
+			if _, err := io.ReadFull(reader, message); err != nil {
+				break
+			}
+			messages = append(messages, string(message))
+		}
+		received <- messages
+	}()
+
+	output, err := NewOutput(&config.LogOutputConfig{
+		Type:    "syslog",
+		Network: "tcp",
+		Address: listener.Addr().String(),
+		Level:   "info",
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewOutput() error = %v", err)
+	}
+	logger := slog.New(output.Handler())
+	logger.Debug("not sent")
+	logger.Info("first line")
+	logger.Error("second\nline", "component", "proxy")
+	if err := output.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	select {
+	case messages := <-received:
+		if len(messages) != 2 || !strings.HasPrefix(messages[0], "<134>1 ") || !strings.HasSuffix(messages[1], " proxy [mercator@32473 component=\"proxy\"] second\nline") {
+			t.Errorf("messages = %q", messages)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("collector received nothing")
+	}
+	if stats := output.Stats(); stats.Shipped != 2 {
+		t.Errorf("Stats() = %+v, want 2 shipped", stats)
+	}
+}
+
+func TestOutput_GELFUDP(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	output, err := NewOutput(&config.LogOutputConfig{
+		Type:         "gelf",
+		Network:      "udp",
+		Address:      conn.LocalAddr().String(),
+		StaticFields: map[string]string{"environment": "test"},
+	}, nil)
+	if err != nil {
+		t.Fatalf("NewOutput() error = %v", err)
+	}
+	slog.New(output.Handler()).Info("hello", "tenant", "team-a")
+	output.Close()
+
+	buf := make([]byte, 2*gelfChunkSize)
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("no datagram received: %v", err)
+	}
+	var message map[string]any
+	if err := json.Unmarshal(buf[:n], &message); err != nil {
+		t.Fatalf("invalid GELF datagram %q: %v", buf[:n], err)
+	}
+	if message["short_message"] != "hello" || message["_tenant"] != "team-a" || message["_environment"] != "test" {
+		t.Errorf("message = %v", message)
+	}
+}
+
+func TestNewOutput_InvalidConfig(t *testing.T) {
+	for _, cfg := range []*config.LogOutputConfig{
+		{Type: "fluentd", Address: "localhost:24224"},
+		{Type: "gelf", Address: "localhost"},
+		{Type: "syslog", Network: "quic", Address: "localhost:514"},
+	} {
+		if _, err := NewOutput(cfg, nil); err == nil {
+			t.Errorf("NewOutput(%+v) succeeded, want error", cfg)
+		}
+	}
+}
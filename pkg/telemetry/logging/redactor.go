@@ -2,16 +2,24 @@ package logging
 
 import (
 	"fmt"
+	"log/slog"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
 
 	"mercator-hq/jupiter/pkg/config"
 )
 
 // Redactor redacts PII (Personally Identifiable Information) from log fields.
+// Patterns can be disabled and enabled at runtime.
 type Redactor struct {
 	patterns map[string]*redactPattern
 	enabled  bool
+
+	// mu guards disabled
+	mu       sync.RWMutex
+	disabled map[string]bool
 }
 
 // redactPattern contains a compiled regex and replacement string.
@@ -39,6 +47,7 @@ func NewRedactor(customPatterns []config.RedactPattern) *Redactor {
 	r := &Redactor{
 		patterns: make(map[string]*redactPattern),
 		enabled:  true,
+		disabled: make(map[string]bool),
 	}
 
 	// Add default patterns
@@ -139,14 +148,80 @@ func (r *Redactor) RedactString(value string) string {
 		return value
 	}
 
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
 	redacted := value
-	for _, pattern := range r.patterns {
+	for name, pattern := range r.patterns {
+		if r.disabled[name] {
+			continue
+		}
 		redacted = pattern.regex.ReplaceAllString(redacted, pattern.replacement)
 	}
 
 	return redacted
 }
 
+// RedactAttr redacts PII from the string values of a log attribute,
+// including the attributes of groups and the messages of errors.
+func (r *Redactor) RedactAttr(attr slog.Attr) slog.Attr {
+	attr.Value = attr.Value.Resolve()
+	switch attr.Value.Kind() {
+	case slog.KindString:
+		attr.Value = slog.StringValue(r.RedactString(attr.Value.String()))
+	case slog.KindGroup:
+		group := attr.Value.Group()
+		attrs := make([]slog.Attr, len(group))
+		for i, a := range group {
+			attrs[i] = r.RedactAttr(a)
+		}
+		attr.Value = slog.GroupValue(attrs...)
+	case slog.KindAny:
+		if err, ok := attr.Value.Any().(error); ok {
+			msg := err.Error()
+			if redacted := r.RedactString(msg); redacted != msg {
+				attr.Value = slog.StringValue(redacted)
+			}
+		}
+	}
+	return attr
+}
+
+// PatternNames returns the names of the patterns, sorted.
+func (r *Redactor) PatternNames() []string {
+	names := make([]string, 0, len(r.patterns))
+	for name := range r.patterns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PatternEnabled reports whether the named pattern is enabled.
+func (r *Redactor) PatternEnabled(name string) bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	_, ok := r.patterns[name]
+	return ok && !r.disabled[name]
+}
+
+// SetPatternEnabled enables or disables the named pattern. It returns an
+// error if there is no such pattern.
+func (r *Redactor) SetPatternEnabled(name string, enabled bool) error {
+	if _, ok := r.patterns[name]; !ok {
+		return fmt.Errorf("unknown redaction pattern: %s", name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if enabled {
+		delete(r.disabled, name)
+	} else {
+		r.disabled[name] = true
+	}
+	return nil
+}
+
 // RedactArgs redacts PII from variadic log arguments.
 // Args are in the form: key1, value1, key2, value2, ...
 func (r *Redactor) RedactArgs(args ...any) []any {
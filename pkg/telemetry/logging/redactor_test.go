@@ -511,6 +511,31 @@ func TestRedactor_CustomPatterns(t *testing.T) {
 	}
 }
 
+func TestRedactor_SetPatternEnabled(t *testing.T) {
+	redactor := NewRedactor(nil)
+	input := "contact user@example.com from 10.0.0.1"
+
+	if err := redactor.SetPatternEnabled(PatternEmail, false); err != nil {
+		t.Fatalf("SetPatternEnabled() error = %v", err)
+	}
+	result := redactor.RedactString(input)
+	if !containsStr(result, "user@example.com") || containsStr(result, "10.0.0.1") {
+		t.Errorf("RedactString() = %q, want only the IP address redacted", result)
+	}
+	if redactor.PatternEnabled(PatternEmail) || !redactor.PatternEnabled(PatternIPv4) {
+		t.Error("PatternEnabled() does not reflect the disabled email pattern")
+	}
+
+	redactor.SetPatternEnabled(PatternEmail, true)
+	if result := redactor.RedactString(input); containsStr(result, "user@example.com") {
+		t.Errorf("RedactString() = %q, want the email redacted again", result)
+	}
+
+	if err := redactor.SetPatternEnabled("unknown", false); err == nil {
+		t.Error("SetPatternEnabled() with an unknown pattern succeeded")
+	}
+}
+
 // Helper functions
 func hasPrefix(s, prefix string) bool {
 	return len(s) >= len(prefix) && s[:len(prefix)] == prefix
@@ -0,0 +1,134 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+
+	"mercator-hq/jupiter/pkg/config"
+)
+
+// ComponentKey is the attribute that names the component of a log line,
+// as in slog.Default().With("component", "evidence.recorder").
+const ComponentKey = "component"
+
+// samplingRule is a sampling rule with its count of matching lines.
+type samplingRule struct {
+	component string
+	message   string
+	every     uint64
+	seen      atomic.Uint64
+}
+
+// SamplingHandler is a slog.Handler that logs 1 in N debug and info lines
+// per sampling rule and passes the others on. Warnings and errors are never
+// sampled. Lines are matched on their message and on the "component"
+// attribute of their logger or record.
+type SamplingHandler struct {
+	next      slog.Handler
+	rules     []*samplingRule
+	component string
+	grouped   bool
+	dropped   *atomic.Int64
+}
+
+// NewSamplingHandler creates a handler that samples the lines of next
+// according to rules. Rules with Every below 2 log every line.
+func NewSamplingHandler(next slog.Handler, rules []config.LogSamplingRuleConfig) *SamplingHandler {
+	h := &SamplingHandler{
+		next:    next,
+		dropped: &atomic.Int64{},
+	}
+	for _, rule := range rules {
+		every := uint64(1)
+		if rule.Every > 1 {
+			every = uint64(rule.Every)
+		}
+		h.rules = append(h.rules, &samplingRule{
+			component: rule.Component,
+			message:   rule.Message,
+			every:     every,
+		})
+	}
+	return h
+}
+
+// Enabled implements slog.Handler.
+func (h *SamplingHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+// Handle implements slog.Handler. It drops the lines sampled out.
+func (h *SamplingHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level < slog.LevelWarn && !h.sample(record) {
+		h.dropped.Add(1)
+		return nil
+	}
+	return h.next.Handle(ctx, record)
+}
+
+// sample returns true if record is to be logged under the first rule it
+// matches.
+func (h *SamplingHandler) sample(record slog.Record) bool {
+	component := h.component
+	if component == "" && !h.grouped {
+		component = recordComponent(record)
+	}
+
+	for _, rule := range h.rules {
+		if rule.component != "" && rule.component != component {
+			continue
+		}
+		if rule.message != "" && rule.message != record.Message {
+			continue
+		}
+		return (rule.seen.Add(1)-1)%rule.every == 0
+	}
+	return true
+}
+
+// recordComponent returns the "component" attribute of record, if any.
+func recordComponent(record slog.Record) string {
+	var component string
+	record.Attrs(func(attr slog.Attr) bool {
+		if attr.Key == ComponentKey {
+			component = attr.Value.String()
+			return false
+		}
+		return true
+	})
+	return component
+}
+
+// WithAttrs implements slog.Handler. It keeps track of the component of
+// the logger.
+func (h *SamplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.next = h.next.WithAttrs(attrs)
+	if !h.grouped {
+		for _, attr := range attrs {
+			if attr.Key == ComponentKey {
+				clone.component = attr.Value.String()
+			}
+		}
+	}
+	return &clone
+}
+
+// WithGroup implements slog.Handler. Attributes of groups do not name the
+// component.
+func (h *SamplingHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.next = h.next.WithGroup(name)
+	clone.grouped = true
+	return &clone
+}
+
+// DroppedCount returns the number of lines sampled out by the handler and
+// the handlers derived from it.
+func (h *SamplingHandler) DroppedCount() int64 {
+	return h.dropped.Load()
+}
@@ -0,0 +1,74 @@
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"mercator-hq/jupiter/pkg/config"
+)
+
+func TestSamplingHandler(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewSamplingHandler(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}), []config.LogSamplingRuleConfig{
+		{Message: "request completed", Every: 100},
+		{Component: "evidence.recorder", Every: 10},
+	})
+	logger := slog.New(handler)
+	recorder := logger.With("component", "evidence.recorder")
+
+	for i := 0; i < 250; i++ {
+		logger.Info("request completed", "status", 200)
+		recorder.Debug("record stored")
+		logger.Info("other line")
+	}
+	logger.Warn("request completed", "status", 500)
+	recorder.Error("write failed")
+	logger.Info("record stored", "component", "evidence.recorder")
+
+	counts := map[string]int{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		switch {
+		case strings.Contains(line, "level=WARN"), strings.Contains(line, "level=ERROR"):
+			counts["warn/error"]++
+		case strings.Contains(line, `msg="request completed"`):
+			counts["request completed"]++
+		case strings.Contains(line, `msg="record stored"`):
+			counts["record stored"]++
+		case strings.Contains(line, `msg="other line"`):
+			counts["other line"]++
+		}
+	}
+
+	want := map[string]int{
+		"request completed": 3,   // lines 1, 101 and 201
+		"record stored":     26,  // 1 in 10 of 251 lines
+		"other line":        250, // matches no rule
+		"warn/error":        2,   // never sampled
+	}
+	for key, n := range want {
+		if counts[key] != n {
+			t.Errorf("%s: logged %d lines, want %d", key, counts[key], n)
+		}
+	}
+	if got, want := handler.DroppedCount(), int64(250-3+251-26); got != want {
+		t.Errorf("DroppedCount() = %d, want %d", got, want)
+	}
+}
+
+func TestSamplingHandler_Groups(t *testing.T) {
+	var buf bytes.Buffer
+	handler := NewSamplingHandler(slog.NewTextHandler(&buf, nil), []config.LogSamplingRuleConfig{
+		{Component: "limits", Every: 1000},
+	})
+
+	// A component attribute within a group does not name the component
+	logger := slog.New(handler).WithGroup("request").With("component", "limits")
+	logger.Info("first")
+	logger.Info("second")
+
+	if n := strings.Count(buf.String(), "msg="); n != 2 {
+		t.Errorf("logged %d lines, want 2:\n%s", n, buf.String())
+	}
+}
@@ -38,8 +38,17 @@ type Collector struct {
 	// Cache metrics (optional, if caching is implemented)
 	cacheMetrics *CacheMetrics
 
+	// SLO good/bad request counters, nil if no objectives are configured
+	sloMetrics *SLOMetrics
+
+	// Per-rule latency aggregates for the slow-rules view
+	ruleLatency *RuleLatencyTracker
+
 	// Cardinality tracking
 	cardinalityLimiter *CardinalityLimiter
+
+	// Cardinality budget of the tenant labels, nil if they are disabled
+	tenantBudget *tenantBudget
 }
 
 // NewCollector creates a new metrics collector with the specified configuration
@@ -78,15 +87,29 @@ func NewCollector(cfg *config.MetricsConfig, registry *prometheus.Registry) *Col
 	c := &Collector{
 		config:             cfg,
 		registry:           registry,
+		ruleLatency:        NewRuleLatencyTracker(10000),
 		cardinalityLimiter: NewCardinalityLimiter(10000), // Max 10K unique label sets
 	}
 
+	if cfg.TenantLabels.Enabled {
+		if cfg.TenantLabels.MaxValues <= 0 {
+			cfg.TenantLabels.MaxValues = config.DefaultMetricsTenantValues
+		}
+		c.tenantBudget = newTenantBudget(cfg.TenantLabels.MaxValues)
+	}
+
 	// Initialize metric subsystems
 	c.requestMetrics = NewRequestMetrics(cfg, registry)
 	c.providerMetrics = NewProviderMetrics(cfg, registry)
 	c.policyMetrics = NewPolicyMetrics(cfg, registry)
 	c.costMetrics = NewCostMetrics(cfg, registry)
 	c.cacheMetrics = NewCacheMetrics(cfg, registry)
+	if len(cfg.SLO.Objectives) > 0 {
+		c.sloMetrics = NewSLOMetrics(cfg, registry)
+	}
+
+	// Go runtime and process metrics
+	registerRuntimeMetrics(cfg.Namespace, registry)
 
 	return c
 }
@@ -112,6 +135,27 @@ func NewCollector(cfg *config.MetricsConfig, registry *prometheus.Registry) *Col
 //		0.05,
 //	)
 func (c *Collector) RecordRequest(provider, model, status string, duration time.Duration, tokens int, cost float64) {
+	c.RecordTenantRequest("", "", provider, model, status, duration, tokens, cost)
+}
+
+// RecordTenantRequest records metrics for a completed request of a tenant
+// and team. The tenant and team are recorded as labels if tenant labels
+// are enabled, within the cardinality budget of each label; further
+// tenants and teams are recorded as "other".
+//
+// Example:
+//
+//	collector.RecordTenantRequest(
+//		"acme",
+//		"search",
+//		"openai",
+//		"gpt-4",
+//		"success",
+//		1200*time.Millisecond,
+//		1500,
+//		0.05,
+//	)
+func (c *Collector) RecordTenantRequest(tenant, team, provider, model, status string, duration time.Duration, tokens int, cost float64) {
 	if !c.config.Enabled {
 		return
 	}
@@ -123,8 +167,12 @@ func (c *Collector) RecordRequest(provider, model, status string, duration time.
 		model = "other"
 	}
 
-	c.requestMetrics.RecordRequest(provider, model, status, duration, tokens)
-	c.costMetrics.RecordRequestCost(provider, model, cost)
+	labels := c.tenantValues(tenant, team)
+	c.requestMetrics.recordRequest(labels, provider, model, status, duration, tokens)
+	c.costMetrics.recordRequestCost(labels, provider, model, cost)
+	if c.sloMetrics != nil {
+		c.sloMetrics.RecordRequest(status, duration)
+	}
 }
 
 // RecordProviderLatency records the latency for a provider API call.
@@ -215,6 +263,77 @@ func (c *Collector) RecordPolicyMiss(ruleID string) {
 	c.policyMetrics.RecordMiss(ruleID)
 }
 
+// RecordRuleEvaluation records the evaluation of a single policy rule.
+// It implements engine.MetricsRecorder.
+//
+// Parameters:
+//   - policyID: Policy identifier
+//   - ruleID: Rule identifier within the policy
+//   - matched: Whether the rule's conditions matched
+//   - duration: Rule evaluation duration
+func (c *Collector) RecordRuleEvaluation(policyID, ruleID string, matched bool, duration time.Duration) {
+	c.RecordTenantRuleEvaluation("", "", policyID, ruleID, matched, duration)
+}
+
+// RecordTenantRuleEvaluation records the evaluation of a policy rule for a
+// request of a tenant and team. It implements engine.TenantMetricsRecorder.
+func (c *Collector) RecordTenantRuleEvaluation(tenant, team, policyID, ruleID string, matched bool, duration time.Duration) {
+	if !c.config.Enabled {
+		return
+	}
+
+	c.ruleLatency.Observe(policyID, ruleID, matched, duration)
+
+	// Check cardinality limit
+	if !c.cardinalityLimiter.Allow(fmt.Sprintf("rule:%s:%s", policyID, ruleID)) {
+		policyID, ruleID = "other", "other"
+	}
+
+	c.policyMetrics.recordRuleEvaluation(c.tenantValues(tenant, team), policyID, ruleID, matched, duration)
+}
+
+// RecordActionExecution records the execution of a policy rule action.
+// It implements engine.MetricsRecorder.
+//
+// Parameters:
+//   - policyID: Policy identifier
+//   - ruleID: Rule identifier within the policy
+//   - actionType: Action type ("deny", "redact", "route", ...)
+//   - success: Whether the action executed successfully
+func (c *Collector) RecordActionExecution(policyID, ruleID, actionType string, success bool) {
+	c.RecordTenantActionExecution("", "", policyID, ruleID, actionType, success)
+}
+
+// RecordTenantActionExecution records the execution of a policy rule action
+// for a request of a tenant and team. It implements
+// engine.TenantMetricsRecorder.
+func (c *Collector) RecordTenantActionExecution(tenant, team, policyID, ruleID, actionType string, success bool) {
+	if !c.config.Enabled {
+		return
+	}
+
+	// Check cardinality limit
+	if !c.cardinalityLimiter.Allow(fmt.Sprintf("rule:%s:%s", policyID, ruleID)) {
+		policyID, ruleID = "other", "other"
+	}
+
+	c.policyMetrics.recordActionExecution(c.tenantValues(tenant, team), policyID, ruleID, actionType, success)
+}
+
+// RecordPolicyDuration records the time spent evaluating a policy.
+// It implements engine.MetricsRecorder.
+//
+// Parameters:
+//   - policyID: Policy identifier
+//   - duration: Policy evaluation duration
+func (c *Collector) RecordPolicyDuration(policyID string, duration time.Duration) {
+	if !c.config.Enabled {
+		return
+	}
+
+	c.policyMetrics.RecordPolicyDuration(policyID, duration)
+}
+
 // RecordCacheHit records a cache hit.
 //
 // Parameters:
@@ -263,6 +382,15 @@ func (c *Collector) Registry() *prometheus.Registry {
 	return c.registry
 }
 
+// tenantValues returns the tenant label values of a tenant and team, or
+// none if tenant labels are disabled.
+func (c *Collector) tenantValues(tenant, team string) []string {
+	if c.tenantBudget == nil {
+		return nil
+	}
+	return c.tenantBudget.values(tenant, team)
+}
+
 // CardinalityLimiter prevents metric cardinality explosion by limiting
 // the number of unique label combinations per metric.
 type CardinalityLimiter struct {
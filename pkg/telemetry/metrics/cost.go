@@ -21,6 +21,9 @@ type CostMetrics struct {
 
 	// Cost per token (derived metric, recorded as gauge)
 	costPerToken *prometheus.GaugeVec
+
+	// Tenant label values of unattributed requests
+	unattributed []string
 }
 
 // NewCostMetrics creates and registers cost metrics with the provided registry.
@@ -33,7 +36,7 @@ func NewCostMetrics(cfg *config.MetricsConfig, registry *prometheus.Registry) *C
 				Name:      "cost_total",
 				Help:      "Total cost in USD by provider and model",
 			},
-			[]string{"provider", "model"},
+			withTenantLabels(cfg, "provider", "model"),
 		),
 
 		costPerRequest: prometheus.NewHistogramVec(
@@ -57,6 +60,8 @@ func NewCostMetrics(cfg *config.MetricsConfig, registry *prometheus.Registry) *C
 			},
 			[]string{"provider", "model"},
 		),
+
+		unattributed: unattributed(cfg),
 	}
 
 	// Register all metrics
@@ -82,11 +87,17 @@ func NewCostMetrics(cfg *config.MetricsConfig, registry *prometheus.Registry) *C
 //
 //	cm.RecordRequestCost("openai", "gpt-4", 0.05)
 func (cm *CostMetrics) RecordRequestCost(provider, model string, costUSD float64) {
+	cm.recordRequestCost(cm.unattributed, provider, model, costUSD)
+}
+
+// recordRequestCost records the cost of a request with the tenant label
+// values of the request, if the tenant labels are enabled.
+func (cm *CostMetrics) recordRequestCost(tenant []string, provider, model string, costUSD float64) {
 	if costUSD <= 0 {
 		return
 	}
 
-	cm.costTotal.WithLabelValues(provider, model).Add(costUSD)
+	cm.costTotal.WithLabelValues(labelValues(tenant, provider, model)...).Add(costUSD)
 	cm.costPerRequest.WithLabelValues(provider, model).Observe(costUSD)
 }
 
@@ -38,6 +38,25 @@
 //	// Record policy metrics
 //	collector.RecordPolicyEvaluation("cost-limit", "allow", 2*time.Millisecond)
 //
+//	// Record per-rule metrics (the collector implements engine.MetricsRecorder)
+//	policyEngine.SetMetricsRecorder(collector)
+//
+// # Slow Rules
+//
+// The collector keeps in-memory latency aggregates per policy rule. The
+// slowest rules by average evaluation time are available via SlowRules and
+// served as JSON by SlowRulesHandler, mounted at /admin/policy/slow-rules.
+//
+// # Runtime Metrics
+//
+// The collector registers the Go runtime and process collectors under the
+// namespace, such as mercator_go_gc_duration_seconds (GC pauses),
+// mercator_go_memstats_heap_alloc_bytes, mercator_go_goroutines,
+// mercator_process_open_fds and mercator_process_resident_memory_bytes.
+// Queue depth gauges for the evidence recorder and the evidence stream
+// exporters are registered with RegisterEvidenceRecorder and
+// RegisterEvidenceStream.
+//
 // # Performance
 //
 // The metrics package is optimized for minimal overhead:
@@ -71,6 +90,32 @@
 //   - Low-frequency labels aggregated into "other"
 //   - Warnings logged when approaching limits
 //
+// # Tenant Labels
+//
+// With telemetry.metrics.tenant_labels enabled, the request, token, cost,
+// rule evaluation and action execution counters carry tenant and team
+// labels, for per-team dashboards. Histograms are left unlabeled.
+// RecordTenantRequest records a request of a tenant and team, and the
+// collector implements engine.TenantMetricsRecorder, which attributes rule
+// metrics to the tenant and team set with engine.ContextWithMetricsTenant.
+//
+// Each label has a cardinality budget of max_values distinct values (100 by
+// default). Tenants and teams first seen after their label's budget is
+// spent are recorded as "other", so a misbehaving client cannot create
+// unbounded series. Unattributed measurements have empty labels.
+//
+// # Service Level Objectives
+//
+// With objectives configured under telemetry.metrics.slo, every request
+// recorded with RecordRequest is counted as good or bad against each
+// objective in mercator_slo_requests_total{slo, result}. Availability
+// objectives count failed requests as bad; latency objectives count
+// requests slower than their threshold as bad and ignore failed requests.
+//
+// SLORules builds the matching Prometheus rule file: error ratio recording
+// rules and multi-window burn-rate alerts, with thresholds scaled to each
+// objective's window. The `mercator slo rules` command writes it as YAML.
+//
 // # Integration with pkg/limits/metrics.go
 //
 // The collector extends (but does not replace) the existing metrics in
@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// EvidenceRecorderStats contains the write queue counters of the evidence
+// recorder.
+type EvidenceRecorderStats struct {
+	Queued       int
+	Spilled      int64
+	Replayed     int64
+	SpillPending int64
+	Dropped      int64
+	SampledOut   int64
+}
+
+// RegisterEvidenceRecorder registers metrics reading the evidence
+// recorder's write queue counters from stats on every scrape.
+//
+// Metrics:
+//   - mercator_evidence_queue_depth: Records waiting in the recorder's write queue
+//   - mercator_evidence_records_spilled_total: Records spilled to disk because the queue was full
+//   - mercator_evidence_records_replayed_total: Spilled records written to storage
+//   - mercator_evidence_spill_pending: Records waiting in the spill queue
+//   - mercator_evidence_records_dropped_total: Records dropped because the queue was full
+//   - mercator_evidence_records_sampled_out_total: Records discarded by sampling rules
+func (c *Collector) RegisterEvidenceRecorder(stats func() EvidenceRecorderStats) {
+	opts := func(name, help string) prometheus.Opts {
+		return prometheus.Opts{
+			Namespace: c.config.Namespace,
+			Subsystem: c.config.Subsystem,
+			Name:      name,
+			Help:      help,
+		}
+	}
+
+	c.registry.MustRegister(
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts(opts(
+			"evidence_queue_depth", "Current number of evidence records waiting in the recorder queue",
+		)), func() float64 { return float64(stats().Queued) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts(opts(
+			"evidence_records_spilled_total", "Total number of evidence records spilled to disk because the recorder queue was full",
+		)), func() float64 { return float64(stats().Spilled) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts(opts(
+			"evidence_records_replayed_total", "Total number of spilled evidence records written to storage",
+		)), func() float64 { return float64(stats().Replayed) }),
+		prometheus.NewGaugeFunc(prometheus.GaugeOpts(opts(
+			"evidence_spill_pending", "Current number of evidence records in the spill queue",
+		)), func() float64 { return float64(stats().SpillPending) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts(opts(
+			"evidence_records_dropped_total", "Total number of evidence records dropped because the recorder queue was full",
+		)), func() float64 { return float64(stats().Dropped) }),
+		prometheus.NewCounterFunc(prometheus.CounterOpts(opts(
+			"evidence_records_sampled_out_total", "Total number of evidence records discarded by the recorder's sampling rules",
+		)), func() float64 { return float64(stats().SampledOut) }),
+	)
+}
+
+// RegisterEvidenceStream registers a metric reading the queue depth of
+// each evidence stream exporter from queued on every scrape. queued
+// returns the number of queued records keyed by exporter name.
+//
+// Metrics:
+//   - mercator_evidence_stream_queue_depth: Records waiting in an exporter's queue
+func (c *Collector) RegisterEvidenceStream(queued func() map[string]int) {
+	c.registry.MustRegister(&queueDepthCollector{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName(c.config.Namespace, c.config.Subsystem, "evidence_stream_queue_depth"),
+			"Current number of evidence records waiting in the queue of a stream exporter",
+			[]string{"exporter"}, nil,
+		),
+		queued: queued,
+	})
+}
+
+// queueDepthCollector reports the depth of a set of named queues.
+type queueDepthCollector struct {
+	desc   *prometheus.Desc
+	queued func() map[string]int
+}
+
+// Describe implements prometheus.Collector.
+func (q *queueDepthCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- q.desc
+}
+
+// Collect implements prometheus.Collector.
+func (q *queueDepthCollector) Collect(ch chan<- prometheus.Metric) {
+	for name, depth := range q.queued() {
+		ch <- prometheus.MustNewConstMetric(q.desc, prometheus.GaugeValue, float64(depth), name)
+	}
+}
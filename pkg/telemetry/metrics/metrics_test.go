@@ -1,6 +1,7 @@
 package metrics
 
 import (
+	"runtime"
 	"testing"
 	"time"
 
@@ -246,6 +247,50 @@ func TestCardinalityLimiter(t *testing.T) {
 	}
 }
 
+// TestCollector_TenantLabels tests tenant and team labels within the
+// cardinality budget of each label
+func TestCollector_TenantLabels(t *testing.T) {
+	cfg := testConfig()
+	cfg.TenantLabels = config.MetricsTenantLabelsConfig{Enabled: true, MaxValues: 2}
+	collector := NewCollector(cfg, prometheus.NewRegistry())
+
+	collector.RecordTenantRequest("acme", "search", "openai", "gpt-4", "success", time.Second, 100, 0.01)
+	collector.RecordTenantRequest("globex", "search", "openai", "gpt-4", "success", time.Second, 100, 0.01)
+	collector.RecordTenantRequest("initech", "ads", "openai", "gpt-4", "success", time.Second, 100, 0.01)
+	collector.RecordTenantRequest("umbrella", "billing", "openai", "gpt-4", "success", time.Second, 100, 0.01)
+	collector.RecordRequest("openai", "gpt-4", "success", time.Second, 100, 0.01)
+
+	requests := collector.requestMetrics.requestsTotal
+	tests := []struct {
+		tenant string
+		team   string
+		want   float64
+	}{
+		{"acme", "search", 1},
+		{"globex", "search", 1},
+		{"other", "ads", 1},
+		{"other", "other", 1},
+		{"", "", 1},
+	}
+	for _, tt := range tests {
+		if got := testutil.ToFloat64(requests.WithLabelValues("openai", "gpt-4", "success", tt.tenant, tt.team)); got != tt.want {
+			t.Errorf("requests{tenant=%q,team=%q} = %v, want %v", tt.tenant, tt.team, got, tt.want)
+		}
+	}
+	if got := testutil.ToFloat64(collector.costMetrics.costTotal.WithLabelValues("openai", "gpt-4", "other", "other")); got != 0.01 {
+		t.Errorf("cost{tenant=other,team=other} = %v, want 0.01", got)
+	}
+
+	collector.RecordTenantRuleEvaluation("acme", "search", "limits", "block", true, time.Millisecond)
+	collector.RecordTenantActionExecution("acme", "search", "limits", "block", "deny", true)
+	if got := testutil.ToFloat64(collector.policyMetrics.ruleEvaluationsTotal.WithLabelValues("limits", "block", "match", "acme", "search")); got != 1 {
+		t.Errorf("rule evaluations = %v, want 1", got)
+	}
+	if got := testutil.ToFloat64(collector.policyMetrics.actionExecutionsTotal.WithLabelValues("limits", "block", "deny", "success", "acme", "search")); got != 1 {
+		t.Errorf("action executions = %v, want 1", got)
+	}
+}
+
 // TestRequestMetrics_RecordTokens tests token recording
 func TestRequestMetrics_RecordTokens(t *testing.T) {
 	cfg := testConfig()
@@ -368,3 +413,93 @@ func TestCollector_ConcurrentRecording(t *testing.T) {
 		t.Errorf("Expected 1000 requests, got %f", count)
 	}
 }
+
+// TestCollector_RegisterEvidenceRecorder tests the evidence recorder metrics
+func TestCollector_RegisterEvidenceRecorder(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	collector := NewCollector(testConfig(), registry)
+
+	collector.RegisterEvidenceRecorder(func() EvidenceRecorderStats {
+		return EvidenceRecorderStats{Queued: 7, Spilled: 5, Replayed: 3, SpillPending: 2, Dropped: 1}
+	})
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	want := map[string]float64{
+		"test_metrics_evidence_queue_depth":            7,
+		"test_metrics_evidence_records_spilled_total":  5,
+		"test_metrics_evidence_records_replayed_total": 3,
+		"test_metrics_evidence_spill_pending":          2,
+		"test_metrics_evidence_records_dropped_total":  1,
+	}
+	for _, family := range families {
+		value, ok := want[family.GetName()]
+		if !ok {
+			continue
+		}
+		metric := family.GetMetric()[0]
+		got := metric.GetCounter().GetValue() + metric.GetGauge().GetValue()
+		if got != value {
+			t.Errorf("%s = %v, want %v", family.GetName(), got, value)
+		}
+		delete(want, family.GetName())
+	}
+	for name := range want {
+		t.Errorf("Metric %s not registered", name)
+	}
+}
+
+// TestCollector_RegisterEvidenceStream tests the evidence stream queue depth
+func TestCollector_RegisterEvidenceStream(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	collector := NewCollector(testConfig(), registry)
+
+	collector.RegisterEvidenceStream(func() map[string]int {
+		return map[string]int{"kafka": 12, "syslog": 0}
+	})
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	got := map[string]float64{}
+	for _, family := range families {
+		if family.GetName() != "test_metrics_evidence_stream_queue_depth" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			got[metric.GetLabel()[0].GetValue()] = metric.GetGauge().GetValue()
+		}
+	}
+	if got["kafka"] != 12 || len(got) != 2 {
+		t.Errorf("evidence_stream_queue_depth = %v, want kafka=12 syslog=0", got)
+	}
+}
+
+// TestCollector_RuntimeMetrics tests the Go runtime and process metrics
+func TestCollector_RuntimeMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	NewCollector(testConfig(), registry)
+
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	want := map[string]bool{
+		"test_go_goroutines":                 true,
+		"test_go_gc_duration_seconds":        true,
+		"test_go_memstats_heap_alloc_bytes":  true,
+		"test_process_resident_memory_bytes": runtime.GOOS == "linux",
+		"test_process_open_fds":              runtime.GOOS == "linux",
+	}
+	for _, family := range families {
+		delete(want, family.GetName())
+	}
+	for name, required := range want {
+		if required {
+			t.Errorf("Metric %s not registered", name)
+		}
+	}
+}
@@ -15,6 +15,10 @@ import (
 //   - mercator_policy_evaluation_duration_seconds: Policy evaluation duration
 //   - mercator_policy_hits_total: Number of times a policy rule matched
 //   - mercator_policy_misses_total: Number of times a policy rule did not match
+//   - mercator_policy_rule_evaluations_total: Rule evaluations by policy, rule, and result
+//   - mercator_policy_rule_duration_seconds: Rule evaluation duration by policy and rule
+//   - mercator_policy_action_executions_total: Action executions by policy, rule, action, and status
+//   - mercator_policy_duration_seconds: Per-policy evaluation duration
 type PolicyMetrics struct {
 	// Total policy evaluations
 	evaluationsTotal *prometheus.CounterVec
@@ -27,6 +31,21 @@ type PolicyMetrics struct {
 
 	// Policy rule misses (rule did not match)
 	missesTotal *prometheus.CounterVec
+
+	// Rule evaluations labeled by policy, rule, and match result
+	ruleEvaluationsTotal *prometheus.CounterVec
+
+	// Rule evaluation duration labeled by policy and rule
+	ruleDuration *prometheus.HistogramVec
+
+	// Action executions labeled by policy, rule, action type, and status
+	actionExecutionsTotal *prometheus.CounterVec
+
+	// Per-policy evaluation duration
+	policyDuration *prometheus.HistogramVec
+
+	// Tenant label values of unattributed evaluations
+	unattributed []string
 }
 
 // NewPolicyMetrics creates and registers policy metrics with the provided registry.
@@ -73,6 +92,50 @@ func NewPolicyMetrics(cfg *config.MetricsConfig, registry *prometheus.Registry)
 			},
 			[]string{"rule_id"},
 		),
+
+		ruleEvaluationsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: cfg.Namespace,
+				Subsystem: cfg.Subsystem,
+				Name:      "policy_rule_evaluations_total",
+				Help:      "Total number of rule evaluations by policy, rule, and result",
+			},
+			withTenantLabels(cfg, "policy", "rule", "result"),
+		),
+
+		ruleDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: cfg.Namespace,
+				Subsystem: cfg.Subsystem,
+				Name:      "policy_rule_duration_seconds",
+				Help:      "Duration of rule evaluation in seconds",
+				Buckets:   prometheus.ExponentialBuckets(0.000001, 2, 15), // 1µs to 16ms
+			},
+			[]string{"policy", "rule"},
+		),
+
+		actionExecutionsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: cfg.Namespace,
+				Subsystem: cfg.Subsystem,
+				Name:      "policy_action_executions_total",
+				Help:      "Total number of policy action executions",
+			},
+			withTenantLabels(cfg, "policy", "rule", "action", "status"),
+		),
+
+		policyDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: cfg.Namespace,
+				Subsystem: cfg.Subsystem,
+				Name:      "policy_duration_seconds",
+				Help:      "Duration of evaluating all rules of a policy in seconds",
+				Buckets:   prometheus.ExponentialBuckets(0.000001, 2, 18), // 1µs to 131ms
+			},
+			[]string{"policy"},
+		),
+
+		unattributed: unattributed(cfg),
 	}
 
 	// Register all metrics
@@ -81,6 +144,10 @@ func NewPolicyMetrics(cfg *config.MetricsConfig, registry *prometheus.Registry)
 		pm.evaluationDuration,
 		pm.hitsTotal,
 		pm.missesTotal,
+		pm.ruleEvaluationsTotal,
+		pm.ruleDuration,
+		pm.actionExecutionsTotal,
+		pm.policyDuration,
 	)
 
 	return pm
@@ -121,3 +188,51 @@ func (pm *PolicyMetrics) RecordHit(ruleID string) {
 func (pm *PolicyMetrics) RecordMiss(ruleID string) {
 	pm.missesTotal.WithLabelValues(ruleID).Inc()
 }
+
+// RecordRuleEvaluation records the evaluation of a single rule.
+//
+// Parameters:
+//   - policyID: Policy identifier
+//   - ruleID: Rule identifier within the policy
+//   - matched: Whether the rule's conditions matched
+//   - duration: Time taken to evaluate the rule
+func (pm *PolicyMetrics) RecordRuleEvaluation(policyID, ruleID string, matched bool, duration time.Duration) {
+	pm.recordRuleEvaluation(pm.unattributed, policyID, ruleID, matched, duration)
+}
+
+// recordRuleEvaluation records a rule evaluation with the tenant label
+// values of the request, if the tenant labels are enabled.
+func (pm *PolicyMetrics) recordRuleEvaluation(tenant []string, policyID, ruleID string, matched bool, duration time.Duration) {
+	result := "miss"
+	if matched {
+		result = "match"
+	}
+	pm.ruleEvaluationsTotal.WithLabelValues(labelValues(tenant, policyID, ruleID, result)...).Inc()
+	pm.ruleDuration.WithLabelValues(policyID, ruleID).Observe(duration.Seconds())
+}
+
+// RecordActionExecution records the execution of a rule action.
+//
+// Parameters:
+//   - policyID: Policy identifier
+//   - ruleID: Rule identifier within the policy
+//   - actionType: Action type ("deny", "redact", "route", ...)
+//   - success: Whether the action executed successfully
+func (pm *PolicyMetrics) RecordActionExecution(policyID, ruleID, actionType string, success bool) {
+	pm.recordActionExecution(pm.unattributed, policyID, ruleID, actionType, success)
+}
+
+// recordActionExecution records an action execution with the tenant label
+// values of the request, if the tenant labels are enabled.
+func (pm *PolicyMetrics) recordActionExecution(tenant []string, policyID, ruleID, actionType string, success bool) {
+	status := "success"
+	if !success {
+		status = "error"
+	}
+	pm.actionExecutionsTotal.WithLabelValues(labelValues(tenant, policyID, ruleID, actionType, status)...).Inc()
+}
+
+// RecordPolicyDuration records the time spent evaluating a policy.
+func (pm *PolicyMetrics) RecordPolicyDuration(policyID string, duration time.Duration) {
+	pm.policyDuration.WithLabelValues(policyID).Observe(duration.Seconds())
+}
@@ -27,6 +27,9 @@ type RequestMetrics struct {
 
 	// Request/response size in bytes
 	sizeBytes *prometheus.HistogramVec
+
+	// Tenant label values of unattributed requests
+	unattributed []string
 }
 
 // NewRequestMetrics creates and registers request metrics with the provided registry.
@@ -39,7 +42,7 @@ func NewRequestMetrics(cfg *config.MetricsConfig, registry *prometheus.Registry)
 				Name:      "requests_total",
 				Help:      "Total number of LLM requests processed",
 			},
-			[]string{"provider", "model", "status"},
+			withTenantLabels(cfg, "provider", "model", "status"),
 		),
 
 		requestDuration: prometheus.NewHistogramVec(
@@ -60,7 +63,7 @@ func NewRequestMetrics(cfg *config.MetricsConfig, registry *prometheus.Registry)
 				Name:      "request_tokens_total",
 				Help:      "Total number of tokens processed",
 			},
-			[]string{"provider", "model", "type"},
+			withTenantLabels(cfg, "provider", "model", "type"),
 		),
 
 		sizeBytes: prometheus.NewHistogramVec(
@@ -73,6 +76,8 @@ func NewRequestMetrics(cfg *config.MetricsConfig, registry *prometheus.Registry)
 			},
 			[]string{"provider", "model", "direction"},
 		),
+
+		unattributed: unattributed(cfg),
 	}
 
 	// Register all metrics
@@ -95,15 +100,21 @@ func NewRequestMetrics(cfg *config.MetricsConfig, registry *prometheus.Registry)
 //   - duration: Request duration
 //   - tokens: Total token count
 func (rm *RequestMetrics) RecordRequest(provider, model, status string, duration time.Duration, tokens int) {
+	rm.recordRequest(rm.unattributed, provider, model, status, duration, tokens)
+}
+
+// recordRequest records a completed request with the tenant label values
+// of the request, if the tenant labels are enabled.
+func (rm *RequestMetrics) recordRequest(tenant []string, provider, model, status string, duration time.Duration, tokens int) {
 	// Increment request counter
-	rm.requestsTotal.WithLabelValues(provider, model, status).Inc()
+	rm.requestsTotal.WithLabelValues(labelValues(tenant, provider, model, status)...).Inc()
 
 	// Record duration
 	rm.requestDuration.WithLabelValues(provider, model).Observe(duration.Seconds())
 
 	// Record tokens (if known)
 	if tokens > 0 {
-		rm.tokensTotal.WithLabelValues(provider, model, "total").Add(float64(tokens))
+		rm.tokensTotal.WithLabelValues(labelValues(tenant, provider, model, "total")...).Add(float64(tokens))
 	}
 }
 
@@ -116,10 +127,10 @@ func (rm *RequestMetrics) RecordRequest(provider, model, status string, duration
 //   - completionTokens: Number of tokens in the completion
 func (rm *RequestMetrics) RecordTokens(provider, model string, promptTokens, completionTokens int) {
 	if promptTokens > 0 {
-		rm.tokensTotal.WithLabelValues(provider, model, "prompt").Add(float64(promptTokens))
+		rm.tokensTotal.WithLabelValues(labelValues(rm.unattributed, provider, model, "prompt")...).Add(float64(promptTokens))
 	}
 	if completionTokens > 0 {
-		rm.tokensTotal.WithLabelValues(provider, model, "completion").Add(float64(completionTokens))
+		rm.tokensTotal.WithLabelValues(labelValues(rm.unattributed, provider, model, "completion")...).Add(float64(completionTokens))
 	}
 }
 
@@ -0,0 +1,24 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// registerRuntimeMetrics registers the Go runtime and process collectors
+// in registry, prefixed with the namespace so they are scraped along with
+// the proxy's own metrics.
+//
+// Metrics:
+//   - mercator_go_gc_duration_seconds: GC pause durations
+//   - mercator_go_goroutines: Number of goroutines
+//   - mercator_go_memstats_*: Heap and allocator statistics
+//   - mercator_process_open_fds, mercator_process_max_fds: File descriptors
+//   - mercator_process_resident_memory_bytes: Resident set size
+//   - mercator_process_cpu_seconds_total: CPU time
+func registerRuntimeMetrics(namespace string, registry *prometheus.Registry) {
+	prometheus.WrapRegistererWithPrefix(namespace+"_", registry).MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
@@ -0,0 +1,76 @@
+package metrics
+
+import (
+	"time"
+
+	"mercator-hq/jupiter/pkg/config"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// SLO indicator results.
+const (
+	sloGood = "good"
+	sloBad  = "bad"
+)
+
+// SLOMetrics counts good and bad requests against the configured service
+// level objectives.
+//
+// Metrics:
+//   - mercator_slo_requests_total: Requests by objective and result (good, bad)
+type SLOMetrics struct {
+	requestsTotal *prometheus.CounterVec
+	objectives    []config.SLOObjectiveConfig
+}
+
+// NewSLOMetrics creates and registers SLO metrics with the provided
+// registry. The counters of every objective start at zero, so burn rates
+// are defined before the first bad request.
+func NewSLOMetrics(cfg *config.MetricsConfig, registry *prometheus.Registry) *SLOMetrics {
+	sm := &SLOMetrics{
+		requestsTotal: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: cfg.Namespace,
+				Subsystem: cfg.Subsystem,
+				Name:      "slo_requests_total",
+				Help:      "Total number of requests counted against a service level objective, by result",
+			},
+			[]string{"slo", "result"},
+		),
+		objectives: cfg.SLO.Objectives,
+	}
+
+	for _, objective := range sm.objectives {
+		sm.requestsTotal.WithLabelValues(objective.Name, sloGood)
+		sm.requestsTotal.WithLabelValues(objective.Name, sloBad)
+	}
+
+	registry.MustRegister(sm.requestsTotal)
+
+	return sm
+}
+
+// RecordRequest counts a completed request against every objective it
+// applies to.
+func (sm *SLOMetrics) RecordRequest(status string, duration time.Duration) {
+	for _, objective := range sm.objectives {
+		result := sloGood
+		switch objective.Type {
+		case "availability":
+			if status == "error" {
+				result = sloBad
+			}
+		case "latency":
+			if status == "error" {
+				continue
+			}
+			if duration > objective.Threshold {
+				result = sloBad
+			}
+		default:
+			continue
+		}
+		sm.requestsTotal.WithLabelValues(objective.Name, result).Inc()
+	}
+}
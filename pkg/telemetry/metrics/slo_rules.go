@@ -0,0 +1,160 @@
+package metrics
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"mercator-hq/jupiter/pkg/config"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// RuleFile is a Prometheus rule file.
+type RuleFile struct {
+	Groups []RuleGroup `yaml:"groups"`
+}
+
+// RuleGroup is a group of Prometheus rules evaluated together.
+type RuleGroup struct {
+	Name  string `yaml:"name"`
+	Rules []Rule `yaml:"rules"`
+}
+
+// Rule is a Prometheus recording rule (Record set) or alerting rule
+// (Alert set).
+type Rule struct {
+	Record      string            `yaml:"record,omitempty"`
+	Alert       string            `yaml:"alert,omitempty"`
+	Expr        string            `yaml:"expr"`
+	For         string            `yaml:"for,omitempty"`
+	Labels      map[string]string `yaml:"labels,omitempty"`
+	Annotations map[string]string `yaml:"annotations,omitempty"`
+}
+
+// burnRateAlert is a multi-window burn-rate alert: it fires when the error
+// budget burns faster than budget/window over both the long and the short
+// window. The short window makes the alert reset soon after the burn
+// stops.
+type burnRateAlert struct {
+	severity string
+	long     time.Duration
+	short    time.Duration
+	budget   float64
+	forDur   string
+}
+
+// burnRateAlerts are the multi-window alerts recommended by the Google SRE
+// workbook: page when 2% of the budget burns within 1h or 5% within 6h,
+// open a ticket when 10% burns within 1d or 3d.
+var burnRateAlerts = []burnRateAlert{
+	{severity: "page", long: time.Hour, short: 5 * time.Minute, budget: 0.02, forDur: "2m"},
+	{severity: "page", long: 6 * time.Hour, short: 30 * time.Minute, budget: 0.05, forDur: "15m"},
+	{severity: "ticket", long: 24 * time.Hour, short: 2 * time.Hour, budget: 0.10, forDur: "1h"},
+	{severity: "ticket", long: 72 * time.Hour, short: 6 * time.Hour, budget: 0.10, forDur: "3h"},
+}
+
+// SLORules returns the Prometheus recording and alerting rules of the
+// configured objectives: the error ratio of each objective over the alert
+// windows, and multi-window burn-rate alerts with thresholds scaled to the
+// objective's window. Alerts with a long window exceeding the objective's
+// window are omitted.
+func SLORules(cfg *config.MetricsConfig) *RuleFile {
+	namespace, subsystem := cfg.Namespace, cfg.Subsystem
+	if namespace == "" {
+		namespace = "mercator"
+	}
+	if subsystem == "" {
+		subsystem = "jupiter"
+	}
+	metric := prometheus.BuildFQName(namespace, subsystem, "slo_requests_total")
+
+	file := &RuleFile{Groups: []RuleGroup{}}
+	for _, objective := range cfg.SLO.Objectives {
+		window := objective.Window
+		if window == 0 {
+			window = config.DefaultSLOWindow
+		}
+
+		var alerts []burnRateAlert
+		for _, alert := range burnRateAlerts {
+			if alert.long <= window {
+				alerts = append(alerts, alert)
+			}
+		}
+
+		// Error ratios of the windows used by the alerts, shortest first
+		var windows []time.Duration
+		seen := make(map[time.Duration]bool)
+		for _, w := range []time.Duration{5 * time.Minute, 30 * time.Minute, time.Hour, 2 * time.Hour, 6 * time.Hour, 24 * time.Hour, 72 * time.Hour} {
+			for _, alert := range alerts {
+				if (alert.long == w || alert.short == w) && !seen[w] {
+					seen[w] = true
+					windows = append(windows, w)
+				}
+			}
+		}
+
+		group := RuleGroup{Name: "slo-" + objective.Name}
+		selector := fmt.Sprintf(`slo=%q`, objective.Name)
+		for _, w := range windows {
+			group.Rules = append(group.Rules, Rule{
+				Record: errorRatioRecord(metric, w),
+				Expr: fmt.Sprintf(`sum(rate(%s{%s,result="bad"}[%s])) / sum(rate(%s{%s}[%s]))`,
+					metric, selector, promDuration(w), metric, selector, promDuration(w)),
+				Labels: map[string]string{"slo": objective.Name},
+			})
+		}
+
+		target := strconv.FormatFloat(objective.Target, 'f', -1, 64)
+		for _, alert := range alerts {
+			// Burn rate at which the alert's share of the budget is
+			// consumed within its long window
+			factor := math.Round(alert.budget*window.Hours()/alert.long.Hours()*100) / 100
+			threshold := fmt.Sprintf("(%s * (1 - %s))", strconv.FormatFloat(factor, 'f', -1, 64), target)
+
+			group.Rules = append(group.Rules, Rule{
+				Alert: "SLOErrorBudgetBurn",
+				Expr: fmt.Sprintf("%s{%s} > %s and %s{%s} > %s",
+					errorRatioRecord(metric, alert.long), selector, threshold,
+					errorRatioRecord(metric, alert.short), selector, threshold),
+				For: alert.forDur,
+				Labels: map[string]string{
+					"slo":         objective.Name,
+					"severity":    alert.severity,
+					"long_window": promDuration(alert.long),
+				},
+				Annotations: map[string]string{
+					"summary": fmt.Sprintf("SLO %s is burning its error budget %sx too fast", objective.Name, strconv.FormatFloat(factor, 'f', -1, 64)),
+					"description": fmt.Sprintf("At the current error rate, the %s objective %s (target %s over %s) consumes %.0f%% of its error budget within %s.",
+						objective.Type, objective.Name, target, promDuration(window), alert.budget*100, promDuration(alert.long)),
+				},
+			})
+		}
+
+		file.Groups = append(file.Groups, group)
+	}
+	return file
+}
+
+// errorRatioRecord returns the name of the recording rule of the error
+// ratio of counter over window, such as
+// slo:mercator_jupiter_slo_requests:error_ratio_rate5m.
+func errorRatioRecord(counter string, window time.Duration) string {
+	return "slo:" + strings.TrimSuffix(counter, "_total") + ":error_ratio_rate" + promDuration(window)
+}
+
+// promDuration formats d as a Prometheus duration in its largest whole
+// unit, such as 5m, 6h or 3d.
+func promDuration(d time.Duration) string {
+	switch {
+	case d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	default:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+}
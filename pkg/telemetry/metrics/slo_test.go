@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"mercator-hq/jupiter/pkg/config"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func sloTestConfig() *config.MetricsConfig {
+	cfg := testConfig()
+	cfg.SLO.Objectives = []config.SLOObjectiveConfig{
+		{Name: "availability", Type: "availability", Target: 0.999, Window: 30 * 24 * time.Hour},
+		{Name: "latency", Type: "latency", Target: 0.99, Threshold: 2 * time.Second, Window: 30 * 24 * time.Hour},
+	}
+	return cfg
+}
+
+// TestCollector_SLOMetrics tests that requests are counted against the
+// objectives they meet or miss
+func TestCollector_SLOMetrics(t *testing.T) {
+	collector := NewCollector(sloTestConfig(), prometheus.NewRegistry())
+
+	collector.RecordRequest("openai", "gpt-4", "success", time.Second, 100, 0.01)
+	collector.RecordRequest("openai", "gpt-4", "success", 5*time.Second, 100, 0.01)
+	collector.RecordRequest("openai", "gpt-4", "blocked", 10*time.Millisecond, 0, 0)
+	collector.RecordRequest("openai", "gpt-4", "error", 30*time.Second, 0, 0)
+
+	counter := collector.sloMetrics.requestsTotal
+	tests := []struct {
+		slo, result string
+		want        float64
+	}{
+		{"availability", "good", 3},
+		{"availability", "bad", 1},
+		// Failed requests are not counted against latency
+		{"latency", "good", 2},
+		{"latency", "bad", 1},
+	}
+	for _, tt := range tests {
+		if got := testutil.ToFloat64(counter.WithLabelValues(tt.slo, tt.result)); got != tt.want {
+			t.Errorf("slo_requests_total{slo=%q,result=%q} = %v, want %v", tt.slo, tt.result, got, tt.want)
+		}
+	}
+}
+
+// TestCollector_SLOMetricsDisabled tests that no SLO counter is registered
+// without objectives
+func TestCollector_SLOMetricsDisabled(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	collector := NewCollector(testConfig(), registry)
+	collector.RecordRequest("openai", "gpt-4", "success", time.Second, 100, 0.01)
+
+	if collector.sloMetrics != nil {
+		t.Fatal("Expected no SLO metrics without objectives")
+	}
+	families, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("Gather() error = %v", err)
+	}
+	for _, family := range families {
+		if strings.Contains(family.GetName(), "slo_requests_total") {
+			t.Errorf("Unexpected metric %s", family.GetName())
+		}
+	}
+}
+
+func TestSLORules(t *testing.T) {
+	cfg := sloTestConfig()
+	cfg.SLO.Objectives[1].Window = 24 * time.Hour
+
+	rules := SLORules(cfg)
+	if len(rules.Groups) != 2 {
+		t.Fatalf("Expected 2 rule groups, got %d", len(rules.Groups))
+	}
+
+	var records, alerts []Rule
+	for _, rule := range rules.Groups[0].Rules {
+		if rule.Record != "" {
+			records = append(records, rule)
+		} else {
+			alerts = append(alerts, rule)
+		}
+	}
+	if len(records) != 7 || len(alerts) != 4 {
+		t.Fatalf("Expected 7 recording and 4 alerting rules, got %d and %d", len(records), len(alerts))
+	}
+
+	record := records[0]
+	if record.Record != "slo:test_metrics_slo_requests:error_ratio_rate5m" {
+		t.Errorf("Record = %q", record.Record)
+	}
+	wantExpr := `sum(rate(test_metrics_slo_requests_total{slo="availability",result="bad"}[5m])) / sum(rate(test_metrics_slo_requests_total{slo="availability"}[5m]))`
+	if record.Expr != wantExpr {
+		t.Errorf("Expr = %q, want %q", record.Expr, wantExpr)
+	}
+
+	// Thresholds are scaled to the 30 day window
+	wantFactors := []string{"(14.4 * (1 - 0.999))", "(6 * (1 - 0.999))", "(3 * (1 - 0.999))", "(1 * (1 - 0.999))"}
+	wantSeverities := []string{"page", "page", "ticket", "ticket"}
+	for i, alert := range alerts {
+		if !strings.Contains(alert.Expr, wantFactors[i]) {
+			t.Errorf("Alert %d expr = %q, want threshold %s", i, alert.Expr, wantFactors[i])
+		}
+		if alert.Labels["severity"] != wantSeverities[i] || alert.Labels["slo"] != "availability" {
+			t.Errorf("Alert %d labels = %v", i, alert.Labels)
+		}
+	}
+
+	// A 1 day window drops the 3 day alert and scales the others
+	var latencyAlerts []Rule
+	for _, rule := range rules.Groups[1].Rules {
+		if rule.Alert != "" {
+			latencyAlerts = append(latencyAlerts, rule)
+		}
+	}
+	if len(latencyAlerts) != 3 {
+		t.Fatalf("Expected 3 latency alerts, got %d", len(latencyAlerts))
+	}
+	if !strings.Contains(latencyAlerts[0].Expr, "(0.48 * (1 - 0.99))") {
+		t.Errorf("Latency alert expr = %q, want threshold (0.48 * (1 - 0.99))", latencyAlerts[0].Expr)
+	}
+}
@@ -0,0 +1,152 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RuleLatencyStats summarizes the evaluation latency of a single rule.
+type RuleLatencyStats struct {
+	// PolicyID is the policy containing the rule.
+	PolicyID string `json:"policy_id"`
+
+	// RuleID is the rule identifier within the policy.
+	RuleID string `json:"rule_id"`
+
+	// Evaluations is the number of times the rule was evaluated.
+	Evaluations int64 `json:"evaluations"`
+
+	// Matches is the number of evaluations where the rule matched.
+	Matches int64 `json:"matches"`
+
+	// AverageDuration is the mean evaluation duration.
+	AverageDuration time.Duration `json:"average_duration_ns"`
+
+	// MaxDuration is the slowest observed evaluation duration.
+	MaxDuration time.Duration `json:"max_duration_ns"`
+
+	// TotalDuration is the cumulative evaluation duration.
+	TotalDuration time.Duration `json:"total_duration_ns"`
+}
+
+// RuleLatencyTracker keeps in-memory latency aggregates per policy rule so that
+// the slowest rules can be listed without querying Prometheus.
+type RuleLatencyTracker struct {
+	mu       sync.Mutex
+	stats    map[string]*RuleLatencyStats
+	maxRules int
+}
+
+// NewRuleLatencyTracker creates a tracker that holds at most maxRules entries.
+// Rules first seen after the limit is reached are ignored.
+func NewRuleLatencyTracker(maxRules int) *RuleLatencyTracker {
+	return &RuleLatencyTracker{
+		stats:    make(map[string]*RuleLatencyStats),
+		maxRules: maxRules,
+	}
+}
+
+// Observe records a single rule evaluation.
+func (t *RuleLatencyTracker) Observe(policyID, ruleID string, matched bool, duration time.Duration) {
+	key := policyID + "/" + ruleID
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	s, ok := t.stats[key]
+	if !ok {
+		if len(t.stats) >= t.maxRules {
+			return
+		}
+		s = &RuleLatencyStats{PolicyID: policyID, RuleID: ruleID}
+		t.stats[key] = s
+	}
+
+	s.Evaluations++
+	if matched {
+		s.Matches++
+	}
+	s.TotalDuration += duration
+	if duration > s.MaxDuration {
+		s.MaxDuration = duration
+	}
+}
+
+// Top returns the n rules with the highest average evaluation duration,
+// slowest first. If n <= 0, all rules are returned.
+func (t *RuleLatencyTracker) Top(n int) []RuleLatencyStats {
+	t.mu.Lock()
+	result := make([]RuleLatencyStats, 0, len(t.stats))
+	for _, s := range t.stats {
+		entry := *s
+		if entry.Evaluations > 0 {
+			entry.AverageDuration = entry.TotalDuration / time.Duration(entry.Evaluations)
+		}
+		result = append(result, entry)
+	}
+	t.mu.Unlock()
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].AverageDuration != result[j].AverageDuration {
+			return result[i].AverageDuration > result[j].AverageDuration
+		}
+		// Deterministic ordering for equal averages
+		if result[i].PolicyID != result[j].PolicyID {
+			return result[i].PolicyID < result[j].PolicyID
+		}
+		return result[i].RuleID < result[j].RuleID
+	})
+
+	if n > 0 && len(result) > n {
+		result = result[:n]
+	}
+	return result
+}
+
+// Reset clears all tracked statistics.
+func (t *RuleLatencyTracker) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stats = make(map[string]*RuleLatencyStats)
+}
+
+// SlowRules returns the n slowest policy rules by average evaluation duration.
+func (c *Collector) SlowRules(n int) []RuleLatencyStats {
+	return c.ruleLatency.Top(n)
+}
+
+// SlowRulesHandler returns an HTTP handler that lists the slowest policy rules
+// as JSON. The number of rules is controlled by the "limit" query parameter
+// (default 10).
+//
+// Example:
+//
+//	GET /admin/policy/slow-rules?limit=5
+func (c *Collector) SlowRulesHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		limit := 10
+		if raw := r.URL.Query().Get("limit"); raw != "" {
+			parsed, err := strconv.Atoi(raw)
+			if err != nil || parsed <= 0 {
+				http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+				return
+			}
+			limit = parsed
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"rules": c.SlowRules(limit),
+		})
+	})
+}
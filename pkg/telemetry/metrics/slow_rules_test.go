@@ -0,0 +1,117 @@
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// TestRuleLatencyTracker_Top tests ordering and limiting of slow rules
+func TestRuleLatencyTracker_Top(t *testing.T) {
+	tracker := NewRuleLatencyTracker(100)
+
+	tracker.Observe("security", "block-pii", true, 4*time.Millisecond)
+	tracker.Observe("security", "block-pii", false, 2*time.Millisecond)
+	tracker.Observe("routing", "route-claude", true, 1*time.Millisecond)
+	tracker.Observe("tagging", "tag-team", false, 10*time.Millisecond)
+
+	top := tracker.Top(2)
+	if len(top) != 2 {
+		t.Fatalf("Expected 2 rules, got %d", len(top))
+	}
+	if top[0].RuleID != "tag-team" {
+		t.Errorf("Expected slowest rule tag-team, got %s", top[0].RuleID)
+	}
+	if top[1].RuleID != "block-pii" {
+		t.Errorf("Expected second slowest rule block-pii, got %s", top[1].RuleID)
+	}
+	if top[1].Evaluations != 2 || top[1].Matches != 1 {
+		t.Errorf("Expected 2 evaluations and 1 match, got %d and %d", top[1].Evaluations, top[1].Matches)
+	}
+	if top[1].AverageDuration != 3*time.Millisecond {
+		t.Errorf("Expected average 3ms, got %v", top[1].AverageDuration)
+	}
+	if top[1].MaxDuration != 4*time.Millisecond {
+		t.Errorf("Expected max 4ms, got %v", top[1].MaxDuration)
+	}
+}
+
+// TestRuleLatencyTracker_MaxRules tests that the tracker is bounded
+func TestRuleLatencyTracker_MaxRules(t *testing.T) {
+	tracker := NewRuleLatencyTracker(1)
+
+	tracker.Observe("p", "r1", true, time.Millisecond)
+	tracker.Observe("p", "r2", true, time.Millisecond)
+
+	if got := len(tracker.Top(0)); got != 1 {
+		t.Errorf("Expected 1 tracked rule, got %d", got)
+	}
+}
+
+// TestCollector_RecordRuleEvaluation tests per-rule metrics recording
+func TestCollector_RecordRuleEvaluation(t *testing.T) {
+	cfg := testConfig()
+	registry := prometheus.NewRegistry()
+	collector := NewCollector(cfg, registry)
+
+	collector.RecordRuleEvaluation("security", "block-pii", true, 2*time.Millisecond)
+	collector.RecordRuleEvaluation("security", "block-pii", false, time.Millisecond)
+	collector.RecordActionExecution("security", "block-pii", "deny", true)
+	collector.RecordPolicyDuration("security", 3*time.Millisecond)
+
+	matches := testutil.ToFloat64(collector.policyMetrics.ruleEvaluationsTotal.WithLabelValues("security", "block-pii", "match"))
+	if matches != 1 {
+		t.Errorf("Expected 1 match, got %f", matches)
+	}
+	misses := testutil.ToFloat64(collector.policyMetrics.ruleEvaluationsTotal.WithLabelValues("security", "block-pii", "miss"))
+	if misses != 1 {
+		t.Errorf("Expected 1 miss, got %f", misses)
+	}
+	actions := testutil.ToFloat64(collector.policyMetrics.actionExecutionsTotal.WithLabelValues("security", "block-pii", "deny", "success"))
+	if actions != 1 {
+		t.Errorf("Expected 1 action execution, got %f", actions)
+	}
+
+	if got := len(collector.SlowRules(10)); got != 1 {
+		t.Errorf("Expected 1 slow rule entry, got %d", got)
+	}
+}
+
+// TestCollector_SlowRulesHandler tests the slow-rules admin endpoint
+func TestCollector_SlowRulesHandler(t *testing.T) {
+	cfg := testConfig()
+	collector := NewCollector(cfg, prometheus.NewRegistry())
+
+	collector.RecordRuleEvaluation("p", "fast", false, time.Microsecond)
+	collector.RecordRuleEvaluation("p", "slow", true, time.Millisecond)
+
+	req := httptest.NewRequest(http.MethodGet, "/admin/policy/slow-rules?limit=1", nil)
+	rec := httptest.NewRecorder()
+	collector.SlowRulesHandler().ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var body struct {
+		Rules []RuleLatencyStats `json:"rules"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(body.Rules) != 1 || body.Rules[0].RuleID != "slow" {
+		t.Errorf("Expected only the slow rule, got %+v", body.Rules)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/admin/policy/slow-rules?limit=abc", nil)
+	rec = httptest.NewRecorder()
+	collector.SlowRulesHandler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for invalid limit, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,65 @@
+package metrics
+
+import (
+	"mercator-hq/jupiter/pkg/config"
+)
+
+// overflowLabel is the label value of tenants and teams over the
+// cardinality budget.
+const overflowLabel = "other"
+
+// tenantLabelNames are the names of the tenant labels, in label order.
+var tenantLabelNames = []string{"tenant", "team"}
+
+// withTenantLabels returns the label names of a metric, followed by the
+// tenant labels if they are enabled.
+func withTenantLabels(cfg *config.MetricsConfig, names ...string) []string {
+	if !cfg.TenantLabels.Enabled {
+		return names
+	}
+	return append(names, tenantLabelNames...)
+}
+
+// unattributed returns the tenant label values of measurements not
+// attributed to a tenant: empty values if the tenant labels are enabled,
+// none otherwise.
+func unattributed(cfg *config.MetricsConfig) []string {
+	if !cfg.TenantLabels.Enabled {
+		return nil
+	}
+	return []string{"", ""}
+}
+
+// labelValues appends the tenant label values to values.
+func labelValues(tenant []string, values ...string) []string {
+	return append(values, tenant...)
+}
+
+// tenantBudget applies the cardinality budget of each tenant label.
+type tenantBudget struct {
+	tenants *CardinalityLimiter
+	teams   *CardinalityLimiter
+}
+
+// newTenantBudget creates a budget of maxValues values per label.
+func newTenantBudget(maxValues int) *tenantBudget {
+	return &tenantBudget{
+		tenants: NewCardinalityLimiter(maxValues),
+		teams:   NewCardinalityLimiter(maxValues),
+	}
+}
+
+// values returns the label values of tenant and team, with values over the
+// budget of their label replaced by "other".
+func (b *tenantBudget) values(tenant, team string) []string {
+	return []string{budgeted(b.tenants, tenant), budgeted(b.teams, team)}
+}
+
+// budgeted returns value if it is empty or within the budget of limiter,
+// and "other" otherwise.
+func budgeted(limiter *CardinalityLimiter, value string) string {
+	if value == "" || limiter.Allow(value) {
+		return value
+	}
+	return overflowLabel
+}
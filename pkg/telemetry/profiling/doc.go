@@ -0,0 +1,28 @@
+// Package profiling serves runtime profiling and debug endpoints, so latency
+// and memory regressions can be investigated in production without
+// rebuilding the proxy.
+//
+// # Endpoints
+//
+// The endpoints are admin endpoints: they are only served when
+// security.admin.debug is enabled and at least one admin key is
+// configured, and every request must present an admin key:
+//
+//   - GET /admin/debug/pprof/ - net/http/pprof index and named profiles
+//     (heap, goroutine, allocs, block, mutex, threadcreate)
+//   - GET /admin/debug/pprof/profile?seconds=N - CPU profile (default 10s)
+//   - GET /admin/debug/pprof/trace?seconds=N - execution trace
+//   - GET /admin/debug/stacks - stack dump of all goroutines
+//   - GET /admin/debug/snapshot - goroutine, heap and GC statistics as JSON
+//
+// Profiles are read with the go tool, passing the admin key as a header:
+//
+//	curl -H "X-Admin-Key: $KEY" -o cpu.pprof \
+//	    https://proxy:8080/admin/debug/pprof/profile?seconds=20
+//	go tool pprof cpu.pprof
+//
+// # Timeouts
+//
+// Profile and trace durations must stay below proxy.write_timeout, or the
+// request is rejected. Raise the timeout to take longer profiles.
+package profiling
@@ -0,0 +1,163 @@
+package profiling
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// DefaultProfileSeconds is the duration of a CPU profile requested without
+// a "seconds" parameter. It stays below the default proxy write timeout,
+// which would otherwise cut the profile short.
+const DefaultProfileSeconds = "10"
+
+// startTime is when the process started serving, for the snapshot uptime.
+var startTime = time.Now()
+
+// Snapshot is a point-in-time view of the goroutines, heap and garbage
+// collector of the process.
+type Snapshot struct {
+	Time          time.Time `json:"time"`
+	UptimeSeconds float64   `json:"uptime_seconds"`
+	GoVersion     string    `json:"go_version"`
+	NumCPU        int       `json:"num_cpu"`
+	GOMAXPROCS    int       `json:"gomaxprocs"`
+	Goroutines    int       `json:"goroutines"`
+	Heap          HeapStats `json:"heap"`
+	GC            GCStats   `json:"gc"`
+}
+
+// HeapStats are the heap fields of runtime.MemStats, in bytes and objects.
+type HeapStats struct {
+	AllocBytes    uint64 `json:"alloc_bytes"`
+	InuseBytes    uint64 `json:"inuse_bytes"`
+	IdleBytes     uint64 `json:"idle_bytes"`
+	ReleasedBytes uint64 `json:"released_bytes"`
+	SysBytes      uint64 `json:"sys_bytes"`
+	Objects       uint64 `json:"objects"`
+	TotalAlloc    uint64 `json:"total_alloc_bytes"`
+	Mallocs       uint64 `json:"mallocs"`
+	Frees         uint64 `json:"frees"`
+}
+
+// GCStats are the garbage collector fields of runtime.MemStats.
+type GCStats struct {
+	NumGC        uint32    `json:"num_gc"`
+	NextGCBytes  uint64    `json:"next_gc_bytes"`
+	LastGC       time.Time `json:"last_gc"`
+	PauseTotalMs float64   `json:"pause_total_ms"`
+	LastPauseMs  float64   `json:"last_pause_ms"`
+	CPUFraction  float64   `json:"cpu_fraction"`
+}
+
+// TakeSnapshot reads the runtime statistics of the process. It briefly
+// stops the world to read the memory statistics.
+func TakeSnapshot() Snapshot {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	snapshot := Snapshot{
+		Time:          time.Now().UTC(),
+		UptimeSeconds: time.Since(startTime).Seconds(),
+		GoVersion:     runtime.Version(),
+		NumCPU:        runtime.NumCPU(),
+		GOMAXPROCS:    runtime.GOMAXPROCS(0),
+		Goroutines:    runtime.NumGoroutine(),
+		Heap: HeapStats{
+			AllocBytes:    mem.HeapAlloc,
+			InuseBytes:    mem.HeapInuse,
+			IdleBytes:     mem.HeapIdle,
+			ReleasedBytes: mem.HeapReleased,
+			SysBytes:      mem.HeapSys,
+			Objects:       mem.HeapObjects,
+			TotalAlloc:    mem.TotalAlloc,
+			Mallocs:       mem.Mallocs,
+			Frees:         mem.Frees,
+		},
+		GC: GCStats{
+			NumGC:        mem.NumGC,
+			NextGCBytes:  mem.NextGC,
+			PauseTotalMs: float64(mem.PauseTotalNs) / float64(time.Millisecond),
+			CPUFraction:  mem.GCCPUFraction,
+		},
+	}
+	if mem.NumGC > 0 {
+		snapshot.GC.LastGC = time.Unix(0, int64(mem.LastGC)).UTC()
+		snapshot.GC.LastPauseMs = float64(mem.PauseNs[(mem.NumGC+255)%256]) / float64(time.Millisecond)
+	}
+	return snapshot
+}
+
+// Handler returns the handler of the debug endpoints, to be mounted at
+// prefix + "/debug/":
+//
+//   - /debug/pprof/: the net/http/pprof index, CPU profile, execution trace
+//     and named profiles (heap, goroutine, allocs, block, mutex, ...)
+//   - /debug/stacks: a text dump of the stacks of all goroutines
+//   - /debug/snapshot: a JSON Snapshot of goroutines, heap and GC
+//
+// The handler does not authenticate requests; it must be served behind
+// admin authentication.
+func Handler(prefix string) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	mux.HandleFunc("/debug/stacks", stacks)
+	mux.HandleFunc("/debug/snapshot", snapshot)
+
+	// pprof.Index resolves profile names relative to /debug/pprof/
+	return http.StripPrefix(strings.TrimSuffix(prefix, "/"), mux)
+}
+
+// profile serves a CPU profile of DefaultProfileSeconds unless the request
+// sets the duration.
+func profile(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Query().Get("seconds") == "" {
+		query := r.URL.Query()
+		query.Set("seconds", DefaultProfileSeconds)
+		r.URL.RawQuery = query.Encode()
+	}
+	pprof.Profile(w, r)
+}
+
+// stacks serves the stacks of all goroutines, growing the buffer until the
+// dump fits.
+func stacks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Write(buf)
+}
+
+// snapshot serves a JSON Snapshot of the process.
+func snapshot(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.Header().Set("Allow", http.MethodGet)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(TakeSnapshot())
+}
@@ -0,0 +1,76 @@
+package profiling
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler(t *testing.T) {
+	handler := Handler("/admin")
+
+	tests := []struct {
+		name        string
+		path        string
+		wantStatus  int
+		wantType    string
+		wantContent string
+	}{
+		{"pprof index", "/admin/debug/pprof/", http.StatusOK, "text/html", "goroutine"},
+		{"named profile", "/admin/debug/pprof/goroutine?debug=1", http.StatusOK, "text/plain", "goroutine profile"},
+		{"heap profile", "/admin/debug/pprof/heap?debug=1", http.StatusOK, "text/plain", "heap profile"},
+		{"stacks", "/admin/debug/stacks", http.StatusOK, "text/plain", "TestHandler"},
+		{"snapshot", "/admin/debug/snapshot", http.StatusOK, "application/json", "goroutines"},
+		{"unknown profile", "/admin/debug/pprof/nonexistent", http.StatusNotFound, "", ""},
+		{"unknown endpoint", "/admin/debug/other", http.StatusNotFound, "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, rec.Code, rec.Body.String())
+			}
+			if !strings.HasPrefix(rec.Header().Get("Content-Type"), tt.wantType) {
+				t.Errorf("Content-Type = %q, want %q", rec.Header().Get("Content-Type"), tt.wantType)
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantContent) {
+				t.Errorf("Body does not contain %q", tt.wantContent)
+			}
+		})
+	}
+}
+
+func TestHandler_Snapshot(t *testing.T) {
+	rec := httptest.NewRecorder()
+	Handler("/admin").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/debug/snapshot", nil))
+
+	var snapshot Snapshot
+	if err := json.NewDecoder(rec.Body).Decode(&snapshot); err != nil {
+		t.Fatalf("Failed to decode snapshot: %v", err)
+	}
+	if snapshot.Goroutines < 1 {
+		t.Errorf("Goroutines = %d, want at least 1", snapshot.Goroutines)
+	}
+	if snapshot.Heap.AllocBytes == 0 || snapshot.Heap.SysBytes == 0 {
+		t.Errorf("Heap = %+v, want allocated bytes", snapshot.Heap)
+	}
+	if snapshot.GOMAXPROCS < 1 || snapshot.GoVersion == "" {
+		t.Errorf("Snapshot = %+v, want runtime details", snapshot)
+	}
+}
+
+func TestHandler_MethodNotAllowed(t *testing.T) {
+	for _, path := range []string{"/admin/debug/stacks", "/admin/debug/snapshot"} {
+		rec := httptest.NewRecorder()
+		Handler("/admin").ServeHTTP(rec, httptest.NewRequest(http.MethodPost, path, nil))
+
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("POST %s: expected status 405, got %d", path, rec.Code)
+		}
+	}
+}
@@ -72,10 +72,11 @@
 //	mercator.proxy.request (10s)
 //	├── mercator.processing.request (5ms)
 //	├── mercator.policy.evaluate (2ms)
-//	├── mercator.provider.call (9.9s)
-//	│   ├── mercator.provider.connect (100ms)
-//	│   ├── mercator.provider.send (50ms)
-//	│   └── mercator.provider.receive (9.75s)
+//	├── provider.request (9.9s)
+//	│   ├── provider.dns (5ms)
+//	│   ├── provider.connect (100ms)
+//	│   ├── provider.tls_handshake (40ms)
+//	│   └── provider.wait (9.75s)
 //	└── mercator.evidence.generate (10ms)
 //
 // # HTTP Integration